@@ -0,0 +1,206 @@
+package evm
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	// keccak256("Error(string)")[:4] and keccak256("Panic(uint256)")[:4].
+	revertErrorSelector = [4]byte{0x08, 0xc3, 0x79, 0xa0}
+	revertPanicSelector = [4]byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+// DecodedEvent is the "decoded" field erpc_getDecodedLogs adds to a log.
+type DecodedEvent struct {
+	Contract  string                 `json:"contract,omitempty"`
+	Name      string                 `json:"name"`
+	Signature string                 `json:"signature"`
+	Args      map[string]interface{} `json:"args"`
+}
+
+// decodeRevert renders revert data as a reason: the message of
+// Error(string), the description of Panic(uint256), or a custom error with
+// its arguments, e.g. InsufficientBalance(available: 1, required: 5). The
+// ABI of the called contract is tried first, then every known ABI. It
+// returns "" when the data cannot be decoded.
+func (r *AbiRegistry) decodeRevert(to *ethcommon.Address, data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	var sel [4]byte
+	copy(sel[:], data[:4])
+	if sel == revertErrorSelector || sel == revertPanicSelector {
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return ""
+		}
+		if sel == revertPanicSelector {
+			return "panic: " + reason
+		}
+		return reason
+	}
+
+	var abiErr *abi.Error
+	if to != nil {
+		if c, _ := r.cached(*to); c != nil {
+			for _, e := range c.abi.Errors {
+				if [4]byte(e.ID[:4]) == sel {
+					e := e
+					abiErr = &e
+					break
+				}
+			}
+		}
+	}
+	if abiErr == nil {
+		abiErr = r.errorBySelector(sel)
+	}
+	if abiErr == nil {
+		return ""
+	}
+	values, err := abiErr.Inputs.Unpack(data[4:])
+	if err != nil {
+		return ""
+	}
+	args := make([]string, len(values))
+	for i, v := range values {
+		args[i] = fmt.Sprintf("%s: %v", argName(abiErr.Inputs[i], i), formatAbiValue(v))
+	}
+	return fmt.Sprintf("%s(%s)", abiErr.Name, strings.Join(args, ", "))
+}
+
+// decodeLog decodes a log with the cached ABI of its emitter, or with any
+// known ABI declaring an event with its topic. It returns nil when the log
+// cannot be decoded, e.g. anonymous events or unknown signatures.
+func (r *AbiRegistry) decodeLog(address ethcommon.Address, topics []ethcommon.Hash, data []byte) *DecodedEvent {
+	if len(topics) == 0 {
+		return nil
+	}
+	var (
+		contract string
+		event    *abi.Event
+	)
+	if c, _ := r.cached(address); c != nil {
+		contract = c.name
+		event, _ = c.abi.EventByID(topics[0])
+	}
+	if event == nil {
+		event = r.eventByTopic(topics[0])
+	}
+	if event == nil {
+		return nil
+	}
+
+	indexed := make(abi.Arguments, 0, len(event.Inputs))
+	for _, in := range event.Inputs {
+		if in.Indexed {
+			indexed = append(indexed, in)
+		}
+	}
+	// Events of the same signature can differ in which arguments are
+	// indexed (ERC-20 vs ERC-721 Transfer); such a log is not this event.
+	if len(indexed) != len(topics)-1 {
+		return nil
+	}
+	nonIndexed, err := event.Inputs.NonIndexed().Unpack(data)
+	if err != nil {
+		return nil
+	}
+
+	args := make(map[string]interface{}, len(event.Inputs))
+	ti, ni := 1, 0
+	for i, in := range event.Inputs {
+		name := argName(in, i)
+		if !in.Indexed {
+			args[name] = formatAbiValue(nonIndexed[ni])
+			ni++
+			continue
+		}
+		topic := topics[ti]
+		ti++
+		switch in.Type.T {
+		case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+			// Dynamic and composite indexed values are stored as their
+			// keccak256 hash; the value itself is not recoverable.
+			args[name] = topic.Hex()
+		default:
+			v, err := abi.Arguments{{Type: in.Type}}.Unpack(topic.Bytes())
+			if err != nil || len(v) != 1 {
+				return nil
+			}
+			args[name] = formatAbiValue(v[0])
+		}
+	}
+	return &DecodedEvent{
+		Contract:  contract,
+		Name:      event.Name,
+		Signature: event.Sig,
+		Args:      args,
+	}
+}
+
+func argName(in abi.Argument, i int) string {
+	if in.Name != "" {
+		return in.Name
+	}
+	return fmt.Sprintf("arg%d", i)
+}
+
+// formatAbiValue turns an unpacked ABI value into a JSON-friendly one:
+// integers as decimal strings (they may exceed what JSON numbers hold),
+// addresses as checksummed hex, bytes as 0x-hex, and arrays and tuples
+// recursively.
+func formatAbiValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case *big.Int:
+		return t.String()
+	case ethcommon.Address:
+		return t.Hex()
+	case ethcommon.Hash:
+		return t.Hex()
+	case []byte:
+		return hexutil.Encode(t)
+	case string, bool:
+		return t
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", t)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = formatAbiValue(rv.Index(i).Interface())
+		}
+		return out
+	case reflect.Struct:
+		// Tuples unpack into anonymous structs whose fields carry the
+		// component names in their json tags.
+		out := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			f := rv.Type().Field(i)
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name == "" {
+				name = f.Name
+			}
+			out[name] = formatAbiValue(rv.Field(i).Interface())
+		}
+		return out
+	}
+	return fmt.Sprintf("%v", v)
+}
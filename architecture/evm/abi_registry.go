@@ -0,0 +1,338 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/singleflight"
+)
+
+// abiFetchMaxBytes bounds the body read from Sourcify or Etherscan; the
+// largest verified ABIs are a few hundred KB.
+const abiFetchMaxBytes = 8 << 20
+
+// AbiRegistryProvider is implemented by networks with an evm.abi registry.
+type AbiRegistryProvider interface {
+	EvmAbiRegistry() *AbiRegistry
+}
+
+// AbiRegistry resolves contract ABIs of one chain: from config, then from
+// Sourcify and Etherscan, caching fetched ABIs (and misses) for cacheTtl.
+//
+// Events and errors of every ABI seen are also indexed by their topic or
+// selector, so logs and reverts of contracts without a known ABI still decode
+// when another contract declares the same signature (e.g. ERC-20 Transfer).
+type AbiRegistry struct {
+	logger       *zerolog.Logger
+	chainId      int64
+	cfg          *common.EvmAbiConfig
+	httpClient   *http.Client
+	cacheTtl     time.Duration
+	fetchTimeout time.Duration
+
+	static map[ethcommon.Address]*abiContract
+
+	mu      sync.RWMutex
+	fetched map[ethcommon.Address]*abiCacheEntry
+	events  map[ethcommon.Hash]*abi.Event
+	errors  map[[4]byte]*abi.Error
+
+	sf singleflight.Group
+}
+
+type abiContract struct {
+	name string
+	abi  *abi.ABI
+}
+
+type abiCacheEntry struct {
+	// contract is nil when no source has the ABI.
+	contract  *abiContract
+	expiresAt time.Time
+}
+
+func NewAbiRegistry(logger *zerolog.Logger, chainId int64, cfg *common.EvmAbiConfig) (*AbiRegistry, error) {
+	lg := logger.With().Str("component", "abiRegistry").Logger()
+	r := &AbiRegistry{
+		logger:       &lg,
+		chainId:      chainId,
+		cfg:          cfg,
+		httpClient:   &http.Client{},
+		cacheTtl:     cfg.CacheTtl.Duration(),
+		fetchTimeout: cfg.FetchTimeout.Duration(),
+		static:       make(map[ethcommon.Address]*abiContract, len(cfg.Contracts)),
+		fetched:      make(map[ethcommon.Address]*abiCacheEntry),
+		events:       make(map[ethcommon.Hash]*abi.Event),
+		errors:       make(map[[4]byte]*abi.Error),
+	}
+	for _, ct := range cfg.Contracts {
+		raw := []byte(ct.Abi)
+		if ct.AbiFile != "" {
+			b, err := os.ReadFile(ct.AbiFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read abi of %s: %w", ct.Address, err)
+			}
+			raw = b
+		}
+		parsed, err := parseAbiJson(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid abi of %s: %w", ct.Address, err)
+		}
+		c := &abiContract{name: ct.Name, abi: parsed}
+		r.static[ethcommon.HexToAddress(ct.Address)] = c
+		r.index(c)
+	}
+	return r, nil
+}
+
+// DecodedLogsEnabled reports whether the erpc_getDecoded* methods are served.
+func (r *AbiRegistry) DecodedLogsEnabled() bool {
+	return r.cfg.DecodedLogs != nil && *r.cfg.DecodedLogs
+}
+
+// parseAbiJson accepts a JSON ABI, or a compiler artifact or explorer
+// response that carries it in an "abi" field.
+func parseAbiJson(raw []byte) (*abi.ABI, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '{' {
+		var artifact struct {
+			Abi json.RawMessage `json:"abi"`
+		}
+		if err := json.Unmarshal(raw, &artifact); err != nil {
+			return nil, err
+		}
+		if len(artifact.Abi) == 0 {
+			return nil, fmt.Errorf("object has no abi field")
+		}
+		raw = artifact.Abi
+	}
+	parsed, err := abi.JSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// index adds the contract's events and errors to the signature indexes. The
+// first declaration of a signature wins.
+func (r *AbiRegistry) index(c *abiContract) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ev := range c.abi.Events {
+		if ev.Anonymous {
+			continue
+		}
+		if _, ok := r.events[ev.ID]; !ok {
+			ev := ev
+			r.events[ev.ID] = &ev
+		}
+	}
+	for _, e := range c.abi.Errors {
+		var sel [4]byte
+		copy(sel[:], e.ID[:4])
+		if _, ok := r.errors[sel]; !ok {
+			e := e
+			r.errors[sel] = &e
+		}
+	}
+}
+
+// cached returns the ABI of address from config or the fetch cache, without
+// fetching. ok is false when the address is unknown or its entry expired.
+func (r *AbiRegistry) cached(address ethcommon.Address) (c *abiContract, ok bool) {
+	if c, ok := r.static[address]; ok {
+		return c, true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if e, ok := r.fetched[address]; ok && util.Now().Before(e.expiresAt) {
+		return e.contract, true
+	}
+	return nil, false
+}
+
+// lookup returns the ABI of address, fetching it when it is not cached. It
+// returns nil without an error when no source has it.
+func (r *AbiRegistry) lookup(ctx context.Context, address ethcommon.Address) (*abiContract, error) {
+	if c, ok := r.cached(address); ok {
+		return c, nil
+	}
+	if r.cfg.Sourcify == nil && r.cfg.Etherscan == nil {
+		return nil, nil
+	}
+	v, err, _ := r.sf.Do(address.Hex(), func() (interface{}, error) {
+		// Detached so that one caller giving up does not fail the fetch for
+		// the others waiting on it.
+		fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.fetchTimeout)
+		defer cancel()
+		c, err := r.fetch(fctx, address)
+		if err != nil {
+			return nil, err
+		}
+		r.mu.Lock()
+		r.fetched[address] = &abiCacheEntry{contract: c, expiresAt: util.Now().Add(r.cacheTtl)}
+		r.mu.Unlock()
+		if c != nil {
+			r.index(c)
+		}
+		return c, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	c, _ := v.(*abiContract)
+	return c, nil
+}
+
+// prefetch fetches the ABI of address in the background when it is not
+// cached, so that the next lookup on the request path finds it.
+func (r *AbiRegistry) prefetch(ctx context.Context, address ethcommon.Address) {
+	if _, ok := r.cached(address); ok {
+		return
+	}
+	go func() {
+		if _, err := r.lookup(context.WithoutCancel(ctx), address); err != nil {
+			r.logger.Debug().Err(err).Str("address", address.Hex()).Msg("failed to prefetch contract abi")
+		}
+	}()
+}
+
+func (r *AbiRegistry) eventByTopic(topic ethcommon.Hash) *abi.Event {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.events[topic]
+}
+
+func (r *AbiRegistry) errorBySelector(sel [4]byte) *abi.Error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.errors[sel]
+}
+
+func (r *AbiRegistry) fetch(ctx context.Context, address ethcommon.Address) (*abiContract, error) {
+	var errs []error
+	if r.cfg.Sourcify != nil {
+		c, err := r.fetchSourcify(ctx, address)
+		if err == nil && c != nil {
+			return c, nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if r.cfg.Etherscan != nil {
+		c, err := r.fetchEtherscan(ctx, address)
+		if err == nil && c != nil {
+			return c, nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// A miss is only cached when every source answered that it has no ABI,
+	// so that a source outage is retried on the next lookup.
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to fetch abi of %s: %v", address.Hex(), errs)
+	}
+	return nil, nil
+}
+
+func (r *AbiRegistry) fetchSourcify(ctx context.Context, address ethcommon.Address) (*abiContract, error) {
+	u := fmt.Sprintf("%s/v2/contract/%d/%s?fields=abi,compilation", strings.TrimSuffix(r.cfg.Sourcify.Endpoint, "/"), r.chainId, address.Hex())
+	status, body, err := r.get(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("sourcify: %w", err)
+	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("sourcify: unexpected status %d", status)
+	}
+	var resp struct {
+		Abi         json.RawMessage `json:"abi"`
+		Compilation struct {
+			Name string `json:"name"`
+		} `json:"compilation"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("sourcify: %w", err)
+	}
+	if len(resp.Abi) == 0 || string(resp.Abi) == "null" {
+		return nil, nil
+	}
+	parsed, err := parseAbiJson(resp.Abi)
+	if err != nil {
+		return nil, fmt.Errorf("sourcify: invalid abi: %w", err)
+	}
+	return &abiContract{name: resp.Compilation.Name, abi: parsed}, nil
+}
+
+func (r *AbiRegistry) fetchEtherscan(ctx context.Context, address ethcommon.Address) (*abiContract, error) {
+	q := url.Values{}
+	q.Set("chainid", strconv.FormatInt(r.chainId, 10))
+	q.Set("module", "contract")
+	q.Set("action", "getabi")
+	q.Set("address", address.Hex())
+	q.Set("apikey", r.cfg.Etherscan.ApiKey)
+	status, body, err := r.get(ctx, r.cfg.Etherscan.Endpoint+"?"+q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("etherscan: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("etherscan: unexpected status %d", status)
+	}
+	var resp struct {
+		Status string `json:"status"`
+		Result string `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("etherscan: %w", err)
+	}
+	if resp.Status != "1" {
+		// Errors such as rate limits come back as status 0 too; only an
+		// unverified contract is a miss.
+		if strings.Contains(strings.ToLower(resp.Result), "not verified") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("etherscan: %s", resp.Result)
+	}
+	parsed, err := parseAbiJson([]byte(resp.Result))
+	if err != nil {
+		return nil, fmt.Errorf("etherscan: invalid abi: %w", err)
+	}
+	return &abiContract{abi: parsed}, nil
+}
+
+func (r *AbiRegistry) get(ctx context.Context, u string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, abiFetchMaxBytes))
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
@@ -0,0 +1,380 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testTokenAbi = `[
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"error","name":"InsufficientBalance","inputs":[
+		{"name":"available","type":"uint256"},
+		{"name":"required","type":"uint256"}]}
+]`
+
+var (
+	testTokenAddress = ethcommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+	testHolderA      = ethcommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	testHolderB      = ethcommon.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+func newTestAbiRegistry(t *testing.T, cfg *common.EvmAbiConfig) *AbiRegistry {
+	t.Helper()
+	if cfg == nil {
+		cfg = &common.EvmAbiConfig{}
+	}
+	ncfg := &common.EvmNetworkConfig{ChainId: 1, Abi: cfg}
+	require.NoError(t, ncfg.SetDefaults())
+	reg, err := NewAbiRegistry(&log.Logger, 1, cfg)
+	require.NoError(t, err)
+	return reg
+}
+
+func packUint256s(t *testing.T, values ...int64) []byte {
+	t.Helper()
+	typ, err := abi.NewType("uint256", "", nil)
+	require.NoError(t, err)
+	args := make(abi.Arguments, len(values))
+	ints := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = abi.Argument{Type: typ}
+		ints[i] = big.NewInt(v)
+	}
+	b, err := args.Pack(ints...)
+	require.NoError(t, err)
+	return b
+}
+
+func transferLog(address ethcommon.Address, value int64, t *testing.T) map[string]interface{} {
+	return map[string]interface{}{
+		"address": strings.ToLower(address.Hex()),
+		"topics": []interface{}{
+			transferTopic.Hex(),
+			ethcommon.BytesToHash(testHolderA.Bytes()).Hex(),
+			ethcommon.BytesToHash(testHolderB.Bytes()).Hex(),
+		},
+		"data": hexutil.Encode(packUint256s(t, value)),
+	}
+}
+
+func TestAbiRegistry_DecodeRevert(t *testing.T) {
+	reg := newTestAbiRegistry(t, &common.EvmAbiConfig{
+		Contracts: []*common.EvmAbiContractConfig{
+			{Address: testTokenAddress.Hex(), Name: "TOKEN", Abi: testTokenAbi},
+		},
+	})
+	insufficient := append(crypto.Keccak256([]byte("InsufficientBalance(uint256,uint256)"))[:4], packUint256s(t, 1, 5)...)
+
+	t.Run("ErrorString", func(t *testing.T) {
+		strTyp, _ := abi.NewType("string", "", nil)
+		packed, err := abi.Arguments{{Type: strTyp}}.Pack("not owner")
+		require.NoError(t, err)
+		data := append(crypto.Keccak256([]byte("Error(string)"))[:4], packed...)
+		assert.Equal(t, "not owner", reg.decodeRevert(nil, data))
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		data := append(crypto.Keccak256([]byte("Panic(uint256)"))[:4], packUint256s(t, 0x11)...)
+		assert.Equal(t, "panic: arithmetic underflow or overflow", reg.decodeRevert(nil, data))
+	})
+
+	t.Run("CustomErrorOfCalledContract", func(t *testing.T) {
+		assert.Equal(t, "InsufficientBalance(available: 1, required: 5)", reg.decodeRevert(&testTokenAddress, insufficient))
+	})
+
+	t.Run("CustomErrorOfAnotherContract", func(t *testing.T) {
+		// e.g. a router bubbling up the revert of the token it called.
+		router := ethcommon.HexToAddress("0x00000000000000000000000000000000000000bb")
+		assert.Equal(t, "InsufficientBalance(available: 1, required: 5)", reg.decodeRevert(&router, insufficient))
+	})
+
+	t.Run("UnknownSelector", func(t *testing.T) {
+		assert.Empty(t, reg.decodeRevert(&testTokenAddress, []byte{0xde, 0xad, 0xbe, 0xef}))
+	})
+}
+
+func TestAbiRegistry_DecodeLogs(t *testing.T) {
+	reg := newTestAbiRegistry(t, &common.EvmAbiConfig{
+		Contracts: []*common.EvmAbiContractConfig{
+			{Address: testTokenAddress.Hex(), Name: "TOKEN", Abi: testTokenAbi},
+		},
+	})
+	other := ethcommon.HexToAddress("0x00000000000000000000000000000000000000cc")
+	nft := map[string]interface{}{
+		// ERC-721 Transfer: same signature, tokenId indexed, no data.
+		"address": other.Hex(),
+		"topics": []interface{}{
+			transferTopic.Hex(),
+			ethcommon.BytesToHash(testHolderA.Bytes()).Hex(),
+			ethcommon.BytesToHash(testHolderB.Bytes()).Hex(),
+			ethcommon.BigToHash(big.NewInt(7)).Hex(),
+		},
+		"data": "0x",
+	}
+	logs := []map[string]interface{}{
+		transferLog(testTokenAddress, 1000, t),
+		transferLog(other, 42, t),
+		nft,
+		{"address": testTokenAddress.Hex(), "topics": []interface{}{}, "data": "0x"},
+	}
+
+	assert.Equal(t, 2, reg.decodeLogs(context.Background(), logs))
+
+	ev := logs[0]["decoded"].(*DecodedEvent)
+	assert.Equal(t, "TOKEN", ev.Contract)
+	assert.Equal(t, "Transfer", ev.Name)
+	assert.Equal(t, "Transfer(address,address,uint256)", ev.Signature)
+	assert.Equal(t, map[string]interface{}{
+		"from":  testHolderA.Hex(),
+		"to":    testHolderB.Hex(),
+		"value": "1000",
+	}, ev.Args)
+
+	// Decoded through the signature index, without a contract name.
+	ev = logs[1]["decoded"].(*DecodedEvent)
+	assert.Empty(t, ev.Contract)
+	assert.Equal(t, "42", ev.Args["value"])
+
+	assert.NotContains(t, logs[2], "decoded")
+	assert.NotContains(t, logs[3], "decoded")
+}
+
+func TestAbiRegistry_Fetch(t *testing.T) {
+	verified := ethcommon.HexToAddress("0x00000000000000000000000000000000000000d1")
+	etherscanOnly := ethcommon.HexToAddress("0x00000000000000000000000000000000000000d2")
+	unverified := ethcommon.HexToAddress("0x00000000000000000000000000000000000000d3")
+
+	var sourcifyHits, etherscanHits atomic.Int32
+	var etherscanDown atomic.Bool
+	sourcify := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sourcifyHits.Add(1)
+		if r.URL.Path != "/v2/contract/1/"+verified.Hex() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"abi":%s,"compilation":{"name":"Token"}}`, testTokenAbi)
+	}))
+	defer sourcify.Close()
+	etherscan := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etherscanHits.Add(1)
+		q := r.URL.Query()
+		assert.Equal(t, "1", q.Get("chainid"))
+		assert.Equal(t, "secret", q.Get("apikey"))
+		switch {
+		case etherscanDown.Load():
+			w.Write([]byte(`{"status":"0","message":"NOTOK","result":"Max rate limit reached"}`))
+		case q.Get("address") == etherscanOnly.Hex():
+			raw, _ := json.Marshal(testTokenAbi)
+			fmt.Fprintf(w, `{"status":"1","message":"OK","result":%s}`, raw)
+		default:
+			w.Write([]byte(`{"status":"0","message":"NOTOK","result":"Contract source code not verified"}`))
+		}
+	}))
+	defer etherscan.Close()
+
+	reg := newTestAbiRegistry(t, &common.EvmAbiConfig{
+		Sourcify:  &common.EvmAbiSourcifyConfig{Endpoint: sourcify.URL},
+		Etherscan: &common.EvmAbiEtherscanConfig{Endpoint: etherscan.URL, ApiKey: "secret"},
+		CacheTtl:  common.Duration(time.Minute),
+	})
+	ctx := context.Background()
+
+	t.Run("FromSourcify", func(t *testing.T) {
+		c, err := reg.lookup(ctx, verified)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.Equal(t, "Token", c.name)
+		assert.Equal(t, int32(0), etherscanHits.Load())
+	})
+
+	t.Run("FallsBackToEtherscan", func(t *testing.T) {
+		c, err := reg.lookup(ctx, etherscanOnly)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.Contains(t, c.abi.Events, "Transfer")
+	})
+
+	t.Run("CachesHitsAndMisses", func(t *testing.T) {
+		c, err := reg.lookup(ctx, unverified)
+		require.NoError(t, err)
+		assert.Nil(t, c)
+		before := sourcifyHits.Load() + etherscanHits.Load()
+		for _, a := range []ethcommon.Address{verified, etherscanOnly, unverified} {
+			_, err := reg.lookup(ctx, a)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, before, sourcifyHits.Load()+etherscanHits.Load())
+	})
+
+	t.Run("DoesNotCacheSourceErrors", func(t *testing.T) {
+		etherscanDown.Store(true)
+		flaky := ethcommon.HexToAddress("0x00000000000000000000000000000000000000d4")
+		_, err := reg.lookup(ctx, flaky)
+		require.Error(t, err)
+		_, ok := reg.cached(flaky)
+		assert.False(t, ok)
+
+		etherscanDown.Store(false)
+		c, err := reg.lookup(ctx, flaky)
+		require.NoError(t, err)
+		assert.Nil(t, c)
+	})
+}
+
+type abiTestNetwork struct {
+	mockNetwork
+	reg *AbiRegistry
+}
+
+func (n *abiTestNetwork) EvmAbiRegistry() *AbiRegistry {
+	return n.reg
+}
+
+func TestNetworkPostForward_DecodeRevert(t *testing.T) {
+	reg := newTestAbiRegistry(t, &common.EvmAbiConfig{
+		Contracts: []*common.EvmAbiContractConfig{
+			{Address: testTokenAddress.Hex(), Abi: testTokenAbi},
+		},
+	})
+	n := &abiTestNetwork{reg: reg}
+	data := hexutil.Encode(append(crypto.Keccak256([]byte("InsufficientBalance(uint256,uint256)"))[:4], packUint256s(t, 1, 5)...))
+	rq := common.NewNormalizedRequest([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":%q,"data":"0x"},"latest"]}`, testTokenAddress.Hex())))
+	revert := func(message string) error {
+		return common.NewErrEndpointExecutionException(
+			common.NewErrJsonRpcExceptionInternal(3, common.JsonRpcErrorEvmReverted, message, nil, map[string]interface{}{
+				"data": data,
+			}),
+		)
+	}
+
+	_, err := HandleNetworkPostForward(context.Background(), n, rq, nil, revert("execution reverted"))
+	jre := &common.ErrJsonRpcExceptionInternal{}
+	require.ErrorAs(t, err, &jre)
+	assert.Equal(t, "execution reverted: InsufficientBalance(available: 1, required: 5)", jre.Message)
+	assert.Equal(t, common.JsonRpcErrorEvmReverted, jre.NormalizedCode())
+	assert.Equal(t, data, jre.Details["data"])
+
+	t.Run("LeavesErrorAloneWhenDisabled", func(t *testing.T) {
+		reg.cfg.DecodeReverts = util.BoolPtr(false)
+		defer func() { reg.cfg.DecodeReverts = util.BoolPtr(true) }()
+		orig := revert("execution reverted")
+		_, err := HandleNetworkPostForward(context.Background(), n, rq, nil, orig)
+		assert.Same(t, orig, err)
+	})
+}
+
+func TestProjectPreForward_GetDecodedLogs(t *testing.T) {
+	reg := newTestAbiRegistry(t, &common.EvmAbiConfig{
+		Contracts: []*common.EvmAbiContractConfig{
+			{Address: testTokenAddress.Hex(), Name: "TOKEN", Abi: testTokenAbi},
+		},
+		DecodedLogs: util.BoolPtr(true),
+	})
+	n := &abiTestNetwork{reg: reg}
+	n.On("Forward", mock.Anything, mock.Anything).Return(func(ctx context.Context, r *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+		method, _ := r.Method()
+		require.Equal(t, "eth_getLogs", method)
+		logs, _ := json.Marshal([]map[string]interface{}{transferLog(testTokenAddress, 1000, t)})
+		jrr, err := common.NewJsonRpcResponse(r.ID(), json.RawMessage(logs), nil)
+		require.NoError(t, err)
+		return common.NewNormalizedResponse().WithRequest(r).WithJsonRpcResponse(jrr), nil
+	}, nil)
+
+	rq := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":7,"method":"erpc_getDecodedLogs","params":[{"fromBlock":"0x1","toBlock":"0x1"}]}`))
+	handled, resp, err := HandleProjectPreForward(context.Background(), n, rq)
+	require.NoError(t, err)
+	require.True(t, handled)
+
+	jrr, err := resp.JsonRpcResponse()
+	require.NoError(t, err)
+	var logs []map[string]interface{}
+	require.NoError(t, json.Unmarshal(jrr.GetResultBytes(), &logs))
+	require.Len(t, logs, 1)
+	assert.Equal(t, map[string]interface{}{
+		"contract":  "TOKEN",
+		"name":      "Transfer",
+		"signature": "Transfer(address,address,uint256)",
+		"args": map[string]interface{}{
+			"from":  testHolderA.Hex(),
+			"to":    testHolderB.Hex(),
+			"value": "1000",
+		},
+	}, logs[0]["decoded"])
+	assert.Equal(t, transferTopic.Hex(), logs[0]["topics"].([]interface{})[0])
+}
+
+func TestProjectPreForward_GetDecodedFilterChanges(t *testing.T) {
+	reg := newTestAbiRegistry(t, &common.EvmAbiConfig{
+		Contracts: []*common.EvmAbiContractConfig{
+			{Address: testTokenAddress.Hex(), Name: "TOKEN", Abi: testTokenAbi},
+		},
+		DecodedLogs: util.BoolPtr(true),
+	})
+	n := &abiTestNetwork{reg: reg}
+	blockHash := "0x" + strings.Repeat("ab", 32)
+	n.On("Forward", mock.Anything, mock.Anything).Return(func(ctx context.Context, r *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+		method, _ := r.Method()
+		require.Equal(t, "eth_getFilterChanges", method)
+		jrq, _ := r.JsonRpcRequest()
+		var result interface{}
+		if jrq.Params[0] == "0x1" {
+			result = []map[string]interface{}{transferLog(testTokenAddress, 1000, t)}
+		} else {
+			// A block filter returns hashes.
+			result = []string{blockHash}
+		}
+		raw, _ := json.Marshal(result)
+		jrr, err := common.NewJsonRpcResponse(r.ID(), json.RawMessage(raw), nil)
+		require.NoError(t, err)
+		return common.NewNormalizedResponse().WithRequest(r).WithJsonRpcResponse(jrr), nil
+	}, nil)
+
+	t.Run("DecodesLogFilterChanges", func(t *testing.T) {
+		rq := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":8,"method":"erpc_getDecodedFilterChanges","params":["0x1"]}`))
+		handled, resp, err := HandleProjectPreForward(context.Background(), n, rq)
+		require.NoError(t, err)
+		require.True(t, handled)
+
+		jrr, err := resp.JsonRpcResponse()
+		require.NoError(t, err)
+		var logs []map[string]interface{}
+		require.NoError(t, json.Unmarshal(jrr.GetResultBytes(), &logs))
+		require.Len(t, logs, 1)
+		assert.Equal(t, "Transfer", logs[0]["decoded"].(map[string]interface{})["name"])
+	})
+
+	t.Run("ReturnsHashesOfOtherFiltersAsTheyAre", func(t *testing.T) {
+		rq := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":9,"method":"erpc_getDecodedFilterChanges","params":["0x2"]}`))
+		handled, resp, err := HandleProjectPreForward(context.Background(), n, rq)
+		require.NoError(t, err)
+		require.True(t, handled)
+
+		jrr, err := resp.JsonRpcResponse()
+		require.NoError(t, err)
+		var hashes []string
+		require.NoError(t, json.Unmarshal(jrr.GetResultBytes(), &hashes))
+		assert.Equal(t, []string{blockHash}, hashes)
+	})
+}
@@ -0,0 +1,113 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/erpc/erpc/common"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// networkPostForward_decodeRevert appends the decoded revert reason to the
+// message of an eth_call or eth_estimateGas revert, e.g. "execution reverted:
+// InsufficientBalance(available: 1, required: 5)", when evm.abi.decodeReverts
+// is enabled. The "data" field is left untouched for clients that decode it
+// themselves.
+//
+// Only cached ABIs are used so that a revert is never held up by a fetch;
+// the called contract's ABI is fetched in the background for later reverts.
+func networkPostForward_decodeRevert(ctx context.Context, n common.Network, rq *common.NormalizedRequest, rs *common.NormalizedResponse, re error) (*common.NormalizedResponse, error) {
+	if re == nil {
+		return rs, re
+	}
+	reg := abiRegistryOf(n)
+	if reg == nil || reg.cfg.DecodeReverts == nil || !*reg.cfg.DecodeReverts {
+		return rs, re
+	}
+	exe := &common.ErrEndpointExecutionException{}
+	if !errors.As(re, &exe) {
+		return rs, re
+	}
+	jre := &common.ErrJsonRpcExceptionInternal{}
+	if !errors.As(exe, &jre) {
+		return rs, re
+	}
+	data := revertData(jre.Details["data"])
+	if len(data) < 4 {
+		return rs, re
+	}
+
+	to := callTarget(rq)
+	if to != nil {
+		reg.prefetch(ctx, *to)
+	}
+	reason := reg.decodeRevert(to, data)
+	if reason == "" || strings.Contains(jre.Message, reason) {
+		return rs, re
+	}
+	msg := reason
+	if jre.Message != "" {
+		msg = fmt.Sprintf("%s: %s", jre.Message, reason)
+	}
+	// Errors can be shared with other requests (multiplexing, cache), so
+	// a new one is returned instead of changing the message in place.
+	return rs, common.NewErrEndpointExecutionException(
+		common.NewErrJsonRpcExceptionInternal(
+			jre.OriginalCode(),
+			jre.NormalizedCode(),
+			msg,
+			jre.Cause,
+			maps.Clone(jre.Details),
+		),
+	)
+}
+
+// revertData extracts the revert bytes from an error's "data" detail, which
+// upstreams send as a hex string, as a JSON string, or nested in an object.
+func revertData(v interface{}) []byte {
+	switch t := v.(type) {
+	case string:
+		b, err := hexutil.Decode(t)
+		if err != nil {
+			return nil
+		}
+		return b
+	case json.RawMessage:
+		var s interface{}
+		if err := json.Unmarshal(t, &s); err != nil {
+			return nil
+		}
+		return revertData(s)
+	case map[string]interface{}:
+		return revertData(t["data"])
+	}
+	return nil
+}
+
+// callTarget returns the "to" of an eth_call or eth_estimateGas request.
+func callTarget(rq *common.NormalizedRequest) *ethcommon.Address {
+	jrq, err := rq.JsonRpcRequest()
+	if err != nil {
+		return nil
+	}
+	jrq.RLock()
+	defer jrq.RUnlock()
+	if len(jrq.Params) == 0 {
+		return nil
+	}
+	call, ok := jrq.Params[0].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	to, ok := call["to"].(string)
+	if !ok || !ethcommon.IsHexAddress(to) {
+		return nil
+	}
+	addr := ethcommon.HexToAddress(to)
+	return &addr
+}
@@ -0,0 +1,436 @@
+package evm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog"
+)
+
+// addressActivityMaxBackfill bounds how many blocks one head advance scans.
+// A bigger jump is a cold start or a catch-up; only its newest blocks are
+// scanned and the others are counted as skipped.
+const addressActivityMaxBackfill = 16
+
+// addressActivityScanTimeout bounds the requests of one block scan.
+const addressActivityScanTimeout = 30 * time.Second
+
+var (
+	transferTopic       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	transferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	transferBatchTopic  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+)
+
+// AddressActivityProvider is implemented by networks with evm.addressActivity.
+type AddressActivityProvider interface {
+	EvmAddressActivity() *AddressActivityTracker
+}
+
+// AddressActivityEvent is one appearance of a watched address in a block.
+type AddressActivityEvent struct {
+	Address string `json:"address"`
+	// Kind is "transaction" or "tokenTransfer".
+	Kind string `json:"kind"`
+	// Direction is "from" or "to".
+	Direction       string `json:"direction"`
+	BlockNumber     string `json:"blockNumber"`
+	BlockHash       string `json:"blockHash"`
+	TransactionHash string `json:"transactionHash"`
+	// Token and LogIndex are set for token transfers.
+	Token    string `json:"token,omitempty"`
+	LogIndex string `json:"logIndex,omitempty"`
+}
+
+type addressActivityWatcher struct {
+	id        string
+	addresses []ethcommon.Address
+	// fromBlock is the head when the watcher was installed; only later
+	// blocks are reported.
+	fromBlock int64
+	events    []*AddressActivityEvent
+	dropped   int
+	polledAt  time.Time
+}
+
+// AddressActivityTracker serves erpc_addressActivity watchers of one network.
+// It follows the head of the network's upstream state pollers and scans each
+// new block once, however many watchers there are: the block's transactions
+// and token transfer logs are fetched through the network (so they are cached
+// and coalesced like client requests) and matched against an index of all
+// watched addresses.
+type AddressActivityTracker struct {
+	logger  *zerolog.Logger
+	network common.Network
+	cfg     *common.EvmAddressActivityConfig
+
+	head atomic.Int64
+	wake chan struct{}
+
+	mu       sync.Mutex
+	watchers map[string]*addressActivityWatcher
+	index    map[ethcommon.Address]map[string]*addressActivityWatcher
+
+	attachMu sync.Mutex
+	attached map[string]bool
+}
+
+func NewAddressActivityTracker(ctx context.Context, logger *zerolog.Logger, network common.Network, cfg *common.EvmAddressActivityConfig) *AddressActivityTracker {
+	lg := logger.With().Str("component", "addressActivity").Logger()
+	t := &AddressActivityTracker{
+		logger:   &lg,
+		network:  network,
+		cfg:      cfg,
+		wake:     make(chan struct{}, 1),
+		watchers: make(map[string]*addressActivityWatcher),
+		index:    make(map[ethcommon.Address]map[string]*addressActivityWatcher),
+		attached: make(map[string]bool),
+	}
+	go t.run(ctx)
+	return t
+}
+
+// Attach registers the tracker with the state poller of every upstream not
+// registered yet. OnLatestBlock registrations are permanent, so each upstream
+// is only registered once however often this is called.
+func (t *AddressActivityTracker) Attach(upstreams []common.EvmUpstream) {
+	t.attachMu.Lock()
+	defer t.attachMu.Unlock()
+	for _, up := range upstreams {
+		if t.attached[up.Id()] {
+			continue
+		}
+		sp := up.EvmStatePoller()
+		if sp == nil || sp.IsObjectNull() {
+			continue
+		}
+		if v := sp.LatestBlock(); v > 0 {
+			t.onHead(v)
+		}
+		if reg, ok := sp.(interface{ OnLatestBlock(func(int64)) }); ok {
+			reg.OnLatestBlock(t.onHead)
+			t.attached[up.Id()] = true
+		}
+	}
+}
+
+// onHead runs inside the poller's latest-block update path, so it only
+// records the head and wakes the scanner without blocking.
+func (t *AddressActivityTracker) onHead(v int64) {
+	for {
+		cur := t.head.Load()
+		if v <= cur {
+			return
+		}
+		if t.head.CompareAndSwap(cur, v) {
+			break
+		}
+	}
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Install adds a watcher of addresses and returns its id.
+func (t *AddressActivityTracker) Install(addresses []ethcommon.Address) (string, error) {
+	if len(addresses) == 0 {
+		return "", fmt.Errorf("at least one address is required")
+	}
+	if len(addresses) > t.cfg.MaxAddresses {
+		return "", fmt.Errorf("at most %d addresses can be watched at once", t.cfg.MaxAddresses)
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	w := &addressActivityWatcher{
+		id:        hexutil.Encode(b[:]),
+		addresses: addresses,
+		fromBlock: t.head.Load(),
+		polledAt:  util.Now(),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expireLocked()
+	if len(t.watchers) >= t.cfg.MaxWatchers {
+		return "", fmt.Errorf("too many address activity watchers (max %d)", t.cfg.MaxWatchers)
+	}
+	t.watchers[w.id] = w
+	for _, a := range addresses {
+		ws, ok := t.index[a]
+		if !ok {
+			ws = make(map[string]*addressActivityWatcher)
+			t.index[a] = ws
+		}
+		ws[w.id] = w
+	}
+	return w.id, nil
+}
+
+// Poll returns the events of a watcher since its last poll and how many were
+// dropped because the buffer was full. ok is false for an unknown id.
+func (t *AddressActivityTracker) Poll(id string) (events []*AddressActivityEvent, dropped int, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w, ok := t.watchers[id]
+	if !ok {
+		return nil, 0, false
+	}
+	events, dropped = w.events, w.dropped
+	w.events, w.dropped = nil, 0
+	w.polledAt = util.Now()
+	return events, dropped, true
+}
+
+// Uninstall removes a watcher and reports whether it existed.
+func (t *AddressActivityTracker) Uninstall(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w, ok := t.watchers[id]
+	if ok {
+		t.removeLocked(w)
+	}
+	return ok
+}
+
+func (t *AddressActivityTracker) removeLocked(w *addressActivityWatcher) {
+	delete(t.watchers, w.id)
+	for _, a := range w.addresses {
+		if ws, ok := t.index[a]; ok {
+			delete(ws, w.id)
+			if len(ws) == 0 {
+				delete(t.index, a)
+			}
+		}
+	}
+}
+
+// expireLocked removes watchers not polled within watcherTtl.
+func (t *AddressActivityTracker) expireLocked() {
+	ttl := t.cfg.WatcherTtl.Duration()
+	if ttl <= 0 {
+		return
+	}
+	cutoff := util.Now().Add(-ttl)
+	for _, w := range t.watchers {
+		if w.polledAt.Before(cutoff) {
+			t.removeLocked(w)
+		}
+	}
+}
+
+func (t *AddressActivityTracker) run(ctx context.Context) {
+	// scanned is the highest block scanned, or skipped while nobody watched.
+	var scanned int64
+	ticker := util.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			t.mu.Lock()
+			t.expireLocked()
+			t.mu.Unlock()
+		case <-t.wake:
+			scanned = t.scan(ctx, scanned)
+		}
+	}
+}
+
+// scan scans the blocks after scanned up to the head and returns the new
+// highest scanned block. A block that cannot be fetched yet stops the scan;
+// it is retried on the next head advance.
+func (t *AddressActivityTracker) scan(ctx context.Context, scanned int64) int64 {
+	head := t.head.Load()
+	t.mu.Lock()
+	t.expireLocked()
+	idle := len(t.watchers) == 0
+	t.mu.Unlock()
+	if idle || scanned == 0 {
+		return head
+	}
+
+	from := scanned + 1
+	if head-from >= addressActivityMaxBackfill {
+		skipped := head - addressActivityMaxBackfill + 1 - from
+		t.count("skipped", float64(skipped))
+		from = head - addressActivityMaxBackfill + 1
+	}
+	for bn := from; bn <= head; bn++ {
+		events, err := t.scanBlock(ctx, bn)
+		if err != nil {
+			t.count("error", 1)
+			t.logger.Debug().Err(err).Int64("blockNumber", bn).Msg("could not scan block for address activity")
+			return bn - 1
+		}
+		t.count("scanned", 1)
+		t.deliver(bn, events)
+	}
+	return head
+}
+
+// scanBlock returns the events of every watched address in block bn.
+// Nothing is delivered unless the whole block could be fetched, so a retried
+// block never yields duplicates.
+func (t *AddressActivityTracker) scanBlock(ctx context.Context, bn int64) ([]*AddressActivityEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, addressActivityScanTimeout)
+	defer cancel()
+	hexBn := hexutil.EncodeUint64(uint64(bn))
+
+	var block *struct {
+		Hash         string `json:"hash"`
+		Transactions []struct {
+			Hash string `json:"hash"`
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"transactions"`
+	}
+	if err := t.forward(ctx, "eth_getBlockByNumber", []interface{}{hexBn, true}, &block); err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d is not available yet", bn)
+	}
+
+	var logs []struct {
+		Address         string   `json:"address"`
+		Topics          []string `json:"topics"`
+		TransactionHash string   `json:"transactionHash"`
+		LogIndex        string   `json:"logIndex"`
+		Removed         bool     `json:"removed"`
+	}
+	if t.cfg.TokenTransfers != nil && *t.cfg.TokenTransfers {
+		filter := map[string]interface{}{
+			"fromBlock": hexBn,
+			"toBlock":   hexBn,
+			"topics":    []interface{}{[]string{transferTopic.Hex(), transferSingleTopic.Hex(), transferBatchTopic.Hex()}},
+		}
+		if err := t.forward(ctx, "eth_getLogs", []interface{}{filter}, &logs); err != nil {
+			return nil, err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var events []*AddressActivityEvent
+	add := func(raw string, ev AddressActivityEvent) {
+		if !ethcommon.IsHexAddress(raw) {
+			return
+		}
+		a := ethcommon.HexToAddress(raw)
+		if _, ok := t.index[a]; !ok {
+			return
+		}
+		ev.Address = a.Hex()
+		ev.BlockNumber = hexBn
+		ev.BlockHash = block.Hash
+		events = append(events, &ev)
+	}
+	for _, tx := range block.Transactions {
+		add(tx.From, AddressActivityEvent{Kind: "transaction", Direction: "from", TransactionHash: tx.Hash})
+		add(tx.To, AddressActivityEvent{Kind: "transaction", Direction: "to", TransactionHash: tx.Hash})
+	}
+	for _, lg := range logs {
+		if lg.Removed || len(lg.Topics) == 0 {
+			continue
+		}
+		// Transfer indexes (from, to); the ERC-1155 events index
+		// (operator, from, to). ERC-20 and ERC-721 Transfer share a topic
+		// and both index from and to.
+		fromIdx := 1
+		if !strings.EqualFold(lg.Topics[0], transferTopic.Hex()) {
+			fromIdx = 2
+		}
+		if len(lg.Topics) <= fromIdx+1 {
+			continue
+		}
+		ev := AddressActivityEvent{Kind: "tokenTransfer", TransactionHash: lg.TransactionHash, Token: lg.Address, LogIndex: lg.LogIndex}
+		from, to := ev, ev
+		from.Direction, to.Direction = "from", "to"
+		add(topicAddress(lg.Topics[fromIdx]), from)
+		add(topicAddress(lg.Topics[fromIdx+1]), to)
+	}
+	return events, nil
+}
+
+// topicAddress returns the address held in a 32-byte topic, or "" when the
+// topic is not a left-padded address.
+func topicAddress(topic string) string {
+	b, err := hexutil.Decode(topic)
+	if err != nil || len(b) != 32 {
+		return ""
+	}
+	for _, c := range b[:12] {
+		if c != 0 {
+			return ""
+		}
+	}
+	return hexutil.Encode(b[12:])
+}
+
+// deliver appends the events of block bn to the watchers of their addresses.
+func (t *AddressActivityTracker) deliver(bn int64, events []*AddressActivityEvent) {
+	if len(events) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, ev := range events {
+		for _, w := range t.index[ethcommon.HexToAddress(ev.Address)] {
+			if bn <= w.fromBlock {
+				continue
+			}
+			if len(w.events) >= t.cfg.MaxEvents {
+				w.events = w.events[1:]
+				w.dropped++
+			}
+			w.events = append(w.events, ev)
+		}
+	}
+}
+
+func (t *AddressActivityTracker) forward(ctx context.Context, method string, params []interface{}, out interface{}) error {
+	jrq := common.NewJsonRpcRequest(method, params)
+	if err := jrq.SetID(util.RandomID()); err != nil {
+		return err
+	}
+	nq := common.NewNormalizedRequestFromJsonRpcRequest(jrq)
+	nq.SetNetwork(t.network)
+	rs, err := t.network.Forward(ctx, nq)
+	if err != nil {
+		return err
+	}
+	if rs == nil {
+		return fmt.Errorf("unexpected empty %s response", method)
+	}
+	defer rs.Release()
+	jrr, err := rs.JsonRpcResponse(ctx)
+	if err != nil {
+		return err
+	}
+	if jrr == nil {
+		return fmt.Errorf("unexpected empty %s response", method)
+	}
+	if jrr.Error != nil {
+		return jrr.Error
+	}
+	return json.Unmarshal(jrr.GetResultBytes(), out)
+}
+
+func (t *AddressActivityTracker) count(outcome string, n float64) {
+	telemetry.MetricNetworkEvmAddressActivityBlocksTotal.WithLabelValues(t.network.ProjectId(), t.network.Label(), outcome).Add(n)
+}
@@ -0,0 +1,202 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type addressActivityTestNetwork struct {
+	mockNetwork
+	tracker *AddressActivityTracker
+}
+
+func (n *addressActivityTestNetwork) EvmAddressActivity() *AddressActivityTracker {
+	return n.tracker
+}
+
+// newTestAddressActivity returns a tracker whose background loop is stopped,
+// so tests drive scans themselves. Block n has one transaction from
+// testHolderA and one token transfer from testHolderB to testHolderA.
+func newTestAddressActivity(t *testing.T, cfg *common.EvmAddressActivityConfig) (*addressActivityTestNetwork, *int) {
+	t.Helper()
+	ncfg := &common.EvmNetworkConfig{ChainId: 1, AddressActivity: cfg}
+	require.NoError(t, ncfg.SetDefaults())
+
+	n := &addressActivityTestNetwork{}
+	n.On("ProjectId").Return("test")
+	fetched := 0
+	n.On("Forward", mock.Anything, mock.Anything).Return(func(ctx context.Context, r *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+		method, _ := r.Method()
+		jrq, _ := r.JsonRpcRequest()
+		var result interface{}
+		switch method {
+		case "eth_getBlockByNumber":
+			fetched++
+			bn := jrq.Params[0].(string)
+			result = map[string]interface{}{
+				"hash": ethcommon.BytesToHash([]byte(bn)).Hex(),
+				"transactions": []interface{}{
+					map[string]interface{}{"hash": "0x01", "from": testHolderA.Hex(), "to": testTokenAddress.Hex()},
+					map[string]interface{}{"hash": "0x02", "from": testTokenAddress.Hex(), "to": nil},
+				},
+			}
+		case "eth_getLogs":
+			lg := transferLog(testTokenAddress, 5, t)
+			lg["topics"] = []interface{}{
+				transferTopic.Hex(),
+				ethcommon.BytesToHash(testHolderB.Bytes()).Hex(),
+				ethcommon.BytesToHash(testHolderA.Bytes()).Hex(),
+			}
+			lg["transactionHash"] = "0x03"
+			lg["logIndex"] = "0x0"
+			result = []interface{}{lg}
+		default:
+			return nil, fmt.Errorf("unexpected method %s", method)
+		}
+		raw, _ := json.Marshal(result)
+		jrr, err := common.NewJsonRpcResponse(r.ID(), json.RawMessage(raw), nil)
+		require.NoError(t, err)
+		return common.NewNormalizedResponse().WithRequest(r).WithJsonRpcResponse(jrr), nil
+	}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n.tracker = NewAddressActivityTracker(ctx, &log.Logger, n, cfg)
+	return n, &fetched
+}
+
+func TestAddressActivityTracker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ReportsTransactionsAndTokenTransfersOfNewBlocks", func(t *testing.T) {
+		n, fetched := newTestAddressActivity(t, &common.EvmAddressActivityConfig{})
+		tr := n.tracker
+		tr.onHead(100)
+		scanned := tr.scan(ctx, 0)
+		require.Equal(t, int64(100), scanned)
+
+		id, err := tr.Install([]ethcommon.Address{testHolderA})
+		require.NoError(t, err)
+		id2, err := tr.Install([]ethcommon.Address{testHolderB, testHolderA})
+		require.NoError(t, err)
+
+		tr.onHead(101)
+		scanned = tr.scan(ctx, scanned)
+		require.Equal(t, int64(101), scanned)
+		assert.Equal(t, 1, *fetched, "the block is fetched once for all watchers")
+
+		events, dropped, ok := tr.Poll(id)
+		require.True(t, ok)
+		assert.Zero(t, dropped)
+		require.Len(t, events, 2)
+		assert.Equal(t, AddressActivityEvent{
+			Address: testHolderA.Hex(), Kind: "transaction", Direction: "from",
+			BlockNumber: "0x65", BlockHash: events[0].BlockHash, TransactionHash: "0x01",
+		}, *events[0])
+		assert.Equal(t, "tokenTransfer", events[1].Kind)
+		assert.Equal(t, "to", events[1].Direction)
+		assert.Equal(t, "0x03", events[1].TransactionHash)
+
+		events, _, _ = tr.Poll(id)
+		assert.Empty(t, events, "events are returned once")
+
+		events, _, _ = tr.Poll(id2)
+		assert.Len(t, events, 3, "the transfer is reported for its sender too")
+	})
+
+	t.Run("SkipsBlocksBeyondTheBackfill", func(t *testing.T) {
+		n, fetched := newTestAddressActivity(t, &common.EvmAddressActivityConfig{TokenTransfers: util.BoolPtr(false)})
+		tr := n.tracker
+		tr.onHead(100)
+		scanned := tr.scan(ctx, 0)
+		_, err := tr.Install([]ethcommon.Address{testHolderA})
+		require.NoError(t, err)
+
+		tr.onHead(200)
+		require.Equal(t, int64(200), tr.scan(ctx, scanned))
+		assert.Equal(t, addressActivityMaxBackfill, *fetched)
+	})
+
+	t.Run("DropsOldestEventsBeyondMaxEvents", func(t *testing.T) {
+		n, _ := newTestAddressActivity(t, &common.EvmAddressActivityConfig{MaxEvents: 1})
+		tr := n.tracker
+		tr.onHead(100)
+		scanned := tr.scan(ctx, 0)
+		id, err := tr.Install([]ethcommon.Address{testHolderA})
+		require.NoError(t, err)
+
+		tr.onHead(101)
+		tr.scan(ctx, scanned)
+		events, dropped, _ := tr.Poll(id)
+		require.Len(t, events, 1)
+		assert.Equal(t, 1, dropped)
+		assert.Equal(t, "tokenTransfer", events[0].Kind)
+	})
+
+	t.Run("EnforcesLimits", func(t *testing.T) {
+		n, _ := newTestAddressActivity(t, &common.EvmAddressActivityConfig{MaxWatchers: 1, MaxAddresses: 1})
+		tr := n.tracker
+		_, err := tr.Install([]ethcommon.Address{testHolderA, testHolderB})
+		assert.ErrorContains(t, err, "at most 1 addresses")
+		id, err := tr.Install([]ethcommon.Address{testHolderA})
+		require.NoError(t, err)
+		_, err = tr.Install([]ethcommon.Address{testHolderB})
+		assert.ErrorContains(t, err, "too many")
+
+		assert.True(t, tr.Uninstall(id))
+		assert.False(t, tr.Uninstall(id))
+		assert.Empty(t, tr.index)
+	})
+}
+
+func TestProjectPreForward_AddressActivity(t *testing.T) {
+	n, _ := newTestAddressActivity(t, &common.EvmAddressActivityConfig{})
+	ctx := context.Background()
+	call := func(body string) (interface{}, error) {
+		handled, resp, err := HandleProjectPreForward(ctx, n, common.NewNormalizedRequest([]byte(body)))
+		require.True(t, handled)
+		if err != nil {
+			return nil, err
+		}
+		jrr, err := resp.JsonRpcResponse()
+		require.NoError(t, err)
+		var out interface{}
+		require.NoError(t, json.Unmarshal(jrr.GetResultBytes(), &out))
+		return out, nil
+	}
+
+	n.tracker.onHead(100)
+	n.tracker.scan(ctx, 0)
+	res, err := call(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"erpc_addressActivity","params":[{"addresses":[%q]}]}`, testHolderA.Hex()))
+	require.NoError(t, err)
+	id := res.(string)
+	_, err = hexutil.Decode(id)
+	require.NoError(t, err)
+
+	n.tracker.onHead(101)
+	n.tracker.scan(ctx, 100)
+	res, err = call(fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"erpc_getAddressActivity","params":[%q]}`, id))
+	require.NoError(t, err)
+	assert.Len(t, res.(map[string]interface{})["events"], 2)
+
+	res, err = call(fmt.Sprintf(`{"jsonrpc":"2.0","id":3,"method":"erpc_uninstallAddressActivity","params":[%q]}`, id))
+	require.NoError(t, err)
+	assert.Equal(t, true, res)
+
+	_, err = call(fmt.Sprintf(`{"jsonrpc":"2.0","id":4,"method":"erpc_getAddressActivity","params":[%q]}`, id))
+	assert.ErrorContains(t, err, "not found")
+
+	_, err = call(`{"jsonrpc":"2.0","id":5,"method":"erpc_addressActivity","params":[{"addresses":["0x12"]}]}`)
+	assert.ErrorContains(t, err, "invalid address")
+}
@@ -0,0 +1,109 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erpc/erpc/common"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+func addressActivityOf(n common.Network) *AddressActivityTracker {
+	if p, ok := n.(AddressActivityProvider); ok {
+		return p.EvmAddressActivity()
+	}
+	return nil
+}
+
+// projectPreForward_erpc_addressActivity installs an address activity watcher
+// when evm.addressActivity is enabled. It takes [{"addresses": [...]}] and
+// returns the watcher id, which is polled with erpc_getAddressActivity and
+// removed with erpc_uninstallAddressActivity, like a filter.
+func projectPreForward_erpc_addressActivity(ctx context.Context, n common.Network, nq *common.NormalizedRequest) (bool, *common.NormalizedResponse, error) {
+	t := addressActivityOf(n)
+	if t == nil {
+		return false, nil, nil
+	}
+	params, err := addressActivityParams(ctx, nq)
+	if err != nil {
+		return true, nil, err
+	}
+	opts, ok := params[0].(map[string]interface{})
+	if !ok {
+		return true, nil, common.NewErrInvalidRequest(fmt.Errorf("params[0] must be an object with an addresses field"))
+	}
+	raw, _ := opts["addresses"].([]interface{})
+	addresses := make([]ethcommon.Address, 0, len(raw))
+	for _, r := range raw {
+		s, _ := r.(string)
+		if !ethcommon.IsHexAddress(s) {
+			return true, nil, common.NewErrInvalidRequest(fmt.Errorf("invalid address %v", r))
+		}
+		addresses = append(addresses, ethcommon.HexToAddress(s))
+	}
+	id, err := t.Install(addresses)
+	if err != nil {
+		return true, nil, common.NewErrInvalidRequest(err)
+	}
+	return addressActivityResponse(nq, id)
+}
+
+// projectPreForward_erpc_getAddressActivity returns the events of a watcher
+// since its previous poll.
+func projectPreForward_erpc_getAddressActivity(ctx context.Context, n common.Network, nq *common.NormalizedRequest) (bool, *common.NormalizedResponse, error) {
+	t := addressActivityOf(n)
+	if t == nil {
+		return false, nil, nil
+	}
+	params, err := addressActivityParams(ctx, nq)
+	if err != nil {
+		return true, nil, err
+	}
+	id, _ := params[0].(string)
+	events, dropped, ok := t.Poll(id)
+	if !ok {
+		return true, nil, common.NewErrInvalidRequest(fmt.Errorf("address activity watcher %q not found", id))
+	}
+	if events == nil {
+		events = []*AddressActivityEvent{}
+	}
+	return addressActivityResponse(nq, map[string]interface{}{
+		"events":  events,
+		"dropped": dropped,
+	})
+}
+
+// projectPreForward_erpc_uninstallAddressActivity removes a watcher.
+func projectPreForward_erpc_uninstallAddressActivity(ctx context.Context, n common.Network, nq *common.NormalizedRequest) (bool, *common.NormalizedResponse, error) {
+	t := addressActivityOf(n)
+	if t == nil {
+		return false, nil, nil
+	}
+	params, err := addressActivityParams(ctx, nq)
+	if err != nil {
+		return true, nil, err
+	}
+	id, _ := params[0].(string)
+	return addressActivityResponse(nq, t.Uninstall(id))
+}
+
+func addressActivityParams(ctx context.Context, nq *common.NormalizedRequest) ([]interface{}, error) {
+	jrq, err := nq.JsonRpcRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jrq.RLock()
+	defer jrq.RUnlock()
+	if len(jrq.Params) == 0 {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("params[0] is required"))
+	}
+	return jrq.Params, nil
+}
+
+func addressActivityResponse(nq *common.NormalizedRequest, result interface{}) (bool, *common.NormalizedResponse, error) {
+	jrr, err := common.NewJsonRpcResponse(nq.ID(), result, nil)
+	if err != nil {
+		return true, nil, err
+	}
+	return true, common.NewNormalizedResponse().WithRequest(nq).WithJsonRpcResponse(jrr), nil
+}
@@ -0,0 +1,214 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/erpc/erpc/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	blockByTimestampBefore  = "before"
+	blockByTimestampAfter   = "after"
+	blockByTimestampNearest = "nearest"
+)
+
+// blockByTimestampHeader is a block header fetched during the search, kept
+// raw so the match is returned exactly as the upstream sent it.
+type blockByTimestampHeader struct {
+	number    uint64
+	timestamp uint64
+	raw       json.RawMessage
+}
+
+// projectPreForward_erpc_getBlockByTimestamp serves erpc_getBlockByTimestamp:
+// params [timestamp, direction?] where timestamp is in unix seconds (a number
+// or a hex or decimal string) and direction is "before" (the last block at or
+// before it), "after" (the first block at or after it) or "nearest" (the
+// default; ties go to the earlier block). The headers are binary-searched
+// with eth_getBlockByNumber sent through the network, so they are cached like
+// client requests and a repeated search mostly hits the cache. The result is
+// the header of the block found, or null when no block matches.
+func projectPreForward_erpc_getBlockByTimestamp(ctx context.Context, n common.Network, nq *common.NormalizedRequest) (bool, *common.NormalizedResponse, error) {
+	ctx, span := common.StartDetailSpan(ctx, "Project.PreForwardHook.erpc_getBlockByTimestamp")
+	defer span.End()
+
+	target, direction, err := blockByTimestampParams(ctx, nq)
+	if err != nil {
+		return true, nil, err
+	}
+
+	lookups := 0
+	fetch := func(block string) (*blockByTimestampHeader, error) {
+		lookups++
+		raw, err := forwardSubRequest(ctx, n, nq.ID(), "", "eth_getBlockByNumber", []interface{}{block, false})
+		if err != nil {
+			return nil, err
+		}
+		var h *struct {
+			Number    hexutil.Uint64 `json:"number"`
+			Timestamp hexutil.Uint64 `json:"timestamp"`
+		}
+		if err := json.Unmarshal(raw, &h); err != nil {
+			return nil, fmt.Errorf("invalid block %s: %w", block, err)
+		}
+		if h == nil {
+			return nil, common.NewErrEndpointMissingData(fmt.Errorf("block %s is not available", block), nil)
+		}
+		return &blockByTimestampHeader{number: uint64(h.Number), timestamp: uint64(h.Timestamp), raw: raw}, nil
+	}
+
+	match, err := searchBlockByTimestamp(fetch, target, direction)
+	span.SetAttributes(
+		attribute.Int64("target_timestamp", int64(target)), // #nosec G115
+		attribute.String("direction", direction),
+		attribute.Int("lookups", lookups),
+	)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return true, nil, err
+	}
+
+	var result interface{}
+	if match != nil {
+		result = match.raw
+	}
+	jrr, err := common.NewJsonRpcResponse(nq.ID(), result, nil)
+	if err != nil {
+		return true, nil, err
+	}
+	return true, common.NewNormalizedResponse().WithRequest(nq).WithJsonRpcResponse(jrr), nil
+}
+
+// searchBlockByTimestamp finds the block for target and direction between
+// genesis and the latest block. Several blocks may share a timestamp (on
+// chains with sub-second blocks), so "before" means the last of them and
+// "after" the first.
+func searchBlockByTimestamp(fetch func(block string) (*blockByTimestampHeader, error), target uint64, direction string) (*blockByTimestampHeader, error) {
+	latest, err := fetch("latest")
+	if err != nil {
+		return nil, err
+	}
+	genesis, err := fetch("0x0")
+	if err != nil {
+		return nil, err
+	}
+	byNumber := func(bn uint64) (*blockByTimestampHeader, error) {
+		switch bn {
+		case genesis.number:
+			return genesis, nil
+		case latest.number:
+			return latest, nil
+		}
+		return fetch(hexutil.EncodeUint64(bn))
+	}
+
+	// firstAtOrAfter is the first block whose timestamp is >= ts, or nil
+	// when even the latest block is older.
+	firstAtOrAfter := func(ts uint64) (*blockByTimestampHeader, error) {
+		if latest.timestamp < ts {
+			return nil, nil
+		}
+		if genesis.timestamp >= ts {
+			return genesis, nil
+		}
+		// genesis is before ts and hi is at or after it; narrow down
+		// until they are adjacent.
+		lo, hi := genesis.number, latest.number
+		found := latest
+		for hi-lo > 1 {
+			mid := lo + (hi-lo)/2
+			h, err := byNumber(mid)
+			if err != nil {
+				return nil, err
+			}
+			if h.timestamp >= ts {
+				hi, found = mid, h
+			} else {
+				lo = mid
+			}
+		}
+		return found, nil
+	}
+
+	switch direction {
+	case blockByTimestampAfter:
+		return firstAtOrAfter(target)
+	case blockByTimestampBefore:
+		if genesis.timestamp > target {
+			return nil, nil
+		}
+		if latest.timestamp <= target {
+			return latest, nil
+		}
+		// The last block at or before target precedes the first one after
+		// it, which exists and is not genesis given the checks above.
+		next, err := firstAtOrAfter(target + 1)
+		if err != nil {
+			return nil, err
+		}
+		return byNumber(next.number - 1)
+	default:
+		after, err := firstAtOrAfter(target)
+		if err != nil {
+			return nil, err
+		}
+		if after == nil {
+			return latest, nil
+		}
+		if after.timestamp == target || after.number == genesis.number {
+			return after, nil
+		}
+		before, err := byNumber(after.number - 1)
+		if err != nil {
+			return nil, err
+		}
+		if target-before.timestamp <= after.timestamp-target {
+			return before, nil
+		}
+		return after, nil
+	}
+}
+
+func blockByTimestampParams(ctx context.Context, nq *common.NormalizedRequest) (uint64, string, error) {
+	jrq, err := nq.JsonRpcRequest(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	jrq.RLock()
+	defer jrq.RUnlock()
+	if len(jrq.Params) == 0 {
+		return 0, "", common.NewErrInvalidRequest(fmt.Errorf("params[0] (timestamp) is required"))
+	}
+
+	var target uint64
+	switch v := jrq.Params[0].(type) {
+	case float64:
+		if v < 0 {
+			return 0, "", common.NewErrInvalidRequest(fmt.Errorf("timestamp must not be negative"))
+		}
+		target = uint64(v)
+	case string:
+		target, err = strconv.ParseUint(v, 0, 64)
+		if err != nil {
+			return 0, "", common.NewErrInvalidRequest(fmt.Errorf("invalid timestamp %q: %w", v, err))
+		}
+	default:
+		return 0, "", common.NewErrInvalidRequest(fmt.Errorf("timestamp must be a number or a string, got %T", v))
+	}
+
+	direction := blockByTimestampNearest
+	if len(jrq.Params) > 1 && jrq.Params[1] != nil {
+		s, _ := jrq.Params[1].(string)
+		direction = strings.ToLower(s)
+		if direction != blockByTimestampBefore && direction != blockByTimestampAfter && direction != blockByTimestampNearest {
+			return 0, "", common.NewErrInvalidRequest(fmt.Errorf("direction must be before, after or nearest, got %v", jrq.Params[1]))
+		}
+	}
+	return target, direction, nil
+}
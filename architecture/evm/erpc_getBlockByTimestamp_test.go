@@ -0,0 +1,137 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newBlockByTimestampTestNetwork serves blocks 0..latest where block n has
+// timestamp ts(n), and counts the eth_getBlockByNumber calls.
+func newBlockByTimestampTestNetwork(t *testing.T, latest uint64, ts func(n uint64) uint64) (*mockNetwork, *int) {
+	t.Helper()
+	n := &mockNetwork{}
+	n.On("Config").Return(&common.NetworkConfig{})
+	calls := 0
+	n.On("Forward", mock.Anything, mock.Anything).Return(func(ctx context.Context, r *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+		jrq, _ := r.JsonRpcRequest()
+		require.Equal(t, "eth_getBlockByNumber", jrq.Method)
+		calls++
+		bn := latest
+		if tag := jrq.Params[0].(string); tag != "latest" {
+			v, err := hexutil.DecodeUint64(tag)
+			require.NoError(t, err)
+			bn = v
+		}
+		var result interface{}
+		if bn <= latest {
+			result = map[string]interface{}{
+				"number":    hexutil.EncodeUint64(bn),
+				"timestamp": hexutil.EncodeUint64(ts(bn)),
+			}
+		}
+		raw, _ := json.Marshal(result)
+		jrr, err := common.NewJsonRpcResponse(r.ID(), json.RawMessage(raw), nil)
+		require.NoError(t, err)
+		return common.NewNormalizedResponse().WithRequest(r).WithJsonRpcResponse(jrr), nil
+	}, nil)
+	return n, &calls
+}
+
+func TestProjectPreForward_GetBlockByTimestamp(t *testing.T) {
+	ctx := context.Background()
+	call := func(t *testing.T, n common.Network, params string) (interface{}, error) {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"erpc_getBlockByTimestamp","params":%s}`, params)
+		handled, resp, err := HandleProjectPreForward(ctx, n, common.NewNormalizedRequest([]byte(body)))
+		require.True(t, handled)
+		if err != nil {
+			return nil, err
+		}
+		jrr, err := resp.JsonRpcResponse()
+		require.NoError(t, err)
+		var out map[string]interface{}
+		require.NoError(t, json.Unmarshal(jrr.GetResultBytes(), &out))
+		if out == nil {
+			return nil, nil
+		}
+		return out["number"], nil
+	}
+
+	// 12 second blocks starting at 1000: block n is at 1000+12n.
+	regular := func(n uint64) uint64 { return 1000 + 12*n }
+
+	t.Run("FindsTheBlockForEachDirection", func(t *testing.T) {
+		n, calls := newBlockByTimestampTestNetwork(t, 1_000_000, regular)
+		target := regular(123_456) + 5
+
+		got, err := call(t, n, fmt.Sprintf(`[%d, "before"]`, target))
+		require.NoError(t, err)
+		assert.Equal(t, hexutil.EncodeUint64(123_456), got)
+		assert.LessOrEqual(t, *calls, 25, "the search takes about log2(latest) lookups")
+
+		got, err = call(t, n, fmt.Sprintf(`["%s", "after"]`, hexutil.EncodeUint64(target)))
+		require.NoError(t, err)
+		assert.Equal(t, hexutil.EncodeUint64(123_457), got)
+
+		got, err = call(t, n, fmt.Sprintf(`[%d]`, target))
+		require.NoError(t, err)
+		assert.Equal(t, hexutil.EncodeUint64(123_456), got, "5 seconds before is nearer than 7 after")
+	})
+
+	t.Run("NearestPrefersTheCloserBlock", func(t *testing.T) {
+		n, _ := newBlockByTimestampTestNetwork(t, 1000, regular)
+		got, err := call(t, n, fmt.Sprintf(`[%d, "nearest"]`, regular(10)+5))
+		require.NoError(t, err)
+		assert.Equal(t, "0xa", got)
+		got, err = call(t, n, fmt.Sprintf(`[%d, "nearest"]`, regular(10)+7))
+		require.NoError(t, err)
+		assert.Equal(t, "0xb", got)
+		got, err = call(t, n, fmt.Sprintf(`[%d, "nearest"]`, regular(10)+6))
+		require.NoError(t, err)
+		assert.Equal(t, "0xa", got, "ties go to the earlier block")
+	})
+
+	t.Run("SharedTimestamps", func(t *testing.T) {
+		// Four blocks per second, like chains with sub-second blocks.
+		n, _ := newBlockByTimestampTestNetwork(t, 1000, func(n uint64) uint64 { return 1000 + n/4 })
+		got, err := call(t, n, `[1010, "before"]`)
+		require.NoError(t, err)
+		assert.Equal(t, "0x2b", got, "the last block of the second")
+		got, err = call(t, n, `[1010, "after"]`)
+		require.NoError(t, err)
+		assert.Equal(t, "0x28", got, "the first block of the second")
+	})
+
+	t.Run("OutOfRange", func(t *testing.T) {
+		n, _ := newBlockByTimestampTestNetwork(t, 1000, regular)
+		got, err := call(t, n, `[10, "before"]`)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+		got, err = call(t, n, `[10]`)
+		require.NoError(t, err)
+		assert.Equal(t, "0x0", got)
+		got, err = call(t, n, `[99999999, "after"]`)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+		got, err = call(t, n, `[99999999]`)
+		require.NoError(t, err)
+		assert.Equal(t, "0x3e8", got)
+	})
+
+	t.Run("InvalidParams", func(t *testing.T) {
+		n, _ := newBlockByTimestampTestNetwork(t, 1000, regular)
+		_, err := call(t, n, `[]`)
+		assert.ErrorContains(t, err, "timestamp")
+		_, err = call(t, n, `["yesterday"]`)
+		assert.ErrorContains(t, err, "invalid timestamp")
+		_, err = call(t, n, `[1000, "around"]`)
+		assert.ErrorContains(t, err, "direction")
+	})
+}
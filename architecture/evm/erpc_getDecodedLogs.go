@@ -0,0 +1,175 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// decodedLogsLookupConcurrency bounds the ABI fetches of one request.
+const decodedLogsLookupConcurrency = 8
+
+func abiRegistryOf(n common.Network) *AbiRegistry {
+	if p, ok := n.(AbiRegistryProvider); ok {
+		return p.EvmAbiRegistry()
+	}
+	return nil
+}
+
+// projectPreForward_erpc_getDecodedLogs serves erpc_getDecodedLogs when
+// evm.abi.decodedLogs is enabled: the params are forwarded as eth_getLogs
+// and each log of the result gets a "decoded" field with its event name and
+// arguments. Logs that cannot be decoded are returned as they are.
+func projectPreForward_erpc_getDecodedLogs(ctx context.Context, n common.Network, nq *common.NormalizedRequest) (bool, *common.NormalizedResponse, error) {
+	return forwardDecoded(ctx, n, nq, "eth_getLogs", "Project.PreForwardHook.erpc_getDecodedLogs")
+}
+
+// projectPreForward_erpc_getDecodedFilterChanges is the decoded counterpart
+// of eth_getFilterChanges: clients create a log filter with eth_newFilter as
+// usual and poll it with this method to receive decoded events, which is how
+// eRPC serves decoded event subscriptions over HTTP. Entries of block and
+// pending transaction filters are hashes and are returned as they are.
+func projectPreForward_erpc_getDecodedFilterChanges(ctx context.Context, n common.Network, nq *common.NormalizedRequest) (bool, *common.NormalizedResponse, error) {
+	return forwardDecoded(ctx, n, nq, "eth_getFilterChanges", "Project.PreForwardHook.erpc_getDecodedFilterChanges")
+}
+
+// projectPreForward_erpc_getDecodedFilterLogs is the decoded counterpart of
+// eth_getFilterLogs.
+func projectPreForward_erpc_getDecodedFilterLogs(ctx context.Context, n common.Network, nq *common.NormalizedRequest) (bool, *common.NormalizedResponse, error) {
+	return forwardDecoded(ctx, n, nq, "eth_getFilterLogs", "Project.PreForwardHook.erpc_getDecodedFilterLogs")
+}
+
+// forwardDecoded forwards the params of nq as method, which returns an array
+// of logs, and adds a "decoded" field to each log it can decode.
+func forwardDecoded(ctx context.Context, n common.Network, nq *common.NormalizedRequest, method string, spanName string) (bool, *common.NormalizedResponse, error) {
+	reg := abiRegistryOf(n)
+	if reg == nil || !reg.DecodedLogsEnabled() {
+		return false, nil, nil
+	}
+	ctx, span := common.StartDetailSpan(ctx, spanName)
+	defer span.End()
+
+	jrq, err := nq.JsonRpcRequest(ctx)
+	if err != nil {
+		return true, nil, err
+	}
+	jrq.RLock()
+	params := jrq.Params
+	jrq.RUnlock()
+
+	sub := common.NewJsonRpcRequest(method, params)
+	if err := sub.SetID(util.RandomID()); err != nil {
+		return true, nil, err
+	}
+	snq := common.NewNormalizedRequestFromJsonRpcRequest(sub)
+	snq.SetDirectives(nq.Directives().Clone())
+	snq.SetNetwork(n)
+	snq.CopyHttpContextFrom(nq)
+
+	// The post-forward hooks are applied as for a client request, so that
+	// eth_getLogs splitting on range errors still works.
+	rs, err := n.Forward(ctx, snq)
+	rs, err = HandleNetworkPostForward(ctx, n, snq, rs, err)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return true, nil, err
+	}
+	defer rs.Release()
+	jrr, err := rs.JsonRpcResponse(ctx)
+	if err != nil {
+		return true, nil, err
+	}
+	if jrr == nil {
+		return true, nil, fmt.Errorf("unexpected empty %s response", method)
+	}
+
+	var items []interface{}
+	if err := json.Unmarshal(jrr.GetResultBytes(), &items); err != nil {
+		return true, nil, fmt.Errorf("failed to parse %s result: %w", method, err)
+	}
+	logs := make([]map[string]interface{}, 0, len(items))
+	for _, it := range items {
+		if lg, ok := it.(map[string]interface{}); ok {
+			logs = append(logs, lg)
+		}
+	}
+	decoded := reg.decodeLogs(ctx, logs)
+	span.SetAttributes(
+		attribute.Int("logs.count", len(logs)),
+		attribute.Int("logs.decoded", decoded),
+	)
+	if items == nil {
+		items = []interface{}{}
+	}
+
+	out, err := common.NewJsonRpcResponse(nq.ID(), items, nil)
+	if err != nil {
+		return true, nil, err
+	}
+	return true, common.NewNormalizedResponse().
+		WithRequest(nq).
+		WithJsonRpcResponse(out).
+		SetFromCache(rs.FromCache()).
+		SetUpstream(rs.Upstream()).
+		SetAttempts(rs.Attempts()), nil
+}
+
+// decodeLogs adds a "decoded" field to each log it can decode and returns
+// how many it decoded. ABIs of the emitting contracts are looked up first,
+// in parallel, so each contract is fetched at most once.
+func (r *AbiRegistry) decodeLogs(ctx context.Context, logs []map[string]interface{}) int {
+	addresses := make(map[ethcommon.Address]struct{})
+	for _, lg := range logs {
+		if a, ok := lg["address"].(string); ok && ethcommon.IsHexAddress(a) {
+			addresses[ethcommon.HexToAddress(a)] = struct{}{}
+		}
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, decodedLogsLookupConcurrency)
+	for a := range addresses {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(a ethcommon.Address) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if _, err := r.lookup(ctx, a); err != nil {
+				r.logger.Debug().Err(err).Str("address", a.Hex()).Msg("failed to look up contract abi")
+			}
+		}(a)
+	}
+	wg.Wait()
+
+	decoded := 0
+	for _, lg := range logs {
+		a, _ := lg["address"].(string)
+		if !ethcommon.IsHexAddress(a) {
+			continue
+		}
+		rawTopics, _ := lg["topics"].([]interface{})
+		topics := make([]ethcommon.Hash, 0, len(rawTopics))
+		for _, t := range rawTopics {
+			s, _ := t.(string)
+			topics = append(topics, ethcommon.HexToHash(s))
+		}
+		var data []byte
+		if s, ok := lg["data"].(string); ok && s != "" && s != "0x" {
+			b, err := hexutil.Decode(s)
+			if err != nil {
+				continue
+			}
+			data = b
+		}
+		if ev := r.decodeLog(ethcommon.HexToAddress(a), topics, data); ev != nil {
+			lg["decoded"] = ev
+			decoded++
+		}
+	}
+	return decoded
+}
@@ -476,7 +476,8 @@ func ExtractJsonRpcError(r *http.Response, nr *common.NormalizedResponse, jr *co
 			strings.Contains(msg, "not supported") ||
 			strings.Contains(msg, "method is not whitelisted") ||
 			strings.Contains(msg, "not allowed to access method") ||
-			strings.Contains(msg, "is not included in your current plan") {
+			strings.Contains(msg, "is not included in your current plan") ||
+			isClientUnsupportedError(upstream, msg) {
 			method := ""
 			if nr != nil && nr.Request() != nil {
 				method, _ = nr.Request().Method()
@@ -892,6 +893,23 @@ func ExtractGrpcError(st *status.Status, upstream common.Upstream) error {
 	}
 }
 
+// isClientUnsupportedError reports whether msg is how the upstream's node
+// client says a method is unsupported, per the matching client quirks.
+func isClientUnsupportedError(upstream common.Upstream, msg string) bool {
+	nu, ok := upstream.(interface{ NodeClient() *common.NodeClient })
+	if !ok {
+		return false
+	}
+	for _, q := range common.ClientQuirksFor(nu.NodeClient()) {
+		for _, e := range q.UnsupportedErrors {
+			if e != "" && strings.Contains(msg, e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func getVendorSpecificErrorIfAny(
 	rp *http.Response,
 	nr *common.NormalizedResponse,
@@ -399,6 +399,12 @@ func upstreamPostForward_eth_getLogs(ctx context.Context, n common.Network, u co
 	))
 	defer span.End()
 
+	if re == nil && rs != nil {
+		if err := maybeCheckGetLogsCompleteness(ctx, n, u, rq, rs); err != nil {
+			return rs, err
+		}
+	}
+
 	if re == nil && rs != nil && rs.IsResultEmptyish(ctx) {
 		return normalizeEmptyArrayResponse(ctx, u, rq, rs)
 	}
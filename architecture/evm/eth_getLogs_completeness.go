@@ -0,0 +1,357 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	bdsevm "github.com/blockchain-data-standards/manifesto/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+)
+
+// getLogsCompletenessTimeout bounds a whole cross-check, which may issue a
+// header and a receipts lookup for every block of the range.
+const getLogsCompletenessTimeout = 30 * time.Second
+
+// getLogsCompletenessContextKey marks the sub-requests of a cross-check so the
+// eth_getLogs re-sent to another upstream is not cross-checked in turn.
+const getLogsCompletenessContextKey common.ContextKey = "getLogsCompletenessCheck"
+
+type getLogsCompletenessLog struct {
+	BlockNumber string   `json:"blockNumber"`
+	Address     string   `json:"address"`
+	Topics      []string `json:"topics"`
+}
+
+// getLogsFilterMatcher matches logs against the address and topics of an
+// eth_getLogs filter.
+type getLogsFilterMatcher struct {
+	addresses []string   // lowercase; empty matches any address
+	topics    [][]string // per position, lowercase; nil matches any topic
+}
+
+func newGetLogsFilterMatcher(filter map[string]interface{}) *getLogsFilterMatcher {
+	m := &getLogsFilterMatcher{}
+	switch a := filter["address"].(type) {
+	case string:
+		m.addresses = []string{strings.ToLower(a)}
+	case []interface{}:
+		for _, v := range a {
+			if s, ok := v.(string); ok {
+				m.addresses = append(m.addresses, strings.ToLower(s))
+			}
+		}
+	}
+	if topics, ok := filter["topics"].([]interface{}); ok {
+		for _, t := range topics {
+			var options []string
+			switch tv := t.(type) {
+			case string:
+				options = []string{strings.ToLower(tv)}
+			case []interface{}:
+				for _, v := range tv {
+					if s, ok := v.(string); ok {
+						options = append(options, strings.ToLower(s))
+					}
+				}
+			}
+			m.topics = append(m.topics, options)
+		}
+	}
+	return m
+}
+
+func (m *getLogsFilterMatcher) matches(lg *getLogsCompletenessLog) bool {
+	if len(m.addresses) > 0 && !containsFold(m.addresses, lg.Address) {
+		return false
+	}
+	for i, options := range m.topics {
+		if len(options) == 0 {
+			continue
+		}
+		if i >= len(lg.Topics) || !containsFold(options, lg.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// mayMatchBloom reports whether a block with the given logsBloom can contain
+// a matching log. A false answer is definitive; a true one may be a false
+// positive of the bloom.
+func (m *getLogsFilterMatcher) mayMatchBloom(bloom []byte) bool {
+	anyIn := func(values []string) bool {
+		for _, v := range values {
+			b, err := bdsevm.HexToBytes(v)
+			if err != nil {
+				return true
+			}
+			probe := make([]byte, bdsevm.BloomLength)
+			bloomAdd(probe, b)
+			contained := true
+			for i := range probe {
+				if probe[i]&bloom[i] != probe[i] {
+					contained = false
+					break
+				}
+			}
+			if contained {
+				return true
+			}
+		}
+		return false
+	}
+	if len(m.addresses) > 0 && !anyIn(m.addresses) {
+		return false
+	}
+	for _, options := range m.topics {
+		if len(options) > 0 && !anyIn(options) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, v string) bool {
+	v = strings.ToLower(v)
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// getLogsCompletenessCheck is one sampled cross-check of an eth_getLogs
+// response, with everything it needs copied out of the response so it can
+// outlive it.
+type getLogsCompletenessCheck struct {
+	cfg       *common.EvmGetLogsCompletenessConfig
+	network   common.Network
+	upstream  common.Upstream
+	requestId interface{}
+	filter    map[string]interface{}
+	fromBlock int64
+	toBlock   int64
+	returned  map[int64]int
+	total     int
+}
+
+// maybeCheckGetLogsCompleteness cross-checks a sample of eth_getLogs responses
+// for finalized ranges. In reject mode a truncated response comes back as a
+// content validation error so it is retried elsewhere; otherwise the check
+// runs in the background and only flags the upstream.
+func maybeCheckGetLogsCompleteness(ctx context.Context, n common.Network, u common.Upstream, rq *common.NormalizedRequest, rs *common.NormalizedResponse) error {
+	ncfg := n.Config()
+	if ncfg == nil || ncfg.Evm == nil || ncfg.Evm.GetLogsCompletenessCheck == nil || rs == nil {
+		return nil
+	}
+	if ctx.Value(getLogsCompletenessContextKey) != nil {
+		return nil
+	}
+	cfg := ncfg.Evm.GetLogsCompletenessCheck
+	if cfg.SampleRate != nil && rand.Float64() >= *cfg.SampleRate { // #nosec G404
+		return nil
+	}
+
+	check, err := newGetLogsCompletenessCheck(ctx, cfg, n, u, rq, rs)
+	if check == nil || err != nil {
+		return nil
+	}
+
+	if cfg.Reject != nil && *cfg.Reject {
+		return check.run(context.WithValue(ctx, getLogsCompletenessContextKey, true))
+	}
+	// The response goes back to the client meanwhile; detach from its request.
+	bgCtx := context.WithValue(context.WithoutCancel(ctx), getLogsCompletenessContextKey, true)
+	go func() {
+		_ = check.run(bgCtx)
+	}()
+	return nil
+}
+
+// newGetLogsCompletenessCheck returns nil when the request is not eligible:
+// no concrete range, a range above maxBlockRange, or not yet finalized.
+func newGetLogsCompletenessCheck(ctx context.Context, cfg *common.EvmGetLogsCompletenessConfig, n common.Network, u common.Upstream, rq *common.NormalizedRequest, rs *common.NormalizedResponse) (*getLogsCompletenessCheck, error) {
+	jrq, err := rq.JsonRpcRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jrq.RLock()
+	var filter map[string]interface{}
+	if len(jrq.Params) > 0 {
+		if f, ok := jrq.Params[0].(map[string]interface{}); ok {
+			filter = make(map[string]interface{}, len(f))
+			for k, v := range f {
+				filter[k] = v
+			}
+		}
+	}
+	jrq.RUnlock()
+	if filter == nil {
+		return nil, nil
+	}
+	if bh, ok := filter["blockHash"].(string); ok && bh != "" {
+		return nil, nil
+	}
+	fromBlock, toBlock, err := extractBlockRange(filter)
+	if err != nil || fromBlock < 0 || toBlock < fromBlock {
+		return nil, nil
+	}
+	if cfg.MaxBlockRange > 0 && toBlock-fromBlock+1 > cfg.MaxBlockRange {
+		return nil, nil
+	}
+	if finalized := n.EvmHighestFinalizedBlockNumber(ctx); finalized <= 0 || toBlock > finalized {
+		return nil, nil
+	}
+
+	jrr, err := rs.JsonRpcResponse(ctx)
+	if err != nil || jrr == nil || jrr.Error != nil {
+		return nil, err
+	}
+	var logs []getLogsCompletenessLog
+	if raw := jrr.GetResultBytes(); len(raw) > 0 {
+		if err := common.SonicCfg.Unmarshal(raw, &logs); err != nil {
+			return nil, err
+		}
+	}
+	returned := make(map[int64]int)
+	for _, lg := range logs {
+		bn, err := common.HexToInt64(lg.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		returned[bn]++
+	}
+
+	return &getLogsCompletenessCheck{
+		cfg:       cfg,
+		network:   n,
+		upstream:  u,
+		requestId: rq.ID(),
+		filter:    filter,
+		fromBlock: fromBlock,
+		toBlock:   toBlock,
+		returned:  returned,
+		total:     len(logs),
+	}, nil
+}
+
+func (c *getLogsCompletenessCheck) run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, getLogsCompletenessTimeout)
+	defer cancel()
+
+	var err error
+	var expected int
+	var block int64
+	if c.cfg.Source == common.GetLogsCompletenessSourceUpstream {
+		expected, err = c.countOnOtherUpstream(ctx)
+	} else {
+		block, expected, err = c.firstIncompleteBlock(ctx)
+	}
+
+	lg := c.network.Logger().With().
+		Str("upstreamId", c.upstream.Id()).
+		Interface("id", c.requestId).
+		Str("source", string(c.cfg.Source)).
+		Int64("fromBlock", c.fromBlock).
+		Int64("toBlock", c.toBlock).
+		Logger()
+	outcome := "complete"
+	var truncErr error
+	switch {
+	case err != nil:
+		outcome = "error"
+		lg.Debug().Err(err).Msg("could not cross-check eth_getLogs completeness")
+	case c.cfg.Source == common.GetLogsCompletenessSourceUpstream && expected > c.total:
+		truncErr = fmt.Errorf("eth_getLogs returned %d logs but another upstream returned %d for the same finalized range", c.total, expected)
+	case block > 0:
+		truncErr = fmt.Errorf("eth_getLogs returned %d logs for block %d but its receipts contain %d matching logs", c.returned[block], block, expected)
+	}
+	if truncErr != nil {
+		outcome = "truncated"
+		lg.Warn().Err(truncErr).Msg("upstream returned a truncated eth_getLogs result")
+	}
+	telemetry.CounterHandle(telemetry.MetricNetworkEvmGetLogsCompletenessChecks,
+		c.network.ProjectId(),
+		c.network.Label(),
+		c.upstream.Id(),
+		string(c.cfg.Source),
+		outcome,
+	).Inc()
+
+	if truncErr != nil {
+		return common.NewErrEndpointContentValidation(truncErr, c.upstream)
+	}
+	return nil
+}
+
+// countOnOtherUpstream repeats the request on any other upstream and returns
+// how many logs it got.
+func (c *getLogsCompletenessCheck) countOnOtherUpstream(ctx context.Context) (int, error) {
+	raw, err := forwardSubRequest(ctx, c.network, c.requestId, "!"+c.upstream.Id(), "eth_getLogs", []interface{}{c.filter})
+	if err != nil {
+		return 0, err
+	}
+	var logs []getLogsCompletenessLog
+	if err := common.SonicCfg.Unmarshal(raw, &logs); err != nil {
+		return 0, err
+	}
+	return len(logs), nil
+}
+
+// firstIncompleteBlock recounts matching logs block by block from receipts,
+// and returns the first block with more matching logs than were returned
+// (0 when none). Blocks with no returned logs whose header bloom rules out a
+// match are not fetched.
+func (c *getLogsCompletenessCheck) firstIncompleteBlock(ctx context.Context) (int64, int, error) {
+	matcher := newGetLogsFilterMatcher(c.filter)
+	for bn := c.fromBlock; bn <= c.toBlock; bn++ {
+		hexBn := fmt.Sprintf("0x%x", bn)
+		if c.returned[bn] == 0 {
+			raw, err := forwardSubRequest(ctx, c.network, c.requestId, "", "eth_getBlockByNumber", []interface{}{hexBn, false})
+			if err != nil {
+				return 0, 0, err
+			}
+			var header struct {
+				LogsBloom string `json:"logsBloom"`
+			}
+			if err := common.SonicCfg.Unmarshal(raw, &header); err != nil {
+				return 0, 0, err
+			}
+			if isZeroBloom(header.LogsBloom) {
+				continue
+			}
+			bloom, err := bdsevm.HexToBytes(header.LogsBloom)
+			if err == nil && len(bloom) == bdsevm.BloomLength && !matcher.mayMatchBloom(bloom) {
+				continue
+			}
+		}
+
+		raw, err := forwardSubRequest(ctx, c.network, c.requestId, "", "eth_getBlockReceipts", []interface{}{hexBn})
+		if err != nil {
+			return 0, 0, err
+		}
+		var receipts []struct {
+			Logs []getLogsCompletenessLog `json:"logs"`
+		}
+		if err := common.SonicCfg.Unmarshal(raw, &receipts); err != nil {
+			return 0, 0, err
+		}
+		expected := 0
+		for i := range receipts {
+			for j := range receipts[i].Logs {
+				if matcher.matches(&receipts[i].Logs[j]) {
+					expected++
+				}
+			}
+		}
+		if expected > c.returned[bn] {
+			return bn, expected, nil
+		}
+	}
+	return 0, 0, nil
+}
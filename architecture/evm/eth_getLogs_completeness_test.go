@@ -0,0 +1,125 @@
+package evm
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	bdsevm "github.com/blockchain-data-standards/manifesto/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLogsCompletenessCheck(t *testing.T) {
+	const (
+		addr  = "0x1111111111111111111111111111111111111111"
+		other = "0x2222222222222222222222222222222222222222"
+	)
+	log := func(block int64, address string) string {
+		return fmt.Sprintf(`{"blockNumber":"0x%x","address":"%s","topics":[]}`, block, address)
+	}
+	bloomOf := func(address string) string {
+		bloom := make([]byte, bdsevm.BloomLength)
+		b, _ := bdsevm.HexToBytes(address)
+		bloomAdd(bloom, b)
+		return "0x" + hex.EncodeToString(bloom)
+	}
+	result := func(raw string) *common.NormalizedResponse {
+		return common.NewNormalizedResponse().WithJsonRpcResponse(
+			common.MustNewJsonRpcResponseFromBytes([]byte(`1`), []byte(raw), nil),
+		)
+	}
+
+	// setup wires a network whose finalized block is 100 and answers the
+	// cross-check sub-requests from the given per-method results.
+	setup := func(t *testing.T, source common.GetLogsCompletenessSource, answers map[string]func(params []interface{}) string) (*mockNetwork, *mockEvmUpstream, map[string]int) {
+		calls := map[string]int{}
+		n := &mockNetwork{}
+		n.On("Config").Return(&common.NetworkConfig{Evm: &common.EvmNetworkConfig{
+			GetLogsCompletenessCheck: &common.EvmGetLogsCompletenessConfig{
+				Source:        source,
+				SampleRate:    util.Float64Ptr(1),
+				MaxBlockRange: 10,
+				Reject:        util.BoolPtr(true),
+			},
+		}}).Maybe()
+		n.On("ProjectId").Return("prj").Maybe()
+		n.On("Id").Return("evm:1").Maybe()
+		n.On("EvmHighestFinalizedBlockNumber", mock.Anything).Return(int64(100)).Maybe()
+		n.On("Forward", mock.Anything, mock.Anything).Return(func(ctx context.Context, r *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+			jrq, err := r.JsonRpcRequest(ctx)
+			require.NoError(t, err)
+			calls[jrq.Method]++
+			if jrq.Method == "eth_getLogs" {
+				assert.Equal(t, "!rpc1", r.Directives().UseUpstream, "cross-check must go to another upstream")
+			}
+			answer, ok := answers[jrq.Method]
+			require.True(t, ok, "unexpected sub-request %s", jrq.Method)
+			return result(answer(jrq.Params)), nil
+		}).Maybe()
+		u := &mockEvmUpstream{}
+		u.On("Id").Return("rpc1").Maybe()
+		return n, u, calls
+	}
+	check := func(n *mockNetwork, u *mockEvmUpstream, fromBlock, toBlock int64, logs string) error {
+		rq := common.NewNormalizedRequest([]byte(fmt.Sprintf(
+			`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"fromBlock":"0x%x","toBlock":"0x%x","address":"%s"}]}`,
+			fromBlock, toBlock, addr,
+		)))
+		return maybeCheckGetLogsCompleteness(context.Background(), n, u, rq, result(logs))
+	}
+
+	t.Run("ReceiptsDetectTruncation", func(t *testing.T) {
+		n, u, _ := setup(t, common.GetLogsCompletenessSourceReceipts, map[string]func([]interface{}) string{
+			"eth_getBlockReceipts": func([]interface{}) string {
+				return `[{"logs":[` + log(90, addr) + `,` + log(90, other) + `]},{"logs":[` + log(90, addr) + `]}]`
+			},
+		})
+		err := check(n, u, 90, 90, `[`+log(90, addr)+`]`)
+		require.Error(t, err)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeEndpointContentValidation))
+	})
+
+	t.Run("ReceiptsComplete", func(t *testing.T) {
+		n, u, _ := setup(t, common.GetLogsCompletenessSourceReceipts, map[string]func([]interface{}) string{
+			"eth_getBlockReceipts": func([]interface{}) string {
+				return `[{"logs":[` + log(90, addr) + `,` + log(90, other) + `]}]`
+			},
+		})
+		assert.NoError(t, check(n, u, 90, 90, `[`+log(90, addr)+`]`))
+	})
+
+	t.Run("BloomSkipsBlocksThatCannotMatch", func(t *testing.T) {
+		n, u, calls := setup(t, common.GetLogsCompletenessSourceReceipts, map[string]func([]interface{}) string{
+			"eth_getBlockByNumber": func(params []interface{}) string {
+				if params[0] == "0x5b" {
+					return `{"logsBloom":"` + bloomOf(other) + `"}`
+				}
+				return `{"logsBloom":"0x00"}`
+			},
+		})
+		assert.NoError(t, check(n, u, 90, 92, `[]`))
+		assert.Equal(t, 3, calls["eth_getBlockByNumber"])
+		assert.Zero(t, calls["eth_getBlockReceipts"])
+	})
+
+	t.Run("OtherUpstreamDetectsTruncation", func(t *testing.T) {
+		n, u, _ := setup(t, common.GetLogsCompletenessSourceUpstream, map[string]func([]interface{}) string{
+			"eth_getLogs": func([]interface{}) string {
+				return `[` + log(90, addr) + `,` + log(91, addr) + `]`
+			},
+		})
+		err := check(n, u, 90, 91, `[`+log(90, addr)+`]`)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeEndpointContentValidation))
+	})
+
+	t.Run("SkipsUnfinalizedRanges", func(t *testing.T) {
+		n, u, calls := setup(t, common.GetLogsCompletenessSourceUpstream, nil)
+		assert.NoError(t, check(n, u, 95, 101, `[]`))
+		assert.Empty(t, calls)
+	})
+}
@@ -131,6 +131,12 @@ func upstreamPostForward_eth_sendRawTransaction(
 	}
 }
 
+// TxHashOfSendRawTransaction returns the hash of the signed transaction an
+// eth_sendRawTransaction request carries.
+func TxHashOfSendRawTransaction(ctx context.Context, rq *common.NormalizedRequest) (string, error) {
+	return extractTxHashFromSendRawTransaction(ctx, rq)
+}
+
 // extractTxHashFromSendRawTransaction extracts the transaction hash from the request
 func extractTxHashFromSendRawTransaction(ctx context.Context, rq *common.NormalizedRequest) (string, error) {
 	_, span := common.StartDetailSpan(ctx, "extractTxHashFromSendRawTransaction")
@@ -0,0 +1,117 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// networkPreForward_eth_syncing answers eth_syncing from the state pollers of
+// the selected upstreams instead of relaying whichever single upstream happens
+// to be tried first. Clients get a consistent view of the pool:
+//
+//   - false when at least one selected upstream is known to be synced;
+//   - a structured progress object when every upstream with a known state is
+//     syncing (currentBlock is the most advanced syncing upstream, highestBlock
+//     is the network's served tip when known);
+//   - pass-through to upstreams when no state is known yet (pollers cold or
+//     eth_syncing unsupported), so behavior is unchanged in that case.
+//
+// Selection has already excluded unhealthy upstreams, so the list passed in is
+// the "healthy" view of the pool. Respects the skip-cache-read directive so
+// force-refresh requests can still reach a real upstream.
+func networkPreForward_eth_syncing(ctx context.Context, n common.Network, ups []common.Upstream, r *common.NormalizedRequest) (handled bool, resp *common.NormalizedResponse, err error) {
+	if r == nil || n == nil || len(ups) == 0 {
+		return false, nil, nil
+	}
+	if r.ShouldSkipCacheRead("") {
+		return false, nil, nil
+	}
+
+	ctx, span := common.StartDetailSpan(ctx, "Network.PreForwardHook.eth_syncing", trace.WithAttributes(
+		attribute.String("request.id", fmt.Sprintf("%v", r.ID())),
+		attribute.String("network.id", n.Id()),
+	))
+	defer span.End()
+
+	var syncingCount, syncedCount int
+	var currentBlock int64
+	for _, u := range ups {
+		eu, ok := u.(common.EvmUpstream)
+		if !ok || eu.EvmStatePoller() == nil || eu.EvmStatePoller().IsObjectNull() {
+			continue
+		}
+		switch eu.EvmSyncingState() {
+		case common.EvmSyncingStateNotSyncing:
+			syncedCount++
+		case common.EvmSyncingStateSyncing:
+			syncingCount++
+			if lb := eu.EvmEffectiveLatestBlock(); lb > currentBlock {
+				currentBlock = lb
+			}
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("upstreams.synced", syncedCount),
+		attribute.Int("upstreams.syncing", syncingCount),
+	)
+
+	var result interface{}
+	switch {
+	case syncedCount > 0:
+		result = false
+	case syncingCount > 0:
+		highestBlock := n.EvmHighestLatestBlockNumber(ctx)
+		if highestBlock < currentBlock {
+			highestBlock = currentBlock
+		}
+		progress, err := buildSyncingProgress(currentBlock, highestBlock)
+		if err != nil {
+			common.SetTraceSpanError(span, err)
+			return true, nil, err
+		}
+		result = progress
+	default:
+		span.SetAttributes(attribute.Bool("skipped.unknown_syncing_state", true))
+		return false, nil, nil
+	}
+
+	jrqID := r.ID()
+	if jrqID == nil {
+		jrqID = util.RandomID()
+	}
+	jrr, err := common.NewJsonRpcResponse(jrqID, result, nil)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return true, nil, fmt.Errorf("failed to create JsonRpcResponse: %w", err)
+	}
+
+	nr := common.NewNormalizedResponse().
+		WithRequest(r).
+		WithJsonRpcResponse(jrr)
+	span.SetAttributes(attribute.Bool("handled_by_hook", true))
+	return true, nr, nil
+}
+
+// buildSyncingProgress renders the geth-style eth_syncing progress object. The
+// pollers do not track where a sync started, so startingBlock mirrors
+// currentBlock rather than inventing a value.
+func buildSyncingProgress(currentBlock, highestBlock int64) (map[string]interface{}, error) {
+	current, err := common.NormalizeHex(currentBlock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize currentBlock %d to hex: %w", currentBlock, err)
+	}
+	highest, err := common.NormalizeHex(highestBlock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize highestBlock %d to hex: %w", highestBlock, err)
+	}
+	return map[string]interface{}{
+		"startingBlock": current,
+		"currentBlock":  current,
+		"highestBlock":  highest,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package evm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSyncingTestUpstream(id string, latest int64, state common.EvmSyncingState) common.Upstream {
+	poller := common.NewFakeEvmStatePoller(latest, latest-10)
+	poller.SetSyncingState(state)
+	return common.NewFakeUpstream(id, common.WithEvmStatePoller(poller))
+}
+
+func newSyncingTestRequest() *common.NormalizedRequest {
+	return common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_syncing","params":[]}`))
+}
+
+func TestNetworkPreForward_EthSyncing(t *testing.T) {
+	network := &testNetwork{
+		cfg:           &common.NetworkConfig{Architecture: common.ArchitectureEvm, Evm: &common.EvmNetworkConfig{ChainId: 123}},
+		highestLatest: 1000,
+	}
+
+	t.Run("AnySyncedUpstreamReturnsFalse", func(t *testing.T) {
+		ups := []common.Upstream{
+			newSyncingTestUpstream("rpc1", 500, common.EvmSyncingStateSyncing),
+			newSyncingTestUpstream("rpc2", 1000, common.EvmSyncingStateNotSyncing),
+		}
+		handled, resp, err := HandleNetworkPreForward(context.Background(), network, ups, newSyncingTestRequest())
+		require.NoError(t, err)
+		require.True(t, handled)
+		jrr, err := resp.JsonRpcResponse()
+		require.NoError(t, err)
+		assert.Equal(t, "false", jrr.GetResultString())
+	})
+
+	t.Run("AllSyncingReturnsProgress", func(t *testing.T) {
+		ups := []common.Upstream{
+			newSyncingTestUpstream("rpc1", 500, common.EvmSyncingStateSyncing),
+			newSyncingTestUpstream("rpc2", 800, common.EvmSyncingStateSyncing),
+		}
+		handled, resp, err := HandleNetworkPreForward(context.Background(), network, ups, newSyncingTestRequest())
+		require.NoError(t, err)
+		require.True(t, handled)
+		jrr, err := resp.JsonRpcResponse()
+		require.NoError(t, err)
+		var progress map[string]string
+		require.NoError(t, common.SonicCfg.Unmarshal(jrr.GetResultBytes(), &progress))
+		assert.Equal(t, "0x320", progress["currentBlock"])
+		assert.Equal(t, "0x3e8", progress["highestBlock"])
+	})
+
+	t.Run("UnknownStatesFallThrough", func(t *testing.T) {
+		ups := []common.Upstream{
+			newSyncingTestUpstream("rpc1", 500, common.EvmSyncingStateUnknown),
+		}
+		handled, resp, err := HandleNetworkPreForward(context.Background(), network, ups, newSyncingTestRequest())
+		require.NoError(t, err)
+		assert.False(t, handled)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("SkipCacheReadFallsThrough", func(t *testing.T) {
+		ups := []common.Upstream{
+			newSyncingTestUpstream("rpc1", 1000, common.EvmSyncingStateNotSyncing),
+		}
+		req := newSyncingTestRequest()
+		req.SetDirectives(&common.RequestDirectives{SkipCacheRead: "true"})
+		handled, _, err := HandleNetworkPreForward(context.Background(), network, ups, req)
+		require.NoError(t, err)
+		assert.False(t, handled)
+	})
+}
@@ -202,14 +202,18 @@ func (e *EvmStatePoller) Bootstrap(ctx context.Context) error {
 	e.Enabled = true
 
 	go (func() {
-		ticker := time.NewTicker(interval.Duration())
+		ticker := util.NewTicker(interval.Duration())
 		defer ticker.Stop()
 		for {
 			select {
 			case <-e.appCtx.Done():
 				e.logger.Debug().Msg("shutting down evm state poller due to app context interruption")
 				return
-			case <-ticker.C:
+			case <-ticker.C():
+				// Retired upstreams are out of health probing until reinstated.
+				if r, ok := e.upstream.(interface{ Retired() bool }); ok && r.Retired() {
+					continue
+				}
 				// Calculate timeout based on shared state config:
 				// 1. Wait for distributed lock (up to lockTtl)
 				// 2. Buffer for operations (fetch block, update remote)
@@ -445,7 +449,7 @@ func (e *EvmStatePoller) PollLatestBlockNumber(ctx context.Context) (int64, erro
 	defer span.End()
 
 	return e.latestBlockShared.TryUpdateIfStale(ctx, dbi, func(ctx context.Context) (int64, error) {
-		if e.logger.GetLevel() <= zerolog.TraceLevel {
+		if common.LogLevelEnabled(e.logger, zerolog.TraceLevel) {
 			e.logger.Trace().Str("ptr", fmt.Sprintf("%p", e)).Str("stack", string(debug.Stack())).Msg("fetching latest block number for evm state poller")
 		}
 		telemetry.MetricUpstreamLatestBlockPolled.WithLabelValues(
@@ -1023,13 +1027,13 @@ func (e *EvmStatePoller) initializeEarliestBlockDetectionAndStartScheduler(ctx c
 // the earliest available block for a given probe. Uses TryUpdateIfStale for cross-instance
 // coordination so only one container instance performs the binary search at a time.
 func (e *EvmStatePoller) runPeriodicEarliestBlockBoundUpdateLoop(probe common.EvmAvailabilityProbeType, rate time.Duration) {
-	ticker := time.NewTicker(rate)
+	ticker := util.NewTicker(rate)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-e.appCtx.Done():
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			// Use PollEarliestBlockNumber with rate as staleness
 			// TryUpdateIfStale handles cross-instance coordination
 			_, err := e.PollEarliestBlockNumber(e.appCtx, probe, rate)
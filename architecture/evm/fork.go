@@ -0,0 +1,40 @@
+package evm
+
+import (
+	"strconv"
+
+	"github.com/erpc/erpc/common"
+)
+
+// IsPreForkBlock reports whether a block reference names a concrete block at
+// or below the fork block, i.e. canonical chain data shared with the live
+// chain. Tags and block hashes are never pre-fork: on a fork they resolve
+// against the fork's own chain.
+func IsPreForkBlock(fork *common.EvmForkConfig, blockRef string, blockNumber int64) bool {
+	if fork == nil || blockNumber <= 0 || blockNumber > fork.BlockNumber {
+		return false
+	}
+	_, err := strconv.ParseInt(blockRef, 10, 64)
+	return err == nil
+}
+
+// cacheNetworkKey returns the network part of a request's cache keys. On a
+// forked network, entries that may hold forked state are namespaced by the
+// fork instance; pre-fork blocks keep the plain network id so they are shared
+// with the live network's cache.
+func cacheNetworkKey(req *common.NormalizedRequest, blockRef string) string {
+	ntwId := req.NetworkId()
+	ntw := req.Network()
+	if ntw == nil {
+		return ntwId
+	}
+	cfg := ntw.Config()
+	if cfg == nil || cfg.Evm == nil || cfg.Evm.Fork == nil {
+		return ntwId
+	}
+	fork := cfg.Evm.Fork
+	if bn, _ := strconv.ParseInt(blockRef, 10, 64); IsPreForkBlock(fork, blockRef, bn) {
+		return ntwId
+	}
+	return ntwId + "@fork-" + fork.InstanceId
+}
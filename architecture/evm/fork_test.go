@@ -0,0 +1,41 @@
+package evm
+
+import (
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPreForkBlock(t *testing.T) {
+	fork := &common.EvmForkConfig{BlockNumber: 1000}
+
+	assert.True(t, IsPreForkBlock(fork, "999", 999))
+	assert.True(t, IsPreForkBlock(fork, "1000", 1000), "the fork block itself is canonical")
+	assert.False(t, IsPreForkBlock(fork, "1001", 1001))
+	assert.False(t, IsPreForkBlock(fork, "latest", 900), "tags resolve against the fork's chain")
+	assert.False(t, IsPreForkBlock(fork, "*", 900), "block hashes may name forked blocks")
+	assert.False(t, IsPreForkBlock(nil, "999", 999))
+}
+
+func TestCacheNetworkKey_Fork(t *testing.T) {
+	forked := &testNetwork{cfg: &common.NetworkConfig{
+		Architecture: common.ArchitectureEvm,
+		Evm:          &common.EvmNetworkConfig{Fork: &common.EvmForkConfig{BlockNumber: 1000, InstanceId: "staging"}},
+	}}
+	req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x3e7",false],"id":1}`))
+	req.SetNetwork(forked)
+
+	assert.Equal(t, "test-network", cacheNetworkKey(req, "999"), "pre-fork blocks share the live network's entries")
+	assert.Equal(t, "test-network@fork-staging", cacheNetworkKey(req, "1001"))
+	assert.Equal(t, "test-network@fork-staging", cacheNetworkKey(req, "latest"))
+	assert.Equal(t, "test-network@fork-staging", cacheNetworkKey(req, "*"))
+
+	pk, _, err := generateKeysForJsonRpcRequest(req, "1001")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-network@fork-staging:1001", pk)
+
+	live := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x3e9",false],"id":1}`))
+	live.SetNetwork(&testNetwork{})
+	assert.Equal(t, "test-network", cacheNetworkKey(live, "1001"), "non-forked networks are unaffected")
+}
@@ -28,6 +28,20 @@ func HandleProjectPreForward(ctx context.Context, network common.Network, nq *co
 		return projectPreForward_eth_getLogs(ctx, network, nq)
 	case "trace_filter", "arbtrace_filter":
 		return projectPreForward_trace_filter(ctx, network, nq)
+	case "erpc_getdecodedlogs":
+		return projectPreForward_erpc_getDecodedLogs(ctx, network, nq)
+	case "erpc_getdecodedfilterchanges":
+		return projectPreForward_erpc_getDecodedFilterChanges(ctx, network, nq)
+	case "erpc_getdecodedfilterlogs":
+		return projectPreForward_erpc_getDecodedFilterLogs(ctx, network, nq)
+	case "erpc_addressactivity":
+		return projectPreForward_erpc_addressActivity(ctx, network, nq)
+	case "erpc_getaddressactivity":
+		return projectPreForward_erpc_getAddressActivity(ctx, network, nq)
+	case "erpc_uninstalladdressactivity":
+		return projectPreForward_erpc_uninstallAddressActivity(ctx, network, nq)
+	case "erpc_getblockbytimestamp":
+		return projectPreForward_erpc_getBlockByTimestamp(ctx, network, nq)
 	default:
 		return false, nil, nil
 	}
@@ -49,6 +63,8 @@ func HandleNetworkPreForward(ctx context.Context, network common.Network, upstre
 		return networkPreForward_eth_getLogs(ctx, network, upstreams, nq)
 	case "eth_chainid":
 		return networkPreForward_eth_chainId(ctx, network, upstreams, nq)
+	case "eth_syncing":
+		return networkPreForward_eth_syncing(ctx, network, upstreams, nq)
 	case "trace_filter", "arbtrace_filter":
 		return networkPreForward_trace_filter(ctx, network, upstreams, nq)
 	default:
@@ -78,6 +94,8 @@ func HandleNetworkPostForward(ctx context.Context, network common.Network, nq *c
 		return networkPostForward_eth_sendRawTransaction(ctx, network, nq, nr, re)
 	case "trace_filter", "arbtrace_filter":
 		return networkPostForward_trace_filter(ctx, network, nq, nr, re)
+	case "eth_call", "eth_estimategas":
+		return networkPostForward_decodeRevert(ctx, network, nq, nr, re)
 	default:
 		return nr, re
 	}
@@ -192,6 +210,12 @@ func HandleUpstreamPostForward(ctx context.Context, n common.Network, u common.U
 		return rs, validationErr
 	}
 
+	// Canonicalize client-specific encodings before the response reaches
+	// consensus and the cache, so both see byte-stable results.
+	if shouldNormalizeResponses(n) {
+		return upstreamPostForward_normalizeResponse(ctx, u, rq, rs, methodLower)
+	}
+
 	return rs, re
 }
 
@@ -159,6 +159,26 @@ func NormalizeHttpJsonRpc(ctx context.Context, nrq *common.NormalizedRequest, jr
 	}
 	var changes []change
 
+	// Block tag policy: user-supplied "latest" is rewritten to "safe"/"finalized"
+	// before interpolation, so the policy tag is what gets resolved, cached and
+	// forwarded. Applied even when interpolation is skipped — it is a policy.
+	// Emulated tags are rewritten the same way, as the nodes cannot serve them.
+	var tagRewrites []change
+	rewriteLatestTo := ""
+	// Tag emulation: "finalized"/"safe" become the block number the network
+	// emulates them at, for nodes that do not support the tags. The
+	// checkpoint method itself is left alone so reading it cannot recurse.
+	var tagEmulation *common.EvmTagEmulationConfig
+	if network != nil {
+		if ncfg := network.Config(); ncfg != nil && ncfg.Evm != nil {
+			rewriteLatestTo = ncfg.Evm.BlockTagPolicy.RewriteLatestFor(method)
+			tagEmulation = ncfg.Evm.TagEmulation
+			if tagEmulation != nil && tagEmulation.Checkpoint != nil && tagEmulation.Checkpoint.Method == method {
+				tagEmulation = nil
+			}
+		}
+	}
+
 	for _, p := range paramsToProcess {
 		var (
 			newVal  interface{}
@@ -174,6 +194,23 @@ func NormalizeHttpJsonRpc(ctx context.Context, nrq *common.NormalizedRequest, jr
 			}
 		}
 
+		if rewriteLatestTo != "" && !isTopLevelParamOfMap {
+			if sv, ok := p.value.(string); ok && sv == "latest" {
+				p.value = rewriteLatestTo
+				tagRewrites = append(tagRewrites, change{path: p.path, newVal: rewriteLatestTo})
+			}
+		}
+		if tagEmulation != nil && !isTopLevelParamOfMap {
+			if sv, ok := p.value.(string); ok && tagEmulation.Emulates(sv) {
+				if bn, ok := emulatedBlockTag(ctx, network, reqId, sv); ok {
+					if hx, err := common.NormalizeHex(bn); err == nil {
+						p.value = hx
+						tagRewrites = append(tagRewrites, change{path: p.path, newVal: hx})
+					}
+				}
+			}
+		}
+
 		// Use composite parser to distinguish block numbers, tags, and hashes.
 		blockRef, blockNum, err := parseCompositeBlockParam(p.value)
 		if err == nil {
@@ -304,16 +341,24 @@ func NormalizeHttpJsonRpc(ctx context.Context, nrq *common.NormalizedRequest, jr
 	}
 
 	// Apply changes
-	if needsUpdate && !skipInterpolation {
+	applyInterpolation := needsUpdate && !skipInterpolation
+	if applyInterpolation || len(tagRewrites) > 0 {
 		// Deep copy snapshot only if we actually need to mutate
 		jrq.RLock()
 		workingParams := deepCopyParams(jrq.Params)
 		jrq.RUnlock()
-		for _, ch := range changes {
+		for _, ch := range tagRewrites {
 			if np, ok := replaceParamAtPath(workingParams, ch.path, ch.newVal); ok {
 				workingParams = np
 			}
 		}
+		if applyInterpolation {
+			for _, ch := range changes {
+				if np, ok := replaceParamAtPath(workingParams, ch.path, ch.newVal); ok {
+					workingParams = np
+				}
+			}
+		}
 		jrq.Lock()
 		jrq.Params = workingParams
 		jrq.Unlock()
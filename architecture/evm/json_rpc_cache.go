@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"strings"
 	"sync"
 	"time"
@@ -30,18 +31,58 @@ type EvmJsonRpcCache struct {
 	compressionLevel     zstd.EncoderLevel
 	encoderPool          *sync.Pool
 	decoderPool          *sync.Pool
+
+	// integrityEnabled seals new values with a checksum; sealed values are
+	// verified on read regardless of this flag.
+	integrityEnabled bool
+
+	// recordsEnabled wraps new values in a record envelope and writes them
+	// through data.SetGuarded; record envelopes are read regardless.
+	recordsEnabled bool
+
+	// keyHashAlgorithm derives range keys; collisionAudit stores each entry's
+	// key material and rejects entries whose material differs on read.
+	keyHashAlgorithm common.CacheKeyHashAlgorithm
+	collisionAudit   bool
+
+	// getTimeout bounds a lookup on the request path (capped by the request's
+	// own deadline); setTimeout bounds a background write.
+	getTimeout time.Duration
+	setTimeout time.Duration
+
+	// hitRates feeds cache.hitRateReport; nil when the report is off.
+	hitRates *cacheHitRateReporter
+
+	// errorResults holds the TTLs of cache.errorResults; nil when deterministic
+	// errors are not cached.
+	errorResults *common.CacheErrorResultsConfig
+
+	// crossPopulate holds cache.crossPopulate; nil when no entries are
+	// derived from block responses.
+	crossPopulate *common.CacheCrossPopulateConfig
+
+	// appCtx bounds background revalidations; revalidating holds the keys of
+	// those in flight, shared by the per-project copies.
+	appCtx       context.Context
+	revalidating *sync.Map
 }
 
 const (
 	JsonRpcCacheContext common.ContextKey = "jsonRpcCache"
 )
 
+const (
+	defaultCacheGetTimeout = 30 * time.Second
+	defaultCacheSetTimeout = 10 * time.Second
+)
+
 func NewEvmJsonRpcCache(ctx context.Context, logger *zerolog.Logger, cfg *common.CacheConfig) (*EvmJsonRpcCache, error) {
 	logger.Info().Msg("initializing evm json rpc cache...")
 
 	// Create connectors map
 	connectors := make(map[string]data.Connector)
 	connectorTags := make(map[string][]string)
+	connectorResidency := make(map[string]string)
 	for _, connCfg := range cfg.Connectors {
 		c, err := data.NewConnector(ctx, logger, connCfg)
 		if err != nil {
@@ -49,6 +90,7 @@ func NewEvmJsonRpcCache(ctx context.Context, logger *zerolog.Logger, cfg *common
 		}
 		connectors[connCfg.Id] = c
 		connectorTags[connCfg.Id] = connCfg.Tags
+		connectorResidency[connCfg.Id] = connCfg.Residency
 	}
 
 	// Create policies
@@ -65,12 +107,37 @@ func NewEvmJsonRpcCache(ctx context.Context, logger *zerolog.Logger, cfg *common
 		}
 		// Connector tags drive use-upstream gating of this policy's cache.
 		policy.SetConnectorTags(connectorTags[policyCfg.Connector])
+		policy.SetConnectorResidency(connectorResidency[policyCfg.Connector])
 		policies = append(policies, policy)
 	}
 
 	cache := &EvmJsonRpcCache{
-		policies: policies,
-		logger:   logger,
+		policies:     policies,
+		logger:       logger,
+		getTimeout:   cfg.GetTimeout.Duration(),
+		setTimeout:   cfg.SetTimeout.Duration(),
+		appCtx:       ctx,
+		revalidating: &sync.Map{},
+	}
+
+	if cfg.Integrity != nil && cfg.Integrity.Enabled != nil && *cfg.Integrity.Enabled {
+		cache.integrityEnabled = true
+	}
+	if cfg.Records != nil && cfg.Records.Enabled != nil && *cfg.Records.Enabled {
+		cache.recordsEnabled = true
+	}
+
+	if cfg.KeyHash != nil {
+		cache.keyHashAlgorithm = cfg.KeyHash.Algorithm
+		cache.collisionAudit = cfg.KeyHash.CollisionAudit != nil && *cfg.KeyHash.CollisionAudit
+	}
+
+	cache.errorResults = cfg.ErrorResults
+	cache.crossPopulate = cfg.CrossPopulate
+
+	if cfg.HitRateReport != nil && cfg.HitRateReport.Interval > 0 {
+		cache.hitRates = newCacheHitRateReporter(logger, cfg.HitRateReport)
+		cache.hitRates.start(ctx)
 	}
 
 	// Initialize compression if configured
@@ -146,13 +213,69 @@ func (c *EvmJsonRpcCache) WithProjectId(projectId string) *EvmJsonRpcCache {
 		compressionLevel:     c.compressionLevel,
 		encoderPool:          c.encoderPool,
 		decoderPool:          c.decoderPool,
+		integrityEnabled:     c.integrityEnabled,
+		recordsEnabled:       c.recordsEnabled,
+		keyHashAlgorithm:     c.keyHashAlgorithm,
+		collisionAudit:       c.collisionAudit,
+		getTimeout:           c.getTimeout,
+		setTimeout:           c.setTimeout,
+		hitRates:             c.hitRates,
+		errorResults:         c.errorResults,
+		crossPopulate:        c.crossPopulate,
+		appCtx:               c.appCtx,
+		revalidating:         c.revalidating,
 	}
 }
 
+// WithResidency returns a copy of the cache that only uses the policies whose
+// connector has the given residency label, so a project bound to a region
+// never reads or writes a connector outside it. With no matching policy the
+// copy caches nothing.
+func (c *EvmJsonRpcCache) WithResidency(residency string) *EvmJsonRpcCache {
+	var policies []*data.CachePolicy
+	for _, p := range c.policies {
+		if p.ConnectorResidency() == residency {
+			policies = append(policies, p)
+		}
+	}
+	if len(policies) == 0 {
+		c.logger.Warn().Str("residency", residency).Msg("no cache policy uses a connector with the project's cache residency, caching is disabled for the project")
+	}
+	cp := *c
+	cp.policies = policies
+	return &cp
+}
+
 func (c *EvmJsonRpcCache) SetPolicies(policies []*data.CachePolicy) {
 	c.policies = policies
 }
 
+// Connectors returns the distinct connectors used by the cache policies, in
+// policy order.
+func (c *EvmJsonRpcCache) Connectors() []data.Connector {
+	var connectors []data.Connector
+	seen := make(map[data.Connector]bool)
+	for _, policy := range c.policies {
+		if conn := policy.GetConnector(); conn != nil && !seen[conn] {
+			seen[conn] = true
+			connectors = append(connectors, conn)
+		}
+	}
+	return connectors
+}
+
+// PoliciesForNetwork returns the policies whose network pattern matches
+// networkId, in configuration order.
+func (c *EvmJsonRpcCache) PoliciesForNetwork(networkId string) []*data.CachePolicy {
+	var out []*data.CachePolicy
+	for _, p := range c.policies {
+		if match, err := common.WildcardMatch(p.Config().Network, networkId); err == nil && match {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
 // observeGetLogsRange records the concrete block-range size of an eth_getLogs
 // request into MetricCacheEvmGetLogsRange, tagged by the connector/policy/ttl
 // involved and the hit/miss outcome. It is a no-op for non-getLogs methods and
@@ -175,6 +298,32 @@ func (c *EvmJsonRpcCache) observeGetLogsRange(ctx context.Context, req *common.N
 	).Observe(rangeSize)
 }
 
+// ReadLatencyBudget returns how long a caller should wait on Get before also
+// forwarding the request upstream. Connectors are read in parallel, so this is
+// the largest maxReadLatency among the policies Get would read; it is 0 (wait
+// for Get) when any of them sets none.
+func (c *EvmJsonRpcCache) ReadLatencyBudget(ctx context.Context, req *common.NormalizedRequest) time.Duration {
+	rpcReq, err := req.JsonRpcRequest(ctx)
+	if err != nil {
+		return 0
+	}
+	policies, err := c.findGetPolicies(req.NetworkId(), rpcReq.Method, rpcReq.Params, req.Finality(ctx))
+	if err != nil {
+		return 0
+	}
+	var budget time.Duration
+	for _, p := range policies {
+		d := p.Config().MaxReadLatency.Duration()
+		if d <= 0 {
+			return 0
+		}
+		if d > budget {
+			budget = d
+		}
+	}
+	return budget
+}
+
 func (c *EvmJsonRpcCache) Get(ctx context.Context, req *common.NormalizedRequest) (*common.NormalizedResponse, error) {
 	ctx, span := common.StartSpan(ctx, "Cache.Get",
 		trace.WithAttributes(
@@ -230,6 +379,7 @@ func (c *EvmJsonRpcCache) Get(ctx context.Context, req *common.NormalizedRequest
 	// a miss (or errors/rejects), the request falls through to the upstream layer.
 	type fanResult struct {
 		jrr        *common.JsonRpcResponse
+		meta       cachedEntryMeta
 		policy     *data.CachePolicy
 		connector  data.Connector
 		err        error
@@ -239,16 +389,16 @@ func (c *EvmJsonRpcCache) Get(ctx context.Context, req *common.NormalizedRequest
 	fanCtx, cancelFan := context.WithCancel(ctx)
 	defer cancelFan()
 
-	// Defensive backstop: if the caller's context has no deadline and a
-	// connector lacks a failsafe timeout, a hung connector could pin the
-	// fan-out goroutine indefinitely — over time, FDs/connection-pool slots
-	// leak per request. Cap the fan-out at 30s. Properly configured
-	// connectors exit far earlier via their own failsafe timeout.
-	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
-		var bsCancel context.CancelFunc
-		fanCtx, bsCancel = context.WithTimeout(fanCtx, 30*time.Second)
-		defer bsCancel()
+	// Cap the fan-out at the cache's read budget. WithTimeout keeps the
+	// caller's deadline when it is sooner, so a lookup never outlives the
+	// request; without a caller deadline this also stops a hung connector
+	// from pinning the fan-out goroutine (and its FDs/pool slots) forever.
+	getTimeout := c.getTimeout
+	if getTimeout <= 0 {
+		getTimeout = defaultCacheGetTimeout
 	}
+	fanCtx, bsCancel := context.WithTimeoutCause(fanCtx, getTimeout, fmt.Errorf("evm json-rpc cache getTimeout of %s exceeded", getTimeout))
+	defer bsCancel()
 
 	// Buffer sized to the worst-case spawn count so late peers (after we've
 	// already taken a winner) can post their result without blocking — we
@@ -276,7 +426,7 @@ func (c *EvmJsonRpcCache) Get(ctx context.Context, req *common.NormalizedRequest
 			))
 			defer policySpan.End()
 
-			jrr, err := c.doGet(policyCtx, connector, req, rpcReq)
+			jrr, meta, err := c.doGet(policyCtx, connector, req, rpcReq)
 			// Unconditional cancellation guard — runs regardless of whether
 			// doGet returned an error. fanCtx is done either because a peer
 			// connector already won (cancelFan), the caller's context was
@@ -317,6 +467,17 @@ func (c *EvmJsonRpcCache) Get(ctx context.Context, req *common.NormalizedRequest
 					}
 					return
 				}
+				if common.HasErrorCode(err, common.ErrCodeFailsafeCircuitBreakerOpen) {
+					// The connector's breaker is open: it was bypassed without
+					// reaching the backend, which erpc_connector_breaker_*
+					// already reports.
+					policySpan.SetAttributes(attribute.String("cache.get_outcome", "bypassed"))
+					select {
+					case results <- fanResult{policy: policy, connector: connector, err: err, missReason: "connector_error"}:
+					case <-fanCtx.Done():
+					}
+					return
+				}
 				common.SetTraceSpanError(policySpan, err)
 				policySpan.SetAttributes(
 					attribute.String("cache.get_outcome", "error"),
@@ -340,7 +501,7 @@ func (c *EvmJsonRpcCache) Get(ctx context.Context, req *common.NormalizedRequest
 					policy.GetTTL().String(),
 					common.ErrorSummary(err),
 				).Observe(time.Since(start).Seconds())
-				if c.logger.GetLevel() <= zerolog.DebugLevel {
+				if common.LogLevelEnabled(c.logger, zerolog.DebugLevel) {
 					c.logger.Debug().Str("connector", connector.Id()).Interface("id", req.ID()).Err(err).Msg("cache connector errored during GET")
 				}
 				select {
@@ -357,7 +518,23 @@ func (c *EvmJsonRpcCache) Get(ctx context.Context, req *common.NormalizedRequest
 				}
 				return
 			}
-			if !c.shouldAcceptCachedResult(ctx, req, jrr, policy) {
+			if jrr.Error == nil && negativeInvalidated(policyCtx, req, policy, meta.headBlock) {
+				// An emptyish result from before the latest block: the block
+				// may have produced the data it lacked.
+				telemetry.MetricCacheGetEmptyInvalidatedTotal.WithLabelValues(
+					c.projectId,
+					req.NetworkLabel(),
+					rpcReq.Method,
+					connector.Id(),
+				).Inc()
+				policySpan.SetAttributes(attribute.String("cache.get_outcome", "empty_invalidated"))
+				select {
+				case results <- fanResult{policy: policy, connector: connector, missReason: "empty_result"}:
+				case <-fanCtx.Done():
+				}
+				return
+			}
+			if jrr.Error == nil && !c.shouldAcceptCachedResult(ctx, req, jrr, policy) {
 				c.logger.Debug().Str("connector", connector.Id()).Interface("id", req.ID()).Msg("cached result rejected due to age exceeding TTL")
 				policySpan.SetAttributes(attribute.String("cache.get_outcome", "ttl_rejected"))
 				select {
@@ -372,7 +549,7 @@ func (c *EvmJsonRpcCache) Get(ctx context.Context, req *common.NormalizedRequest
 			// cancel peers, and only THEN get reclassified as a miss by the
 			// post-fan-out emptyish handling — losing the chance for a peer
 			// with non-empty data or Allow policy to serve a real hit.
-			if jrr.IsResultEmptyish() && policy.EmptyState() == common.CacheEmptyBehaviorIgnore {
+			if jrr.Error == nil && jrr.IsResultEmptyish() && policy.EmptyState() == common.CacheEmptyBehaviorIgnore {
 				policySpan.SetAttributes(attribute.String("cache.get_outcome", "empty_ignored"))
 				select {
 				case results <- fanResult{policy: policy, connector: connector, missReason: "empty_result"}:
@@ -382,7 +559,7 @@ func (c *EvmJsonRpcCache) Get(ctx context.Context, req *common.NormalizedRequest
 			}
 			policySpan.SetAttributes(attribute.String("cache.get_outcome", "found"))
 			select {
-			case results <- fanResult{jrr: jrr, policy: policy, connector: connector}:
+			case results <- fanResult{jrr: jrr, meta: meta, policy: policy, connector: connector}:
 				cancelFan()
 			case <-fanCtx.Done():
 			}
@@ -396,6 +573,7 @@ func (c *EvmJsonRpcCache) Get(ctx context.Context, req *common.NormalizedRequest
 	// user-visible latency of a fast winner.
 	var (
 		jrr        *common.JsonRpcResponse
+		meta       cachedEntryMeta
 		policy     *data.CachePolicy
 		connector  data.Connector
 		lastMiss   *fanResult
@@ -411,6 +589,7 @@ drain:
 			if r.jrr != nil {
 				rr := r
 				jrr = rr.jrr
+				meta = rr.meta
 				policy = rr.policy
 				connector = rr.connector
 				continue
@@ -458,6 +637,7 @@ drain:
 					if r.jrr != nil && jrr == nil {
 						rr := r
 						jrr = rr.jrr
+						meta = rr.meta
 						policy = rr.policy
 						connector = rr.connector
 					} else {
@@ -555,11 +735,12 @@ drain:
 			labelTTL,
 		).Observe(time.Since(start).Seconds())
 		c.observeGetLogsRange(ctx, req, rpcReq, labelConnectorId, labelPolicyStr, labelTTL, "miss")
+		c.hitRates.record(c.projectId, req.NetworkLabel(), rpcReq.Method, false)
 		span.SetAttributes(attribute.Bool("cache.hit", false))
 		return nil, nil
 	}
 
-	if jrr.IsResultEmptyish() {
+	if jrr.Error == nil && jrr.IsResultEmptyish() {
 		switch policy.EmptyState() {
 		case common.CacheEmptyBehaviorIgnore:
 			// Treat as cache miss - return nil to indicate no cached data
@@ -580,6 +761,7 @@ drain:
 				policy.GetTTL().String(),
 			).Observe(time.Since(start).Seconds())
 			c.observeGetLogsRange(ctx, req, rpcReq, connector.Id(), policy.String(), policy.GetTTL().String(), "miss")
+			c.hitRates.record(c.projectId, req.NetworkLabel(), rpcReq.Method, false)
 			span.SetAttributes(attribute.Bool("cache.hit", false))
 			return nil, nil
 		case common.CacheEmptyBehaviorAllow, common.CacheEmptyBehaviorOnly:
@@ -592,6 +774,11 @@ drain:
 		WithRequest(req).
 		WithFromCache(true).
 		WithJsonRpcResponse(jrr)
+	if meta.record != nil {
+		// The record knows the finality of the data as of when it was
+		// fetched, which the request alone cannot tell for a by-hash lookup.
+		resp.WithFinality(meta.record.finality)
+	}
 
 	telemetry.MetricCacheGetSuccessHitTotal.WithLabelValues(
 		c.projectId,
@@ -610,8 +797,13 @@ drain:
 		policy.GetTTL().String(),
 	).Observe(time.Since(start).Seconds())
 	c.observeGetLogsRange(ctx, req, rpcReq, connector.Id(), policy.String(), policy.GetTTL().String(), "hit")
+	c.hitRates.record(c.projectId, req.NetworkLabel(), rpcReq.Method, true)
 	span.SetAttributes(attribute.Bool("cache.hit", true))
-	if c.logger.GetLevel() <= zerolog.DebugLevel {
+	if jrr.Error == nil && shouldRevalidate(policy, meta.expiresAt) {
+		span.SetAttributes(attribute.Bool("cache.revalidate", true))
+		c.revalidate(req, rpcReq, connector)
+	}
+	if common.LogLevelEnabled(c.logger, zerolog.DebugLevel) {
 		result := jrr.GetResultBytes()
 		if common.IsSemiValidJson(result) {
 			c.logger.Trace().Str("method", rpcReq.Method).Interface("id", req.ID()).RawJSON("result", result).Msg("returning cached response")
@@ -626,6 +818,18 @@ drain:
 }
 
 func (c *EvmJsonRpcCache) Set(ctx context.Context, req *common.NormalizedRequest, resp *common.NormalizedResponse) error {
+	if err := c.set(ctx, req, resp, nil); err != nil {
+		return err
+	}
+	if c.crossPopulate != nil {
+		c.setDerived(ctx, req, resp)
+	}
+	return nil
+}
+
+// set stores resp under every matching policy. With a batch, the prepared
+// entries are queued for a later flush instead of written right away.
+func (c *EvmJsonRpcCache) set(ctx context.Context, req *common.NormalizedRequest, resp *common.NormalizedResponse, batch *cacheWriteBatch) error {
 	upsId := "n/a"
 	if resp != nil && resp.Upstream() != nil {
 		upsId = resp.Upstream().Id()
@@ -641,6 +845,15 @@ func (c *EvmJsonRpcCache) Set(ctx context.Context, req *common.NormalizedRequest
 		)
 	}
 
+	// Writes run in the background after the response is sent, so they get their
+	// own budget rather than the (usually already finished) request's deadline.
+	setTimeout := c.setTimeout
+	if setTimeout <= 0 {
+		setTimeout = defaultCacheSetTimeout
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, setTimeout, fmt.Errorf("evm json-rpc cache setTimeout of %s exceeded", setTimeout))
+	defer cancel()
+
 	// TODO after subscription epic this method can be called for every new block data to pre-populate the cache,
 	// based on the evmJsonRpcCache.hyrdation.filters which is only the data (logs, txs) that user cares about.
 	start := time.Now()
@@ -702,7 +915,7 @@ func (c *EvmJsonRpcCache) Set(ctx context.Context, req *common.NormalizedRequest
 
 	if blockRef == "" {
 		// Do not cache if we can't resolve a block reference (e.g. unknown methods)
-		if lg.GetLevel() <= zerolog.TraceLevel {
+		if common.LogLevelEnabled(&lg, zerolog.TraceLevel) {
 			lg.Trace().
 				Object("request", req).
 				Str("blockRef", blockRef).
@@ -718,13 +931,20 @@ func (c *EvmJsonRpcCache) Set(ctx context.Context, req *common.NormalizedRequest
 		return nil
 	}
 
-	pk, rk, err := generateKeysForJsonRpcRequest(req, blockRef, ctx)
+	pk, rk, err := c.generateKeys(req, rpcReq, blockRef, ctx)
 	if err != nil {
 		common.SetTraceSpanError(span, err)
 		return err
 	}
+	var keyMaterial []byte
+	if c.collisionAudit {
+		if keyMaterial, err = rpcReq.CacheKeyMaterial(); err != nil {
+			common.SetTraceSpanError(span, err)
+			return err
+		}
+	}
 
-	if lg.GetLevel() <= zerolog.TraceLevel {
+	if common.LogLevelEnabled(&lg, zerolog.TraceLevel) {
 		lg.Trace().
 			Str("blockRef", blockRef).
 			Str("primaryKey", pk).
@@ -770,6 +990,11 @@ func (c *EvmJsonRpcCache) Set(ctx context.Context, req *common.NormalizedRequest
 			if resolved := policy.ResolveTTL(networkBlockTime(req), defaultRealtimeColdStartTTL); resolved > 0 {
 				storageTTL = &resolved
 			}
+			var headBlock int64
+			if emptyTtl := policy.EmptyTtl(); emptyTtl > 0 && isEmptyish {
+				storageTTL = &emptyTtl
+				headBlock = negativeHeadBlock(ctx, req, policy)
+			}
 
 			shouldCache, err := shouldCacheResponse(ctx, lg, resp, rpcResp, policy, finState)
 			if !shouldCache {
@@ -842,9 +1067,60 @@ func (c *EvmJsonRpcCache) Set(ctx context.Context, req *common.NormalizedRequest
 				}
 			}
 
-			ctx, cancel := context.WithTimeoutCause(ctx, 5*time.Second, errors.New("evm json-rpc cache driver timeout during set"))
-			defer cancel()
-			err = connector.Set(ctx, pk, rk, valueToStore, storageTTL)
+			if c.collisionAudit {
+				valueToStore = wrapAuditedValue(keyMaterial, valueToStore)
+			}
+			if headBlock > 0 {
+				valueToStore = wrapHeadStampedValue(headBlock, valueToStore)
+			}
+			if policy.StaleWhileRevalidate() > 0 && storageTTL != nil && *storageTTL > 0 {
+				valueToStore = wrapStampedValue(time.Now().Add(*storageTTL), valueToStore)
+			}
+			if c.recordsEnabled {
+				valueToStore = wrapRecordValue(cacheRecord{blockNumber: blockNumber, finality: finState, upstreamId: resp.UpstreamId()}, valueToStore)
+			}
+			if c.integrityEnabled {
+				valueToStore = sealCacheValue(valueToStore)
+			}
+
+			// Conditional writes cost a round trip each, so records are not
+			// batched.
+			if c.recordsEnabled {
+				var stored bool
+				stored, err = data.SetGuarded(ctx, connector, pk, rk, valueToStore, data.CacheRecordGuard(finState, blockNumber), storageTTL)
+				if err == nil && !stored {
+					lg.Debug().Str("connector", connector.Id()).Int64("blockNumber", blockNumber).Str("finalityState", finState.String()).Msg("cache write rejected, stored entry is derived from a later finalized block")
+					telemetry.MetricCacheSetDowngradeRejectedTotal.WithLabelValues(
+						c.projectId,
+						req.NetworkLabel(),
+						rpcReq.Method,
+						connector.Id(),
+						policy.String(),
+					).Inc()
+					return
+				}
+			} else if batch != nil {
+				batch.add(connector, storageTTL, data.KeyValuePair{PartitionKey: pk, RangeKey: rk, Value: valueToStore}, cacheWriteLabels{
+					network: req.NetworkLabel(),
+					method:  rpcReq.Method,
+					policy:  policy.String(),
+					ttl:     ttl.String(),
+				})
+				return
+			} else {
+				err = connector.Set(ctx, pk, rk, valueToStore, storageTTL)
+			}
+			if common.HasErrorCode(err, common.ErrCodeFailsafeCircuitBreakerOpen) {
+				telemetry.MetricCacheSetSkippedTotal.WithLabelValues(
+					c.projectId,
+					req.NetworkLabel(),
+					rpcReq.Method,
+					connector.Id(),
+					policy.String(),
+					ttl.String(),
+				).Inc()
+				return
+			}
 			if err != nil {
 				errsMu.Lock()
 				errs = append(errs, err)
@@ -914,11 +1190,41 @@ func (c *EvmJsonRpcCache) IsObjectNull() bool {
 // an unbounded-stale head.
 const defaultRealtimeColdStartTTL = 2 * time.Second
 
+// chainStateBlockAge estimates the age of a block named by a cached result that carries no
+// timestamp, using the head view the network's state pollers maintain: block N is as old as the
+// highest block H with a known timestamp plus (H-N) block times. Without a block time estimate a
+// block below H cannot be aged and is reported as older than any TTL. Returns false when the
+// network has no such head or the result is newer than it.
+func chainStateBlockAge(ctx context.Context, req *common.NormalizedRequest, nr *common.NormalizedResponse, now int64) (time.Duration, bool) {
+	csp, ok := req.Network().(common.EvmChainStateProvider)
+	if !ok {
+		return 0, false
+	}
+	_, blockNumber, err := ExtractBlockReferenceFromResponse(ctx, nr)
+	if err != nil || blockNumber <= 0 {
+		return 0, false
+	}
+	cs := csp.EvmChainState(ctx)
+	if cs.LatestBlockTimestamp <= 0 || blockNumber > cs.LatestBlockTimestampBlock {
+		return 0, false
+	}
+	age := time.Duration(now-cs.LatestBlockTimestamp) * time.Second
+	if behind := cs.LatestBlockTimestampBlock - blockNumber; behind > 0 {
+		if cs.BlockTime <= 0 || behind > int64(math.MaxInt64/cs.BlockTime) {
+			return time.Duration(math.MaxInt64), true
+		}
+		age += time.Duration(behind) * cs.BlockTime
+	}
+	return age, true
+}
+
 // shouldAcceptCachedResult checks if a cached realtime result is still fresh enough to serve, by
 // comparing a block timestamp against the policy's TTL. The timestamp is taken from the response
 // when present; for responses that carry none (eth_blockNumber, eth_gasPrice, eth_getLogs) it falls
 // back to the serving connector's reported latest-block timestamp (read-through connectors that
-// implement data.CacheHeadReporter), so a lagging source is still caught for those methods.
+// implement data.CacheHeadReporter), so a lagging source is still caught for those methods. As a
+// last resort, responses that name a block (eth_blockNumber) are aged against the network's chain
+// state, see chainStateBlockAge.
 // Applies only to realtime finality — finalized/unfinalized/unknown block data is immutable and is
 // always accepted regardless of age.
 func (c *EvmJsonRpcCache) shouldAcceptCachedResult(
@@ -963,26 +1269,29 @@ func (c *EvmJsonRpcCache) shouldAcceptCachedResult(
 				blockTimestamp = ts
 			}
 		}
-		if blockTimestamp <= 0 {
-			// Still can't determine the age (connector not head-aware or head unknown), so accept.
-			if c.logger.GetLevel() <= zerolog.TraceLevel {
-				method, _ := req.Method()
-				c.logger.Trace().
-					Err(err).
-					Str("method", method).
-					Msg("cannot determine block timestamp for age validation, accepting cached result")
-			}
-			return true
-		}
 	}
 
-	// Calculate the age of the block
 	now := time.Now().Unix()
-	age := time.Duration(now-blockTimestamp) * time.Second
+	var age time.Duration
+	if blockTimestamp > 0 {
+		age = time.Duration(now-blockTimestamp) * time.Second
+	} else if chainAge, known := chainStateBlockAge(ctx, req, nr, now); known {
+		age = chainAge
+	} else {
+		// Still can't determine the age (no head-aware connector and no usable network head), so accept.
+		if common.LogLevelEnabled(c.logger, zerolog.TraceLevel) {
+			method, _ := req.Method()
+			c.logger.Trace().
+				Err(err).
+				Str("method", method).
+				Msg("cannot determine block timestamp for age validation, accepting cached result")
+		}
+		return true
+	}
 
 	// Check if the age exceeds the TTL
 	if age > effectiveTTL {
-		if c.logger.GetLevel() <= zerolog.DebugLevel {
+		if common.LogLevelEnabled(c.logger, zerolog.DebugLevel) {
 			c.logger.Debug().
 				Dur("age", age).
 				Dur("ttl", effectiveTTL).
@@ -1029,7 +1338,7 @@ func (c *EvmJsonRpcCache) findSetPolicies(networkId, method string, params []int
 	var policies []*data.CachePolicy
 	for _, policy := range c.policies {
 		// Add debug logging for complex param matching
-		if c.logger.GetLevel() <= zerolog.TraceLevel {
+		if common.LogLevelEnabled(c.logger, zerolog.TraceLevel) {
 			c.logger.Trace().
 				Str("networkId", networkId).
 				Str("method", method).
@@ -1055,7 +1364,7 @@ func (c *EvmJsonRpcCache) findGetPolicies(networkId, method string, params []int
 	visitedConnectorsMap := make(map[data.Connector]bool)
 	for _, policy := range c.policies {
 		// Add debug logging for complex param matching
-		if c.logger.GetLevel() <= zerolog.TraceLevel {
+		if common.LogLevelEnabled(c.logger, zerolog.TraceLevel) {
 			c.logger.Trace().
 				Str("networkId", networkId).
 				Str("method", method).
@@ -1079,13 +1388,13 @@ func (c *EvmJsonRpcCache) findGetPolicies(networkId, method string, params []int
 	return policies, nil
 }
 
-func (c *EvmJsonRpcCache) doGet(ctx context.Context, connector data.Connector, req *common.NormalizedRequest, rpcReq *common.JsonRpcRequest) (*common.JsonRpcResponse, error) {
+func (c *EvmJsonRpcCache) doGet(ctx context.Context, connector data.Connector, req *common.NormalizedRequest, rpcReq *common.JsonRpcRequest) (*common.JsonRpcResponse, cachedEntryMeta, error) {
 	rpcReq.RLockWithTrace(ctx)
 	defer rpcReq.RUnlock()
 
 	blockRef, _, err := ExtractBlockReferenceFromRequest(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, cachedEntryMeta{}, err
 	}
 	if blockRef == "" {
 		// Add trace attribute for empty blockRef so we know WHY cache was skipped
@@ -1094,12 +1403,12 @@ func (c *EvmJsonRpcCache) doGet(ctx context.Context, connector data.Connector, r
 			attribute.String("cache.skip_reason", "empty_block_ref"),
 			attribute.String("cache.method", rpcReq.Method),
 		)
-		return nil, nil
+		return nil, cachedEntryMeta{}, nil
 	}
 
-	groupKey, requestKey, err := generateKeysForJsonRpcRequest(req, blockRef, ctx)
+	groupKey, requestKey, err := c.generateKeys(req, rpcReq, blockRef, ctx)
 	if err != nil {
-		return nil, err
+		return nil, cachedEntryMeta{}, err
 	}
 
 	// Annotate the span with cache lookup details for debugging
@@ -1119,23 +1428,95 @@ func (c *EvmJsonRpcCache) doGet(ctx context.Context, connector data.Connector, r
 	}
 	if err != nil {
 		span.SetAttributes(attribute.String("cache.connector_error", common.ErrorSummary(err)))
-		return nil, err
+		return nil, cachedEntryMeta{}, err
 	}
 	if len(resultBytes) == 0 {
 		span.SetAttributes(attribute.String("cache.connector_result", "empty_bytes"))
-		return nil, nil
+		return nil, cachedEntryMeta{}, nil
 	}
 	span.SetAttributes(
 		attribute.String("cache.connector_result", "found"),
 		attribute.Int("cache.result_bytes", len(resultBytes)),
 	)
 
+	resultBytes, sealed, corruption := openCacheValue(resultBytes)
+	if corruption != "" {
+		c.handleCorruptedValue(req, rpcReq, connector, blockRef, groupKey, requestKey, corruption)
+		span.SetAttributes(attribute.String("cache.corruption", corruption))
+		return nil, cachedEntryMeta{}, nil
+	}
+
+	var meta cachedEntryMeta
+	resultBytes, record, recorded := unwrapRecordValue(resultBytes)
+	if recorded && resultBytes == nil {
+		c.handleCorruptedValue(req, rpcReq, connector, blockRef, groupKey, requestKey, cacheCorruptionTruncated)
+		span.SetAttributes(attribute.String("cache.corruption", cacheCorruptionTruncated))
+		return nil, cachedEntryMeta{}, nil
+	}
+	if recorded {
+		meta.record = &record
+		span.SetAttributes(
+			attribute.String("cache.record.finality", record.finality.String()),
+			attribute.Int64("cache.record.block_number", record.blockNumber),
+			attribute.String("cache.record.upstream_id", record.upstreamId),
+		)
+	}
+
+	var stamped, headStamped bool
+	resultBytes, meta.expiresAt, stamped = unwrapStampedValue(resultBytes)
+	if stamped && resultBytes == nil {
+		c.handleCorruptedValue(req, rpcReq, connector, blockRef, groupKey, requestKey, cacheCorruptionTruncated)
+		span.SetAttributes(attribute.String("cache.corruption", cacheCorruptionTruncated))
+		return nil, cachedEntryMeta{}, nil
+	}
+	resultBytes, meta.headBlock, headStamped = unwrapHeadStampedValue(resultBytes)
+	if headStamped && resultBytes == nil {
+		c.handleCorruptedValue(req, rpcReq, connector, blockRef, groupKey, requestKey, cacheCorruptionTruncated)
+		span.SetAttributes(attribute.String("cache.corruption", cacheCorruptionTruncated))
+		return nil, cachedEntryMeta{}, nil
+	}
+
+	resultBytes, storedMaterial, audited := unwrapAuditedValue(resultBytes)
+	if audited && resultBytes == nil {
+		c.handleCorruptedValue(req, rpcReq, connector, blockRef, groupKey, requestKey, cacheCorruptionTruncated)
+		span.SetAttributes(attribute.String("cache.corruption", cacheCorruptionTruncated))
+		return nil, cachedEntryMeta{}, nil
+	}
+	if audited && c.collisionAudit {
+		material, err := rpcReq.CacheKeyMaterial()
+		if err != nil {
+			return nil, cachedEntryMeta{}, err
+		}
+		if !bytes.Equal(material, storedMaterial) {
+			c.handleKeyCollision(req, rpcReq, connector, groupKey, requestKey, storedMaterial)
+			span.SetAttributes(attribute.Bool("cache.key_collision", true))
+			return nil, cachedEntryMeta{}, nil
+		}
+	}
+
+	if isCachedErrorResult(resultBytes) {
+		jrr, err := decodeCachedErrorResult(resultBytes)
+		if err != nil {
+			return nil, cachedEntryMeta{}, err
+		}
+		_ = jrr.SetID(rpcReq.ID)
+		span.SetAttributes(attribute.Bool("cache.error_result", true))
+		return jrr, meta, nil
+	}
+
 	// Check if it's compressed data
-	if c.compressionEnabled && c.isCompressed(resultBytes) {
+	if (c.compressionEnabled || sealed || recorded || audited || stamped || headStamped) && c.isCompressed(resultBytes) {
 		decompressed, err := c.decompressValueBytes(resultBytes)
 		if err != nil {
+			if sealed {
+				// The checksum matched, so the frame was written broken; it
+				// will never decode and must not keep shadowing the upstream.
+				c.handleCorruptedValue(req, rpcReq, connector, blockRef, groupKey, requestKey, cacheCorruptionDecompressFailed)
+				span.SetAttributes(attribute.String("cache.corruption", cacheCorruptionDecompressFailed))
+				return nil, cachedEntryMeta{}, nil
+			}
 			c.logger.Error().Err(err).Msg("failed to decompress cached value")
-			return nil, fmt.Errorf("failed to decompress cached value: %w", err)
+			return nil, cachedEntryMeta{}, fmt.Errorf("failed to decompress cached value: %w", err)
 		}
 		c.logger.Debug().
 			Int("compressedSize", len(resultBytes)).
@@ -1146,11 +1527,47 @@ func (c *EvmJsonRpcCache) doGet(ctx context.Context, connector data.Connector, r
 
 	jrr, err := common.NewJsonRpcResponseFromBytes(nil, resultBytes, nil)
 	if err != nil {
-		return nil, err
+		return nil, cachedEntryMeta{}, err
 	}
 	_ = jrr.SetID(rpcReq.ID)
 
-	return jrr, nil
+	return jrr, meta, nil
+}
+
+// handleCorruptedValue counts a value that failed integrity checks and
+// deletes it in the background so the next request refills it from upstream.
+// Values found via the reverse index are only counted: their primary keys are
+// not known here and the entry expires with its TTL.
+func (c *EvmJsonRpcCache) handleCorruptedValue(
+	req *common.NormalizedRequest,
+	rpcReq *common.JsonRpcRequest,
+	connector data.Connector,
+	blockRef, groupKey, requestKey, reason string,
+) {
+	telemetry.MetricCacheGetCorruptedTotal.WithLabelValues(
+		c.projectId,
+		req.NetworkLabel(),
+		rpcReq.Method,
+		connector.Id(),
+		reason,
+	).Inc()
+	c.logger.Warn().
+		Str("connector", connector.Id()).
+		Str("method", rpcReq.Method).
+		Str("partitionKey", groupKey).
+		Str("rangeKey", requestKey).
+		Str("reason", reason).
+		Msg("corrupted cache value detected, treating as miss")
+	if blockRef == "*" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeoutCause(context.Background(), 5*time.Second, errors.New("evm json-rpc cache driver timeout during delete"))
+		defer cancel()
+		if err := connector.Delete(ctx, groupKey, requestKey); err != nil {
+			c.logger.Warn().Err(err).Str("connector", connector.Id()).Msg("failed to delete corrupted cache value")
+		}
+	}()
 }
 
 func shouldCacheResponse(
@@ -1239,12 +1656,32 @@ func generateKeysForJsonRpcRequest(
 	if err != nil {
 		return "", "", err
 	}
+	return partitionKeyFor(req, blockRef), cacheKey, nil
+}
 
+// generateKeys is generateKeysForJsonRpcRequest with the configured
+// cache.keyHash.algorithm.
+func (c *EvmJsonRpcCache) generateKeys(
+	req *common.NormalizedRequest,
+	rpcReq *common.JsonRpcRequest,
+	blockRef string,
+	ctx ...context.Context,
+) (string, string, error) {
+	if c.keyHashAlgorithm == "" || c.keyHashAlgorithm == common.CacheKeyHashSha256 {
+		return generateKeysForJsonRpcRequest(req, blockRef, ctx...)
+	}
+	cacheKey, err := rpcReq.CacheHashWith(c.keyHashAlgorithm, ctx...)
+	if err != nil {
+		return "", "", err
+	}
+	return partitionKeyFor(req, blockRef), cacheKey, nil
+}
+
+func partitionKeyFor(req *common.NormalizedRequest, blockRef string) string {
 	if blockRef != "" {
-		return fmt.Sprintf("%s:%s", req.NetworkId(), blockRef), cacheKey, nil
-	} else {
-		return fmt.Sprintf("%s:nil", req.NetworkId()), cacheKey, nil
+		return fmt.Sprintf("%s:%s", cacheNetworkKey(req, blockRef), blockRef)
 	}
+	return fmt.Sprintf("%s:nil", cacheNetworkKey(req, blockRef))
 }
 
 // compressValueBytes compresses byte data using zstd
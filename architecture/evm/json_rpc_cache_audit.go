@@ -0,0 +1,73 @@
+package evm
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
+)
+
+// Audited cache values are laid out as magic (4 bytes) + length of the key
+// material (4 bytes, big-endian) + key material + payload, where the key
+// material is JsonRpcRequest.CacheKeyMaterial of the request that wrote the
+// value and payload is the possibly-compressed value. Like the integrity
+// magic, the first byte can neither start a JSON document nor a zstd frame;
+// the last byte tells the two envelopes apart. When both apply, the integrity
+// seal wraps the audited value.
+var cacheAuditMagic = []byte{0xE7, 0xC4, 0x1A, 0x02}
+
+const cacheAuditHeaderSize = 8
+
+func wrapAuditedValue(material, payload []byte) []byte {
+	out := make([]byte, cacheAuditHeaderSize+len(material)+len(payload))
+	copy(out, cacheAuditMagic)
+	binary.BigEndian.PutUint32(out[4:8], uint32(len(material))) // #nosec G115 -- key material is a few KB at most
+	copy(out[cacheAuditHeaderSize:], material)
+	copy(out[cacheAuditHeaderSize+len(material):], payload)
+	return out
+}
+
+// unwrapAuditedValue strips the audit header. Values written without one are
+// returned unchanged with audited=false. An audited value with a nil payload
+// is truncated and must not be served.
+func unwrapAuditedValue(value []byte) (payload, material []byte, audited bool) {
+	if len(value) < len(cacheAuditMagic) || !bytes.Equal(value[:len(cacheAuditMagic)], cacheAuditMagic) {
+		return value, nil, false
+	}
+	if len(value) < cacheAuditHeaderSize {
+		return nil, nil, true
+	}
+	end := cacheAuditHeaderSize + int(binary.BigEndian.Uint32(value[4:8]))
+	if end > len(value) || end < cacheAuditHeaderSize {
+		return nil, nil, true
+	}
+	return value[end:], value[cacheAuditHeaderSize:end], true
+}
+
+// handleKeyCollision counts a value that was stored for a different request
+// under the same key. The value is left in place: it is correct for the
+// request that wrote it, and deleting it would only make the two requests
+// evict each other.
+func (c *EvmJsonRpcCache) handleKeyCollision(
+	req *common.NormalizedRequest,
+	rpcReq *common.JsonRpcRequest,
+	connector data.Connector,
+	groupKey, requestKey string,
+	storedMaterial []byte,
+) {
+	telemetry.MetricCacheGetKeyCollisionTotal.WithLabelValues(
+		c.projectId,
+		req.NetworkLabel(),
+		rpcReq.Method,
+		connector.Id(),
+	).Inc()
+	c.logger.Error().
+		Str("connector", connector.Id()).
+		Str("method", rpcReq.Method).
+		Str("partitionKey", groupKey).
+		Str("rangeKey", requestKey).
+		Str("storedRequest", string(storedMaterial)).
+		Msg("cache key collision detected, treating as miss")
+}
@@ -0,0 +1,93 @@
+package evm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
+	promUtil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheValueAudit_WrapUnwrap(t *testing.T) {
+	material := []byte(`["eth_call",[{"to":"0xabc"},"0x1"]]`)
+	payload := []byte(`"0x1234"`)
+	wrapped := wrapAuditedValue(material, payload)
+
+	out, stored, audited := unwrapAuditedValue(wrapped)
+	assert.True(t, audited)
+	assert.Equal(t, material, stored)
+	assert.Equal(t, payload, out)
+
+	out, _, audited = unwrapAuditedValue(payload)
+	assert.False(t, audited, "values written before the audit was enabled are passed through")
+	assert.Equal(t, payload, out)
+
+	out, _, audited = unwrapAuditedValue(wrapped[:cacheAuditHeaderSize+3])
+	assert.True(t, audited)
+	assert.Nil(t, out, "a header claiming more material than stored is truncated")
+
+	// Sealing wraps the audited value, so the two envelopes nest.
+	opened, sealed, corruption := openCacheValue(sealCacheValue(wrapped))
+	require.True(t, sealed)
+	require.Empty(t, corruption)
+	out, _, audited = unwrapAuditedValue(opened)
+	assert.True(t, audited)
+	assert.Equal(t, payload, out)
+}
+
+func TestEvmJsonRpcCache_KeyCollisionIsMiss(t *testing.T) {
+	ctx := context.Background()
+	logger := log.Logger
+
+	owner := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x1234",true],"id":1}`))
+	ownerReq, err := owner.JsonRpcRequest()
+	require.NoError(t, err)
+	material, err := ownerReq.CacheKeyMaterial()
+	require.NoError(t, err)
+
+	// The connector answers every key with the owner's value, standing in for
+	// a key shared by two different requests.
+	mockConnector := &data.MockConnector{}
+	mockConnector.On("Get", mock.Anything, data.ConnectorMainIndex, mock.Anything, mock.Anything, mock.Anything).
+		Return(wrapAuditedValue(material, []byte(`{"number":"0x1234"}`)), nil)
+
+	policy, err := data.NewCachePolicy(&common.CachePolicyConfig{
+		Connector: "mock-connector",
+		Network:   "*",
+		Method:    "eth_getBlockByNumber",
+		Finality:  common.DataFinalityStateUnknown,
+	}, mockConnector)
+	require.NoError(t, err)
+
+	cache := &EvmJsonRpcCache{
+		projectId:      "test-project",
+		logger:         &logger,
+		policies:       []*data.CachePolicy{policy},
+		collisionAudit: true,
+	}
+
+	resp, err := cache.Get(ctx, owner)
+	require.NoError(t, err)
+	require.NotNil(t, resp, "the request that wrote the value is served")
+
+	other := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x1234",false],"id":2}`))
+	collisions := telemetry.MetricCacheGetKeyCollisionTotal.WithLabelValues("test-project", other.NetworkLabel(), "eth_getBlockByNumber", mockConnector.Id())
+	before := promUtil.ToFloat64(collisions)
+	resp, err = cache.Get(ctx, other)
+	require.NoError(t, err)
+	assert.Nil(t, resp, "another request's value must be treated as a miss")
+	assert.Equal(t, before+1, promUtil.ToFloat64(collisions))
+	mockConnector.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+
+	// With the audit off, the envelope is still understood.
+	cache.collisionAudit = false
+	resp, err = cache.Get(ctx, other)
+	require.NoError(t, err)
+	assert.NotNil(t, resp)
+}
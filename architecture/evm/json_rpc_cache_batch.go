@@ -0,0 +1,131 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// cacheWriteBatch collects the entries of several cache writes so that each
+// connector receives them in one SetMany call instead of one Set per entry.
+// Entries are grouped by connector and storage TTL, since a SetMany call
+// carries a single TTL.
+type cacheWriteBatch struct {
+	mu     sync.Mutex
+	groups []*cacheWriteGroup
+}
+
+type cacheWriteGroup struct {
+	connector data.Connector
+	ttl       *time.Duration
+	items     []data.KeyValuePair
+	labels    []cacheWriteLabels
+}
+
+// cacheWriteLabels are the metric labels of one batched entry.
+type cacheWriteLabels struct {
+	network string
+	method  string
+	policy  string
+	ttl     string
+}
+
+func (b *cacheWriteBatch) add(connector data.Connector, ttl *time.Duration, item data.KeyValuePair, labels cacheWriteLabels) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, g := range b.groups {
+		if g.connector == connector && sameTTL(g.ttl, ttl) {
+			g.items = append(g.items, item)
+			g.labels = append(g.labels, labels)
+			return
+		}
+	}
+	b.groups = append(b.groups, &cacheWriteGroup{
+		connector: connector,
+		ttl:       ttl,
+		items:     []data.KeyValuePair{item},
+		labels:    []cacheWriteLabels{labels},
+	})
+}
+
+func sameTTL(a, b *time.Duration) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// flush writes every group with one SetMany call, groups in parallel, and
+// returns how many entries were stored.
+func (c *EvmJsonRpcCache) flush(ctx context.Context, b *cacheWriteBatch) (int, error) {
+	b.mu.Lock()
+	groups := b.groups
+	b.groups = nil
+	b.mu.Unlock()
+	if len(groups) == 0 {
+		return 0, nil
+	}
+
+	ctx, span := common.StartDetailSpan(ctx, "Cache.SetMany")
+	defer span.End()
+	span.SetAttributes(attribute.Int("cache.batch_groups", len(groups)))
+
+	setTimeout := c.setTimeout
+	if setTimeout <= 0 {
+		setTimeout = defaultCacheSetTimeout
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, setTimeout, fmt.Errorf("evm json-rpc cache setTimeout of %s exceeded", setTimeout))
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		written int
+		errs    []error
+	)
+	for _, g := range groups {
+		wg.Add(1)
+		go func(g *cacheWriteGroup) {
+			defer wg.Done()
+			start := time.Now()
+			err := g.connector.SetMany(ctx, g.items, g.ttl)
+			elapsed := time.Since(start).Seconds()
+			if common.HasErrorCode(err, common.ErrCodeFailsafeCircuitBreakerOpen) {
+				for _, l := range g.labels {
+					telemetry.MetricCacheSetSkippedTotal.WithLabelValues(c.projectId, l.network, l.method, g.connector.Id(), l.policy, l.ttl).Inc()
+				}
+				return
+			}
+			for _, l := range g.labels {
+				if err != nil {
+					telemetry.MetricCacheSetErrorTotal.WithLabelValues(c.projectId, l.network, l.method, g.connector.Id(), l.policy, l.ttl, common.ErrorSummary(err)).Inc()
+					telemetry.MetricCacheSetErrorDuration.WithLabelValues(c.projectId, l.network, l.method, g.connector.Id(), l.policy, l.ttl, common.ErrorSummary(err)).Observe(elapsed)
+				} else {
+					telemetry.MetricCacheSetSuccessTotal.WithLabelValues(c.projectId, l.network, l.method, g.connector.Id(), l.policy, l.ttl).Inc()
+					telemetry.MetricCacheSetSuccessDuration.WithLabelValues(c.projectId, l.network, l.method, g.connector.Id(), l.policy, l.ttl).Observe(elapsed)
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("connector %s: %w", g.connector.Id(), err))
+				return
+			}
+			written += len(g.items)
+		}(g)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		err := fmt.Errorf("failed to write %d of %d cache batches: %v", len(errs), len(groups), errs)
+		common.SetTraceSpanError(span, err)
+		return written, err
+	}
+	return written, nil
+}
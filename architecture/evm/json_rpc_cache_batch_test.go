@@ -0,0 +1,63 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/data"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheWriteBatch(t *testing.T) {
+	c := &EvmJsonRpcCache{projectId: "prjA", logger: &log.Logger}
+	labels := cacheWriteLabels{network: "evm:1", method: "eth_getTransactionByHash", policy: "p", ttl: "1m"}
+	kv := func(rk string) data.KeyValuePair {
+		return data.KeyValuePair{PartitionKey: "evm:1:1", RangeKey: rk, Value: []byte(`{}`)}
+	}
+	minute, hour := time.Minute, time.Hour
+
+	t.Run("OneSetManyPerConnectorAndTTL", func(t *testing.T) {
+		a, b := data.NewMockConnector("a"), data.NewMockConnector("b")
+		a.On("SetMany", mock.Anything, []data.KeyValuePair{kv("1"), kv("2")}, &minute).Return(nil).Once()
+		a.On("SetMany", mock.Anything, []data.KeyValuePair{kv("3")}, &hour).Return(nil).Once()
+		b.On("SetMany", mock.Anything, []data.KeyValuePair{kv("1")}, &minute).Return(nil).Once()
+
+		batch := &cacheWriteBatch{}
+		batch.add(a, &minute, kv("1"), labels)
+		batch.add(b, &minute, kv("1"), labels)
+		batch.add(a, &hour, kv("3"), labels)
+		m2 := time.Minute
+		batch.add(a, &m2, kv("2"), labels)
+
+		written, err := c.flush(context.Background(), batch)
+		require.NoError(t, err)
+		require.Equal(t, 4, written)
+		a.AssertExpectations(t)
+		b.AssertExpectations(t)
+	})
+
+	t.Run("FailedGroupDoesNotCountAsWritten", func(t *testing.T) {
+		a, b := data.NewMockConnector("a"), data.NewMockConnector("b")
+		a.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("throttled")).Once()
+		b.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		batch := &cacheWriteBatch{}
+		batch.add(a, nil, kv("1"), labels)
+		batch.add(a, nil, kv("2"), labels)
+		batch.add(b, nil, kv("1"), labels)
+
+		written, err := c.flush(context.Background(), batch)
+		require.ErrorContains(t, err, "throttled")
+		require.Equal(t, 1, written)
+	})
+
+	t.Run("EmptyBatchIsNoop", func(t *testing.T) {
+		written, err := c.flush(context.Background(), &cacheWriteBatch{})
+		require.NoError(t, err)
+		require.Zero(t, written)
+	})
+}
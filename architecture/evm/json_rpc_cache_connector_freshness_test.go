@@ -34,6 +34,16 @@ func newHeadReportingConnector(id string, latestTs int64, known bool) *headRepor
 	return &headReportingConnector{MockConnector: mc, latestTs: latestTs, known: known}
 }
 
+// chainStateNetwork is a testNetwork that also implements common.EvmChainStateProvider.
+type chainStateNetwork struct {
+	testNetwork
+	state common.EvmChainState
+}
+
+func (n *chainStateNetwork) EvmChainState(ctx context.Context) common.EvmChainState {
+	return n.state
+}
+
 func plainConnector(id string) *data.MockConnector {
 	mc := &data.MockConnector{}
 	mc.On("Id").Return(id)
@@ -137,6 +147,56 @@ func TestEvmJsonRpcCache_ConnectorFreshnessGuard(t *testing.T) {
 		assert.False(t, cache.shouldAcceptCachedResult(ctx, req, common.MustNewJsonRpcResponse(1, "0x1234", nil), policy))
 	})
 
+	// --- network chain-state path (responses that name a block but carry no timestamp) ---
+
+	chainStateReq := func(state common.EvmChainState) *common.NormalizedRequest {
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_blockNumber","params":[],"id":1}`))
+		req.SetNetwork(&chainStateNetwork{
+			testNetwork: testNetwork{finalityState: common.DataFinalityStateRealtime},
+			state:       state,
+		})
+		return req
+	}
+
+	t.Run("EthBlockNumberChainStateStaleRejected", func(t *testing.T) {
+		policy := tsPolicy(t, plainConnector("conn"), time.Minute)
+		req := chainStateReq(common.EvmChainState{LatestBlockTimestamp: now - 120, LatestBlockTimestampBlock: 0x1234})
+		assert.False(t, cache.shouldAcceptCachedResult(ctx, req, common.MustNewJsonRpcResponse(1, "0x1234", nil), policy))
+	})
+
+	t.Run("EthBlockNumberChainStateFreshAccepted", func(t *testing.T) {
+		policy := tsPolicy(t, plainConnector("conn"), time.Minute)
+		req := chainStateReq(common.EvmChainState{LatestBlockTimestamp: now - 5, LatestBlockTimestampBlock: 0x1234})
+		assert.True(t, cache.shouldAcceptCachedResult(ctx, req, common.MustNewJsonRpcResponse(1, "0x1234", nil), policy))
+	})
+
+	t.Run("EthBlockNumberBelowFreshChainStateAgedByBlockTime", func(t *testing.T) {
+		// The head is fresh, but the cached block is 30 blocks of 12s behind it.
+		policy := tsPolicy(t, plainConnector("conn"), time.Minute)
+		req := chainStateReq(common.EvmChainState{LatestBlockTimestamp: now - 2, LatestBlockTimestampBlock: 0x1234 + 30, BlockTime: 12 * time.Second})
+		assert.False(t, cache.shouldAcceptCachedResult(ctx, req, common.MustNewJsonRpcResponse(1, "0x1234", nil), policy))
+	})
+
+	t.Run("EthBlockNumberJustBelowFreshChainStateAccepted", func(t *testing.T) {
+		policy := tsPolicy(t, plainConnector("conn"), time.Minute)
+		req := chainStateReq(common.EvmChainState{LatestBlockTimestamp: now - 2, LatestBlockTimestampBlock: 0x1234 + 1, BlockTime: 12 * time.Second})
+		assert.True(t, cache.shouldAcceptCachedResult(ctx, req, common.MustNewJsonRpcResponse(1, "0x1234", nil), policy))
+	})
+
+	t.Run("EthBlockNumberBelowChainStateWithoutBlockTimeRejected", func(t *testing.T) {
+		policy := tsPolicy(t, plainConnector("conn"), time.Minute)
+		req := chainStateReq(common.EvmChainState{LatestBlockTimestamp: now - 2, LatestBlockTimestampBlock: 0x1234 + 1})
+		assert.False(t, cache.shouldAcceptCachedResult(ctx, req, common.MustNewJsonRpcResponse(1, "0x1234", nil), policy))
+	})
+
+	t.Run("EthBlockNumberAheadOfChainStateAccepted", func(t *testing.T) {
+		// The cached block is newer than the head's timestamped block, so that timestamp
+		// says nothing about the cached block's age.
+		policy := tsPolicy(t, plainConnector("conn"), time.Minute)
+		req := chainStateReq(common.EvmChainState{LatestBlockTimestamp: now - 120, LatestBlockTimestampBlock: 0x1000})
+		assert.True(t, cache.shouldAcceptCachedResult(ctx, req, common.MustNewJsonRpcResponse(1, "0x1234", nil), policy))
+	})
+
 	// --- fail-open cases ---
 
 	t.Run("NoResponseTsConnectorNotHeadAwareAccepted", func(t *testing.T) {
@@ -0,0 +1,165 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/erpc/erpc/common"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// derivedBlock is the part of a block result needed to derive other entries.
+type derivedBlock struct {
+	Hash         string            `json:"hash"`
+	Number       string            `json:"number"`
+	Transactions []json.RawMessage `json:"transactions"`
+}
+
+// setDerived writes the entries of other methods answered by a block just
+// cached for req (cache.crossPopulate): the block under the other of
+// eth_getBlockByNumber/eth_getBlockByHash and, for full-transaction blocks,
+// each transaction by block and index and by its hash. Entries keyed by block number are only
+// written for finalized blocks, since a number can be reorged to another
+// block while a hash cannot. Failures are logged and otherwise ignored.
+func (c *EvmJsonRpcCache) setDerived(ctx context.Context, req *common.NormalizedRequest, resp *common.NormalizedResponse) {
+	rpcReq, err := req.JsonRpcRequest(ctx)
+	if err != nil {
+		return
+	}
+	method := rpcReq.Method
+	if method != "eth_getBlockByNumber" && method != "eth_getBlockByHash" {
+		return
+	}
+	if resp == nil || resp.IsResultEmptyish(ctx) {
+		return
+	}
+	ctx, span := common.StartDetailSpan(ctx, "Cache.SetDerived")
+	defer span.End()
+
+	rpcResp, err := resp.JsonRpcResponse(ctx)
+	if err != nil || rpcResp == nil || rpcResp.Error != nil {
+		return
+	}
+	var block derivedBlock
+	if err := common.SonicCfg.Unmarshal(rpcResp.GetResultBytes(), &block); err != nil || block.Hash == "" || block.Number == "" {
+		return
+	}
+	fullTx := false
+	rpcReq.RLock()
+	if len(rpcReq.Params) > 1 {
+		fullTx, _ = rpcReq.Params[1].(bool)
+	}
+	rpcReq.RUnlock()
+
+	// A block asked for by tag is still a concrete block once it is stored
+	// under its hash or number.
+	finality := resp.Finality(ctx)
+	if finality == common.DataFinalityStateRealtime {
+		finality = common.DataFinalityStateUnfinalized
+	}
+	finalized := finality == common.DataFinalityStateFinalized
+
+	type derivedEntry struct {
+		method string
+		params []interface{}
+		result []byte
+	}
+	var entries []derivedEntry
+	if c.crossPopulate.Blocks != nil && *c.crossPopulate.Blocks {
+		if method == "eth_getBlockByNumber" {
+			entries = append(entries, derivedEntry{"eth_getBlockByHash", []interface{}{block.Hash, fullTx}, rpcResp.GetResultBytes()})
+		} else if finalized {
+			entries = append(entries, derivedEntry{"eth_getBlockByNumber", []interface{}{block.Number, fullTx}, rpcResp.GetResultBytes()})
+		}
+	}
+	byIndex := c.crossPopulate.TransactionsByIndex != nil && *c.crossPopulate.TransactionsByIndex
+	byHash := c.crossPopulate.TransactionsByHash != nil && *c.crossPopulate.TransactionsByHash
+	if fullTx && (byIndex || byHash) {
+		for i, tx := range block.Transactions {
+			// Hash-only transaction lists are not what the transaction methods return.
+			if len(tx) == 0 || tx[0] != '{' {
+				break
+			}
+			if byIndex {
+				index := fmt.Sprintf("0x%x", i)
+				entries = append(entries, derivedEntry{"eth_getTransactionByBlockHashAndIndex", []interface{}{block.Hash, index}, tx})
+				if finalized {
+					entries = append(entries, derivedEntry{"eth_getTransactionByBlockNumberAndIndex", []interface{}{block.Number, index}, tx})
+				}
+			}
+			if byHash {
+				var ref struct {
+					Hash string `json:"hash"`
+				}
+				if err := common.SonicCfg.Unmarshal(tx, &ref); err == nil && ref.Hash != "" {
+					entries = append(entries, derivedEntry{"eth_getTransactionByHash", []interface{}{ref.Hash}, tx})
+				}
+			}
+		}
+	}
+	span.SetAttributes(attribute.Int("cache.derived_entries", len(entries)))
+
+	// A block with many transactions derives many entries; they are queued
+	// and written with one SetMany per connector.
+	batch := &cacheWriteBatch{}
+	for _, e := range entries {
+		dreq, dresp, err := newDerivedCacheEntry(req, resp, e.method, e.params, e.result, finality)
+		if err != nil {
+			c.logger.Debug().Err(err).Str("method", e.method).Msg("could not build derived cache entry")
+			continue
+		}
+		if err := c.set(ctx, dreq, dresp, batch); err != nil {
+			c.logger.Debug().Err(err).Str("method", e.method).Msg("could not store derived cache entry")
+		}
+	}
+	written, err := c.flush(ctx, batch)
+	if err != nil {
+		c.logger.Debug().Err(err).Str("method", method).Msg("could not store derived cache entries")
+	}
+	if written > 0 {
+		c.logger.Debug().
+			Str("method", method).
+			Str("blockHash", block.Hash).
+			Int("entries", written).
+			Msg("stored entries derived from block response")
+	}
+}
+
+// newDerivedCacheEntry builds a request/response pair for method that looks
+// like it was served by the same upstream as the original response.
+func newDerivedCacheEntry(
+	req *common.NormalizedRequest,
+	resp *common.NormalizedResponse,
+	method string,
+	params []interface{},
+	result []byte,
+	finality common.DataFinalityState,
+) (*common.NormalizedRequest, *common.NormalizedResponse, error) {
+	body, err := common.SonicCfg.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	dreq := common.NewNormalizedRequest(body)
+	dreq.SetNetwork(req.Network())
+	if d := req.Directives(); d != nil {
+		dreq.SetDirectives(d)
+	}
+	jrr, err := common.NewJsonRpcResponseFromBytes([]byte("1"), append([]byte(nil), result...), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	dresp := common.NewNormalizedResponse().
+		WithRequest(dreq).
+		WithJsonRpcResponse(jrr).
+		WithFinality(finality)
+	if ups := resp.Upstream(); ups != nil {
+		dresp.SetUpstream(ups)
+	}
+	return dreq, dresp, nil
+}
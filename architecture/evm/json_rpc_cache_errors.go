@@ -0,0 +1,227 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Cached error results are laid out as magic (4 bytes) + the JSON-RPC error
+// object as served to clients, i.e. with its normalized code. They are never
+// compressed; the audit envelope and the integrity seal wrap them like any
+// other value.
+var cacheErrorResultMagic = []byte{0xE7, 0xC4, 0x1A, 0x03}
+
+func isCachedErrorResult(value []byte) bool {
+	return bytes.HasPrefix(value, cacheErrorResultMagic)
+}
+
+func encodeCachedErrorResult(jre *common.ErrJsonRpcExceptionInternal) ([]byte, error) {
+	body, err := common.SonicCfg.Marshal(&common.ErrJsonRpcExceptionExternal{
+		Code:    int(jre.NormalizedCode()),
+		Message: jre.Message,
+		Data:    jre.Details["data"],
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, cacheErrorResultMagic...), body...), nil
+}
+
+func decodeCachedErrorResult(value []byte) (*common.JsonRpcResponse, error) {
+	jrr, err := common.NewJsonRpcResponseFromBytes(nil, nil, value[len(cacheErrorResultMagic):])
+	if err != nil {
+		return nil, err
+	}
+	if jrr.Error == nil {
+		return nil, fmt.Errorf("cached error result has no error object")
+	}
+	return jrr, nil
+}
+
+// CachedResponseError turns a response served from the cache with a JSON-RPC
+// error back into the error an upstream would have returned, so it is
+// rendered, logged and counted the same way. Returns nil for regular results.
+func CachedResponseError(ctx context.Context, resp *common.NormalizedResponse) error {
+	if resp == nil || !resp.FromCache() {
+		return nil
+	}
+	jrr, err := resp.JsonRpcResponse(ctx)
+	if err != nil || jrr == nil || jrr.Error == nil {
+		return nil
+	}
+	details := map[string]interface{}{}
+	if jrr.Error.Data != nil {
+		details["data"] = jrr.Error.Data
+	}
+	code := common.JsonRpcErrorNumber(jrr.Error.Code)
+	jre := common.NewErrJsonRpcExceptionInternal(jrr.Error.Code, code, jrr.Error.Message, nil, details)
+	if code == common.JsonRpcErrorEvmReverted {
+		return common.NewErrEndpointExecutionException(jre)
+	}
+	return common.NewErrEndpointClientSideException(jre).WithRetryableTowardNetwork(false)
+}
+
+// errorResultTtl returns the JSON-RPC error to cache for upstreamErr and how
+// long to keep it, or a zero TTL when the error is not deterministic. When
+// several upstreams were tried, all of them must have failed the same way.
+func (c *EvmJsonRpcCache) errorResultTtl(ctx context.Context, req *common.NormalizedRequest, upstreamErr error) (time.Duration, *common.ErrJsonRpcExceptionInternal) {
+	exh := &common.ErrUpstreamsExhausted{}
+	if !errors.As(upstreamErr, &exh) {
+		return c.singleErrorResultTtl(ctx, req, upstreamErr)
+	}
+	var ttl time.Duration
+	var jre *common.ErrJsonRpcExceptionInternal
+	for _, err := range exh.Errors() {
+		t, j := c.singleErrorResultTtl(ctx, req, err)
+		if t <= 0 || (jre != nil && j.NormalizedCode() != jre.NormalizedCode()) {
+			return 0, nil
+		}
+		ttl, jre = t, j
+	}
+	return ttl, jre
+}
+
+func (c *EvmJsonRpcCache) singleErrorResultTtl(ctx context.Context, req *common.NormalizedRequest, err error) (time.Duration, *common.ErrJsonRpcExceptionInternal) {
+	jre := &common.ErrJsonRpcExceptionInternal{}
+	if err == nil || !errors.As(err, &jre) {
+		return 0, nil
+	}
+	switch {
+	case common.HasErrorCode(err, common.ErrCodeEndpointExecutionException) &&
+		jre.NormalizedCode() == common.JsonRpcErrorEvmReverted:
+		// The same call against a block that can no longer change reverts
+		// the same way on every node.
+		if req.Finality(ctx) != common.DataFinalityStateFinalized {
+			return 0, nil
+		}
+		return c.errorResults.ExecutionRevertedTtl.Duration(), jre
+	case common.HasErrorCode(err, common.ErrCodeEndpointClientSideException) &&
+		jre.NormalizedCode() == common.JsonRpcErrorInvalidArgument &&
+		!common.IsRetryableTowardNetwork(err):
+		// Only the invalid-params errors the normalizer marked as the
+		// caller's mistake; generic ones are retried on other upstreams and
+		// may succeed there.
+		return c.errorResults.InvalidParamsTtl.Duration(), jre
+	}
+	return 0, nil
+}
+
+// SetError caches a deterministic upstream error for req, so the next
+// identical request gets the same error without reaching an upstream. Any
+// other error is ignored. Policies are matched as for a non-empty response;
+// the stored entry expires after the error TTL, or the policy TTL when that
+// is shorter.
+func (c *EvmJsonRpcCache) SetError(ctx context.Context, req *common.NormalizedRequest, upstreamErr error) error {
+	if c.errorResults == nil {
+		return nil
+	}
+	ctx, span := common.StartDetailSpan(ctx, "Cache.SetError")
+	defer span.End()
+
+	setTimeout := c.setTimeout
+	if setTimeout <= 0 {
+		setTimeout = defaultCacheSetTimeout
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, setTimeout, fmt.Errorf("evm json-rpc cache setTimeout of %s exceeded", setTimeout))
+	defer cancel()
+
+	ttl, jre := c.errorResultTtl(ctx, req, upstreamErr)
+	if ttl <= 0 {
+		return nil
+	}
+	rpcReq, err := req.JsonRpcRequest(ctx)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	blockRef, _, err := ExtractBlockReferenceFromRequest(ctx, req)
+	if err != nil || blockRef == "" {
+		return err
+	}
+	policies, err := c.findSetPolicies(req.NetworkId(), rpcReq.Method, rpcReq.Params, req.Finality(ctx), false)
+	if err != nil || len(policies) == 0 {
+		return err
+	}
+	pk, rk, err := c.generateKeys(req, rpcReq, blockRef, ctx)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	value, err := encodeCachedErrorResult(jre)
+	if err != nil {
+		return err
+	}
+	if c.collisionAudit {
+		material, err := rpcReq.CacheKeyMaterial()
+		if err != nil {
+			return err
+		}
+		value = wrapAuditedValue(material, value)
+	}
+	if c.integrityEnabled {
+		value = sealCacheValue(value)
+	}
+	code := fmt.Sprintf("%d", jre.NormalizedCode())
+	span.SetAttributes(
+		attribute.String("request.method", rpcReq.Method),
+		attribute.String("cache.error_code", code),
+	)
+
+	var wg sync.WaitGroup
+	var errsMu sync.Mutex
+	var errs []error
+	useUpstream := useUpstreamSelector(req)
+	for _, policy := range policies {
+		if eligible, _ := policy.MatchesUpstreamSelector(useUpstream); !eligible {
+			continue
+		}
+		storageTTL := ttl
+		if pt := policy.GetTTL(); pt != nil && *pt > 0 && *pt < storageTTL {
+			storageTTL = *pt
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			connector := policy.GetConnector()
+			if err := connector.Set(ctx, pk, rk, value, &storageTTL); err != nil {
+				errsMu.Lock()
+				errs = append(errs, err)
+				errsMu.Unlock()
+				return
+			}
+			telemetry.MetricCacheErrorResultSetTotal.WithLabelValues(
+				c.projectId,
+				req.NetworkLabel(),
+				rpcReq.Method,
+				connector.Id(),
+				code,
+			).Inc()
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	c.logger.Debug().
+		Str("method", rpcReq.Method).
+		Interface("id", req.ID()).
+		Str("code", code).
+		Dur("ttl", ttl).
+		Msg("cached deterministic error result")
+	return nil
+}
+
+// CachesErrorResults reports whether cache.errorResults is configured.
+func (c *EvmJsonRpcCache) CachesErrorResults() bool {
+	return c != nil && c.errorResults != nil
+}
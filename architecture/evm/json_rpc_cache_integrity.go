@@ -0,0 +1,52 @@
+package evm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+)
+
+// Sealed cache values are laid out as magic (4 bytes) + CRC-32C of the payload
+// (4 bytes, big-endian) + payload, where payload is the possibly-compressed
+// value. The magic's first byte can neither start a JSON document nor a zstd
+// frame, so sealed and legacy values can live side by side in one connector.
+var cacheIntegrityMagic = []byte{0xE7, 0xC4, 0x1A, 0x01}
+
+const cacheIntegrityHeaderSize = 8
+
+const (
+	cacheCorruptionChecksumMismatch = "checksum_mismatch"
+	cacheCorruptionTruncated        = "truncated"
+	cacheCorruptionDecompressFailed = "decompress_failed"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+func sealCacheValue(payload []byte) []byte {
+	out := make([]byte, cacheIntegrityHeaderSize+len(payload))
+	copy(out, cacheIntegrityMagic)
+	binary.BigEndian.PutUint32(out[4:8], crc32.Checksum(payload, crc32cTable))
+	copy(out[cacheIntegrityHeaderSize:], payload)
+	return out
+}
+
+func isSealedCacheValue(value []byte) bool {
+	return len(value) >= len(cacheIntegrityMagic) && bytes.Equal(value[:len(cacheIntegrityMagic)], cacheIntegrityMagic)
+}
+
+// openCacheValue verifies and strips the integrity header. Values written
+// without a header are returned unchanged with sealed=false. A non-empty
+// corruption reason means the value must not be served.
+func openCacheValue(value []byte) (payload []byte, sealed bool, corruption string) {
+	if !isSealedCacheValue(value) {
+		return value, false, ""
+	}
+	if len(value) < cacheIntegrityHeaderSize {
+		return nil, true, cacheCorruptionTruncated
+	}
+	payload = value[cacheIntegrityHeaderSize:]
+	if crc32.Checksum(payload, crc32cTable) != binary.BigEndian.Uint32(value[4:8]) {
+		return nil, true, cacheCorruptionChecksumMismatch
+	}
+	return payload, true, ""
+}
@@ -0,0 +1,86 @@
+package evm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheValueIntegrity_SealOpen(t *testing.T) {
+	payload := []byte(`{"number":"0x1234","hash":"0xabcd"}`)
+	sealed := sealCacheValue(payload)
+
+	out, isSealed, corruption := openCacheValue(sealed)
+	assert.True(t, isSealed)
+	assert.Empty(t, corruption)
+	assert.Equal(t, payload, out)
+
+	out, isSealed, corruption = openCacheValue(payload)
+	assert.False(t, isSealed, "values written before integrity was enabled are passed through")
+	assert.Empty(t, corruption)
+	assert.Equal(t, payload, out)
+
+	flipped := append([]byte(nil), sealed...)
+	flipped[len(flipped)-3] ^= 0x01
+	_, _, corruption = openCacheValue(flipped)
+	assert.Equal(t, cacheCorruptionChecksumMismatch, corruption)
+
+	_, _, corruption = openCacheValue(sealed[:len(sealed)-5])
+	assert.Equal(t, cacheCorruptionChecksumMismatch, corruption, "partial writes fail the checksum")
+
+	_, _, corruption = openCacheValue(sealed[:6])
+	assert.Equal(t, cacheCorruptionTruncated, corruption)
+}
+
+func TestEvmJsonRpcCache_CorruptedValueIsMissAndDeleted(t *testing.T) {
+	ctx := context.Background()
+	logger := log.Logger
+
+	sealed := sealCacheValue([]byte(`{"number":"0x1234","hash":"0xabcd"}`))
+	sealed[len(sealed)-2] ^= 0xFF
+
+	mockConnector := &data.MockConnector{}
+	mockConnector.On("Id").Return("mock-connector")
+	mockConnector.On("Get", mock.Anything, data.ConnectorMainIndex, mock.Anything, mock.Anything, mock.Anything).
+		Return(sealed, nil)
+	deleted := make(chan [2]string, 1)
+	mockConnector.On("Delete", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			deleted <- [2]string{args.String(1), args.String(2)}
+		}).Return(nil)
+
+	policy, err := data.NewCachePolicy(&common.CachePolicyConfig{
+		Connector: "mock-connector",
+		Network:   "*",
+		Method:    "eth_getBlockByNumber",
+		Finality:  common.DataFinalityStateUnknown,
+	}, mockConnector)
+	require.NoError(t, err)
+
+	cache := &EvmJsonRpcCache{
+		projectId:        "test-project",
+		logger:           &logger,
+		policies:         []*data.CachePolicy{policy},
+		integrityEnabled: true,
+	}
+	req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x1234",true],"id":1}`))
+
+	resp, err := cache.Get(ctx, req)
+	assert.NoError(t, err)
+	assert.Nil(t, resp, "a corrupted value must be treated as a miss")
+
+	select {
+	case keys := <-deleted:
+		assert.Equal(t, "n/a:4660", keys[0], "the record is deleted under the key it was read from")
+		assert.NotEmpty(t, keys[1])
+	case <-time.After(2 * time.Second):
+		t.Fatal("corrupted cache value was not deleted")
+	}
+}
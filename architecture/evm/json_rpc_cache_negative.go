@@ -0,0 +1,78 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+)
+
+// Head-stamped cache values are laid out as magic (4 bytes) + latest block
+// of the network when the value was written (8 bytes, big-endian) + payload.
+// Only emptyish results of policies with emptyTtl carry it, so that they stop
+// being served once a new block may have produced the missing data. When all
+// envelopes apply, the expiry stamp wraps the head stamp, which wraps the
+// audited value.
+var cacheHeadStampMagic = []byte{0xE7, 0xC4, 0x1A, 0x04}
+
+const cacheHeadStampHeaderSize = 12
+
+// cachedEntryMeta is what the envelopes of a cached value tell about it.
+type cachedEntryMeta struct {
+	// expiresAt is zero when the value was written without an expiry stamp.
+	expiresAt time.Time
+	// headBlock is zero when the value was written without a head stamp.
+	headBlock int64
+	// record is set when the value was written with a record envelope.
+	record *cacheRecord
+}
+
+func wrapHeadStampedValue(headBlock int64, payload []byte) []byte {
+	out := make([]byte, cacheHeadStampHeaderSize+len(payload))
+	copy(out, cacheHeadStampMagic)
+	binary.BigEndian.PutUint64(out[4:12], uint64(headBlock)) // #nosec G115 -- block numbers are positive
+	copy(out[cacheHeadStampHeaderSize:], payload)
+	return out
+}
+
+// unwrapHeadStampedValue strips the head header. Values written without one
+// are returned unchanged with stamped=false. A stamped value with a nil
+// payload is truncated and must not be served.
+func unwrapHeadStampedValue(value []byte) (payload []byte, headBlock int64, stamped bool) {
+	if len(value) < len(cacheHeadStampMagic) || !bytes.Equal(value[:len(cacheHeadStampMagic)], cacheHeadStampMagic) {
+		return value, 0, false
+	}
+	if len(value) < cacheHeadStampHeaderSize {
+		return nil, 0, true
+	}
+	return value[cacheHeadStampHeaderSize:], int64(binary.BigEndian.Uint64(value[4:12])), true // #nosec G115 -- written from block numbers
+}
+
+// negativeHeadBlock returns the head to stamp an emptyish result with, or
+// zero when it should not be stamped.
+func negativeHeadBlock(ctx context.Context, req *common.NormalizedRequest, policy *data.CachePolicy) int64 {
+	if !policy.EmptyInvalidateOnNewHead() {
+		return 0
+	}
+	ntw := req.Network()
+	if ntw == nil {
+		return 0
+	}
+	return ntw.EvmHighestLatestBlockNumber(ctx)
+}
+
+// negativeInvalidated tells whether an emptyish result stored at headBlock
+// is outdated by a newer latest block of the network.
+func negativeInvalidated(ctx context.Context, req *common.NormalizedRequest, policy *data.CachePolicy, headBlock int64) bool {
+	if headBlock <= 0 || !policy.EmptyInvalidateOnNewHead() {
+		return false
+	}
+	ntw := req.Network()
+	if ntw == nil {
+		return false
+	}
+	return ntw.EvmHighestLatestBlockNumber(ctx) > headBlock
+}
@@ -0,0 +1,101 @@
+package evm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheValueHeadStamp_WrapUnwrap(t *testing.T) {
+	payload := []byte(`null`)
+	wrapped := wrapHeadStampedValue(0x1234, payload)
+
+	out, head, stamped := unwrapHeadStampedValue(wrapped)
+	assert.True(t, stamped)
+	assert.Equal(t, int64(0x1234), head)
+	assert.Equal(t, payload, out)
+
+	out, head, stamped = unwrapHeadStampedValue(payload)
+	assert.False(t, stamped, "values written without emptyTtl are passed through")
+	assert.Zero(t, head)
+	assert.Equal(t, payload, out)
+
+	out, _, stamped = unwrapHeadStampedValue(wrapped[:cacheHeadStampHeaderSize-1])
+	assert.True(t, stamped)
+	assert.Nil(t, out)
+}
+
+func TestEvmJsonRpcCache_EmptyTtl(t *testing.T) {
+	ctx := context.Background()
+	logger := log.Logger
+
+	newPolicy := func(t *testing.T, conn data.Connector) *data.CachePolicy {
+		cfg := &common.CachePolicyConfig{
+			Connector: conn.Id(),
+			Network:   "*",
+			Method:    "eth_getTransactionReceipt",
+			Finality:  common.DataFinalityStateUnknown,
+			TTL:       &common.BlockTimeAdaptiveDuration{Fallback: common.Duration(time.Minute)},
+			EmptyTtl:  common.Duration(2 * time.Second),
+		}
+		require.NoError(t, cfg.SetDefaults())
+		policy, err := data.NewCachePolicy(cfg, conn)
+		require.NoError(t, err)
+		return policy
+	}
+	newReq := func(ntw common.Network) *common.NormalizedRequest {
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getTransactionReceipt","params":["0xabcd"],"id":1}`))
+		req.SetNetwork(ntw)
+		return req
+	}
+
+	t.Run("EmptyResultIsStoredWithEmptyTtlAndHeadStamp", func(t *testing.T) {
+		conn := plainConnector("conn")
+		var stored []byte
+		var ttl *time.Duration
+		conn.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				stored = args.Get(3).([]byte)
+				ttl, _ = args.Get(4).(*time.Duration)
+			}).
+			Return(nil)
+		cache := &EvmJsonRpcCache{projectId: "test-project", logger: &logger, policies: []*data.CachePolicy{newPolicy(t, conn)}}
+
+		ntw := &testNetwork{finalityState: common.DataFinalityStateUnknown, highestLatest: 100}
+		req := newReq(ntw)
+		resp := common.NewNormalizedResponse().WithRequest(req).
+			WithJsonRpcResponse(common.MustNewJsonRpcResponseFromBytes([]byte(`1`), []byte(`null`), nil))
+		require.NoError(t, cache.Set(ctx, req, resp))
+		require.NotNil(t, ttl)
+		assert.Equal(t, 2*time.Second, *ttl)
+		_, head, stamped := unwrapHeadStampedValue(stored)
+		assert.True(t, stamped)
+		assert.Equal(t, int64(100), head)
+	})
+
+	t.Run("EmptyHitIsInvalidatedByNewHead", func(t *testing.T) {
+		conn := plainConnector("conn")
+		// Receipts are looked up through the reverse index.
+		conn.On("Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(wrapHeadStampedValue(100, []byte(`null`)), nil)
+		cache := &EvmJsonRpcCache{projectId: "test-project", logger: &logger, policies: []*data.CachePolicy{newPolicy(t, conn)}}
+
+		ntw := &testNetwork{finalityState: common.DataFinalityStateUnknown, highestLatest: 100}
+		resp, err := cache.Get(ctx, newReq(ntw))
+		require.NoError(t, err)
+		require.NotNil(t, resp, "the empty result is served while the head has not moved")
+		assert.True(t, resp.FromCache())
+
+		ntw.highestLatest = 101
+		resp, err = cache.Get(ctx, newReq(ntw))
+		require.NoError(t, err)
+		assert.Nil(t, resp, "a new head may have produced the missing data")
+	})
+}
@@ -0,0 +1,69 @@
+package evm
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/erpc/erpc/common"
+)
+
+// Record envelopes are laid out as magic (4 bytes) + format version (1 byte)
+// + finality (1 byte) + block number (8 bytes, big-endian) + upstream id
+// length (1 byte) + upstream id + payload. They are written when
+// cache.records is enabled and wrap every other envelope except the
+// integrity seal. The guard the value was written with is derived from the
+// finality and block number (see data.CacheRecordGuard).
+var cacheRecordMagic = []byte{0xE7, 0xC4, 0x1A, 0x05}
+
+const (
+	cacheRecordVersion    byte = 1
+	cacheRecordHeaderSize      = 15
+	// cacheRecordMaxUpstreamId bounds the upstream id to what its length
+	// byte can hold; longer ids are truncated.
+	cacheRecordMaxUpstreamId = 255
+)
+
+// cacheRecord is where a cached value came from.
+type cacheRecord struct {
+	blockNumber int64
+	finality    common.DataFinalityState
+	upstreamId  string
+}
+
+func wrapRecordValue(rec cacheRecord, payload []byte) []byte {
+	upstreamId := rec.upstreamId
+	if len(upstreamId) > cacheRecordMaxUpstreamId {
+		upstreamId = upstreamId[:cacheRecordMaxUpstreamId]
+	}
+	out := make([]byte, cacheRecordHeaderSize+len(upstreamId)+len(payload))
+	copy(out, cacheRecordMagic)
+	out[4] = cacheRecordVersion
+	out[5] = byte(rec.finality)                                    // #nosec G115 -- finality states are small non-negative values
+	binary.BigEndian.PutUint64(out[6:14], uint64(rec.blockNumber)) // #nosec G115 -- round-trips through unwrapRecordValue
+	out[14] = byte(len(upstreamId))
+	copy(out[cacheRecordHeaderSize:], upstreamId)
+	copy(out[cacheRecordHeaderSize+len(upstreamId):], payload)
+	return out
+}
+
+// unwrapRecordValue strips the record header. Values written without one are
+// returned unchanged with recorded=false. A recorded value with a nil payload
+// is truncated or of an unknown format version and must not be served.
+func unwrapRecordValue(value []byte) (payload []byte, rec cacheRecord, recorded bool) {
+	if len(value) < len(cacheRecordMagic) || !bytes.Equal(value[:len(cacheRecordMagic)], cacheRecordMagic) {
+		return value, cacheRecord{}, false
+	}
+	if len(value) < cacheRecordHeaderSize || value[4] != cacheRecordVersion {
+		return nil, cacheRecord{}, true
+	}
+	end := cacheRecordHeaderSize + int(value[14])
+	if len(value) < end {
+		return nil, cacheRecord{}, true
+	}
+	rec = cacheRecord{
+		finality:    common.DataFinalityState(value[5]),
+		blockNumber: int64(binary.BigEndian.Uint64(value[6:14])), // #nosec G115 -- written from block numbers
+		upstreamId:  string(value[cacheRecordHeaderSize:end]),
+	}
+	return value[end:], rec, true
+}
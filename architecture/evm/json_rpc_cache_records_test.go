@@ -0,0 +1,109 @@
+package evm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheValueRecord_WrapUnwrap(t *testing.T) {
+	payload := []byte(`{"number":"0x64"}`)
+	rec := cacheRecord{blockNumber: 100, finality: common.DataFinalityStateFinalized, upstreamId: "alchemy"}
+	wrapped := wrapRecordValue(rec, payload)
+
+	out, got, recorded := unwrapRecordValue(wrapped)
+	assert.True(t, recorded)
+	assert.Equal(t, rec, got)
+	assert.Equal(t, payload, out)
+
+	out, _, recorded = unwrapRecordValue(payload)
+	assert.False(t, recorded, "values written without records are passed through")
+	assert.Equal(t, payload, out)
+
+	out, _, recorded = unwrapRecordValue(wrapped[:cacheRecordHeaderSize+3])
+	assert.True(t, recorded)
+	assert.Nil(t, out, "the upstream id is cut short")
+
+	future := append([]byte{}, wrapped...)
+	future[4] = cacheRecordVersion + 1
+	out, _, recorded = unwrapRecordValue(future)
+	assert.True(t, recorded)
+	assert.Nil(t, out, "unknown format versions are not served")
+}
+
+func TestEvmJsonRpcCache_Records(t *testing.T) {
+	ctx := context.Background()
+	logger := log.Logger
+
+	newCache := func(t *testing.T) *EvmJsonRpcCache {
+		conn, err := data.NewMemoryConnector(ctx, &logger, "mem", &common.MemoryConnectorConfig{
+			MaxItems: 100, MaxTotalSize: "1MB",
+		})
+		require.NoError(t, err)
+		var policies []*data.CachePolicy
+		for _, finality := range []common.DataFinalityState{common.DataFinalityStateFinalized, common.DataFinalityStateUnfinalized} {
+			cfg := &common.CachePolicyConfig{
+				Connector: conn.Id(),
+				Network:   "*",
+				Method:    "eth_getBlockByNumber",
+				Finality:  finality,
+				TTL:       &common.BlockTimeAdaptiveDuration{Fallback: common.Duration(time.Minute)},
+			}
+			require.NoError(t, cfg.SetDefaults())
+			policy, err := data.NewCachePolicy(cfg, conn)
+			require.NoError(t, err)
+			policies = append(policies, policy)
+		}
+		return &EvmJsonRpcCache{projectId: "test-project", logger: &logger, policies: policies, recordsEnabled: true}
+	}
+	newReq := func(ntw common.Network) *common.NormalizedRequest {
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x64",false],"id":1}`))
+		req.SetNetwork(ntw)
+		return req
+	}
+	newResp := func(req *common.NormalizedRequest, hash string, finality common.DataFinalityState) *common.NormalizedResponse {
+		return common.NewNormalizedResponse().WithRequest(req).WithFinality(finality).
+			WithJsonRpcResponse(common.MustNewJsonRpcResponseFromBytes([]byte(`1`), []byte(`{"number":"0x64","hash":"`+hash+`"}`), nil))
+	}
+
+	t.Run("UnfinalizedResponseDoesNotReplaceFinalizedRecord", func(t *testing.T) {
+		cache := newCache(t)
+		ntw := &testNetwork{finalityState: common.DataFinalityStateFinalized}
+
+		req := newReq(ntw)
+		require.NoError(t, cache.Set(ctx, req, newResp(req, "0xfinal", common.DataFinalityStateFinalized)))
+		req = newReq(ntw)
+		require.NoError(t, cache.Set(ctx, req, newResp(req, "0xreorged", common.DataFinalityStateUnfinalized)))
+
+		resp, err := cache.Get(ctx, newReq(ntw))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		jrr, err := resp.JsonRpcResponse(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, string(jrr.GetResultBytes()), "0xfinal")
+		assert.Equal(t, common.DataFinalityStateFinalized, resp.Finality(ctx), "the hit carries the finality of its record")
+	})
+
+	t.Run("FinalizedResponseReplacesUnfinalizedRecord", func(t *testing.T) {
+		cache := newCache(t)
+		ntw := &testNetwork{finalityState: common.DataFinalityStateFinalized}
+
+		req := newReq(ntw)
+		require.NoError(t, cache.Set(ctx, req, newResp(req, "0xtip", common.DataFinalityStateUnfinalized)))
+		req = newReq(ntw)
+		require.NoError(t, cache.Set(ctx, req, newResp(req, "0xfinal", common.DataFinalityStateFinalized)))
+
+		resp, err := cache.Get(ctx, newReq(ntw))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		jrr, err := resp.JsonRpcResponse(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, string(jrr.GetResultBytes()), "0xfinal")
+	})
+}
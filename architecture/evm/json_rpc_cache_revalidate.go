@@ -0,0 +1,127 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+)
+
+// Stamped cache values are laid out as magic (4 bytes) + expiry of the entry
+// (unix milliseconds, 8 bytes, big-endian) + payload, where payload is the
+// possibly-audited value. Connectors do not return the remaining TTL of an
+// entry, so policies with staleWhileRevalidate store it with the value. When
+// all envelopes apply, the integrity seal wraps the stamp, which wraps the
+// audited value.
+var cacheStampMagic = []byte{0xE7, 0xC4, 0x1A, 0x06}
+
+const cacheStampHeaderSize = 12
+
+// cacheRevalidateTimeout bounds a background refresh; it is detached from the
+// request that triggered it.
+const cacheRevalidateTimeout = 30 * time.Second
+
+func wrapStampedValue(expiresAt time.Time, payload []byte) []byte {
+	out := make([]byte, cacheStampHeaderSize+len(payload))
+	copy(out, cacheStampMagic)
+	binary.BigEndian.PutUint64(out[4:12], uint64(expiresAt.UnixMilli())) // #nosec G115 -- unix millis are positive
+	copy(out[cacheStampHeaderSize:], payload)
+	return out
+}
+
+// unwrapStampedValue strips the expiry header. Values written without one are
+// returned unchanged with stamped=false. A stamped value with a nil payload is
+// truncated and must not be served.
+func unwrapStampedValue(value []byte) (payload []byte, expiresAt time.Time, stamped bool) {
+	if len(value) < len(cacheStampMagic) || !bytes.Equal(value[:len(cacheStampMagic)], cacheStampMagic) {
+		return value, time.Time{}, false
+	}
+	if len(value) < cacheStampHeaderSize {
+		return nil, time.Time{}, true
+	}
+	ms := int64(binary.BigEndian.Uint64(value[4:12])) // #nosec G115 -- written from unix millis
+	return value[cacheStampHeaderSize:], time.UnixMilli(ms), true
+}
+
+// shouldRevalidate tells whether a hit served by policy expires soon enough
+// to be refreshed in the background.
+func shouldRevalidate(policy *data.CachePolicy, expiresAt time.Time) bool {
+	window := policy.StaleWhileRevalidate()
+	if window <= 0 || expiresAt.IsZero() {
+		return false
+	}
+	return time.Until(expiresAt) <= window
+}
+
+// revalidate refetches the request from upstreams without reading the cache,
+// so that the network stores a fresh entry before the served one expires. At
+// most one refresh per entry runs at a time in this instance; a failed one is
+// retried by the next read within the window.
+func (c *EvmJsonRpcCache) revalidate(req *common.NormalizedRequest, rpcReq *common.JsonRpcRequest, connector data.Connector) {
+	ntw := req.Network()
+	if c.revalidating == nil || c.appCtx == nil || ntw == nil {
+		return
+	}
+	hash, err := rpcReq.CacheHash()
+	if err != nil {
+		return
+	}
+	key := fmt.Sprintf("%s/%s/%s", c.projectId, req.NetworkId(), hash)
+	if _, running := c.revalidating.LoadOrStore(key, struct{}{}); running {
+		telemetry.MetricCacheRevalidateTotal.WithLabelValues(c.projectId, req.NetworkLabel(), rpcReq.Method, connector.Id(), "in_flight").Inc()
+		return
+	}
+
+	rpcReq.RLock()
+	params := rpcReq.Params
+	rpcReq.RUnlock()
+	sub := common.NewJsonRpcRequest(rpcReq.Method, params)
+	if err := sub.SetID(util.RandomID()); err != nil {
+		c.revalidating.Delete(key)
+		return
+	}
+	nq := common.NewNormalizedRequestFromJsonRpcRequest(sub)
+	dirs := req.Directives().Clone()
+	dirs.SkipCacheRead = "true"
+	nq.SetDirectives(dirs)
+	nq.SetNetwork(ntw)
+	nq.SetParentRequestId(req.ID())
+
+	networkLabel, method := req.NetworkLabel(), rpcReq.Method
+	go func() {
+		defer c.revalidating.Delete(key)
+		defer func() {
+			if rec := recover(); rec != nil {
+				telemetry.MetricUnexpectedPanicTotal.WithLabelValues(
+					"cache-revalidate",
+					fmt.Sprintf("network:%s method:%s", networkLabel, method),
+					common.ErrorFingerprint(rec),
+				).Inc()
+				c.logger.Error().
+					Interface("panic", rec).
+					Str("stack", string(debug.Stack())).
+					Msgf("unexpected panic on cache revalidation")
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(c.appCtx, cacheRevalidateTimeout)
+		defer cancel()
+		resp, err := ntw.Forward(ctx, nq)
+		if resp != nil {
+			resp.Release()
+		}
+		outcome := "success"
+		if err != nil {
+			outcome = "failed"
+			c.logger.Debug().Err(err).Str("method", method).Str("network", networkLabel).Msg("failed to revalidate near-expiry cache entry")
+		}
+		telemetry.MetricCacheRevalidateTotal.WithLabelValues(c.projectId, networkLabel, method, connector.Id(), outcome).Inc()
+	}()
+}
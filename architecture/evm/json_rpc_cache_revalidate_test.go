@@ -0,0 +1,138 @@
+package evm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// revalidateNetwork hands each forwarded request to the test and holds the
+// forward until released.
+type revalidateNetwork struct {
+	*testNetwork
+	forwarded chan *common.NormalizedRequest
+	release   chan struct{}
+}
+
+func (n *revalidateNetwork) Forward(ctx context.Context, req *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	n.forwarded <- req
+	<-n.release
+	return nil, nil
+}
+
+func TestCacheValueStamp_WrapUnwrap(t *testing.T) {
+	payload := []byte(`"0x1234"`)
+	expiresAt := time.UnixMilli(time.Now().Add(time.Minute).UnixMilli())
+	wrapped := wrapStampedValue(expiresAt, payload)
+
+	out, at, stamped := unwrapStampedValue(wrapped)
+	assert.True(t, stamped)
+	assert.True(t, expiresAt.Equal(at))
+	assert.Equal(t, payload, out)
+
+	out, at, stamped = unwrapStampedValue(payload)
+	assert.False(t, stamped, "values written without staleWhileRevalidate are passed through")
+	assert.True(t, at.IsZero())
+	assert.Equal(t, payload, out)
+
+	out, _, stamped = unwrapStampedValue(wrapped[:cacheStampHeaderSize-1])
+	assert.True(t, stamped)
+	assert.Nil(t, out)
+
+	// The seal wraps the stamp, which wraps the audited value.
+	material := []byte(`["eth_call",[]]`)
+	opened, sealed, corruption := openCacheValue(sealCacheValue(wrapStampedValue(expiresAt, wrapAuditedValue(material, payload))))
+	require.True(t, sealed)
+	require.Empty(t, corruption)
+	opened, _, stamped = unwrapStampedValue(opened)
+	require.True(t, stamped)
+	out, _, audited := unwrapAuditedValue(opened)
+	assert.True(t, audited)
+	assert.Equal(t, payload, out)
+}
+
+func TestEvmJsonRpcCache_StaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	logger := log.Logger
+
+	newCache := func(t *testing.T, expiresIn time.Duration) (*EvmJsonRpcCache, *revalidateNetwork) {
+		conn := &data.MockConnector{}
+		conn.On("Get", mock.Anything, data.ConnectorMainIndex, mock.Anything, mock.Anything, mock.Anything).
+			Return(wrapStampedValue(time.Now().Add(expiresIn), []byte(`{"number":"0x1234"}`)), nil)
+		policy, err := data.NewCachePolicy(&common.CachePolicyConfig{
+			Connector:            "mock-connector",
+			Network:              "*",
+			Method:               "eth_getBlockByNumber",
+			Finality:             common.DataFinalityStateUnknown,
+			TTL:                  &common.BlockTimeAdaptiveDuration{Fallback: common.Duration(time.Minute)},
+			StaleWhileRevalidate: common.Duration(10 * time.Second),
+		}, conn)
+		require.NoError(t, err)
+		ntw := &revalidateNetwork{
+			testNetwork: &testNetwork{finalityState: common.DataFinalityStateUnknown},
+			forwarded:   make(chan *common.NormalizedRequest, 4),
+			release:     make(chan struct{}),
+		}
+		return &EvmJsonRpcCache{
+			projectId:    "test-project",
+			logger:       &logger,
+			policies:     []*data.CachePolicy{policy},
+			appCtx:       ctx,
+			revalidating: &sync.Map{},
+		}, ntw
+	}
+	newReq := func(ntw common.Network) *common.NormalizedRequest {
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x1234",false],"id":1}`))
+		req.SetNetwork(ntw)
+		return req
+	}
+
+	t.Run("NearExpiryHitIsServedAndRefreshedOnce", func(t *testing.T) {
+		cache, ntw := newCache(t, 5*time.Second)
+		defer close(ntw.release)
+
+		for range 3 {
+			resp, err := cache.Get(ctx, newReq(ntw))
+			require.NoError(t, err)
+			require.NotNil(t, resp, "the entry is served while it is refreshed")
+			assert.True(t, resp.FromCache())
+		}
+
+		select {
+		case fwd := <-ntw.forwarded:
+			assert.True(t, fwd.ShouldSkipCacheRead("mock-connector"), "the refresh must bypass the cache")
+			method, err := fwd.Method()
+			require.NoError(t, err)
+			assert.Equal(t, "eth_getBlockByNumber", method)
+		case <-time.After(time.Second):
+			t.Fatal("near-expiry hit was not refreshed")
+		}
+		select {
+		case <-ntw.forwarded:
+			t.Fatal("a refresh already in flight must not be repeated")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("FreshHitIsNotRefreshed", func(t *testing.T) {
+		cache, ntw := newCache(t, 50*time.Second)
+		defer close(ntw.release)
+
+		resp, err := cache.Get(ctx, newReq(ntw))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		select {
+		case <-ntw.forwarded:
+			t.Fatal("an entry outside the window must not be refreshed")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package evm
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+)
+
+type cacheHitRateKey struct {
+	projectId string
+	network   string
+	method    string
+}
+
+type cacheHitRateCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// CacheHitRateBreach is one aggregate whose hit rate fell below its budget.
+type CacheHitRateBreach struct {
+	ProjectId  string  `json:"projectId"`
+	Network    string  `json:"network"`
+	Method     string  `json:"method"`
+	Hits       int64   `json:"hits"`
+	Misses     int64   `json:"misses"`
+	HitRate    float64 `json:"hitRate"`
+	MinHitRate float64 `json:"minHitRate"`
+}
+
+// cacheHitRateReporter counts cache hits and misses per project, network and
+// method, and every interval turns them into a hit-rate report checked
+// against the configured budgets. Breaches are exported as metrics, which
+// cacheHitRateBudget alert rules deliver to the alerting sinks. It is shared
+// by all per-project clones of the cache.
+type cacheHitRateReporter struct {
+	cfg      *common.CacheHitRateReportConfig
+	logger   *zerolog.Logger
+	counters sync.Map // cacheHitRateKey -> *cacheHitRateCounters
+}
+
+func newCacheHitRateReporter(logger *zerolog.Logger, cfg *common.CacheHitRateReportConfig) *cacheHitRateReporter {
+	lg := logger.With().Str("component", "cacheHitRateReport").Logger()
+	return &cacheHitRateReporter{
+		cfg:    cfg,
+		logger: &lg,
+	}
+}
+
+func (r *cacheHitRateReporter) record(projectId, network, method string, hit bool) {
+	if r == nil {
+		return
+	}
+	key := cacheHitRateKey{projectId: projectId, network: network, method: method}
+	v, ok := r.counters.Load(key)
+	if !ok {
+		v, _ = r.counters.LoadOrStore(key, &cacheHitRateCounters{})
+	}
+	if hit {
+		v.(*cacheHitRateCounters).hits.Add(1)
+	} else {
+		v.(*cacheHitRateCounters).misses.Add(1)
+	}
+}
+
+func (r *cacheHitRateReporter) start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.cfg.Interval.Duration())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.report()
+			}
+		}
+	}()
+}
+
+// report drains the counters of the interval that just ended, publishes the
+// hit-rate gauges and returns the aggregates that breached their budget.
+func (r *cacheHitRateReporter) report() []*CacheHitRateBreach {
+	var breaches []*CacheHitRateBreach
+	var lookups, hits int64
+	r.counters.Range(func(k, v any) bool {
+		key := k.(cacheHitRateKey)
+		c := v.(*cacheHitRateCounters)
+		h, m := c.hits.Swap(0), c.misses.Swap(0)
+		if h+m == 0 {
+			// Nothing since the last report; forget the key so methods that
+			// are no longer called don't accumulate.
+			r.counters.Delete(key)
+			telemetry.MetricCacheHitRate.DeleteLabelValues(key.projectId, key.network, key.method)
+			telemetry.MetricCacheHitRateBudgetBreached.DeleteLabelValues(key.projectId, key.network, key.method)
+			return true
+		}
+		lookups += h + m
+		hits += h
+		rate := float64(h) / float64(h+m)
+		telemetry.MetricCacheHitRate.WithLabelValues(key.projectId, key.network, key.method).Set(rate)
+
+		breached := telemetry.MetricCacheHitRateBudgetBreached.WithLabelValues(key.projectId, key.network, key.method)
+		if h+m < int64(r.cfg.MinRequests) {
+			breached.Set(0)
+			return true
+		}
+		budget := r.matchBudget(key)
+		if budget == nil || rate >= budget.MinHitRate {
+			breached.Set(0)
+			return true
+		}
+		breached.Set(1)
+		telemetry.MetricCacheHitRateBudgetBreachTotal.WithLabelValues(key.projectId, key.network, key.method).Inc()
+		r.logger.Warn().
+			Str("projectId", key.projectId).
+			Str("networkId", key.network).
+			Str("method", key.method).
+			Int64("hits", h).
+			Int64("misses", m).
+			Float64("hitRate", rate).
+			Float64("minHitRate", budget.MinHitRate).
+			Msg("cache hit rate is below its budget")
+		breaches = append(breaches, &CacheHitRateBreach{
+			ProjectId:  key.projectId,
+			Network:    key.network,
+			Method:     key.method,
+			Hits:       h,
+			Misses:     m,
+			HitRate:    rate,
+			MinHitRate: budget.MinHitRate,
+		})
+		return true
+	})
+
+	if lookups > 0 {
+		r.logger.Info().
+			Int64("lookups", lookups).
+			Float64("hitRate", float64(hits)/float64(lookups)).
+			Int("breaches", len(breaches)).
+			Msg("cache hit rate report")
+	}
+	sort.Slice(breaches, func(i, j int) bool { return breaches[i].HitRate < breaches[j].HitRate })
+	return breaches
+}
+
+func (r *cacheHitRateReporter) matchBudget(key cacheHitRateKey) *common.CacheHitRateBudgetConfig {
+	for _, budget := range r.cfg.Budgets {
+		if match, err := common.WildcardMatch(budget.Network, key.network); err != nil || !match {
+			continue
+		}
+		if match, err := common.WildcardMatch(budget.Method, key.method); err != nil || !match {
+			continue
+		}
+		return budget
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package evm
+
+import (
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	promUtil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheHitRateReporter(t *testing.T) {
+	logger := zerolog.Nop()
+	newReporter := func() *cacheHitRateReporter {
+		cfg := &common.CacheConfig{HitRateReport: &common.CacheHitRateReportConfig{
+			MinRequests: 10,
+			Budgets: []*common.CacheHitRateBudgetConfig{
+				{Network: "evm:1", Method: "eth_getLogs", MinHitRate: 0.2},
+				{MinHitRate: 0.5},
+			},
+		}}
+		require.NoError(t, cfg.SetDefaults())
+		return newCacheHitRateReporter(&logger, cfg.HitRateReport)
+	}
+	recordN := func(r *cacheHitRateReporter, network, method string, hits, misses int) {
+		for range hits {
+			r.record("prjHitRate", network, method, true)
+		}
+		for range misses {
+			r.record("prjHitRate", network, method, false)
+		}
+	}
+
+	t.Run("BreachesUseFirstMatchingBudget", func(t *testing.T) {
+		r := newReporter()
+		recordN(r, "evm:1", "eth_getLogs", 3, 7)     // 30% >= 20%
+		recordN(r, "evm:1", "eth_getBalance", 4, 6)  // 40% < 50%
+		recordN(r, "evm:10", "eth_getBalance", 0, 5) // too few lookups
+
+		breachesBefore := promUtil.ToFloat64(telemetry.MetricCacheHitRateBudgetBreachTotal.WithLabelValues("prjHitRate", "evm:1", "eth_getBalance"))
+		breaches := r.report()
+		require.Len(t, breaches, 1)
+		assert.Equal(t, "eth_getBalance", breaches[0].Method)
+		assert.Equal(t, "evm:1", breaches[0].Network)
+		assert.InDelta(t, 0.4, breaches[0].HitRate, 1e-9)
+		assert.Equal(t, 0.5, breaches[0].MinHitRate)
+		assert.Equal(t, breachesBefore+1, promUtil.ToFloat64(telemetry.MetricCacheHitRateBudgetBreachTotal.WithLabelValues("prjHitRate", "evm:1", "eth_getBalance")))
+		assert.InDelta(t, 0.3, promUtil.ToFloat64(telemetry.MetricCacheHitRate.WithLabelValues("prjHitRate", "evm:1", "eth_getLogs")), 1e-9)
+
+		// Each report covers only its own interval.
+		recordN(r, "evm:1", "eth_getBalance", 10, 0)
+		assert.Empty(t, r.report())
+		assert.Equal(t, 1.0, promUtil.ToFloat64(telemetry.MetricCacheHitRate.WithLabelValues("prjHitRate", "evm:1", "eth_getBalance")))
+	})
+
+	t.Run("BreachedGaugeFollowsLatestReport", func(t *testing.T) {
+		r := newReporter()
+		breached := telemetry.MetricCacheHitRateBudgetBreached.WithLabelValues("prjHitRate", "evm:1", "eth_call")
+		recordN(r, "evm:1", "eth_call", 0, 20)
+		require.Len(t, r.report(), 1)
+		assert.Equal(t, 1.0, promUtil.ToFloat64(breached), "cacheHitRateBudget alert rules notify sinks from this gauge")
+
+		recordN(r, "evm:1", "eth_call", 20, 0)
+		assert.Empty(t, r.report())
+		assert.Equal(t, 0.0, promUtil.ToFloat64(breached))
+	})
+}
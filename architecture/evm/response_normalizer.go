@@ -0,0 +1,275 @@
+package evm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/erpc/erpc/common"
+)
+
+// Node clients (Geth, Erigon, Nethermind, Besu, Reth) encode the same chain
+// data slightly differently: a missing field vs an explicit null, null vs an
+// empty array, zero-padded quantities, mixed-case hex, and field order. The
+// normalizers below rewrite results into one canonical shape so that cached
+// and served responses are byte-identical whichever node answered, and so
+// consensus does not see disputes that are only encoding noise.
+
+// responseNormalizers maps lower-cased methods to the normalizer of their
+// result. Normalizers mutate the decoded result and return it (possibly
+// replaced, e.g. null → []).
+var responseNormalizers = map[string]func(interface{}) interface{}{
+	"eth_getblockbynumber":                    normalizeBlock,
+	"eth_getblockbyhash":                      normalizeBlock,
+	"eth_gettransactionbyhash":                normalizeTransaction,
+	"eth_gettransactionbyblockhashandindex":   normalizeTransaction,
+	"eth_gettransactionbyblocknumberandindex": normalizeTransaction,
+	"eth_gettransactionreceipt":               normalizeReceipt,
+	"eth_getblockreceipts":                    normalizeReceipts,
+	"eth_getlogs":                             normalizeLogs,
+}
+
+// quantityFields are QUANTITY-encoded fields (no leading zeros per the
+// JSON-RPC spec). The block header "nonce" is 8-byte DATA and is therefore
+// only treated as a quantity inside transactions.
+var quantityFields = map[string]bool{
+	"number": true, "gasLimit": true, "gasUsed": true, "timestamp": true,
+	"difficulty": true, "totalDifficulty": true, "size": true,
+	"baseFeePerGas": true, "blobGasUsed": true, "excessBlobGas": true,
+	"blockNumber": true, "transactionIndex": true, "logIndex": true,
+	"cumulativeGasUsed": true, "effectiveGasPrice": true, "blobGasPrice": true,
+	"status": true, "type": true, "gas": true, "gasPrice": true,
+	"maxFeePerGas": true, "maxPriorityFeePerGas": true, "maxFeePerBlobGas": true,
+	"value": true, "chainId": true, "v": true, "r": true, "s": true, "yParity": true,
+}
+
+// normalizedResultCfg sorts object keys so field order never differs
+// between clients; numbers are kept as written.
+var normalizedResultCfg = sonic.Config{
+	UseNumber:        true,
+	SortMapKeys:      true,
+	EscapeHTML:       false,
+	CompactMarshaler: true,
+}.Froze()
+
+func shouldNormalizeResponses(n common.Network) bool {
+	if n == nil || n.Config() == nil || n.Config().Evm == nil {
+		return false
+	}
+	v := n.Config().Evm.NormalizeResponses
+	return v != nil && *v
+}
+
+// upstreamPostForward_normalizeResponse rewrites a successful result into
+// its canonical form. Results that fail to decode are passed through as-is.
+func upstreamPostForward_normalizeResponse(
+	ctx context.Context,
+	u common.Upstream,
+	rq *common.NormalizedRequest,
+	rs *common.NormalizedResponse,
+	methodLower string,
+) (*common.NormalizedResponse, error) {
+	if _, ok := responseNormalizers[methodLower]; !ok || rs == nil || rs.IsObjectNull(ctx) {
+		return rs, nil
+	}
+	jrr, err := rs.JsonRpcResponse(ctx)
+	if err != nil || jrr == nil || jrr.Error != nil {
+		return rs, nil
+	}
+	result, err := NormalizeResult(methodLower, jrr.GetResultBytes())
+	if err != nil || result == nil {
+		return rs, nil
+	}
+
+	njrr, err := common.NewJsonRpcResponseFromBytes(nil, result, nil)
+	if err != nil {
+		return rs, nil
+	}
+	if err := njrr.SetID(rq.ID()); err != nil {
+		return rs, nil
+	}
+	nnr := common.NewNormalizedResponse().WithRequest(rq).WithJsonRpcResponse(njrr)
+	nnr.SetFromCache(rs.FromCache())
+	nnr.SetEvmBlockRef(rs.EvmBlockRef())
+	nnr.SetEvmBlockNumber(rs.EvmBlockNumber())
+	nnr.SetDuration(rs.Duration())
+	nnr.SetAttempts(rs.Attempts())
+	nnr.SetRetries(rs.Retries())
+	nnr.SetHedges(rs.Hedges())
+	nnr.SetUpstream(u)
+	rq.SetLastValidResponse(ctx, nnr)
+	rs.Release()
+	return nnr, nil
+}
+
+// NormalizeResult returns the canonical encoding of a method's raw result,
+// or nil when the method has no normalizer.
+func NormalizeResult(methodLower string, raw []byte) ([]byte, error) {
+	normalize, ok := responseNormalizers[methodLower]
+	if !ok || len(raw) == 0 {
+		return nil, nil
+	}
+	var v interface{}
+	if err := normalizedResultCfg.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return normalizedResultCfg.Marshal(normalize(v))
+}
+
+func normalizeBlock(v interface{}) interface{} {
+	blk, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	normalizeHexFields(blk, false)
+	blk["transactions"] = emptyArrayIfNull(blk["transactions"])
+	blk["uncles"] = emptyArrayIfNull(blk["uncles"])
+	if txs, ok := blk["transactions"].([]interface{}); ok {
+		for i, tx := range txs {
+			if s, ok := tx.(string); ok {
+				txs[i] = canonicalHex(s)
+			} else {
+				txs[i] = normalizeTransaction(tx)
+			}
+		}
+	}
+	if uncles, ok := blk["uncles"].([]interface{}); ok {
+		for i, h := range uncles {
+			if s, ok := h.(string); ok {
+				uncles[i] = canonicalHex(s)
+			}
+		}
+	}
+	return blk
+}
+
+func normalizeTransaction(v interface{}) interface{} {
+	tx, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	normalizeHexFields(tx, true)
+	// Legacy transactions predate typed envelopes; some clients omit the type.
+	if _, ok := tx["type"]; !ok {
+		tx["type"] = "0x0"
+	}
+	// Contract creations: missing "to" vs explicit null.
+	if _, ok := tx["to"]; !ok {
+		tx["to"] = nil
+	}
+	return tx
+}
+
+func normalizeReceipt(v interface{}) interface{} {
+	rcpt, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	normalizeHexFields(rcpt, false)
+	if _, ok := rcpt["type"]; !ok {
+		rcpt["type"] = "0x0"
+	}
+	if _, ok := rcpt["to"]; !ok {
+		rcpt["to"] = nil
+	}
+	if _, ok := rcpt["contractAddress"]; !ok {
+		rcpt["contractAddress"] = nil
+	}
+	rcpt["logs"] = normalizeLogs(rcpt["logs"])
+	return rcpt
+}
+
+func normalizeReceipts(v interface{}) interface{} {
+	rcpts, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	for i, r := range rcpts {
+		rcpts[i] = normalizeReceipt(r)
+	}
+	return rcpts
+}
+
+func normalizeLogs(v interface{}) interface{} {
+	v = emptyArrayIfNull(v)
+	logs, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	for _, l := range logs {
+		lg, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		normalizeHexFields(lg, false)
+		if _, ok := lg["removed"]; !ok {
+			lg["removed"] = false
+		}
+		if topics, ok := lg["topics"].([]interface{}); ok {
+			for i, t := range topics {
+				if s, ok := t.(string); ok {
+					topics[i] = canonicalHex(s)
+				}
+			}
+		}
+	}
+	return logs
+}
+
+// normalizeHexFields lower-cases every hex string of obj and strips leading
+// zeros from quantity fields. txNonce treats "nonce" as a quantity (it is
+// 8-byte DATA in block headers).
+func normalizeHexFields(obj map[string]interface{}, txNonce bool) {
+	for k, val := range obj {
+		s, ok := val.(string)
+		if !ok {
+			continue
+		}
+		if quantityFields[k] || (txNonce && k == "nonce") {
+			obj[k] = canonicalQuantity(s)
+		} else {
+			obj[k] = canonicalHex(s)
+		}
+	}
+}
+
+func emptyArrayIfNull(v interface{}) interface{} {
+	if v == nil {
+		return []interface{}{}
+	}
+	return v
+}
+
+func isHexString(s string) bool {
+	if len(s) < 2 || s[0] != '0' || (s[1] != 'x' && s[1] != 'X') {
+		return false
+	}
+	for i := 2; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalHex lower-cases a hex string; anything else is returned as-is.
+func canonicalHex(s string) string {
+	if !isHexString(s) {
+		return s
+	}
+	return strings.ToLower(s)
+}
+
+// canonicalQuantity lower-cases a hex quantity and strips its leading zeros
+// ("0x00" → "0x0", "0x0a" → "0xa").
+func canonicalQuantity(s string) string {
+	if !isHexString(s) {
+		return s
+	}
+	digits := strings.TrimLeft(strings.ToLower(s[2:]), "0")
+	if digits == "" {
+		digits = "0"
+	}
+	return "0x" + digits
+}
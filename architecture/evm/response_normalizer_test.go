@@ -0,0 +1,100 @@
+package evm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeResult_ClientsProduceIdenticalBytes(t *testing.T) {
+	cases := []struct {
+		name    string
+		method  string
+		clients []string
+	}{
+		{
+			name:   "receipt with missing type, contractAddress and null logs",
+			method: "eth_gettransactionreceipt",
+			clients: []string{
+				`{"blockHash":"0xAB","blockNumber":"0x10","contractAddress":null,"cumulativeGasUsed":"0x5208","gasUsed":"0x5208","logs":[],"status":"0x1","to":"0xCd","transactionHash":"0xef","transactionIndex":"0x0","type":"0x0"}`,
+				`{"transactionHash":"0xEF","transactionIndex":"0x00","blockHash":"0xab","blockNumber":"0x010","gasUsed":"0x5208","cumulativeGasUsed":"0x5208","logs":null,"status":"0x01","to":"0xcd"}`,
+			},
+		},
+		{
+			name:   "block with null uncles and padded header quantities",
+			method: "eth_getblockbynumber",
+			clients: []string{
+				`{"hash":"0xaa","nonce":"0x0000000000000000","number":"0x1","transactions":[{"hash":"0xbb","nonce":"0x0","type":"0x0","to":null,"value":"0x0"}],"uncles":[]}`,
+				`{"number":"0x01","hash":"0xAA","nonce":"0x0000000000000000","uncles":null,"transactions":[{"hash":"0xBB","nonce":"0x00","value":"0x00"}]}`,
+			},
+		},
+		{
+			name:   "logs without removed flag",
+			method: "eth_getlogs",
+			clients: []string{
+				`[{"address":"0xaa","logIndex":"0x1","removed":false,"topics":["0xdd"]}]`,
+				`[{"topics":["0xDD"],"logIndex":"0x01","address":"0xAA"}]`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var first []byte
+			for i, raw := range tc.clients {
+				out, err := NormalizeResult(tc.method, []byte(raw))
+				require.NoError(t, err)
+				if i == 0 {
+					first = out
+					continue
+				}
+				assert.Equal(t, string(first), string(out))
+			}
+		})
+	}
+}
+
+func TestNormalizeResult_KeepsDataFields(t *testing.T) {
+	out, err := NormalizeResult("eth_getblockbyhash", []byte(`{"nonce":"0x0000000000000042","logsBloom":"0x00FF","number":"0x00"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"logsBloom":"0x00ff","nonce":"0x0000000000000042","number":"0x0","transactions":[],"uncles":[]}`, string(out))
+
+	out, err = NormalizeResult("eth_call", []byte(`"0x00"`))
+	require.NoError(t, err)
+	assert.Nil(t, out, "methods without a normalizer are left alone")
+}
+
+func TestUpstreamPostForward_NormalizeResponses(t *testing.T) {
+	raw := `{"transactionHash":"0xEF","blockNumber":"0x010","logs":null,"status":"0x01"}`
+	run := func(t *testing.T, enabled *bool) string {
+		network := &testNetwork{cfg: &common.NetworkConfig{
+			Architecture: common.ArchitectureEvm,
+			Evm:          &common.EvmNetworkConfig{NormalizeResponses: enabled},
+		}}
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":7,"method":"eth_getTransactionReceipt","params":["0xef"]}`))
+		jrr, err := common.NewJsonRpcResponseFromBytes([]byte(`7`), []byte(raw), nil)
+		require.NoError(t, err)
+		resp := common.NewNormalizedResponse().WithRequest(req).WithJsonRpcResponse(jrr)
+
+		out, err := HandleUpstreamPostForward(context.Background(), network, newMockEvmUpstream("mock-up"), req, resp, nil, false)
+		require.NoError(t, err)
+		outJrr, err := out.JsonRpcResponse(context.Background())
+		require.NoError(t, err)
+		assert.EqualValues(t, 7, outJrr.ID())
+		return outJrr.GetResultString()
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		assert.Equal(t, raw, run(t, nil))
+	})
+	t.Run("Enabled", func(t *testing.T) {
+		assert.Equal(t,
+			`{"blockNumber":"0x10","contractAddress":null,"logs":[],"status":"0x1","to":null,"transactionHash":"0xef","type":"0x0"}`,
+			run(t, util.BoolPtr(true)),
+		)
+	})
+}
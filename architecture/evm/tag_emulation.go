@@ -0,0 +1,126 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+)
+
+// tagCheckpoints holds the last checkpoint read for each project network.
+var tagCheckpoints sync.Map // "projectId/networkId" -> *tagCheckpoint
+
+type tagCheckpoint struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	fetched   bool
+	finalized int64
+	safe      int64
+}
+
+// emulatedBlockTag returns the block number tag is emulated at on network,
+// or false when the network does not emulate tag or its block is not known
+// yet, in which case the tag is forwarded as is.
+func emulatedBlockTag(ctx context.Context, network common.Network, parentReqID interface{}, tag string) (int64, bool) {
+	if network == nil {
+		return 0, false
+	}
+	ncfg := network.Config()
+	if ncfg == nil || ncfg.Evm == nil || !ncfg.Evm.TagEmulation.Emulates(tag) {
+		return 0, false
+	}
+	cfg := ncfg.Evm.TagEmulation
+	if cfg.Checkpoint != nil {
+		return checkpointBlockTag(ctx, network, parentReqID, cfg.Checkpoint, tag)
+	}
+
+	latest := network.EvmHighestLatestBlockNumber(ctx)
+	if latest <= 0 {
+		return 0, false
+	}
+	depth := cfg.FinalizedDepth
+	if tag == "safe" {
+		depth = cfg.SafeDepth
+	}
+	if latest <= depth {
+		return 0, true
+	}
+	return latest - depth, true
+}
+
+// checkpointBlockTag reads tag from the checkpoint method, fetching it at
+// most once per refresh interval. Concurrent requests wait for the fetch in
+// flight rather than sending their own. When a refresh fails the previous
+// checkpoint keeps being used: it is older, so still at or below the real one.
+func checkpointBlockTag(ctx context.Context, network common.Network, parentReqID interface{}, cfg *common.EvmTagCheckpointConfig, tag string) (int64, bool) {
+	v, _ := tagCheckpoints.LoadOrStore(network.ProjectId()+"/"+network.Id(), &tagCheckpoint{})
+	cp := v.(*tagCheckpoint)
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if time.Since(cp.checkedAt) >= cfg.RefreshInterval.Duration() {
+		cp.checkedAt = time.Now()
+		finalized, safe, err := fetchTagCheckpoint(ctx, network, parentReqID, cfg)
+		if err != nil {
+			network.Logger().Warn().Err(err).
+				Str("method", cfg.Method).
+				Bool("hasPrevious", cp.fetched).
+				Msg("could not fetch block tag checkpoint")
+		} else {
+			cp.fetched, cp.finalized, cp.safe = true, finalized, safe
+		}
+	}
+	if !cp.fetched {
+		return 0, false
+	}
+	if tag == "safe" {
+		return cp.safe, true
+	}
+	return cp.finalized, true
+}
+
+func fetchTagCheckpoint(ctx context.Context, network common.Network, parentReqID interface{}, cfg *common.EvmTagCheckpointConfig) (int64, int64, error) {
+	raw, err := forwardSubRequest(ctx, network, parentReqID, "", cfg.Method, cfg.Params)
+	if err != nil {
+		return 0, 0, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return 0, 0, fmt.Errorf("invalid %s result: %w", cfg.Method, err)
+	}
+
+	var finalized, safe int64
+	if cfg.FinalizedPath != "" {
+		if finalized, err = checkpointBlockAt(result, cfg.FinalizedPath); err != nil {
+			return 0, 0, err
+		}
+	}
+	if cfg.SafePath != "" {
+		if safe, err = checkpointBlockAt(result, cfg.SafePath); err != nil {
+			return 0, 0, err
+		}
+	}
+	return finalized, safe, nil
+}
+
+// checkpointBlockAt reads the block number at the dot-separated path of a
+// decoded result. The number may be a JSON number or a hex or decimal string.
+func checkpointBlockAt(result interface{}, path string) (int64, error) {
+	cur := result
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return 0, fmt.Errorf("checkpoint path %q: %q is not in an object", path, key)
+		}
+		cur = obj[key]
+	}
+	bn, err := parseUint64Value(cur)
+	if err != nil {
+		return 0, fmt.Errorf("checkpoint path %q: %w", path, err)
+	}
+	return int64(bn), nil // #nosec G115
+}
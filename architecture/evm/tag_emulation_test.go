@@ -0,0 +1,109 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeHttpJsonRpc_TagEmulation(t *testing.T) {
+	ctx := context.Background()
+
+	newNetwork := func(id string, emulation *common.EvmTagEmulationConfig) *mockNetwork {
+		n := &mockNetwork{}
+		n.On("Id").Return(id)
+		n.On("ProjectId").Return("test")
+		n.On("Config").Return(&common.NetworkConfig{
+			Evm: &common.EvmNetworkConfig{ChainId: 10, TagEmulation: emulation},
+		})
+		n.On("EvmHighestLatestBlockNumber", mock.Anything).Return(int64(1000))
+		n.On("EvmHighestFinalizedBlockNumber", mock.Anything).Return(int64(0))
+		return n
+	}
+	normalize := func(t *testing.T, n common.Network, params string) []interface{} {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":%s}`, params)
+		req := common.NewNormalizedRequest([]byte(body))
+		req.SetNetwork(n)
+		jrq, err := req.JsonRpcRequest()
+		require.NoError(t, err)
+		NormalizeHttpJsonRpc(ctx, req, jrq)
+		return jrq.Params
+	}
+	syncStatus := func(finalized, safe interface{}, err error) func(context.Context, *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+		return func(ctx context.Context, r *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+			if err != nil {
+				return nil, err
+			}
+			raw, _ := json.Marshal(map[string]interface{}{
+				"finalized_l2": map[string]interface{}{"number": finalized},
+				"safe_l2":      map[string]interface{}{"number": safe},
+			})
+			jrr, jerr := common.NewJsonRpcResponse(r.ID(), json.RawMessage(raw), nil)
+			require.NoError(t, jerr)
+			return common.NewNormalizedResponse().WithRequest(r).WithJsonRpcResponse(jrr), nil
+		}
+	}
+
+	t.Run("DepthsBelowLatest", func(t *testing.T) {
+		n := newNetwork("evm:10:depth", &common.EvmTagEmulationConfig{
+			Tags:           []string{"finalized", "safe"},
+			FinalizedDepth: 64,
+			SafeDepth:      16,
+		})
+		assert.Equal(t, "0x3a8", normalize(t, n, `["0xabc", "finalized"]`)[1])
+		assert.Equal(t, "0x3d8", normalize(t, n, `["0xabc", "safe"]`)[1])
+	})
+
+	t.Run("TagsNotListedPassThrough", func(t *testing.T) {
+		n := newNetwork("evm:10:safe-only", &common.EvmTagEmulationConfig{
+			Tags:           []string{"safe"},
+			FinalizedDepth: 64,
+			SafeDepth:      16,
+		})
+		assert.Equal(t, "finalized", normalize(t, n, `["0xabc", "finalized"]`)[1])
+		assert.Equal(t, "0x3d8", normalize(t, n, `["0xabc", "safe"]`)[1])
+	})
+
+	t.Run("ReadsTheCheckpoint", func(t *testing.T) {
+		n := newNetwork("evm:10:checkpoint", &common.EvmTagEmulationConfig{
+			Tags: []string{"finalized", "safe"},
+			Checkpoint: &common.EvmTagCheckpointConfig{
+				Method:          "optimism_syncStatus",
+				FinalizedPath:   "finalized_l2.number",
+				SafePath:        "safe_l2.number",
+				RefreshInterval: common.Duration(1 << 62),
+			},
+		})
+		n.On("Forward", mock.Anything, mock.Anything).Return(syncStatus(900, "0x3b6", nil), nil).Once()
+
+		assert.Equal(t, "0x384", normalize(t, n, `["0xabc", "finalized"]`)[1])
+		// The checkpoint is reused until the refresh interval passes.
+		assert.Equal(t, "0x3b6", normalize(t, n, `["0xabc", "safe"]`)[1])
+		n.AssertNumberOfCalls(t, "Forward", 1)
+	})
+
+	t.Run("KeepsThePreviousCheckpointWhenRefreshFails", func(t *testing.T) {
+		n := newNetwork("evm:10:checkpoint-failing", &common.EvmTagEmulationConfig{
+			Tags: []string{"finalized"},
+			Checkpoint: &common.EvmTagCheckpointConfig{
+				Method:        "optimism_syncStatus",
+				FinalizedPath: "finalized_l2.number",
+			},
+		})
+		n.On("Forward", mock.Anything, mock.Anything).Return(syncStatus(nil, nil, errors.New("rollup node down")), nil).Once()
+		assert.Equal(t, "finalized", normalize(t, n, `["0xabc", "finalized"]`)[1], "no checkpoint yet, the tag is forwarded")
+
+		n.On("Forward", mock.Anything, mock.Anything).Return(syncStatus(900, 950, nil), nil).Once()
+		assert.Equal(t, "0x384", normalize(t, n, `["0xabc", "finalized"]`)[1])
+
+		n.On("Forward", mock.Anything, mock.Anything).Return(syncStatus(nil, nil, errors.New("rollup node down")), nil).Once()
+		assert.Equal(t, "0x384", normalize(t, n, `["0xabc", "finalized"]`)[1])
+	})
+}
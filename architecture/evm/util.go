@@ -16,6 +16,18 @@ func IsNonRetryableWriteMethod(method string) bool {
 		method == "eth_newPendingTransactionFilter"
 }
 
+// IsWalletMethod returns true for methods that use or manage the accounts of
+// the node serving them: signing, sending unsigned transactions and the
+// personal_* namespace. A node with unlocked accounts would sign or spend on
+// behalf of whoever reaches it.
+func IsWalletMethod(method string) bool {
+	switch method {
+	case "eth_accounts", "eth_requestAccounts", "eth_sendTransaction":
+		return true
+	}
+	return strings.HasPrefix(method, "eth_sign") || strings.HasPrefix(method, "personal_")
+}
+
 func IsMissingDataError(err error) bool {
 	txt := err.Error()
 	return strings.Contains(txt, "missing trie node") ||
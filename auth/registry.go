@@ -109,3 +109,14 @@ func (r *AuthRegistry) FindDatabaseConnector(connectorId string) (data.Connector
 	}
 	return nil, fmt.Errorf("database connector with ID '%s' not found", connectorId)
 }
+
+// DatabaseConnectors returns the connectors of every database strategy in the registry
+func (r *AuthRegistry) DatabaseConnectors() []data.Connector {
+	var connectors []data.Connector
+	for _, az := range r.strategies {
+		if dbStrategy, ok := az.strategy.(*DatabaseStrategy); ok {
+			connectors = append(connectors, dbStrategy.GetConnector())
+		}
+	}
+	return connectors
+}
@@ -33,12 +33,21 @@ func (notImplementedConnector) Get(context.Context, string, string, string, inte
 func (notImplementedConnector) Set(context.Context, string, string, []byte, *time.Duration) error {
 	panic("notImplementedConnector: Set")
 }
+func (notImplementedConnector) SetMany(context.Context, []data.KeyValuePair, *time.Duration) error {
+	panic("notImplementedConnector: SetMany")
+}
 func (notImplementedConnector) Delete(context.Context, string, string) error {
 	panic("notImplementedConnector: Delete")
 }
+func (notImplementedConnector) DeleteByPrefix(context.Context, string) (int, error) {
+	panic("notImplementedConnector: DeleteByPrefix")
+}
 func (notImplementedConnector) List(context.Context, string, int, string) ([]data.KeyValuePair, string, error) {
 	panic("notImplementedConnector: List")
 }
+func (notImplementedConnector) Scan(context.Context, string, string, int, string) ([]data.KeyValuePair, string, error) {
+	panic("notImplementedConnector: Scan")
+}
 func (notImplementedConnector) Lock(context.Context, string, time.Duration) (data.DistributedLock, error) {
 	panic("notImplementedConnector: Lock")
 }
@@ -48,6 +57,12 @@ func (notImplementedConnector) WatchCounterInt64(context.Context, string) (<-cha
 func (notImplementedConnector) PublishCounterInt64(context.Context, string, data.CounterInt64State) error {
 	panic("notImplementedConnector: PublishCounterInt64")
 }
+func (notImplementedConnector) State() data.ConnectorState {
+	panic("notImplementedConnector: State")
+}
+func (notImplementedConnector) Ping(context.Context) error {
+	panic("notImplementedConnector: Ping")
+}
 
 // fakeConnector is a minimal data.Connector implementation that captures
 // Get call counts and returns programmable results. By embedding
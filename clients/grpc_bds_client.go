@@ -101,7 +101,7 @@ func NewGrpcBdsClient(
 		projectId:       projectId,
 		upstream:        upstream,
 		upstreamId:      upsId,
-		isLogLevelTrace: logger.GetLevel() == zerolog.TraceLevel,
+		isLogLevelTrace: common.LogLevelEnabled(logger, zerolog.TraceLevel),
 		headers:         make(map[string]string),
 	}
 	if upstream != nil {
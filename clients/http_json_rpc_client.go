@@ -96,7 +96,7 @@ func NewGenericHttpJsonRpcClient(
 		projectId:       projectId,
 		upstream:        upstream,
 		proxyPool:       proxyPool,
-		isLogLevelTrace: logger.GetLevel() == zerolog.TraceLevel,
+		isLogLevelTrace: common.LogLevelEnabled(logger, zerolog.TraceLevel),
 		gzipPool:        util.NewGzipReaderPool(),
 		gzipWriterPool:  util.NewGzipWriterPool(),
 		errorExtractor:  extractor,
@@ -208,6 +208,17 @@ func (c *GenericHttpJsonRpcClient) shutdown() {
 	c.processBatch(true)
 }
 
+// CapBatchMaxSize lowers the batch size limit to n when n is smaller, for
+// node clients known to reject bigger batches (clientQuirks). Callers
+// type-assert for it. Safe to call concurrently with queued requests.
+func (c *GenericHttpJsonRpcClient) CapBatchMaxSize(n int) {
+	c.batchMu.Lock()
+	defer c.batchMu.Unlock()
+	if n > 0 && n < c.batchMaxSize {
+		c.batchMaxSize = n
+	}
+}
+
 func (c *GenericHttpJsonRpcClient) getHttpClient() *http.Client {
 	if c.proxyPool != nil {
 		client, err := c.proxyPool.GetClient()
@@ -828,6 +839,12 @@ func (c *GenericHttpJsonRpcClient) prepareRequest(ctx context.Context, body []by
 		httpReq.Header.Set("Content-Encoding", "gzip")
 	}
 
+	// Let the provider's logs be matched to the client request; batches
+	// combine several client requests and carry none.
+	if header, id := common.ForwardedCorrelationId(ctx); id != "" {
+		httpReq.Header.Set(header, id)
+	}
+
 	// Add custom headers if provided
 	for k, v := range c.headers {
 		httpReq.Header.Set(k, v)
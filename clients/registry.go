@@ -80,7 +80,8 @@ func (manager *ClientRegistry) CreateClient(appCtx context.Context, ups common.U
 		clientErr = fmt.Errorf("failed to parse URL for upstream: %v", cfg.Id)
 	} else {
 		once.Do(func() {
-			lg := manager.logger.With().Str("upstreamId", cfg.Id).Logger()
+			lg := manager.logger.With().Str("upstreamId", cfg.Id).Logger().
+				Sample(common.LogLevels.Sampler(common.LogTarget{Kind: common.LogTargetUpstream, Id: cfg.Id}))
 			switch cfg.Type {
 			case common.UpstreamTypeEvm:
 				if parsedUrl.Scheme == "http" || parsedUrl.Scheme == "https" {
@@ -11,10 +11,12 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/common/legacy"
 	"github.com/erpc/erpc/erpc"
+	"github.com/erpc/erpc/internal/bench"
 	"github.com/erpc/erpc/internal/policy"
 	"github.com/erpc/erpc/util"
 	"github.com/joho/godotenv"
@@ -197,6 +199,130 @@ func main() {
 		},
 	}
 
+	// Define the bench command
+	benchCmd := &cli.Command{
+		Name:      "bench",
+		Usage:     "Send a synthetic or recorded JSON-RPC workload to a running eRPC instance and report latency percentiles, cache hit rate and upstream distribution",
+		ArgsUsage: "<target url, e.g. http://localhost:4000/main/evm/1>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "methods",
+				Usage: "Weighted method mix for synthetic requests, e.g. eth_blockNumber=5,eth_getBalance=2",
+				Value: bench.DefaultMix,
+			},
+			&cli.StringFlag{
+				Name:  "workload",
+				Usage: "Replay JSON-RPC requests from a file (one request or batch per line) instead of the method mix",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Number of concurrent workers",
+				Value: 10,
+			},
+			&cli.DurationFlag{
+				Name:  "duration",
+				Usage: "How long to run; 0 runs until --requests are sent",
+				Value: 30 * time.Second,
+			},
+			&cli.IntFlag{
+				Name:  "requests",
+				Usage: "Stop after this many requests; 0 means no limit",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "Per-request timeout",
+				Value: 30 * time.Second,
+			},
+			&cli.StringSliceFlag{
+				Name:  "header",
+				Usage: "Header to add to every request as 'Name: value' (can be specified multiple times)",
+			},
+			&cli.Int64Flag{
+				Name:  "seed",
+				Usage: "Seed for synthetic params; 0 picks a random one",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Output format: text|json",
+				Value: "text",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			zerolog.SetGlobalLevel(zerolog.Disabled)
+
+			opts := bench.Options{
+				Target:      cmd.Args().First(),
+				Concurrency: int(cmd.Int("concurrency")),
+				Duration:    cmd.Duration("duration"),
+				MaxRequests: int(cmd.Int("requests")),
+				Timeout:     cmd.Duration("timeout"),
+				Seed:        cmd.Int64("seed"),
+				Headers:     map[string]string{},
+			}
+			if opts.Target == "" {
+				fmt.Fprintf(os.Stderr, "error: target url is required, e.g. erpc bench http://localhost:4000/main/evm/1\n")
+				util.OsExit(1)
+				return nil
+			}
+			for _, h := range cmd.StringSlice("header") {
+				name, value, ok := strings.Cut(h, ":")
+				if !ok || strings.TrimSpace(name) == "" {
+					fmt.Fprintf(os.Stderr, "error: invalid header %q (use 'Name: value')\n", h)
+					util.OsExit(1)
+					return nil
+				}
+				opts.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+			if path := cmd.String("workload"); path != "" {
+				f, err := os.Open(path)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "error: failed to open workload: %v\n", err)
+					util.OsExit(1)
+					return nil
+				}
+				opts.Workload, err = bench.LoadWorkload(f)
+				f.Close()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "error: failed to load workload %s: %v\n", path, err)
+					util.OsExit(1)
+					return nil
+				}
+			} else {
+				mix, err := bench.ParseMix(cmd.String("methods"))
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "error: invalid --methods: %v\n", err)
+					util.OsExit(1)
+					return nil
+				}
+				opts.Mix = mix
+			}
+
+			report, err := bench.Run(ctx, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				util.OsExit(1)
+				return nil
+			}
+
+			switch format := cmd.String("format"); format {
+			case "json":
+				out, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "error: failed to marshal report to JSON: %v\n", err)
+					util.OsExit(1)
+					return nil
+				}
+				fmt.Println(string(out))
+			case "text":
+				report.WriteText(os.Stdout)
+			default:
+				fmt.Fprintf(os.Stderr, "error: unsupported format %q (use text or json)\n", format)
+				util.OsExit(1)
+			}
+			return nil
+		},
+	}
+
 	// Define the start command
 	startCmd := &cli.Command{
 		Name:  "start",
@@ -235,11 +361,12 @@ func main() {
 				logger,
 			)
 		}),
-		// sub command for start / validation / dump
+		// sub command for start / validation / dump / bench
 		Commands: []*cli.Command{
 			startCmd,
 			validateCmd,
 			dumpCmd,
+			benchCmd,
 		},
 	}
 	if err := cmd.Run(ctx, os.Args); err != nil {
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ParseAttestationKey decodes server.attestation.privateKey: an ed25519 seed
+// (32 bytes) or private key (64 bytes), hex (optionally 0x-prefixed) or
+// base64 encoded.
+func ParseAttestationKey(encoded string) (ed25519.PrivateKey, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, fmt.Errorf("is required")
+	}
+	var raw []byte
+	if b, err := hex.DecodeString(strings.TrimPrefix(encoded, "0x")); err == nil {
+		raw = b
+	} else if b, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+		raw = b
+	} else if b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "=")); err == nil {
+		raw = b
+	} else {
+		return nil, fmt.Errorf("must be hex or base64 encoded")
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		key := ed25519.PrivateKey(raw)
+		// The second half of a private key is its public key; reject keys
+		// whose halves disagree rather than sign with a mismatched pair.
+		if !key.Public().(ed25519.PublicKey).Equal(ed25519.NewKeyFromSeed(key.Seed()).Public()) {
+			return nil, fmt.Errorf("public half does not match the seed")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("must decode to a %d-byte ed25519 seed or %d-byte private key, got %d bytes", ed25519.SeedSize, ed25519.PrivateKeySize, len(raw))
+	}
+}
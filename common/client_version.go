@@ -0,0 +1,124 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// NodeClient is the node software behind an upstream, as reported by
+// web3_clientVersion (e.g. "Geth/v1.14.8-stable-a9523b64/linux-amd64/go1.22.6").
+type NodeClient struct {
+	// Name is the lowercased client name, e.g. "geth", "reth", "erigon".
+	Name string `json:"name"`
+	// Version is the numeric part of the version, e.g. "1.14.8".
+	Version string `json:"version"`
+	// Raw is the unparsed web3_clientVersion result.
+	Raw string `json:"raw"`
+}
+
+// ParseClientVersion parses a web3_clientVersion result of the usual
+// "<name>/<version>/<platform>/..." form. It returns nil for an empty value;
+// a value without a version keeps an empty Version.
+func ParseClientVersion(raw string) *NodeClient {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	parts := strings.Split(raw, "/")
+	nc := &NodeClient{
+		Name: strings.ToLower(strings.TrimSpace(parts[0])),
+		Raw:  raw,
+	}
+	if len(parts) > 1 {
+		v := strings.TrimPrefix(strings.TrimSpace(parts[1]), "v")
+		if i := strings.IndexAny(v, "-+ "); i >= 0 {
+			v = v[:i]
+		}
+		nc.Version = v
+	}
+	return nc
+}
+
+// CompareVersions compares two dotted numeric versions ("1.2.10" > "1.2.9").
+// Missing components count as zero and non-numeric ones as their leading
+// digits, so "1.2" == "1.2.0".
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var av, bv int
+		if i < len(as) {
+			av = leadingInt(as[i])
+		}
+		if i < len(bs) {
+			bv = leadingInt(bs[i])
+		}
+		if av != bv {
+			if av < bv {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func leadingInt(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+// Matches reports whether the quirk applies to nc.
+func (q *ClientQuirkConfig) Matches(nc *NodeClient) bool {
+	if q == nil || nc == nil {
+		return false
+	}
+	if match, err := WildcardMatch(strings.ToLower(q.Client), nc.Name); err != nil || !match {
+		return false
+	}
+	if q.MinVersion != "" && (nc.Version == "" || CompareVersions(nc.Version, q.MinVersion) < 0) {
+		return false
+	}
+	if q.MaxVersion != "" && (nc.Version == "" || CompareVersions(nc.Version, q.MaxVersion) > 0) {
+		return false
+	}
+	return true
+}
+
+var clientQuirks atomic.Pointer[[]*ClientQuirkConfig]
+
+// SetClientQuirks installs the global client quirks (config clientQuirks).
+// Call once during init, before upstreams bootstrap. A nil list turns node
+// client detection off; an empty one only detects.
+func SetClientQuirks(quirks []*ClientQuirkConfig) {
+	if quirks == nil {
+		clientQuirks.Store(nil)
+		return
+	}
+	clientQuirks.Store(&quirks)
+}
+
+// ClientDetectionEnabled reports whether upstreams should detect their node
+// client, i.e. whether clientQuirks is configured.
+func ClientDetectionEnabled() bool {
+	return clientQuirks.Load() != nil
+}
+
+// ClientQuirksFor returns the quirks that apply to nc, in config order.
+func ClientQuirksFor(nc *NodeClient) []*ClientQuirkConfig {
+	p := clientQuirks.Load()
+	if p == nil || nc == nil {
+		return nil
+	}
+	var out []*ClientQuirkConfig
+	for _, q := range *p {
+		if q.Matches(nc) {
+			out = append(out, q)
+		}
+	}
+	return out
+}
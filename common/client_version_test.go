@@ -0,0 +1,49 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClientVersion(t *testing.T) {
+	cases := []struct {
+		raw     string
+		name    string
+		version string
+	}{
+		{"Geth/v1.14.8-stable-a9523b64/linux-amd64/go1.22.6", "geth", "1.14.8"},
+		{"reth/v1.3.1-0e9a3d8/x86_64-unknown-linux-gnu", "reth", "1.3.1"},
+		{"erigon/2.60.1/linux-amd64/go1.21.5", "erigon", "2.60.1"},
+		{"Nethermind/v1.25.4+20b10b35/linux-x64/dotnet8.0.2", "nethermind", "1.25.4"},
+		{"anvil", "anvil", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.raw, func(t *testing.T) {
+			nc := ParseClientVersion(tc.raw)
+			require.NotNil(t, nc)
+			assert.Equal(t, tc.name, nc.Name)
+			assert.Equal(t, tc.version, nc.Version)
+			assert.Equal(t, tc.raw, nc.Raw)
+		})
+	}
+	assert.Nil(t, ParseClientVersion("  "))
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 1, CompareVersions("1.2.10", "1.2.9"))
+	assert.Equal(t, -1, CompareVersions("1.2", "1.10"))
+	assert.Equal(t, 0, CompareVersions("1.2", "1.2.0"))
+	assert.Equal(t, 0, CompareVersions("1.2.0rc1", "1.2.0"))
+}
+
+func TestClientQuirkMatches(t *testing.T) {
+	reth := ParseClientVersion("reth/v1.3.1/x86_64-unknown-linux-gnu")
+	assert.True(t, (&ClientQuirkConfig{Client: "reth"}).Matches(reth))
+	assert.True(t, (&ClientQuirkConfig{Client: "geth|reth", MinVersion: "1.3.0", MaxVersion: "1.3.1"}).Matches(reth))
+	assert.False(t, (&ClientQuirkConfig{Client: "reth", MinVersion: "1.4"}).Matches(reth))
+	assert.False(t, (&ClientQuirkConfig{Client: "reth", MaxVersion: "1.2.9"}).Matches(reth))
+	assert.False(t, (&ClientQuirkConfig{Client: "Geth"}).Matches(reth))
+	assert.False(t, (&ClientQuirkConfig{Client: "anvil", MinVersion: "1.0"}).Matches(ParseClientVersion("anvil")))
+}
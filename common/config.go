@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"maps"
 	"os"
+	"slices"
 	"time"
 
 	"strings"
@@ -45,8 +46,21 @@ type Config struct {
 	Projects     []*ProjectConfig   `yaml:"projects,omitempty" json:"projects"`
 	RateLimiters *RateLimiterConfig `yaml:"rateLimiters,omitempty" json:"rateLimiters"`
 	Metrics      *MetricsConfig     `yaml:"metrics,omitempty" json:"metrics"`
-	ProxyPools   []*ProxyPoolConfig `yaml:"proxyPools,omitempty" json:"proxyPools"`
-	Tracing      *TracingConfig     `yaml:"tracing,omitempty" json:"tracing"`
+	// Management serves the operator endpoints on a listener of their own:
+	// see ManagementConfig. Nil keeps them on the public listener.
+	Management *ManagementConfig  `yaml:"management,omitempty" json:"management,omitempty"`
+	ProxyPools []*ProxyPoolConfig `yaml:"proxyPools,omitempty" json:"proxyPools"`
+	Tracing    *TracingConfig     `yaml:"tracing,omitempty" json:"tracing"`
+	Scheduler  *SchedulerConfig   `yaml:"scheduler,omitempty" json:"scheduler"`
+	// Alerting evaluates threshold rules over eRPC's own metrics and notifies
+	// webhook, Slack or PagerDuty sinks, for deployments without Alertmanager.
+	Alerting *AlertingConfig `yaml:"alerting,omitempty" json:"alerting,omitempty"`
+
+	// ClientQuirks adjusts how upstreams are used based on the node client
+	// and version they report via web3_clientVersion, for every upstream of
+	// every project at once. Upstreams only detect their client when it is
+	// set; an empty list detects without changing anything.
+	ClientQuirks []*ClientQuirkConfig `yaml:"clientQuirks,omitempty" json:"clientQuirks,omitempty"`
 
 	// UserScript is the compiled program of the user's TS/JS config file
 	// (the WHOLE thing — imports, helpers, the createConfig call). Set
@@ -67,6 +81,25 @@ type Config struct {
 	UserScript *sobek.Program `yaml:"-" json:"-"`
 }
 
+// ClientQuirkConfig describes how a node client (optionally limited to a
+// version range) differs from what eRPC expects by default.
+type ClientQuirkConfig struct {
+	// Client matches the lowercased client name, e.g. "reth" or "geth|bor".
+	Client string `yaml:"client" json:"client"`
+	// MinVersion and MaxVersion bound the versions the quirk applies to,
+	// both inclusive. Either can be left empty.
+	MinVersion string `yaml:"minVersion,omitempty" json:"minVersion,omitempty"`
+	MaxVersion string `yaml:"maxVersion,omitempty" json:"maxVersion,omitempty"`
+	// IgnoreMethods are methods the client does not serve. An upstream's own
+	// allowMethods still wins.
+	IgnoreMethods []string `yaml:"ignoreMethods,omitempty" json:"ignoreMethods,omitempty"`
+	// MaxBatchSize caps the JSON-RPC batches sent to the client.
+	MaxBatchSize int `yaml:"maxBatchSize,omitempty" json:"maxBatchSize,omitempty"`
+	// UnsupportedErrors are error message substrings with which the client
+	// reports an unsupported method, beyond the ones eRPC already knows.
+	UnsupportedErrors []string `yaml:"unsupportedErrors,omitempty" json:"unsupportedErrors,omitempty"`
+}
+
 // LegacyTranslateFn is the post-decode migration hook invoked by
 // LoadConfig before SetDefaults. nil = no migration (canonical configs
 // only). cmd/erpc/main.go wires this to legacy.TranslateFromConfig
@@ -173,6 +206,135 @@ type ServerConfig struct {
 	// default. Credit-unit pricing is vendor-level configuration — see
 	// CreditUnitsProvider and UpstreamConfig.CreditUnits.
 	CostHeaders *bool `yaml:"costHeaders,omitempty" json:"costHeaders"`
+
+	// ResponseCompression picks the encodings negotiated with clients and the
+	// body size above which responses are compressed. Projects can override it.
+	// Setting enableGzip to false turns response compression off entirely.
+	ResponseCompression *ResponseCompressionConfig `yaml:"responseCompression,omitempty" json:"responseCompression,omitempty"`
+
+	// ETag adds a weak ETag, derived from the JSON-RPC result, to successful
+	// single (non-batch) responses served from cache or about finalized data,
+	// and answers a matching If-None-Match with 304 Not Modified and no body.
+	// Defaults to false.
+	ETag *bool `yaml:"etag,omitempty" json:"etag"`
+
+	// CorrelationId assigns each HTTP request an id that is logged with it,
+	// recorded on its traces, returned in its response and error bodies, and
+	// sent to the upstreams serving it.
+	CorrelationId *CorrelationIdConfig `yaml:"correlationId,omitempty" json:"correlationId,omitempty"`
+
+	// UnixSocket also serves the HTTP API on a unix domain socket, e.g. for
+	// an application running next to erpc in the same pod or host. Set
+	// listenV4/listenV6 to false to serve only on the socket.
+	UnixSocket *UnixSocketConfig `yaml:"unixSocket,omitempty" json:"unixSocket,omitempty"`
+
+	// SocketActivation serves the HTTP API on the stream sockets passed by
+	// systemd (LISTEN_FDS), in addition to the listeners above. TLS applies
+	// to them when enabled.
+	SocketActivation *bool `yaml:"socketActivation,omitempty" json:"socketActivation,omitempty"`
+
+	// ProxyProtocol reads PROXY protocol v1/v2 headers on the TCP listeners
+	// (HTTP and gRPC) so the client address seen by rate limiting, logs and
+	// trustedIPForwarders is the one a load balancer such as an AWS NLB
+	// received the connection from.
+	ProxyProtocol *ProxyProtocolConfig `yaml:"proxyProtocol,omitempty" json:"proxyProtocol,omitempty"`
+
+	// Attestation signs single (non-batch) JSON-RPC responses with an
+	// ed25519 key held by erpc, so a client can later prove which body was
+	// served for which request.
+	Attestation *AttestationConfig `yaml:"attestation,omitempty" json:"attestation,omitempty"`
+
+	// RequestValidation rejects malformed JSON-RPC requests (wrong jsonrpc
+	// version, ill-typed id, method or params) with a JSON-RPC error before
+	// they are parsed and forwarded.
+	RequestValidation *RequestValidationConfig `yaml:"requestValidation,omitempty" json:"requestValidation,omitempty"`
+}
+
+// RequestValidationConfig controls the strict checks of incoming JSON-RPC
+// requests. See ValidateJsonRpcRequestStrict.
+type RequestValidationConfig struct {
+	// Enabled defaults to true when the section is present.
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// MaxMethodLength caps the length of the method name. Defaults to 64.
+	MaxMethodLength int `yaml:"maxMethodLength,omitempty" json:"maxMethodLength,omitempty"`
+	// MaxIdLength caps the length of a string or numeric id. Defaults to 128.
+	MaxIdLength int `yaml:"maxIdLength,omitempty" json:"maxIdLength,omitempty"`
+	// MaxParams caps the number of positional params. Defaults to 32.
+	MaxParams int `yaml:"maxParams,omitempty" json:"maxParams,omitempty"`
+}
+
+// AttestationConfig controls signed response attestations.
+type AttestationConfig struct {
+	// Enabled defaults to true when the section is present.
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// PrivateKey is the ed25519 key, as a 32-byte seed or a 64-byte private
+	// key, hex or base64 encoded.
+	PrivateKey string `yaml:"privateKey" json:"privateKey"`
+	// KeyId names the key in each attestation so verifiers can pick the
+	// right public key after a rotation. Defaults to the first 16 hex
+	// characters of the sha256 of the public key.
+	KeyId string `yaml:"keyId,omitempty" json:"keyId,omitempty"`
+	// Header carries the attestation. Defaults to "X-ERPC-Attestation".
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
+}
+
+// ProxyProtocolConfig controls PROXY protocol headers on incoming connections.
+type ProxyProtocolConfig struct {
+	// TrustedSources lists the peers (IPs or CIDRs) whose PROXY header is
+	// read; connections from other peers are served as they are, so clients
+	// cannot spoof their address. Defaults to server.trustedIPForwarders.
+	TrustedSources []string `yaml:"trustedSources,omitempty" json:"trustedSources,omitempty"`
+	// Required closes connections from trusted sources that do not start
+	// with a PROXY header. Defaults to false.
+	Required *bool `yaml:"required,omitempty" json:"required,omitempty"`
+	// HeaderTimeout bounds how long a trusted source may take to send the
+	// header. Defaults to 5s.
+	HeaderTimeout *Duration `yaml:"headerTimeout,omitempty" json:"headerTimeout,omitempty" tstype:"Duration"`
+}
+
+// UnixSocketConfig is a unix domain socket listener of the HTTP API.
+type UnixSocketConfig struct {
+	// Path of the socket file. A stale socket left by a previous process is
+	// replaced; any other existing file is an error.
+	Path string `yaml:"path" json:"path"`
+	// Mode sets the socket file permissions as an octal string. Defaults to
+	// "0660" so only the owner and group can connect.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+}
+
+// CorrelationIdConfig controls the per-request correlation id.
+type CorrelationIdConfig struct {
+	// Enabled defaults to true.
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// Header carries the id in client requests, responses and upstream
+	// requests. Defaults to "X-Request-Id".
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
+	// TrustClient reuses a valid id sent by the client instead of generating
+	// one. Defaults to true.
+	TrustClient *bool `yaml:"trustClient,omitempty" json:"trustClient,omitempty"`
+	// ForwardToUpstreams sends the id to HTTP upstreams. Defaults to true.
+	ForwardToUpstreams *bool `yaml:"forwardToUpstreams,omitempty" json:"forwardToUpstreams,omitempty"`
+}
+
+// ContentEncoding is an HTTP response encoding negotiated via Accept-Encoding.
+type ContentEncoding string
+
+const (
+	ContentEncodingBrotli ContentEncoding = "br"
+	ContentEncodingZstd   ContentEncoding = "zstd"
+	ContentEncodingGzip   ContentEncoding = "gzip"
+)
+
+// ResponseCompressionConfig controls HTTP response compression. Each response
+// uses the encoding the client accepts with the highest q-value; ties go to
+// the earliest entry of Encodings. At project level, unset fields inherit the
+// server's values.
+type ResponseCompressionConfig struct {
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// Encodings offered to clients, most preferred first. Defaults to ["gzip"].
+	Encodings []ContentEncoding `yaml:"encodings,omitempty" json:"encodings,omitempty" tstype:"ContentEncoding[]"`
+	// Threshold is the minimum body size in bytes to compress. Defaults to 1024.
+	Threshold int `yaml:"threshold,omitempty" json:"threshold,omitempty"`
 }
 
 // ExecutionHeadersMode controls how much per-request execution detail is
@@ -195,6 +357,12 @@ type HealthCheckConfig struct {
 	Mode        HealthCheckMode `yaml:"mode,omitempty" json:"mode"`
 	Auth        *AuthConfig     `yaml:"auth,omitempty" json:"auth"`
 	DefaultEval string          `yaml:"defaultEval,omitempty" json:"defaultEval"`
+
+	// FailOnCacheUnavailable fails the healthcheck while a connector of
+	// database.evmJsonRpcCache is not healthy (still initializing, or
+	// unreachable), for deployments that must not take traffic without
+	// their cache. Default: false, the cache is treated as optional.
+	FailOnCacheUnavailable bool `yaml:"failOnCacheUnavailable,omitempty" json:"failOnCacheUnavailable"`
 }
 
 type HealthCheckMode string
@@ -253,11 +421,244 @@ type ForceTraceMatcher struct {
 	Method string `yaml:"method,omitempty" json:"method"`
 }
 
+// ManagementConfig is a second HTTP listener for the operator endpoints —
+// /metrics, /healthcheck, /admin and /debug/pprof/ — so they can be bound to
+// localhost or an internal interface, with their own TLS and auth, while the
+// public listener only serves RPC traffic.
+type ManagementConfig struct {
+	Host string     `yaml:"host,omitempty" json:"host"`
+	Port *int       `yaml:"port,omitempty" json:"port"`
+	TLS  *TLSConfig `yaml:"tls,omitempty" json:"tls"`
+
+	// Auth guards /metrics and /debug/pprof/. The healthcheck and admin API
+	// keep using healthCheck.auth and admin.auth.
+	Auth *AuthConfig `yaml:"auth,omitempty" json:"auth"`
+
+	Metrics     *bool `yaml:"metrics,omitempty" json:"metrics"`
+	HealthCheck *bool `yaml:"healthCheck,omitempty" json:"healthCheck"`
+	Admin       *bool `yaml:"admin,omitempty" json:"admin"`
+	Pprof       *bool `yaml:"pprof,omitempty" json:"pprof"`
+
+	// Exclusive stops serving the endpoints enabled here anywhere else: the
+	// public listener answers 404 for them and the metrics.port listener is
+	// not started.
+	Exclusive *bool `yaml:"exclusive,omitempty" json:"exclusive"`
+}
+
 type AdminConfig struct {
 	Auth *AuthConfig `yaml:"auth" json:"auth"`
 	CORS *CORSConfig `yaml:"cors" json:"cors"`
 }
 
+// SchedulerConfig declares maintenance jobs run on cron schedules. Each run
+// takes a cluster-wide lock (via the shared state connector) so a job fires on
+// one instance at a time, and recent runs are exposed via the admin API.
+type SchedulerConfig struct {
+	// HistorySize is how many past runs are kept per job.
+	HistorySize int                   `yaml:"historySize,omitempty" json:"historySize"`
+	Jobs        []*ScheduledJobConfig `yaml:"jobs,omitempty" json:"jobs"`
+}
+
+type ScheduledJobType string
+
+const (
+	ScheduledJobTypeBackfill     ScheduledJobType = "backfill"
+	ScheduledJobTypeWarm         ScheduledJobType = "warm"
+	ScheduledJobTypeCacheSweep   ScheduledJobType = "cacheSweep"
+	ScheduledJobTypeQuotaReset   ScheduledJobType = "quotaReset"
+	ScheduledJobTypeHealthReport ScheduledJobType = "healthReport"
+)
+
+type ScheduledJobConfig struct {
+	Id string `yaml:"id" json:"id"`
+	// Schedule is a 5-field cron expression ("*/10 * * * *"), a descriptor
+	// such as "@hourly", or "@every <duration>". Evaluated in UTC.
+	Schedule string           `yaml:"schedule" json:"schedule"`
+	Type     ScheduledJobType `yaml:"type" json:"type" tstype:"'backfill' | 'warm' | 'cacheSweep' | 'quotaReset' | 'healthReport'"`
+	// Timeout bounds a single run. The cluster-wide lock taken by a run is
+	// held until the next activation, or for Timeout if longer, so a crashed
+	// instance cannot block the job forever.
+	Timeout      Duration                     `yaml:"timeout,omitempty" json:"timeout" tstype:"Duration"`
+	Backfill     *ScheduledBackfillConfig     `yaml:"backfill,omitempty" json:"backfill,omitempty"`
+	Warm         *ScheduledWarmConfig         `yaml:"warm,omitempty" json:"warm,omitempty"`
+	CacheSweep   *ScheduledCacheSweepConfig   `yaml:"cacheSweep,omitempty" json:"cacheSweep,omitempty"`
+	QuotaReset   *ScheduledQuotaResetConfig   `yaml:"quotaReset,omitempty" json:"quotaReset,omitempty"`
+	HealthReport *ScheduledHealthReportConfig `yaml:"healthReport,omitempty" json:"healthReport,omitempty"`
+}
+
+// ScheduledBackfillConfig pre-populates the cache with the most recent
+// finalized blocks on every run (see the erpc_startBackfill admin method).
+type ScheduledBackfillConfig struct {
+	ProjectId string `yaml:"projectId" json:"projectId"`
+	NetworkId string `yaml:"networkId" json:"networkId"`
+	// LastBlocks is how many blocks, ending at the network's finalized head,
+	// are walked on each run.
+	LastBlocks  int64    `yaml:"lastBlocks" json:"lastBlocks"`
+	Methods     []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+	RateLimit   float64  `yaml:"rateLimit,omitempty" json:"rateLimit,omitempty"`
+	Concurrency int      `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+}
+
+// ScheduledWarmConfig forwards a fixed set of requests through a network so
+// their responses are (re)cached before clients ask for them.
+type ScheduledWarmConfig struct {
+	ProjectId string                  `yaml:"projectId" json:"projectId"`
+	NetworkId string                  `yaml:"networkId" json:"networkId"`
+	Requests  []*ScheduledWarmRequest `yaml:"requests" json:"requests"`
+}
+
+type ScheduledWarmRequest struct {
+	Method string        `yaml:"method" json:"method"`
+	Params []interface{} `yaml:"params,omitempty" json:"params,omitempty"`
+}
+
+// ScheduledCacheSweepConfig deletes the cache entries whose partition key
+// starts with PartitionKeyPrefix on every run (see the erpc_purgeCache admin
+// method), e.g. to drop a network's entries on connectors without TTLs.
+type ScheduledCacheSweepConfig struct {
+	// ConnectorId limits the sweep to one cache connector; all of them when empty.
+	ConnectorId        string `yaml:"connectorId,omitempty" json:"connectorId,omitempty"`
+	PartitionKeyPrefix string `yaml:"partitionKeyPrefix" json:"partitionKeyPrefix"`
+}
+
+// ScheduledQuotaResetConfig clears the per-consumer usage counters of a
+// project (see the erpc_myUsage self-service method), so they cover the
+// period since the last reset, e.g. a billing month.
+type ScheduledQuotaResetConfig struct {
+	ProjectId string `yaml:"projectId" json:"projectId"`
+	// UserIds limits the reset to these consumers; all of them when empty.
+	UserIds []string `yaml:"userIds,omitempty" json:"userIds,omitempty"`
+}
+
+// ScheduledHealthReportConfig posts the health of a project's upstreams (as
+// returned by the erpc_project admin method) to a webhook on every run.
+type ScheduledHealthReportConfig struct {
+	// ProjectId limits the report to one project; all of them when empty.
+	ProjectId string `yaml:"projectId,omitempty" json:"projectId,omitempty"`
+	Url       string `yaml:"url" json:"url"`
+	// Headers are added to every request, e.g. for authentication.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// AlertingConfig declares alert rules evaluated every EvaluationInterval
+// against the metrics this instance exports. An alert is identified by its
+// rule and the labels of the series that triggered it, and is delivered once
+// when it starts firing (again every RepeatInterval while it keeps firing)
+// and once when it resolves. Each instance evaluates and notifies on its own.
+type AlertingConfig struct {
+	EvaluationInterval Duration `yaml:"evaluationInterval,omitempty" json:"evaluationInterval" tstype:"Duration"`
+	// RepeatInterval re-notifies alerts that are still firing.
+	RepeatInterval Duration `yaml:"repeatInterval,omitempty" json:"repeatInterval" tstype:"Duration"`
+	// SendResolved notifies sinks when a firing alert clears.
+	SendResolved *bool              `yaml:"sendResolved,omitempty" json:"sendResolved"`
+	Sinks        []*AlertSinkConfig `yaml:"sinks,omitempty" json:"sinks"`
+	Rules        []*AlertRuleConfig `yaml:"rules,omitempty" json:"rules"`
+}
+
+type AlertSinkType string
+
+const (
+	AlertSinkTypeWebhook   AlertSinkType = "webhook"
+	AlertSinkTypeSlack     AlertSinkType = "slack"
+	AlertSinkTypePagerDuty AlertSinkType = "pagerduty"
+)
+
+type AlertSinkConfig struct {
+	Id   string        `yaml:"id" json:"id"`
+	Type AlertSinkType `yaml:"type" json:"type" tstype:"'webhook' | 'slack' | 'pagerduty'"`
+	// Url is the endpoint of a webhook sink or the incoming webhook URL of a
+	// Slack sink. PagerDuty sinks default to the Events API v2 endpoint.
+	Url string `yaml:"url,omitempty" json:"url,omitempty"`
+	// Headers are added to every webhook request, e.g. for authentication.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	// RoutingKey is the integration key of a PagerDuty Events API v2 service.
+	RoutingKey string `yaml:"routingKey,omitempty" json:"routingKey,omitempty"`
+}
+
+// alertSinkConfigRedacted is AlertSinkConfig without its methods, so the
+// marshalers below can redact the URL (Slack puts its secret in the path),
+// routing key and headers without recursing.
+type alertSinkConfigRedacted AlertSinkConfig
+
+func (s *AlertSinkConfig) redacted() alertSinkConfigRedacted {
+	cp := alertSinkConfigRedacted(*s)
+	if cp.Url != "" {
+		cp.Url = util.RedactEndpoint(cp.Url)
+	}
+	if cp.RoutingKey != "" {
+		cp.RoutingKey = "REDACTED"
+	}
+	if len(s.Headers) > 0 {
+		cp.Headers = make(map[string]string, len(s.Headers))
+		for k := range s.Headers {
+			cp.Headers[k] = "REDACTED"
+		}
+	}
+	return cp
+}
+
+func (s *AlertSinkConfig) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(s.redacted())
+}
+
+func (s *AlertSinkConfig) MarshalYAML() (interface{}, error) {
+	return s.redacted(), nil
+}
+
+type AlertRuleType string
+
+const (
+	// AlertRuleTypeUpstreamDown fires for every upstream cordoned on all
+	// methods or retired.
+	AlertRuleTypeUpstreamDown AlertRuleType = "upstreamDown"
+	// AlertRuleTypeConnectorUnhealthy fires for every connector that is not
+	// in the healthy state.
+	AlertRuleTypeConnectorUnhealthy AlertRuleType = "connectorUnhealthy"
+	// AlertRuleTypeErrorRate fires for every network whose share of failed
+	// requests since the previous evaluation is above Threshold.
+	AlertRuleTypeErrorRate AlertRuleType = "errorRate"
+	// AlertRuleTypeSubscriptionLag fires for every network whose slowest
+	// block stream subscriber is more than Threshold blocks behind the head.
+	AlertRuleTypeSubscriptionLag AlertRuleType = "subscriptionLag"
+	// AlertRuleTypeCacheHitRateBudget fires for every project, network and
+	// method whose last cache.hitRateReport interval was below its budget.
+	AlertRuleTypeCacheHitRateBudget AlertRuleType = "cacheHitRateBudget"
+	// AlertRuleTypeMetric compares any exported gauge (its value) or counter
+	// (its per-second rate since the previous evaluation) with Threshold.
+	AlertRuleTypeMetric AlertRuleType = "metric"
+)
+
+type AlertSeverity string
+
+const (
+	AlertSeverityInfo     AlertSeverity = "info"
+	AlertSeverityWarning  AlertSeverity = "warning"
+	AlertSeverityCritical AlertSeverity = "critical"
+)
+
+type AlertRuleConfig struct {
+	Id       string        `yaml:"id" json:"id"`
+	Type     AlertRuleType `yaml:"type" json:"type" tstype:"'upstreamDown' | 'connectorUnhealthy' | 'errorRate' | 'subscriptionLag' | 'cacheHitRateBudget' | 'metric'"`
+	Severity AlertSeverity `yaml:"severity,omitempty" json:"severity" tstype:"'info' | 'warning' | 'critical'"`
+	// Metric is the full name of the series a metric rule watches, e.g.
+	// "erpc_network_served_tip_advance_age_seconds".
+	Metric string `yaml:"metric,omitempty" json:"metric,omitempty"`
+	// Labels restrict the rule to series whose labels match these patterns,
+	// e.g. {project: main, network: "evm:*"}.
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	// Threshold is the error-rate fraction, the lag in blocks or the metric
+	// value above (or, with Below, under) which the rule fires.
+	Threshold float64 `yaml:"threshold,omitempty" json:"threshold"`
+	Below     bool    `yaml:"below,omitempty" json:"below,omitempty"`
+	// MinRequests ignores networks that served fewer requests since the
+	// previous evaluation, so an error-rate rule does not fire on one failure.
+	MinRequests int64 `yaml:"minRequests,omitempty" json:"minRequests,omitempty"`
+	// For is how long the condition must hold before the alert fires.
+	For Duration `yaml:"for,omitempty" json:"for" tstype:"Duration"`
+	// Sinks are the ids of the sinks to notify; empty notifies all of them.
+	Sinks []string `yaml:"sinks,omitempty" json:"sinks,omitempty"`
+}
+
 type AliasingConfig struct {
 	Rules []*AliasingRuleConfig `yaml:"rules" json:"rules"`
 }
@@ -272,6 +673,70 @@ type AliasingRuleConfig struct {
 type DatabaseConfig struct {
 	EvmJsonRpcCache *CacheConfig       `yaml:"evmJsonRpcCache,omitempty" json:"evmJsonRpcCache"`
 	SharedState     *SharedStateConfig `yaml:"sharedState,omitempty" json:"sharedState"`
+	Idempotency     *IdempotencyConfig `yaml:"idempotency,omitempty" json:"idempotency,omitempty"`
+	Journal         *JournalConfig     `yaml:"journal,omitempty" json:"journal,omitempty"`
+	Analytics       *AnalyticsConfig   `yaml:"analytics,omitempty" json:"analytics,omitempty"`
+}
+
+// IdempotencyConfig deduplicates retried write requests that carry an
+// idempotency key (Idempotency-Key header or "idempotencyKey" body field),
+// across every replica sharing the connector.
+type IdempotencyConfig struct {
+	// Connector stores the responses of completed requests and the locks of
+	// in-flight ones. Use a shared driver (redis, postgresql, dynamodb) so
+	// retries landing on another replica are deduplicated too.
+	Connector *ConnectorConfig `yaml:"connector,omitempty" json:"connector"`
+	// Ttl is how long a completed response is replayed for its key.
+	Ttl Duration `yaml:"ttl,omitempty" json:"ttl" tstype:"Duration"`
+	// LockTtl bounds how long an in-flight request holds its key; a retry
+	// with the same key waits up to this long for the original to finish.
+	LockTtl Duration `yaml:"lockTtl,omitempty" json:"lockTtl" tstype:"Duration"`
+	// Methods lists the methods (wildcards allowed) for which keys are
+	// honored. Keys sent with other methods are ignored.
+	Methods []string `yaml:"methods,omitempty" json:"methods"`
+}
+
+// JournalConfig records write requests (transaction broadcasts) while they
+// are in flight, so that requests abandoned by a crashed or killed replica
+// can be resolved — found on-chain, re-broadcast or reported lost — instead
+// of leaving their callers with an ambiguous timeout.
+type JournalConfig struct {
+	// Connector stores the journal. Use a shared driver (redis, postgresql,
+	// dynamodb) so surviving replicas can recover a crashed one's entries.
+	Connector *ConnectorConfig `yaml:"connector,omitempty" json:"connector"`
+	// Methods lists the methods (wildcards allowed) that are journaled.
+	Methods []string `yaml:"methods,omitempty" json:"methods"`
+	// Ttl is how long an entry is kept once its outcome is known.
+	Ttl Duration `yaml:"ttl,omitempty" json:"ttl" tstype:"Duration"`
+	// RecoverAfter is how old a pending entry must be before it is treated
+	// as abandoned. Keep it well above the longest request timeout.
+	RecoverAfter Duration `yaml:"recoverAfter,omitempty" json:"recoverAfter" tstype:"Duration"`
+	// RecoveryInterval is how often abandoned entries are looked for.
+	RecoveryInterval Duration `yaml:"recoveryInterval,omitempty" json:"recoveryInterval" tstype:"Duration"`
+	// Rebroadcast re-sends abandoned transactions that are not found on-chain
+	// instead of only reporting them lost. Requests without a transaction
+	// hash are never re-sent.
+	Rebroadcast *bool `yaml:"rebroadcast,omitempty" json:"rebroadcast,omitempty"`
+}
+
+// AnalyticsConfig aggregates proxied requests into per-minute and per-hour
+// rollups (requests, errors, credit units, cache hits by project, network,
+// method and consumer) and persists them through a connector, so usage can
+// be queried without an external analytics database.
+type AnalyticsConfig struct {
+	// Connector stores the rollups. Every replica writes its own records,
+	// which are summed when read, so replicas can share one store.
+	Connector *ConnectorConfig `yaml:"connector,omitempty" json:"connector"`
+	// FlushInterval is how often the rollups of open buckets are written.
+	FlushInterval Duration `yaml:"flushInterval,omitempty" json:"flushInterval" tstype:"Duration"`
+	// MinuteRetention is how long per-minute rollups are kept.
+	MinuteRetention Duration `yaml:"minuteRetention,omitempty" json:"minuteRetention" tstype:"Duration"`
+	// HourRetention is how long per-hour rollups are kept.
+	HourRetention Duration `yaml:"hourRetention,omitempty" json:"hourRetention" tstype:"Duration"`
+	// MaxRowsPerBucket caps the distinct network/method/consumer rows of one
+	// bucket of a replica; further combinations are folded into an "other"
+	// method row of their network.
+	MaxRowsPerBucket int `yaml:"maxRowsPerBucket,omitempty" json:"maxRowsPerBucket"`
 }
 
 type SharedStateConfig struct {
@@ -291,12 +756,162 @@ type SharedStateConfig struct {
 	// UpdateMaxWait caps how long the foreground path will spend computing a new value
 	// (e.g., polling latest block) before returning the current local value.
 	UpdateMaxWait Duration `yaml:"updateMaxWait,omitempty" json:"updateMaxWait" tstype:"Duration"`
+	// Routing shares each replica's view of upstream health through the
+	// connector, so that all replicas converge on the same routing decisions
+	// instead of each rediscovering a failing upstream. Off when nil.
+	Routing *SharedRoutingConfig `yaml:"routing,omitempty" json:"routing,omitempty"`
+	// Leases elects one replica to run single-writer work such as scheduled
+	// jobs, and hands the role to another replica when it dies. Off when nil,
+	// in which case such work is only guarded by per-run locks.
+	Leases *SharedLeasesConfig `yaml:"leases,omitempty" json:"leases,omitempty"`
+}
+
+// SharedLeasesConfig tunes the leases through which replicas elect the holder
+// of a single-writer role.
+type SharedLeasesConfig struct {
+	// Ttl is how long a holder keeps its lease without renewing it, and so
+	// how long a role stays vacant after its holder dies. Default: 15s.
+	Ttl Duration `yaml:"ttl,omitempty" json:"ttl" tstype:"Duration"`
+	// RenewInterval is how often the holder renews its lease and the other
+	// replicas try to take it over. Default: 5s.
+	RenewInterval Duration `yaml:"renewInterval,omitempty" json:"renewInterval" tstype:"Duration"`
+}
+
+// SharedRoutingConfig selects which upstream health signals replicas share.
+// Every replica periodically publishes its own signals and reads those of
+// its peers.
+type SharedRoutingConfig struct {
+	// SyncInterval is how often a replica publishes its signals and reads its
+	// peers'. Default: 5s.
+	SyncInterval Duration `yaml:"syncInterval,omitempty" json:"syncInterval" tstype:"Duration"`
+	// StaleAfter is how long a peer's signals are used after it published
+	// them, so replicas that stop publishing drop out. Default: 30s.
+	StaleAfter Duration `yaml:"staleAfter,omitempty" json:"staleAfter" tstype:"Duration"`
+	// Metrics adds the peers' request, error, throttled and misbehavior counts
+	// of each upstream and method to the rates upstreams are scored by.
+	// Default: true.
+	Metrics *bool `yaml:"metrics,omitempty" json:"metrics,omitempty"`
+	// CircuitBreakers cordons an upstream on every replica while its circuit
+	// breaker is open on any of them. Default: true.
+	CircuitBreakers *bool `yaml:"circuitBreakers,omitempty" json:"circuitBreakers,omitempty"`
 }
 
 type CacheConfig struct {
-	Connectors  []*ConnectorConfig   `yaml:"connectors,omitempty" json:"connectors" tstype:"TsConnectorConfig[]"`
-	Policies    []*CachePolicyConfig `yaml:"policies,omitempty" json:"policies"`
-	Compression *CompressionConfig   `yaml:"compression,omitempty" json:"compression"`
+	Connectors  []*ConnectorConfig    `yaml:"connectors,omitempty" json:"connectors" tstype:"TsConnectorConfig[]"`
+	Policies    []*CachePolicyConfig  `yaml:"policies,omitempty" json:"policies"`
+	Compression *CompressionConfig    `yaml:"compression,omitempty" json:"compression"`
+	Integrity   *CacheIntegrityConfig `yaml:"integrity,omitempty" json:"integrity"`
+	// GetTimeout is the hot-path budget for a cache lookup across all matching
+	// connectors. It never extends the request's own deadline, only shortens it.
+	GetTimeout Duration `yaml:"getTimeout,omitempty" json:"getTimeout" tstype:"Duration"`
+	// SetTimeout bounds a background cache write, which runs after the response
+	// has been sent and therefore is not tied to the request's deadline.
+	SetTimeout Duration `yaml:"setTimeout,omitempty" json:"setTimeout" tstype:"Duration"`
+	// HitRateReport periodically aggregates the cache hit rate per network and
+	// method, and warns when it falls below a configured budget. Off when nil.
+	HitRateReport *CacheHitRateReportConfig `yaml:"hitRateReport,omitempty" json:"hitRateReport,omitempty"`
+	// KeyHash selects how cache keys are derived from requests.
+	KeyHash *CacheKeyHashConfig `yaml:"keyHash,omitempty" json:"keyHash"`
+	// ErrorResults caches deterministic upstream errors so that repeating a
+	// failing call does not reach the upstreams again. Off when nil.
+	ErrorResults *CacheErrorResultsConfig `yaml:"errorResults,omitempty" json:"errorResults,omitempty"`
+	// CrossPopulate also writes the entries of other methods a cached block
+	// response answers, e.g. eth_getBlockByHash for eth_getBlockByNumber.
+	// Off when nil.
+	CrossPopulate *CacheCrossPopulateConfig `yaml:"crossPopulate,omitempty" json:"crossPopulate,omitempty"`
+	// Records stores the block number, finality and upstream of every value
+	// with it, and writes values conditionally so that a response derived
+	// from an unfinalized or earlier block never replaces one derived from a
+	// finalized block.
+	Records *CacheRecordsConfig `yaml:"records,omitempty" json:"records"`
+}
+
+// CacheRecordsConfig turns cached values into versioned records. Conditional
+// writes use the connector's native primitive (a ConditionExpression on
+// DynamoDB, a Lua script on Redis, ON CONFLICT ... WHERE on PostgreSQL);
+// other connectors write unconditionally.
+type CacheRecordsConfig struct {
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled"`
+}
+
+// CacheCrossPopulateConfig selects which entries are derived from a cached
+// eth_getBlockByNumber or eth_getBlockByHash response. Entries keyed by block
+// number are only derived from finalized blocks.
+type CacheCrossPopulateConfig struct {
+	// Blocks stores the block under the other of eth_getBlockByNumber and
+	// eth_getBlockByHash. Defaults to true.
+	Blocks *bool `yaml:"blocks,omitempty" json:"blocks"`
+	// TransactionsByIndex stores eth_getTransactionByBlockHashAndIndex and
+	// eth_getTransactionByBlockNumberAndIndex for every transaction of a
+	// full-transaction block. Defaults to true.
+	TransactionsByIndex *bool `yaml:"transactionsByIndex,omitempty" json:"transactionsByIndex"`
+	// TransactionsByHash stores eth_getTransactionByHash for every transaction
+	// of a full-transaction block, for indexers that fetch a block and then
+	// its transactions one by one. Defaults to true.
+	TransactionsByHash *bool `yaml:"transactionsByHash,omitempty" json:"transactionsByHash"`
+}
+
+// CacheErrorResultsConfig sets how long each class of deterministic error is
+// cached. Only execution reverts of calls at a finalized block and non-retryable
+// invalid-params errors are cached; transient errors (timeouts, rate limits,
+// server-side failures) never are. The TTL of a matching cache policy still
+// applies when it is shorter.
+type CacheErrorResultsConfig struct {
+	// ExecutionRevertedTtl defaults to 5m.
+	ExecutionRevertedTtl Duration `yaml:"executionRevertedTtl,omitempty" json:"executionRevertedTtl" tstype:"Duration"`
+	// InvalidParamsTtl defaults to 1m.
+	InvalidParamsTtl Duration `yaml:"invalidParamsTtl,omitempty" json:"invalidParamsTtl" tstype:"Duration"`
+}
+
+// CacheKeyHashAlgorithm hashes the canonical request params into a cache key.
+type CacheKeyHashAlgorithm string
+
+const (
+	CacheKeyHashSha256 CacheKeyHashAlgorithm = "sha256"
+	CacheKeyHashXxhash CacheKeyHashAlgorithm = "xxhash"
+	CacheKeyHashBlake3 CacheKeyHashAlgorithm = "blake3"
+)
+
+// CacheKeyHashConfig controls cache key derivation. Changing the algorithm
+// changes every key, so existing entries are no longer found.
+type CacheKeyHashConfig struct {
+	// Algorithm defaults to sha256. xxhash is the cheapest but only 64 bits
+	// wide; blake3 is as collision-resistant as sha256 and faster.
+	Algorithm CacheKeyHashAlgorithm `yaml:"algorithm,omitempty" json:"algorithm" tstype:"CacheKeyHashAlgorithm"`
+	// CollisionAudit stores the canonical request alongside every cached value
+	// and checks it on read, so a key collision is served as a miss instead of
+	// another request's result.
+	CollisionAudit *bool `yaml:"collisionAudit,omitempty" json:"collisionAudit"`
+}
+
+// CacheHitRateReportConfig controls the scheduled cache statistics report.
+// Every interval, lookups (hits + misses) are aggregated per project, network
+// and method; each aggregate with at least minRequests lookups is checked
+// against the first matching budget.
+type CacheHitRateReportConfig struct {
+	// Interval between reports. Defaults to 5m.
+	Interval Duration `yaml:"interval,omitempty" json:"interval" tstype:"Duration"`
+	// MinRequests is the number of lookups below which an aggregate is
+	// reported but never alerted on. Defaults to 100.
+	MinRequests int `yaml:"minRequests,omitempty" json:"minRequests"`
+	// Budgets are matched in order against network and method. Breaches are
+	// delivered by alerting rules of type cacheHitRateBudget.
+	Budgets []*CacheHitRateBudgetConfig `yaml:"budgets,omitempty" json:"budgets"`
+}
+
+// CacheHitRateBudgetConfig is the minimum acceptable hit rate for the networks
+// and methods it matches. Network and Method accept wildcards and default to "*".
+type CacheHitRateBudgetConfig struct {
+	Network    string  `yaml:"network,omitempty" json:"network"`
+	Method     string  `yaml:"method,omitempty" json:"method"`
+	MinHitRate float64 `yaml:"minHitRate" json:"minHitRate"`
+}
+
+// CacheIntegrityConfig stores a checksum alongside every cached value and
+// verifies it on read. A mismatch is treated as a cache miss and the record is
+// deleted so it gets refilled from an upstream.
+type CacheIntegrityConfig struct {
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled"`
 }
 
 type CompressionConfig struct {
@@ -340,6 +955,27 @@ type CachePolicyConfig struct {
 	// fallback component is also used as the cache storage expiry. See
 	// BlockTimeAdaptiveDuration.
 	TTL *BlockTimeAdaptiveDuration `yaml:"ttl,omitempty" json:"ttl,omitempty" tstype:"Duration | BlockTimeAdaptiveDuration"`
+
+	// MaxReadLatency bounds how long a request waits on this policy's connector
+	// before it is also forwarded to upstreams; whichever answers first is
+	// served. Zero (default) waits for the cache read up to cache.getTimeout.
+	MaxReadLatency Duration `yaml:"maxReadLatency,omitempty" json:"maxReadLatency,omitempty" tstype:"Duration"`
+
+	// StaleWhileRevalidate refreshes an entry from upstreams in the background
+	// when it is read within this window before its TTL runs out; the read is
+	// still served from the cache. Zero (default) lets entries expire.
+	StaleWhileRevalidate Duration `yaml:"staleWhileRevalidate,omitempty" json:"staleWhileRevalidate,omitempty" tstype:"Duration"`
+
+	// EmptyTtl stores emptyish results (null, [], "0x") for this long instead
+	// of ttl, even when empty is 'ignore', so that polling for data that does
+	// not exist yet (e.g. receipts of pending transactions) is answered from
+	// the cache. Zero (default) leaves emptyish results to empty.
+	EmptyTtl Duration `yaml:"emptyTtl,omitempty" json:"emptyTtl,omitempty" tstype:"Duration"`
+
+	// EmptyInvalidateOnNewHead stops serving an emptyish result stored with
+	// emptyTtl once the network has a newer latest block than when it was
+	// stored. Default: true when emptyTtl is set.
+	EmptyInvalidateOnNewHead *bool `yaml:"emptyInvalidateOnNewHead,omitempty" json:"emptyInvalidateOnNewHead,omitempty"`
 }
 
 type ConnectorDriverType string
@@ -350,6 +986,13 @@ const (
 	DriverPostgreSQL ConnectorDriverType = "postgresql"
 	DriverDynamoDB   ConnectorDriverType = "dynamodb"
 	DriverGrpc       ConnectorDriverType = "grpc"
+	DriverTiered     ConnectorDriverType = "tiered"
+	DriverMemcached  ConnectorDriverType = "memcached"
+	DriverLayered    ConnectorDriverType = "layered"
+	DriverCassandra  ConnectorDriverType = "cassandra"
+	DriverMongoDB    ConnectorDriverType = "mongodb"
+	DriverBadger     ConnectorDriverType = "badger"
+	DriverClickHouse ConnectorDriverType = "clickhouse"
 )
 
 type ConnectorConfig struct {
@@ -361,15 +1004,230 @@ type ConnectorConfig struct {
 	// request's selector admits it. Same glob/`!negation` grammar as upstream
 	// tags. Untagged connectors are always eligible (a use-upstream pin meant
 	// for upstreams must not disable a normal cache).
-	Tags            []string                   `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	// Residency labels where the connector stores its data (e.g. "eu"). A
+	// project with cacheResidency only reads and writes connectors with the
+	// same label.
+	Residency       string                     `yaml:"residency,omitempty" json:"residency,omitempty"`
 	Memory          *MemoryConnectorConfig     `yaml:"memory,omitempty" json:"memory"`
 	Redis           *RedisConnectorConfig      `yaml:"redis,omitempty" json:"redis"`
 	DynamoDB        *DynamoDBConnectorConfig   `yaml:"dynamodb,omitempty" json:"dynamodb"`
 	PostgreSQL      *PostgreSQLConnectorConfig `yaml:"postgresql,omitempty" json:"postgresql"`
 	Grpc            *GrpcConnectorConfig       `yaml:"grpc,omitempty" json:"grpc"`
+	Tiered          *TieredConnectorConfig     `yaml:"tiered,omitempty" json:"tiered"`
+	Memcached       *MemcachedConnectorConfig  `yaml:"memcached,omitempty" json:"memcached"`
+	Cassandra       *CassandraConnectorConfig  `yaml:"cassandra,omitempty" json:"cassandra"`
+	MongoDB         *MongoDBConnectorConfig    `yaml:"mongodb,omitempty" json:"mongodb"`
+	Badger          *BadgerConnectorConfig     `yaml:"badger,omitempty" json:"badger"`
+	ClickHouse      *ClickHouseConnectorConfig `yaml:"clickhouse,omitempty" json:"clickhouse"`
+	Layered         *LayeredConnectorConfig    `yaml:"layered,omitempty" json:"layered"`
 	FailsafeForGets []*FailsafeConfig          `yaml:"failsafeForGets,omitempty" json:"failsafeForGets"`
 	FailsafeForSets []*FailsafeConfig          `yaml:"failsafeForSets,omitempty" json:"failsafeForSets"`
-	Mock            *MockConnectorConfig       `yaml:"-" json:"-"`
+	// Tombstones makes Delete soft: see TombstoneConfig. Nil deletes entries
+	// immediately.
+	Tombstones *TombstoneConfig `yaml:"tombstones,omitempty" json:"tombstones,omitempty"`
+	// Overflow stores values too large for the connector in S3: see
+	// OverflowConfig. Nil passes every value to the connector as is.
+	Overflow *OverflowConfig `yaml:"overflow,omitempty" json:"overflow,omitempty"`
+	// Compression compresses values before they reach the connector (and
+	// overflow): see ConnectorCompressionConfig. Nil stores values as is.
+	Compression *ConnectorCompressionConfig `yaml:"compression,omitempty" json:"compression,omitempty"`
+	// Encryption encrypts values at rest with AES-GCM: see
+	// ConnectorEncryptionConfig. Nil stores values in plaintext.
+	Encryption *ConnectorEncryptionConfig `yaml:"encryption,omitempty" json:"encryption,omitempty"`
+	// CircuitBreaker stops sending operations to a backend that keeps
+	// failing: see ConnectorCircuitBreakerConfig.
+	CircuitBreaker *ConnectorCircuitBreakerConfig `yaml:"circuitBreaker,omitempty" json:"circuitBreaker,omitempty"`
+	Mock           *MockConnectorConfig           `yaml:"-" json:"-"`
+}
+
+// ConnectorCircuitBreakerConfig opens a breaker around a connector after
+// consecutive transport failures or timeouts. While it is open, reads and
+// writes fail immediately instead of waiting for the backend to time out, so
+// the cache is bypassed. Once ProbeInterval has passed, one operation (or a
+// background ping if no operation comes) checks the backend, and a success
+// closes the breaker again.
+type ConnectorCircuitBreakerConfig struct {
+	// Enabled defaults to true for drivers that reach a backend over the
+	// network, and to false for memory, badger, tiered and layered
+	// connectors (the connectors a tiered or layered one is made of have
+	// their own breakers).
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// FailureThreshold is how many consecutive failures open the breaker.
+	// Defaults to 5.
+	FailureThreshold int `yaml:"failureThreshold,omitempty" json:"failureThreshold,omitempty"`
+	// ProbeInterval is how long the breaker stays open before the backend
+	// is checked again. Defaults to 10s.
+	ProbeInterval Duration `yaml:"probeInterval,omitempty" json:"probeInterval,omitempty" tstype:"Duration"`
+}
+
+// ConnectorEncryptionConfig encrypts values with AES-256-GCM before they
+// reach the connector (and overflow) and decrypts them on reads. Each stored
+// value names the key it was encrypted with, so keys can be rotated: add the
+// new key, make it the primary, and keep the old one listed until the values
+// it encrypted have expired.
+type ConnectorEncryptionConfig struct {
+	// PrimaryKeyId is the key new values are encrypted with. Default: the
+	// first key.
+	PrimaryKeyId string `yaml:"primaryKeyId,omitempty" json:"primaryKeyId,omitempty"`
+
+	// Keys are every key values may be encrypted with.
+	Keys []*EncryptionKeyConfig `yaml:"keys" json:"keys"`
+}
+
+// EncryptionKeyConfig loads one 256-bit data key, either as base64 from an
+// environment variable or as a data key encrypted by AWS KMS (the
+// CiphertextBlob of kms:GenerateDataKey), decrypted once at startup.
+type EncryptionKeyConfig struct {
+	// Id is written with every value encrypted with this key, so it must not
+	// be reused for a different key. At most 255 bytes.
+	Id string `yaml:"id" json:"id"`
+
+	// Source is "env" or "kms". Default: inferred from the fields set.
+	Source string `yaml:"source,omitempty" json:"source,omitempty" tstype:"'env' | 'kms'"`
+
+	// Env is the environment variable holding the base64 key (source env).
+	Env string `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// EncryptedDataKey is the base64 KMS ciphertext of the key (source kms).
+	EncryptedDataKey string `yaml:"encryptedDataKey,omitempty" json:"encryptedDataKey,omitempty"`
+
+	// KmsRegion and Auth are used to call kms:Decrypt (source kms); nil Auth
+	// uses the default AWS credential chain.
+	KmsRegion string         `yaml:"kmsRegion,omitempty" json:"kmsRegion,omitempty"`
+	Auth      *AwsAuthConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
+}
+
+// ConnectorCompressionConfig compresses values of at least Threshold bytes
+// on Set and decompresses them on Get, List and Scan. Compressed values carry
+// a short header naming the algorithm, so values written uncompressed or with
+// another algorithm stay readable when the config changes.
+type ConnectorCompressionConfig struct {
+	// Algorithm is "zstd" (smaller) or "snappy" (faster). Default: zstd.
+	Algorithm string `yaml:"algorithm,omitempty" json:"algorithm,omitempty" tstype:"'zstd' | 'snappy'"`
+
+	// Threshold is the smallest value size (e.g. "1KB") that is compressed.
+	// Default: 1KB.
+	Threshold string `yaml:"threshold,omitempty" json:"threshold,omitempty" tstype:"ByteSize"`
+
+	// ZstdLevel is "fastest", "default", "better" or "best". Default:
+	// fastest.
+	ZstdLevel string `yaml:"zstdLevel,omitempty" json:"zstdLevel,omitempty"`
+}
+
+// OverflowConfig moves values larger than Threshold (e.g. eth_getLogs over a
+// wide range, debug_traceBlock) out of the connector into S3 and keeps only a
+// small pointer in the connector, so stores with an item size limit such as
+// DynamoDB (400KB) can still cache them. Objects expire through S3 lifecycle
+// rules; see S3OverflowConfig.
+type OverflowConfig struct {
+	// Threshold is the value size (e.g. "350KB") above which values are
+	// written to S3.
+	Threshold string            `yaml:"threshold,omitempty" json:"threshold" tstype:"ByteSize"`
+	S3        *S3OverflowConfig `yaml:"s3,omitempty" json:"s3"`
+}
+
+// S3OverflowConfig is where oversized values are stored. Objects are grouped
+// under "<prefix>ttl-<N>d/" by their TTL rounded up to 1, 7, 30, 90 or 365
+// days (or "<prefix>permanent/"), so one lifecycle rule per group expires
+// them.
+type S3OverflowConfig struct {
+	Bucket   string         `yaml:"bucket,omitempty" json:"bucket"`
+	Prefix   string         `yaml:"prefix,omitempty" json:"prefix"`
+	Region   string         `yaml:"region,omitempty" json:"region"`
+	Endpoint string         `yaml:"endpoint,omitempty" json:"endpoint"`
+	Auth     *AwsAuthConfig `yaml:"auth,omitempty" json:"auth"`
+
+	// ForcePathStyle addresses the bucket as "<endpoint>/<bucket>" instead of
+	// a virtual host, as most S3-compatible stores (MinIO, localstack) need.
+	ForcePathStyle bool `yaml:"forcePathStyle,omitempty" json:"forcePathStyle"`
+
+	// ManageLifecycle installs the expiration rules for the TTL groups on the
+	// bucket at startup, keeping any other rules. Disable it when the bucket's
+	// lifecycle is managed elsewhere (IaC), and create the same rules there.
+	ManageLifecycle *bool `yaml:"manageLifecycle,omitempty" json:"manageLifecycle,omitempty"`
+
+	InitTimeout Duration `yaml:"initTimeout,omitempty" json:"initTimeout" tstype:"Duration"`
+	GetTimeout  Duration `yaml:"getTimeout,omitempty" json:"getTimeout" tstype:"Duration"`
+	SetTimeout  Duration `yaml:"setTimeout,omitempty" json:"setTimeout" tstype:"Duration"`
+}
+
+// TombstoneConfig turns connector deletes (reorg invalidation, purges,
+// corrupted values) into tombstones: the entry is overwritten with a marker
+// that every replica reads as a miss, and is physically deleted in background
+// batches once GracePeriod has passed. Writes to a tombstoned key made by the
+// same replica during the grace period are dropped, so an in-flight upstream
+// response cannot resurrect the invalidated value.
+type TombstoneConfig struct {
+	// GracePeriod is how long the tombstone is kept (and its TTL) before the
+	// key is physically deleted.
+	GracePeriod Duration `yaml:"gracePeriod,omitempty" json:"gracePeriod" tstype:"Duration"`
+
+	// BatchSize caps how many keys are physically deleted per flush.
+	BatchSize int `yaml:"batchSize,omitempty" json:"batchSize"`
+
+	// FlushInterval is how often due tombstones are physically deleted.
+	FlushInterval Duration `yaml:"flushInterval,omitempty" json:"flushInterval" tstype:"Duration"`
+}
+
+// TieredConnectorConfig splits a cache between a small, fast "hot" connector
+// (memory/Redis/DynamoDB) and a deep, cheap "cold" one (e.g. PostgreSQL or a
+// gRPC-backed archive). Entries that outlive OffloadAfter (finalized blocks,
+// old receipts) are written to both tiers but only kept in the hot tier for
+// OffloadAfter, so the hot store stays bounded while the cold store retains
+// the long tail. Reads go to the hot tier first and transparently fall back
+// to the cold tier on a miss.
+type TieredConnectorConfig struct {
+	Hot  *ConnectorConfig `yaml:"hot" json:"hot" tstype:"TsConnectorConfig"`
+	Cold *ConnectorConfig `yaml:"cold" json:"cold" tstype:"TsConnectorConfig"`
+
+	// OffloadAfter is how long an entry stays in the hot tier. Entries whose
+	// TTL is longer (or unlimited) are also written to the cold tier with their
+	// original TTL; shorter-lived entries (e.g. realtime data) never leave the
+	// hot tier.
+	OffloadAfter Duration `yaml:"offloadAfter,omitempty" json:"offloadAfter" tstype:"Duration"`
+
+	// PromoteOnRead copies a cold-tier hit back into the hot tier (for
+	// OffloadAfter) so repeatedly requested historical data is served hot.
+	PromoteOnRead *bool `yaml:"promoteOnRead,omitempty" json:"promoteOnRead,omitempty"`
+}
+
+type LayeredWriteMode string
+
+const (
+	LayeredWriteThrough LayeredWriteMode = "writeThrough"
+	LayeredWriteBack    LayeredWriteMode = "writeBack"
+)
+
+// LayeredConnectorConfig puts an in-process memory cache (L1) in front of a
+// shared remote connector (L2) such as Redis, DynamoDB or PostgreSQL, so hot
+// keys like latest-block responses are served without a network round trip.
+// Unlike TieredConnectorConfig every entry is written to L2, whatever its TTL,
+// so replicas keep sharing what each of them fetched.
+type LayeredConnectorConfig struct {
+	L1 *MemoryConnectorConfig `yaml:"l1,omitempty" json:"l1"`
+	L2 *ConnectorConfig       `yaml:"l2" json:"l2" tstype:"TsConnectorConfig"`
+
+	// L1Ttl caps how long an entry is kept in L1. It bounds how long a
+	// replica can serve a value that was overwritten or deleted in L2 by
+	// another replica.
+	L1Ttl Duration `yaml:"l1Ttl,omitempty" json:"l1Ttl" tstype:"Duration"`
+
+	// L2Ttl caps the TTL of entries written to L2. Zero keeps the TTL of the
+	// cache policy.
+	L2Ttl Duration `yaml:"l2Ttl,omitempty" json:"l2Ttl" tstype:"Duration"`
+
+	// WriteMode is "writeThrough" (Set returns once both tiers are written)
+	// or "writeBack" (Set returns once L1 is written; L2 writes are queued
+	// and flushed in batches every FlushInterval).
+	WriteMode LayeredWriteMode `yaml:"writeMode,omitempty" json:"writeMode" tstype:"'writeThrough' | 'writeBack'"`
+
+	// FlushInterval is how often queued L2 writes are flushed (writeBack).
+	FlushInterval Duration `yaml:"flushInterval,omitempty" json:"flushInterval" tstype:"Duration"`
+
+	// MaxPending caps the queued L2 writes (writeBack); beyond it writes go to
+	// L2 synchronously, as in writeThrough.
+	MaxPending int `yaml:"maxPending,omitempty" json:"maxPending"`
 }
 
 type GrpcConnectorConfig struct {
@@ -421,6 +1279,57 @@ type RedisConnectorConfig struct {
 	SetTimeout        Duration            `yaml:"setTimeout,omitempty" json:"setTimeout" tstype:"Duration"`
 	LockRetryInterval Duration            `yaml:"lockRetryInterval,omitempty" json:"lockRetryInterval" tstype:"Duration"`
 	IAMAuth           *RedisIAMAuthConfig `yaml:"iamAuth,omitempty" json:"iamAuth,omitempty"`
+
+	// Cluster connects to a Redis Cluster instead of a single server. The
+	// credentials, TLS and timeouts of uri/addr apply to every node.
+	Cluster *RedisClusterConfig `yaml:"cluster,omitempty" json:"cluster,omitempty"`
+	// Sentinel discovers the current master through Redis Sentinel and
+	// follows failovers. The credentials, db, TLS and timeouts of uri/addr
+	// apply to the master; its host is ignored.
+	Sentinel *RedisSentinelConfig `yaml:"sentinel,omitempty" json:"sentinel,omitempty"`
+}
+
+// RedisClusterConfig configures the Redis connector for a Redis Cluster.
+// Keys are routed to their hash slot, so a "{tag}" in a key places it with
+// the other keys of the same tag.
+type RedisClusterConfig struct {
+	// Addrs are "host:port" seed nodes the rest of the cluster is discovered
+	// from. The host of uri/addr is always used as a seed too.
+	Addrs []string `yaml:"addrs,omitempty" json:"addrs,omitempty"`
+	// MaxRedirects caps the MOVED/ASK redirects followed per command.
+	// Defaults to 3.
+	MaxRedirects int `yaml:"maxRedirects,omitempty" json:"maxRedirects,omitempty"`
+}
+
+// RedisSentinelConfig configures the Redis connector for a Sentinel-managed
+// master.
+type RedisSentinelConfig struct {
+	// MasterName is the name the sentinels monitor the master under.
+	MasterName string `yaml:"masterName" json:"masterName"`
+	// Addrs are the "host:port" addresses of the sentinels.
+	Addrs []string `yaml:"addrs" json:"addrs"`
+	// Username and Password authenticate to the sentinels, which often use
+	// other ACL users than the data nodes.
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+	Password string `yaml:"password,omitempty" json:"-"`
+}
+
+func (s *RedisSentinelConfig) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(map[string]interface{}{
+		"masterName": s.MasterName,
+		"addrs":      s.Addrs,
+		"username":   s.Username,
+		"password":   "REDACTED",
+	})
+}
+
+func (s *RedisSentinelConfig) MarshalYAML() (interface{}, error) {
+	return map[string]interface{}{
+		"masterName": s.MasterName,
+		"addrs":      s.Addrs,
+		"username":   s.Username,
+		"password":   "REDACTED",
+	}, nil
 }
 
 func (r *RedisConnectorConfig) MarshalJSON() ([]byte, error) {
@@ -436,6 +1345,8 @@ func (r *RedisConnectorConfig) MarshalJSON() ([]byte, error) {
 		"getTimeout":   r.GetTimeout.String(),
 		"setTimeout":   r.SetTimeout.String(),
 		"iamAuth":      r.IAMAuth,
+		"cluster":      r.Cluster,
+		"sentinel":     r.Sentinel,
 	})
 }
 
@@ -453,9 +1364,32 @@ func (r *RedisConnectorConfig) MarshalYAML() (interface{}, error) {
 		"setTimeout":        r.SetTimeout.String(),
 		"lockRetryInterval": r.LockRetryInterval.String(),
 		"iamAuth":           r.IAMAuth,
+		"cluster":           r.Cluster,
+		"sentinel":          r.Sentinel,
 	}, nil
 }
 
+// MemcachedConnectorConfig stores entries in one or more memcached servers,
+// spread over them by key hash. Memcached cannot enumerate keys, so List and
+// Scan are not supported; the reverse index is emulated with extra keys.
+type MemcachedConnectorConfig struct {
+	// Servers are the memcached "host:port" addresses (or unix socket paths).
+	Servers []string `yaml:"servers" json:"servers"`
+	// KeyPrefix is prepended to every key, to share servers between
+	// deployments.
+	KeyPrefix string     `yaml:"keyPrefix,omitempty" json:"keyPrefix"`
+	TLS       *TLSConfig `yaml:"tls,omitempty" json:"tls"`
+	// ConnPoolSize is the number of idle connections kept per server.
+	ConnPoolSize      int      `yaml:"connPoolSize,omitempty" json:"connPoolSize"`
+	InitTimeout       Duration `yaml:"initTimeout,omitempty" json:"initTimeout" tstype:"Duration"`
+	GetTimeout        Duration `yaml:"getTimeout,omitempty" json:"getTimeout" tstype:"Duration"`
+	SetTimeout        Duration `yaml:"setTimeout,omitempty" json:"setTimeout" tstype:"Duration"`
+	LockRetryInterval Duration `yaml:"lockRetryInterval,omitempty" json:"lockRetryInterval" tstype:"Duration"`
+	// StatePollInterval is how often shared counters are polled, since
+	// memcached has no pub/sub.
+	StatePollInterval Duration `yaml:"statePollInterval,omitempty" json:"statePollInterval" tstype:"Duration"`
+}
+
 type DynamoDBConnectorConfig struct {
 	Table             string         `yaml:"table,omitempty" json:"table"`
 	Region            string         `yaml:"region,omitempty" json:"region"`
@@ -471,6 +1405,201 @@ type DynamoDBConnectorConfig struct {
 	MaxRetries        int            `yaml:"maxRetries,omitempty" json:"maxRetries"`
 	StatePollInterval Duration       `yaml:"statePollInterval,omitempty" json:"statePollInterval" tstype:"Duration"`
 	LockRetryInterval Duration       `yaml:"lockRetryInterval,omitempty" json:"lockRetryInterval" tstype:"Duration"`
+
+	// ChunkSize is the largest value stored in a single item. Larger values
+	// are split into chunk items of this size plus a manifest item under the
+	// original key, to stay below DynamoDB's 400KB item limit.
+	ChunkSize string `yaml:"chunkSize,omitempty" json:"chunkSize" tstype:"ByteSize"`
+
+	// Dax routes item reads and writes through a DynamoDB Accelerator cluster
+	// in front of the table. Table management always goes to DynamoDB.
+	Dax *DynamoDBDaxConfig `yaml:"dax,omitempty" json:"dax,omitempty"`
+}
+
+// DynamoDBDaxConfig points the DynamoDB connector at a DAX cluster. While the
+// cluster is unreachable, calls fall back to the plain DynamoDB client.
+type DynamoDBDaxConfig struct {
+	// Endpoint is the cluster discovery endpoint, "dax://<host>:8111", or
+	// "daxs://<host>" for clusters with encryption in transit.
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+	// FallbackFor is how long calls go straight to DynamoDB after the cluster
+	// failed to answer, before it is tried again.
+	FallbackFor Duration `yaml:"fallbackFor,omitempty" json:"fallbackFor" tstype:"Duration"`
+}
+
+// CassandraConnectorConfig stores entries in a Cassandra or ScyllaDB table
+// with the partition key as the row partition and the range key as the
+// clustering column. A second table keyed by range key serves the reverse
+// index. TTLs are native row TTLs.
+type CassandraConnectorConfig struct {
+	// Hosts are the contact points ("host" or "host:port", default port 9042).
+	Hosts []string `yaml:"hosts" json:"hosts"`
+	// Keyspace must exist; the connector creates its tables in it.
+	Keyspace string `yaml:"keyspace" json:"keyspace"`
+	Table    string `yaml:"table,omitempty" json:"table"`
+	// LocalDC routes queries to the replicas of this datacenter first.
+	LocalDC string `yaml:"localDC,omitempty" json:"localDC"`
+	// Consistency of reads and writes, e.g. "LOCAL_QUORUM" or "LOCAL_ONE".
+	Consistency string     `yaml:"consistency,omitempty" json:"consistency"`
+	Username    string     `yaml:"username,omitempty" json:"username"`
+	Password    string     `yaml:"password,omitempty" json:"password"`
+	TLS         *TLSConfig `yaml:"tls,omitempty" json:"tls"`
+	// NumConns is the number of connections opened to each host.
+	NumConns          int      `yaml:"numConns,omitempty" json:"numConns"`
+	InitTimeout       Duration `yaml:"initTimeout,omitempty" json:"initTimeout" tstype:"Duration"`
+	GetTimeout        Duration `yaml:"getTimeout,omitempty" json:"getTimeout" tstype:"Duration"`
+	SetTimeout        Duration `yaml:"setTimeout,omitempty" json:"setTimeout" tstype:"Duration"`
+	StatePollInterval Duration `yaml:"statePollInterval,omitempty" json:"statePollInterval" tstype:"Duration"`
+	LockRetryInterval Duration `yaml:"lockRetryInterval,omitempty" json:"lockRetryInterval" tstype:"Duration"`
+}
+
+// cassandraConfigRedacted is CassandraConnectorConfig without its methods,
+// so the marshalers below can redact the password without recursing.
+type cassandraConfigRedacted CassandraConnectorConfig
+
+func (c *CassandraConnectorConfig) MarshalJSON() ([]byte, error) {
+	cp := cassandraConfigRedacted(*c)
+	if cp.Password != "" {
+		cp.Password = "REDACTED"
+	}
+	return sonic.Marshal(cp)
+}
+
+func (c *CassandraConnectorConfig) MarshalYAML() (interface{}, error) {
+	cp := cassandraConfigRedacted(*c)
+	if cp.Password != "" {
+		cp.Password = "REDACTED"
+	}
+	return cp, nil
+}
+
+// BadgerConnectorConfig stores entries in an embedded BadgerDB database on
+// local disk, for single-instance deployments that want a cache surviving
+// restarts without running Redis. Locks and shared counters are local to the
+// process, as for the memory connector.
+type BadgerConnectorConfig struct {
+	// Dir is the database directory, created if missing. Only one process
+	// can open it at a time.
+	Dir string `yaml:"dir" json:"dir"`
+	// MaxDiskSize caps the size of the live entries. Above it, the least
+	// recently used entries are evicted. Empty means no cap.
+	MaxDiskSize string `yaml:"maxDiskSize,omitempty" json:"maxDiskSize" tstype:"ByteSize"`
+	// EvictionInterval is how often the size is checked against MaxDiskSize.
+	EvictionInterval Duration `yaml:"evictionInterval,omitempty" json:"evictionInterval" tstype:"Duration"`
+	// GCInterval is how often value log files are garbage collected, so the
+	// space of expired, overwritten and evicted values is reclaimed.
+	GCInterval Duration `yaml:"gcInterval,omitempty" json:"gcInterval" tstype:"Duration"`
+	// GCDiscardRatio is the share of stale data above which a value log file
+	// is rewritten, between 0 and 1 exclusive.
+	GCDiscardRatio float64 `yaml:"gcDiscardRatio,omitempty" json:"gcDiscardRatio"`
+	// SyncWrites fsyncs every write. Without it a crash can lose the last
+	// writes, which a cache can usually afford.
+	SyncWrites  bool     `yaml:"syncWrites,omitempty" json:"syncWrites"`
+	InitTimeout Duration `yaml:"initTimeout,omitempty" json:"initTimeout" tstype:"Duration"`
+}
+
+// MongoDBConnectorConfig stores entries as documents of one collection with
+// a unique index on (partition key, range key), a second index on (range key,
+// partition key) for the reverse index, and a TTL index on the expiry date.
+type MongoDBConnectorConfig struct {
+	// URI is the mongodb:// or mongodb+srv:// connection string. Options in
+	// it are applied first; the fields below override them when set.
+	URI        string `yaml:"uri" json:"uri"`
+	Database   string `yaml:"database" json:"database"`
+	Collection string `yaml:"collection,omitempty" json:"collection"`
+	// ReplicaSet is the replica set name to connect to.
+	ReplicaSet string `yaml:"replicaSet,omitempty" json:"replicaSet"`
+	// ReadPreference is one of "primary", "primaryPreferred", "secondary",
+	// "secondaryPreferred" or "nearest". Reads from secondaries may lag.
+	ReadPreference string `yaml:"readPreference,omitempty" json:"readPreference"`
+	// WriteConcern is "majority" or the number of members that must
+	// acknowledge a write.
+	WriteConcern string     `yaml:"writeConcern,omitempty" json:"writeConcern"`
+	Username     string     `yaml:"username,omitempty" json:"username"`
+	Password     string     `yaml:"password,omitempty" json:"password"`
+	TLS          *TLSConfig `yaml:"tls,omitempty" json:"tls"`
+	// MaxPoolSize is the largest number of connections per server.
+	MaxPoolSize       uint64   `yaml:"maxPoolSize,omitempty" json:"maxPoolSize"`
+	InitTimeout       Duration `yaml:"initTimeout,omitempty" json:"initTimeout" tstype:"Duration"`
+	GetTimeout        Duration `yaml:"getTimeout,omitempty" json:"getTimeout" tstype:"Duration"`
+	SetTimeout        Duration `yaml:"setTimeout,omitempty" json:"setTimeout" tstype:"Duration"`
+	StatePollInterval Duration `yaml:"statePollInterval,omitempty" json:"statePollInterval" tstype:"Duration"`
+	LockRetryInterval Duration `yaml:"lockRetryInterval,omitempty" json:"lockRetryInterval" tstype:"Duration"`
+}
+
+// mongoDBConfigRedacted is MongoDBConnectorConfig without its methods, so the
+// marshalers below can redact the credentials without recursing.
+type mongoDBConfigRedacted MongoDBConnectorConfig
+
+func (m *MongoDBConnectorConfig) MarshalJSON() ([]byte, error) {
+	cp := mongoDBConfigRedacted(*m)
+	cp.URI = util.RedactEndpoint(cp.URI)
+	if cp.Password != "" {
+		cp.Password = "REDACTED"
+	}
+	return sonic.Marshal(cp)
+}
+
+func (m *MongoDBConnectorConfig) MarshalYAML() (interface{}, error) {
+	cp := mongoDBConfigRedacted(*m)
+	cp.URI = util.RedactEndpoint(cp.URI)
+	if cp.Password != "" {
+		cp.Password = "REDACTED"
+	}
+	return cp, nil
+}
+
+// ClickHouseConnectorConfig stores cache entries in an append-only
+// ClickHouse table over the HTTP interface, for long-term storage of
+// finalized responses (blocks, receipts, logs) that can also be queried for
+// analytics. Writes are buffered and inserted in batches; the table is
+// partitioned by expiry date so expired data is dropped a partition at a
+// time. It can only be used as a cache connector.
+type ClickHouseConnectorConfig struct {
+	// Url is the HTTP(S) interface endpoint, e.g. "http://clickhouse:8123".
+	Url      string     `yaml:"url" json:"url"`
+	Database string     `yaml:"database,omitempty" json:"database"`
+	Table    string     `yaml:"table,omitempty" json:"table"`
+	Username string     `yaml:"username,omitempty" json:"username"`
+	Password string     `yaml:"password,omitempty" json:"password"`
+	TLS      *TLSConfig `yaml:"tls,omitempty" json:"tls"`
+	// PartitionBy is the width of the expiry date partitions: "day", "week"
+	// or "month". It only applies when the table is created.
+	PartitionBy string `yaml:"partitionBy,omitempty" json:"partitionBy"`
+	// BatchSize is the largest number of rows sent in one INSERT. A full
+	// batch is flushed without waiting for FlushInterval.
+	BatchSize int `yaml:"batchSize,omitempty" json:"batchSize"`
+	// FlushInterval is the longest a write waits in the buffer.
+	FlushInterval Duration `yaml:"flushInterval,omitempty" json:"flushInterval" tstype:"Duration"`
+	// MaxBufferedRows caps the rows waiting to be inserted. Writes beyond it
+	// are rejected until the buffer drains.
+	MaxBufferedRows int      `yaml:"maxBufferedRows,omitempty" json:"maxBufferedRows"`
+	InitTimeout     Duration `yaml:"initTimeout,omitempty" json:"initTimeout" tstype:"Duration"`
+	GetTimeout      Duration `yaml:"getTimeout,omitempty" json:"getTimeout" tstype:"Duration"`
+	// SetTimeout bounds each batch INSERT and each DELETE.
+	SetTimeout Duration `yaml:"setTimeout,omitempty" json:"setTimeout" tstype:"Duration"`
+}
+
+// clickHouseConfigRedacted is ClickHouseConnectorConfig without its methods,
+// so the marshalers below can redact the credentials without recursing.
+type clickHouseConfigRedacted ClickHouseConnectorConfig
+
+func (c *ClickHouseConnectorConfig) MarshalJSON() ([]byte, error) {
+	cp := clickHouseConfigRedacted(*c)
+	cp.Url = util.RedactEndpoint(cp.Url)
+	if cp.Password != "" {
+		cp.Password = "REDACTED"
+	}
+	return sonic.Marshal(cp)
+}
+
+func (c *ClickHouseConnectorConfig) MarshalYAML() (interface{}, error) {
+	cp := clickHouseConfigRedacted(*c)
+	cp.Url = util.RedactEndpoint(cp.Url)
+	if cp.Password != "" {
+		cp.Password = "REDACTED"
+	}
+	return cp, nil
 }
 
 type PostgreSQLConnectorConfig struct {
@@ -520,30 +1649,49 @@ func (p *PostgreSQLConnectorConfig) MarshalYAML() (interface{}, error) {
 }
 
 type AwsAuthConfig struct {
-	Mode            string `yaml:"mode" json:"mode" tstype:"'file' | 'env' | 'secret'"` // "file", "env", "secret"
+	Mode            string `yaml:"mode" json:"mode" tstype:"'file' | 'env' | 'secret' | 'assumeRole' | 'webIdentity'"` // "file", "env", "secret", "assumeRole", "webIdentity"
 	CredentialsFile string `yaml:"credentialsFile" json:"credentialsFile"`
 	Profile         string `yaml:"profile" json:"profile"`
 	AccessKeyID     string `yaml:"accessKeyID" json:"accessKeyID"`
 	SecretAccessKey string `yaml:"secretAccessKey" json:"secretAccessKey"`
+	// RoleArn is the IAM role to assume (assumeRole) or to exchange the web
+	// identity token for (webIdentity; defaults to AWS_ROLE_ARN).
+	RoleArn string `yaml:"roleArn,omitempty" json:"roleArn,omitempty"`
+	// ExternalId is passed to sts:AssumeRole when the role's trust policy
+	// requires one (assumeRole only).
+	ExternalId string `yaml:"externalId,omitempty" json:"externalId,omitempty"`
+	// RoleSessionName names the assumed-role session (default "erpc").
+	RoleSessionName string `yaml:"roleSessionName,omitempty" json:"roleSessionName,omitempty"`
+	// WebIdentityTokenFile is the OIDC token file for webIdentity mode
+	// (defaults to AWS_WEB_IDENTITY_TOKEN_FILE, which EKS IRSA injects).
+	WebIdentityTokenFile string `yaml:"webIdentityTokenFile,omitempty" json:"webIdentityTokenFile,omitempty"`
 }
 
 func (a *AwsAuthConfig) MarshalJSON() ([]byte, error) {
 	return sonic.Marshal(map[string]interface{}{
-		"mode":            a.Mode,
-		"credentialsFile": a.CredentialsFile,
-		"profile":         a.Profile,
-		"accessKeyID":     a.AccessKeyID,
-		"secretAccessKey": "REDACTED",
+		"mode":                 a.Mode,
+		"credentialsFile":      a.CredentialsFile,
+		"profile":              a.Profile,
+		"accessKeyID":          a.AccessKeyID,
+		"secretAccessKey":      "REDACTED",
+		"roleArn":              a.RoleArn,
+		"externalId":           a.ExternalId,
+		"roleSessionName":      a.RoleSessionName,
+		"webIdentityTokenFile": a.WebIdentityTokenFile,
 	})
 }
 
 func (a *AwsAuthConfig) MarshalYAML() (interface{}, error) {
 	return map[string]interface{}{
-		"mode":            a.Mode,
-		"credentialsFile": a.CredentialsFile,
-		"profile":         a.Profile,
-		"accessKeyID":     a.AccessKeyID,
-		"secretAccessKey": "REDACTED",
+		"mode":                 a.Mode,
+		"credentialsFile":      a.CredentialsFile,
+		"profile":              a.Profile,
+		"accessKeyID":          a.AccessKeyID,
+		"secretAccessKey":      "REDACTED",
+		"roleArn":              a.RoleArn,
+		"externalId":           a.ExternalId,
+		"roleSessionName":      a.RoleSessionName,
+		"webIdentityTokenFile": a.WebIdentityTokenFile,
 	}, nil
 }
 
@@ -607,6 +1755,41 @@ type ProjectConfig struct {
 	AllowClientDirectives *string  `yaml:"allowClientDirectives,omitempty" json:"allowClientDirectives"`
 	IgnoreMethods         []string `yaml:"ignoreMethods,omitempty" json:"ignoreMethods"`
 	AllowMethods          []string `yaml:"allowMethods,omitempty" json:"allowMethods"`
+	// AllowWalletMethods forwards methods that use the accounts of upstream
+	// nodes (eth_accounts, eth_sign*, eth_sendTransaction, personal_*) instead
+	// of refusing them. Only enable it when the upstreams are nodes you run
+	// and their accounts are meant to be used through this project.
+	AllowWalletMethods bool `yaml:"allowWalletMethods,omitempty" json:"allowWalletMethods,omitempty"`
+	// MethodRewrites alias methods and rewrite params of inbound requests
+	// before cache lookup and upstream selection (e.g. force "safe" instead of
+	// "latest" for every eth_call of this project). First matching rule wins.
+	MethodRewrites []*MethodRewriteConfig `yaml:"methodRewrites,omitempty" json:"methodRewrites,omitempty"`
+	// SelfService exposes tenant-scoped erpc_my* methods on the project
+	// endpoint so consumers can inspect their own keys, usage, rate limits and
+	// recent errors using the same credentials they send requests with.
+	SelfService *SelfServiceConfig `yaml:"selfService,omitempty" json:"selfService,omitempty"`
+	// Capabilities exposes the erpc_capabilities method on network endpoints
+	// so clients can discover what the proxy offers for that network.
+	Capabilities *CapabilitiesConfig `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
+	// ResponseCompression overrides server.responseCompression for this
+	// project's endpoints, e.g. to disable compression or offer br/zstd.
+	ResponseCompression *ResponseCompressionConfig `yaml:"responseCompression,omitempty" json:"responseCompression,omitempty"`
+	// PreferredUpstreams lists upstream ids (e.g. dedicated nodes) that are
+	// tried first, in this order, for every network of the project whatever
+	// their score. The rest of the upstreams follow in policy order as a
+	// fallback.
+	PreferredUpstreams []string `yaml:"preferredUpstreams,omitempty" json:"preferredUpstreams,omitempty"`
+
+	// CacheResidency restricts the project's cache reads and writes to the
+	// database.evmJsonRpcCache connectors whose residency is this label
+	// (e.g. "eu"), for data that must stay in a region. Policies on other
+	// connectors are ignored for the project. Empty uses every policy.
+	CacheResidency string `yaml:"cacheResidency,omitempty" json:"cacheResidency,omitempty"`
+
+	// SubscriptionArchive writes the heads and logs served to subscription
+	// clients (eth_getFilterChanges on block and log filters) to object
+	// storage, as a historical event lake of what the project delivered.
+	SubscriptionArchive *SubscriptionArchiveConfig `yaml:"subscriptionArchive,omitempty" json:"subscriptionArchive,omitempty"`
 
 	// ScoreMetricsWindowSize is the tumbling window the per-upstream
 	// health tracker uses for its rolling counters (errorRate, p50/p70/
@@ -626,6 +1809,73 @@ type ProjectConfig struct {
 	LegacyProject *LegacyProjectFields `yaml:"-" json:"-"`
 }
 
+type SelfServiceConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// RecentErrorsSize is how many of the latest failed requests are kept per
+	// user for erpc_myRecentErrors.
+	RecentErrorsSize int `yaml:"recentErrorsSize,omitempty" json:"recentErrorsSize"`
+	// MaxTrackedUsers bounds the in-memory usage tracker; when exceeded the
+	// least recently seen user is evicted.
+	MaxTrackedUsers int `yaml:"maxTrackedUsers,omitempty" json:"maxTrackedUsers"`
+}
+
+type CapabilitiesConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+}
+
+// SubscriptionArchiveConfig batches subscription events into objects under
+// "<prefix>network=<networkId>/date=<YYYY-MM-DD>/", one file per event kind
+// and flush. GCS buckets are written through its S3-compatible XML API, with
+// HMAC keys as auth.
+type SubscriptionArchiveConfig struct {
+	// Path is the destination, "s3://bucket/prefix/" or "gs://bucket/prefix/".
+	Path string `yaml:"path" json:"path"`
+	// Format of the objects. Only "ndjson" (gzip-compressed newline-delimited
+	// JSON) is supported.
+	Format string `yaml:"format,omitempty" json:"format"`
+	// Events lists the kinds archived: "heads" and/or "logs".
+	Events []string `yaml:"events,omitempty" json:"events"`
+	Region string   `yaml:"region,omitempty" json:"region"`
+	// Endpoint overrides the storage endpoint, e.g. for MinIO. Defaults to
+	// https://storage.googleapis.com for gs:// paths.
+	Endpoint       string         `yaml:"endpoint,omitempty" json:"endpoint"`
+	ForcePathStyle bool           `yaml:"forcePathStyle,omitempty" json:"forcePathStyle"`
+	Auth           *AwsAuthConfig `yaml:"auth,omitempty" json:"auth"`
+	// FlushInterval is the longest an event waits in memory before upload.
+	FlushInterval Duration `yaml:"flushInterval,omitempty" json:"flushInterval" tstype:"Duration"`
+	// MaxRecords and MaxSize flush a batch early once it holds that many
+	// events or uncompressed bytes.
+	MaxRecords int    `yaml:"maxRecords,omitempty" json:"maxRecords"`
+	MaxSize    string `yaml:"maxSize,omitempty" json:"maxSize"`
+	// QueueSize bounds the responses waiting to be parsed; when full, new
+	// ones are dropped rather than slowing down requests.
+	QueueSize     int      `yaml:"queueSize,omitempty" json:"queueSize"`
+	UploadTimeout Duration `yaml:"uploadTimeout,omitempty" json:"uploadTimeout" tstype:"Duration"`
+}
+
+// BucketAndPrefix splits Path into the bucket and the key prefix, which is
+// empty or ends with "/". ok is false when Path is not an s3:// or gs:// URI
+// with a bucket.
+func (s *SubscriptionArchiveConfig) BucketAndPrefix() (bucket, prefix string, ok bool) {
+	var rest string
+	switch {
+	case strings.HasPrefix(s.Path, "s3://"):
+		rest = strings.TrimPrefix(s.Path, "s3://")
+	case strings.HasPrefix(s.Path, "gs://"):
+		rest = strings.TrimPrefix(s.Path, "gs://")
+	default:
+		return "", "", false
+	}
+	bucket, prefix, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", false
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return bucket, prefix, true
+}
+
 // LegacyProjectFields collects the deprecated project-level scoring +
 // routing keys. The translator inspects these to synthesize a
 // `selectionPolicy.eval` for each network and to emit deprecation
@@ -856,6 +2106,10 @@ type UpstreamConfig struct {
 	// copied onto every upstream the provider generates.
 	CreditUnits map[string]int64      `yaml:"creditUnits,omitempty" json:"creditUnits,omitempty"`
 	Shadow      *ShadowUpstreamConfig `yaml:"shadow,omitempty" json:"shadow"`
+	// MethodRewrites alias methods and rewrite params only for what is sent to
+	// this upstream; cache keys and other upstreams keep seeing the original
+	// request. Inherited from upstreamDefaults when unset.
+	MethodRewrites []*MethodRewriteConfig `yaml:"methodRewrites,omitempty" json:"methodRewrites,omitempty"`
 
 	// Routing holds per-upstream routing hints consumed by the selection
 	// policy. `scoreMultipliers` bias this upstream's rank inside
@@ -866,6 +2120,68 @@ type UpstreamConfig struct {
 	// inherits the project-level `upstreamDefaults.routing` (all-or-nothing,
 	// matching the Tags inheritance pattern).
 	Routing *UpstreamRoutingConfig `yaml:"routing,omitempty" json:"routing,omitempty"`
+
+	// Maintenance declares planned windows during which the upstream gets no
+	// new requests (in-flight ones finish) and its failures are not counted
+	// against its health metrics. Use the erpc_drainUpstream admin method for
+	// unplanned maintenance.
+	Maintenance []*UpstreamMaintenanceWindowConfig `yaml:"maintenance,omitempty" json:"maintenance,omitempty"`
+
+	// Canary rolls a newly added upstream into traffic gradually and rolls it
+	// back to 0% when it performs worse than the rest of the network's pool.
+	Canary *UpstreamCanaryConfig `yaml:"canary,omitempty" json:"canary,omitempty"`
+
+	// Retirement takes an upstream out of routing and health probing once it
+	// has failed every request for DeadFor. upstreamDown alerting rules
+	// notify operators.
+	// Inherited from upstreamDefaults when unset.
+	Retirement *UpstreamRetirementConfig `yaml:"retirement,omitempty" json:"retirement,omitempty"`
+}
+
+// UpstreamRetirementConfig retires an upstream that has not served a single
+// successful request for DeadFor while at least MinFailures requests failed
+// against it. A retired upstream stays out of routing until it is reinstated
+// via the erpc_unretireUpstream admin method. The failure history is written
+// to shared state, so a restart resumes it instead of starting over.
+type UpstreamRetirementConfig struct {
+	Enabled bool     `yaml:"enabled" json:"enabled"`
+	DeadFor Duration `yaml:"deadFor,omitempty" json:"deadFor,omitempty" tstype:"Duration"`
+	// MinFailures guards against retiring an upstream that merely saw no
+	// traffic: this many failures must be recorded since its last success.
+	MinFailures int64 `yaml:"minFailures,omitempty" json:"minFailures,omitempty"`
+}
+
+// UpstreamCanaryConfig describes a staged rollout: the upstream serves
+// Weights[i] of the network's requests for StepDuration before moving to the
+// next step. At every evaluation its error rate and latency are compared with
+// the other upstreams of the network; exceeding either threshold rolls it back
+// to 0% until reset via the erpc_resetCanary admin method.
+type UpstreamCanaryConfig struct {
+	Enabled      bool      `yaml:"enabled" json:"enabled"`
+	Weights      []float64 `yaml:"weights,omitempty" json:"weights,omitempty"`
+	StepDuration Duration  `yaml:"stepDuration,omitempty" json:"stepDuration,omitempty" tstype:"Duration"`
+	// MinSamples is the number of requests (in the current health-tracker
+	// window) required before the canary is compared with the pool or promoted.
+	MinSamples int64 `yaml:"minSamples,omitempty" json:"minSamples,omitempty"`
+	// MaxErrorRateDelta rolls back when the canary error rate exceeds the pool
+	// error rate by more than this many points, e.g. 0.05 = 5 percentage points.
+	MaxErrorRateDelta float64 `yaml:"maxErrorRateDelta,omitempty" json:"maxErrorRateDelta,omitempty"`
+	// MaxLatencyRatio rolls back when the canary p90 latency exceeds the pool
+	// p90 latency multiplied by this ratio.
+	MaxLatencyRatio float64 `yaml:"maxLatencyRatio,omitempty" json:"maxLatencyRatio,omitempty"`
+}
+
+// UpstreamMaintenanceWindowConfig is either recurring (Schedule + Duration) or
+// one-off (Start + End).
+type UpstreamMaintenanceWindowConfig struct {
+	// Schedule is a 5-field cron expression (UTC) that opens the window, which
+	// then stays open for Duration.
+	Schedule string   `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+	Duration Duration `yaml:"duration,omitempty" json:"duration,omitempty" tstype:"Duration"`
+	// Start and End are RFC3339 timestamps of a one-off window.
+	Start  string `yaml:"start,omitempty" json:"start,omitempty"`
+	End    string `yaml:"end,omitempty" json:"end,omitempty"`
+	Reason string `yaml:"reason,omitempty" json:"reason,omitempty"`
 }
 
 // UpstreamRoutingConfig holds per-upstream routing hints. Today this is
@@ -1097,6 +2413,29 @@ func (c *UpstreamConfig) Copy() *UpstreamConfig {
 		copy(copied.AllowMethods, c.AllowMethods)
 	}
 
+	if c.Maintenance != nil {
+		copied.Maintenance = make([]*UpstreamMaintenanceWindowConfig, len(c.Maintenance))
+		for i, w := range c.Maintenance {
+			if w != nil {
+				wc := *w
+				copied.Maintenance[i] = &wc
+			}
+		}
+	}
+
+	if c.Canary != nil {
+		cc := *c.Canary
+		if c.Canary.Weights != nil {
+			cc.Weights = make([]float64, len(c.Canary.Weights))
+			copy(cc.Weights, c.Canary.Weights)
+		}
+		copied.Canary = &cc
+	}
+	if c.Retirement != nil {
+		rc := *c.Retirement
+		copied.Retirement = &rc
+	}
+
 	return copied
 }
 
@@ -1177,6 +2516,13 @@ type RateLimitAutoTuneConfig struct {
 	DecreaseFactor     float64  `yaml:"decreaseFactor" json:"decreaseFactor"`
 	MinBudget          int      `yaml:"minBudget" json:"minBudget"`
 	MaxBudget          int      `yaml:"maxBudget" json:"maxBudget"`
+	// DiscoverLimits learns the provider's effective limit from its 429
+	// responses (RateLimit / X-RateLimit headers, or "N requests per second"
+	// in the error message) and lowers the budget straight to it.
+	DiscoverLimits *bool `yaml:"discoverLimits,omitempty" json:"discoverLimits,omitempty"`
+	// DiscoveredLimitTtl is how long a discovered limit caps increases after
+	// it was last seen; afterwards the tuner probes upward again.
+	DiscoveredLimitTtl Duration `yaml:"discoveredLimitTtl,omitempty" json:"discoveredLimitTtl,omitempty" tstype:"Duration"`
 }
 
 func (c *RateLimitAutoTuneConfig) Copy() *RateLimitAutoTuneConfig {
@@ -1738,6 +3084,13 @@ type ConsensusPolicyConfig struct {
 	// Default is false (normal behavior - cancel remaining requests on short-circuit).
 	FireAndForget bool `yaml:"fireAndForget,omitempty" json:"fireAndForget"`
 
+	// Quorum when true, returns as soon as agreementThreshold participants agree on
+	// the same non-empty result, instead of waiting until the leading group's lead
+	// is unassailable by the remaining participants. Remaining requests are then
+	// cancelled (unless FireAndForget is set). Scope it to the methods that need it
+	// with the failsafe's matchMethod. Default is false.
+	Quorum bool `yaml:"quorum,omitempty" json:"quorum"`
+
 	// MaxWaitOnResult caps how long consensus waits for additional participants
 	// AFTER at least one non-empty response has arrived. Use this to bound
 	// p99 latency when most upstreams are fast but one is a slow straggler:
@@ -1947,6 +3300,14 @@ type RateLimitRuleConfig struct {
 	PerIP      bool            `yaml:"perIP,omitempty" json:"perIP,omitempty"`
 	PerUser    bool            `yaml:"perUser,omitempty" json:"perUser,omitempty"`
 	PerNetwork bool            `yaml:"perNetwork,omitempty" json:"perNetwork,omitempty"`
+	// SmoothingWindow additionally enforces the rule in sub-windows of this
+	// period (e.g. "second" for a per-minute rule). Each sub-window admits its
+	// even share of maxCount plus Burst, so a whole period's quota cannot be
+	// spent at once right after a window boundary.
+	SmoothingWindow *RateLimitPeriod `yaml:"smoothingWindow,omitempty" json:"smoothingWindow,omitempty" tstype:"RateLimitPeriod"`
+	// Burst is the headroom each smoothing sub-window allows above its even
+	// share of maxCount. Requires SmoothingWindow.
+	Burst uint32 `yaml:"burst,omitempty" json:"burst,omitempty"`
 }
 
 // ScopeString returns a comma-separated list of enabled scopes in deterministic order.
@@ -2136,6 +3497,30 @@ type NetworkConfig struct {
 	StaticResponses   []*StaticResponseConfig  `yaml:"staticResponses,omitempty" json:"staticResponses,omitempty"`
 }
 
+// MethodRewriteConfig aliases a method and/or rewrites its positional params.
+// Method is a wildcard pattern matched against the incoming method name; the
+// first matching rule in a list is applied. ResultField optionally unwraps a
+// field of an object result so an aliased method can return the shape the
+// client asked for (e.g. alchemy_getTransactionReceipts → {receipts: [...]}).
+type MethodRewriteConfig struct {
+	Method      string                `yaml:"method" json:"method"`
+	Alias       string                `yaml:"alias,omitempty" json:"alias,omitempty"`
+	Params      []*ParamRewriteConfig `yaml:"params,omitempty" json:"params,omitempty"`
+	ResultField string                `yaml:"resultField,omitempty" json:"resultField,omitempty"`
+}
+
+// ParamRewriteConfig rewrites the param at Index. When From is set the rule
+// only applies if the current value equals it (e.g. "latest" → "safe"); when
+// To is set the value is replaced; when WrapField is set the resulting value is
+// wrapped into an object under that key (e.g. "0x1" → {"blockNumber":"0x1"}).
+// An Index equal to the current params length appends To as a new param.
+type ParamRewriteConfig struct {
+	Index     int         `yaml:"index" json:"index"`
+	From      interface{} `yaml:"from,omitempty" json:"from,omitempty"`
+	To        interface{} `yaml:"to,omitempty" json:"to,omitempty"`
+	WrapField string      `yaml:"wrapField,omitempty" json:"wrapField,omitempty"`
+}
+
 // StaticResponseConfig declares a canned JSON-RPC response for a specific
 // (method, params) pair on a network. When an inbound request matches, the
 // configured response is returned immediately and no upstream is contacted.
@@ -2380,12 +3765,321 @@ type EvmNetworkConfig struct {
 	//     finalized head; an unfinalized block's empty is treated as not-yet-confirmed.
 	EmptyResultConfidence AvailbilityConfidence `yaml:"emptyResultConfidence,omitempty" json:"emptyResultConfidence,omitempty"`
 
+	// BlockTagPolicy gives risk-averse consumers reorg-safe reads by default:
+	// user-supplied "latest" is rewritten to "safe"/"finalized" for the
+	// selected methods, and numeric blocks close to the head are treated as
+	// unfinalized for caching. Nil disables the policy. See
+	// EvmBlockTagPolicyConfig.
+	BlockTagPolicy *EvmBlockTagPolicyConfig `yaml:"blockTagPolicy,omitempty" json:"blockTagPolicy,omitempty"`
+
+	// Fork marks the network as a local fork of a live chain (e.g. anvil
+	// --fork-url). Reads of blocks up to the fork block go to the live
+	// upstreams, everything else to the fork nodes. Nil means not a fork.
+	// See EvmForkConfig.
+	Fork *EvmForkConfig `yaml:"fork,omitempty" json:"fork,omitempty"`
+
+	// NormalizeResponses rewrites block, transaction, receipt and log results
+	// into one canonical encoding (null vs missing fields, empty arrays,
+	// quantity padding, hex case, key order) so responses are byte-stable
+	// whichever node client answered. Default: false.
+	NormalizeResponses *bool `yaml:"normalizeResponses,omitempty" json:"normalizeResponses,omitempty"`
+
+	// GetLogsCompletenessCheck cross-checks a sample of eth_getLogs results
+	// for finalized ranges against an independent source, flagging upstreams
+	// that return silently truncated results. Nil disables it. See
+	// EvmGetLogsCompletenessConfig.
+	GetLogsCompletenessCheck *EvmGetLogsCompletenessConfig `yaml:"getLogsCompletenessCheck,omitempty" json:"getLogsCompletenessCheck,omitempty"`
+
+	// ReceiptsPrefetch fetches the receipts (and optionally the blocks and
+	// logs) of every new head into the cache before clients ask for them.
+	// Nil disables it. See EvmReceiptsPrefetchConfig.
+	ReceiptsPrefetch *EvmReceiptsPrefetchConfig `yaml:"receiptsPrefetch,omitempty" json:"receiptsPrefetch,omitempty"`
+
+	// Abi is a registry of known contract ABIs used to decode revert data
+	// and, through erpc_getDecodedLogs, event logs. Nil disables it. See
+	// EvmAbiConfig.
+	Abi *EvmAbiConfig `yaml:"abi,omitempty" json:"abi,omitempty"`
+
+	// AddressActivity serves erpc_addressActivity, which notifies clients
+	// when watched addresses appear in new blocks. Nil disables it. See
+	// EvmAddressActivityConfig.
+	AddressActivity *EvmAddressActivityConfig `yaml:"addressActivity,omitempty" json:"addressActivity,omitempty"`
+
+	// TagEmulation rewrites the "finalized"/"safe" block tags to concrete
+	// block numbers for nodes that do not support them, so clients can use
+	// them on every network. Nil disables it. See EvmTagEmulationConfig.
+	TagEmulation *EvmTagEmulationConfig `yaml:"tagEmulation,omitempty" json:"tagEmulation,omitempty"`
+
 	// Deprecated: replaced by EmptyResultConfidence (blockHead). Retained as a yaml-only
 	// key so existing configs keep loading; SetDefaults warns and ignores it. The old
 	// numeric distance band is gone — use emptyResultConfidence instead.
 	MaxFutureBlockRetryDistance *int64 `yaml:"maxFutureBlockRetryDistance,omitempty" json:"-"`
 }
 
+type GetLogsCompletenessSource string
+
+const (
+	// GetLogsCompletenessSourceReceipts recounts matching logs from the block
+	// receipts, using the header logsBloom to skip blocks that cannot match.
+	GetLogsCompletenessSourceReceipts GetLogsCompletenessSource = "receipts"
+	// GetLogsCompletenessSourceUpstream repeats the eth_getLogs request on a
+	// different upstream and compares the counts.
+	GetLogsCompletenessSourceUpstream GetLogsCompletenessSource = "upstream"
+)
+
+// EvmGetLogsCompletenessConfig verifies that eth_getLogs results of finalized
+// ranges are complete.
+type EvmGetLogsCompletenessConfig struct {
+	// Source is what results are compared against: "receipts" (default) or
+	// "upstream".
+	Source GetLogsCompletenessSource `yaml:"source,omitempty" json:"source,omitempty"`
+
+	// SampleRate is the fraction (0..1] of eligible responses that are
+	// checked. Default: 0.1.
+	SampleRate *float64 `yaml:"sampleRate,omitempty" json:"sampleRate,omitempty"`
+
+	// MaxBlockRange skips requests spanning more blocks than this, bounding
+	// the cost of a check (one header and up to one receipts lookup per block
+	// with the receipts source). Default: 10.
+	MaxBlockRange int64 `yaml:"maxBlockRange,omitempty" json:"maxBlockRange,omitempty"`
+
+	// Reject runs the check before the response is returned and turns a
+	// truncated result into a content validation error, so it is retried on
+	// another upstream. When false (default) the check runs in the background
+	// and only flags the upstream.
+	Reject *bool `yaml:"reject,omitempty" json:"reject,omitempty"`
+}
+
+// EvmReceiptsPrefetchConfig prefetches the data of new blocks on head
+// advances, while clients show interest in it (or always, with Always).
+type EvmReceiptsPrefetchConfig struct {
+	// Receipts prefetches eth_getBlockReceipts. Default: true.
+	Receipts *bool `yaml:"receipts,omitempty" json:"receipts,omitempty"`
+
+	// Blocks also prefetches eth_getBlockByNumber and, with the hash it
+	// returns, eth_getBlockByHash. Default: false.
+	Blocks *bool `yaml:"blocks,omitempty" json:"blocks,omitempty"`
+
+	// BlockFullTransactions requests prefetched blocks with full transaction
+	// objects instead of transaction hashes. Each variant is cached under
+	// its own key, so match what clients ask for. Default: false.
+	BlockFullTransactions *bool `yaml:"blockFullTransactions,omitempty" json:"blockFullTransactions,omitempty"`
+
+	// Logs also prefetches eth_getLogs of the whole block
+	// ({fromBlock: n, toBlock: n}, no address or topics). Default: false.
+	Logs *bool `yaml:"logs,omitempty" json:"logs,omitempty"`
+
+	// Always prefetches every enabled method on every head, without waiting
+	// for clients to request it first. Default: false.
+	Always *bool `yaml:"always,omitempty" json:"always,omitempty"`
+
+	// MaxConcurrency caps the blocks being prefetched at once; an advance
+	// that finds no free slot is skipped. Default: 4.
+	MaxConcurrency int `yaml:"maxConcurrency,omitempty" json:"maxConcurrency,omitempty"`
+
+	// InterestWindow is how recently a client must have requested a method
+	// (or a block stream subscriber been connected) for its data to be
+	// prefetched. Default: 1m.
+	InterestWindow Duration `yaml:"interestWindow,omitempty" json:"interestWindow,omitempty" tstype:"Duration"`
+}
+
+// EvmAddressActivityConfig bounds the address activity watchers of a
+// network. Each new block is scanned once for all watchers: its transactions
+// (sender and recipient) and, optionally, its token transfer logs.
+type EvmAddressActivityConfig struct {
+	// MaxWatchers caps the watchers installed at once. Default: 1000.
+	MaxWatchers int `yaml:"maxWatchers,omitempty" json:"maxWatchers,omitempty"`
+
+	// MaxAddresses caps the addresses of one watcher. Default: 1000.
+	MaxAddresses int `yaml:"maxAddresses,omitempty" json:"maxAddresses,omitempty"`
+
+	// MaxEvents caps the events buffered for one watcher between polls;
+	// the oldest are dropped beyond it. Default: 10000.
+	MaxEvents int `yaml:"maxEvents,omitempty" json:"maxEvents,omitempty"`
+
+	// WatcherTtl removes watchers that have not been polled for this long,
+	// like nodes do with filters. Default: 5m.
+	WatcherTtl Duration `yaml:"watcherTtl,omitempty" json:"watcherTtl,omitempty" tstype:"Duration"`
+
+	// TokenTransfers also matches the from and to of ERC-20/721 Transfer and
+	// ERC-1155 TransferSingle/TransferBatch logs, at the cost of one
+	// eth_getLogs per block. Default: true.
+	TokenTransfers *bool `yaml:"tokenTransfers,omitempty" json:"tokenTransfers,omitempty"`
+}
+
+// EvmTagEmulationConfig emulates block tags the network's nodes do not
+// support. Each emulated tag is resolved to a block number, either a fixed
+// depth below the latest block or the value reported by an L1 checkpoint
+// method, and requests carry that number instead of the tag.
+type EvmTagEmulationConfig struct {
+	// Tags lists the tags to emulate: "finalized" and/or "safe". Default:
+	// both.
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// FinalizedDepth is how many blocks below the latest block "finalized"
+	// is emulated at. Default: evm.fallbackFinalityDepth.
+	FinalizedDepth int64 `yaml:"finalizedDepth,omitempty" json:"finalizedDepth,omitempty"`
+
+	// SafeDepth is how many blocks below the latest block "safe" is
+	// emulated at. Default: finalizedDepth.
+	SafeDepth int64 `yaml:"safeDepth,omitempty" json:"safeDepth,omitempty"`
+
+	// Checkpoint reads the tags from a method reporting the L2 blocks
+	// checkpointed on L1 (e.g. optimism_syncStatus) instead of using
+	// depths. Nil uses depths.
+	Checkpoint *EvmTagCheckpointConfig `yaml:"checkpoint,omitempty" json:"checkpoint,omitempty"`
+}
+
+// EvmTagCheckpointConfig describes the method the emulated tags are read
+// from and where its result holds each block number.
+type EvmTagCheckpointConfig struct {
+	// Method is called through the network, e.g. optimism_syncStatus.
+	Method string `yaml:"method" json:"method"`
+
+	// Params are sent with Method. Default: none.
+	Params []interface{} `yaml:"params,omitempty" json:"params,omitempty"`
+
+	// FinalizedPath is the dot-separated path of the finalized block number
+	// in the result, e.g. "finalized_l2.number".
+	FinalizedPath string `yaml:"finalizedPath,omitempty" json:"finalizedPath,omitempty"`
+
+	// SafePath is the dot-separated path of the safe block number in the
+	// result, e.g. "safe_l2.number".
+	SafePath string `yaml:"safePath,omitempty" json:"safePath,omitempty"`
+
+	// RefreshInterval is how long a checkpoint is reused before it is
+	// fetched again. Default: 5s.
+	RefreshInterval Duration `yaml:"refreshInterval,omitempty" json:"refreshInterval,omitempty" tstype:"Duration"`
+}
+
+// Emulates reports whether tag is one of the emulated tags.
+func (c *EvmTagEmulationConfig) Emulates(tag string) bool {
+	return c != nil && slices.Contains(c.Tags, tag)
+}
+
+// EvmAbiConfig lists contract ABIs known to a network and where to fetch
+// the ABIs of other verified contracts.
+type EvmAbiConfig struct {
+	// Contracts are ABIs provided in config. They take precedence over
+	// fetched ABIs and are never refetched.
+	Contracts []*EvmAbiContractConfig `yaml:"contracts,omitempty" json:"contracts,omitempty"`
+
+	// Sourcify fetches the ABIs of contracts verified on Sourcify. Nil
+	// disables it.
+	Sourcify *EvmAbiSourcifyConfig `yaml:"sourcify,omitempty" json:"sourcify,omitempty"`
+
+	// Etherscan fetches the ABIs of contracts verified on Etherscan (API v2,
+	// which covers every chain Etherscan supports). Tried after Sourcify.
+	// Nil disables it.
+	Etherscan *EvmAbiEtherscanConfig `yaml:"etherscan,omitempty" json:"etherscan,omitempty"`
+
+	// CacheTtl is how long a fetched ABI, or the absence of one, is kept
+	// before it is fetched again. Default: 24h.
+	CacheTtl Duration `yaml:"cacheTtl,omitempty" json:"cacheTtl,omitempty" tstype:"Duration"`
+
+	// FetchTimeout bounds one fetch from Sourcify or Etherscan. Default: 5s.
+	FetchTimeout Duration `yaml:"fetchTimeout,omitempty" json:"fetchTimeout,omitempty" tstype:"Duration"`
+
+	// DecodeReverts appends the decoded revert reason or custom error to the
+	// message of eth_call and eth_estimateGas revert errors. Default: true.
+	DecodeReverts *bool `yaml:"decodeReverts,omitempty" json:"decodeReverts,omitempty"`
+
+	// DecodedLogs enables erpc_getDecodedLogs, erpc_getDecodedFilterChanges
+	// and erpc_getDecodedFilterLogs, which take the params of eth_getLogs,
+	// eth_getFilterChanges and eth_getFilterLogs and add the decoded event to
+	// each log. Default: false.
+	DecodedLogs *bool `yaml:"decodedLogs,omitempty" json:"decodedLogs,omitempty"`
+}
+
+type EvmAbiContractConfig struct {
+	// Address is the contract address. For a proxy, list the
+	// implementation's ABI under the proxy address.
+	Address string `yaml:"address" json:"address"`
+	// Name labels decoded output, e.g. "USDC".
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Abi is the JSON ABI. Exactly one of Abi and AbiFile is set.
+	Abi string `yaml:"abi,omitempty" json:"abi,omitempty"`
+	// AbiFile is the path of a JSON ABI file, or of a compiler artifact
+	// with an "abi" field.
+	AbiFile string `yaml:"abiFile,omitempty" json:"abiFile,omitempty"`
+}
+
+type EvmAbiSourcifyConfig struct {
+	// Endpoint is the Sourcify server. Default: https://sourcify.dev/server.
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+}
+
+type EvmAbiEtherscanConfig struct {
+	// Endpoint is the Etherscan API. Default: https://api.etherscan.io/v2/api.
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	ApiKey   string `yaml:"apiKey" json:"apiKey"`
+}
+
+// EvmForkConfig layers a fork node over a live chain.
+type EvmForkConfig struct {
+	// BlockNumber is the block the fork was taken at. Blocks at or below it
+	// are canonical chain data and are served by the live upstreams.
+	BlockNumber int64 `yaml:"blockNumber" json:"blockNumber"`
+
+	// Upstreams selects the fork nodes by upstream id or tag (same syntax as
+	// the use-upstream directive). Every other upstream of the network is a
+	// live upstream.
+	Upstreams []string `yaml:"upstreams" json:"upstreams"`
+
+	// InstanceId namespaces cache entries of post-fork state, so restarting a
+	// fork or running several over one shared cache never mixes their state.
+	// Defaults to the fork block number; set a new value whenever the fork
+	// node is restarted at the same block.
+	InstanceId string `yaml:"instanceId,omitempty" json:"instanceId,omitempty"`
+}
+
+// IsForkUpstream reports whether u is one of the fork's nodes.
+func (c *EvmForkConfig) IsForkUpstream(u Upstream) bool {
+	if c == nil || u == nil {
+		return false
+	}
+	for _, sel := range c.Upstreams {
+		if ok, _ := UpstreamMatchesSelector(sel, u); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// EvmBlockTagPolicyConfig enforces reorg-safe block tags for a network.
+type EvmBlockTagPolicyConfig struct {
+	// RewriteLatestTo is the tag user-supplied "latest" is rewritten to before
+	// interpolation and caching: "safe" or "finalized". Empty disables the
+	// rewrite.
+	RewriteLatestTo string `yaml:"rewriteLatestTo,omitempty" json:"rewriteLatestTo,omitempty"`
+
+	// Methods limits the rewrite to matching methods (wildcard patterns).
+	// Empty applies it to every method with a block reference.
+	Methods []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+
+	// UnfinalizedDepth treats numeric blocks within this many blocks of the
+	// served tip as unfinalized, even when upstreams already report them as
+	// finalized, so they are cached with unfinalized TTLs. Zero disables it.
+	UnfinalizedDepth int64 `yaml:"unfinalizedDepth,omitempty" json:"unfinalizedDepth,omitempty"`
+}
+
+// RewriteLatestFor returns the tag "latest" must be rewritten to for the given
+// method, or "" when the policy does not apply.
+func (c *EvmBlockTagPolicyConfig) RewriteLatestFor(method string) string {
+	if c == nil || c.RewriteLatestTo == "" {
+		return ""
+	}
+	if len(c.Methods) == 0 {
+		return c.RewriteLatestTo
+	}
+	for _, m := range c.Methods {
+		if match, err := WildcardMatch(m, method); err == nil && match {
+			return c.RewriteLatestTo
+		}
+	}
+	return ""
+}
+
 // EvmServedTipConfig controls how the network derives the "latest"/"finalized"
 // block it advertises (and enforces) from its upstreams.
 //
@@ -39,6 +39,9 @@ const (
 	connectorScopeSharedState connectorScope = "shared-state"
 	connectorScopeCache       connectorScope = "cache"
 	connectorScopeAuth        connectorScope = "auth"
+	connectorScopeIdempotency connectorScope = "idempotency"
+	connectorScopeJournal     connectorScope = "journal"
+	connectorScopeAnalytics   connectorScope = "analytics"
 )
 
 // DefaultOptions is used to pass env-provided or args-provided options to the config defaults initializer
@@ -90,6 +93,20 @@ func (c *Config) SetDefaults(opts *DefaultOptions) error {
 		}
 	}
 
+	if c.Management != nil {
+		if err := c.Management.SetDefaults(); err != nil {
+			return err
+		}
+	}
+
+	if c.Scheduler != nil {
+		c.Scheduler.SetDefaults()
+	}
+
+	if c.Alerting != nil {
+		c.Alerting.SetDefaults()
+	}
+
 	if c.Projects != nil {
 		for _, project := range c.Projects {
 			if err := project.SetDefaults(opts); err != nil {
@@ -487,6 +504,79 @@ func (c *CacheConfig) SetDefaults() error {
 		return fmt.Errorf("failed to set defaults for compression: %w", err)
 	}
 
+	if c.Integrity == nil {
+		c.Integrity = &CacheIntegrityConfig{}
+	}
+	if c.Integrity.Enabled == nil {
+		// Off by default: sealed values cannot be read by older eRPC versions
+		// sharing the same cache, so enabling it is an explicit choice.
+		c.Integrity.Enabled = util.BoolPtr(false)
+	}
+
+	if c.Records == nil {
+		c.Records = &CacheRecordsConfig{}
+	}
+	if c.Records.Enabled == nil {
+		// Off by default for the same reason as integrity: older versions
+		// cannot read record envelopes.
+		c.Records.Enabled = util.BoolPtr(false)
+	}
+
+	if c.GetTimeout == 0 {
+		c.GetTimeout = Duration(30 * time.Second)
+	}
+	if c.SetTimeout == 0 {
+		c.SetTimeout = Duration(10 * time.Second)
+	}
+
+	if c.KeyHash == nil {
+		c.KeyHash = &CacheKeyHashConfig{}
+	}
+	if c.KeyHash.Algorithm == "" {
+		c.KeyHash.Algorithm = CacheKeyHashSha256
+	}
+	if c.KeyHash.CollisionAudit == nil {
+		c.KeyHash.CollisionAudit = util.BoolPtr(false)
+	}
+
+	if c.CrossPopulate != nil {
+		if c.CrossPopulate.Blocks == nil {
+			c.CrossPopulate.Blocks = util.BoolPtr(true)
+		}
+		if c.CrossPopulate.TransactionsByIndex == nil {
+			c.CrossPopulate.TransactionsByIndex = util.BoolPtr(true)
+		}
+		if c.CrossPopulate.TransactionsByHash == nil {
+			c.CrossPopulate.TransactionsByHash = util.BoolPtr(true)
+		}
+	}
+
+	if c.ErrorResults != nil {
+		if c.ErrorResults.ExecutionRevertedTtl == 0 {
+			c.ErrorResults.ExecutionRevertedTtl = Duration(5 * time.Minute)
+		}
+		if c.ErrorResults.InvalidParamsTtl == 0 {
+			c.ErrorResults.InvalidParamsTtl = Duration(1 * time.Minute)
+		}
+	}
+
+	if c.HitRateReport != nil {
+		if c.HitRateReport.Interval == 0 {
+			c.HitRateReport.Interval = Duration(5 * time.Minute)
+		}
+		if c.HitRateReport.MinRequests == 0 {
+			c.HitRateReport.MinRequests = 100
+		}
+		for _, budget := range c.HitRateReport.Budgets {
+			if budget.Network == "" {
+				budget.Network = "*"
+			}
+			if budget.Method == "" {
+				budget.Method = "*"
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -611,6 +701,9 @@ func (c *CachePolicyConfig) SetDefaults() error {
 	if c.AppliesTo == "" {
 		c.AppliesTo = CachePolicyAppliesToBoth
 	}
+	if c.EmptyTtl > 0 && c.EmptyInvalidateOnNewHead == nil {
+		c.EmptyInvalidateOnNewHead = util.BoolPtr(true)
+	}
 
 	return nil
 }
@@ -669,6 +762,9 @@ func (s *ServerConfig) SetDefaults() error {
 	if s.GrpcEnabled == nil {
 		s.GrpcEnabled = util.BoolPtr(false)
 	}
+	if s.UnixSocket != nil && s.UnixSocket.Mode == "" {
+		s.UnixSocket.Mode = "0660"
+	}
 	if s.GrpcHostV4 == nil && s.HttpHostV4 != nil {
 		v := *s.HttpHostV4
 		s.GrpcHostV4 = &v
@@ -727,6 +823,58 @@ func (s *ServerConfig) SetDefaults() error {
 	if s.CostHeaders == nil {
 		s.CostHeaders = util.BoolPtr(false)
 	}
+	if s.ETag == nil {
+		s.ETag = util.BoolPtr(false)
+	}
+	if s.ResponseCompression == nil {
+		s.ResponseCompression = &ResponseCompressionConfig{}
+	}
+	if s.ResponseCompression.Enabled == nil {
+		s.ResponseCompression.Enabled = util.BoolPtr(true)
+	}
+	if len(s.ResponseCompression.Encodings) == 0 {
+		s.ResponseCompression.Encodings = []ContentEncoding{ContentEncodingGzip}
+	}
+	if s.ResponseCompression.Threshold == 0 {
+		s.ResponseCompression.Threshold = 1024
+	}
+	if s.CorrelationId == nil {
+		s.CorrelationId = &CorrelationIdConfig{}
+	}
+	if s.CorrelationId.Enabled == nil {
+		s.CorrelationId.Enabled = util.BoolPtr(true)
+	}
+	if s.CorrelationId.Header == "" {
+		s.CorrelationId.Header = "X-Request-Id"
+	}
+	if s.CorrelationId.TrustClient == nil {
+		s.CorrelationId.TrustClient = util.BoolPtr(true)
+	}
+	if s.CorrelationId.ForwardToUpstreams == nil {
+		s.CorrelationId.ForwardToUpstreams = util.BoolPtr(true)
+	}
+	if s.Attestation != nil {
+		if s.Attestation.Enabled == nil {
+			s.Attestation.Enabled = util.BoolPtr(true)
+		}
+		if s.Attestation.Header == "" {
+			s.Attestation.Header = "X-ERPC-Attestation"
+		}
+	}
+	if s.RequestValidation != nil {
+		if s.RequestValidation.Enabled == nil {
+			s.RequestValidation.Enabled = util.BoolPtr(true)
+		}
+		if s.RequestValidation.MaxMethodLength == 0 {
+			s.RequestValidation.MaxMethodLength = 64
+		}
+		if s.RequestValidation.MaxIdLength == 0 {
+			s.RequestValidation.MaxIdLength = 128
+		}
+		if s.RequestValidation.MaxParams == 0 {
+			s.RequestValidation.MaxParams = 32
+		}
+	}
 
 	// Safe defaults for client IP resolution
 	if len(s.TrustedIPForwarders) == 0 {
@@ -737,6 +885,18 @@ func (s *ServerConfig) SetDefaults() error {
 		// Empty by default to avoid trusting headers unless explicitly configured
 		s.TrustedIPHeaders = []string{}
 	}
+	if s.ProxyProtocol != nil {
+		if len(s.ProxyProtocol.TrustedSources) == 0 {
+			s.ProxyProtocol.TrustedSources = append([]string(nil), s.TrustedIPForwarders...)
+		}
+		if s.ProxyProtocol.Required == nil {
+			s.ProxyProtocol.Required = util.BoolPtr(false)
+		}
+		if s.ProxyProtocol.HeaderTimeout == nil {
+			d := Duration(5 * time.Second)
+			s.ProxyProtocol.HeaderTimeout = &d
+		}
+	}
 
 	return nil
 }
@@ -772,6 +932,108 @@ func (m *MetricsConfig) SetDefaults() error {
 	return nil
 }
 
+func (s *SchedulerConfig) SetDefaults() {
+	if s.HistorySize <= 0 {
+		s.HistorySize = 20
+	}
+	for _, job := range s.Jobs {
+		if job == nil {
+			continue
+		}
+		if job.Timeout == 0 {
+			job.Timeout = Duration(10 * time.Minute)
+		}
+		if job.Type == "" {
+			if job.Backfill != nil {
+				job.Type = ScheduledJobTypeBackfill
+			} else if job.Warm != nil {
+				job.Type = ScheduledJobTypeWarm
+			} else if job.CacheSweep != nil {
+				job.Type = ScheduledJobTypeCacheSweep
+			} else if job.QuotaReset != nil {
+				job.Type = ScheduledJobTypeQuotaReset
+			} else if job.HealthReport != nil {
+				job.Type = ScheduledJobTypeHealthReport
+			}
+		}
+	}
+}
+
+// DefaultPagerDutyEventsUrl is the PagerDuty Events API v2 endpoint used by
+// pagerduty alert sinks without a url.
+const DefaultPagerDutyEventsUrl = "https://events.pagerduty.com/v2/enqueue"
+
+func (a *AlertingConfig) SetDefaults() {
+	if a.EvaluationInterval == 0 {
+		a.EvaluationInterval = Duration(30 * time.Second)
+	}
+	if a.RepeatInterval == 0 {
+		a.RepeatInterval = Duration(4 * time.Hour)
+	}
+	if a.SendResolved == nil {
+		a.SendResolved = util.BoolPtr(true)
+	}
+	for _, sink := range a.Sinks {
+		if sink != nil && sink.Type == AlertSinkTypePagerDuty && sink.Url == "" {
+			sink.Url = DefaultPagerDutyEventsUrl
+		}
+	}
+	for _, rule := range a.Rules {
+		if rule == nil {
+			continue
+		}
+		if rule.Severity == "" {
+			rule.Severity = AlertSeverityWarning
+		}
+		if rule.For == 0 {
+			rule.For = Duration(time.Minute)
+		}
+		switch rule.Type {
+		case AlertRuleTypeErrorRate:
+			if rule.Threshold == 0 {
+				rule.Threshold = 0.2
+			}
+			if rule.MinRequests == 0 {
+				rule.MinRequests = 20
+			}
+		case AlertRuleTypeSubscriptionLag:
+			if rule.Threshold == 0 {
+				rule.Threshold = 10
+			}
+		}
+	}
+}
+
+func (m *ManagementConfig) SetDefaults() error {
+	if m.Host == "" {
+		m.Host = "127.0.0.1"
+	}
+	if m.Port == nil {
+		m.Port = util.IntPtr(4002)
+	}
+	if m.Auth != nil {
+		if err := m.Auth.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for management.auth: %w", err)
+		}
+	}
+	if m.Metrics == nil {
+		m.Metrics = util.BoolPtr(true)
+	}
+	if m.HealthCheck == nil {
+		m.HealthCheck = util.BoolPtr(true)
+	}
+	if m.Admin == nil {
+		m.Admin = util.BoolPtr(true)
+	}
+	if m.Pprof == nil {
+		m.Pprof = util.BoolPtr(false)
+	}
+	if m.Exclusive == nil {
+		m.Exclusive = util.BoolPtr(true)
+	}
+	return nil
+}
+
 func (a *AdminConfig) SetDefaults() error {
 	if a.Auth != nil {
 		if err := a.Auth.SetDefaults(); err != nil {
@@ -831,9 +1093,39 @@ func (c *SharedStateConfig) SetDefaults(defClusterKey string) error {
 		// How long the foreground waits for the update function before returning stale
 		c.UpdateMaxWait = Duration(50 * time.Millisecond)
 	}
+	if c.Routing != nil {
+		c.Routing.SetDefaults()
+	}
+	if c.Leases != nil {
+		c.Leases.SetDefaults()
+	}
 	return nil
 }
 
+func (c *SharedLeasesConfig) SetDefaults() {
+	if c.Ttl == 0 {
+		c.Ttl = Duration(15 * time.Second)
+	}
+	if c.RenewInterval == 0 {
+		c.RenewInterval = Duration(5 * time.Second)
+	}
+}
+
+func (c *SharedRoutingConfig) SetDefaults() {
+	if c.SyncInterval == 0 {
+		c.SyncInterval = Duration(5 * time.Second)
+	}
+	if c.StaleAfter == 0 {
+		c.StaleAfter = Duration(30 * time.Second)
+	}
+	if c.Metrics == nil {
+		c.Metrics = util.BoolPtr(true)
+	}
+	if c.CircuitBreakers == nil {
+		c.CircuitBreakers = util.BoolPtr(true)
+	}
+}
+
 func (d *DatabaseConfig) SetDefaults(defClusterKey string) error {
 	if d.EvmJsonRpcCache != nil {
 		if err := d.EvmJsonRpcCache.SetDefaults(); err != nil {
@@ -845,14 +1137,193 @@ func (d *DatabaseConfig) SetDefaults(defClusterKey string) error {
 			return err
 		}
 	}
+	if d.Idempotency != nil {
+		if err := d.Idempotency.SetDefaults(); err != nil {
+			return err
+		}
+	}
+	if d.Journal != nil {
+		if err := d.Journal.SetDefaults(); err != nil {
+			return err
+		}
+	}
+	if d.Analytics != nil {
+		if err := d.Analytics.SetDefaults(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
+// DefaultIdempotencyMethods are the write methods whose retries are
+// deduplicated when idempotency is configured without an explicit list:
+// transaction broadcasts and the admin mutations.
+var DefaultIdempotencyMethods = []string{
+	"eth_sendRawTransaction",
+	"erpc_addApiKey",
+	"erpc_updateApiKey",
+	"erpc_deleteApiKey",
+	"erpc_cordonUpstream",
+	"erpc_uncordonUpstream",
+	"erpc_drainUpstream",
+	"erpc_undrainUpstream",
+	"erpc_resetCanary",
+	"erpc_unretireUpstream",
+	"erpc_startBackfill",
+	"erpc_cancelBackfill",
+}
+
+func (c *IdempotencyConfig) SetDefaults() error {
+	if c.Connector != nil {
+		if err := c.Connector.SetDefaults(connectorScopeIdempotency); err != nil {
+			return err
+		}
+	}
+	if c.Ttl == 0 {
+		c.Ttl = Duration(24 * time.Hour)
+	}
+	if c.LockTtl == 0 {
+		c.LockTtl = Duration(30 * time.Second)
+	}
+	if len(c.Methods) == 0 {
+		c.Methods = append([]string(nil), DefaultIdempotencyMethods...)
+	}
+	return nil
+}
+
+func (c *JournalConfig) SetDefaults() error {
+	if c.Connector != nil {
+		if err := c.Connector.SetDefaults(connectorScopeJournal); err != nil {
+			return err
+		}
+	}
+	if len(c.Methods) == 0 {
+		c.Methods = []string{"eth_sendRawTransaction"}
+	}
+	if c.Ttl == 0 {
+		c.Ttl = Duration(24 * time.Hour)
+	}
+	if c.RecoverAfter == 0 {
+		c.RecoverAfter = Duration(2 * time.Minute)
+	}
+	if c.RecoveryInterval == 0 {
+		c.RecoveryInterval = Duration(time.Minute)
+	}
+	if c.Rebroadcast == nil {
+		c.Rebroadcast = util.BoolPtr(false)
+	}
+	return nil
+}
+
+func (c *AnalyticsConfig) SetDefaults() error {
+	if c.Connector != nil {
+		if err := c.Connector.SetDefaults(connectorScopeAnalytics); err != nil {
+			return err
+		}
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = Duration(15 * time.Second)
+	}
+	if c.MinuteRetention == 0 {
+		c.MinuteRetention = Duration(48 * time.Hour)
+	}
+	if c.HourRetention == 0 {
+		c.HourRetention = Duration(30 * 24 * time.Hour)
+	}
+	if c.MaxRowsPerBucket == 0 {
+		c.MaxRowsPerBucket = 10_000
+	}
 	return nil
 }
 
+func (t *TombstoneConfig) SetDefaults() {
+	if t.GracePeriod == 0 {
+		t.GracePeriod = Duration(time.Minute)
+	}
+	if t.BatchSize == 0 {
+		t.BatchSize = 100
+	}
+	if t.FlushInterval == 0 {
+		t.FlushInterval = Duration(5 * time.Second)
+	}
+}
+
+func (o *OverflowConfig) SetDefaults() {
+	if o.Threshold == "" {
+		// Leaves headroom under DynamoDB's 400KB item limit for keys and
+		// attributes.
+		o.Threshold = "350KB"
+	}
+	if o.S3 != nil {
+		o.S3.SetDefaults()
+	}
+}
+
+func (c *ConnectorCompressionConfig) SetDefaults() {
+	if c.Algorithm == "" {
+		c.Algorithm = "zstd"
+	}
+	if c.Threshold == "" {
+		c.Threshold = "1KB"
+	}
+	if c.ZstdLevel == "" {
+		c.ZstdLevel = "fastest"
+	}
+}
+
+func (c *ConnectorEncryptionConfig) SetDefaults() {
+	for _, k := range c.Keys {
+		if k == nil {
+			continue
+		}
+		if k.Source == "" {
+			if k.EncryptedDataKey != "" {
+				k.Source = "kms"
+			} else {
+				k.Source = "env"
+			}
+		}
+	}
+	if c.PrimaryKeyId == "" && len(c.Keys) > 0 && c.Keys[0] != nil {
+		c.PrimaryKeyId = c.Keys[0].Id
+	}
+}
+
+func (s *S3OverflowConfig) SetDefaults() {
+	if s.Prefix == "" {
+		s.Prefix = "erpc/"
+	}
+	if s.ManageLifecycle == nil {
+		s.ManageLifecycle = util.BoolPtr(true)
+	}
+	if s.InitTimeout == 0 {
+		s.InitTimeout = Duration(5 * time.Second)
+	}
+	if s.GetTimeout == 0 {
+		s.GetTimeout = Duration(5 * time.Second)
+	}
+	if s.SetTimeout == 0 {
+		s.SetTimeout = Duration(10 * time.Second)
+	}
+}
+
 func (c *ConnectorConfig) SetDefaults(scope connectorScope) error {
 	if c.Id == "" {
 		c.Id = string(scope) + "-" + string(c.Driver)
 	}
+	if c.Tombstones != nil {
+		c.Tombstones.SetDefaults()
+	}
+	if c.Overflow != nil {
+		c.Overflow.SetDefaults()
+	}
+	if c.Compression != nil {
+		c.Compression.SetDefaults()
+	}
+	if c.Encryption != nil {
+		c.Encryption.SetDefaults()
+	}
 	if c.FailsafeForGets != nil {
 		for idx, f := range c.FailsafeForGets {
 			if f == nil {
@@ -934,7 +1405,162 @@ func (c *ConnectorConfig) SetDefaults(scope connectorScope) error {
 			c.Grpc.GetTimeout = Duration(100 * time.Millisecond)
 		}
 	}
+	if c.Memcached != nil {
+		c.Driver = DriverMemcached
+	}
+	if c.Driver == DriverMemcached {
+		if c.Memcached == nil {
+			c.Memcached = &MemcachedConnectorConfig{}
+		}
+		if err := c.Memcached.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for memcached connector: %w", err)
+		}
+	}
+	if c.Cassandra != nil {
+		c.Driver = DriverCassandra
+	}
+	if c.Driver == DriverCassandra {
+		if c.Cassandra == nil {
+			c.Cassandra = &CassandraConnectorConfig{}
+		}
+		if err := c.Cassandra.SetDefaults(scope); err != nil {
+			return fmt.Errorf("failed to set defaults for cassandra connector: %w", err)
+		}
+	}
+	if c.MongoDB != nil {
+		c.Driver = DriverMongoDB
+	}
+	if c.Driver == DriverMongoDB {
+		if c.MongoDB == nil {
+			c.MongoDB = &MongoDBConnectorConfig{}
+		}
+		if err := c.MongoDB.SetDefaults(scope); err != nil {
+			return fmt.Errorf("failed to set defaults for mongodb connector: %w", err)
+		}
+	}
+	if c.Badger != nil {
+		c.Driver = DriverBadger
+	}
+	if c.Driver == DriverBadger {
+		if c.Badger == nil {
+			c.Badger = &BadgerConnectorConfig{}
+		}
+		if err := c.Badger.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for badger connector: %w", err)
+		}
+	}
+	if c.ClickHouse != nil {
+		c.Driver = DriverClickHouse
+	}
+	if c.Driver == DriverClickHouse {
+		if c.ClickHouse == nil {
+			c.ClickHouse = &ClickHouseConnectorConfig{}
+		}
+		if err := c.ClickHouse.SetDefaults(scope); err != nil {
+			return fmt.Errorf("failed to set defaults for clickhouse connector: %w", err)
+		}
+	}
+	if c.Tiered != nil {
+		c.Driver = DriverTiered
+	}
+	if c.Driver == DriverTiered {
+		if c.Tiered == nil {
+			c.Tiered = &TieredConnectorConfig{}
+		}
+		if err := c.Tiered.SetDefaults(scope, c.Id); err != nil {
+			return fmt.Errorf("failed to set defaults for tiered connector: %w", err)
+		}
+	}
+	if c.Layered != nil {
+		c.Driver = DriverLayered
+	}
+	if c.Driver == DriverLayered {
+		if c.Layered == nil {
+			c.Layered = &LayeredConnectorConfig{}
+		}
+		if err := c.Layered.SetDefaults(scope, c.Id); err != nil {
+			return fmt.Errorf("failed to set defaults for layered connector: %w", err)
+		}
+	}
+	if c.CircuitBreaker == nil {
+		c.CircuitBreaker = &ConnectorCircuitBreakerConfig{}
+	}
+	c.CircuitBreaker.SetDefaults(c.Driver)
+
+	return nil
+}
 
+func (b *ConnectorCircuitBreakerConfig) SetDefaults(driver ConnectorDriverType) {
+	if b.Enabled == nil {
+		switch driver {
+		case DriverMemory, DriverBadger, DriverTiered, DriverLayered:
+			b.Enabled = util.BoolPtr(false)
+		default:
+			b.Enabled = util.BoolPtr(true)
+		}
+	}
+	if b.FailureThreshold == 0 {
+		b.FailureThreshold = 5
+	}
+	if b.ProbeInterval == 0 {
+		b.ProbeInterval = Duration(10 * time.Second)
+	}
+}
+
+func (t *TieredConnectorConfig) SetDefaults(scope connectorScope, parentId string) error {
+	if t.OffloadAfter == 0 {
+		t.OffloadAfter = Duration(24 * time.Hour)
+	}
+	if t.PromoteOnRead == nil {
+		t.PromoteOnRead = util.BoolPtr(true)
+	}
+	if t.Hot != nil {
+		if t.Hot.Id == "" {
+			t.Hot.Id = parentId + "-hot"
+		}
+		if err := t.Hot.SetDefaults(scope); err != nil {
+			return fmt.Errorf("failed to set defaults for hot tier: %w", err)
+		}
+	}
+	if t.Cold != nil {
+		if t.Cold.Id == "" {
+			t.Cold.Id = parentId + "-cold"
+		}
+		if err := t.Cold.SetDefaults(scope); err != nil {
+			return fmt.Errorf("failed to set defaults for cold tier: %w", err)
+		}
+	}
+	return nil
+}
+
+func (l *LayeredConnectorConfig) SetDefaults(scope connectorScope, parentId string) error {
+	if l.L1 == nil {
+		// Smaller than a standalone memory connector: L1 only needs the hot set.
+		l.L1 = &MemoryConnectorConfig{MaxItems: 10000, MaxTotalSize: "256MB"}
+	}
+	if err := l.L1.SetDefaults(); err != nil {
+		return fmt.Errorf("failed to set defaults for l1: %w", err)
+	}
+	if l.L1Ttl == 0 {
+		l.L1Ttl = Duration(30 * time.Second)
+	}
+	if l.WriteMode == "" {
+		l.WriteMode = LayeredWriteThrough
+	}
+	if l.FlushInterval == 0 {
+		l.FlushInterval = Duration(100 * time.Millisecond)
+	}
+	if l.MaxPending == 0 {
+		l.MaxPending = 10000
+	}
+	if l.L2 != nil {
+		if l.L2.Id == "" {
+			l.L2.Id = parentId + "-l2"
+		}
+		if err := l.L2.SetDefaults(scope); err != nil {
+			return fmt.Errorf("failed to set defaults for l2: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -987,6 +1613,20 @@ func (r *RedisConnectorConfig) SetDefaults() error {
 		r.TLS.Enabled = true
 	}
 
+	if r.Cluster != nil && r.Cluster.MaxRedirects == 0 {
+		r.Cluster.MaxRedirects = 3
+	}
+	// Cluster and sentinel deployments are usually described by their node
+	// addresses alone; the first one stands in for addr so that username,
+	// password, db and tls still build the URI the nodes are dialed with.
+	if r.URI == "" && r.Addr == "" {
+		if r.Cluster != nil && len(r.Cluster.Addrs) > 0 {
+			r.Addr = r.Cluster.Addrs[0]
+		} else if r.Sentinel != nil && len(r.Sentinel.Addrs) > 0 {
+			r.Addr = r.Sentinel.Addrs[0]
+		}
+	}
+
 	// URI is provided directly
 	if r.URI != "" {
 		return nil
@@ -1046,6 +1686,12 @@ func (p *PostgreSQLConnectorConfig) SetDefaults(scope connectorScope) error {
 			p.Table = "erpc_json_rpc_cache"
 		case connectorScopeAuth:
 			p.Table = "erpc_auth"
+		case connectorScopeIdempotency:
+			p.Table = "erpc_idempotency"
+		case connectorScopeJournal:
+			p.Table = "erpc_journal"
+		case connectorScopeAnalytics:
+			p.Table = "erpc_analytics"
 		default:
 			return fmt.Errorf("invalid connector scope: %s", scope)
 		}
@@ -1099,6 +1745,170 @@ func (p *PostgreSQLConnectorConfig) SetDefaults(scope connectorScope) error {
 	return nil
 }
 
+func (m *MemcachedConnectorConfig) SetDefaults() error {
+	if m.ConnPoolSize == 0 {
+		m.ConnPoolSize = 8
+	}
+	if m.InitTimeout == 0 {
+		m.InitTimeout = Duration(5 * time.Second)
+	}
+	if m.GetTimeout == 0 {
+		m.GetTimeout = Duration(1 * time.Second)
+	}
+	if m.SetTimeout == 0 {
+		m.SetTimeout = Duration(3 * time.Second)
+	}
+	if m.LockRetryInterval == 0 {
+		m.LockRetryInterval = Duration(500 * time.Millisecond)
+	}
+	if m.StatePollInterval == 0 {
+		m.StatePollInterval = Duration(5 * time.Second)
+	}
+	return nil
+}
+
+func (c *CassandraConnectorConfig) SetDefaults(scope connectorScope) error {
+	if c.Table == "" {
+		switch scope {
+		case connectorScopeSharedState:
+			c.Table = "erpc_shared_state"
+		case connectorScopeCache:
+			c.Table = "erpc_json_rpc_cache"
+		case connectorScopeAuth:
+			c.Table = "erpc_auth"
+		case connectorScopeIdempotency:
+			c.Table = "erpc_idempotency"
+		case connectorScopeJournal:
+			c.Table = "erpc_journal"
+		case connectorScopeAnalytics:
+			c.Table = "erpc_analytics"
+		default:
+			return fmt.Errorf("invalid connector scope: %s", scope)
+		}
+	}
+	if c.Consistency == "" {
+		c.Consistency = "LOCAL_QUORUM"
+	}
+	if c.NumConns == 0 {
+		c.NumConns = 2
+	}
+	if c.InitTimeout == 0 {
+		c.InitTimeout = Duration(10 * time.Second)
+	}
+	if c.GetTimeout == 0 {
+		c.GetTimeout = Duration(1 * time.Second)
+	}
+	if c.SetTimeout == 0 {
+		c.SetTimeout = Duration(2 * time.Second)
+	}
+	if c.StatePollInterval == 0 {
+		c.StatePollInterval = Duration(5 * time.Second)
+	}
+	if c.LockRetryInterval == 0 {
+		c.LockRetryInterval = Duration(500 * time.Millisecond)
+	}
+	return nil
+}
+
+func (b *BadgerConnectorConfig) SetDefaults() error {
+	if b.EvictionInterval == 0 {
+		b.EvictionInterval = Duration(1 * time.Minute)
+	}
+	if b.GCInterval == 0 {
+		b.GCInterval = Duration(5 * time.Minute)
+	}
+	if b.GCDiscardRatio == 0 {
+		b.GCDiscardRatio = 0.5
+	}
+	if b.InitTimeout == 0 {
+		b.InitTimeout = Duration(30 * time.Second)
+	}
+	return nil
+}
+
+// ClickHouseConnectorConfig.SetDefaults rejects every scope but the cache:
+// writes are buffered and the table has no locks or counters, which the
+// other scopes rely on.
+func (c *ClickHouseConnectorConfig) SetDefaults(scope connectorScope) error {
+	if scope != connectorScopeCache {
+		return fmt.Errorf("clickhouse connector can only be used for the cache (not for %s)", scope)
+	}
+	if c.Database == "" {
+		c.Database = "default"
+	}
+	if c.Table == "" {
+		c.Table = "erpc_json_rpc_cache"
+	}
+	if c.PartitionBy == "" {
+		c.PartitionBy = "day"
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = 1000
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = Duration(1 * time.Second)
+	}
+	if c.MaxBufferedRows == 0 {
+		c.MaxBufferedRows = 100000
+	}
+	if c.InitTimeout == 0 {
+		c.InitTimeout = Duration(10 * time.Second)
+	}
+	if c.GetTimeout == 0 {
+		c.GetTimeout = Duration(2 * time.Second)
+	}
+	if c.SetTimeout == 0 {
+		c.SetTimeout = Duration(10 * time.Second)
+	}
+	return nil
+}
+
+func (m *MongoDBConnectorConfig) SetDefaults(scope connectorScope) error {
+	if m.Collection == "" {
+		switch scope {
+		case connectorScopeSharedState:
+			m.Collection = "erpc_shared_state"
+		case connectorScopeCache:
+			m.Collection = "erpc_json_rpc_cache"
+		case connectorScopeAuth:
+			m.Collection = "erpc_auth"
+		case connectorScopeIdempotency:
+			m.Collection = "erpc_idempotency"
+		case connectorScopeJournal:
+			m.Collection = "erpc_journal"
+		case connectorScopeAnalytics:
+			m.Collection = "erpc_analytics"
+		default:
+			return fmt.Errorf("invalid connector scope: %s", scope)
+		}
+	}
+	if m.ReadPreference == "" {
+		m.ReadPreference = "primary"
+	}
+	if m.WriteConcern == "" {
+		m.WriteConcern = "majority"
+	}
+	if m.MaxPoolSize == 0 {
+		m.MaxPoolSize = 100
+	}
+	if m.InitTimeout == 0 {
+		m.InitTimeout = Duration(10 * time.Second)
+	}
+	if m.GetTimeout == 0 {
+		m.GetTimeout = Duration(1 * time.Second)
+	}
+	if m.SetTimeout == 0 {
+		m.SetTimeout = Duration(2 * time.Second)
+	}
+	if m.StatePollInterval == 0 {
+		m.StatePollInterval = Duration(5 * time.Second)
+	}
+	if m.LockRetryInterval == 0 {
+		m.LockRetryInterval = Duration(500 * time.Millisecond)
+	}
+	return nil
+}
+
 func (d *DynamoDBConnectorConfig) SetDefaults(scope connectorScope) error {
 	if d.Table == "" {
 		switch scope {
@@ -1108,6 +1918,12 @@ func (d *DynamoDBConnectorConfig) SetDefaults(scope connectorScope) error {
 			d.Table = "erpc_json_rpc_cache"
 		case connectorScopeAuth:
 			d.Table = "erpc_auth"
+		case connectorScopeIdempotency:
+			d.Table = "erpc_idempotency"
+		case connectorScopeJournal:
+			d.Table = "erpc_journal"
+		case connectorScopeAnalytics:
+			d.Table = "erpc_analytics"
 		default:
 			return fmt.Errorf("invalid connector scope: %s", scope)
 		}
@@ -1136,6 +1952,12 @@ func (d *DynamoDBConnectorConfig) SetDefaults(scope connectorScope) error {
 	if d.StatePollInterval == 0 {
 		d.StatePollInterval = Duration(5 * time.Second)
 	}
+	if d.ChunkSize == "" {
+		d.ChunkSize = "350KB"
+	}
+	if d.Dax != nil && d.Dax.FallbackFor == 0 {
+		d.Dax.FallbackFor = Duration(30 * time.Second)
+	}
 
 	return nil
 }
@@ -1228,9 +2050,54 @@ func (p *ProjectConfig) SetDefaults(opts *DefaultOptions) error {
 			return fmt.Errorf("failed to set defaults for cors: %w", err)
 		}
 	}
+	if p.SelfService != nil {
+		p.SelfService.SetDefaults()
+	}
+	if p.SubscriptionArchive != nil {
+		p.SubscriptionArchive.SetDefaults()
+	}
 	return nil
 }
 
+func (s *SelfServiceConfig) SetDefaults() {
+	if s.RecentErrorsSize == 0 {
+		s.RecentErrorsSize = 20
+	}
+	if s.MaxTrackedUsers == 0 {
+		s.MaxTrackedUsers = 10_000
+	}
+}
+
+func (s *SubscriptionArchiveConfig) SetDefaults() {
+	if s.Format == "" {
+		s.Format = "ndjson"
+	}
+	if len(s.Events) == 0 {
+		s.Events = []string{"heads", "logs"}
+	}
+	if s.Endpoint == "" && strings.HasPrefix(s.Path, "gs://") {
+		s.Endpoint = "https://storage.googleapis.com"
+		if s.Region == "" {
+			s.Region = "auto"
+		}
+	}
+	if s.FlushInterval == 0 {
+		s.FlushInterval = Duration(60 * time.Second)
+	}
+	if s.MaxRecords == 0 {
+		s.MaxRecords = 50_000
+	}
+	if s.MaxSize == "" {
+		s.MaxSize = "32MB"
+	}
+	if s.QueueSize == 0 {
+		s.QueueSize = 1024
+	}
+	if s.UploadTimeout == 0 {
+		s.UploadTimeout = Duration(30 * time.Second)
+	}
+}
+
 func convertUpstreamToProvider(upstream *UpstreamConfig) (*ProviderConfig, error) {
 	if strings.HasPrefix(upstream.Endpoint, "http://") ||
 		strings.HasPrefix(upstream.Endpoint, "https://") ||
@@ -1634,6 +2501,9 @@ func (u *UpstreamConfig) ApplyDefaults(defaults *UpstreamConfig) error {
 	if u.IgnoreMethods == nil && defaults.IgnoreMethods != nil {
 		u.IgnoreMethods = append([]string{}, defaults.IgnoreMethods...)
 	}
+	if u.MethodRewrites == nil && defaults.MethodRewrites != nil {
+		u.MethodRewrites = append([]*MethodRewriteConfig{}, defaults.MethodRewrites...)
+	}
 	if u.AutoIgnoreUnsupportedMethods == nil && defaults.AutoIgnoreUnsupportedMethods != nil {
 		u.AutoIgnoreUnsupportedMethods = defaults.AutoIgnoreUnsupportedMethods
 	}
@@ -1651,6 +2521,10 @@ func (u *UpstreamConfig) ApplyDefaults(defaults *UpstreamConfig) error {
 		}
 		u.Routing = &cp
 	}
+	if u.Retirement == nil && defaults.Retirement != nil && defaults != u {
+		rc := *defaults.Retirement
+		u.Retirement = &rc
+	}
 
 	return nil
 }
@@ -1765,6 +2639,12 @@ func (u *UpstreamConfig) SetDefaults(defaults *UpstreamConfig) error {
 	if err := u.JsonRpc.SetDefaults(); err != nil {
 		return fmt.Errorf("failed to set defaults for json rpc: %w", err)
 	}
+	if u.Canary != nil {
+		u.Canary.SetDefaults()
+	}
+	if u.Retirement != nil {
+		u.Retirement.SetDefaults()
+	}
 	// By default if any allowed methods are specified, all other methods are ignored (unless ignoreMethods is explicitly defined by user)
 	// Similar to how common network security policies work.
 	if u.AllowMethods != nil {
@@ -1776,6 +2656,33 @@ func (u *UpstreamConfig) SetDefaults(defaults *UpstreamConfig) error {
 	return nil
 }
 
+func (c *UpstreamCanaryConfig) SetDefaults() {
+	if len(c.Weights) == 0 {
+		c.Weights = []float64{0.01, 0.1, 0.5, 1}
+	}
+	if c.StepDuration == 0 {
+		c.StepDuration = Duration(10 * time.Minute)
+	}
+	if c.MinSamples == 0 {
+		c.MinSamples = 100
+	}
+	if c.MaxErrorRateDelta == 0 {
+		c.MaxErrorRateDelta = 0.05
+	}
+	if c.MaxLatencyRatio == 0 {
+		c.MaxLatencyRatio = 2
+	}
+}
+
+func (c *UpstreamRetirementConfig) SetDefaults() {
+	if c.DeadFor == 0 {
+		c.DeadFor = Duration(72 * time.Hour)
+	}
+	if c.MinFailures == 0 {
+		c.MinFailures = 100
+	}
+}
+
 func (e *EvmUpstreamConfig) SetDefaults(defaults *EvmUpstreamConfig) error {
 	if e.StatePollerInterval == 0 {
 		if defaults != nil && defaults.StatePollerInterval != 0 {
@@ -1949,6 +2856,13 @@ func (n *NetworkConfig) SetDefaults(upstreams []*UpstreamConfig, defaults *Netwo
 			if n.Evm.EmptyResultConfidence == 0 && defaults.Evm.EmptyResultConfidence != 0 {
 				n.Evm.EmptyResultConfidence = defaults.Evm.EmptyResultConfidence
 			}
+			if n.Evm.BlockTagPolicy == nil && defaults.Evm.BlockTagPolicy != nil {
+				cp := *defaults.Evm.BlockTagPolicy
+				n.Evm.BlockTagPolicy = &cp
+			}
+			if n.Evm.NormalizeResponses == nil && defaults.Evm.NormalizeResponses != nil {
+				n.Evm.NormalizeResponses = util.BoolPtr(*defaults.Evm.NormalizeResponses)
+			}
 		} else if n.Evm == nil && defaults.Evm != nil {
 			n.Evm = &EvmNetworkConfig{}
 			*n.Evm = *defaults.Evm
@@ -2157,6 +3071,101 @@ func (e *EvmNetworkConfig) SetDefaults() error {
 	if err := e.Integrity.SetDefaults(); err != nil {
 		return err
 	}
+	if e.Fork != nil && e.Fork.InstanceId == "" {
+		e.Fork.InstanceId = strconv.FormatInt(e.Fork.BlockNumber, 10)
+	}
+	if c := e.GetLogsCompletenessCheck; c != nil {
+		if c.Source == "" {
+			c.Source = GetLogsCompletenessSourceReceipts
+		}
+		if c.SampleRate == nil {
+			c.SampleRate = util.Float64Ptr(0.1)
+		}
+		if c.MaxBlockRange == 0 {
+			c.MaxBlockRange = 10
+		}
+		if c.Reject == nil {
+			c.Reject = util.BoolPtr(false)
+		}
+	}
+
+	if c := e.Abi; c != nil {
+		if c.Sourcify != nil && c.Sourcify.Endpoint == "" {
+			c.Sourcify.Endpoint = "https://sourcify.dev/server"
+		}
+		if c.Etherscan != nil && c.Etherscan.Endpoint == "" {
+			c.Etherscan.Endpoint = "https://api.etherscan.io/v2/api"
+		}
+		if c.CacheTtl == 0 {
+			c.CacheTtl = Duration(24 * time.Hour)
+		}
+		if c.FetchTimeout == 0 {
+			c.FetchTimeout = Duration(5 * time.Second)
+		}
+		if c.DecodeReverts == nil {
+			c.DecodeReverts = util.BoolPtr(true)
+		}
+		if c.DecodedLogs == nil {
+			c.DecodedLogs = util.BoolPtr(false)
+		}
+	}
+
+	if c := e.AddressActivity; c != nil {
+		if c.MaxWatchers == 0 {
+			c.MaxWatchers = 1000
+		}
+		if c.MaxAddresses == 0 {
+			c.MaxAddresses = 1000
+		}
+		if c.MaxEvents == 0 {
+			c.MaxEvents = 10000
+		}
+		if c.WatcherTtl == 0 {
+			c.WatcherTtl = Duration(5 * time.Minute)
+		}
+		if c.TokenTransfers == nil {
+			c.TokenTransfers = util.BoolPtr(true)
+		}
+	}
+
+	if c := e.TagEmulation; c != nil {
+		if len(c.Tags) == 0 {
+			c.Tags = []string{"finalized", "safe"}
+		}
+		if c.FinalizedDepth == 0 {
+			c.FinalizedDepth = e.FallbackFinalityDepth
+		}
+		if c.SafeDepth == 0 {
+			c.SafeDepth = c.FinalizedDepth
+		}
+		if c.Checkpoint != nil && c.Checkpoint.RefreshInterval == 0 {
+			c.Checkpoint.RefreshInterval = Duration(5 * time.Second)
+		}
+	}
+
+	if c := e.ReceiptsPrefetch; c != nil {
+		if c.Receipts == nil {
+			c.Receipts = util.BoolPtr(true)
+		}
+		if c.Blocks == nil {
+			c.Blocks = util.BoolPtr(false)
+		}
+		if c.BlockFullTransactions == nil {
+			c.BlockFullTransactions = util.BoolPtr(false)
+		}
+		if c.Logs == nil {
+			c.Logs = util.BoolPtr(false)
+		}
+		if c.Always == nil {
+			c.Always = util.BoolPtr(false)
+		}
+		if c.MaxConcurrency == 0 {
+			c.MaxConcurrency = 4
+		}
+		if c.InterestWindow == 0 {
+			c.InterestWindow = Duration(time.Minute)
+		}
+	}
 
 	// Defaults for network-level getLogs controls
 	if e.GetLogsMaxAllowedRange == 0 {
@@ -2575,6 +3584,12 @@ func (r *RateLimitAutoTuneConfig) SetDefaults() error {
 	if r.MaxBudget == 0 {
 		r.MaxBudget = 100000
 	}
+	if r.DiscoverLimits == nil {
+		r.DiscoverLimits = util.BoolPtr(true)
+	}
+	if r.DiscoveredLimitTtl == 0 {
+		r.DiscoveredLimitTtl = Duration(10 * time.Minute)
+	}
 
 	return nil
 }
@@ -1435,3 +1435,47 @@ func TestPostgreSQLIAMAuthDeriveFromURIExplicitOverrides(t *testing.T) {
 	assert.Equal(t, "custom-endpoint:5432", cfg.IAMAuth.Endpoint, "explicit Endpoint must not be overwritten")
 	assert.Equal(t, "custom-user", cfg.IAMAuth.DBUser, "explicit DBUser must not be overwritten")
 }
+
+func TestClickHouseConnectorDefaults_CacheOnly(t *testing.T) {
+	t.Parallel()
+
+	cfg := &ConnectorConfig{Id: "ch", ClickHouse: &ClickHouseConnectorConfig{Url: "http://localhost:8123"}}
+	require.NoError(t, cfg.SetDefaults(connectorScopeCache))
+	assert.Equal(t, DriverClickHouse, cfg.Driver)
+	assert.Equal(t, "erpc_json_rpc_cache", cfg.ClickHouse.Table)
+	assert.NoError(t, cfg.Validate())
+
+	cfg = &ConnectorConfig{Id: "ch", ClickHouse: &ClickHouseConnectorConfig{Url: "http://localhost:8123"}}
+	assert.ErrorContains(t, cfg.SetDefaults(connectorScopeSharedState), "can only be used for the cache")
+}
+
+func TestCachePolicyConfig_Validate_UnguardedConnectorFinalizedOnly(t *testing.T) {
+	t.Parallel()
+
+	ch := &ConnectorConfig{Driver: DriverClickHouse, ClickHouse: &ClickHouseConnectorConfig{Url: "http://localhost:8123"}}
+	mem := &ConnectorConfig{Driver: DriverMemory, Memory: &MemoryConnectorConfig{MaxItems: 1}}
+	cacheCfg := &CacheConfig{
+		Records: &CacheRecordsConfig{Enabled: util.BoolPtr(true)},
+		Connectors: []*ConnectorConfig{
+			{Id: "ch", Driver: DriverClickHouse, ClickHouse: ch.ClickHouse},
+			{Id: "tiered", Driver: DriverTiered, Tiered: &TieredConnectorConfig{Hot: mem, Cold: ch}},
+			{Id: "layered", Driver: DriverLayered, Layered: &LayeredConnectorConfig{L2: ch}},
+			{Id: "mem", Driver: DriverMemory, Memory: mem.Memory},
+		},
+	}
+
+	for _, id := range []string{"ch", "tiered", "layered"} {
+		p := &CachePolicyConfig{Network: "*", Method: "*", Connector: id, Finality: DataFinalityStateFinalized}
+		assert.NoError(t, p.Validate(cacheCfg), id)
+
+		p.Finality = DataFinalityStateUnfinalized
+		assert.ErrorContains(t, p.Validate(cacheCfg), "can only be used with finality: finalized", id)
+	}
+
+	p := &CachePolicyConfig{Network: "*", Method: "*", Connector: "mem", Finality: DataFinalityStateRealtime}
+	assert.NoError(t, p.Validate(cacheCfg))
+
+	cacheCfg.Records = nil
+	p = &CachePolicyConfig{Network: "*", Method: "*", Connector: "ch", Finality: DataFinalityStateUnfinalized}
+	assert.NoError(t, p.Validate(cacheCfg), "writes are unconditional without records")
+}
@@ -574,6 +574,60 @@ func (e *ErrAuthRateLimitRuleExceeded) ErrorStatusCode() int {
 	return http.StatusTooManyRequests
 }
 
+//
+// Idempotency
+//
+
+type ErrIdempotencyKeyConflict struct{ BaseError }
+
+const ErrCodeIdempotencyKeyConflict ErrorCode = "ErrIdempotencyKeyConflict"
+
+// NewErrIdempotencyKeyConflict is returned when an idempotency key cannot be
+// honored: it was already used for a different request, or the request that
+// holds it is still in flight.
+var NewErrIdempotencyKeyConflict = func(key, reason string) error {
+	return &ErrIdempotencyKeyConflict{
+		BaseError{
+			Code:    ErrCodeIdempotencyKeyConflict,
+			Message: "idempotency key conflict: " + reason,
+			Details: map[string]interface{}{
+				"idempotencyKey": key,
+			},
+		},
+	}
+}
+
+func (e *ErrIdempotencyKeyConflict) ErrorStatusCode() int {
+	return http.StatusConflict
+}
+
+//
+// Wallet methods
+//
+
+type ErrWalletMethodDisabled struct{ BaseError }
+
+const ErrCodeWalletMethodDisabled ErrorCode = "ErrWalletMethodDisabled"
+
+// NewErrWalletMethodDisabled is returned for methods that use the accounts of
+// upstream nodes (see evm.IsWalletMethod) on projects without
+// allowWalletMethods.
+var NewErrWalletMethodDisabled = func(method string) error {
+	return &ErrWalletMethodDisabled{
+		BaseError{
+			Code:    ErrCodeWalletMethodDisabled,
+			Message: fmt.Sprintf("method %s is disabled because it uses accounts of upstream nodes; sign transactions client-side and use eth_sendRawTransaction, or set allowWalletMethods on the project", method),
+			Details: map[string]interface{}{
+				"method": method,
+			},
+		},
+	}
+}
+
+func (e *ErrWalletMethodDisabled) ErrorStatusCode() int {
+	return http.StatusForbidden
+}
+
 //
 // Projects
 //
@@ -1007,7 +1061,7 @@ func (e *ErrUpstreamsExhausted) SummarizeCauses() string {
 			} else if HasErrorCode(e, ErrCodeUpstreamHedgeCancelled) {
 				cancelled++
 				continue
-			} else if HasErrorCode(e, ErrCodeEndpointClientSideException, ErrCodeJsonRpcRequestUnmarshal, ErrCodeInvalidRequest, ErrCodeInvalidUrlPath) {
+			} else if HasErrorCode(e, ErrCodeEndpointClientSideException, ErrCodeJsonRpcRequestUnmarshal, ErrCodeJsonRpcRequestMalformed, ErrCodeInvalidRequest, ErrCodeInvalidUrlPath) {
 				client++
 				continue
 			} else if HasErrorCode(e, ErrCodeEndpointTransportFailure) {
@@ -1436,6 +1490,24 @@ var NewErrUpstreamShadowing = func(upstreamId string) error {
 	}
 }
 
+type ErrUpstreamDraining struct{ BaseError }
+
+const ErrCodeUpstreamDraining ErrorCode = "ErrUpstreamDraining"
+
+var NewErrUpstreamDraining = func(upstreamId string, mode UpstreamDrainMode, reason string) error {
+	return &ErrUpstreamDraining{
+		BaseError{
+			Code:    ErrCodeUpstreamDraining,
+			Message: "upstream is " + string(mode) + " and does not accept new requests",
+			Details: map[string]interface{}{
+				"upstreamId": upstreamId,
+				"mode":       mode,
+				"reason":     reason,
+			},
+		},
+	}
+}
+
 type ErrUpstreamNotAllowed struct{ BaseError }
 
 const ErrCodeUpstreamNotAllowed ErrorCode = "ErrUpstreamNotAllowed"
@@ -1533,6 +1605,38 @@ var NewErrJsonRpcRequestUnmarshal = func(cause error, body []byte) error {
 
 func (e *ErrJsonRpcRequestUnmarshal) ErrorStatusCode() int { return 400 }
 
+// ErrJsonRpcRequestMalformed is a request refused by the strict request
+// validation, with the JSON-RPC error code it is answered with: parse error,
+// invalid request or invalid params.
+type ErrJsonRpcRequestMalformed struct {
+	BaseError
+}
+
+const ErrCodeJsonRpcRequestMalformed ErrorCode = "ErrJsonRpcRequestMalformed"
+
+var NewErrJsonRpcRequestMalformed = func(code JsonRpcErrorNumber, reason string) error {
+	return &ErrJsonRpcRequestMalformed{
+		BaseError{
+			Code:    ErrCodeJsonRpcRequestMalformed,
+			Message: reason,
+			Details: map[string]interface{}{
+				"retryableTowardNetwork": false,
+				"jsonRpcCode":            int(code),
+			},
+		},
+	}
+}
+
+// JsonRpcCode is the JSON-RPC error code the request is answered with.
+func (e *ErrJsonRpcRequestMalformed) JsonRpcCode() JsonRpcErrorNumber {
+	if c, ok := e.Details["jsonRpcCode"].(int); ok {
+		return JsonRpcErrorNumber(c)
+	}
+	return JsonRpcErrorClientSideException
+}
+
+func (e *ErrJsonRpcRequestMalformed) ErrorStatusCode() int { return 400 }
+
 type ErrJsonRpcRequestUnresolvableMethod struct {
 	BaseError
 }
@@ -2500,6 +2604,7 @@ func IsRetryableTowardsUpstream(err error) bool {
 
 		// 400 / 404 / 405 / 413 -> No Retry
 		ErrCodeJsonRpcRequestUnmarshal,
+		ErrCodeJsonRpcRequestMalformed,
 
 		// Invalid/malformed request (e.g. eth_getLogs fromBlock > toBlock, bad
 		// params, missing method) -> No Retry: no upstream will accept it, so
@@ -2550,6 +2655,7 @@ func IsClientError(err error) bool {
 		err,
 		ErrCodeEndpointClientSideException,
 		ErrCodeJsonRpcRequestUnmarshal,
+		ErrCodeJsonRpcRequestMalformed,
 		ErrCodeInvalidRequest,
 		ErrCodeGetLogsExceededMaxAllowedRange,
 		ErrCodeGetLogsExceededMaxAllowedAddresses,
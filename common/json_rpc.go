@@ -1391,16 +1391,14 @@ func (r *JsonRpcRequest) CacheHash(ctx ...context.Context) (string, error) {
 		return "", nil
 	}
 
-	if ch := r.cacheHash.Load(); ch != nil {
-		return ch.(string), nil
+	// An empty string marks a hash invalidated by an in-place rewrite
+	if ch, ok := r.cacheHash.Load().(string); ok && ch != "" {
+		return ch, nil
 	}
 
 	hasher := sha256.New()
-	for _, p := range r.Params {
-		err := hashValue(hasher, p)
-		if err != nil {
-			return "", err
-		}
+	if err := r.writeCacheHashParams(hasher); err != nil {
+		return "", err
 	}
 	b := sha256.Sum256(hasher.Sum(nil))
 	ch := fmt.Sprintf("%s:%x", r.Method, b)
@@ -1482,7 +1480,7 @@ func hashValue(h io.Writer, v interface{}) error {
 			if _, err := h.Write([]byte(k)); err != nil {
 				return err
 			}
-			err := hashValue(h, t[k])
+			err := hashValue(h, canonicalCacheParam(k, t[k]))
 			if err != nil {
 				return err
 			}
@@ -1586,6 +1584,30 @@ func TranslateToJsonRpcException(err error) error {
 			nil,
 		)
 	}
+	if HasErrorCode(err, ErrCodeWalletMethodDisabled) {
+		var msg string
+		if se, ok := err.(StandardError); ok {
+			msg = se.DeepestMessage()
+		} else {
+			msg = err.Error()
+		}
+		return NewErrJsonRpcExceptionInternal(
+			0,
+			JsonRpcErrorUnsupportedException,
+			msg,
+			err,
+			nil,
+		)
+	}
+	if mre, ok := err.(*ErrJsonRpcRequestMalformed); ok {
+		return NewErrJsonRpcExceptionInternal(
+			0,
+			mre.JsonRpcCode(),
+			mre.Message,
+			err,
+			nil,
+		)
+	}
 	if HasErrorCode(err, ErrCodeJsonRpcRequestUnmarshal) {
 		return NewErrJsonRpcExceptionInternal(
 			0,
@@ -1604,6 +1626,21 @@ func TranslateToJsonRpcException(err error) error {
 			nil,
 		)
 	}
+	if HasErrorCode(err, ErrCodeIdempotencyKeyConflict) {
+		var msg string
+		if se, ok := err.(StandardError); ok {
+			msg = se.DeepestMessage()
+		} else {
+			msg = err.Error()
+		}
+		return NewErrJsonRpcExceptionInternal(
+			0,
+			JsonRpcErrorInvalidArgument,
+			msg,
+			err,
+			nil,
+		)
+	}
 	if HasErrorCode(err, ErrCodeGetLogsExceededMaxAllowedRange, ErrCodeGetLogsExceededMaxAllowedAddresses, ErrCodeGetLogsExceededMaxAllowedTopics) {
 		return NewErrJsonRpcExceptionInternal(
 			0,
@@ -0,0 +1,200 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/zeebo/blake3"
+)
+
+// Different SDKs encode the same request differently: "0x010" vs "0x10",
+// "earliest" vs "0x0", {"blockNumber":"0x10"} vs "0x10", checksummed vs
+// lower-case addresses. CacheHash reduces params to one canonical form (see
+// also hashValue, which lower-cases strings and sorts object keys) so such
+// requests share cache entries. Only the hash is affected; the forwarded
+// request is left as the client sent it.
+
+// cacheHashBlockParams lists, per method, the top-level params that hold a
+// block number, tag or hash (taken from the default cache method refs).
+var cacheHashBlockParams = func() map[string]map[int]bool {
+	idx := make(map[string]map[int]bool)
+	for method, cfg := range DefaultWithBlockCacheMethods {
+		for _, ref := range cfg.ReqRefs {
+			if len(ref) != 1 {
+				continue
+			}
+			if i, ok := ref[0].(int); ok {
+				if idx[method] == nil {
+					idx[method] = make(map[int]bool)
+				}
+				idx[method][i] = true
+			}
+		}
+	}
+	return idx
+}()
+
+// cacheHashBlockKeys are object fields holding a block number or tag
+// (eth_getLogs/trace_filter filters, EIP-1898 block params).
+var cacheHashBlockKeys = map[string]bool{
+	"fromBlock":   true,
+	"toBlock":     true,
+	"blockNumber": true,
+}
+
+// cacheHashQuantityKeys are QUANTITY fields of call/transaction objects.
+var cacheHashQuantityKeys = map[string]bool{
+	"gas":                  true,
+	"gasPrice":             true,
+	"value":                true,
+	"nonce":                true,
+	"maxFeePerGas":         true,
+	"maxPriorityFeePerGas": true,
+	"maxFeePerBlobGas":     true,
+	"chainId":              true,
+	"type":                 true,
+}
+
+func canonicalCacheParam(key string, v interface{}) interface{} {
+	switch {
+	case cacheHashBlockKeys[key]:
+		return canonicalBlockParam(v)
+	case cacheHashQuantityKeys[key]:
+		if s, ok := v.(string); ok {
+			return canonicalHexQuantity(s)
+		}
+	}
+	return v
+}
+
+// canonicalBlockParam reduces a block number, tag or hash to one form:
+// numbers without leading zeros, "earliest" as "0x0", and EIP-1898 objects
+// collapsed to the plain number or hash they name. A block hash with
+// requireCanonical=true is kept as an object, since it is a different request.
+func canonicalBlockParam(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		if strings.EqualFold(t, "earliest") {
+			return "0x0"
+		}
+		// 32-byte block hashes are DATA and keep their zeros.
+		if len(t) == 66 {
+			return t
+		}
+		return canonicalHexQuantity(t)
+	case map[string]interface{}:
+		if len(t) == 1 {
+			if bn, ok := t["blockNumber"]; ok {
+				return canonicalBlockParam(bn)
+			}
+		}
+		if bh, ok := t["blockHash"].(string); ok {
+			rc, hasRc := t["requireCanonical"]
+			if len(t) == 1 || (len(t) == 2 && hasRc && rc == false) {
+				return bh
+			}
+		}
+	}
+	return v
+}
+
+// canonicalHexQuantity strips leading zeros from a hex quantity ("0x00" →
+// "0x0"); anything that is not a hex number is returned unchanged.
+func canonicalHexQuantity(s string) string {
+	if len(s) < 3 || s[0] != '0' || (s[1] != 'x' && s[1] != 'X') {
+		return s
+	}
+	for i := 2; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return s
+		}
+	}
+	digits := strings.TrimLeft(s[2:], "0")
+	if digits == "" {
+		digits = "0"
+	}
+	return "0x" + digits
+}
+
+func (r *JsonRpcRequest) writeCacheHashParams(h io.Writer) error {
+	blockParams := cacheHashBlockParams[r.Method]
+	for i, p := range r.Params {
+		if blockParams[i] {
+			p = canonicalBlockParam(p)
+		}
+		if err := hashValue(h, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CacheHashWith is CacheHash with a configurable algorithm. sha256 yields
+// exactly CacheHash; the others are computed on every call.
+func (r *JsonRpcRequest) CacheHashWith(algorithm CacheKeyHashAlgorithm, ctx ...context.Context) (string, error) {
+	var hasher hash.Hash
+	switch algorithm {
+	case "", CacheKeyHashSha256:
+		return r.CacheHash(ctx...)
+	case CacheKeyHashXxhash:
+		hasher = xxhash.New()
+	case CacheKeyHashBlake3:
+		hasher = blake3.New()
+	default:
+		return "", fmt.Errorf("unsupported cache key hash algorithm: %s", algorithm)
+	}
+	if r == nil {
+		return "", nil
+	}
+	if err := r.writeCacheHashParams(hasher); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%x", r.Method, hasher.Sum(nil)), nil
+}
+
+// CacheKeyMaterial returns the method and params as CacheHash sees them, as
+// JSON: the same normalizations apply, but the structure and exact values are
+// kept. Two requests sharing a cache key but not their material have collided.
+func (r *JsonRpcRequest) CacheKeyMaterial() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	blockParams := cacheHashBlockParams[r.Method]
+	params := make([]interface{}, len(r.Params))
+	for i, p := range r.Params {
+		if blockParams[i] {
+			p = canonicalBlockParam(p)
+		}
+		params[i] = canonicalKeyMaterial(p)
+	}
+	// encoding/json sorts map keys, so equal material always encodes equally.
+	return json.Marshal([]interface{}{r.Method, params})
+}
+
+// canonicalKeyMaterial mirrors hashValue's normalizations (lower-cased
+// strings, canonical block and quantity fields) on a structure-preserving copy.
+func canonicalKeyMaterial(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return strings.ToLower(t)
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = canonicalKeyMaterial(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			out[k] = canonicalKeyMaterial(canonicalCacheParam(k, item))
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,120 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonRpcRequest_CacheHash_Canonical(t *testing.T) {
+	hashOf := func(t *testing.T, method string, params ...interface{}) string {
+		h, err := NewJsonRpcRequest(method, params).CacheHash()
+		require.NoError(t, err)
+		return h
+	}
+	const blockHash = "0x00aabbccddeeff00112233445566778899aabbccddeeff001122334455667700"
+
+	sameHash := []struct {
+		name   string
+		method string
+		a, b   []interface{}
+	}{
+		{"PaddedBlockNumber", "eth_getBlockByNumber",
+			[]interface{}{"0x010", false}, []interface{}{"0x10", false}},
+		{"EarliestIsGenesis", "eth_getBalance",
+			[]interface{}{"0xAbC", "earliest"}, []interface{}{"0xabc", "0x0"}},
+		{"Eip1898BlockNumber", "eth_call",
+			[]interface{}{map[string]interface{}{"to": "0xabc"}, map[string]interface{}{"blockNumber": "0x0a"}},
+			[]interface{}{map[string]interface{}{"to": "0xABC"}, "0xa"}},
+		{"Eip1898NonCanonicalBlockHash", "eth_getCode",
+			[]interface{}{"0xabc", map[string]interface{}{"blockHash": blockHash, "requireCanonical": false}},
+			[]interface{}{"0xabc", blockHash}},
+		{"CallObjectQuantities", "eth_call",
+			[]interface{}{map[string]interface{}{"to": "0xabc", "value": "0x00", "gas": "0x0100"}, "latest"},
+			[]interface{}{map[string]interface{}{"gas": "0x100", "value": "0x0", "to": "0xabc"}, "LATEST"}},
+		{"FilterBlocks", "eth_getLogs",
+			[]interface{}{map[string]interface{}{"fromBlock": "0x0001", "toBlock": "earliest"}},
+			[]interface{}{map[string]interface{}{"fromBlock": "0x1", "toBlock": "0x0"}}},
+	}
+	for _, tc := range sameHash {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, hashOf(t, tc.method, tc.a...), hashOf(t, tc.method, tc.b...))
+		})
+	}
+
+	differentHash := []struct {
+		name   string
+		method string
+		a, b   []interface{}
+	}{
+		{"BlockHashKeepsZeros", "eth_getBlockByHash",
+			[]interface{}{blockHash, false}, []interface{}{"0xaabbccddeeff00112233445566778899aabbccddeeff001122334455667700", false}},
+		{"CallDataKeepsZeros", "eth_call",
+			[]interface{}{map[string]interface{}{"to": "0xabc", "data": "0x00"}, "latest"},
+			[]interface{}{map[string]interface{}{"to": "0xabc", "data": "0x0"}, "latest"}},
+		{"RequireCanonicalBlockHash", "eth_getCode",
+			[]interface{}{"0xabc", map[string]interface{}{"blockHash": blockHash, "requireCanonical": true}},
+			[]interface{}{"0xabc", blockHash}},
+	}
+	for _, tc := range differentHash {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NotEqual(t, hashOf(t, tc.method, tc.a...), hashOf(t, tc.method, tc.b...))
+		})
+	}
+}
+
+func TestJsonRpcRequest_CacheHashWith(t *testing.T) {
+	padded := NewJsonRpcRequest("eth_getBlockByNumber", []interface{}{"0x010", false})
+	plain := NewJsonRpcRequest("eth_getBlockByNumber", []interface{}{"0x10", false})
+
+	sha, err := padded.CacheHashWith(CacheKeyHashSha256)
+	require.NoError(t, err)
+	legacy, err := padded.CacheHash()
+	require.NoError(t, err)
+	assert.Equal(t, legacy, sha, "sha256 keeps existing cache keys")
+
+	for _, algorithm := range []CacheKeyHashAlgorithm{CacheKeyHashXxhash, CacheKeyHashBlake3} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			a, err := padded.CacheHashWith(algorithm)
+			require.NoError(t, err)
+			b, err := plain.CacheHashWith(algorithm)
+			require.NoError(t, err)
+			assert.Equal(t, a, b, "canonicalization applies to every algorithm")
+			assert.NotEqual(t, sha, a)
+			assert.Regexp(t, `^eth_getBlockByNumber:[0-9a-f]+$`, a)
+		})
+	}
+
+	_, err = padded.CacheHashWith("md5")
+	assert.Error(t, err)
+}
+
+func TestJsonRpcRequest_CacheKeyMaterial(t *testing.T) {
+	materialOf := func(t *testing.T, method string, params ...interface{}) string {
+		m, err := NewJsonRpcRequest(method, params).CacheKeyMaterial()
+		require.NoError(t, err)
+		return string(m)
+	}
+
+	// Equivalent encodings share their material as they share their key.
+	assert.Equal(t,
+		materialOf(t, "eth_call", map[string]interface{}{"to": "0xABC", "gas": "0x0100"}, "0x0a"),
+		materialOf(t, "eth_call", map[string]interface{}{"gas": "0x100", "to": "0xabc"}, "0xa"),
+	)
+
+	// Params are hashed as an undelimited stream, so these two requests share
+	// a key; their material tells them apart.
+	a := NewJsonRpcRequest("eth_call", []interface{}{map[string]interface{}{"data": "0xb", "to": "0xa"}, "0x1"})
+	b := NewJsonRpcRequest("eth_call", []interface{}{map[string]interface{}{"data": "0xbto0xa"}, "0x1"})
+	ha, err := a.CacheHash()
+	require.NoError(t, err)
+	hb, err := b.CacheHash()
+	require.NoError(t, err)
+	require.Equal(t, ha, hb)
+	ma, err := a.CacheKeyMaterial()
+	require.NoError(t, err)
+	mb, err := b.CacheKeyMaterial()
+	require.NoError(t, err)
+	assert.NotEqual(t, string(ma), string(mb))
+}
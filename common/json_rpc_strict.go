@@ -0,0 +1,340 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ValidateJsonRpcRequestStrict checks a single JSON-RPC request body before
+// it is parsed, so malformed or hostile input never reaches the request
+// parsing, caching and forwarding paths. The body must be one JSON object
+// with no duplicate or differently-cased members, "jsonrpc" must be "2.0",
+// "id" a string, an integer or null, "method" a name made of letters, digits,
+// '_' and '.', and "params" absent, null or an array. The params of the
+// common eth_* methods are also checked for count and type. The returned
+// error is an ErrJsonRpcRequestMalformed carrying the JSON-RPC code (-32700,
+// -32600 or -32602) the request is answered with.
+func ValidateJsonRpcRequestStrict(body []byte, cfg *RequestValidationConfig) error {
+	maxMethodLength, maxIdLength, maxParams := 64, 128, 32
+	if cfg != nil {
+		if cfg.MaxMethodLength > 0 {
+			maxMethodLength = cfg.MaxMethodLength
+		}
+		if cfg.MaxIdLength > 0 {
+			maxIdLength = cfg.MaxIdLength
+		}
+		if cfg.MaxParams > 0 {
+			maxParams = cfg.MaxParams
+		}
+	}
+
+	members, err := decodeStrictObject(body)
+	if err != nil {
+		return err
+	}
+
+	version, ok := members["jsonrpc"]
+	if !ok {
+		return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, "jsonrpc member is required")
+	}
+	if v, isString := jsonString(version); !isString || v != "2.0" {
+		return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, `jsonrpc must be "2.0"`)
+	}
+
+	if id, ok := members["id"]; ok {
+		if err := validateStrictId(id, maxIdLength); err != nil {
+			return err
+		}
+	}
+
+	rawMethod, ok := members["method"]
+	if !ok {
+		return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, "method member is required")
+	}
+	method, isString := jsonString(rawMethod)
+	if !isString {
+		return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, "method must be a string")
+	}
+	if err := validateStrictMethod(method, maxMethodLength); err != nil {
+		return err
+	}
+
+	var params []json.RawMessage
+	if raw, ok := members["params"]; ok && !isJsonNull(raw) {
+		switch raw[0] {
+		case '[':
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return NewErrJsonRpcRequestMalformed(JsonRpcErrorParseException, "params is not valid JSON")
+			}
+		case '{':
+			return NewErrJsonRpcRequestMalformed(JsonRpcErrorInvalidArgument, "params must be an array, params by name are not supported")
+		default:
+			return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, "params must be an array")
+		}
+	}
+	if len(params) > maxParams {
+		return NewErrJsonRpcRequestMalformed(JsonRpcErrorInvalidArgument, fmt.Sprintf("too many params, at most %d are allowed", maxParams))
+	}
+
+	if rule, ok := strictParamRules[method]; ok {
+		if err := rule.check(method, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeStrictObject decodes the top-level members of a JSON object without
+// decoding their values.
+func decodeStrictObject(body []byte) (map[string]json.RawMessage, error) {
+	parseErr := func(err error) error {
+		return NewErrJsonRpcRequestMalformed(JsonRpcErrorParseException, fmt.Sprintf("request is not valid JSON: %s", err))
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, parseErr(err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		// The value still has to be valid JSON for the error to be an
+		// invalid request rather than a parse error.
+		if !json.Valid(body) {
+			return nil, parseErr(errors.New("invalid value"))
+		}
+		return nil, NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, "request must be a JSON object")
+	}
+
+	members := make(map[string]json.RawMessage, 4)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, parseErr(err)
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, parseErr(err)
+		}
+		if _, dup := members[key]; dup {
+			return nil, NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, fmt.Sprintf("duplicate member %q", key))
+		}
+		// Request parsers match member names case-insensitively, so a
+		// "Method" next to "method" could be read differently by each.
+		switch lower := strings.ToLower(key); lower {
+		case "jsonrpc", "id", "method", "params":
+			if key != lower {
+				return nil, NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, fmt.Sprintf("member %q must be spelled %q", key, lower))
+			}
+		}
+		members[key] = value
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, parseErr(err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, parseErr(errors.New("unexpected data after the request object"))
+	}
+	return members, nil
+}
+
+func validateStrictId(raw json.RawMessage, maxLength int) error {
+	switch {
+	case isJsonNull(raw):
+		return nil
+	case raw[0] == '"':
+		id, _ := jsonString(raw)
+		if len(id) > maxLength {
+			return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, fmt.Sprintf("id is longer than %d characters", maxLength))
+		}
+		return nil
+	case raw[0] == '-' || (raw[0] >= '0' && raw[0] <= '9'):
+		if bytes.ContainsAny(raw, ".eE") {
+			return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, "numeric id must be an integer")
+		}
+		if len(raw) > maxLength {
+			return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, fmt.Sprintf("id is longer than %d characters", maxLength))
+		}
+		return nil
+	default:
+		return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, "id must be a string, an integer or null")
+	}
+}
+
+func validateStrictMethod(method string, maxLength int) error {
+	if method == "" {
+		return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, "method must not be empty")
+	}
+	if len(method) > maxLength {
+		return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, fmt.Sprintf("method is longer than %d characters", maxLength))
+	}
+	for i := 0; i < len(method); i++ {
+		c := method[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '_' || c == '.'):
+		default:
+			return NewErrJsonRpcRequestMalformed(JsonRpcErrorClientSideException, "method must start with a letter and contain only letters, digits, '_' and '.'")
+		}
+	}
+	return nil
+}
+
+// strictParam is the expected type of one positional param.
+type strictParam int
+
+const (
+	paramAddress  strictParam = iota // 20-byte hex address
+	paramHash                        // 32-byte hex hash
+	paramQuantity                    // hex number
+	paramBlockTag                    // hex block number or a block tag
+	paramBlockRef                    // block tag, block hash or EIP-1898 object
+	paramData                        // hex bytes
+	paramBool
+	paramObject
+)
+
+func (p strictParam) String() string {
+	switch p {
+	case paramAddress:
+		return "an address"
+	case paramHash:
+		return "a 32-byte hash"
+	case paramQuantity:
+		return "a hex quantity"
+	case paramBlockTag:
+		return "a block number or tag"
+	case paramBlockRef:
+		return "a block number, tag, hash or object"
+	case paramData:
+		return "hex data"
+	case paramBool:
+		return "a boolean"
+	default:
+		return "an object"
+	}
+}
+
+// strictParamRule lists the params of a method, of which the first min are
+// required.
+type strictParamRule struct {
+	min    int
+	params []strictParam
+}
+
+var strictParamRules = map[string]strictParamRule{
+	"eth_chainId":                             {},
+	"eth_blockNumber":                         {},
+	"eth_gasPrice":                            {},
+	"eth_maxPriorityFeePerGas":                {},
+	"net_version":                             {},
+	"eth_getBalance":                          {1, []strictParam{paramAddress, paramBlockRef}},
+	"eth_getCode":                             {1, []strictParam{paramAddress, paramBlockRef}},
+	"eth_getTransactionCount":                 {1, []strictParam{paramAddress, paramBlockRef}},
+	"eth_getStorageAt":                        {2, []strictParam{paramAddress, paramQuantity, paramBlockRef}},
+	"eth_getBlockByNumber":                    {1, []strictParam{paramBlockTag, paramBool}},
+	"eth_getBlockByHash":                      {1, []strictParam{paramHash, paramBool}},
+	"eth_getBlockReceipts":                    {1, []strictParam{paramBlockRef}},
+	"eth_getBlockTransactionCountByNumber":    {1, []strictParam{paramBlockTag}},
+	"eth_getBlockTransactionCountByHash":      {1, []strictParam{paramHash}},
+	"eth_getTransactionByHash":                {1, []strictParam{paramHash}},
+	"eth_getTransactionReceipt":               {1, []strictParam{paramHash}},
+	"eth_getTransactionByBlockNumberAndIndex": {2, []strictParam{paramBlockTag, paramQuantity}},
+	"eth_getTransactionByBlockHashAndIndex":   {2, []strictParam{paramHash, paramQuantity}},
+	"eth_call":                                {1, []strictParam{paramObject, paramBlockRef, paramObject}},
+	"eth_estimateGas":                         {1, []strictParam{paramObject, paramBlockRef, paramObject}},
+	"eth_getLogs":                             {1, []strictParam{paramObject}},
+	"eth_sendRawTransaction":                  {1, []strictParam{paramData}},
+}
+
+func (r strictParamRule) check(method string, params []json.RawMessage) error {
+	if len(params) < r.min || len(params) > len(r.params) {
+		if r.min == len(r.params) {
+			return NewErrJsonRpcRequestMalformed(JsonRpcErrorInvalidArgument, fmt.Sprintf("%s takes %d params, got %d", method, r.min, len(params)))
+		}
+		return NewErrJsonRpcRequestMalformed(JsonRpcErrorInvalidArgument, fmt.Sprintf("%s takes %d to %d params, got %d", method, r.min, len(r.params), len(params)))
+	}
+	for i, raw := range params {
+		if !r.params[i].matches(raw) {
+			return NewErrJsonRpcRequestMalformed(JsonRpcErrorInvalidArgument, fmt.Sprintf("param %d of %s must be %s", i, method, r.params[i]))
+		}
+	}
+	return nil
+}
+
+func (p strictParam) matches(raw json.RawMessage) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	switch p {
+	case paramBool:
+		return string(raw) == "true" || string(raw) == "false"
+	case paramObject:
+		return raw[0] == '{'
+	case paramBlockRef:
+		if raw[0] == '{' {
+			return true
+		}
+	}
+
+	s, ok := jsonString(raw)
+	if !ok {
+		return false
+	}
+	switch p {
+	case paramAddress:
+		return isHexString(s, 40, 40)
+	case paramHash:
+		return isHexString(s, 64, 64)
+	case paramQuantity:
+		return isHexString(s, 1, 64)
+	case paramData:
+		return isHexString(s, 0, len(s)) && len(s)%2 == 0
+	case paramBlockTag, paramBlockRef:
+		switch s {
+		case "latest", "earliest", "pending", "safe", "finalized":
+			return true
+		}
+		if p == paramBlockRef && isHexString(s, 64, 64) {
+			return true
+		}
+		return isHexString(s, 1, 16)
+	}
+	return false
+}
+
+// isHexString reports whether s is "0x" followed by lo to hi hex digits.
+func isHexString(s string, lo, hi int) bool {
+	if len(s) < 2 || s[0] != '0' || (s[1] != 'x' && s[1] != 'X') {
+		return false
+	}
+	digits := s[2:]
+	if len(digits) < lo || len(digits) > hi {
+		return false
+	}
+	for i := 0; i < len(digits); i++ {
+		c := digits[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonString(raw json.RawMessage) (string, bool) {
+	if len(raw) == 0 || raw[0] != '"' {
+		return "", false
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+func isJsonNull(raw json.RawMessage) bool {
+	return string(raw) == "null"
+}
@@ -0,0 +1,137 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateJsonRpcRequestStrict(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		code JsonRpcErrorNumber // 0 when the request is valid
+	}{
+		{"Valid", `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x00000000219ab540356cBB839Cbe05303d7705Fa","latest"]}`, 0},
+		{"ValidStringId", `{"jsonrpc":"2.0","id":"abc","method":"eth_blockNumber","params":[]}`, 0},
+		{"ValidNullIdNoParams", `{"jsonrpc":"2.0","id":null,"method":"eth_chainId"}`, 0},
+		{"ValidBigIntegerId", `{"jsonrpc":"2.0","id":123456789012345678901234567890,"method":"eth_chainId"}`, 0},
+		{"ValidUnknownMethod", `{"jsonrpc":"2.0","id":1,"method":"debug_traceTransaction","params":["0x1",{"tracer":"callTracer"}]}`, 0},
+		{"ValidExtraMember", `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","extra":{"a":[1,2]}}`, 0},
+		{"ValidEip1898BlockRef", `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":"0x1"},{"blockHash":"0x01"}]}`, 0},
+		{"ValidBlockHashRef", `{"jsonrpc":"2.0","id":1,"method":"eth_getCode","params":["0x00000000219ab540356cBB839Cbe05303d7705Fa","0x88e96d4537bea4d9c05d12549907b32561d3bf31f45aae734cdc119f13406cb6"]}`, 0},
+		{"ValidOpenRpcDiscover", `{"jsonrpc":"2.0","id":1,"method":"rpc.discover"}`, 0},
+
+		{"Empty", ``, JsonRpcErrorParseException},
+		{"Truncated", `{"jsonrpc":"2.0","id":1,"method":"eth_chainId"`, JsonRpcErrorParseException},
+		{"TrailingData", `{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}{}`, JsonRpcErrorParseException},
+		{"TrailingComma", `{"jsonrpc":"2.0","id":1,"method":"eth_chainId",}`, JsonRpcErrorParseException},
+		{"NotAnObject", `"eth_chainId"`, JsonRpcErrorClientSideException},
+		{"NestedBatch", `[{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}]`, JsonRpcErrorClientSideException},
+		{"MissingVersion", `{"id":1,"method":"eth_chainId"}`, JsonRpcErrorClientSideException},
+		{"WrongVersion", `{"jsonrpc":"1.0","id":1,"method":"eth_chainId"}`, JsonRpcErrorClientSideException},
+		{"NumericVersion", `{"jsonrpc":2.0,"id":1,"method":"eth_chainId"}`, JsonRpcErrorClientSideException},
+		{"ObjectId", `{"jsonrpc":"2.0","id":{"a":1},"method":"eth_chainId"}`, JsonRpcErrorClientSideException},
+		{"BoolId", `{"jsonrpc":"2.0","id":true,"method":"eth_chainId"}`, JsonRpcErrorClientSideException},
+		{"FractionalId", `{"jsonrpc":"2.0","id":1.5,"method":"eth_chainId"}`, JsonRpcErrorClientSideException},
+		{"ExponentId", `{"jsonrpc":"2.0","id":1e400,"method":"eth_chainId"}`, JsonRpcErrorClientSideException},
+		{"DuplicateMethod", `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","method":"eth_sendRawTransaction"}`, JsonRpcErrorClientSideException},
+		{"MixedCaseMethod", `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","Method":"eth_sendRawTransaction"}`, JsonRpcErrorClientSideException},
+		{"MissingMethod", `{"jsonrpc":"2.0","id":1}`, JsonRpcErrorClientSideException},
+		{"EmptyMethod", `{"jsonrpc":"2.0","id":1,"method":""}`, JsonRpcErrorClientSideException},
+		{"NumericMethod", `{"jsonrpc":"2.0","id":1,"method":1}`, JsonRpcErrorClientSideException},
+		{"MethodCharset", `{"jsonrpc":"2.0","id":1,"method":"eth_chainId\n"}`, JsonRpcErrorClientSideException},
+		{"MethodStartsWithDigit", `{"jsonrpc":"2.0","id":1,"method":"1eth"}`, JsonRpcErrorClientSideException},
+		{"ScalarParams", `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":"x"}`, JsonRpcErrorClientSideException},
+		{"NamedParams", `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":{"a":1}}`, JsonRpcErrorInvalidArgument},
+		{"TooFewParams", `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":[]}`, JsonRpcErrorInvalidArgument},
+		{"TooManyParams", `{"jsonrpc":"2.0","id":1,"method":"eth_getTransactionByHash","params":["0x88e96d4537bea4d9c05d12549907b32561d3bf31f45aae734cdc119f13406cb6",1]}`, JsonRpcErrorInvalidArgument},
+		{"BadAddress", `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x123","latest"]}`, JsonRpcErrorInvalidArgument},
+		{"BadBlockTag", `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["newest",false]}`, JsonRpcErrorInvalidArgument},
+		{"NumericBlockNumber", `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":[123,false]}`, JsonRpcErrorInvalidArgument},
+		{"StringBool", `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["latest","true"]}`, JsonRpcErrorInvalidArgument},
+		{"OddRawTransaction", `{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0xabc"]}`, JsonRpcErrorInvalidArgument},
+		{"LogsFilterNotObject", `{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":["0x1"]}`, JsonRpcErrorInvalidArgument},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateJsonRpcRequestStrict([]byte(tc.body), nil)
+			if tc.code == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			mre, ok := err.(*ErrJsonRpcRequestMalformed)
+			require.True(t, ok, "unexpected error type %T", err)
+			assert.Equal(t, tc.code, mre.JsonRpcCode(), mre.Message)
+
+			translated := TranslateToJsonRpcException(err)
+			jre, ok := translated.(*ErrJsonRpcExceptionInternal)
+			require.True(t, ok)
+			assert.Equal(t, tc.code, jre.NormalizedCode())
+		})
+	}
+
+	t.Run("Limits", func(t *testing.T) {
+		cfg := &RequestValidationConfig{MaxMethodLength: 8, MaxIdLength: 3, MaxParams: 1}
+		assert.Error(t, ValidateJsonRpcRequestStrict([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`), cfg))
+		assert.Error(t, ValidateJsonRpcRequestStrict([]byte(`{"jsonrpc":"2.0","id":"abcd","method":"net_x"}`), cfg))
+		assert.Error(t, ValidateJsonRpcRequestStrict([]byte(`{"jsonrpc":"2.0","id":1234,"method":"net_x"}`), cfg))
+		assert.Error(t, ValidateJsonRpcRequestStrict([]byte(`{"jsonrpc":"2.0","id":1,"method":"net_x","params":[1,2]}`), cfg))
+		assert.NoError(t, ValidateJsonRpcRequestStrict([]byte(`{"jsonrpc":"2.0","id":123,"method":"net_x","params":[1]}`), cfg))
+	})
+}
+
+// FuzzValidateJsonRpcRequestStrict checks that no input makes the validator
+// panic, that every rejection carries one of the three JSON-RPC codes, and
+// that whatever it accepts is parsed by NormalizedRequest to the same method.
+func FuzzValidateJsonRpcRequestStrict(f *testing.F) {
+	for _, seed := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x00000000219ab540356cBB839Cbe05303d7705Fa","latest"]}`,
+		`{"jsonrpc":"2.0","id":"x","method":"eth_call","params":[{"to":"0x1"},{"blockNumber":"0x1"}]}`,
+		`{"jsonrpc":"2.0","id":null,"method":"eth_getLogs","params":[{"fromBlock":"0x1"}]}`,
+		`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","Method":"eth_sendRawTransaction"}`,
+		`{"jsonrpc":"2.0","id":1e3,"method":"eth_chainId"}`,
+		`{"jsonrpc":"2.0","id":[1],"method":"eth_chainId"}`,
+		`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}  `,
+		`{"jsonrpc":"2.0","method":"\u0000"}`,
+		`[{"jsonrpc":"2.0"}]`,
+		`{"a":`,
+		``,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		err := ValidateJsonRpcRequestStrict(body, nil)
+		if err != nil {
+			mre, ok := err.(*ErrJsonRpcRequestMalformed)
+			if !ok {
+				t.Fatalf("unexpected error type %T: %v", err, err)
+			}
+			switch mre.JsonRpcCode() {
+			case JsonRpcErrorParseException, JsonRpcErrorClientSideException, JsonRpcErrorInvalidArgument:
+			default:
+				t.Fatalf("unexpected json-rpc code %d", mre.JsonRpcCode())
+			}
+			return
+		}
+
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(body, &members); err != nil {
+			t.Fatalf("accepted a body that is not a JSON object: %v", err)
+		}
+		var method string
+		if err := json.Unmarshal(members["method"], &method); err != nil {
+			t.Fatalf("accepted a body without a string method: %v", err)
+		}
+		got, err := NewNormalizedRequest(body).Method()
+		if err != nil {
+			t.Fatalf("accepted a body the request parser rejects: %v", err)
+		}
+		if got != method {
+			t.Fatalf("request parser read method %q, validator read %q", got, method)
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package common
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// LogTargetKind is the kind of component a targeted log level applies to.
+type LogTargetKind string
+
+const (
+	LogTargetUpstream   LogTargetKind = "upstream"
+	LogTargetConnector  LogTargetKind = "connector"
+	LogTargetNetwork    LogTargetKind = "network"
+	LogTargetConnection LogTargetKind = "connection"
+)
+
+func (k LogTargetKind) Valid() bool {
+	switch k {
+	case LogTargetUpstream, LogTargetConnector, LogTargetNetwork, LogTargetConnection:
+		return true
+	}
+	return false
+}
+
+// LogTarget identifies a single component, e.g. {upstream, alchemy-mainnet}.
+type LogTarget struct {
+	Kind LogTargetKind
+	Id   string
+}
+
+// LogTargetState is a snapshot of an active targeted log level.
+type LogTargetState struct {
+	Kind      LogTargetKind `json:"component"`
+	Id        string        `json:"id"`
+	Level     string        `json:"level"`
+	ExpiresAt time.Time     `json:"expiresAt"`
+}
+
+type logOverride struct {
+	level     zerolog.Level
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+// LogControl owns the process log level at runtime. Loggers are built at
+// Trace level and carry a Sampler from this control, so the effective level
+// can change without rebuilding them: the base level applies to every
+// logger, and a targeted level lowers it for one component until it expires.
+//
+// zerolog's global level is kept at the lowest active level so events below
+// it are still rejected before reaching any sampler.
+type LogControl struct {
+	baseLevel atomic.Int32
+
+	mu        sync.Mutex
+	overrides map[LogTarget]*logOverride
+	snapshot  atomic.Pointer[map[LogTarget]zerolog.Level]
+}
+
+// LogLevels is the process-wide log control used by the admin API.
+var LogLevels = NewLogControl()
+
+func NewLogControl() *LogControl {
+	c := &LogControl{overrides: make(map[LogTarget]*logOverride)}
+	c.baseLevel.Store(int32(zerolog.TraceLevel))
+	return c
+}
+
+// Level returns the base log level.
+func (c *LogControl) Level() zerolog.Level {
+	return zerolog.Level(c.baseLevel.Load())
+}
+
+// SetLevel changes the base log level for every logger.
+func (c *LogControl) SetLevel(level zerolog.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.baseLevel.Store(int32(level))
+	c.apply()
+}
+
+// SetTargetLevel lowers the level of a single component for ttl, replacing
+// any previous override of the same target. Returns the expiry time.
+func (c *LogControl) SetTargetLevel(target LogTarget, level zerolog.Level, ttl time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if prev, ok := c.overrides[target]; ok {
+		prev.timer.Stop()
+	}
+	o := &logOverride{level: level, expiresAt: time.Now().Add(ttl)}
+	o.timer = time.AfterFunc(ttl, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.overrides[target] == o {
+			delete(c.overrides, target)
+			c.apply()
+		}
+	})
+	c.overrides[target] = o
+	c.apply()
+	return o.expiresAt
+}
+
+// ResetTarget removes the override of a single component, reporting whether
+// one was active.
+func (c *LogControl) ResetTarget(target LogTarget) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	o, ok := c.overrides[target]
+	if !ok {
+		return false
+	}
+	o.timer.Stop()
+	delete(c.overrides, target)
+	c.apply()
+	return true
+}
+
+// ResetTargets removes every override and returns how many were active.
+func (c *LogControl) ResetTargets() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := len(c.overrides)
+	for t, o := range c.overrides {
+		o.timer.Stop()
+		delete(c.overrides, t)
+	}
+	c.apply()
+	return n
+}
+
+// Targets returns the active overrides ordered by component and id.
+func (c *LogControl) Targets() []LogTargetState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	states := make([]LogTargetState, 0, len(c.overrides))
+	for t, o := range c.overrides {
+		states = append(states, LogTargetState{
+			Kind:      t.Kind,
+			Id:        t.Id,
+			Level:     o.level.String(),
+			ExpiresAt: o.expiresAt,
+		})
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Kind != states[j].Kind {
+			return states[i].Kind < states[j].Kind
+		}
+		return states[i].Id < states[j].Id
+	})
+	return states
+}
+
+// apply must be called with c.mu held.
+func (c *LogControl) apply() {
+	global := c.Level()
+	var snap map[LogTarget]zerolog.Level
+	if len(c.overrides) > 0 {
+		snap = make(map[LogTarget]zerolog.Level, len(c.overrides))
+		for t, o := range c.overrides {
+			snap[t] = o.level
+			if o.level < global {
+				global = o.level
+			}
+		}
+	}
+	c.snapshot.Store(&snap)
+	zerolog.SetGlobalLevel(global)
+}
+
+// Sampler returns a zerolog sampler that lets through events at or above the
+// base level, or at or above the override of any of the given targets.
+// Attach it with logger.Sample() where a component's logger is built.
+func (c *LogControl) Sampler(targets ...LogTarget) zerolog.Sampler {
+	return &logTargetSampler{control: c, targets: targets}
+}
+
+type logTargetSampler struct {
+	control *LogControl
+	targets []LogTarget
+}
+
+func (s *logTargetSampler) Sample(lvl zerolog.Level) bool {
+	if lvl >= s.control.Level() {
+		return true
+	}
+	snap := s.control.snapshot.Load()
+	if snap == nil {
+		return false
+	}
+	for _, t := range s.targets {
+		if min, ok := (*snap)[t]; ok && lvl >= min {
+			return true
+		}
+	}
+	return false
+}
+
+// LogLevelEnabled reports whether lg may emit events at lvl. Use it instead
+// of comparing lg.GetLevel() to guard expensive log-only work: loggers are
+// built at Trace level and gated by LogControl.
+func LogLevelEnabled(lg *zerolog.Logger, lvl zerolog.Level) bool {
+	return lvl >= lg.GetLevel() && lvl >= zerolog.GlobalLevel()
+}
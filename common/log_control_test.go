@@ -0,0 +1,66 @@
+package common
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogControl(t *testing.T) {
+	prev := zerolog.GlobalLevel()
+	t.Cleanup(func() { zerolog.SetGlobalLevel(prev) })
+
+	c := NewLogControl()
+	c.SetLevel(zerolog.InfoLevel)
+
+	var buf bytes.Buffer
+	root := zerolog.New(&buf).Sample(c.Sampler())
+	upsA := LogTarget{Kind: LogTargetUpstream, Id: "a"}
+	lgA := root.With().Str("upstreamId", "a").Logger().Sample(c.Sampler(upsA))
+	lgB := root.With().Str("upstreamId", "b").Logger().Sample(c.Sampler(LogTarget{Kind: LogTargetUpstream, Id: "b"}))
+
+	emitted := func(lg zerolog.Logger) bool {
+		buf.Reset()
+		lg.Debug().Msg("x")
+		return buf.Len() > 0
+	}
+
+	assert.False(t, emitted(lgA))
+	assert.False(t, LogLevelEnabled(&lgA, zerolog.DebugLevel))
+
+	c.SetTargetLevel(upsA, zerolog.DebugLevel, time.Hour)
+	assert.True(t, emitted(lgA), "targeted upstream logs at debug")
+	assert.False(t, emitted(lgB), "other upstreams stay at the base level")
+	assert.False(t, emitted(root))
+	assert.True(t, LogLevelEnabled(&lgA, zerolog.DebugLevel))
+
+	targets := c.Targets()
+	require.Len(t, targets, 1)
+	assert.Equal(t, LogTargetUpstream, targets[0].Kind)
+	assert.Equal(t, "debug", targets[0].Level)
+
+	assert.True(t, c.ResetTarget(upsA))
+	assert.False(t, c.ResetTarget(upsA))
+	assert.False(t, emitted(lgA))
+	assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel(), "global level restored once no override is active")
+
+	t.Run("Expiry", func(t *testing.T) {
+		c.SetTargetLevel(upsA, zerolog.DebugLevel, 20*time.Millisecond)
+		assert.True(t, emitted(lgA))
+		require.Eventually(t, func() bool { return len(c.Targets()) == 0 }, time.Second, 5*time.Millisecond)
+		assert.False(t, emitted(lgA))
+	})
+
+	t.Run("BaseLevel", func(t *testing.T) {
+		c.SetLevel(zerolog.DebugLevel)
+		assert.True(t, emitted(lgB))
+		c.SetLevel(zerolog.WarnLevel)
+		buf.Reset()
+		lgB.Info().Msg("x")
+		assert.Zero(t, buf.Len())
+	})
+}
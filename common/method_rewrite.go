@@ -0,0 +1,163 @@
+package common
+
+import (
+	"context"
+	"fmt"
+)
+
+// FindMethodRewrite returns the first rule whose method pattern matches the
+// given method, or nil if none match.
+func FindMethodRewrite(rules []*MethodRewriteConfig, method string) *MethodRewriteConfig {
+	for _, rule := range rules {
+		if rule == nil || rule.Method == "" {
+			continue
+		}
+		if match, err := WildcardMatch(rule.Method, method); err == nil && match {
+			return rule
+		}
+	}
+	return nil
+}
+
+// ApplyTo rewrites the method and params of jrq in place and reports whether
+// anything changed. Callers that must not affect other consumers of the same
+// request (e.g. per-upstream rewrites) should pass a clone.
+func (m *MethodRewriteConfig) ApplyTo(jrq *JsonRpcRequest) bool {
+	if m == nil || jrq == nil {
+		return false
+	}
+
+	jrq.Lock()
+	defer jrq.Unlock()
+
+	changed := false
+	if m.Alias != "" && m.Alias != jrq.Method {
+		jrq.Method = m.Alias
+		changed = true
+	}
+	for _, pr := range m.Params {
+		if pr == nil || pr.Index < 0 || pr.Index > len(jrq.Params) {
+			continue
+		}
+		if pr.Index == len(jrq.Params) {
+			// Appending only makes sense for an unconditional replacement value
+			if pr.From != nil || pr.To == nil {
+				continue
+			}
+			jrq.Params = append(jrq.Params, wrapParamValue(pr, pr.To))
+			changed = true
+			continue
+		}
+		current := jrq.Params[pr.Index]
+		if pr.From != nil && !valueEqual(pr.From, current) {
+			continue
+		}
+		next := current
+		if pr.To != nil {
+			next = pr.To
+		}
+		jrq.Params[pr.Index] = wrapParamValue(pr, next)
+		changed = true
+	}
+	if changed {
+		// Params/method changed, so any previously computed hash is stale
+		jrq.cacheHash.Store("")
+	}
+
+	return changed
+}
+
+func wrapParamValue(pr *ParamRewriteConfig, value interface{}) interface{} {
+	if pr.WrapField == "" {
+		return value
+	}
+	return map[string]interface{}{pr.WrapField: value}
+}
+
+// UnwrapResult returns a response whose result is the ResultField of the
+// original object result. Responses without a result (errors, nulls) or rules
+// without a ResultField are returned as-is.
+func (m *MethodRewriteConfig) UnwrapResult(ctx context.Context, jrr *JsonRpcResponse) (*JsonRpcResponse, error) {
+	if m == nil || m.ResultField == "" || jrr == nil || jrr.Error != nil {
+		return jrr, nil
+	}
+	if jrr.IsResultEmptyish(ctx) {
+		return jrr, nil
+	}
+	raw, err := jrr.PeekBytesByPath(ctx, m.ResultField)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap result field '%s' for rewritten method: %w", m.ResultField, err)
+	}
+	out, err := NewJsonRpcResponseFromBytes(nil, raw, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := out.SetID(jrr.ID()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UnwrapResponse applies UnwrapResult to the JSON-RPC payload of nr in place.
+func (m *MethodRewriteConfig) UnwrapResponse(ctx context.Context, nr *NormalizedResponse) error {
+	if m == nil || m.ResultField == "" || nr == nil {
+		return nil
+	}
+	jrr, err := nr.JsonRpcResponse(ctx)
+	if err != nil || jrr == nil {
+		return err
+	}
+	unwrapped, err := m.UnwrapResult(ctx, jrr)
+	if err != nil {
+		return err
+	}
+	if unwrapped != jrr {
+		nr.WithJsonRpcResponse(unwrapped)
+	}
+	return nil
+}
+
+// ApplyMethodRewrite rewrites this request in place (see MethodRewriteConfig.ApplyTo)
+// and resets the cached method name so downstream components see the alias.
+func (r *NormalizedRequest) ApplyMethodRewrite(ctx context.Context, rule *MethodRewriteConfig) (bool, error) {
+	if r == nil || rule == nil {
+		return false, nil
+	}
+	jrq, err := r.JsonRpcRequest(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !rule.ApplyTo(jrq) {
+		return false, nil
+	}
+	r.Lock()
+	r.method = ""
+	r.Unlock()
+	return true, nil
+}
+
+// WithMethodRewrite returns a derived request carrying a rewritten clone of
+// this request's JSON-RPC payload, or the request itself when the rule does
+// not change anything. The derived request shares network, directives and
+// forwarded headers with the original so transports behave identically.
+func (r *NormalizedRequest) WithMethodRewrite(ctx context.Context, rule *MethodRewriteConfig) (*NormalizedRequest, error) {
+	if r == nil || rule == nil {
+		return r, nil
+	}
+	jrq, err := r.JsonRpcRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cloned := jrq.Clone()
+	if !rule.ApplyTo(cloned) {
+		return r, nil
+	}
+
+	derived := NewNormalizedRequestFromJsonRpcRequest(cloned)
+	derived.network = r.Network()
+	derived.directives = r.Directives()
+	derived.ForwardHeaders = r.ForwardHeaders
+	derived.CopyHttpContextFrom(r)
+	derived.SetParentRequestId(r.ID())
+	return derived, nil
+}
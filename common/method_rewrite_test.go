@@ -0,0 +1,91 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindMethodRewrite(t *testing.T) {
+	rules := []*MethodRewriteConfig{
+		{Method: "eth_getBlockReceipts", Alias: "alchemy_getTransactionReceipts"},
+		{Method: "eth_*", Params: []*ParamRewriteConfig{{Index: 1, From: "latest", To: "safe"}}},
+	}
+
+	assert.Equal(t, rules[0], FindMethodRewrite(rules, "eth_getBlockReceipts"))
+	assert.Equal(t, rules[1], FindMethodRewrite(rules, "eth_call"))
+	assert.Nil(t, FindMethodRewrite(rules, "debug_traceTransaction"))
+	assert.Nil(t, FindMethodRewrite(nil, "eth_call"))
+}
+
+func TestMethodRewriteApplyTo(t *testing.T) {
+	t.Run("ReplacesMatchingBlockTagOnly", func(t *testing.T) {
+		rule := &MethodRewriteConfig{
+			Method: "eth_call",
+			Params: []*ParamRewriteConfig{{Index: 1, From: "latest", To: "safe"}},
+		}
+		jrq := NewJsonRpcRequest("eth_call", []interface{}{map[string]interface{}{"to": "0x1"}, "latest"})
+		assert.True(t, rule.ApplyTo(jrq))
+		assert.Equal(t, "safe", jrq.Params[1])
+
+		jrq = NewJsonRpcRequest("eth_call", []interface{}{map[string]interface{}{"to": "0x1"}, "0x10"})
+		assert.False(t, rule.ApplyTo(jrq))
+		assert.Equal(t, "0x10", jrq.Params[1])
+	})
+
+	t.Run("AliasesAndWrapsParam", func(t *testing.T) {
+		rule := &MethodRewriteConfig{
+			Method: "eth_getBlockReceipts",
+			Alias:  "alchemy_getTransactionReceipts",
+			Params: []*ParamRewriteConfig{{Index: 0, WrapField: "blockNumber"}},
+		}
+		jrq := NewJsonRpcRequest("eth_getBlockReceipts", []interface{}{"0x10"})
+		before, err := jrq.CacheHash()
+		require.NoError(t, err)
+
+		assert.True(t, rule.ApplyTo(jrq))
+		assert.Equal(t, "alchemy_getTransactionReceipts", jrq.Method)
+		assert.Equal(t, map[string]interface{}{"blockNumber": "0x10"}, jrq.Params[0])
+
+		after, err := jrq.CacheHash()
+		require.NoError(t, err)
+		assert.NotEqual(t, before, after, "cache hash must be recomputed after rewrite")
+	})
+
+	t.Run("AppendsMissingParam", func(t *testing.T) {
+		rule := &MethodRewriteConfig{
+			Method: "eth_getBalance",
+			Params: []*ParamRewriteConfig{{Index: 1, To: "finalized"}},
+		}
+		jrq := NewJsonRpcRequest("eth_getBalance", []interface{}{"0xabc"})
+		assert.True(t, rule.ApplyTo(jrq))
+		assert.Equal(t, []interface{}{"0xabc", "finalized"}, jrq.Params)
+	})
+}
+
+func TestNormalizedRequestWithMethodRewrite(t *testing.T) {
+	rule := &MethodRewriteConfig{Method: "eth_getBlockReceipts", Alias: "alchemy_getTransactionReceipts"}
+	nq := NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":7,"method":"eth_getBlockReceipts","params":["0x10"]}`))
+
+	derived, err := nq.WithMethodRewrite(context.Background(), rule)
+	require.NoError(t, err)
+	require.NotSame(t, nq, derived)
+
+	m, _ := derived.Method()
+	assert.Equal(t, "alchemy_getTransactionReceipts", m)
+	om, _ := nq.Method()
+	assert.Equal(t, "eth_getBlockReceipts", om, "original request must not be mutated")
+}
+
+func TestMethodRewriteUnwrapResult(t *testing.T) {
+	rule := &MethodRewriteConfig{Method: "eth_getBlockReceipts", ResultField: "receipts"}
+	jrr, err := NewJsonRpcResponseFromBytes([]byte(`7`), []byte(`{"receipts":[{"status":"0x1"}]}`), nil)
+	require.NoError(t, err)
+
+	out, err := rule.UnwrapResult(context.Background(), jrr)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"status":"0x1"}]`, out.GetResultString())
+	assert.EqualValues(t, 7, out.ID())
+}
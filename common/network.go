@@ -32,6 +32,25 @@ type Network interface {
 	EvmLeaderUpstream(ctx context.Context) Upstream
 }
 
+// EvmChainState is a network's head view as maintained by its upstreams'
+// state pollers. Zero values mean unknown.
+type EvmChainState struct {
+	LatestBlock    int64 `json:"latestBlock"`
+	FinalizedBlock int64 `json:"finalizedBlock"`
+	// LatestBlockTimestamp is the timestamp (unix seconds) of
+	// LatestBlockTimestampBlock, the highest block whose timestamp is known.
+	LatestBlockTimestamp      int64         `json:"latestBlockTimestamp"`
+	LatestBlockTimestampBlock int64         `json:"latestBlockTimestampBlock"`
+	BlockTime                 time.Duration `json:"blockTime"`
+}
+
+// EvmChainStateProvider is implemented by networks that track their chain
+// head, so the cache reuses the head the state pollers already maintain
+// instead of polling eth_blockNumber on its own.
+type EvmChainStateProvider interface {
+	EvmChainState(ctx context.Context) EvmChainState
+}
+
 func IsValidArchitecture(architecture string) bool {
 	return architecture == string(ArchitectureEvm) // TODO add more architectures when they are supported
 }
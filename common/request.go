@@ -2,6 +2,8 @@ package common
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -72,6 +74,64 @@ func IsSweepIteration(ctx context.Context) bool {
 	return v
 }
 
+// CorrelationIdContextKey carries the correlation id of the client request
+// being served (server.correlationId).
+const CorrelationIdContextKey ContextKey = "correlationId"
+
+// correlationIdMaxLength bounds correlation ids accepted from clients.
+const correlationIdMaxLength = 128
+
+type correlationId struct {
+	id     string
+	header string
+}
+
+// WithCorrelationId returns ctx carrying id. When upstreamHeader is set, HTTP
+// upstream clients send id in that header with the requests they make for
+// ctx.
+func WithCorrelationId(ctx context.Context, id string, upstreamHeader string) context.Context {
+	return context.WithValue(ctx, CorrelationIdContextKey, correlationId{id: id, header: upstreamHeader})
+}
+
+// CorrelationIdFromContext returns the correlation id carried by ctx, or ""
+// when there is none.
+func CorrelationIdFromContext(ctx context.Context) string {
+	v, _ := ctx.Value(CorrelationIdContextKey).(correlationId)
+	return v.id
+}
+
+// ForwardedCorrelationId returns the header and correlation id to send to
+// upstreams for ctx, or empty strings when there is none to send.
+func ForwardedCorrelationId(ctx context.Context) (header string, id string) {
+	v, _ := ctx.Value(CorrelationIdContextKey).(correlationId)
+	if v.header == "" {
+		return "", ""
+	}
+	return v.header, v.id
+}
+
+// IsValidCorrelationId reports whether id is safe to accept from a client:
+// non-empty, at most 128 characters, and printable ASCII without spaces, so
+// it can be echoed in headers and logs as-is.
+func IsValidCorrelationId(id string) bool {
+	if id == "" || len(id) > correlationIdMaxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewCorrelationId returns a random 32-character hex correlation id.
+func NewCorrelationId() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
 type directiveKeyNames struct {
 	header string
 	query  string
@@ -101,6 +161,7 @@ const (
 	headerDirectiveValidateTxFields           = "X-ERPC-Validate-Transaction-Fields"
 	headerDirectiveValidateTxBlockInfo        = "X-ERPC-Validate-Transaction-Block-Info"
 	headerDirectiveValidateLogFields          = "X-ERPC-Validate-Log-Fields"
+	headerDirectiveFields                     = "X-ERPC-Fields"
 )
 
 // HeaderUserId is the request header erpc reads as the caller's user identity
@@ -135,6 +196,7 @@ const (
 	queryDirectiveValidateTxFields           = "validate-transaction-fields"
 	queryDirectiveValidateTxBlockInfo        = "validate-transaction-block-info"
 	queryDirectiveValidateLogFields          = "validate-log-fields"
+	queryDirectiveFields                     = "fields"
 )
 
 var directiveKeyRegistry = []directiveKeyNames{
@@ -161,6 +223,7 @@ var directiveKeyRegistry = []directiveKeyNames{
 	{header: headerDirectiveValidateTxFields, query: queryDirectiveValidateTxFields},
 	{header: headerDirectiveValidateTxBlockInfo, query: queryDirectiveValidateTxBlockInfo},
 	{header: headerDirectiveValidateLogFields, query: queryDirectiveValidateLogFields},
+	{header: headerDirectiveFields, query: queryDirectiveFields},
 }
 
 var DenyAllClientDirectives MatcherFunc = func(_ string) bool { return false }
@@ -222,6 +285,12 @@ type RequestDirectives struct {
 	// latency over multi-upstream agreement.
 	SkipConsensus bool `json:"skipConsensus"`
 
+	// Fields trims the result returned to the client down to these fields,
+	// comma-separated, with dots selecting nested fields (e.g.
+	// "number,hash,transactions.hash"). It is applied after the cache and
+	// upstreams, so cached entries always hold the full result.
+	Fields string `json:"fields,omitempty"`
+
 	// Validation: Block Integrity
 	EnforceHighestBlock        bool `json:"enforceHighestBlock,omitempty"`
 	EnforceGetLogsBlockRange   bool `json:"enforceGetLogsBlockRange,omitempty"`
@@ -308,6 +377,7 @@ func (d *RequestDirectives) Clone() *RequestDirectives {
 		ByPassMethodExclusion:           d.ByPassMethodExclusion,
 		SkipInterpolation:               d.SkipInterpolation,
 		SkipConsensus:                   d.SkipConsensus,
+		Fields:                          d.Fields,
 		EnforceHighestBlock:             d.EnforceHighestBlock,
 		EnforceGetLogsBlockRange:        d.EnforceGetLogsBlockRange,
 		EnforceNonNullTaggedBlocks:      d.EnforceNonNullTaggedBlocks,
@@ -854,6 +924,9 @@ func (r *NormalizedRequest) EnrichFromHttp(headers http.Header, queryArgs url.Va
 	if hv := getHeader(headerDirectiveSkipConsensus); hv != "" {
 		r.directives.SkipConsensus = strings.ToLower(strings.TrimSpace(hv)) == "true"
 	}
+	if hv := getHeader(headerDirectiveFields); hv != "" {
+		r.directives.Fields = strings.TrimSpace(hv)
+	}
 
 	// Validation Headers
 	if hv := getHeader(headerDirectiveEnforceHighestBlock); hv != "" {
@@ -948,6 +1021,10 @@ func (r *NormalizedRequest) EnrichFromHttp(headers http.Header, queryArgs url.Va
 		r.directives.SkipConsensus = strings.ToLower(strings.TrimSpace(skipConsensus)) == "true"
 	}
 
+	if fields := getQueryArg(queryDirectiveFields); fields != "" {
+		r.directives.Fields = strings.TrimSpace(fields)
+	}
+
 	// Validation query parameters
 	if v := getQueryArg(queryDirectiveEnforceHighestBlock); v != "" {
 		r.directives.EnforceHighestBlock = strings.ToLower(strings.TrimSpace(v)) == "true"
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"context"
+	"strings"
+)
+
+// resultFieldTree is a parsed fields directive: each key is kept, and a
+// non-empty subtree keeps only those fields of the key's value.
+type resultFieldTree map[string]resultFieldTree
+
+// parseResultFields parses a comma-separated list of fields where dots select
+// nested fields, e.g. "number,hash,transactions.hash". A field listed both
+// whole and with nested fields is kept whole.
+func parseResultFields(fields string) resultFieldTree {
+	tree := resultFieldTree{}
+	for _, f := range strings.Split(fields, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		node := tree
+		parts := strings.Split(f, ".")
+		for i, p := range parts {
+			child, seen := node[p]
+			if seen && len(child) == 0 {
+				// Already kept whole.
+				break
+			}
+			if i == len(parts)-1 {
+				node[p] = resultFieldTree{}
+				break
+			}
+			if child == nil {
+				child = resultFieldTree{}
+				node[p] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// project keeps the fields of the tree in v. Arrays are projected element by
+// element; scalars are returned as-is.
+func (t resultFieldTree) project(v interface{}) interface{} {
+	if len(t) == 0 {
+		return v
+	}
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, sub := range t {
+			if fv, ok := tv[k]; ok {
+				out[k] = sub.project(fv)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(tv))
+		for i, e := range tv {
+			out[i] = t.project(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// ProjectResult returns a response whose result only has the given fields
+// (see the fields directive). Responses without a result (errors, nulls) and
+// an empty fields list are returned as-is.
+func ProjectResult(ctx context.Context, jrr *JsonRpcResponse, fields string) (*JsonRpcResponse, error) {
+	if fields == "" || jrr == nil || jrr.Error != nil {
+		return jrr, nil
+	}
+	tree := parseResultFields(fields)
+	if len(tree) == 0 || jrr.IsResultEmptyish(ctx) {
+		return jrr, nil
+	}
+	var result interface{}
+	if err := SonicCfg.Unmarshal(jrr.GetResultBytes(), &result); err != nil {
+		return nil, err
+	}
+	raw, err := SonicCfg.Marshal(tree.project(result))
+	if err != nil {
+		return nil, err
+	}
+	out, err := NewJsonRpcResponseFromBytes(nil, raw, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := out.SetID(jrr.ID()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProjectResponse applies ProjectResult to the JSON-RPC payload of nr in place.
+func ProjectResponse(ctx context.Context, nr *NormalizedResponse, fields string) error {
+	if fields == "" || nr == nil {
+		return nil
+	}
+	jrr, err := nr.JsonRpcResponse(ctx)
+	if err != nil || jrr == nil {
+		return err
+	}
+	projected, err := ProjectResult(ctx, jrr, fields)
+	if err != nil {
+		return err
+	}
+	if projected != jrr {
+		nr.WithJsonRpcResponse(projected)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectResult(t *testing.T) {
+	ctx := context.Background()
+	block := `{"number":"0x1","hash":"0xaa","timestamp":"0x5","miner":"0xbb","transactions":[{"hash":"0x01","from":"0xcc"},{"hash":"0x02","from":"0xdd"}]}`
+
+	cases := []struct {
+		name   string
+		result string
+		fields string
+		want   string
+	}{
+		{"TopLevel", block, "number,hash,timestamp", `{"number":"0x1","hash":"0xaa","timestamp":"0x5"}`},
+		{"Nested", block, "number, transactions.hash", `{"number":"0x1","transactions":[{"hash":"0x01"},{"hash":"0x02"}]}`},
+		{"WholeWinsOverNested", block, "transactions.hash,transactions", `{"transactions":[{"hash":"0x01","from":"0xcc"},{"hash":"0x02","from":"0xdd"}]}`},
+		{"UnknownFieldsAreDropped", block, "number,nope", `{"number":"0x1"}`},
+		{"ArrayOfObjects", `[{"address":"0x1","data":"0x"},{"address":"0x2","data":"0x"}]`, "address", `[{"address":"0x1"},{"address":"0x2"}]`},
+		{"Scalar", `"0x10"`, "number", `"0x10"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			jrr, err := NewJsonRpcResponseFromBytes([]byte(`7`), []byte(tc.result), nil)
+			require.NoError(t, err)
+			out, err := ProjectResult(ctx, jrr, tc.fields)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.want, out.GetResultString())
+			assert.EqualValues(t, 7, out.ID())
+		})
+	}
+
+	t.Run("NullAndErrorsAreUntouched", func(t *testing.T) {
+		jrr, err := NewJsonRpcResponseFromBytes([]byte(`1`), []byte(`null`), nil)
+		require.NoError(t, err)
+		out, err := ProjectResult(ctx, jrr, "number")
+		require.NoError(t, err)
+		assert.Same(t, jrr, out)
+
+		jrr, err = NewJsonRpcResponse(1, nil, NewErrJsonRpcExceptionExternal(-32000, "boom", ""))
+		require.NoError(t, err)
+		out, err = ProjectResult(ctx, jrr, "number")
+		require.NoError(t, err)
+		assert.Same(t, jrr, out)
+	})
+}
+
+func TestFieldsDirective(t *testing.T) {
+	req := NewNormalizedRequest(nil)
+	h := http.Header{}
+	h.Set("X-ERPC-Fields", " number,hash ")
+	req.EnrichFromHttp(h, nil, UserAgentTrackingModeSimplified)
+	require.NotNil(t, req.Directives())
+	assert.Equal(t, "number,hash", req.Directives().Fields)
+
+	// Query parameters win over headers.
+	req = NewNormalizedRequest(nil)
+	req.EnrichFromHttp(h, url.Values{"fields": []string{"timestamp"}}, UserAgentTrackingModeSimplified)
+	assert.Equal(t, "timestamp", req.Directives().Fields)
+	assert.Equal(t, "timestamp", req.Directives().Clone().Fields)
+}
@@ -116,7 +116,7 @@ func InitializeTracing(ctx context.Context, logger *zerolog.Logger, cfg *Tracing
 			propagation.Baggage{},
 		))
 
-		if logger.GetLevel() <= zerolog.DebugLevel {
+		if LogLevelEnabled(logger, zerolog.DebugLevel) {
 			otel.SetLogger(zerologr.New(logger))
 			logger.Info().Msg("OpenTelemetry debug logging enabled")
 		}
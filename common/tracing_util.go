@@ -168,6 +168,9 @@ func StartRequestSpan(ctx context.Context, req *NormalizedRequest) context.Conte
 	if forceTrace {
 		span.SetAttributes(attribute.String("erpc.forced_trace_reason", forceTraceReason))
 	}
+	if id := CorrelationIdFromContext(ctx); id != "" {
+		span.SetAttributes(attribute.String("request.correlation_id", id))
+	}
 
 	if IsTracingDetailed {
 		span.SetAttributes(
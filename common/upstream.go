@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"time"
 
 	"github.com/rs/zerolog"
 )
@@ -22,6 +23,60 @@ const (
 
 type UpstreamType string
 
+// UpstreamDrainMode labels why an upstream stopped taking new requests. All
+// modes route identically; the mode only tells operators and dashboards
+// whether the stop was an ad-hoc drain, planned maintenance or a retirement.
+type UpstreamDrainMode string
+
+const (
+	UpstreamDrainModeDraining    UpstreamDrainMode = "draining"
+	UpstreamDrainModeMaintenance UpstreamDrainMode = "maintenance"
+	// UpstreamDrainModeRetired is set by automatic retirement (Source
+	// "retirement") and is lifted only via erpc_unretireUpstream.
+	UpstreamDrainModeRetired UpstreamDrainMode = "retired"
+)
+
+// UpstreamDrainState describes an active drain, either set via the admin API
+// (Source "admin"), derived from a configured maintenance window (Source
+// "window") or set by automatic retirement (Source "retirement").
+type UpstreamDrainState struct {
+	Mode   UpstreamDrainMode `json:"mode"`
+	Reason string            `json:"reason,omitempty"`
+	Source string            `json:"source"`
+	Since  time.Time         `json:"since"`
+	// Until is zero for drains that last until explicitly lifted.
+	Until time.Time `json:"until,omitempty"`
+}
+
+// UpstreamRetirementState is the failure history automatic retirement
+// decides on. Retired is set once the upstream has been retired.
+type UpstreamRetirementState struct {
+	Retired       *UpstreamDrainState `json:"retired,omitempty"`
+	LastSuccessAt time.Time           `json:"lastSuccessAt,omitempty"`
+	// FailingSince is the first failure after the last success; zero while
+	// the upstream is healthy.
+	FailingSince time.Time `json:"failingSince,omitempty"`
+	Failures     int64     `json:"failures"`
+}
+
+type UpstreamCanaryStatus string
+
+const (
+	UpstreamCanaryStatusProgressing UpstreamCanaryStatus = "progressing"
+	UpstreamCanaryStatusCompleted   UpstreamCanaryStatus = "completed"
+	UpstreamCanaryStatusRolledBack  UpstreamCanaryStatus = "rolledBack"
+)
+
+// UpstreamCanaryState is a snapshot of a canary rollout. Step indexes the
+// configured weights; Since is when the current step or status began.
+type UpstreamCanaryState struct {
+	Status UpstreamCanaryStatus `json:"status"`
+	Step   int                  `json:"step"`
+	Weight float64              `json:"weight"`
+	Since  time.Time            `json:"since"`
+	Reason string               `json:"reason,omitempty"`
+}
+
 // HealthTracker is an interface for tracking upstream health metrics.
 //
 // `finality` is the DataFinalityState the request resolved to —
@@ -123,7 +123,10 @@ func (u *FakeUpstream) Network() Network {
 }
 
 func (u *FakeUpstream) EvmSyncingState() EvmSyncingState {
-	return EvmSyncingStateUnknown
+	if u.evmStatePoller == nil {
+		return EvmSyncingStateUnknown
+	}
+	return u.evmStatePoller.SyncingState()
 }
 
 func (u *FakeUpstream) Vendor() Vendor {
@@ -220,6 +223,7 @@ func (u *FakeUpstream) EvmBlockAvailabilityBounds() (int64, int64) {
 type FakeEvmStatePoller struct {
 	latestBlockNumber    int64
 	finalizedBlockNumber int64
+	syncingState         EvmSyncingState
 }
 
 func NewFakeEvmStatePoller(latestBlockNumber int64, finalizedBlockNumber int64) EvmStatePoller {
@@ -278,7 +282,7 @@ func (p *FakeEvmStatePoller) SetNetworkConfig(config *NetworkConfig) {
 }
 
 func (p *FakeEvmStatePoller) SetSyncingState(state EvmSyncingState) {
-	// No-op for testing
+	p.syncingState = state
 }
 
 func (p *FakeEvmStatePoller) SuggestFinalizedBlock(blockNumber int64) {
@@ -290,7 +294,7 @@ func (p *FakeEvmStatePoller) SuggestLatestBlock(blockNumber int64) {
 }
 
 func (p *FakeEvmStatePoller) SyncingState() EvmSyncingState {
-	return EvmSyncingStateUnknown
+	return p.syncingState
 }
 
 func (p *FakeEvmStatePoller) GetDiagnostics() *EvmStatePollerDiagnostics {
@@ -298,7 +302,7 @@ func (p *FakeEvmStatePoller) GetDiagnostics() *EvmStatePollerDiagnostics {
 		Enabled:        true,
 		LatestBlock:    p.latestBlockNumber,
 		FinalizedBlock: p.finalizedBlockNumber,
-		SyncingState:   EvmSyncingStateUnknown.String(),
+		SyncingState:   p.syncingState.String(),
 	}
 }
 
@@ -1,9 +1,11 @@
 package common
 
 import (
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/url"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
@@ -38,11 +40,34 @@ func (c *Config) Validate() error {
 			return err
 		}
 	}
+	if c.Management != nil {
+		if err := c.Management.Validate(c); err != nil {
+			return err
+		}
+	}
+	if c.Scheduler != nil {
+		if err := c.Scheduler.Validate(c); err != nil {
+			return err
+		}
+	}
+	if c.Alerting != nil {
+		if err := c.Alerting.Validate(); err != nil {
+			return err
+		}
+	}
 	if c.Database != nil {
 		if err := c.Database.Validate(); err != nil {
 			return err
 		}
 	}
+	for i, q := range c.ClientQuirks {
+		if q == nil || strings.TrimSpace(q.Client) == "" {
+			return fmt.Errorf("clientQuirks[%d].client is required", i)
+		}
+		if q.MaxBatchSize < 0 {
+			return fmt.Errorf("clientQuirks[%d].maxBatchSize must be >= 0", i)
+		}
+	}
 	if c.Projects != nil {
 		for _, project := range c.Projects {
 			if err := project.Validate(c); err != nil {
@@ -91,6 +116,14 @@ func (s *ServerConfig) Validate() error {
 	if s.MaxTimeout == nil || *s.MaxTimeout == 0 {
 		return fmt.Errorf("server.maxTimeout is required")
 	}
+	if s.UnixSocket != nil {
+		if s.UnixSocket.Path == "" {
+			return fmt.Errorf("server.unixSocket.path is required")
+		}
+		if mode, err := strconv.ParseUint(s.UnixSocket.Mode, 8, 32); err != nil || mode > 0o777 {
+			return fmt.Errorf("server.unixSocket.mode %q must be an octal permission such as 0660", s.UnixSocket.Mode)
+		}
+	}
 
 	// Validate trusted IP forwarders if provided (IPs or CIDRs). Support legacy + new field
 	for _, entry := range s.TrustedIPForwarders {
@@ -108,7 +141,66 @@ func (s *ServerConfig) Validate() error {
 			}
 		}
 	}
+	if s.ProxyProtocol != nil {
+		for _, entry := range s.ProxyProtocol.TrustedSources {
+			val := strings.TrimSpace(entry)
+			if strings.Contains(val, "/") {
+				if _, _, err := net.ParseCIDR(val); err != nil {
+					return fmt.Errorf("server.proxyProtocol.trustedSources entry '%s' is not a valid CIDR: %v", val, err)
+				}
+			} else if ip := net.ParseIP(val); ip == nil {
+				return fmt.Errorf("server.proxyProtocol.trustedSources entry '%s' is not a valid IP address", val)
+			}
+		}
+		if s.ProxyProtocol.HeaderTimeout != nil && *s.ProxyProtocol.HeaderTimeout <= 0 {
+			return fmt.Errorf("server.proxyProtocol.headerTimeout must be positive")
+		}
+	}
 	// No validation for trusted IP headers; treat as raw header names with XFF-like syntax
+	if s.ResponseCompression != nil {
+		if err := s.ResponseCompression.Validate("server.responseCompression"); err != nil {
+			return err
+		}
+	}
+	if s.CorrelationId != nil && strings.ContainsAny(s.CorrelationId.Header, " :\t\r\n") {
+		return fmt.Errorf("server.correlationId.header '%s' is not a valid header name", s.CorrelationId.Header)
+	}
+	if s.Attestation != nil && (s.Attestation.Enabled == nil || *s.Attestation.Enabled) {
+		if _, err := ParseAttestationKey(s.Attestation.PrivateKey); err != nil {
+			return fmt.Errorf("server.attestation.privateKey: %w", err)
+		}
+		if strings.ContainsAny(s.Attestation.Header, " :\t\r\n") {
+			return fmt.Errorf("server.attestation.header '%s' is not a valid header name", s.Attestation.Header)
+		}
+		if strings.ContainsAny(s.Attestation.KeyId, ";= \t\r\n") {
+			return fmt.Errorf("server.attestation.keyId '%s' must not contain ';', '=' or whitespace", s.Attestation.KeyId)
+		}
+	}
+	if s.RequestValidation != nil {
+		if s.RequestValidation.MaxMethodLength < 0 {
+			return fmt.Errorf("server.requestValidation.maxMethodLength must be greater than or equal to 0")
+		}
+		if s.RequestValidation.MaxIdLength < 0 {
+			return fmt.Errorf("server.requestValidation.maxIdLength must be greater than or equal to 0")
+		}
+		if s.RequestValidation.MaxParams < 0 {
+			return fmt.Errorf("server.requestValidation.maxParams must be greater than or equal to 0")
+		}
+	}
+	return nil
+}
+
+func (c *ResponseCompressionConfig) Validate(path string) error {
+	for _, enc := range c.Encodings {
+		switch enc {
+		case ContentEncodingBrotli, ContentEncodingZstd, ContentEncodingGzip:
+		default:
+			return fmt.Errorf("%s.encodings contains '%s', must be one of: br, zstd, gzip", path, enc)
+		}
+	}
+	if c.Threshold < 0 {
+		return fmt.Errorf("%s.threshold must be greater than or equal to 0", path)
+	}
 	return nil
 }
 
@@ -121,6 +213,33 @@ func (h *HealthCheckConfig) Validate() error {
 	return nil
 }
 
+func (m *ManagementConfig) Validate(c *Config) error {
+	if m.Port == nil || *m.Port <= 0 || *m.Port > 65535 {
+		return fmt.Errorf("management.port must be between 1 and 65535")
+	}
+	if m.TLS != nil && m.TLS.Enabled && (m.TLS.CertFile == "" || m.TLS.KeyFile == "") {
+		return fmt.Errorf("management.tls.certFile and management.tls.keyFile are required when management.tls.enabled is true")
+	}
+	if m.Auth != nil {
+		if err := m.Auth.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Server != nil {
+		for _, p := range []*int{c.Server.HttpPortV4, c.Server.HttpPortV6} {
+			if p != nil && *p == *m.Port {
+				return fmt.Errorf("management.port %d must differ from the public server ports", *m.Port)
+			}
+		}
+	}
+	if c.Metrics != nil && c.Metrics.Enabled != nil && *c.Metrics.Enabled && c.Metrics.Port != nil && *c.Metrics.Port == *m.Port {
+		if m.Exclusive == nil || !*m.Exclusive || m.Metrics == nil || !*m.Metrics {
+			return fmt.Errorf("management.port %d is also used by metrics.port", *m.Port)
+		}
+	}
+	return nil
+}
+
 func (a *AdminConfig) Validate() error {
 	if a.Auth != nil {
 		if err := a.Auth.Validate(); err != nil {
@@ -135,6 +254,217 @@ func (a *AdminConfig) Validate() error {
 	return nil
 }
 
+func (a *AlertingConfig) Validate() error {
+	if a.EvaluationInterval < Duration(time.Second) {
+		return fmt.Errorf("alerting.evaluationInterval must be at least 1s")
+	}
+	if a.RepeatInterval < 0 {
+		return fmt.Errorf("alerting.repeatInterval must be >= 0")
+	}
+	if len(a.Rules) > 0 && len(a.Sinks) == 0 {
+		return fmt.Errorf("alerting.sinks must not be empty when rules are defined")
+	}
+	sinks := make(map[string]bool, len(a.Sinks))
+	for i, sink := range a.Sinks {
+		if sink == nil {
+			return fmt.Errorf("alerting.sinks[%d] is nil", i)
+		}
+		if sink.Id == "" {
+			return fmt.Errorf("alerting.sinks[%d].id is required", i)
+		}
+		if sinks[sink.Id] {
+			return fmt.Errorf("alerting.sinks[%d].id '%s' is duplicated", i, sink.Id)
+		}
+		sinks[sink.Id] = true
+		switch sink.Type {
+		case AlertSinkTypeWebhook, AlertSinkTypeSlack:
+			if sink.Url == "" {
+				return fmt.Errorf("alerting.sinks[%d].url is required for %s sinks", i, sink.Type)
+			}
+		case AlertSinkTypePagerDuty:
+			if sink.RoutingKey == "" {
+				return fmt.Errorf("alerting.sinks[%d].routingKey is required for pagerduty sinks", i)
+			}
+		default:
+			return fmt.Errorf("alerting.sinks[%d].type must be 'webhook', 'slack' or 'pagerduty'", i)
+		}
+		if u, err := url.Parse(sink.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("alerting.sinks[%d].url must be an http(s) URL", i)
+		}
+	}
+	seen := make(map[string]bool, len(a.Rules))
+	for i, rule := range a.Rules {
+		if rule == nil {
+			return fmt.Errorf("alerting.rules[%d] is nil", i)
+		}
+		if rule.Id == "" {
+			return fmt.Errorf("alerting.rules[%d].id is required", i)
+		}
+		if seen[rule.Id] {
+			return fmt.Errorf("alerting.rules[%d].id '%s' is duplicated", i, rule.Id)
+		}
+		seen[rule.Id] = true
+		switch rule.Type {
+		case AlertRuleTypeUpstreamDown, AlertRuleTypeConnectorUnhealthy, AlertRuleTypeCacheHitRateBudget:
+		case AlertRuleTypeErrorRate:
+			if rule.Threshold <= 0 || rule.Threshold > 1 {
+				return fmt.Errorf("alerting.rules[%d].threshold must be in (0, 1] for errorRate rules", i)
+			}
+			if rule.MinRequests < 1 {
+				return fmt.Errorf("alerting.rules[%d].minRequests must be at least 1", i)
+			}
+		case AlertRuleTypeSubscriptionLag:
+			if rule.Threshold <= 0 {
+				return fmt.Errorf("alerting.rules[%d].threshold must be > 0 for subscriptionLag rules", i)
+			}
+		case AlertRuleTypeMetric:
+			if rule.Metric == "" {
+				return fmt.Errorf("alerting.rules[%d].metric is required for metric rules", i)
+			}
+		default:
+			return fmt.Errorf("alerting.rules[%d].type must be one of 'upstreamDown', 'connectorUnhealthy', 'errorRate', 'subscriptionLag', 'cacheHitRateBudget' or 'metric'", i)
+		}
+		switch rule.Severity {
+		case AlertSeverityInfo, AlertSeverityWarning, AlertSeverityCritical:
+		default:
+			return fmt.Errorf("alerting.rules[%d].severity must be 'info', 'warning' or 'critical'", i)
+		}
+		if rule.For < 0 {
+			return fmt.Errorf("alerting.rules[%d].for must be >= 0", i)
+		}
+		for label, pattern := range rule.Labels {
+			if _, err := NewWildcardMatcher(pattern); err != nil {
+				return fmt.Errorf("alerting.rules[%d].labels.%s is invalid: %w", i, label, err)
+			}
+		}
+		for _, id := range rule.Sinks {
+			if !sinks[id] {
+				return fmt.Errorf("alerting.rules[%d].sinks references unknown sink '%s'", i, id)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SchedulerConfig) Validate(c *Config) error {
+	hasProject := func(id string) bool {
+		for _, p := range c.Projects {
+			if p != nil && p.Id == id {
+				return true
+			}
+		}
+		return false
+	}
+	seen := make(map[string]bool, len(s.Jobs))
+	for i, job := range s.Jobs {
+		if job == nil {
+			return fmt.Errorf("scheduler.jobs[%d] is nil", i)
+		}
+		if job.Id == "" {
+			return fmt.Errorf("scheduler.jobs[%d].id is required", i)
+		}
+		if seen[job.Id] {
+			return fmt.Errorf("scheduler.jobs[%d].id '%s' is duplicated", i, job.Id)
+		}
+		seen[job.Id] = true
+		if _, err := util.ParseCronSchedule(job.Schedule); err != nil {
+			return fmt.Errorf("scheduler.jobs[%d].schedule is invalid: %w", i, err)
+		}
+		if job.Timeout < 0 {
+			return fmt.Errorf("scheduler.jobs[%d].timeout must be >= 0", i)
+		}
+		switch job.Type {
+		case ScheduledJobTypeBackfill:
+			b := job.Backfill
+			if b == nil {
+				return fmt.Errorf("scheduler.jobs[%d].backfill is required when type is backfill", i)
+			}
+			if b.ProjectId == "" || b.NetworkId == "" {
+				return fmt.Errorf("scheduler.jobs[%d].backfill.projectId and networkId are required", i)
+			}
+			if !hasProject(b.ProjectId) {
+				return fmt.Errorf("scheduler.jobs[%d].backfill.projectId '%s' does not exist", i, b.ProjectId)
+			}
+			if b.LastBlocks <= 0 {
+				return fmt.Errorf("scheduler.jobs[%d].backfill.lastBlocks must be > 0", i)
+			}
+			if b.RateLimit < 0 || b.Concurrency < 0 {
+				return fmt.Errorf("scheduler.jobs[%d].backfill.rateLimit and concurrency must be >= 0", i)
+			}
+		case ScheduledJobTypeWarm:
+			w := job.Warm
+			if w == nil {
+				return fmt.Errorf("scheduler.jobs[%d].warm is required when type is warm", i)
+			}
+			if w.ProjectId == "" || w.NetworkId == "" {
+				return fmt.Errorf("scheduler.jobs[%d].warm.projectId and networkId are required", i)
+			}
+			if !hasProject(w.ProjectId) {
+				return fmt.Errorf("scheduler.jobs[%d].warm.projectId '%s' does not exist", i, w.ProjectId)
+			}
+			if len(w.Requests) == 0 {
+				return fmt.Errorf("scheduler.jobs[%d].warm.requests must not be empty", i)
+			}
+			for j, r := range w.Requests {
+				if r == nil || r.Method == "" {
+					return fmt.Errorf("scheduler.jobs[%d].warm.requests[%d].method is required", i, j)
+				}
+			}
+		case ScheduledJobTypeCacheSweep:
+			cs := job.CacheSweep
+			if cs == nil {
+				return fmt.Errorf("scheduler.jobs[%d].cacheSweep is required when type is cacheSweep", i)
+			}
+			if cs.PartitionKeyPrefix == "" {
+				return fmt.Errorf("scheduler.jobs[%d].cacheSweep.partitionKeyPrefix is required", i)
+			}
+			if c.Database == nil || c.Database.EvmJsonRpcCache == nil {
+				return fmt.Errorf("scheduler.jobs[%d].cacheSweep requires database.evmJsonRpcCache", i)
+			}
+			if cs.ConnectorId != "" {
+				found := false
+				for _, conn := range c.Database.EvmJsonRpcCache.Connectors {
+					if conn != nil && conn.Id == cs.ConnectorId {
+						found = true
+						break
+					}
+				}
+				if !found {
+					return fmt.Errorf("scheduler.jobs[%d].cacheSweep.connectorId '%s' is not a cache connector", i, cs.ConnectorId)
+				}
+			}
+		case ScheduledJobTypeQuotaReset:
+			q := job.QuotaReset
+			if q == nil {
+				return fmt.Errorf("scheduler.jobs[%d].quotaReset is required when type is quotaReset", i)
+			}
+			if !hasProject(q.ProjectId) {
+				return fmt.Errorf("scheduler.jobs[%d].quotaReset.projectId '%s' does not exist", i, q.ProjectId)
+			}
+		case ScheduledJobTypeHealthReport:
+			h := job.HealthReport
+			if h == nil {
+				return fmt.Errorf("scheduler.jobs[%d].healthReport is required when type is healthReport", i)
+			}
+			if h.ProjectId != "" && !hasProject(h.ProjectId) {
+				return fmt.Errorf("scheduler.jobs[%d].healthReport.projectId '%s' does not exist", i, h.ProjectId)
+			}
+			if u, err := url.Parse(h.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("scheduler.jobs[%d].healthReport.url must be an http(s) url", i)
+			}
+		default:
+			return fmt.Errorf("scheduler.jobs[%d].type '%s' is invalid must be one of: %v", i, job.Type, []ScheduledJobType{
+				ScheduledJobTypeBackfill,
+				ScheduledJobTypeWarm,
+				ScheduledJobTypeCacheSweep,
+				ScheduledJobTypeQuotaReset,
+				ScheduledJobTypeHealthReport,
+			})
+		}
+	}
+	return nil
+}
+
 func (m *MetricsConfig) Validate() error {
 	if m.Enabled != nil && *m.Enabled {
 		if m.HostV4 == nil && m.HostV6 == nil {
@@ -178,6 +508,9 @@ func (r *RateLimiterConfig) Validate() error {
 		if err := r.Store.Redis.Validate(); err != nil {
 			return fmt.Errorf("rateLimiters.store.redis is invalid: %w", err)
 		}
+		if r.Store.Redis.IAMAuth != nil && r.Store.Redis.IAMAuth.Enabled && r.Store.Redis.Cluster != nil {
+			return fmt.Errorf("rateLimiters.store.redis.iamAuth cannot be combined with cluster")
+		}
 	case "memory":
 		// No validation for memory store
 	case "":
@@ -225,6 +558,13 @@ func (r *RateLimitRuleConfig) Validate() error {
 	default:
 		return fmt.Errorf("rateLimiter.*.budget.rules.*.period must be one of: second, minute, hour, day, week, month, year")
 	}
+	if r.SmoothingWindow != nil {
+		if *r.SmoothingWindow < RateLimitPeriodSecond || *r.SmoothingWindow >= r.Period {
+			return fmt.Errorf("rateLimiter.*.budget.rules.*.smoothingWindow must be a period shorter than the rule's period '%s'", r.Period)
+		}
+	} else if r.Burst > 0 {
+		return fmt.Errorf("rateLimiter.*.budget.rules.*.burst requires smoothingWindow to be set")
+	}
 	return nil
 }
 
@@ -258,6 +598,94 @@ func (d *DatabaseConfig) Validate() error {
 			return err
 		}
 	}
+	if d.Idempotency != nil {
+		if err := d.Idempotency.Validate(); err != nil {
+			return err
+		}
+	}
+	if d.Journal != nil {
+		if err := d.Journal.Validate(); err != nil {
+			return err
+		}
+	}
+	if d.Analytics != nil {
+		if err := d.Analytics.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *AnalyticsConfig) Validate() error {
+	if c.Connector == nil {
+		return fmt.Errorf("database.analytics.connector is required")
+	}
+	if c.Connector.Driver == DriverGrpc || c.Connector.Driver == DriverMemcached {
+		return fmt.Errorf("database.analytics.connector cannot use the %s driver, rollups are read back with prefix scans", c.Connector.Driver)
+	}
+	if err := c.Connector.Validate(); err != nil {
+		return err
+	}
+	if c.FlushInterval <= 0 {
+		return fmt.Errorf("database.analytics.flushInterval must be greater than 0")
+	}
+	if c.MinuteRetention < Duration(time.Minute) {
+		return fmt.Errorf("database.analytics.minuteRetention must be at least 1m")
+	}
+	if c.HourRetention < Duration(time.Hour) {
+		return fmt.Errorf("database.analytics.hourRetention must be at least 1h")
+	}
+	if c.MaxRowsPerBucket < 0 {
+		return fmt.Errorf("database.analytics.maxRowsPerBucket cannot be negative")
+	}
+	return nil
+}
+
+func (c *JournalConfig) Validate() error {
+	if c.Connector == nil {
+		return fmt.Errorf("database.journal.connector is required")
+	}
+	if c.Connector.Driver == DriverGrpc {
+		return fmt.Errorf("database.journal.connector cannot use the read-only grpc driver")
+	}
+	if err := c.Connector.Validate(); err != nil {
+		return err
+	}
+	for _, m := range c.Methods {
+		if err := ValidatePattern(m); err != nil {
+			return fmt.Errorf("database.journal.methods has invalid pattern %q: %w", m, err)
+		}
+	}
+	if c.Ttl <= 0 {
+		return fmt.Errorf("database.journal.ttl must be greater than 0")
+	}
+	if c.RecoverAfter <= 0 {
+		return fmt.Errorf("database.journal.recoverAfter must be greater than 0")
+	}
+	if c.RecoveryInterval <= 0 {
+		return fmt.Errorf("database.journal.recoveryInterval must be greater than 0")
+	}
+	return nil
+}
+
+func (c *IdempotencyConfig) Validate() error {
+	if c.Connector == nil {
+		return fmt.Errorf("database.idempotency.connector is required")
+	}
+	if err := c.Connector.Validate(); err != nil {
+		return err
+	}
+	if c.Ttl <= 0 {
+		return fmt.Errorf("database.idempotency.ttl must be greater than 0")
+	}
+	if c.LockTtl <= 0 {
+		return fmt.Errorf("database.idempotency.lockTtl must be greater than 0")
+	}
+	for _, m := range c.Methods {
+		if err := ValidatePattern(m); err != nil {
+			return fmt.Errorf("database.idempotency.methods has invalid pattern %q: %w", m, err)
+		}
+	}
 	return nil
 }
 
@@ -306,6 +734,46 @@ func (s *SharedStateConfig) Validate() error {
 			updateMaxWait, fallbackTimeout)
 	}
 
+	if s.Routing != nil {
+		if s.Connector.Driver == DriverMemory {
+			return fmt.Errorf("sharedState.routing requires a connector shared by all replicas, the memory driver is local to one")
+		}
+		if err := s.Routing.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.Leases != nil {
+		if err := s.Leases.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *SharedLeasesConfig) Validate() error {
+	if c.RenewInterval.Duration() < 100*time.Millisecond {
+		return fmt.Errorf("sharedState.leases.renewInterval should be at least 100ms")
+	}
+	if c.Ttl.Duration() < 2*c.RenewInterval.Duration() {
+		return fmt.Errorf("sharedState.leases.ttl (%v) should be at least twice renewInterval (%v), otherwise one failed renewal loses the lease",
+			c.Ttl.Duration(), c.RenewInterval.Duration())
+	}
+	return nil
+}
+
+func (c *SharedRoutingConfig) Validate() error {
+	if c.SyncInterval.Duration() < time.Second {
+		return fmt.Errorf("sharedState.routing.syncInterval should be at least 1s")
+	}
+	if c.StaleAfter.Duration() < 2*c.SyncInterval.Duration() {
+		return fmt.Errorf("sharedState.routing.staleAfter (%v) should be at least twice syncInterval (%v), otherwise peers drop out between two syncs",
+			c.StaleAfter.Duration(), c.SyncInterval.Duration())
+	}
+	if c.Metrics != nil && !*c.Metrics && c.CircuitBreakers != nil && !*c.CircuitBreakers {
+		return fmt.Errorf("sharedState.routing must enable at least one of metrics or circuitBreakers")
+	}
 	return nil
 }
 
@@ -330,6 +798,47 @@ func (c *CacheConfig) Validate() error {
 			return err
 		}
 	}
+	if c.GetTimeout < 0 {
+		return fmt.Errorf("cache.getTimeout must be greater than or equal to 0")
+	}
+	if c.SetTimeout < 0 {
+		return fmt.Errorf("cache.setTimeout must be greater than or equal to 0")
+	}
+	if c.KeyHash != nil {
+		switch c.KeyHash.Algorithm {
+		case "", CacheKeyHashSha256, CacheKeyHashXxhash, CacheKeyHashBlake3:
+		default:
+			return fmt.Errorf("cache.keyHash.algorithm must be one of 'sha256', 'xxhash' or 'blake3', got '%s'", c.KeyHash.Algorithm)
+		}
+	}
+	if c.ErrorResults != nil {
+		if c.ErrorResults.ExecutionRevertedTtl < 0 {
+			return fmt.Errorf("cache.errorResults.executionRevertedTtl must be greater than or equal to 0")
+		}
+		if c.ErrorResults.InvalidParamsTtl < 0 {
+			return fmt.Errorf("cache.errorResults.invalidParamsTtl must be greater than or equal to 0")
+		}
+	}
+	if c.HitRateReport != nil {
+		if err := c.HitRateReport.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CacheHitRateReportConfig) Validate() error {
+	if c.Interval < 0 {
+		return fmt.Errorf("cache.hitRateReport.interval must be greater than or equal to 0")
+	}
+	if c.MinRequests < 0 {
+		return fmt.Errorf("cache.hitRateReport.minRequests must be greater than or equal to 0")
+	}
+	for _, budget := range c.Budgets {
+		if budget.MinHitRate < 0 || budget.MinHitRate > 1 {
+			return fmt.Errorf("cache.hitRateReport.budgets.*.minHitRate must be between 0 and 1, got %v", budget.MinHitRate)
+		}
+	}
 	return nil
 }
 
@@ -370,16 +879,23 @@ func (p *CachePolicyConfig) Validate(c *CacheConfig) error {
 		return fmt.Errorf("cache.*.policies.*.connector is required")
 	}
 
-	found := false
+	var target *ConnectorConfig
 	for _, connector := range c.Connectors {
 		if connector.Id == p.Connector {
-			found = true
+			target = connector
 			break
 		}
 	}
-	if !found {
+	if target == nil {
 		return fmt.Errorf("cache.*.policies.*.connector '%s' does not exist in cache.connectors", p.Connector)
 	}
+	// With records on, a late write of an unfinalized response must not
+	// replace the finalized one; a connector that cannot refuse it may only
+	// cache finalized data.
+	if c.Records != nil && c.Records.Enabled != nil && *c.Records.Enabled &&
+		p.Finality != DataFinalityStateFinalized && !target.canGuardWrites() {
+		return fmt.Errorf("cache.*.policies.*.connector '%s' cannot guard writes against stale overwrites, so it can only be used with finality: finalized", p.Connector)
+	}
 
 	if p.MinItemSize != nil {
 		if _, err := util.ParseByteSize(*p.MinItemSize); err != nil {
@@ -420,9 +936,48 @@ func (p *CachePolicyConfig) Validate(c *CacheConfig) error {
 		}
 	}
 
+	if p.MaxReadLatency < 0 {
+		return fmt.Errorf("cache.*.policies.*.maxReadLatency must be greater than or equal to 0")
+	}
+
+	if p.EmptyTtl < 0 {
+		return fmt.Errorf("cache.*.policies.*.emptyTtl must be greater than or equal to 0")
+	}
+	if p.EmptyTtl == 0 && p.EmptyInvalidateOnNewHead != nil {
+		return fmt.Errorf("cache.*.policies.*.emptyInvalidateOnNewHead requires emptyTtl")
+	}
+
+	if p.StaleWhileRevalidate < 0 {
+		return fmt.Errorf("cache.*.policies.*.staleWhileRevalidate must be greater than or equal to 0")
+	}
+	if p.StaleWhileRevalidate > 0 {
+		if p.TTL == nil || (p.TTL.FixedDuration() <= 0 && p.TTL.BlockTimeMultiplier <= 0) {
+			return fmt.Errorf("cache.*.policies.*.staleWhileRevalidate requires a ttl, entries that never expire need no refresh")
+		}
+		if ttl := p.TTL.FixedDuration(); ttl > 0 && p.StaleWhileRevalidate.Duration() >= ttl {
+			return fmt.Errorf("cache.*.policies.*.staleWhileRevalidate (%v) must be shorter than ttl (%v), otherwise every read refreshes the entry", p.StaleWhileRevalidate.Duration(), ttl)
+		}
+	}
+
 	return nil
 }
 
+// canGuardWrites reports whether every tier the connector writes to can refuse
+// a write that would replace an entry with a higher finality guard. ClickHouse
+// only appends rows, so it cannot.
+func (c *ConnectorConfig) canGuardWrites() bool {
+	switch {
+	case c.Driver == DriverClickHouse:
+		return false
+	case c.Driver == DriverTiered && c.Tiered != nil:
+		return (c.Tiered.Hot == nil || c.Tiered.Hot.canGuardWrites()) &&
+			(c.Tiered.Cold == nil || c.Tiered.Cold.canGuardWrites())
+	case c.Driver == DriverLayered && c.Layered != nil:
+		return c.Layered.L2 == nil || c.Layered.L2.canGuardWrites()
+	}
+	return true
+}
+
 func (c *ConnectorConfig) Validate() error {
 	if c.Id == "" {
 		return fmt.Errorf("*.connector.id is required")
@@ -430,7 +985,7 @@ func (c *ConnectorConfig) Validate() error {
 	if c.Driver == "" {
 		return fmt.Errorf("database.*.connector.driver is required")
 	}
-	drivers := []ConnectorDriverType{DriverMemory, DriverRedis, DriverPostgreSQL, DriverDynamoDB, DriverGrpc}
+	drivers := []ConnectorDriverType{DriverMemory, DriverRedis, DriverPostgreSQL, DriverDynamoDB, DriverGrpc, DriverTiered, DriverMemcached, DriverLayered, DriverCassandra, DriverMongoDB, DriverBadger, DriverClickHouse}
 	if !slices.Contains(drivers, c.Driver) {
 		return fmt.Errorf("database.*.connector.driver '%s' is invalid must be one of: %v", c.Driver, drivers)
 	}
@@ -446,6 +1001,21 @@ func (c *ConnectorConfig) Validate() error {
 	if c.Driver == DriverDynamoDB && c.DynamoDB == nil {
 		return fmt.Errorf("database.*.connector.dynamodb is required when driver is dynamodb")
 	}
+	if c.Driver == DriverMemcached && c.Memcached == nil {
+		return fmt.Errorf("database.*.connector.memcached is required when driver is memcached")
+	}
+	if c.Driver == DriverCassandra && c.Cassandra == nil {
+		return fmt.Errorf("database.*.connector.cassandra is required when driver is cassandra")
+	}
+	if c.Driver == DriverMongoDB && c.MongoDB == nil {
+		return fmt.Errorf("database.*.connector.mongodb is required when driver is mongodb")
+	}
+	if c.Driver == DriverBadger && c.Badger == nil {
+		return fmt.Errorf("database.*.connector.badger is required when driver is badger")
+	}
+	if c.Driver == DriverClickHouse && c.ClickHouse == nil {
+		return fmt.Errorf("database.*.connector.clickhouse is required when driver is clickhouse")
+	}
 
 	// TODO switch to go-validator library :D
 	if c.Memory != nil && (c.Redis != nil || c.PostgreSQL != nil || c.DynamoDB != nil) {
@@ -460,6 +1030,9 @@ func (c *ConnectorConfig) Validate() error {
 	if c.DynamoDB != nil && (c.Memory != nil || c.Redis != nil || c.PostgreSQL != nil) {
 		return fmt.Errorf("database.*.connector.dynamodb is mutually exclusive with database.*.connector.memory, database.*.connector.redis, and database.*.connector.postgresql")
 	}
+	if c.Memcached != nil && (c.Memory != nil || c.Redis != nil || c.PostgreSQL != nil || c.DynamoDB != nil) {
+		return fmt.Errorf("database.*.connector.memcached is mutually exclusive with database.*.connector.memory, database.*.connector.redis, database.*.connector.postgresql, and database.*.connector.dynamodb")
+	}
 
 	if c.DynamoDB != nil {
 		if err := c.DynamoDB.Validate(); err != nil {
@@ -481,11 +1054,115 @@ func (c *ConnectorConfig) Validate() error {
 			return err
 		}
 	}
+	if c.Memcached != nil {
+		if err := c.Memcached.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Cassandra != nil {
+		if c.Memory != nil || c.Redis != nil || c.PostgreSQL != nil || c.DynamoDB != nil || c.Memcached != nil {
+			return fmt.Errorf("database.*.connector.cassandra is mutually exclusive with the other driver configs")
+		}
+		if err := c.Cassandra.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.MongoDB != nil {
+		if c.Memory != nil || c.Redis != nil || c.PostgreSQL != nil || c.DynamoDB != nil || c.Memcached != nil || c.Cassandra != nil {
+			return fmt.Errorf("database.*.connector.mongodb is mutually exclusive with the other driver configs")
+		}
+		if err := c.MongoDB.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Badger != nil {
+		if c.Memory != nil || c.Redis != nil || c.PostgreSQL != nil || c.DynamoDB != nil || c.Memcached != nil || c.Cassandra != nil || c.MongoDB != nil {
+			return fmt.Errorf("database.*.connector.badger is mutually exclusive with the other driver configs")
+		}
+		if err := c.Badger.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.ClickHouse != nil {
+		if c.Memory != nil || c.Redis != nil || c.PostgreSQL != nil || c.DynamoDB != nil || c.Memcached != nil || c.Cassandra != nil || c.MongoDB != nil || c.Badger != nil {
+			return fmt.Errorf("database.*.connector.clickhouse is mutually exclusive with the other driver configs")
+		}
+		if err := c.ClickHouse.Validate(); err != nil {
+			return err
+		}
+	}
 	if c.Grpc != nil {
 		if err := c.Grpc.Validate(); err != nil {
 			return err
 		}
 	}
+	if c.Driver == DriverTiered {
+		if c.Tiered == nil {
+			return fmt.Errorf("database.*.connector.tiered is required when driver is tiered")
+		}
+		if err := c.Tiered.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Driver == DriverLayered {
+		if c.Layered == nil {
+			return fmt.Errorf("database.*.connector.layered is required when driver is layered")
+		}
+		if err := c.Layered.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Tombstones != nil {
+		if c.Driver == DriverGrpc {
+			return fmt.Errorf("database.*.connector.tombstones is not supported by the read-only grpc driver")
+		}
+		if c.Tombstones.GracePeriod <= 0 {
+			return fmt.Errorf("database.*.connector.tombstones.gracePeriod must be > 0")
+		}
+		if c.Tombstones.BatchSize <= 0 {
+			return fmt.Errorf("database.*.connector.tombstones.batchSize must be > 0")
+		}
+		if c.Tombstones.FlushInterval <= 0 {
+			return fmt.Errorf("database.*.connector.tombstones.flushInterval must be > 0")
+		}
+	}
+
+	if c.CircuitBreaker != nil {
+		if c.CircuitBreaker.FailureThreshold < 0 {
+			return fmt.Errorf("database.*.connector.circuitBreaker.failureThreshold must be > 0")
+		}
+		if c.CircuitBreaker.ProbeInterval < 0 {
+			return fmt.Errorf("database.*.connector.circuitBreaker.probeInterval must be > 0")
+		}
+	}
+
+	if c.Overflow != nil {
+		if c.Driver == DriverGrpc {
+			return fmt.Errorf("database.*.connector.overflow is not supported by the read-only grpc driver")
+		}
+		if err := c.Overflow.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Compression != nil {
+		if c.Driver == DriverGrpc {
+			return fmt.Errorf("database.*.connector.compression is not supported by the read-only grpc driver")
+		}
+		if err := c.Compression.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Encryption != nil {
+		if c.Driver == DriverGrpc {
+			return fmt.Errorf("database.*.connector.encryption is not supported by the read-only grpc driver")
+		}
+		if err := c.Encryption.Validate(); err != nil {
+			return err
+		}
+	}
 
 	for i, fsCfg := range c.FailsafeForGets {
 		if err := validateConnectorFailsafe(c.Id, "failsafeForGets", i, fsCfg); err != nil {
@@ -506,6 +1183,63 @@ func (c *ConnectorConfig) Validate() error {
 // connections to each backing server; it is not a recommended operating point.
 const MaxGrpcConnPoolSize = 256
 
+func (t *TieredConnectorConfig) Validate() error {
+	if t.Hot == nil || t.Cold == nil {
+		return fmt.Errorf("database.*.connector.tiered.hot and database.*.connector.tiered.cold are both required")
+	}
+	if t.Hot.Driver == DriverTiered || t.Cold.Driver == DriverTiered {
+		return fmt.Errorf("database.*.connector.tiered.hot/cold cannot themselves be tiered connectors")
+	}
+	if t.OffloadAfter < 0 {
+		return fmt.Errorf("database.*.connector.tiered.offloadAfter must be >= 0")
+	}
+	if err := t.Hot.Validate(); err != nil {
+		return fmt.Errorf("database.*.connector.tiered.hot: %w", err)
+	}
+	if err := t.Cold.Validate(); err != nil {
+		return fmt.Errorf("database.*.connector.tiered.cold: %w", err)
+	}
+	return nil
+}
+
+func (l *LayeredConnectorConfig) Validate() error {
+	if l.L2 == nil {
+		return fmt.Errorf("database.*.connector.layered.l2 is required")
+	}
+	if l.L2.Driver == DriverLayered {
+		return fmt.Errorf("database.*.connector.layered.l2 cannot itself be a layered connector")
+	}
+	if l.L2.Driver == DriverMemory {
+		return fmt.Errorf("database.*.connector.layered.l2 must be a remote connector, use a memory connector directly instead")
+	}
+	if l.L1Ttl <= 0 {
+		return fmt.Errorf("database.*.connector.layered.l1Ttl must be > 0")
+	}
+	if l.L2Ttl < 0 {
+		return fmt.Errorf("database.*.connector.layered.l2Ttl must be >= 0")
+	}
+	if l.WriteMode != LayeredWriteThrough && l.WriteMode != LayeredWriteBack {
+		return fmt.Errorf("database.*.connector.layered.writeMode must be one of: %s, %s", LayeredWriteThrough, LayeredWriteBack)
+	}
+	if l.WriteMode == LayeredWriteBack {
+		if l.FlushInterval <= 0 {
+			return fmt.Errorf("database.*.connector.layered.flushInterval must be > 0")
+		}
+		if l.MaxPending <= 0 {
+			return fmt.Errorf("database.*.connector.layered.maxPending must be > 0")
+		}
+	}
+	if l.L1 != nil {
+		if err := l.L1.Validate(); err != nil {
+			return fmt.Errorf("database.*.connector.layered.l1: %w", err)
+		}
+	}
+	if err := l.L2.Validate(); err != nil {
+		return fmt.Errorf("database.*.connector.layered.l2: %w", err)
+	}
+	return nil
+}
+
 func validateGrpcConnPoolSize(scope string, poolSize int) error {
 	if poolSize < 0 {
 		return fmt.Errorf("%s.poolSize must not be negative", scope)
@@ -567,8 +1301,24 @@ func (p *DynamoDBConnectorConfig) Validate() error {
 	if p.SetTimeout == 0 {
 		return fmt.Errorf("database.*.connector.dynamodb.setTimeout is required")
 	}
-	if p.StatePollInterval == 0 {
-		return fmt.Errorf("database.*.connector.dynamodb.statePollInterval is required")
+	if p.StatePollInterval == 0 {
+		return fmt.Errorf("database.*.connector.dynamodb.statePollInterval is required")
+	}
+	if chunkSize, err := util.ParseByteSize(p.ChunkSize); err != nil || chunkSize <= 0 || chunkSize > 390*1024 {
+		return fmt.Errorf("database.*.connector.dynamodb.chunkSize %q must be a size between 1B and 390KB", p.ChunkSize)
+	}
+	if p.Auth != nil {
+		if err := p.Auth.Validate("database.*.connector.dynamodb.auth"); err != nil {
+			return err
+		}
+	}
+	if p.Dax != nil {
+		if !strings.HasPrefix(p.Dax.Endpoint, "dax://") && !strings.HasPrefix(p.Dax.Endpoint, "daxs://") {
+			return fmt.Errorf("database.*.connector.dynamodb.dax.endpoint must start with dax:// or daxs://")
+		}
+		if p.Dax.FallbackFor <= 0 {
+			return fmt.Errorf("database.*.connector.dynamodb.dax.fallbackFor must be > 0")
+		}
 	}
 	return nil
 }
@@ -628,8 +1378,8 @@ func (p *PostgreSQLConnectorConfig) Validate() error {
 			}
 		}
 		if p.IAMAuth.Auth != nil {
-			if !slices.Contains([]string{"file", "env", "secret"}, p.IAMAuth.Auth.Mode) {
-				return fmt.Errorf("postgresql.iamAuth.auth.mode %q is invalid; must be file, env, or secret", p.IAMAuth.Auth.Mode)
+			if err := p.IAMAuth.Auth.Validate("postgresql.iamAuth.auth"); err != nil {
+				return err
 			}
 		}
 	}
@@ -666,10 +1416,44 @@ func (c *RedisConnectorConfig) Validate() error {
 			}
 		}
 		if c.IAMAuth.Auth != nil {
-			if !slices.Contains([]string{"file", "env", "secret"}, c.IAMAuth.Auth.Mode) {
-				return fmt.Errorf("redis.iamAuth.auth.mode %q is invalid; must be file, env, or secret", c.IAMAuth.Auth.Mode)
+			if err := c.IAMAuth.Auth.Validate("redis.iamAuth.auth"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c.Cluster != nil && c.Sentinel != nil {
+		return fmt.Errorf("redis connector: 'cluster' and 'sentinel' cannot be used together")
+	}
+	if c.Cluster != nil {
+		for _, addr := range c.Cluster.Addrs {
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return fmt.Errorf("redis.cluster.addrs contains '%s', must be host:port", addr)
+			}
+		}
+		if c.Cluster.MaxRedirects < 0 {
+			return fmt.Errorf("redis.cluster.maxRedirects must be greater than or equal to 0")
+		}
+		// Redis Cluster only has database 0.
+		if parsed, perr := url.Parse(uri); perr == nil && strings.Trim(parsed.Path, "/") != "" && strings.Trim(parsed.Path, "/") != "0" {
+			return fmt.Errorf("redis.cluster only supports db 0 (got '%s')", strings.Trim(parsed.Path, "/"))
+		}
+	}
+	if c.Sentinel != nil {
+		if c.Sentinel.MasterName == "" {
+			return fmt.Errorf("redis.sentinel.masterName is required")
+		}
+		if len(c.Sentinel.Addrs) == 0 {
+			return fmt.Errorf("redis.sentinel.addrs is required")
+		}
+		for _, addr := range c.Sentinel.Addrs {
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return fmt.Errorf("redis.sentinel.addrs contains '%s', must be host:port", addr)
 			}
 		}
+		if c.IAMAuth != nil && c.IAMAuth.Enabled {
+			return fmt.Errorf("redis.iamAuth cannot be combined with redis.sentinel")
+		}
 	}
 
 	// Validate lock retry interval
@@ -680,6 +1464,249 @@ func (c *RedisConnectorConfig) Validate() error {
 	return nil
 }
 
+func (m *MemcachedConnectorConfig) Validate() error {
+	if len(m.Servers) == 0 {
+		return fmt.Errorf("database.*.connector.memcached.servers is required")
+	}
+	for i, s := range m.Servers {
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("database.*.connector.memcached.servers[%d] must not be empty", i)
+		}
+	}
+	// Keys are limited to 250 bytes without spaces or control characters;
+	// leave most of that to the cache keys themselves.
+	if len(m.KeyPrefix) > 64 || strings.ContainsAny(m.KeyPrefix, " \t\r\n") {
+		return fmt.Errorf("database.*.connector.memcached.keyPrefix must be at most 64 bytes without whitespace")
+	}
+	if m.ConnPoolSize < 0 {
+		return fmt.Errorf("database.*.connector.memcached.connPoolSize must not be negative")
+	}
+	if m.LockRetryInterval.Duration() < 100*time.Millisecond && m.LockRetryInterval.Duration() > 0 {
+		return fmt.Errorf("memcached.lockRetryInterval should be at least 100ms to avoid excessive memcached load")
+	}
+	return nil
+}
+
+// cassandraIdentifier matches the unquoted keyspace and table names the
+// connector interpolates into CQL.
+var cassandraIdentifier = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,47}$`)
+
+func (c *CassandraConnectorConfig) Validate() error {
+	if len(c.Hosts) == 0 {
+		return fmt.Errorf("database.*.connector.cassandra.hosts is required")
+	}
+	for i, h := range c.Hosts {
+		if strings.TrimSpace(h) == "" {
+			return fmt.Errorf("database.*.connector.cassandra.hosts[%d] must not be empty", i)
+		}
+	}
+	if !cassandraIdentifier.MatchString(c.Keyspace) {
+		return fmt.Errorf("database.*.connector.cassandra.keyspace %q must be a letter followed by up to 47 letters, digits or underscores", c.Keyspace)
+	}
+	// The reverse index table adds "_rvi" to the name.
+	if !cassandraIdentifier.MatchString(c.Table) || len(c.Table) > 44 {
+		return fmt.Errorf("database.*.connector.cassandra.table %q must be a letter followed by up to 43 letters, digits or underscores", c.Table)
+	}
+	if _, err := cassandraConsistency(c.Consistency); err != nil {
+		return fmt.Errorf("database.*.connector.cassandra.consistency: %w", err)
+	}
+	if c.Password != "" && c.Username == "" {
+		return fmt.Errorf("database.*.connector.cassandra.username is required when password is set")
+	}
+	if c.NumConns < 0 {
+		return fmt.Errorf("database.*.connector.cassandra.numConns must not be negative")
+	}
+	if c.LockRetryInterval.Duration() < 100*time.Millisecond && c.LockRetryInterval.Duration() > 0 {
+		return fmt.Errorf("cassandra.lockRetryInterval should be at least 100ms to avoid excessive lightweight transactions")
+	}
+	return nil
+}
+
+// cassandraConsistency normalizes a consistency level name, checked here
+// without importing the driver into common.
+func cassandraConsistency(name string) (string, error) {
+	switch strings.ToUpper(name) {
+	case "ANY", "ONE", "TWO", "THREE", "QUORUM", "ALL", "LOCAL_QUORUM", "EACH_QUORUM", "LOCAL_ONE":
+		return strings.ToUpper(name), nil
+	}
+	return "", fmt.Errorf("unknown consistency level %q", name)
+}
+
+var clickHouseIdentifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]{0,127}$`)
+
+func (c *ClickHouseConnectorConfig) Validate() error {
+	if !strings.HasPrefix(c.Url, "http://") && !strings.HasPrefix(c.Url, "https://") {
+		return fmt.Errorf("database.*.connector.clickhouse.url must start with http:// or https:// (the HTTP interface, port 8123 or 8443 by default)")
+	}
+	if !clickHouseIdentifier.MatchString(c.Database) {
+		return fmt.Errorf("database.*.connector.clickhouse.database %q must be a letter or underscore followed by up to 127 letters, digits or underscores", c.Database)
+	}
+	if !clickHouseIdentifier.MatchString(c.Table) {
+		return fmt.Errorf("database.*.connector.clickhouse.table %q must be a letter or underscore followed by up to 127 letters, digits or underscores", c.Table)
+	}
+	if c.Password != "" && c.Username == "" {
+		return fmt.Errorf("database.*.connector.clickhouse.username is required when password is set")
+	}
+	switch c.PartitionBy {
+	case "day", "week", "month":
+	default:
+		return fmt.Errorf("database.*.connector.clickhouse.partitionBy must be day, week or month (got %q)", c.PartitionBy)
+	}
+	if c.BatchSize < 1 {
+		return fmt.Errorf("database.*.connector.clickhouse.batchSize must be at least 1")
+	}
+	if c.MaxBufferedRows < c.BatchSize {
+		return fmt.Errorf("database.*.connector.clickhouse.maxBufferedRows must be at least batchSize (%d)", c.BatchSize)
+	}
+	if c.FlushInterval.Duration() < 10*time.Millisecond {
+		return fmt.Errorf("database.*.connector.clickhouse.flushInterval must be at least 10ms")
+	}
+	return nil
+}
+
+func (b *BadgerConnectorConfig) Validate() error {
+	if strings.TrimSpace(b.Dir) == "" {
+		return fmt.Errorf("database.*.connector.badger.dir is required")
+	}
+	if b.MaxDiskSize != "" {
+		if size, err := util.ParseByteSize(b.MaxDiskSize); err != nil || size <= 0 {
+			return fmt.Errorf("database.*.connector.badger.maxDiskSize %q must be a positive size such as 10GB", b.MaxDiskSize)
+		}
+	}
+	if b.GCDiscardRatio <= 0 || b.GCDiscardRatio >= 1 {
+		return fmt.Errorf("database.*.connector.badger.gcDiscardRatio must be between 0 and 1 exclusive (got %v)", b.GCDiscardRatio)
+	}
+	if b.EvictionInterval.Duration() < time.Second {
+		return fmt.Errorf("database.*.connector.badger.evictionInterval must be at least 1s")
+	}
+	if b.GCInterval.Duration() < time.Second {
+		return fmt.Errorf("database.*.connector.badger.gcInterval must be at least 1s")
+	}
+	return nil
+}
+
+func (m *MongoDBConnectorConfig) Validate() error {
+	if !strings.HasPrefix(m.URI, "mongodb://") && !strings.HasPrefix(m.URI, "mongodb+srv://") {
+		return fmt.Errorf("database.*.connector.mongodb.uri must start with mongodb:// or mongodb+srv://")
+	}
+	if m.Database == "" || strings.ContainsAny(m.Database, "/\\. \"$") {
+		return fmt.Errorf("database.*.connector.mongodb.database %q must be set and must not contain any of /\\. \"$", m.Database)
+	}
+	if m.Collection == "" || strings.Contains(m.Collection, "$") || strings.HasPrefix(m.Collection, "system.") {
+		return fmt.Errorf("database.*.connector.mongodb.collection %q must be set, must not contain $ and must not start with \"system.\"", m.Collection)
+	}
+	switch m.ReadPreference {
+	case "primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest":
+	default:
+		return fmt.Errorf("database.*.connector.mongodb.readPreference must be primary, primaryPreferred, secondary, secondaryPreferred or nearest (got %q)", m.ReadPreference)
+	}
+	if m.WriteConcern != "majority" {
+		if w, err := strconv.Atoi(m.WriteConcern); err != nil || w < 0 {
+			return fmt.Errorf("database.*.connector.mongodb.writeConcern must be majority or a non-negative number (got %q)", m.WriteConcern)
+		}
+	}
+	if m.Password != "" && m.Username == "" {
+		return fmt.Errorf("database.*.connector.mongodb.username is required when password is set")
+	}
+	if m.LockRetryInterval.Duration() < 100*time.Millisecond && m.LockRetryInterval.Duration() > 0 {
+		return fmt.Errorf("mongodb.lockRetryInterval should be at least 100ms to avoid excessive lock attempts")
+	}
+	return nil
+}
+
+func (c *ConnectorCompressionConfig) Validate() error {
+	if c.Algorithm != "zstd" && c.Algorithm != "snappy" {
+		return fmt.Errorf("database.*.connector.compression.algorithm must be zstd or snappy (got %q)", c.Algorithm)
+	}
+	if _, err := util.ParseByteSize(c.Threshold); err != nil {
+		return fmt.Errorf("database.*.connector.compression.threshold %q must be a size such as 1KB", c.Threshold)
+	}
+	switch c.ZstdLevel {
+	case "fastest", "default", "better", "best":
+	default:
+		return fmt.Errorf("database.*.connector.compression.zstdLevel must be fastest, default, better or best (got %q)", c.ZstdLevel)
+	}
+	return nil
+}
+
+func (c *ConnectorEncryptionConfig) Validate() error {
+	if len(c.Keys) == 0 {
+		return fmt.Errorf("database.*.connector.encryption.keys must have at least one key")
+	}
+	ids := make(map[string]bool, len(c.Keys))
+	for i, k := range c.Keys {
+		if k == nil {
+			return fmt.Errorf("database.*.connector.encryption.keys[%d] must not be null", i)
+		}
+		if k.Id == "" || len(k.Id) > 255 {
+			return fmt.Errorf("database.*.connector.encryption.keys[%d].id must be 1 to 255 bytes", i)
+		}
+		if ids[k.Id] {
+			return fmt.Errorf("database.*.connector.encryption.keys[%d].id %q is duplicated", i, k.Id)
+		}
+		ids[k.Id] = true
+		switch k.Source {
+		case "env":
+			if k.Env == "" {
+				return fmt.Errorf("database.*.connector.encryption.keys[%d].env is required for source env", i)
+			}
+		case "kms":
+			if k.EncryptedDataKey == "" || k.KmsRegion == "" {
+				return fmt.Errorf("database.*.connector.encryption.keys[%d].encryptedDataKey and kmsRegion are required for source kms", i)
+			}
+			if k.Auth != nil {
+				if err := k.Auth.Validate(fmt.Sprintf("database.*.connector.encryption.keys[%d].auth", i)); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("database.*.connector.encryption.keys[%d].source must be env or kms (got %q)", i, k.Source)
+		}
+	}
+	if !ids[c.PrimaryKeyId] {
+		return fmt.Errorf("database.*.connector.encryption.primaryKeyId %q is not one of the keys", c.PrimaryKeyId)
+	}
+	return nil
+}
+
+func (o *OverflowConfig) Validate() error {
+	threshold, err := util.ParseByteSize(o.Threshold)
+	if err != nil || threshold == 0 {
+		return fmt.Errorf("database.*.connector.overflow.threshold %q must be a positive size such as 350KB", o.Threshold)
+	}
+	if o.S3 == nil {
+		return fmt.Errorf("database.*.connector.overflow.s3 is required")
+	}
+	if o.S3.Bucket == "" {
+		return fmt.Errorf("database.*.connector.overflow.s3.bucket is required")
+	}
+	if o.S3.Region == "" {
+		return fmt.Errorf("database.*.connector.overflow.s3.region is required")
+	}
+	if o.S3.Auth != nil {
+		if err := o.S3.Auth.Validate("database.*.connector.overflow.s3.auth"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the credential mode and the fields it needs. webIdentity
+// may leave roleArn and webIdentityTokenFile empty when the IRSA env vars
+// provide them at runtime.
+func (a *AwsAuthConfig) Validate(prefix string) error {
+	if !slices.Contains([]string{"file", "env", "secret", "assumeRole", "webIdentity"}, a.Mode) {
+		return fmt.Errorf("%s.mode %q is invalid; must be file, env, secret, assumeRole, or webIdentity", prefix, a.Mode)
+	}
+	if a.Mode == "assumeRole" && a.RoleArn == "" {
+		return fmt.Errorf("%s.roleArn is required when mode is assumeRole", prefix)
+	}
+	if a.ExternalId != "" && a.Mode != "assumeRole" {
+		return fmt.Errorf("%s.externalId is only supported when mode is assumeRole", prefix)
+	}
+	return nil
+}
+
 func (p *MemoryConnectorConfig) Validate() error {
 	return nil
 }
@@ -688,6 +1715,35 @@ func (p *ProjectConfig) Validate(c *Config) error {
 	if p.Id == "" {
 		return fmt.Errorf("project id is required")
 	}
+	if p.CacheResidency != "" && c.Database != nil && c.Database.EvmJsonRpcCache != nil {
+		found := false
+		for _, conn := range c.Database.EvmJsonRpcCache.Connectors {
+			if conn != nil && conn.Residency == p.CacheResidency {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("project.%s.cacheResidency %q matches no database.evmJsonRpcCache connector residency", p.Id, p.CacheResidency)
+		}
+	}
+	if p.ResponseCompression != nil {
+		if err := p.ResponseCompression.Validate("project.*.responseCompression"); err != nil {
+			return err
+		}
+	}
+	if len(p.PreferredUpstreams) > 0 {
+		seen := make(map[string]bool, len(p.PreferredUpstreams))
+		for _, id := range p.PreferredUpstreams {
+			if id == "" {
+				return fmt.Errorf("project.*.preferredUpstreams must not contain empty ids")
+			}
+			if seen[id] {
+				return fmt.Errorf("project.*.preferredUpstreams must be unique, '%s' is duplicated", id)
+			}
+			seen[id] = true
+		}
+	}
 	if len(p.Providers) > 0 {
 		existingIds := make(map[string]bool)
 		for _, provider := range p.Providers {
@@ -739,6 +1795,27 @@ func (p *ProjectConfig) Validate(c *Config) error {
 			return err
 		}
 	}
+	for i, mr := range p.MethodRewrites {
+		if err := mr.Validate(); err != nil {
+			return fmt.Errorf("project.*.methodRewrites[%d]: %w", i, err)
+		}
+	}
+	if p.SelfService != nil && p.SelfService.Enabled {
+		if p.Auth == nil || len(p.Auth.Strategies) == 0 {
+			return fmt.Errorf("project.*.selfService requires project.*.auth to be configured so callers can be identified")
+		}
+		if p.SelfService.RecentErrorsSize < 0 {
+			return fmt.Errorf("project.*.selfService.recentErrorsSize must be >= 0")
+		}
+		if p.SelfService.MaxTrackedUsers < 0 {
+			return fmt.Errorf("project.*.selfService.maxTrackedUsers must be >= 0")
+		}
+	}
+	if p.SubscriptionArchive != nil {
+		if err := p.SubscriptionArchive.Validate(); err != nil {
+			return err
+		}
+	}
 	if p.CORS != nil {
 		if err := p.CORS.Validate(); err != nil {
 			return err
@@ -757,6 +1834,41 @@ func (p *ProjectConfig) Validate(c *Config) error {
 	return nil
 }
 
+func (s *SubscriptionArchiveConfig) Validate() error {
+	if _, _, ok := s.BucketAndPrefix(); !ok {
+		return fmt.Errorf("project.*.subscriptionArchive.path must be s3://bucket/prefix/ or gs://bucket/prefix/, got '%s'", s.Path)
+	}
+	if s.Format != "ndjson" {
+		return fmt.Errorf("project.*.subscriptionArchive.format '%s' is not supported, use 'ndjson'", s.Format)
+	}
+	for _, e := range s.Events {
+		if e != "heads" && e != "logs" {
+			return fmt.Errorf("project.*.subscriptionArchive.events contains '%s', allowed values are 'heads' and 'logs'", e)
+		}
+	}
+	if s.FlushInterval.Duration() < time.Second {
+		return fmt.Errorf("project.*.subscriptionArchive.flushInterval must be at least 1s")
+	}
+	if s.MaxRecords < 1 {
+		return fmt.Errorf("project.*.subscriptionArchive.maxRecords must be > 0")
+	}
+	if size, err := util.ParseByteSize(s.MaxSize); err != nil || size < 1 {
+		return fmt.Errorf("project.*.subscriptionArchive.maxSize '%s' is invalid", s.MaxSize)
+	}
+	if s.QueueSize < 1 {
+		return fmt.Errorf("project.*.subscriptionArchive.queueSize must be > 0")
+	}
+	if s.UploadTimeout.Duration() <= 0 {
+		return fmt.Errorf("project.*.subscriptionArchive.uploadTimeout must be > 0")
+	}
+	if s.Auth != nil {
+		if err := s.Auth.Validate("project.*.subscriptionArchive.auth"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (a *AuthConfig) Validate() error {
 	if len(a.Strategies) == 0 {
 		return fmt.Errorf("project.*.auth.strategies is required, add at least one strategy")
@@ -980,6 +2092,99 @@ func (u *UpstreamConfig) Validate(c *Config, skipEndpointCheck bool) error {
 			return fmt.Errorf("upstream.*.rateLimitBudget '%s' does not exist in config.rateLimiters", u.RateLimitBudget)
 		}
 	}
+	for i, mr := range u.MethodRewrites {
+		if err := mr.Validate(); err != nil {
+			return fmt.Errorf("upstream.*.methodRewrites[%d]: %w", i, err)
+		}
+	}
+	for i, w := range u.Maintenance {
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("upstream.*.maintenance[%d]: %w", i, err)
+		}
+	}
+	if u.Canary != nil && u.Canary.Enabled {
+		if u.Shadow != nil && u.Shadow.Enabled {
+			return fmt.Errorf("upstream.*.canary cannot be enabled on a shadow upstream")
+		}
+		if err := u.Canary.Validate(); err != nil {
+			return fmt.Errorf("upstream.*.canary: %w", err)
+		}
+	}
+	if u.Retirement != nil && u.Retirement.Enabled {
+		if err := u.Retirement.Validate(); err != nil {
+			return fmt.Errorf("upstream.*.retirement: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *UpstreamRetirementConfig) Validate() error {
+	if c.DeadFor < Duration(time.Minute) {
+		return fmt.Errorf("deadFor must be at least 1m")
+	}
+	if c.MinFailures < 1 {
+		return fmt.Errorf("minFailures must be at least 1")
+	}
+	return nil
+}
+
+func (c *UpstreamCanaryConfig) Validate() error {
+	prev := 0.0
+	for i, w := range c.Weights {
+		if w <= prev || w > 1 {
+			return fmt.Errorf("weights[%d] must be greater than the previous weight and at most 1, got %v", i, w)
+		}
+		prev = w
+	}
+	if c.StepDuration <= 0 {
+		return fmt.Errorf("stepDuration must be positive")
+	}
+	if c.MinSamples < 0 {
+		return fmt.Errorf("minSamples must not be negative")
+	}
+	if c.MaxErrorRateDelta < 0 || c.MaxErrorRateDelta > 1 {
+		return fmt.Errorf("maxErrorRateDelta must be between 0 and 1")
+	}
+	if c.MaxLatencyRatio < 1 {
+		return fmt.Errorf("maxLatencyRatio must be at least 1")
+	}
+	return nil
+}
+
+func (w *UpstreamMaintenanceWindowConfig) Validate() error {
+	if w == nil {
+		return fmt.Errorf("window must not be empty")
+	}
+	recurring := w.Schedule != "" || w.Duration > 0
+	oneOff := w.Start != "" || w.End != ""
+	switch {
+	case recurring && oneOff:
+		return fmt.Errorf("use either schedule+duration or start+end, not both")
+	case recurring:
+		if w.Schedule == "" || w.Duration <= 0 {
+			return fmt.Errorf("schedule and a positive duration are both required")
+		}
+		if strings.HasPrefix(strings.TrimSpace(w.Schedule), "@every") {
+			return fmt.Errorf("@every is not supported for maintenance windows, use a cron expression")
+		}
+		if _, err := util.ParseCronSchedule(w.Schedule); err != nil {
+			return err
+		}
+	case oneOff:
+		start, err := time.Parse(time.RFC3339, w.Start)
+		if err != nil {
+			return fmt.Errorf("start must be an RFC3339 timestamp: %w", err)
+		}
+		end, err := time.Parse(time.RFC3339, w.End)
+		if err != nil {
+			return fmt.Errorf("end must be an RFC3339 timestamp: %w", err)
+		}
+		if !end.After(start) {
+			return fmt.Errorf("end must be after start")
+		}
+	default:
+		return fmt.Errorf("either schedule+duration or start+end is required")
+	}
 	return nil
 }
 
@@ -1370,6 +2575,9 @@ func (r *RateLimitAutoTuneConfig) Validate() error {
 	if r.MaxBudget < 0 {
 		return fmt.Errorf("upstream.*.rateLimitAutoTune.maxBudget must be greater than or equal to 0")
 	}
+	if r.DiscoveredLimitTtl < 0 {
+		return fmt.Errorf("upstream.*.rateLimitAutoTune.discoveredLimitTtl must be greater than or equal to 0")
+	}
 	return nil
 }
 
@@ -1416,6 +2624,30 @@ func (n *NetworkConfig) Validate(c *Config) error {
 	return nil
 }
 
+func (m *MethodRewriteConfig) Validate() error {
+	if m == nil {
+		return fmt.Errorf("entry is nil")
+	}
+	if m.Method == "" {
+		return fmt.Errorf("method is required")
+	}
+	if m.Alias == "" && len(m.Params) == 0 && m.ResultField == "" {
+		return fmt.Errorf("at least one of alias, params or resultField is required")
+	}
+	for i, pr := range m.Params {
+		if pr == nil {
+			return fmt.Errorf("params[%d] is nil", i)
+		}
+		if pr.Index < 0 {
+			return fmt.Errorf("params[%d].index must be >= 0", i)
+		}
+		if pr.To == nil && pr.WrapField == "" {
+			return fmt.Errorf("params[%d] must set to or wrapField", i)
+		}
+	}
+	return nil
+}
+
 func (s *StaticResponseConfig) Validate() error {
 	if s == nil {
 		return fmt.Errorf("entry is nil")
@@ -1467,6 +2699,130 @@ func (e *EvmNetworkConfig) Validate() error {
 			}
 		}
 	}
+	if e.BlockTagPolicy != nil {
+		switch e.BlockTagPolicy.RewriteLatestTo {
+		case "", "safe", "finalized":
+		default:
+			return fmt.Errorf("network.*.evm.blockTagPolicy.rewriteLatestTo must be one of: safe, finalized (got %q)", e.BlockTagPolicy.RewriteLatestTo)
+		}
+		if e.BlockTagPolicy.UnfinalizedDepth < 0 {
+			return fmt.Errorf("network.*.evm.blockTagPolicy.unfinalizedDepth must be >= 0")
+		}
+		for _, m := range e.BlockTagPolicy.Methods {
+			if err := ValidatePattern(m); err != nil {
+				return fmt.Errorf("network.*.evm.blockTagPolicy.methods has invalid pattern %q: %w", m, err)
+			}
+		}
+	}
+	if e.Fork != nil {
+		if e.Fork.BlockNumber <= 0 {
+			return fmt.Errorf("network.*.evm.fork.blockNumber must be greater than 0")
+		}
+		if len(e.Fork.Upstreams) == 0 {
+			return fmt.Errorf("network.*.evm.fork.upstreams must select at least one fork node")
+		}
+		for _, sel := range e.Fork.Upstreams {
+			if err := ValidatePattern(sel); err != nil {
+				return fmt.Errorf("network.*.evm.fork.upstreams has invalid selector %q: %w", sel, err)
+			}
+		}
+		if strings.ContainsAny(e.Fork.InstanceId, ":*") {
+			return fmt.Errorf("network.*.evm.fork.instanceId must not contain ':' or '*' (got %q)", e.Fork.InstanceId)
+		}
+	}
+	if c := e.GetLogsCompletenessCheck; c != nil {
+		switch c.Source {
+		case "", GetLogsCompletenessSourceReceipts, GetLogsCompletenessSourceUpstream:
+		default:
+			return fmt.Errorf("network.*.evm.getLogsCompletenessCheck.source must be one of: receipts, upstream (got %q)", c.Source)
+		}
+		if c.SampleRate != nil && (*c.SampleRate <= 0 || *c.SampleRate > 1) {
+			return fmt.Errorf("network.*.evm.getLogsCompletenessCheck.sampleRate must be in (0, 1]")
+		}
+		if c.MaxBlockRange < 0 {
+			return fmt.Errorf("network.*.evm.getLogsCompletenessCheck.maxBlockRange must be >= 0")
+		}
+	}
+	if c := e.Abi; c != nil {
+		seen := make(map[string]bool, len(c.Contracts))
+		for i, ct := range c.Contracts {
+			if ct == nil {
+				return fmt.Errorf("network.*.evm.abi.contracts[%d] must not be empty", i)
+			}
+			if !isHexAddress(ct.Address) {
+				return fmt.Errorf("network.*.evm.abi.contracts[%d].address must be a 0x-prefixed 20-byte hex address (got %q)", i, ct.Address)
+			}
+			if seen[strings.ToLower(ct.Address)] {
+				return fmt.Errorf("network.*.evm.abi.contracts has duplicate address %s", ct.Address)
+			}
+			seen[strings.ToLower(ct.Address)] = true
+			if (ct.Abi == "") == (ct.AbiFile == "") {
+				return fmt.Errorf("network.*.evm.abi.contracts[%d] must set exactly one of abi or abiFile", i)
+			}
+		}
+		if c.Etherscan != nil && c.Etherscan.ApiKey == "" {
+			return fmt.Errorf("network.*.evm.abi.etherscan.apiKey is required")
+		}
+		if c.CacheTtl < 0 {
+			return fmt.Errorf("network.*.evm.abi.cacheTtl must be >= 0")
+		}
+		if c.FetchTimeout < 0 {
+			return fmt.Errorf("network.*.evm.abi.fetchTimeout must be >= 0")
+		}
+	}
+	if c := e.AddressActivity; c != nil {
+		if c.MaxWatchers < 0 {
+			return fmt.Errorf("network.*.evm.addressActivity.maxWatchers must be >= 0")
+		}
+		if c.MaxAddresses < 0 {
+			return fmt.Errorf("network.*.evm.addressActivity.maxAddresses must be >= 0")
+		}
+		if c.MaxEvents < 0 {
+			return fmt.Errorf("network.*.evm.addressActivity.maxEvents must be >= 0")
+		}
+		if c.WatcherTtl < 0 {
+			return fmt.Errorf("network.*.evm.addressActivity.watcherTtl must be >= 0")
+		}
+	}
+	if c := e.TagEmulation; c != nil {
+		for _, tag := range c.Tags {
+			if tag != "finalized" && tag != "safe" {
+				return fmt.Errorf("network.*.evm.tagEmulation.tags must only contain finalized or safe (got %q)", tag)
+			}
+		}
+		if c.FinalizedDepth < 0 {
+			return fmt.Errorf("network.*.evm.tagEmulation.finalizedDepth must be >= 0")
+		}
+		if c.SafeDepth < 0 {
+			return fmt.Errorf("network.*.evm.tagEmulation.safeDepth must be >= 0")
+		}
+		if cp := c.Checkpoint; cp != nil {
+			if cp.Method == "" {
+				return fmt.Errorf("network.*.evm.tagEmulation.checkpoint.method is required")
+			}
+			if c.Emulates("finalized") && cp.FinalizedPath == "" {
+				return fmt.Errorf("network.*.evm.tagEmulation.checkpoint.finalizedPath is required to emulate finalized")
+			}
+			if c.Emulates("safe") && cp.SafePath == "" {
+				return fmt.Errorf("network.*.evm.tagEmulation.checkpoint.safePath is required to emulate safe")
+			}
+			if cp.RefreshInterval < 0 {
+				return fmt.Errorf("network.*.evm.tagEmulation.checkpoint.refreshInterval must be >= 0")
+			}
+		}
+	}
+	if c := e.ReceiptsPrefetch; c != nil {
+		receipts := c.Receipts == nil || *c.Receipts
+		if !receipts && (c.Blocks == nil || !*c.Blocks) && (c.Logs == nil || !*c.Logs) {
+			return fmt.Errorf("network.*.evm.receiptsPrefetch must enable at least one of receipts, blocks or logs")
+		}
+		if c.MaxConcurrency < 0 {
+			return fmt.Errorf("network.*.evm.receiptsPrefetch.maxConcurrency must be >= 0")
+		}
+		if c.InterestWindow < 0 {
+			return fmt.Errorf("network.*.evm.receiptsPrefetch.interestWindow must be >= 0")
+		}
+	}
 	return nil
 }
 
@@ -1503,3 +2859,11 @@ func (c *SelectionPolicyConfig) Validate() error {
 	}
 	return nil
 }
+
+func isHexAddress(s string) bool {
+	if len(s) != 42 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
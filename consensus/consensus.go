@@ -31,6 +31,7 @@ func NewConsensus(cfg *common.ConsensusPolicyConfig, logger *zerolog.Logger) (*C
 		WithLowParticipantsBehavior(cfg.LowParticipantsBehavior).
 		WithLogger(logger).
 		WithFireAndForget(cfg.FireAndForget).
+		WithQuorum(cfg.Quorum).
 		WithMaxWaitOnResult(cfg.MaxWaitOnResult).
 		WithMaxWaitOnEmpty(cfg.MaxWaitOnEmpty).
 		WithRequiredParticipants(cfg.RequiredParticipants)
@@ -1068,7 +1068,7 @@ func (e *executor) trackAndPunishMisbehavingUpstreams(lg *zerolog.Logger, req *c
 	consensusSize := consensusGroup.ResponseSize
 
 	// Determine if the dispute log level would be emitted by the current logger level
-	shouldLog := e.logger.GetLevel() <= e.disputeLogLevel
+	shouldLog := common.LogLevelEnabled(e.logger, e.disputeLogLevel)
 
 	// Collect participants when either logging is enabled OR exporter is configured
 	collectParticipants := shouldLog || e.exporter != nil
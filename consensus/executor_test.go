@@ -362,6 +362,48 @@ func TestConsensus_ShortCircuit_CallerGetsWinnerBeforeSlowParticipants(t *testin
 	close(slowRelease)
 }
 
+// TestConsensus_Quorum_ReturnsOnceThresholdAgrees verifies that quorum mode
+// returns as soon as agreementThreshold participants agree, although the
+// lead (2) is not unassailable by the remaining participants (3) — the point
+// where the default short-circuit would keep waiting.
+func TestConsensus_Quorum_ReturnsOnceThresholdAgrees(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	pol := NewConsensusPolicyBuilder().
+		WithMaxParticipants(5).
+		WithAgreementThreshold(2).
+		WithQuorum(true).
+		WithLogger(&logger).
+		Build()
+
+	req := newTestRequest()
+
+	var callCount atomic.Int32
+	slowRelease := make(chan struct{})
+	defer close(slowRelease)
+
+	ctx := context.WithValue(context.Background(), common.RequestContextKey, req)
+
+	start := time.Now()
+	resp, err := pol.Run(ctx, req, func(ctx context.Context, _ *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+		if callCount.Add(1) <= 2 {
+			return validResponseWithValue("0x1"), nil
+		}
+		select {
+		case <-slowRelease:
+		case <-ctx.Done():
+		}
+		return validResponseWithValue("0x2"), nil
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	require.Less(t, time.Since(start), 500*time.Millisecond, "quorum should return without waiting for the slow participants")
+	jrr, err := resp.JsonRpcResponse()
+	require.NoError(t, err)
+	assert.JSONEq(t, `["0x1"]`, string(jrr.GetResultBytes()))
+}
+
 // TestConsensus_ShortCircuit_ReleasesLateResponses verifies the cleanup side of
 // the refactor: once the caller has been released on short-circuit, a later
 // non-winning response still has to be released exactly once. Without this,
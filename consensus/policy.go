@@ -25,6 +25,7 @@ type config struct {
 	preferLargerResponses   bool
 	preferHighestValueFor   map[string][]string
 	fireAndForget           bool
+	quorum                  bool
 	maxWaitOnResult         *common.AdaptiveDuration
 	maxWaitOnEmpty          *common.AdaptiveDuration
 	requiredParticipants    []*common.ConsensusRequiredParticipant
@@ -94,6 +95,7 @@ func (b *builder) WithPreferHighestValueFor(m map[string][]string) *builder {
 	return b
 }
 func (b *builder) WithFireAndForget(v bool) *builder { b.cfg.fireAndForget = v; return b }
+func (b *builder) WithQuorum(v bool) *builder        { b.cfg.quorum = v; return b }
 func (b *builder) WithMaxWaitOnResult(d *common.AdaptiveDuration) *builder {
 	b.cfg.maxWaitOnResult = d
 	return b
@@ -859,6 +859,19 @@ var shortCircuitRules = []shortCircuitRule{
 			return false
 		},
 	},
+	{
+		Description: "quorum: winner is non-empty and agreed on by agreementThreshold participants",
+		Reason:      "quorum_reached",
+		Condition: func(w *slotResult, a *consensusAnalysis) bool {
+			// Quorum trades the rest of the fan-out for latency: K matching answers
+			// are enough, whatever the remaining participants would say.
+			if !a.config.quorum || w == nil || w.Result == nil {
+				return false
+			}
+			g := a.groupOf(w)
+			return g != nil && g.ResponseType == ResponseTypeNonEmpty && g.Count >= a.config.agreementThreshold
+		},
+	},
 	{
 		Description: "consensus-valid error meets agreement threshold -> short-circuit to error",
 		Reason:      "consensus_error_threshold",
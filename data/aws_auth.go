@@ -2,13 +2,17 @@ package data
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/erpc/erpc/common"
 )
 
+const defaultAwsRoleSessionName = "erpc"
+
 // createAWSSession builds an AWS session for the given region using the
 // credential source described by auth. Pass auth=nil to use the default
 // credential chain (EC2/ECS instance role, env vars, shared credentials file).
@@ -26,17 +30,82 @@ func createAWSSession(auth *common.AwsAuthConfig, region string) (*session.Sessi
 		return session.NewSession(cfg)
 	}
 
-	var creds *credentials.Credentials
+	creds, err := awsAuthCredentials(auth, cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Credentials = creds
+	return session.NewSession(cfg)
+}
+
+// awsAuthCredentials resolves the credentials for auth. The assumeRole and
+// webIdentity modes call STS through a session built from base (region and
+// HTTP client only, never a service-specific endpoint), and the returned
+// credentials refresh themselves before the temporary keys expire.
+func awsAuthCredentials(auth *common.AwsAuthConfig, base *aws.Config) (*credentials.Credentials, error) {
 	switch auth.Mode {
 	case "file":
-		creds = credentials.NewSharedCredentials(auth.CredentialsFile, auth.Profile)
+		return credentials.NewSharedCredentials(auth.CredentialsFile, auth.Profile), nil
 	case "env":
-		creds = credentials.NewEnvCredentials()
+		return credentials.NewEnvCredentials(), nil
 	case "secret":
-		creds = credentials.NewStaticCredentials(auth.AccessKeyID, auth.SecretAccessKey, "")
+		return credentials.NewStaticCredentials(auth.AccessKeyID, auth.SecretAccessKey, ""), nil
+	case "assumeRole":
+		if auth.RoleArn == "" {
+			return nil, fmt.Errorf("auth.roleArn is required for auth.mode assumeRole")
+		}
+		// The role is assumed with whatever the default chain provides
+		// (instance role, env vars, shared credentials file, IRSA).
+		stsSess, err := session.NewSession(stsBaseConfig(base))
+		if err != nil {
+			return nil, err
+		}
+		return stscreds.NewCredentials(stsSess, auth.RoleArn, func(p *stscreds.AssumeRoleProvider) {
+			p.RoleSessionName = roleSessionName(auth)
+			if auth.ExternalId != "" {
+				p.ExternalID = aws.String(auth.ExternalId)
+			}
+		}), nil
+	case "webIdentity":
+		// EKS IRSA injects AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE into
+		// the pod, so both may be omitted from the config.
+		roleArn := auth.RoleArn
+		if roleArn == "" {
+			roleArn = os.Getenv("AWS_ROLE_ARN")
+		}
+		tokenFile := auth.WebIdentityTokenFile
+		if tokenFile == "" {
+			tokenFile = os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+		}
+		if roleArn == "" || tokenFile == "" {
+			return nil, fmt.Errorf("auth.mode webIdentity requires auth.roleArn and auth.webIdentityTokenFile (or AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE)")
+		}
+		// AssumeRoleWithWebIdentity is an unsigned call, so the STS session
+		// needs no credentials of its own.
+		stsCfg := stsBaseConfig(base)
+		stsCfg.Credentials = credentials.AnonymousCredentials
+		stsSess, err := session.NewSession(stsCfg)
+		if err != nil {
+			return nil, err
+		}
+		return stscreds.NewWebIdentityCredentials(stsSess, roleArn, roleSessionName(auth), tokenFile), nil
 	default:
-		return nil, fmt.Errorf("unsupported auth.mode: %q (must be file, env, or secret)", auth.Mode)
+		return nil, fmt.Errorf("unsupported auth.mode: %q (must be file, env, secret, assumeRole, or webIdentity)", auth.Mode)
 	}
-	cfg.Credentials = creds
-	return session.NewSession(cfg)
+}
+
+func stsBaseConfig(base *aws.Config) *aws.Config {
+	cfg := &aws.Config{}
+	if base != nil {
+		cfg.Region = base.Region
+		cfg.HTTPClient = base.HTTPClient
+	}
+	return cfg
+}
+
+func roleSessionName(auth *common.AwsAuthConfig) string {
+	if auth.RoleSessionName != "" {
+		return auth.RoleSessionName
+	}
+	return defaultAwsRoleSessionName
 }
@@ -0,0 +1,61 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/erpc/erpc/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAwsAuthCredentials(t *testing.T) {
+	base := &aws.Config{Region: aws.String("us-east-1")}
+
+	t.Run("assumeRole requires roleArn", func(t *testing.T) {
+		_, err := awsAuthCredentials(&common.AwsAuthConfig{Mode: "assumeRole"}, base)
+		require.ErrorContains(t, err, "roleArn")
+	})
+
+	t.Run("assumeRole builds an sts provider without calling sts", func(t *testing.T) {
+		creds, err := awsAuthCredentials(&common.AwsAuthConfig{
+			Mode:       "assumeRole",
+			RoleArn:    "arn:aws:iam::123456789012:role/erpc",
+			ExternalId: "ext",
+		}, base)
+		require.NoError(t, err)
+		require.NotNil(t, creds)
+	})
+
+	t.Run("webIdentity falls back to the IRSA env vars", func(t *testing.T) {
+		t.Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/erpc")
+		t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "/var/run/secrets/eks.amazonaws.com/serviceaccount/token")
+		creds, err := awsAuthCredentials(&common.AwsAuthConfig{Mode: "webIdentity"}, base)
+		require.NoError(t, err)
+		require.NotNil(t, creds)
+	})
+
+	t.Run("webIdentity without role or token is rejected", func(t *testing.T) {
+		t.Setenv("AWS_ROLE_ARN", "")
+		t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "")
+		_, err := awsAuthCredentials(&common.AwsAuthConfig{Mode: "webIdentity"}, base)
+		require.ErrorContains(t, err, "webIdentityTokenFile")
+	})
+
+	t.Run("unknown mode is rejected", func(t *testing.T) {
+		_, err := awsAuthCredentials(&common.AwsAuthConfig{Mode: "magic"}, base)
+		require.ErrorContains(t, err, "unsupported auth.mode")
+	})
+
+	t.Run("role session name defaults to erpc", func(t *testing.T) {
+		require.Equal(t, defaultAwsRoleSessionName, roleSessionName(&common.AwsAuthConfig{}))
+		require.Equal(t, "custom", roleSessionName(&common.AwsAuthConfig{RoleSessionName: "custom"}))
+	})
+}
+
+func TestAwsAuthConfigValidate(t *testing.T) {
+	require.NoError(t, (&common.AwsAuthConfig{Mode: "webIdentity"}).Validate("auth"))
+	require.NoError(t, (&common.AwsAuthConfig{Mode: "assumeRole", RoleArn: "arn:aws:iam::1:role/x", ExternalId: "e"}).Validate("auth"))
+	require.ErrorContains(t, (&common.AwsAuthConfig{Mode: "assumeRole"}).Validate("auth"), "auth.roleArn is required")
+	require.ErrorContains(t, (&common.AwsAuthConfig{Mode: "webIdentity", ExternalId: "e"}).Validate("auth"), "externalId")
+	require.ErrorContains(t, (&common.AwsAuthConfig{Mode: "nope"}).Validate("auth"), "auth.mode")
+}
@@ -0,0 +1,892 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	BadgerDriverName = "badger"
+
+	badgerMainPrefix       = "m:"
+	badgerReversePrefix    = "r:"
+	badgerKeySeparator     = "\x00"
+	badgerSchemaVersionKey = "erpc:schema:version"
+
+	// badgerEvictionTarget is the share of maxDiskSize an eviction pass
+	// brings the live entries down to, so passes do not run back to back.
+	badgerEvictionTarget = 0.9
+
+	// badgerGuardedRetries bounds the retries of a guarded write that lost
+	// a transaction conflict to a concurrent write of the same key.
+	badgerGuardedRetries = 3
+)
+
+var _ Connector = (*BadgerConnector)(nil)
+var _ GuardedSetter = (*BadgerConnector)(nil)
+
+// BadgerConnector stores entries in an embedded BadgerDB database. Entries
+// live under "m:<partitionKey>\x00<rangeKey>" with native TTLs. EVM entries
+// also get an empty "r:<rangeKey>\x00<partitionKey>" key with the same TTL,
+// so a wildcard lookup is a reverse prefix iteration. Badger orders keys, so
+// Scan and List page in key order. Locks and counters are local to the
+// process, since the database can only be opened by one.
+type BadgerConnector struct {
+	id          string
+	appCtx      context.Context
+	logger      *zerolog.Logger
+	cfg         *common.BadgerConnectorConfig
+	initializer *util.Initializer
+
+	mu sync.RWMutex
+	db *badger.DB
+
+	maxDiskSize int64
+	locks       sync.Map
+
+	// accessed holds the last Get hit or Set of each main key, in unix
+	// nanoseconds, to evict the least recently used entries first. Only kept
+	// when maxDiskSize is set.
+	accessMu sync.Mutex
+	accessed map[string]int64
+}
+
+func NewBadgerConnector(
+	appCtx context.Context,
+	logger *zerolog.Logger,
+	id string,
+	cfg *common.BadgerConnectorConfig,
+) (*BadgerConnector, error) {
+	lg := logger.With().Str("connector", id).Logger()
+	lg.Debug().Interface("config", cfg).Msg("creating badger connector")
+
+	connector := &BadgerConnector{
+		id:     id,
+		appCtx: appCtx,
+		logger: &lg,
+		cfg:    cfg,
+	}
+	if cfg.MaxDiskSize != "" {
+		size, err := util.ParseByteSize(cfg.MaxDiskSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid badger maxDiskSize: %w", err)
+		}
+		connector.maxDiskSize = int64(size)
+		connector.accessed = make(map[string]int64)
+	}
+
+	connector.initializer = util.NewInitializer(appCtx, &lg, nil)
+	openTask := util.NewBootstrapTask(fmt.Sprintf("badger-open/%s", id), connector.openTask)
+	if err := connector.initializer.ExecuteTasks(appCtx, openTask); err != nil {
+		lg.Error().Err(err).Msg("failed to open badger on first attempt (will retry in background)")
+		return connector, nil
+	}
+
+	return connector, nil
+}
+
+// openTask opens the database, applies the schema migrations and starts the
+// maintenance loop, which closes the database when the app shuts down.
+func (b *BadgerConnector) openTask(ctx context.Context) error {
+	opts := badger.DefaultOptions(b.cfg.Dir).
+		WithLogger(&badgerLogger{logger: b.logger}).
+		WithSyncWrites(b.cfg.SyncWrites)
+	db, err := badger.Open(opts)
+	if err != nil {
+		return fmt.Errorf("failed to open badger database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.InitTimeout.Duration())
+	defer cancel()
+	if err := runSchemaMigrations(ctx, b.logger, b.id, &badgerSchemaVersions{db: db}, badgerSchemaMigrations()); err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	b.mu.Lock()
+	b.db = db
+	b.mu.Unlock()
+	go b.maintenanceLoop(db)
+
+	b.logger.Info().Str("dir", b.cfg.Dir).Str("maxDiskSize", b.cfg.MaxDiskSize).Msg("successfully opened badger database")
+	return nil
+}
+
+// badgerSchemaMigrations lists the changes to the key layout in order. Never
+// edit or reorder a released migration; append a new one instead.
+func badgerSchemaMigrations() []SchemaMigration {
+	return []SchemaMigration{
+		{
+			// Main and reverse index keys as described on BadgerConnector;
+			// nothing to change, the version marks where later layouts start.
+			Version:     1,
+			Description: "baseline key layout",
+			Apply:       func(ctx context.Context) error { return nil },
+		},
+	}
+}
+
+type badgerSchemaVersions struct {
+	db *badger.DB
+}
+
+func (s *badgerSchemaVersions) SchemaVersion(ctx context.Context) (int, error) {
+	var raw string
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(badgerSchemaVersionKey))
+		if err != nil {
+			return err
+		}
+		value, err := item.ValueCopy(nil)
+		raw = string(value)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return parseSchemaVersion(raw)
+}
+
+func (s *badgerSchemaVersions) SetSchemaVersion(ctx context.Context, version int) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(badgerSchemaVersionKey), []byte(fmt.Sprintf("%d", version)))
+	})
+}
+
+// badgerLogger forwards badger's logs to zerolog. Badger is chatty at info
+// level, so its info logs are debug logs here.
+type badgerLogger struct {
+	logger *zerolog.Logger
+}
+
+func (l *badgerLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Error().Msg(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l *badgerLogger) Warningf(format string, args ...interface{}) {
+	l.logger.Warn().Msg(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l *badgerLogger) Infof(format string, args ...interface{}) {
+	l.logger.Debug().Msg(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l *badgerLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Trace().Msg(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+// maintenanceLoop runs value log GC every gcInterval and, with maxDiskSize
+// set, eviction every evictionInterval, until the app shuts down.
+func (b *BadgerConnector) maintenanceLoop(db *badger.DB) {
+	gcTicker := util.NewTicker(b.cfg.GCInterval.Duration())
+	defer gcTicker.Stop()
+	var evictC <-chan time.Time
+	if b.maxDiskSize > 0 {
+		evictTicker := util.NewTicker(b.cfg.EvictionInterval.Duration())
+		defer evictTicker.Stop()
+		evictC = evictTicker.C()
+	}
+
+	for {
+		select {
+		case <-b.appCtx.Done():
+			if err := db.Close(); err != nil {
+				b.logger.Error().Err(err).Msg("failed to close badger database")
+			}
+			return
+		case <-gcTicker.C():
+			b.runValueLogGC(db)
+		case <-evictC:
+			if err := b.evict(db); err != nil {
+				b.logger.Warn().Err(err).Msg("failed to evict badger entries")
+			}
+		}
+	}
+}
+
+// runValueLogGC rewrites value log files until none has enough stale data.
+func (b *BadgerConnector) runValueLogGC(db *badger.DB) {
+	rewritten := 0
+	for b.appCtx.Err() == nil {
+		if err := db.RunValueLogGC(b.cfg.GCDiscardRatio); err != nil {
+			if !errors.Is(err, badger.ErrNoRewrite) {
+				b.logger.Warn().Err(err).Msg("badger value log GC failed")
+			}
+			break
+		}
+		rewritten++
+	}
+	lsm, vlog := db.Size()
+	telemetry.MetricConnectorDiskBytes.WithLabelValues(b.id, "lsm").Set(float64(lsm))
+	telemetry.MetricConnectorDiskBytes.WithLabelValues(b.id, "vlog").Set(float64(vlog))
+	b.logger.Debug().Int("rewrittenFiles", rewritten).Int64("lsmBytes", lsm).Int64("vlogBytes", vlog).Msg("badger value log GC finished")
+}
+
+// badgerEvictionCandidate is a main key considered for eviction.
+type badgerEvictionCandidate struct {
+	key        []byte
+	size       int64
+	accessedAt int64
+	version    uint64
+}
+
+// sortForEviction orders candidates least recently used first. Keys not
+// accessed since the process started come first, oldest write first.
+func sortForEviction(candidates []badgerEvictionCandidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].accessedAt != candidates[j].accessedAt {
+			return candidates[i].accessedAt < candidates[j].accessedAt
+		}
+		return candidates[i].version < candidates[j].version
+	})
+}
+
+// evict sums the estimated size of the live keys and, above maxDiskSize,
+// deletes the least recently used entries until the live size is back under
+// badgerEvictionTarget of it. The files shrink later, through compaction and
+// value log GC.
+func (b *BadgerConnector) evict(db *badger.DB) error {
+	started := time.Now().UnixNano()
+	var live int64
+	var candidates []badgerEvictionCandidate
+	err := db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			size := item.EstimatedSize()
+			live += size
+			if !strings.HasPrefix(string(item.Key()), badgerMainPrefix) {
+				continue
+			}
+			key := item.KeyCopy(nil)
+			b.accessMu.Lock()
+			accessedAt := b.accessed[string(key)]
+			b.accessMu.Unlock()
+			candidates = append(candidates, badgerEvictionCandidate{
+				key:        key,
+				size:       size,
+				accessedAt: accessedAt,
+				version:    item.Version(),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	telemetry.MetricConnectorDiskBytes.WithLabelValues(b.id, "live").Set(float64(live))
+
+	// Drop the access times of keys that expired since, leaving those of
+	// keys written while iterating.
+	seen := make(map[string]struct{}, len(candidates))
+	for _, c := range candidates {
+		if c.accessedAt > 0 {
+			seen[string(c.key)] = struct{}{}
+		}
+	}
+	b.accessMu.Lock()
+	for k, at := range b.accessed {
+		if _, ok := seen[k]; !ok && at < started {
+			delete(b.accessed, k)
+		}
+	}
+	b.accessMu.Unlock()
+
+	if live <= b.maxDiskSize {
+		return nil
+	}
+	target := int64(float64(b.maxDiskSize) * badgerEvictionTarget)
+	sortForEviction(candidates)
+
+	wb := db.NewWriteBatch()
+	defer wb.Cancel()
+	evicted := 0
+	for _, c := range candidates {
+		if live <= target {
+			break
+		}
+		if err := wb.Delete(c.key); err != nil {
+			return err
+		}
+		if partitionKey, rangeKey, ok := splitBadgerKey(c.key, badgerMainPrefix); ok && badgerReverseIndexed(partitionKey) {
+			if err := wb.Delete(badgerReverseKey(partitionKey, rangeKey)); err != nil {
+				return err
+			}
+		}
+		b.forget(string(c.key))
+		live -= c.size
+		evicted++
+	}
+	if err := wb.Flush(); err != nil {
+		return err
+	}
+	telemetry.MetricConnectorEvictionsTotal.WithLabelValues(b.id).Add(float64(evicted))
+	b.logger.Info().Int("evicted", evicted).Int64("liveBytes", live).Int64("maxDiskSize", b.maxDiskSize).Msg("evicted least recently used badger entries")
+	return nil
+}
+
+// touch records an access to a main key for LRU eviction.
+func (b *BadgerConnector) touch(key []byte) {
+	if b.accessed == nil {
+		return
+	}
+	now := time.Now().UnixNano()
+	b.accessMu.Lock()
+	b.accessed[string(key)] = now
+	b.accessMu.Unlock()
+}
+
+func (b *BadgerConnector) forget(key string) {
+	if b.accessed == nil {
+		return
+	}
+	b.accessMu.Lock()
+	delete(b.accessed, key)
+	b.accessMu.Unlock()
+}
+
+func badgerMainKey(partitionKey, rangeKey string) []byte {
+	return []byte(badgerMainPrefix + partitionKey + badgerKeySeparator + rangeKey)
+}
+
+func badgerReverseKey(partitionKey, rangeKey string) []byte {
+	return []byte(badgerReversePrefix + rangeKey + badgerKeySeparator + partitionKey)
+}
+
+// splitBadgerKey returns the two parts of a main or reverse key, in key
+// order: partition and range key for main keys, range and partition key for
+// reverse keys.
+func splitBadgerKey(key []byte, prefix string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(string(key), prefix)
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, badgerKeySeparator)
+}
+
+// badgerReverseIndexed reports whether an entry also gets a reverse index
+// key: concrete EVM partition keys, as for Redis.
+func badgerReverseIndexed(partitionKey string) bool {
+	return strings.HasPrefix(partitionKey, "evm:") && !strings.HasSuffix(partitionKey, "*")
+}
+
+// badgerExpiresAt returns the unix second an entry written now with ttl
+// expires at, rounded up so short TTLs do not expire at once, or 0 if it
+// never expires.
+func badgerExpiresAt(now time.Time, ttl *time.Duration) uint64 {
+	if ttl == nil || *ttl <= 0 {
+		return 0
+	}
+	at := now.Add(*ttl)
+	seconds := at.Unix()
+	if at.Nanosecond() > 0 {
+		seconds++
+	}
+	return uint64(seconds)
+}
+
+// getDB returns the open database, or an error if it is not open yet.
+func (b *BadgerConnector) getDB() (*badger.DB, error) {
+	if state := b.initializer.State(); state != util.StateReady {
+		return nil, fmt.Errorf("badger is not open (state: %s), errors: %v", state.String(), b.initializer.Errors())
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.db == nil {
+		return nil, fmt.Errorf("badger database not opened yet")
+	}
+	if b.db.IsClosed() {
+		return nil, fmt.Errorf("badger database is closed")
+	}
+	return b.db, nil
+}
+
+func (b *BadgerConnector) Id() string {
+	return b.id
+}
+
+func (b *BadgerConnector) State() ConnectorState {
+	return initializerConnectorState(b.initializer)
+}
+
+func (b *BadgerConnector) Ping(ctx context.Context) error {
+	_, err := b.getDB()
+	return err
+}
+
+func (b *BadgerConnector) setEntries(partitionKey, rangeKey string, value []byte, expiresAt uint64) []*badger.Entry {
+	entries := []*badger.Entry{{Key: badgerMainKey(partitionKey, rangeKey), Value: value, ExpiresAt: expiresAt}}
+	if badgerReverseIndexed(partitionKey) {
+		entries = append(entries, &badger.Entry{Key: badgerReverseKey(partitionKey, rangeKey), ExpiresAt: expiresAt})
+	}
+	return entries
+}
+
+// Set writes the entry and, for EVM keys, its reverse index key in one
+// transaction with the same expiry.
+func (b *BadgerConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	ctx, span := common.StartSpan(ctx, "BadgerConnector.Set")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+		)
+	}
+
+	db, err := b.getDB()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	b.logger.Debug().Int("len", len(value)).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Interface("ttl", ttl).Msg("writing item to badger")
+
+	// Badger keeps the value slice until the write is committed; the caller
+	// may reuse it after Set returns, so it is copied.
+	entries := b.setEntries(partitionKey, rangeKey, append([]byte(nil), value...), badgerExpiresAt(time.Now(), ttl))
+	err = db.Update(func(txn *badger.Txn) error {
+		for _, e := range entries {
+			if err := txn.SetEntry(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	b.touch(entries[0].Key)
+	return nil
+}
+
+// SetGuarded reads the stored guard and writes the entry in one transaction.
+// Badger aborts it with ErrConflict when a concurrent transaction wrote the
+// key in between, and it is retried. The guard is kept in front of the
+// value, as for the memory connector.
+func (b *BadgerConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	ctx, span := common.StartSpan(ctx, "BadgerConnector.SetGuarded")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+			attribute.Int64("guard", guard),
+		)
+	}
+
+	db, err := b.getDB()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+
+	b.logger.Debug().Int("len", len(value)).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int64("guard", guard).Interface("ttl", ttl).Msg("writing guarded item to badger")
+
+	expiresAt := badgerExpiresAt(time.Now(), ttl)
+	key := badgerMainKey(partitionKey, rangeKey)
+	var stored bool
+	for attempt := 0; ; attempt++ {
+		stored = false
+		err = db.Update(func(txn *badger.Txn) error {
+			item, err := txn.Get(key)
+			if err == nil {
+				current, err := item.ValueCopy(nil)
+				if err != nil {
+					return err
+				}
+				if _, currentGuard := unwrapGuardedValue(current); currentGuard > guard {
+					return nil
+				}
+			} else if !errors.Is(err, badger.ErrKeyNotFound) {
+				return err
+			}
+			// A transaction keeps its entries, so each attempt builds its own.
+			for _, e := range b.setEntries(partitionKey, rangeKey, wrapGuardedValue(guard, value), expiresAt) {
+				if err := txn.SetEntry(e); err != nil {
+					return err
+				}
+			}
+			stored = true
+			return nil
+		})
+		if !errors.Is(err, badger.ErrConflict) || attempt >= badgerGuardedRetries {
+			break
+		}
+	}
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+	if stored {
+		b.touch(key)
+	}
+	return stored, nil
+}
+
+// SetMany writes all items through one write batch, which Badger splits into
+// transactions as needed.
+func (b *BadgerConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	ctx, span := common.StartSpan(ctx, "BadgerConnector.SetMany")
+	defer span.End()
+	span.SetAttributes(attribute.Int("items", len(items)))
+
+	db, err := b.getDB()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	items = uniqueKeyValuePairs(items)
+	expiresAt := badgerExpiresAt(time.Now(), ttl)
+	wb := db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, item := range items {
+		for _, e := range b.setEntries(item.PartitionKey, item.RangeKey, append([]byte(nil), item.Value...), expiresAt) {
+			if err := wb.SetEntry(e); err != nil {
+				common.SetTraceSpanError(span, err)
+				return err
+			}
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	for _, item := range items {
+		b.touch(badgerMainKey(item.PartitionKey, item.RangeKey))
+	}
+	return nil
+}
+
+// Get reads an entry by its keys or, on the reverse index, the entry with the
+// given range key whose partition key matches partitionKey (a trailing "*"
+// matches a prefix). When several match, the greatest partition key wins,
+// as with DynamoDB.
+func (b *BadgerConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
+	ctx, span := common.StartSpan(ctx, "BadgerConnector.Get")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("index", index),
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+		)
+	}
+
+	db, err := b.getDB()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	if index == ConnectorReverseIndex && (rangeKey == "" || strings.HasSuffix(rangeKey, "*")) {
+		err := fmt.Errorf("when using reverse index rangeKey must be a non-empty string and not contain wildcards (rangeKey: '%s', partitionKey: '%s')", rangeKey, partitionKey)
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	b.logger.Debug().Str("index", index).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("getting item from badger")
+	var key, value []byte
+	err = db.View(func(txn *badger.Txn) error {
+		if index == ConnectorReverseIndex && (partitionKey == "" || strings.HasSuffix(partitionKey, "*")) {
+			k, v, err := b.getReverse(txn, strings.TrimSuffix(partitionKey, "*"), rangeKey)
+			key, value = k, v
+			return err
+		}
+		key = badgerMainKey(partitionKey, rangeKey)
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			err = common.NewErrRecordNotFound(partitionKey, rangeKey, BadgerDriverName)
+		}
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+	b.touch(key)
+	value, _ = unwrapGuardedValue(value)
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(attribute.Int("value_size", len(value)))
+	}
+	return value, nil
+}
+
+// getReverse iterates the reverse index keys of rangeKey whose partition key
+// starts with prefix, greatest first, and returns the first whose main entry
+// still exists.
+func (b *BadgerConnector) getReverse(txn *badger.Txn, prefix, rangeKey string) ([]byte, []byte, error) {
+	seek := badgerReverseKey(prefix, rangeKey)
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Reverse = true
+	opts.Prefix = seek
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	// 0xFF never appears in UTF-8, so it sorts after every key with the prefix.
+	for it.Seek(append(append([]byte(nil), seek...), 0xFF)); it.ValidForPrefix(seek); it.Next() {
+		_, partitionKey, ok := splitBadgerKey(it.Item().Key(), badgerReversePrefix)
+		if !ok {
+			continue
+		}
+		key := badgerMainKey(partitionKey, rangeKey)
+		item, err := txn.Get(key)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := item.ValueCopy(nil)
+		return key, value, err
+	}
+	return nil, nil, badger.ErrKeyNotFound
+}
+
+func (b *BadgerConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	ctx, span := common.StartSpan(ctx, "BadgerConnector.Delete")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+		)
+	}
+
+	db, err := b.getDB()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	b.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("deleting item from badger")
+
+	key := badgerMainKey(partitionKey, rangeKey)
+	err = db.Update(func(txn *badger.Txn) error {
+		if err := txn.Delete(key); err != nil {
+			return err
+		}
+		if badgerReverseIndexed(partitionKey) {
+			return txn.Delete(badgerReverseKey(partitionKey, rangeKey))
+		}
+		return nil
+	})
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	b.forget(string(key))
+	return nil
+}
+
+// DeleteByPrefix scans the main keys with the prefix and deletes them one
+// by one, with their reverse index keys.
+func (b *BadgerConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return deleteByScan(ctx, b.Scan, b.Delete, partitionKeyPrefix)
+}
+
+func (b *BadgerConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "BadgerConnector.List")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("index", index),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	items, next, err := b.iteratePage(ctx, index == ConnectorReverseIndex, "", "", limit, paginationToken)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return items, next, err
+}
+
+// Scan pages through the main keys in (partition key, range key) order. The
+// partition key prefix bounds the iteration; the range key prefix is
+// filtered while iterating.
+func (b *BadgerConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "BadgerConnector.Scan")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key_prefix", partitionKeyPrefix),
+			attribute.String("range_key_prefix", rangeKeyPrefix),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	if err := validateScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+
+	items, next, err := b.iteratePage(ctx, false, partitionKeyPrefix, rangeKeyPrefix, limit, cursor)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return items, next, err
+}
+
+// iteratePage returns up to limit entries after cursor, iterating the main
+// keys or, with reverse, the reverse index keys and their main entries. A
+// full page returns a cursor to its last entry; a short one ends the scan.
+func (b *BadgerConnector) iteratePage(ctx context.Context, reverse bool, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	db, err := b.getDB()
+	if err != nil {
+		return nil, "", err
+	}
+	after, err := decodeScanCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	prefix := []byte(badgerMainPrefix + partitionKeyPrefix)
+	if reverse {
+		prefix = []byte(badgerReversePrefix)
+	}
+	var results []KeyValuePair
+	err = db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = !reverse
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		it.Seek(prefix)
+		var afterKey []byte
+		if after != nil {
+			afterKey = badgerMainKey(after.PartitionKey, after.RangeKey)
+			if reverse {
+				afterKey = badgerReverseKey(after.PartitionKey, after.RangeKey)
+			}
+			it.Seek(afterKey)
+		}
+		for ; it.ValidForPrefix(prefix); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			item := it.Item()
+			if afterKey != nil && string(item.Key()) == string(afterKey) {
+				continue
+			}
+
+			var partitionKey, rangeKey string
+			var value []byte
+			if reverse {
+				rk, pk, ok := splitBadgerKey(item.Key(), badgerReversePrefix)
+				if !ok {
+					continue
+				}
+				main, err := txn.Get(badgerMainKey(pk, rk))
+				if errors.Is(err, badger.ErrKeyNotFound) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				if value, err = main.ValueCopy(nil); err != nil {
+					return err
+				}
+				partitionKey, rangeKey = pk, rk
+			} else {
+				pk, rk, ok := splitBadgerKey(item.Key(), badgerMainPrefix)
+				if !ok || !strings.HasPrefix(rk, rangeKeyPrefix) {
+					continue
+				}
+				if value, err = item.ValueCopy(nil); err != nil {
+					return err
+				}
+				partitionKey, rangeKey = pk, rk
+			}
+
+			results = append(results, KeyValuePair{PartitionKey: partitionKey, RangeKey: rangeKey, Value: value})
+			if limit > 0 && len(results) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	results = unwrapGuardedValues(results)
+	if limit <= 0 || len(results) < limit {
+		return results, "", nil
+	}
+	last := results[len(results)-1]
+	next, err := encodeScanCursor(last.PartitionKey, last.RangeKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return results, next, nil
+}
+
+// Lock takes a process-local lock: only one process can open the database,
+// so no other instance can contend for it. ttl is not enforced, as for the
+// memory connector.
+func (b *BadgerConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	value, _ := b.locks.LoadOrStore(key, &sync.Mutex{})
+	mutex := value.(*sync.Mutex)
+
+	tryInterval := 2 * time.Millisecond
+	for {
+		if mutex.TryLock() {
+			return &memoryLock{mutex: mutex}, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("lock acquisition cancelled or timed out for key '%s': %w", key, ctx.Err())
+		case <-util.After(tryInterval):
+			if tryInterval < 20*time.Millisecond {
+				tryInterval += 1 * time.Millisecond
+			}
+		}
+	}
+}
+
+// WatchCounterInt64 is a no-op: counters are only shared within the process,
+// as for the memory connector.
+func (b *BadgerConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	ch := make(chan CounterInt64State)
+	return ch, func() {}, nil
+}
+
+// PublishCounterInt64 is a no-op, see WatchCounterInt64.
+func (b *BadgerConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return nil
+}
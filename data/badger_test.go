@@ -0,0 +1,178 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBadgerConnector(t *testing.T, maxDiskSize string) *BadgerConnector {
+	t.Helper()
+	logger := zerolog.New(io.Discard)
+	// Cleanups run last-registered first: close the database before its
+	// directory is removed.
+	cfg := &common.BadgerConnectorConfig{Dir: t.TempDir(), MaxDiskSize: maxDiskSize}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	require.NoError(t, cfg.SetDefaults())
+	connector, err := NewBadgerConnector(ctx, &logger, "badger-test", cfg)
+	require.NoError(t, err)
+	require.Equal(t, ConnectorStateHealthy, connector.State())
+	return connector
+}
+
+func TestBadgerConnector_SetGet(t *testing.T) {
+	connector := newTestBadgerConnector(t, "")
+	ctx := context.Background()
+
+	require.NoError(t, connector.Set(ctx, "evm:1:100", "eth_getBlockByNumber:a", []byte("block"), nil))
+	value, err := connector.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:a", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("block"), value)
+
+	_, err = connector.Get(ctx, ConnectorMainIndex, "evm:1:100", "missing", nil)
+	assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+
+	require.NoError(t, connector.Delete(ctx, "evm:1:100", "eth_getBlockByNumber:a"))
+	_, err = connector.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:a", nil)
+	assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+}
+
+func TestBadgerConnector_TTL(t *testing.T) {
+	connector := newTestBadgerConnector(t, "")
+	ctx := context.Background()
+
+	ttl := time.Second
+	require.NoError(t, connector.Set(ctx, "pk", "rk", []byte("v"), &ttl))
+	_, err := connector.Get(ctx, ConnectorMainIndex, "pk", "rk", nil)
+	require.NoError(t, err)
+
+	time.Sleep(2100 * time.Millisecond)
+	_, err = connector.Get(ctx, ConnectorMainIndex, "pk", "rk", nil)
+	assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+}
+
+func TestBadgerConnector_ReverseIndex(t *testing.T) {
+	connector := newTestBadgerConnector(t, "")
+	ctx := context.Background()
+
+	require.NoError(t, connector.Set(ctx, "evm:1:0xaa", "tx:0x1", []byte("aa"), nil))
+	require.NoError(t, connector.Set(ctx, "evm:1:0xbb", "tx:0x1", []byte("bb"), nil))
+	require.NoError(t, connector.Set(ctx, "evm:2:0xcc", "tx:0x1", []byte("cc"), nil))
+
+	value, err := connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "tx:0x1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bb"), value, "the greatest matching partition key wins")
+
+	value, err = connector.Get(ctx, ConnectorReverseIndex, "evm:2:*", "tx:0x1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cc"), value)
+
+	require.NoError(t, connector.Delete(ctx, "evm:1:0xbb", "tx:0x1"))
+	value, err = connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "tx:0x1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("aa"), value)
+
+	_, err = connector.Get(ctx, ConnectorReverseIndex, "evm:3:*", "tx:0x1", nil)
+	assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+}
+
+func TestBadgerConnector_Scan(t *testing.T) {
+	connector := newTestBadgerConnector(t, "")
+	ctx := context.Background()
+
+	items := make([]KeyValuePair, 0, 10)
+	for i := 0; i < 10; i++ {
+		items = append(items, KeyValuePair{PartitionKey: fmt.Sprintf("evm:1:%d", i), RangeKey: "a", Value: []byte{byte(i)}})
+	}
+	items = append(items, KeyValuePair{PartitionKey: "evm:1:0", RangeKey: "b", Value: []byte("b")})
+	items = append(items, KeyValuePair{PartitionKey: "evm:2:0", RangeKey: "a", Value: []byte("other")})
+	require.NoError(t, connector.SetMany(ctx, items, nil))
+
+	var all []KeyValuePair
+	cursor := ""
+	for {
+		page, next, err := connector.Scan(ctx, "evm:1:", "a", 3, cursor)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), 3)
+		all = append(all, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	require.Len(t, all, 10)
+	for i, item := range all {
+		assert.Equal(t, fmt.Sprintf("evm:1:%d", i), item.PartitionKey)
+		assert.Equal(t, "a", item.RangeKey)
+	}
+
+	deleted, err := connector.DeleteByPrefix(ctx, "evm:1:")
+	require.NoError(t, err)
+	assert.Equal(t, 11, deleted)
+	value, err := connector.Get(ctx, ConnectorMainIndex, "evm:2:0", "a", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("other"), value)
+}
+
+func TestBadgerConnector_Eviction(t *testing.T) {
+	connector := newTestBadgerConnector(t, "64KB")
+	ctx := context.Background()
+
+	value := make([]byte, 1024)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, connector.Set(ctx, "pk", fmt.Sprintf("rk-%03d", i), value, nil))
+	}
+	// Read the first entry so it is the most recently used.
+	_, err := connector.Get(ctx, ConnectorMainIndex, "pk", "rk-000", nil)
+	require.NoError(t, err)
+
+	db, err := connector.getDB()
+	require.NoError(t, err)
+	require.NoError(t, connector.evict(db))
+
+	_, err = connector.Get(ctx, ConnectorMainIndex, "pk", "rk-000", nil)
+	assert.NoError(t, err, "the most recently used entry is kept")
+	_, err = connector.Get(ctx, ConnectorMainIndex, "pk", "rk-001", nil)
+	assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), "the least recently used entry is evicted")
+	_, err = connector.Get(ctx, ConnectorMainIndex, "pk", "rk-099", nil)
+	assert.NoError(t, err)
+
+	items, _, err := connector.Scan(ctx, "pk", "", 1000, "")
+	require.NoError(t, err)
+	assert.Less(t, len(items), 64)
+	assert.Greater(t, len(items), 40)
+}
+
+func TestBadgerExpiresAt(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	ttl := func(d time.Duration) *time.Duration { return &d }
+
+	assert.Equal(t, uint64(0), badgerExpiresAt(now, nil))
+	assert.Equal(t, uint64(0), badgerExpiresAt(now, ttl(0)))
+	assert.Equal(t, uint64(1700000001), badgerExpiresAt(now, ttl(time.Millisecond)), "sub-second TTLs round up rather than expiring at once")
+	assert.Equal(t, uint64(1700000060), badgerExpiresAt(now, ttl(time.Minute)))
+}
+
+func TestSplitBadgerKey(t *testing.T) {
+	pk, rk, ok := splitBadgerKey(badgerMainKey("evm:1:0xaa", "tx:0x1"), badgerMainPrefix)
+	require.True(t, ok)
+	assert.Equal(t, "evm:1:0xaa", pk)
+	assert.Equal(t, "tx:0x1", rk)
+
+	rk, pk, ok = splitBadgerKey(badgerReverseKey("evm:1:0xaa", "tx:0x1"), badgerReversePrefix)
+	require.True(t, ok)
+	assert.Equal(t, "evm:1:0xaa", pk)
+	assert.Equal(t, "tx:0x1", rk)
+
+	_, _, ok = splitBadgerKey([]byte(badgerSchemaVersionKey), badgerMainPrefix)
+	assert.False(t, ok)
+}
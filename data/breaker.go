@@ -0,0 +1,228 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/failsafe"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+)
+
+// BreakerConnector fails reads and writes immediately while the backend
+// keeps failing, instead of letting every request wait for it to time out.
+// It opens after FailureThreshold consecutive transport failures or
+// timeouts; after ProbeInterval one operation, or a background Ping when no
+// operation comes first, is let through, and a success closes it again.
+// Misses and other answers from the backend count as successes. See
+// common.ConnectorCircuitBreakerConfig.
+type BreakerConnector struct {
+	wrapped       Connector
+	logger        *zerolog.Logger
+	breaker       *failsafe.Breaker
+	probeInterval time.Duration
+}
+
+var _ Connector = (*BreakerConnector)(nil)
+var _ CacheHeadReporter = (*BreakerConnector)(nil)
+var _ GuardedSetter = (*BreakerConnector)(nil)
+
+func NewBreakerConnector(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	wrapped Connector,
+	cfg *common.ConnectorCircuitBreakerConfig,
+) *BreakerConnector {
+	lg := logger.With().Str("component", "breakerConnector").Str("connectorId", wrapped.Id()).Logger()
+	threshold := cfg.FailureThreshold
+	if threshold <= 0 {
+		threshold = 5
+	}
+	probeInterval := cfg.ProbeInterval.Duration()
+	if probeInterval <= 0 {
+		probeInterval = 10 * time.Second
+	}
+	b := &BreakerConnector{
+		wrapped: wrapped,
+		logger:  &lg,
+		// A window as long as the threshold opens only when every one of
+		// the last FailureThreshold operations failed, and one successful
+		// trial closes it.
+		breaker: failsafe.NewBreaker(&common.CircuitBreakerPolicyConfig{
+			FailureThresholdCount:    uint(threshold), // #nosec G115 -- validated to be positive
+			FailureThresholdCapacity: uint(threshold), // #nosec G115 -- validated to be positive
+			HalfOpenAfter:            common.Duration(probeInterval),
+			SuccessThresholdCount:    1,
+			SuccessThresholdCapacity: 1,
+		}, &lg),
+		probeInterval: probeInterval,
+	}
+	b.breaker.OnTransition = func(_, to failsafe.State, _ string) {
+		b.reportState(to)
+	}
+	b.reportState(failsafe.StateClosed)
+	go b.probe(ctx)
+	return b
+}
+
+var breakerStates = []failsafe.State{failsafe.StateClosed, failsafe.StateOpen, failsafe.StateHalfOpen}
+
+func (b *BreakerConnector) reportState(current failsafe.State) {
+	for _, s := range breakerStates {
+		v := 0.0
+		if s == current {
+			v = 1
+		}
+		telemetry.MetricConnectorBreakerState.WithLabelValues(b.wrapped.Id(), s.String()).Set(v)
+	}
+}
+
+// probe pings the backend every ProbeInterval while the breaker is open, so
+// it closes once the backend is back even when no operation arrives to try.
+func (b *BreakerConnector) probe(ctx context.Context) {
+	ticker := util.NewTicker(b.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+		if b.breaker.State() != failsafe.StateOpen || !b.breaker.TryAcquirePermit() {
+			continue
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, b.probeInterval)
+		err := b.wrapped.Ping(pingCtx)
+		cancel()
+		if err != nil {
+			b.logger.Debug().Err(err).Msg("connector is still unreachable, keeping circuit breaker open")
+			b.breaker.Record(failsafe.OutcomeFailure)
+		} else {
+			b.breaker.Record(failsafe.OutcomeSuccess)
+		}
+	}
+}
+
+// connectorBreakerOutcome classifies the result of an operation. Transport
+// errors and timeouts of the backend are failures; any other answer, misses
+// included, shows the backend is reachable. An operation abandoned by its
+// caller says nothing about the backend.
+func connectorBreakerOutcome(ctx context.Context, err error) failsafe.Outcome {
+	switch {
+	case err == nil:
+		return failsafe.OutcomeSuccess
+	case ctx.Err() != nil:
+		return failsafe.OutcomeIgnore
+	case isTransportError(err), errors.Is(err, context.DeadlineExceeded):
+		return failsafe.OutcomeFailure
+	default:
+		return failsafe.OutcomeSuccess
+	}
+}
+
+func (b *BreakerConnector) run(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	if !b.breaker.TryAcquirePermit() {
+		telemetry.MetricConnectorBreakerRejectedTotal.WithLabelValues(b.wrapped.Id(), operation).Inc()
+		startTime := time.Now()
+		return common.NewErrFailsafeCircuitBreakerOpen(scopeConnector, failsafe.ErrCircuitOpen, &startTime)
+	}
+	err := fn(ctx)
+	o := connectorBreakerOutcome(ctx, err)
+	if o == failsafe.OutcomeIgnore && b.breaker.State() == failsafe.StateHalfOpen {
+		// The trial permit must be given back, and an unknown outcome keeps
+		// the breaker open until the next probe.
+		o = failsafe.OutcomeFailure
+	}
+	b.breaker.Record(o)
+	return err
+}
+
+func (b *BreakerConnector) Id() string {
+	return b.wrapped.Id()
+}
+
+// State is degraded while the breaker is not closed.
+func (b *BreakerConnector) State() ConnectorState {
+	if b.breaker.State() != failsafe.StateClosed {
+		return ConnectorStateDegraded
+	}
+	return b.wrapped.State()
+}
+
+func (b *BreakerConnector) Ping(ctx context.Context) error {
+	return b.wrapped.Ping(ctx)
+}
+
+func (b *BreakerConnector) CacheLatestBlockTimestamp(networkId string) (int64, bool) {
+	if r, ok := b.wrapped.(CacheHeadReporter); ok {
+		return r.CacheLatestBlockTimestamp(networkId)
+	}
+	return 0, false
+}
+
+func (b *BreakerConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error) {
+	var value []byte
+	err := b.run(ctx, "get", func(ctx context.Context) error {
+		var err error
+		value, err = b.wrapped.Get(ctx, index, partitionKey, rangeKey, metadata)
+		return err
+	})
+	return value, err
+}
+
+func (b *BreakerConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	return b.run(ctx, "set", func(ctx context.Context) error {
+		return b.wrapped.Set(ctx, partitionKey, rangeKey, value, ttl)
+	})
+}
+
+func (b *BreakerConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	var stored bool
+	err := b.run(ctx, "set", func(ctx context.Context) error {
+		var err error
+		stored, err = SetGuarded(ctx, b.wrapped, partitionKey, rangeKey, value, guard, ttl)
+		return err
+	})
+	return stored, err
+}
+
+func (b *BreakerConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	return b.run(ctx, "set", func(ctx context.Context) error {
+		return b.wrapped.SetMany(ctx, items, ttl)
+	})
+}
+
+func (b *BreakerConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	return b.run(ctx, "delete", func(ctx context.Context) error {
+		return b.wrapped.Delete(ctx, partitionKey, rangeKey)
+	})
+}
+
+// DeleteByPrefix, List and Scan are admin operations whose callers want the
+// backend's own error, so they are not gated by the breaker.
+func (b *BreakerConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return b.wrapped.DeleteByPrefix(ctx, partitionKeyPrefix)
+}
+
+func (b *BreakerConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	return b.wrapped.List(ctx, index, limit, paginationToken)
+}
+
+func (b *BreakerConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	return b.wrapped.Scan(ctx, partitionKeyPrefix, rangeKeyPrefix, limit, cursor)
+}
+
+func (b *BreakerConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	return b.wrapped.Lock(ctx, key, ttl)
+}
+
+func (b *BreakerConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	return b.wrapped.WatchCounterInt64(ctx, key)
+}
+
+func (b *BreakerConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return b.wrapped.PublishCounterInt64(ctx, key, value)
+}
@@ -0,0 +1,120 @@
+package data
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/failsafe"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableConnector fails with a transport error while down is set.
+type unreachableConnector struct {
+	*MockConnector
+	down  atomic.Bool
+	calls atomic.Int32
+	pings atomic.Int32
+}
+
+func (u *unreachableConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error) {
+	u.calls.Add(1)
+	if u.down.Load() {
+		return nil, syscall.ECONNREFUSED
+	}
+	return nil, common.NewErrRecordNotFound(partitionKey, rangeKey, "test")
+}
+
+func (u *unreachableConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	u.calls.Add(1)
+	if u.down.Load() {
+		return syscall.ECONNREFUSED
+	}
+	return nil
+}
+
+func (u *unreachableConnector) Ping(ctx context.Context) error {
+	u.pings.Add(1)
+	if u.down.Load() {
+		return syscall.ECONNREFUSED
+	}
+	return nil
+}
+
+func TestBreakerConnector(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &common.ConnectorCircuitBreakerConfig{
+		FailureThreshold: 3,
+		ProbeInterval:    common.Duration(10 * time.Second),
+	}
+
+	t.Run("OpensAfterConsecutiveFailuresAndBypasses", func(t *testing.T) {
+		backend := &unreachableConnector{MockConnector: NewMockConnector("test")}
+		backend.down.Store(true)
+		b := NewBreakerConnector(ctx, &logger, backend, cfg)
+
+		for i := 0; i < 3; i++ {
+			_, err := b.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+			require.ErrorIs(t, err, syscall.ECONNREFUSED)
+		}
+		assert.Equal(t, ConnectorStateDegraded, b.State())
+
+		_, err := b.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeFailsafeCircuitBreakerOpen))
+		err = b.Set(ctx, "evm:1:100", "eth_call:h", []byte(`"0x1"`), nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeFailsafeCircuitBreakerOpen), "reads and writes share the breaker")
+		assert.Equal(t, int32(3), backend.calls.Load(), "operations are not sent to the backend while open")
+	})
+
+	t.Run("MissesAndSuccessesKeepItClosed", func(t *testing.T) {
+		backend := &unreachableConnector{MockConnector: NewMockConnector("test")}
+		b := NewBreakerConnector(ctx, &logger, backend, cfg)
+
+		backend.down.Store(true)
+		for i := 0; i < 2; i++ {
+			_, _ = b.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		}
+		backend.down.Store(false)
+		_, err := b.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+		backend.down.Store(true)
+		for i := 0; i < 2; i++ {
+			_, _ = b.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		}
+		assert.Equal(t, failsafe.StateClosed, b.breaker.State(), "failures must be consecutive")
+	})
+
+	t.Run("BackgroundProbeClosesItOnceTheBackendIsBack", func(t *testing.T) {
+		clock := util.NewVirtualClock(time.Now())
+		defer util.SetClock(clock)()
+
+		backend := &unreachableConnector{MockConnector: NewMockConnector("test")}
+		backend.down.Store(true)
+		b := NewBreakerConnector(ctx, &logger, backend, cfg)
+		clock.BlockUntil(1) // the probe loop's ticker
+		for i := 0; i < 3; i++ {
+			_ = b.Set(ctx, "evm:1:100", "eth_call:h", nil, nil)
+		}
+		require.Equal(t, failsafe.StateOpen, b.breaker.State())
+
+		clock.Advance(10 * time.Second)
+		require.Eventually(t, func() bool { return backend.pings.Load() == 1 }, time.Second, time.Millisecond)
+		require.Eventually(t, func() bool { return b.breaker.State() == failsafe.StateOpen }, time.Second, time.Millisecond)
+
+		backend.down.Store(false)
+		clock.Advance(10 * time.Second)
+		require.Eventually(t, func() bool { return b.breaker.State() == failsafe.StateClosed }, time.Second, time.Millisecond)
+		assert.Equal(t, ConnectorStateHealthy, b.State())
+		require.NoError(t, b.Set(ctx, "evm:1:100", "eth_call:h", nil, nil))
+	})
+}
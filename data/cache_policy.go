@@ -13,6 +13,7 @@ type CachePolicy struct {
 	config        *common.CachePolicyConfig
 	connector     Connector
 	connectorTags []string
+	residency     string
 	str           string
 	minSize       *int
 	maxSize       *int
@@ -63,6 +64,16 @@ func (p *CachePolicy) SetConnectorTags(tags []string) {
 	p.connectorTags = tags
 }
 
+// SetConnectorResidency records the residency label of the policy's
+// connector, see common.ConnectorConfig.Residency.
+func (p *CachePolicy) SetConnectorResidency(residency string) {
+	p.residency = residency
+}
+
+func (p *CachePolicy) ConnectorResidency() string {
+	return p.residency
+}
+
 // MatchesUpstreamSelector reports whether this policy's connector is eligible to
 // serve (get) or store (set) a request carrying the given use-upstream selector.
 // An untagged connector — or an empty selector — is always eligible, so an
@@ -108,7 +119,7 @@ func (p *CachePolicy) MatchesForSet(networkId, method string, params []interface
 		return false, nil
 	}
 
-	if isEmptyish && p.config.Empty == common.CacheEmptyBehaviorIgnore {
+	if isEmptyish && p.EmptyState() == common.CacheEmptyBehaviorIgnore {
 		return false, nil
 	}
 
@@ -179,10 +190,27 @@ func (p *CachePolicy) MatchesSizeLimits(size int) bool {
 	return true
 }
 
+// EmptyState is the effective empty behavior: an 'ignore' policy with an
+// emptyTtl allows emptyish results, which are then stored with emptyTtl.
 func (p *CachePolicy) EmptyState() common.CacheEmptyBehavior {
+	if p.config.Empty == common.CacheEmptyBehaviorIgnore && p.config.EmptyTtl > 0 {
+		return common.CacheEmptyBehaviorAllow
+	}
 	return p.config.Empty
 }
 
+// EmptyTtl returns how long emptyish results are stored, or zero when they
+// are stored with the policy's ttl.
+func (p *CachePolicy) EmptyTtl() time.Duration {
+	return p.config.EmptyTtl.Duration()
+}
+
+// EmptyInvalidateOnNewHead tells whether emptyish results stored with
+// emptyTtl stop being served once the network has a newer latest block.
+func (p *CachePolicy) EmptyInvalidateOnNewHead() bool {
+	return p.config.EmptyTtl > 0 && p.config.EmptyInvalidateOnNewHead != nil && *p.config.EmptyInvalidateOnNewHead
+}
+
 func (p *CachePolicy) matchParams(params []interface{}) (bool, error) {
 	if len(p.config.Params) == 0 {
 		return true, nil
@@ -268,6 +296,10 @@ func paramToString(param interface{}) string {
 	}
 }
 
+func (p *CachePolicy) Config() *common.CachePolicyConfig {
+	return p.config
+}
+
 func (p *CachePolicy) GetConnector() Connector {
 	return p.connector
 }
@@ -284,6 +316,12 @@ func (p *CachePolicy) GetTTL() *time.Duration {
 	return &d
 }
 
+// StaleWhileRevalidate returns how long before expiry a read also refreshes
+// the entry, or zero when it never does.
+func (p *CachePolicy) StaleWhileRevalidate() time.Duration {
+	return p.config.StaleWhileRevalidate.Duration()
+}
+
 // ResolveTTL returns the effective TTL for the given network block time,
 // applying coldStartDefault when the policy is block-time dynamic but the
 // block time isn't known yet and no fallback is set. It is the single source
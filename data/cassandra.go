@@ -0,0 +1,788 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/gocql/gocql"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	CassandraDriverName = "cassandra"
+
+	cassandraReverseTableSuffix = "_rvi"
+	cassandraSchemaVersionTable = "erpc_schema_versions"
+	cassandraLockRangeKey       = "lock"
+
+	// cassandraMaxTTL is the longest TTL Cassandra accepts (20 years).
+	cassandraMaxTTL = 630720000
+)
+
+var _ Connector = (*CassandraConnector)(nil)
+var _ GuardedSetter = (*CassandraConnector)(nil)
+
+// CassandraConnector stores entries in a Cassandra or ScyllaDB table keyed by
+// ((partition_key), range_key). EVM entries are also written to a "<table>_rvi"
+// table keyed by ((range_key), partition_key), so a wildcard lookup is a
+// clustering-key prefix read of a single partition. TTLs are native row TTLs,
+// so expired entries are never returned. Locks are lightweight transactions
+// and shared counters are polled.
+type CassandraConnector struct {
+	id          string
+	logger      *zerolog.Logger
+	cfg         *common.CassandraConnectorConfig
+	initializer *util.Initializer
+
+	mu      sync.RWMutex
+	session *gocql.Session
+
+	consistency       gocql.Consistency
+	table             string
+	reverseTable      string
+	initTimeout       time.Duration
+	getTimeout        time.Duration
+	setTimeout        time.Duration
+	statePollInterval time.Duration
+	lockRetryInterval time.Duration
+}
+
+func NewCassandraConnector(
+	appCtx context.Context,
+	logger *zerolog.Logger,
+	id string,
+	cfg *common.CassandraConnectorConfig,
+) (*CassandraConnector, error) {
+	lg := logger.With().Str("connector", id).Logger()
+	lg.Debug().Interface("config", cfg).Msg("creating cassandra connector")
+
+	consistency, err := gocql.ParseConsistencyWrapper(strings.ToUpper(cfg.Consistency))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cassandra consistency: %w", err)
+	}
+	connector := &CassandraConnector{
+		id:                id,
+		logger:            &lg,
+		cfg:               cfg,
+		consistency:       consistency,
+		table:             cfg.Keyspace + "." + cfg.Table,
+		reverseTable:      cfg.Keyspace + "." + cfg.Table + cassandraReverseTableSuffix,
+		initTimeout:       cfg.InitTimeout.Duration(),
+		getTimeout:        cfg.GetTimeout.Duration(),
+		setTimeout:        cfg.SetTimeout.Duration(),
+		statePollInterval: cfg.StatePollInterval.Duration(),
+		lockRetryInterval: cfg.LockRetryInterval.Duration(),
+	}
+	if connector.statePollInterval <= 0 {
+		connector.statePollInterval = 5 * time.Second
+	}
+	if connector.lockRetryInterval <= 0 {
+		connector.lockRetryInterval = 500 * time.Millisecond
+	}
+
+	connector.initializer = util.NewInitializer(appCtx, &lg, nil)
+	connectTask := util.NewBootstrapTask(fmt.Sprintf("cassandra-connect/%s", id), connector.connectTask)
+	if err := connector.initializer.ExecuteTasks(appCtx, connectTask); err != nil {
+		lg.Error().Err(err).Msg("failed to initialize cassandra on first attempt (will retry in background)")
+		return connector, nil
+	}
+
+	return connector, nil
+}
+
+// connectTask opens a session to the cluster and applies the schema
+// migrations. A session from an earlier attempt is closed once replaced.
+func (c *CassandraConnector) connectTask(ctx context.Context) error {
+	cluster := gocql.NewCluster(c.cfg.Hosts...)
+	cluster.Consistency = c.consistency
+	cluster.SerialConsistency = gocql.LocalSerial
+	cluster.ConnectTimeout = c.initTimeout
+	cluster.Timeout = max(c.getTimeout, c.setTimeout)
+	cluster.NumConns = c.cfg.NumConns
+	if c.cfg.LocalDC != "" {
+		cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(gocql.DCAwareRoundRobinPolicy(c.cfg.LocalDC))
+	} else {
+		cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(gocql.RoundRobinHostPolicy())
+	}
+	if c.cfg.Username != "" {
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: c.cfg.Username,
+			Password: c.cfg.Password,
+		}
+	}
+	if cfgTLS := c.cfg.TLS; cfgTLS != nil && cfgTLS.Enabled {
+		tlsConfig, err := common.CreateTLSConfig(cfgTLS)
+		if err != nil {
+			return common.NewTaskFatal(fmt.Errorf("failed to create TLS config: %w", err))
+		}
+		cluster.SslOpts = &gocql.SslOptions{
+			Config:                 tlsConfig,
+			EnableHostVerification: !cfgTLS.InsecureSkipVerify,
+		}
+	}
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return fmt.Errorf("failed to connect to cassandra: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.initTimeout)
+	defer cancel()
+	versions := &cassandraSchemaVersions{session: session, keyspace: c.cfg.Keyspace, table: c.cfg.Table}
+	if err := runSchemaMigrations(ctx, c.logger, c.id, versions, c.schemaMigrations(session)); err != nil {
+		session.Close()
+		return err
+	}
+
+	c.mu.Lock()
+	old := c.session
+	c.session = session
+	c.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+
+	c.logger.Info().Strs("hosts", c.cfg.Hosts).Str("keyspace", c.cfg.Keyspace).Str("table", c.cfg.Table).Msg("successfully connected to cassandra")
+	return nil
+}
+
+// schemaMigrations lists the changes to the connector tables in order. Never
+// edit or reorder a released migration; append a new one instead.
+func (c *CassandraConnector) schemaMigrations(session *gocql.Session) []SchemaMigration {
+	return []SchemaMigration{
+		{
+			Version:     1,
+			Description: "create table",
+			Apply: func(ctx context.Context) error {
+				return session.Query(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+					partition_key text,
+					range_key text,
+					value blob,
+					PRIMARY KEY ((partition_key), range_key)
+				)`, c.table)).WithContext(ctx).Exec()
+			},
+		},
+		{
+			Version:     2,
+			Description: "create reverse index table",
+			Apply: func(ctx context.Context) error {
+				return session.Query(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+					range_key text,
+					partition_key text,
+					value blob,
+					PRIMARY KEY ((range_key), partition_key)
+				) WITH CLUSTERING ORDER BY (partition_key DESC)`, c.reverseTable)).WithContext(ctx).Exec()
+			},
+		},
+		{
+			Version:     3,
+			Description: "add finality guard column",
+			Apply: func(ctx context.Context) error {
+				return session.Query(fmt.Sprintf(`ALTER TABLE %s ADD finality_guard bigint`, c.table)).WithContext(ctx).Exec()
+			},
+		},
+	}
+}
+
+// cassandraSchemaVersions records the migration version of a table in the
+// keyspace's erpc_schema_versions table.
+type cassandraSchemaVersions struct {
+	session  *gocql.Session
+	keyspace string
+	table    string
+}
+
+func (s *cassandraSchemaVersions) SchemaVersion(ctx context.Context) (int, error) {
+	err := s.session.Query(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+		table_name text PRIMARY KEY,
+		version int
+	)`, s.keyspace, cassandraSchemaVersionTable)).WithContext(ctx).Exec()
+	if err != nil {
+		return 0, err
+	}
+	var version int
+	err = s.session.Query(fmt.Sprintf(`SELECT version FROM %s.%s WHERE table_name = ?`, s.keyspace, cassandraSchemaVersionTable), s.table).
+		WithContext(ctx).Scan(&version)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return 0, nil
+	}
+	return version, err
+}
+
+func (s *cassandraSchemaVersions) SetSchemaVersion(ctx context.Context, version int) error {
+	return s.session.Query(fmt.Sprintf(`INSERT INTO %s.%s (table_name, version) VALUES (?, ?)`, s.keyspace, cassandraSchemaVersionTable), s.table, version).
+		WithContext(ctx).Exec()
+}
+
+// getSession returns the current session, or an error if the connector is
+// not connected yet.
+func (c *CassandraConnector) getSession() (*gocql.Session, error) {
+	if state := c.initializer.State(); state != util.StateReady {
+		return nil, fmt.Errorf("cassandra is not connected (state: %s), errors: %v", state.String(), c.initializer.Errors())
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.session == nil {
+		return nil, fmt.Errorf("cassandra session not initialized yet")
+	}
+	return c.session, nil
+}
+
+func (c *CassandraConnector) Id() string {
+	return c.id
+}
+
+func (c *CassandraConnector) State() ConnectorState {
+	return initializerConnectorState(c.initializer)
+}
+
+func (c *CassandraConnector) Ping(ctx context.Context) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withOperationTimeout(ctx, c.getTimeout, CassandraDriverName, "getTimeout")
+	defer cancel()
+	return session.Query(`SELECT release_version FROM system.local`).WithContext(ctx).Exec()
+}
+
+// cassandraTTL converts a TTL to whole seconds (rounded up, capped at the
+// maximum Cassandra accepts). Zero means the row never expires.
+func cassandraTTL(ttl *time.Duration) int {
+	if ttl == nil || *ttl <= 0 {
+		return 0
+	}
+	seconds := int64((*ttl + time.Second - 1) / time.Second)
+	if seconds > cassandraMaxTTL {
+		return cassandraMaxTTL
+	}
+	return int(seconds)
+}
+
+// cassandraReverseIndexed reports whether an entry is also written to the
+// reverse index table: concrete EVM partition keys, as for Redis.
+func cassandraReverseIndexed(partitionKey string) bool {
+	return strings.HasPrefix(partitionKey, "evm:") && !strings.HasSuffix(partitionKey, "*")
+}
+
+// cassandraPrefixBound returns the exclusive upper bound of the text values
+// starting with prefix: prefix followed by the highest code point.
+func cassandraPrefixBound(prefix string) string {
+	return prefix + "\U0010FFFF"
+}
+
+func (c *CassandraConnector) addWrites(batch *gocql.Batch, partitionKey, rangeKey string, value []byte, ttl int) {
+	batch.Query(fmt.Sprintf(`INSERT INTO %s (partition_key, range_key, value) VALUES (?, ?, ?) USING TTL ?`, c.table),
+		partitionKey, rangeKey, value, ttl)
+	if cassandraReverseIndexed(partitionKey) {
+		batch.Query(fmt.Sprintf(`INSERT INTO %s (range_key, partition_key, value) VALUES (?, ?, ?) USING TTL ?`, c.reverseTable),
+			rangeKey, partitionKey, value, ttl)
+	}
+}
+
+// Set writes the entry and, for EVM keys, its reverse index row in one
+// unlogged batch with the same TTL.
+func (c *CassandraConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	ctx, span := common.StartSpan(ctx, "CassandraConnector.Set")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+		)
+	}
+
+	session, err := c.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	c.logger.Debug().Int("len", len(value)).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Interface("ttl", ttl).Msg("writing item to cassandra")
+
+	ctx, cancel := withOperationTimeout(ctx, c.setTimeout, CassandraDriverName, "setTimeout")
+	defer cancel()
+
+	batch := session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+	c.addWrites(batch, partitionKey, rangeKey, value, cassandraTTL(ttl))
+	if err := session.ExecuteBatch(batch); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	return nil
+}
+
+// SetGuarded writes the entry with a lightweight transaction conditional on
+// the stored finality_guard. Conditions on a null column never hold, so an
+// entry that does not exist or was written by Set is claimed with a second
+// transaction conditional on the guard being null. The reverse index row is
+// written once the entry is stored.
+func (c *CassandraConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	ctx, span := common.StartSpan(ctx, "CassandraConnector.SetGuarded")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+			attribute.Int64("guard", guard),
+		)
+	}
+
+	session, err := c.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+
+	c.logger.Debug().Int("len", len(value)).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int64("guard", guard).Interface("ttl", ttl).Msg("writing guarded item to cassandra")
+
+	ctx, cancel := withOperationTimeout(ctx, c.setTimeout, CassandraDriverName, "setTimeout")
+	defer cancel()
+
+	seconds := cassandraTTL(ttl)
+	update := fmt.Sprintf(`UPDATE %s USING TTL ? SET value = ?, finality_guard = ? WHERE partition_key = ? AND range_key = ? IF finality_guard `, c.table)
+	var current *int64
+	applied, err := session.Query(update+`<= ?`, seconds, value, guard, partitionKey, rangeKey, guard).WithContext(ctx).ScanCAS(&current)
+	if err == nil && !applied && current == nil {
+		applied, err = session.Query(update+`= null`, seconds, value, guard, partitionKey, rangeKey).WithContext(ctx).ScanCAS(&current)
+	}
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+	if !applied {
+		return false, nil
+	}
+
+	if cassandraReverseIndexed(partitionKey) {
+		err := session.Query(fmt.Sprintf(`INSERT INTO %s (range_key, partition_key, value) VALUES (?, ?, ?) USING TTL ?`, c.reverseTable),
+			rangeKey, partitionKey, value, seconds).WithContext(ctx).Exec()
+		if err != nil {
+			common.SetTraceSpanError(span, err)
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// SetMany writes each item in its own batch, concurrently: a multi-partition
+// batch would load a single coordinator for no atomicity gain.
+func (c *CassandraConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	ctx, span := common.StartSpan(ctx, "CassandraConnector.SetMany")
+	defer span.End()
+	span.SetAttributes(attribute.Int("items", len(items)))
+
+	session, err := c.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	items = uniqueKeyValuePairs(items)
+	ctx, cancel := withOperationTimeout(ctx, c.setTimeout, CassandraDriverName, "setTimeout")
+	defer cancel()
+
+	seconds := cassandraTTL(ttl)
+	errs := make([]error, len(items))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		go func(i int, item KeyValuePair) {
+			defer wg.Done()
+			batch := session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+			c.addWrites(batch, item.PartitionKey, item.RangeKey, item.Value, seconds)
+			errs[i] = session.ExecuteBatch(batch)
+		}(i, item)
+	}
+	wg.Wait()
+
+	err = errors.Join(errs...)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return err
+}
+
+// Get reads an entry by its keys or, on the reverse index, the entry with the
+// given range key whose partition key matches partitionKey (a trailing "*"
+// matches a prefix). When several match, the greatest partition key wins,
+// as with DynamoDB.
+func (c *CassandraConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
+	ctx, span := common.StartSpan(ctx, "CassandraConnector.Get")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("index", index),
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+		)
+	}
+
+	session, err := c.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, c.getTimeout, CassandraDriverName, "getTimeout")
+	defer cancel()
+
+	var q *gocql.Query
+	if index == ConnectorReverseIndex {
+		if rangeKey == "" || strings.HasSuffix(rangeKey, "*") {
+			err := fmt.Errorf("when using reverse index rangeKey must be a non-empty string and not contain wildcards (rangeKey: '%s', partitionKey: '%s')", rangeKey, partitionKey)
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		}
+		switch {
+		case partitionKey == "" || partitionKey == "*":
+			q = session.Query(fmt.Sprintf(`SELECT value FROM %s WHERE range_key = ? LIMIT 1`, c.reverseTable), rangeKey)
+		case strings.HasSuffix(partitionKey, "*"):
+			prefix := strings.TrimSuffix(partitionKey, "*")
+			q = session.Query(fmt.Sprintf(`SELECT value FROM %s WHERE range_key = ? AND partition_key >= ? AND partition_key < ? LIMIT 1`, c.reverseTable),
+				rangeKey, prefix, cassandraPrefixBound(prefix))
+		default:
+			q = session.Query(fmt.Sprintf(`SELECT value FROM %s WHERE range_key = ? AND partition_key = ?`, c.reverseTable), rangeKey, partitionKey)
+		}
+	} else {
+		q = session.Query(fmt.Sprintf(`SELECT value FROM %s WHERE partition_key = ? AND range_key = ?`, c.table), partitionKey, rangeKey)
+	}
+
+	c.logger.Debug().Str("index", index).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("getting item from cassandra")
+	var value []byte
+	if err := q.WithContext(ctx).Scan(&value); err != nil {
+		if errors.Is(err, gocql.ErrNotFound) {
+			err = common.NewErrRecordNotFound(partitionKey, rangeKey, CassandraDriverName)
+		}
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(attribute.Int("value_size", len(value)))
+	}
+	return value, nil
+}
+
+func (c *CassandraConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	ctx, span := common.StartSpan(ctx, "CassandraConnector.Delete")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+		)
+	}
+
+	session, err := c.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	c.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("deleting item from cassandra")
+
+	ctx, cancel := withOperationTimeout(ctx, c.setTimeout, CassandraDriverName, "setTimeout")
+	defer cancel()
+
+	batch := session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+	batch.Query(fmt.Sprintf(`DELETE FROM %s WHERE partition_key = ? AND range_key = ?`, c.table), partitionKey, rangeKey)
+	if cassandraReverseIndexed(partitionKey) {
+		batch.Query(fmt.Sprintf(`DELETE FROM %s WHERE range_key = ? AND partition_key = ?`, c.reverseTable), rangeKey, partitionKey)
+	}
+	if err := session.ExecuteBatch(batch); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteByPrefix scans the table for matching entries and deletes them one
+// by one, with their reverse index rows.
+func (c *CassandraConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return deleteByScan(ctx, c.Scan, c.Delete, partitionKeyPrefix)
+}
+
+func (c *CassandraConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "CassandraConnector.List")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("index", index),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	stmt := fmt.Sprintf(`SELECT partition_key, range_key, value FROM %s`, c.table)
+	if index == ConnectorReverseIndex {
+		stmt = fmt.Sprintf(`SELECT partition_key, range_key, value FROM %s`, c.reverseTable)
+	}
+	items, next, err := c.scanPage(ctx, stmt, nil, "", limit, paginationToken)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return items, next, err
+}
+
+// Scan pages through the whole table. A range key prefix is filtered by the
+// nodes on the clustering column; a partition key prefix can only be
+// filtered here, since partitions are ordered by token. Pages are therefore
+// often smaller than limit; only an empty cursor marks the end.
+func (c *CassandraConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "CassandraConnector.Scan")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key_prefix", partitionKeyPrefix),
+			attribute.String("range_key_prefix", rangeKeyPrefix),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	if err := validateScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+
+	stmt := fmt.Sprintf(`SELECT partition_key, range_key, value FROM %s`, c.table)
+	var args []interface{}
+	if rangeKeyPrefix != "" {
+		stmt += ` WHERE range_key >= ? AND range_key < ? ALLOW FILTERING`
+		args = append(args, rangeKeyPrefix, cassandraPrefixBound(rangeKeyPrefix))
+	}
+	items, next, err := c.scanPage(ctx, stmt, args, partitionKeyPrefix, limit, cursor)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return items, next, err
+}
+
+// scanPage runs one page of stmt from cursor, keeping the rows whose
+// partition key starts with partitionKeyPrefix. The cursor is the driver's
+// base64 page state.
+func (c *CassandraConnector) scanPage(ctx context.Context, stmt string, args []interface{}, partitionKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, "", err
+	}
+	var pageState []byte
+	if cursor != "" {
+		if pageState, err = base64.StdEncoding.DecodeString(cursor); err != nil {
+			return nil, "", fmt.Errorf("invalid pagination token: %w", err)
+		}
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, c.getTimeout, CassandraDriverName, "getTimeout")
+	defer cancel()
+
+	iter := session.Query(stmt, args...).WithContext(ctx).PageSize(limit).PageState(pageState).Iter()
+	results := make([]KeyValuePair, 0, iter.NumRows())
+	var partitionKey, rangeKey string
+	var value []byte
+	for iter.Scan(&partitionKey, &rangeKey, &value) {
+		if !strings.HasPrefix(partitionKey, partitionKeyPrefix) {
+			continue
+		}
+		results = append(results, KeyValuePair{
+			PartitionKey: partitionKey,
+			RangeKey:     rangeKey,
+			Value:        append([]byte(nil), value...),
+		})
+	}
+	next := iter.PageState()
+	if err := iter.Close(); err != nil {
+		return nil, "", err
+	}
+	if len(next) == 0 {
+		return results, "", nil
+	}
+	return results, base64.StdEncoding.EncodeToString(next), nil
+}
+
+// Lock acquires a distributed lock with an INSERT ... IF NOT EXISTS
+// lightweight transaction, retrying every lockRetryInterval until ctx is
+// done. The lock row has a TTL of ttl so a crashed holder cannot keep it.
+func (c *CassandraConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	ctx, span := common.StartSpan(ctx, "CassandraConnector.Lock",
+		trace.WithAttributes(
+			attribute.String("lock_key", key),
+			attribute.Int64("ttl_ms", ttl.Milliseconds()),
+		),
+	)
+	defer span.End()
+
+	session, err := c.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	lockKey := fmt.Sprintf("%s:lock", key)
+	stmt := fmt.Sprintf(`INSERT INTO %s (partition_key, range_key, value) VALUES (?, ?, ?) IF NOT EXISTS USING TTL ?`, c.table)
+
+	for {
+		attemptCtx, cancel := withOperationTimeout(ctx, c.setTimeout, CassandraDriverName, "setTimeout")
+		applied, err := session.Query(stmt, lockKey, cassandraLockRangeKey, []byte(token), max(cassandraTTL(&ttl), 1)).
+			WithContext(attemptCtx).MapScanCAS(map[string]interface{}{})
+		cancel()
+		if err == nil && applied {
+			c.logger.Debug().Str("lockKey", lockKey).Dur("ttl", ttl).Msg("distributed lock acquired")
+			return &cassandraLock{connector: c, lockKey: lockKey, token: token}, nil
+		}
+		if err != nil && ctx.Err() == nil {
+			c.logger.Warn().Err(err).Str("lockKey", lockKey).Msg("failed to acquire lock")
+			common.SetTraceSpanError(span, err)
+			return nil, fmt.Errorf("failed to acquire lock for key '%s': %w", key, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			err := fmt.Errorf("lock acquisition cancelled or timed out for key '%s': %w", key, ctx.Err())
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		case <-util.After(c.lockRetryInterval):
+		}
+	}
+}
+
+var _ DistributedLock = &cassandraLock{}
+
+type cassandraLock struct {
+	connector *CassandraConnector
+	lockKey   string
+	token     string
+}
+
+func (l *cassandraLock) IsNil() bool {
+	return l == nil || l.connector == nil
+}
+
+// Unlock deletes the lock row only if it still holds this lock's token, so a
+// lock that expired and was taken by someone else is left alone.
+func (l *cassandraLock) Unlock(ctx context.Context) error {
+	ctx, span := common.StartSpan(ctx, "CassandraConnector.Unlock",
+		trace.WithAttributes(
+			attribute.String("lock_key", l.lockKey),
+		),
+	)
+	defer span.End()
+
+	session, err := l.connector.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, l.connector.setTimeout, CassandraDriverName, "setTimeout")
+	defer cancel()
+
+	applied, err := session.Query(fmt.Sprintf(`DELETE FROM %s WHERE partition_key = ? AND range_key = ? IF value = ?`, l.connector.table),
+		l.lockKey, cassandraLockRangeKey, []byte(l.token)).WithContext(ctx).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		err = fmt.Errorf("error releasing lock: %w", err)
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	if !applied {
+		err := errors.New("failed to release lock: expired or held by another owner")
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	l.connector.logger.Debug().Str("lockKey", l.lockKey).Msg("distributed lock released")
+	return nil
+}
+
+// WatchCounterInt64 polls the counter every statePollInterval, since
+// Cassandra has no pub/sub. Callers of this method are responsible to re-try
+// the operation if "values" channel is closed.
+func (c *CassandraConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	if _, err := c.getSession(); err != nil {
+		return nil, nil, err
+	}
+
+	updates := make(chan CounterInt64State, 1)
+	ticker := util.NewTicker(c.statePollInterval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		var lastUpdatedAt int64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C():
+				st, ok, err := c.getCounterState(ctx, key)
+				if err != nil {
+					c.logger.Warn().Err(err).Str("key", key).Msg("failed to poll counter value")
+					continue
+				}
+				if ok && st.UpdatedAt > lastUpdatedAt {
+					lastUpdatedAt = st.UpdatedAt
+					select {
+					case updates <- st:
+					default:
+					}
+				}
+			}
+		}
+	}()
+
+	if st, ok, err := c.getCounterState(ctx, key); err == nil && ok {
+		updates <- st
+	}
+
+	cleanup := func() {
+		close(done)
+		close(updates)
+	}
+
+	return updates, cleanup, nil
+}
+
+func (c *CassandraConnector) getCounterState(ctx context.Context, key string) (CounterInt64State, bool, error) {
+	raw, err := c.Get(ctx, ConnectorMainIndex, key, "value", nil)
+	if err != nil {
+		if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+			return CounterInt64State{}, false, nil
+		}
+		return CounterInt64State{}, false, err
+	}
+	var st CounterInt64State
+	if err := common.SonicCfg.Unmarshal(raw, &st); err != nil || st.UpdatedAt <= 0 {
+		return CounterInt64State{}, false, nil
+	}
+	return st, true, nil
+}
+
+// PublishCounterInt64 is a no-op: Cassandra has no pub/sub, so counters are
+// propagated by WatchCounterInt64 polling the value stored via Set.
+func (c *CassandraConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return nil
+}
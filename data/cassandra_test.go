@@ -0,0 +1,289 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/gocql/gocql"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestCassandraTTL(t *testing.T) {
+	ttl := func(d time.Duration) *time.Duration { return &d }
+
+	assert.Equal(t, 0, cassandraTTL(nil))
+	assert.Equal(t, 0, cassandraTTL(ttl(0)))
+	assert.Equal(t, 0, cassandraTTL(ttl(-time.Second)))
+	assert.Equal(t, 1, cassandraTTL(ttl(time.Millisecond)), "sub-second TTLs round up rather than disabling expiry")
+	assert.Equal(t, 2, cassandraTTL(ttl(1500*time.Millisecond)))
+	assert.Equal(t, 60, cassandraTTL(ttl(time.Minute)))
+	assert.Equal(t, cassandraMaxTTL, cassandraTTL(ttl(100*365*24*time.Hour)))
+}
+
+func TestCassandraReverseIndexed(t *testing.T) {
+	assert.True(t, cassandraReverseIndexed("evm:1:latest"))
+	assert.False(t, cassandraReverseIndexed("evm:1*"))
+	assert.False(t, cassandraReverseIndexed("shared-state:counter"))
+}
+
+func TestCassandraPrefixBound(t *testing.T) {
+	bound := cassandraPrefixBound("evm:1:")
+	assert.Less(t, "evm:1:", bound)
+	assert.Less(t, "evm:1:0xffff", bound)
+	assert.Less(t, "evm:1:￿", bound)
+	assert.Greater(t, "evm:1;", bound)
+	assert.Greater(t, "evm:2:", bound)
+}
+
+// startCassandra runs a single-node Cassandra container with the test
+// keyspace created, and returns its host:port.
+func startCassandra(t *testing.T, ctx context.Context) string {
+	t.Helper()
+	req := testcontainers.ContainerRequest{
+		Image:        "cassandra:4.1",
+		ExposedPorts: []string{"9042/tcp"},
+		Env: map[string]string{
+			"MAX_HEAP_SIZE": "512M",
+			"HEAP_NEWSIZE":  "128M",
+		},
+		WaitingFor: wait.ForLog("Starting listening for CQL clients").WithStartupTimeout(3 * time.Minute),
+	}
+	cassC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	require.NoError(t, err, "failed to start Cassandra container")
+	t.Cleanup(func() { _ = cassC.Terminate(context.Background()) })
+
+	host, err := cassC.Host(ctx)
+	require.NoError(t, err)
+	port, err := cassC.MappedPort(ctx, "9042")
+	require.NoError(t, err)
+	addr := fmt.Sprintf("%s:%s", host, port.Port())
+
+	cluster := gocql.NewCluster(addr)
+	cluster.ConnectTimeout = 10 * time.Second
+	cluster.Timeout = 10 * time.Second
+	session, err := cluster.CreateSession()
+	require.NoError(t, err)
+	defer session.Close()
+	require.NoError(t, session.Query(`CREATE KEYSPACE IF NOT EXISTS erpc_test WITH replication = {'class': 'SimpleStrategy', 'replication_factor': 1}`).Exec())
+	return addr
+}
+
+func newTestCassandraConnector(t *testing.T, ctx context.Context, addr, table string) *CassandraConnector {
+	t.Helper()
+	cfg := &common.CassandraConnectorConfig{
+		Hosts:             []string{addr},
+		Keyspace:          "erpc_test",
+		Table:             table,
+		Consistency:       "ONE",
+		NumConns:          2,
+		InitTimeout:       common.Duration(30 * time.Second),
+		GetTimeout:        common.Duration(5 * time.Second),
+		SetTimeout:        common.Duration(5 * time.Second),
+		StatePollInterval: common.Duration(100 * time.Millisecond),
+		LockRetryInterval: common.Duration(50 * time.Millisecond),
+	}
+	connector, err := NewCassandraConnector(ctx, &log.Logger, "test-cassandra", cfg)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return connector.State() == ConnectorStateHealthy
+	}, 30*time.Second, 100*time.Millisecond, "connector should be ready")
+	return connector
+}
+
+func TestCassandraConnector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr := startCassandra(t, ctx)
+
+	t.Run("SetGetDelete", func(t *testing.T) {
+		c := newTestCassandraConnector(t, ctx, addr, "set_get_delete")
+
+		require.NoError(t, c.Set(ctx, "evm:1:100", "eth_getBlockByNumber:abc", []byte("hello"), nil))
+		val, err := c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:abc", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hello"), val)
+
+		val, err = c.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getBlockByNumber:abc", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hello"), val, "evm entries are reverse indexed")
+
+		require.NoError(t, c.Delete(ctx, "evm:1:100", "eth_getBlockByNumber:abc"))
+		_, err = c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:abc", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+		_, err = c.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getBlockByNumber:abc", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), "the reverse index row is deleted too")
+	})
+
+	t.Run("ReverseIndexPrefersGreatestPartitionKey", func(t *testing.T) {
+		c := newTestCassandraConnector(t, ctx, addr, "reverse_index")
+
+		require.NoError(t, c.Set(ctx, "evm:1:100", "tx:0xab", []byte("older"), nil))
+		require.NoError(t, c.Set(ctx, "evm:1:101", "tx:0xab", []byte("newer"), nil))
+		val, err := c.Get(ctx, ConnectorReverseIndex, "evm:1:*", "tx:0xab", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("newer"), val)
+
+		val, err = c.Get(ctx, ConnectorReverseIndex, "evm:1:100", "tx:0xab", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("older"), val)
+	})
+
+	t.Run("TTLExpiry", func(t *testing.T) {
+		c := newTestCassandraConnector(t, ctx, addr, "ttl_expiry")
+
+		ttl := time.Second
+		require.NoError(t, c.Set(ctx, "evm:1:100", "eth_call:h", []byte("short"), &ttl))
+		val, err := c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("short"), val)
+
+		require.Eventually(t, func() bool {
+			_, err := c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+			return common.HasErrorCode(err, common.ErrCodeRecordNotFound)
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("SetGuarded", func(t *testing.T) {
+		c := newTestCassandraConnector(t, ctx, addr, "set_guarded")
+
+		stored, err := c.SetGuarded(ctx, "evm:1:100", "eth_getBlockByNumber:h", []byte("final"), 101, nil)
+		require.NoError(t, err)
+		require.True(t, stored, "a missing entry is claimed")
+
+		stored, err = c.SetGuarded(ctx, "evm:1:100", "eth_getBlockByNumber:h", []byte("unfinal"), 0, nil)
+		require.NoError(t, err)
+		assert.False(t, stored, "a lower guard is refused")
+
+		stored, err = c.SetGuarded(ctx, "evm:1:100", "eth_getBlockByNumber:h", []byte("again"), 101, nil)
+		require.NoError(t, err)
+		assert.True(t, stored, "an equal guard replaces")
+
+		val, err := c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:h", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("again"), val)
+		val, err = c.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getBlockByNumber:h", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("again"), val)
+
+		require.NoError(t, c.Set(ctx, "evm:1:100", "eth_call:h", []byte("plain"), nil))
+		stored, err = c.SetGuarded(ctx, "evm:1:100", "eth_call:h", []byte("guarded"), 0, nil)
+		require.NoError(t, err)
+		assert.True(t, stored, "an entry written by Set has no guard")
+	})
+
+	t.Run("ScanAndList", func(t *testing.T) {
+		c := newTestCassandraConnector(t, ctx, addr, "scan_list")
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, c.Set(ctx, fmt.Sprintf("evm:1:%d", i), "eth_call:h", []byte("v"), nil))
+		}
+		require.NoError(t, c.Set(ctx, "evm:2:0", "eth_call:h", []byte("v"), nil))
+		require.NoError(t, c.Set(ctx, "evm:1:0", "eth_getBalance:h", []byte("v"), nil))
+
+		var scanned []KeyValuePair
+		cursor := ""
+		for {
+			page, next, err := c.Scan(ctx, "evm:1:", "eth_call", 2, cursor)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(page), 2)
+			scanned = append(scanned, page...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		assert.Len(t, scanned, 5, "only entries matching both prefixes")
+		for _, kv := range scanned {
+			assert.Contains(t, kv.PartitionKey, "evm:1:")
+			assert.Equal(t, "eth_call:h", kv.RangeKey)
+		}
+
+		var listed []KeyValuePair
+		token := ""
+		for {
+			page, next, err := c.List(ctx, ConnectorMainIndex, 3, token)
+			require.NoError(t, err)
+			listed = append(listed, page...)
+			if next == "" {
+				break
+			}
+			token = next
+		}
+		assert.Len(t, listed, 7)
+
+		deleted, err := c.DeleteByPrefix(ctx, "evm:1:")
+		require.NoError(t, err)
+		assert.Equal(t, 6, deleted)
+		_, err = c.Get(ctx, ConnectorMainIndex, "evm:2:0", "eth_call:h", nil)
+		assert.NoError(t, err, "other partitions are kept")
+	})
+
+	t.Run("Lock", func(t *testing.T) {
+		c := newTestCassandraConnector(t, ctx, addr, "lock")
+
+		lock, err := c.Lock(ctx, "job", 10*time.Second)
+		require.NoError(t, err)
+
+		waitCtx, waitCancel := context.WithTimeout(ctx, 300*time.Millisecond)
+		_, err = c.Lock(waitCtx, "job", 10*time.Second)
+		waitCancel()
+		assert.Error(t, err, "a held lock cannot be acquired")
+
+		require.NoError(t, lock.Unlock(ctx))
+		assert.Error(t, lock.Unlock(ctx), "a released lock cannot be released again")
+
+		lock, err = c.Lock(ctx, "job", time.Second)
+		require.NoError(t, err, "a released lock can be acquired")
+
+		// The lock row expires with its TTL, so a crashed holder cannot keep it.
+		_, err = c.Lock(ctx, "job", 10*time.Second)
+		require.NoError(t, err)
+		assert.Error(t, lock.Unlock(ctx), "an expired lock taken by another owner is left alone")
+	})
+
+	t.Run("WatchCounterInt64", func(t *testing.T) {
+		c := newTestCassandraConnector(t, ctx, addr, "counter")
+
+		raw, err := common.SonicCfg.Marshal(CounterInt64State{Value: 42, UpdatedAt: 1})
+		require.NoError(t, err)
+		require.NoError(t, c.Set(ctx, "counter-key", "value", raw, nil))
+
+		updates, cleanup, err := c.WatchCounterInt64(ctx, "counter-key")
+		require.NoError(t, err)
+		defer cleanup()
+
+		select {
+		case st := <-updates:
+			assert.Equal(t, int64(42), st.Value)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no initial counter value")
+		}
+
+		raw, err = common.SonicCfg.Marshal(CounterInt64State{Value: 43, UpdatedAt: 2})
+		require.NoError(t, err)
+		require.NoError(t, c.Set(ctx, "counter-key", "value", raw, nil))
+		// The first poll may still report the initial value.
+		deadline := time.After(5 * time.Second)
+		for {
+			select {
+			case st := <-updates:
+				if st.Value == 43 {
+					return
+				}
+			case <-deadline:
+				t.Fatal("polling did not pick up the newer value")
+			}
+		}
+	})
+}
@@ -0,0 +1,783 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	ClickHouseDriverName = "clickhouse"
+
+	clickHouseSchemaVersionTable = "erpc_schema_versions"
+
+	// clickHouseNeverExpires is the expiry of entries without a TTL: the
+	// largest DateTime value (2106-02-07).
+	clickHouseNeverExpires = math.MaxUint32
+
+	// clickHouseFinalFlushTimeout bounds the insert of buffered rows on
+	// shutdown.
+	clickHouseFinalFlushTimeout = 10 * time.Second
+
+	// clickHouseMaxErrorBody is how much of an error response is kept in
+	// the returned error.
+	clickHouseMaxErrorBody = 1024
+)
+
+var _ Connector = (*ClickHouseConnector)(nil)
+
+// ClickHouseConnector stores cache entries in an append-only
+// ReplacingMergeTree table ordered by (partition_key, range_key), over the
+// HTTP interface. A write is a new row: reads take the latest row of a key,
+// and merges eventually drop the older ones. Rows are buffered and inserted
+// in RowBinary batches of up to batchSize; until then they are only visible
+// to Get on the same instance. The table is partitioned by expiry date with
+// ttl_only_drop_parts, so expired data is dropped a whole part at a time
+// instead of being rewritten. A bloom filter index on range_key serves the
+// reverse index. There are no locks or shared counters, so the connector is
+// restricted to the cache.
+type ClickHouseConnector struct {
+	id          string
+	logger      *zerolog.Logger
+	cfg         *common.ClickHouseConnectorConfig
+	initializer *util.Initializer
+	client      *http.Client
+	table       string
+	getTimeout  time.Duration
+	setTimeout  time.Duration
+
+	mu      sync.Mutex
+	pending map[clickHouseKey]clickHouseRow
+	flushC  chan struct{}
+}
+
+type clickHouseKey struct {
+	partitionKey string
+	rangeKey     string
+}
+
+// clickHouseRow is one row of the table, as buffered before its insert.
+type clickHouseRow struct {
+	partitionKey string
+	rangeKey     string
+	value        []byte
+	insertedAt   time.Time
+	expiresAt    uint32
+}
+
+func NewClickHouseConnector(
+	appCtx context.Context,
+	logger *zerolog.Logger,
+	id string,
+	cfg *common.ClickHouseConnectorConfig,
+) (*ClickHouseConnector, error) {
+	lg := logger.With().Str("connector", id).Logger()
+	lg.Debug().Interface("config", cfg).Msg("creating clickhouse connector")
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.TLS != nil && cfg.TLS.Enabled {
+		tlsConfig, err := common.CreateTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create TLS config: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	connector := &ClickHouseConnector{
+		id:         id,
+		logger:     &lg,
+		cfg:        cfg,
+		client:     &http.Client{Transport: transport},
+		table:      fmt.Sprintf("`%s`.`%s`", cfg.Database, cfg.Table),
+		getTimeout: cfg.GetTimeout.Duration(),
+		setTimeout: cfg.SetTimeout.Duration(),
+		pending:    make(map[clickHouseKey]clickHouseRow),
+		flushC:     make(chan struct{}, 1),
+	}
+
+	connector.initializer = util.NewInitializer(appCtx, &lg, nil)
+	connectTask := util.NewBootstrapTask(fmt.Sprintf("clickhouse-connect/%s", id), connector.connectTask)
+	go connector.flushLoop(appCtx)
+	if err := connector.initializer.ExecuteTasks(appCtx, connectTask); err != nil {
+		lg.Error().Err(err).Msg("failed to initialize clickhouse on first attempt (will retry in background)")
+		return connector, nil
+	}
+
+	return connector, nil
+}
+
+// connectTask checks that the server answers and applies the schema
+// migrations.
+func (c *ClickHouseConnector) connectTask(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.InitTimeout.Duration())
+	defer cancel()
+	if _, err := c.query(ctx, "SELECT 1", nil, nil); err != nil {
+		return fmt.Errorf("failed to connect to clickhouse: %w", err)
+	}
+	versions := &clickHouseSchemaVersions{connector: c}
+	if err := runSchemaMigrations(ctx, c.logger, c.id, versions, c.schemaMigrations()); err != nil {
+		return err
+	}
+	c.logger.Info().Str("url", util.RedactEndpoint(c.cfg.Url)).Str("database", c.cfg.Database).Str("table", c.cfg.Table).Msg("successfully connected to clickhouse")
+	return nil
+}
+
+// clickHousePartitionExpr returns the PARTITION BY expression for a
+// partitionBy setting.
+func clickHousePartitionExpr(partitionBy string) string {
+	switch partitionBy {
+	case "week":
+		return "toMonday(expires_at)"
+	case "month":
+		return "toYYYYMM(expires_at)"
+	default:
+		return "toYYYYMMDD(expires_at)"
+	}
+}
+
+// schemaMigrations lists the changes to the connector table in order. Never
+// edit or reorder a released migration; append a new one instead.
+func (c *ClickHouseConnector) schemaMigrations() []SchemaMigration {
+	return []SchemaMigration{
+		{
+			Version:     1,
+			Description: "create table",
+			Apply: func(ctx context.Context) error {
+				_, err := c.query(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+					partition_key String,
+					range_key String,
+					value String CODEC(ZSTD(3)),
+					inserted_at DateTime64(3),
+					expires_at DateTime,
+					INDEX idx_range_key range_key TYPE bloom_filter GRANULARITY 4
+				) ENGINE = ReplacingMergeTree(inserted_at)
+				PARTITION BY %s
+				ORDER BY (partition_key, range_key)
+				TTL expires_at
+				SETTINGS ttl_only_drop_parts = 1`, c.table, clickHousePartitionExpr(c.cfg.PartitionBy)), nil, nil)
+				return err
+			},
+		},
+	}
+}
+
+// clickHouseSchemaVersions records the migration version of the table in
+// the database's erpc_schema_versions table.
+type clickHouseSchemaVersions struct {
+	connector *ClickHouseConnector
+}
+
+func (s *clickHouseSchemaVersions) SchemaVersion(ctx context.Context) (int, error) {
+	c := s.connector
+	_, err := c.query(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+		table_name String,
+		version UInt32,
+		applied_at DateTime64(3)
+	) ENGINE = ReplacingMergeTree(applied_at)
+	ORDER BY table_name`, "`"+c.cfg.Database+"`", clickHouseSchemaVersionTable), nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	raw, err := c.query(ctx, fmt.Sprintf(`SELECT max(version) FROM %s.%s WHERE table_name = {table:String} FORMAT TabSeparated`, "`"+c.cfg.Database+"`", clickHouseSchemaVersionTable),
+		map[string]string{"table": c.cfg.Table}, nil)
+	if err != nil {
+		return 0, err
+	}
+	return parseSchemaVersion(strings.TrimSpace(string(raw)))
+}
+
+func (s *clickHouseSchemaVersions) SetSchemaVersion(ctx context.Context, version int) error {
+	c := s.connector
+	_, err := c.query(ctx, fmt.Sprintf(`INSERT INTO %s.%s (table_name, version, applied_at) SELECT {table:String}, {version:UInt32}, now64(3)`, "`"+c.cfg.Database+"`", clickHouseSchemaVersionTable),
+		map[string]string{"table": c.cfg.Table, "version": strconv.Itoa(version)}, nil)
+	return err
+}
+
+// query runs a statement over the HTTP interface and returns the response
+// body. Parameters are bound server-side as {name:Type} placeholders. With a
+// body (insert data) the statement goes in the URL, otherwise in the body.
+func (c *ClickHouseConnector) query(ctx context.Context, stmt string, params map[string]string, data []byte) ([]byte, error) {
+	u, err := url.Parse(c.cfg.Url)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("database", c.cfg.Database)
+	for k, v := range params {
+		q.Set("param_"+k, v)
+	}
+	body := []byte(stmt)
+	if data != nil {
+		q.Set("query", stmt)
+		body = data
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if c.cfg.Username != "" {
+		req.Header.Set("X-ClickHouse-User", c.cfg.Username)
+		req.Header.Set("X-ClickHouse-Key", c.cfg.Password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(raw))
+		if len(msg) > clickHouseMaxErrorBody {
+			msg = msg[:clickHouseMaxErrorBody]
+		}
+		return nil, fmt.Errorf("clickhouse returned status %d: %s", resp.StatusCode, msg)
+	}
+	return raw, nil
+}
+
+// checkReady returns an error if the connector is not connected yet.
+func (c *ClickHouseConnector) checkReady() error {
+	if state := c.initializer.State(); state != util.StateReady {
+		return fmt.Errorf("clickhouse is not connected (state: %s), errors: %v", state.String(), c.initializer.Errors())
+	}
+	return nil
+}
+
+func (c *ClickHouseConnector) Id() string {
+	return c.id
+}
+
+func (c *ClickHouseConnector) State() ConnectorState {
+	return initializerConnectorState(c.initializer)
+}
+
+func (c *ClickHouseConnector) Ping(ctx context.Context) error {
+	if err := c.checkReady(); err != nil {
+		return err
+	}
+	ctx, cancel := withOperationTimeout(ctx, c.getTimeout, ClickHouseDriverName, "getTimeout")
+	defer cancel()
+	_, err := c.query(ctx, "SELECT 1", nil, nil)
+	return err
+}
+
+// clickHouseExpiresAt returns the expiry of an entry written at now, in
+// seconds rounded up. Entries without a TTL never expire.
+func clickHouseExpiresAt(now time.Time, ttl *time.Duration) uint32 {
+	if ttl == nil || *ttl <= 0 {
+		return clickHouseNeverExpires
+	}
+	at := now.Add(*ttl)
+	seconds := at.Unix()
+	if at.Nanosecond() > 0 {
+		seconds++
+	}
+	if seconds >= clickHouseNeverExpires {
+		return clickHouseNeverExpires - 1
+	}
+	return uint32(seconds)
+}
+
+// Set buffers the entry for the next batch insert. It fails only when the
+// connector is not connected or the buffer is full.
+func (c *ClickHouseConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	_, span := common.StartSpan(ctx, "ClickHouseConnector.Set")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+		)
+	}
+
+	err := c.enqueue([]KeyValuePair{{PartitionKey: partitionKey, RangeKey: rangeKey, Value: value}}, ttl)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return err
+}
+
+// SetMany buffers all items at once: either all of them fit in the buffer
+// or none is written.
+func (c *ClickHouseConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	_, span := common.StartSpan(ctx, "ClickHouseConnector.SetMany")
+	defer span.End()
+	span.SetAttributes(attribute.Int("items", len(items)))
+
+	err := c.enqueue(uniqueKeyValuePairs(items), ttl)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return err
+}
+
+func (c *ClickHouseConnector) enqueue(items []KeyValuePair, ttl *time.Duration) error {
+	if err := c.checkReady(); err != nil {
+		return err
+	}
+	now := time.Now()
+	expiresAt := clickHouseExpiresAt(now, ttl)
+
+	c.mu.Lock()
+	added := 0
+	for _, item := range items {
+		if _, ok := c.pending[clickHouseKey{item.PartitionKey, item.RangeKey}]; !ok {
+			added++
+		}
+	}
+	if len(c.pending)+added > c.cfg.MaxBufferedRows {
+		c.mu.Unlock()
+		telemetry.MetricConnectorBufferedRowsTotal.WithLabelValues(c.id, "rejected").Add(float64(len(items)))
+		return fmt.Errorf("clickhouse insert buffer is full (%d rows waiting)", c.cfg.MaxBufferedRows)
+	}
+	for _, item := range items {
+		c.pending[clickHouseKey{item.PartitionKey, item.RangeKey}] = clickHouseRow{
+			partitionKey: item.PartitionKey,
+			rangeKey:     item.RangeKey,
+			// The value is kept until the flush, past the caller's request.
+			value:      append([]byte(nil), item.Value...),
+			insertedAt: now,
+			expiresAt:  expiresAt,
+		}
+	}
+	full := len(c.pending) >= c.cfg.BatchSize
+	c.mu.Unlock()
+
+	c.logger.Debug().Int("items", len(items)).Interface("ttl", ttl).Msg("buffered items for clickhouse insert")
+	if full {
+		select {
+		case c.flushC <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// flushLoop inserts the buffered rows every flushInterval, or as soon as a
+// batch is full, until the app shuts down.
+func (c *ClickHouseConnector) flushLoop(ctx context.Context) {
+	ticker := util.NewTicker(c.cfg.FlushInterval.Duration())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), clickHouseFinalFlushTimeout)
+			c.flush(fctx)
+			cancel()
+			return
+		case <-ticker.C():
+			c.flush(ctx)
+		case <-c.flushC:
+			c.flush(ctx)
+		}
+	}
+}
+
+// flush inserts the buffered rows in batches of batchSize. Failed batches
+// are not retried: the entries are cache data, and a later miss is served
+// by upstreams.
+func (c *ClickHouseConnector) flush(ctx context.Context) {
+	c.mu.Lock()
+	if len(c.pending) == 0 {
+		c.mu.Unlock()
+		return
+	}
+	rows := make([]clickHouseRow, 0, len(c.pending))
+	for _, row := range c.pending {
+		rows = append(rows, row)
+	}
+	c.pending = make(map[clickHouseKey]clickHouseRow)
+	c.mu.Unlock()
+
+	for start := 0; start < len(rows); start += c.cfg.BatchSize {
+		batch := rows[start:min(start+c.cfg.BatchSize, len(rows))]
+		if err := c.insert(ctx, batch); err != nil {
+			telemetry.MetricConnectorBufferedRowsTotal.WithLabelValues(c.id, "failed").Add(float64(len(batch)))
+			c.logger.Warn().Err(err).Int("rows", len(batch)).Msg("failed to insert rows into clickhouse")
+			continue
+		}
+		telemetry.MetricConnectorBufferedRowsTotal.WithLabelValues(c.id, "inserted").Add(float64(len(batch)))
+	}
+}
+
+func (c *ClickHouseConnector) insert(ctx context.Context, rows []clickHouseRow) error {
+	ctx, span := common.StartSpan(ctx, "ClickHouseConnector.Insert")
+	defer span.End()
+	span.SetAttributes(attribute.Int("rows", len(rows)))
+
+	ctx, cancel := withOperationTimeout(ctx, c.setTimeout, ClickHouseDriverName, "setTimeout")
+	defer cancel()
+	_, err := c.query(ctx, fmt.Sprintf(`INSERT INTO %s (partition_key, range_key, value, inserted_at, expires_at) FORMAT RowBinary`, c.table), nil, encodeClickHouseRows(rows))
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return err
+}
+
+// encodeClickHouseRows encodes rows in the RowBinary format: strings are
+// length-prefixed, DateTime64(3) is little-endian int64 milliseconds and
+// DateTime little-endian uint32 seconds.
+func encodeClickHouseRows(rows []clickHouseRow) []byte {
+	size := 0
+	for _, row := range rows {
+		size += len(row.partitionKey) + len(row.rangeKey) + len(row.value) + 3*binary.MaxVarintLen64 + 12
+	}
+	buf := make([]byte, 0, size)
+	for _, row := range rows {
+		buf = appendClickHouseString(buf, []byte(row.partitionKey))
+		buf = appendClickHouseString(buf, []byte(row.rangeKey))
+		buf = appendClickHouseString(buf, row.value)
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(row.insertedAt.UnixMilli()))
+		buf = binary.LittleEndian.AppendUint32(buf, row.expiresAt)
+	}
+	return buf
+}
+
+func appendClickHouseString(buf, s []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// readClickHouseString reads one RowBinary string. It returns io.EOF when r
+// is at the end of the response.
+func readClickHouseString(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	s := make([]byte, n)
+	if _, err := io.ReadFull(r, s); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return s, nil
+}
+
+// Get reads the latest row of an entry or, on the reverse index, of the
+// entry with the given range key whose partition key matches partitionKey
+// (a trailing "*" matches a prefix). When several match, the greatest
+// partition key wins, as with DynamoDB. Rows still in the insert buffer are
+// only found by main index lookups.
+func (c *ClickHouseConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
+	ctx, span := common.StartSpan(ctx, "ClickHouseConnector.Get")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("index", index),
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+		)
+	}
+
+	if err := c.checkReady(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	var stmt string
+	params := map[string]string{"rk": rangeKey}
+	if index == ConnectorReverseIndex {
+		if rangeKey == "" || strings.HasSuffix(rangeKey, "*") {
+			err := fmt.Errorf("when using reverse index rangeKey must be a non-empty string and not contain wildcards (rangeKey: '%s', partitionKey: '%s')", rangeKey, partitionKey)
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		}
+		where := ""
+		switch {
+		case partitionKey == "" || partitionKey == "*":
+		case strings.HasSuffix(partitionKey, "*"):
+			where = ` AND startsWith(partition_key, {pk:String})`
+			params["pk"] = strings.TrimSuffix(partitionKey, "*")
+		default:
+			where = ` AND partition_key = {pk:String}`
+			params["pk"] = partitionKey
+		}
+		stmt = fmt.Sprintf(`SELECT value, expires_at FROM %s WHERE range_key = {rk:String}%s ORDER BY partition_key DESC, inserted_at DESC LIMIT 1 FORMAT RowBinary`, c.table, where)
+	} else {
+		c.mu.Lock()
+		row, ok := c.pending[clickHouseKey{partitionKey, rangeKey}]
+		c.mu.Unlock()
+		if ok && int64(row.expiresAt) > now {
+			return row.value, nil
+		}
+		stmt = fmt.Sprintf(`SELECT value, expires_at FROM %s WHERE partition_key = {pk:String} AND range_key = {rk:String} ORDER BY inserted_at DESC LIMIT 1 FORMAT RowBinary`, c.table)
+		params["pk"] = partitionKey
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, c.getTimeout, ClickHouseDriverName, "getTimeout")
+	defer cancel()
+
+	c.logger.Debug().Str("index", index).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("getting item from clickhouse")
+	raw, err := c.query(ctx, stmt, params, nil)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+	if len(raw) == 0 {
+		err := common.NewErrRecordNotFound(partitionKey, rangeKey, ClickHouseDriverName)
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+	r := bufio.NewReader(bytes.NewReader(raw))
+	value, err := readClickHouseString(r)
+	if err != nil {
+		err = fmt.Errorf("failed to decode clickhouse row: %w", err)
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+	var expiresAt uint32
+	if err := binary.Read(r, binary.LittleEndian, &expiresAt); err != nil {
+		err = fmt.Errorf("failed to decode clickhouse row: %w", err)
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+	if int64(expiresAt) <= now {
+		err := common.NewErrRecordExpired(partitionKey, rangeKey, ClickHouseDriverName, now, int64(expiresAt))
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(attribute.Int("value_size", len(value)))
+	}
+	return value, nil
+}
+
+// Delete drops the buffered row of the entry and runs a lightweight DELETE
+// for the stored ones (ClickHouse 23.3 or later).
+func (c *ClickHouseConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	ctx, span := common.StartSpan(ctx, "ClickHouseConnector.Delete")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+		)
+	}
+
+	if err := c.checkReady(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	c.mu.Lock()
+	delete(c.pending, clickHouseKey{partitionKey, rangeKey})
+	c.mu.Unlock()
+
+	c.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("deleting item from clickhouse")
+
+	ctx, cancel := withOperationTimeout(ctx, c.setTimeout, ClickHouseDriverName, "setTimeout")
+	defer cancel()
+	_, err := c.query(ctx, fmt.Sprintf(`DELETE FROM %s WHERE partition_key = {pk:String} AND range_key = {rk:String}`, c.table),
+		map[string]string{"pk": partitionKey, "rk": rangeKey}, nil)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return err
+}
+
+// DeleteByPrefix counts the live entries under the prefix, then deletes
+// them with one lightweight DELETE. Buffered rows under the prefix are
+// dropped too but not counted.
+func (c *ClickHouseConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	ctx, span := common.StartSpan(ctx, "ClickHouseConnector.DeleteByPrefix")
+	defer span.End()
+	span.SetAttributes(attribute.String("partition_key_prefix", partitionKeyPrefix))
+
+	if err := c.checkReady(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return 0, err
+	}
+
+	c.mu.Lock()
+	for k := range c.pending {
+		if strings.HasPrefix(k.partitionKey, partitionKeyPrefix) {
+			delete(c.pending, k)
+		}
+	}
+	c.mu.Unlock()
+
+	ctx, cancel := withOperationTimeout(ctx, c.setTimeout, ClickHouseDriverName, "setTimeout")
+	defer cancel()
+
+	params := map[string]string{"prefix": partitionKeyPrefix}
+	raw, err := c.query(ctx, fmt.Sprintf(`SELECT count() FROM (
+		SELECT 1 FROM %s WHERE startsWith(partition_key, {prefix:String})
+		GROUP BY partition_key, range_key
+		HAVING argMax(expires_at, inserted_at) > now()
+	) FORMAT TabSeparated`, c.table), params, nil)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return 0, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+	if err != nil {
+		err = fmt.Errorf("invalid clickhouse count %q: %w", raw, err)
+		common.SetTraceSpanError(span, err)
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if _, err := c.query(ctx, fmt.Sprintf(`DELETE FROM %s WHERE startsWith(partition_key, {prefix:String})`, c.table), params, nil); err != nil {
+		common.SetTraceSpanError(span, err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// List pages through the table in key order for either index, as the
+// reverse index is not a separate table.
+func (c *ClickHouseConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "ClickHouseConnector.List")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("index", index),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	if err := validateScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	items, next, err := c.scanPage(ctx, "", "", limit, paginationToken)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return items, next, err
+}
+
+// Scan pages through the latest live row of each entry in key order, with
+// prefixes matched by startsWith and keyset pagination on the sort key.
+// Rows still in the insert buffer are not included.
+func (c *ClickHouseConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "ClickHouseConnector.Scan")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key_prefix", partitionKeyPrefix),
+			attribute.String("range_key_prefix", rangeKeyPrefix),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	if err := validateScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	items, next, err := c.scanPage(ctx, partitionKeyPrefix, rangeKeyPrefix, limit, cursor)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return items, next, err
+}
+
+func (c *ClickHouseConnector) scanPage(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	if err := c.checkReady(); err != nil {
+		return nil, "", err
+	}
+	after, err := decodeScanCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	params := map[string]string{"pp": partitionKeyPrefix, "rp": rangeKeyPrefix}
+	where := `startsWith(partition_key, {pp:String}) AND startsWith(range_key, {rp:String})`
+	if after != nil {
+		where += ` AND (partition_key, range_key) > ({cpk:String}, {crk:String})`
+		params["cpk"] = after.PartitionKey
+		params["crk"] = after.RangeKey
+	}
+	stmt := fmt.Sprintf(`SELECT partition_key, range_key, argMax(value, inserted_at) FROM %s
+		WHERE %s
+		GROUP BY partition_key, range_key
+		HAVING argMax(expires_at, inserted_at) > now()
+		ORDER BY partition_key, range_key
+		LIMIT %d FORMAT RowBinary`, c.table, where, limit)
+
+	ctx, cancel := withOperationTimeout(ctx, c.getTimeout, ClickHouseDriverName, "getTimeout")
+	defer cancel()
+	raw, err := c.query(ctx, stmt, params, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	results, err := decodeClickHouseKeyValues(raw)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(results) < limit {
+		return results, "", nil
+	}
+	last := results[len(results)-1]
+	next, err := encodeScanCursor(last.PartitionKey, last.RangeKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return results, next, nil
+}
+
+// decodeClickHouseKeyValues decodes RowBinary rows of (partition_key,
+// range_key, value).
+func decodeClickHouseKeyValues(raw []byte) ([]KeyValuePair, error) {
+	r := bufio.NewReader(bytes.NewReader(raw))
+	var results []KeyValuePair
+	for {
+		pk, err := readClickHouseString(r)
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode clickhouse row: %w", err)
+		}
+		rk, err := readClickHouseString(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode clickhouse row: %w", io.ErrUnexpectedEOF)
+		}
+		value, err := readClickHouseString(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode clickhouse row: %w", io.ErrUnexpectedEOF)
+		}
+		results = append(results, KeyValuePair{PartitionKey: string(pk), RangeKey: string(rk), Value: value})
+	}
+}
+
+func (c *ClickHouseConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	return nil, fmt.Errorf("clickhouse connector does not support locks")
+}
+
+func (c *ClickHouseConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	ch := make(chan CounterInt64State)
+	return ch, func() {}, fmt.Errorf("clickhouse connector does not support WatchCounterInt64")
+}
+
+func (c *ClickHouseConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return fmt.Errorf("clickhouse connector does not support PublishCounterInt64")
+}
@@ -0,0 +1,137 @@
+package data
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickHouseExpiresAt(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	ttl := func(d time.Duration) *time.Duration { return &d }
+
+	assert.Equal(t, uint32(clickHouseNeverExpires), clickHouseExpiresAt(now, nil))
+	assert.Equal(t, uint32(clickHouseNeverExpires), clickHouseExpiresAt(now, ttl(0)))
+	assert.Equal(t, uint32(1700000060), clickHouseExpiresAt(now, ttl(time.Minute)))
+	assert.Equal(t, uint32(1700000001), clickHouseExpiresAt(now, ttl(time.Millisecond)), "sub-second TTLs round up rather than expiring at once")
+	assert.Equal(t, uint32(clickHouseNeverExpires-1), clickHouseExpiresAt(now, ttl(200*365*24*time.Hour)))
+}
+
+func TestClickHousePartitionExpr(t *testing.T) {
+	assert.Equal(t, "toYYYYMMDD(expires_at)", clickHousePartitionExpr("day"))
+	assert.Equal(t, "toMonday(expires_at)", clickHousePartitionExpr("week"))
+	assert.Equal(t, "toYYYYMM(expires_at)", clickHousePartitionExpr("month"))
+}
+
+func TestDecodeClickHouseKeyValues(t *testing.T) {
+	var raw []byte
+	for _, s := range []string{"evm:1:0x1", "eth_getBlockByNumber", "\x00\xff", "evm:1:0x2", "eth_getLogs", ""} {
+		raw = appendClickHouseString(raw, []byte(s))
+	}
+	items, err := decodeClickHouseKeyValues(raw)
+	require.NoError(t, err)
+	assert.Equal(t, []KeyValuePair{
+		{PartitionKey: "evm:1:0x1", RangeKey: "eth_getBlockByNumber", Value: []byte("\x00\xff")},
+		{PartitionKey: "evm:1:0x2", RangeKey: "eth_getLogs", Value: []byte{}},
+	}, items)
+
+	_, err = decodeClickHouseKeyValues(raw[:len(raw)-3])
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+// clickHouseTestServer answers the connector's statements like an empty
+// ClickHouse and records the bodies of the inserts into the cache table.
+type clickHouseTestServer struct {
+	mu      sync.Mutex
+	inserts [][]byte
+	srv     *httptest.Server
+}
+
+func newClickHouseTestServer(t *testing.T) *clickHouseTestServer {
+	s := &clickHouseTestServer{}
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		stmt := r.URL.Query().Get("query")
+		if stmt == "" {
+			stmt = string(body)
+		}
+		switch {
+		case strings.HasPrefix(stmt, "INSERT INTO `default`.`erpc_json_rpc_cache`"):
+			s.mu.Lock()
+			s.inserts = append(s.inserts, body)
+			s.mu.Unlock()
+		case strings.Contains(stmt, "max(version)"):
+			_, _ = w.Write([]byte("0\n"))
+		}
+	}))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+func (s *clickHouseTestServer) insertedBatches() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte{}, s.inserts...)
+}
+
+func TestClickHouseConnector_BufferedInserts(t *testing.T) {
+	srv := newClickHouseTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	cfg := &common.ClickHouseConnectorConfig{Url: srv.srv.URL, BatchSize: 2, FlushInterval: common.Duration(time.Hour), MaxBufferedRows: 3}
+	require.NoError(t, (&common.ConnectorConfig{Id: "ch", ClickHouse: cfg}).SetDefaults("cache"))
+	require.NoError(t, cfg.Validate())
+	c, err := NewClickHouseConnector(ctx, &log.Logger, "ch", cfg)
+	require.NoError(t, err)
+	require.Equal(t, ConnectorStateHealthy, c.State())
+
+	ttl := time.Hour
+	require.NoError(t, c.Set(ctx, "evm:1:100", "eth_getBlockByNumber", []byte("block"), &ttl))
+	value, err := c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("block"), value, "buffered rows are readable before they are inserted")
+	assert.Empty(t, srv.insertedBatches())
+
+	require.NoError(t, c.Set(ctx, "evm:1:100", "eth_getBlockReceipts", []byte("receipts"), nil))
+	require.Eventually(t, func() bool { return len(srv.insertedBatches()) == 1 }, 5*time.Second, 10*time.Millisecond,
+		"a full batch is inserted without waiting for flushInterval")
+
+	batch := srv.insertedBatches()[0]
+	rows := map[string]uint32{}
+	for len(batch) > 0 {
+		var fields [3]string
+		for i := range fields {
+			n, read := binary.Uvarint(batch)
+			fields[i] = string(batch[read : read+int(n)])
+			batch = batch[read+int(n):]
+		}
+		rows[fields[1]+"="+fields[2]] = binary.LittleEndian.Uint32(batch[8:12])
+		batch = batch[12:]
+	}
+	require.Len(t, rows, 2)
+	assert.Greater(t, rows["eth_getBlockByNumber=block"], uint32(time.Now().Unix()))
+	assert.Equal(t, uint32(clickHouseNeverExpires), rows["eth_getBlockReceipts=receipts"])
+
+	_, err = c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber", nil)
+	assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), "inserted rows are read from the table")
+
+	err = c.SetMany(ctx, []KeyValuePair{
+		{PartitionKey: "evm:1:101", RangeKey: "a"},
+		{PartitionKey: "evm:1:101", RangeKey: "b"},
+		{PartitionKey: "evm:1:101", RangeKey: "c"},
+		{PartitionKey: "evm:1:101", RangeKey: "d"},
+	}, nil)
+	assert.ErrorContains(t, err, "buffer is full")
+}
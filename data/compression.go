@@ -0,0 +1,245 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog"
+)
+
+// compressedValuePrefix marks a compressed value; the algorithm byte and the
+// compressed payload follow it. Like TombstoneValue it starts with a NUL
+// byte, which no cached value does.
+var compressedValuePrefix = []byte("\x00erpc:z\x00")
+
+const (
+	compressionAlgoZstd   byte = 'z'
+	compressionAlgoSnappy byte = 's'
+)
+
+// zstdFrameMagic starts every zstd frame. Values that already are zstd
+// frames (the EVM cache's own compression) are not compressed again.
+var zstdFrameMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// compressionDecoder decodes zstd values whatever level they were written
+// with; DecodeAll is safe for concurrent use.
+var compressionDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+
+// IsCompressedValue reports whether value was written by a
+// CompressionConnector.
+func IsCompressedValue(value []byte) bool {
+	return len(value) > len(compressedValuePrefix) && bytes.HasPrefix(value, compressedValuePrefix)
+}
+
+// CompressionConnector compresses values of at least the configured
+// threshold before writing them to the wrapped connector, and decompresses
+// them on reads. Reads detect compressed values by their header, so values
+// written before compression was enabled, or with another algorithm, are
+// returned correctly. See common.ConnectorCompressionConfig.
+type CompressionConnector struct {
+	wrapped   Connector
+	logger    *zerolog.Logger
+	algorithm byte
+	algoName  string
+	threshold int
+	encoder   *zstd.Encoder
+}
+
+var _ Connector = (*CompressionConnector)(nil)
+var _ CacheHeadReporter = (*CompressionConnector)(nil)
+var _ GuardedSetter = (*CompressionConnector)(nil)
+
+func NewCompressionConnector(
+	logger *zerolog.Logger,
+	wrapped Connector,
+	cfg *common.ConnectorCompressionConfig,
+) (*CompressionConnector, error) {
+	threshold, err := util.ParseByteSize(cfg.Threshold)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compression threshold: %w", err)
+	}
+	lg := logger.With().Str("component", "compressionConnector").Str("connectorId", wrapped.Id()).Logger()
+	c := &CompressionConnector{
+		wrapped:   wrapped,
+		logger:    &lg,
+		algoName:  cfg.Algorithm,
+		threshold: threshold,
+	}
+	switch cfg.Algorithm {
+	case "snappy":
+		c.algorithm = compressionAlgoSnappy
+	case "zstd", "":
+		c.algorithm = compressionAlgoZstd
+		c.algoName = "zstd"
+		level := zstd.SpeedFastest
+		switch cfg.ZstdLevel {
+		case "default":
+			level = zstd.SpeedDefault
+		case "better":
+			level = zstd.SpeedBetterCompression
+		case "best":
+			level = zstd.SpeedBestCompression
+		}
+		// EncodeAll is safe for concurrent use, so one encoder serves all writes.
+		c.encoder, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(level), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm %q", cfg.Algorithm)
+	}
+	return c, nil
+}
+
+// compress returns the value to store: compressed with its header, or value
+// itself when it is below the threshold, a marker (tombstone, pointer), an
+// existing zstd frame, or does not get smaller.
+func (c *CompressionConnector) compress(value []byte) []byte {
+	if len(value) < c.threshold || len(value) == 0 || value[0] == 0 || bytes.HasPrefix(value, zstdFrameMagic) {
+		return value
+	}
+	out := make([]byte, 0, len(compressedValuePrefix)+1+len(value)/2)
+	out = append(out, compressedValuePrefix...)
+	out = append(out, c.algorithm)
+	switch c.algorithm {
+	case compressionAlgoSnappy:
+		out = append(out, snappy.Encode(nil, value)...)
+	default:
+		out = c.encoder.EncodeAll(value, out)
+	}
+
+	telemetry.MetricConnectorCompressionBytesTotal.WithLabelValues(c.wrapped.Id(), c.algoName, "original").Add(float64(len(value)))
+	if len(out) >= len(value) {
+		telemetry.MetricConnectorCompressionBytesTotal.WithLabelValues(c.wrapped.Id(), c.algoName, "stored").Add(float64(len(value)))
+		return value
+	}
+	telemetry.MetricConnectorCompressionBytesTotal.WithLabelValues(c.wrapped.Id(), c.algoName, "stored").Add(float64(len(out)))
+	return out
+}
+
+// decompressValue returns the original of a compressed value, and any other
+// value as is.
+func decompressValue(value []byte) ([]byte, error) {
+	if !IsCompressedValue(value) {
+		return value, nil
+	}
+	payload := value[len(compressedValuePrefix)+1:]
+	switch algo := value[len(compressedValuePrefix)]; algo {
+	case compressionAlgoZstd:
+		return compressionDecoder.DecodeAll(payload, nil)
+	case compressionAlgoSnappy:
+		return snappy.Decode(nil, payload)
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %q", algo)
+	}
+}
+
+func (c *CompressionConnector) Id() string {
+	return c.wrapped.Id()
+}
+
+func (c *CompressionConnector) State() ConnectorState {
+	return c.wrapped.State()
+}
+
+func (c *CompressionConnector) Ping(ctx context.Context) error {
+	return c.wrapped.Ping(ctx)
+}
+
+func (c *CompressionConnector) CacheLatestBlockTimestamp(networkId string) (int64, bool) {
+	if r, ok := c.wrapped.(CacheHeadReporter); ok {
+		return r.CacheLatestBlockTimestamp(networkId)
+	}
+	return 0, false
+}
+
+// Get treats a value that cannot be decompressed as a miss, so a corrupt
+// entry is refetched and overwritten rather than failing every read.
+func (c *CompressionConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error) {
+	value, err := c.wrapped.Get(ctx, index, partitionKey, rangeKey, metadata)
+	if err != nil {
+		return nil, err
+	}
+	out, err := decompressValue(value)
+	if err != nil {
+		c.logger.Warn().Err(err).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("could not decompress value, treating it as a miss")
+		return nil, common.NewErrRecordNotFound(partitionKey, rangeKey, c.wrapped.Id())
+	}
+	return out, nil
+}
+
+func (c *CompressionConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	return c.wrapped.Set(ctx, partitionKey, rangeKey, c.compress(value), ttl)
+}
+
+func (c *CompressionConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	return SetGuarded(ctx, c.wrapped, partitionKey, rangeKey, c.compress(value), guard, ttl)
+}
+
+func (c *CompressionConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	batch := make([]KeyValuePair, len(items))
+	for i, item := range items {
+		item.Value = c.compress(item.Value)
+		batch[i] = item
+	}
+	return c.wrapped.SetMany(ctx, batch, ttl)
+}
+
+func (c *CompressionConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	return c.wrapped.Delete(ctx, partitionKey, rangeKey)
+}
+
+func (c *CompressionConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return c.wrapped.DeleteByPrefix(ctx, partitionKeyPrefix)
+}
+
+func (c *CompressionConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	items, next, err := c.wrapped.List(ctx, index, limit, paginationToken)
+	if err != nil {
+		return nil, "", err
+	}
+	return c.decompressAll(items), next, nil
+}
+
+func (c *CompressionConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	items, next, err := c.wrapped.Scan(ctx, partitionKeyPrefix, rangeKeyPrefix, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	return c.decompressAll(items), next, nil
+}
+
+// decompressAll decompresses items in place, dropping the ones that cannot
+// be decompressed.
+func (c *CompressionConnector) decompressAll(items []KeyValuePair) []KeyValuePair {
+	kept := items[:0]
+	for _, kv := range items {
+		value, err := decompressValue(kv.Value)
+		if err != nil {
+			c.logger.Debug().Err(err).Str("partitionKey", kv.PartitionKey).Str("rangeKey", kv.RangeKey).Msg("skipping value that could not be decompressed")
+			continue
+		}
+		kv.Value = value
+		kept = append(kept, kv)
+	}
+	return kept
+}
+
+func (c *CompressionConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	return c.wrapped.Lock(ctx, key, ttl)
+}
+
+func (c *CompressionConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	return c.wrapped.WatchCounterInt64(ctx, key)
+}
+
+func (c *CompressionConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return c.wrapped.PublishCounterInt64(ctx, key, value)
+}
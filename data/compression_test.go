@@ -0,0 +1,97 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressionConnector(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	ctx := context.Background()
+	large := []byte(`{"jsonrpc":"2.0","result":"` + strings.Repeat("0x00000000000000000000000000000000", 100) + `"}`)
+
+	newConnector := func(t *testing.T, algorithm string) (*CompressionConnector, *MemoryConnector) {
+		mem, err := NewMemoryConnector(ctx, &logger, "test", &common.MemoryConnectorConfig{
+			MaxItems: 1000, MaxTotalSize: "10MB",
+		})
+		require.NoError(t, err)
+		cfg := &common.ConnectorCompressionConfig{Algorithm: algorithm}
+		cfg.SetDefaults()
+		cc, err := NewCompressionConnector(&logger, mem, cfg)
+		require.NoError(t, err)
+		return cc, mem
+	}
+
+	for _, algorithm := range []string{"zstd", "snappy"} {
+		t.Run("RoundTrip_"+algorithm, func(t *testing.T) {
+			cc, mem := newConnector(t, algorithm)
+			require.NoError(t, cc.Set(ctx, "evm:1:100", "eth_call:h", large, nil))
+			mem.cache.Wait()
+
+			raw, err := mem.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+			require.NoError(t, err)
+			require.True(t, IsCompressedValue(raw))
+			require.Less(t, len(raw), len(large))
+
+			got, err := cc.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+			require.NoError(t, err)
+			require.Equal(t, large, got)
+		})
+	}
+
+	t.Run("ValuesBelowThresholdAreStoredAsIs", func(t *testing.T) {
+		cc, mem := newConnector(t, "zstd")
+		small := []byte(`"0x1"`)
+		require.NoError(t, cc.Set(ctx, "evm:1:100", "eth_call:s", small, nil))
+		mem.cache.Wait()
+
+		raw, err := mem.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:s", nil)
+		require.NoError(t, err)
+		require.Equal(t, small, raw)
+	})
+
+	t.Run("MarkersAreStoredAsIs", func(t *testing.T) {
+		cc, _ := newConnector(t, "zstd")
+		marker := append([]byte(TombstoneValue), bytes.Repeat([]byte{'a'}, 2048)...)
+		require.Equal(t, marker, cc.compress(marker))
+	})
+
+	t.Run("ReadsValuesWrittenWithoutCompressionOrAnotherAlgorithm", func(t *testing.T) {
+		cc, mem := newConnector(t, "zstd")
+		require.NoError(t, mem.Set(ctx, "evm:1:100", "eth_call:plain", large, nil))
+		snappyCc, err := NewCompressionConnector(&logger, mem, &common.ConnectorCompressionConfig{Algorithm: "snappy", Threshold: "1KB"})
+		require.NoError(t, err)
+		require.NoError(t, snappyCc.Set(ctx, "evm:1:100", "eth_call:snappy", large, nil))
+		mem.cache.Wait()
+
+		for _, rangeKey := range []string{"eth_call:plain", "eth_call:snappy"} {
+			got, err := cc.Get(ctx, ConnectorMainIndex, "evm:1:100", rangeKey, nil)
+			require.NoError(t, err)
+			require.Equal(t, large, got)
+		}
+	})
+
+	t.Run("CorruptValueReadsAsMiss", func(t *testing.T) {
+		cc, mem := newConnector(t, "zstd")
+		corrupt := append(append([]byte{}, compressedValuePrefix...), compressionAlgoZstd, 1, 2, 3)
+		require.NoError(t, mem.Set(ctx, "evm:1:100", "eth_call:bad", corrupt, nil))
+		require.NoError(t, cc.Set(ctx, "evm:1:100", "eth_call:good", large, nil))
+		mem.cache.Wait()
+
+		_, err := cc.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:bad", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+
+		items, _, err := cc.Scan(ctx, "evm:1:", "", 10, "")
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		require.Equal(t, "eth_call:good", items[0].RangeKey)
+		require.Equal(t, large, items[0].Value)
+	})
+}
@@ -2,9 +2,13 @@ package data
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
 	"github.com/erpc/erpc/util"
 	"github.com/rs/zerolog"
 )
@@ -25,6 +29,43 @@ type KeyValuePair struct {
 	Value        []byte
 }
 
+// scanCursor is the position of the last entry returned by Scan, for drivers
+// that page in key order.
+type scanCursor struct {
+	PartitionKey string `json:"p"`
+	RangeKey     string `json:"r"`
+}
+
+func encodeScanCursor(partitionKey, rangeKey string) (string, error) {
+	b, err := common.SonicCfg.Marshal(scanCursor{PartitionKey: partitionKey, RangeKey: rangeKey})
+	if err != nil {
+		return "", fmt.Errorf("failed to create scan cursor: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func validateScanLimit(limit int) error {
+	if limit <= 0 {
+		return fmt.Errorf("scan limit must be greater than 0 (got %d)", limit)
+	}
+	return nil
+}
+
+func decodeScanCursor(cursor string) (*scanCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scan cursor: %w", err)
+	}
+	var c scanCursor
+	if err := common.SonicCfg.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid scan cursor format: %w", err)
+	}
+	return &c, nil
+}
+
 // CounterInt64State is the canonical JSON payload stored for shared int64 counters.
 //
 // NOTE:
@@ -37,14 +78,51 @@ type CounterInt64State struct {
 	UpdatedBy string `json:"b,omitempty"`
 }
 
+// ConnectorState is the readiness of a connector as reported by State.
+type ConnectorState string
+
+const (
+	// ConnectorStateInitializing is reported until the first connection
+	// attempt completes.
+	ConnectorStateInitializing ConnectorState = "initializing"
+	// ConnectorStateHealthy is reported while the backend is connected.
+	ConnectorStateHealthy ConnectorState = "healthy"
+	// ConnectorStateDegraded is reported while the backend, or a part of it,
+	// is unreachable and the connector retries in the background.
+	ConnectorStateDegraded ConnectorState = "degraded"
+)
+
+var connectorStates = []ConnectorState{ConnectorStateInitializing, ConnectorStateHealthy, ConnectorStateDegraded}
+
 type Connector interface {
 	Id() string
+	// State reports the connector's readiness without any I/O.
+	State() ConnectorState
+	// Ping checks that the backend answers, with a cheap round trip where
+	// the driver has one.
+	Ping(ctx context.Context) error
 	Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error)
 	// Note if "value" is going to be stored/kept in memory for longer than response lifecycle it must be
 	// copied to a new memory location because B2Str is used to provide "value" as a string reference.
 	Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error
+	// SetMany stores every item with the same ttl, using the driver's native
+	// batch write where it has one. Items are not written atomically: on error
+	// some of them may have been stored. When a key appears more than once the
+	// last item wins.
+	SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error
 	Delete(ctx context.Context, partitionKey, rangeKey string) error
+	// DeleteByPrefix deletes every entry whose partition key starts with
+	// partitionKeyPrefix (an empty prefix matches everything) and returns how
+	// many were deleted. It is not atomic: entries written while it runs may
+	// survive, and on error some entries may already be gone.
+	DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error)
 	List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error)
+	// Scan pages through main-index entries whose partition key starts with
+	// partitionKeyPrefix and whose range key starts with rangeKeyPrefix (empty
+	// prefixes match everything). Pass the returned cursor back to continue;
+	// an empty cursor means the scan is complete. A page may hold fewer than
+	// limit entries, or none, while more remain.
+	Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error)
 	Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error)
 	WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error)
 	PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error
@@ -60,6 +138,160 @@ type CacheHeadReporter interface {
 	CacheLatestBlockTimestamp(networkId string) (unixSeconds int64, ok bool)
 }
 
+// GuardedSetter is an optional capability of connectors that can write a
+// value conditionally on the guard of the entry already stored under the same
+// key, in a single atomic operation of the backend. See CacheRecordGuard.
+type GuardedSetter interface {
+	// SetGuarded stores value unless the live entry under the key was written
+	// by SetGuarded with a higher guard, and reports whether it was stored.
+	SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error)
+}
+
+// initializerConnectorState maps the state of a driver's connect task to a
+// ConnectorState. A connector that was never ready and is retrying is
+// degraded, not initializing, so a backend that is down at startup shows up.
+func initializerConnectorState(i *util.Initializer) ConnectorState {
+	if i == nil {
+		return ConnectorStateInitializing
+	}
+	switch i.State() {
+	case util.StateReady:
+		return ConnectorStateHealthy
+	case util.StateUninitialized, util.StateInitializing:
+		return ConnectorStateInitializing
+	default:
+		return ConnectorStateDegraded
+	}
+}
+
+// worstConnectorState returns the least ready of states, for connectors
+// composed of several backends.
+func worstConnectorState(states ...ConnectorState) ConnectorState {
+	worst := ConnectorStateHealthy
+	for _, s := range states {
+		switch s {
+		case ConnectorStateDegraded:
+			return ConnectorStateDegraded
+		case ConnectorStateInitializing:
+			worst = ConnectorStateInitializing
+		}
+	}
+	return worst
+}
+
+// connectorStateReportInterval is how often reportConnectorState refreshes
+// the connector state gauge.
+const connectorStateReportInterval = 10 * time.Second
+
+// reportConnectorState keeps erpc_connector_state up to date until ctx is
+// done.
+func reportConnectorState(ctx context.Context, connector Connector) {
+	report := func() {
+		current := connector.State()
+		for _, s := range connectorStates {
+			v := 0.0
+			if s == current {
+				v = 1
+			}
+			telemetry.MetricConnectorState.WithLabelValues(connector.Id(), string(s)).Set(v)
+		}
+	}
+	report()
+	ticker := util.NewTicker(connectorStateReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			report()
+		}
+	}
+}
+
+// setEach is SetMany for drivers without a batch write: it stores the items one
+// by one and reports every failure.
+func setEach(ctx context.Context, items []KeyValuePair, ttl *time.Duration, set func(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error) error {
+	var errs []error
+	for _, item := range items {
+		if err := set(ctx, item.PartitionKey, item.RangeKey, item.Value, ttl); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deleteByPrefixScanPage is how many keys deleteByScan reads per Scan page.
+const deleteByPrefixScanPage = 500
+
+// deleteByScan is DeleteByPrefix for drivers without a native prefix delete:
+// it pages through Scan and deletes every entry with del, so wrappers keep
+// their own Delete semantics (overflow objects, tombstones).
+func deleteByScan(
+	ctx context.Context,
+	scan func(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error),
+	del func(ctx context.Context, partitionKey, rangeKey string) error,
+	partitionKeyPrefix string,
+) (int, error) {
+	deleted := 0
+	cursor := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+		items, next, err := scan(ctx, partitionKeyPrefix, "", deleteByPrefixScanPage, cursor)
+		if err != nil {
+			return deleted, err
+		}
+		for _, item := range items {
+			if err := del(ctx, item.PartitionKey, item.RangeKey); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+		if next == "" {
+			return deleted, nil
+		}
+		cursor = next
+	}
+}
+
+// uniqueKeyValuePairs drops all but the last item of every key, since batch
+// writes (BatchWriteItem, INSERT ... ON CONFLICT) reject a key that appears
+// twice in one request.
+func uniqueKeyValuePairs(items []KeyValuePair) []KeyValuePair {
+	type key struct{ pk, rk string }
+	last := make(map[key]int, len(items))
+	for i, item := range items {
+		last[key{item.PartitionKey, item.RangeKey}] = i
+	}
+	if len(last) == len(items) {
+		return items
+	}
+	out := make([]KeyValuePair, 0, len(last))
+	for i, item := range items {
+		if last[key{item.PartitionKey, item.RangeKey}] == i {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// withOperationTimeout bounds a connector operation by min(timeout, the caller's
+// remaining deadline), so a cache read or write never outlives the request it
+// serves. When the caller's deadline is the tighter one (or timeout is not set)
+// the operation is only bound by the caller, which keeps the caller's own cause
+// on the context instead of a connector timeout that never really applied.
+func withOperationTimeout(ctx context.Context, timeout time.Duration, driver, timeoutName string) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	if dl, ok := ctx.Deadline(); ok && time.Until(dl) <= timeout {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%s connector %s of %s exceeded", driver, timeoutName, timeout))
+}
+
 func NewConnector(
 	ctx context.Context,
 	logger *zerolog.Logger,
@@ -68,6 +300,9 @@ func NewConnector(
 	var connector Connector
 	var err error
 
+	lg := logger.Sample(common.LogLevels.Sampler(common.LogTarget{Kind: common.LogTargetConnector, Id: cfg.Id}))
+	logger = &lg
+
 	switch cfg.Driver {
 	case common.DriverMemory:
 		connector, err = NewMemoryConnector(ctx, logger, cfg.Id, cfg.Memory)
@@ -79,6 +314,20 @@ func NewConnector(
 		connector, err = NewPostgreSQLConnector(ctx, logger, cfg.Id, cfg.PostgreSQL)
 	case common.DriverGrpc:
 		connector, err = NewGrpcConnector(ctx, logger, cfg.Id, cfg.Grpc)
+	case common.DriverTiered:
+		connector, err = NewTieredConnector(ctx, logger, cfg.Id, cfg.Tiered)
+	case common.DriverMemcached:
+		connector, err = NewMemcachedConnector(ctx, logger, cfg.Id, cfg.Memcached)
+	case common.DriverCassandra:
+		connector, err = NewCassandraConnector(ctx, logger, cfg.Id, cfg.Cassandra)
+	case common.DriverMongoDB:
+		connector, err = NewMongoDBConnector(ctx, logger, cfg.Id, cfg.MongoDB)
+	case common.DriverBadger:
+		connector, err = NewBadgerConnector(ctx, logger, cfg.Id, cfg.Badger)
+	case common.DriverClickHouse:
+		connector, err = NewClickHouseConnector(ctx, logger, cfg.Id, cfg.ClickHouse)
+	case common.DriverLayered:
+		connector, err = NewLayeredConnector(ctx, logger, cfg.Id, cfg.Layered)
 	default:
 		if util.IsTest() && cfg.Driver == "mock" {
 			connector, err = NewMockMemoryConnector(ctx, logger, "mock", cfg.Mock)
@@ -99,5 +348,42 @@ func NewConnector(
 		}
 	}
 
+	// The breaker sits outside failsafe so that an operation counts once,
+	// whatever its retries, and an open breaker skips them altogether.
+	if cfg.CircuitBreaker != nil && cfg.CircuitBreaker.Enabled != nil && *cfg.CircuitBreaker.Enabled {
+		connector = NewBreakerConnector(ctx, logger, connector, cfg.CircuitBreaker)
+	}
+
+	if cfg.Overflow != nil {
+		connector, err = NewOverflowConnector(ctx, logger, connector, cfg.Overflow)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Encryption sits outside overflow so S3 objects are encrypted too, and
+	// inside compression because ciphertext does not compress.
+	if cfg.Encryption != nil {
+		connector, err = NewEncryptionConnector(ctx, logger, connector, cfg.Encryption)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Compression sits outside overflow so the threshold applies to the
+	// compressed size, and inside tombstones so markers are written as is.
+	if cfg.Compression != nil {
+		connector, err = NewCompressionConnector(logger, connector, cfg.Compression)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Tombstones != nil {
+		connector = NewTombstoneConnector(ctx, logger, connector, cfg.Tombstones)
+	}
+
+	go reportConnectorState(ctx, connector)
+
 	return connector, nil
 }
@@ -0,0 +1,32 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitializerConnectorState(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.Equal(t, ConnectorStateInitializing, initializerConnectorState(nil))
+
+	ready := util.NewInitializer(ctx, &log.Logger, nil)
+	_ = ready.ExecuteTasks(ctx, util.NewBootstrapTask("ok", func(ctx context.Context) error { return nil }))
+	assert.Equal(t, ConnectorStateHealthy, initializerConnectorState(ready))
+
+	failing := util.NewInitializer(ctx, &log.Logger, nil)
+	_ = failing.ExecuteTasks(ctx, util.NewBootstrapTask("down", func(ctx context.Context) error { return errors.New("connection refused") }))
+	assert.Equal(t, ConnectorStateDegraded, initializerConnectorState(failing))
+}
+
+func TestWorstConnectorState(t *testing.T) {
+	assert.Equal(t, ConnectorStateHealthy, worstConnectorState(ConnectorStateHealthy, ConnectorStateHealthy))
+	assert.Equal(t, ConnectorStateInitializing, worstConnectorState(ConnectorStateHealthy, ConnectorStateInitializing))
+	assert.Equal(t, ConnectorStateDegraded, worstConnectorState(ConnectorStateInitializing, ConnectorStateDegraded, ConnectorStateHealthy))
+}
@@ -0,0 +1,52 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOperationTimeout(t *testing.T) {
+	t.Run("ConnectorTimeoutIsTighter", func(t *testing.T) {
+		parent, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		ctx, opCancel := withOperationTimeout(parent, 10*time.Millisecond, RedisDriverName, "getTimeout")
+		defer opCancel()
+
+		dl, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(10*time.Millisecond), dl, 10*time.Millisecond)
+		<-ctx.Done()
+		assert.ErrorContains(t, context.Cause(ctx), "redis connector getTimeout of 10ms exceeded")
+	})
+
+	t.Run("CallerDeadlineIsTighter", func(t *testing.T) {
+		callerErr := errors.New("request deadline")
+		parent, cancel := context.WithTimeoutCause(context.Background(), 10*time.Millisecond, callerErr)
+		defer cancel()
+		parentDl, _ := parent.Deadline()
+
+		ctx, opCancel := withOperationTimeout(parent, time.Minute, PostgreSQLDriverName, "setTimeout")
+		defer opCancel()
+
+		dl, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.Equal(t, parentDl, dl, "the operation must not outlive the caller")
+		<-ctx.Done()
+		assert.ErrorIs(t, context.Cause(ctx), callerErr, "the caller's cause is kept")
+	})
+
+	t.Run("NoTimeoutConfigured", func(t *testing.T) {
+		ctx, opCancel := withOperationTimeout(context.Background(), 0, DynamoDBDriverName, "getTimeout")
+		defer opCancel()
+
+		_, ok := ctx.Deadline()
+		assert.False(t, ok, "a zero timeout must not expire the operation immediately")
+		assert.NoError(t, ctx.Err())
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -12,10 +13,10 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/util"
 	"github.com/rs/zerolog"
@@ -29,13 +30,21 @@ const (
 )
 
 var _ Connector = (*DynamoDBConnector)(nil)
+var _ GuardedSetter = (*DynamoDBConnector)(nil)
+
+// dynamoGuardAttr holds the guard of items written by SetGuarded.
+const dynamoGuardAttr = "finalityGuard"
+
+// errDynamoGuardRejected is returned by guarded writes whose condition
+// failed because the stored item has a higher guard.
+var errDynamoGuardRejected = errors.New("dynamodb item has a higher finality guard")
 
 type DynamoDBConnector struct {
 	id                string
 	logger            *zerolog.Logger
 	initializer       *util.Initializer
-	writeClient       *dynamodb.DynamoDB
-	readClient        *dynamodb.DynamoDB
+	writeClient       dynamodbiface.DynamoDBAPI
+	readClient        dynamodbiface.DynamoDBAPI
 	table             string
 	ttlAttributeName  string
 	partitionKeyName  string
@@ -46,6 +55,7 @@ type DynamoDBConnector struct {
 	setTimeout        time.Duration
 	statePollInterval time.Duration
 	lockRetryInterval time.Duration
+	chunkSize         int
 }
 
 var _ DistributedLock = &dynamoLock{}
@@ -105,6 +115,14 @@ func NewDynamoDBConnector(
 		setTimeout:        cfg.SetTimeout.Duration(),
 		statePollInterval: cfg.StatePollInterval.Duration(),
 		lockRetryInterval: cfg.LockRetryInterval.Duration(),
+		chunkSize:         dynamoDefaultChunkSize,
+	}
+	if cfg.ChunkSize != "" {
+		chunkSize, err := util.ParseByteSize(cfg.ChunkSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dynamodb chunkSize: %w", err)
+		}
+		connector.chunkSize = chunkSize
 	}
 
 	// create an Initializer to handle (re)connecting
@@ -129,14 +147,14 @@ func (d *DynamoDBConnector) connectTask(ctx context.Context, cfg *common.DynamoD
 		return common.NewTaskFatal(err)
 	}
 
-	d.writeClient = dynamodb.New(sess, &aws.Config{
+	writeClient := dynamodb.New(sess, &aws.Config{
 		Endpoint:   aws.String(cfg.Endpoint),
 		HTTPClient: sharedWriteClient,
 		MaxRetries: aws.Int(cfg.MaxRetries),
 		Region:     aws.String(cfg.Region),
 	})
 
-	d.readClient = dynamodb.New(sess, &aws.Config{
+	readClient := dynamodb.New(sess, &aws.Config{
 		Endpoint:   aws.String(cfg.Endpoint),
 		HTTPClient: sharedReadClient,
 		MaxRetries: aws.Int(cfg.MaxRetries),
@@ -147,17 +165,98 @@ func (d *DynamoDBConnector) connectTask(ctx context.Context, cfg *common.DynamoD
 		return common.NewTaskFatal(fmt.Errorf("missing table name for dynamodb connector"))
 	}
 
-	err = createTableIfNotExists(ctx, d.logger, d.writeClient, cfg)
-	if err != nil {
-		return err
+	if cfg.Dax != nil {
+		fallback, err := newDaxFallback(d.logger, d.id, sess, cfg)
+		if err != nil {
+			return common.NewTaskFatal(fmt.Errorf("failed to create dax client: %w", err))
+		}
+		d.writeClient = fallback.wrap(writeClient)
+		d.readClient = fallback.wrap(readClient)
+	} else {
+		d.writeClient = writeClient
+		d.readClient = readClient
+	}
+
+	// DAX does not manage tables, so migrations always talk to DynamoDB.
+	versions := &dynamoDBSchemaVersions{client: writeClient, cfg: cfg}
+	return runSchemaMigrations(ctx, d.logger, d.id, versions, dynamoDBSchemaMigrations(d.logger, writeClient, cfg))
+}
+
+// dynamoDBSchemaMigrations lists the changes to the connector table in
+// order. Never edit or reorder a released migration; append a new one
+// instead.
+func dynamoDBSchemaMigrations(logger *zerolog.Logger, client *dynamodb.DynamoDB, cfg *common.DynamoDBConnectorConfig) []SchemaMigration {
+	return []SchemaMigration{
+		{
+			Version:     1,
+			Description: "create table with TTL",
+			Apply: func(ctx context.Context) error {
+				return createTableIfNotExists(ctx, logger, client, cfg)
+			},
+		},
+		{
+			Version:     2,
+			Description: "create reverse index",
+			Apply: func(ctx context.Context) error {
+				return ensureGlobalSecondaryIndexes(ctx, logger, client, cfg)
+			},
+		},
+	}
+}
+
+// The schema version is an item of the table itself, under a partition key
+// no cache or shared-state key uses. List and Scan skip it.
+const (
+	dynamoSchemaPartitionKey = "erpc:schema"
+	dynamoSchemaRangeKey     = "version"
+	dynamoSchemaVersionAttr  = "schemaVersion"
+)
+
+type dynamoDBSchemaVersions struct {
+	client *dynamodb.DynamoDB
+	cfg    *common.DynamoDBConnectorConfig
+}
+
+func (s *dynamoDBSchemaVersions) key() map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		s.cfg.PartitionKeyName: {S: aws.String(dynamoSchemaPartitionKey)},
+		s.cfg.RangeKeyName:     {S: aws.String(dynamoSchemaRangeKey)},
 	}
+}
 
-	err = ensureGlobalSecondaryIndexes(ctx, d.logger, d.writeClient, cfg)
+// SchemaVersion reports 0 when the table does not exist yet, which is what
+// the first migration creates.
+func (s *dynamoDBSchemaVersions) SchemaVersion(ctx context.Context) (int, error) {
+	out, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.cfg.Table),
+		Key:            s.key(),
+		ConsistentRead: aws.Bool(true),
+	})
 	if err != nil {
-		return err
+		if strings.Contains(err.Error(), dynamodb.ErrCodeResourceNotFoundException) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if v := out.Item[dynamoSchemaVersionAttr]; v != nil && v.N != nil {
+		return parseSchemaVersion(*v.N)
 	}
+	return 0, nil
+}
 
-	return nil
+func (s *dynamoDBSchemaVersions) SetSchemaVersion(ctx context.Context, version int) error {
+	item := s.key()
+	item[dynamoSchemaVersionAttr] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(version))}
+	_, err := s.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.cfg.Table),
+		Item:      item,
+	})
+	return err
+}
+
+func isDynamoSchemaItem(item map[string]*dynamodb.AttributeValue) bool {
+	_, ok := item[dynamoSchemaVersionAttr]
+	return ok
 }
 
 func createSession(cfg *common.DynamoDBConnectorConfig) (*session.Session, error) {
@@ -174,25 +273,21 @@ func createSession(cfg *common.DynamoDBConnectorConfig) (*session.Session, error
 			},
 		})
 	}
-	var creds *credentials.Credentials
-
-	switch cfg.Auth.Mode {
-	case "file":
-		creds = credentials.NewSharedCredentials(cfg.Auth.CredentialsFile, cfg.Auth.Profile)
-	case "env":
-		creds = credentials.NewEnvCredentials()
-	case "secret":
-		creds = credentials.NewStaticCredentials(cfg.Auth.AccessKeyID, cfg.Auth.SecretAccessKey, "")
-	default:
-		return nil, fmt.Errorf("unsupported auth.mode for store.dynamodb: %s", cfg.Auth.Mode)
+	httpClient := &http.Client{
+		Timeout: cfg.InitTimeout.Duration(),
+	}
+	creds, err := awsAuthCredentials(cfg.Auth, &aws.Config{
+		Region:     aws.String(cfg.Region),
+		HTTPClient: httpClient,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("store.dynamodb: %w", err)
 	}
 
 	return session.NewSession(&aws.Config{
 		Region:      aws.String(cfg.Region),
 		Credentials: creds,
-		HTTPClient: &http.Client{
-			Timeout: cfg.InitTimeout.Duration(),
-		},
+		HTTPClient:  httpClient,
 	})
 }
 
@@ -351,6 +446,28 @@ func (d *DynamoDBConnector) Id() string {
 	return d.id
 }
 
+func (d *DynamoDBConnector) State() ConnectorState {
+	return initializerConnectorState(d.initializer)
+}
+
+// Ping reads a key that is never written: a data-plane call that is cheap
+// and, unlike DescribeTable, not subject to control-plane rate limits.
+func (d *DynamoDBConnector) Ping(ctx context.Context) error {
+	if d.readClient == nil {
+		return fmt.Errorf("DynamoDB client not initialized yet")
+	}
+	ctx, cancel := withOperationTimeout(ctx, d.getTimeout, "dynamodb", "getTimeout")
+	defer cancel()
+	_, err := d.readClient.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			d.partitionKeyName: {S: aws.String("erpc:ping")},
+			d.rangeKeyName:     {S: aws.String("ping")},
+		},
+	})
+	return err
+}
+
 func (d *DynamoDBConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
 	ctx, span := common.StartSpan(ctx, "DynamoDBConnector.Set")
 	defer span.End()
@@ -375,6 +492,26 @@ func (d *DynamoDBConnector) Set(ctx context.Context, partitionKey, rangeKey stri
 		d.logger.Debug().Int("len", len(value)).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Interface("ttl", ttl).Msg("putting item in dynamodb")
 	}
 
+	ctx, cancel := withOperationTimeout(ctx, d.setTimeout, DynamoDBDriverName, "setTimeout")
+	defer cancel()
+
+	// Add TTL if provided
+	var ttlAttr *dynamodb.AttributeValue
+	if ttl != nil && *ttl > 0 {
+		expirationTime := util.Now().Add(*ttl).Unix()
+		ttlAttr = &dynamodb.AttributeValue{
+			N: aws.String(fmt.Sprintf("%d", expirationTime)),
+		}
+	}
+
+	if len(value) > d.chunkSize {
+		err := d.setChunked(ctx, partitionKey, rangeKey, value, ttlAttr, nil)
+		if err != nil {
+			common.SetTraceSpanError(span, err)
+		}
+		return err
+	}
+
 	item := map[string]*dynamodb.AttributeValue{
 		d.partitionKeyName: {
 			S: aws.String(partitionKey),
@@ -386,16 +523,8 @@ func (d *DynamoDBConnector) Set(ctx context.Context, partitionKey, rangeKey stri
 			B: value, // Using Binary attribute type
 		},
 	}
-
-	ctx, cancel := context.WithTimeout(ctx, d.setTimeout)
-	defer cancel()
-
-	// Add TTL if provided
-	if ttl != nil && *ttl > 0 {
-		expirationTime := time.Now().Add(*ttl).Unix()
-		item[d.ttlAttributeName] = &dynamodb.AttributeValue{
-			N: aws.String(fmt.Sprintf("%d", expirationTime)),
-		}
+	if ttlAttr != nil {
+		item[d.ttlAttributeName] = ttlAttr
 	}
 
 	_, err := d.writeClient.PutItemWithContext(ctx, &dynamodb.PutItemInput{
@@ -410,6 +539,150 @@ func (d *DynamoDBConnector) Set(ctx context.Context, partitionKey, rangeKey stri
 	return err
 }
 
+// SetGuarded puts the item with a ConditionExpression on the guard of the
+// stored item; an item past its TTL that DynamoDB has not removed yet does not
+// hold a write back.
+func (d *DynamoDBConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	ctx, span := common.StartSpan(ctx, "DynamoDBConnector.SetGuarded")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+			attribute.Int64("guard", guard),
+		)
+	}
+
+	if d.writeClient == nil {
+		err := fmt.Errorf("DynamoDB client not initialized yet")
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+
+	d.logger.Debug().Int("len", len(value)).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int64("guard", guard).Interface("ttl", ttl).Msg("putting guarded item in dynamodb")
+
+	ctx, cancel := withOperationTimeout(ctx, d.setTimeout, DynamoDBDriverName, "setTimeout")
+	defer cancel()
+
+	var ttlAttr *dynamodb.AttributeValue
+	if ttl != nil && *ttl > 0 {
+		ttlAttr = &dynamodb.AttributeValue{
+			N: aws.String(fmt.Sprintf("%d", util.Now().Add(*ttl).Unix())),
+		}
+	}
+
+	var err error
+	if len(value) > d.chunkSize {
+		err = d.setChunked(ctx, partitionKey, rangeKey, value, ttlAttr, &guard)
+	} else {
+		item := map[string]*dynamodb.AttributeValue{
+			d.partitionKeyName: {S: aws.String(partitionKey)},
+			d.rangeKeyName:     {S: aws.String(rangeKey)},
+			"value":            {B: value},
+		}
+		if ttlAttr != nil {
+			item[d.ttlAttributeName] = ttlAttr
+		}
+		input := &dynamodb.PutItemInput{
+			TableName: aws.String(d.table),
+			Item:      item,
+		}
+		d.guardPutItem(input, guard)
+		_, err = d.writeClient.PutItemWithContext(ctx, input)
+		if isDynamoConditionalCheckFailed(err) {
+			err = errDynamoGuardRejected
+		}
+	}
+	if errors.Is(err, errDynamoGuardRejected) {
+		return false, nil
+	}
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// guardPutItem stores guard with the item and only lets the put replace an
+// item without a higher guard, or one that has expired.
+func (d *DynamoDBConnector) guardPutItem(input *dynamodb.PutItemInput, guard int64) {
+	input.Item[dynamoGuardAttr] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(guard, 10))}
+	input.ConditionExpression = aws.String("attribute_not_exists(#guard) OR #guard <= :guard OR #ttl < :now")
+	input.ExpressionAttributeNames = map[string]*string{
+		"#guard": aws.String(dynamoGuardAttr),
+		"#ttl":   aws.String(d.ttlAttributeName),
+	}
+	input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+		":guard": {N: aws.String(strconv.FormatInt(guard, 10))},
+		":now":   {N: aws.String(strconv.FormatInt(util.Now().Unix(), 10))},
+	}
+}
+
+func isDynamoConditionalCheckFailed(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
+
+// SetMany writes the items with BatchWriteItem, 25 per request. Values
+// above chunkSize still go through the chunked write one by one.
+func (d *DynamoDBConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	ctx, span := common.StartSpan(ctx, "DynamoDBConnector.SetMany")
+	defer span.End()
+	span.SetAttributes(attribute.Int("items", len(items)))
+
+	if d.writeClient == nil {
+		err := fmt.Errorf("DynamoDB client not initialized yet")
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	items = uniqueKeyValuePairs(items)
+	d.logger.Debug().Int("items", len(items)).Interface("ttl", ttl).Msg("batch putting items in dynamodb")
+
+	ctx, cancel := withOperationTimeout(ctx, d.setTimeout, DynamoDBDriverName, "setTimeout")
+	defer cancel()
+
+	var ttlAttr *dynamodb.AttributeValue
+	if ttl != nil && *ttl > 0 {
+		ttlAttr = &dynamodb.AttributeValue{
+			N: aws.String(fmt.Sprintf("%d", util.Now().Add(*ttl).Unix())),
+		}
+	}
+
+	var errs []error
+	requests := make([]*dynamodb.WriteRequest, 0, len(items))
+	for _, item := range items {
+		if len(item.Value) > d.chunkSize {
+			if err := d.setChunked(ctx, item.PartitionKey, item.RangeKey, item.Value, ttlAttr, nil); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		attrs := map[string]*dynamodb.AttributeValue{
+			d.partitionKeyName: {S: aws.String(item.PartitionKey)},
+			d.rangeKeyName:     {S: aws.String(item.RangeKey)},
+			"value":            {B: item.Value},
+		}
+		if ttlAttr != nil {
+			attrs[d.ttlAttributeName] = ttlAttr
+		}
+		requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: attrs}})
+	}
+	if len(requests) > 0 {
+		if err := d.batchWrite(ctx, requests); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return err
+}
+
 func (d *DynamoDBConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
 	ctx, span := common.StartSpan(ctx, "DynamoDBConnector.Get")
 	defer span.End()
@@ -461,7 +734,7 @@ func (d *DynamoDBConnector) Get(ctx context.Context, index, partitionKey, rangeK
 
 		// Build a query that returns newest first, requesting value and ttl.
 		// We fetch up to 10 items and will pick the first non-expired one.
-		now := time.Now().Unix()
+		now := util.Now().Unix()
 		qi := &dynamodb.QueryInput{
 			TableName:                 aws.String(d.table),
 			IndexName:                 aws.String(d.reverseIndexName),
@@ -470,17 +743,22 @@ func (d *DynamoDBConnector) Get(ctx context.Context, index, partitionKey, rangeK
 			ExpressionAttributeValues: exprAttrValues,
 			ScanIndexForward:          aws.Bool(false), // newest first
 			Limit:                     aws.Int64(10),   // examine a handful to avoid pagination
-			ProjectionExpression:      aws.String("#val, #ttl"),
+			ProjectionExpression:      aws.String("#pk, #rk, #val, #ttl, #cc, #cs, #vs"),
 			Select:                    aws.String("SPECIFIC_ATTRIBUTES"),
 			FilterExpression:          aws.String("attribute_not_exists(#ttl) OR #ttl = :zero OR #ttl > :now"),
 		}
 		// Add aliases required by Projection/Filter
+		qi.ExpressionAttributeNames["#pk"] = aws.String(d.partitionKeyName)
+		qi.ExpressionAttributeNames["#rk"] = aws.String(d.rangeKeyName)
 		qi.ExpressionAttributeNames["#val"] = aws.String("value")
+		qi.ExpressionAttributeNames["#cc"] = aws.String(dynamoChunkCountAttr)
+		qi.ExpressionAttributeNames["#cs"] = aws.String(dynamoChunkSetAttr)
+		qi.ExpressionAttributeNames["#vs"] = aws.String(dynamoValueSizeAttr)
 		qi.ExpressionAttributeNames["#ttl"] = aws.String(d.ttlAttributeName)
 		qi.ExpressionAttributeValues[":now"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now, 10))}
 		qi.ExpressionAttributeValues[":zero"] = &dynamodb.AttributeValue{N: aws.String("0")}
 
-		ctx, cancel := context.WithTimeout(ctx, d.getTimeout)
+		ctx, cancel := withOperationTimeout(ctx, d.getTimeout, DynamoDBDriverName, "getTimeout")
 		defer cancel()
 
 		d.logger.Debug().Str("index", d.reverseIndexName).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("getting item from dynamodb")
@@ -510,7 +788,18 @@ func (d *DynamoDBConnector) Get(ctx context.Context, index, partitionKey, rangeK
 		}
 
 		// Backward compatibility: check both B and S attributes
-		if chosen["value"].B != nil {
+		if m := dynamoChunkManifest(chosen); m != nil {
+			if chosen[d.partitionKeyName] == nil || chosen[d.partitionKeyName].S == nil {
+				return nil, fmt.Errorf("chunked item returned by reverse index has no partition key")
+			}
+			value, err = d.getChunked(ctx, *chosen[d.partitionKeyName].S, rangeKey, m)
+			if err != nil {
+				common.SetTraceSpanError(span, err)
+				return nil, err
+			}
+		} else if chosen["value"] == nil {
+			return nil, fmt.Errorf("value attribute is missing")
+		} else if chosen["value"].B != nil {
 			value = chosen["value"].B
 		} else if chosen["value"].S != nil {
 			// Legacy string value - treat as final decompressed value
@@ -547,7 +836,7 @@ func (d *DynamoDBConnector) Get(ctx context.Context, index, partitionKey, rangeK
 		// Check if the item has expired
 		if ttl, exists := result.Item[d.ttlAttributeName]; exists && ttl.N != nil && *ttl.N != "" && *ttl.N != "0" {
 			expirationTime, err := strconv.ParseInt(*ttl.N, 10, 64)
-			now := time.Now().Unix()
+			now := util.Now().Unix()
 			if err == nil && now > expirationTime {
 				err := common.NewErrRecordExpired(partitionKey, rangeKey, DynamoDBDriverName, now, expirationTime)
 				common.SetTraceSpanError(span, err)
@@ -556,7 +845,15 @@ func (d *DynamoDBConnector) Get(ctx context.Context, index, partitionKey, rangeK
 		}
 
 		// Backward compatibility: check both B and S attributes
-		if result.Item["value"].B != nil {
+		if m := dynamoChunkManifest(result.Item); m != nil {
+			value, err = d.getChunked(ctx, partitionKey, rangeKey, m)
+			if err != nil {
+				common.SetTraceSpanError(span, err)
+				return nil, err
+			}
+		} else if result.Item["value"] == nil {
+			return nil, fmt.Errorf("value attribute is missing")
+		} else if result.Item["value"].B != nil {
 			value = result.Item["value"].B
 		} else if result.Item["value"].S != nil {
 			// Legacy string value - treat as final decompressed value
@@ -609,12 +906,12 @@ func (d *DynamoDBConnector) Lock(ctx context.Context, key string, ttl time.Durat
 
 		// Calculate expiry time for the lock item itself IF it's acquired in this attempt.
 		// This 'ttl' is the duration the lock will be held.
-		lockItemExpiryTime := time.Now().Add(ttl).Unix()
+		lockItemExpiryTime := util.Now().Add(ttl).Unix()
 
 		// Context for the individual PutItem attempt.
 		// This is bounded by the connector's configured SetTimeout and the parent context 'ctx'.
 		// If 'ctx' finishes, PutItemWithContext will be interrupted.
-		putAttemptCtx, putAttemptCancel := context.WithTimeout(ctx, d.setTimeout)
+		putAttemptCtx, putAttemptCancel := withOperationTimeout(ctx, d.setTimeout, DynamoDBDriverName, "setTimeout")
 
 		_, err := d.writeClient.PutItemWithContext(putAttemptCtx, &dynamodb.PutItemInput{
 			TableName: aws.String(d.table),
@@ -631,7 +928,7 @@ func (d *DynamoDBConnector) Lock(ctx context.Context, key string, ttl time.Durat
 				"#expiry": aws.String("expiry"),
 			},
 			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-				":now": {N: aws.String(fmt.Sprintf("%d", time.Now().Unix()))},
+				":now": {N: aws.String(fmt.Sprintf("%d", util.Now().Unix()))},
 			},
 		})
 		putAttemptCancel() // Release resources for this attempt's context immediately
@@ -670,7 +967,7 @@ func (d *DynamoDBConnector) Lock(ctx context.Context, key string, ttl time.Durat
 		if retryableError {
 			// Wait for d.lockRetryInterval before retrying, but also respect parent context cancellation.
 			select {
-			case <-time.After(d.lockRetryInterval):
+			case <-util.After(d.lockRetryInterval):
 				// Continue to the next iteration of the loop to retry
 			case <-ctx.Done(): // Parent context was cancelled/timed out while waiting
 				wrappedErr := fmt.Errorf("lock acquisition timed out while waiting to retry for key '%s': %w", key, ctx.Err())
@@ -720,7 +1017,7 @@ func (d *DynamoDBConnector) WatchCounterInt64(ctx context.Context, key string) (
 	updates := make(chan CounterInt64State, 1)
 
 	// Start polling goroutine
-	ticker := time.NewTicker(d.statePollInterval)
+	ticker := util.NewTicker(d.statePollInterval)
 	done := make(chan struct{})
 
 	go func() {
@@ -732,7 +1029,7 @@ func (d *DynamoDBConnector) WatchCounterInt64(ctx context.Context, key string) (
 				return
 			case <-done:
 				return
-			case <-ticker.C:
+			case <-ticker.C():
 				st, ok, err := d.getSimpleValue(ctx, key)
 				if err != nil {
 					d.logger.Warn().Err(err).Str("key", key).Msg("failed to poll counter value")
@@ -829,6 +1126,12 @@ func (d *DynamoDBConnector) PublishCounterInt64(ctx context.Context, key string,
 	return nil
 }
 
+// DeleteByPrefix scans the table for matching items and deletes them one by
+// one, which also removes the chunks of chunked values.
+func (d *DynamoDBConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return deleteByScan(ctx, d.Scan, d.Delete, partitionKeyPrefix)
+}
+
 func (d *DynamoDBConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
 	ctx, span := common.StartSpan(ctx, "DynamoDBConnector.Delete")
 	defer span.End()
@@ -848,10 +1151,10 @@ func (d *DynamoDBConnector) Delete(ctx context.Context, partitionKey, rangeKey s
 
 	d.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("deleting item from dynamodb")
 
-	ctx, cancel := context.WithTimeout(ctx, d.setTimeout)
+	ctx, cancel := withOperationTimeout(ctx, d.setTimeout, DynamoDBDriverName, "setTimeout")
 	defer cancel()
 
-	_, err := d.writeClient.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+	out, err := d.writeClient.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
 		TableName: aws.String(d.table),
 		Key: map[string]*dynamodb.AttributeValue{
 			d.partitionKeyName: {
@@ -861,13 +1164,19 @@ func (d *DynamoDBConnector) Delete(ctx context.Context, partitionKey, rangeKey s
 				S: aws.String(rangeKey),
 			},
 		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
 	})
 
 	if err != nil {
 		common.SetTraceSpanError(span, err)
+		return err
 	}
 
-	return err
+	if m := dynamoChunkManifest(out.Attributes); m != nil {
+		d.deleteChunks(ctx, partitionKey, rangeKey, m)
+	}
+
+	return nil
 }
 
 func (d *DynamoDBConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
@@ -887,24 +1196,15 @@ func (d *DynamoDBConnector) List(ctx context.Context, index string, limit int, p
 		return nil, "", err
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, d.getTimeout)
+	ctx, cancel := withOperationTimeout(ctx, d.getTimeout, DynamoDBDriverName, "getTimeout")
 	defer cancel()
 
-	// Parse pagination token
-	var exclusiveStartKey map[string]*dynamodb.AttributeValue
-	if paginationToken != "" {
-		decoded, err := base64.StdEncoding.DecodeString(paginationToken)
-		if err != nil {
-			return nil, "", fmt.Errorf("invalid pagination token: %w", err)
-		}
-		err = json.Unmarshal(decoded, &exclusiveStartKey)
-		if err != nil {
-			return nil, "", fmt.Errorf("invalid pagination token format: %w", err)
-		}
+	exclusiveStartKey, err := decodeDynamoDBPaginationToken(paginationToken)
+	if err != nil {
+		return nil, "", err
 	}
 
 	var result *dynamodb.ScanOutput
-	var err error
 
 	if index == ConnectorReverseIndex {
 		// Use the reverse index (GSI)
@@ -928,8 +1228,82 @@ func (d *DynamoDBConnector) List(ctx context.Context, index string, limit int, p
 		return nil, "", err
 	}
 
+	return d.scanPage(ctx, result)
+}
+
+// Scan runs a table scan filtered with begins_with on both keys. DynamoDB
+// applies the filter after reading each page, so pages are often smaller than
+// limit; only an empty cursor marks the end.
+func (d *DynamoDBConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "DynamoDBConnector.Scan")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key_prefix", partitionKeyPrefix),
+			attribute.String("range_key_prefix", rangeKeyPrefix),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	if d.readClient == nil {
+		err := fmt.Errorf("DynamoDB client not initialized yet")
+		common.SetTraceSpanError(span, err)
+		return nil, "", err
+	}
+
+	if err := validateScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	exclusiveStartKey, err := decodeDynamoDBPaginationToken(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:         aws.String(d.table),
+		Limit:             aws.Int64(int64(limit)),
+		ExclusiveStartKey: exclusiveStartKey,
+	}
+	var filters []string
+	if partitionKeyPrefix != "" {
+		filters = append(filters, "begins_with(#pkey, :pkey)")
+	}
+	if rangeKeyPrefix != "" {
+		filters = append(filters, "begins_with(#rkey, :rkey)")
+	}
+	if len(filters) > 0 {
+		input.FilterExpression = aws.String(strings.Join(filters, " AND "))
+		input.ExpressionAttributeNames = map[string]*string{}
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{}
+		if partitionKeyPrefix != "" {
+			input.ExpressionAttributeNames["#pkey"] = aws.String(d.partitionKeyName)
+			input.ExpressionAttributeValues[":pkey"] = &dynamodb.AttributeValue{S: aws.String(partitionKeyPrefix)}
+		}
+		if rangeKeyPrefix != "" {
+			input.ExpressionAttributeNames["#rkey"] = aws.String(d.rangeKeyName)
+			input.ExpressionAttributeValues[":rkey"] = &dynamodb.AttributeValue{S: aws.String(rangeKeyPrefix)}
+		}
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, d.getTimeout, DynamoDBDriverName, "getTimeout")
+	defer cancel()
+
+	result, err := d.readClient.ScanWithContext(ctx, input)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, "", err
+	}
+
+	return d.scanPage(ctx, result)
+}
+
+// scanPage converts a scan page into non-expired key-value pairs and the
+// token of the next page. Chunks are skipped and chunked values reassembled
+// under their manifest's key.
+func (d *DynamoDBConnector) scanPage(ctx context.Context, result *dynamodb.ScanOutput) ([]KeyValuePair, string, error) {
 	results := make([]KeyValuePair, 0, len(result.Items))
-	now := time.Now().Unix()
+	now := util.Now().Unix()
 
 	for _, item := range result.Items {
 		// Check if item has expired
@@ -950,9 +1324,20 @@ func (d *DynamoDBConnector) List(ctx context.Context, index string, limit int, p
 			rangeKey = *item[d.rangeKeyName].S
 		}
 
+		if isDynamoChunkItem(item) || isDynamoSchemaItem(item) {
+			continue
+		}
+
 		// Extract value
 		var value []byte
-		if item["value"] != nil {
+		if m := dynamoChunkManifest(item); m != nil {
+			var err error
+			value, err = d.getChunked(ctx, partitionKey, rangeKey, m)
+			if err != nil {
+				d.logger.Debug().Err(err).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("skipping chunked item that could not be reassembled")
+				continue
+			}
+		} else if item["value"] != nil {
 			if item["value"].B != nil {
 				value = item["value"].B
 			} else if item["value"].S != nil {
@@ -981,3 +1366,18 @@ func (d *DynamoDBConnector) List(ctx context.Context, index string, limit int, p
 
 	return results, nextToken, nil
 }
+
+func decodeDynamoDBPaginationToken(token string) (map[string]*dynamodb.AttributeValue, error) {
+	if token == "" {
+		return nil, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pagination token: %w", err)
+	}
+	var exclusiveStartKey map[string]*dynamodb.AttributeValue
+	if err := json.Unmarshal(decoded, &exclusiveStartKey); err != nil {
+		return nil, fmt.Errorf("invalid pagination token format: %w", err)
+	}
+	return exclusiveStartKey, nil
+}
@@ -0,0 +1,274 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+)
+
+// Values larger than chunkSize are stored as a manifest item under the
+// original key plus one item per chunk. Chunk range keys carry a per-write
+// chunk set id, so a reader never mixes chunks of two concurrent writes: the
+// manifest it read names exactly the chunks it needs, and chunks are always
+// written before the manifest that references them.
+const (
+	dynamoChunkCountAttr = "chunkCount"
+	dynamoChunkSetAttr   = "chunkSet"
+	dynamoChunkIndexAttr = "chunkIndex"
+	dynamoValueSizeAttr  = "valueSize"
+
+	dynamoDefaultChunkSize = 350 * 1024
+
+	// BatchWriteItem takes at most 25 requests and BatchGetItem 100 keys.
+	dynamoBatchWriteLimit = 25
+	dynamoBatchGetLimit   = 100
+	dynamoBatchAttempts   = 5
+)
+
+// dynamoChunk describes the chunks a manifest item points at.
+type dynamoChunk struct {
+	set   string
+	count int
+	size  int
+}
+
+func dynamoChunkRangeKey(rangeKey, set string, i int) string {
+	return fmt.Sprintf("%s#chunk:%s:%d", rangeKey, set, i)
+}
+
+// dynamoChunkManifest returns the chunk set referenced by item, or nil when
+// item holds its value inline.
+func dynamoChunkManifest(item map[string]*dynamodb.AttributeValue) *dynamoChunk {
+	countAttr, setAttr := item[dynamoChunkCountAttr], item[dynamoChunkSetAttr]
+	if countAttr == nil || countAttr.N == nil || setAttr == nil || setAttr.S == nil {
+		return nil
+	}
+	count, err := strconv.Atoi(*countAttr.N)
+	if err != nil || count <= 0 {
+		return nil
+	}
+	m := &dynamoChunk{set: *setAttr.S, count: count, size: -1}
+	if sizeAttr := item[dynamoValueSizeAttr]; sizeAttr != nil && sizeAttr.N != nil {
+		if size, err := strconv.Atoi(*sizeAttr.N); err == nil {
+			m.size = size
+		}
+	}
+	return m
+}
+
+// isDynamoChunkItem reports whether item is one of the chunks of a larger
+// value, which List and Scan must not return on their own.
+func isDynamoChunkItem(item map[string]*dynamodb.AttributeValue) bool {
+	_, ok := item[dynamoChunkIndexAttr]
+	return ok
+}
+
+func newDynamoChunkSet() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// setChunked writes value as chunks followed by a manifest item, then removes
+// the chunks of the value the manifest replaced. With a guard the manifest is
+// written conditionally (see SetGuarded); when the condition fails the new
+// chunks are removed and errDynamoGuardRejected is returned.
+func (d *DynamoDBConnector) setChunked(ctx context.Context, partitionKey, rangeKey string, value []byte, ttlAttr *dynamodb.AttributeValue, guard *int64) error {
+	set, err := newDynamoChunkSet()
+	if err != nil {
+		return err
+	}
+	count := (len(value) + d.chunkSize - 1) / d.chunkSize
+
+	requests := make([]*dynamodb.WriteRequest, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * d.chunkSize
+		if end > len(value) {
+			end = len(value)
+		}
+		item := map[string]*dynamodb.AttributeValue{
+			d.partitionKeyName:   {S: aws.String(partitionKey)},
+			d.rangeKeyName:       {S: aws.String(dynamoChunkRangeKey(rangeKey, set, i))},
+			"value":              {B: value[i*d.chunkSize : end]},
+			dynamoChunkSetAttr:   {S: aws.String(set)},
+			dynamoChunkIndexAttr: {N: aws.String(strconv.Itoa(i))},
+		}
+		if ttlAttr != nil {
+			item[d.ttlAttributeName] = ttlAttr
+		}
+		requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}})
+	}
+	d.logger.Debug().Int("len", len(value)).Int("chunks", count).Str("chunkSet", set).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("putting chunked item in dynamodb")
+	if err := d.batchWrite(ctx, requests); err != nil {
+		// Chunks that made it carry the same TTL as the value; without a TTL
+		// they are unreachable but remain until deleted.
+		return fmt.Errorf("failed to write %d chunks: %w", count, err)
+	}
+
+	manifest := map[string]*dynamodb.AttributeValue{
+		d.partitionKeyName:   {S: aws.String(partitionKey)},
+		d.rangeKeyName:       {S: aws.String(rangeKey)},
+		dynamoChunkCountAttr: {N: aws.String(strconv.Itoa(count))},
+		dynamoChunkSetAttr:   {S: aws.String(set)},
+		dynamoValueSizeAttr:  {N: aws.String(strconv.Itoa(len(value)))},
+	}
+	if ttlAttr != nil {
+		manifest[d.ttlAttributeName] = ttlAttr
+	}
+	input := &dynamodb.PutItemInput{
+		TableName:    aws.String(d.table),
+		Item:         manifest,
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+	}
+	if guard != nil {
+		d.guardPutItem(input, *guard)
+	}
+	out, err := d.writeClient.PutItemWithContext(ctx, input)
+	if err != nil {
+		if guard != nil && isDynamoConditionalCheckFailed(err) {
+			d.deleteChunks(ctx, partitionKey, rangeKey, &dynamoChunk{set: set, count: count})
+			return errDynamoGuardRejected
+		}
+		return err
+	}
+	if old := dynamoChunkManifest(out.Attributes); old != nil && old.set != set {
+		d.deleteChunks(ctx, partitionKey, rangeKey, old)
+	}
+	return nil
+}
+
+// getChunked reassembles the value of a manifest item. A missing chunk (not
+// yet visible, expired or deleted) is reported as a missing record.
+func (d *DynamoDBConnector) getChunked(ctx context.Context, partitionKey, rangeKey string, m *dynamoChunk) ([]byte, error) {
+	ctx, cancel := withOperationTimeout(ctx, d.getTimeout, DynamoDBDriverName, "getTimeout")
+	defer cancel()
+
+	chunks := make([][]byte, m.count)
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, m.count)
+	for i := 0; i < m.count; i++ {
+		keys = append(keys, d.chunkKey(partitionKey, rangeKey, m.set, i))
+	}
+	now := util.Now().Unix()
+	for start := 0; start < len(keys); start += dynamoBatchGetLimit {
+		end := start + dynamoBatchGetLimit
+		if end > len(keys) {
+			end = len(keys)
+		}
+		pending := map[string]*dynamodb.KeysAndAttributes{
+			d.table: {Keys: keys[start:end]},
+		}
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt >= dynamoBatchAttempts {
+				return nil, fmt.Errorf("dynamodb kept returning unprocessed keys for %d chunks", len(pending[d.table].Keys))
+			}
+			if attempt > 0 {
+				if err := dynamoBatchBackoff(ctx, attempt); err != nil {
+					return nil, err
+				}
+			}
+			out, err := d.readClient.BatchGetItemWithContext(ctx, &dynamodb.BatchGetItemInput{RequestItems: pending})
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range out.Responses[d.table] {
+				idx := item[dynamoChunkIndexAttr]
+				if idx == nil || idx.N == nil || item["value"] == nil {
+					continue
+				}
+				i, err := strconv.Atoi(*idx.N)
+				if err != nil || i < 0 || i >= m.count {
+					continue
+				}
+				if ttl, exists := item[d.ttlAttributeName]; exists && ttl.N != nil && *ttl.N != "" && *ttl.N != "0" {
+					if exp, perr := strconv.ParseInt(*ttl.N, 10, 64); perr == nil && now > exp {
+						continue
+					}
+				}
+				chunks[i] = item["value"].B
+			}
+			pending = out.UnprocessedKeys
+		}
+	}
+
+	value := make([]byte, 0, max(m.size, 0))
+	for i, chunk := range chunks {
+		if chunk == nil {
+			d.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Str("chunkSet", m.set).Int("chunk", i).Msg("chunk of dynamodb item is missing")
+			return nil, common.NewErrRecordNotFound(partitionKey, rangeKey, DynamoDBDriverName)
+		}
+		value = append(value, chunk...)
+	}
+	if m.size >= 0 && len(value) != m.size {
+		return nil, fmt.Errorf("reassembled %d bytes from %d chunks but manifest says %d", len(value), m.count, m.size)
+	}
+	return value, nil
+}
+
+// deleteChunks removes the chunks of a replaced or deleted value. It is best
+// effort: leftovers are unreachable and expire with their TTL.
+func (d *DynamoDBConnector) deleteChunks(ctx context.Context, partitionKey, rangeKey string, m *dynamoChunk) {
+	requests := make([]*dynamodb.WriteRequest, 0, m.count)
+	for i := 0; i < m.count; i++ {
+		requests = append(requests, &dynamodb.WriteRequest{
+			DeleteRequest: &dynamodb.DeleteRequest{Key: d.chunkKey(partitionKey, rangeKey, m.set, i)},
+		})
+	}
+	if err := d.batchWrite(ctx, requests); err != nil {
+		d.logger.Warn().Err(err).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Str("chunkSet", m.set).Int("chunks", m.count).Msg("failed to delete chunks of replaced dynamodb item")
+	}
+}
+
+func (d *DynamoDBConnector) chunkKey(partitionKey, rangeKey, set string, i int) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		d.partitionKeyName: {S: aws.String(partitionKey)},
+		d.rangeKeyName:     {S: aws.String(dynamoChunkRangeKey(rangeKey, set, i))},
+	}
+}
+
+// batchWrite sends requests in batches of 25, retrying unprocessed items.
+func (d *DynamoDBConnector) batchWrite(ctx context.Context, requests []*dynamodb.WriteRequest) error {
+	for start := 0; start < len(requests); start += dynamoBatchWriteLimit {
+		end := start + dynamoBatchWriteLimit
+		if end > len(requests) {
+			end = len(requests)
+		}
+		pending := map[string][]*dynamodb.WriteRequest{d.table: requests[start:end]}
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt >= dynamoBatchAttempts {
+				return fmt.Errorf("dynamodb kept returning %d unprocessed items", len(pending[d.table]))
+			}
+			if attempt > 0 {
+				if err := dynamoBatchBackoff(ctx, attempt); err != nil {
+					return err
+				}
+			}
+			out, err := d.writeClient.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return err
+			}
+			pending = out.UnprocessedItems
+		}
+	}
+	return nil
+}
+
+// dynamoBatchBackoff waits before resending unprocessed batch entries, which
+// DynamoDB returns when the table or partition is throttled.
+func dynamoBatchBackoff(ctx context.Context, attempt int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-util.After(time.Duration(attempt*attempt) * 25 * time.Millisecond):
+		return nil
+	}
+}
@@ -0,0 +1,183 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-dax-go/dax"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+)
+
+// daxErrCodeServiceUnavailable is what the DAX client returns when it has no
+// route to a cluster node.
+const daxErrCodeServiceUnavailable = "ServiceUnavailable"
+
+// daxItemAPI is the part of the DynamoDB API that DAX serves. The DAX client
+// predates some DynamoDB calls, so it does not satisfy dynamodbiface.
+type daxItemAPI interface {
+	GetItemWithContext(aws.Context, *dynamodb.GetItemInput, ...request.Option) (*dynamodb.GetItemOutput, error)
+	PutItemWithContext(aws.Context, *dynamodb.PutItemInput, ...request.Option) (*dynamodb.PutItemOutput, error)
+	DeleteItemWithContext(aws.Context, *dynamodb.DeleteItemInput, ...request.Option) (*dynamodb.DeleteItemOutput, error)
+	QueryWithContext(aws.Context, *dynamodb.QueryInput, ...request.Option) (*dynamodb.QueryOutput, error)
+	ScanWithContext(aws.Context, *dynamodb.ScanInput, ...request.Option) (*dynamodb.ScanOutput, error)
+	BatchGetItemWithContext(aws.Context, *dynamodb.BatchGetItemInput, ...request.Option) (*dynamodb.BatchGetItemOutput, error)
+	BatchWriteItemWithContext(aws.Context, *dynamodb.BatchWriteItemInput, ...request.Option) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+// daxFallback tracks whether a DAX cluster is answering; it is shared by the
+// read and write clients of a connector.
+type daxFallback struct {
+	connectorId string
+	logger      *zerolog.Logger
+	cluster     daxItemAPI
+	fallbackFor time.Duration
+	// downUntil is the unix nano time until which calls skip the cluster.
+	downUntil atomic.Int64
+}
+
+func newDaxFallback(logger *zerolog.Logger, connectorId string, sess *session.Session, cfg *common.DynamoDBConnectorConfig) (*daxFallback, error) {
+	dc := dax.DefaultConfig()
+	dc.HostPorts = []string{cfg.Dax.Endpoint}
+	dc.Region = cfg.Region
+	dc.Credentials = sess.Config.Credentials
+	cluster, err := dax.New(dc)
+	if err != nil {
+		return nil, err
+	}
+	return &daxFallback{
+		connectorId: connectorId,
+		logger:      logger,
+		cluster:     cluster,
+		fallbackFor: cfg.Dax.FallbackFor.Duration(),
+	}, nil
+}
+
+// wrap returns a client that sends item calls of direct through the cluster.
+func (f *daxFallback) wrap(direct dynamodbiface.DynamoDBAPI) *daxClient {
+	return &daxClient{DynamoDBAPI: direct, fallback: f}
+}
+
+func (f *daxFallback) available() bool {
+	return time.Now().UnixNano() >= f.downUntil.Load()
+}
+
+// markDown sends calls straight to DynamoDB for fallbackFor. Only the call
+// that flips the state logs it.
+func (f *daxFallback) markDown(operation string, err error) {
+	now := time.Now()
+	prev := f.downUntil.Load()
+	if f.downUntil.CompareAndSwap(prev, now.Add(f.fallbackFor).UnixNano()) && now.UnixNano() >= prev {
+		f.logger.Warn().Err(err).Str("operation", operation).Dur("fallbackFor", f.fallbackFor).Msg("dax cluster is unreachable; falling back to dynamodb")
+	}
+	telemetry.MetricDynamoDBDaxFallbackTotal.WithLabelValues(f.connectorId, operation).Inc()
+}
+
+// isDaxUnreachable tells cluster and network failures, which DynamoDB itself
+// may still serve, from errors of the call (conditional check failures,
+// validation, throttling) that DynamoDB would return just the same.
+func isDaxUnreachable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		if awsErr.Code() == daxErrCodeServiceUnavailable || awsErr.Code() == request.ErrCodeRequestError {
+			return true
+		}
+		if orig := awsErr.OrigErr(); orig != nil && orig != err {
+			return isDaxUnreachable(ctx, orig)
+		}
+	}
+	return false
+}
+
+// viaDax runs call against the cluster and retries it against DynamoDB when
+// the cluster does not answer.
+func viaDax[I any, O any](
+	ctx aws.Context,
+	c *daxClient,
+	operation string,
+	input I,
+	opts []request.Option,
+	call func(daxItemAPI) func(aws.Context, I, ...request.Option) (O, error),
+) (O, error) {
+	if c.fallback.available() {
+		// DAX rejects most request options (custom handlers); its calls are
+		// bounded by ctx instead.
+		out, err := call(c.fallback.cluster)(ctx, input)
+		if !isDaxUnreachable(ctx, err) {
+			return out, err
+		}
+		c.fallback.markDown(operation, err)
+	}
+	return call(c.DynamoDBAPI)(ctx, input, opts...)
+}
+
+// daxClient serves item reads and writes from a DAX cluster. Every other
+// call, table management included, goes to the embedded DynamoDB client,
+// since DAX does not implement them.
+type daxClient struct {
+	dynamodbiface.DynamoDBAPI
+	fallback *daxFallback
+}
+
+func (c *daxClient) GetItemWithContext(ctx aws.Context, in *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	return viaDax(ctx, c, "GetItem", in, opts, func(api daxItemAPI) func(aws.Context, *dynamodb.GetItemInput, ...request.Option) (*dynamodb.GetItemOutput, error) {
+		return api.GetItemWithContext
+	})
+}
+
+func (c *daxClient) PutItemWithContext(ctx aws.Context, in *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	return viaDax(ctx, c, "PutItem", in, opts, func(api daxItemAPI) func(aws.Context, *dynamodb.PutItemInput, ...request.Option) (*dynamodb.PutItemOutput, error) {
+		return api.PutItemWithContext
+	})
+}
+
+func (c *daxClient) DeleteItemWithContext(ctx aws.Context, in *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	return viaDax(ctx, c, "DeleteItem", in, opts, func(api daxItemAPI) func(aws.Context, *dynamodb.DeleteItemInput, ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+		return api.DeleteItemWithContext
+	})
+}
+
+func (c *daxClient) QueryWithContext(ctx aws.Context, in *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	return viaDax(ctx, c, "Query", in, opts, func(api daxItemAPI) func(aws.Context, *dynamodb.QueryInput, ...request.Option) (*dynamodb.QueryOutput, error) {
+		return api.QueryWithContext
+	})
+}
+
+func (c *daxClient) ScanWithContext(ctx aws.Context, in *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	return viaDax(ctx, c, "Scan", in, opts, func(api daxItemAPI) func(aws.Context, *dynamodb.ScanInput, ...request.Option) (*dynamodb.ScanOutput, error) {
+		return api.ScanWithContext
+	})
+}
+
+func (c *daxClient) BatchGetItemWithContext(ctx aws.Context, in *dynamodb.BatchGetItemInput, opts ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	return viaDax(ctx, c, "BatchGetItem", in, opts, func(api daxItemAPI) func(aws.Context, *dynamodb.BatchGetItemInput, ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+		return api.BatchGetItemWithContext
+	})
+}
+
+func (c *daxClient) BatchWriteItemWithContext(ctx aws.Context, in *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	return viaDax(ctx, c, "BatchWriteItem", in, opts, func(api daxItemAPI) func(aws.Context, *dynamodb.BatchWriteItemInput, ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+		return api.BatchWriteItemWithContext
+	})
+}
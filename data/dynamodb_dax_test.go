@@ -0,0 +1,98 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDaxGetItem struct {
+	daxItemAPI
+	calls int
+	err   error
+}
+
+func (f *fakeDaxGetItem) GetItemWithContext(aws.Context, *dynamodb.GetItemInput, ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{"from": {S: aws.String("dax")}}}, nil
+}
+
+type fakeDirectGetItem struct {
+	dynamodbiface.DynamoDBAPI
+	calls int
+}
+
+func (f *fakeDirectGetItem) GetItemWithContext(aws.Context, *dynamodb.GetItemInput, ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.calls++
+	return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{"from": {S: aws.String("dynamodb")}}}, nil
+}
+
+func TestDaxClient_Fallback(t *testing.T) {
+	ctx := context.Background()
+	newClient := func(clusterErr error, fallbackFor time.Duration) (*daxClient, *fakeDaxGetItem, *fakeDirectGetItem) {
+		cluster := &fakeDaxGetItem{err: clusterErr}
+		direct := &fakeDirectGetItem{}
+		fallback := &daxFallback{connectorId: "test", logger: &log.Logger, cluster: cluster, fallbackFor: fallbackFor}
+		return fallback.wrap(direct), cluster, direct
+	}
+	servedBy := func(t *testing.T, c *daxClient) string {
+		t.Helper()
+		out, err := c.GetItemWithContext(ctx, &dynamodb.GetItemInput{})
+		require.NoError(t, err)
+		return *out.Item["from"].S
+	}
+
+	t.Run("ServesFromClusterWhileItAnswers", func(t *testing.T) {
+		c, cluster, direct := newClient(nil, time.Minute)
+		assert.Equal(t, "dax", servedBy(t, c))
+		assert.Equal(t, 1, cluster.calls)
+		assert.Equal(t, 0, direct.calls)
+	})
+
+	t.Run("FallsBackAndSkipsClusterWhileDown", func(t *testing.T) {
+		c, cluster, direct := newClient(awserr.New(daxErrCodeServiceUnavailable, "No routes found", nil), time.Minute)
+		assert.Equal(t, "dynamodb", servedBy(t, c))
+		assert.Equal(t, "dynamodb", servedBy(t, c))
+		assert.Equal(t, 1, cluster.calls)
+		assert.Equal(t, 2, direct.calls)
+	})
+
+	t.Run("RetriesClusterAfterFallbackWindow", func(t *testing.T) {
+		c, cluster, _ := newClient(awserr.New(request.ErrCodeRequestError, "send request failed", errors.New("connection refused")), time.Millisecond)
+		assert.Equal(t, "dynamodb", servedBy(t, c))
+		time.Sleep(5 * time.Millisecond)
+		cluster.err = nil
+		assert.Equal(t, "dax", servedBy(t, c))
+	})
+
+	t.Run("ReturnsCallErrorsWithoutFallingBack", func(t *testing.T) {
+		c, cluster, direct := newClient(awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil), time.Minute)
+		_, err := c.GetItemWithContext(ctx, &dynamodb.GetItemInput{})
+		require.Error(t, err)
+		assert.Equal(t, 1, cluster.calls)
+		assert.Equal(t, 0, direct.calls)
+		assert.True(t, c.fallback.available())
+	})
+
+	t.Run("DoesNotFallBackWhenCallerGaveUp", func(t *testing.T) {
+		c, _, direct := newClient(context.Canceled, time.Minute)
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := c.GetItemWithContext(cctx, &dynamodb.GetItemInput{})
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, direct.calls)
+	})
+}
@@ -482,3 +482,190 @@ func TestDynamoDBDistributedLocking(t *testing.T) {
 		require.NoError(t, err, "unlock should succeed")
 	})
 }
+
+func TestDynamoDBConnectorChunking(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req := testcontainers.ContainerRequest{
+		Image:        "amazon/dynamodb-local",
+		ExposedPorts: []string{"8000/tcp"},
+		WaitingFor:   wait.ForListeningPort("8000/tcp"),
+	}
+	ddbC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	require.NoError(t, err, "failed to start DynamoDB Local container")
+	defer ddbC.Terminate(ctx)
+
+	host, err := ddbC.Host(ctx)
+	require.NoError(t, err)
+	port, err := ddbC.MappedPort(ctx, "8000")
+	require.NoError(t, err)
+
+	cfg := &common.DynamoDBConnectorConfig{
+		Endpoint:         fmt.Sprintf("http://%s:%s", host, port.Port()),
+		Region:           "us-west-2",
+		Table:            "test_chunking",
+		PartitionKeyName: "pk",
+		RangeKeyName:     "rk",
+		ReverseIndexName: "rk-pk-index",
+		TTLAttributeName: "ttl",
+		InitTimeout:      common.Duration(2 * time.Second),
+		GetTimeout:       common.Duration(2 * time.Second),
+		SetTimeout:       common.Duration(2 * time.Second),
+		ChunkSize:        "1KB",
+		Auth: &common.AwsAuthConfig{
+			Mode:            "secret",
+			AccessKeyID:     "fakeKey",
+			SecretAccessKey: "fakeSecret",
+		},
+	}
+	connector, err := NewDynamoDBConnector(ctx, &log.Logger, "test-chunking", cfg)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return connector.initializer.State() == util.StateReady
+	}, 5*time.Second, 100*time.Millisecond, "connector should be in ready state")
+
+	countItems := func(t *testing.T, pk string) (chunks int, total int) {
+		out, err := connector.readClient.ScanWithContext(ctx, &dynamodb.ScanInput{
+			TableName:                 aws.String(cfg.Table),
+			FilterExpression:          aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":pk": {S: aws.String(pk)}},
+		})
+		require.NoError(t, err)
+		for _, item := range out.Items {
+			if isDynamoChunkItem(item) {
+				chunks++
+			}
+		}
+		return chunks, len(out.Items)
+	}
+	large := []byte(strings.Repeat("0123456789", 450)) // 4500 bytes -> 5 chunks
+
+	t.Run("LargeValueRoundTrips", func(t *testing.T) {
+		ttl := time.Hour
+		require.NoError(t, connector.Set(ctx, "evm:1:100", "eth_getLogs:a", large, &ttl))
+
+		value, err := connector.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getLogs:a", nil)
+		require.NoError(t, err)
+		assert.Equal(t, large, value)
+
+		value, err = connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getLogs:a", nil)
+		require.NoError(t, err)
+		assert.Equal(t, large, value)
+
+		out, err := connector.readClient.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String(cfg.Table),
+			Key: map[string]*dynamodb.AttributeValue{
+				"pk": {S: aws.String("evm:1:100")},
+				"rk": {S: aws.String("eth_getLogs:a")},
+			},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, out.Item["value"], "manifest item must not hold the value")
+		assert.Equal(t, "5", *out.Item[dynamoChunkCountAttr].N)
+
+		chunks, _ := countItems(t, "evm:1:100")
+		assert.Equal(t, 5, chunks)
+	})
+
+	t.Run("ChunksCarryTheValueTTL", func(t *testing.T) {
+		ttl := time.Hour
+		require.NoError(t, connector.Set(ctx, "evm:1:200", "eth_getLogs:b", large, &ttl))
+		out, err := connector.readClient.ScanWithContext(ctx, &dynamodb.ScanInput{
+			TableName:                 aws.String(cfg.Table),
+			FilterExpression:          aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":pk": {S: aws.String("evm:1:200")}},
+		})
+		require.NoError(t, err)
+		require.Len(t, out.Items, 6)
+		for _, item := range out.Items {
+			require.NotNil(t, item["ttl"])
+			assert.Equal(t, *out.Items[0]["ttl"].N, *item["ttl"].N)
+		}
+	})
+
+	t.Run("OverwriteRemovesPreviousChunks", func(t *testing.T) {
+		require.NoError(t, connector.Set(ctx, "evm:1:300", "eth_getLogs:c", large, nil))
+		larger := append(append([]byte{}, large...), large...)
+		require.NoError(t, connector.Set(ctx, "evm:1:300", "eth_getLogs:c", larger, nil))
+
+		value, err := connector.Get(ctx, ConnectorMainIndex, "evm:1:300", "eth_getLogs:c", nil)
+		require.NoError(t, err)
+		assert.Equal(t, larger, value)
+		chunks, total := countItems(t, "evm:1:300")
+		assert.Equal(t, 9, chunks)
+		assert.Equal(t, 10, total)
+	})
+
+	t.Run("DeleteRemovesChunks", func(t *testing.T) {
+		require.NoError(t, connector.Set(ctx, "evm:1:400", "eth_getLogs:d", large, nil))
+		require.NoError(t, connector.Delete(ctx, "evm:1:400", "eth_getLogs:d"))
+
+		_, err := connector.Get(ctx, ConnectorMainIndex, "evm:1:400", "eth_getLogs:d", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+		_, total := countItems(t, "evm:1:400")
+		assert.Zero(t, total)
+	})
+
+	t.Run("MissingChunkIsAMiss", func(t *testing.T) {
+		require.NoError(t, connector.Set(ctx, "evm:1:500", "eth_getLogs:e", large, nil))
+		out, err := connector.readClient.ScanWithContext(ctx, &dynamodb.ScanInput{
+			TableName:                 aws.String(cfg.Table),
+			FilterExpression:          aws.String("pk = :pk AND attribute_exists(chunkIndex)"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":pk": {S: aws.String("evm:1:500")}},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, out.Items)
+		_, err = connector.writeClient.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(cfg.Table),
+			Key:       map[string]*dynamodb.AttributeValue{"pk": out.Items[0]["pk"], "rk": out.Items[0]["rk"]},
+		})
+		require.NoError(t, err)
+
+		_, err = connector.Get(ctx, ConnectorMainIndex, "evm:1:500", "eth_getLogs:e", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), "got %v", err)
+	})
+
+	t.Run("ScanSkipsChunksAndReassemblesValues", func(t *testing.T) {
+		require.NoError(t, connector.Set(ctx, "evm:9:1", "eth_getLogs:f", large, nil))
+		require.NoError(t, connector.Set(ctx, "evm:9:1", "eth_call:g", []byte("small"), nil))
+
+		var items []KeyValuePair
+		cursor := ""
+		for {
+			page, next, err := connector.Scan(ctx, "evm:9:", "", 100, cursor)
+			require.NoError(t, err)
+			items = append(items, page...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		require.Len(t, items, 2)
+		for _, item := range items {
+			if item.RangeKey == "eth_getLogs:f" {
+				assert.Equal(t, large, item.Value)
+			} else {
+				assert.Equal(t, []byte("small"), item.Value)
+			}
+		}
+	})
+}
+
+func TestDynamoChunkManifest(t *testing.T) {
+	assert.Nil(t, dynamoChunkManifest(map[string]*dynamodb.AttributeValue{"value": {B: []byte("x")}}))
+	assert.Nil(t, dynamoChunkManifest(nil))
+
+	m := dynamoChunkManifest(map[string]*dynamodb.AttributeValue{
+		dynamoChunkCountAttr: {N: aws.String("3")},
+		dynamoChunkSetAttr:   {S: aws.String("abc")},
+		dynamoValueSizeAttr:  {N: aws.String("2500")},
+	})
+	require.NotNil(t, m)
+	assert.Equal(t, dynamoChunk{set: "abc", count: 3, size: 2500}, *m)
+	assert.Equal(t, "eth_getLogs:h#chunk:abc:2", dynamoChunkRangeKey("eth_getLogs:h", "abc", 2))
+	assert.True(t, isDynamoChunkItem(map[string]*dynamodb.AttributeValue{dynamoChunkIndexAttr: {N: aws.String("0")}}))
+}
@@ -0,0 +1,277 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+)
+
+// encryptedValuePrefix marks an encrypted value. It is followed by the key id
+// length (one byte), the key id, the GCM nonce and the sealed value.
+var encryptedValuePrefix = []byte("\x00erpc:e\x00")
+
+// kmsDecryptTimeout bounds the kms:Decrypt of each data key at startup.
+const kmsDecryptTimeout = 10 * time.Second
+
+// IsEncryptedValue reports whether value was written by an
+// EncryptionConnector.
+func IsEncryptedValue(value []byte) bool {
+	return len(value) > len(encryptedValuePrefix) && bytes.HasPrefix(value, encryptedValuePrefix)
+}
+
+// EncryptionConnector encrypts values with AES-256-GCM before writing them to
+// the wrapped connector and decrypts them on reads. The range key is
+// authenticated with each value, so a value copied to another key fails to
+// decrypt; the partition key is not, as reverse-index reads only know its
+// prefix. Values without the encryption header (written before encryption
+// was enabled) are returned as is. See common.ConnectorEncryptionConfig.
+type EncryptionConnector struct {
+	wrapped   Connector
+	logger    *zerolog.Logger
+	primaryId string
+	keys      map[string]cipher.AEAD
+}
+
+var _ Connector = (*EncryptionConnector)(nil)
+var _ CacheHeadReporter = (*EncryptionConnector)(nil)
+var _ GuardedSetter = (*EncryptionConnector)(nil)
+
+func NewEncryptionConnector(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	wrapped Connector,
+	cfg *common.ConnectorEncryptionConfig,
+) (*EncryptionConnector, error) {
+	lg := logger.With().Str("component", "encryptionConnector").Str("connectorId", wrapped.Id()).Logger()
+	c := &EncryptionConnector{
+		wrapped:   wrapped,
+		logger:    &lg,
+		primaryId: cfg.PrimaryKeyId,
+		keys:      make(map[string]cipher.AEAD, len(cfg.Keys)),
+	}
+	for _, kc := range cfg.Keys {
+		key, err := loadEncryptionKey(ctx, kc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load encryption key %q: %w", kc.Id, err)
+		}
+		aead, err := newEncryptionAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", kc.Id, err)
+		}
+		c.keys[kc.Id] = aead
+	}
+	if _, ok := c.keys[c.primaryId]; !ok {
+		return nil, fmt.Errorf("encryption primaryKeyId %q is not one of the keys", c.primaryId)
+	}
+	lg.Info().Str("primaryKeyId", c.primaryId).Int("keys", len(c.keys)).Msg("connector values are encrypted at rest")
+	return c, nil
+}
+
+func newEncryptionAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes (AES-256), got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// loadEncryptionKey returns the plaintext data key: decoded from the
+// environment, or decrypted by KMS.
+func loadEncryptionKey(ctx context.Context, kc *common.EncryptionKeyConfig) ([]byte, error) {
+	switch kc.Source {
+	case "env":
+		raw := strings.TrimSpace(os.Getenv(kc.Env))
+		if raw == "" {
+			return nil, fmt.Errorf("environment variable %s is empty", kc.Env)
+		}
+		key, err := base64.StdEncoding.DecodeString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s is not base64: %w", kc.Env, err)
+		}
+		return key, nil
+	case "kms":
+		blob, err := base64.StdEncoding.DecodeString(kc.EncryptedDataKey)
+		if err != nil {
+			return nil, fmt.Errorf("encryptedDataKey is not base64: %w", err)
+		}
+		sess, err := createAWSSession(kc.Auth, kc.KmsRegion)
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithTimeout(ctx, kmsDecryptTimeout)
+		defer cancel()
+		out, err := kms.New(sess).DecryptWithContext(ctx, &kms.DecryptInput{CiphertextBlob: blob})
+		if err != nil {
+			return nil, fmt.Errorf("kms:Decrypt failed: %w", err)
+		}
+		return out.Plaintext, nil
+	default:
+		return nil, fmt.Errorf("unknown key source %q", kc.Source)
+	}
+}
+
+func (c *EncryptionConnector) encrypt(rangeKey string, value []byte) ([]byte, error) {
+	aead := c.keys[c.primaryId]
+	headerLen := len(encryptedValuePrefix) + 1 + len(c.primaryId)
+	out := make([]byte, headerLen+aead.NonceSize(), headerLen+aead.NonceSize()+len(value)+aead.Overhead())
+	copy(out, encryptedValuePrefix)
+	out[len(encryptedValuePrefix)] = byte(len(c.primaryId))
+	copy(out[len(encryptedValuePrefix)+1:], c.primaryId)
+	nonce := out[headerLen:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, value, []byte(rangeKey)), nil
+}
+
+// decrypt returns the plaintext of an encrypted value, and any other value
+// as is.
+func (c *EncryptionConnector) decrypt(rangeKey string, value []byte) ([]byte, error) {
+	if !IsEncryptedValue(value) {
+		return value, nil
+	}
+	rest := value[len(encryptedValuePrefix):]
+	idLen := int(rest[0])
+	if len(rest) < 1+idLen {
+		return nil, fmt.Errorf("truncated encrypted value")
+	}
+	keyId := string(rest[1 : 1+idLen])
+	aead, ok := c.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("value is encrypted with unknown key %q", keyId)
+	}
+	rest = rest[1+idLen:]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("truncated encrypted value")
+	}
+	return aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(rangeKey))
+}
+
+func (c *EncryptionConnector) Id() string {
+	return c.wrapped.Id()
+}
+
+func (c *EncryptionConnector) State() ConnectorState {
+	return c.wrapped.State()
+}
+
+func (c *EncryptionConnector) Ping(ctx context.Context) error {
+	return c.wrapped.Ping(ctx)
+}
+
+func (c *EncryptionConnector) CacheLatestBlockTimestamp(networkId string) (int64, bool) {
+	if r, ok := c.wrapped.(CacheHeadReporter); ok {
+		return r.CacheLatestBlockTimestamp(networkId)
+	}
+	return 0, false
+}
+
+// Get treats a value that cannot be decrypted as a miss, so the entry is
+// refetched and overwritten with one encrypted by the primary key.
+func (c *EncryptionConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error) {
+	value, err := c.wrapped.Get(ctx, index, partitionKey, rangeKey, metadata)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.decrypt(rangeKey, value)
+	if err != nil {
+		c.logger.Warn().Err(err).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("could not decrypt value, treating it as a miss")
+		return nil, common.NewErrRecordNotFound(partitionKey, rangeKey, c.wrapped.Id())
+	}
+	return out, nil
+}
+
+func (c *EncryptionConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	sealed, err := c.encrypt(rangeKey, value)
+	if err != nil {
+		return err
+	}
+	return c.wrapped.Set(ctx, partitionKey, rangeKey, sealed, ttl)
+}
+
+func (c *EncryptionConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	sealed, err := c.encrypt(rangeKey, value)
+	if err != nil {
+		return false, err
+	}
+	return SetGuarded(ctx, c.wrapped, partitionKey, rangeKey, sealed, guard, ttl)
+}
+
+func (c *EncryptionConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	batch := make([]KeyValuePair, len(items))
+	for i, item := range items {
+		sealed, err := c.encrypt(item.RangeKey, item.Value)
+		if err != nil {
+			return err
+		}
+		item.Value = sealed
+		batch[i] = item
+	}
+	return c.wrapped.SetMany(ctx, batch, ttl)
+}
+
+func (c *EncryptionConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	return c.wrapped.Delete(ctx, partitionKey, rangeKey)
+}
+
+func (c *EncryptionConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return c.wrapped.DeleteByPrefix(ctx, partitionKeyPrefix)
+}
+
+func (c *EncryptionConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	items, next, err := c.wrapped.List(ctx, index, limit, paginationToken)
+	if err != nil {
+		return nil, "", err
+	}
+	return c.decryptAll(items), next, nil
+}
+
+func (c *EncryptionConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	items, next, err := c.wrapped.Scan(ctx, partitionKeyPrefix, rangeKeyPrefix, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	return c.decryptAll(items), next, nil
+}
+
+// decryptAll decrypts items in place, dropping the ones that cannot be
+// decrypted.
+func (c *EncryptionConnector) decryptAll(items []KeyValuePair) []KeyValuePair {
+	kept := items[:0]
+	for _, kv := range items {
+		value, err := c.decrypt(kv.RangeKey, kv.Value)
+		if err != nil {
+			c.logger.Debug().Err(err).Str("partitionKey", kv.PartitionKey).Str("rangeKey", kv.RangeKey).Msg("skipping value that could not be decrypted")
+			continue
+		}
+		kv.Value = value
+		kept = append(kept, kv)
+	}
+	return kept
+}
+
+func (c *EncryptionConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	return c.wrapped.Lock(ctx, key, ttl)
+}
+
+func (c *EncryptionConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	return c.wrapped.WatchCounterInt64(ctx, key)
+}
+
+func (c *EncryptionConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return c.wrapped.PublishCounterInt64(ctx, key, value)
+}
@@ -0,0 +1,112 @@
+package data
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptionConnector(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	ctx := context.Background()
+	t.Setenv("ERPC_TEST_KEY_A", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 32))))
+	t.Setenv("ERPC_TEST_KEY_B", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("b", 32))))
+	value := []byte(`{"jsonrpc":"2.0","result":"0xsecret"}`)
+
+	newMemory := func(t *testing.T) *MemoryConnector {
+		mem, err := NewMemoryConnector(ctx, &logger, "test", &common.MemoryConnectorConfig{
+			MaxItems: 1000, MaxTotalSize: "10MB",
+		})
+		require.NoError(t, err)
+		return mem
+	}
+	newConnector := func(t *testing.T, mem Connector, primary string, keys ...string) *EncryptionConnector {
+		cfg := &common.ConnectorEncryptionConfig{PrimaryKeyId: primary}
+		for _, id := range keys {
+			cfg.Keys = append(cfg.Keys, &common.EncryptionKeyConfig{Id: id, Env: "ERPC_TEST_KEY_" + strings.ToUpper(id)})
+		}
+		cfg.SetDefaults()
+		require.NoError(t, cfg.Validate())
+		ec, err := NewEncryptionConnector(ctx, &logger, mem, cfg)
+		require.NoError(t, err)
+		return ec
+	}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		mem := newMemory(t)
+		ec := newConnector(t, mem, "", "a")
+		require.NoError(t, ec.Set(ctx, "evm:1:100", "eth_call:h", value, nil))
+		mem.cache.Wait()
+
+		raw, err := mem.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.NoError(t, err)
+		require.True(t, IsEncryptedValue(raw))
+		require.NotContains(t, string(raw), "0xsecret")
+
+		got, err := ec.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.NoError(t, err)
+		require.Equal(t, value, got)
+
+		items, _, err := ec.Scan(ctx, "evm:1:", "", 10, "")
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		require.Equal(t, value, items[0].Value)
+	})
+
+	t.Run("RotatedKeysStillDecryptOldValues", func(t *testing.T) {
+		mem := newMemory(t)
+		require.NoError(t, newConnector(t, mem, "a", "a").Set(ctx, "evm:1:100", "eth_call:old", value, nil))
+		rotated := newConnector(t, mem, "b", "a", "b")
+		require.NoError(t, rotated.Set(ctx, "evm:1:100", "eth_call:new", value, nil))
+		mem.cache.Wait()
+
+		for _, rangeKey := range []string{"eth_call:old", "eth_call:new"} {
+			got, err := rotated.Get(ctx, ConnectorMainIndex, "evm:1:100", rangeKey, nil)
+			require.NoError(t, err)
+			require.Equal(t, value, got)
+		}
+		raw, err := mem.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:new", nil)
+		require.NoError(t, err)
+		require.Contains(t, string(raw), "\x01b", "new values name the primary key")
+	})
+
+	t.Run("UnknownKeyOrMovedValueReadsAsMiss", func(t *testing.T) {
+		mem := newMemory(t)
+		require.NoError(t, newConnector(t, mem, "b", "b").Set(ctx, "evm:1:100", "eth_call:h", value, nil))
+		mem.cache.Wait()
+
+		_, err := newConnector(t, mem, "a", "a").Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+
+		ec := newConnector(t, mem, "b", "b")
+		raw, err := mem.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.NoError(t, err)
+		require.NoError(t, mem.Set(ctx, "evm:1:100", "eth_call:other", raw, nil))
+		mem.cache.Wait()
+		_, err = ec.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:other", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), "the range key is authenticated")
+	})
+
+	t.Run("PlaintextValuesPassThrough", func(t *testing.T) {
+		mem := newMemory(t)
+		require.NoError(t, mem.Set(ctx, "evm:1:100", "eth_call:plain", value, nil))
+		mem.cache.Wait()
+		got, err := newConnector(t, mem, "a", "a").Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:plain", nil)
+		require.NoError(t, err)
+		require.Equal(t, value, got)
+	})
+
+	t.Run("RejectsKeysOfTheWrongSize", func(t *testing.T) {
+		t.Setenv("ERPC_TEST_KEY_SHORT", base64.StdEncoding.EncodeToString([]byte("short")))
+		cfg := &common.ConnectorEncryptionConfig{Keys: []*common.EncryptionKeyConfig{{Id: "short", Env: "ERPC_TEST_KEY_SHORT"}}}
+		cfg.SetDefaults()
+		_, err := NewEncryptionConnector(ctx, &logger, newMemory(t), cfg)
+		require.ErrorContains(t, err, "32 bytes")
+	})
+}
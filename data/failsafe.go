@@ -104,6 +104,7 @@ type FailsafeConnector struct {
 
 var _ Connector = (*FailsafeConnector)(nil)
 var _ CacheHeadReporter = (*FailsafeConnector)(nil)
+var _ GuardedSetter = (*FailsafeConnector)(nil)
 
 // NewFailsafeConnector constructs a FailsafeConnector backed by per-direction
 // cacheExecutor instances for Get vs Set/Delete operations.
@@ -206,6 +207,14 @@ func (f *FailsafeConnector) Id() string {
 	return f.wrapped.Id()
 }
 
+func (f *FailsafeConnector) State() ConnectorState {
+	return f.wrapped.State()
+}
+
+func (f *FailsafeConnector) Ping(ctx context.Context) error {
+	return f.wrapped.Ping(ctx)
+}
+
 // CacheLatestBlockTimestamp forwards to the wrapped connector when it is head-aware, so the realtime
 // cache age guard keeps working through the failsafe wrapper. Returns (0, false) otherwise.
 func (f *FailsafeConnector) CacheLatestBlockTimestamp(networkId string) (int64, bool) {
@@ -277,6 +286,76 @@ func (f *FailsafeConnector) Set(ctx context.Context, partitionKey, rangeKey stri
 	return nil
 }
 
+// SetGuarded runs under the set policy; a retry repeats the conditional
+// write, which is idempotent.
+func (f *FailsafeConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	fe := pickCacheExecutor(f.setExecutors, ctx)
+	if fe == nil {
+		return SetGuarded(ctx, f.wrapped, partitionKey, rangeKey, value, guard, ttl)
+	}
+
+	ctx, span := common.StartDetailSpan(ctx, "ConnectorFailsafe.SetGuarded",
+		trace.WithAttributes(
+			attribute.String("connector.id", f.wrapped.Id()),
+			attribute.String("connector.operation", "set_guarded"),
+			attribute.String("connector.partition_key", partitionKey),
+			attribute.String("connector.range_key", rangeKey),
+			attribute.String("failsafe.match_method", fe.method),
+			attribute.Int("value.bytes", len(value)),
+		),
+	)
+	defer span.End()
+
+	// The outcome travels as the result of the attempt that won, since hedged
+	// attempts run concurrently.
+	result, err := fe.RunBytes(ctx, func(ctx context.Context) ([]byte, error) {
+		stored, err := SetGuarded(ctx, f.wrapped, partitionKey, rangeKey, value, guard, ttl)
+		if err != nil || !stored {
+			return nil, err
+		}
+		return []byte{1}, nil
+	})
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		span.SetAttributes(attribute.String("error.summary", common.ErrorSummary(err)))
+		return false, err
+	}
+	return len(result) > 0, nil
+}
+
+// SetMany runs the whole batch under the set policy, so a retry resends
+// every item.
+func (f *FailsafeConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	fe := pickCacheExecutor(f.setExecutors, ctx)
+	if fe == nil {
+		return f.wrapped.SetMany(ctx, items, ttl)
+	}
+
+	ctx, span := common.StartDetailSpan(ctx, "ConnectorFailsafe.SetMany",
+		trace.WithAttributes(
+			attribute.String("connector.id", f.wrapped.Id()),
+			attribute.String("connector.operation", "set_many"),
+			attribute.String("failsafe.match_method", fe.method),
+			attribute.Int("items", len(items)),
+		),
+	)
+	defer span.End()
+
+	if ttl != nil {
+		span.SetAttributes(attribute.Int64("ttl.ms", ttl.Milliseconds()))
+	}
+
+	err := fe.RunVoid(ctx, func(ctx context.Context) error {
+		return f.wrapped.SetMany(ctx, items, ttl)
+	})
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		span.SetAttributes(attribute.String("error.summary", common.ErrorSummary(err)))
+		return err
+	}
+	return nil
+}
+
 func (f *FailsafeConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
 	fe := pickCacheExecutor(f.setExecutors, ctx)
 	if fe == nil {
@@ -305,10 +384,20 @@ func (f *FailsafeConnector) Delete(ctx context.Context, partitionKey, rangeKey s
 	return nil
 }
 
+// DeleteByPrefix is a bulk admin operation, so it is not bounded by the
+// per-write set policy.
+func (f *FailsafeConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return f.wrapped.DeleteByPrefix(ctx, partitionKeyPrefix)
+}
+
 func (f *FailsafeConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
 	return f.wrapped.List(ctx, index, limit, paginationToken)
 }
 
+func (f *FailsafeConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	return f.wrapped.Scan(ctx, partitionKeyPrefix, rangeKeyPrefix, limit, cursor)
+}
+
 func (f *FailsafeConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
 	return f.wrapped.Lock(ctx, key, ttl)
 }
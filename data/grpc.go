@@ -203,6 +203,16 @@ func NewGrpcConnector(
 
 func (g *GrpcConnector) Id() string { return g.id }
 
+func (g *GrpcConnector) State() ConnectorState {
+	return initializerConnectorState(g.initializer)
+}
+
+// Ping only checks that clients are set up: the BDS API has no cheap call
+// that does not depend on a network.
+func (g *GrpcConnector) Ping(ctx context.Context) error {
+	return g.checkReady()
+}
+
 // CacheLatestBlockTimestamp reports the unix timestamp (seconds) of the latest block this
 // read-through cache currently has for networkId (refreshed by the background head poller), and
 // whether it is known. Implements CacheHeadReporter so the realtime cache age guard can be enforced
@@ -467,14 +477,26 @@ func (g *GrpcConnector) Set(ctx context.Context, partitionKey, rangeKey string,
 	return fmt.Errorf("grpc connector is read-only")
 }
 
+func (g *GrpcConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	return fmt.Errorf("grpc connector is read-only")
+}
+
 func (g *GrpcConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
 	return fmt.Errorf("grpc connector is read-only")
 }
 
+func (g *GrpcConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return 0, fmt.Errorf("grpc connector is read-only")
+}
+
 func (g *GrpcConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
 	return nil, "", fmt.Errorf("grpc connector is does not support List")
 }
 
+func (g *GrpcConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	return nil, "", fmt.Errorf("grpc connector does not support Scan")
+}
+
 func (g *GrpcConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
 	return nil, fmt.Errorf("grpc connector is read-only")
 }
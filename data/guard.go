@@ -0,0 +1,77 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/erpc/erpc/common"
+)
+
+// CacheRecordGuard is the guard a cached value is written with by
+// SetGuarded: the block number plus one for finalized data, and zero for
+// anything else. A value is never replaced by one with a lower guard, so a
+// response derived from an unfinalized or earlier block cannot overwrite one
+// derived from a finalized block, while unfinalized entries stay replaceable.
+func CacheRecordGuard(finality common.DataFinalityState, blockNumber int64) int64 {
+	if finality != common.DataFinalityStateFinalized {
+		return 0
+	}
+	if blockNumber < 0 {
+		blockNumber = 0
+	}
+	return blockNumber + 1
+}
+
+// SetGuarded writes through the connector's GuardedSetter, and falls back to
+// an unconditional Set for connectors without one (ClickHouse, which config
+// validation limits to finalized data when records are on, and gRPC, which
+// is read-only).
+func SetGuarded(ctx context.Context, connector Connector, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	if g, ok := connector.(GuardedSetter); ok {
+		return g.SetGuarded(ctx, partitionKey, rangeKey, value, guard, ttl)
+	}
+	if err := connector.Set(ctx, partitionKey, rangeKey, value, ttl); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Drivers that store values as opaque blobs (Redis, memory, Badger,
+// memcached) keep the guard in front of the value: guardedValuePrefix + the
+// guard as 20 zero-padded decimal digits, so that a Redis script can compare
+// it with GETRANGE. Like TombstoneValue it starts with a NUL byte, which no
+// cached value does, and reads strip it before returning the value.
+var guardedValuePrefix = []byte("\x00erpc:g\x00")
+
+const guardedValueHeaderSize = 8 + 20
+
+func wrapGuardedValue(guard int64, value []byte) []byte {
+	out := make([]byte, 0, guardedValueHeaderSize+len(value))
+	out = append(out, guardedValuePrefix...)
+	out = append(out, fmt.Sprintf("%020d", guard)...)
+	return append(out, value...)
+}
+
+// unwrapGuardedValue strips the guard header. Values written without one are
+// returned unchanged with a zero guard.
+func unwrapGuardedValue(value []byte) ([]byte, int64) {
+	if len(value) < guardedValueHeaderSize || !bytes.HasPrefix(value, guardedValuePrefix) {
+		return value, 0
+	}
+	guard, err := strconv.ParseInt(string(value[len(guardedValuePrefix):guardedValueHeaderSize]), 10, 64)
+	if err != nil {
+		return value, 0
+	}
+	return value[guardedValueHeaderSize:], guard
+}
+
+// unwrapGuardedValues strips the guard header of every item in place.
+func unwrapGuardedValues(items []KeyValuePair) []KeyValuePair {
+	for i := range items {
+		items[i].Value, _ = unwrapGuardedValue(items[i].Value)
+	}
+	return items
+}
@@ -0,0 +1,124 @@
+package data
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheRecordGuard(t *testing.T) {
+	assert.Equal(t, int64(101), CacheRecordGuard(common.DataFinalityStateFinalized, 100))
+	assert.Equal(t, int64(1), CacheRecordGuard(common.DataFinalityStateFinalized, 0), "genesis is still guarded")
+	assert.Zero(t, CacheRecordGuard(common.DataFinalityStateUnfinalized, 100))
+	assert.Zero(t, CacheRecordGuard(common.DataFinalityStateRealtime, 100))
+	assert.Zero(t, CacheRecordGuard(common.DataFinalityStateUnknown, 100))
+}
+
+func TestGuardedValue_WrapUnwrap(t *testing.T) {
+	wrapped := wrapGuardedValue(101, []byte(`"0x1"`))
+	value, guard := unwrapGuardedValue(wrapped)
+	assert.Equal(t, []byte(`"0x1"`), value)
+	assert.Equal(t, int64(101), guard)
+
+	value, guard = unwrapGuardedValue([]byte(`"0x1"`))
+	assert.Equal(t, []byte(`"0x1"`), value, "values written by Set are passed through")
+	assert.Zero(t, guard)
+}
+
+func TestSetGuarded(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	connectors := map[string]func(t *testing.T) Connector{
+		"Memory": func(t *testing.T) Connector {
+			mem, err := NewMemoryConnector(ctx, &logger, "test", &common.MemoryConnectorConfig{
+				MaxItems: 1000, MaxTotalSize: "10MB",
+			})
+			require.NoError(t, err)
+			return mem
+		},
+		"Redis": func(t *testing.T) Connector {
+			s, err := miniredis.Run()
+			require.NoError(t, err)
+			t.Cleanup(s.Close)
+			cfg := &common.RedisConnectorConfig{Addr: s.Addr()}
+			require.NoError(t, cfg.SetDefaults())
+			conn, err := NewRedisConnector(ctx, &logger, "test", cfg)
+			require.NoError(t, err)
+			require.Eventually(t, func() bool {
+				return conn.initializer.State() == util.StateReady
+			}, 3*time.Second, 50*time.Millisecond)
+			return conn
+		},
+		"Badger": func(t *testing.T) Connector {
+			return newTestBadgerConnector(t, "")
+		},
+		"Memcached": func(t *testing.T) Connector {
+			srv := newFakeMemcached(t)
+			return newTestMemcachedConnector(t, ctx, &common.MemcachedConnectorConfig{Servers: []string{srv.Addr()}})
+		},
+		"Tiered": func(t *testing.T) Connector {
+			conn, _, _ := newTestTieredConnector(t, time.Hour, false)
+			return conn
+		},
+		"LayeredWriteThrough": func(t *testing.T) Connector {
+			conn, _, _ := newTestLayeredConnector(t, ctx, &common.LayeredConnectorConfig{})
+			return conn
+		},
+		"LayeredWriteBack": func(t *testing.T) Connector {
+			conn, _, _ := newTestLayeredConnector(t, ctx, &common.LayeredConnectorConfig{
+				WriteMode: common.LayeredWriteBack, FlushInterval: common.Duration(time.Hour), MaxPending: 100,
+			})
+			return conn
+		},
+	}
+
+	for name, newConnector := range connectors {
+		t.Run(name, func(t *testing.T) {
+			t.Run("UnfinalizedCannotReplaceFinalized", func(t *testing.T) {
+				conn := newConnector(t)
+				stored, err := SetGuarded(ctx, conn, "evm:1:100", "eth_getBlockByNumber:h", []byte(`"final"`), 101, nil)
+				require.NoError(t, err)
+				require.True(t, stored)
+
+				stored, err = SetGuarded(ctx, conn, "evm:1:100", "eth_getBlockByNumber:h", []byte(`"unfinal"`), 0, nil)
+				require.NoError(t, err)
+				assert.False(t, stored)
+
+				stored, err = SetGuarded(ctx, conn, "evm:1:100", "eth_getBlockByNumber:h", []byte(`"earlier"`), 90, nil)
+				require.NoError(t, err)
+				assert.False(t, stored)
+
+				value, err := conn.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:h", nil)
+				require.NoError(t, err)
+				assert.Equal(t, []byte(`"final"`), value, "reads return the value without its guard")
+			})
+
+			t.Run("EqualOrHigherGuardReplaces", func(t *testing.T) {
+				conn := newConnector(t)
+				_, err := SetGuarded(ctx, conn, "evm:1:100", "eth_call:h", []byte(`"a"`), 0, nil)
+				require.NoError(t, err)
+				stored, err := SetGuarded(ctx, conn, "evm:1:100", "eth_call:h", []byte(`"b"`), 0, nil)
+				require.NoError(t, err)
+				assert.True(t, stored, "unfinalized entries stay replaceable")
+
+				stored, err = SetGuarded(ctx, conn, "evm:1:100", "eth_call:h", []byte(`"c"`), 101, nil)
+				require.NoError(t, err)
+				assert.True(t, stored)
+
+				value, err := conn.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+				require.NoError(t, err)
+				assert.Equal(t, []byte(`"c"`), value)
+			})
+		})
+	}
+}
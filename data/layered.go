@@ -0,0 +1,420 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// layeredFinalFlushTimeout bounds the flush of queued writes on shutdown.
+const layeredFinalFlushTimeout = 5 * time.Second
+
+var _ Connector = (*LayeredConnector)(nil)
+var _ CacheHeadReporter = (*LayeredConnector)(nil)
+var _ GuardedSetter = (*LayeredConnector)(nil)
+
+type layeredKey struct {
+	partitionKey, rangeKey string
+}
+
+type layeredPendingWrite struct {
+	value []byte
+	ttl   *time.Duration
+	// guarded writes are flushed one by one with SetGuarded and guard.
+	guarded bool
+	guard   int64
+}
+
+// LayeredConnector serves reads from an in-process memory connector (L1) and
+// falls back to a remote connector (L2), copying L2 hits into L1 for at most
+// l1Ttl. Every write reaches L2, either before Set returns (write-through) or
+// from a queue flushed in batches (write-back). Locks and counters are shared
+// state and always go to L2.
+type LayeredConnector struct {
+	id            string
+	logger        *zerolog.Logger
+	l1            Connector
+	l2            Connector
+	l1Ttl         time.Duration
+	l2Ttl         time.Duration
+	writeBack     bool
+	flushInterval time.Duration
+	maxPending    int
+
+	mu      sync.Mutex
+	pending map[layeredKey]layeredPendingWrite
+}
+
+func NewLayeredConnector(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	id string,
+	cfg *common.LayeredConnectorConfig,
+) (*LayeredConnector, error) {
+	lg := logger.With().Str("connector", id).Logger()
+
+	l1, err := NewMemoryConnector(ctx, &lg, id+"-l1", cfg.L1)
+	if err != nil {
+		return nil, err
+	}
+	l2, err := NewConnector(ctx, &lg, cfg.L2)
+	if err != nil {
+		return nil, err
+	}
+	return NewLayeredConnectorFromConnectors(ctx, &lg, id, l1, l2, cfg), nil
+}
+
+func NewLayeredConnectorFromConnectors(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	id string,
+	l1 Connector,
+	l2 Connector,
+	cfg *common.LayeredConnectorConfig,
+) *LayeredConnector {
+	l := &LayeredConnector{
+		id:            id,
+		logger:        logger,
+		l1:            l1,
+		l2:            l2,
+		l1Ttl:         cfg.L1Ttl.Duration(),
+		l2Ttl:         cfg.L2Ttl.Duration(),
+		writeBack:     cfg.WriteMode == common.LayeredWriteBack,
+		flushInterval: cfg.FlushInterval.Duration(),
+		maxPending:    cfg.MaxPending,
+		pending:       make(map[layeredKey]layeredPendingWrite),
+	}
+	if l.writeBack {
+		go l.flushLoop(ctx)
+	}
+	return l
+}
+
+func (l *LayeredConnector) Id() string {
+	return l.id
+}
+
+// State follows L2: L1 is in memory and always available.
+func (l *LayeredConnector) State() ConnectorState {
+	return l.l2.State()
+}
+
+func (l *LayeredConnector) Ping(ctx context.Context) error {
+	return l.l2.Ping(ctx)
+}
+
+// CacheLatestBlockTimestamp forwards to L2, which holds what every replica
+// has written.
+func (l *LayeredConnector) CacheLatestBlockTimestamp(networkId string) (int64, bool) {
+	if r, ok := l.l2.(CacheHeadReporter); ok {
+		return r.CacheLatestBlockTimestamp(networkId)
+	}
+	return 0, false
+}
+
+// tierTtls returns the TTLs of an entry written with ttl: L1 always expires
+// within l1Ttl, L2 within l2Ttl when set. A nil or non-positive ttl means the
+// entry never expires.
+func (l *LayeredConnector) tierTtls(ttl *time.Duration) (*time.Duration, *time.Duration) {
+	l1Ttl := l.l1Ttl
+	if ttl != nil && *ttl > 0 && *ttl < l1Ttl {
+		l1Ttl = *ttl
+	}
+	l2Ttl := ttl
+	if l.l2Ttl > 0 && (ttl == nil || *ttl <= 0 || *ttl > l.l2Ttl) {
+		capped := l.l2Ttl
+		l2Ttl = &capped
+	}
+	return &l1Ttl, l2Ttl
+}
+
+func (l *LayeredConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error) {
+	ctx, span := common.StartDetailSpan(ctx, "LayeredConnector.Get",
+		trace.WithAttributes(
+			attribute.String("connector_id", l.id),
+			attribute.String("index", index),
+		),
+	)
+	defer span.End()
+
+	value, err := l.l1.Get(ctx, index, partitionKey, rangeKey, metadata)
+	if err == nil {
+		telemetry.MetricConnectorLayeredRequestsTotal.WithLabelValues(l.id, "l1", "hit").Inc()
+		span.SetAttributes(attribute.String("tier", "l1"))
+		return value, nil
+	}
+	telemetry.MetricConnectorLayeredRequestsTotal.WithLabelValues(l.id, "l1", "miss").Inc()
+	if l.writeBack {
+		// L1 may have evicted a write that is not flushed to L2 yet.
+		l.mu.Lock()
+		pw, ok := l.pending[layeredKey{partitionKey, rangeKey}]
+		l.mu.Unlock()
+		if ok {
+			span.SetAttributes(attribute.String("tier", "pending"))
+			return pw.value, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+
+	value, err = l.l2.Get(ctx, index, partitionKey, rangeKey, metadata)
+	if err != nil {
+		if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+			telemetry.MetricConnectorLayeredRequestsTotal.WithLabelValues(l.id, "l2", "miss").Inc()
+		} else {
+			telemetry.MetricConnectorLayeredRequestsTotal.WithLabelValues(l.id, "l2", "error").Inc()
+		}
+		return nil, err
+	}
+	telemetry.MetricConnectorLayeredRequestsTotal.WithLabelValues(l.id, "l2", "hit").Inc()
+	span.SetAttributes(attribute.String("tier", "l2"))
+
+	// Reverse-index lookups do not carry the concrete key needed to fill L1.
+	if index != ConnectorReverseIndex && !strings.HasSuffix(partitionKey, "*") {
+		// The remaining TTL of the L2 entry is unknown, hence l1Ttl.
+		ttl := l.l1Ttl
+		if err := l.l1.Set(ctx, partitionKey, rangeKey, value, &ttl); err != nil {
+			l.logger.Debug().Err(err).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("failed to fill l1 with l2 entry")
+		}
+	}
+	return value, nil
+}
+
+func (l *LayeredConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	ctx, span := common.StartDetailSpan(ctx, "LayeredConnector.Set",
+		trace.WithAttributes(
+			attribute.String("connector_id", l.id),
+		),
+	)
+	defer span.End()
+
+	l1Ttl, l2Ttl := l.tierTtls(ttl)
+	l1Err := l.l1.Set(ctx, partitionKey, rangeKey, value, l1Ttl)
+	if l.enqueue([]KeyValuePair{{PartitionKey: partitionKey, RangeKey: rangeKey, Value: value}}, l2Ttl) {
+		return l1Err
+	}
+	return errors.Join(l1Err, l.l2.Set(ctx, partitionKey, rangeKey, value, l2Ttl))
+}
+
+func (l *LayeredConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	ctx, span := common.StartDetailSpan(ctx, "LayeredConnector.SetMany",
+		trace.WithAttributes(
+			attribute.String("connector_id", l.id),
+			attribute.Int("items", len(items)),
+		),
+	)
+	defer span.End()
+
+	l1Ttl, l2Ttl := l.tierTtls(ttl)
+	l1Err := l.l1.SetMany(ctx, items, l1Ttl)
+	if l.enqueue(items, l2Ttl) {
+		return l1Err
+	}
+	return errors.Join(l1Err, l.l2.SetMany(ctx, items, l2Ttl))
+}
+
+// SetGuarded leaves the guard check to L2, which every replica writes, and
+// only fills L1 once L2 took the entry; when L2 refuses it, the L1 copy is
+// dropped so the next read fills L1 from L2. In write-back mode the write is
+// queued with its guard and checked by L2 when flushed.
+func (l *LayeredConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	ctx, span := common.StartDetailSpan(ctx, "LayeredConnector.SetGuarded",
+		trace.WithAttributes(
+			attribute.String("connector_id", l.id),
+		),
+	)
+	defer span.End()
+
+	l1Ttl, l2Ttl := l.tierTtls(ttl)
+	if queued, ok := l.enqueueGuarded(partitionKey, rangeKey, value, guard, l2Ttl); ok {
+		if !queued {
+			return false, nil
+		}
+		return true, l.l1.Set(ctx, partitionKey, rangeKey, value, l1Ttl)
+	}
+	stored, err := SetGuarded(ctx, l.l2, partitionKey, rangeKey, value, guard, l2Ttl)
+	if err != nil {
+		return false, err
+	}
+	if !stored {
+		return false, l.l1.Delete(ctx, partitionKey, rangeKey)
+	}
+	return true, l.l1.Set(ctx, partitionKey, rangeKey, value, l1Ttl)
+}
+
+// enqueue queues the L2 writes of items in write-back mode. It returns false
+// when the caller must write them to L2 itself: in write-through mode, or when
+// the queue is full. A queued write replaces any pending write of its key.
+func (l *LayeredConnector) enqueue(items []KeyValuePair, ttl *time.Duration) bool {
+	if !l.writeBack {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.pending)+len(items) > l.maxPending {
+		telemetry.MetricConnectorLayeredWriteBackTotal.WithLabelValues(l.id, "direct").Add(float64(len(items)))
+		return false
+	}
+	for _, item := range items {
+		l.pending[layeredKey{item.PartitionKey, item.RangeKey}] = layeredPendingWrite{value: item.Value, ttl: ttl}
+	}
+	return true
+}
+
+// enqueueGuarded queues a guarded L2 write like enqueue. A pending write of
+// the key with a higher guard is kept, and queued is false.
+func (l *LayeredConnector) enqueueGuarded(partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (queued bool, ok bool) {
+	if !l.writeBack {
+		return false, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := layeredKey{partitionKey, rangeKey}
+	if pw, found := l.pending[key]; found && pw.guarded && pw.guard > guard {
+		return false, true
+	}
+	if len(l.pending)+1 > l.maxPending {
+		telemetry.MetricConnectorLayeredWriteBackTotal.WithLabelValues(l.id, "direct").Inc()
+		return false, false
+	}
+	l.pending[key] = layeredPendingWrite{value: value, ttl: ttl, guarded: true, guard: guard}
+	return true, true
+}
+
+// Delete removes the entry from both tiers and drops its pending write, so
+// that a flush cannot bring it back.
+func (l *LayeredConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	l.mu.Lock()
+	delete(l.pending, layeredKey{partitionKey, rangeKey})
+	l.mu.Unlock()
+	return errors.Join(
+		l.l1.Delete(ctx, partitionKey, rangeKey),
+		l.l2.Delete(ctx, partitionKey, rangeKey),
+	)
+}
+
+// DeleteByPrefix clears both tiers and reports L2's count, since every entry
+// is written to it.
+func (l *LayeredConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	l.mu.Lock()
+	for k := range l.pending {
+		if strings.HasPrefix(k.partitionKey, partitionKeyPrefix) {
+			delete(l.pending, k)
+		}
+	}
+	l.mu.Unlock()
+	_, l1Err := l.l1.DeleteByPrefix(ctx, partitionKeyPrefix)
+	deleted, l2Err := l.l2.DeleteByPrefix(ctx, partitionKeyPrefix)
+	return deleted, errors.Join(l1Err, l2Err)
+}
+
+func (l *LayeredConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	return l.l2.List(ctx, index, limit, paginationToken)
+}
+
+// Scan covers L2, which has every entry once pending writes are flushed.
+func (l *LayeredConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	return l.l2.Scan(ctx, partitionKeyPrefix, rangeKeyPrefix, limit, cursor)
+}
+
+func (l *LayeredConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	return l.l2.Lock(ctx, key, ttl)
+}
+
+func (l *LayeredConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	return l.l2.WatchCounterInt64(ctx, key)
+}
+
+func (l *LayeredConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return l.l2.PublishCounterInt64(ctx, key, value)
+}
+
+func (l *LayeredConnector) flushLoop(ctx context.Context) {
+	ticker := util.NewTicker(l.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), layeredFinalFlushTimeout)
+			l.flush(fctx)
+			cancel()
+			return
+		case <-ticker.C():
+			fctx, cancel := context.WithTimeout(ctx, l.flushInterval*10)
+			l.flush(fctx)
+			cancel()
+		}
+	}
+}
+
+// flush writes the pending L2 writes with one SetMany per TTL, and guarded
+// writes one by one. Failed writes are not retried: the entries stay in L1
+// until l1Ttl, and a later miss is served by upstreams.
+func (l *LayeredConnector) flush(ctx context.Context) {
+	l.mu.Lock()
+	if len(l.pending) == 0 {
+		l.mu.Unlock()
+		return
+	}
+	pending := l.pending
+	l.pending = make(map[layeredKey]layeredPendingWrite)
+	l.mu.Unlock()
+
+	type group struct {
+		ttl   *time.Duration
+		items []KeyValuePair
+	}
+	// -1 groups the entries that never expire.
+	groups := make(map[time.Duration]*group)
+	for k, pw := range pending {
+		if pw.guarded {
+			l.flushGuarded(ctx, k, pw)
+			continue
+		}
+		gk := time.Duration(-1)
+		if pw.ttl != nil {
+			gk = *pw.ttl
+		}
+		g, ok := groups[gk]
+		if !ok {
+			g = &group{ttl: pw.ttl}
+			groups[gk] = g
+		}
+		g.items = append(g.items, KeyValuePair{PartitionKey: k.partitionKey, RangeKey: k.rangeKey, Value: pw.value})
+	}
+	for _, g := range groups {
+		if err := l.l2.SetMany(ctx, g.items, g.ttl); err != nil {
+			telemetry.MetricConnectorLayeredWriteBackTotal.WithLabelValues(l.id, "failed").Add(float64(len(g.items)))
+			l.logger.Warn().Err(err).Int("items", len(g.items)).Msg("failed to flush write-back entries to l2")
+			continue
+		}
+		telemetry.MetricConnectorLayeredWriteBackTotal.WithLabelValues(l.id, "flushed").Add(float64(len(g.items)))
+	}
+}
+
+// flushGuarded applies a queued guarded write to L2. When L2 refuses it, the
+// L1 copy is dropped, as in SetGuarded.
+func (l *LayeredConnector) flushGuarded(ctx context.Context, k layeredKey, pw layeredPendingWrite) {
+	stored, err := SetGuarded(ctx, l.l2, k.partitionKey, k.rangeKey, pw.value, pw.guard, pw.ttl)
+	if err != nil {
+		telemetry.MetricConnectorLayeredWriteBackTotal.WithLabelValues(l.id, "failed").Inc()
+		l.logger.Warn().Err(err).Str("partitionKey", k.partitionKey).Str("rangeKey", k.rangeKey).Msg("failed to flush guarded write-back entry to l2")
+		return
+	}
+	telemetry.MetricConnectorLayeredWriteBackTotal.WithLabelValues(l.id, "flushed").Inc()
+	if !stored {
+		if err := l.l1.Delete(ctx, k.partitionKey, k.rangeKey); err != nil {
+			l.logger.Debug().Err(err).Str("partitionKey", k.partitionKey).Str("rangeKey", k.rangeKey).Msg("failed to drop l1 entry refused by l2")
+		}
+	}
+}
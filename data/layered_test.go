@@ -0,0 +1,199 @@
+package data
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLayeredConnector(t *testing.T, ctx context.Context, cfg *common.LayeredConnectorConfig) (*LayeredConnector, *MemoryConnector, *MemoryConnector) {
+	logger := zerolog.New(io.Discard)
+	l1, err := NewMemoryConnector(ctx, &logger, "l1", &common.MemoryConnectorConfig{MaxItems: 1000, MaxTotalSize: "10MB"})
+	require.NoError(t, err)
+	l2, err := NewMemoryConnector(ctx, &logger, "l2", &common.MemoryConnectorConfig{MaxItems: 1000, MaxTotalSize: "10MB"})
+	require.NoError(t, err)
+	if cfg.L1Ttl == 0 {
+		cfg.L1Ttl = common.Duration(time.Hour)
+	}
+	if cfg.WriteMode == "" {
+		cfg.WriteMode = common.LayeredWriteThrough
+	}
+	return NewLayeredConnectorFromConnectors(ctx, &logger, "layered", l1, l2, cfg), l1, l2
+}
+
+func TestLayeredConnector(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("write-through writes both tiers, including short-lived entries", func(t *testing.T) {
+		lc, l1, l2 := newTestLayeredConnector(t, ctx, &common.LayeredConnectorConfig{})
+		ttl := 2 * time.Second
+		require.NoError(t, lc.Set(ctx, "pk1", "rk1", []byte("v1"), &ttl))
+		time.Sleep(10 * time.Millisecond)
+
+		val, err := l1.Get(ctx, ConnectorMainIndex, "pk1", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), val)
+		val, err = l2.Get(ctx, ConnectorMainIndex, "pk1", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), val)
+	})
+
+	t.Run("l1 entries are capped at l1Ttl and refilled from l2", func(t *testing.T) {
+		lc, l1, _ := newTestLayeredConnector(t, ctx, &common.LayeredConnectorConfig{L1Ttl: common.Duration(100 * time.Millisecond)})
+		require.NoError(t, lc.Set(ctx, "pk2", "rk1", []byte("v2"), nil))
+		time.Sleep(150 * time.Millisecond)
+
+		_, err := l1.Get(ctx, ConnectorMainIndex, "pk2", "rk1", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), "l1 copy should have aged out")
+
+		val, err := lc.Get(ctx, ConnectorMainIndex, "pk2", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2"), val)
+		time.Sleep(10 * time.Millisecond)
+
+		val, err = l1.Get(ctx, ConnectorMainIndex, "pk2", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2"), val)
+	})
+
+	t.Run("l2Ttl caps the l2 entry", func(t *testing.T) {
+		lc, _, l2 := newTestLayeredConnector(t, ctx, &common.LayeredConnectorConfig{L2Ttl: common.Duration(100 * time.Millisecond)})
+		require.NoError(t, lc.Set(ctx, "pk3", "rk1", []byte("v3"), nil))
+		time.Sleep(150 * time.Millisecond)
+
+		_, err := l2.Get(ctx, ConnectorMainIndex, "pk3", "rk1", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("write-back queues l2 writes until the flush", func(t *testing.T) {
+		lc, l1, l2 := newTestLayeredConnector(t, ctx, &common.LayeredConnectorConfig{
+			WriteMode:     common.LayeredWriteBack,
+			FlushInterval: common.Duration(time.Hour),
+			MaxPending:    10,
+		})
+		require.NoError(t, lc.Set(ctx, "pk4", "rk1", []byte("v4"), nil))
+		time.Sleep(10 * time.Millisecond)
+
+		_, err := l2.Get(ctx, ConnectorMainIndex, "pk4", "rk1", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+
+		// A pending write is still served after L1 lost it.
+		require.NoError(t, l1.Delete(ctx, "pk4", "rk1"))
+		val, err := lc.Get(ctx, ConnectorMainIndex, "pk4", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v4"), val)
+
+		lc.flush(ctx)
+		time.Sleep(10 * time.Millisecond)
+		val, err = l2.Get(ctx, ConnectorMainIndex, "pk4", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v4"), val)
+	})
+
+	t.Run("write-back writes directly when the queue is full", func(t *testing.T) {
+		lc, _, l2 := newTestLayeredConnector(t, ctx, &common.LayeredConnectorConfig{
+			WriteMode:     common.LayeredWriteBack,
+			FlushInterval: common.Duration(time.Hour),
+			MaxPending:    1,
+		})
+		require.NoError(t, lc.Set(ctx, "pk5", "rk1", []byte("a"), nil))
+		require.NoError(t, lc.Set(ctx, "pk5", "rk2", []byte("b"), nil))
+		time.Sleep(10 * time.Millisecond)
+
+		_, err := l2.Get(ctx, ConnectorMainIndex, "pk5", "rk1", nil)
+		require.Error(t, err, "first write is queued")
+		val, err := l2.Get(ctx, ConnectorMainIndex, "pk5", "rk2", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("b"), val)
+	})
+
+	t.Run("write-back flushes guarded writes guarded", func(t *testing.T) {
+		lc, l1, l2 := newTestLayeredConnector(t, ctx, &common.LayeredConnectorConfig{
+			WriteMode:     common.LayeredWriteBack,
+			FlushInterval: common.Duration(time.Hour),
+			MaxPending:    10,
+		})
+		// L2 already holds the finalized value, e.g. written by another replica.
+		stored, err := l2.SetGuarded(ctx, "pk9", "rk1", []byte("final"), 101, nil)
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		stored, err = lc.SetGuarded(ctx, "pk9", "rk1", []byte("late"), 0, nil)
+		require.NoError(t, err)
+		require.True(t, stored, "the write is queued")
+
+		lc.flush(ctx)
+		time.Sleep(10 * time.Millisecond)
+		val, err := l2.Get(ctx, ConnectorMainIndex, "pk9", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("final"), val)
+		_, err = l1.Get(ctx, ConnectorMainIndex, "pk9", "rk1", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), "the refused value is dropped from l1")
+		val, err = lc.Get(ctx, ConnectorMainIndex, "pk9", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("final"), val)
+	})
+
+	t.Run("delete drops pending writes", func(t *testing.T) {
+		lc, _, l2 := newTestLayeredConnector(t, ctx, &common.LayeredConnectorConfig{
+			WriteMode:     common.LayeredWriteBack,
+			FlushInterval: common.Duration(time.Hour),
+			MaxPending:    10,
+		})
+		require.NoError(t, lc.Set(ctx, "pk6", "rk1", []byte("v6"), nil))
+		require.NoError(t, lc.Set(ctx, "evm:1:7", "rk1", []byte("v7"), nil))
+		require.NoError(t, lc.Delete(ctx, "pk6", "rk1"))
+		_, err := lc.DeleteByPrefix(ctx, "evm:1:")
+		require.NoError(t, err)
+
+		lc.flush(ctx)
+		time.Sleep(10 * time.Millisecond)
+		_, err = l2.Get(ctx, ConnectorMainIndex, "pk6", "rk1", nil)
+		require.Error(t, err)
+		_, err = l2.Get(ctx, ConnectorMainIndex, "evm:1:7", "rk1", nil)
+		require.Error(t, err)
+		_, err = lc.Get(ctx, ConnectorMainIndex, "pk6", "rk1", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("pending writes are flushed on shutdown", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		lc, _, l2 := newTestLayeredConnector(t, cctx, &common.LayeredConnectorConfig{
+			WriteMode:     common.LayeredWriteBack,
+			FlushInterval: common.Duration(time.Hour),
+			MaxPending:    10,
+		})
+		require.NoError(t, lc.Set(ctx, "pk8", "rk1", []byte("v8"), nil))
+		cancel()
+
+		require.Eventually(t, func() bool {
+			_, err := l2.Get(ctx, ConnectorMainIndex, "pk8", "rk1", nil)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestLayeredConnectorConfig_Defaults(t *testing.T) {
+	cfg := &common.ConnectorConfig{
+		Id: "near",
+		Layered: &common.LayeredConnectorConfig{
+			L2: &common.ConnectorConfig{Memcached: &common.MemcachedConnectorConfig{Servers: []string{"localhost:11211"}}},
+		},
+	}
+	require.NoError(t, cfg.SetDefaults("cache"))
+	require.Equal(t, common.DriverLayered, cfg.Driver)
+	require.Equal(t, "near-l2", cfg.Layered.L2.Id)
+	require.Equal(t, common.LayeredWriteThrough, cfg.Layered.WriteMode)
+	require.Equal(t, 30*time.Second, cfg.Layered.L1Ttl.Duration())
+	require.NotNil(t, cfg.Layered.L1)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Layered.L2 = &common.ConnectorConfig{Id: "l2", Memory: &common.MemoryConnectorConfig{}}
+	require.NoError(t, cfg.Layered.L2.SetDefaults("cache"))
+	require.ErrorContains(t, cfg.Validate(), "layered.l2 must be a remote connector")
+}
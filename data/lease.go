@@ -0,0 +1,263 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+)
+
+// leaseState is the record of a lease in the connector. Term grows by one
+// every time the lease changes hands, so a holder can tell its writes apart
+// from a previous holder's.
+type leaseState struct {
+	Holder string `json:"holder"`
+	Term   int64  `json:"term"`
+	// ExpiresAt is unix milliseconds.
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// Lease elects one replica as the holder of a single-writer role, such as
+// running scheduled jobs. The holder renews it every renewInterval; when it
+// stops doing so (it died, or lost the connector), another replica takes
+// the lease over once ttl has passed since the last renewal.
+//
+// The lease state is read and written under the distributed lock of the
+// shared state connector, which only needs to be held for one round trip,
+// so connectors whose locks cannot be extended still support leases. Like
+// any lease it assumes clocks of the replicas are roughly in sync: a holder
+// steps down on its own once ttl has passed without a successful renewal.
+type Lease struct {
+	registry      *sharedStateRegistry
+	name          string
+	key           string
+	ttl           time.Duration
+	renewInterval time.Duration
+	logger        *zerolog.Logger
+
+	mu        sync.Mutex
+	held      bool
+	term      int64
+	renewedAt time.Time
+	holder    string
+	// heldCtx is cancelled when the lease is lost.
+	heldCtx    context.Context
+	heldCancel context.CancelFunc
+}
+
+func (r *sharedStateRegistry) GetLease(name string) *Lease {
+	if r.leases == nil {
+		return nil
+	}
+	lg := r.logger.With().Str("lease", name).Logger()
+	value, alreadySetup := r.leaseMap.LoadOrStore(name, &Lease{
+		registry:      r,
+		name:          name,
+		key:           r.clusterKey + "/lease/" + name,
+		ttl:           r.leases.Ttl.Duration(),
+		renewInterval: r.leases.RenewInterval.Duration(),
+		logger:        &lg,
+	})
+	lease := value.(*Lease)
+	if !alreadySetup {
+		go lease.run(r.appCtx)
+	}
+	return lease
+}
+
+// Name is the role the lease elects a holder for.
+func (l *Lease) Name() string {
+	return l.name
+}
+
+// Held returns a context that is cancelled when this replica loses the
+// lease, and false when it does not hold it.
+func (l *Lease) Held() (context.Context, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.held {
+		return nil, false
+	}
+	if time.Since(l.renewedAt) >= l.ttl {
+		// Renewals have been failing for a whole ttl, so another replica
+		// may already have taken over.
+		l.stepDownLocked("expired")
+		return nil, false
+	}
+	return l.heldCtx, true
+}
+
+// Holder is the instance id of the replica that held the lease as of the
+// last campaign, or empty when it was vacant or unknown.
+func (l *Lease) Holder() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.holder
+}
+
+func (l *Lease) run(ctx context.Context) {
+	ticker := time.NewTicker(l.renewInterval)
+	defer ticker.Stop()
+	l.campaign(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			l.release()
+			return
+		case <-ticker.C:
+			l.campaign(ctx)
+		}
+	}
+}
+
+// campaign takes the lease when it is vacant or expired, and renews it when
+// this replica already holds it.
+func (l *Lease) campaign(ctx context.Context) {
+	r := l.registry
+	self := r.instanceId
+	ctx, cancel := context.WithTimeout(ctx, r.fallbackTimeout)
+	defer cancel()
+
+	lock, err := r.connector.Lock(ctx, l.key+"/lock", r.lockTtl)
+	if err != nil || lock == nil || lock.IsNil() {
+		if err == nil {
+			err = errors.New("lock is not available")
+		}
+		l.failed(err)
+		return
+	}
+	defer func() {
+		unlockCtx, cancel := context.WithTimeout(context.Background(), r.fallbackTimeout)
+		defer cancel()
+		if err := lock.Unlock(unlockCtx); err != nil {
+			l.logger.Warn().Err(err).Msg("failed to release lease lock")
+		}
+	}()
+
+	var current leaseState
+	raw, err := r.connector.Get(ctx, ConnectorMainIndex, l.key, "state", nil)
+	if err != nil && !common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+		l.failed(err)
+		return
+	}
+	if err == nil {
+		if err := common.SonicCfg.Unmarshal(raw, &current); err != nil {
+			l.logger.Warn().Err(err).Msg("overwriting unreadable lease state")
+			current = leaseState{}
+		}
+	}
+
+	now := time.Now()
+	if current.Holder != "" && current.Holder != self && current.ExpiresAt > now.UnixMilli() {
+		l.mu.Lock()
+		l.holder = current.Holder
+		if l.held {
+			l.stepDownLocked("taken_over")
+		}
+		l.mu.Unlock()
+		telemetry.MetricLeaseCampaignTotal.WithLabelValues(l.name, "follower").Inc()
+		return
+	}
+
+	next := leaseState{Holder: self, Term: current.Term, ExpiresAt: now.Add(l.ttl).UnixMilli()}
+	if current.Holder != self {
+		next.Term++
+	}
+	value, err := common.SonicCfg.Marshal(next)
+	if err == nil {
+		// Stored without a connector ttl, so that the term survives the
+		// expiry of the lease.
+		err = r.connector.Set(ctx, l.key, "state", value, nil)
+	}
+	if err != nil {
+		l.failed(err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.holder = self
+	l.renewedAt = now
+	if !l.held || l.term != next.Term {
+		if l.held {
+			l.stepDownLocked("taken_over")
+		}
+		l.held = true
+		l.term = next.Term
+		l.heldCtx, l.heldCancel = context.WithCancel(r.appCtx)
+		telemetry.MetricLeaseHeld.WithLabelValues(l.name).Set(1)
+		telemetry.MetricLeaseTransitionTotal.WithLabelValues(l.name, "acquired").Inc()
+		l.logger.Info().Int64("term", next.Term).Str("previousHolder", current.Holder).Msg("acquired lease")
+	}
+	telemetry.MetricLeaseCampaignTotal.WithLabelValues(l.name, "holder").Inc()
+}
+
+// failed records a campaign that could not reach the connector. A holder
+// keeps the lease until ttl has passed since its last renewal.
+func (l *Lease) failed(err error) {
+	telemetry.MetricLeaseCampaignTotal.WithLabelValues(l.name, "failed").Inc()
+	l.logger.Warn().Err(err).Msg("failed to renew or acquire lease")
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held && time.Since(l.renewedAt) >= l.ttl {
+		l.stepDownLocked("expired")
+	}
+}
+
+// release hands the lease back on shutdown, so another replica takes over
+// at its next campaign instead of after ttl.
+func (l *Lease) release() {
+	l.mu.Lock()
+	held := l.held
+	term := l.term
+	if held {
+		l.stepDownLocked("released")
+	}
+	l.mu.Unlock()
+	if !held {
+		return
+	}
+
+	r := l.registry
+	ctx, cancel := context.WithTimeout(context.Background(), r.fallbackTimeout)
+	defer cancel()
+	lock, err := r.connector.Lock(ctx, l.key+"/lock", r.lockTtl)
+	if err != nil || lock == nil || lock.IsNil() {
+		return
+	}
+	defer func() { _ = lock.Unlock(ctx) }()
+	raw, err := r.connector.Get(ctx, ConnectorMainIndex, l.key, "state", nil)
+	if err != nil {
+		return
+	}
+	var current leaseState
+	if err := common.SonicCfg.Unmarshal(raw, &current); err != nil || current.Holder != r.instanceId || current.Term != term {
+		return
+	}
+	current.ExpiresAt = 0
+	value, err := common.SonicCfg.Marshal(current)
+	if err == nil {
+		err = r.connector.Set(ctx, l.key, "state", value, nil)
+	}
+	if err != nil {
+		l.logger.Warn().Err(err).Msg("failed to release lease on shutdown, it will expire after its ttl")
+	}
+}
+
+func (l *Lease) stepDownLocked(reason string) {
+	l.held = false
+	if l.heldCancel != nil {
+		l.heldCancel()
+	}
+	telemetry.MetricLeaseHeld.WithLabelValues(l.name).Set(0)
+	telemetry.MetricLeaseTransitionTotal.WithLabelValues(l.name, reason).Inc()
+	if reason == "released" {
+		l.logger.Info().Int64("term", l.term).Msg("released lease")
+		return
+	}
+	l.logger.Warn().Int64("term", l.term).Str("reason", reason).Msg("lost lease")
+}
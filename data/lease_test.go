@@ -0,0 +1,99 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLease(t *testing.T) {
+	util.ConfigureTestLogger()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// newReplicas returns two replicas sharing one connector. Their leases
+	// campaign only when the test says so.
+	newReplicas := func(t *testing.T, ttl time.Duration) (*Lease, *Lease) {
+		conn, err := NewMemoryConnector(ctx, &log.Logger, "test", &common.MemoryConnectorConfig{MaxItems: 1000, MaxTotalSize: "10MB"})
+		require.NoError(t, err)
+		newLease := func(id string) *Lease {
+			r := &sharedStateRegistry{
+				appCtx:          ctx,
+				logger:          &log.Logger,
+				clusterKey:      "test",
+				instanceId:      id,
+				connector:       conn,
+				fallbackTimeout: time.Second,
+				lockTtl:         time.Second,
+			}
+			return &Lease{registry: r, name: "jobs", key: "test/lease/jobs", ttl: ttl, renewInterval: ttl / 3, logger: &log.Logger}
+		}
+		return newLease("a"), newLease("b")
+	}
+	campaign := func(l *Lease) {
+		l.campaign(ctx)
+		// Memory connector writes become visible asynchronously.
+		l.registry.connector.(*MemoryConnector).cache.Wait()
+	}
+
+	t.Run("OneHolderAtATime", func(t *testing.T) {
+		a, b := newReplicas(t, time.Minute)
+		campaign(a)
+		campaign(b)
+
+		aCtx, held := a.Held()
+		require.True(t, held)
+		_, held = b.Held()
+		assert.False(t, held)
+		assert.Equal(t, "a", b.Holder())
+
+		campaign(a)
+		campaign(b)
+		_, held = a.Held()
+		assert.True(t, held, "renewing keeps the lease")
+		assert.NoError(t, aCtx.Err())
+		assert.Equal(t, int64(1), a.term, "renewing does not start a new term")
+	})
+
+	t.Run("FailsOverOnceTtlPasses", func(t *testing.T) {
+		a, b := newReplicas(t, 100*time.Millisecond)
+		campaign(a)
+		aCtx, held := a.Held()
+		require.True(t, held)
+
+		// a stops renewing, as if it had died.
+		time.Sleep(150 * time.Millisecond)
+		campaign(b)
+		_, held = b.Held()
+		require.True(t, held)
+		assert.Equal(t, int64(2), b.term)
+
+		_, held = a.Held()
+		assert.False(t, held, "a holder that could not renew for a whole ttl steps down")
+		assert.Error(t, aCtx.Err())
+		campaign(a)
+		_, held = a.Held()
+		assert.False(t, held)
+		assert.Equal(t, "b", a.Holder())
+	})
+
+	t.Run("ReleaseHandsOverImmediately", func(t *testing.T) {
+		a, b := newReplicas(t, time.Minute)
+		campaign(a)
+		a.release()
+		a.registry.connector.(*MemoryConnector).cache.Wait()
+		_, held := a.Held()
+		assert.False(t, held)
+
+		campaign(b)
+		_, held = b.Held()
+		assert.True(t, held)
+		assert.Equal(t, int64(2), b.term)
+	})
+}
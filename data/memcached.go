@@ -0,0 +1,732 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	MemcachedDriverName         = "memcached"
+	memcachedReverseIndexPrefix = "rvi"
+
+	// memcachedMaxKeyLength is the protocol limit on key length.
+	memcachedMaxKeyLength = 250
+	// memcachedMaxRelativeTTL is the longest expiration memcached accepts as
+	// relative seconds; anything longer must be sent as a unix timestamp.
+	memcachedMaxRelativeTTL = 30 * 24 * time.Hour
+	// memcachedResolveInterval throttles re-resolving server addresses after
+	// dial failures.
+	memcachedResolveInterval = 5 * time.Second
+)
+
+var _ Connector = &MemcachedConnector{}
+var _ GuardedSetter = &MemcachedConnector{}
+
+// MemcachedConnector stores entries in memcached under "partitionKey:rangeKey".
+// Keys that are too long or hold characters memcached rejects are replaced by
+// a hash. Memcached has no secondary indexes or key enumeration, so the
+// reverse index is kept as extra "rvi#<wildcard>#<rangeKey>" keys pointing at
+// the concrete partition key (like the Redis connector), shared counters are
+// polled, and List/Scan are not supported.
+type MemcachedConnector struct {
+	id          string
+	logger      *zerolog.Logger
+	cfg         *common.MemcachedConnectorConfig
+	initializer *util.Initializer
+
+	mu          sync.RWMutex
+	servers     *memcache.ServerList
+	readClient  *memcache.Client
+	writeClient *memcache.Client
+	lastResolve atomic.Int64
+
+	initTimeout       time.Duration
+	getTimeout        time.Duration
+	setTimeout        time.Duration
+	statePollInterval time.Duration
+}
+
+func NewMemcachedConnector(
+	appCtx context.Context,
+	logger *zerolog.Logger,
+	id string,
+	cfg *common.MemcachedConnectorConfig,
+) (*MemcachedConnector, error) {
+	lg := logger.With().Str("connector", id).Logger()
+	lg.Debug().Interface("config", cfg).Msg("creating memcached connector")
+
+	connector := &MemcachedConnector{
+		id:                id,
+		logger:            &lg,
+		cfg:               cfg,
+		initTimeout:       cfg.InitTimeout.Duration(),
+		getTimeout:        cfg.GetTimeout.Duration(),
+		setTimeout:        cfg.SetTimeout.Duration(),
+		statePollInterval: cfg.StatePollInterval.Duration(),
+	}
+	if connector.statePollInterval <= 0 {
+		connector.statePollInterval = 5 * time.Second
+	}
+
+	connector.initializer = util.NewInitializer(appCtx, &lg, nil)
+	connectTask := util.NewBootstrapTask(fmt.Sprintf("memcached-connect/%s", id), connector.connectTask)
+	if err := connector.initializer.ExecuteTasks(appCtx, connectTask); err != nil {
+		lg.Error().Err(err).Msg("failed to initialize memcached connection on first attempt (will keep retrying in the background)")
+		return connector, nil
+	}
+
+	return connector, nil
+}
+
+func (m *MemcachedConnector) Id() string {
+	return m.id
+}
+
+func (m *MemcachedConnector) State() ConnectorState {
+	return initializerConnectorState(m.initializer)
+}
+
+func (m *MemcachedConnector) Ping(ctx context.Context) error {
+	readClient, _, err := m.clients()
+	if err != nil {
+		return err
+	}
+	return readClient.Ping()
+}
+
+// connectTask resolves the server list, builds the read and write clients and
+// pings every server.
+func (m *MemcachedConnector) connectTask(ctx context.Context) error {
+	servers := new(memcache.ServerList)
+	if err := servers.SetServers(m.cfg.Servers...); err != nil {
+		return fmt.Errorf("failed to resolve memcached servers: %w", err)
+	}
+
+	var dial func(ctx context.Context, network, address string) (net.Conn, error)
+	if cfgTLS := m.cfg.TLS; cfgTLS != nil && cfgTLS.Enabled {
+		tlsConfig, err := common.CreateTLSConfig(cfgTLS)
+		if err != nil {
+			return common.NewTaskFatal(fmt.Errorf("failed to create TLS config: %w", err))
+		}
+		dialer := &tls.Dialer{Config: tlsConfig}
+		dial = dialer.DialContext
+	}
+
+	newClient := func(timeout time.Duration) *memcache.Client {
+		c := memcache.NewFromSelector(servers)
+		c.Timeout = timeout
+		c.MaxIdleConns = m.cfg.ConnPoolSize
+		c.DialContext = dial
+		return c
+	}
+	readClient := newClient(m.getTimeout)
+	writeClient := newClient(m.setTimeout)
+
+	ctx, cancel := context.WithTimeout(ctx, m.initTimeout)
+	defer cancel()
+	if err := memcachedCall(ctx, readClient.Ping); err != nil {
+		return fmt.Errorf("failed to ping memcached servers: %w", err)
+	}
+
+	m.mu.Lock()
+	oldRead, oldWrite := m.readClient, m.writeClient
+	m.servers, m.readClient, m.writeClient = servers, readClient, writeClient
+	m.mu.Unlock()
+	if oldRead != nil {
+		_ = oldRead.Close()
+	}
+	if oldWrite != nil {
+		_ = oldWrite.Close()
+	}
+
+	m.logger.Info().Strs("servers", m.cfg.Servers).Msg("successfully connected to memcached")
+	return nil
+}
+
+// clients returns the read and write clients, or an error if the connector is
+// not connected yet.
+func (m *MemcachedConnector) clients() (*memcache.Client, *memcache.Client, error) {
+	if m.initializer == nil {
+		return nil, nil, fmt.Errorf("initializer not set")
+	}
+	if state := m.initializer.State(); state != util.StateReady {
+		return nil, nil, fmt.Errorf("memcached is not connected (state: %s), errors: %v", state.String(), m.initializer.Errors())
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.readClient == nil || m.writeClient == nil {
+		return nil, nil, fmt.Errorf("memcached client not initialized yet")
+	}
+	return m.readClient, m.writeClient, nil
+}
+
+// handleError re-resolves the server addresses after a dial failure, so a
+// server that moved (e.g. a restarted pod behind a DNS name) is picked up
+// without waiting for a reconnect. Other errors are left to the client, which
+// opens a fresh connection for the next operation anyway.
+func (m *MemcachedConnector) handleError(err error) {
+	if err == nil || isMemcachedMiss(err) || errors.Is(err, memcache.ErrNotStored) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+	var connectErr *memcache.ConnectTimeoutError
+	var opErr *net.OpError
+	if !errors.As(err, &connectErr) && !(errors.As(err, &opErr) && opErr.Op == "dial") {
+		m.logger.Debug().Err(err).Msg("memcached operation failed")
+		return
+	}
+
+	now := time.Now().UnixNano()
+	last := m.lastResolve.Load()
+	if now-last < int64(memcachedResolveInterval) || !m.lastResolve.CompareAndSwap(last, now) {
+		return
+	}
+	m.mu.RLock()
+	servers := m.servers
+	m.mu.RUnlock()
+	if servers == nil {
+		return
+	}
+	if rerr := servers.SetServers(m.cfg.Servers...); rerr != nil {
+		m.logger.Warn().Err(rerr).Msg("failed to re-resolve memcached servers after a dial failure")
+		return
+	}
+	m.logger.Warn().Err(err).Msg("memcached dial failed, re-resolved server addresses")
+}
+
+// memcachedCall runs a blocking memcached call, returning early if ctx is done
+// first. The client's own timeout still bounds the abandoned call.
+func memcachedCall(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if cause := context.Cause(ctx); cause != nil {
+			return cause
+		}
+		return ctx.Err()
+	}
+}
+
+func memcachedGet(ctx context.Context, client *memcache.Client, key string) (*memcache.Item, error) {
+	type result struct {
+		item *memcache.Item
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		item, err := client.Get(key)
+		done <- result{item, err}
+	}()
+	select {
+	case r := <-done:
+		return r.item, r.err
+	case <-ctx.Done():
+		if cause := context.Cause(ctx); cause != nil {
+			return nil, cause
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func isMemcachedMiss(err error) bool {
+	return errors.Is(err, memcache.ErrCacheMiss)
+}
+
+// key maps a raw key to a valid memcached key: KeyPrefix + raw, or a SHA-256
+// of raw when that would be too long or contain whitespace/control bytes.
+func (m *MemcachedConnector) key(raw string) string {
+	k := m.cfg.KeyPrefix + raw
+	if len(k) <= memcachedMaxKeyLength && legalMemcachedKey(k) {
+		return k
+	}
+	sum := sha256.Sum256([]byte(raw))
+	return m.cfg.KeyPrefix + "h:" + hex.EncodeToString(sum[:])
+}
+
+func legalMemcachedKey(k string) bool {
+	for i := 0; i < len(k); i++ {
+		if k[i] <= ' ' || k[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *MemcachedConnector) reverseIndexKey(partitionKey, rangeKey string) (string, bool) {
+	if !strings.HasPrefix(partitionKey, "evm:") || strings.HasSuffix(partitionKey, "*") {
+		return "", false
+	}
+	parts := strings.SplitAfterN(partitionKey, ":", 3)
+	if len(parts) < 2 {
+		return "", false
+	}
+	wildcardPartitionKey := parts[0] + parts[1] + "*"
+	return m.key(fmt.Sprintf("%s#%s#%s", memcachedReverseIndexPrefix, wildcardPartitionKey, rangeKey)), true
+}
+
+// memcachedExpiration converts a TTL to memcached's expiration field: relative
+// seconds (rounded up) up to 30 days, an absolute unix time beyond that.
+func memcachedExpiration(ttl *time.Duration) int32 {
+	if ttl == nil || *ttl <= 0 {
+		return 0
+	}
+	if *ttl > memcachedMaxRelativeTTL {
+		return int32(time.Now().Add(*ttl).Unix())
+	}
+	return int32((*ttl + time.Second - 1) / time.Second)
+}
+
+// Set stores a value with an optional TTL and, for EVM keys, the reverse index
+// entry used by wildcard lookups.
+func (m *MemcachedConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	ctx, span := common.StartSpan(ctx, "MemcachedConnector.Set")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+		)
+	}
+
+	_, client, err := m.clients()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	key := m.key(partitionKey + ":" + rangeKey)
+	m.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int("len", len(value)).Msg("writing value to memcached")
+
+	ctx, cancel := withOperationTimeout(ctx, m.setTimeout, MemcachedDriverName, "setTimeout")
+	defer cancel()
+
+	expiration := memcachedExpiration(ttl)
+	if err := memcachedCall(ctx, func() error {
+		return client.Set(&memcache.Item{Key: key, Value: value, Expiration: expiration})
+	}); err != nil {
+		m.logger.Warn().Err(err).Str("key", key).Msg("failed to SET in memcached")
+		m.handleError(err)
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	if reverseKey, ok := m.reverseIndexKey(partitionKey, rangeKey); ok {
+		// Best-effort: log on error but do not fail the primary SET.
+		if err := memcachedCall(ctx, func() error {
+			return client.Set(&memcache.Item{Key: reverseKey, Value: []byte(partitionKey), Expiration: expiration})
+		}); err != nil {
+			m.logger.Warn().Err(err).Str("key", reverseKey).Msg("failed to SET reverse index in memcached")
+		}
+	}
+
+	return nil
+}
+
+// SetMany stores the items one by one: the memcached protocol has no
+// multi-set command.
+func (m *MemcachedConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	return setEach(ctx, items, ttl, m.Set)
+}
+
+// Get retrieves a value. With the reverse index and a wildcard partition key,
+// the concrete partition key is resolved first.
+// memcachedGuardedRetries bounds the retries of a guarded write that lost a
+// compare-and-swap to a concurrent write of the same key.
+const memcachedGuardedRetries = 3
+
+// SetGuarded adds the entry when the key is free, and otherwise replaces it
+// with a compare-and-swap against the value whose guard it checked; a write
+// that lost to a concurrent one starts over. The guard is kept in front of
+// the value, as for the Redis connector.
+func (m *MemcachedConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	ctx, span := common.StartSpan(ctx, "MemcachedConnector.SetGuarded")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+			attribute.Int64("guard", guard),
+		)
+	}
+
+	_, client, err := m.clients()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+
+	key := m.key(partitionKey + ":" + rangeKey)
+	m.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int("len", len(value)).Int64("guard", guard).Msg("writing guarded value to memcached")
+
+	ctx, cancel := withOperationTimeout(ctx, m.setTimeout, MemcachedDriverName, "setTimeout")
+	defer cancel()
+
+	expiration := memcachedExpiration(ttl)
+	wrapped := wrapGuardedValue(guard, value)
+	stored := false
+	for attempt := 0; attempt <= memcachedGuardedRetries && !stored; attempt++ {
+		current, err := memcachedGet(ctx, client, key)
+		switch {
+		case isMemcachedMiss(err):
+			err = memcachedCall(ctx, func() error {
+				return client.Add(&memcache.Item{Key: key, Value: wrapped, Expiration: expiration})
+			})
+		case err == nil:
+			if _, currentGuard := unwrapGuardedValue(current.Value); currentGuard > guard {
+				return false, nil
+			}
+			current.Value = wrapped
+			current.Expiration = expiration
+			err = memcachedCall(ctx, func() error { return client.CompareAndSwap(current) })
+		}
+		switch {
+		case err == nil:
+			stored = true
+		case errors.Is(err, memcache.ErrNotStored), errors.Is(err, memcache.ErrCASConflict):
+			// A concurrent write got in between; check its guard again.
+		default:
+			m.logger.Warn().Err(err).Str("key", key).Msg("failed to write guarded value to memcached")
+			m.handleError(err)
+			common.SetTraceSpanError(span, err)
+			return false, err
+		}
+	}
+	if !stored {
+		return false, nil
+	}
+
+	if reverseKey, ok := m.reverseIndexKey(partitionKey, rangeKey); ok {
+		// Best-effort, as for Set.
+		if err := memcachedCall(ctx, func() error {
+			return client.Set(&memcache.Item{Key: reverseKey, Value: []byte(partitionKey), Expiration: expiration})
+		}); err != nil {
+			m.logger.Warn().Err(err).Str("key", reverseKey).Msg("failed to SET reverse index in memcached")
+		}
+	}
+	return true, nil
+}
+
+func (m *MemcachedConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
+	ctx, span := common.StartSpan(ctx, "MemcachedConnector.Get",
+		trace.WithAttributes(
+			attribute.String("index", index),
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+		),
+	)
+	defer span.End()
+
+	client, _, err := m.clients()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	// One budget covers the reverse lookup and the final GET.
+	ctx, cancel := withOperationTimeout(ctx, m.getTimeout, MemcachedDriverName, "getTimeout")
+	defer cancel()
+
+	if index == ConnectorReverseIndex && strings.HasSuffix(partitionKey, "*") {
+		revKey := m.key(fmt.Sprintf("%s#%s#%s", memcachedReverseIndexPrefix, partitionKey, rangeKey))
+		item, revErr := memcachedGet(ctx, client, revKey)
+		if revErr != nil {
+			if !isMemcachedMiss(revErr) {
+				m.logger.Debug().Err(revErr).Str("key", revKey).Msg("failed to GET reverse index in memcached")
+				m.handleError(revErr)
+				common.SetTraceSpanError(span, revErr)
+			}
+		} else if len(item.Value) > 0 {
+			partitionKey = string(item.Value)
+		}
+	}
+
+	key := m.key(partitionKey + ":" + rangeKey)
+	m.logger.Trace().Str("key", key).Msg("getting item from memcached")
+	item, err := memcachedGet(ctx, client, key)
+	if isMemcachedMiss(err) {
+		err = common.NewErrRecordNotFound(partitionKey, rangeKey, MemcachedDriverName)
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	} else if err != nil {
+		m.logger.Warn().Err(err).Str("key", key).Msg("failed to GET in memcached")
+		m.handleError(err)
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+	m.logger.Debug().Str("key", key).Int("len", len(item.Value)).Msg("received item from memcached")
+
+	value, _ := unwrapGuardedValue(item.Value)
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.Int("value_size", len(value)),
+		)
+	}
+
+	return value, nil
+}
+
+func (m *MemcachedConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	ctx, span := common.StartSpan(ctx, "MemcachedConnector.Delete")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+		)
+	}
+
+	_, client, err := m.clients()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	key := m.key(partitionKey + ":" + rangeKey)
+	m.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("deleting from memcached")
+
+	ctx, cancel := withOperationTimeout(ctx, m.setTimeout, MemcachedDriverName, "setTimeout")
+	defer cancel()
+
+	if err := memcachedCall(ctx, func() error { return client.Delete(key) }); err != nil && !isMemcachedMiss(err) {
+		m.logger.Warn().Err(err).Str("key", key).Msg("failed to DELETE in memcached")
+		m.handleError(err)
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	if reverseKey, ok := m.reverseIndexKey(partitionKey, rangeKey); ok {
+		// Best-effort: log on error but do not fail the primary DELETE.
+		if err := memcachedCall(ctx, func() error { return client.Delete(reverseKey) }); err != nil && !isMemcachedMiss(err) {
+			m.logger.Warn().Err(err).Str("key", reverseKey).Msg("failed to DELETE reverse index in memcached")
+		}
+	}
+
+	return nil
+}
+
+func (m *MemcachedConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return 0, fmt.Errorf("memcached connector does not support DeleteByPrefix: keys cannot be enumerated")
+}
+
+func (m *MemcachedConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	return nil, "", fmt.Errorf("memcached connector does not support List")
+}
+
+func (m *MemcachedConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	return nil, "", fmt.Errorf("memcached connector does not support Scan")
+}
+
+// Lock acquires a distributed lock with ADD, which only succeeds when the key
+// is absent, retrying every lockRetryInterval until ctx is done. The lock key
+// expires after ttl so a crashed holder cannot keep it forever.
+func (m *MemcachedConnector) Lock(ctx context.Context, lockKey string, ttl time.Duration) (DistributedLock, error) {
+	ctx, span := common.StartSpan(ctx, "MemcachedConnector.Lock",
+		trace.WithAttributes(
+			attribute.String("lock_key", lockKey),
+			attribute.Int64("ttl_ms", ttl.Milliseconds()),
+		),
+	)
+	defer span.End()
+
+	_, client, err := m.clients()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	retryInterval := 500 * time.Millisecond
+	if m.cfg.LockRetryInterval.Duration() > 0 {
+		retryInterval = m.cfg.LockRetryInterval.Duration()
+	}
+
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	key := m.key("lock:" + lockKey)
+	expiration := memcachedExpiration(&ttl)
+
+	for {
+		attemptCtx, cancel := withOperationTimeout(ctx, m.setTimeout, MemcachedDriverName, "setTimeout")
+		err := memcachedCall(attemptCtx, func() error {
+			return client.Add(&memcache.Item{Key: key, Value: []byte(token), Expiration: expiration})
+		})
+		cancel()
+		if err == nil {
+			m.logger.Debug().Str("key", lockKey).Dur("ttl", ttl).Msg("distributed lock acquired")
+			return &memcachedLock{connector: m, key: key, lockKey: lockKey, token: token}, nil
+		}
+		if !errors.Is(err, memcache.ErrNotStored) {
+			m.handleError(err)
+			if ctx.Err() == nil {
+				m.logger.Warn().Err(err).Str("key", lockKey).Msg("failed to acquire lock")
+				common.SetTraceSpanError(span, err)
+				return nil, fmt.Errorf("failed to acquire lock for key '%s': %w", lockKey, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			err := fmt.Errorf("failed to acquire lock for key '%s': %w", lockKey, ctx.Err())
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		case <-util.After(retryInterval):
+		}
+	}
+}
+
+var _ DistributedLock = &memcachedLock{}
+
+type memcachedLock struct {
+	connector *MemcachedConnector
+	key       string
+	lockKey   string
+	token     string
+}
+
+func (l *memcachedLock) IsNil() bool {
+	return l == nil || l.connector == nil
+}
+
+// Unlock releases the lock only if it still holds this lock's token. The
+// check and the release are one compare-and-swap to an already-expired item,
+// so a lock that expired and was taken by someone else is left alone.
+func (l *memcachedLock) Unlock(ctx context.Context) error {
+	ctx, span := common.StartSpan(ctx, "MemcachedConnector.Unlock",
+		trace.WithAttributes(
+			attribute.String("lock_key", l.lockKey),
+		),
+	)
+	defer span.End()
+
+	_, client, err := l.connector.clients()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, l.connector.setTimeout, MemcachedDriverName, "setTimeout")
+	defer cancel()
+
+	item, err := memcachedGet(ctx, client, l.key)
+	if err != nil {
+		if isMemcachedMiss(err) {
+			err = errors.New("failed to release lock: lock expired")
+		} else {
+			err = fmt.Errorf("error releasing lock: %w", err)
+		}
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	if string(item.Value) != l.token {
+		err := errors.New("failed to release lock: held by another owner")
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	item.Expiration = -1
+	if err := memcachedCall(ctx, func() error { return client.CompareAndSwap(item) }); err != nil {
+		err = fmt.Errorf("error releasing lock: %w", err)
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	l.connector.logger.Trace().Str("key", l.lockKey).Msg("distributed lock released")
+	return nil
+}
+
+// WatchCounterInt64 polls the counter every statePollInterval, since memcached
+// has no pub/sub. Callers of this method are responsible to re-try the
+// operation if "values" channel is closed.
+func (m *MemcachedConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	if _, _, err := m.clients(); err != nil {
+		return nil, nil, err
+	}
+
+	updates := make(chan CounterInt64State, 1)
+	ticker := util.NewTicker(m.statePollInterval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		var lastUpdatedAt int64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C():
+				st, ok, err := m.getCounterState(ctx, key)
+				if err != nil {
+					m.logger.Warn().Err(err).Str("key", key).Msg("failed to poll counter value")
+					continue
+				}
+				if ok && st.UpdatedAt > lastUpdatedAt {
+					lastUpdatedAt = st.UpdatedAt
+					select {
+					case updates <- st:
+					default:
+					}
+				}
+			}
+		}
+	}()
+
+	if st, ok, err := m.getCounterState(ctx, key); err == nil && ok {
+		updates <- st
+	}
+
+	cleanup := func() {
+		close(done)
+		close(updates)
+	}
+
+	return updates, cleanup, nil
+}
+
+func (m *MemcachedConnector) getCounterState(ctx context.Context, key string) (CounterInt64State, bool, error) {
+	raw, err := m.Get(ctx, ConnectorMainIndex, key, "value", nil)
+	if err != nil {
+		if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+			return CounterInt64State{}, false, nil
+		}
+		return CounterInt64State{}, false, err
+	}
+	var st CounterInt64State
+	if err := common.SonicCfg.Unmarshal(raw, &st); err != nil || st.UpdatedAt <= 0 {
+		return CounterInt64State{}, false, nil
+	}
+	return st, true, nil
+}
+
+// PublishCounterInt64 is a no-op: memcached has no pub/sub, so counters are
+// propagated by WatchCounterInt64 polling the value stored via Set.
+func (m *MemcachedConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return nil
+}
@@ -0,0 +1,321 @@
+package data
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMemcached speaks the subset of the memcached text protocol the
+// connector uses: gets, set, add, cas, delete and version.
+type fakeMemcached struct {
+	ln    net.Listener
+	mu    sync.Mutex
+	items map[string]fakeMemcachedItem
+	cas   uint64
+}
+
+type fakeMemcachedItem struct {
+	value   []byte
+	flags   uint32
+	cas     uint64
+	expires time.Time
+}
+
+func newFakeMemcached(t *testing.T) *fakeMemcached {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeMemcached{ln: ln, items: map[string]fakeMemcachedItem{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { _ = ln.Close() })
+	return f
+}
+
+func (f *fakeMemcached) Addr() string { return f.ln.Addr().String() }
+
+func (f *fakeMemcached) Keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.items))
+	for k := range f.items {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (f *fakeMemcached) lookup(key string) (fakeMemcachedItem, bool) {
+	it, ok := f.items[key]
+	if ok && !it.expires.IsZero() && !time.Now().Before(it.expires) {
+		delete(f.items, key)
+		return fakeMemcachedItem{}, false
+	}
+	return it, ok
+}
+
+func (f *fakeMemcached) serve(conn net.Conn) {
+	defer conn.Close()
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		f.mu.Lock()
+		switch fields[0] {
+		case "version":
+			fmt.Fprintf(rw, "VERSION 1.6.0\r\n")
+		case "get", "gets":
+			for _, k := range fields[1:] {
+				if it, ok := f.lookup(k); ok {
+					fmt.Fprintf(rw, "VALUE %s %d %d %d\r\n%s\r\n", k, it.flags, len(it.value), it.cas, it.value)
+				}
+			}
+			fmt.Fprintf(rw, "END\r\n")
+		case "delete":
+			if _, ok := f.lookup(fields[1]); ok {
+				delete(f.items, fields[1])
+				fmt.Fprintf(rw, "DELETED\r\n")
+			} else {
+				fmt.Fprintf(rw, "NOT_FOUND\r\n")
+			}
+		case "set", "add", "cas":
+			flags, _ := strconv.ParseUint(fields[2], 10, 32)
+			exptime, _ := strconv.ParseInt(fields[3], 10, 64)
+			size, _ := strconv.Atoi(fields[4])
+			data := make([]byte, size+2)
+			f.mu.Unlock()
+			_, err := io.ReadFull(rw, data)
+			f.mu.Lock()
+			if err != nil {
+				f.mu.Unlock()
+				return
+			}
+			it := fakeMemcachedItem{value: data[:size], flags: uint32(flags)}
+			switch {
+			case exptime < 0:
+				it.expires = time.Now()
+			case exptime > 30*24*3600:
+				it.expires = time.Unix(exptime, 0)
+			case exptime > 0:
+				it.expires = time.Now().Add(time.Duration(exptime) * time.Second)
+			}
+			existing, exists := f.lookup(fields[1])
+			switch {
+			case fields[0] == "add" && exists:
+				fmt.Fprintf(rw, "NOT_STORED\r\n")
+			case fields[0] == "cas" && !exists:
+				fmt.Fprintf(rw, "NOT_FOUND\r\n")
+			case fields[0] == "cas" && fields[5] != strconv.FormatUint(existing.cas, 10):
+				fmt.Fprintf(rw, "EXISTS\r\n")
+			default:
+				f.cas++
+				it.cas = f.cas
+				f.items[fields[1]] = it
+				fmt.Fprintf(rw, "STORED\r\n")
+			}
+		default:
+			fmt.Fprintf(rw, "ERROR\r\n")
+		}
+		f.mu.Unlock()
+		if err := rw.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func newTestMemcachedConnector(t *testing.T, ctx context.Context, cfg *common.MemcachedConnectorConfig) *MemcachedConnector {
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+	logger := zerolog.New(io.Discard)
+	connector, err := NewMemcachedConnector(ctx, &logger, "test-memcached", cfg)
+	require.NoError(t, err)
+	return connector
+}
+
+func TestMemcachedConnector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("SetGetDelete", func(t *testing.T) {
+		srv := newFakeMemcached(t)
+		c := newTestMemcachedConnector(t, ctx, &common.MemcachedConnectorConfig{Servers: []string{srv.Addr()}})
+		require.Equal(t, util.StateReady, c.initializer.State())
+
+		require.NoError(t, c.Set(ctx, "evm:1:100", "eth_getBlockByNumber:abc", []byte("hello"), nil))
+		val, err := c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:abc", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hello"), val)
+
+		require.NoError(t, c.Delete(ctx, "evm:1:100", "eth_getBlockByNumber:abc"))
+		_, err = c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:abc", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+		assert.Empty(t, srv.Keys(), "reverse index entry should be deleted too")
+
+		// Deleting a missing key is not an error.
+		require.NoError(t, c.Delete(ctx, "evm:1:100", "missing"))
+	})
+
+	t.Run("ReverseIndexLookup", func(t *testing.T) {
+		srv := newFakeMemcached(t)
+		c := newTestMemcachedConnector(t, ctx, &common.MemcachedConnectorConfig{Servers: []string{srv.Addr()}})
+
+		require.NoError(t, c.Set(ctx, "evm:1:0xabc", "eth_getTransactionReceipt:0x1", []byte("receipt"), nil))
+		val, err := c.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getTransactionReceipt:0x1", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("receipt"), val)
+
+		// Another chain must not resolve through chain 1's reverse index.
+		_, err = c.Get(ctx, ConnectorReverseIndex, "evm:137:*", "eth_getTransactionReceipt:0x1", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("KeyEncoding", func(t *testing.T) {
+		srv := newFakeMemcached(t)
+		c := newTestMemcachedConnector(t, ctx, &common.MemcachedConnectorConfig{Servers: []string{srv.Addr()}, KeyPrefix: "erpc/"})
+
+		longRange := strings.Repeat("x", 300)
+		require.NoError(t, c.Set(ctx, "pk", longRange, []byte("long"), nil))
+		require.NoError(t, c.Set(ctx, "pk", "has space", []byte("space"), nil))
+
+		val, err := c.Get(ctx, ConnectorMainIndex, "pk", longRange, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("long"), val)
+		val, err = c.Get(ctx, ConnectorMainIndex, "pk", "has space", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("space"), val)
+
+		for _, k := range srv.Keys() {
+			assert.True(t, strings.HasPrefix(k, "erpc/h:"), k)
+			assert.LessOrEqual(t, len(k), memcachedMaxKeyLength)
+		}
+		assert.Equal(t, "erpc/pk:rk", c.key("pk:rk"))
+	})
+
+	t.Run("TTLExpiry", func(t *testing.T) {
+		srv := newFakeMemcached(t)
+		c := newTestMemcachedConnector(t, ctx, &common.MemcachedConnectorConfig{Servers: []string{srv.Addr()}})
+
+		ttl := 500 * time.Millisecond // rounded up to 1s
+		require.NoError(t, c.Set(ctx, "pk", "rk", []byte("v"), &ttl))
+		_, err := c.Get(ctx, ConnectorMainIndex, "pk", "rk", nil)
+		require.NoError(t, err)
+		time.Sleep(1100 * time.Millisecond)
+		_, err = c.Get(ctx, ConnectorMainIndex, "pk", "rk", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("LockExcludesAndReleases", func(t *testing.T) {
+		srv := newFakeMemcached(t)
+		c := newTestMemcachedConnector(t, ctx, &common.MemcachedConnectorConfig{
+			Servers:           []string{srv.Addr()},
+			LockRetryInterval: common.Duration(100 * time.Millisecond),
+		})
+
+		lock, err := c.Lock(ctx, "my-lock", 10*time.Second)
+		require.NoError(t, err)
+		require.False(t, lock.IsNil())
+
+		shortCtx, shortCancel := context.WithTimeout(ctx, 300*time.Millisecond)
+		_, err = c.Lock(shortCtx, "my-lock", 10*time.Second)
+		shortCancel()
+		require.Error(t, err, "second lock must wait while the first is held")
+
+		require.NoError(t, lock.Unlock(ctx))
+		lock2, err := c.Lock(ctx, "my-lock", 10*time.Second)
+		require.NoError(t, err)
+		require.NoError(t, lock2.Unlock(ctx))
+
+		// Unlocking a lock someone else now holds must not release it.
+		require.Error(t, lock.Unlock(ctx))
+	})
+
+	t.Run("WatchCounterInt64Polls", func(t *testing.T) {
+		srv := newFakeMemcached(t)
+		c := newTestMemcachedConnector(t, ctx, &common.MemcachedConnectorConfig{
+			Servers:           []string{srv.Addr()},
+			StatePollInterval: common.Duration(50 * time.Millisecond),
+		})
+
+		updates, cleanup, err := c.WatchCounterInt64(ctx, "counter")
+		require.NoError(t, err)
+		defer cleanup()
+
+		payload, err := common.SonicCfg.Marshal(CounterInt64State{Value: 42, UpdatedAt: time.Now().UnixMilli()})
+		require.NoError(t, err)
+		require.NoError(t, c.Set(ctx, "counter", "value", payload, nil))
+
+		select {
+		case st := <-updates:
+			assert.Equal(t, int64(42), st.Value)
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected a counter update from polling")
+		}
+	})
+
+	t.Run("UnreachableServerKeepsConnectorNotReady", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := ln.Addr().String()
+		require.NoError(t, ln.Close())
+
+		c := newTestMemcachedConnector(t, ctx, &common.MemcachedConnectorConfig{
+			Servers:     []string{addr},
+			InitTimeout: common.Duration(500 * time.Millisecond),
+		})
+		require.NotEqual(t, util.StateReady, c.initializer.State())
+		err = c.Set(ctx, "pk", "rk", []byte("v"), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "memcached is not connected")
+
+		_, _, err = c.List(ctx, ConnectorMainIndex, 10, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestMemcachedExpiration(t *testing.T) {
+	assert.Equal(t, int32(0), memcachedExpiration(nil))
+	zero := time.Duration(0)
+	assert.Equal(t, int32(0), memcachedExpiration(&zero))
+	sub := 1500 * time.Millisecond
+	assert.Equal(t, int32(2), memcachedExpiration(&sub))
+	long := 60 * 24 * time.Hour
+	assert.InDelta(t, time.Now().Add(long).Unix(), int64(memcachedExpiration(&long)), 2)
+}
+
+func TestMemcachedConnectorConfigValidation(t *testing.T) {
+	cfg := &common.ConnectorConfig{Id: "mc", Memcached: &common.MemcachedConnectorConfig{}}
+	require.NoError(t, cfg.SetDefaults(""))
+	assert.Equal(t, common.DriverMemcached, cfg.Driver)
+	assert.ErrorContains(t, cfg.Validate(), "memcached.servers is required")
+
+	cfg.Memcached.Servers = []string{"localhost:11211"}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Memcached.KeyPrefix = "has space"
+	assert.Error(t, cfg.Validate())
+}
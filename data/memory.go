@@ -1,14 +1,17 @@
 package data
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/dgraph-io/ristretto/v2"
+	"github.com/dgraph-io/ristretto/v2/z"
 	"github.com/dustin/go-humanize"
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/telemetry"
@@ -21,6 +24,7 @@ const (
 )
 
 var _ Connector = (*MemoryConnector)(nil)
+var _ GuardedSetter = (*MemoryConnector)(nil)
 
 type MemoryConnector struct {
 	id          string
@@ -29,6 +33,14 @@ type MemoryConnector struct {
 	locks       sync.Map // map[string]*sync.Mutex
 	emitMetrics bool
 
+	// guardMu serializes SetGuarded, which reads the stored guard before
+	// writing.
+	guardMu sync.Mutex
+
+	// keys indexes stored entries by their ristretto key hash so Scan can
+	// iterate them; evictions and expiries remove them via OnEvict.
+	keys sync.Map // map[memoryKeyHash]memoryKey
+
 	// Previous metric values for calculating deltas
 	prevMetrics struct {
 		setsDropped  uint64
@@ -70,6 +82,15 @@ func NewMemoryConnector(
 	// Determine if metrics should be enabled
 	enableMetrics := cfg.EmitMetrics != nil && *cfg.EmitMetrics
 
+	c := &MemoryConnector{
+		id:          id,
+		logger:      &lg,
+		emitMetrics: enableMetrics,
+	}
+	forget := func(item *ristretto.Item[[]byte]) {
+		c.keys.Delete(memoryKeyHash{item.Key, item.Conflict})
+	}
+
 	ristrettoCfg := &ristretto.Config[string, []byte]{
 		NumCounters: int64(3 * cfg.MaxItems), // number of keys to track frequency of.
 		MaxCost:     maxCost,                 // maximum cost of cache.
@@ -78,6 +99,8 @@ func NewMemoryConnector(
 		Cost: func(v []byte) int64 {
 			return int64(len(v) + 256)
 		},
+		OnEvict:  forget,
+		OnReject: c.onReject,
 	}
 
 	cache, err := ristretto.NewCache(ristrettoCfg)
@@ -85,12 +108,7 @@ func NewMemoryConnector(
 		return nil, fmt.Errorf("failed to create ristretto cache: %w", err)
 	}
 
-	c := &MemoryConnector{
-		id:          id,
-		logger:      &lg,
-		cache:       cache,
-		emitMetrics: enableMetrics,
-	}
+	c.cache = cache
 
 	// Start metrics collection goroutine if enabled
 	if enableMetrics {
@@ -103,19 +121,67 @@ func NewMemoryConnector(
 	return c, nil
 }
 
+// onReject removes a write the admission policy rejected from the key index,
+// unless the key is stored anyway: a second write of a new key that was
+// handed over before the first one was admitted is rejected as a duplicate,
+// while Get keeps serving the first value.
+func (m *MemoryConnector) onReject(item *ristretto.Item[[]byte]) {
+	hash := memoryKeyHash{item.Key, item.Conflict}
+	k, ok := m.keys.Load(hash)
+	if !ok {
+		return
+	}
+	key := k.(memoryKey)
+	if _, stored := m.cache.GetTTL(key.partitionKey + ":" + key.rangeKey); stored {
+		return
+	}
+	m.keys.Delete(hash)
+}
+
 func (m *MemoryConnector) Id() string {
 	return m.id
 }
 
+func (m *MemoryConnector) State() ConnectorState {
+	return ConnectorStateHealthy
+}
+
+func (m *MemoryConnector) Ping(ctx context.Context) error {
+	return nil
+}
+
 func (m *MemoryConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	m.set(partitionKey, rangeKey, value, ttl)
+	return nil
+}
+
+// set hands the write to ristretto and reports whether it was accepted. An
+// accepted write may still be rejected by the admission policy later, which
+// onReject removes from the key index.
+func (m *MemoryConnector) set(partitionKey, rangeKey string, value []byte, ttl *time.Duration) bool {
 	m.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int("len", len(value)).Msg("writing to memory (ristretto)")
 
 	key := fmt.Sprintf("%s:%s", partitionKey, rangeKey)
 
+	// The key is indexed before the write is handed over, since OnReject runs
+	// on ristretto's own goroutine and may fire before Set returns. A dropped
+	// write takes its index entry back out unless an earlier value of the key
+	// is still stored.
+	h, conflict := z.KeyToHash(key)
+	hash := memoryKeyHash{h, conflict}
+	_, indexed := m.keys.LoadOrStore(hash, memoryKey{partitionKey, rangeKey})
+	var accepted bool
 	if ttl != nil && *ttl > 0 {
-		m.cache.SetWithTTL(key, value, 0, *ttl)
+		accepted = m.cache.SetWithTTL(key, value, 0, *ttl)
 	} else {
-		m.cache.Set(key, value, 0)
+		accepted = m.cache.Set(key, value, 0)
+	}
+	if !accepted {
+		if !indexed {
+			m.keys.Delete(hash)
+		}
+		m.logger.Debug().Str("key", key).Msg("memory (ristretto) dropped write")
+		return false
 	}
 
 	/**
@@ -129,7 +195,32 @@ func (m *MemoryConnector) Set(ctx context.Context, partitionKey, rangeKey string
 		}
 	}
 
-	return nil
+	return true
+}
+
+func (m *MemoryConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	return setEach(ctx, items, ttl, m.Set)
+}
+
+// SetGuarded waits for the write to be applied before returning, so the next
+// guarded write of the key compares against it. It reports false when
+// ristretto dropped or rejected the write, as the value was not stored.
+func (m *MemoryConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	m.guardMu.Lock()
+	defer m.guardMu.Unlock()
+	if current, found := m.cache.Get(fmt.Sprintf("%s:%s", partitionKey, rangeKey)); found {
+		if _, currentGuard := unwrapGuardedValue(current); currentGuard > guard {
+			return false, nil
+		}
+	}
+	wrapped := wrapGuardedValue(guard, value)
+	if !m.set(partitionKey, rangeKey, wrapped, ttl) {
+		return false, nil
+	}
+	m.cache.Wait()
+	// A rejected write can leave an earlier value of the key stored.
+	current, stored := m.cache.Get(fmt.Sprintf("%s:%s", partitionKey, rangeKey))
+	return stored && bytes.Equal(current, wrapped), nil
 }
 
 func (m *MemoryConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
@@ -150,6 +241,7 @@ func (m *MemoryConnector) Get(ctx context.Context, index, partitionKey, rangeKey
 		return nil, common.NewErrRecordNotFound(partitionKey, rangeKey, MemoryDriverName)
 	}
 
+	item, _ = unwrapGuardedValue(item)
 	return item, nil
 }
 
@@ -299,6 +391,8 @@ func (m *MemoryConnector) Delete(ctx context.Context, partitionKey, rangeKey str
 
 	// Delete main entry
 	m.cache.Del(key)
+	h, conflict := z.KeyToHash(key)
+	m.keys.Delete(memoryKeyHash{h, conflict})
 
 	// Clean up reverse index if it exists
 	if strings.HasPrefix(partitionKey, "evm:") && !strings.HasSuffix(partitionKey, "*") {
@@ -313,6 +407,25 @@ func (m *MemoryConnector) Delete(ctx context.Context, partitionKey, rangeKey str
 	return nil
 }
 
+// DeleteByPrefix walks the key index, so it only sees entries that were
+// admitted to the cache.
+func (m *MemoryConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	var matches []memoryKey
+	m.keys.Range(func(_, v interface{}) bool {
+		if k := v.(memoryKey); strings.HasPrefix(k.partitionKey, partitionKeyPrefix) {
+			matches = append(matches, k)
+		}
+		return true
+	})
+	for _, k := range matches {
+		if err := m.Delete(ctx, k.partitionKey, k.rangeKey); err != nil {
+			return 0, err
+		}
+	}
+	m.logger.Debug().Str("partitionKeyPrefix", partitionKeyPrefix).Int("deleted", len(matches)).Msg("deleted entries by prefix from memory (ristretto)")
+	return len(matches), nil
+}
+
 func (m *MemoryConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
 	m.logger.Warn().Msg("List operation on MemoryConnector is not efficiently supported by underlying Ristretto cache")
 	// TODO: Ristretto doesn't provide efficient iteration capabilities
@@ -321,6 +434,68 @@ func (m *MemoryConnector) List(ctx context.Context, index string, limit int, pag
 	return nil, "", fmt.Errorf("List operation not supported by MemoryConnector - Ristretto cache doesn't provide efficient iteration")
 }
 
+type memoryKeyHash struct {
+	key, conflict uint64
+}
+
+type memoryKey struct {
+	partitionKey, rangeKey string
+}
+
+// Scan walks the key index in (partitionKey, rangeKey) order. Each page sorts
+// the matching keys, so it costs O(n log n) in the number of stored entries.
+func (m *MemoryConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	if err := validateScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	after, err := decodeScanCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var matches []memoryKey
+	m.keys.Range(func(_, v interface{}) bool {
+		k := v.(memoryKey)
+		if !strings.HasPrefix(k.partitionKey, partitionKeyPrefix) || !strings.HasPrefix(k.rangeKey, rangeKeyPrefix) {
+			return true
+		}
+		if after != nil && (k.partitionKey < after.PartitionKey || (k.partitionKey == after.PartitionKey && k.rangeKey <= after.RangeKey)) {
+			return true
+		}
+		matches = append(matches, k)
+		return true
+	})
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].partitionKey != matches[j].partitionKey {
+			return matches[i].partitionKey < matches[j].partitionKey
+		}
+		return matches[i].rangeKey < matches[j].rangeKey
+	})
+
+	results := make([]KeyValuePair, 0, min(limit, len(matches)))
+	for i, k := range matches {
+		if len(results) >= limit {
+			last := matches[i-1]
+			next, err := encodeScanCursor(last.partitionKey, last.rangeKey)
+			return results, next, err
+		}
+		key := k.partitionKey + ":" + k.rangeKey
+		value, found := m.cache.Get(key)
+		if !found {
+			// Not admitted yet (writes are applied asynchronously), or expired
+			// and not cleaned up yet; OnEvict/OnReject prune the index.
+			continue
+		}
+		value, _ = unwrapGuardedValue(value)
+		results = append(results, KeyValuePair{
+			PartitionKey: k.partitionKey,
+			RangeKey:     k.rangeKey,
+			Value:        value,
+		})
+	}
+	return results, "", nil
+}
+
 // Close cleans up resources including stopping the metrics collection goroutine
 func (m *MemoryConnector) Close() error {
 	if m.stopMetrics != nil {
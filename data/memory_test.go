@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/ristretto/v2"
+	"github.com/dgraph-io/ristretto/v2/z"
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/telemetry"
 	"github.com/rs/zerolog"
@@ -244,3 +246,142 @@ func TestMemoryConnector_ChainIsolation(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, testValueB, gotB)
 }
+
+func TestMemoryConnector_Scan(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	ctx := context.Background()
+	connector, err := NewMemoryConnector(ctx, &logger, "test", &common.MemoryConnectorConfig{
+		MaxItems: 1000, MaxTotalSize: "10MB",
+	})
+	require.NoError(t, err)
+
+	for block := 1; block <= 3; block++ {
+		for _, method := range []string{"eth_call", "eth_getBalance"} {
+			pk := fmt.Sprintf("evm:1:%d", block)
+			require.NoError(t, connector.Set(ctx, pk, method+":h", []byte(pk+"/"+method), nil))
+		}
+	}
+	require.NoError(t, connector.Set(ctx, "evm:10:1", "eth_call:h", []byte("other chain"), nil))
+	connector.cache.Wait()
+
+	scanAll := func(pkPrefix, rkPrefix string, limit int) []KeyValuePair {
+		var all []KeyValuePair
+		cursor := ""
+		for {
+			page, next, err := connector.Scan(ctx, pkPrefix, rkPrefix, limit, cursor)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(page), limit)
+			all = append(all, page...)
+			if next == "" {
+				return all
+			}
+			cursor = next
+		}
+	}
+
+	t.Run("PaginatesInKeyOrder", func(t *testing.T) {
+		items := scanAll("evm:1:", "", 4)
+		require.Len(t, items, 6)
+		require.Equal(t, "evm:1:1", items[0].PartitionKey)
+		require.Equal(t, "eth_call:h", items[0].RangeKey)
+		require.Equal(t, []byte("evm:1:1/eth_call"), items[0].Value)
+		require.Equal(t, "evm:1:3", items[5].PartitionKey)
+		require.Equal(t, "eth_getBalance:h", items[5].RangeKey)
+	})
+
+	t.Run("FiltersByRangeKeyPrefix", func(t *testing.T) {
+		items := scanAll("", "eth_getBalance", 10)
+		require.Len(t, items, 3)
+	})
+
+	t.Run("SkipsDeletedEntries", func(t *testing.T) {
+		require.NoError(t, connector.Delete(ctx, "evm:10:1", "eth_call:h"))
+		require.Empty(t, scanAll("evm:10:", "", 10))
+	})
+
+	t.Run("RejectsNonPositiveLimit", func(t *testing.T) {
+		_, _, err := connector.Scan(ctx, "", "", 0, "")
+		require.Error(t, err)
+	})
+}
+
+func TestMemoryConnector_RejectedWrites(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	ctx := context.Background()
+	connector, err := NewMemoryConnector(ctx, &logger, "test", &common.MemoryConnectorConfig{
+		MaxItems: 100, MaxTotalSize: "1KB",
+	})
+	require.NoError(t, err)
+
+	// Costs more than the whole cache, so the admission policy rejects it.
+	tooLarge := make([]byte, 2048)
+	stored, err := connector.SetGuarded(ctx, "evm:1:1", "eth_call:h", tooLarge, 0, nil)
+	require.NoError(t, err)
+	require.False(t, stored)
+
+	require.NoError(t, connector.Set(ctx, "evm:1:2", "eth_call:h", tooLarge, nil))
+	connector.cache.Wait()
+	count := 0
+	connector.keys.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	require.Zero(t, count, "rejected writes must not stay in the scan index")
+}
+
+func TestMemoryConnector_RejectedDuplicateKeepsIndex(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	ctx := context.Background()
+	connector, err := NewMemoryConnector(ctx, &logger, "test", &common.MemoryConnectorConfig{
+		MaxItems: 100, MaxTotalSize: "1MB",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, connector.Set(ctx, "evm:1:1", "eth_call:h", []byte("first"), nil))
+	connector.cache.Wait()
+
+	// A second write of the key handed over before the first one was
+	// admitted is rejected as a duplicate; the first value stays stored.
+	h, conflict := z.KeyToHash("evm:1:1:eth_call:h")
+	connector.onReject(&ristretto.Item[[]byte]{Key: h, Conflict: conflict})
+	page, _, err := connector.Scan(ctx, "evm:1:", "", 10, "")
+	require.NoError(t, err)
+	require.Len(t, page, 1, "a stored key stays in the scan index")
+	require.Equal(t, []byte("first"), page[0].Value)
+
+	// A key that was never admitted is dropped from the index.
+	h, conflict = z.KeyToHash("evm:1:2:eth_call:h")
+	connector.keys.Store(memoryKeyHash{h, conflict}, memoryKey{"evm:1:2", "eth_call:h"})
+	connector.onReject(&ristretto.Item[[]byte]{Key: h, Conflict: conflict})
+	_, indexed := connector.keys.Load(memoryKeyHash{h, conflict})
+	require.False(t, indexed)
+}
+
+func TestMemoryConnector_DeleteByPrefix(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	ctx := context.Background()
+	connector, err := NewMemoryConnector(ctx, &logger, "test", &common.MemoryConnectorConfig{
+		MaxItems: 1000, MaxTotalSize: "10MB",
+	})
+	require.NoError(t, err)
+
+	for _, pk := range []string{"evm:1:100", "evm:1:1000", "evm:1:200", "evm:10:100"} {
+		require.NoError(t, connector.Set(ctx, pk, "eth_call:h", []byte(pk), nil))
+	}
+	connector.cache.Wait()
+
+	// "evm:1:100" also matches "evm:1:1000"; callers wanting an exact block
+	// use Delete instead.
+	deleted, err := connector.DeleteByPrefix(ctx, "evm:1:100")
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	for pk, exists := range map[string]bool{"evm:1:100": false, "evm:1:1000": false, "evm:1:200": true, "evm:10:100": true} {
+		_, err := connector.Get(ctx, ConnectorMainIndex, pk, "eth_call:h", nil)
+		if exists {
+			require.NoError(t, err, pk)
+		} else {
+			require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), pk)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+)
+
+// SchemaMigration is one change to a structure a connector manages: a table,
+// an index or a key layout. Migrations run in version order and the last
+// applied version is recorded by the connector, so each runs once per store.
+//
+// Apply must be idempotent. A migration that failed half-way is applied again
+// on the next connect, and two replicas starting at once may both apply it
+// when the connector cannot lock the store.
+type SchemaMigration struct {
+	Version     int
+	Description string
+	Apply       func(ctx context.Context) error
+}
+
+// schemaVersionStore reads and records the last applied migration version.
+// A store that was never migrated reports version 0.
+type schemaVersionStore interface {
+	SchemaVersion(ctx context.Context) (int, error)
+	SetSchemaVersion(ctx context.Context, version int) error
+}
+
+// runSchemaMigrations applies the migrations newer than the recorded version,
+// recording the version after each one so a failure resumes where it stopped.
+// A recorded version newer than every migration means a newer erpc already
+// migrated the store; nothing is applied and the connector carries on, as
+// migrations only ever add to the structures.
+func runSchemaMigrations(ctx context.Context, logger *zerolog.Logger, connectorId string, store schemaVersionStore, migrations []SchemaMigration) error {
+	for i, m := range migrations {
+		if m.Version <= 0 || (i > 0 && m.Version <= migrations[i-1].Version) {
+			return fmt.Errorf("schema migrations of connector %s must have increasing positive versions (got %d at position %d)", connectorId, m.Version, i)
+		}
+	}
+
+	current, err := store.SchemaVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if len(migrations) > 0 && current > migrations[len(migrations)-1].Version {
+		logger.Warn().
+			Int("schemaVersion", current).
+			Int("latestKnownVersion", migrations[len(migrations)-1].Version).
+			Msg("connector schema was migrated by a newer erpc version, skipping migrations")
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		logger.Info().
+			Int("version", m.Version).
+			Str("description", m.Description).
+			Msg("applying connector schema migration")
+		if err := m.Apply(ctx); err != nil {
+			return fmt.Errorf("schema migration %d (%s) failed: %w", m.Version, m.Description, err)
+		}
+		if err := store.SetSchemaVersion(ctx, m.Version); err != nil {
+			return fmt.Errorf("failed to record schema version %d: %w", m.Version, err)
+		}
+		current = m.Version
+	}
+
+	telemetry.MetricConnectorSchemaVersion.WithLabelValues(connectorId).Set(float64(current))
+	logger.Debug().Int("schemaVersion", current).Msg("connector schema is up to date")
+	return nil
+}
+
+// parseSchemaVersion reads a version recorded as a decimal string.
+func parseSchemaVersion(raw string) (int, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid schema version %q: %w", raw, err)
+	}
+	return v, nil
+}
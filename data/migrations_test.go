@@ -0,0 +1,106 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySchemaVersions struct {
+	version int
+	sets    []int
+}
+
+func (s *memorySchemaVersions) SchemaVersion(ctx context.Context) (int, error) {
+	return s.version, nil
+}
+
+func (s *memorySchemaVersions) SetSchemaVersion(ctx context.Context, version int) error {
+	s.version = version
+	s.sets = append(s.sets, version)
+	return nil
+}
+
+func TestRunSchemaMigrations(t *testing.T) {
+	ctx := context.Background()
+	var applied []int
+	migration := func(v int, err error) SchemaMigration {
+		return SchemaMigration{
+			Version:     v,
+			Description: "test",
+			Apply: func(ctx context.Context) error {
+				applied = append(applied, v)
+				return err
+			},
+		}
+	}
+
+	t.Run("AppliesPendingMigrationsInOrder", func(t *testing.T) {
+		applied = nil
+		store := &memorySchemaVersions{version: 1}
+		err := runSchemaMigrations(ctx, &log.Logger, "test", store, []SchemaMigration{
+			migration(1, nil), migration(2, nil), migration(5, nil),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{2, 5}, applied)
+		assert.Equal(t, []int{2, 5}, store.sets)
+	})
+
+	t.Run("UpToDateStoreAppliesNothing", func(t *testing.T) {
+		applied = nil
+		store := &memorySchemaVersions{version: 2}
+		require.NoError(t, runSchemaMigrations(ctx, &log.Logger, "test", store, []SchemaMigration{migration(1, nil), migration(2, nil)}))
+		assert.Empty(t, applied)
+		assert.Empty(t, store.sets)
+	})
+
+	t.Run("FailureKeepsTheVersionOfTheLastSuccess", func(t *testing.T) {
+		applied = nil
+		store := &memorySchemaVersions{}
+		err := runSchemaMigrations(ctx, &log.Logger, "test", store, []SchemaMigration{
+			migration(1, nil), migration(2, errors.New("boom")), migration(3, nil),
+		})
+		require.ErrorContains(t, err, "schema migration 2")
+		assert.Equal(t, []int{1, 2}, applied)
+		assert.Equal(t, 1, store.version, "the next connect resumes at migration 2")
+	})
+
+	t.Run("NewerStoreIsLeftAlone", func(t *testing.T) {
+		applied = nil
+		store := &memorySchemaVersions{version: 9}
+		require.NoError(t, runSchemaMigrations(ctx, &log.Logger, "test", store, []SchemaMigration{migration(1, nil)}))
+		assert.Empty(t, applied)
+		assert.Equal(t, 9, store.version)
+	})
+
+	t.Run("RejectsUnorderedVersions", func(t *testing.T) {
+		store := &memorySchemaVersions{}
+		err := runSchemaMigrations(ctx, &log.Logger, "test", store, []SchemaMigration{migration(2, nil), migration(2, nil)})
+		require.ErrorContains(t, err, "increasing positive versions")
+	})
+}
+
+func TestRedisSchemaVersion(t *testing.T) {
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(m.Close)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	ctx := context.Background()
+	store := &redisSchemaVersions{client: client}
+
+	v, err := store.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, v)
+
+	require.NoError(t, runSchemaMigrations(ctx, &log.Logger, "test", store, redisSchemaMigrations()))
+	got, err := m.Get(redisSchemaVersionKey)
+	require.NoError(t, err)
+	assert.Equal(t, "1", got)
+}
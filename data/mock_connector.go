@@ -24,6 +24,17 @@ func (m *MockConnector) Id() string {
 	return m.id
 }
 
+// State always reports healthy, so code that inspects connector health does
+// not need expectations set up.
+func (m *MockConnector) State() ConnectorState {
+	return ConnectorStateHealthy
+}
+
+// Ping always succeeds, like State.
+func (m *MockConnector) Ping(ctx context.Context) error {
+	return nil
+}
+
 // Get mocks the Get method of the Connector interface
 func (m *MockConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error) {
 	args := m.Called(ctx, index, partitionKey, rangeKey, metadata)
@@ -39,12 +50,24 @@ func (m *MockConnector) Set(ctx context.Context, partitionKey, rangeKey string,
 	return args.Error(0)
 }
 
+// SetMany mocks the SetMany method of the Connector interface
+func (m *MockConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	args := m.Called(ctx, items, ttl)
+	return args.Error(0)
+}
+
 // Delete mocks the Delete method of the Connector interface
 func (m *MockConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
 	args := m.Called(ctx, partitionKey, rangeKey)
 	return args.Error(0)
 }
 
+// DeleteByPrefix mocks the DeleteByPrefix method of the Connector interface
+func (m *MockConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	args := m.Called(ctx, partitionKeyPrefix)
+	return args.Int(0), args.Error(1)
+}
+
 // List mocks the List method of the Connector interface
 func (m *MockConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
 	args := m.Called(ctx, index, limit, paginationToken)
@@ -54,6 +77,15 @@ func (m *MockConnector) List(ctx context.Context, index string, limit int, pagin
 	return args.Get(0).([]KeyValuePair), args.String(1), args.Error(2)
 }
 
+// Scan mocks the Scan method of the Connector interface
+func (m *MockConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	args := m.Called(ctx, partitionKeyPrefix, rangeKeyPrefix, limit, cursor)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]KeyValuePair), args.String(1), args.Error(2)
+}
+
 // Lock mocks the Lock method of the Connector interface
 func (m *MockConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
 	args := m.Called(ctx, key, ttl)
@@ -122,6 +154,11 @@ func (m *MockMemoryConnector) Set(ctx context.Context, partitionKey, rangeKey st
 	return m.MemoryConnector.Set(ctx, partitionKey, rangeKey, value, ttl)
 }
 
+// SetMany goes through Set so every item gets the fake delay and error rate
+func (m *MockMemoryConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	return setEach(ctx, items, ttl, m.Set)
+}
+
 // Get overrides the base Get method to include a fake delay
 func (m *MockMemoryConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
 	select {
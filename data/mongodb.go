@@ -0,0 +1,913 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	MongoDBDriverName = "mongodb"
+
+	mongoDBSchemaVersionCollection = "erpc_schema_versions"
+	mongoDBLockRangeKey            = "lock"
+)
+
+var _ Connector = (*MongoDBConnector)(nil)
+var _ GuardedSetter = (*MongoDBConnector)(nil)
+
+// MongoDBConnector stores entries as {pk, rk, value, expiresAt} documents. A
+// unique (pk, rk) index serves the main index and prefix scans, and an
+// (rk, pk desc) index serves the reverse index. Expired documents are removed
+// by a TTL index, which only runs about once a minute, so reads also filter
+// them out. Locks are upserts on the unique index and shared counters are
+// polled.
+type MongoDBConnector struct {
+	id          string
+	logger      *zerolog.Logger
+	cfg         *common.MongoDBConnectorConfig
+	initializer *util.Initializer
+
+	mu         sync.RWMutex
+	client     *mongo.Client
+	collection *mongo.Collection
+
+	readPref          *readpref.ReadPref
+	writeConcern      *writeconcern.WriteConcern
+	initTimeout       time.Duration
+	getTimeout        time.Duration
+	setTimeout        time.Duration
+	statePollInterval time.Duration
+	lockRetryInterval time.Duration
+}
+
+// mongoDBDocument is the stored form of an entry. ExpiresAt is nil for
+// entries without a TTL, which the TTL index then never removes.
+type mongoDBDocument struct {
+	PartitionKey string     `bson:"pk"`
+	RangeKey     string     `bson:"rk"`
+	Value        []byte     `bson:"value"`
+	ExpiresAt    *time.Time `bson:"expiresAt,omitempty"`
+	// FinalityGuard is the guard of entries written by SetGuarded.
+	FinalityGuard int64 `bson:"finalityGuard,omitempty"`
+}
+
+func NewMongoDBConnector(
+	appCtx context.Context,
+	logger *zerolog.Logger,
+	id string,
+	cfg *common.MongoDBConnectorConfig,
+) (*MongoDBConnector, error) {
+	lg := logger.With().Str("connector", id).Logger()
+	lg.Debug().Interface("config", cfg).Msg("creating mongodb connector")
+
+	mode, err := readpref.ModeFromString(cfg.ReadPreference)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mongodb read preference: %w", err)
+	}
+	readPref, err := readpref.New(mode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mongodb read preference: %w", err)
+	}
+	writeConcern, err := mongoDBWriteConcern(cfg.WriteConcern)
+	if err != nil {
+		return nil, err
+	}
+
+	connector := &MongoDBConnector{
+		id:                id,
+		logger:            &lg,
+		cfg:               cfg,
+		readPref:          readPref,
+		writeConcern:      writeConcern,
+		initTimeout:       cfg.InitTimeout.Duration(),
+		getTimeout:        cfg.GetTimeout.Duration(),
+		setTimeout:        cfg.SetTimeout.Duration(),
+		statePollInterval: cfg.StatePollInterval.Duration(),
+		lockRetryInterval: cfg.LockRetryInterval.Duration(),
+	}
+	if connector.statePollInterval <= 0 {
+		connector.statePollInterval = 5 * time.Second
+	}
+	if connector.lockRetryInterval <= 0 {
+		connector.lockRetryInterval = 500 * time.Millisecond
+	}
+
+	connector.initializer = util.NewInitializer(appCtx, &lg, nil)
+	connectTask := util.NewBootstrapTask(fmt.Sprintf("mongodb-connect/%s", id), connector.connectTask)
+	if err := connector.initializer.ExecuteTasks(appCtx, connectTask); err != nil {
+		lg.Error().Err(err).Msg("failed to initialize mongodb on first attempt (will retry in background)")
+		return connector, nil
+	}
+
+	return connector, nil
+}
+
+// mongoDBWriteConcern parses "majority" or a number of members.
+func mongoDBWriteConcern(w string) (*writeconcern.WriteConcern, error) {
+	if w == "majority" {
+		return writeconcern.Majority(), nil
+	}
+	n, err := strconv.Atoi(w)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid mongodb write concern %q", w)
+	}
+	return &writeconcern.WriteConcern{W: n}, nil
+}
+
+// connectTask connects to the deployment, checks it is reachable and applies
+// the schema migrations. A client from an earlier attempt is disconnected
+// once replaced.
+func (m *MongoDBConnector) connectTask(ctx context.Context) error {
+	opts := options.Client().ApplyURI(m.cfg.URI)
+	if m.cfg.ReplicaSet != "" {
+		opts.SetReplicaSet(m.cfg.ReplicaSet)
+	}
+	opts.SetReadPreference(m.readPref)
+	opts.SetWriteConcern(m.writeConcern)
+	opts.SetMaxPoolSize(m.cfg.MaxPoolSize)
+	opts.SetConnectTimeout(m.initTimeout)
+	opts.SetServerSelectionTimeout(m.initTimeout)
+	if m.cfg.Username != "" {
+		// Keep the auth source and mechanism given in the URI, if any.
+		cred := options.Credential{}
+		if opts.Auth != nil {
+			cred = *opts.Auth
+		}
+		cred.Username = m.cfg.Username
+		cred.Password = m.cfg.Password
+		cred.PasswordSet = m.cfg.Password != ""
+		opts.SetAuth(cred)
+	}
+	if cfgTLS := m.cfg.TLS; cfgTLS != nil && cfgTLS.Enabled {
+		tlsConfig, err := common.CreateTLSConfig(cfgTLS)
+		if err != nil {
+			return common.NewTaskFatal(fmt.Errorf("failed to create TLS config: %w", err))
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+	if err := opts.Validate(); err != nil {
+		return common.NewTaskFatal(fmt.Errorf("invalid mongodb options: %w", err))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.initTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to connect to mongodb: %w", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(context.Background())
+		return fmt.Errorf("failed to ping mongodb: %w", err)
+	}
+
+	db := client.Database(m.cfg.Database)
+	collection := db.Collection(m.cfg.Collection)
+	versions := &mongoDBSchemaVersions{collection: db.Collection(mongoDBSchemaVersionCollection), name: m.cfg.Collection}
+	if err := runSchemaMigrations(ctx, m.logger, m.id, versions, m.schemaMigrations(collection)); err != nil {
+		_ = client.Disconnect(context.Background())
+		return err
+	}
+
+	m.mu.Lock()
+	old := m.client
+	m.client = client
+	m.collection = collection
+	m.mu.Unlock()
+	if old != nil {
+		_ = old.Disconnect(context.Background())
+	}
+
+	m.logger.Info().Str("database", m.cfg.Database).Str("collection", m.cfg.Collection).Str("replicaSet", m.cfg.ReplicaSet).Msg("successfully connected to mongodb")
+	return nil
+}
+
+// schemaMigrations lists the changes to the collection's indexes in order.
+// Never edit or reorder a released migration; append a new one instead.
+func (m *MongoDBConnector) schemaMigrations(collection *mongo.Collection) []SchemaMigration {
+	return []SchemaMigration{
+		{
+			Version:     1,
+			Description: "create unique key index",
+			Apply: func(ctx context.Context) error {
+				_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "pk", Value: 1}, {Key: "rk", Value: 1}},
+					Options: options.Index().SetName("pk_rk").SetUnique(true),
+				})
+				return err
+			},
+		},
+		{
+			Version:     2,
+			Description: "create reverse index",
+			Apply: func(ctx context.Context) error {
+				_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "rk", Value: 1}, {Key: "pk", Value: -1}},
+					Options: options.Index().SetName("rk_pk"),
+				})
+				return err
+			},
+		},
+		{
+			Version:     3,
+			Description: "create TTL index",
+			Apply: func(ctx context.Context) error {
+				_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+					Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+				})
+				return err
+			},
+		},
+	}
+}
+
+// mongoDBSchemaVersions records the migration version of a collection in the
+// database's erpc_schema_versions collection.
+type mongoDBSchemaVersions struct {
+	collection *mongo.Collection
+	name       string
+}
+
+func (s *mongoDBSchemaVersions) SchemaVersion(ctx context.Context) (int, error) {
+	var doc struct {
+		Version int `bson:"version"`
+	}
+	err := s.collection.FindOne(ctx, bson.M{"_id": s.name}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return doc.Version, err
+}
+
+func (s *mongoDBSchemaVersions) SetSchemaVersion(ctx context.Context, version int) error {
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": s.name},
+		bson.M{"$set": bson.M{"version": version}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// getCollection returns the current collection handle, or an error if the
+// connector is not connected yet.
+func (m *MongoDBConnector) getCollection() (*mongo.Collection, error) {
+	if state := m.initializer.State(); state != util.StateReady {
+		return nil, fmt.Errorf("mongodb is not connected (state: %s), errors: %v", state.String(), m.initializer.Errors())
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.collection == nil {
+		return nil, fmt.Errorf("mongodb client not initialized yet")
+	}
+	return m.collection, nil
+}
+
+func (m *MongoDBConnector) Id() string {
+	return m.id
+}
+
+func (m *MongoDBConnector) State() ConnectorState {
+	return initializerConnectorState(m.initializer)
+}
+
+func (m *MongoDBConnector) Ping(ctx context.Context) error {
+	collection, err := m.getCollection()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withOperationTimeout(ctx, m.getTimeout, MongoDBDriverName, "getTimeout")
+	defer cancel()
+	return collection.Database().Client().Ping(ctx, nil)
+}
+
+// mongoDBExpiresAt returns the expiry date of an entry written now with ttl,
+// or nil if it never expires.
+func mongoDBExpiresAt(now time.Time, ttl *time.Duration) *time.Time {
+	if ttl == nil || *ttl <= 0 {
+		return nil
+	}
+	at := now.Add(*ttl)
+	return &at
+}
+
+// mongoDBNotExpired matches documents without an expiry date or with one
+// after now. The TTL monitor deletes expired documents late, so every read
+// applies it.
+func mongoDBNotExpired(now time.Time) bson.M {
+	return bson.M{"$not": bson.M{"$lte": now}}
+}
+
+// mongoDBPrefixRange matches the strings starting with prefix, as a range
+// that uses the index: MongoDB compares strings by their UTF-8 bytes.
+func mongoDBPrefixRange(prefix string) bson.M {
+	return bson.M{"$gte": prefix, "$lt": prefix + "\U0010FFFF"}
+}
+
+// mongoDBPrefixRegex matches the strings starting with prefix. An anchored
+// regex without options is also an index range scan.
+func mongoDBPrefixRegex(prefix string) bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}
+}
+
+// mongoDBSetModel returns the upsert writing an entry.
+func mongoDBSetModel(partitionKey, rangeKey string, value []byte, expiresAt *time.Time) (bson.M, bson.M) {
+	filter := bson.M{"pk": partitionKey, "rk": rangeKey}
+	update := bson.M{"$set": bson.M{"value": value, "expiresAt": expiresAt}}
+	if expiresAt == nil {
+		update = bson.M{"$set": bson.M{"value": value}, "$unset": bson.M{"expiresAt": ""}}
+	}
+	return filter, update
+}
+
+// Set upserts the entry. Two concurrent upserts of a new key can both try to
+// insert, so a duplicate key error is retried once as an update.
+func (m *MongoDBConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	ctx, span := common.StartSpan(ctx, "MongoDBConnector.Set")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+		)
+	}
+
+	collection, err := m.getCollection()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	m.logger.Debug().Int("len", len(value)).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Interface("ttl", ttl).Msg("writing item to mongodb")
+
+	ctx, cancel := withOperationTimeout(ctx, m.setTimeout, MongoDBDriverName, "setTimeout")
+	defer cancel()
+
+	filter, update := mongoDBSetModel(partitionKey, rangeKey, value, mongoDBExpiresAt(time.Now(), ttl))
+	_, err = collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		_, err = collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	}
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	return nil
+}
+
+// SetGuarded upserts the entry with a filter that only matches a document
+// without a higher finalityGuard, or an expired one. When the stored document
+// has a higher guard the filter matches nothing and the upsert's insert hits
+// the unique index, which reports the write refused. A duplicate key error
+// can also come from a concurrent insert of a new key, so it is retried once.
+func (m *MongoDBConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	ctx, span := common.StartSpan(ctx, "MongoDBConnector.SetGuarded")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+			attribute.Int64("guard", guard),
+		)
+	}
+
+	collection, err := m.getCollection()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+
+	m.logger.Debug().Int("len", len(value)).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int64("guard", guard).Interface("ttl", ttl).Msg("writing guarded item to mongodb")
+
+	ctx, cancel := withOperationTimeout(ctx, m.setTimeout, MongoDBDriverName, "setTimeout")
+	defer cancel()
+
+	now := time.Now()
+	filter, update := mongoDBSetModel(partitionKey, rangeKey, value, mongoDBExpiresAt(now, ttl))
+	filter["$or"] = bson.A{
+		bson.M{"finalityGuard": bson.M{"$not": bson.M{"$gt": guard}}},
+		bson.M{"expiresAt": bson.M{"$lte": now}},
+	}
+	update["$set"].(bson.M)["finalityGuard"] = guard
+	_, err = collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		_, err = collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// SetMany upserts all items in one unordered bulk write, retried once if a
+// concurrent insert of the same key made part of it fail.
+func (m *MongoDBConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	ctx, span := common.StartSpan(ctx, "MongoDBConnector.SetMany")
+	defer span.End()
+	span.SetAttributes(attribute.Int("items", len(items)))
+
+	collection, err := m.getCollection()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	items = uniqueKeyValuePairs(items)
+	if len(items) == 0 {
+		return nil
+	}
+	ctx, cancel := withOperationTimeout(ctx, m.setTimeout, MongoDBDriverName, "setTimeout")
+	defer cancel()
+
+	expiresAt := mongoDBExpiresAt(time.Now(), ttl)
+	models := make([]mongo.WriteModel, 0, len(items))
+	for _, item := range items {
+		filter, update := mongoDBSetModel(item.PartitionKey, item.RangeKey, item.Value, expiresAt)
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+	bulkOpts := options.BulkWrite().SetOrdered(false)
+	_, err = collection.BulkWrite(ctx, models, bulkOpts)
+	if mongo.IsDuplicateKeyError(err) {
+		_, err = collection.BulkWrite(ctx, models, bulkOpts)
+	}
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	return nil
+}
+
+// Get reads an entry by its keys or, on the reverse index, the entry with the
+// given range key whose partition key matches partitionKey (a trailing "*"
+// matches a prefix). When several match, the greatest partition key wins,
+// as with DynamoDB.
+func (m *MongoDBConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
+	ctx, span := common.StartSpan(ctx, "MongoDBConnector.Get")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("index", index),
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+		)
+	}
+
+	collection, err := m.getCollection()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, m.getTimeout, MongoDBDriverName, "getTimeout")
+	defer cancel()
+
+	filter := bson.M{"expiresAt": mongoDBNotExpired(time.Now())}
+	findOpts := options.FindOne().SetProjection(bson.M{"value": 1})
+	if index == ConnectorReverseIndex {
+		if rangeKey == "" || strings.HasSuffix(rangeKey, "*") {
+			err := fmt.Errorf("when using reverse index rangeKey must be a non-empty string and not contain wildcards (rangeKey: '%s', partitionKey: '%s')", rangeKey, partitionKey)
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		}
+		filter["rk"] = rangeKey
+		switch {
+		case partitionKey == "" || partitionKey == "*":
+		case strings.HasSuffix(partitionKey, "*"):
+			filter["pk"] = mongoDBPrefixRange(strings.TrimSuffix(partitionKey, "*"))
+		default:
+			filter["pk"] = partitionKey
+		}
+		findOpts.SetSort(bson.D{{Key: "pk", Value: -1}})
+	} else {
+		filter["pk"] = partitionKey
+		filter["rk"] = rangeKey
+	}
+
+	m.logger.Debug().Str("index", index).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("getting item from mongodb")
+	var doc mongoDBDocument
+	if err := collection.FindOne(ctx, filter, findOpts).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = common.NewErrRecordNotFound(partitionKey, rangeKey, MongoDBDriverName)
+		}
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(attribute.Int("value_size", len(doc.Value)))
+	}
+	return doc.Value, nil
+}
+
+func (m *MongoDBConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	ctx, span := common.StartSpan(ctx, "MongoDBConnector.Delete")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+		)
+	}
+
+	collection, err := m.getCollection()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	m.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("deleting item from mongodb")
+
+	ctx, cancel := withOperationTimeout(ctx, m.setTimeout, MongoDBDriverName, "setTimeout")
+	defer cancel()
+
+	if _, err := collection.DeleteOne(ctx, bson.M{"pk": partitionKey, "rk": rangeKey}); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteByPrefix deletes every entry whose partition key starts with the
+// prefix in one range delete on the unique index.
+func (m *MongoDBConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	ctx, span := common.StartSpan(ctx, "MongoDBConnector.DeleteByPrefix")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(attribute.String("partition_key_prefix", partitionKeyPrefix))
+	}
+
+	collection, err := m.getCollection()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return 0, err
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, m.setTimeout, MongoDBDriverName, "setTimeout")
+	defer cancel()
+
+	res, err := collection.DeleteMany(ctx, bson.M{"pk": mongoDBPrefixRange(partitionKeyPrefix)})
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return 0, err
+	}
+	return int(res.DeletedCount), nil
+}
+
+func (m *MongoDBConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "MongoDBConnector.List")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("index", index),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	first, second := "pk", "rk"
+	if index == ConnectorReverseIndex {
+		first, second = "rk", "pk"
+	}
+	items, next, err := m.scanPage(ctx, bson.M{}, first, second, limit, paginationToken)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return items, next, err
+}
+
+// Scan pages through the entries in (partition key, range key) order. The
+// partition key prefix is a range and the range key prefix an anchored
+// regex, both on the unique index.
+func (m *MongoDBConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "MongoDBConnector.Scan")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key_prefix", partitionKeyPrefix),
+			attribute.String("range_key_prefix", rangeKeyPrefix),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	if err := validateScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+
+	filter := bson.M{}
+	if partitionKeyPrefix != "" {
+		filter["pk"] = mongoDBPrefixRange(partitionKeyPrefix)
+	}
+	if rangeKeyPrefix != "" {
+		filter["rk"] = mongoDBPrefixRegex(rangeKeyPrefix)
+	}
+	items, next, err := m.scanPage(ctx, filter, "pk", "rk", limit, cursor)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+	}
+	return items, next, err
+}
+
+// mongoDBCursor is the keyset pagination position: the sort keys of the last
+// document of the previous page.
+type mongoDBCursor struct {
+	First  string `json:"f"`
+	Second string `json:"s"`
+}
+
+func encodeMongoDBCursor(c mongoDBCursor) (string, error) {
+	raw, err := common.SonicCfg.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeMongoDBCursor(token string) (mongoDBCursor, error) {
+	var c mongoDBCursor
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, fmt.Errorf("invalid pagination token: %w", err)
+	}
+	if err := common.SonicCfg.Unmarshal(raw, &c); err != nil {
+		return c, fmt.Errorf("invalid pagination token: %w", err)
+	}
+	return c, nil
+}
+
+// mongoDBAfter matches the documents sorted after the cursor by (first,
+// second).
+func mongoDBAfter(first, second string, c mongoDBCursor) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{first: bson.M{"$gt": c.First}},
+		bson.M{first: c.First, second: bson.M{"$gt": c.Second}},
+	}}
+}
+
+// scanPage returns up to limit live documents matching filter after cursor,
+// sorted by (first, second). A full page returns a cursor to its last
+// document; a short one ends the scan.
+func (m *MongoDBConnector) scanPage(ctx context.Context, filter bson.M, first, second string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	collection, err := m.getCollection()
+	if err != nil {
+		return nil, "", err
+	}
+
+	conds := bson.A{filter, bson.M{"expiresAt": mongoDBNotExpired(time.Now())}}
+	if cursor != "" {
+		after, err := decodeMongoDBCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		conds = append(conds, mongoDBAfter(first, second, after))
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, m.getTimeout, MongoDBDriverName, "getTimeout")
+	defer cancel()
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: first, Value: 1}, {Key: second, Value: 1}}).
+		SetProjection(bson.M{"pk": 1, "rk": 1, "value": 1})
+	if limit > 0 {
+		findOpts.SetLimit(int64(limit))
+	}
+	cur, err := collection.Find(ctx, bson.M{"$and": conds}, findOpts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	var results []KeyValuePair
+	for cur.Next(ctx) {
+		var doc mongoDBDocument
+		if err := cur.Decode(&doc); err != nil {
+			return nil, "", err
+		}
+		results = append(results, KeyValuePair{
+			PartitionKey: doc.PartitionKey,
+			RangeKey:     doc.RangeKey,
+			Value:        doc.Value,
+		})
+	}
+	if err := cur.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if limit <= 0 || len(results) < limit {
+		return results, "", nil
+	}
+	last := results[len(results)-1]
+	next := mongoDBCursor{First: last.PartitionKey, Second: last.RangeKey}
+	if first == "rk" {
+		next = mongoDBCursor{First: last.RangeKey, Second: last.PartitionKey}
+	}
+	token, err := encodeMongoDBCursor(next)
+	if err != nil {
+		return nil, "", err
+	}
+	return results, token, nil
+}
+
+// Lock acquires a distributed lock by upserting the lock document where it
+// is missing or expired. A live lock makes the upsert collide with it on the
+// unique index, so it is retried every lockRetryInterval until ctx is done.
+func (m *MongoDBConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	ctx, span := common.StartSpan(ctx, "MongoDBConnector.Lock",
+		trace.WithAttributes(
+			attribute.String("lock_key", key),
+			attribute.Int64("ttl_ms", ttl.Milliseconds()),
+		),
+	)
+	defer span.End()
+
+	collection, err := m.getCollection()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	lockKey := fmt.Sprintf("%s:lock", key)
+
+	for {
+		now := time.Now()
+		attemptCtx, cancel := withOperationTimeout(ctx, m.setTimeout, MongoDBDriverName, "setTimeout")
+		_, err := collection.UpdateOne(attemptCtx,
+			bson.M{"pk": lockKey, "rk": mongoDBLockRangeKey, "expiresAt": bson.M{"$lte": now}},
+			bson.M{"$set": bson.M{"value": []byte(token), "expiresAt": now.Add(ttl)}},
+			options.Update().SetUpsert(true),
+		)
+		cancel()
+		if err == nil {
+			m.logger.Debug().Str("lockKey", lockKey).Dur("ttl", ttl).Msg("distributed lock acquired")
+			return &mongoDBLock{connector: m, lockKey: lockKey, token: token}, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) && ctx.Err() == nil {
+			m.logger.Warn().Err(err).Str("lockKey", lockKey).Msg("failed to acquire lock")
+			common.SetTraceSpanError(span, err)
+			return nil, fmt.Errorf("failed to acquire lock for key '%s': %w", key, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			err := fmt.Errorf("lock acquisition cancelled or timed out for key '%s': %w", key, ctx.Err())
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		case <-util.After(m.lockRetryInterval):
+		}
+	}
+}
+
+var _ DistributedLock = &mongoDBLock{}
+
+type mongoDBLock struct {
+	connector *MongoDBConnector
+	lockKey   string
+	token     string
+}
+
+func (l *mongoDBLock) IsNil() bool {
+	return l == nil || l.connector == nil
+}
+
+// Unlock deletes the lock document only if it still holds this lock's token,
+// so a lock that expired and was taken by someone else is left alone.
+func (l *mongoDBLock) Unlock(ctx context.Context) error {
+	ctx, span := common.StartSpan(ctx, "MongoDBConnector.Unlock",
+		trace.WithAttributes(
+			attribute.String("lock_key", l.lockKey),
+		),
+	)
+	defer span.End()
+
+	collection, err := l.connector.getCollection()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, l.connector.setTimeout, MongoDBDriverName, "setTimeout")
+	defer cancel()
+
+	res, err := collection.DeleteOne(ctx, bson.M{"pk": l.lockKey, "rk": mongoDBLockRangeKey, "value": []byte(l.token)})
+	if err != nil {
+		err = fmt.Errorf("error releasing lock: %w", err)
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	if res.DeletedCount == 0 {
+		err := errors.New("failed to release lock: expired or held by another owner")
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	l.connector.logger.Debug().Str("lockKey", l.lockKey).Msg("distributed lock released")
+	return nil
+}
+
+// WatchCounterInt64 polls the counter every statePollInterval. Change streams
+// need a replica set and the connector also supports standalone servers.
+// Callers of this method are responsible to re-try the operation if "values"
+// channel is closed.
+func (m *MongoDBConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	if _, err := m.getCollection(); err != nil {
+		return nil, nil, err
+	}
+
+	updates := make(chan CounterInt64State, 1)
+	ticker := util.NewTicker(m.statePollInterval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		var lastUpdatedAt int64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C():
+				st, ok, err := m.getCounterState(ctx, key)
+				if err != nil {
+					m.logger.Warn().Err(err).Str("key", key).Msg("failed to poll counter value")
+					continue
+				}
+				if ok && st.UpdatedAt > lastUpdatedAt {
+					lastUpdatedAt = st.UpdatedAt
+					select {
+					case updates <- st:
+					default:
+					}
+				}
+			}
+		}
+	}()
+
+	if st, ok, err := m.getCounterState(ctx, key); err == nil && ok {
+		updates <- st
+	}
+
+	cleanup := func() {
+		close(done)
+		close(updates)
+	}
+
+	return updates, cleanup, nil
+}
+
+func (m *MongoDBConnector) getCounterState(ctx context.Context, key string) (CounterInt64State, bool, error) {
+	raw, err := m.Get(ctx, ConnectorMainIndex, key, "value", nil)
+	if err != nil {
+		if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+			return CounterInt64State{}, false, nil
+		}
+		return CounterInt64State{}, false, err
+	}
+	var st CounterInt64State
+	if err := common.SonicCfg.Unmarshal(raw, &st); err != nil || st.UpdatedAt <= 0 {
+		return CounterInt64State{}, false, nil
+	}
+	return st, true, nil
+}
+
+// PublishCounterInt64 is a no-op: counters are propagated by
+// WatchCounterInt64 polling the value stored via Set.
+func (m *MongoDBConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return nil
+}
@@ -0,0 +1,316 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMongoDBExpiresAt(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	ttl := func(d time.Duration) *time.Duration { return &d }
+
+	assert.Nil(t, mongoDBExpiresAt(now, nil))
+	assert.Nil(t, mongoDBExpiresAt(now, ttl(0)))
+	assert.Nil(t, mongoDBExpiresAt(now, ttl(-time.Second)))
+	assert.Equal(t, now.Add(time.Minute), *mongoDBExpiresAt(now, ttl(time.Minute)))
+}
+
+func TestMongoDBSetModel(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+
+	filter, update := mongoDBSetModel("evm:1:latest", "blockNumber", []byte("v"), &at)
+	assert.Equal(t, bson.M{"pk": "evm:1:latest", "rk": "blockNumber"}, filter)
+	assert.Equal(t, bson.M{"$set": bson.M{"value": []byte("v"), "expiresAt": &at}}, update)
+
+	_, update = mongoDBSetModel("evm:1:latest", "blockNumber", []byte("v"), nil)
+	assert.Equal(t, bson.M{"$set": bson.M{"value": []byte("v")}, "$unset": bson.M{"expiresAt": ""}}, update,
+		"rewriting an entry without a TTL must clear its previous expiry")
+}
+
+func TestMongoDBPrefixFilters(t *testing.T) {
+	assert.Equal(t, bson.M{"$gte": "evm:1:", "$lt": "evm:1:\U0010FFFF"}, mongoDBPrefixRange("evm:1:"))
+	assert.Equal(t, bson.M{"$regex": `^0x\.\*\(a\)`}, mongoDBPrefixRegex("0x.*(a)"), "regex metacharacters are matched literally")
+}
+
+func TestMongoDBCursor(t *testing.T) {
+	token, err := encodeMongoDBCursor(mongoDBCursor{First: "evm:1:0x1", Second: "eth_call:0xab"})
+	require.NoError(t, err)
+
+	c, err := decodeMongoDBCursor(token)
+	require.NoError(t, err)
+	assert.Equal(t, mongoDBCursor{First: "evm:1:0x1", Second: "eth_call:0xab"}, c)
+
+	_, err = decodeMongoDBCursor("not base64!")
+	assert.Error(t, err)
+
+	assert.Equal(t, bson.M{"$or": bson.A{
+		bson.M{"rk": bson.M{"$gt": "a"}},
+		bson.M{"rk": "a", "pk": bson.M{"$gt": "b"}},
+	}}, mongoDBAfter("rk", "pk", mongoDBCursor{First: "a", Second: "b"}))
+}
+
+func TestMongoDBWriteConcern(t *testing.T) {
+	wc, err := mongoDBWriteConcern("majority")
+	require.NoError(t, err)
+	assert.Equal(t, "majority", wc.W)
+
+	wc, err = mongoDBWriteConcern("2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, wc.W)
+
+	_, err = mongoDBWriteConcern("all")
+	assert.Error(t, err)
+}
+
+// startMongoDB runs a standalone MongoDB container and returns its URI.
+func startMongoDB(t *testing.T, ctx context.Context) string {
+	t.Helper()
+	req := testcontainers.ContainerRequest{
+		Image:        "mongo:7",
+		ExposedPorts: []string{"27017/tcp"},
+		WaitingFor:   wait.ForListeningPort("27017/tcp"),
+	}
+	mongoC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	require.NoError(t, err, "failed to start MongoDB container")
+	t.Cleanup(func() { _ = mongoC.Terminate(context.Background()) })
+
+	host, err := mongoC.Host(ctx)
+	require.NoError(t, err)
+	port, err := mongoC.MappedPort(ctx, "27017")
+	require.NoError(t, err)
+	return fmt.Sprintf("mongodb://%s:%s", host, port.Port())
+}
+
+func newTestMongoDBConnector(t *testing.T, ctx context.Context, uri, collection string) *MongoDBConnector {
+	t.Helper()
+	cfg := &common.MongoDBConnectorConfig{
+		URI:               uri,
+		Database:          "erpc_test",
+		Collection:        collection,
+		ReadPreference:    "primary",
+		WriteConcern:      "majority",
+		MaxPoolSize:       10,
+		InitTimeout:       common.Duration(10 * time.Second),
+		GetTimeout:        common.Duration(5 * time.Second),
+		SetTimeout:        common.Duration(5 * time.Second),
+		StatePollInterval: common.Duration(100 * time.Millisecond),
+		LockRetryInterval: common.Duration(50 * time.Millisecond),
+	}
+	connector, err := NewMongoDBConnector(ctx, &log.Logger, "test-mongodb", cfg)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return connector.State() == ConnectorStateHealthy
+	}, 10*time.Second, 100*time.Millisecond, "connector should be ready")
+	return connector
+}
+
+func TestMongoDBConnector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uri := startMongoDB(t, ctx)
+
+	t.Run("SetGetDelete", func(t *testing.T) {
+		c := newTestMongoDBConnector(t, ctx, uri, "set_get_delete")
+
+		require.NoError(t, c.Set(ctx, "evm:1:100", "eth_getBlockByNumber:abc", []byte("hello"), nil))
+		val, err := c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:abc", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hello"), val)
+
+		require.NoError(t, c.Set(ctx, "evm:1:100", "eth_getBlockByNumber:abc", []byte("again"), nil))
+		val, err = c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:abc", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("again"), val, "Set replaces the entry")
+
+		require.NoError(t, c.Delete(ctx, "evm:1:100", "eth_getBlockByNumber:abc"))
+		_, err = c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:abc", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("ReverseIndexPrefersGreatestPartitionKey", func(t *testing.T) {
+		c := newTestMongoDBConnector(t, ctx, uri, "reverse_index")
+
+		require.NoError(t, c.Set(ctx, "evm:1:100", "tx:0xab", []byte("older"), nil))
+		require.NoError(t, c.Set(ctx, "evm:1:101", "tx:0xab", []byte("newer"), nil))
+		val, err := c.Get(ctx, ConnectorReverseIndex, "evm:1:*", "tx:0xab", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("newer"), val)
+
+		val, err = c.Get(ctx, ConnectorReverseIndex, "evm:1:100", "tx:0xab", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("older"), val)
+	})
+
+	t.Run("TTLExpiry", func(t *testing.T) {
+		c := newTestMongoDBConnector(t, ctx, uri, "ttl_expiry")
+
+		ttl := 500 * time.Millisecond
+		require.NoError(t, c.Set(ctx, "evm:1:100", "eth_call:h", []byte("short"), &ttl))
+		val, err := c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("short"), val)
+
+		// Reads filter on expiresAt, so an entry is gone before the TTL
+		// monitor removes the document.
+		time.Sleep(ttl + 100*time.Millisecond)
+		_, err = c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+		items, _, err := c.Scan(ctx, "evm:1:", "", 10, "")
+		require.NoError(t, err)
+		assert.Empty(t, items, "scans skip expired entries")
+
+		require.NoError(t, c.Set(ctx, "evm:1:100", "eth_call:h", []byte("forever"), nil))
+		time.Sleep(ttl)
+		val, err = c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("forever"), val, "rewriting without a TTL clears the expiry")
+	})
+
+	t.Run("SetGuarded", func(t *testing.T) {
+		c := newTestMongoDBConnector(t, ctx, uri, "set_guarded")
+
+		stored, err := c.SetGuarded(ctx, "evm:1:100", "eth_getBlockByNumber:h", []byte("final"), 101, nil)
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		stored, err = c.SetGuarded(ctx, "evm:1:100", "eth_getBlockByNumber:h", []byte("unfinal"), 0, nil)
+		require.NoError(t, err)
+		assert.False(t, stored, "a lower guard is refused")
+
+		stored, err = c.SetGuarded(ctx, "evm:1:100", "eth_getBlockByNumber:h", []byte("again"), 101, nil)
+		require.NoError(t, err)
+		assert.True(t, stored, "an equal guard replaces")
+
+		val, err := c.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:h", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("again"), val)
+
+		ttl := 300 * time.Millisecond
+		stored, err = c.SetGuarded(ctx, "evm:1:100", "eth_call:h", []byte("final"), 101, &ttl)
+		require.NoError(t, err)
+		require.True(t, stored)
+		time.Sleep(ttl + 100*time.Millisecond)
+		stored, err = c.SetGuarded(ctx, "evm:1:100", "eth_call:h", []byte("unfinal"), 0, nil)
+		require.NoError(t, err)
+		assert.True(t, stored, "an expired entry does not guard")
+	})
+
+	t.Run("ScanAndList", func(t *testing.T) {
+		c := newTestMongoDBConnector(t, ctx, uri, "scan_list")
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, c.Set(ctx, fmt.Sprintf("evm:1:%d", i), "eth_call:h", []byte("v"), nil))
+		}
+		require.NoError(t, c.Set(ctx, "evm:2:0", "eth_call:h", []byte("v"), nil))
+		require.NoError(t, c.Set(ctx, "evm:1:0", "eth_getBalance:h", []byte("v"), nil))
+
+		var scanned []string
+		cursor := ""
+		for {
+			page, next, err := c.Scan(ctx, "evm:1:", "eth_call", 2, cursor)
+			require.NoError(t, err)
+			for _, kv := range page {
+				scanned = append(scanned, kv.PartitionKey+"/"+kv.RangeKey)
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		assert.Equal(t, []string{
+			"evm:1:0/eth_call:h", "evm:1:1/eth_call:h", "evm:1:2/eth_call:h", "evm:1:3/eth_call:h", "evm:1:4/eth_call:h",
+		}, scanned)
+
+		listed := 0
+		token := ""
+		for {
+			page, next, err := c.List(ctx, ConnectorReverseIndex, 3, token)
+			require.NoError(t, err)
+			listed += len(page)
+			if next == "" {
+				break
+			}
+			token = next
+		}
+		assert.Equal(t, 7, listed)
+
+		deleted, err := c.DeleteByPrefix(ctx, "evm:1:")
+		require.NoError(t, err)
+		assert.Equal(t, 6, deleted)
+		_, err = c.Get(ctx, ConnectorMainIndex, "evm:2:0", "eth_call:h", nil)
+		assert.NoError(t, err, "other partitions are kept")
+	})
+
+	t.Run("Lock", func(t *testing.T) {
+		c := newTestMongoDBConnector(t, ctx, uri, "lock")
+
+		lock, err := c.Lock(ctx, "job", 10*time.Second)
+		require.NoError(t, err)
+
+		waitCtx, waitCancel := context.WithTimeout(ctx, 300*time.Millisecond)
+		_, err = c.Lock(waitCtx, "job", 10*time.Second)
+		waitCancel()
+		assert.Error(t, err, "a held lock cannot be acquired")
+
+		require.NoError(t, lock.Unlock(ctx))
+		assert.Error(t, lock.Unlock(ctx), "a released lock cannot be released again")
+
+		lock, err = c.Lock(ctx, "job", 300*time.Millisecond)
+		require.NoError(t, err, "a released lock can be acquired")
+
+		// An expired lock is taken over, and its former holder cannot
+		// release it.
+		_, err = c.Lock(ctx, "job", 10*time.Second)
+		require.NoError(t, err)
+		assert.Error(t, lock.Unlock(ctx))
+	})
+
+	t.Run("WatchCounterInt64", func(t *testing.T) {
+		c := newTestMongoDBConnector(t, ctx, uri, "counter")
+
+		raw, err := common.SonicCfg.Marshal(CounterInt64State{Value: 42, UpdatedAt: 1})
+		require.NoError(t, err)
+		require.NoError(t, c.Set(ctx, "counter-key", "value", raw, nil))
+
+		updates, cleanup, err := c.WatchCounterInt64(ctx, "counter-key")
+		require.NoError(t, err)
+		defer cleanup()
+
+		select {
+		case st := <-updates:
+			assert.Equal(t, int64(42), st.Value)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no initial counter value")
+		}
+
+		raw, err = common.SonicCfg.Marshal(CounterInt64State{Value: 43, UpdatedAt: 2})
+		require.NoError(t, err)
+		require.NoError(t, c.Set(ctx, "counter-key", "value", raw, nil))
+		// The first poll may still report the initial value.
+		deadline := time.After(5 * time.Second)
+		for {
+			select {
+			case st := <-updates:
+				if st.Value == 43 {
+					return
+				}
+			case <-deadline:
+				t.Fatal("polling did not pick up the newer value")
+			}
+		}
+	})
+}
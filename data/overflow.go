@@ -0,0 +1,498 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	S3DriverName = "s3"
+
+	overflowLifecycleRulePrefix = "erpc-overflow:"
+)
+
+// overflowPointerPrefix marks a value whose payload lives in S3; the object
+// key follows it. Like TombstoneValue it starts with a NUL byte, which no
+// cached value does.
+var overflowPointerPrefix = []byte("\x00erpc:s3\x00")
+
+// overflowTTLGroups are the lifecycle groups (in days) objects are filed
+// under. A TTL is rounded up to the next group, so an object always outlives
+// the pointer to it.
+var overflowTTLGroups = []int{1, 7, 30, 90, 365}
+
+var s3HttpClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext:         util.DefaultOutboundDialer().DialContext,
+		MaxIdleConns:        256,
+		MaxIdleConnsPerHost: 256,
+		IdleConnTimeout:     120 * time.Second,
+	},
+}
+
+// NewS3Client returns an S3 client authenticated like the connectors'
+// AWS clients. endpoint and forcePathStyle target S3-compatible stores
+// (MinIO, GCS interoperability); empty endpoint uses AWS.
+func NewS3Client(auth *common.AwsAuthConfig, region, endpoint string, forcePathStyle bool) (*s3.S3, error) {
+	sess, err := createAWSSession(auth, region)
+	if err != nil {
+		return nil, err
+	}
+	return s3.New(sess, &aws.Config{
+		Endpoint:         aws.String(endpoint),
+		Region:           aws.String(region),
+		S3ForcePathStyle: aws.Bool(forcePathStyle),
+		HTTPClient:       s3HttpClient,
+	}), nil
+}
+
+// IsOverflowPointer reports whether value points to an object in S3.
+func IsOverflowPointer(value []byte) bool {
+	return bytes.HasPrefix(value, overflowPointerPrefix)
+}
+
+// OverflowConnector stores values larger than the configured threshold in S3
+// and keeps only a pointer in the wrapped connector. Reads of a pointer fetch
+// the object; a missing object (expired by lifecycle or deleted) is a miss.
+// See common.OverflowConfig.
+type OverflowConnector struct {
+	wrapped         Connector
+	logger          *zerolog.Logger
+	initializer     *util.Initializer
+	threshold       int
+	bucket          string
+	prefix          string
+	manageLifecycle bool
+	getTimeout      time.Duration
+	setTimeout      time.Duration
+
+	mu     sync.RWMutex
+	client *s3.S3
+}
+
+var _ Connector = (*OverflowConnector)(nil)
+var _ CacheHeadReporter = (*OverflowConnector)(nil)
+var _ GuardedSetter = (*OverflowConnector)(nil)
+
+func NewOverflowConnector(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	wrapped Connector,
+	cfg *common.OverflowConfig,
+) (*OverflowConnector, error) {
+	threshold, err := util.ParseByteSize(cfg.Threshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse overflow threshold '%s': %w", cfg.Threshold, err)
+	}
+	if cfg.S3 == nil {
+		return nil, fmt.Errorf("overflow.s3 is required for connector %s", wrapped.Id())
+	}
+
+	lg := logger.With().Str("component", "overflowConnector").Str("connectorId", wrapped.Id()).Str("bucket", cfg.S3.Bucket).Logger()
+	o := &OverflowConnector{
+		wrapped:         wrapped,
+		logger:          &lg,
+		threshold:       threshold,
+		bucket:          cfg.S3.Bucket,
+		prefix:          cfg.S3.Prefix,
+		manageLifecycle: cfg.S3.ManageLifecycle == nil || *cfg.S3.ManageLifecycle,
+		getTimeout:      cfg.S3.GetTimeout.Duration(),
+		setTimeout:      cfg.S3.SetTimeout.Duration(),
+	}
+
+	o.initializer = util.NewInitializer(ctx, &lg, nil)
+	connectTask := util.NewBootstrapTask(fmt.Sprintf("s3-overflow-connect/%s", wrapped.Id()), func(ctx context.Context) error {
+		return o.connectTask(ctx, cfg.S3)
+	})
+	if err := o.initializer.ExecuteTasks(ctx, connectTask); err != nil {
+		lg.Error().Err(err).Msg("failed to initialize S3 overflow on first attempt (will retry in background)")
+		// Small values keep flowing to the wrapped connector meanwhile.
+		return o, nil
+	}
+
+	return o, nil
+}
+
+func (o *OverflowConnector) connectTask(ctx context.Context, cfg *common.S3OverflowConfig) error {
+	client, err := NewS3Client(cfg.Auth, cfg.Region, cfg.Endpoint, cfg.ForcePathStyle)
+	if err != nil {
+		return common.NewTaskFatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.InitTimeout.Duration())
+	defer cancel()
+	if _, err := client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(o.bucket)}); err != nil {
+		return fmt.Errorf("failed to access s3 bucket %s: %w", o.bucket, err)
+	}
+	if o.manageLifecycle {
+		if err := o.ensureLifecycleRules(ctx, client); err != nil {
+			// Not fatal: values are still stored and served, they just never
+			// expire until the rules exist.
+			o.logger.Warn().Err(err).Msg("failed to install S3 lifecycle rules for overflow objects; create them manually or objects will not expire")
+		}
+	}
+
+	o.mu.Lock()
+	o.client = client
+	o.mu.Unlock()
+	o.logger.Info().Str("prefix", o.prefix).Int("threshold", o.threshold).Msg("S3 overflow is ready")
+	return nil
+}
+
+// ensureLifecycleRules installs one expiration rule per TTL group, replacing
+// earlier eRPC rules for the same prefix and keeping all other rules.
+func (o *OverflowConnector) ensureLifecycleRules(ctx context.Context, client *s3.S3) error {
+	var rules []*s3.LifecycleRule
+	out, err := client.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(o.bucket),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if !errors.As(err, &aerr) || aerr.Code() != "NoSuchLifecycleConfiguration" {
+			return err
+		}
+	} else {
+		rules = out.Rules
+	}
+
+	wanted := o.lifecycleRules()
+	kept := make([]*s3.LifecycleRule, 0, len(rules)+len(wanted))
+	missing := len(wanted)
+	for _, r := range rules {
+		id := aws.StringValue(r.ID)
+		if !strings.HasPrefix(id, overflowLifecycleRulePrefix+o.prefix+"ttl-") {
+			kept = append(kept, r)
+			continue
+		}
+		for _, w := range wanted {
+			if id == aws.StringValue(w.ID) && r.Expiration != nil && aws.Int64Value(r.Expiration.Days) == aws.Int64Value(w.Expiration.Days) &&
+				r.Filter != nil && aws.StringValue(r.Filter.Prefix) == aws.StringValue(w.Filter.Prefix) && aws.StringValue(r.Status) == s3.ExpirationStatusEnabled {
+				missing--
+			}
+		}
+	}
+	if missing == 0 {
+		return nil
+	}
+
+	_, err = client.PutBucketLifecycleConfigurationWithContext(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(o.bucket),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: append(kept, wanted...)},
+	})
+	if err == nil {
+		o.logger.Info().Ints("days", overflowTTLGroups).Msg("installed S3 lifecycle rules for overflow objects")
+	}
+	return err
+}
+
+func (o *OverflowConnector) lifecycleRules() []*s3.LifecycleRule {
+	rules := make([]*s3.LifecycleRule, 0, len(overflowTTLGroups))
+	for _, days := range overflowTTLGroups {
+		group := fmt.Sprintf("%sttl-%dd", o.prefix, days)
+		rules = append(rules, &s3.LifecycleRule{
+			ID:         aws.String(overflowLifecycleRulePrefix + group),
+			Status:     aws.String(s3.ExpirationStatusEnabled),
+			Filter:     &s3.LifecycleRuleFilter{Prefix: aws.String(group + "/")},
+			Expiration: &s3.LifecycleExpiration{Days: aws.Int64(int64(days))},
+		})
+	}
+	return rules
+}
+
+func (o *OverflowConnector) s3Client() (*s3.S3, error) {
+	if state := o.initializer.State(); state != util.StateReady {
+		return nil, fmt.Errorf("s3 overflow is not connected (state: %s), errors: %v", state.String(), o.initializer.Errors())
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if o.client == nil {
+		return nil, fmt.Errorf("s3 client not initialized yet")
+	}
+	return o.client, nil
+}
+
+// objectKey is stable per cache key, so rewrites replace the object instead
+// of orphaning it (unless the TTL group changes).
+func (o *OverflowConnector) objectKey(partitionKey, rangeKey string, ttl *time.Duration) string {
+	h := sha256.Sum256([]byte(partitionKey + "\x00" + rangeKey))
+	return o.prefix + overflowTTLGroup(ttl) + "/" + hex.EncodeToString(h[:])
+}
+
+func overflowTTLGroup(ttl *time.Duration) string {
+	if ttl == nil || *ttl <= 0 {
+		return "permanent"
+	}
+	days := int((*ttl + 24*time.Hour - 1) / (24 * time.Hour))
+	for _, g := range overflowTTLGroups {
+		if days <= g {
+			return fmt.Sprintf("ttl-%dd", g)
+		}
+	}
+	return "permanent"
+}
+
+func (o *OverflowConnector) Id() string {
+	return o.wrapped.Id()
+}
+
+func (o *OverflowConnector) State() ConnectorState {
+	return worstConnectorState(o.wrapped.State(), initializerConnectorState(o.initializer))
+}
+
+func (o *OverflowConnector) Ping(ctx context.Context) error {
+	if err := o.wrapped.Ping(ctx); err != nil {
+		return err
+	}
+	client, err := o.s3Client()
+	if err != nil {
+		return err
+	}
+	_, err = client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(o.bucket)})
+	return err
+}
+
+func (o *OverflowConnector) CacheLatestBlockTimestamp(networkId string) (int64, bool) {
+	if r, ok := o.wrapped.(CacheHeadReporter); ok {
+		return r.CacheLatestBlockTimestamp(networkId)
+	}
+	return 0, false
+}
+
+// Set writes values above the threshold to S3 first and then the pointer, so
+// a reader never sees a pointer to an object that was not written.
+func (o *OverflowConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	if len(value) <= o.threshold {
+		return o.wrapped.Set(ctx, partitionKey, rangeKey, value, ttl)
+	}
+
+	ctx, span := common.StartSpan(ctx, "OverflowConnector.Set")
+	defer span.End()
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+		)
+	}
+
+	pointer, err := o.offload(ctx, o.objectKey(partitionKey, rangeKey, ttl), value)
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	return o.wrapped.Set(ctx, partitionKey, rangeKey, pointer, ttl)
+}
+
+// SetGuarded offloads large values to an object named after the guard too,
+// so a write the wrapped connector rejects never replaces the object a stored
+// pointer refers to. The rejected write's object is left to the bucket's
+// lifecycle rule.
+func (o *OverflowConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	if len(value) <= o.threshold {
+		return SetGuarded(ctx, o.wrapped, partitionKey, rangeKey, value, guard, ttl)
+	}
+	key := o.objectKey(partitionKey, fmt.Sprintf("%s\x00%d", rangeKey, guard), ttl)
+	pointer, err := o.offload(ctx, key, value)
+	if err != nil {
+		return false, err
+	}
+	return SetGuarded(ctx, o.wrapped, partitionKey, rangeKey, pointer, guard, ttl)
+}
+
+// SetMany offloads the items above the threshold one by one and then writes
+// their pointers in the same batch as the items kept inline.
+func (o *OverflowConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	var errs []error
+	batch := make([]KeyValuePair, 0, len(items))
+	for _, item := range items {
+		if len(item.Value) > o.threshold {
+			pointer, err := o.offload(ctx, o.objectKey(item.PartitionKey, item.RangeKey, ttl), item.Value)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			item.Value = pointer
+		}
+		batch = append(batch, item)
+	}
+	if len(batch) > 0 {
+		errs = append(errs, o.wrapped.SetMany(ctx, batch, ttl))
+	}
+	return errors.Join(errs...)
+}
+
+// offload writes value to S3 under key and returns the pointer to store in
+// its place.
+func (o *OverflowConnector) offload(ctx context.Context, key string, value []byte) ([]byte, error) {
+	client, err := o.s3Client()
+	if err != nil {
+		telemetry.MetricConnectorOverflowTotal.WithLabelValues(o.wrapped.Id(), "put", "error").Inc()
+		return nil, err
+	}
+
+	pctx, cancel := withOperationTimeout(ctx, o.setTimeout, S3DriverName, "setTimeout")
+	_, err = client.PutObjectWithContext(pctx, &s3.PutObjectInput{
+		Bucket:        aws.String(o.bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(value),
+		ContentLength: aws.Int64(int64(len(value))),
+		ContentType:   aws.String("application/octet-stream"),
+	})
+	cancel()
+	if err != nil {
+		telemetry.MetricConnectorOverflowTotal.WithLabelValues(o.wrapped.Id(), "put", "error").Inc()
+		o.logger.Warn().Err(err).Str("key", key).Int("size", len(value)).Msg("failed to write overflow object to S3")
+		return nil, err
+	}
+	telemetry.MetricConnectorOverflowTotal.WithLabelValues(o.wrapped.Id(), "put", "ok").Inc()
+	telemetry.MetricConnectorOverflowBytesTotal.WithLabelValues(o.wrapped.Id(), "put").Add(float64(len(value)))
+
+	pointer := make([]byte, 0, len(overflowPointerPrefix)+len(key))
+	return append(append(pointer, overflowPointerPrefix...), key...), nil
+}
+
+func (o *OverflowConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error) {
+	value, err := o.wrapped.Get(ctx, index, partitionKey, rangeKey, metadata)
+	if err != nil || !IsOverflowPointer(value) {
+		return value, err
+	}
+	return o.fetch(ctx, value, partitionKey, rangeKey)
+}
+
+func (o *OverflowConnector) fetch(ctx context.Context, pointer []byte, partitionKey, rangeKey string) ([]byte, error) {
+	ctx, span := common.StartSpan(ctx, "OverflowConnector.Get")
+	defer span.End()
+
+	client, err := o.s3Client()
+	if err != nil {
+		telemetry.MetricConnectorOverflowTotal.WithLabelValues(o.wrapped.Id(), "get", "error").Inc()
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	key := string(pointer[len(overflowPointerPrefix):])
+	gctx, cancel := withOperationTimeout(ctx, o.getTimeout, S3DriverName, "getTimeout")
+	defer cancel()
+	out, err := client.GetObjectWithContext(gctx, &s3.GetObjectInput{
+		Bucket: aws.String(o.bucket),
+		Key:    aws.String(key),
+	})
+	if err == nil {
+		defer out.Body.Close()
+		var value []byte
+		value, err = io.ReadAll(out.Body)
+		if err == nil {
+			telemetry.MetricConnectorOverflowTotal.WithLabelValues(o.wrapped.Id(), "get", "ok").Inc()
+			telemetry.MetricConnectorOverflowBytesTotal.WithLabelValues(o.wrapped.Id(), "get").Add(float64(len(value)))
+			return value, nil
+		}
+	}
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+		telemetry.MetricConnectorOverflowTotal.WithLabelValues(o.wrapped.Id(), "get", "miss").Inc()
+		return nil, common.NewErrRecordNotFound(partitionKey, rangeKey, o.wrapped.Id())
+	}
+	telemetry.MetricConnectorOverflowTotal.WithLabelValues(o.wrapped.Id(), "get", "error").Inc()
+	o.logger.Warn().Err(err).Str("key", key).Msg("failed to read overflow object from S3")
+	common.SetTraceSpanError(span, err)
+	return nil, err
+}
+
+// Delete removes the pointer and then the object. An object left behind by a
+// failed delete is still removed by its lifecycle rule.
+func (o *OverflowConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	current, getErr := o.wrapped.Get(ctx, ConnectorMainIndex, partitionKey, rangeKey, nil)
+	if err := o.wrapped.Delete(ctx, partitionKey, rangeKey); err != nil {
+		return err
+	}
+	if getErr != nil || !IsOverflowPointer(current) {
+		return nil
+	}
+
+	client, err := o.s3Client()
+	if err == nil {
+		key := string(current[len(overflowPointerPrefix):])
+		dctx, cancel := withOperationTimeout(ctx, o.setTimeout, S3DriverName, "setTimeout")
+		_, err = client.DeleteObjectWithContext(dctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(o.bucket),
+			Key:    aws.String(key),
+		})
+		cancel()
+	}
+	if err != nil {
+		telemetry.MetricConnectorOverflowTotal.WithLabelValues(o.wrapped.Id(), "delete", "error").Inc()
+		o.logger.Warn().Err(err).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("failed to delete overflow object from S3, leaving it to the lifecycle rule")
+		return nil
+	}
+	telemetry.MetricConnectorOverflowTotal.WithLabelValues(o.wrapped.Id(), "delete", "ok").Inc()
+	return nil
+}
+
+// DeleteByPrefix scans the wrapped connector, where overflowed values are
+// only pointers, and deletes each entry with its S3 object.
+func (o *OverflowConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return deleteByScan(ctx, o.wrapped.Scan, o.Delete, partitionKeyPrefix)
+}
+
+func (o *OverflowConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	items, next, err := o.wrapped.List(ctx, index, limit, paginationToken)
+	if err != nil {
+		return nil, "", err
+	}
+	return o.resolve(ctx, items), next, nil
+}
+
+func (o *OverflowConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	items, next, err := o.wrapped.Scan(ctx, partitionKeyPrefix, rangeKeyPrefix, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	return o.resolve(ctx, items), next, nil
+}
+
+// resolve replaces pointers with their objects, dropping entries whose object
+// cannot be read.
+func (o *OverflowConnector) resolve(ctx context.Context, items []KeyValuePair) []KeyValuePair {
+	kept := items[:0]
+	for _, kv := range items {
+		if IsOverflowPointer(kv.Value) {
+			value, err := o.fetch(ctx, kv.Value, kv.PartitionKey, kv.RangeKey)
+			if err != nil {
+				continue
+			}
+			kv.Value = value
+		}
+		kept = append(kept, kv)
+	}
+	return kept
+}
+
+func (o *OverflowConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	return o.wrapped.Lock(ctx, key, ttl)
+}
+
+func (o *OverflowConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	return o.wrapped.WatchCounterInt64(ctx, key)
+}
+
+func (o *OverflowConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return o.wrapped.PublishCounterInt64(ctx, key, value)
+}
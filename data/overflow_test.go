@@ -0,0 +1,236 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a path-style S3 endpoint with just the calls the overflow
+// connector makes.
+type fakeS3 struct {
+	mu             sync.Mutex
+	objects        map[string][]byte
+	lifecycle      []byte
+	lifecyclePuts  int
+	failPutObjects bool
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{objects: map[string][]byte{}}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+	if bucket != "erpc-overflow" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case key == "" && r.URL.Query().Has("lifecycle") && r.Method == http.MethodGet:
+		if f.lifecycle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<Error><Code>NoSuchLifecycleConfiguration</Code><Message>none</Message></Error>`))
+			return
+		}
+		_, _ = w.Write(f.lifecycle)
+	case key == "" && r.URL.Query().Has("lifecycle") && r.Method == http.MethodPut:
+		f.lifecycle = body
+		f.lifecyclePuts++
+	case r.Method == http.MethodPut:
+		if f.failPutObjects {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<Error><Code>InternalError</Code><Message>boom</Message></Error>`))
+			return
+		}
+		f.objects[key] = body
+	case r.Method == http.MethodGet:
+		v, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>missing</Message></Error>`))
+			return
+		}
+		_, _ = w.Write(v)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for k := range f.objects {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestOverflowConnector(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newConnector := func(t *testing.T, endpoint string) (*OverflowConnector, *MemoryConnector) {
+		mem, err := NewMemoryConnector(ctx, &logger, "test", &common.MemoryConnectorConfig{
+			MaxItems: 1000, MaxTotalSize: "10MB",
+		})
+		require.NoError(t, err)
+		cfg := &common.OverflowConfig{
+			Threshold: "1KB",
+			S3: &common.S3OverflowConfig{
+				Bucket:         "erpc-overflow",
+				Region:         "us-east-1",
+				Endpoint:       endpoint,
+				ForcePathStyle: true,
+				Auth:           &common.AwsAuthConfig{Mode: "secret", AccessKeyID: "test", SecretAccessKey: "test"},
+			},
+		}
+		cfg.SetDefaults()
+		require.NoError(t, cfg.Validate())
+		oc, err := NewOverflowConnector(ctx, &logger, mem, cfg)
+		require.NoError(t, err)
+		return oc, mem
+	}
+	large := bytes.Repeat([]byte("a"), 4096)
+	ttl := 2 * time.Hour
+
+	t.Run("SmallValuesStayInTheConnector", func(t *testing.T) {
+		s3, srv := newFakeS3(t)
+		oc, mem := newConnector(t, srv.URL)
+		require.NoError(t, oc.Set(ctx, "evm:1:100", "eth_call:h", []byte(`"0x1"`), nil))
+		mem.cache.Wait()
+
+		raw, err := mem.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`"0x1"`), raw)
+		assert.Empty(t, s3.keys())
+	})
+
+	t.Run("LargeValuesAreStoredInS3", func(t *testing.T) {
+		s3, srv := newFakeS3(t)
+		oc, mem := newConnector(t, srv.URL)
+		require.NoError(t, oc.Set(ctx, "evm:1:100", "eth_getLogs:h", large, &ttl))
+		mem.cache.Wait()
+
+		keys := s3.keys()
+		require.Len(t, keys, 1)
+		assert.True(t, strings.HasPrefix(keys[0], "erpc/ttl-1d/"), keys[0])
+		raw, err := mem.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getLogs:h", nil)
+		require.NoError(t, err)
+		assert.True(t, IsOverflowPointer(raw))
+		assert.Less(t, len(raw), 100)
+
+		v, err := oc.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getLogs:h", nil)
+		require.NoError(t, err)
+		assert.Equal(t, large, v)
+
+		items, _, err := oc.Scan(ctx, "evm:1:", "", 10, "")
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, large, items[0].Value)
+	})
+
+	t.Run("ExpiredObjectIsAMiss", func(t *testing.T) {
+		s3, srv := newFakeS3(t)
+		oc, mem := newConnector(t, srv.URL)
+		require.NoError(t, oc.Set(ctx, "evm:1:100", "eth_getLogs:h", large, nil))
+		mem.cache.Wait()
+		require.Len(t, s3.keys(), 1)
+		assert.True(t, strings.HasPrefix(s3.keys()[0], "erpc/permanent/"))
+
+		s3.mu.Lock()
+		s3.objects = map[string][]byte{}
+		s3.mu.Unlock()
+		_, err := oc.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getLogs:h", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), "got %v", err)
+	})
+
+	t.Run("FailedUploadLeavesNoPointer", func(t *testing.T) {
+		s3, srv := newFakeS3(t)
+		oc, mem := newConnector(t, srv.URL)
+		s3.mu.Lock()
+		s3.failPutObjects = true
+		s3.mu.Unlock()
+		require.Error(t, oc.Set(ctx, "evm:1:100", "eth_getLogs:h", large, &ttl))
+		mem.cache.Wait()
+		_, err := mem.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getLogs:h", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("DeleteRemovesPointerAndObject", func(t *testing.T) {
+		s3, srv := newFakeS3(t)
+		oc, mem := newConnector(t, srv.URL)
+		require.NoError(t, oc.Set(ctx, "evm:1:100", "eth_getLogs:h", large, &ttl))
+		mem.cache.Wait()
+		require.NoError(t, oc.Delete(ctx, "evm:1:100", "eth_getLogs:h"))
+		mem.cache.Wait()
+
+		assert.Empty(t, s3.keys())
+		_, err := oc.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getLogs:h", nil)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("LifecycleRulesAreInstalledOnceAndKeepOtherRules", func(t *testing.T) {
+		s3, srv := newFakeS3(t)
+		s3.lifecycle = []byte(`<LifecycleConfiguration><Rule><ID>keep-me</ID><Status>Enabled</Status><Filter><Prefix>logs/</Prefix></Filter><Expiration><Days>3</Days></Expiration></Rule></LifecycleConfiguration>`)
+
+		newConnector(t, srv.URL)
+		s3.mu.Lock()
+		rules := string(s3.lifecycle)
+		assert.Equal(t, 1, s3.lifecyclePuts)
+		s3.mu.Unlock()
+		assert.Contains(t, rules, "keep-me")
+		for _, group := range []string{"ttl-1d", "ttl-7d", "ttl-30d", "ttl-90d", "ttl-365d"} {
+			assert.Contains(t, rules, "<Prefix>erpc/"+group+"/</Prefix>")
+		}
+
+		newConnector(t, srv.URL)
+		s3.mu.Lock()
+		assert.Equal(t, 1, s3.lifecyclePuts, "rules already in place are not rewritten")
+		s3.mu.Unlock()
+	})
+
+	t.Run("UnreachableBucketKeepsSmallValuesWorking", func(t *testing.T) {
+		oc, _ := newConnector(t, "http://127.0.0.1:1")
+		assert.NotEqual(t, util.StateReady, oc.initializer.State())
+		require.NoError(t, oc.Set(ctx, "evm:1:100", "eth_call:h", []byte(`"0x1"`), nil))
+		require.Error(t, oc.Set(ctx, "evm:1:100", "eth_getLogs:h", large, &ttl))
+	})
+}
+
+func TestOverflowTTLGroup(t *testing.T) {
+	d := func(v time.Duration) *time.Duration { return &v }
+	assert.Equal(t, "permanent", overflowTTLGroup(nil))
+	assert.Equal(t, "permanent", overflowTTLGroup(d(0)))
+	assert.Equal(t, "ttl-1d", overflowTTLGroup(d(time.Second)))
+	assert.Equal(t, "ttl-1d", overflowTTLGroup(d(24*time.Hour)))
+	assert.Equal(t, "ttl-7d", overflowTTLGroup(d(24*time.Hour+time.Second)))
+	assert.Equal(t, "ttl-365d", overflowTTLGroup(d(200*24*time.Hour)))
+	assert.Equal(t, "permanent", overflowTTLGroup(d(400*24*time.Hour)))
+}
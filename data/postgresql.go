@@ -37,6 +37,7 @@ const (
 var ErrConnectorNotReady = errors.New("PostgreSQLConnector not connected yet")
 
 var _ Connector = (*PostgreSQLConnector)(nil)
+var _ GuardedSetter = (*PostgreSQLConnector)(nil)
 
 type PostgreSQLConnector struct {
 	id     string
@@ -216,10 +217,9 @@ func (p *PostgreSQLConnector) connectTask(ctx context.Context, cfg *common.Postg
 
 	// Apply schema at most once successfully per process. ensureSchema
 	// serializes the check-and-set under a mutex so two concurrent
-	// connectTask calls can't both run the migration in parallel — only
-	// the `CREATE TABLE IF NOT EXISTS` / `CREATE INDEX IF NOT EXISTS` parts
-	// are safe under that race; the TEXT→BYTEA `DROP COLUMN` and
-	// `cron.schedule` steps are not.
+	// connectTask calls can't both run the migrations in parallel — the
+	// `cron.schedule` step is not idempotent. Across replicas, the
+	// migrations are serialized by an advisory lock (see applySchema).
 	// Schema setup issues DDL (CREATE TABLE/INDEX, ALTER, pg_cron) that a
 	// read-only replica cannot execute (SQLSTATE 25006) and that Aurora global
 	// write-forwarding does not forward. SkipSchemaSetup lets a reader-region
@@ -282,8 +282,8 @@ func (p *PostgreSQLConnector) connectTask(ctx context.Context, cfg *common.Postg
 // ensureSchema runs applySchema at most once successfully per process
 // lifetime. The check-and-set is serialized under schemaMu so concurrent
 // connectTask callers can't race past the gate and both run the
-// (partially non-idempotent) migration in parallel. On failure the bool
-// stays false and the next connectTask will retry.
+// migrations in parallel. On failure the bool stays false and the next
+// connectTask will retry.
 func (p *PostgreSQLConnector) ensureSchema(ctx context.Context, conn *pgxpool.Pool, cfg *common.PostgreSQLConnectorConfig) error {
 	p.schemaMu.Lock()
 	defer p.schemaMu.Unlock()
@@ -297,83 +297,184 @@ func (p *PostgreSQLConnector) ensureSchema(ctx context.Context, conn *pgxpool.Po
 	return nil
 }
 
-// applySchema runs the idempotent one-time schema setup against the
-// supplied pool. It is intentionally split from connectTask so that
-// reconnects can rebuild only the pool without re-issuing DDL — see the
-// long comment on connectTask for why this matters in production.
-func (p *PostgreSQLConnector) applySchema(ctx context.Context, conn *pgxpool.Pool, cfg *common.PostgreSQLConnectorConfig) error {
-	// Create table if not exists with TTL column
-	if _, err := conn.Exec(ctx, fmt.Sprintf(`
-		CREATE TABLE IF NOT EXISTS %s (
-			partition_key TEXT,
-			range_key TEXT,
-			value BYTEA,
-			expires_at TIMESTAMP WITH TIME ZONE,
-			PRIMARY KEY (partition_key, range_key)
-		)
-	`, cfg.Table)); err != nil {
-		return err
-	}
+// postgresSchemaVersionsTable records the migration version of every
+// connector table, one row per table, so connectors sharing a database
+// track their tables independently.
+const postgresSchemaVersionsTable = "erpc_schema_versions"
 
-	// Migrate existing TEXT column to BYTEA if needed
-	var dataType string
-	err := conn.QueryRow(ctx, `
-		SELECT data_type 
-		FROM information_schema.columns 
-		WHERE table_name = $1 AND column_name = 'value'
-	`, cfg.Table).Scan(&dataType)
+// pgExecutor is the part of pgxpool.Pool and pgxpool.Conn the schema setup
+// uses, so it can run on the connection holding the migration lock.
+type pgExecutor interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
 
-	if err == nil && dataType == "text" {
-		p.logger.Info().Msg("migrating value column from TEXT to BYTEA")
+type postgresSchemaVersions struct {
+	db    pgExecutor
+	table string
+}
 
-		if _, err := conn.Exec(ctx, fmt.Sprintf(`
-			ALTER TABLE %s ADD COLUMN IF NOT EXISTS value_new BYTEA
-		`, cfg.Table)); err != nil {
-			return fmt.Errorf("failed to add temporary column: %w", err)
-		}
+func (s *postgresSchemaVersions) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := s.db.QueryRow(ctx, fmt.Sprintf(`SELECT version FROM %s WHERE table_name = $1`, postgresSchemaVersionsTable), s.table).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return version, err
+}
 
-		if _, err := conn.Exec(ctx, fmt.Sprintf(`
-			UPDATE %s SET value_new = value::bytea WHERE value IS NOT NULL
-		`, cfg.Table)); err != nil {
-			return fmt.Errorf("failed to migrate data: %w", err)
-		}
+func (s *postgresSchemaVersions) SetSchemaVersion(ctx context.Context, version int) error {
+	_, err := s.db.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO %s (table_name, version, updated_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (table_name) DO UPDATE SET version = EXCLUDED.version, updated_at = EXCLUDED.updated_at
+	`, postgresSchemaVersionsTable), s.table, version)
+	return err
+}
 
-		if _, err := conn.Exec(ctx, fmt.Sprintf(`
-			ALTER TABLE %s DROP COLUMN value;
-			ALTER TABLE %s RENAME COLUMN value_new TO value;
-		`, cfg.Table, cfg.Table)); err != nil {
-			return fmt.Errorf("failed to complete migration: %w", err)
-		}
+// applySchema brings the table up to date with postgresSchemaMigrations and
+// sets up the expired-items cleanup. The migrations run under a session
+// advisory lock keyed by the table name, so replicas starting together apply
+// them one at a time and the later ones find the version already recorded.
+// It is intentionally split from connectTask so that reconnects can rebuild
+// only the pool without re-issuing DDL — see the long comment on
+// connectTask for why this matters in production.
+func (p *PostgreSQLConnector) applySchema(ctx context.Context, pool *pgxpool.Pool, cfg *common.PostgreSQLConnectorConfig) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
 
-		p.logger.Info().Msg("successfully migrated value column to BYTEA")
+	lockKey := "erpc_schema:" + cfg.Table
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock(hashtext($1))`, lockKey); err != nil {
+		return fmt.Errorf("failed to acquire schema migration lock: %w", err)
 	}
+	defer func() {
+		// Use a fresh context: the lock must be released even when ctx
+		// expired mid-migration, or the session keeps it until the pool
+		// closes the connection.
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, `SELECT pg_advisory_unlock(hashtext($1))`, lockKey); err != nil {
+			p.logger.Warn().Err(err).Msg("failed to release schema migration lock")
+		}
+	}()
 
-	// Add expires_at column if it doesn't exist
 	if _, err := conn.Exec(ctx, fmt.Sprintf(`
-        ALTER TABLE %s
-        ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE
-    `, cfg.Table)); err != nil {
-		return fmt.Errorf("failed to add expires_at column: %w", err)
+		CREATE TABLE IF NOT EXISTS %s (
+			table_name TEXT PRIMARY KEY,
+			version INTEGER NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE
+		)
+	`, postgresSchemaVersionsTable)); err != nil {
+		return fmt.Errorf("failed to create schema versions table: %w", err)
 	}
 
-	// Create index for reverse lookups (range_key first to support queries that filter by range_key)
-	if _, err := conn.Exec(ctx, fmt.Sprintf(`
-		CREATE INDEX IF NOT EXISTS idx_reverse ON %s (range_key, partition_key)
-	`, cfg.Table)); err != nil {
-		return fmt.Errorf("failed to create reverse index: %w", err)
+	versions := &postgresSchemaVersions{db: conn, table: cfg.Table}
+	if err := runSchemaMigrations(ctx, p.logger, p.id, versions, p.schemaMigrations(conn, cfg.Table)); err != nil {
+		return err
 	}
 
-	// Create index for TTL cleanup
-	if _, err := conn.Exec(ctx, fmt.Sprintf(`
-		CREATE INDEX IF NOT EXISTS idx_expires_at ON %s (expires_at)
-		WHERE expires_at IS NOT NULL
-	`, cfg.Table)); err != nil {
-		return fmt.Errorf("failed to create TTL index: %w", err)
+	p.setupCronCleanup(ctx, conn)
+	return nil
+}
+
+// schemaMigrations lists the changes to the connector table in order.
+// Never edit or reorder a released migration; append a new one instead.
+func (p *PostgreSQLConnector) schemaMigrations(db pgExecutor, table string) []SchemaMigration {
+	exec := func(sql string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			_, err := db.Exec(ctx, fmt.Sprintf(sql, table))
+			return err
+		}
 	}
+	return []SchemaMigration{
+		{
+			Version:     1,
+			Description: "create table",
+			Apply: exec(`
+				CREATE TABLE IF NOT EXISTS %s (
+					partition_key TEXT,
+					range_key TEXT,
+					value BYTEA,
+					expires_at TIMESTAMP WITH TIME ZONE,
+					PRIMARY KEY (partition_key, range_key)
+				)
+			`),
+		},
+		{
+			Version:     2,
+			Description: "migrate value column from TEXT to BYTEA",
+			Apply: func(ctx context.Context) error {
+				var dataType string
+				err := db.QueryRow(ctx, `
+					SELECT data_type
+					FROM information_schema.columns
+					WHERE table_name = $1 AND column_name = 'value'
+				`, table).Scan(&dataType)
+				if err != nil || dataType != "text" {
+					return nil
+				}
 
-	// Try to set up pg_cron cleanup job if extension exists
+				p.logger.Info().Msg("migrating value column from TEXT to BYTEA")
+				if _, err := db.Exec(ctx, fmt.Sprintf(`
+					ALTER TABLE %s ADD COLUMN IF NOT EXISTS value_new BYTEA
+				`, table)); err != nil {
+					return fmt.Errorf("failed to add temporary column: %w", err)
+				}
+				if _, err := db.Exec(ctx, fmt.Sprintf(`
+					UPDATE %s SET value_new = value::bytea WHERE value IS NOT NULL
+				`, table)); err != nil {
+					return fmt.Errorf("failed to migrate data: %w", err)
+				}
+				if _, err := db.Exec(ctx, fmt.Sprintf(`
+					ALTER TABLE %s DROP COLUMN value;
+					ALTER TABLE %s RENAME COLUMN value_new TO value;
+				`, table, table)); err != nil {
+					return fmt.Errorf("failed to complete migration: %w", err)
+				}
+				p.logger.Info().Msg("successfully migrated value column to BYTEA")
+				return nil
+			},
+		},
+		{
+			Version:     3,
+			Description: "add expires_at column",
+			Apply: exec(`
+				ALTER TABLE %s
+				ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE
+			`),
+		},
+		{
+			// range_key first to support queries that filter by range_key
+			Version:     4,
+			Description: "create reverse index",
+			Apply:       exec(`CREATE INDEX IF NOT EXISTS idx_reverse ON %s (range_key, partition_key)`),
+		},
+		{
+			Version:     5,
+			Description: "create TTL index",
+			Apply: exec(`
+				CREATE INDEX IF NOT EXISTS idx_expires_at ON %s (expires_at)
+				WHERE expires_at IS NOT NULL
+			`),
+		},
+		{
+			Version:     6,
+			Description: "add finality_guard column",
+			Apply: exec(`
+				ALTER TABLE %s
+				ADD COLUMN IF NOT EXISTS finality_guard BIGINT NOT NULL DEFAULT 0
+			`),
+		},
+	}
+}
+
+// setupCronCleanup schedules the expired-items cleanup with pg_cron when the
+// extension exists, replacing the local cleanup routine.
+func (p *PostgreSQLConnector) setupCronCleanup(ctx context.Context, db pgExecutor) {
 	var hasPgCron bool
-	if err := conn.QueryRow(ctx, `
+	if err := db.QueryRow(ctx, `
         SELECT EXISTS (
             SELECT 1 FROM pg_extension WHERE extname = 'pg_cron'
         )
@@ -382,7 +483,7 @@ func (p *PostgreSQLConnector) applySchema(ctx context.Context, conn *pgxpool.Poo
 	}
 
 	if hasPgCron {
-		if _, err := conn.Exec(ctx, fmt.Sprintf(`
+		if _, err := db.Exec(ctx, fmt.Sprintf(`
             SELECT cron.schedule('*/5 * * * *', $$
                 DELETE FROM %s
                 WHERE expires_at IS NOT NULL AND expires_at <= NOW() AT TIME ZONE 'UTC'
@@ -392,20 +493,35 @@ func (p *PostgreSQLConnector) applySchema(ctx context.Context, conn *pgxpool.Poo
 		} else {
 			p.logger.Info().Msg("successfully configured pg_cron cleanup job")
 			// Don't start the local cleanup routine since we're using pg_cron.
-			// Safe to mutate here: initSchema is only ever called once
-			// (gated on schemaInitialized) and before connectTask spawns
+			// Safe to mutate here: applySchema is only ever called once
+			// (gated on schemaApplied) and before connectTask spawns
 			// the cleanup goroutine.
 			p.cleanupTicker = nil
 		}
 	}
-
-	return nil
 }
 
 func (p *PostgreSQLConnector) Id() string {
 	return p.id
 }
 
+func (p *PostgreSQLConnector) State() ConnectorState {
+	return initializerConnectorState(p.initializer)
+}
+
+func (p *PostgreSQLConnector) Ping(ctx context.Context) error {
+	if s := p.initializer.State(); s != util.StateReady {
+		return fmt.Errorf("postgres is not connected (state: %s), errors: %v", s.String(), p.initializer.Errors())
+	}
+	p.connMu.RLock()
+	conn := p.conn
+	p.connMu.RUnlock()
+	if conn == nil {
+		return fmt.Errorf("PostgreSQLConnector not connected yet")
+	}
+	return conn.Ping(ctx)
+}
+
 // acquirePool takes the connMu read lock and returns the live pgxpool
 // snapshot together with a release function that the caller MUST defer.
 // It centralises the not-ready check so every entry point (Get/Set/Lock/
@@ -465,7 +581,7 @@ func (p *PostgreSQLConnector) Set(ctx context.Context, partitionKey, rangeKey st
 		expiresAt = &t
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, p.setTimeout)
+	ctx, cancel := withOperationTimeout(ctx, p.setTimeout, PostgreSQLDriverName, "setTimeout")
 	defer cancel()
 
 	if expiresAt != nil {
@@ -492,6 +608,122 @@ func (p *PostgreSQLConnector) Set(ctx context.Context, partitionKey, rangeKey st
 	return err
 }
 
+// SetGuarded upserts the row with an ON CONFLICT update that only applies
+// when the stored row has no higher finality_guard, or has expired.
+func (p *PostgreSQLConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	ctx, span := common.StartSpan(ctx, "PostgreSQLConnector.SetGuarded")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+			attribute.Int64("guard", guard),
+		)
+	}
+
+	pool, release, err := p.acquirePool(span)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	p.logger.Debug().Int("length", len(value)).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int64("guard", guard).Msg("writing guarded row to postgres")
+
+	var expiresAt *time.Time
+	if ttl != nil && *ttl > 0 {
+		t := time.Now().UTC().Add(*ttl)
+		expiresAt = &t
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, p.setTimeout, PostgreSQLDriverName, "setTimeout")
+	defer cancel()
+
+	tag, err := pool.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO %[1]s (partition_key, range_key, value, expires_at, finality_guard)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (partition_key, range_key) DO UPDATE
+		SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at, finality_guard = EXCLUDED.finality_guard
+		WHERE %[1]s.finality_guard <= EXCLUDED.finality_guard OR %[1]s.expires_at <= NOW()
+	`, p.table), partitionKey, rangeKey, value, expiresAt, guard)
+	if err != nil {
+		p.handleConnectionFailure(err)
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// postgresBatchRows bounds the rows of one multi-row INSERT, keeping its
+// bind parameters well under PostgreSQL's limit of 65535.
+const postgresBatchRows = 1000
+
+// SetMany upserts the items with multi-row INSERTs of up to postgresBatchRows
+// rows each.
+func (p *PostgreSQLConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	ctx, span := common.StartSpan(ctx, "PostgreSQLConnector.SetMany")
+	defer span.End()
+	span.SetAttributes(attribute.Int("items", len(items)))
+
+	pool, release, err := p.acquirePool(span)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	items = uniqueKeyValuePairs(items)
+	if len(items) == 0 {
+		return nil
+	}
+	p.logger.Debug().Int("items", len(items)).Msg("writing batch to postgres")
+
+	var expiresAt *time.Time
+	if ttl != nil && *ttl > 0 {
+		t := time.Now().UTC().Add(*ttl)
+		expiresAt = &t
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, p.setTimeout, PostgreSQLDriverName, "setTimeout")
+	defer cancel()
+
+	for start := 0; start < len(items); start += postgresBatchRows {
+		end := min(start+postgresBatchRows, len(items))
+		var query strings.Builder
+		args := make([]interface{}, 0, (end-start)*4)
+		if expiresAt != nil {
+			fmt.Fprintf(&query, "INSERT INTO %s (partition_key, range_key, value, expires_at) VALUES ", p.table)
+		} else {
+			fmt.Fprintf(&query, "INSERT INTO %s (partition_key, range_key, value) VALUES ", p.table)
+		}
+		for i, item := range items[start:end] {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			n := len(args)
+			if expiresAt != nil {
+				fmt.Fprintf(&query, "($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4)
+				args = append(args, item.PartitionKey, item.RangeKey, item.Value, expiresAt)
+			} else {
+				fmt.Fprintf(&query, "($%d, $%d, $%d)", n+1, n+2, n+3)
+				args = append(args, item.PartitionKey, item.RangeKey, item.Value)
+			}
+		}
+		if expiresAt != nil {
+			query.WriteString(" ON CONFLICT (partition_key, range_key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at")
+		} else {
+			query.WriteString(" ON CONFLICT (partition_key, range_key) DO UPDATE SET value = EXCLUDED.value")
+		}
+
+		if _, err := pool.Exec(ctx, query.String(), args...); err != nil {
+			p.handleConnectionFailure(err)
+			common.SetTraceSpanError(span, err)
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *PostgreSQLConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
 	ctx, span := common.StartSpan(ctx, "PostgreSQLConnector.Get")
 	defer span.End()
@@ -513,7 +745,7 @@ func (p *PostgreSQLConnector) Get(ctx context.Context, index, partitionKey, rang
 	var query string
 	var args []interface{}
 
-	ctx, cancel := context.WithTimeout(ctx, p.getTimeout)
+	ctx, cancel := withOperationTimeout(ctx, p.getTimeout, PostgreSQLDriverName, "getTimeout")
 	defer cancel()
 
 	if strings.HasSuffix(partitionKey, "*") || strings.HasSuffix(rangeKey, "*") {
@@ -1175,7 +1407,7 @@ func (p *PostgreSQLConnector) Delete(ctx context.Context, partitionKey, rangeKey
 
 	p.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("deleting from postgres")
 
-	ctx, cancel := context.WithTimeout(ctx, p.setTimeout)
+	ctx, cancel := withOperationTimeout(ctx, p.setTimeout, PostgreSQLDriverName, "setTimeout")
 	defer cancel()
 
 	_, err = pool.Exec(ctx, fmt.Sprintf(`
@@ -1191,6 +1423,33 @@ func (p *PostgreSQLConnector) Delete(ctx context.Context, partitionKey, rangeKey
 	return err
 }
 
+func (p *PostgreSQLConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	ctx, span := common.StartSpan(ctx, "PostgreSQLConnector.DeleteByPrefix")
+	defer span.End()
+	span.SetAttributes(attribute.String("partition_key_prefix", partitionKeyPrefix))
+
+	pool, release, err := p.acquirePool(span)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	ctx, cancel := withOperationTimeout(ctx, p.setTimeout, PostgreSQLDriverName, "setTimeout")
+	defer cancel()
+
+	tag, err := pool.Exec(ctx, fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE partition_key LIKE $1 ESCAPE '\'
+	`, p.table), escapeLikePattern(partitionKeyPrefix)+"%")
+	if err != nil {
+		p.handleConnectionFailure(err)
+		common.SetTraceSpanError(span, err)
+		return 0, err
+	}
+	p.logger.Debug().Str("partitionKeyPrefix", partitionKeyPrefix).Int64("deleted", tag.RowsAffected()).Msg("deleted entries by prefix from postgres")
+	return int(tag.RowsAffected()), nil
+}
+
 func (p *PostgreSQLConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
 	ctx, span := common.StartSpan(ctx, "PostgreSQLConnector.List")
 	defer span.End()
@@ -1208,7 +1467,7 @@ func (p *PostgreSQLConnector) List(ctx context.Context, index string, limit int,
 	}
 	defer release()
 
-	ctx, cancel := context.WithTimeout(ctx, p.getTimeout)
+	ctx, cancel := withOperationTimeout(ctx, p.getTimeout, PostgreSQLDriverName, "getTimeout")
 	defer cancel()
 
 	// Parse pagination token - we'll use base64 encoded JSON with offset info
@@ -1298,3 +1557,96 @@ func (p *PostgreSQLConnector) List(ctx context.Context, index string, limit int,
 
 	return results, nextToken, nil
 }
+
+// Scan pages in (partition_key, range_key) order, resuming after the last
+// returned key, so entries written or deleted meanwhile never shift pages.
+func (p *PostgreSQLConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "PostgreSQLConnector.Scan")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key_prefix", partitionKeyPrefix),
+			attribute.String("range_key_prefix", rangeKeyPrefix),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	if err := validateScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	after, err := decodeScanCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if after == nil {
+		after = &scanCursor{}
+	}
+
+	pool, release, err := p.acquirePool(span)
+	if err != nil {
+		return nil, "", err
+	}
+	defer release()
+
+	ctx, cancel := withOperationTimeout(ctx, p.getTimeout, PostgreSQLDriverName, "getTimeout")
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		SELECT partition_key, range_key, value
+		FROM %s
+		WHERE partition_key LIKE $1 ESCAPE '\' AND range_key LIKE $2 ESCAPE '\'
+		  AND (partition_key, range_key) > ($3, $4)
+		  AND (expires_at IS NULL OR expires_at > NOW() AT TIME ZONE 'UTC')
+		ORDER BY partition_key, range_key
+		LIMIT $5
+	`, p.table)
+	args := []interface{}{
+		escapeLikePattern(partitionKeyPrefix) + "%",
+		escapeLikePattern(rangeKeyPrefix) + "%",
+		after.PartitionKey,
+		after.RangeKey,
+		limit,
+	}
+
+	p.logger.Debug().Str("query", query).Interface("args", args).Msg("scanning postgres")
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		p.handleConnectionFailure(err)
+		common.SetTraceSpanError(span, err)
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	results := make([]KeyValuePair, 0, limit)
+	for rows.Next() {
+		var kv KeyValuePair
+		if err := rows.Scan(&kv.PartitionKey, &kv.RangeKey, &kv.Value); err != nil {
+			return nil, "", fmt.Errorf("failed to scan row: %w", err)
+		}
+		results = append(results, kv)
+	}
+	if err := rows.Err(); err != nil {
+		p.handleConnectionFailure(err)
+		common.SetTraceSpanError(span, err)
+		return nil, "", err
+	}
+
+	// A full page may have more behind it; the next call returns an empty
+	// page with an empty cursor if not.
+	if len(results) < limit {
+		return results, "", nil
+	}
+	last := results[len(results)-1]
+	next, err := encodeScanCursor(last.PartitionKey, last.RangeKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return results, next, nil
+}
+
+// escapeLikePattern escapes the LIKE metacharacters of s (escape char '\').
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
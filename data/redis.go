@@ -25,6 +25,27 @@ const (
 )
 
 var _ Connector = &RedisConnector{}
+var _ GuardedSetter = &RedisConnector{}
+
+// redisSetGuardedScript writes ARGV[1] (a guarded value) under KEYS[1]
+// unless the stored value carries a guard above ARGV[2]. ARGV[3] is the TTL
+// in milliseconds, zero for none. It only reads the header of the stored
+// value, whatever its size.
+var redisSetGuardedScript = redis.NewScript(`
+local head = redis.call('GETRANGE', KEYS[1], 0, 27)
+if string.sub(head, 1, 8) == ARGV[4] then
+	local current = tonumber(string.sub(head, 9, 28))
+	if current and current > tonumber(ARGV[2]) then
+		return 0
+	end
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return 1
+`)
 
 // zerologAdapter adapts zerolog to work with go-redis internal logger
 type zerologAdapter struct {
@@ -54,7 +75,7 @@ func (z *zerologAdapter) Printf(ctx context.Context, format string, v ...interfa
 type RedisConnector struct {
 	id            string
 	logger        *zerolog.Logger
-	client        *redis.Client
+	client        redis.UniversalClient
 	initializer   *util.Initializer
 	cfg           *common.RedisConnectorConfig
 	redsync       *redsync.Redsync
@@ -110,6 +131,17 @@ func (r *RedisConnector) Id() string {
 	return r.id
 }
 
+func (r *RedisConnector) State() ConnectorState {
+	return initializerConnectorState(r.initializer)
+}
+
+func (r *RedisConnector) Ping(ctx context.Context) error {
+	if err := r.checkReady(); err != nil {
+		return err
+	}
+	return r.client.Ping(ctx).Err()
+}
+
 // connectTask is the function that tries to establish a Redis connection (and pings to verify).
 func (r *RedisConnector) connectTask(ctx context.Context) error {
 	// First, check if existing connection is still healthy
@@ -207,8 +239,8 @@ func (r *RedisConnector) connectTask(ctx context.Context) error {
 			Msg("Redis IAM auth enabled (ElastiCache)")
 	}
 
-	r.logger.Debug().Str("addr", options.Addr).Msg("attempting to connect to Redis")
-	client := redis.NewClient(options)
+	client, topology := newRedisClient(options, r.cfg)
+	r.logger.Debug().Str("topology", topology).Msg("attempting to connect to Redis")
 
 	// Test the connection with Ping.
 	ctx, cancel := context.WithTimeout(ctx, r.initTimeout)
@@ -230,6 +262,11 @@ func (r *RedisConnector) connectTask(ctx context.Context) error {
 		return err
 	}
 
+	if err := runSchemaMigrations(ctx, r.logger, r.id, &redisSchemaVersions{client: client}, redisSchemaMigrations()); err != nil {
+		_ = client.Close()
+		return err
+	}
+
 	if r.client != nil {
 		_ = r.client.Close()
 	}
@@ -245,10 +282,47 @@ func (r *RedisConnector) connectTask(ctx context.Context) error {
 	}
 	// Manager will detect the client change and reconnect internally
 
-	r.logger.Info().Str("addr", options.Addr).Msg("successfully connected to Redis") // Use options.Addr for logging consistency
+	r.logger.Info().Str("topology", topology).Msg("successfully connected to Redis")
 	return nil
 }
 
+// redisSchemaVersionKey holds the key layout version, outside the
+// "partitionKey:rangeKey" keys the connector stores values under.
+const redisSchemaVersionKey = "erpc:schema:version"
+
+// redisSchemaMigrations lists the changes to the key layout in order. Never
+// edit or reorder a released migration; append a new one instead.
+func redisSchemaMigrations() []SchemaMigration {
+	return []SchemaMigration{
+		{
+			// Values under "partitionKey:rangeKey" with native TTLs; nothing
+			// to change, the version marks where later layouts start from.
+			Version:     1,
+			Description: "baseline key layout",
+			Apply:       func(ctx context.Context) error { return nil },
+		},
+	}
+}
+
+type redisSchemaVersions struct {
+	client redis.UniversalClient
+}
+
+func (s *redisSchemaVersions) SchemaVersion(ctx context.Context) (int, error) {
+	raw, err := s.client.Get(ctx, redisSchemaVersionKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return parseSchemaVersion(raw)
+}
+
+func (s *redisSchemaVersions) SetSchemaVersion(ctx context.Context, version int) error {
+	return s.client.Set(ctx, redisSchemaVersionKey, strconv.Itoa(version), 0).Err()
+}
+
 // markConnectionAsLostIfNecessary sets the connection task's state to "failed" so that the Initializer triggers a retry.
 func (r *RedisConnector) markConnectionAsLostIfNecessary(err error) {
 	if r.initializer == nil {
@@ -329,7 +403,7 @@ func (r *RedisConnector) Set(ctx context.Context, partitionKey, rangeKey string,
 		r.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int("len", len(value)).Msg("writing value to Redis")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, r.setTimeout)
+	ctx, cancel := withOperationTimeout(ctx, r.setTimeout, RedisDriverName, "setTimeout")
 	defer cancel()
 
 	duration := time.Duration(0)
@@ -364,6 +438,120 @@ func (r *RedisConnector) Set(ctx context.Context, partitionKey, rangeKey string,
 	return nil
 }
 
+// SetMany writes the items and their reverse index entries in one pipeline,
+// so the whole batch costs a single round trip.
+func (r *RedisConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	ctx, span := common.StartSpan(ctx, "RedisConnector.SetMany")
+	defer span.End()
+	span.SetAttributes(attribute.Int("items", len(items)))
+
+	if err := r.checkReady(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	r.logger.Debug().Int("items", len(items)).Msg("writing batch of values to Redis")
+
+	ctx, cancel := withOperationTimeout(ctx, r.setTimeout, RedisDriverName, "setTimeout")
+	defer cancel()
+
+	duration := time.Duration(0)
+	if ttl != nil && *ttl > 0 {
+		duration = *ttl
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.StatusCmd, 0, len(items))
+	for _, item := range items {
+		cmds = append(cmds, pipe.Set(ctx, fmt.Sprintf("%s:%s", item.PartitionKey, item.RangeKey), item.Value, duration))
+		if strings.HasPrefix(item.PartitionKey, "evm:") && !strings.HasSuffix(item.PartitionKey, "*") {
+			parts := strings.SplitAfterN(item.PartitionKey, ":", 3)
+			if len(parts) >= 2 {
+				reverseKey := fmt.Sprintf("%s#%s#%s", redisReverseIndexPrefix, parts[0]+parts[1]+"*", item.RangeKey)
+				pipe.Set(ctx, reverseKey, item.PartitionKey, duration)
+			}
+		}
+	}
+	// Exec returns the first failed command; reverse index failures are
+	// best-effort like in Set, so only the value commands decide the result.
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.markConnectionAsLostIfNecessary(err)
+	}
+	var firstErr error
+	failed := 0
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	if firstErr != nil {
+		err := fmt.Errorf("%d of %d redis SETs failed: %w", failed, len(cmds), firstErr)
+		r.logger.Warn().Err(err).Msg("failed to SET batch in Redis")
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	return nil
+}
+
+// SetGuarded compares and writes the value in one server-side script, and
+// maintains the reverse index like Set.
+func (r *RedisConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	ctx, span := common.StartSpan(ctx, "RedisConnector.SetGuarded")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+			attribute.Int64("guard", guard),
+		)
+	}
+
+	if err := r.checkReady(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+
+	key := fmt.Sprintf("%s:%s", partitionKey, rangeKey)
+	r.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int("len", len(value)).Int64("guard", guard).Msg("writing guarded value to Redis")
+
+	ctx, cancel := withOperationTimeout(ctx, r.setTimeout, RedisDriverName, "setTimeout")
+	defer cancel()
+
+	duration := time.Duration(0)
+	if ttl != nil && *ttl > 0 {
+		duration = *ttl
+	}
+
+	stored, err := redisSetGuardedScript.Run(ctx, r.client, []string{key}, wrapGuardedValue(guard, value), guard, duration.Milliseconds(), string(guardedValuePrefix)).Int()
+	if err != nil {
+		r.logger.Warn().Err(err).Str("key", key).Msg("failed to run guarded SET in Redis")
+		r.markConnectionAsLostIfNecessary(err)
+		common.SetTraceSpanError(span, err)
+		return false, err
+	}
+	if stored == 0 {
+		return false, nil
+	}
+
+	if strings.HasPrefix(partitionKey, "evm:") && !strings.HasSuffix(partitionKey, "*") {
+		parts := strings.SplitAfterN(partitionKey, ":", 3)
+		if len(parts) >= 2 {
+			reverseKey := fmt.Sprintf("%s#%s#%s", redisReverseIndexPrefix, parts[0]+parts[1]+"*", rangeKey)
+			if err := r.client.Set(ctx, reverseKey, partitionKey, duration).Err(); err != nil {
+				r.logger.Warn().Err(err).Str("key", reverseKey).Msg("failed to SET reverse index in Redis")
+			}
+		}
+	}
+	return true, nil
+}
+
 // Get retrieves a value from Redis. If wildcard, retrieves the first matching key. Returns early if not ready.
 func (r *RedisConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
 	ctx, span := common.StartSpan(ctx, "RedisConnector.Get",
@@ -380,13 +568,16 @@ func (r *RedisConnector) Get(ctx context.Context, index, partitionKey, rangeKey
 		return nil, err
 	}
 
+	// One budget covers the reverse lookup, the TTL check and the final GET, so
+	// a wildcard read costs no more wall time than a direct one.
+	ctx, cancel := withOperationTimeout(ctx, r.getTimeout, RedisDriverName, "getTimeout")
+	defer cancel()
+
 	// If the caller specifies the special index "idx_reverse" and the partitionKey contains a wildcard
 	// we attempt to resolve the concrete partition key through the reverse index (to avoid SCAN).
 	if index == ConnectorReverseIndex && strings.HasSuffix(partitionKey, "*") {
 		revKey := fmt.Sprintf("%s#%s#%s", redisReverseIndexPrefix, partitionKey, rangeKey)
-		lookupCtx, lookupCancel := context.WithTimeout(ctx, r.getTimeout)
-		revPartitionKey, revErr := r.client.Get(lookupCtx, revKey).Result()
-		lookupCancel()
+		revPartitionKey, revErr := r.client.Get(ctx, revKey).Result()
 		if revErr != nil {
 			r.logger.Debug().Err(revErr).Str("key", revKey).Msg("failed to GET reverse index in Redis")
 			r.markConnectionAsLostIfNecessary(revErr)
@@ -400,9 +591,7 @@ func (r *RedisConnector) Get(ctx context.Context, index, partitionKey, rangeKey
 			// Verify the resolved key still exists and hasn't expired
 			// This handles the edge case where reverse index points to an expired key
 			resolvedKey := fmt.Sprintf("%s:%s", partitionKey, rangeKey)
-			ttlCtx, ttlCancel := context.WithTimeout(ctx, r.getTimeout)
-			ttl, ttlErr := r.client.TTL(ttlCtx, resolvedKey).Result()
-			ttlCancel()
+			ttl, ttlErr := r.client.TTL(ctx, resolvedKey).Result()
 
 			if ttlErr != nil {
 				r.logger.Debug().Err(ttlErr).Str("key", resolvedKey).Msg("failed to check TTL for resolved key")
@@ -425,9 +614,6 @@ func (r *RedisConnector) Get(ctx context.Context, index, partitionKey, rangeKey
 	// Construct the final key and continue with the regular retrieval path.
 	key := fmt.Sprintf("%s:%s", partitionKey, rangeKey)
 
-	ctx, cancel := context.WithTimeout(ctx, r.getTimeout)
-	defer cancel()
-
 	r.logger.Trace().Str("key", key).Msg("getting item from Redis")
 	value, err := r.client.Get(ctx, key).Bytes()
 	if err == redis.Nil {
@@ -452,6 +638,7 @@ func (r *RedisConnector) Get(ctx context.Context, index, partitionKey, rangeKey
 		)
 	}
 
+	value, _ = unwrapGuardedValue(value)
 	return value, nil
 }
 
@@ -624,6 +811,115 @@ func (r *RedisConnector) PublishCounterInt64(ctx context.Context, key string, va
 	return err
 }
 
+// Scan uses SCAN MATCH on the partition key prefix. Redis stores entries
+// under "partitionKey:rangeKey", so the split is recovered from the key: EVM
+// cache keys always have a three-segment partition key (evm:<chain>:<block>),
+// other keys split at the first ':' that leaves both prefixes matching.
+func (r *RedisConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "RedisConnector.Scan")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key_prefix", partitionKeyPrefix),
+			attribute.String("range_key_prefix", rangeKeyPrefix),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	if err := r.checkReady(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, "", err
+	}
+
+	if err := validateScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	ctx, cancel := withOperationTimeout(ctx, r.getTimeout, RedisDriverName, "getTimeout")
+	defer cancel()
+
+	keys, next, err := r.scanKeys(ctx, cursor, partitionKeyPrefix, escapeRedisGlob(partitionKeyPrefix)+"*", int64(limit))
+	if err != nil {
+		r.logger.Warn().Err(err).Msg("failed to SCAN in Redis")
+		r.markConnectionAsLostIfNecessary(err)
+		common.SetTraceSpanError(span, err)
+		return nil, "", err
+	}
+
+	type match struct{ key, partitionKey, rangeKey string }
+	matches := make([]match, 0, len(keys))
+	for _, key := range keys {
+		if pk, rk, ok := splitRedisKey(key, partitionKeyPrefix, rangeKeyPrefix); ok {
+			matches = append(matches, match{key, pk, rk})
+		}
+	}
+
+	results := make([]KeyValuePair, 0, len(matches))
+	if len(matches) > 0 {
+		pipe := r.client.Pipeline()
+		cmds := make([]*redis.StringCmd, len(matches))
+		for i, m := range matches {
+			cmds[i] = pipe.Get(ctx, m.key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			r.logger.Warn().Err(err).Msg("failed to execute pipeline in Redis Scan")
+			r.markConnectionAsLostIfNecessary(err)
+			common.SetTraceSpanError(span, err)
+			return nil, "", err
+		}
+		for i, cmd := range cmds {
+			value, err := cmd.Bytes()
+			if err != nil {
+				// Deleted between SCAN and GET, or not a plain value (locks, counters).
+				continue
+			}
+			results = append(results, KeyValuePair{
+				PartitionKey: matches[i].partitionKey,
+				RangeKey:     matches[i].rangeKey,
+				Value:        value,
+			})
+		}
+	}
+
+	return unwrapGuardedValues(results), next, nil
+}
+
+func splitRedisKey(key, partitionKeyPrefix, rangeKeyPrefix string) (string, string, bool) {
+	if strings.HasPrefix(key, redisReverseIndexPrefix+"#") {
+		return "", "", false
+	}
+	if strings.HasPrefix(key, "evm:") {
+		parts := strings.SplitN(key, ":", 4)
+		if len(parts) != 4 {
+			return "", "", false
+		}
+		pk, rk := strings.Join(parts[:3], ":"), parts[3]
+		return pk, rk, strings.HasPrefix(pk, partitionKeyPrefix) && strings.HasPrefix(rk, rangeKeyPrefix)
+	}
+	for i := len(partitionKeyPrefix); i < len(key); i++ {
+		if key[i] != ':' {
+			continue
+		}
+		if pk, rk := key[:i], key[i+1:]; pk != "" && rk != "" && strings.HasPrefix(pk, partitionKeyPrefix) && strings.HasPrefix(rk, rangeKeyPrefix) {
+			return pk, rk, true
+		}
+	}
+	return "", "", false
+}
+
+// escapeRedisGlob escapes the glob metacharacters of a SCAN MATCH pattern.
+func escapeRedisGlob(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
 var _ DistributedLock = &redisLock{}
 
 type redisLock struct {
@@ -644,7 +940,7 @@ func (l *redisLock) Unlock(ctx context.Context) error {
 	)
 	defer span.End()
 
-	ctx, cancel := context.WithTimeout(ctx, l.connector.setTimeout)
+	ctx, cancel := withOperationTimeout(ctx, l.connector.setTimeout, RedisDriverName, "setTimeout")
 	defer cancel()
 	ok, err := l.mutex.UnlockContext(ctx)
 	if err != nil {
@@ -660,6 +956,12 @@ func (l *redisLock) Unlock(ctx context.Context) error {
 	return nil
 }
 
+// DeleteByPrefix scans the matching keys and deletes them one by one, which
+// also removes their reverse index entries.
+func (r *RedisConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return deleteByScan(ctx, r.Scan, r.Delete, partitionKeyPrefix)
+}
+
 func (r *RedisConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
 	ctx, span := common.StartSpan(ctx, "RedisConnector.Delete")
 	defer span.End()
@@ -679,7 +981,7 @@ func (r *RedisConnector) Delete(ctx context.Context, partitionKey, rangeKey stri
 	key := fmt.Sprintf("%s:%s", partitionKey, rangeKey)
 	r.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("deleting from Redis")
 
-	ctx, cancel := context.WithTimeout(ctx, r.setTimeout)
+	ctx, cancel := withOperationTimeout(ctx, r.setTimeout, RedisDriverName, "setTimeout")
 	defer cancel()
 
 	// Delete main key
@@ -722,26 +1024,17 @@ func (r *RedisConnector) List(ctx context.Context, index string, limit int, pagi
 		return nil, "", err
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, r.getTimeout)
+	ctx, cancel := withOperationTimeout(ctx, r.getTimeout, RedisDriverName, "getTimeout")
 	defer cancel()
 
-	// Use SCAN for efficient pagination
-	cursor := uint64(0)
-	if paginationToken != "" {
-		var err error
-		cursor, err = strconv.ParseUint(paginationToken, 10, 64)
-		if err != nil {
-			return nil, "", fmt.Errorf("invalid pagination token: %w", err)
-		}
-	}
-
 	// Determine the pattern to scan for
-	pattern := "*"
+	prefix := ""
 	if index == ConnectorReverseIndex {
-		pattern = redisReverseIndexPrefix + "#*"
+		prefix = redisReverseIndexPrefix + "#"
 	}
 
-	keys, nextCursor, err := r.client.Scan(ctx, cursor, pattern, int64(limit)).Result()
+	// Use SCAN for efficient pagination
+	keys, nextToken, err := r.scanKeys(ctx, paginationToken, prefix, prefix+"*", int64(limit))
 	if err != nil {
 		r.logger.Warn().Err(err).Msg("failed to SCAN in Redis")
 		r.markConnectionAsLostIfNecessary(err)
@@ -803,10 +1096,5 @@ func (r *RedisConnector) List(ctx context.Context, index string, limit int, pagi
 		}
 	}
 
-	nextToken := ""
-	if nextCursor != 0 {
-		nextToken = strconv.FormatUint(nextCursor, 10)
-	}
-
-	return results, nextToken, nil
+	return unwrapGuardedValues(results), nextToken, nil
 }
@@ -717,6 +717,47 @@ func TestRedisReverseIndexLookup(t *testing.T) {
 	require.Equal(t, value, got)
 }
 
+func TestRedisConnector_SetMany(t *testing.T) {
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	defer m.Close()
+
+	logger := zerolog.New(io.Discard)
+	ctx := context.Background()
+
+	cfg := &common.RedisConnectorConfig{
+		Addr:        m.Addr(),
+		InitTimeout: common.Duration(2 * time.Second),
+		GetTimeout:  common.Duration(2 * time.Second),
+		SetTimeout:  common.Duration(2 * time.Second),
+	}
+	require.NoError(t, cfg.SetDefaults())
+	connector, err := NewRedisConnector(ctx, &logger, "test-set-many", cfg)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return connector.initializer.State() == util.StateReady
+	}, 3*time.Second, 100*time.Millisecond, "connector did not become ready")
+
+	ttl := time.Minute
+	items := []KeyValuePair{
+		{PartitionKey: "evm:1:100", RangeKey: "eth_getTransactionByHash:a", Value: []byte("tx-a")},
+		{PartitionKey: "evm:1:100", RangeKey: "eth_getTransactionByHash:b", Value: []byte("tx-b")},
+		{PartitionKey: "admin", RangeKey: "key", Value: []byte("v")},
+	}
+	require.NoError(t, connector.SetMany(ctx, items, &ttl))
+
+	for _, item := range items {
+		got, err := connector.Get(ctx, ConnectorMainIndex, item.PartitionKey, item.RangeKey, nil)
+		require.NoError(t, err)
+		require.Equal(t, item.Value, got)
+		require.Equal(t, ttl, m.TTL(item.PartitionKey+":"+item.RangeKey))
+	}
+	got, err := connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getTransactionByHash:b", nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("tx-b"), got)
+	require.False(t, m.Exists(fmt.Sprintf("%s#%s#%s", redisReverseIndexPrefix, "admin*", "key")))
+}
+
 func TestRedisConnector_ChainIsolation(t *testing.T) {
 	// Setup Redis connector
 	m, err := miniredis.Run()
@@ -959,3 +1000,96 @@ func TestRedisIAMAuthValidation(t *testing.T) {
 	})
 }
 
+func TestRedisConnector_Scan(t *testing.T) {
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	defer m.Close()
+
+	logger := zerolog.New(io.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &common.RedisConnectorConfig{
+		Addr:         m.Addr(),
+		ConnPoolSize: 5,
+		InitTimeout:  common.Duration(2 * time.Second),
+		GetTimeout:   common.Duration(2 * time.Second),
+		SetTimeout:   common.Duration(2 * time.Second),
+	}
+	require.NoError(t, cfg.SetDefaults())
+	connector, err := NewRedisConnector(ctx, &logger, "test-connector", cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, connector.Set(ctx, "evm:1:100", "eth_call:abc", []byte("a"), nil))
+	require.NoError(t, connector.Set(ctx, "evm:1:101", "eth_getBalance:def", []byte("b"), nil))
+	require.NoError(t, connector.Set(ctx, "evm:10:100", "eth_call:abc", []byte("c"), nil))
+	require.NoError(t, connector.Set(ctx, "idempotency:prj/evm:1", "key-1", []byte("d"), nil))
+
+	scanAll := func(pkPrefix, rkPrefix string) map[string]string {
+		found := map[string]string{}
+		cursor := ""
+		for {
+			page, next, err := connector.Scan(ctx, pkPrefix, rkPrefix, 1, cursor)
+			require.NoError(t, err)
+			for _, kv := range page {
+				found[kv.PartitionKey+"|"+kv.RangeKey] = string(kv.Value)
+			}
+			if next == "" {
+				return found
+			}
+			cursor = next
+		}
+	}
+
+	require.Equal(t, map[string]string{
+		"evm:1:100|eth_call:abc":       "a",
+		"evm:1:101|eth_getBalance:def": "b",
+	}, scanAll("evm:1:", ""))
+	require.Equal(t, map[string]string{
+		"evm:1:100|eth_call:abc":  "a",
+		"evm:10:100|eth_call:abc": "c",
+	}, scanAll("evm:", "eth_call"))
+	require.Equal(t, map[string]string{
+		"idempotency:prj/evm:1|key-1": "d",
+	}, scanAll("idempotency:prj/evm:1", ""))
+}
+
+func TestRedisConnector_DeleteByPrefix(t *testing.T) {
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	defer m.Close()
+
+	logger := zerolog.New(io.Discard)
+	ctx := context.Background()
+
+	cfg := &common.RedisConnectorConfig{
+		Addr:        m.Addr(),
+		InitTimeout: common.Duration(2 * time.Second),
+		GetTimeout:  common.Duration(2 * time.Second),
+		SetTimeout:  common.Duration(2 * time.Second),
+	}
+	require.NoError(t, cfg.SetDefaults())
+	connector, err := NewRedisConnector(ctx, &logger, "test-delete-by-prefix", cfg)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return connector.initializer.State() == util.StateReady
+	}, 3*time.Second, 100*time.Millisecond, "connector did not become ready")
+
+	require.NoError(t, connector.SetMany(ctx, []KeyValuePair{
+		{PartitionKey: "evm:1:100", RangeKey: "eth_getTransactionByHash:a", Value: []byte("tx-a")},
+		{PartitionKey: "evm:1:100", RangeKey: "eth_getBlockByNumber:b", Value: []byte("block")},
+		{PartitionKey: "evm:1:101", RangeKey: "eth_getTransactionByHash:c", Value: []byte("tx-c")},
+	}, nil))
+
+	deleted, err := connector.DeleteByPrefix(ctx, "evm:1:100")
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	_, err = connector.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:b", nil)
+	require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	_, err = connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getTransactionByHash:a", nil)
+	require.Error(t, err, "reverse index entry should be removed with the main key")
+	got, err := connector.Get(ctx, ConnectorMainIndex, "evm:1:101", "eth_getTransactionByHash:c", nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("tx-c"), got)
+}
@@ -0,0 +1,213 @@
+package data
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/erpc/erpc/common"
+	"github.com/redis/go-redis/v9"
+)
+
+// newRedisClient creates the client for the deployment cfg describes, from
+// the options parsed from its URI, along with a description for logs.
+func newRedisClient(options *redis.Options, cfg *common.RedisConnectorConfig) (redis.UniversalClient, string) {
+	switch {
+	case cfg.Cluster != nil:
+		opts := redisClusterOptions(options, cfg.Cluster)
+		return redis.NewClusterClient(opts), "cluster " + strings.Join(opts.Addrs, ",")
+	case cfg.Sentinel != nil:
+		opts := redisFailoverOptions(options, cfg.Sentinel)
+		return redis.NewFailoverClient(opts), fmt.Sprintf("sentinel master '%s' via %s", opts.MasterName, strings.Join(opts.SentinelAddrs, ","))
+	default:
+		return redis.NewClient(options), options.Addr
+	}
+}
+
+// redisClusterOptions dials every node of the cluster like the single server
+// of base would be dialed. The host of base is used as one more seed.
+func redisClusterOptions(base *redis.Options, cfg *common.RedisClusterConfig) *redis.ClusterOptions {
+	addrs := slices.Clone(cfg.Addrs)
+	if base.Addr != "" && !slices.Contains(addrs, base.Addr) {
+		addrs = append(addrs, base.Addr)
+	}
+	return &redis.ClusterOptions{
+		Addrs:                      addrs,
+		ClientName:                 base.ClientName,
+		MaxRedirects:               cfg.MaxRedirects,
+		Protocol:                   base.Protocol,
+		Username:                   base.Username,
+		Password:                   base.Password,
+		CredentialsProviderContext: base.CredentialsProviderContext,
+		MaxRetries:                 base.MaxRetries,
+		MinRetryBackoff:            base.MinRetryBackoff,
+		MaxRetryBackoff:            base.MaxRetryBackoff,
+		DialTimeout:                base.DialTimeout,
+		ReadTimeout:                base.ReadTimeout,
+		WriteTimeout:               base.WriteTimeout,
+		ContextTimeoutEnabled:      base.ContextTimeoutEnabled,
+		PoolFIFO:                   base.PoolFIFO,
+		PoolSize:                   base.PoolSize,
+		PoolTimeout:                base.PoolTimeout,
+		MinIdleConns:               base.MinIdleConns,
+		MaxIdleConns:               base.MaxIdleConns,
+		MaxActiveConns:             base.MaxActiveConns,
+		ConnMaxIdleTime:            base.ConnMaxIdleTime,
+		ConnMaxLifetime:            base.ConnMaxLifetime,
+		ConnMaxLifetimeJitter:      base.ConnMaxLifetimeJitter,
+		TLSConfig:                  redisNodeTLSConfig(base),
+	}
+}
+
+// redisFailoverOptions dials the master the sentinels report like the single
+// server of base would be dialed, and the sentinels with their own
+// credentials.
+func redisFailoverOptions(base *redis.Options, cfg *common.RedisSentinelConfig) *redis.FailoverOptions {
+	return &redis.FailoverOptions{
+		MasterName:            cfg.MasterName,
+		SentinelAddrs:         slices.Clone(cfg.Addrs),
+		SentinelUsername:      cfg.Username,
+		SentinelPassword:      cfg.Password,
+		ClientName:            base.ClientName,
+		Protocol:              base.Protocol,
+		Username:              base.Username,
+		Password:              base.Password,
+		DB:                    base.DB,
+		MaxRetries:            base.MaxRetries,
+		MinRetryBackoff:       base.MinRetryBackoff,
+		MaxRetryBackoff:       base.MaxRetryBackoff,
+		DialTimeout:           base.DialTimeout,
+		ReadTimeout:           base.ReadTimeout,
+		WriteTimeout:          base.WriteTimeout,
+		ContextTimeoutEnabled: base.ContextTimeoutEnabled,
+		PoolFIFO:              base.PoolFIFO,
+		PoolSize:              base.PoolSize,
+		PoolTimeout:           base.PoolTimeout,
+		MinIdleConns:          base.MinIdleConns,
+		MaxIdleConns:          base.MaxIdleConns,
+		MaxActiveConns:        base.MaxActiveConns,
+		ConnMaxIdleTime:       base.ConnMaxIdleTime,
+		ConnMaxLifetime:       base.ConnMaxLifetime,
+		ConnMaxLifetimeJitter: base.ConnMaxLifetimeJitter,
+		TLSConfig:             redisNodeTLSConfig(base),
+	}
+}
+
+// redisNodeTLSConfig returns the TLS config of base without the server name
+// that redis.ParseURL pins to the URI host, so each discovered node is
+// verified against its own address.
+func redisNodeTLSConfig(base *redis.Options) *tls.Config {
+	if base.TLSConfig == nil {
+		return nil
+	}
+	c := base.TLSConfig.Clone()
+	c.ServerName = ""
+	return c
+}
+
+// redisHashTag returns the hash tag of key, the part between the first '{'
+// and the next '}', which Redis Cluster hashes instead of the whole key when
+// it is not empty.
+func redisHashTag(key string) string {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return ""
+	}
+	end := strings.IndexByte(key[start+1:], '}')
+	if end <= 0 {
+		return ""
+	}
+	return key[start+1 : start+1+end]
+}
+
+// scanKeys runs one SCAN step for pattern, whose literal part is prefix. On
+// a cluster the keys are spread over every master, so the cursor also names
+// the master being scanned, as "<addr>@<cursor>", and moves on to the next
+// master in address order once one is exhausted. When prefix holds a whole
+// hash tag, all matching keys share its slot and only its master is scanned.
+func (r *RedisConnector) scanKeys(ctx context.Context, cursor, prefix, pattern string, count int64) ([]string, string, error) {
+	cc, ok := r.client.(*redis.ClusterClient)
+	if !ok {
+		from := uint64(0)
+		if cursor != "" {
+			var err error
+			if from, err = strconv.ParseUint(cursor, 10, 64); err != nil {
+				return nil, "", fmt.Errorf("invalid scan cursor: %w", err)
+			}
+		}
+		keys, next, err := r.client.Scan(ctx, from, pattern, count).Result()
+		if err != nil || next == 0 {
+			return keys, "", err
+		}
+		return keys, strconv.FormatUint(next, 10), nil
+	}
+
+	var masters []*redis.Client
+	if redisHashTag(prefix) != "" {
+		master, err := cc.MasterForKey(ctx, prefix)
+		if err != nil {
+			return nil, "", err
+		}
+		masters = []*redis.Client{master}
+	} else {
+		var err error
+		if masters, err = redisClusterMasters(ctx, cc); err != nil {
+			return nil, "", err
+		}
+	}
+
+	idx, from := 0, uint64(0)
+	if cursor != "" {
+		sep := strings.LastIndexByte(cursor, '@')
+		if sep < 0 {
+			return nil, "", fmt.Errorf("invalid scan cursor: %q", cursor)
+		}
+		var err error
+		if from, err = strconv.ParseUint(cursor[sep+1:], 10, 64); err != nil {
+			return nil, "", fmt.Errorf("invalid scan cursor: %w", err)
+		}
+		addr := cursor[:sep]
+		idx = slices.IndexFunc(masters, func(c *redis.Client) bool { return c.Options().Addr == addr })
+		if idx < 0 {
+			return nil, "", fmt.Errorf("invalid scan cursor: %s is no longer a cluster master", addr)
+		}
+	}
+	if len(masters) == 0 {
+		return nil, "", nil
+	}
+
+	keys, next, err := masters[idx].Scan(ctx, from, pattern, count).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	switch {
+	case next != 0:
+		return keys, fmt.Sprintf("%s@%d", masters[idx].Options().Addr, next), nil
+	case idx+1 < len(masters):
+		return keys, masters[idx+1].Options().Addr + "@0", nil
+	default:
+		return keys, "", nil
+	}
+}
+
+// redisClusterMasters lists the masters of the cluster in address order.
+func redisClusterMasters(ctx context.Context, cc *redis.ClusterClient) ([]*redis.Client, error) {
+	var mu sync.Mutex
+	var masters []*redis.Client
+	err := cc.ForEachMaster(ctx, func(_ context.Context, c *redis.Client) error {
+		mu.Lock()
+		masters = append(masters, c)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(masters, func(i, j int) bool { return masters[i].Options().Addr < masters[j].Options().Addr })
+	return masters, nil
+}
@@ -39,8 +39,30 @@ type SharedStateRegistry interface {
 	GetCounterInt64(key string, ignoreRollbackOf int64) CounterInt64SharedVariable
 	GetLockTtl() time.Duration
 	GetFallbackTimeout() time.Duration
+	// Lock acquires a cluster-wide lock on key (scoped to the cluster key) that
+	// expires after ttl unless released earlier.
+	Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error)
+	// GetRoutingConfig returns the config of routing state shared between
+	// replicas, or nil when it is off.
+	GetRoutingConfig() *common.SharedRoutingConfig
+	// GetInstanceId identifies this replica within the cluster.
+	GetInstanceId() string
+	// PutInstanceState stores this replica's value of key (scoped to the
+	// cluster key) for ttl, next to the other replicas' values of it.
+	PutInstanceState(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// GetInstanceStates returns every replica's unexpired value of key,
+	// including this one's, by instance id.
+	GetInstanceStates(ctx context.Context, key string) (map[string][]byte, error)
+	// GetLease returns the lease electing the holder of the named
+	// single-writer role, campaigning for it from the first call on. Nil when
+	// leases are off.
+	GetLease(name string) *Lease
 }
 
+// instanceStatesPageSize is the Scan page size used to read every replica's
+// value of a key; one page is enough for all but very large clusters.
+const instanceStatesPageSize = 1000
+
 type sharedStateRegistry struct {
 	appCtx          context.Context
 	logger          *zerolog.Logger
@@ -52,6 +74,9 @@ type sharedStateRegistry struct {
 	lockTtl         time.Duration
 	lockMaxWait     time.Duration
 	updateMaxWait   time.Duration
+	routing         *common.SharedRoutingConfig
+	leases          *common.SharedLeasesConfig
+	leaseMap        sync.Map // map[string]*Lease
 	initializer     *util.Initializer
 }
 
@@ -95,6 +120,8 @@ func NewSharedStateRegistry(
 		lockTtl:         lockTtl,
 		lockMaxWait:     lockMaxWait,
 		updateMaxWait:   updateMaxWait,
+		routing:         cfg.Routing,
+		leases:          cfg.Leases,
 		initializer:     util.NewInitializer(appCtx, &lg, nil),
 	}, nil
 }
@@ -272,3 +299,45 @@ func (r *sharedStateRegistry) GetLockTtl() time.Duration {
 func (r *sharedStateRegistry) GetFallbackTimeout() time.Duration {
 	return r.fallbackTimeout
 }
+
+func (r *sharedStateRegistry) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	return r.connector.Lock(ctx, fmt.Sprintf("%s/%s", r.clusterKey, key), ttl)
+}
+
+func (r *sharedStateRegistry) GetRoutingConfig() *common.SharedRoutingConfig {
+	return r.routing
+}
+
+func (r *sharedStateRegistry) GetInstanceId() string {
+	return r.instanceId
+}
+
+func (r *sharedStateRegistry) PutInstanceState(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, r.fallbackTimeout)
+	defer cancel()
+	return r.connector.Set(ctx, fmt.Sprintf("%s/%s", r.clusterKey, key), r.instanceId, value, &ttl)
+}
+
+func (r *sharedStateRegistry) GetInstanceStates(ctx context.Context, key string) (map[string][]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.fallbackTimeout)
+	defer cancel()
+	pk := fmt.Sprintf("%s/%s", r.clusterKey, key)
+	states := map[string][]byte{}
+	cursor := ""
+	for {
+		items, next, err := r.connector.Scan(ctx, pk, "", instanceStatesPageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range items {
+			// Scan matches by prefix, so skip keys that merely start with pk.
+			if it.PartitionKey == pk {
+				states[it.RangeKey] = it.Value
+			}
+		}
+		if next == "" {
+			return states, nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,294 @@
+package data
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ Connector = (*TieredConnector)(nil)
+var _ GuardedSetter = (*TieredConnector)(nil)
+
+// TieredConnector keeps recent entries in a hot connector and offloads
+// long-lived ones to a cold connector with transparent read-through.
+// Offloading is write-through: eligible entries are written to both tiers, and
+// the hot copy is capped at offloadAfter so it ages out on its own without a
+// background sweeper scanning the hot store.
+type TieredConnector struct {
+	id            string
+	logger        *zerolog.Logger
+	hot           Connector
+	cold          Connector
+	offloadAfter  time.Duration
+	promoteOnRead bool
+}
+
+func NewTieredConnector(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	id string,
+	cfg *common.TieredConnectorConfig,
+) (*TieredConnector, error) {
+	lg := logger.With().Str("connector", id).Logger()
+
+	hot, err := NewConnector(ctx, &lg, cfg.Hot)
+	if err != nil {
+		return nil, err
+	}
+	cold, err := NewConnector(ctx, &lg, cfg.Cold)
+	if err != nil {
+		return nil, err
+	}
+	return NewTieredConnectorFromConnectors(&lg, id, hot, cold, cfg.OffloadAfter.Duration(), cfg.PromoteOnRead == nil || *cfg.PromoteOnRead), nil
+}
+
+func NewTieredConnectorFromConnectors(
+	logger *zerolog.Logger,
+	id string,
+	hot Connector,
+	cold Connector,
+	offloadAfter time.Duration,
+	promoteOnRead bool,
+) *TieredConnector {
+	return &TieredConnector{
+		id:            id,
+		logger:        logger,
+		hot:           hot,
+		cold:          cold,
+		offloadAfter:  offloadAfter,
+		promoteOnRead: promoteOnRead,
+	}
+}
+
+func (t *TieredConnector) Id() string {
+	return t.id
+}
+
+func (t *TieredConnector) State() ConnectorState {
+	return worstConnectorState(t.hot.State(), t.cold.State())
+}
+
+func (t *TieredConnector) Ping(ctx context.Context) error {
+	return errors.Join(t.hot.Ping(ctx), t.cold.Ping(ctx))
+}
+
+// CacheLatestBlockTimestamp forwards to the hot tier when it is head-aware; the cold tier only
+// holds old data so it never defines the served head.
+func (t *TieredConnector) CacheLatestBlockTimestamp(networkId string) (int64, bool) {
+	if r, ok := t.hot.(CacheHeadReporter); ok {
+		return r.CacheLatestBlockTimestamp(networkId)
+	}
+	return 0, false
+}
+
+// isOffloadable reports whether an entry written with ttl outlives the hot tier retention.
+// A nil ttl means "never expires" which is the case for finalized data.
+func (t *TieredConnector) isOffloadable(ttl *time.Duration) bool {
+	if t.offloadAfter <= 0 {
+		return false
+	}
+	return ttl == nil || *ttl <= 0 || *ttl > t.offloadAfter
+}
+
+func (t *TieredConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error) {
+	ctx, span := common.StartDetailSpan(ctx, "TieredConnector.Get",
+		trace.WithAttributes(
+			attribute.String("connector_id", t.id),
+			attribute.String("index", index),
+		),
+	)
+	defer span.End()
+
+	value, hotErr := t.hot.Get(ctx, index, partitionKey, rangeKey, metadata)
+	if hotErr == nil {
+		span.SetAttributes(attribute.String("tier", "hot"))
+		return value, nil
+	}
+	if ctx.Err() != nil {
+		return nil, hotErr
+	}
+
+	value, coldErr := t.cold.Get(ctx, index, partitionKey, rangeKey, metadata)
+	if coldErr != nil {
+		// Prefer reporting a real hot-tier failure over a plain cold-tier miss
+		if !common.HasErrorCode(hotErr, common.ErrCodeRecordNotFound) && common.HasErrorCode(coldErr, common.ErrCodeRecordNotFound) {
+			return nil, hotErr
+		}
+		return nil, coldErr
+	}
+	span.SetAttributes(attribute.String("tier", "cold"))
+
+	// Reverse-index lookups do not carry the concrete key needed to write the entry back
+	if t.promoteOnRead && index != ConnectorReverseIndex && !strings.HasSuffix(partitionKey, "*") {
+		ttl := t.offloadAfter
+		if err := t.hot.Set(ctx, partitionKey, rangeKey, value, &ttl); err != nil {
+			t.logger.Debug().Err(err).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("failed to promote cold-tier entry into hot tier")
+		}
+	}
+
+	return value, nil
+}
+
+func (t *TieredConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	if !t.isOffloadable(ttl) {
+		return t.hot.Set(ctx, partitionKey, rangeKey, value, ttl)
+	}
+
+	ctx, span := common.StartDetailSpan(ctx, "TieredConnector.Set",
+		trace.WithAttributes(
+			attribute.String("connector_id", t.id),
+		),
+	)
+	defer span.End()
+
+	hotTtl := t.offloadAfter
+	hotErr := t.hot.Set(ctx, partitionKey, rangeKey, value, &hotTtl)
+	coldErr := t.cold.Set(ctx, partitionKey, rangeKey, value, ttl)
+	return errors.Join(hotErr, coldErr)
+}
+
+// SetGuarded writes each tier the entry goes to with its own guarded write,
+// as for Set. It reports the entry stored if either tier took it: the cold
+// tier outlives a hot copy it refused, and vice versa.
+func (t *TieredConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	if !t.isOffloadable(ttl) {
+		return SetGuarded(ctx, t.hot, partitionKey, rangeKey, value, guard, ttl)
+	}
+
+	ctx, span := common.StartDetailSpan(ctx, "TieredConnector.SetGuarded",
+		trace.WithAttributes(
+			attribute.String("connector_id", t.id),
+		),
+	)
+	defer span.End()
+
+	hotTtl := t.offloadAfter
+	hotStored, hotErr := SetGuarded(ctx, t.hot, partitionKey, rangeKey, value, guard, &hotTtl)
+	coldStored, coldErr := SetGuarded(ctx, t.cold, partitionKey, rangeKey, value, guard, ttl)
+	return hotStored || coldStored, errors.Join(hotErr, coldErr)
+}
+
+func (t *TieredConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	if !t.isOffloadable(ttl) {
+		return t.hot.SetMany(ctx, items, ttl)
+	}
+
+	ctx, span := common.StartDetailSpan(ctx, "TieredConnector.SetMany",
+		trace.WithAttributes(
+			attribute.String("connector_id", t.id),
+			attribute.Int("items", len(items)),
+		),
+	)
+	defer span.End()
+
+	hotTtl := t.offloadAfter
+	hotErr := t.hot.SetMany(ctx, items, &hotTtl)
+	coldErr := t.cold.SetMany(ctx, items, ttl)
+	return errors.Join(hotErr, coldErr)
+}
+
+func (t *TieredConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	return errors.Join(
+		t.hot.Delete(ctx, partitionKey, rangeKey),
+		t.cold.Delete(ctx, partitionKey, rangeKey),
+	)
+}
+
+// DeleteByPrefix deletes every matching entry from both tiers and counts each
+// key once, whether it was in one tier or both.
+func (t *TieredConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return deleteByScan(ctx, t.Scan, t.Delete, partitionKeyPrefix)
+}
+
+// List only covers the hot tier; cold tiers are typically too large to page through.
+func (t *TieredConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	return t.hot.List(ctx, index, limit, paginationToken)
+}
+
+// tieredScanCursor is the position of a Scan: it pages through the hot tier
+// first, then the cold tier, each with its own driver cursor.
+type tieredScanCursor struct {
+	Cold   bool   `json:"cold,omitempty"`
+	Cursor string `json:"c,omitempty"`
+}
+
+func encodeTieredScanCursor(c tieredScanCursor) (string, error) {
+	b, err := common.SonicCfg.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to create scan cursor: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func decodeTieredScanCursor(cursor string) (tieredScanCursor, error) {
+	var c tieredScanCursor
+	if cursor == "" {
+		return c, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, fmt.Errorf("invalid scan cursor: %w", err)
+	}
+	if err := common.SonicCfg.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("invalid scan cursor format: %w", err)
+	}
+	return c, nil
+}
+
+// Scan covers both tiers: entries with a ttl at or below offloadAfter only
+// ever live in the hot tier, and offloaded ones outlive their hot copy in the
+// cold tier. Cold entries that still have a hot copy were returned by the hot
+// pass and are skipped, so a key is returned once unless its hot copy expires
+// between the two passes.
+func (t *TieredConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	pos, err := decodeTieredScanCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !pos.Cold {
+		items, next, err := t.hot.Scan(ctx, partitionKeyPrefix, rangeKeyPrefix, limit, pos.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		next, err = encodeTieredScanCursor(tieredScanCursor{Cold: next == "", Cursor: next})
+		return items, next, err
+	}
+
+	items, next, err := t.cold.Scan(ctx, partitionKeyPrefix, rangeKeyPrefix, limit, pos.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	coldOnly := items[:0]
+	for _, item := range items {
+		if _, err := t.hot.Get(ctx, ConnectorMainIndex, item.PartitionKey, item.RangeKey, nil); err == nil {
+			continue
+		}
+		coldOnly = append(coldOnly, item)
+	}
+	if next == "" {
+		return coldOnly, "", nil
+	}
+	next, err = encodeTieredScanCursor(tieredScanCursor{Cold: true, Cursor: next})
+	return coldOnly, next, err
+}
+
+func (t *TieredConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	return t.hot.Lock(ctx, key, ttl)
+}
+
+func (t *TieredConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	return t.hot.WatchCounterInt64(ctx, key)
+}
+
+func (t *TieredConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return t.hot.PublishCounterInt64(ctx, key, value)
+}
@@ -0,0 +1,157 @@
+package data
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTieredConnector(t *testing.T, offloadAfter time.Duration, promoteOnRead bool) (*TieredConnector, *MemoryConnector, *MemoryConnector) {
+	logger := zerolog.New(io.Discard)
+	ctx := context.Background()
+	hot, err := NewMemoryConnector(ctx, &logger, "hot", &common.MemoryConnectorConfig{MaxItems: 1000, MaxTotalSize: "10MB"})
+	require.NoError(t, err)
+	cold, err := NewMemoryConnector(ctx, &logger, "cold", &common.MemoryConnectorConfig{MaxItems: 1000, MaxTotalSize: "10MB"})
+	require.NoError(t, err)
+	return NewTieredConnectorFromConnectors(&logger, "tiered", hot, cold, offloadAfter, promoteOnRead), hot, cold
+}
+
+func TestTieredConnector(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("short-lived entries stay in the hot tier only", func(t *testing.T) {
+		tc, _, cold := newTestTieredConnector(t, time.Hour, true)
+		ttl := time.Minute
+		require.NoError(t, tc.Set(ctx, "pk1", "rk1", []byte("v1"), &ttl))
+		time.Sleep(10 * time.Millisecond)
+
+		val, err := tc.Get(ctx, ConnectorMainIndex, "pk1", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), val)
+
+		_, err = cold.Get(ctx, ConnectorMainIndex, "pk1", "rk1", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("immutable entries are offloaded and read through after hot expiry", func(t *testing.T) {
+		tc, hot, _ := newTestTieredConnector(t, 100*time.Millisecond, false)
+		require.NoError(t, tc.Set(ctx, "pk2", "rk1", []byte("v2"), nil))
+		time.Sleep(150 * time.Millisecond)
+
+		_, err := hot.Get(ctx, ConnectorMainIndex, "pk2", "rk1", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), "hot copy should have aged out")
+
+		val, err := tc.Get(ctx, ConnectorMainIndex, "pk2", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2"), val)
+
+		_, err = hot.Get(ctx, ConnectorMainIndex, "pk2", "rk1", nil)
+		require.Error(t, err, "promotion is disabled")
+	})
+
+	t.Run("cold hits are promoted into the hot tier", func(t *testing.T) {
+		tc, hot, cold := newTestTieredConnector(t, time.Hour, true)
+		require.NoError(t, cold.Set(ctx, "pk3", "rk1", []byte("v3"), nil))
+		time.Sleep(10 * time.Millisecond)
+
+		val, err := tc.Get(ctx, ConnectorMainIndex, "pk3", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v3"), val)
+		time.Sleep(10 * time.Millisecond)
+
+		val, err = hot.Get(ctx, ConnectorMainIndex, "pk3", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v3"), val)
+	})
+
+	t.Run("miss in both tiers returns not found", func(t *testing.T) {
+		tc, _, _ := newTestTieredConnector(t, time.Hour, true)
+		_, err := tc.Get(ctx, ConnectorMainIndex, "missing", "rk1", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("delete removes from both tiers", func(t *testing.T) {
+		tc, hot, cold := newTestTieredConnector(t, time.Hour, true)
+		require.NoError(t, tc.Set(ctx, "pk4", "rk1", []byte("v4"), nil))
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, tc.Delete(ctx, "pk4", "rk1"))
+		time.Sleep(10 * time.Millisecond)
+
+		_, err := hot.Get(ctx, ConnectorMainIndex, "pk4", "rk1", nil)
+		require.Error(t, err)
+		_, err = cold.Get(ctx, ConnectorMainIndex, "pk4", "rk1", nil)
+		require.Error(t, err)
+	})
+}
+
+func TestTieredConnector_PrefixOperations(t *testing.T) {
+	ctx := context.Background()
+	// pk:1 is short-lived and hot only, pk:2 is offloaded to both tiers and
+	// pk:3 only has its cold copy left.
+	setup := func(t *testing.T) (*TieredConnector, *MemoryConnector, *MemoryConnector) {
+		tc, hot, cold := newTestTieredConnector(t, time.Hour, false)
+		ttl := time.Minute
+		require.NoError(t, tc.Set(ctx, "pk:1", "rk", []byte("v1"), &ttl))
+		require.NoError(t, tc.Set(ctx, "pk:2", "rk", []byte("v2"), nil))
+		require.NoError(t, cold.Set(ctx, "pk:3", "rk", []byte("v3"), nil))
+		hot.cache.Wait()
+		cold.cache.Wait()
+		return tc, hot, cold
+	}
+
+	t.Run("scan merges both tiers once per key", func(t *testing.T) {
+		tc, _, _ := setup(t)
+		var keys []string
+		cursor := ""
+		for {
+			page, next, err := tc.Scan(ctx, "pk:", "", 1, cursor)
+			require.NoError(t, err)
+			for _, item := range page {
+				keys = append(keys, item.PartitionKey)
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		require.ElementsMatch(t, []string{"pk:1", "pk:2", "pk:3"}, keys)
+	})
+
+	t.Run("delete by prefix clears both tiers", func(t *testing.T) {
+		tc, hot, cold := setup(t)
+		deleted, err := tc.DeleteByPrefix(ctx, "pk:")
+		require.NoError(t, err)
+		require.Equal(t, 3, deleted)
+		hot.cache.Wait()
+		cold.cache.Wait()
+		for _, pk := range []string{"pk:1", "pk:2", "pk:3"} {
+			_, err := tc.Get(ctx, ConnectorMainIndex, pk, "rk", nil)
+			require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), pk)
+		}
+	})
+}
+
+func TestTieredConnectorConfig_Defaults(t *testing.T) {
+	cfg := &common.ConnectorConfig{
+		Id: "archive",
+		Tiered: &common.TieredConnectorConfig{
+			Hot:  &common.ConnectorConfig{Memory: &common.MemoryConnectorConfig{}},
+			Cold: &common.ConnectorConfig{Memory: &common.MemoryConnectorConfig{}},
+		},
+	}
+	require.NoError(t, cfg.SetDefaults("cache"))
+	require.Equal(t, common.DriverTiered, cfg.Driver)
+	require.Equal(t, "archive-hot", cfg.Tiered.Hot.Id)
+	require.Equal(t, "archive-cold", cfg.Tiered.Cold.Id)
+	require.Equal(t, 24*time.Hour, cfg.Tiered.OffloadAfter.Duration())
+	require.NoError(t, cfg.Validate())
+
+	c, err := NewConnector(context.Background(), &zerolog.Logger{}, cfg)
+	require.NoError(t, err)
+	require.Equal(t, "archive", c.Id())
+}
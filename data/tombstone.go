@@ -0,0 +1,256 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+)
+
+// TombstoneValue marks a deleted entry until it is physically removed. It can
+// never be a cached value: cache values are JSON or zstd frames, neither of
+// which starts with a NUL byte.
+var TombstoneValue = []byte("\x00erpc:tombstone\x00")
+
+// IsTombstone reports whether value is a tombstone marker.
+func IsTombstone(value []byte) bool {
+	return bytes.Equal(value, TombstoneValue)
+}
+
+type tombstoneKey struct {
+	partitionKey, rangeKey string
+}
+
+// TombstoneConnector soft-deletes entries of the wrapped connector: Delete
+// overwrites the entry with TombstoneValue (TTL = grace period), reads treat a
+// tombstone as a miss, and a background loop physically deletes due
+// tombstones in batches. See common.TombstoneConfig.
+type TombstoneConnector struct {
+	wrapped       Connector
+	logger        *zerolog.Logger
+	gracePeriod   time.Duration
+	batchSize     int
+	flushInterval time.Duration
+
+	mu      sync.Mutex
+	pending map[tombstoneKey]time.Time // key -> when it may be physically deleted
+}
+
+var _ Connector = (*TombstoneConnector)(nil)
+var _ CacheHeadReporter = (*TombstoneConnector)(nil)
+var _ GuardedSetter = (*TombstoneConnector)(nil)
+
+func NewTombstoneConnector(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	wrapped Connector,
+	cfg *common.TombstoneConfig,
+) *TombstoneConnector {
+	lg := logger.With().Str("component", "tombstoneConnector").Str("connectorId", wrapped.Id()).Logger()
+	t := &TombstoneConnector{
+		wrapped:       wrapped,
+		logger:        &lg,
+		gracePeriod:   cfg.GracePeriod.Duration(),
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval.Duration(),
+		pending:       make(map[tombstoneKey]time.Time),
+	}
+	go t.flushLoop(ctx)
+	return t
+}
+
+func (t *TombstoneConnector) Id() string {
+	return t.wrapped.Id()
+}
+
+func (t *TombstoneConnector) State() ConnectorState {
+	return t.wrapped.State()
+}
+
+func (t *TombstoneConnector) Ping(ctx context.Context) error {
+	return t.wrapped.Ping(ctx)
+}
+
+func (t *TombstoneConnector) CacheLatestBlockTimestamp(networkId string) (int64, bool) {
+	if r, ok := t.wrapped.(CacheHeadReporter); ok {
+		return r.CacheLatestBlockTimestamp(networkId)
+	}
+	return 0, false
+}
+
+func (t *TombstoneConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error) {
+	value, err := t.wrapped.Get(ctx, index, partitionKey, rangeKey, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if IsTombstone(value) {
+		return nil, common.NewErrRecordNotFound(partitionKey, rangeKey, t.wrapped.Id())
+	}
+	return value, nil
+}
+
+// Set drops writes to keys this replica tombstoned within the grace period,
+// so a response fetched before the invalidation cannot overwrite it.
+func (t *TombstoneConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	if t.dropTombstoned(partitionKey, rangeKey) {
+		return nil
+	}
+	return t.wrapped.Set(ctx, partitionKey, rangeKey, value, ttl)
+}
+
+// SetGuarded drops writes to tombstoned keys, like Set.
+func (t *TombstoneConnector) SetGuarded(ctx context.Context, partitionKey, rangeKey string, value []byte, guard int64, ttl *time.Duration) (bool, error) {
+	if t.dropTombstoned(partitionKey, rangeKey) {
+		return false, nil
+	}
+	return SetGuarded(ctx, t.wrapped, partitionKey, rangeKey, value, guard, ttl)
+}
+
+// dropTombstoned tells whether a write to the key must be dropped because
+// the key is tombstoned, and forgets tombstones that are due.
+func (t *TombstoneConnector) dropTombstoned(partitionKey, rangeKey string) bool {
+	key := tombstoneKey{partitionKey, rangeKey}
+	t.mu.Lock()
+	due, tombstoned := t.pending[key]
+	if tombstoned && !util.Now().Before(due) {
+		// The new value supersedes the tombstone; it must not be purged.
+		delete(t.pending, key)
+		tombstoned = false
+	}
+	t.mu.Unlock()
+	if tombstoned {
+		telemetry.MetricConnectorTombstonesTotal.WithLabelValues(t.wrapped.Id(), "write_dropped").Inc()
+		t.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("dropping write to tombstoned key")
+	}
+	return tombstoned
+}
+
+// SetMany drops the items whose keys are tombstoned, like Set.
+func (t *TombstoneConnector) SetMany(ctx context.Context, items []KeyValuePair, ttl *time.Duration) error {
+	live := make([]KeyValuePair, 0, len(items))
+	now := util.Now()
+	t.mu.Lock()
+	for _, item := range items {
+		key := tombstoneKey{item.PartitionKey, item.RangeKey}
+		if due, tombstoned := t.pending[key]; tombstoned {
+			if now.Before(due) {
+				telemetry.MetricConnectorTombstonesTotal.WithLabelValues(t.wrapped.Id(), "write_dropped").Inc()
+				continue
+			}
+			delete(t.pending, key)
+		}
+		live = append(live, item)
+	}
+	t.mu.Unlock()
+	if len(live) == 0 {
+		return nil
+	}
+	return t.wrapped.SetMany(ctx, live, ttl)
+}
+
+// Delete writes a tombstone and schedules the physical delete. The tombstone
+// is visible to every replica as soon as the write is.
+func (t *TombstoneConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	grace := t.gracePeriod
+	if err := t.wrapped.Set(ctx, partitionKey, rangeKey, TombstoneValue, &grace); err != nil {
+		return err
+	}
+	telemetry.MetricConnectorTombstonesTotal.WithLabelValues(t.wrapped.Id(), "written").Inc()
+
+	t.mu.Lock()
+	t.pending[tombstoneKey{partitionKey, rangeKey}] = util.Now().Add(grace)
+	t.mu.Unlock()
+	return nil
+}
+
+// DeleteByPrefix tombstones every matching entry like Delete, so responses
+// in flight cannot bring them back during the grace period.
+func (t *TombstoneConnector) DeleteByPrefix(ctx context.Context, partitionKeyPrefix string) (int, error) {
+	return deleteByScan(ctx, t.Scan, t.Delete, partitionKeyPrefix)
+}
+
+func (t *TombstoneConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	items, next, err := t.wrapped.List(ctx, index, limit, paginationToken)
+	return withoutTombstones(items), next, err
+}
+
+func (t *TombstoneConnector) Scan(ctx context.Context, partitionKeyPrefix, rangeKeyPrefix string, limit int, cursor string) ([]KeyValuePair, string, error) {
+	items, next, err := t.wrapped.Scan(ctx, partitionKeyPrefix, rangeKeyPrefix, limit, cursor)
+	return withoutTombstones(items), next, err
+}
+
+func (t *TombstoneConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	return t.wrapped.Lock(ctx, key, ttl)
+}
+
+func (t *TombstoneConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan CounterInt64State, func(), error) {
+	return t.wrapped.WatchCounterInt64(ctx, key)
+}
+
+func (t *TombstoneConnector) PublishCounterInt64(ctx context.Context, key string, value CounterInt64State) error {
+	return t.wrapped.PublishCounterInt64(ctx, key, value)
+}
+
+func withoutTombstones(items []KeyValuePair) []KeyValuePair {
+	kept := items[:0]
+	for _, kv := range items {
+		if !IsTombstone(kv.Value) {
+			kept = append(kept, kv)
+		}
+	}
+	return kept
+}
+
+func (t *TombstoneConnector) flushLoop(ctx context.Context) {
+	ticker := util.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			t.flush(ctx)
+		}
+	}
+}
+
+// flush physically deletes up to batchSize due tombstones. Failed deletes are
+// retried on the next flush; the tombstone's TTL covers stores that expire it
+// first.
+func (t *TombstoneConnector) flush(ctx context.Context) {
+	now := util.Now()
+	var due []tombstoneKey
+	t.mu.Lock()
+	for k, at := range t.pending {
+		if len(due) >= t.batchSize {
+			break
+		}
+		if !now.Before(at) {
+			due = append(due, k)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, k := range due {
+		dctx, cancel := context.WithTimeout(ctx, t.flushInterval)
+		err := t.wrapped.Delete(dctx, k.partitionKey, k.rangeKey)
+		cancel()
+		if err != nil {
+			telemetry.MetricConnectorTombstonesTotal.WithLabelValues(t.wrapped.Id(), "purge_failed").Inc()
+			t.logger.Warn().Err(err).Str("partitionKey", k.partitionKey).Str("rangeKey", k.rangeKey).Msg("failed to purge tombstoned key, will retry")
+			continue
+		}
+		telemetry.MetricConnectorTombstonesTotal.WithLabelValues(t.wrapped.Id(), "purged").Inc()
+		t.mu.Lock()
+		// A newer Delete may have re-tombstoned the key meanwhile.
+		if at, ok := t.pending[k]; ok && !now.Before(at) {
+			delete(t.pending, k)
+		}
+		t.mu.Unlock()
+	}
+}
@@ -0,0 +1,132 @@
+package data
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTombstoneConnector(t *testing.T) {
+	logger := zerolog.New(io.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newConnector := func(t *testing.T, grace time.Duration) (*TombstoneConnector, *MemoryConnector) {
+		mem, err := NewMemoryConnector(ctx, &logger, "test", &common.MemoryConnectorConfig{
+			MaxItems: 1000, MaxTotalSize: "10MB",
+		})
+		require.NoError(t, err)
+		tc := NewTombstoneConnector(ctx, &logger, mem, &common.TombstoneConfig{
+			GracePeriod:   common.Duration(grace),
+			BatchSize:     10,
+			FlushInterval: common.Duration(time.Hour), // flushed manually below
+		})
+		return tc, mem
+	}
+
+	t.Run("DeletedEntryReadsAsMissImmediately", func(t *testing.T) {
+		tc, mem := newConnector(t, time.Minute)
+		require.NoError(t, tc.Set(ctx, "evm:1:100", "eth_call:h", []byte(`"0x1"`), nil))
+		mem.cache.Wait()
+
+		require.NoError(t, tc.Delete(ctx, "evm:1:100", "eth_call:h"))
+		mem.cache.Wait()
+
+		_, err := tc.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+		raw, err := mem.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.NoError(t, err)
+		require.True(t, IsTombstone(raw), "the entry is only marked until the grace period ends")
+
+		items, _, err := tc.Scan(ctx, "evm:1:", "", 10, "")
+		require.NoError(t, err)
+		require.Empty(t, items)
+	})
+
+	t.Run("WritesDuringGracePeriodAreDropped", func(t *testing.T) {
+		tc, mem := newConnector(t, time.Minute)
+		require.NoError(t, tc.Delete(ctx, "evm:1:100", "eth_call:h"))
+		require.NoError(t, tc.Set(ctx, "evm:1:100", "eth_call:h", []byte(`"stale"`), nil))
+		mem.cache.Wait()
+
+		_, err := tc.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("SetManyDropsOnlyTombstonedItems", func(t *testing.T) {
+		tc, mem := newConnector(t, time.Minute)
+		require.NoError(t, tc.Delete(ctx, "evm:1:100", "eth_call:h"))
+		require.NoError(t, tc.SetMany(ctx, []KeyValuePair{
+			{PartitionKey: "evm:1:100", RangeKey: "eth_call:h", Value: []byte(`"stale"`)},
+			{PartitionKey: "evm:1:100", RangeKey: "eth_call:other", Value: []byte(`"0x2"`)},
+		}, nil))
+		mem.cache.Wait()
+
+		_, err := tc.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+		got, err := tc.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:other", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte(`"0x2"`), got)
+	})
+
+	t.Run("FlushPurgesDueTombstones", func(t *testing.T) {
+		tc, mem := newConnector(t, 10*time.Millisecond)
+		require.NoError(t, tc.Delete(ctx, "evm:1:100", "eth_call:h"))
+		mem.cache.Wait()
+
+		time.Sleep(20 * time.Millisecond)
+		tc.flush(ctx)
+		mem.cache.Wait()
+
+		_, err := mem.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+		tc.mu.Lock()
+		require.Empty(t, tc.pending)
+		tc.mu.Unlock()
+
+		// After the grace period writes go through again.
+		require.NoError(t, tc.Set(ctx, "evm:1:100", "eth_call:h", []byte(`"0x2"`), nil))
+		mem.cache.Wait()
+		v, err := tc.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte(`"0x2"`), v)
+	})
+
+	t.Run("FlushLoopFollowsTheProcessClock", func(t *testing.T) {
+		clock := util.NewVirtualClock(time.Now())
+		defer util.SetClock(clock)()
+
+		mem, err := NewMemoryConnector(ctx, &logger, "test", &common.MemoryConnectorConfig{
+			MaxItems: 1000, MaxTotalSize: "10MB",
+		})
+		require.NoError(t, err)
+		tc := NewTombstoneConnector(ctx, &logger, mem, &common.TombstoneConfig{
+			GracePeriod:   common.Duration(time.Minute),
+			BatchSize:     10,
+			FlushInterval: common.Duration(time.Minute),
+		})
+		require.NoError(t, tc.Delete(ctx, "evm:1:100", "eth_call:h"))
+		clock.BlockUntil(1) // the flush loop's ticker
+
+		clock.Advance(59 * time.Second)
+		tc.mu.Lock()
+		require.Len(t, tc.pending, 1)
+		tc.mu.Unlock()
+
+		clock.Advance(time.Second)
+		require.Eventually(t, func() bool {
+			tc.mu.Lock()
+			defer tc.mu.Unlock()
+			return len(tc.pending) == 0
+		}, time.Second, time.Millisecond)
+		mem.cache.Wait()
+		_, err = mem.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_call:h", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+}
@@ -2,4 +2,7 @@ module.exports = {
 	"evm-json-rpc-cache": { title: "EVM JSON-RPC cache" },
 	drivers: { title: "Drivers" },
 	"shared-state": { title: "Shared state" },
+	idempotency: { title: "Idempotency keys" },
+	journal: { title: "Request journal" },
+	analytics: { title: "Request analytics" },
 };
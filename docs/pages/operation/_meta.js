@@ -26,6 +26,9 @@ module.exports = {
 	cordoning: {
 		title: "Cordoning",
 	},
+	alerting: {
+		title: "Alerting",
+	},
 	cli: {
 		title: "CLI & env vars",
 	}
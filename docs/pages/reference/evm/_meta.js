@@ -1,5 +1,12 @@
 module.exports = {
 	"getlogs-splitting": { title: "getLogs auto-splitting" },
+	"getlogs-completeness": { title: "getLogs completeness check" },
+	"receipts-prefetch": { title: "Receipts prefetch" },
+	"abi-registry": { title: "ABI registry & decoding" },
+	"address-activity": { title: "Address activity" },
+	"block-by-timestamp": { title: "Block by timestamp" },
+	"tag-emulation": { title: "Block tag emulation" },
 	"method-handlers": { title: "Method handlers" },
+	"client-quirks": { title: "Node client detection & quirks" },
 	"block-tracking": { title: "Block tracking & served tip" },
 };
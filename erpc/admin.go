@@ -10,6 +10,8 @@ import (
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/data"
 	"github.com/erpc/erpc/upstream"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
 )
 
 // API Key structure for management
@@ -56,6 +58,48 @@ func (e *ERPC) AdminHandleRequest(ctx context.Context, nq *common.NormalizedRequ
 		return e.handleCordonUpstream(ctx, nq, false)
 	case "erpc_listCordoned":
 		return e.handleListCordoned(ctx, nq)
+	case "erpc_drainUpstream":
+		return e.handleDrainUpstream(ctx, nq, true)
+	case "erpc_undrainUpstream":
+		return e.handleDrainUpstream(ctx, nq, false)
+	case "erpc_listDrained":
+		return e.handleListDrained(ctx, nq)
+	case "erpc_listCanaries":
+		return e.handleListCanaries(ctx, nq)
+	case "erpc_resetCanary":
+		return e.handleResetCanary(ctx, nq)
+	case "erpc_listAlerts":
+		return e.handleListAlerts(ctx, nq)
+	case "erpc_listRetired":
+		return e.handleListRetired(ctx, nq)
+	case "erpc_unretireUpstream":
+		return e.handleUnretireUpstream(ctx, nq)
+	case "erpc_startBackfill":
+		return e.handleStartBackfill(ctx, nq)
+	case "erpc_getBackfill":
+		return e.handleGetBackfill(ctx, nq)
+	case "erpc_listBackfills":
+		return e.handleListBackfills(ctx, nq)
+	case "erpc_cancelBackfill":
+		return e.handleCancelBackfill(ctx, nq)
+	case "erpc_listScheduledJobs":
+		return e.handleListScheduledJobs(ctx, nq)
+	case "erpc_setLogLevel":
+		return e.handleSetLogLevel(ctx, nq)
+	case "erpc_resetLogLevel":
+		return e.handleResetLogLevel(ctx, nq)
+	case "erpc_getLogLevels":
+		return e.handleGetLogLevels(ctx, nq)
+	case "erpc_listJournal":
+		return e.handleListJournal(ctx, nq)
+	case "erpc_setRateLimitBudget":
+		return e.handleSetRateLimitBudget(ctx, nq)
+	case "erpc_listRateLimitBudgets":
+		return e.handleListRateLimitBudgets(ctx, nq)
+	case "erpc_purgeCache":
+		return e.handlePurgeCache(ctx, nq)
+	case "erpc_getAnalytics":
+		return e.handleGetAnalytics(ctx, nq)
 
 	default:
 		return nil, common.NewErrEndpointUnsupported(
@@ -478,8 +522,10 @@ func (e *ERPC) handleTaxonomy(ctx context.Context, nq *common.NormalizedRequest)
 	}
 
 	type taxonomyUpstream struct {
-		Id     string `json:"id"`
-		Vendor string `json:"vendor"`
+		Id            string `json:"id"`
+		Vendor        string `json:"vendor"`
+		Client        string `json:"client,omitempty"`
+		ClientVersion string `json:"clientVersion,omitempty"`
 	}
 	type taxonomyProvider struct {
 		Id     string `json:"id"`
@@ -519,6 +565,10 @@ func (e *ERPC) handleTaxonomy(ctx context.Context, nq *common.NormalizedRequest)
 				if u.Vendor() != nil {
 					ups.Vendor = u.Vendor().Name()
 				}
+				if nc := u.NodeClient(); nc != nil {
+					ups.Client = nc.Name
+					ups.ClientVersion = nc.Version
+				}
 				ntw.Upstreams = append(ntw.Upstreams, &ups)
 			}
 			networks = append(networks, ntw)
@@ -727,3 +777,642 @@ func (e *ERPC) handleListCordoned(_ context.Context, nq *common.NormalizedReques
 		"cordoned":  rows,
 	})
 }
+
+type drainParams struct {
+	ProjectID string                   `json:"projectId"`
+	Upstream  string                   `json:"upstream"`
+	Mode      common.UpstreamDrainMode `json:"mode,omitempty"`
+	Reason    string                   `json:"reason,omitempty"`
+	// Duration auto-lifts the drain, e.g. "30m"; empty means until undrained.
+	Duration string `json:"duration,omitempty"`
+}
+
+// handleDrainUpstream puts an upstream into (or takes it out of) the
+// draining/maintenance state: no new requests, in-flight ones finish, and
+// failures are not recorded against its health while drained.
+func (e *ERPC) handleDrainUpstream(_ context.Context, nq *common.NormalizedRequest, drain bool) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	if len(jrr.Params) == 0 {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("drain admin: params is required"))
+	}
+	var p drainParams
+	raw, err := json.Marshal(jrr.Params[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("drain admin: invalid params: %w", err))
+	}
+	if p.ProjectID == "" || p.Upstream == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("drain admin: projectId and upstream are required"))
+	}
+	if p.Mode == "" {
+		p.Mode = common.UpstreamDrainModeDraining
+	}
+	if p.Mode != common.UpstreamDrainModeDraining && p.Mode != common.UpstreamDrainModeMaintenance {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("drain admin: mode must be 'draining' or 'maintenance'"))
+	}
+	var duration time.Duration
+	if p.Duration != "" {
+		if duration, err = time.ParseDuration(p.Duration); err != nil || duration < 0 {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("drain admin: invalid duration '%s'", p.Duration))
+		}
+	}
+	u, err := e.findUpstreamById(p.ProjectID, p.Upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	if !drain {
+		lifted := u.Undrain()
+		return makeSelectionResponse(nq, map[string]interface{}{
+			"projectId": p.ProjectID,
+			"upstream":  p.Upstream,
+			"undrained": lifted,
+			"state":     u.DrainState(),
+		})
+	}
+	reason := p.Reason
+	if reason == "" {
+		reason = "admin: manual " + string(p.Mode)
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"projectId": p.ProjectID,
+		"upstream":  p.Upstream,
+		"state":     u.Drain(p.Mode, reason, duration),
+	})
+}
+
+func (e *ERPC) handleListDrained(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	type listParams struct {
+		ProjectID string `json:"projectId"`
+	}
+	var lp listParams
+	if len(jrr.Params) > 0 {
+		raw, _ := json.Marshal(jrr.Params[0])
+		_ = json.Unmarshal(raw, &lp)
+	}
+	if lp.ProjectID == "" {
+		return nil, fmt.Errorf("drain admin: projectId is required")
+	}
+	prj, err := e.GetProject(lp.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if prj.upstreamsRegistry == nil {
+		return nil, fmt.Errorf("drain admin: project %s has no upstream registry", lp.ProjectID)
+	}
+	type drainedRow struct {
+		Upstream string `json:"upstream"`
+		*common.UpstreamDrainState
+	}
+	rows := []drainedRow{}
+	for _, u := range prj.upstreamsRegistry.GetAllUpstreams() {
+		if st := u.DrainState(); st != nil {
+			rows = append(rows, drainedRow{Upstream: u.Id(), UpstreamDrainState: st})
+		}
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"projectId": lp.ProjectID,
+		"drained":   rows,
+	})
+}
+
+func (e *ERPC) handleListCanaries(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	type listParams struct {
+		ProjectID string `json:"projectId"`
+	}
+	var lp listParams
+	if len(jrr.Params) > 0 {
+		raw, _ := json.Marshal(jrr.Params[0])
+		_ = json.Unmarshal(raw, &lp)
+	}
+	if lp.ProjectID == "" {
+		return nil, fmt.Errorf("canary admin: projectId is required")
+	}
+	prj, err := e.GetProject(lp.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if prj.upstreamsRegistry == nil {
+		return nil, fmt.Errorf("canary admin: project %s has no upstream registry", lp.ProjectID)
+	}
+	type canaryRow struct {
+		Upstream string    `json:"upstream"`
+		Weights  []float64 `json:"weights"`
+		*common.UpstreamCanaryState
+	}
+	rows := []canaryRow{}
+	for _, u := range prj.upstreamsRegistry.GetAllUpstreams() {
+		if st := u.CanaryState(); st != nil {
+			rows = append(rows, canaryRow{Upstream: u.Id(), Weights: u.Config().Canary.Weights, UpstreamCanaryState: st})
+		}
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"projectId": lp.ProjectID,
+		"canaries":  rows,
+	})
+}
+
+// handleResetCanary restarts a canary rollout from its first step, typically
+// after an automatic rollback once the upstream has been fixed.
+func (e *ERPC) handleResetCanary(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	type resetParams struct {
+		ProjectID string `json:"projectId"`
+		Upstream  string `json:"upstream"`
+	}
+	var p resetParams
+	if len(jrr.Params) > 0 {
+		raw, _ := json.Marshal(jrr.Params[0])
+		_ = json.Unmarshal(raw, &p)
+	}
+	if p.ProjectID == "" || p.Upstream == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("canary admin: projectId and upstream are required"))
+	}
+	u, err := e.findUpstreamById(p.ProjectID, p.Upstream)
+	if err != nil {
+		return nil, err
+	}
+	st := u.ResetCanary()
+	if st == nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("canary admin: upstream %s has no canary rollout enabled", p.Upstream))
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"projectId": p.ProjectID,
+		"upstream":  p.Upstream,
+		"state":     st,
+	})
+}
+
+func (e *ERPC) handleListRetired(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	type listParams struct {
+		ProjectID string `json:"projectId"`
+	}
+	var lp listParams
+	if len(jrr.Params) > 0 {
+		raw, _ := json.Marshal(jrr.Params[0])
+		_ = json.Unmarshal(raw, &lp)
+	}
+	if lp.ProjectID == "" {
+		return nil, fmt.Errorf("retirement admin: projectId is required")
+	}
+	prj, err := e.GetProject(lp.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if prj.upstreamsRegistry == nil {
+		return nil, fmt.Errorf("retirement admin: project %s has no upstream registry", lp.ProjectID)
+	}
+	type retiredRow struct {
+		Upstream string `json:"upstream"`
+		Endpoint string `json:"endpoint"`
+		*common.UpstreamRetirementState
+	}
+	rows := []retiredRow{}
+	for _, u := range prj.upstreamsRegistry.GetAllUpstreams() {
+		if st := u.RetirementState(); st != nil && st.Retired != nil {
+			rows = append(rows, retiredRow{Upstream: u.Id(), Endpoint: util.RedactEndpoint(u.Config().Endpoint), UpstreamRetirementState: st})
+		}
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"projectId": lp.ProjectID,
+		"retired":   rows,
+	})
+}
+
+// handleUnretireUpstream puts a retired upstream back into routing, typically
+// after its endpoint has been fixed; otherwise remove it from the config.
+func (e *ERPC) handleUnretireUpstream(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	type unretireParams struct {
+		ProjectID string `json:"projectId"`
+		Upstream  string `json:"upstream"`
+	}
+	var p unretireParams
+	if len(jrr.Params) > 0 {
+		raw, _ := json.Marshal(jrr.Params[0])
+		_ = json.Unmarshal(raw, &p)
+	}
+	if p.ProjectID == "" || p.Upstream == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("retirement admin: projectId and upstream are required"))
+	}
+	u, err := e.findUpstreamById(p.ProjectID, p.Upstream)
+	if err != nil {
+		return nil, err
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"projectId": p.ProjectID,
+		"upstream":  p.Upstream,
+		"unretired": u.Unretire(),
+		"state":     u.DrainState(),
+	})
+}
+
+// handleListAlerts returns the pending and firing alerts of this instance.
+func (e *ERPC) handleListAlerts(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	if e.alerter == nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("alerting admin: no alerting rules are configured"))
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"alerts": e.alerter.Alerts(),
+	})
+}
+
+const (
+	defaultLogTargetDuration = 15 * time.Minute
+	maxLogTargetDuration     = 24 * time.Hour
+)
+
+type logLevelParams struct {
+	Level     string               `json:"level"`
+	Component common.LogTargetKind `json:"component,omitempty"`
+	Id        string               `json:"id,omitempty"`
+	// Duration auto-reverts a component override, e.g. "30m"; defaults to 15m.
+	Duration string `json:"duration,omitempty"`
+}
+
+func parseLogLevelParams(nq *common.NormalizedRequest) (*logLevelParams, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	var p logLevelParams
+	if len(jrr.Params) > 0 {
+		raw, err := json.Marshal(jrr.Params[0])
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("log admin: invalid params: %w", err))
+		}
+	}
+	if p.Component != "" {
+		if !p.Component.Valid() {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("log admin: component must be one of 'upstream', 'connector', 'network' or 'connection'"))
+		}
+		if p.Id == "" {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("log admin: id is required when component is set"))
+		}
+	}
+	return &p, nil
+}
+
+// handleSetLogLevel changes the base log level, or — when a component is
+// given — lowers the level of that single upstream, connector, network or
+// connection until the override expires.
+func (e *ERPC) handleSetLogLevel(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	p, err := parseLogLevelParams(nq)
+	if err != nil {
+		return nil, err
+	}
+	level, err := zerolog.ParseLevel(p.Level)
+	if err != nil || p.Level == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("log admin: invalid level '%s'", p.Level))
+	}
+
+	if p.Component == "" {
+		common.LogLevels.SetLevel(level)
+		return makeSelectionResponse(nq, map[string]interface{}{
+			"level": level.String(),
+		})
+	}
+
+	if level > zerolog.InfoLevel {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("log admin: component level must be 'trace', 'debug' or 'info'"))
+	}
+	duration := defaultLogTargetDuration
+	if p.Duration != "" {
+		if duration, err = time.ParseDuration(p.Duration); err != nil || duration <= 0 || duration > maxLogTargetDuration {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("log admin: duration must be between 0 and %s, got '%s'", maxLogTargetDuration, p.Duration))
+		}
+	}
+	expiresAt := common.LogLevels.SetTargetLevel(common.LogTarget{Kind: p.Component, Id: p.Id}, level, duration)
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"component": p.Component,
+		"id":        p.Id,
+		"level":     level.String(),
+		"expiresAt": expiresAt,
+	})
+}
+
+// handleResetLogLevel removes a component override before it expires, or
+// every override when no component is given. The base level is untouched.
+func (e *ERPC) handleResetLogLevel(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	p, err := parseLogLevelParams(nq)
+	if err != nil {
+		return nil, err
+	}
+	reset := 0
+	if p.Component == "" {
+		reset = common.LogLevels.ResetTargets()
+	} else if common.LogLevels.ResetTarget(common.LogTarget{Kind: p.Component, Id: p.Id}) {
+		reset = 1
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"reset": reset,
+	})
+}
+
+func (e *ERPC) handleGetLogLevels(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"level":   common.LogLevels.Level().String(),
+		"targets": common.LogLevels.Targets(),
+	})
+}
+
+// handleListJournal pages through journaled write requests, e.g. to find
+// what happened to transactions abandoned by a crashed replica
+// (state "found", "rebroadcast", "lost" or "unknown").
+func (e *ERPC) handleListJournal(ctx context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	if e.journal == nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("journal admin: database.journal is not configured"))
+	}
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	type listParams struct {
+		ProjectID string `json:"projectId,omitempty"`
+		State     string `json:"state,omitempty"`
+		Limit     int    `json:"limit,omitempty"`
+		Cursor    string `json:"cursor,omitempty"`
+	}
+	var lp listParams
+	if len(jrr.Params) > 0 {
+		raw, _ := json.Marshal(jrr.Params[0])
+		_ = json.Unmarshal(raw, &lp)
+	}
+	if lp.Limit <= 0 {
+		lp.Limit = 50
+	}
+	entries, next, err := e.journal.List(ctx, lp.ProjectID, lp.State, lp.Limit, lp.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"entries":    entries,
+		"nextCursor": next,
+	})
+}
+
+type rateLimitBudgetParams struct {
+	ProjectID string `json:"projectId,omitempty"`
+	Upstream  string `json:"upstream,omitempty"`
+	Budget    string `json:"budget,omitempty"`
+	// Method selects the rule by its configured method pattern; all rules
+	// of the budget when empty.
+	Method   string  `json:"method,omitempty"`
+	MaxCount *uint32 `json:"maxCount"`
+}
+
+// resolveRateLimitBudget returns the budget id named directly, or the one
+// the upstream (when given) or else the project is configured with.
+func (e *ERPC) resolveRateLimitBudget(p *rateLimitBudgetParams) (string, error) {
+	if p.Budget != "" {
+		return p.Budget, nil
+	}
+	if p.ProjectID == "" {
+		return "", common.NewErrInvalidRequest(fmt.Errorf("rate limit admin: budget or projectId is required"))
+	}
+	if p.Upstream != "" {
+		u, err := e.findUpstreamById(p.ProjectID, p.Upstream)
+		if err != nil {
+			return "", err
+		}
+		if u.Config().RateLimitBudget == "" {
+			return "", common.NewErrInvalidRequest(fmt.Errorf("rate limit admin: upstream %q has no rateLimitBudget", p.Upstream))
+		}
+		return u.Config().RateLimitBudget, nil
+	}
+	prj, err := e.GetProject(p.ProjectID)
+	if err != nil {
+		return "", err
+	}
+	if prj.Config.RateLimitBudget == "" {
+		return "", common.NewErrInvalidRequest(fmt.Errorf("rate limit admin: project %q has no rateLimitBudget", p.ProjectID))
+	}
+	return prj.Config.RateLimitBudget, nil
+}
+
+// handleSetRateLimitBudget changes maxCount of a budget's rules at runtime,
+// e.g. to relieve pressure during an incident without a config deploy. The
+// budget is shared by everything that references it.
+func (e *ERPC) handleSetRateLimitBudget(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	if len(jrr.Params) == 0 {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("rate limit admin: params is required"))
+	}
+	var p rateLimitBudgetParams
+	raw, err := json.Marshal(jrr.Params[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("rate limit admin: invalid params: %w", err))
+	}
+	if p.MaxCount == nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("rate limit admin: maxCount is required"))
+	}
+	budgetId, err := e.resolveRateLimitBudget(&p)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := e.projectsRegistry.rateLimitersRegistry.SetBudgetMaxCount(budgetId, p.Method, *p.MaxCount)
+	if err != nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("rate limit admin: %w", err))
+	}
+	e.logger.Warn().Str("budget", budgetId).Str("method", p.Method).Uint32("maxCount", *p.MaxCount).Msg("rate limit budget changed via admin api")
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"budget": budgetId,
+		"rules":  changes,
+	})
+}
+
+// handleListRateLimitBudgets returns the current rules of every budget, or
+// of the one resolved from budget/projectId/upstream, including changes made
+// by erpc_setRateLimitBudget and the auto-tuner.
+func (e *ERPC) handleListRateLimitBudgets(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	var p rateLimitBudgetParams
+	if len(jrr.Params) > 0 {
+		raw, _ := json.Marshal(jrr.Params[0])
+		_ = json.Unmarshal(raw, &p)
+	}
+	registry := e.projectsRegistry.rateLimitersRegistry
+	var ids []string
+	if p.Budget != "" || p.ProjectID != "" {
+		id, err := e.resolveRateLimitBudget(&p)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	} else {
+		for _, b := range registry.GetBudgets() {
+			ids = append(ids, b.Id)
+		}
+	}
+	type budgetRow struct {
+		Id    string                       `json:"id"`
+		Rules []common.RateLimitRuleConfig `json:"rules"`
+	}
+	rows := []budgetRow{}
+	for _, id := range ids {
+		budget, err := registry.GetBudget(id)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, budgetRow{Id: id, Rules: budget.RuleConfigs()})
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"budgets": rows,
+	})
+}
+
+type purgeCacheParams struct {
+	ConnectorId        string `json:"connectorId"`
+	PartitionKey       string `json:"partitionKey"`
+	RangeKey           string `json:"rangeKey"`
+	PartitionKeyPrefix string `json:"partitionKeyPrefix"`
+}
+
+// handlePurgeCache deletes one cache entry (partitionKey + rangeKey) or every
+// entry whose partition key starts with partitionKeyPrefix, on the given
+// connector or on every connector used by the evm json-rpc cache.
+func (e *ERPC) handlePurgeCache(ctx context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	if len(jrr.Params) == 0 {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("cache admin: params is required"))
+	}
+	var p purgeCacheParams
+	raw, err := json.Marshal(jrr.Params[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("cache admin: invalid params: %w", err))
+	}
+	byKey := p.PartitionKey != "" || p.RangeKey != ""
+	if byKey == (p.PartitionKeyPrefix != "") {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("cache admin: set either partitionKey and rangeKey, or partitionKeyPrefix"))
+	}
+	if byKey && (p.PartitionKey == "" || p.RangeKey == "") {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("cache admin: partitionKey and rangeKey are both required"))
+	}
+
+	rows, err := e.purgeCache(ctx, &p)
+	if err != nil {
+		return nil, common.NewErrInvalidRequest(err)
+	}
+	e.logger.Warn().
+		Str("connector", p.ConnectorId).
+		Str("partitionKey", p.PartitionKey).
+		Str("rangeKey", p.RangeKey).
+		Str("partitionKeyPrefix", p.PartitionKeyPrefix).
+		Interface("results", rows).
+		Msg("cache entries purged via admin api")
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"connectors": rows,
+	})
+}
+
+type cachePurgeResult struct {
+	Connector string `json:"connector"`
+	Deleted   int    `json:"deleted"`
+	Error     string `json:"error,omitempty"`
+}
+
+// purgeCache deletes the entries selected by p on the matching connectors of
+// the evm json-rpc cache. Failures of one connector are reported in its row.
+func (e *ERPC) purgeCache(ctx context.Context, p *purgeCacheParams) ([]cachePurgeResult, error) {
+	cache := e.projectsRegistry.evmJsonRpcCache
+	if cache == nil {
+		return nil, fmt.Errorf("cache admin: database.evmJsonRpcCache is not configured")
+	}
+	var connectors []data.Connector
+	for _, c := range cache.Connectors() {
+		if p.ConnectorId == "" || c.Id() == p.ConnectorId {
+			connectors = append(connectors, c)
+		}
+	}
+	if len(connectors) == 0 {
+		return nil, fmt.Errorf("cache admin: connector %q is not used by the cache", p.ConnectorId)
+	}
+
+	rows := make([]cachePurgeResult, 0, len(connectors))
+	for _, c := range connectors {
+		row := cachePurgeResult{Connector: c.Id()}
+		var err error
+		if p.PartitionKeyPrefix == "" {
+			// Delete does not report whether the entry existed.
+			err = c.Delete(ctx, p.PartitionKey, p.RangeKey)
+			if err == nil {
+				row.Deleted = 1
+			}
+		} else {
+			row.Deleted, err = c.DeleteByPrefix(ctx, p.PartitionKeyPrefix)
+		}
+		if err != nil {
+			row.Error = err.Error()
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// handleGetAnalytics returns the request rollups of a project as a time
+// series, grouped by network and method unless groupBy says otherwise.
+func (e *ERPC) handleGetAnalytics(ctx context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	if e.analytics == nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("analytics admin: database.analytics is not configured"))
+	}
+	q, err := parseAnalyticsQuery(nq, []string{AnalyticsGroupByNetwork, AnalyticsGroupByMethod})
+	if err != nil {
+		return nil, err
+	}
+	if q.ProjectId == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("analytics admin: projectId is required"))
+	}
+	points, totals, err := e.analytics.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"projectId":  q.ProjectId,
+		"resolution": q.Resolution,
+		"from":       q.From.UTC(),
+		"to":         q.To.UTC(),
+		"points":     points,
+		"totals":     totals,
+	})
+}
@@ -0,0 +1,455 @@
+package erpc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rs/zerolog"
+)
+
+const (
+	AlertStatusPending  = "pending"
+	AlertStatusFiring   = "firing"
+	AlertStatusResolved = "resolved"
+
+	alertNotifyTimeout = 10 * time.Second
+)
+
+// Alert is one instance of a rule: the rule plus the labels of the series
+// that breached it. Its fingerprint is what deduplicates notifications.
+type Alert struct {
+	Fingerprint string               `json:"fingerprint"`
+	Rule        string               `json:"rule"`
+	Severity    common.AlertSeverity `json:"severity"`
+	Status      string               `json:"status"`
+	Labels      map[string]string    `json:"labels"`
+	Value       float64              `json:"value"`
+	Threshold   float64              `json:"threshold"`
+	Summary     string               `json:"summary"`
+	// StartsAt is when the condition was first seen, FiredAt when it had held
+	// for the rule's `for` duration and EndsAt when it cleared.
+	StartsAt time.Time `json:"startsAt"`
+	FiredAt  time.Time `json:"firedAt,omitempty"`
+	EndsAt   time.Time `json:"endsAt,omitempty"`
+
+	notifiedAt time.Time
+}
+
+// alertSample is one series breaching a rule at an evaluation.
+type alertSample struct {
+	labels  map[string]string
+	value   float64
+	summary string
+}
+
+type alertNotification struct {
+	rule  *common.AlertRuleConfig
+	alert Alert
+}
+
+// Alerter evaluates the `alerting.rules` against the metrics this instance
+// exports and notifies the configured sinks when alerts fire and resolve.
+type Alerter struct {
+	cfg      *common.AlertingConfig
+	logger   *zerolog.Logger
+	gatherer prometheus.Gatherer
+	sinks    map[string]alertSink
+
+	mu     sync.Mutex
+	alerts map[string]*Alert
+	// prev holds counter values from the previous evaluation, to turn
+	// counters into rates.
+	prev   map[string]float64
+	prevAt time.Time
+}
+
+func NewAlerter(logger *zerolog.Logger, cfg *common.AlertingConfig) *Alerter {
+	lg := logger.With().Str("component", "alerting").Logger()
+	sinks := make(map[string]alertSink, len(cfg.Sinks))
+	for _, sc := range cfg.Sinks {
+		if sc != nil {
+			sinks[sc.Id] = newAlertSink(sc)
+		}
+	}
+	return &Alerter{
+		cfg:    cfg,
+		logger: &lg,
+		sinks:  sinks,
+		alerts: make(map[string]*Alert),
+		prev:   make(map[string]float64),
+	}
+}
+
+func (a *Alerter) Start(ctx context.Context) {
+	a.logger.Info().Int("rules", len(a.cfg.Rules)).Int("sinks", len(a.cfg.Sinks)).Msg("starting alert rule evaluation")
+	go func() {
+		ticker := time.NewTicker(a.cfg.EvaluationInterval.Duration())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				a.evaluate(ctx, now)
+			}
+		}
+	}()
+}
+
+// Alerts returns the pending and firing alerts, oldest first.
+func (a *Alerter) Alerts() []Alert {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]Alert, 0, len(a.alerts))
+	for _, al := range a.alerts {
+		out = append(out, *al)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].StartsAt.Equal(out[j].StartsAt) {
+			return out[i].Fingerprint < out[j].Fingerprint
+		}
+		return out[i].StartsAt.Before(out[j].StartsAt)
+	})
+	return out
+}
+
+func (a *Alerter) evaluate(ctx context.Context, now time.Time) {
+	gatherer := a.gatherer
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+	mfs, err := gatherer.Gather()
+	if err != nil {
+		// Gather returns what it could collect alongside the error.
+		a.logger.Warn().Err(err).Msg("failed to gather some metrics for alert rules")
+	}
+	families := make(map[string]*dto.MetricFamily, len(mfs))
+	for _, mf := range mfs {
+		families[mf.GetName()] = mf
+	}
+
+	a.mu.Lock()
+	elapsed := now.Sub(a.prevAt).Seconds()
+	if a.prevAt.IsZero() {
+		elapsed = 0
+	}
+	var notifications []alertNotification
+	for _, rule := range a.cfg.Rules {
+		if rule == nil {
+			continue
+		}
+		samples := a.samplesLocked(rule, families, elapsed)
+		notifications = append(notifications, a.updateLocked(rule, samples, now)...)
+	}
+	a.prevAt = now
+	a.mu.Unlock()
+
+	a.notify(ctx, notifications)
+}
+
+// samplesLocked returns the series breaching the rule. Rates need a previous
+// evaluation, so counter-based rules have no samples on the first one.
+func (a *Alerter) samplesLocked(rule *common.AlertRuleConfig, families map[string]*dto.MetricFamily, elapsed float64) []alertSample {
+	var samples []alertSample
+	switch rule.Type {
+	case common.AlertRuleTypeUpstreamDown:
+		down := make(map[string]alertSample)
+		for _, m := range families["erpc_upstream_cordoned"].GetMetric() {
+			labels := alertMetricLabels(m)
+			if labels["category"] != "*" || m.GetGauge().GetValue() < 1 {
+				continue
+			}
+			s := alertSample{
+				labels:  map[string]string{"project": labels["project"], "network": labels["network"], "upstream": labels["upstream"]},
+				value:   1,
+				summary: fmt.Sprintf("upstream %s on %s is cordoned: %s", labels["upstream"], labels["network"], labels["reason"]),
+			}
+			down[alertFingerprint(rule.Id, s.labels)] = s
+		}
+		for _, m := range families["erpc_upstream_retired"].GetMetric() {
+			labels := alertMetricLabels(m)
+			if m.GetGauge().GetValue() < 1 {
+				continue
+			}
+			s := alertSample{
+				labels:  map[string]string{"project": labels["project"], "network": labels["network"], "upstream": labels["upstream"]},
+				value:   1,
+				summary: fmt.Sprintf("upstream %s on %s is retired after failing every request", labels["upstream"], labels["network"]),
+			}
+			down[alertFingerprint(rule.Id, s.labels)] = s
+		}
+		for _, s := range down {
+			samples = append(samples, s)
+		}
+	case common.AlertRuleTypeConnectorUnhealthy:
+		for _, m := range families["erpc_connector_state"].GetMetric() {
+			labels := alertMetricLabels(m)
+			if labels["state"] == "healthy" || m.GetGauge().GetValue() < 1 {
+				continue
+			}
+			samples = append(samples, alertSample{
+				labels:  map[string]string{"connector": labels["connector"]},
+				value:   1,
+				summary: fmt.Sprintf("connector %s is %s", labels["connector"], labels["state"]),
+			})
+		}
+	case common.AlertRuleTypeErrorRate:
+		type counts struct{ failed, succeeded float64 }
+		byNetwork := make(map[[2]string]*counts)
+		add := func(name string, failed bool) {
+			for _, m := range families[name].GetMetric() {
+				labels := alertMetricLabels(m)
+				key := [2]string{labels["project"], labels["network"]}
+				c := byNetwork[key]
+				if c == nil {
+					c = &counts{}
+					byNetwork[key] = c
+				}
+				if failed {
+					c.failed += m.GetCounter().GetValue()
+				} else {
+					c.succeeded += m.GetCounter().GetValue()
+				}
+			}
+		}
+		add("erpc_network_failed_request_total", true)
+		add("erpc_network_successful_request_total", false)
+		for key, c := range byNetwork {
+			failed := a.deltaLocked(rule.Id+"|failed|"+key[0]+"|"+key[1], c.failed, elapsed > 0)
+			succeeded := a.deltaLocked(rule.Id+"|succeeded|"+key[0]+"|"+key[1], c.succeeded, elapsed > 0)
+			total := failed + succeeded
+			if elapsed == 0 || total < float64(rule.MinRequests) {
+				continue
+			}
+			rate := failed / total
+			samples = append(samples, alertSample{
+				labels:  map[string]string{"project": key[0], "network": key[1]},
+				value:   rate,
+				summary: fmt.Sprintf("%.1f%% of %.0f requests to %s failed", rate*100, total, key[1]),
+			})
+		}
+	case common.AlertRuleTypeSubscriptionLag:
+		for _, m := range families["erpc_subscription_lag_blocks"].GetMetric() {
+			labels := alertMetricLabels(m)
+			samples = append(samples, alertSample{
+				labels:  labels,
+				value:   m.GetGauge().GetValue(),
+				summary: fmt.Sprintf("block stream subscribers of %s are %.0f blocks behind the head", labels["network"], m.GetGauge().GetValue()),
+			})
+		}
+	case common.AlertRuleTypeCacheHitRateBudget:
+		hitRates := make(map[string]float64)
+		for _, m := range families["erpc_cache_hit_rate"].GetMetric() {
+			hitRates[alertFingerprint("", alertMetricLabels(m))] = m.GetGauge().GetValue()
+		}
+		for _, m := range families["erpc_cache_hit_rate_budget_breached"].GetMetric() {
+			if m.GetGauge().GetValue() < 1 {
+				continue
+			}
+			labels := alertMetricLabels(m)
+			rate := hitRates[alertFingerprint("", labels)]
+			samples = append(samples, alertSample{
+				labels:  labels,
+				value:   rate,
+				summary: fmt.Sprintf("cache hit rate of %s on %s is %.1f%%, below its budget", labels["method"], labels["network"], rate*100),
+			})
+		}
+	case common.AlertRuleTypeMetric:
+		mf := families[rule.Metric]
+		for _, m := range mf.GetMetric() {
+			labels := alertMetricLabels(m)
+			var value float64
+			switch mf.GetType() {
+			case dto.MetricType_GAUGE:
+				value = m.GetGauge().GetValue()
+			case dto.MetricType_UNTYPED:
+				value = m.GetUntyped().GetValue()
+			case dto.MetricType_COUNTER:
+				delta := a.deltaLocked(rule.Id+"|"+alertFingerprint(rule.Metric, labels), m.GetCounter().GetValue(), elapsed > 0)
+				if elapsed == 0 {
+					continue
+				}
+				value = delta / elapsed
+			default:
+				continue
+			}
+			samples = append(samples, alertSample{
+				labels:  labels,
+				value:   value,
+				summary: fmt.Sprintf("%s is %g", rule.Metric, value),
+			})
+		}
+	}
+
+	breached := samples[:0]
+	for _, s := range samples {
+		if !alertLabelsMatch(rule.Labels, s.labels) {
+			continue
+		}
+		if rule.Type == common.AlertRuleTypeUpstreamDown || rule.Type == common.AlertRuleTypeConnectorUnhealthy || rule.Type == common.AlertRuleTypeCacheHitRateBudget {
+			breached = append(breached, s)
+			continue
+		}
+		if (rule.Below && s.value < rule.Threshold) || (!rule.Below && s.value > rule.Threshold) {
+			breached = append(breached, s)
+		}
+	}
+	return breached
+}
+
+// deltaLocked records a counter value and returns its increase since the
+// previous evaluation; a counter that went down was reset and counts from 0.
+func (a *Alerter) deltaLocked(key string, value float64, hasPrev bool) float64 {
+	prev, ok := a.prev[key]
+	a.prev[key] = value
+	if !hasPrev || !ok {
+		return 0
+	}
+	if value < prev {
+		return value
+	}
+	return value - prev
+}
+
+// updateLocked moves the rule's alerts through pending, firing and resolved,
+// and returns the notifications that transition calls for.
+func (a *Alerter) updateLocked(rule *common.AlertRuleConfig, samples []alertSample, now time.Time) []alertNotification {
+	var out []alertNotification
+	active := make(map[string]bool, len(samples))
+	for _, s := range samples {
+		fp := alertFingerprint(rule.Id, s.labels)
+		active[fp] = true
+		al := a.alerts[fp]
+		if al == nil {
+			al = &Alert{
+				Fingerprint: fp,
+				Rule:        rule.Id,
+				Severity:    rule.Severity,
+				Status:      AlertStatusPending,
+				Labels:      s.labels,
+				Threshold:   rule.Threshold,
+				StartsAt:    now,
+			}
+			a.alerts[fp] = al
+		}
+		al.Value = s.value
+		al.Summary = s.summary
+
+		switch {
+		case al.Status == AlertStatusPending && now.Sub(al.StartsAt) >= rule.For.Duration():
+			al.Status = AlertStatusFiring
+			al.FiredAt = now
+			al.notifiedAt = now
+			a.logger.Warn().Str("rule", rule.Id).Interface("labels", al.Labels).Str("summary", al.Summary).Msg("alert firing")
+			out = append(out, alertNotification{rule: rule, alert: *al})
+		case al.Status == AlertStatusFiring && a.cfg.RepeatInterval > 0 && now.Sub(al.notifiedAt) >= a.cfg.RepeatInterval.Duration():
+			al.notifiedAt = now
+			out = append(out, alertNotification{rule: rule, alert: *al})
+		}
+	}
+
+	firing := 0
+	for fp, al := range a.alerts {
+		if al.Rule != rule.Id {
+			continue
+		}
+		if active[fp] {
+			if al.Status == AlertStatusFiring {
+				firing++
+			}
+			continue
+		}
+		delete(a.alerts, fp)
+		if al.Status != AlertStatusFiring {
+			continue
+		}
+		al.Status = AlertStatusResolved
+		al.EndsAt = now
+		a.logger.Info().Str("rule", rule.Id).Interface("labels", al.Labels).Msg("alert resolved")
+		if a.cfg.SendResolved != nil && *a.cfg.SendResolved {
+			out = append(out, alertNotification{rule: rule, alert: *al})
+		}
+	}
+	telemetry.MetricAlertsFiring.WithLabelValues(rule.Id, string(rule.Severity)).Set(float64(firing))
+	return out
+}
+
+func (a *Alerter) notify(ctx context.Context, notifications []alertNotification) {
+	var wg sync.WaitGroup
+	for _, n := range notifications {
+		sinkIds := n.rule.Sinks
+		if len(sinkIds) == 0 {
+			for _, sc := range a.cfg.Sinks {
+				if sc != nil {
+					sinkIds = append(sinkIds, sc.Id)
+				}
+			}
+		}
+		for _, id := range sinkIds {
+			sink := a.sinks[id]
+			if sink == nil {
+				continue
+			}
+			wg.Add(1)
+			go func(id string, sink alertSink, al Alert) {
+				defer wg.Done()
+				nctx, cancel := context.WithTimeout(ctx, alertNotifyTimeout)
+				defer cancel()
+				outcome := "sent"
+				if err := sink.Notify(nctx, &al); err != nil {
+					outcome = "failed"
+					a.logger.Warn().Err(err).Str("rule", al.Rule).Str("sink", id).Msg("failed to deliver alert notification")
+				}
+				telemetry.MetricAlertNotificationsTotal.WithLabelValues(al.Rule, id, al.Status, outcome).Inc()
+			}(id, sink, n.alert)
+		}
+	}
+	wg.Wait()
+}
+
+func alertMetricLabels(m *dto.Metric) map[string]string {
+	labels := make(map[string]string, len(m.GetLabel()))
+	for _, lp := range m.GetLabel() {
+		labels[lp.GetName()] = lp.GetValue()
+	}
+	return labels
+}
+
+func alertLabelsMatch(patterns map[string]string, labels map[string]string) bool {
+	for name, pattern := range patterns {
+		ok, err := common.WildcardMatch(pattern, labels[name])
+		if err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// alertFingerprint identifies an alert by its rule and labels, e.g.
+// `upstream-down{network="evm:1",upstream="rpc1"}`.
+func alertFingerprint(rule string, labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString(rule)
+	sb.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, "%s=%q", name, labels[name])
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
@@ -0,0 +1,123 @@
+package erpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/erpc/erpc/common"
+)
+
+// alertSink delivers one alert notification, either firing or resolved.
+type alertSink interface {
+	Notify(ctx context.Context, alert *Alert) error
+}
+
+var webhookHttpClient = &http.Client{Timeout: alertNotifyTimeout}
+
+func newAlertSink(cfg *common.AlertSinkConfig) alertSink {
+	switch cfg.Type {
+	case common.AlertSinkTypeSlack:
+		return &slackAlertSink{cfg: cfg}
+	case common.AlertSinkTypePagerDuty:
+		return &pagerDutyAlertSink{cfg: cfg}
+	default:
+		return &webhookAlertSink{cfg: cfg}
+	}
+}
+
+// webhookAlertSink posts the alert as JSON.
+type webhookAlertSink struct {
+	cfg *common.AlertSinkConfig
+}
+
+func (s *webhookAlertSink) Notify(ctx context.Context, alert *Alert) error {
+	return postWebhookJSON(ctx, s.cfg.Url, s.cfg.Headers, alert)
+}
+
+// slackAlertSink posts a one-line message to a Slack incoming webhook.
+type slackAlertSink struct {
+	cfg *common.AlertSinkConfig
+}
+
+func (s *slackAlertSink) Notify(ctx context.Context, alert *Alert) error {
+	icon := ":rotating_light:"
+	if alert.Status == AlertStatusResolved {
+		icon = ":white_check_mark:"
+	}
+	text := fmt.Sprintf("%s [%s] %s (%s): %s", icon, strings.ToUpper(alert.Status), alert.Rule, alert.Severity, alert.Summary)
+	return postWebhookJSON(ctx, s.cfg.Url, s.cfg.Headers, map[string]interface{}{"text": text})
+}
+
+// pagerDutyAlertSink triggers and resolves incidents through the Events API
+// v2, using the alert fingerprint as dedup key so PagerDuty groups repeats.
+type pagerDutyAlertSink struct {
+	cfg *common.AlertSinkConfig
+}
+
+// pagerDutyMaxSummary and pagerDutyMaxDedupKey are the Events API v2 limits.
+const (
+	pagerDutyMaxSummary  = 1024
+	pagerDutyMaxDedupKey = 255
+)
+
+func (s *pagerDutyAlertSink) Notify(ctx context.Context, alert *Alert) error {
+	action := "trigger"
+	if alert.Status == AlertStatusResolved {
+		action = "resolve"
+	}
+	summary := fmt.Sprintf("[%s] %s", alert.Rule, alert.Summary)
+	if len(summary) > pagerDutyMaxSummary {
+		summary = summary[:pagerDutyMaxSummary]
+	}
+	dedupKey := alert.Fingerprint
+	if len(dedupKey) > pagerDutyMaxDedupKey {
+		dedupKey = dedupKey[:pagerDutyMaxDedupKey]
+	}
+	details := make(map[string]interface{}, len(alert.Labels)+2)
+	for k, v := range alert.Labels {
+		details[k] = v
+	}
+	details["value"] = alert.Value
+	details["threshold"] = alert.Threshold
+	return postWebhookJSON(ctx, s.cfg.Url, s.cfg.Headers, map[string]interface{}{
+		"routing_key":  s.cfg.RoutingKey,
+		"event_action": action,
+		"dedup_key":    dedupKey,
+		"payload": map[string]interface{}{
+			"summary":        summary,
+			"source":         "erpc",
+			"severity":       string(alert.Severity),
+			"timestamp":      alert.StartsAt,
+			"custom_details": details,
+		},
+	})
+}
+
+// postWebhookJSON posts payload as JSON to url. Every outbound notification
+// goes through it: alert sinks and scheduled health reports.
+func postWebhookJSON(ctx context.Context, url string, headers map[string]string, payload interface{}) error {
+	body, err := common.SonicCfg.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := webhookHttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package erpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type alertTestSink struct {
+	mu       sync.Mutex
+	payloads []map[string]interface{}
+	srv      *httptest.Server
+}
+
+func newAlertTestSink(t *testing.T) *alertTestSink {
+	s := &alertTestSink{}
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.mu.Lock()
+		s.payloads = append(s.payloads, body)
+		s.mu.Unlock()
+	}))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+func (s *alertTestSink) received() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]interface{}{}, s.payloads...)
+}
+
+func newTestAlerter(t *testing.T, reg *prometheus.Registry, cfg *common.AlertingConfig) *Alerter {
+	t.Helper()
+	cfg.SetDefaults()
+	require.NoError(t, cfg.Validate())
+	a := NewAlerter(&log.Logger, cfg)
+	a.gatherer = reg
+	return a
+}
+
+func TestAlerter_UpstreamDown(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cordoned := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "erpc_upstream_cordoned"}, []string{"project", "vendor", "network", "upstream", "category", "reason"})
+	reg.MustRegister(cordoned)
+	sink := newAlertTestSink(t)
+	a := newTestAlerter(t, reg, &common.AlertingConfig{
+		RepeatInterval: common.Duration(time.Hour),
+		Sinks:          []*common.AlertSinkConfig{{Id: "hook", Type: common.AlertSinkTypeWebhook, Url: sink.srv.URL}},
+		Rules:          []*common.AlertRuleConfig{{Id: "down", Type: common.AlertRuleTypeUpstreamDown, For: common.Duration(time.Minute)}},
+	})
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	cordoned.WithLabelValues("main", "n/a", "evm:1", "rpc1", "eth_call", "errors").Set(1)
+	a.evaluate(ctx, now)
+	assert.Empty(t, a.Alerts(), "a per-method cordon does not mean the upstream is down")
+
+	cordoned.WithLabelValues("main", "n/a", "evm:1", "rpc1", "*", "high error rate").Set(1)
+	a.evaluate(ctx, now)
+	alerts := a.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, AlertStatusPending, alerts[0].Status)
+	assert.Empty(t, sink.received())
+
+	a.evaluate(ctx, now.Add(time.Minute))
+	require.Len(t, sink.received(), 1)
+	first := sink.received()[0]
+	assert.Equal(t, AlertStatusFiring, first["status"])
+	assert.Equal(t, map[string]interface{}{"project": "main", "network": "evm:1", "upstream": "rpc1"}, first["labels"])
+	assert.Contains(t, first["summary"], "high error rate")
+
+	a.evaluate(ctx, now.Add(30*time.Minute))
+	assert.Len(t, sink.received(), 1, "a firing alert is not re-sent before repeatInterval")
+	a.evaluate(ctx, now.Add(61*time.Minute))
+	assert.Len(t, sink.received(), 2)
+
+	cordoned.WithLabelValues("main", "n/a", "evm:1", "rpc1", "*", "high error rate").Set(0)
+	a.evaluate(ctx, now.Add(62*time.Minute))
+	require.Len(t, sink.received(), 3)
+	assert.Equal(t, AlertStatusResolved, sink.received()[2]["status"])
+	assert.Empty(t, a.Alerts())
+}
+
+func TestAlerter_ErrorRate(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	failed := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "erpc_network_failed_request_total"}, []string{"project", "network", "category"})
+	succeeded := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "erpc_network_successful_request_total"}, []string{"project", "network", "category"})
+	reg.MustRegister(failed, succeeded)
+	sink := newAlertTestSink(t)
+	a := newTestAlerter(t, reg, &common.AlertingConfig{
+		Sinks: []*common.AlertSinkConfig{{Id: "hook", Type: common.AlertSinkTypeWebhook, Url: sink.srv.URL}},
+		Rules: []*common.AlertRuleConfig{{
+			Id:          "errors",
+			Type:        common.AlertRuleTypeErrorRate,
+			Threshold:   0.5,
+			MinRequests: 10,
+			For:         common.Duration(time.Nanosecond),
+			Labels:      map[string]string{"network": "evm:*"},
+		}},
+	})
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	failed.WithLabelValues("main", "evm:1", "eth_call").Add(1000)
+	succeeded.WithLabelValues("main", "evm:1", "eth_call").Add(10)
+	a.evaluate(ctx, now)
+	assert.Empty(t, a.Alerts(), "rates need a previous evaluation, so past failures are ignored")
+
+	failed.WithLabelValues("main", "evm:1", "eth_call").Add(3)
+	succeeded.WithLabelValues("main", "evm:1", "eth_getLogs").Add(2)
+	a.evaluate(ctx, now.Add(time.Minute))
+	assert.Empty(t, a.Alerts(), "below minRequests")
+
+	for i := 2; i <= 3; i++ {
+		failed.WithLabelValues("main", "evm:1", "eth_call").Add(8)
+		succeeded.WithLabelValues("main", "evm:1", "eth_call").Add(2)
+		failed.WithLabelValues("main", "other:1", "eth_call").Add(100)
+		a.evaluate(ctx, now.Add(time.Duration(i)*time.Minute))
+	}
+	require.Len(t, a.Alerts(), 1, "networks outside the label filter are ignored")
+
+	a.evaluate(ctx, now.Add(4*time.Minute))
+	assert.Empty(t, a.Alerts(), "the alert resolves once no new requests fail")
+	received := sink.received()
+	require.Len(t, received, 2)
+	assert.Equal(t, AlertStatusFiring, received[0]["status"])
+	assert.InDelta(t, 0.8, received[0]["value"], 0.001)
+	assert.Equal(t, "evm:1", received[0]["labels"].(map[string]interface{})["network"])
+	assert.Equal(t, AlertStatusResolved, received[1]["status"])
+}
+
+func TestAlerter_MetricRule(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	age := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "erpc_network_served_tip_advance_age_seconds"}, []string{"project", "network"})
+	reg.MustRegister(age)
+	sink := newAlertTestSink(t)
+	a := newTestAlerter(t, reg, &common.AlertingConfig{
+		SendResolved: new(bool),
+		Sinks:        []*common.AlertSinkConfig{{Id: "hook", Type: common.AlertSinkTypeWebhook, Url: sink.srv.URL}},
+		Rules: []*common.AlertRuleConfig{{
+			Id:        "stuck-tip",
+			Type:      common.AlertRuleTypeMetric,
+			Metric:    "erpc_network_served_tip_advance_age_seconds",
+			Threshold: 120,
+			For:       common.Duration(time.Nanosecond),
+			Severity:  common.AlertSeverityCritical,
+		}},
+	})
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	age.WithLabelValues("main", "evm:1").Set(300)
+	age.WithLabelValues("main", "evm:10").Set(5)
+	a.evaluate(ctx, now)
+	a.evaluate(ctx, now.Add(time.Second))
+	alerts := a.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, common.AlertSeverityCritical, alerts[0].Severity)
+	assert.Equal(t, `stuck-tip{network="evm:1",project="main"}`, alerts[0].Fingerprint)
+
+	age.WithLabelValues("main", "evm:1").Set(1)
+	a.evaluate(ctx, now.Add(2*time.Second))
+	assert.Len(t, sink.received(), 1, "resolved alerts are not sent when sendResolved is false")
+}
+
+func TestAlerter_CacheHitRateBudget(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	rate := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "erpc_cache_hit_rate"}, []string{"project", "network", "method"})
+	breached := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "erpc_cache_hit_rate_budget_breached"}, []string{"project", "network", "method"})
+	reg.MustRegister(rate, breached)
+	sink := newAlertTestSink(t)
+	a := newTestAlerter(t, reg, &common.AlertingConfig{
+		Sinks: []*common.AlertSinkConfig{{Id: "hook", Type: common.AlertSinkTypeWebhook, Url: sink.srv.URL}},
+		Rules: []*common.AlertRuleConfig{{Id: "cache-budget", Type: common.AlertRuleTypeCacheHitRateBudget, For: common.Duration(time.Nanosecond)}},
+	})
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	rate.WithLabelValues("main", "evm:1", "eth_getLogs").Set(0.25)
+	breached.WithLabelValues("main", "evm:1", "eth_getLogs").Set(1)
+	rate.WithLabelValues("main", "evm:1", "eth_call").Set(0.9)
+	breached.WithLabelValues("main", "evm:1", "eth_call").Set(0)
+	a.evaluate(ctx, now)
+	a.evaluate(ctx, now.Add(time.Second))
+	received := sink.received()
+	require.Len(t, received, 1)
+	assert.Equal(t, AlertStatusFiring, received[0]["status"])
+	assert.Equal(t, map[string]interface{}{"project": "main", "network": "evm:1", "method": "eth_getLogs"}, received[0]["labels"])
+	assert.InDelta(t, 0.25, received[0]["value"], 1e-9)
+	assert.Contains(t, received[0]["summary"], "25.0%")
+
+	breached.WithLabelValues("main", "evm:1", "eth_getLogs").Set(0)
+	a.evaluate(ctx, now.Add(2*time.Second))
+	assert.Empty(t, a.Alerts(), "the alert resolves once a report is within budget")
+}
+
+func TestAlertSinks_Payloads(t *testing.T) {
+	sink := newAlertTestSink(t)
+	alert := &Alert{
+		Fingerprint: `down{upstream="rpc1"}`,
+		Rule:        "down",
+		Severity:    common.AlertSeverityCritical,
+		Status:      AlertStatusResolved,
+		Labels:      map[string]string{"upstream": "rpc1"},
+		Summary:     "upstream rpc1 is cordoned",
+	}
+
+	require.NoError(t, newAlertSink(&common.AlertSinkConfig{Type: common.AlertSinkTypeSlack, Url: sink.srv.URL}).Notify(context.Background(), alert))
+	require.NoError(t, newAlertSink(&common.AlertSinkConfig{Type: common.AlertSinkTypePagerDuty, Url: sink.srv.URL, RoutingKey: "key"}).Notify(context.Background(), alert))
+
+	received := sink.received()
+	require.Len(t, received, 2)
+	assert.Equal(t, ":white_check_mark: [RESOLVED] down (critical): upstream rpc1 is cordoned", received[0]["text"])
+	assert.Equal(t, "resolve", received[1]["event_action"])
+	assert.Equal(t, "key", received[1]["routing_key"])
+	assert.Equal(t, `down{upstream="rpc1"}`, received[1]["dedup_key"])
+	assert.Equal(t, "critical", received[1]["payload"].(map[string]interface{})["severity"])
+}
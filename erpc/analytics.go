@@ -0,0 +1,517 @@
+package erpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+)
+
+const (
+	analyticsPartitionPrefix = "analytics:"
+	analyticsWriteTimeout    = 5 * time.Second
+	analyticsScanPageSize    = 500
+	// analyticsOtherMethod collects the rows of a bucket past its row cap.
+	analyticsOtherMethod = "other"
+)
+
+const (
+	AnalyticsResolutionMinute = "1m"
+	AnalyticsResolutionHour   = "1h"
+)
+
+const (
+	AnalyticsGroupByNetwork = "network"
+	AnalyticsGroupByMethod  = "method"
+	AnalyticsGroupByUser    = "user"
+)
+
+// AnalyticsCounters are the totals of a rollup row.
+type AnalyticsCounters struct {
+	Requests    int64 `json:"requests"`
+	Errors      int64 `json:"errors"`
+	RateLimited int64 `json:"rateLimited"`
+	CreditUnits int64 `json:"creditUnits"`
+	CacheHits   int64 `json:"cacheHits"`
+}
+
+func (c *AnalyticsCounters) add(o *AnalyticsCounters) {
+	c.Requests += o.Requests
+	c.Errors += o.Errors
+	c.RateLimited += o.RateLimited
+	c.CreditUnits += o.CreditUnits
+	c.CacheHits += o.CacheHits
+}
+
+// AnalyticsRow holds the counters of one network/method/consumer combination.
+// Dimensions a query does not group by are left empty.
+type AnalyticsRow struct {
+	NetworkId string `json:"networkId,omitempty"`
+	Method    string `json:"method,omitempty"`
+	UserId    string `json:"userId,omitempty"`
+	AnalyticsCounters
+}
+
+// AnalyticsPoint is one bucket of a queried time series.
+type AnalyticsPoint struct {
+	Start time.Time       `json:"start"`
+	Rows  []*AnalyticsRow `json:"rows"`
+}
+
+// AnalyticsQuery selects rollups of a project. Empty filters match
+// everything; From is rounded down to the resolution and To is exclusive.
+type AnalyticsQuery struct {
+	ProjectId  string
+	NetworkId  string
+	Method     string
+	UserId     string
+	Resolution string
+	From       time.Time
+	To         time.Time
+	GroupBy    []string
+}
+
+// analyticsRecord is what one writer stores for one bucket: its cumulative
+// totals so far, overwritten on every flush.
+type analyticsRecord struct {
+	ProjectId  string          `json:"projectId"`
+	Resolution string          `json:"resolution"`
+	Start      time.Time       `json:"start"`
+	Writer     string          `json:"writer"`
+	Rows       []*AnalyticsRow `json:"rows"`
+}
+
+type analyticsRowKey struct {
+	networkId string
+	method    string
+	userId    string
+}
+
+type analyticsBucketKey struct {
+	projectId  string
+	resolution string
+	start      int64
+}
+
+type analyticsBucket struct {
+	rows  map[analyticsRowKey]*AnalyticsCounters
+	dirty bool
+}
+
+// AnalyticsRecorder aggregates proxied requests into per-minute and per-hour
+// rollups in memory and periodically writes them to a connector. Each
+// process writes its own record per bucket, named by a writer id unique to
+// the process, so replicas never contend on a key; reads sum the records of
+// every writer. Retention is the connector TTL of each resolution.
+type AnalyticsRecorder struct {
+	logger    *zerolog.Logger
+	connector data.Connector
+	writer    string
+	interval  time.Duration
+	ttls      map[string]time.Duration
+	maxRows   int
+
+	mu      sync.Mutex
+	buckets map[analyticsBucketKey]*analyticsBucket
+}
+
+func NewAnalyticsRecorder(ctx context.Context, logger *zerolog.Logger, cfg *common.AnalyticsConfig) (*AnalyticsRecorder, error) {
+	connector, err := data.NewConnector(ctx, logger, cfg.Connector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create analytics connector: %w", err)
+	}
+	lg := logger.With().Str("component", "analytics").Logger()
+	return &AnalyticsRecorder{
+		logger:    &lg,
+		connector: connector,
+		writer:    processInstanceName(),
+		interval:  cfg.FlushInterval.Duration(),
+		ttls: map[string]time.Duration{
+			AnalyticsResolutionMinute: cfg.MinuteRetention.Duration(),
+			AnalyticsResolutionHour:   cfg.HourRetention.Duration(),
+		},
+		maxRows: cfg.MaxRowsPerBucket,
+		buckets: make(map[analyticsBucketKey]*analyticsBucket),
+	}, nil
+}
+
+// Start flushes the open buckets every flush interval until ctx is done, and
+// once more on the way out so a clean shutdown loses nothing.
+func (a *AnalyticsRecorder) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				a.flush(context.WithoutCancel(ctx))
+				return
+			case <-ticker.C:
+				a.flush(ctx)
+			}
+		}
+	}()
+}
+
+// Record counts one request of a project. It is a no-op on a nil recorder.
+func (a *AnalyticsRecorder) Record(projectId, networkId, method string, nq *common.NormalizedRequest, resp *common.NormalizedResponse, err error) {
+	if a == nil || projectId == "" {
+		return
+	}
+	c := AnalyticsCounters{Requests: 1}
+	if err != nil {
+		c.Errors = 1
+		if common.HasErrorCode(
+			err,
+			common.ErrCodeAuthRateLimitRuleExceeded,
+			common.ErrCodeProjectRateLimitRuleExceeded,
+			common.ErrCodeNetworkRateLimitRuleExceeded,
+		) {
+			c.RateLimited = 1
+		}
+	}
+	if resp != nil && resp.FromCache() {
+		c.CacheHits = 1
+	}
+	key := analyticsRowKey{networkId: networkId, method: method}
+	if nq != nil {
+		if st := nq.ExecState(); st != nil {
+			for _, attempt := range st.UpstreamAttemptLog() {
+				c.CreditUnits += attempt.CreditUnits
+			}
+		}
+		if user := nq.User(); user != nil {
+			key.userId = user.Id
+		}
+	}
+
+	now := time.Now().UTC()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, res := range []string{AnalyticsResolutionMinute, AnalyticsResolutionHour} {
+		bk := analyticsBucketKey{projectId: projectId, resolution: res, start: analyticsBucketStart(now, res).Unix()}
+		b, ok := a.buckets[bk]
+		if !ok {
+			b = &analyticsBucket{rows: make(map[analyticsRowKey]*AnalyticsCounters)}
+			a.buckets[bk] = b
+		}
+		rk := key
+		if _, ok := b.rows[rk]; !ok && a.maxRows > 0 && len(b.rows) >= a.maxRows {
+			rk = analyticsRowKey{networkId: networkId, method: analyticsOtherMethod}
+		}
+		row, ok := b.rows[rk]
+		if !ok {
+			row = &AnalyticsCounters{}
+			b.rows[rk] = row
+		}
+		row.add(&c)
+		b.dirty = true
+	}
+}
+
+// flush writes every bucket changed since the last flush. Buckets whose
+// period is over are dropped from memory once written; a failed write is
+// retried on the next flush.
+func (a *AnalyticsRecorder) flush(ctx context.Context) {
+	now := time.Now().UTC()
+	type pending struct {
+		key    analyticsBucketKey
+		record *analyticsRecord
+	}
+	var writes []pending
+	a.mu.Lock()
+	for bk, b := range a.buckets {
+		if !b.dirty {
+			// Written after its last change, so nothing is left to keep.
+			if !analyticsBucketOpen(time.Unix(bk.start, 0), bk.resolution, now) {
+				delete(a.buckets, bk)
+			}
+			continue
+		}
+		b.dirty = false
+		writes = append(writes, pending{key: bk, record: a.recordOf(bk, b)})
+	}
+	a.mu.Unlock()
+
+	for _, w := range writes {
+		err := a.put(ctx, w.record)
+		outcome := "success"
+		if err != nil {
+			outcome = "error"
+			a.logger.Warn().Err(err).
+				Str("projectId", w.key.projectId).
+				Str("resolution", w.key.resolution).
+				Time("start", w.record.Start).
+				Msg("failed to write analytics rollup, will retry on next flush")
+		}
+		telemetry.MetricAnalyticsRollupWritesTotal.WithLabelValues(w.key.projectId, w.key.resolution, outcome).Inc()
+		a.mu.Lock()
+		if b, ok := a.buckets[w.key]; ok {
+			if err != nil {
+				b.dirty = true
+			} else if !b.dirty && !analyticsBucketOpen(w.record.Start, w.key.resolution, now) {
+				delete(a.buckets, w.key)
+			}
+		}
+		a.mu.Unlock()
+	}
+}
+
+// recordOf snapshots a bucket; the caller holds a.mu.
+func (a *AnalyticsRecorder) recordOf(bk analyticsBucketKey, b *analyticsBucket) *analyticsRecord {
+	rec := &analyticsRecord{
+		ProjectId:  bk.projectId,
+		Resolution: bk.resolution,
+		Start:      time.Unix(bk.start, 0).UTC(),
+		Writer:     a.writer,
+		Rows:       make([]*AnalyticsRow, 0, len(b.rows)),
+	}
+	for rk, c := range b.rows {
+		rec.Rows = append(rec.Rows, &AnalyticsRow{NetworkId: rk.networkId, Method: rk.method, UserId: rk.userId, AnalyticsCounters: *c})
+	}
+	return rec
+}
+
+func (a *AnalyticsRecorder) put(ctx context.Context, rec *analyticsRecord) error {
+	value, err := common.SonicCfg.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	pk, rk := analyticsKeys(rec.ProjectId, rec.Resolution, rec.Start, a.writer)
+	ttl := a.ttls[rec.Resolution]
+	ctx, cancel := context.WithTimeout(ctx, analyticsWriteTimeout)
+	defer cancel()
+	return a.connector.Set(ctx, pk, rk, value, &ttl)
+}
+
+// Query returns the rollups of q.ProjectId between q.From and q.To, summed
+// over every writer and grouped by q.GroupBy, one point per bucket that saw
+// traffic. Buckets this process still holds in memory are read from memory,
+// so the latest requests show up before they are flushed.
+func (a *AnalyticsRecorder) Query(ctx context.Context, q *AnalyticsQuery) ([]*AnalyticsPoint, *AnalyticsCounters, error) {
+	from := analyticsBucketStart(q.From.UTC(), q.Resolution)
+	to := q.To.UTC()
+	if retention := a.ttls[q.Resolution]; from.Before(to.Add(-retention)) {
+		from = analyticsBucketStart(to.Add(-retention), q.Resolution)
+	}
+	group := map[string]bool{}
+	for _, g := range q.GroupBy {
+		group[g] = true
+	}
+	points := map[int64]map[analyticsRowKey]*AnalyticsCounters{}
+	add := func(start time.Time, row *AnalyticsRow) {
+		if start.Before(from) || !start.Before(to) ||
+			(q.NetworkId != "" && row.NetworkId != q.NetworkId) ||
+			(q.Method != "" && row.Method != q.Method) ||
+			(q.UserId != "" && row.UserId != q.UserId) {
+			return
+		}
+		var key analyticsRowKey
+		if group[AnalyticsGroupByNetwork] {
+			key.networkId = row.NetworkId
+		}
+		if group[AnalyticsGroupByMethod] {
+			key.method = row.Method
+		}
+		if group[AnalyticsGroupByUser] {
+			key.userId = row.UserId
+		}
+		rows, ok := points[start.Unix()]
+		if !ok {
+			rows = map[analyticsRowKey]*AnalyticsCounters{}
+			points[start.Unix()] = rows
+		}
+		c, ok := rows[key]
+		if !ok {
+			c = &AnalyticsCounters{}
+			rows[key] = c
+		}
+		c.add(&row.AnalyticsCounters)
+	}
+
+	a.mu.Lock()
+	var local []*analyticsRecord
+	inMemory := map[int64]bool{}
+	for bk, b := range a.buckets {
+		if bk.projectId == q.ProjectId && bk.resolution == q.Resolution {
+			local = append(local, a.recordOf(bk, b))
+			inMemory[bk.start] = true
+		}
+	}
+	a.mu.Unlock()
+	for _, rec := range local {
+		for _, row := range rec.Rows {
+			add(rec.Start, row)
+		}
+	}
+
+	for partition := analyticsPartitionStart(from, q.Resolution); partition.Before(to); partition = analyticsNextPartition(partition, q.Resolution) {
+		pk, _ := analyticsKeys(q.ProjectId, q.Resolution, partition, "")
+		cursor := ""
+		for {
+			items, next, err := a.connector.Scan(ctx, pk, "", analyticsScanPageSize, cursor)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, kv := range items {
+				var rec analyticsRecord
+				if err := common.SonicCfg.Unmarshal(kv.Value, &rec); err != nil {
+					continue
+				}
+				if rec.ProjectId != q.ProjectId || rec.Resolution != q.Resolution {
+					continue
+				}
+				if rec.Writer == a.writer && inMemory[rec.Start.Unix()] {
+					continue
+				}
+				for _, row := range rec.Rows {
+					add(rec.Start.UTC(), row)
+				}
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+	}
+
+	total := &AnalyticsCounters{}
+	out := make([]*AnalyticsPoint, 0, len(points))
+	for start, rows := range points {
+		p := &AnalyticsPoint{Start: time.Unix(start, 0).UTC(), Rows: make([]*AnalyticsRow, 0, len(rows))}
+		for k, c := range rows {
+			p.Rows = append(p.Rows, &AnalyticsRow{NetworkId: k.networkId, Method: k.method, UserId: k.userId, AnalyticsCounters: *c})
+			total.add(c)
+		}
+		sort.Slice(p.Rows, func(i, j int) bool {
+			if p.Rows[i].NetworkId != p.Rows[j].NetworkId {
+				return p.Rows[i].NetworkId < p.Rows[j].NetworkId
+			}
+			if p.Rows[i].Method != p.Rows[j].Method {
+				return p.Rows[i].Method < p.Rows[j].Method
+			}
+			return p.Rows[i].UserId < p.Rows[j].UserId
+		})
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out, total, nil
+}
+
+// analyticsKeys returns where a writer stores a bucket. Minute buckets are
+// partitioned by hour and hour buckets by day, so a query scans one
+// partition per hour or day of its range.
+func analyticsKeys(projectId, resolution string, start time.Time, writer string) (string, string) {
+	start = start.UTC()
+	if resolution == AnalyticsResolutionHour {
+		return analyticsPartitionPrefix + projectId + ":" + resolution + ":" + start.Format("20060102"), start.Format("15") + "/" + writer
+	}
+	return analyticsPartitionPrefix + projectId + ":" + resolution + ":" + start.Format("2006010215"), start.Format("04") + "/" + writer
+}
+
+func analyticsBucketStart(t time.Time, resolution string) time.Time {
+	if resolution == AnalyticsResolutionHour {
+		return t.Truncate(time.Hour)
+	}
+	return t.Truncate(time.Minute)
+}
+
+func analyticsBucketOpen(start time.Time, resolution string, now time.Time) bool {
+	if resolution == AnalyticsResolutionHour {
+		return now.Before(start.Add(time.Hour))
+	}
+	return now.Before(start.Add(time.Minute))
+}
+
+func analyticsPartitionStart(t time.Time, resolution string) time.Time {
+	if resolution == AnalyticsResolutionHour {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(time.Hour)
+}
+
+func analyticsNextPartition(t time.Time, resolution string) time.Time {
+	if resolution == AnalyticsResolutionHour {
+		return t.AddDate(0, 0, 1)
+	}
+	return t.Add(time.Hour)
+}
+
+// analyticsQueryParams are the params of the methods that read rollups.
+type analyticsQueryParams struct {
+	ProjectID  string     `json:"projectId,omitempty"`
+	NetworkID  string     `json:"networkId,omitempty"`
+	Method     string     `json:"method,omitempty"`
+	UserID     string     `json:"userId,omitempty"`
+	Resolution string     `json:"resolution,omitempty"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+	GroupBy    []string   `json:"groupBy,omitempty"`
+}
+
+// parseAnalyticsQuery reads the first param of nq as analyticsQueryParams.
+// The range defaults to the last hour for minute rollups and the last day
+// for hour rollups, ending now.
+func parseAnalyticsQuery(nq *common.NormalizedRequest, defaultGroupBy []string) (*AnalyticsQuery, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	var p analyticsQueryParams
+	if len(jrr.Params) > 0 {
+		raw, err := json.Marshal(jrr.Params[0])
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("analytics: invalid params: %w", err))
+		}
+	}
+	q := &AnalyticsQuery{
+		ProjectId:  p.ProjectID,
+		NetworkId:  p.NetworkID,
+		Method:     p.Method,
+		UserId:     p.UserID,
+		Resolution: p.Resolution,
+		GroupBy:    p.GroupBy,
+	}
+	window := time.Hour
+	switch q.Resolution {
+	case "":
+		q.Resolution = AnalyticsResolutionMinute
+	case AnalyticsResolutionMinute:
+	case AnalyticsResolutionHour:
+		window = 24 * time.Hour
+	default:
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("analytics: resolution must be %q or %q", AnalyticsResolutionMinute, AnalyticsResolutionHour))
+	}
+	q.To = time.Now()
+	if p.To != nil {
+		q.To = *p.To
+	}
+	q.From = q.To.Add(-window)
+	if p.From != nil {
+		q.From = *p.From
+	}
+	if !q.From.Before(q.To) {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("analytics: from must be before to"))
+	}
+	if q.GroupBy == nil {
+		q.GroupBy = defaultGroupBy
+	}
+	for _, g := range q.GroupBy {
+		switch g {
+		case AnalyticsGroupByNetwork, AnalyticsGroupByMethod, AnalyticsGroupByUser:
+		default:
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("analytics: cannot group by %q, use %q, %q or %q", g, AnalyticsGroupByNetwork, AnalyticsGroupByMethod, AnalyticsGroupByUser))
+		}
+	}
+	return q, nil
+}
@@ -0,0 +1,169 @@
+package erpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsRecorder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	connector, err := data.NewMemoryConnector(ctx, &log.Logger, "analytics-test", &common.MemoryConnectorConfig{
+		MaxItems: 1000, MaxTotalSize: "1MB",
+	})
+	require.NoError(t, err)
+	newRecorder := func(writer string, maxRows int) *AnalyticsRecorder {
+		return &AnalyticsRecorder{
+			logger:    &log.Logger,
+			connector: connector,
+			writer:    writer,
+			interval:  time.Minute,
+			ttls: map[string]time.Duration{
+				AnalyticsResolutionMinute: time.Hour,
+				AnalyticsResolutionHour:   24 * time.Hour,
+			},
+			maxRows: maxRows,
+			buckets: make(map[analyticsBucketKey]*analyticsBucket),
+		}
+	}
+	request := func(method, userId string, creditUnits int64) *common.NormalizedRequest {
+		nq := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":[]}`))
+		if userId != "" {
+			nq.SetUser(&common.User{Id: userId})
+		}
+		if creditUnits > 0 {
+			nq.ExecState().RecordUpstreamAttempt(common.UpstreamAttempt{UpstreamId: "up", CreditUnits: creditUnits})
+		}
+		return nq
+	}
+	query := func(a *AnalyticsRecorder, q AnalyticsQuery) ([]*AnalyticsPoint, *AnalyticsCounters) {
+		q.ProjectId = "main"
+		if q.Resolution == "" {
+			q.Resolution = AnalyticsResolutionMinute
+		}
+		q.To = time.Now().Add(time.Minute)
+		q.From = q.To.Add(-time.Hour)
+		points, totals, err := a.Query(ctx, &q)
+		require.NoError(t, err)
+		return points, totals
+	}
+
+	a := newRecorder("replica-a", 0)
+	b := newRecorder("replica-b", 0)
+	a.Record("main", "evm:1", "eth_call", request("eth_call", "alice", 26), nil, nil)
+	a.Record("main", "evm:1", "eth_call", request("eth_call", "alice", 0), common.NewNormalizedResponse().WithFromCache(true), nil)
+	a.Record("main", "evm:1", "eth_getBalance", request("eth_getBalance", "bob", 0), nil, common.NewErrProjectRateLimitRuleExceeded("main", "default", "*"))
+	a.Record("other", "evm:1", "eth_call", request("eth_call", "alice", 0), nil, nil)
+	b.Record("main", "evm:10", "eth_call", request("eth_call", "alice", 20), nil, errors.New("boom"))
+
+	t.Run("UnflushedBucketsAreReadFromMemory", func(t *testing.T) {
+		_, totals := query(a, AnalyticsQuery{})
+		assert.Equal(t, AnalyticsCounters{Requests: 3, Errors: 1, RateLimited: 1, CreditUnits: 26, CacheHits: 1}, *totals)
+	})
+
+	a.flush(ctx)
+	b.flush(ctx)
+	// The memory connector applies writes asynchronously.
+	require.Eventually(t, func() bool {
+		_, totals := query(a, AnalyticsQuery{})
+		return totals.Requests == 4
+	}, time.Second, 10*time.Millisecond)
+
+	t.Run("WritersAreSummed", func(t *testing.T) {
+		points, totals := query(a, AnalyticsQuery{Resolution: AnalyticsResolutionHour, GroupBy: []string{AnalyticsGroupByNetwork, AnalyticsGroupByMethod}})
+		assert.Equal(t, AnalyticsCounters{Requests: 4, Errors: 2, RateLimited: 1, CreditUnits: 46, CacheHits: 1}, *totals)
+		require.Len(t, points, 1)
+		assert.Equal(t, []*AnalyticsRow{
+			{NetworkId: "evm:1", Method: "eth_call", AnalyticsCounters: AnalyticsCounters{Requests: 2, CreditUnits: 26, CacheHits: 1}},
+			{NetworkId: "evm:1", Method: "eth_getBalance", AnalyticsCounters: AnalyticsCounters{Requests: 1, Errors: 1, RateLimited: 1}},
+			{NetworkId: "evm:10", Method: "eth_call", AnalyticsCounters: AnalyticsCounters{Requests: 1, Errors: 1, CreditUnits: 20}},
+		}, points[0].Rows)
+
+		// Another replica sees the same totals, all read from the store.
+		_, totals = query(newRecorder("replica-c", 0), AnalyticsQuery{})
+		assert.Equal(t, int64(4), totals.Requests)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		points, totals := query(a, AnalyticsQuery{Resolution: AnalyticsResolutionHour, UserId: "alice", GroupBy: []string{AnalyticsGroupByUser}})
+		assert.Equal(t, int64(3), totals.Requests)
+		require.Len(t, points, 1)
+		assert.Equal(t, []*AnalyticsRow{{UserId: "alice", AnalyticsCounters: AnalyticsCounters{Requests: 3, Errors: 1, CreditUnits: 46, CacheHits: 1}}}, points[0].Rows)
+
+		_, totals = query(a, AnalyticsQuery{NetworkId: "evm:10"})
+		assert.Equal(t, int64(1), totals.Requests)
+	})
+
+	t.Run("ClosedBucketsLeaveMemoryOnceWritten", func(t *testing.T) {
+		c := newRecorder("replica-c", 0)
+		start := analyticsBucketStart(time.Now().UTC().Add(-10*time.Minute), AnalyticsResolutionMinute)
+		bk := analyticsBucketKey{projectId: "main", resolution: AnalyticsResolutionMinute, start: start.Unix()}
+		c.buckets[bk] = &analyticsBucket{
+			rows:  map[analyticsRowKey]*AnalyticsCounters{{networkId: "evm:1", method: "eth_chainId"}: {Requests: 5}},
+			dirty: true,
+		}
+		c.flush(ctx)
+		assert.Empty(t, c.buckets)
+
+		var points []*AnalyticsPoint
+		require.Eventually(t, func() bool {
+			points, _ = query(a, AnalyticsQuery{Method: "eth_chainId"})
+			return len(points) == 1
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, int64(5), points[0].Rows[0].Requests)
+		assert.Equal(t, start, points[0].Start)
+	})
+
+	t.Run("RowsPastTheCapAreFoldedIntoOther", func(t *testing.T) {
+		d := newRecorder("replica-d", 1)
+		d.Record("capped", "evm:1", "eth_call", request("eth_call", "", 0), nil, nil)
+		d.Record("capped", "evm:1", "eth_getLogs", request("eth_getLogs", "", 0), nil, nil)
+		d.Record("capped", "evm:1", "eth_getCode", request("eth_getCode", "", 0), nil, nil)
+		points, _, err := d.Query(ctx, &AnalyticsQuery{
+			ProjectId:  "capped",
+			Resolution: AnalyticsResolutionHour,
+			From:       time.Now().Add(-time.Minute),
+			To:         time.Now().Add(time.Minute),
+			GroupBy:    []string{AnalyticsGroupByMethod},
+		})
+		require.NoError(t, err)
+		require.Len(t, points, 1)
+		assert.Equal(t, []*AnalyticsRow{
+			{Method: "eth_call", AnalyticsCounters: AnalyticsCounters{Requests: 1}},
+			{Method: analyticsOtherMethod, AnalyticsCounters: AnalyticsCounters{Requests: 2}},
+		}, points[0].Rows)
+	})
+}
+
+func TestParseAnalyticsQuery(t *testing.T) {
+	parse := func(body string) (*AnalyticsQuery, error) {
+		return parseAnalyticsQuery(common.NewNormalizedRequest([]byte(body)), []string{AnalyticsGroupByMethod})
+	}
+
+	q, err := parse(`{"jsonrpc":"2.0","id":1,"method":"erpc_getAnalytics","params":[{"projectId":"main"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, AnalyticsResolutionMinute, q.Resolution)
+	assert.Equal(t, time.Hour, q.To.Sub(q.From))
+	assert.Equal(t, []string{AnalyticsGroupByMethod}, q.GroupBy)
+
+	q, err = parse(`{"jsonrpc":"2.0","id":1,"method":"erpc_getAnalytics","params":[{"resolution":"1h","to":"2026-10-17T12:00:00Z","groupBy":[]}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), q.From.UTC())
+	assert.Empty(t, q.GroupBy)
+
+	_, err = parse(`{"jsonrpc":"2.0","id":1,"method":"erpc_getAnalytics","params":[{"resolution":"5m"}]}`)
+	assert.ErrorContains(t, err, "resolution")
+	_, err = parse(`{"jsonrpc":"2.0","id":1,"method":"erpc_getAnalytics","params":[{"groupBy":["upstream"]}]}`)
+	assert.ErrorContains(t, err, "cannot group by")
+	_, err = parse(`{"jsonrpc":"2.0","id":1,"method":"erpc_getAnalytics","params":[{"from":"2026-10-17T12:00:00Z","to":"2026-10-17T11:00:00Z"}]}`)
+	assert.ErrorContains(t, err, "from must be before to")
+}
@@ -0,0 +1,453 @@
+package erpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"golang.org/x/time/rate"
+)
+
+// Backfill jobs walk a block range and issue the configured per-block methods
+// through the network, so responses land in the cache exactly as if a client
+// had requested them. Already-cached blocks are served from the cache and cost
+// no upstream request, which makes restarting an interrupted job cheap.
+
+type BackfillJobState string
+
+const (
+	BackfillJobStateRunning   BackfillJobState = "running"
+	BackfillJobStateCompleted BackfillJobState = "completed"
+	BackfillJobStateCancelled BackfillJobState = "cancelled"
+)
+
+const (
+	defaultBackfillRateLimit   = 10
+	defaultBackfillConcurrency = 4
+	maxBackfillConcurrency     = 64
+	// maxFinishedBackfillJobs is how many finished jobs a manager keeps for
+	// Get/List; older ones are dropped when a new job starts, so a scheduled
+	// backfill that runs forever does not grow the job map without bound.
+	maxFinishedBackfillJobs = 100
+)
+
+// backfillMethods lists the per-block methods a backfill job can warm, keyed by
+// method name, with the params builder for a given block number.
+var backfillMethods = map[string]func(blockHex string) []interface{}{
+	"eth_getBlockByNumber": func(b string) []interface{} { return []interface{}{b, true} },
+	"eth_getBlockReceipts": func(b string) []interface{} { return []interface{}{b} },
+	"debug_traceBlockByNumber": func(b string) []interface{} {
+		return []interface{}{b, map[string]interface{}{"tracer": "callTracer"}}
+	},
+	"trace_block": func(b string) []interface{} { return []interface{}{b} },
+}
+
+var defaultBackfillMethods = []string{"eth_getBlockByNumber", "eth_getBlockReceipts"}
+
+type backfillParams struct {
+	ProjectID string   `json:"projectId"`
+	NetworkID string   `json:"networkId"`
+	FromBlock int64    `json:"fromBlock"`
+	ToBlock   int64    `json:"toBlock"`
+	Methods   []string `json:"methods,omitempty"`
+	// RateLimit is the max number of requests per second issued by the job.
+	RateLimit   float64 `json:"rateLimit,omitempty"`
+	Concurrency int     `json:"concurrency,omitempty"`
+}
+
+func (p *backfillParams) normalize() error {
+	if p.ProjectID == "" || p.NetworkID == "" {
+		return fmt.Errorf("backfill admin: projectId and networkId are required")
+	}
+	if p.FromBlock < 0 || p.ToBlock < p.FromBlock {
+		return fmt.Errorf("backfill admin: invalid block range %d-%d", p.FromBlock, p.ToBlock)
+	}
+	if len(p.Methods) == 0 {
+		p.Methods = defaultBackfillMethods
+	}
+	for _, m := range p.Methods {
+		if _, ok := backfillMethods[m]; !ok {
+			return fmt.Errorf("backfill admin: method %q is not supported", m)
+		}
+	}
+	if p.RateLimit < 0 {
+		return fmt.Errorf("backfill admin: rateLimit must be >= 0")
+	}
+	if p.RateLimit == 0 {
+		p.RateLimit = defaultBackfillRateLimit
+	}
+	if p.Concurrency <= 0 {
+		p.Concurrency = defaultBackfillConcurrency
+	}
+	if p.Concurrency > maxBackfillConcurrency {
+		p.Concurrency = maxBackfillConcurrency
+	}
+	return nil
+}
+
+// BackfillJob is a snapshot-able record of a single backfill run.
+type BackfillJob struct {
+	mu sync.RWMutex
+
+	Id          string
+	ProjectId   string
+	NetworkId   string
+	FromBlock   int64
+	ToBlock     int64
+	Methods     []string
+	RateLimit   float64
+	Concurrency int
+	State       BackfillJobState
+	// NextBlock is the lowest block not yet dispatched.
+	NextBlock  int64
+	Succeeded  int64
+	Failed     int64
+	LastError  string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Done is closed once the job has stopped dispatching and all in-flight
+// requests have finished.
+func (j *BackfillJob) Done() <-chan struct{} {
+	return j.done
+}
+
+func (j *BackfillJob) snapshot() map[string]interface{} {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	out := map[string]interface{}{
+		"id":          j.Id,
+		"projectId":   j.ProjectId,
+		"networkId":   j.NetworkId,
+		"fromBlock":   j.FromBlock,
+		"toBlock":     j.ToBlock,
+		"methods":     j.Methods,
+		"rateLimit":   j.RateLimit,
+		"concurrency": j.Concurrency,
+		"state":       j.State,
+		"nextBlock":   j.NextBlock,
+		"succeeded":   j.Succeeded,
+		"failed":      j.Failed,
+		"startedAt":   j.StartedAt,
+	}
+	if j.LastError != "" {
+		out["lastError"] = j.LastError
+	}
+	if j.FinishedAt != nil {
+		out["finishedAt"] = *j.FinishedAt
+	}
+	return out
+}
+
+func (j *BackfillJob) record(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil {
+		j.Failed++
+		j.LastError = err.Error()
+	} else {
+		j.Succeeded++
+	}
+}
+
+func (j *BackfillJob) finish(state BackfillJobState) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.State != BackfillJobStateRunning {
+		return
+	}
+	now := time.Now()
+	j.State = state
+	j.FinishedAt = &now
+}
+
+// BackfillManager keeps track of backfill jobs started via the admin API. Jobs
+// live in memory only; a restarted instance forgets them, and only the last
+// maxFinished finished jobs are kept.
+type BackfillManager struct {
+	mu          sync.RWMutex
+	jobs        map[string]*BackfillJob
+	seq         int64
+	maxFinished int
+	logger      *zerolog.Logger
+}
+
+func NewBackfillManager(logger *zerolog.Logger) *BackfillManager {
+	lg := logger.With().Str("component", "backfill").Logger()
+	return &BackfillManager{
+		jobs:        make(map[string]*BackfillJob),
+		maxFinished: maxFinishedBackfillJobs,
+		logger:      &lg,
+	}
+}
+
+// Start launches a backfill job against the given network and returns it
+// immediately; progress is observable through Get/List.
+func (m *BackfillManager) Start(network *Network, p backfillParams) (*BackfillJob, error) {
+	if err := p.normalize(); err != nil {
+		return nil, err
+	}
+	if head := network.EvmHighestLatestBlockNumber(network.appCtx); head > 0 && p.ToBlock > head {
+		return nil, fmt.Errorf("backfill admin: toBlock %d is beyond network head %d", p.ToBlock, head)
+	}
+
+	m.mu.Lock()
+	m.seq++
+	id := fmt.Sprintf("backfill-%d-%d", time.Now().Unix(), m.seq)
+	ctx, cancel := context.WithCancel(network.appCtx)
+	job := &BackfillJob{
+		Id:          id,
+		ProjectId:   p.ProjectID,
+		NetworkId:   p.NetworkID,
+		FromBlock:   p.FromBlock,
+		ToBlock:     p.ToBlock,
+		Methods:     p.Methods,
+		RateLimit:   p.RateLimit,
+		Concurrency: p.Concurrency,
+		State:       BackfillJobStateRunning,
+		NextBlock:   p.FromBlock,
+		StartedAt:   time.Now(),
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	m.pruneFinishedLocked()
+	m.jobs[id] = job
+	m.mu.Unlock()
+
+	go m.run(ctx, network, job)
+
+	return job, nil
+}
+
+// pruneFinishedLocked drops the oldest finished jobs beyond maxFinished.
+// Running jobs are always kept. m.mu must be held.
+func (m *BackfillManager) pruneFinishedLocked() {
+	type finished struct {
+		id string
+		at time.Time
+	}
+	var done []finished
+	for id, job := range m.jobs {
+		job.mu.RLock()
+		if job.FinishedAt != nil {
+			done = append(done, finished{id, *job.FinishedAt})
+		}
+		job.mu.RUnlock()
+	}
+	if len(done) <= m.maxFinished {
+		return
+	}
+	sort.Slice(done, func(i, j int) bool {
+		return done[i].at.Before(done[j].at)
+	})
+	for _, f := range done[:len(done)-m.maxFinished] {
+		delete(m.jobs, f.id)
+	}
+}
+
+func (m *BackfillManager) Get(id string) (*BackfillJob, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+func (m *BackfillManager) List() []*BackfillJob {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	jobs := make([]*BackfillJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].StartedAt.Before(jobs[j].StartedAt)
+	})
+	return jobs
+}
+
+func (m *BackfillManager) Cancel(id string) (*BackfillJob, bool) {
+	job, ok := m.Get(id)
+	if !ok {
+		return nil, false
+	}
+	job.finish(BackfillJobStateCancelled)
+	job.cancel()
+	return job, true
+}
+
+func (m *BackfillManager) run(ctx context.Context, network *Network, job *BackfillJob) {
+	defer close(job.done)
+	defer job.cancel()
+	lg := m.logger.With().Str("jobId", job.Id).Str("networkId", job.NetworkId).Logger()
+	lg.Info().Int64("fromBlock", job.FromBlock).Int64("toBlock", job.ToBlock).Strs("methods", job.Methods).Msg("backfill job started")
+
+	limiter := rate.NewLimiter(rate.Limit(job.RateLimit), 1)
+	sem := make(chan struct{}, job.Concurrency)
+	var wg sync.WaitGroup
+
+dispatch:
+	for block := job.FromBlock; block <= job.ToBlock; block++ {
+		blockHex := fmt.Sprintf("0x%x", block)
+		for _, method := range job.Methods {
+			if err := limiter.Wait(ctx); err != nil {
+				break dispatch
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				break dispatch
+			}
+			wg.Add(1)
+			go func(method string, params []interface{}) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				err := forwardInternalRequest(ctx, network, method, params)
+				job.record(err)
+				outcome := "success"
+				if err != nil {
+					outcome = "failure"
+					lg.Debug().Err(err).Str("method", method).Interface("params", params).Msg("backfill request failed")
+				}
+				telemetry.MetricBackfillRequestTotal.WithLabelValues(job.ProjectId, network.Label(), method, outcome).Inc()
+			}(method, backfillMethods[method](blockHex))
+		}
+		job.mu.Lock()
+		job.NextBlock = block + 1
+		job.mu.Unlock()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		job.finish(BackfillJobStateCancelled)
+	} else {
+		job.finish(BackfillJobStateCompleted)
+	}
+	snap := job.snapshot()
+	lg.Info().Interface("state", snap["state"]).Interface("succeeded", snap["succeeded"]).Interface("failed", snap["failed"]).Msg("backfill job finished")
+}
+
+// forwardInternalRequest sends an internally-generated request through the
+// network (and therefore its cache) and reports whether it succeeded.
+func forwardInternalRequest(ctx context.Context, network *Network, method string, params []interface{}) error {
+	jrq := common.NewJsonRpcRequest(method, params)
+	jrq.ID = util.RandomID()
+	nrq := common.NewNormalizedRequestFromJsonRpcRequest(jrq)
+	nrq.SetNetwork(network)
+
+	resp, err := network.Forward(ctx, nrq)
+	if resp != nil {
+		defer resp.Release()
+	}
+	if err != nil {
+		return err
+	}
+	jrr, err := resp.JsonRpcResponse(ctx)
+	if err != nil {
+		return err
+	}
+	if jrr == nil {
+		return fmt.Errorf("unexpected empty json-rpc response for %s", method)
+	}
+	if jrr.Error != nil {
+		return jrr.Error
+	}
+	return nil
+}
+
+func parseBackfillJobId(nq *common.NormalizedRequest) (string, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return "", err
+	}
+	var p struct {
+		JobID string `json:"jobId"`
+	}
+	if len(jrr.Params) > 0 {
+		raw, _ := json.Marshal(jrr.Params[0])
+		_ = json.Unmarshal(raw, &p)
+	}
+	if p.JobID == "" {
+		return "", fmt.Errorf("backfill admin: jobId is required")
+	}
+	return p.JobID, nil
+}
+
+// handleStartBackfill starts a cache backfill job for a project's network.
+func (e *ERPC) handleStartBackfill(ctx context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	if len(jrr.Params) == 0 {
+		return nil, fmt.Errorf("backfill admin: params is required")
+	}
+	var p backfillParams
+	raw, err := json.Marshal(jrr.Params[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("backfill admin: invalid params: %w", err)
+	}
+	if p.ProjectID == "" || p.NetworkID == "" {
+		return nil, fmt.Errorf("backfill admin: projectId and networkId are required")
+	}
+	network, err := e.GetNetwork(ctx, p.ProjectID, p.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	job, err := e.backfills.Start(network, p)
+	if err != nil {
+		return nil, err
+	}
+	return makeSelectionResponse(nq, job.snapshot())
+}
+
+// handleGetBackfill returns the progress of a single backfill job.
+func (e *ERPC) handleGetBackfill(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	id, err := parseBackfillJobId(nq)
+	if err != nil {
+		return nil, err
+	}
+	job, ok := e.backfills.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("backfill admin: job %q not found", id)
+	}
+	return makeSelectionResponse(nq, job.snapshot())
+}
+
+// handleListBackfills returns every backfill job known to this instance.
+func (e *ERPC) handleListBackfills(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jobs := e.backfills.List()
+	rows := make([]map[string]interface{}, 0, len(jobs))
+	for _, job := range jobs {
+		rows = append(rows, job.snapshot())
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"jobs": rows,
+	})
+}
+
+// handleCancelBackfill stops a running backfill job; in-flight requests finish.
+func (e *ERPC) handleCancelBackfill(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	id, err := parseBackfillJobId(nq)
+	if err != nil {
+		return nil, err
+	}
+	job, ok := e.backfills.Cancel(id)
+	if !ok {
+		return nil, fmt.Errorf("backfill admin: job %q not found", id)
+	}
+	return makeSelectionResponse(nq, job.snapshot())
+}
@@ -0,0 +1,141 @@
+package erpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillParamsNormalize(t *testing.T) {
+	p := backfillParams{ProjectID: "prjA", NetworkID: "evm:123", FromBlock: 10, ToBlock: 20}
+	require.NoError(t, p.normalize())
+	assert.Equal(t, defaultBackfillMethods, p.Methods)
+	assert.EqualValues(t, defaultBackfillRateLimit, p.RateLimit)
+	assert.Equal(t, defaultBackfillConcurrency, p.Concurrency)
+
+	p = backfillParams{ProjectID: "prjA", NetworkID: "evm:123", FromBlock: 20, ToBlock: 10}
+	assert.Error(t, p.normalize())
+
+	p = backfillParams{ProjectID: "prjA", NetworkID: "evm:123", FromBlock: 1, ToBlock: 2, Methods: []string{"eth_call"}}
+	assert.Error(t, p.normalize())
+}
+
+func TestBackfillManager_WarmsBlockRange(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	util.SetupMocksForEvmStatePoller()
+
+	gock.New("http://rpc1.localhost").
+		Post("").
+		Times(3).
+		Filter(func(r *http.Request) bool {
+			body := util.SafeReadBody(r)
+			return strings.Contains(body, "eth_getBlockReceipts")
+		}).
+		Reply(200).
+		JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":[]}`))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	network, _ := setupTestNetworkForInterpolation(t, ctx, nil)
+
+	mgr := NewBackfillManager(&log.Logger)
+	job, err := mgr.Start(network, backfillParams{
+		ProjectID: "prjA",
+		NetworkID: network.Id(),
+		FromBlock: 0x100,
+		ToBlock:   0x102,
+		Methods:   []string{"eth_getBlockReceipts"},
+		RateLimit: 100,
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return job.snapshot()["state"] == BackfillJobStateCompleted
+	}, 5*time.Second, 20*time.Millisecond)
+
+	snap := job.snapshot()
+	assert.EqualValues(t, 3, snap["succeeded"])
+	assert.EqualValues(t, 0, snap["failed"])
+	assert.EqualValues(t, 0x103, snap["nextBlock"])
+
+	listed := mgr.List()
+	require.Len(t, listed, 1)
+	assert.Equal(t, job.Id, listed[0].Id)
+}
+
+func TestBackfillManager_KeepsOnlyLastFinishedJobs(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	util.SetupMocksForEvmStatePoller()
+
+	gock.New("http://rpc1.localhost").
+		Post("").
+		Persist().
+		Filter(func(r *http.Request) bool {
+			body := util.SafeReadBody(r)
+			return strings.Contains(body, "eth_getBlockReceipts")
+		}).
+		Reply(200).
+		JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":[]}`))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	network, _ := setupTestNetworkForInterpolation(t, ctx, nil)
+
+	mgr := NewBackfillManager(&log.Logger)
+	mgr.maxFinished = 2
+	var ids []string
+	for i := int64(0); i < 5; i++ {
+		job, err := mgr.Start(network, backfillParams{
+			ProjectID: "prjA",
+			NetworkID: network.Id(),
+			FromBlock: 0x100 + i,
+			ToBlock:   0x100 + i,
+			Methods:   []string{"eth_getBlockReceipts"},
+			RateLimit: 100,
+		})
+		require.NoError(t, err)
+		<-job.Done()
+		ids = append(ids, job.Id)
+		assert.LessOrEqual(t, len(mgr.List()), 3, "the finished jobs kept plus the one just started")
+	}
+
+	_, ok := mgr.Get(ids[0])
+	assert.False(t, ok, "the oldest finished job is dropped")
+	for _, id := range ids[2:] {
+		_, ok := mgr.Get(id)
+		assert.True(t, ok, "job %s is among the last finished jobs", id)
+	}
+}
+
+func TestBackfillManager_RejectsRangeBeyondHead(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	util.SetupMocksForEvmStatePoller()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	network, _ := setupTestNetworkForInterpolation(t, ctx, nil)
+
+	mgr := NewBackfillManager(&log.Logger)
+	_, err := mgr.Start(network, backfillParams{
+		ProjectID: "prjA",
+		NetworkID: network.Id(),
+		FromBlock: 0x11118880,
+		ToBlock:   0x11119999,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "beyond network head")
+}
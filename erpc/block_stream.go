@@ -9,6 +9,7 @@ import (
 	"github.com/blockchain-data-standards/manifesto/evm"
 	"github.com/bytedance/sonic"
 	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
 )
 
 // blockStreamMaxBackfill bounds how many blocks a single advance may backfill.
@@ -45,7 +46,11 @@ func (m *blockStreamManager) hubFor(ctx context.Context, network *Network) *bloc
 		return h
 	}
 
-	h := &blockStreamHub{subs: make(map[*blockStreamSub]struct{})}
+	h := &blockStreamHub{
+		projectId: network.projectId,
+		networkId: network.networkId,
+		subs:      make(map[*blockStreamSub]struct{}),
+	}
 	// Wire the hub onto every upstream's latest-block callback and seed the head
 	// with the highest value any of them already observed. The head is the max
 	// across upstreams; advance() merges the independent callbacks monotonically.
@@ -67,7 +72,9 @@ func (m *blockStreamManager) hubFor(ctx context.Context, network *Network) *bloc
 
 // blockStreamHub fans a network's head advances out to its live subscribers.
 type blockStreamHub struct {
-	head atomic.Int64
+	projectId string
+	networkId string
+	head      atomic.Int64
 
 	mu   sync.Mutex
 	subs map[*blockStreamSub]struct{}
@@ -78,6 +85,8 @@ type blockStreamHub struct {
 // single pending signal; the subscriber then reads the current head and catches up.
 type blockStreamSub struct {
 	ch chan struct{}
+	// lastSent is the last block pushed to the subscriber, read by reportLag.
+	lastSent atomic.Int64
 }
 
 // advance records a new head and wakes subscribers. It runs synchronously inside
@@ -102,6 +111,9 @@ func (h *blockStreamHub) advance(v int64) {
 		}
 	}
 	h.mu.Unlock()
+	// Also measured here so a subscriber stuck on a slow send shows up
+	// before it gets to report on its own.
+	h.reportLag()
 }
 
 func (h *blockStreamHub) subscribe() *blockStreamSub {
@@ -116,6 +128,21 @@ func (h *blockStreamHub) unsubscribe(s *blockStreamSub) {
 	h.mu.Lock()
 	delete(h.subs, s)
 	h.mu.Unlock()
+	h.reportLag()
+}
+
+// reportLag exports how far the slowest subscriber is behind the head.
+func (h *blockStreamHub) reportLag() {
+	head := h.head.Load()
+	var lag int64
+	h.mu.Lock()
+	for s := range h.subs {
+		if sent := s.lastSent.Load(); sent > 0 && head-sent > lag {
+			lag = head - sent
+		}
+	}
+	h.mu.Unlock()
+	telemetry.MetricSubscriptionLagBlocks.WithLabelValues(h.projectId, h.networkId).Set(float64(lag))
 }
 
 // ProcessBlockStream subscribes to the network's head and pushes one header per
@@ -152,10 +179,16 @@ func (rp *RequestProcessor) ProcessBlockStream(
 	hub := rp.blockStream.hubFor(ctx, network)
 	sub := hub.subscribe()
 	defer hub.unsubscribe(sub)
+	if network.prefetcher != nil {
+		// Subscribers typically fetch each new block's receipts next.
+		network.prefetcher.subscribers.Add(1)
+		defer network.prefetcher.subscribers.Add(-1)
+	}
 
 	// Tip subscription: start from the current head and emit only strictly-new
 	// blocks (never backfill history for a fresh subscriber).
 	lastSent := hub.head.Load()
+	sub.lastSent.Store(lastSent)
 	for {
 		select {
 		case <-ctx.Done():
@@ -166,6 +199,7 @@ func (rp *RequestProcessor) ProcessBlockStream(
 			if lastSent == 0 || head-lastSent > blockStreamMaxBackfill {
 				// Cold start or a gap too large to be a live tail — resync forward.
 				lastSent = head
+				sub.lastSent.Store(head)
 				continue
 			}
 			for n := lastSent + 1; n <= head; n++ {
@@ -182,7 +216,9 @@ func (rp *RequestProcessor) ProcessBlockStream(
 					return err
 				}
 				lastSent = n
+				sub.lastSent.Store(n)
 			}
+			hub.reportLag()
 		}
 	}
 }
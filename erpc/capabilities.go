@@ -0,0 +1,135 @@
+package erpc
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+)
+
+// evmFilterSubscriptions maps the subscription types clients know from
+// eth_subscribe to the filter method that offers the same stream over HTTP
+// polling, which is how eRPC serves them.
+var evmFilterSubscriptions = []struct {
+	kind   string
+	method string
+}{
+	{"logs", "eth_newFilter"},
+	{"newHeads", "eth_newBlockFilter"},
+	{"newPendingTransactions", "eth_newPendingTransactionFilter"},
+}
+
+// HandleCapabilitiesRequest serves erpc_capabilities on a network endpoint.
+// It returns handled=false when capabilities are not enabled for the project
+// or the method is a different one, so the request is forwarded as usual.
+func (p *PreparedProject) HandleCapabilitiesRequest(ctx context.Context, nw *Network, nq *common.NormalizedRequest, method string) (resp *common.NormalizedResponse, handled bool, err error) {
+	if method != "erpc_capabilities" || p.Config.Capabilities == nil || !p.Config.Capabilities.Enabled {
+		return nil, false, nil
+	}
+	resp, err = makeSelectionResponse(nq, p.networkCapabilities(ctx, nw, nq.User()))
+	return resp, true, err
+}
+
+func (p *PreparedProject) networkCapabilities(ctx context.Context, nw *Network, user *common.User) map[string]interface{} {
+	ups := nw.upstreamsRegistry.GetNetworkUpstreams(ctx, nw.networkId)
+
+	perUpstream := make([]map[string]bool, 0, len(ups))
+	for _, u := range ups {
+		perUpstream = append(perUpstream, u.MethodSupport())
+	}
+	supportedList, unsupportedList := mergeMethodSupport(perUpstream)
+
+	subscriptions := []string{}
+	if nw.Architecture() == common.ArchitectureEvm {
+		for _, fs := range evmFilterSubscriptions {
+			for _, u := range ups {
+				if ok, err := u.ShouldHandleMethod(fs.method); err == nil && ok {
+					subscriptions = append(subscriptions, fs.kind)
+					break
+				}
+			}
+		}
+		// Decoded logs are polled with erpc_getDecodedFilterChanges on a
+		// regular logs filter.
+		if reg := nw.EvmAbiRegistry(); reg != nil && slices.Contains(subscriptions, "logs") && reg.DecodedLogsEnabled() {
+			subscriptions = append(subscriptions, "decodedLogs")
+		}
+		if nw.EvmAddressActivity() != nil {
+			subscriptions = append(subscriptions, "addressActivity")
+		}
+	}
+
+	policies := []map[string]interface{}{}
+	if cache, ok := nw.cacheDal.(*evm.EvmJsonRpcCache); ok && cache != nil {
+		for _, cp := range cache.PoliciesForNetwork(nw.networkId) {
+			cfg := cp.Config()
+			appliesTo := cfg.AppliesTo
+			if appliesTo == "" {
+				appliesTo = common.CachePolicyAppliesToBoth
+			}
+			policies = append(policies, map[string]interface{}{
+				"method":    cfg.Method,
+				"finality":  cfg.Finality.String(),
+				"ttl":       cp.GetTTL().String(),
+				"empty":     cfg.Empty.String(),
+				"appliesTo": appliesTo,
+			})
+		}
+	}
+
+	rateLimits := []map[string]interface{}{}
+	scopes := [][2]string{{"network", nw.cfg.RateLimitBudget}, {"project", p.Config.RateLimitBudget}}
+	if user != nil {
+		scopes = append([][2]string{{"consumer", user.RateLimitBudget}}, scopes...)
+	}
+	for _, s := range scopes {
+		if b := p.describeRateLimitBudget(s[0], s[1]); b != nil {
+			rateLimits = append(rateLimits, b)
+		}
+	}
+
+	return map[string]interface{}{
+		"networkId":    nw.networkId,
+		"architecture": nw.Architecture(),
+		"upstreams":    len(ups),
+		"methods": map[string]interface{}{
+			"supported":   supportedList,
+			"unsupported": unsupportedList,
+		},
+		"cache": map[string]interface{}{
+			"enabled":  len(policies) > 0,
+			"policies": policies,
+		},
+		"subscriptions": map[string]interface{}{
+			"transport": "filters",
+			"types":     subscriptions,
+		},
+		"rateLimits": rateLimits,
+	}
+}
+
+// mergeMethodSupport combines what each upstream reported: a method is
+// supported when at least one upstream handles it. Support is only known for
+// methods that have been requested at least once, so both lists grow as
+// traffic flows through the network.
+func mergeMethodSupport(perUpstream []map[string]bool) (supported, unsupported []string) {
+	merged := map[string]bool{}
+	for _, ms := range perUpstream {
+		for m, ok := range ms {
+			merged[m] = merged[m] || ok
+		}
+	}
+	supported, unsupported = []string{}, []string{}
+	for m, ok := range merged {
+		if ok {
+			supported = append(supported, m)
+		} else {
+			unsupported = append(unsupported, m)
+		}
+	}
+	sort.Strings(supported)
+	sort.Strings(unsupported)
+	return supported, unsupported
+}
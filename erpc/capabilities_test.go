@@ -0,0 +1,105 @@
+package erpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/upstream"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeMethodSupport(t *testing.T) {
+	supported, unsupported := mergeMethodSupport([]map[string]bool{
+		{"eth_call": true, "trace_block": false, "debug_traceTransaction": false},
+		{"eth_call": true, "trace_block": true},
+	})
+	assert.Equal(t, []string{"eth_call", "trace_block"}, supported, "one supporting upstream is enough")
+	assert.Equal(t, []string{"debug_traceTransaction"}, unsupported)
+
+	supported, unsupported = mergeMethodSupport(nil)
+	assert.Empty(t, supported)
+	assert.NotNil(t, unsupported, "empty lists are reported as [] rather than null")
+}
+
+func TestPreparedProject_HandleCapabilitiesRequest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rlr, err := upstream.NewRateLimitersRegistry(ctx, &common.RateLimiterConfig{
+		Budgets: []*common.RateLimitBudgetConfig{
+			{
+				Id: "tenant-basic",
+				Rules: []*common.RateLimitRuleConfig{
+					{Method: "*", MaxCount: 100, Period: common.RateLimitPeriodSecond, PerUser: true},
+				},
+			},
+			{
+				Id: "network-wide",
+				Rules: []*common.RateLimitRuleConfig{
+					{Method: "eth_getLogs", MaxCount: 10, Period: common.RateLimitPeriodSecond},
+				},
+			},
+		},
+	}, &log.Logger)
+	require.NoError(t, err)
+
+	network := createTestNetworkWithSelectionPolicy(t, ctx)
+	network.cfg.RateLimitBudget = "network-wide"
+
+	matching, err := data.NewCachePolicy(&common.CachePolicyConfig{Network: "evm:*", Method: "eth_getBlockByNumber", Finality: common.DataFinalityStateFinalized}, nil)
+	require.NoError(t, err)
+	other, err := data.NewCachePolicy(&common.CachePolicyConfig{Network: "evm:1", Method: "*", Finality: common.DataFinalityStateRealtime}, nil)
+	require.NoError(t, err)
+	cache := &evm.EvmJsonRpcCache{}
+	cache.SetPolicies([]*data.CachePolicy{matching, other})
+	network.cacheDal = cache
+
+	pp := &PreparedProject{
+		Config:               &common.ProjectConfig{Id: "test", Capabilities: &common.CapabilitiesConfig{Enabled: true}},
+		rateLimitersRegistry: rlr,
+	}
+	user := &common.User{Id: "alice", RateLimitBudget: "tenant-basic"}
+
+	t.Run("DisabledProjectPassesThrough", func(t *testing.T) {
+		disabled := &PreparedProject{Config: &common.ProjectConfig{Id: "test"}}
+		_, handled, _ := disabled.HandleCapabilitiesRequest(ctx, network, newSelfServiceTestRequest(t, "erpc_capabilities", user), "erpc_capabilities")
+		assert.False(t, handled)
+	})
+
+	t.Run("IgnoresRegularMethods", func(t *testing.T) {
+		_, handled, err := pp.HandleCapabilitiesRequest(ctx, network, newSelfServiceTestRequest(t, "eth_call", user), "eth_call")
+		assert.False(t, handled)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ReportsNetworkMatrix", func(t *testing.T) {
+		resp, handled, err := pp.HandleCapabilitiesRequest(ctx, network, newSelfServiceTestRequest(t, "erpc_capabilities", user), "erpc_capabilities")
+		require.True(t, handled)
+		require.NoError(t, err)
+		result := selfServiceResult(t, resp)
+
+		assert.Equal(t, "evm:123", result["networkId"])
+
+		cacheInfo := result["cache"].(map[string]interface{})
+		assert.Equal(t, true, cacheInfo["enabled"])
+		policies := cacheInfo["policies"].([]interface{})
+		require.Len(t, policies, 1, "policies of other networks are not reported")
+		assert.Equal(t, "eth_getBlockByNumber", policies[0].(map[string]interface{})["method"])
+		assert.Equal(t, "both", policies[0].(map[string]interface{})["appliesTo"])
+
+		rateLimits := result["rateLimits"].([]interface{})
+		require.Len(t, rateLimits, 2)
+		assert.Equal(t, "consumer", rateLimits[0].(map[string]interface{})["scope"])
+		assert.Equal(t, "network", rateLimits[1].(map[string]interface{})["scope"])
+		assert.Equal(t, "network-wide", rateLimits[1].(map[string]interface{})["budget"])
+
+		subs := result["subscriptions"].(map[string]interface{})
+		assert.Equal(t, "filters", subs["transport"])
+		assert.Empty(t, subs["types"], "no upstream, no filter methods")
+	})
+}
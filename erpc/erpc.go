@@ -18,6 +18,12 @@ type ERPC struct {
 	cfg               *common.Config
 	projectsRegistry  *ProjectsRegistry
 	adminAuthRegistry *auth.AuthRegistry
+	backfills         *BackfillManager
+	scheduler         *Scheduler
+	alerter           *Alerter
+	idempotency       *IdempotencyStore
+	journal           *RequestJournal
+	analytics         *AnalyticsRecorder
 	logger            *zerolog.Logger
 }
 
@@ -98,16 +104,69 @@ func NewERPC(
 		}
 	}()
 
-	return &ERPC{
+	e := &ERPC{
 		cfg:               cfg,
 		projectsRegistry:  projectRegistry,
 		adminAuthRegistry: adminAuthRegistry,
+		backfills:         NewBackfillManager(logger),
 		logger:            logger,
-	}, nil
+	}
+
+	if cfg.Database != nil && cfg.Database.Idempotency != nil {
+		e.idempotency, err = NewIdempotencyStore(appCtx, logger, cfg.Database.Idempotency)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Database != nil && cfg.Database.Journal != nil {
+		e.journal, err = NewRequestJournal(appCtx, logger, cfg.Database.Journal)
+		if err != nil {
+			return nil, err
+		}
+		e.journal.Start(appCtx, func(ctx context.Context, projectId, networkId string, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+			prj, err := e.GetProject(projectId)
+			if err != nil {
+				return nil, err
+			}
+			return prj.Forward(ctx, networkId, nq)
+		})
+	}
+
+	if cfg.Database != nil && cfg.Database.Analytics != nil {
+		e.analytics, err = NewAnalyticsRecorder(appCtx, logger, cfg.Database.Analytics)
+		if err != nil {
+			return nil, err
+		}
+		e.analytics.Start(appCtx)
+		// Self-service usage reads the rollups of its own project.
+		for _, prj := range projectRegistry.GetAll() {
+			prj.analytics = e.analytics
+		}
+	}
+
+	if cfg.Scheduler != nil {
+		e.scheduler, err = NewScheduler(logger, e, sharedState, cfg.Scheduler)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Alerting != nil && len(cfg.Alerting.Rules) > 0 {
+		e.alerter = NewAlerter(logger, cfg.Alerting)
+	}
+
+	return e, nil
 }
 
 func (e *ERPC) Bootstrap(ctx context.Context) {
 	e.projectsRegistry.Bootstrap(ctx)
+	if e.scheduler != nil {
+		e.scheduler.Start(ctx)
+	}
+	if e.alerter != nil {
+		e.alerter.Start(ctx)
+	}
 }
 
 func (e *ERPC) GetNetwork(ctx context.Context, projectId string, networkId string) (*Network, error) {
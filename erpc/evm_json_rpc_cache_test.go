@@ -183,6 +183,47 @@ func TestEvmJsonRpcCache_Set_UseUpstreamGating(t *testing.T) {
 	mockConnectors[1].AssertCalled(t, "Set", mock.Anything, "evm:123:2", mock.Anything, mock.Anything, mock.Anything)
 }
 
+func TestEvmJsonRpcCache_WithResidency(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockConnectors, mockNetwork, mockUpstreams, cache := createCacheTestFixtures(ctx, []upsTestCfg{{id: "upsA", syncing: common.EvmSyncingStateUnknown, finBn: 10, lstBn: 15}})
+
+	// connector[0] stores in the EU, connector[1] is an unlabelled global cache.
+	euPolicy, err := data.NewCachePolicy(&common.CachePolicyConfig{
+		Network: "evm:123",
+		Method:  "eth_getBlockByNumber",
+	}, mockConnectors[0])
+	require.NoError(t, err)
+	euPolicy.SetConnectorResidency("eu")
+
+	globalPolicy, err := data.NewCachePolicy(&common.CachePolicyConfig{
+		Network: "evm:123",
+		Method:  "eth_getBlockByNumber",
+	}, mockConnectors[1])
+	require.NoError(t, err)
+
+	cache.SetPolicies([]*data.CachePolicy{euPolicy, globalPolicy})
+	euCache := cache.WithProjectId("eu-project").WithResidency("eu")
+	require.Len(t, euCache.Connectors(), 1)
+	require.Len(t, cache.Connectors(), 2, "the shared cache keeps every policy")
+
+	req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x2",false],"id":1}`))
+	req.SetNetwork(mockNetwork)
+	req.SetCacheDal(euCache)
+	resp := common.NewNormalizedResponse().WithRequest(req).WithBody(stringToReaderCloser(`{"result":{"hash":"0xabc","number":"0x2"}}`))
+	resp.SetUpstream(mockUpstreams[0])
+	req.SetLastValidResponse(ctx, resp)
+
+	mockConnectors[0].On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockConnectors[1].On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	require.NoError(t, euCache.Set(context.Background(), req, resp))
+	mockConnectors[0].AssertCalled(t, "Set", mock.Anything, "evm:123:2", mock.Anything, mock.Anything, mock.Anything)
+	mockConnectors[1].AssertNotCalled(t, "Set")
+
+	require.Empty(t, cache.WithResidency("us").Connectors())
+}
+
 func TestEvmJsonRpcCache_Set(t *testing.T) {
 	t.Run("DoNotCacheWhenEthGetTransactionByHashMissingBlockNumber", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -2789,6 +2830,61 @@ func TestEvmJsonRpcCache_Compression(t *testing.T) {
 		assert.Contains(t, jrr.GetResultString(), largeData)
 	})
 
+	t.Run("CompressionWithIntegrity_RoundTrip", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mockConnectors, mockNetwork, mockUpstreams, _ := createCacheTestFixturesWithCompression(ctx,
+			[]upsTestCfg{{id: "upsA", syncing: common.EvmSyncingStateNotSyncing, finBn: 10, lstBn: 15}},
+			nil)
+		logger := log.Logger
+		cacheCfg := &common.CacheConfig{
+			Compression: &common.CompressionConfig{Enabled: util.BoolPtr(true), Threshold: 100},
+			Integrity:   &common.CacheIntegrityConfig{Enabled: util.BoolPtr(true)},
+		}
+		cacheCfg.SetDefaults()
+		cache, err := evm.NewEvmJsonRpcCache(ctx, &logger, cacheCfg)
+		require.NoError(t, err)
+
+		largeData := strings.Repeat("abcdefghijklmnopqrstuvwxyz", 100)
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x5",false],"id":1}`))
+		req.SetNetwork(mockNetwork)
+		req.SetCacheDal(cache)
+		resp := common.NewNormalizedResponse().
+			WithRequest(req).
+			WithBody(stringToReaderCloser(`{"result":{"data":"` + largeData + `"}}`))
+		resp.SetUpstream(mockUpstreams[0])
+		req.SetLastValidResponse(ctx, resp)
+
+		policy, err := data.NewCachePolicy(&common.CachePolicyConfig{
+			Network:  "evm:123",
+			Method:   "eth_getBlockByNumber",
+			Finality: common.DataFinalityStateFinalized,
+		}, mockConnectors[0])
+		require.NoError(t, err)
+		cache.SetPolicies([]*data.CachePolicy{policy})
+
+		var storedValue []byte
+		mockConnectors[0].On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				storedValue = args.Get(3).([]byte)
+			}).Return(nil)
+		require.NoError(t, cache.Set(ctx, req, resp))
+
+		// Integrity header wraps the compressed frame
+		require.Greater(t, len(storedValue), 12)
+		assert.Equal(t, []byte{0x28, 0xB5, 0x2F, 0xFD}, storedValue[8:12])
+
+		mockConnectors[0].On("Get", mock.Anything, mock.Anything, "evm:123:5", mock.Anything, mock.Anything).
+			Return(storedValue, nil)
+		cachedResp, err := cache.Get(ctx, req)
+		require.NoError(t, err)
+		require.NotNil(t, cachedResp)
+		jrr, err := cachedResp.JsonRpcResponse()
+		require.NoError(t, err)
+		assert.Contains(t, jrr.GetResultString(), largeData)
+	})
+
 	t.Run("CompressionThreshold_BelowThreshold", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -150,6 +150,7 @@ func (gs *GrpcServer) Start(logger *zerolog.Logger) error {
 	if err != nil {
 		return fmt.Errorf("gRPC: failed to listen on %s: %w", addr, err)
 	}
+	lis = withProxyProtocol(lis, gs.serverCfg.ProxyProtocol)
 	logger.Info().Str("addr", addr).Msg("starting gRPC server")
 	go func() {
 		<-gs.appCtx.Done()
@@ -13,14 +13,24 @@ import (
 	"github.com/bytedance/sonic"
 	"github.com/erpc/erpc/auth"
 	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
 	"github.com/erpc/erpc/health"
 	"github.com/erpc/erpc/upstream"
 )
 
 type HealthCheckResponse struct {
-	Status  string         `json:"status"`
-	Message string         `json:"message,omitempty"`
-	Details map[string]any `json:"details,omitempty"`
+	Status     string                 `json:"status"`
+	Message    string                 `json:"message,omitempty"`
+	Details    map[string]any         `json:"details,omitempty"`
+	Connectors []*ConnectorHealthData `json:"connectors,omitempty"`
+}
+
+// ConnectorHealthData is the state of a cache connector. A healthy connector
+// whose ping fails is reported as degraded, with the error as message.
+type ConnectorHealthData struct {
+	ConnectorId string              `json:"connectorId"`
+	State       data.ConnectorState `json:"state"`
+	Message     string              `json:"message,omitempty"`
 }
 
 // Unified health data structures for all modes
@@ -328,6 +338,19 @@ func (s *HttpServer) handleHealthCheck(
 		projectsHealth = append(projectsHealth, projectHealth)
 	}
 
+	// The cache is shared by all projects, so it counts for every scope.
+	var connectorsHealth []*ConnectorHealthData
+	if s.healthCheckCfg != nil && (s.healthCheckCfg.FailOnCacheUnavailable || s.healthCheckCfg.Mode == common.HealthCheckModeVerbose) {
+		connectorsHealth = s.gatherConnectorsHealth(ctx)
+		if s.healthCheckCfg.FailOnCacheUnavailable {
+			for _, ch := range connectorsHealth {
+				if ch.State != data.ConnectorStateHealthy {
+					allHealthy = false
+				}
+			}
+		}
+	}
+
 	// Format and return response based on mode
 	statusCode := http.StatusOK
 	if !allHealthy {
@@ -341,6 +364,10 @@ func (s *HttpServer) handleHealthCheck(
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write([]byte("OK"))
 		} else {
+			details := s.formatHealthDataForMode(projectsHealth, "simple").(map[string]any)
+			if len(connectorsHealth) > 0 {
+				details["cacheConnectors"] = connectorsHealth
+			}
 			w.WriteHeader(http.StatusBadGateway)
 			handleErrorResponse(
 				ctx,
@@ -350,7 +377,7 @@ func (s *HttpServer) handleHealthCheck(
 				&common.BaseError{
 					Code:    "ErrHealthCheckFailed",
 					Message: "one or more health checks failed",
-					Details: s.formatHealthDataForMode(projectsHealth, "simple").(map[string]any),
+					Details: details,
 				},
 				w,
 				encoder,
@@ -387,7 +414,8 @@ func (s *HttpServer) handleHealthCheck(
 					return "one or more health checks failed"
 				}
 			}(),
-			Details: s.formatHealthDataForMode(projectsHealth, "verbose").(map[string]any),
+			Details:    s.formatHealthDataForMode(projectsHealth, "verbose").(map[string]any),
+			Connectors: connectorsHealth,
 		}
 	}
 
@@ -396,6 +424,36 @@ func (s *HttpServer) handleHealthCheck(
 	}
 }
 
+// gatherConnectorsHealth reports the state of every database.evmJsonRpcCache
+// connector, pinging the healthy ones so a backend lost since the last
+// operation is noticed.
+func (s *HttpServer) gatherConnectorsHealth(ctx context.Context) []*ConnectorHealthData {
+	if s.erpc.projectsRegistry == nil || s.erpc.projectsRegistry.evmJsonRpcCache == nil {
+		return nil
+	}
+	connectors := s.erpc.projectsRegistry.evmJsonRpcCache.Connectors()
+	results := make([]*ConnectorHealthData, len(connectors))
+	wg := sync.WaitGroup{}
+	for i, c := range connectors {
+		results[i] = &ConnectorHealthData{ConnectorId: c.Id(), State: c.State()}
+		if results[i].State != data.ConnectorStateHealthy {
+			continue
+		}
+		wg.Add(1)
+		go func(ch *ConnectorHealthData, c data.Connector) {
+			defer wg.Done()
+			pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			defer cancel()
+			if err := c.Ping(pingCtx); err != nil {
+				ch.State = data.ConnectorStateDegraded
+				ch.Message = err.Error()
+			}
+		}(results[i], c)
+	}
+	wg.Wait()
+	return results
+}
+
 func (s *HttpServer) isSimpleMode() bool {
 	return s.healthCheckCfg == nil ||
 		s.healthCheckCfg.Mode == "" ||
@@ -524,9 +582,12 @@ func (s *HttpServer) evaluateNetworkHealth(
 		totalInitializedUpstreams := len(networkUpstreams)
 		activeUpstreams := 0
 		cordonedUpstreams := 0
+		drainedUpstreams := 0
 
 		for _, ups := range networkUpstreams {
-			if metricsTracker.IsCordoned(ups, "*") {
+			if ups.DrainState() != nil {
+				drainedUpstreams++
+			} else if metricsTracker.IsCordoned(ups, "*") {
 				cordonedUpstreams++
 			} else {
 				activeUpstreams++
@@ -544,6 +605,9 @@ func (s *HttpServer) evaluateNetworkHealth(
 			return false, "ERROR", fmt.Sprintf("only %d / %d upstreams are initialized (%d active, %d cordoned)", totalInitializedUpstreams, networkStaticUpsCount, activeUpstreams, cordonedUpstreams)
 		} else if activeUpstreams >= totalInitializedUpstreams {
 			return true, "OK", fmt.Sprintf("all %d upstreams are active (initialized and not cordoned)", totalInitializedUpstreams)
+		} else if drainedUpstreams > 0 && activeUpstreams > 0 && activeUpstreams+drainedUpstreams >= totalInitializedUpstreams {
+			// Planned maintenance is not an outage as long as someone is still serving
+			return true, "OK", fmt.Sprintf("%d / %d upstreams are active (%d draining or in maintenance)", activeUpstreams, totalInitializedUpstreams, drainedUpstreams)
 		}
 		return false, "ERROR", fmt.Sprintf("%d / %d upstreams are active (%d cordoned)", activeUpstreams, totalInitializedUpstreams, cordonedUpstreams)
 
@@ -652,9 +716,12 @@ func (s *HttpServer) evaluateProjectHealth(
 		totalInitializedUpstreams := len(filteredUpstreams)
 		activeUpstreams := 0
 		cordonedUpstreams := 0
+		drainedUpstreams := 0
 
 		for _, ups := range filteredUpstreams {
-			if metricsTracker.IsCordoned(ups, "*") {
+			if ups.DrainState() != nil {
+				drainedUpstreams++
+			} else if metricsTracker.IsCordoned(ups, "*") {
 				cordonedUpstreams++
 			} else {
 				activeUpstreams++
@@ -675,6 +742,9 @@ func (s *HttpServer) evaluateProjectHealth(
 			return false, "ERROR", fmt.Sprintf("only %d / %d upstreams are initialized (%d active, %d cordoned)", totalInitializedUpstreams, networkStaticUpsCount, activeUpstreams, cordonedUpstreams)
 		} else if activeUpstreams >= totalInitializedUpstreams {
 			return true, "OK", fmt.Sprintf("all %d upstreams are active (initialized and not cordoned)", totalInitializedUpstreams)
+		} else if drainedUpstreams > 0 && activeUpstreams > 0 && activeUpstreams+drainedUpstreams >= totalInitializedUpstreams {
+			// Planned maintenance is not an outage as long as someone is still serving
+			return true, "OK", fmt.Sprintf("%d / %d upstreams are active (%d draining or in maintenance)", activeUpstreams, totalInitializedUpstreams, drainedUpstreams)
 		}
 		return false, "ERROR", fmt.Sprintf("%d / %d upstreams are active (%d cordoned)", activeUpstreams, totalInitializedUpstreams, cordonedUpstreams)
 
@@ -0,0 +1,138 @@
+package erpc
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erpc/erpc/common"
+)
+
+// Attestations are sent as "v=1;kid=…;ts=…;project=…;network=…;req=…;res=…;sig=…"
+// where ts is the signing time in unix milliseconds, req and res are the hex
+// sha256 of the request body erpc received (after gzip decoding) and of the
+// response body it sent (before compression), and sig is the unpadded
+// base64url ed25519 signature of attestationDomain followed by everything
+// before ";sig=". Verifiers therefore never rebuild the signed message: they
+// split the header at ";sig=" and check the fields of the part that verifies.
+const (
+	attestationVersion = "1"
+	attestationDomain  = "erpc-attestation\n"
+)
+
+// responseAttestor signs responses with the key of server.attestation.
+type responseAttestor struct {
+	key    ed25519.PrivateKey
+	keyId  string
+	header string
+}
+
+func newResponseAttestor(cfg *common.AttestationConfig) (*responseAttestor, error) {
+	if cfg == nil || (cfg.Enabled != nil && !*cfg.Enabled) {
+		return nil, nil
+	}
+	key, err := common.ParseAttestationKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("server.attestation.privateKey: %w", err)
+	}
+	keyId := cfg.KeyId
+	if keyId == "" {
+		sum := sha256.Sum256(key.Public().(ed25519.PublicKey))
+		keyId = hex.EncodeToString(sum[:8])
+	}
+	header := cfg.Header
+	if header == "" {
+		header = "X-ERPC-Attestation"
+	}
+	return &responseAttestor{key: key, keyId: keyId, header: header}, nil
+}
+
+func (a *responseAttestor) publicKey() ed25519.PublicKey {
+	return a.key.Public().(ed25519.PublicKey)
+}
+
+// attest returns the attestation of resp as the answer to a request whose
+// body hashed to requestDigest.
+func (a *responseAttestor) attest(projectId, networkId string, requestDigest []byte, resp *common.NormalizedResponse, now time.Time) (string, error) {
+	h := sha256.New()
+	if _, err := resp.WriteTo(h); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString("v=" + attestationVersion)
+	sb.WriteString(";kid=" + a.keyId)
+	sb.WriteString(";ts=" + strconv.FormatInt(now.UnixMilli(), 10))
+	sb.WriteString(";project=" + url.PathEscape(projectId))
+	sb.WriteString(";network=" + url.PathEscape(networkId))
+	sb.WriteString(";req=" + hex.EncodeToString(requestDigest))
+	sb.WriteString(";res=" + hex.EncodeToString(h.Sum(nil)))
+	statement := sb.String()
+	sig := ed25519.Sign(a.key, []byte(attestationDomain+statement))
+	return statement + ";sig=" + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// ResponseAttestation is a verified attestation header.
+type ResponseAttestation struct {
+	KeyId     string
+	Timestamp time.Time
+	ProjectId string
+	NetworkId string
+}
+
+// VerifyResponseAttestation checks an attestation header against the public
+// key and the exact request and response bodies it should cover.
+func VerifyResponseAttestation(publicKey ed25519.PublicKey, header string, requestBody, responseBody []byte) (*ResponseAttestation, error) {
+	statement, encodedSig, ok := strings.Cut(header, ";sig=")
+	if !ok {
+		return nil, errors.New("attestation has no signature")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return nil, fmt.Errorf("attestation signature is not base64url: %w", err)
+	}
+	if !ed25519.Verify(publicKey, []byte(attestationDomain+statement), sig) {
+		return nil, errors.New("attestation signature does not verify")
+	}
+
+	fields := make(map[string]string, 7)
+	for _, part := range strings.Split(statement, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		fields[k] = v
+	}
+	if fields["v"] != attestationVersion {
+		return nil, fmt.Errorf("unsupported attestation version %q", fields["v"])
+	}
+	reqSum := sha256.Sum256(requestBody)
+	if fields["req"] != hex.EncodeToString(reqSum[:]) {
+		return nil, errors.New("attestation does not cover this request body")
+	}
+	resSum := sha256.Sum256(responseBody)
+	if fields["res"] != hex.EncodeToString(resSum[:]) {
+		return nil, errors.New("attestation does not cover this response body")
+	}
+	ts, err := strconv.ParseInt(fields["ts"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation timestamp: %w", err)
+	}
+	projectId, err := url.PathUnescape(fields["project"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation project: %w", err)
+	}
+	networkId, err := url.PathUnescape(fields["network"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation network: %w", err)
+	}
+	return &ResponseAttestation{
+		KeyId:     fields["kid"],
+		Timestamp: time.UnixMilli(ts),
+		ProjectId: projectId,
+		NetworkId: networkId,
+	}, nil
+}
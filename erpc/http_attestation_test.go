@@ -0,0 +1,108 @@
+package erpc
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAttestationKey(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	seed[0] = 1
+	want := ed25519.NewKeyFromSeed(seed)
+
+	for name, encoded := range map[string]string{
+		"HexSeed":          hex.EncodeToString(seed),
+		"PrefixedHexSeed":  "0x" + hex.EncodeToString(seed),
+		"HexPrivateKey":    hex.EncodeToString(want),
+		"Base64PrivateKey": "AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+	} {
+		t.Run(name, func(t *testing.T) {
+			key, err := common.ParseAttestationKey(encoded)
+			require.NoError(t, err)
+			assert.True(t, want.Equal(key))
+		})
+	}
+
+	_, err := common.ParseAttestationKey("")
+	assert.Error(t, err)
+	_, err = common.ParseAttestationKey("abcd")
+	assert.Error(t, err, "keys of the wrong length are rejected")
+	mismatched := append([]byte{}, want...)
+	mismatched[40] ^= 0xff
+	_, err = common.ParseAttestationKey(hex.EncodeToString(mismatched))
+	assert.Error(t, err, "private keys whose public half does not match are rejected")
+}
+
+func TestHttpServer_Attestation(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	gock.EnableNetworking()
+	gock.NetworkingFilter(func(req *http.Request) bool {
+		return strings.Split(req.URL.Host, ":")[0] == "localhost"
+	})
+	util.SetupMocksForEvmStatePoller()
+
+	gock.New("http://rpc1.localhost").
+		Post("").
+		Persist().
+		Filter(func(r *http.Request) bool {
+			return strings.Contains(util.SafeReadBody(r), "eth_getBalance")
+		}).
+		Reply(200).
+		JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+
+	seed := make([]byte, ed25519.SeedSize)
+	seed[31] = 7
+	publicKey := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+
+	cfg := gzipE2ECfg()
+	cfg.Server.Attestation = &common.AttestationConfig{
+		PrivateKey: hex.EncodeToString(seed),
+		KeyId:      "k1",
+	}
+	sendRequest, _, _, shutdown, _ := createServerTestFixtures(cfg, t)
+	defer shutdown()
+
+	const request = `{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x123","latest"],"id":1}`
+	status, headers, body := sendRequest(request, nil, nil)
+	require.Equal(t, http.StatusOK, status, "body: %s", body)
+	header := headers["X-Erpc-Attestation"]
+	require.NotEmpty(t, header, "single responses must be attested")
+
+	att, err := VerifyResponseAttestation(publicKey, header, []byte(request), []byte(body))
+	require.NoError(t, err)
+	assert.Equal(t, "k1", att.KeyId)
+	assert.Equal(t, "test_project", att.ProjectId)
+	assert.Equal(t, "evm:123", att.NetworkId)
+	assert.WithinDuration(t, time.Now(), att.Timestamp, time.Minute)
+
+	t.Run("OtherRequestDoesNotVerify", func(t *testing.T) {
+		_, err := VerifyResponseAttestation(publicKey, header, []byte(strings.Replace(request, "0x123", "0x124", 1)), []byte(body))
+		assert.ErrorContains(t, err, "request body")
+	})
+
+	t.Run("OtherResponseDoesNotVerify", func(t *testing.T) {
+		_, err := VerifyResponseAttestation(publicKey, header, []byte(request), []byte(strings.Replace(body, "0x1", "0x2", 1)))
+		assert.ErrorContains(t, err, "response body")
+	})
+
+	t.Run("TamperedFieldsDoNotVerify", func(t *testing.T) {
+		_, err := VerifyResponseAttestation(publicKey, strings.Replace(header, "project=test_project", "project=other", 1), []byte(request), []byte(body))
+		assert.ErrorContains(t, err, "signature")
+	})
+
+	t.Run("BatchesAreNotAttested", func(t *testing.T) {
+		_, headers, _ := sendRequest("["+request+"]", nil, nil)
+		assert.Empty(t, headers["X-Erpc-Attestation"])
+	})
+}
@@ -0,0 +1,292 @@
+package erpc
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/erpc/erpc/common"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Only compress responses larger than 1KB to save CPU on small responses
+const compressionThreshold = 1024
+
+// responseEncoder is what the gzip, zstd and brotli writers have in common.
+type responseEncoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// responseEncoderPools reuse encoders across responses; an encoder's window
+// and tables are far more expensive to allocate than to reset.
+var responseEncoderPools = map[common.ContentEncoding]*sync.Pool{
+	common.ContentEncodingGzip: {New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}},
+	common.ContentEncodingZstd: {New: func() any {
+		// One goroutine per encoder: responses are compressed as they stream,
+		// and pooled encoders must not keep idle workers around.
+		enc, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+		return enc
+	}},
+	common.ContentEncodingBrotli: {New: func() any {
+		return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression)
+	}},
+}
+
+// negotiateEncoding returns the offered encoding the client accepts with the
+// highest q-value, ties going to the earliest offered one, or "" when the
+// client accepts none of them.
+func negotiateEncoding(acceptEncoding string, offered []common.ContentEncoding) common.ContentEncoding {
+	accepted := make(map[string]float64)
+	wildcard := 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(param, "=")
+			if ok && strings.EqualFold(strings.TrimSpace(k), "q") {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if name == "*" {
+			wildcard = q
+		} else {
+			accepted[name] = q
+		}
+	}
+
+	var best common.ContentEncoding
+	bestQ := 0.0
+	for _, enc := range offered {
+		q, ok := accepted[string(enc)]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// conditionalCompressWriter wraps ResponseWriter and decides whether to compress
+// once enough body bytes have been observed. Writes (and any explicit status
+// code) are held back until the total body size reaches the threshold, at
+// which point the response is committed to the negotiated encoding; if the
+// handler finishes or flushes below the threshold, the buffered bytes are sent
+// uncompressed.
+//
+// Buffering across writes (instead of deciding on the first write only) is
+// required because JSON-RPC responses are streamed in multiple small writes:
+// JsonRpcResponse.WriteTo emits the ~22-byte envelope prefix first, so a
+// first-write-only decision would permanently disable compression for every
+// JSON-RPC response regardless of total size (see issue #990).
+//
+// WriteHeader is deferred for the same reason: the JSON-RPC path calls
+// WriteHeader before streaming the body, and Content-Encoding must be set
+// before the header block is flushed to the client.
+type conditionalCompressWriter struct {
+	http.ResponseWriter
+	acceptEncoding string
+	cfg            *common.ResponseCompressionConfig
+	encoding       common.ContentEncoding
+	encoder        responseEncoder
+	decided        bool
+	compressing    bool
+	buf            []byte // body bytes buffered while undecided
+	status         int    // deferred status code from WriteHeader, 0 if none
+}
+
+// Compile-time check that conditionalCompressWriter implements http.Flusher
+var _ http.Flusher = (*conditionalCompressWriter)(nil)
+
+// override applies a project's responseCompression on top of the server's.
+// It has no effect once the response has been committed.
+func (w *conditionalCompressWriter) override(cfg *common.ResponseCompressionConfig) {
+	if cfg == nil || w.decided {
+		return
+	}
+	merged := *w.cfg
+	if cfg.Enabled != nil {
+		merged.Enabled = cfg.Enabled
+	}
+	if len(cfg.Encodings) > 0 {
+		merged.Encodings = cfg.Encodings
+	}
+	if cfg.Threshold > 0 {
+		merged.Threshold = cfg.Threshold
+	}
+	w.cfg = &merged
+}
+
+func (w *conditionalCompressWriter) threshold() int {
+	if w.cfg.Threshold > 0 {
+		return w.cfg.Threshold
+	}
+	return compressionThreshold
+}
+
+// WriteHeader defers the status code until the compression decision is made,
+// so Content-Encoding can still be set when compression kicks in.
+func (w *conditionalCompressWriter) WriteHeader(statusCode int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	w.status = statusCode
+}
+
+func (w *conditionalCompressWriter) Write(b []byte) (int, error) {
+	// If we've already decided, just pass through
+	if w.decided {
+		if w.compressing {
+			return w.encoder.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	// The first write resolves the encoding; project overrides are applied
+	// before the body starts.
+	if w.encoding == "" {
+		if w.cfg.Enabled == nil || *w.cfg.Enabled {
+			offered := w.cfg.Encodings
+			if len(offered) == 0 {
+				offered = []common.ContentEncoding{common.ContentEncodingGzip}
+			}
+			w.encoding = negotiateEncoding(w.acceptEncoding, offered)
+		}
+		if w.encoding == "" {
+			if err := w.decide(false); err != nil {
+				return 0, err
+			}
+			return w.ResponseWriter.Write(b)
+		}
+	}
+
+	// Enough cumulative bytes to justify compression: commit and route this
+	// write through the encoder directly (avoids copying large payloads into buf).
+	if len(w.buf)+len(b) >= w.threshold() {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return w.encoder.Write(b)
+	}
+
+	// Still undecided: buffer and wait for more writes.
+	if w.buf == nil {
+		w.buf = make([]byte, 0, w.threshold())
+	}
+	w.buf = append(w.buf, b...)
+	return len(b), nil
+}
+
+// decide commits to compressing or not: it sets the relevant headers, sends
+// the deferred status code, and drains buffered bytes to the chosen sink.
+func (w *conditionalCompressWriter) decide(compress bool) error {
+	w.decided = true
+	w.compressing = compress
+
+	if compress {
+		w.ResponseWriter.Header().Del("Content-Length")
+		w.ResponseWriter.Header().Set("Content-Encoding", string(w.encoding))
+		if ct := w.ResponseWriter.Header().Get("Content-Type"); ct == "" {
+			w.ResponseWriter.Header().Set("Content-Type", "application/json")
+		}
+		w.encoder = responseEncoderPools[w.encoding].Get().(responseEncoder)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	var err error
+	if len(w.buf) > 0 {
+		if compress {
+			_, err = w.encoder.Write(w.buf)
+		} else {
+			_, err = w.ResponseWriter.Write(w.buf)
+		}
+		w.buf = nil
+	}
+	return err
+}
+
+// Flush implements http.Flusher interface to support streaming responses.
+// A flush while undecided means the handler wants the (sub-threshold)
+// buffered bytes on the wire now, so the response commits to passthrough.
+func (w *conditionalCompressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.compressing && w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	// Also flush underlying ResponseWriter if it supports it
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finalizes the response. If the total body stayed below the threshold,
+// the buffered bytes (and any deferred status code) are sent uncompressed.
+func (w *conditionalCompressWriter) Close() error {
+	if !w.decided {
+		return w.decide(false)
+	}
+	if w.compressing && w.encoder != nil {
+		err := w.encoder.Close()
+		// Drop the reference to the response before pooling the encoder.
+		w.encoder.Reset(io.Discard)
+		responseEncoderPools[w.encoding].Put(w.encoder)
+		w.encoder = nil
+		return err
+	}
+	return nil
+}
+
+// compressionHandler compresses responses with the best encoding that both
+// the client (Accept-Encoding) and cfg allow. cfg may be nil for gzip-only
+// defaults; projects can override it per request (see override).
+func compressionHandler(next http.Handler, cfg *common.ResponseCompressionConfig) http.Handler {
+	if cfg == nil {
+		cfg = &common.ResponseCompressionConfig{}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The response representation depends on Accept-Encoding, so caches
+		// must be told regardless of whether this response ends up compressed.
+		w.Header().Set("Vary", "Accept-Encoding")
+
+		acceptEncoding := r.Header.Get("Accept-Encoding")
+		if acceptEncoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Create a conditional response writer that decides once enough
+		// body bytes have been seen (or the response completes/flushes).
+		cw := &conditionalCompressWriter{
+			ResponseWriter: w,
+			acceptEncoding: acceptEncoding,
+			cfg:            cfg,
+		}
+
+		// Call the next handler with our conditional response writer
+		next.ServeHTTP(cw, r)
+
+		// Ensure proper cleanup
+		_ = cw.Close()
+	})
+}
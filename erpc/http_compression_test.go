@@ -0,0 +1,114 @@
+package erpc
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	all := []common.ContentEncoding{common.ContentEncodingBrotli, common.ContentEncodingZstd, common.ContentEncodingGzip}
+	cases := []struct {
+		name           string
+		acceptEncoding string
+		offered        []common.ContentEncoding
+		want           common.ContentEncoding
+	}{
+		{"ServerOrderBreaksTies", "gzip, zstd, br", all, common.ContentEncodingBrotli},
+		{"OnlyOffered", "gzip, br", []common.ContentEncoding{common.ContentEncodingGzip}, common.ContentEncodingGzip},
+		{"HighestQWins", "br;q=0.5, gzip;q=0.9", all, common.ContentEncodingGzip},
+		{"ZeroQRejects", "gzip;q=0", all, ""},
+		{"Wildcard", "*", all, common.ContentEncodingBrotli},
+		{"ExplicitOverridesWildcard", "br;q=0, *;q=0.5", all, common.ContentEncodingZstd},
+		{"IdentityOnly", "identity", all, ""},
+		{"CaseAndSpaces", " GZIP ; Q=1 ", all, common.ContentEncodingGzip},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, negotiateEncoding(tc.acceptEncoding, tc.offered))
+		})
+	}
+}
+
+func TestCompressionHandler(t *testing.T) {
+	payload := strings.Repeat(`{"logIndex":"0x1","data":"0xdeadbeef"},`, 500)
+	request := func(t *testing.T, h http.Handler, acceptEncoding string) (*http.Response, []byte) {
+		t.Helper()
+		srv := httptest.NewServer(h)
+		t.Cleanup(srv.Close)
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+	cfg := &common.ResponseCompressionConfig{
+		Encodings: []common.ContentEncoding{common.ContentEncodingBrotli, common.ContentEncodingZstd, common.ContentEncodingGzip},
+	}
+
+	t.Run("Brotli", func(t *testing.T) {
+		h := compressionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(payload))
+		}), cfg)
+		resp, body := request(t, h, "gzip, br")
+		require.Equal(t, "br", resp.Header.Get("Content-Encoding"))
+		out, err := io.ReadAll(brotli.NewReader(bytes.NewReader(body)))
+		require.NoError(t, err)
+		assert.Equal(t, payload, string(out))
+	})
+
+	t.Run("Zstd", func(t *testing.T) {
+		h := compressionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(payload))
+		}), cfg)
+		// Twice, so the second response reuses a pooled encoder.
+		for range 2 {
+			resp, body := request(t, h, "zstd")
+			require.Equal(t, "zstd", resp.Header.Get("Content-Encoding"))
+			dec, err := zstd.NewReader(bytes.NewReader(body))
+			require.NoError(t, err)
+			out, err := io.ReadAll(dec)
+			dec.Close()
+			require.NoError(t, err)
+			assert.Equal(t, payload, string(out))
+		}
+	})
+
+	t.Run("ProjectOverride", func(t *testing.T) {
+		var projectCfg *common.ResponseCompressionConfig
+		h := compressionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.(*conditionalCompressWriter).override(projectCfg)
+			_, _ = w.Write([]byte(payload))
+		}), &common.ResponseCompressionConfig{Enabled: util.BoolPtr(false)})
+
+		resp, body := request(t, h, "gzip, br")
+		assert.Empty(t, resp.Header.Get("Content-Encoding"), "disabled at server level")
+		assert.Equal(t, payload, string(body))
+
+		projectCfg = &common.ResponseCompressionConfig{
+			Enabled:   util.BoolPtr(true),
+			Encodings: []common.ContentEncoding{common.ContentEncodingBrotli},
+		}
+		resp, _ = request(t, h, "gzip, br")
+		assert.Equal(t, "br", resp.Header.Get("Content-Encoding"), "enabled by the project")
+
+		projectCfg = &common.ResponseCompressionConfig{Threshold: len(payload) + 1}
+		resp, _ = request(t, h, "gzip, br")
+		assert.Empty(t, resp.Header.Get("Content-Encoding"), "below the project threshold")
+	})
+}
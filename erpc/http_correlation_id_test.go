@@ -0,0 +1,69 @@
+package erpc
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpServer_CorrelationId(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	gock.EnableNetworking()
+	gock.NetworkingFilter(func(req *http.Request) bool {
+		return strings.Split(req.URL.Host, ":")[0] == "localhost"
+	})
+	util.SetupMocksForEvmStatePoller()
+
+	gock.New("http://rpc1.localhost").
+		Post("").
+		Times(1).
+		MatchHeader("X-Request-Id", "^client-req-1$").
+		Filter(func(r *http.Request) bool {
+			return strings.Contains(util.SafeReadBody(r), "eth_getBalance")
+		}).
+		Reply(200).
+		JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	gock.New("http://rpc1.localhost").
+		Post("").
+		Persist().
+		Filter(func(r *http.Request) bool {
+			return strings.Contains(util.SafeReadBody(r), "eth_getCode")
+		}).
+		Reply(200).
+		JSON([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`))
+
+	sendRequest, _, _, shutdown, _ := createServerTestFixtures(gzipE2ECfg(), t)
+	defer shutdown()
+
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+	t.Run("ClientIdIsEchoedAndForwarded", func(t *testing.T) {
+		status, headers, body := sendRequest(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x123","latest"],"id":1}`, map[string]string{"X-Request-Id": "client-req-1"}, nil)
+		require.Equal(t, http.StatusOK, status, "body: %s", body)
+		assert.Equal(t, "client-req-1", headers["X-Request-Id"])
+		assert.Contains(t, body, `"result":"0x1"`)
+	})
+
+	t.Run("GeneratedWhenMissing", func(t *testing.T) {
+		_, headers, _ := sendRequest(`{"jsonrpc":"2.0","method":"eth_getCode","params":["0x123","latest"],"id":1}`, nil, nil)
+		assert.Regexp(t, generated, headers["X-Request-Id"])
+	})
+
+	t.Run("InvalidClientIdIsReplaced", func(t *testing.T) {
+		_, headers, _ := sendRequest(`{"jsonrpc":"2.0","method":"eth_getCode","params":["0x123","latest"],"id":1}`, map[string]string{"X-Request-Id": "has space"}, nil)
+		assert.Regexp(t, generated, headers["X-Request-Id"])
+	})
+
+	t.Run("ErrorBodyCarriesId", func(t *testing.T) {
+		_, headers, body := sendRequest(`{"jsonrpc":"2.0","method":"eth_getCode","params":["0x123","latest"],"id":1}`, map[string]string{"X-Request-Id": "client-req-2"}, nil)
+		assert.Equal(t, "client-req-2", headers["X-Request-Id"])
+		assert.Contains(t, body, `"correlationId":"client-req-2"`)
+	})
+}
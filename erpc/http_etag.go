@@ -0,0 +1,55 @@
+package erpc
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/erpc/erpc/common"
+)
+
+// responseETag returns a weak ETag for a successful JSON-RPC response that
+// cannot change (served from cache, or about finalized data), or "" for any
+// other response. Only the result is hashed: the id differs between otherwise
+// identical polls, so the tag is weak and clients answered with 304 must
+// reuse the body they already hold.
+func responseETag(ctx context.Context, resp *common.NormalizedResponse) string {
+	if resp == nil {
+		return ""
+	}
+	if !resp.FromCache() && resp.Finality(ctx) != common.DataFinalityStateFinalized {
+		return ""
+	}
+	jrr, err := resp.JsonRpcResponse(ctx)
+	if err != nil || jrr == nil || jrr.Error != nil || jrr.ResultLength() == 0 {
+		return ""
+	}
+	h := xxhash.New()
+	if _, err := jrr.WriteResultTo(h, false); err != nil {
+		return ""
+	}
+	return `W/"` + strconv.FormatUint(h.Sum64(), 16) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header value lists etag,
+// using the weak comparison RFC 9110 requires for If-None-Match. "*" is not
+// honored: it would turn any POST into a 304 without naming a result.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagEnabled reports whether `server.etag` is on. Off by default.
+func (s *HttpServer) etagEnabled() bool {
+	return s != nil && s.serverCfg != nil && s.serverCfg.ETag != nil && *s.serverCfg.ETag
+}
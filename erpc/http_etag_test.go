@@ -0,0 +1,134 @@
+package erpc
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEtagMatches(t *testing.T) {
+	const etag = `W/"1a2b"`
+	cases := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{"Empty", "", false},
+		{"Exact", `W/"1a2b"`, true},
+		{"StrongFormOfWeakTag", `"1a2b"`, true},
+		{"InList", `"ffff", W/"1a2b"`, true},
+		{"WildcardIsNotHonored", "*", false},
+		{"Different", `W/"ffff"`, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, etagMatches(tc.ifNoneMatch, etag))
+		})
+	}
+}
+
+func TestHttpServer_ETag(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	gock.EnableNetworking()
+	gock.NetworkingFilter(func(req *http.Request) bool {
+		return strings.Split(req.URL.Host, ":")[0] == "localhost"
+	})
+	util.SetupMocksForEvmStatePoller()
+
+	gock.New("http://rpc1.localhost").
+		Post("").
+		Persist().
+		Filter(func(r *http.Request) bool {
+			return strings.Contains(util.SafeReadBody(r), "eth_getBalance")
+		}).
+		Reply(200).
+		JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	gock.New("http://rpc1.localhost").
+		Post("").
+		Persist().
+		Filter(func(r *http.Request) bool {
+			return strings.Contains(util.SafeReadBody(r), "eth_getCode")
+		}).
+		Reply(200).
+		JSON([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`))
+
+	cfg := gzipE2ECfg()
+	cfg.Server.ETag = util.BoolPtr(true)
+	sendRequest, _, _, shutdown, _ := createServerTestFixtures(cfg, t)
+	defer shutdown()
+
+	// Block 0x100 is below the mocked finalized block, so its balance is final.
+	const balance = `{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x123","0x100"],"id":%d}`
+	status, headers, body := sendRequest(fmt.Sprintf(balance, 1), nil, nil)
+	require.Equal(t, http.StatusOK, status, "body: %s", body)
+	etag := headers["Etag"]
+	require.True(t, strings.HasPrefix(etag, `W/"`), "successful responses must carry a weak ETag, got %q", etag)
+
+	t.Run("MatchingIfNoneMatchIsNotModified", func(t *testing.T) {
+		// A different id must not defeat the match: only the result is hashed.
+		status, headers, body := sendRequest(fmt.Sprintf(balance, 2), map[string]string{"If-None-Match": etag}, nil)
+		assert.Equal(t, http.StatusNotModified, status)
+		assert.Equal(t, etag, headers["Etag"])
+		assert.Empty(t, body)
+	})
+
+	t.Run("StaleIfNoneMatchGetsFullBody", func(t *testing.T) {
+		status, headers, body := sendRequest(fmt.Sprintf(balance, 3), map[string]string{"If-None-Match": `W/"0"`}, nil)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, etag, headers["Etag"])
+		assert.Contains(t, body, `"result":"0x1"`)
+	})
+
+	t.Run("WildcardIfNoneMatchGetsFullBody", func(t *testing.T) {
+		status, _, body := sendRequest(fmt.Sprintf(balance, 4), map[string]string{"If-None-Match": "*"}, nil)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `"result":"0x1"`)
+	})
+
+	t.Run("UnfinalizedResultsHaveNoETag", func(t *testing.T) {
+		status, headers, body := sendRequest(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x123","latest"],"id":5}`, map[string]string{"If-None-Match": etag}, nil)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, headers["Etag"])
+		assert.Contains(t, body, `"result":"0x1"`)
+	})
+
+	t.Run("ErrorsHaveNoETag", func(t *testing.T) {
+		_, headers, body := sendRequest(`{"jsonrpc":"2.0","method":"eth_getCode","params":["0x123","latest"],"id":1}`, nil, nil)
+		assert.Contains(t, body, `"error"`)
+		assert.Empty(t, headers["Etag"])
+	})
+}
+
+func TestHttpServer_ETagIsOptIn(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	gock.EnableNetworking()
+	gock.NetworkingFilter(func(req *http.Request) bool {
+		return strings.Split(req.URL.Host, ":")[0] == "localhost"
+	})
+	util.SetupMocksForEvmStatePoller()
+
+	gock.New("http://rpc1.localhost").
+		Post("").
+		Persist().
+		Filter(func(r *http.Request) bool {
+			return strings.Contains(util.SafeReadBody(r), "eth_getBalance")
+		}).
+		Reply(200).
+		JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+
+	sendRequest, _, _, shutdown, _ := createServerTestFixtures(gzipE2ECfg(), t)
+	defer shutdown()
+
+	status, headers, body := sendRequest(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x123","0x100"],"id":1}`, map[string]string{"If-None-Match": "*"}, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, headers["Etag"])
+	assert.Contains(t, body, `"result":"0x1"`)
+}
@@ -80,7 +80,7 @@ func gzipE2ECfg() *common.Config {
 // a TCP socket and drives a real HTTP client, so the response travels the full
 // production path: routing -> upstream client -> NormalizedResponse.WriteTo ->
 // JsonRpcResponse.WriteTo (envelope prefix write, then the large result write)
-// -> gzipHandler/conditionalGzipWriter -> the wire.
+// -> compressionHandler/conditionalCompressWriter -> the wire.
 //
 // Setting Accept-Encoding: gzip explicitly disables Go's transparent
 // decompression, so the test observes the actual Content-Encoding header and
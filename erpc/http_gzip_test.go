@@ -13,12 +13,12 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-// gzipTestClient performs a request against a gzipHandler-wrapped handler and
+// gzipTestClient performs a request against a compressionHandler-wrapped handler and
 // returns the raw (non-auto-decompressed) response.
 func gzipTestRequest(t *testing.T, handler http.HandlerFunc, acceptGzip bool) (*http.Response, []byte) {
 	t.Helper()
 
-	srv := httptest.NewServer(gzipHandler(handler))
+	srv := httptest.NewServer(compressionHandler(handler, nil))
 	t.Cleanup(srv.Close)
 
 	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
@@ -0,0 +1,99 @@
+package erpc
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/erpc/erpc/common"
+)
+
+// sdListenFdsStart is the first file descriptor systemd passes to an
+// activated service (SD_LISTEN_FDS_START).
+const sdListenFdsStart = 3
+
+// listenUnixSocket binds the unix domain socket listener, replacing a stale
+// socket file left behind by a process that did not shut down cleanly.
+func listenUnixSocket(cfg *common.UnixSocketConfig) (net.Listener, error) {
+	if fi, err := os.Lstat(cfg.Path); err == nil {
+		if fi.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("server.unixSocket.path %s exists and is not a socket", cfg.Path)
+		}
+		// A live socket still accepts connections; only remove dead ones.
+		if conn, err := net.Dial("unix", cfg.Path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("server.unixSocket.path %s is in use by another process", cfg.Path)
+		}
+		if err := os.Remove(cfg.Path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale unix socket %s: %w", cfg.Path, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	mode, err := strconv.ParseUint(cfg.Mode, 8, 32)
+	if err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("invalid server.unixSocket.mode %q: %w", cfg.Mode, err)
+	}
+	if err := os.Chmod(cfg.Path, fs.FileMode(mode)); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to chmod unix socket %s: %w", cfg.Path, err)
+	}
+	return ln, nil
+}
+
+// systemdListeners returns the sockets passed by systemd socket activation,
+// or none when the process was not socket-activated. The LISTEN_* variables
+// are cleared so that child processes do not pick the sockets up.
+func systemdListeners() ([]net.Listener, error) {
+	pid, fds, names := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	return activatedListeners(pid, fds, names, sdListenFdsStart)
+}
+
+func activatedListeners(pid, fds, names string, start int) ([]net.Listener, error) {
+	if pid == "" || fds == "" {
+		return nil, nil
+	}
+	if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+		// Sockets meant for another process (e.g. inherited by a wrapper).
+		return nil, nil
+	}
+	count, err := strconv.Atoi(fds)
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", fds)
+	}
+	fdNames := strings.Split(names, ":")
+
+	listeners := make([]net.Listener, 0, count)
+	for i := 0; i < count; i++ {
+		name := "LISTEN_FD_" + strconv.Itoa(start+i)
+		if i < len(fdNames) && fdNames[i] != "" {
+			name = fdNames[i]
+		}
+		f := os.NewFile(uintptr(start+i), name)
+		// FileListener duplicates the descriptor (close-on-exec), so the
+		// inherited one is closed either way.
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("activated socket %s is not a stream listener: %w", name, err)
+		}
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
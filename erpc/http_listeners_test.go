@@ -0,0 +1,129 @@
+package erpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpServer_UnixSocket(t *testing.T) {
+	logger := log.Logger
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sock := filepath.Join(t.TempDir(), "erpc.sock")
+	// Leave a stale socket behind, as a crashed process would.
+	stale, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	cfg := &common.Config{
+		Server: &common.ServerConfig{
+			ListenV4:   util.BoolPtr(false),
+			UnixSocket: &common.UnixSocketConfig{Path: sock},
+		},
+		Projects: []*common.ProjectConfig{{Id: "test_project"}},
+	}
+	require.NoError(t, cfg.SetDefaults(nil))
+	require.NoError(t, cfg.Server.Validate())
+
+	ssr, err := data.NewSharedStateRegistry(ctx, &logger, &common.SharedStateConfig{
+		Connector: &common.ConnectorConfig{Driver: "memory", Memory: &common.MemoryConnectorConfig{MaxItems: 1000, MaxTotalSize: "1MB"}},
+	})
+	require.NoError(t, err)
+	erpcInstance, err := NewERPC(ctx, &logger, ssr, nil, cfg)
+	require.NoError(t, err)
+	httpServer, err := NewHttpServer(ctx, &logger, cfg.Server, cfg.HealthCheck, cfg.Admin, erpcInstance)
+	require.NoError(t, err)
+	require.Nil(t, httpServer.serverV4)
+
+	go func() { _ = httpServer.Start(&logger) }()
+	defer httpServer.serverSocket.Shutdown(context.Background())
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		resp, err = client.Get("http://erpc/healthcheck")
+		return err == nil
+	}, 5*time.Second, 20*time.Millisecond)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.NotEmpty(t, body)
+
+	fi, err := os.Stat(sock)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), fi.Mode().Perm())
+
+	t.Run("SocketInUseIsNotReplaced", func(t *testing.T) {
+		_, err := listenUnixSocket(cfg.Server.UnixSocket)
+		assert.ErrorContains(t, err, "in use")
+	})
+
+	t.Run("RegularFileIsNotReplaced", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "not-a-socket")
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0o600))
+		_, err := listenUnixSocket(&common.UnixSocketConfig{Path: path, Mode: "0660"})
+		assert.ErrorContains(t, err, "not a socket")
+	})
+}
+
+func TestActivatedListeners(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	f, err := ln.(*net.TCPListener).File()
+	require.NoError(t, err)
+	fd := int(f.Fd())
+	pid := strconv.Itoa(os.Getpid())
+
+	t.Run("IgnoredWhenNotActivated", func(t *testing.T) {
+		listeners, err := activatedListeners("", "", "", fd)
+		require.NoError(t, err)
+		assert.Empty(t, listeners)
+		listeners, err = activatedListeners("1", "1", "", fd)
+		require.NoError(t, err)
+		assert.Empty(t, listeners, "sockets for another pid are left alone")
+	})
+
+	t.Run("ServesPassedSocket", func(t *testing.T) {
+		listeners, err := activatedListeners(pid, "1", "http", fd)
+		require.NoError(t, err)
+		require.Len(t, listeners, 1)
+		defer listeners[0].Close()
+
+		srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})}
+		go func() { _ = srv.Serve(listeners[0]) }()
+		defer srv.Close()
+
+		resp, err := http.Get("http://" + ln.Addr().String() + "/")
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "ok", string(body))
+	})
+
+	t.Run("InvalidCount", func(t *testing.T) {
+		_, err := activatedListeners(pid, "x", "", fd)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,64 @@
+package erpc
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpServer_StrictRequestValidation(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	gock.EnableNetworking()
+	gock.NetworkingFilter(func(req *http.Request) bool {
+		return strings.Split(req.URL.Host, ":")[0] == "localhost"
+	})
+	util.SetupMocksForEvmStatePoller()
+
+	gock.New("http://rpc1.localhost").
+		Post("").
+		Persist().
+		Filter(func(r *http.Request) bool {
+			return strings.Contains(util.SafeReadBody(r), "eth_getBalance")
+		}).
+		Reply(200).
+		JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+
+	cfg := gzipE2ECfg()
+	cfg.Server.RequestValidation = &common.RequestValidationConfig{}
+	sendRequest, _, _, shutdown, _ := createServerTestFixtures(cfg, t)
+	defer shutdown()
+
+	t.Run("ValidRequestIsForwarded", func(t *testing.T) {
+		status, _, body := sendRequest(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x00000000219ab540356cBB839Cbe05303d7705Fa","latest"],"id":1}`, nil, nil)
+		require.Equal(t, http.StatusOK, status, "body: %s", body)
+		assert.Contains(t, body, `"result":"0x1"`)
+	})
+
+	t.Run("WrongVersionIsAnInvalidRequest", func(t *testing.T) {
+		status, _, body := sendRequest(`{"jsonrpc":"1.0","method":"eth_getBalance","params":[],"id":7}`, nil, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, body, `"code":-32600`)
+		assert.Contains(t, body, `"id":null`, "the id of an invalid request is not echoed")
+	})
+
+	t.Run("BadParamsAreInvalidParams", func(t *testing.T) {
+		status, _, body := sendRequest(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x123","latest"],"id":7}`, nil, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, body, `"code":-32602`)
+		assert.Contains(t, body, `"id":7`)
+	})
+
+	t.Run("BatchItemsAreValidatedOneByOne", func(t *testing.T) {
+		status, _, body := sendRequest(`[{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x00000000219ab540356cBB839Cbe05303d7705Fa","latest"],"id":1},{"jsonrpc":"2.0","method":"eth_getBalance","id":{"x":1}}]`, nil, nil)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `"result":"0x1"`)
+		assert.Contains(t, body, `"code":-32600`)
+	})
+}
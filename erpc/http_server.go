@@ -2,8 +2,10 @@ package erpc
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -25,7 +27,6 @@ import (
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/telemetry"
 	"github.com/erpc/erpc/util"
-	"github.com/klauspost/compress/gzip"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -33,8 +34,16 @@ import (
 	"golang.org/x/net/http2/h2c"
 )
 
-// Only compress responses larger than 1KB to save CPU on small responses
-const compressionThreshold = 1024
+// connectionIdContextKey carries the id assigned to each accepted connection.
+// It shows up as "connId" in request logs and is what the admin API's
+// connection-targeted log levels match on.
+const connectionIdContextKey common.ContextKey = "connId"
+
+var connectionIdSeq atomic.Uint64
+
+func withConnectionId(ctx context.Context, _ net.Conn) context.Context {
+	return context.WithValue(ctx, connectionIdContextKey, strconv.FormatUint(connectionIdSeq.Add(1), 10))
+}
 
 type HttpServer struct {
 	appCtx                  context.Context
@@ -43,6 +52,7 @@ type HttpServer struct {
 	adminCfg                *common.AdminConfig
 	serverV4                *http.Server
 	serverV6                *http.Server
+	serverSocket            *http.Server // unix socket and systemd-activated listeners
 	sharedGrpcServer        *GrpcServer
 	erpc                    *ERPC
 	logger                  *zerolog.Logger
@@ -53,6 +63,13 @@ type HttpServer struct {
 	trustedForwarderIPs     map[string]struct{}
 	trustedIPHeaders        []string
 	resolvedResponseHeaders map[string]string
+	reqMaxTimeout           time.Duration
+	attestor                *responseAttestor
+
+	// Set by NewManagementServer when those endpoints are only served on
+	// the management listener.
+	hideAdmin       bool
+	hideHealthCheck bool
 }
 
 func NewHttpServer(
@@ -94,6 +111,7 @@ func NewHttpServer(
 		erpc:           erpc,
 		draining:       &draining,
 		gzipPool:       gzipPool,
+		reqMaxTimeout:  reqMaxTimeout,
 	}
 
 	if cfg != nil {
@@ -148,10 +166,25 @@ func NewHttpServer(
 		}
 	}
 
-	h := srv.createRequestHandler()
+	if cfg != nil {
+		attestor, err := newResponseAttestor(cfg.Attestation)
+		if err != nil {
+			return nil, err
+		}
+		if attestor != nil {
+			srv.attestor = attestor
+			logger.Info().
+				Str("keyId", attestor.keyId).
+				Str("publicKey", hex.EncodeToString(attestor.publicKey())).
+				Str("header", attestor.header).
+				Msg("response attestation enabled")
+		}
+	}
+
+	h := srv.createRequestHandler(true)
 
 	if cfg.EnableGzip != nil && *cfg.EnableGzip {
-		h = gzipHandler(h)
+		h = compressionHandler(h, cfg.ResponseCompression)
 	}
 
 	// Create handler with timeout
@@ -181,6 +214,7 @@ func NewHttpServer(
 	if cfg.ListenV4 != nil && *cfg.ListenV4 {
 		srv.serverV4 = &http.Server{
 			Handler:        handlerV4,
+			ConnContext:    withConnectionId,
 			ReadTimeout:    readTimeout,
 			WriteTimeout:   writeTimeout,
 			IdleTimeout:    300 * time.Second,
@@ -192,6 +226,19 @@ func NewHttpServer(
 	if cfg.ListenV6 != nil && *cfg.ListenV6 {
 		srv.serverV6 = &http.Server{
 			Handler:        handlerV6,
+			ConnContext:    withConnectionId,
+			ReadTimeout:    readTimeout,
+			WriteTimeout:   writeTimeout,
+			IdleTimeout:    300 * time.Second,
+			MaxHeaderBytes: 1 << 20, // 1MB
+		}
+	}
+
+	// Unix socket and socket-activated listeners share one server
+	if cfg.UnixSocket != nil || (cfg.SocketActivation != nil && *cfg.SocketActivation) {
+		srv.serverSocket = &http.Server{
+			Handler:        handlerV4,
+			ConnContext:    withConnectionId,
 			ReadTimeout:    readTimeout,
 			WriteTimeout:   writeTimeout,
 			IdleTimeout:    300 * time.Second,
@@ -222,6 +269,9 @@ func NewHttpServer(
 		if srv.serverV6 != nil {
 			srv.serverV6.SetKeepAlivesEnabled(false)
 		}
+		if srv.serverSocket != nil {
+			srv.serverSocket.SetKeepAlivesEnabled(false)
+		}
 		// wait for readiness probe to mark the pod NotReady
 		// ideally (period_seconds * failure_threshold) + safety margin (1s)
 		if srv.serverCfg.WaitBeforeShutdown != nil {
@@ -237,7 +287,10 @@ func NewHttpServer(
 	return srv, nil
 }
 
-func (s *HttpServer) createRequestHandler() http.Handler {
+// createRequestHandler builds the handler for RPC, admin and healthcheck
+// requests. The public handler refuses admin and healthcheck requests that
+// were moved to the management listener.
+func (s *HttpServer) createRequestHandler(public bool) http.Handler {
 	handleRequest := func(httpCtx context.Context, r *http.Request, w http.ResponseWriter, writeFatalError func(ctx context.Context, statusCode int, body error)) {
 		startedAt := time.Now()
 		encoder := common.SonicCfg.NewEncoder(w)
@@ -282,6 +335,8 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 			w.Header().Set(key, value)
 		}
 
+		httpCtx = s.applyCorrelationId(httpCtx, r, w)
+
 		projectId, architecture, chainId, isAdmin, isHealthCheck, err = s.parseUrlPath(r, projectId, architecture, chainId)
 		if err != nil {
 			handleErrorResponse(
@@ -299,6 +354,15 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 			return
 		}
 
+		if public && ((isAdmin && s.hideAdmin) || (isHealthCheck && s.hideHealthCheck)) {
+			writeFatalError(httpCtx, http.StatusNotFound, fmt.Errorf("%s is served on the management listener", path.Clean(r.URL.Path)))
+			return
+		}
+		if !public && !isAdmin && !isHealthCheck {
+			writeFatalError(httpCtx, http.StatusNotFound, fmt.Errorf("only healthcheck and admin requests are served on the management listener"))
+			return
+		}
+
 		// Set network in context for force-trace matching in child spans (uses existing parsed values)
 		if !isAdmin && !isHealthCheck && architecture != "" && chainId != "" {
 			httpCtx = common.SetForceTraceNetwork(httpCtx, architecture+":"+chainId)
@@ -318,10 +382,20 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 		}
 
 		var lg zerolog.Logger
+		connId, _ := r.Context().Value(connectionIdContextKey).(string)
 		if isAdmin {
-			lg = s.logger.With().Str("component", "admin").Logger()
+			lg = s.logger.With().Str("component", "admin").Str("connId", connId).Logger().
+				Sample(common.LogLevels.Sampler(common.LogTarget{Kind: common.LogTargetConnection, Id: connId}))
 		} else {
-			lg = s.logger.With().Str("component", "proxy").Str("projectId", projectId).Str("networkId", fmt.Sprintf("%s:%s", architecture, chainId)).Logger()
+			networkId := fmt.Sprintf("%s:%s", architecture, chainId)
+			lg = s.logger.With().Str("component", "proxy").Str("projectId", projectId).Str("networkId", networkId).Str("connId", connId).Logger().
+				Sample(common.LogLevels.Sampler(
+					common.LogTarget{Kind: common.LogTargetNetwork, Id: networkId},
+					common.LogTarget{Kind: common.LogTargetConnection, Id: connId},
+				))
+		}
+		if cid := common.CorrelationIdFromContext(httpCtx); cid != "" {
+			lg = lg.With().Str("correlationId", cid).Logger()
 		}
 
 		if projectId == "" && !isAdmin {
@@ -357,6 +431,10 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 			return
 		}
 
+		if cw, ok := w.(*conditionalCompressWriter); ok && project != nil {
+			cw.override(project.Config.ResponseCompression)
+		}
+
 		if project != nil && project.Config.CORS != nil {
 			if !s.handleCORS(httpCtx, w, r, project.Config.CORS) || r.Method == http.MethodOptions {
 				return
@@ -420,8 +498,13 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 
 		var requests []json.RawMessage
 		isBatch := len(body) > 0 && body[0] == '['
+		var requestDigest []byte
 		if !isBatch {
 			requests = []json.RawMessage{body}
+			if s.attestor != nil {
+				sum := sha256.Sum256(body)
+				requestDigest = sum[:]
+			}
 		} else {
 			err = common.SonicCfg.Unmarshal(body, &requests)
 			if err != nil {
@@ -485,6 +568,27 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 				clientIP := s.resolveRealClientIP(r)
 				nq.SetClientIP(clientIP)
 
+				if rv := s.serverCfg.RequestValidation; rv != nil && rv.Enabled != nil && *rv.Enabled && !isAdmin {
+					if err := common.ValidateJsonRpcRequestStrict(nq.Body(), rv); err != nil {
+						mre := err.(*common.ErrJsonRpcRequestMalformed)
+						telemetry.MetricNetworkRequestMalformedTotal.WithLabelValues(
+							projectId,
+							fmt.Sprintf("%s:%s", architecture, chainId),
+							strconv.Itoa(int(mre.JsonRpcCode())),
+						).Inc()
+						// Only a request rejected for its params has an id
+						// that can be trusted and echoed back; the others are
+						// answered with a null id, as JSON-RPC requires.
+						errReq := nq
+						if mre.JsonRpcCode() != common.JsonRpcErrorInvalidArgument {
+							errReq = nil
+						}
+						responses[index] = processErrorBody(&lg, &startedAt, errReq, err, &common.TRUE)
+						common.EndRequestSpan(requestCtx, nil, responses[index])
+						return
+					}
+				}
+
 				// Validate the raw JSON-RPC payload early
 				if err := nq.Validate(); err != nil {
 					responses[index] = processErrorBody(&lg, &startedAt, nq, err, &common.TRUE)
@@ -566,6 +670,24 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 					return
 				}
 
+				// erpc_ping is answered before auth, rate limiters and routing so
+				// client liveness checks neither consume quota nor skew latency metrics.
+				if project != nil && method == pingMethod {
+					pingNetworkId := ""
+					if architecture != "" && chainId != "" {
+						pingNetworkId = fmt.Sprintf("%s:%s", architecture, chainId)
+					}
+					resp, err := project.HandlePingRequest(requestCtx, pingNetworkId, nq)
+					if err != nil {
+						responses[index] = processErrorBody(&rlg, &startedAt, nq, err, s.serverCfg.IncludeErrorDetails)
+						common.EndRequestSpan(requestCtx, nil, err)
+						return
+					}
+					responses[index] = resp
+					common.EndRequestSpan(requestCtx, resp, nil)
+					return
+				}
+
 				var ap *auth.AuthPayload
 				var err error
 
@@ -590,6 +712,8 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 				} else {
 					user, err := project.AuthenticateConsumer(requestCtx, nq, method, ap)
 					if err != nil {
+						project.RecordConsumerOutcome(nq, method, "", err)
+						s.erpc.analytics.Record(project.Config.Id, "", method, nq, nil, err)
 						responses[index] = processErrorBody(&rlg, &startedAt, nq, err, s.serverCfg.IncludeErrorDetails)
 						common.EndRequestSpan(requestCtx, nil, err)
 						return
@@ -598,6 +722,19 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 						rlg = rlg.With().Str("userId", user.Id).Logger()
 					}
 					nq.SetUser(user)
+
+					// Self-service methods are answered by the project itself, using only
+					// the identity resolved by consumer auth (never the trusted header).
+					if resp, handled, err := project.HandleSelfServiceRequest(requestCtx, nq, method); handled {
+						if err != nil {
+							responses[index] = processErrorBody(&rlg, &startedAt, nq, err, s.serverCfg.IncludeErrorDetails)
+							common.EndRequestSpan(requestCtx, nil, err)
+							return
+						}
+						responses[index] = resp
+						common.EndRequestSpan(requestCtx, resp, nil)
+						return
+					}
 				}
 
 				// Trusted upstream identity: when auth resolved no user and the
@@ -613,7 +750,9 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 
 				if isAdmin {
 					if s.adminCfg != nil {
-						resp, err := s.erpc.AdminHandleRequest(requestCtx, nq)
+						resp, err := s.erpc.idempotency.Do(requestCtx, "admin", "admin", method, idempotencyKeyOf(nq, headers, isBatch), nq, func() (*common.NormalizedResponse, error) {
+							return s.erpc.AdminHandleRequest(requestCtx, nq)
+						})
 						if err != nil {
 							responses[index] = processErrorBody(&rlg, &startedAt, nq, err, &common.TRUE)
 							common.EndRequestSpan(requestCtx, nil, err)
@@ -689,7 +828,24 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 				nq.EnrichFromHttp(headers, queryArgs, uaMode)
 				rlg.Trace().Interface("directives", nq.Directives()).Msgf("applied request directives")
 
-				resp, err := project.Forward(requestCtx, networkId, nq)
+				if resp, handled, err := project.HandleCapabilitiesRequest(requestCtx, nw, nq, method); handled {
+					if err != nil {
+						responses[index] = processErrorBody(&rlg, &startedAt, nq, err, s.serverCfg.IncludeErrorDetails)
+						common.EndRequestSpan(requestCtx, nil, err)
+						return
+					}
+					responses[index] = resp
+					common.EndRequestSpan(requestCtx, resp, nil)
+					return
+				}
+
+				resp, err := s.erpc.idempotency.Do(requestCtx, project.Config.Id, idempotencyScope(project.Config.Id, networkId, nq), method, idempotencyKeyOf(nq, headers, isBatch), nq, func() (*common.NormalizedResponse, error) {
+					return s.erpc.journal.Do(requestCtx, project.Config.Id, networkId, method, nq, func() (*common.NormalizedResponse, error) {
+						return project.Forward(requestCtx, networkId, nq)
+					})
+				})
+				project.RecordConsumerOutcome(nq, method, networkId, err)
+				s.erpc.analytics.Record(project.Config.Id, networkId, method, nq, resp, err)
 				if err != nil {
 					// If an error occurred but a response was produced (e.g., lastValidResponse),
 					// release it now since we are not going to write it.
@@ -708,6 +864,10 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 
 		wg.Wait()
 
+		for _, resp := range responses {
+			setErrorCorrelationId(httpCtx, resp)
+		}
+
 		httpCtx, writeResponseSpan := common.StartDetailSpan(httpCtx, "HttpServer.WriteResponse")
 		defer writeResponseSpan.End()
 
@@ -759,6 +919,27 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 			// Determine HTTP status code - defaults to 200 for JSON-RPC responses,
 			// but transport-level errors (auth, rate limit, etc.) get appropriate status codes
 			statusCode := determineResponseStatusCode(res)
+
+			// Polling clients that already hold this exact result get a
+			// bodiless 304 instead of the full payload.
+			if v, ok := res.(*common.NormalizedResponse); ok && statusCode == http.StatusOK && s.etagEnabled() {
+				if etag := responseETag(httpCtx, v); etag != "" {
+					w.Header().Set("ETag", etag)
+					if etagMatches(r.Header.Get("If-None-Match"), etag) {
+						w.WriteHeader(http.StatusNotModified)
+						go v.Release()
+						common.EnrichHTTPServerSpan(httpCtx, http.StatusNotModified, nil)
+						return
+					}
+				}
+			}
+			if v, ok := res.(*common.NormalizedResponse); ok && s.attestor != nil && requestDigest != nil {
+				if att, err := s.attestor.attest(projectId, fmt.Sprintf("%s:%s", architecture, chainId), requestDigest, v, time.Now()); err != nil {
+					lg.Warn().Err(err).Msg("failed to sign response attestation")
+				} else {
+					w.Header().Set(s.attestor.header, att)
+				}
+			}
 			w.WriteHeader(statusCode)
 
 			switch v := res.(type) {
@@ -1119,6 +1300,52 @@ func (s *HttpServer) executionHeadersMode() common.ExecutionHeadersMode {
 	return *s.serverCfg.ExecutionHeaders
 }
 
+// applyCorrelationId assigns the request its correlation id
+// (server.correlationId): the client's own when it sent a valid one and is
+// trusted, a random one otherwise. The id is echoed in the response and
+// returned in ctx for logs, traces and upstream requests.
+func (s *HttpServer) applyCorrelationId(ctx context.Context, r *http.Request, w http.ResponseWriter) context.Context {
+	if s.serverCfg == nil || s.serverCfg.CorrelationId == nil {
+		return ctx
+	}
+	cfg := s.serverCfg.CorrelationId
+	if cfg.Enabled == nil || !*cfg.Enabled || cfg.Header == "" {
+		return ctx
+	}
+	id := ""
+	if cfg.TrustClient != nil && *cfg.TrustClient {
+		if v := r.Header.Get(cfg.Header); common.IsValidCorrelationId(v) {
+			id = v
+		}
+	}
+	if id == "" {
+		id = common.NewCorrelationId()
+	}
+	upstreamHeader := ""
+	if cfg.ForwardToUpstreams != nil && *cfg.ForwardToUpstreams {
+		upstreamHeader = cfg.Header
+	}
+	w.Header().Set(cfg.Header, id)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.correlation_id", id))
+	return common.WithCorrelationId(ctx, id, upstreamHeader)
+}
+
+// setErrorCorrelationId adds the correlation id of ctx to the error object of
+// a JSON-RPC error response, so it survives clients that only keep bodies.
+func setErrorCorrelationId(ctx context.Context, resp interface{}) {
+	e, ok := resp.(*HttpJsonRpcErrorResponse)
+	if !ok || e == nil {
+		return
+	}
+	errObj, ok := e.Error.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if id := common.CorrelationIdFromContext(ctx); id != "" {
+		errObj["correlationId"] = id
+	}
+}
+
 // setResponseHeaders emits the full X-ERPC-* diagnostic surface for a
 // single response — success OR error. Defensive: any nil piece is
 // silently skipped so headers stay consistent across paths. Called
@@ -1532,7 +1759,7 @@ func determineResponseStatusCode(res interface{}) int {
 	// Transport-level errors get appropriate HTTP status codes
 	switch {
 	// 400 Bad Request - malformed requests
-	case common.HasErrorCode(err, common.ErrCodeInvalidUrlPath, common.ErrCodeJsonRpcRequestUnmarshal, common.ErrCodeInvalidRequest):
+	case common.HasErrorCode(err, common.ErrCodeInvalidUrlPath, common.ErrCodeJsonRpcRequestUnmarshal, common.ErrCodeJsonRpcRequestMalformed, common.ErrCodeInvalidRequest):
 		return http.StatusBadRequest
 	// 401 Unauthorized - authentication failures
 	case common.HasErrorCode(err, common.ErrCodeAuthUnauthorized, common.ErrCodeEndpointUnauthorized):
@@ -1540,6 +1767,9 @@ func determineResponseStatusCode(res interface{}) int {
 	// 404 Not Found - resource not found
 	case common.HasErrorCode(err, common.ErrCodeProjectNotFound, common.ErrCodeNetworkNotFound, common.ErrCodeNetworkNotSupported):
 		return http.StatusNotFound
+	// 409 Conflict - idempotency key reused or still in flight
+	case common.HasErrorCode(err, common.ErrCodeIdempotencyKeyConflict):
+		return http.StatusConflict
 	// 429 Too Many Requests - rate limiting
 	case common.HasErrorCode(err,
 		common.ErrCodeAuthRateLimitRuleExceeded,
@@ -1601,6 +1831,7 @@ func processErrorBody(logger *zerolog.Logger, startedAt *time.Time, nq *common.N
 			common.ErrCodeAuthUnauthorized,
 			common.ErrCodeAuthRateLimitRuleExceeded,
 			common.ErrCodeJsonRpcRequestUnmarshal,
+			common.ErrCodeJsonRpcRequestMalformed,
 			common.ErrCodeProjectNotFound,
 		) {
 			logger.Debug().Err(err).Object("request", nq).Object("response", respMarshaler).Msg("forward request errored with client-side exception")
@@ -1704,6 +1935,7 @@ func handleErrorResponse(
 	mode common.ExecutionHeadersMode,
 ) {
 	resp := processErrorBody(logger, startedAt, nq, err, includeErrorDetails)
+	setErrorCorrelationId(httpCtx, resp)
 	// Transport defaults to 200 for JSON-RPC, with limited exceptions.
 	// Non-200 codes are reserved for transport/infrastructure level issues,
 	// not JSON-RPC application errors (which stay 200 with error in body).
@@ -1718,6 +1950,9 @@ func handleErrorResponse(
 	// 404 Not Found - resource not found at HTTP level
 	case common.HasErrorCode(err, common.ErrCodeProjectNotFound, common.ErrCodeNetworkNotFound, common.ErrCodeNetworkNotSupported):
 		statusCode = http.StatusNotFound
+	// 409 Conflict - idempotency key reused or still in flight
+	case common.HasErrorCode(err, common.ErrCodeIdempotencyKeyConflict):
+		statusCode = http.StatusConflict
 	// 429 Too Many Requests - rate limiting (critical for client retry logic)
 	case common.HasErrorCode(err,
 		common.ErrCodeAuthRateLimitRuleExceeded,
@@ -1754,12 +1989,37 @@ func handleErrorResponse(
 
 func (s *HttpServer) Start(logger *zerolog.Logger) error {
 	// Validate that at least one server is configured
-	if s.serverV4 == nil && s.serverV6 == nil {
-		return fmt.Errorf("you must configure at least one of server.listenV4 or server.listenV6")
+	if s.serverV4 == nil && s.serverV6 == nil && s.serverSocket == nil {
+		return fmt.Errorf("you must configure at least one of server.listenV4, server.listenV6, server.unixSocket or server.socketActivation")
+	}
+
+	var unixListener net.Listener
+	var activatedListeners []net.Listener
+	if s.serverSocket != nil {
+		if s.serverCfg.UnixSocket != nil {
+			var err error
+			unixListener, err = listenUnixSocket(s.serverCfg.UnixSocket)
+			if err != nil {
+				return fmt.Errorf("failed to listen on unix socket: %w", err)
+			}
+		}
+		if s.serverCfg.SocketActivation != nil && *s.serverCfg.SocketActivation {
+			var err error
+			activatedListeners, err = systemdListeners()
+			if err != nil {
+				if unixListener != nil {
+					unixListener.Close()
+				}
+				return fmt.Errorf("failed to use systemd-activated sockets: %w", err)
+			}
+			if len(activatedListeners) == 0 {
+				logger.Warn().Msg("server.socketActivation is enabled but no sockets were passed by systemd (LISTEN_FDS)")
+			}
+		}
 	}
 
 	// Channel to collect errors from server goroutines
-	errChan := make(chan error, 2)
+	errChan := make(chan error, 3+len(activatedListeners))
 	serversStarted := 0
 
 	// Start IPv4 server if configured
@@ -1782,13 +2042,8 @@ func (s *HttpServer) Start(logger *zerolog.Logger) error {
 
 		serversStarted++
 		go func() {
-			var err error
 			s.serverV4.Addr = addrV4
-			if s.serverCfg.TLS != nil && s.serverCfg.TLS.Enabled {
-				err = s.serverV4.ListenAndServeTLS(s.serverCfg.TLS.CertFile, s.serverCfg.TLS.KeyFile)
-			} else {
-				err = s.serverV4.ListenAndServe()
-			}
+			err := s.listenAndServe(s.serverV4)
 			if err != nil && err != http.ErrServerClosed {
 				errChan <- fmt.Errorf("IPv4 server error: %w", err)
 			} else {
@@ -1817,21 +2072,53 @@ func (s *HttpServer) Start(logger *zerolog.Logger) error {
 
 		serversStarted++
 		go func() {
-			var err error
 			s.serverV6.Addr = addrV6
-			if s.serverCfg.TLS != nil && s.serverCfg.TLS.Enabled {
-				err = s.serverV6.ListenAndServeTLS(s.serverCfg.TLS.CertFile, s.serverCfg.TLS.KeyFile)
+			err := s.listenAndServe(s.serverV6)
+			if err != nil && err != http.ErrServerClosed {
+				errChan <- fmt.Errorf("IPv6 server error: %w", err)
 			} else {
-				err = s.serverV6.ListenAndServe()
+				errChan <- nil
+			}
+		}()
+	}
+
+	if unixListener != nil {
+		logger.Info().Msgf("starting HTTP server on unix socket %s", s.serverCfg.UnixSocket.Path)
+		serversStarted++
+		go func() {
+			// Local peers: TLS is never applied on the unix socket
+			if err := s.serverSocket.Serve(unixListener); err != nil && err != http.ErrServerClosed {
+				errChan <- fmt.Errorf("unix socket server error: %w", err)
+			} else {
+				errChan <- nil
 			}
+		}()
+	}
+
+	if len(activatedListeners) > 0 && s.serverCfg.TLS != nil && s.serverCfg.TLS.Enabled {
+		tlsConfig, err := s.createTLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to create TLS config: %w", err)
+		}
+		s.serverSocket.TLSConfig = tlsConfig
+	}
+	for _, ln := range activatedListeners {
+		logger.Info().Msgf("starting HTTP server on systemd-activated socket %s", ln.Addr())
+		serversStarted++
+		go func() {
+			err := s.serve(s.serverSocket, withProxyProtocol(ln, s.serverCfg.ProxyProtocol))
 			if err != nil && err != http.ErrServerClosed {
-				errChan <- fmt.Errorf("IPv6 server error: %w", err)
+				errChan <- fmt.Errorf("activated socket server error: %w", err)
 			} else {
 				errChan <- nil
 			}
 		}()
 	}
 
+	if serversStarted == 0 {
+		return fmt.Errorf("no HTTP listener was started")
+	}
+
 	// Wait for the first error or all servers to finish
 	for i := 0; i < serversStarted; i++ {
 		if err := <-errChan; err != nil {
@@ -1844,20 +2131,26 @@ func (s *HttpServer) Start(logger *zerolog.Logger) error {
 
 // createTLSConfig creates a TLS configuration from server config
 func (s *HttpServer) createTLSConfig() (*tls.Config, error) {
+	return newListenerTLSConfig(s.serverCfg.TLS)
+}
+
+// newListenerTLSConfig builds the TLS configuration of an HTTP listener. A CA
+// file turns on mutual TLS.
+func newListenerTLSConfig(cfg *common.TLSConfig) (*tls.Config, error) {
 	tlsConfig := &tls.Config{
 		MinVersion: tls.VersionTLS12,
 	}
 
 	// Load certificate and key
-	cert, err := tls.LoadX509KeyPair(s.serverCfg.TLS.CertFile, s.serverCfg.TLS.KeyFile)
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load TLS certificate and key: %w", err)
 	}
 	tlsConfig.Certificates = []tls.Certificate{cert}
 
 	// Load CA if specified
-	if s.serverCfg.TLS.CAFile != "" {
-		caCert, err := os.ReadFile(s.serverCfg.TLS.CAFile)
+	if cfg.CAFile != "" {
+		caCert, err := os.ReadFile(cfg.CAFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read CA file: %w", err)
 		}
@@ -1869,7 +2162,7 @@ func (s *HttpServer) createTLSConfig() (*tls.Config, error) {
 		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
 	}
 
-	tlsConfig.InsecureSkipVerify = s.serverCfg.TLS.InsecureSkipVerify
+	tlsConfig.InsecureSkipVerify = cfg.InsecureSkipVerify
 	return tlsConfig, nil
 }
 
@@ -1879,7 +2172,7 @@ func (s *HttpServer) Shutdown(logger *zerolog.Logger) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	errChan := make(chan error, 2)
+	errChan := make(chan error, 3)
 	serversToShutdown := 0
 
 	// Shutdown IPv4 server if running
@@ -1908,6 +2201,19 @@ func (s *HttpServer) Shutdown(logger *zerolog.Logger) error {
 		}()
 	}
 
+	// Shutdown unix socket and socket-activated listeners if running
+	if s.serverSocket != nil {
+		serversToShutdown++
+		go func() {
+			if err := s.serverSocket.Shutdown(ctx); err != nil {
+				errChan <- fmt.Errorf("socket server shutdown error: %w", err)
+			} else {
+				logger.Info().Msg("socket HTTP server stopped")
+				errChan <- nil
+			}
+		}()
+	}
+
 	// Wait for all servers to shutdown
 	var lastErr error
 	for i := 0; i < serversToShutdown; i++ {
@@ -1919,158 +2225,21 @@ func (s *HttpServer) Shutdown(logger *zerolog.Logger) error {
 	return lastErr
 }
 
-// conditionalGzipWriter wraps ResponseWriter and decides whether to compress
-// once enough body bytes have been observed. Writes (and any explicit status
-// code) are held back until the total body size reaches compressionThreshold,
-// at which point the response is committed as gzip; if the handler finishes or
-// flushes below the threshold, the buffered bytes are sent uncompressed.
-//
-// Buffering across writes (instead of deciding on the first write only) is
-// required because JSON-RPC responses are streamed in multiple small writes:
-// JsonRpcResponse.WriteTo emits the ~22-byte envelope prefix first, so a
-// first-write-only decision would permanently disable compression for every
-// JSON-RPC response regardless of total size (see issue #990).
-//
-// WriteHeader is deferred for the same reason: the JSON-RPC path calls
-// WriteHeader before streaming the body, and Content-Encoding must be set
-// before the header block is flushed to the client.
-type conditionalGzipWriter struct {
-	http.ResponseWriter
-	gzipWriter  *gzip.Writer
-	pool        *util.GzipWriterPool
-	decided     bool
-	compressing bool
-	buf         []byte // body bytes buffered while undecided
-	status      int    // deferred status code from WriteHeader, 0 if none
-}
-
-// Compile-time check that conditionalGzipWriter implements http.Flusher
-var _ http.Flusher = (*conditionalGzipWriter)(nil)
-
-// WriteHeader defers the status code until the compression decision is made,
-// so Content-Encoding can still be set when compression kicks in.
-func (w *conditionalGzipWriter) WriteHeader(statusCode int) {
-	if w.decided {
-		w.ResponseWriter.WriteHeader(statusCode)
-		return
-	}
-	w.status = statusCode
-}
-
-func (w *conditionalGzipWriter) Write(b []byte) (int, error) {
-	// If we've already decided, just pass through
-	if w.decided {
-		if w.compressing {
-			return w.gzipWriter.Write(b)
-		}
-		return w.ResponseWriter.Write(b)
-	}
-
-	// Enough cumulative bytes to justify compression: commit and route this
-	// write through gzip directly (avoids copying large payloads into buf).
-	if len(w.buf)+len(b) >= compressionThreshold {
-		if err := w.decide(true); err != nil {
-			return 0, err
-		}
-		return w.gzipWriter.Write(b)
-	}
-
-	// Still undecided: buffer and wait for more writes.
-	if w.buf == nil {
-		w.buf = make([]byte, 0, compressionThreshold)
-	}
-	w.buf = append(w.buf, b...)
-	return len(b), nil
-}
-
-// decide commits to compressing or not: it sets the relevant headers, sends
-// the deferred status code, and drains buffered bytes to the chosen sink.
-func (w *conditionalGzipWriter) decide(compress bool) error {
-	w.decided = true
-	w.compressing = compress
-
-	if compress {
-		w.ResponseWriter.Header().Del("Content-Length")
-		w.ResponseWriter.Header().Set("Content-Encoding", "gzip")
-		if ct := w.ResponseWriter.Header().Get("Content-Type"); ct == "" {
-			w.ResponseWriter.Header().Set("Content-Type", "application/json")
-		}
-		w.gzipWriter = w.pool.Get(w.ResponseWriter)
-	}
-	if w.status != 0 {
-		w.ResponseWriter.WriteHeader(w.status)
-	}
-
-	var err error
-	if len(w.buf) > 0 {
-		if compress {
-			_, err = w.gzipWriter.Write(w.buf)
-		} else {
-			_, err = w.ResponseWriter.Write(w.buf)
-		}
-		w.buf = nil
-	}
-	return err
-}
-
-// Flush implements http.Flusher interface to support streaming responses.
-// A flush while undecided means the handler wants the (sub-threshold)
-// buffered bytes on the wire now, so the response commits to passthrough.
-func (w *conditionalGzipWriter) Flush() {
-	if !w.decided {
-		_ = w.decide(false)
-	}
-	if w.compressing && w.gzipWriter != nil {
-		_ = w.gzipWriter.Flush()
-	}
-	// Also flush underlying ResponseWriter if it supports it
-	if f, ok := w.ResponseWriter.(http.Flusher); ok {
-		f.Flush()
-	}
-}
-
-// Close finalizes the response. If the total body stayed below the threshold,
-// the buffered bytes (and any deferred status code) are sent uncompressed.
-func (w *conditionalGzipWriter) Close() error {
-	if !w.decided {
-		return w.decide(false)
-	}
-	if w.compressing && w.gzipWriter != nil {
-		err := w.gzipWriter.Close()
-		w.pool.Put(w.gzipWriter)
+// listenAndServe is srv.ListenAndServe(TLS) with the listener wrapped for
+// server.proxyProtocol.
+func (s *HttpServer) listenAndServe(srv *http.Server) error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
 		return err
 	}
-	return nil
+	return s.serve(srv, withProxyProtocol(ln, s.serverCfg.ProxyProtocol))
 }
 
-func gzipHandler(next http.Handler) http.Handler {
-	// Pool writers across responses for better performance
-	var gzPool = util.NewGzipWriterPool()
-
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// The response representation depends on Accept-Encoding, so caches
-		// must be told regardless of whether this response ends up compressed.
-		w.Header().Set("Vary", "Accept-Encoding")
-
-		// Check if client accepts gzip encoding
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		// Create conditional gzip response writer that decides once enough
-		// body bytes have been seen (or the response completes/flushes).
-		gzw := &conditionalGzipWriter{
-			ResponseWriter: w,
-			pool:           gzPool,
-		}
-
-		// Call the next handler with our conditional gzip response writer
-		next.ServeHTTP(gzw, r)
-
-		// Ensure proper cleanup
-		_ = gzw.Close()
-	})
+func (s *HttpServer) serve(srv *http.Server, ln net.Listener) error {
+	if s.serverCfg.TLS != nil && s.serverCfg.TLS.Enabled {
+		return srv.ServeTLS(ln, s.serverCfg.TLS.CertFile, s.serverCfg.TLS.KeyFile)
+	}
+	return srv.Serve(ln)
 }
 
 // resolveRealClientIP determines the originating client IP, honoring standard forwarding headers
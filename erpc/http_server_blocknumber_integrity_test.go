@@ -42,9 +42,9 @@ import (
 //	   value never reaches the cache.
 //	B2 read-side bypass: responses with FromCache()==true skip enforcement
 //	   entirely, so a poisoned (or otherwise stale) cache entry is served
-//	   verbatim for a full TTL window. The realtime age guard cannot save
-//	   eth_blockNumber either: its responses carry no block timestamp and
-//	   non-head-aware connectors (memory/redis) fail open.
+//	   verbatim for a full TTL window. The realtime age guard only partly
+//	   helps: eth_blockNumber responses carry no block timestamp, so it ages
+//	   them against the pollers' head, which needs a timestamped head.
 //	B3 selector scope: the hook resolves the tip with a context that does not
 //	   carry the request (common.RequestContextKey is only set inside
 //	   network.Forward), so X-ERPC-Use-Upstream-pinned requests are corrected
@@ -66,6 +66,12 @@ const (
 // eth_getBlockByNumber, so these never collide with user eth_blockNumber
 // traffic.
 func setupBniPollerMocks() {
+	setupBniPollerMocksAt(0x6702a8f0)
+}
+
+// setupBniPollerMocksAt is setupBniPollerMocks with the latest heads stamped
+// at latestTs (unix seconds) and the finalized ones 16 seconds earlier.
+func setupBniPollerMocksAt(latestTs int64) {
 	for _, h := range []struct {
 		host      string
 		latest    string
@@ -92,14 +98,14 @@ func setupBniPollerMocks() {
 				return strings.Contains(b, "eth_getBlockByNumber") && strings.Contains(b, "latest")
 			}).
 			Reply(200).
-			JSON([]byte(fmt.Sprintf(`{"result":{"number":"%s","timestamp":"0x6702a8f0"}}`, h.latest)))
+			JSON([]byte(fmt.Sprintf(`{"result":{"number":"%s","timestamp":"0x%x"}}`, h.latest, latestTs)))
 		gock.New(h.host).Post("").Persist().
 			Filter(func(r *http.Request) bool {
 				b := util.SafeReadBody(r)
 				return strings.Contains(b, "eth_getBlockByNumber") && strings.Contains(b, "finalized")
 			}).
 			Reply(200).
-			JSON([]byte(fmt.Sprintf(`{"result":{"number":"%s","timestamp":"0x6702a8e0"}}`, h.finalized)))
+			JSON([]byte(fmt.Sprintf(`{"result":{"number":"%s","timestamp":"0x%x"}}`, h.finalized, latestTs-16)))
 	}
 }
 
@@ -240,11 +246,17 @@ func bniSend(
 	return n, respHeaders
 }
 
+// bniUngatedNetwork hides the network's chain state, so a probe reads what is
+// stored without the realtime age gate ageing it against the pollers' head.
+type bniUngatedNetwork struct {
+	common.Network
+}
+
 // bniProbeCache reads the eth_blockNumber cache entry (if any) through the
 // network's real cache DAL — the same key the HTTP path reads/writes.
 func bniProbeCache(ntw *Network) (int64, bool) {
 	probe := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":99,"method":"eth_blockNumber","params":[]}`))
-	probe.SetNetwork(ntw)
+	probe.SetNetwork(bniUngatedNetwork{ntw})
 	resp, err := ntw.cacheDal.Get(context.Background(), probe)
 	if err != nil || resp == nil || resp.IsObjectNull() {
 		return 0, false
@@ -411,10 +423,9 @@ func TestHttpServer_EthBlockNumberIntegrity(t *testing.T) {
 		defer shutdown()
 
 		// Simulate a stale value present in the cache regardless of how it
-		// got there (another pod, an earlier window, a race). Note the
-		// realtime age guard cannot reject it: eth_blockNumber responses
-		// carry no block timestamp and the memory connector is not
-		// head-aware, so the guard fails open.
+		// got there (another pod, an earlier window, a race). Whether the
+		// realtime age guard rejects it (the pollers' head is old here) or
+		// it is served as a hit, the client must never see it.
 		bniSeedCache(t, ntw, "0x800")
 
 		got, hdrs := bniSend(t, send, nil, nil)
@@ -423,6 +434,25 @@ func TestHttpServer_EthBlockNumberIntegrity(t *testing.T) {
 			"a cache-hit response below the known tip must be corrected just like a fresh response (FromCache responses currently skip enforcement entirely)")
 	})
 
+	t.Run("StaleCacheEntryBelowFreshHeadIsNotServed", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		setupBniPollerMocksAt(time.Now().Unix())
+		setupBniBlockNumberMocks()
+
+		send, ntw, shutdown := bniBoot(t, bniConfig(true, integrityOff()))
+		defer shutdown()
+
+		// The pollers' head (0x1000) is fresh, but the cached value is far
+		// below it: the realtime age guard must age the entry itself rather
+		// than borrow the head's timestamp.
+		bniSeedCache(t, ntw, "0x800")
+
+		_, hdrs := bniSend(t, send, nil, nil)
+		assert.NotEqual(t, "HIT", hdrs["X-Erpc-Cache"],
+			"a cached block far below a fresh head must be rejected by the realtime age guard")
+	})
+
 	// ── B3: use-upstream pinned requests must use the subset's tip ──────────
 
 	t.Run("Bug_UseUpstreamPinnedRequestMustUseSelectorScopedTip", func(t *testing.T) {
@@ -4490,6 +4490,71 @@ func TestHttpServer_HandleHealthCheck(t *testing.T) {
 			wantStatus:   http.StatusOK,
 			wantBody:     `OK`,
 		},
+		{
+			name: "Unavailable cache connector fails the healthcheck when required",
+			setupServer: func(ctx context.Context) *HttpServer {
+				pp := &PreparedProject{
+					Config:            &common.ProjectConfig{Id: "test", Upstreams: []*common.UpstreamConfig{up1}},
+					upstreamsRegistry: upstream.NewUpstreamsRegistry(ctx, logger, "", []*common.UpstreamConfig{up1}, ssr, nil, vr, pr, nil, mtk, nil),
+				}
+				pp.upstreamsRegistry.Bootstrap(ctx)
+				pp.networksRegistry = NewNetworksRegistry(pp, ctx, pp.upstreamsRegistry, mtk, nil, nil, nil, logger)
+				return &HttpServer{
+					logger: logger,
+					erpc: &ERPC{
+						projectsRegistry: &ProjectsRegistry{
+							preparedProjects: map[string]*PreparedProject{
+								"test": pp,
+							},
+							evmJsonRpcCache: newDegradedTestCache(t, ctx),
+						},
+					},
+					healthCheckCfg: &common.HealthCheckConfig{
+						Mode:                   common.HealthCheckModeSimple,
+						FailOnCacheUnavailable: true,
+					},
+					draining: &atomic.Bool{},
+				}
+			},
+			projectId:    "test",
+			architecture: "",
+			chainId:      "",
+			request:      &http.Request{Method: "GET", URL: &url.URL{Path: "/healthcheck"}},
+			wantStatus:   http.StatusBadGateway,
+			wantBody:     `"state":"degraded"`,
+		},
+		{
+			name: "Unavailable cache connector is only reported by default",
+			setupServer: func(ctx context.Context) *HttpServer {
+				pp := &PreparedProject{
+					Config:            &common.ProjectConfig{Id: "test", Upstreams: []*common.UpstreamConfig{up1}},
+					upstreamsRegistry: upstream.NewUpstreamsRegistry(ctx, logger, "", []*common.UpstreamConfig{up1}, ssr, nil, vr, pr, nil, mtk, nil),
+				}
+				pp.upstreamsRegistry.Bootstrap(ctx)
+				pp.networksRegistry = NewNetworksRegistry(pp, ctx, pp.upstreamsRegistry, mtk, nil, nil, nil, logger)
+				return &HttpServer{
+					logger: logger,
+					erpc: &ERPC{
+						projectsRegistry: &ProjectsRegistry{
+							preparedProjects: map[string]*PreparedProject{
+								"test": pp,
+							},
+							evmJsonRpcCache: newDegradedTestCache(t, ctx),
+						},
+					},
+					healthCheckCfg: &common.HealthCheckConfig{
+						Mode: common.HealthCheckModeVerbose,
+					},
+					draining: &atomic.Bool{},
+				}
+			},
+			projectId:    "test",
+			architecture: "",
+			chainId:      "",
+			request:      &http.Request{Method: "GET", URL: &url.URL{Path: "/healthcheck"}},
+			wantStatus:   http.StatusOK,
+			wantBody:     `"connectors":[{"connectorId":"degraded","state":"degraded"}]`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -4524,6 +4589,26 @@ func TestHttpServer_HandleHealthCheck(t *testing.T) {
 	}
 }
 
+// degradedTestConnector is a memory connector that reports a lost backend.
+type degradedTestConnector struct {
+	*data.MemoryConnector
+}
+
+func (degradedTestConnector) State() data.ConnectorState {
+	return data.ConnectorStateDegraded
+}
+
+func newDegradedTestCache(t *testing.T, ctx context.Context) *evm.EvmJsonRpcCache {
+	t.Helper()
+	mc, err := data.NewMemoryConnector(ctx, &log.Logger, "degraded", &common.MemoryConnectorConfig{MaxItems: 10, MaxTotalSize: "1MB"})
+	require.NoError(t, err)
+	policy, err := data.NewCachePolicy(&common.CachePolicyConfig{Network: "*", Method: "*", Connector: "degraded"}, degradedTestConnector{mc})
+	require.NoError(t, err)
+	cache := &evm.EvmJsonRpcCache{}
+	cache.SetPolicies([]*data.CachePolicy{policy})
+	return cache
+}
+
 func TestHttpServer_ProviderBasedUpstreams(t *testing.T) {
 	t.Run("SimpleCallExistingNetwork", func(t *testing.T) {
 		cfg := &common.Config{
@@ -0,0 +1,227 @@
+package erpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+)
+
+const (
+	headerIdempotencyKey     = "Idempotency-Key"
+	idempotencyKeyBodyField  = "idempotencyKey"
+	idempotencyUnlockTimeout = 5 * time.Second
+)
+
+// IdempotencyStore deduplicates retried write requests by their idempotency
+// key. The first request holding a key runs and its successful response is
+// stored; retries within the TTL are answered with the stored response
+// instead of running again, on whichever replica they land.
+type IdempotencyStore struct {
+	logger    *zerolog.Logger
+	connector data.Connector
+	ttl       time.Duration
+	lockTtl   time.Duration
+	methods   []string
+}
+
+type idempotencyRecord struct {
+	RequestHash string          `json:"h"`
+	Result      json.RawMessage `json:"r"`
+}
+
+func NewIdempotencyStore(ctx context.Context, logger *zerolog.Logger, cfg *common.IdempotencyConfig) (*IdempotencyStore, error) {
+	connector, err := data.NewConnector(ctx, logger, cfg.Connector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create idempotency connector: %w", err)
+	}
+	lg := logger.With().Str("component", "idempotency").Logger()
+	return &IdempotencyStore{
+		logger:    &lg,
+		connector: connector,
+		ttl:       cfg.Ttl.Duration(),
+		lockTtl:   cfg.LockTtl.Duration(),
+		methods:   cfg.Methods,
+	}, nil
+}
+
+// idempotencyKeyOf returns the request's idempotency key: the
+// "idempotencyKey" field of the JSON-RPC request, else the Idempotency-Key
+// header. Items of a batch share the headers, so they only honor the field.
+func idempotencyKeyOf(nq *common.NormalizedRequest, headers http.Header, isBatch bool) string {
+	if body := nq.Body(); len(body) > 0 {
+		if node, err := sonic.Get(body, idempotencyKeyBodyField); err == nil {
+			if key, err := node.String(); err == nil && key != "" {
+				return key
+			}
+		}
+	}
+	if isBatch {
+		return ""
+	}
+	return strings.TrimSpace(headers.Get(headerIdempotencyKey))
+}
+
+// idempotencyScope separates key spaces per project, network and user, so
+// two clients picking the same key never see each other's responses.
+func idempotencyScope(projectId, networkId string, nq *common.NormalizedRequest) string {
+	scope := projectId + "/" + networkId
+	if user := nq.User(); user != nil {
+		scope += "/" + user.Id
+	}
+	return scope
+}
+
+func (s *IdempotencyStore) appliesTo(method string) bool {
+	for _, m := range s.methods {
+		if ok, _ := common.WildcardMatch(m, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Do runs the request unless a request with the same key already completed
+// within the TTL, in which case its response is replayed. scope separates key
+// spaces (project, network and user; or admin) so clients cannot collide. A
+// request without a key, or for a method idempotency does not cover, just
+// runs. When the store is unreachable the request runs as well: deduplication
+// is best-effort and never blocks writes.
+func (s *IdempotencyStore) Do(
+	ctx context.Context,
+	projectId, scope, method, key string,
+	nq *common.NormalizedRequest,
+	run func() (*common.NormalizedResponse, error),
+) (*common.NormalizedResponse, error) {
+	if s == nil || key == "" || !s.appliesTo(method) {
+		return run()
+	}
+	// Each request counts exactly one outcome; a failed store read only marks
+	// the request, which is then counted as store_error instead of executed
+	// if it ends up running without deduplication.
+	outcome := func(o string) {
+		telemetry.MetricIdempotencyRequestsTotal.WithLabelValues(projectId, method, o).Inc()
+	}
+	storeFailed := false
+	onStoreError := func() { storeFailed = true }
+
+	hash, err := nq.CacheHash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pk := "idempotency:" + scope
+	lg := s.logger.With().Str("method", method).Str("idempotencyKey", key).Logger()
+
+	resp, found, err := s.replay(ctx, nq, pk, key, hash, onStoreError)
+	if err != nil || found {
+		outcomeOf(err, outcome)
+		return resp, err
+	}
+
+	lockCtx, cancel := context.WithTimeout(ctx, s.lockTtl)
+	lock, lockErr := s.connector.Lock(lockCtx, pk+"#"+key, s.lockTtl)
+	cancel()
+	if lockErr != nil || lock == nil || lock.IsNil() {
+		// The key is held by an in-flight request for longer than we can wait;
+		// it may have completed in the meantime.
+		resp, found, err := s.replay(ctx, nq, pk, key, hash, onStoreError)
+		if err != nil || found {
+			outcomeOf(err, outcome)
+			return resp, err
+		}
+		outcome("conflict")
+		lg.Debug().Err(lockErr).Msg("idempotency key is held by an in-flight request")
+		return nil, common.NewErrIdempotencyKeyConflict(key, "a request with this key is still in progress")
+	}
+	defer func() {
+		unlockCtx, cancel := context.WithTimeout(context.Background(), idempotencyUnlockTimeout)
+		defer cancel()
+		if err := lock.Unlock(unlockCtx); err != nil {
+			lg.Warn().Err(err).Msg("failed to release idempotency lock")
+		}
+	}()
+
+	// Re-check under the lock: a concurrent duplicate may have finished while
+	// we were waiting for it.
+	resp, found, err = s.replay(ctx, nq, pk, key, hash, onStoreError)
+	if err != nil || found {
+		outcomeOf(err, outcome)
+		return resp, err
+	}
+
+	resp, err = run()
+	if storeFailed {
+		outcome("store_error")
+	} else {
+		outcome("executed")
+	}
+	if err == nil && resp != nil {
+		s.store(ctx, &lg, pk, key, hash, resp)
+	}
+	return resp, err
+}
+
+func outcomeOf(err error, outcome func(string)) {
+	if err != nil {
+		outcome("conflict")
+	} else {
+		outcome("replayed")
+	}
+}
+
+// replay returns the stored response for key, found=false when there is none
+// (or the store could not be read), and a conflict error when the key was
+// used for a different request. A failed read is reported to onStoreError.
+func (s *IdempotencyStore) replay(ctx context.Context, nq *common.NormalizedRequest, pk, key, hash string, onStoreError func()) (*common.NormalizedResponse, bool, error) {
+	raw, err := s.connector.Get(ctx, data.ConnectorMainIndex, pk, key, nil)
+	if err != nil {
+		if !common.HasErrorCode(err, common.ErrCodeRecordNotFound, common.ErrCodeRecordExpired) {
+			onStoreError()
+			s.logger.Warn().Err(err).Str("idempotencyKey", key).Msg("failed to read idempotency record, running request without deduplication")
+		}
+		return nil, false, nil
+	}
+	var rec idempotencyRecord
+	if err := common.SonicCfg.Unmarshal(raw, &rec); err != nil {
+		s.logger.Warn().Err(err).Str("idempotencyKey", key).Msg("ignoring unreadable idempotency record")
+		return nil, false, nil
+	}
+	if rec.RequestHash != hash {
+		return nil, false, common.NewErrIdempotencyKeyConflict(key, "the key was already used for a different request")
+	}
+	jrr, err := common.NewJsonRpcResponseFromBytes(nil, rec.Result, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if rq, err := nq.JsonRpcRequest(ctx); err == nil && rq != nil {
+		_ = jrr.SetID(rq.ID)
+	}
+	return common.NewNormalizedResponse().WithRequest(nq).WithJsonRpcResponse(jrr), true, nil
+}
+
+// store keeps successful responses only: a failed broadcast must stay
+// retryable under the same key.
+func (s *IdempotencyStore) store(ctx context.Context, lg *zerolog.Logger, pk, key, hash string, resp *common.NormalizedResponse) {
+	jrr, err := resp.JsonRpcResponse(ctx)
+	if err != nil || jrr == nil || jrr.Error != nil {
+		return
+	}
+	value, err := common.SonicCfg.Marshal(idempotencyRecord{
+		RequestHash: hash,
+		Result:      append(json.RawMessage(nil), jrr.GetResultBytes()...),
+	})
+	if err != nil {
+		return
+	}
+	if err := s.connector.Set(ctx, pk, key, value, &s.ttl); err != nil {
+		lg.Warn().Err(err).Msg("failed to store idempotency record, retries of this request will run again")
+	}
+}
@@ -0,0 +1,164 @@
+package erpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newStore := func(t *testing.T) *IdempotencyStore {
+		connector, err := data.NewMemoryConnector(ctx, &log.Logger, "idempotency-test", &common.MemoryConnectorConfig{
+			MaxItems: 1000, MaxTotalSize: "1MB",
+		})
+		require.NoError(t, err)
+		return &IdempotencyStore{
+			logger:    &log.Logger,
+			connector: connector,
+			ttl:       time.Minute,
+			lockTtl:   time.Second,
+			methods:   []string{"eth_sendRawTransaction"},
+		}
+	}
+	sendTx := func(id int, tx string) *common.NormalizedRequest {
+		return common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":` + strconv.Itoa(id) + `,"method":"eth_sendRawTransaction","params":["` + tx + `"]}`))
+	}
+	counted := func(calls *int, nq *common.NormalizedRequest) func() (*common.NormalizedResponse, error) {
+		return func() (*common.NormalizedResponse, error) {
+			*calls++
+			jrr, err := common.NewJsonRpcResponse(1, "0xabc", nil)
+			if err != nil {
+				return nil, err
+			}
+			return common.NewNormalizedResponse().WithRequest(nq).WithJsonRpcResponse(jrr), nil
+		}
+	}
+	// The memory connector applies writes asynchronously.
+	awaitRecord := func(t *testing.T, s *IdempotencyStore, scope, key string) {
+		require.Eventually(t, func() bool {
+			_, err := s.connector.Get(ctx, data.ConnectorMainIndex, "idempotency:"+scope, key, nil)
+			return err == nil
+		}, time.Second, 5*time.Millisecond)
+	}
+
+	t.Run("RetryIsReplayedWithItsOwnId", func(t *testing.T) {
+		s := newStore(t)
+		calls := 0
+		first := sendTx(1, "0x02f8")
+		resp, err := s.Do(ctx, "prj", "prj/evm:1", "eth_sendRawTransaction", "k1", first, counted(&calls, first))
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		awaitRecord(t, s, "prj/evm:1", "k1")
+
+		retry := sendTx(2, "0x02f8")
+		resp, err = s.Do(ctx, "prj", "prj/evm:1", "eth_sendRawTransaction", "k1", retry, counted(&calls, retry))
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls, "the retry must not be sent again")
+		jrr, err := resp.JsonRpcResponse(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, `"0xabc"`, string(jrr.GetResultBytes()))
+		assert.EqualValues(t, 2, jrr.ID())
+	})
+
+	t.Run("KeyReusedForDifferentRequestConflicts", func(t *testing.T) {
+		s := newStore(t)
+		calls := 0
+		first := sendTx(1, "0x02f8")
+		_, err := s.Do(ctx, "prj", "prj/evm:1", "eth_sendRawTransaction", "k1", first, counted(&calls, first))
+		require.NoError(t, err)
+		awaitRecord(t, s, "prj/evm:1", "k1")
+
+		other := sendTx(1, "0x02f9")
+		_, err = s.Do(ctx, "prj", "prj/evm:1", "eth_sendRawTransaction", "k1", other, counted(&calls, other))
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeIdempotencyKeyConflict))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("ScopesAreIsolated", func(t *testing.T) {
+		s := newStore(t)
+		calls := 0
+		nq := sendTx(1, "0x02f8")
+		_, err := s.Do(ctx, "prj", "prj/evm:1/alice", "eth_sendRawTransaction", "k1", nq, counted(&calls, nq))
+		require.NoError(t, err)
+		awaitRecord(t, s, "prj/evm:1/alice", "k1")
+
+		_, err = s.Do(ctx, "prj", "prj/evm:1/bob", "eth_sendRawTransaction", "k1", nq, counted(&calls, nq))
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("UnlistedMethodsAndMissingKeysAlwaysRun", func(t *testing.T) {
+		s := newStore(t)
+		calls := 0
+		nq := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}`))
+		for i := 0; i < 2; i++ {
+			_, err := s.Do(ctx, "prj", "prj/evm:1", "eth_call", "k1", nq, counted(&calls, nq))
+			require.NoError(t, err)
+		}
+		tx := sendTx(1, "0x02f8")
+		for i := 0; i < 2; i++ {
+			_, err := s.Do(ctx, "prj", "prj/evm:1", "eth_sendRawTransaction", "", tx, counted(&calls, tx))
+			require.NoError(t, err)
+		}
+		var nilStore *IdempotencyStore
+		_, err := nilStore.Do(ctx, "prj", "prj/evm:1", "eth_sendRawTransaction", "k1", tx, counted(&calls, tx))
+		require.NoError(t, err)
+		assert.Equal(t, 5, calls)
+	})
+
+	t.Run("StoreErrorIsCountedOnce", func(t *testing.T) {
+		s := newStore(t)
+		s.connector = &failingGetConnector{Connector: s.connector}
+		outcomes := func() (executed, storeError float64) {
+			return testutil.ToFloat64(telemetry.MetricIdempotencyRequestsTotal.WithLabelValues("prj-store-error", "eth_sendRawTransaction", "executed")),
+				testutil.ToFloat64(telemetry.MetricIdempotencyRequestsTotal.WithLabelValues("prj-store-error", "eth_sendRawTransaction", "store_error"))
+		}
+		executedBefore, storeErrorBefore := outcomes()
+
+		calls := 0
+		nq := sendTx(1, "0x02f8")
+		_, err := s.Do(ctx, "prj-store-error", "prj/evm:1", "eth_sendRawTransaction", "k1", nq, counted(&calls, nq))
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls, "an unreadable store must not block the request")
+
+		executed, storeError := outcomes()
+		assert.Equal(t, executedBefore, executed)
+		assert.Equal(t, storeErrorBefore+1, storeError)
+	})
+}
+
+// failingGetConnector fails every read, as an unreachable idempotency store
+// would, while locks and writes still go through.
+type failingGetConnector struct {
+	data.Connector
+}
+
+func (c *failingGetConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestIdempotencyKeyOf(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("Idempotency-Key", "from-header")
+	plain := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x02"]}`))
+	withField := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x02"],"idempotencyKey":"from-body"}`))
+
+	assert.Equal(t, "from-header", idempotencyKeyOf(plain, headers, false))
+	assert.Equal(t, "from-body", idempotencyKeyOf(withField, headers, false))
+	assert.Equal(t, "", idempotencyKeyOf(plain, headers, true), "batch items do not inherit the header")
+	assert.Equal(t, "from-body", idempotencyKeyOf(withField, headers, true))
+}
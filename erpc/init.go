@@ -24,15 +24,19 @@ func Init(
 	//
 	// 1) Set the right log level depending on the configuration
 	//
+	// The level lives in common.LogLevels rather than on the logger so the
+	// admin API can change it (globally or per component) at runtime.
+	baseLevel := zerolog.GlobalLevel()
 	level, err := zerolog.ParseLevel(cfg.LogLevel)
 	if err != nil {
 		logger.Warn().Msgf("invalid log level '%s', defaulting to 'debug': %s", cfg.LogLevel, err)
-		level = zerolog.DebugLevel
-	} else {
-		logger = logger.Level(level)
+	} else if level > baseLevel {
+		baseLevel = level
 	}
+	common.LogLevels.SetLevel(baseLevel)
+	logger = logger.Level(zerolog.TraceLevel).Sample(common.LogLevels.Sampler())
 
-	if logger.GetLevel() <= zerolog.InfoLevel {
+	if common.LogLevelEnabled(&logger, zerolog.InfoLevel) {
 		finalCfgJson, err := common.SonicCfg.Marshal(cfg)
 		if err != nil {
 			logger.Warn().Msgf("failed to marshal final configuration for tracing: %v", err)
@@ -74,6 +78,7 @@ func Init(
 		if len(aliasByNetworkId) > 0 {
 			common.SetNetworkAliasResolver(func(networkId string) string { return aliasByNetworkId[networkId] })
 		}
+		common.SetClientQuirks(cfg.ClientQuirks)
 	}
 
 	//
@@ -108,11 +113,28 @@ func Init(
 	// 4) Expose Transports
 	//
 	logger.Info().Msg("initializing transports")
+	var httpServer *HttpServer
 	if cfg.Server != nil {
-		httpServer, err := NewHttpServer(appCtx, &logger, cfg.Server, cfg.HealthCheck, cfg.Admin, erpcInstance)
+		httpServer, err = NewHttpServer(appCtx, &logger, cfg.Server, cfg.HealthCheck, cfg.Admin, erpcInstance)
 		if err != nil {
 			return err
 		}
+	}
+	// The management listener is built before the public one starts serving
+	// so that exclusive endpoints are never briefly exposed on it.
+	if cfg.Management != nil {
+		managementServer, err := NewManagementServer(appCtx, &logger, cfg.Management, httpServer)
+		if err != nil {
+			return err
+		}
+		go func() {
+			if err := managementServer.Start(); err != nil {
+				logger.Error().Msgf("failed to start management server: %v", err)
+				util.OsExit(util.ExitCodeHttpServerFailed)
+			}
+		}()
+	}
+	if httpServer != nil {
 		go func() {
 			if err := httpServer.Start(&logger); err != nil {
 				if err != http.ErrServerClosed {
@@ -138,6 +160,8 @@ func Init(
 		if cfg.Metrics.ErrorLabelMode != "" {
 			common.SetErrorLabelMode(cfg.Metrics.ErrorLabelMode)
 		}
+	}
+	if cfg.Metrics != nil && cfg.Metrics.Enabled != nil && *cfg.Metrics.Enabled && !ServesMetricsExclusively(cfg.Management) {
 		if cfg.Metrics.Port == nil {
 			return fmt.Errorf("metrics.port is not configured")
 		}
@@ -0,0 +1,349 @@
+package erpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+)
+
+const (
+	journalPartitionPrefix = "journal:"
+	journalWriteTimeout    = 5 * time.Second
+	journalResolveTimeout  = 30 * time.Second
+	journalScanPageSize    = 100
+)
+
+const (
+	JournalStatePending     = "pending"
+	JournalStateCompleted   = "completed"
+	JournalStateFailed      = "failed"
+	JournalStateFound       = "found"
+	JournalStateRebroadcast = "rebroadcast"
+	JournalStateLost        = "lost"
+	// JournalStateUnknown marks abandoned entries without a transaction hash:
+	// nothing tells whether they took effect, so they are never re-sent.
+	JournalStateUnknown = "unknown"
+)
+
+// JournalEntry is the journaled state of one write request.
+type JournalEntry struct {
+	ProjectId string          `json:"projectId"`
+	NetworkId string          `json:"networkId"`
+	Method    string          `json:"method"`
+	TxHash    string          `json:"txHash,omitempty"`
+	Request   json.RawMessage `json:"request"`
+	State     string          `json:"state"`
+	Error     string          `json:"error,omitempty"`
+	StartedAt time.Time       `json:"startedAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+type journalForwardFunc func(ctx context.Context, projectId, networkId string, nq *common.NormalizedRequest) (*common.NormalizedResponse, error)
+
+// RequestJournal records write requests while they are in flight. An entry
+// still pending after recoverAfter was abandoned by a replica that died
+// mid-request; the recovery loop (on any replica) then resolves it: a
+// transaction found on-chain is "found", otherwise it is re-broadcast when
+// enabled, or reported "lost". Entries of other methods are "unknown".
+type RequestJournal struct {
+	logger       *zerolog.Logger
+	connector    data.Connector
+	methods      []string
+	ttl          time.Duration
+	recoverAfter time.Duration
+	interval     time.Duration
+	rebroadcast  bool
+	forward      journalForwardFunc
+}
+
+func NewRequestJournal(ctx context.Context, logger *zerolog.Logger, cfg *common.JournalConfig) (*RequestJournal, error) {
+	connector, err := data.NewConnector(ctx, logger, cfg.Connector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create journal connector: %w", err)
+	}
+	lg := logger.With().Str("component", "journal").Logger()
+	return &RequestJournal{
+		logger:       &lg,
+		connector:    connector,
+		methods:      cfg.Methods,
+		ttl:          cfg.Ttl.Duration(),
+		recoverAfter: cfg.RecoverAfter.Duration(),
+		interval:     cfg.RecoveryInterval.Duration(),
+		rebroadcast:  cfg.Rebroadcast != nil && *cfg.Rebroadcast,
+	}, nil
+}
+
+// Start runs the recovery loop until ctx is done; the first pass runs right
+// away so a restarted replica resolves what it left behind.
+func (j *RequestJournal) Start(ctx context.Context, forward journalForwardFunc) {
+	j.forward = forward
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for {
+			j.recover(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (j *RequestJournal) appliesTo(method string) bool {
+	for _, m := range j.methods {
+		if ok, _ := common.WildcardMatch(m, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Do journals the request around run. Like idempotency it fails open: when
+// the entry cannot be written the request still runs, unjournaled.
+func (j *RequestJournal) Do(
+	ctx context.Context,
+	projectId, networkId, method string,
+	nq *common.NormalizedRequest,
+	run func() (*common.NormalizedResponse, error),
+) (*common.NormalizedResponse, error) {
+	if j == nil || !j.appliesTo(method) {
+		return run()
+	}
+
+	// The raw body is dropped once parsed; keep what a rebroadcast needs.
+	jrq, err := nq.JsonRpcRequest(ctx)
+	if err != nil {
+		return run()
+	}
+	jrq.RLock()
+	request, err := common.SonicCfg.Marshal(common.NewJsonRpcRequest(jrq.Method, jrq.Params))
+	jrq.RUnlock()
+	if err != nil {
+		return run()
+	}
+	entry := &JournalEntry{
+		ProjectId: projectId,
+		NetworkId: networkId,
+		Method:    method,
+		Request:   request,
+		State:     JournalStatePending,
+		StartedAt: time.Now(),
+	}
+	id := ""
+	if strings.EqualFold(method, "eth_sendRawTransaction") {
+		if txHash, err := evm.TxHashOfSendRawTransaction(ctx, nq); err == nil {
+			entry.TxHash = strings.ToLower(txHash)
+			id = entry.TxHash
+		}
+	}
+	if id == "" {
+		hash, err := nq.CacheHash(ctx)
+		if err != nil {
+			return run()
+		}
+		id = hash
+	}
+	pk, rk := journalPartitionPrefix+projectId, networkId+"/"+id
+	lg := j.logger.With().Str("projectId", projectId).Str("networkId", networkId).Str("method", method).Str("journalKey", rk).Logger()
+
+	if err := j.put(ctx, pk, rk, entry); err != nil {
+		j.outcome(entry, "store_error")
+		lg.Warn().Err(err).Msg("failed to journal request, running it unjournaled")
+		return run()
+	}
+
+	resp, err := run()
+	if err != nil {
+		entry.State = JournalStateFailed
+		entry.Error = err.Error()
+	} else {
+		entry.State = JournalStateCompleted
+	}
+	// The caller may be gone already; the outcome must still be recorded or
+	// the entry would be recovered as abandoned.
+	if perr := j.put(context.WithoutCancel(ctx), pk, rk, entry); perr != nil {
+		lg.Warn().Err(perr).Msg("failed to record journaled request outcome, it will be recovered as abandoned")
+	}
+	j.outcome(entry, entry.State)
+	return resp, err
+}
+
+func (j *RequestJournal) put(ctx context.Context, pk, rk string, entry *JournalEntry) error {
+	entry.UpdatedAt = time.Now()
+	value, err := common.SonicCfg.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, journalWriteTimeout)
+	defer cancel()
+	return j.connector.Set(ctx, pk, rk, value, &j.ttl)
+}
+
+func (j *RequestJournal) get(ctx context.Context, pk, rk string) (*JournalEntry, error) {
+	raw, err := j.connector.Get(ctx, data.ConnectorMainIndex, pk, rk, nil)
+	if err != nil {
+		return nil, err
+	}
+	var entry JournalEntry
+	if err := common.SonicCfg.Unmarshal(raw, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (j *RequestJournal) outcome(entry *JournalEntry, outcome string) {
+	telemetry.MetricJournalEntriesTotal.WithLabelValues(entry.ProjectId, entry.NetworkId, entry.Method, outcome).Inc()
+}
+
+// List returns journal entries of a project (every project when projectId is
+// empty), optionally only those in state, one page at a time.
+func (j *RequestJournal) List(ctx context.Context, projectId, state string, limit int, cursor string) ([]*JournalEntry, string, error) {
+	items, next, err := j.connector.Scan(ctx, journalPartitionPrefix+projectId, "", limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	entries := make([]*JournalEntry, 0, len(items))
+	for _, kv := range items {
+		var entry JournalEntry
+		if err := common.SonicCfg.Unmarshal(kv.Value, &entry); err != nil {
+			continue
+		}
+		// The prefix "journal:main" also matches project "mainnet".
+		if projectId != "" && entry.ProjectId != projectId {
+			continue
+		}
+		if state == "" || entry.State == state {
+			entries = append(entries, &entry)
+		}
+	}
+	return entries, next, nil
+}
+
+func (j *RequestJournal) abandoned(entry *JournalEntry) bool {
+	return entry.State == JournalStatePending && time.Since(entry.StartedAt) >= j.recoverAfter
+}
+
+// recover resolves every abandoned entry of the journal.
+func (j *RequestJournal) recover(ctx context.Context) {
+	cursor := ""
+	for ctx.Err() == nil {
+		items, next, err := j.connector.Scan(ctx, journalPartitionPrefix, "", journalScanPageSize, cursor)
+		if err != nil {
+			j.logger.Warn().Err(err).Msg("failed to scan journal for abandoned requests")
+			return
+		}
+		for _, kv := range items {
+			var entry JournalEntry
+			if err := common.SonicCfg.Unmarshal(kv.Value, &entry); err != nil || !j.abandoned(&entry) {
+				continue
+			}
+			j.resolve(ctx, kv.PartitionKey, kv.RangeKey)
+		}
+		if next == "" {
+			return
+		}
+		cursor = next
+	}
+}
+
+// resolve settles one abandoned entry under a lock, so that concurrent
+// recovery passes on several replicas rebroadcast it at most once.
+func (j *RequestJournal) resolve(ctx context.Context, pk, rk string) {
+	lg := j.logger.With().Str("journalKey", rk).Logger()
+	lock, err := j.connector.Lock(ctx, "journal-recovery/"+pk+"/"+rk, journalResolveTimeout)
+	if err != nil || lock == nil || lock.IsNil() {
+		lg.Debug().Err(err).Msg("journal entry is being recovered elsewhere")
+		return
+	}
+	defer func() {
+		unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), journalWriteTimeout)
+		defer cancel()
+		if err := lock.Unlock(unlockCtx); err != nil {
+			lg.Warn().Err(err).Msg("failed to release journal recovery lock")
+		}
+	}()
+
+	entry, err := j.get(ctx, pk, rk)
+	if err != nil || !j.abandoned(entry) {
+		return
+	}
+	rctx, cancel := context.WithTimeout(ctx, journalResolveTimeout)
+	defer cancel()
+	state, reason := j.fateOf(rctx, entry)
+	if state == "" {
+		lg.Debug().Str("reason", reason).Msg("could not determine fate of abandoned request, will retry")
+		return
+	}
+	entry.State, entry.Error = state, reason
+	if err := j.put(ctx, pk, rk, entry); err != nil {
+		lg.Warn().Err(err).Msg("failed to record fate of abandoned request")
+		return
+	}
+	j.outcome(entry, state)
+	ev := lg.Info()
+	if state == JournalStateLost || state == JournalStateUnknown {
+		ev = lg.Warn()
+	}
+	ev.Str("projectId", entry.ProjectId).Str("networkId", entry.NetworkId).Str("txHash", entry.TxHash).
+		Str("state", state).Str("reason", reason).Msg("resolved abandoned journaled request")
+}
+
+// fateOf returns the final state of an abandoned entry, or "" when it
+// cannot be decided yet (e.g. the network is not reachable).
+// Only a transaction whose lookup came back empty is re-broadcast: any other
+// request may have taken effect, and re-sending it is not known to be safe.
+func (j *RequestJournal) fateOf(ctx context.Context, entry *JournalEntry) (string, string) {
+	if entry.TxHash == "" {
+		return JournalStateUnknown, "no transaction hash to look up after the replica handling it stopped"
+	}
+	found, err := j.transactionExists(ctx, entry)
+	if err != nil {
+		return "", err.Error()
+	}
+	if found {
+		return JournalStateFound, ""
+	}
+	if !j.rebroadcast {
+		return JournalStateLost, "not found after the replica handling it stopped"
+	}
+	var jrq common.JsonRpcRequest
+	if err := common.SonicCfg.Unmarshal(entry.Request, &jrq); err != nil {
+		return JournalStateLost, "journaled request is unreadable: " + err.Error()
+	}
+	jrq.ID = util.RandomID()
+	resp, err := j.forward(ctx, entry.ProjectId, entry.NetworkId, common.NewNormalizedRequestFromJsonRpcRequest(&jrq))
+	if resp != nil {
+		defer resp.Release()
+	}
+	if err != nil {
+		return JournalStateLost, "rebroadcast failed: " + err.Error()
+	}
+	return JournalStateRebroadcast, ""
+}
+
+func (j *RequestJournal) transactionExists(ctx context.Context, entry *JournalEntry) (bool, error) {
+	nq := common.NewNormalizedRequest([]byte(fmt.Sprintf(
+		`{"jsonrpc":"2.0","id":%d,"method":"eth_getTransactionByHash","params":[%q]}`,
+		util.RandomID(),
+		entry.TxHash,
+	)))
+	resp, err := j.forward(ctx, entry.ProjectId, entry.NetworkId, nq)
+	if resp != nil {
+		defer resp.Release()
+	}
+	if err != nil {
+		return false, err
+	}
+	return !resp.IsResultEmptyish(ctx), nil
+}
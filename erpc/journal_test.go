@@ -0,0 +1,161 @@
+package erpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const journalTestRawTx = "0x02f873010a8459682f008506fc23ac0082520894d8da6bf26964af9d7eed9e03e53415d37aa9604588016345785d8a000080c080a0a3d5fd825e582675933b2b6aea774b0454633edb49e94699d6f88d197cd26589a06295b0b43a9e93a3390b308272a65bb063d9f18deb4cb7db5ecf352bf9ba9fe7"
+
+func TestRequestJournal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newJournal := func(t *testing.T, rebroadcast bool) *RequestJournal {
+		connector, err := data.NewMemoryConnector(ctx, &log.Logger, "journal-test", &common.MemoryConnectorConfig{
+			MaxItems: 1000, MaxTotalSize: "1MB",
+		})
+		require.NoError(t, err)
+		return &RequestJournal{
+			logger:       &log.Logger,
+			connector:    connector,
+			methods:      []string{"eth_sendRawTransaction"},
+			ttl:          time.Minute,
+			recoverAfter: time.Minute,
+			interval:     time.Minute,
+			rebroadcast:  rebroadcast,
+		}
+	}
+	sendTx := func() *common.NormalizedRequest {
+		return common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["` + journalTestRawTx + `"]}`))
+	}
+	result := func(nq *common.NormalizedRequest, v interface{}) *common.NormalizedResponse {
+		jrr, err := common.NewJsonRpcResponse(1, v, nil)
+		require.NoError(t, err)
+		return common.NewNormalizedResponse().WithRequest(nq).WithJsonRpcResponse(jrr)
+	}
+	// The memory connector applies writes asynchronously.
+	awaitState := func(t *testing.T, j *RequestJournal, state string) *JournalEntry {
+		var found *JournalEntry
+		require.Eventually(t, func() bool {
+			entries, _, err := j.List(ctx, "prj", "", 10, "")
+			if err != nil || len(entries) != 1 || entries[0].State != state {
+				return false
+			}
+			found = entries[0]
+			return true
+		}, time.Second, 5*time.Millisecond)
+		return found
+	}
+	// abandon leaves the entry a replica killed mid-request would: pending,
+	// and started longer than recoverAfter ago.
+	abandon := func(t *testing.T, j *RequestJournal) {
+		txHash, err := evm.TxHashOfSendRawTransaction(ctx, sendTx())
+		require.NoError(t, err)
+		txHash = strings.ToLower(txHash)
+		require.NoError(t, j.put(ctx, journalPartitionPrefix+"prj", "evm:1/"+txHash, &JournalEntry{
+			ProjectId: "prj",
+			NetworkId: "evm:1",
+			Method:    "eth_sendRawTransaction",
+			TxHash:    txHash,
+			Request:   []byte(`{"jsonrpc":"2.0","method":"eth_sendRawTransaction","params":["` + journalTestRawTx + `"]}`),
+			State:     JournalStatePending,
+			StartedAt: time.Now().Add(-2 * time.Minute),
+		}))
+		awaitState(t, j, JournalStatePending)
+	}
+
+	t.Run("RecordsOutcome", func(t *testing.T) {
+		j := newJournal(t, false)
+		nq := sendTx()
+		_, err := j.Do(ctx, "prj", "evm:1", "eth_sendRawTransaction", nq, func() (*common.NormalizedResponse, error) {
+			entry := awaitState(t, j, JournalStatePending)
+			assert.False(t, j.abandoned(entry), "in-flight requests are not recovered")
+			return result(nq, entry.TxHash), nil
+		})
+		require.NoError(t, err)
+		entry := awaitState(t, j, JournalStateCompleted)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","method":"eth_sendRawTransaction","params":["`+journalTestRawTx+`"]}`, string(entry.Request))
+	})
+
+	t.Run("IgnoresOtherMethods", func(t *testing.T) {
+		j := newJournal(t, false)
+		nq := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}`))
+		_, err := j.Do(ctx, "prj", "evm:1", "eth_call", nq, func() (*common.NormalizedResponse, error) {
+			return result(nq, "0x"), nil
+		})
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+		entries, _, err := j.List(ctx, "", "", 10, "")
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	cases := []struct {
+		name        string
+		rebroadcast bool
+		onChain     bool
+		want        string
+		wantSends   int
+	}{
+		{"FoundOnChain", true, true, JournalStateFound, 0},
+		{"Lost", false, false, JournalStateLost, 0},
+		{"Rebroadcast", true, false, JournalStateRebroadcast, 1},
+	}
+	for _, tc := range cases {
+		t.Run("Recover"+tc.name, func(t *testing.T) {
+			j := newJournal(t, tc.rebroadcast)
+			sends := 0
+			j.forward = func(ctx context.Context, projectId, networkId string, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+				assert.Equal(t, "prj", projectId)
+				assert.Equal(t, "evm:1", networkId)
+				method, _ := nq.Method()
+				if method == "eth_sendRawTransaction" {
+					sends++
+					return result(nq, "0xhash"), nil
+				}
+				if tc.onChain {
+					return result(nq, map[string]interface{}{"hash": "0xhash"}), nil
+				}
+				return result(nq, nil), nil
+			}
+			abandon(t, j)
+
+			j.recover(ctx)
+			awaitState(t, j, tc.want)
+			assert.Equal(t, tc.wantSends, sends)
+		})
+	}
+
+	t.Run("RecoverWithoutTxHashIsUnknown", func(t *testing.T) {
+		j := newJournal(t, true)
+		calls := 0
+		j.forward = func(ctx context.Context, projectId, networkId string, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+			calls++
+			return result(nq, "0x1"), nil
+		}
+		require.NoError(t, j.put(ctx, journalPartitionPrefix+"prj", "evm:1/somehash", &JournalEntry{
+			ProjectId: "prj",
+			NetworkId: "evm:1",
+			Method:    "eth_sendTransaction",
+			Request:   []byte(`{"jsonrpc":"2.0","method":"eth_sendTransaction","params":[{"to":"0x1"}]}`),
+			State:     JournalStatePending,
+			StartedAt: time.Now().Add(-2 * time.Minute),
+		}))
+		awaitState(t, j, JournalStatePending)
+
+		j.recover(ctx)
+		entry := awaitState(t, j, JournalStateUnknown)
+		assert.NotEmpty(t, entry.Error)
+		assert.Equal(t, 0, calls, "requests without a transaction hash are neither looked up nor re-sent")
+	})
+}
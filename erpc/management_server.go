@@ -0,0 +1,161 @@
+package erpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/erpc/erpc/auth"
+	"github.com/erpc/erpc/common"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog"
+)
+
+// ManagementServer is the listener for the operator endpoints (metrics,
+// healthcheck, admin API, pprof). See common.ManagementConfig.
+type ManagementServer struct {
+	cfg          *common.ManagementConfig
+	logger       *zerolog.Logger
+	server       *http.Server
+	authRegistry *auth.AuthRegistry
+}
+
+// NewManagementServer builds the management listener. httpServer provides the
+// healthcheck and admin handlers and may be nil when there is no public
+// server; when the config is exclusive those endpoints are removed from it.
+func NewManagementServer(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	cfg *common.ManagementConfig,
+	httpServer *HttpServer,
+) (*ManagementServer, error) {
+	lg := logger.With().Str("component", "management").Logger()
+	m := &ManagementServer{cfg: cfg, logger: &lg}
+
+	if cfg.Auth != nil {
+		var err error
+		m.authRegistry, err = auth.NewAuthRegistry(ctx, &lg, "management", cfg.Auth, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create management auth registry: %w", err)
+		}
+	}
+
+	exclusive := cfg.Exclusive == nil || *cfg.Exclusive
+	mux := http.NewServeMux()
+	if cfg.Metrics != nil && *cfg.Metrics {
+		mux.Handle("/metrics", m.authenticated(promhttp.Handler()))
+	}
+	if cfg.Pprof != nil && *cfg.Pprof {
+		mux.Handle("/debug/pprof/", m.authenticated(http.HandlerFunc(pprof.Index)))
+		mux.Handle("/debug/pprof/cmdline", m.authenticated(http.HandlerFunc(pprof.Cmdline)))
+		mux.Handle("/debug/pprof/profile", m.authenticated(http.HandlerFunc(pprof.Profile)))
+		mux.Handle("/debug/pprof/symbol", m.authenticated(http.HandlerFunc(pprof.Symbol)))
+		mux.Handle("/debug/pprof/trace", m.authenticated(http.HandlerFunc(pprof.Trace)))
+	}
+	if httpServer != nil {
+		serveHealthCheck := cfg.HealthCheck != nil && *cfg.HealthCheck
+		serveAdmin := cfg.Admin != nil && *cfg.Admin
+		if serveHealthCheck || serveAdmin {
+			// Same handler as the public listener (auth, CORS, drain-aware
+			// healthcheck); it refuses anything but healthcheck and admin.
+			h := TimeoutHandler(&lg, httpServer.createRequestHandler(false), httpServer.reqMaxTimeout)
+			mux.Handle("/", managementEndpoints(h, serveHealthCheck, serveAdmin))
+		}
+		if exclusive {
+			httpServer.hideHealthCheck = serveHealthCheck
+			httpServer.hideAdmin = serveAdmin
+		}
+	}
+
+	m.server = &http.Server{
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		Addr:              net.JoinHostPort(cfg.Host, strconv.Itoa(*cfg.Port)),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if cfg.TLS != nil && cfg.TLS.Enabled {
+		tlsConfig, err := newListenerTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create management TLS config: %w", err)
+		}
+		m.server.TLSConfig = tlsConfig
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := m.server.Shutdown(shutdownCtx); err != nil {
+			lg.Error().Err(err).Msg("management server forced to shutdown")
+		} else {
+			lg.Info().Msg("management server stopped")
+		}
+	}()
+
+	return m, nil
+}
+
+// Start blocks serving the management listener until it is shut down.
+func (m *ManagementServer) Start() error {
+	m.logger.Info().Str("addr", m.server.Addr).Bool("tls", m.server.TLSConfig != nil).Msg("starting management server")
+	var err error
+	if m.server.TLSConfig != nil {
+		// Certificates are already loaded into TLSConfig.
+		err = m.server.ListenAndServeTLS("", "")
+	} else {
+		err = m.server.ListenAndServe()
+	}
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// ServesMetricsExclusively reports whether the metrics.port listener should be skipped
+// because /metrics is served exclusively by the management listener.
+func ServesMetricsExclusively(cfg *common.ManagementConfig) bool {
+	return cfg != nil && cfg.Metrics != nil && *cfg.Metrics && (cfg.Exclusive == nil || *cfg.Exclusive)
+}
+
+func (m *ManagementServer) authenticated(next http.Handler) http.Handler {
+	if m.authRegistry == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ap, err := auth.NewPayloadFromHttp("management", r.RemoteAddr, r.Header, r.URL.Query())
+		if err == nil {
+			nq := common.NewNormalizedRequest(nil)
+			if ip := parseRemoteIP(r.RemoteAddr); ip != nil {
+				nq.SetClientIP(ip.String())
+			}
+			_, err = m.authRegistry.Authenticate(r.Context(), nq, "management", ap)
+		}
+		if err != nil {
+			m.logger.Debug().Err(err).Str("path", r.URL.Path).Msg("management request rejected")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// managementEndpoints routes /admin to h when the admin API is enabled and
+// every other path (the healthcheck variants) when the healthcheck is enabled.
+func managementEndpoints(h http.Handler, healthCheck, admin bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enabled := healthCheck
+		if path.Clean(r.URL.Path) == "/admin" {
+			enabled = admin
+		}
+		if !enabled {
+			http.NotFound(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
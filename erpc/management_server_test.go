@@ -0,0 +1,118 @@
+package erpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagementServer(t *testing.T) {
+	logger := log.Logger
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newServers := func(t *testing.T, mgmt *common.ManagementConfig) (public http.Handler, management http.Handler) {
+		cfg := &common.Config{
+			Server:     &common.ServerConfig{ListenV4: util.BoolPtr(true)},
+			Projects:   []*common.ProjectConfig{{Id: "test_project"}},
+			Management: mgmt,
+		}
+		require.NoError(t, cfg.SetDefaults(nil))
+		cfg.Server.ListenV4 = util.BoolPtr(true)
+		require.NoError(t, cfg.Management.Validate(cfg))
+
+		ssr, err := data.NewSharedStateRegistry(ctx, &logger, &common.SharedStateConfig{
+			Connector: &common.ConnectorConfig{Driver: "memory", Memory: &common.MemoryConnectorConfig{MaxItems: 1000, MaxTotalSize: "1MB"}},
+		})
+		require.NoError(t, err)
+		erpcInstance, err := NewERPC(ctx, &logger, ssr, nil, cfg)
+		require.NoError(t, err)
+		httpServer, err := NewHttpServer(ctx, &logger, cfg.Server, cfg.HealthCheck, cfg.Admin, erpcInstance)
+		require.NoError(t, err)
+		ms, err := NewManagementServer(ctx, &logger, cfg.Management, httpServer)
+		require.NoError(t, err)
+		return httpServer.serverV4.Handler, ms.server.Handler
+	}
+	do := func(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.RemoteAddr = "127.0.0.1:40000"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("ExclusiveMovesHealthCheckOffThePublicListener", func(t *testing.T) {
+		public, management := newServers(t, &common.ManagementConfig{})
+
+		rec := do(public, http.MethodGet, "/healthcheck", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), "management listener")
+
+		rec = do(management, http.MethodGet, "/healthcheck", "")
+		assert.NotContains(t, rec.Body.String(), "management listener")
+
+		rec = do(management, http.MethodGet, "/metrics", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "go_goroutines")
+	})
+
+	t.Run("ManagementListenerDoesNotServeRpc", func(t *testing.T) {
+		public, management := newServers(t, &common.ManagementConfig{})
+
+		rpc := `{"jsonrpc":"2.0","method":"eth_chainId","params":[],"id":1}`
+		rec := do(management, http.MethodPost, "/test_project/evm/123", rpc)
+		assert.Contains(t, rec.Body.String(), "only healthcheck and admin requests")
+
+		rec = do(public, http.MethodPost, "/unknown_project/evm/123", rpc)
+		assert.NotContains(t, rec.Body.String(), "management listener")
+	})
+
+	t.Run("NonExclusiveKeepsPublicEndpoints", func(t *testing.T) {
+		public, _ := newServers(t, &common.ManagementConfig{Exclusive: util.BoolPtr(false)})
+
+		rec := do(public, http.MethodGet, "/healthcheck", "")
+		assert.NotContains(t, rec.Body.String(), "management listener")
+	})
+
+	t.Run("DisabledEndpointsAreNotRouted", func(t *testing.T) {
+		public, management := newServers(t, &common.ManagementConfig{
+			HealthCheck: util.BoolPtr(false),
+			Metrics:     util.BoolPtr(false),
+		})
+
+		assert.Equal(t, http.StatusNotFound, do(management, http.MethodGet, "/healthcheck", "").Code)
+		assert.Equal(t, http.StatusNotFound, do(management, http.MethodGet, "/metrics", "").Code)
+		assert.Equal(t, http.StatusNotFound, do(management, http.MethodGet, "/debug/pprof/", "").Code)
+		assert.NotContains(t, do(public, http.MethodGet, "/healthcheck", "").Body.String(), "management listener")
+	})
+
+	t.Run("AuthGuardsMetricsAndPprof", func(t *testing.T) {
+		_, management := newServers(t, &common.ManagementConfig{
+			Pprof: util.BoolPtr(true),
+			Auth: &common.AuthConfig{Strategies: []*common.AuthStrategyConfig{
+				{Type: common.AuthTypeSecret, Secret: &common.SecretStrategyConfig{Value: "ops-secret"}},
+			}},
+		})
+
+		assert.Equal(t, http.StatusUnauthorized, do(management, http.MethodGet, "/metrics", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, do(management, http.MethodGet, "/debug/pprof/", "").Code)
+		assert.Equal(t, http.StatusOK, do(management, http.MethodGet, "/metrics?secret=ops-secret", "").Code)
+		assert.Equal(t, http.StatusOK, do(management, http.MethodGet, "/debug/pprof/?secret=ops-secret", "").Code)
+	})
+}
+
+func TestServesMetricsExclusively(t *testing.T) {
+	assert.False(t, ServesMetricsExclusively(nil))
+	assert.True(t, ServesMetricsExclusively(&common.ManagementConfig{Metrics: util.BoolPtr(true)}))
+	assert.False(t, ServesMetricsExclusively(&common.ManagementConfig{Metrics: util.BoolPtr(true), Exclusive: util.BoolPtr(false)}))
+	assert.False(t, ServesMetricsExclusively(&common.ManagementConfig{Metrics: util.BoolPtr(false)}))
+}
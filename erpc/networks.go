@@ -49,6 +49,22 @@ type Network struct {
 	policyEngine *policy.Engine
 	initializer  *util.Initializer
 
+	// preferredUpstreams is the project's preferredUpstreams: ids moved to
+	// the front of every request's upstream list.
+	preferredUpstreams []string
+
+	// prefetcher prefetches receipts/logs of new heads (evm.receiptsPrefetch);
+	// nil when disabled.
+	prefetcher *blockPrefetcher
+
+	// abiRegistry resolves contract ABIs for revert and log decoding
+	// (evm.abi); nil when disabled.
+	abiRegistry *evm.AbiRegistry
+
+	// addressActivity serves erpc_addressActivity watchers
+	// (evm.addressActivity); nil when disabled.
+	addressActivity *evm.AddressActivityTracker
+
 	// servedLatest / servedFinalized are STRICT-MONOTONIC at the network level:
 	// once we serve a tip of N to clients, EvmHighestLatest/FinalizedBlockNumber
 	// servedTipAnchor watchdogs track when this process last SAW the served
@@ -137,6 +153,17 @@ const servedTipLaneNone = "\x00scoped"
 // The upstream list is supplied as a closure so newly-bootstrapped upstreams
 // become visible to the engine each tick without a re-register.
 func (n *Network) Bootstrap(ctx context.Context) error {
+	if n.prefetcher != nil {
+		n.prefetcher.attach(ctx)
+	}
+	if n.addressActivity != nil {
+		ups := n.upstreamsRegistry.GetNetworkUpstreams(ctx, n.networkId)
+		evmUps := make([]common.EvmUpstream, 0, len(ups))
+		for _, up := range ups {
+			evmUps = append(evmUps, up)
+		}
+		n.addressActivity.Attach(evmUps)
+	}
 	if n.policyEngine == nil {
 		return nil
 	}
@@ -198,6 +225,22 @@ func (n *Network) EvmBlockTime() time.Duration {
 	return n.metricsTracker.GetNetworkBlockTime(n.networkId)
 }
 
+// EvmChainState returns the network's head view as the upstreams' state
+// pollers see it, so the cache's realtime age gate reuses that head instead of
+// polling eth_blockNumber itself. Like EvmHighestLatestBlockNumber it is
+// computed on each call rather than cached.
+func (n *Network) EvmChainState(ctx context.Context) common.EvmChainState {
+	cs := common.EvmChainState{
+		LatestBlock:    n.EvmHighestLatestBlockNumber(ctx),
+		FinalizedBlock: n.EvmHighestFinalizedBlockNumber(ctx),
+	}
+	if n.metricsTracker != nil {
+		cs.LatestBlockTimestampBlock, cs.LatestBlockTimestamp = n.metricsTracker.GetNetworkLatestBlockTimestamp(n.networkId)
+		cs.BlockTime = n.metricsTracker.GetNetworkBlockTime(n.networkId)
+	}
+	return cs
+}
+
 // AllUpstreams returns every upstream configured on the network, in
 // no particular order. Diagnostic tooling uses this to walk upstreams
 // for tracker lookups without needing to know the routing order.
@@ -405,6 +448,7 @@ func (n *Network) tipCandidateUpstreams(ctx context.Context, method string) []co
 			ups = append(ups, u)
 		}
 	}
+	ups = n.forkNodesOnly(ups)
 
 	// Selector-scoped served tip: when the request targets a subset of
 	// upstreams (the use-upstream id/tag selector), the network's
@@ -832,6 +876,16 @@ func (n *Network) EvmLeaderUpstream(ctx context.Context) common.Upstream {
 	return leader
 }
 
+// EvmAbiRegistry implements evm.AbiRegistryProvider.
+func (n *Network) EvmAbiRegistry() *evm.AbiRegistry {
+	return n.abiRegistry
+}
+
+// EvmAddressActivity implements evm.AddressActivityProvider.
+func (n *Network) EvmAddressActivity() *evm.AddressActivityTracker {
+	return n.addressActivity
+}
+
 func (n *Network) getFailsafeExecutor(ctx context.Context, req *common.NormalizedRequest) *networkExecutor {
 	method, _ := req.Method()
 	finality := req.Finality(ctx)
@@ -866,6 +920,13 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 
 	method, _ := req.Method()
 	lg := n.logger.With().Str("method", method).Interface("id", req.ID()).Str("ptr", fmt.Sprintf("%p", req)).Logger()
+	if cid := common.CorrelationIdFromContext(ctx); cid != "" {
+		lg = lg.With().Str("correlationId", cid).Logger()
+	}
+
+	if n.prefetcher != nil {
+		n.prefetcher.observe(ctx, method)
+	}
 
 	// Start a span for network forwarding
 	ctx, forwardSpan := common.StartSpan(ctx, "Network.Forward",
@@ -892,7 +953,7 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 	}
 	defer forwardSpan.End()
 
-	if lg.GetLevel() == zerolog.TraceLevel {
+	if common.LogLevelEnabled(&lg, zerolog.TraceLevel) {
 		lg.Debug().Object("request", req).Msgf("forwarding request for network")
 	} else {
 		lg.Debug().Msgf("forwarding request for network")
@@ -928,13 +989,24 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 		defer n.cleanupMultiplexer(mlx)
 	}
 
+	var pendingCacheRead <-chan cacheReadResult
 	if n.cacheDal != nil && !req.ShouldSkipCacheRead("") {
 		lg.Debug().Msgf("checking cache for request")
-		resp, err := n.cacheDal.Get(ctx, req)
+		var resp *common.NormalizedResponse
+		var err error
+		resp, pendingCacheRead, err = n.getFromCache(ctx, req)
 		if err != nil {
 			lg.Debug().Err(err).Msgf("could not find response in cache")
+		} else if cachedErr := evm.CachedResponseError(ctx, resp); cachedErr != nil {
+			lg.Debug().Err(cachedErr).Msgf("error result served from cache")
+			resp.Release()
+			if mlx != nil {
+				mlx.Close(ctx, nil, cachedErr)
+			}
+			forwardSpan.SetAttributes(attribute.Bool("cache.hit", true))
+			return nil, cachedErr
 		} else if resp != nil && !resp.IsObjectNull(ctx) {
-			if lg.GetLevel() <= zerolog.DebugLevel {
+			if common.LogLevelEnabled(&lg, zerolog.DebugLevel) {
 				lg.Debug().Object("response", resp).Msgf("response served from cache")
 			} else {
 				lg.Info().Msgf("response served from cache")
@@ -948,6 +1020,17 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 		forwardSpan.SetAttributes(attribute.Bool("cache.hit", false))
 	}
 
+	if pendingCacheRead != nil {
+		return n.raceLateCacheRead(ctx, &lg, req, method, forwardSpan, mlx, pendingCacheRead, func(ctx context.Context) (*common.NormalizedResponse, error) {
+			return n.forwardToUpstreams(ctx, req, method, startTime, lg, forwardSpan, mlx)
+		})
+	}
+	return n.forwardToUpstreams(ctx, req, method, startTime, lg, forwardSpan, mlx)
+}
+
+// forwardToUpstreams selects upstreams for a request that was not served from
+// the cache and runs it through the failsafe executor.
+func (n *Network) forwardToUpstreams(ctx context.Context, req *common.NormalizedRequest, method string, startTime time.Time, lg zerolog.Logger, forwardSpan trace.Span, mlx *Multiplexer) (*common.NormalizedResponse, error) {
 	_, upstreamSpan := common.StartDetailSpan(ctx, "PolicyEngine.GetOrdered")
 	var upsList []common.Upstream
 	if n.policyEngine != nil {
@@ -978,6 +1061,9 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 		upstreamSpan.SetAttributes(attribute.Int("upstreams.method_ineligible", dropped))
 		upsList = eligible
 	}
+	upsList = n.applyForkRouting(ctx, req, upsList)
+	upsList = n.applyCanaryWeights(ctx, upsList)
+	upsList = n.applyPreferredUpstreams(upsList)
 	upstreamSpan.SetAttributes(attribute.Int("upstreams.count", len(upsList)))
 	if common.IsTracingDetailed {
 		ids := make([]string, len(upsList))
@@ -1215,7 +1301,10 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 				}
 			}
 
-			ulg := lg.With().Str("upstreamId", u.Id()).Logger()
+			ulg := lg.With().Str("upstreamId", u.Id()).Logger().Sample(common.LogLevels.Sampler(
+				common.LogTarget{Kind: common.LogTargetNetwork, Id: n.networkId},
+				common.LogTarget{Kind: common.LogTargetUpstream, Id: u.Id()},
+			))
 			ulg.Debug().
 				Interface("id", effectiveReq.ID()).
 				Str("ptr", fmt.Sprintf("%p", effectiveReq)).
@@ -1402,6 +1491,7 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 		// For example if 1 upstream gives empty response another 3 give "reverted" error,
 		// we should still return reverted error, even though there was an empty response before.
 		if failsafeExecutor.HasConsensus() {
+			n.storeErrorInCache(&lg, req, method, translatedErr, forwardSpan)
 			if mlx != nil {
 				mlx.Close(ctx, nil, translatedErr)
 			}
@@ -1426,6 +1516,7 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 			resp = lvr
 			req.SetLastUpstream(resp.Upstream())
 		} else {
+			n.storeErrorInCache(&lg, req, method, translatedErr, forwardSpan)
 			if mlx != nil {
 				mlx.Close(ctx, nil, translatedErr)
 			}
@@ -1456,9 +1547,9 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 				})()
 				defer resp.DoneRef()
 
-				timeoutCtx, timeoutCtxCancel := context.WithTimeoutCause(n.appCtx, 10*time.Second, errors.New("cache driver timeout during set"))
-				defer timeoutCtxCancel()
-				tracedCtx := trace.ContextWithSpanContext(timeoutCtx, forwardSpan.SpanContext())
+				// Detached from the request on purpose; the cache bounds the
+				// write with its own cache.setTimeout.
+				tracedCtx := trace.ContextWithSpanContext(n.appCtx, forwardSpan.SpanContext())
 				err := n.cacheDal.Set(tracedCtx, req, resp)
 				if err != nil {
 					lg.Warn().Err(err).Msgf("could not store response in cache")
@@ -1648,7 +1739,7 @@ func (n *Network) GetFinality(ctx context.Context, req *common.NormalizedRequest
 						} else {
 							finality = common.DataFinalityStateUnfinalized
 						}
-						return finality
+						return n.applyUnfinalizedDepth(ctx, blockNumber, finality)
 					}
 				}
 			}
@@ -1663,7 +1754,7 @@ func (n *Network) GetFinality(ctx context.Context, req *common.NormalizedRequest
 					} else {
 						finality = common.DataFinalityStateUnfinalized
 					}
-					return finality
+					return n.applyUnfinalizedDepth(ctx, blockNumber, finality)
 				}
 			}
 		}
@@ -1687,7 +1778,30 @@ func (n *Network) GetFinality(ctx context.Context, req *common.NormalizedRequest
 		// If we still can't determine, it remains unknown
 	}
 
-	return finality
+	return n.applyUnfinalizedDepth(ctx, blockNumber, finality)
+}
+
+// applyUnfinalizedDepth enforces evm.blockTagPolicy.unfinalizedDepth: a block
+// within that many blocks of the network's highest latest block is treated as
+// unfinalized (and cached with unfinalized TTLs) even if upstreams already
+// report it as finalized, giving reorg-averse consumers a safety margin. Only
+// finalized data is demoted: unknown and realtime keep their own TTLs.
+func (n *Network) applyUnfinalizedDepth(ctx context.Context, blockNumber int64, finality common.DataFinalityState) common.DataFinalityState {
+	if blockNumber <= 0 || finality != common.DataFinalityStateFinalized {
+		return finality
+	}
+	if n.cfg == nil || n.cfg.Evm == nil || n.cfg.Evm.BlockTagPolicy == nil {
+		return finality
+	}
+	depth := n.cfg.Evm.BlockTagPolicy.UnfinalizedDepth
+	if depth <= 0 {
+		return finality
+	}
+	head := n.EvmHighestLatestBlockNumber(ctx)
+	if head <= 0 || blockNumber <= head-depth {
+		return finality
+	}
+	return common.DataFinalityStateUnfinalized
 }
 
 func (n *Network) doForward(execSpanCtx context.Context, u common.Upstream, req *common.NormalizedRequest, skipCacheRead, isHedgeAttempt bool) (*common.NormalizedResponse, error) {
@@ -2086,10 +2200,6 @@ func (n *Network) shouldHandleMethod(req *common.NormalizedRequest, method strin
 		}
 	}
 
-	if method == "eth_accounts" || method == "eth_sign" {
-		return common.NewErrNotImplemented("eth_accounts and eth_sign are not supported")
-	}
-
 	return nil
 }
 
@@ -2137,7 +2247,7 @@ func (n *Network) enrichStatePoller(ctx context.Context, method string, req *com
 					blkTag = s
 				}
 			}
-			if lg := n.logger; lg != nil && lg.GetLevel() <= zerolog.TraceLevel {
+			if lg := n.logger; lg != nil && common.LogLevelEnabled(lg, zerolog.TraceLevel) {
 				lg.Trace().
 					Str("blkTagFromEvmBlockRef", blkTag).
 					Interface("evmBlockRefRaw", req.EvmBlockRef()).
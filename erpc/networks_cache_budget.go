@@ -0,0 +1,122 @@
+package erpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errCacheReadWon cancels an upstream forward that lost the race to a cache
+// read which outlived its latency budget.
+var errCacheReadWon = errors.New("response served from cache while forwarding upstream")
+
+type cacheReadResult struct {
+	resp *common.NormalizedResponse
+	err  error
+}
+
+// getFromCache reads the cache within the matching policies' maxReadLatency.
+// When the budget runs out first, it returns no response and a channel that
+// delivers the read's outcome once it completes, so the caller can forward
+// upstream meanwhile.
+func (n *Network) getFromCache(ctx context.Context, req *common.NormalizedRequest) (*common.NormalizedResponse, <-chan cacheReadResult, error) {
+	var budget time.Duration
+	if cache, ok := n.cacheDal.(*evm.EvmJsonRpcCache); ok && cache != nil {
+		budget = cache.ReadLatencyBudget(ctx, req)
+	}
+	if budget <= 0 {
+		resp, err := n.cacheDal.Get(ctx, req)
+		return resp, nil, err
+	}
+
+	done := make(chan cacheReadResult, 1)
+	go func() {
+		resp, err := n.cacheDal.Get(ctx, req)
+		done <- cacheReadResult{resp: resp, err: err}
+	}()
+	timer := time.NewTimer(budget)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.resp, nil, r.err
+	case <-timer.C:
+		return nil, done, nil
+	}
+}
+
+// raceLateCacheRead runs the upstream forward alongside a cache read that
+// outlived its latency budget and serves whichever answers first. A cache hit
+// cancels the forward; a miss or error leaves the forward to finish. When the
+// forward wins, its response is written back to the cache as usual. Whichever
+// side loses is released once it completes.
+func (n *Network) raceLateCacheRead(
+	ctx context.Context,
+	lg *zerolog.Logger,
+	req *common.NormalizedRequest,
+	method string,
+	forwardSpan trace.Span,
+	mlx *Multiplexer,
+	pending <-chan cacheReadResult,
+	forward func(ctx context.Context) (*common.NormalizedResponse, error),
+) (*common.NormalizedResponse, error) {
+	forwardSpan.SetAttributes(attribute.Bool("cache.budget_exceeded", true))
+	fwdCtx, cancel := context.WithCancelCause(ctx)
+	forwarded := make(chan cacheReadResult, 1)
+	go func() {
+		resp, err := forward(fwdCtx)
+		forwarded <- cacheReadResult{resp: resp, err: err}
+	}()
+
+	for {
+		select {
+		case r := <-forwarded:
+			cancel(nil)
+			if pending != nil {
+				go releaseLate(pending)
+			}
+			telemetry.CounterHandle(telemetry.MetricCacheGetBudgetExceededTotal,
+				n.projectId, req.NetworkLabel(), method, "upstream",
+			).Inc()
+			return r.resp, r.err
+		case r := <-pending:
+			if r.err != nil || r.resp == nil || r.resp.IsObjectNull(ctx) {
+				// Nothing to serve from the cache; keep waiting on the forward.
+				r.resp.Release()
+				pending = nil
+				continue
+			}
+			lg.Info().Msgf("response served from cache after exceeding its read latency budget")
+			cachedErr := evm.CachedResponseError(ctx, r.resp)
+			if cachedErr != nil {
+				r.resp.Release()
+				r.resp = nil
+			}
+			// Close before cancelling so multiplexed followers get the hit
+			// rather than the cancellation error of the forward.
+			if mlx != nil {
+				mlx.Close(ctx, r.resp, cachedErr)
+			}
+			cancel(errCacheReadWon)
+			go releaseLate(forwarded)
+			forwardSpan.SetAttributes(attribute.Bool("cache.hit", true))
+			telemetry.CounterHandle(telemetry.MetricCacheGetBudgetExceededTotal,
+				n.projectId, req.NetworkLabel(), method, "cache",
+			).Inc()
+			return r.resp, cachedErr
+		}
+	}
+}
+
+// releaseLate waits for the losing side of raceLateCacheRead and releases its
+// response, which nobody else holds.
+func releaseLate(ch <-chan cacheReadResult) {
+	r := <-ch
+	r.resp.Release()
+}
@@ -0,0 +1,165 @@
+package erpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	promUtil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNetwork_CacheReadLatencyBudget(t *testing.T) {
+	setup := func(t *testing.T, ctx context.Context, getDelay time.Duration) (*Network, *evm.EvmJsonRpcCache) {
+		cacheCfg := &common.CacheConfig{
+			Connectors: []*common.ConnectorConfig{
+				{
+					Id:     "slow",
+					Driver: "mock",
+					Mock: &common.MockConnectorConfig{
+						MemoryConnectorConfig: common.MemoryConnectorConfig{
+							MaxItems: 100_000, MaxTotalSize: "1GB",
+						},
+						GetDelay: getDelay,
+					},
+				},
+			},
+			Policies: []*common.CachePolicyConfig{
+				{
+					Network:        "*",
+					Method:         "*",
+					TTL:            common.FixedDuration(5 * time.Minute),
+					Connector:      "slow",
+					MaxReadLatency: common.Duration(50 * time.Millisecond),
+				},
+			},
+		}
+		require.NoError(t, cacheCfg.SetDefaults())
+		cache, err := evm.NewEvmJsonRpcCache(ctx, &log.Logger, cacheCfg)
+		require.NoError(t, err)
+		network := setupTestNetworkSimple(t, ctx, nil, nil)
+		network.cacheDal = cache.WithProjectId("prjA")
+		return network, cache
+	}
+	request := func(network *Network) *common.NormalizedRequest {
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x11","0x11"],"id":1}`))
+		req.SetNetwork(network)
+		return req
+	}
+	mockUpstream := func(delay time.Duration) {
+		gock.New("http://rpc1.localhost").
+			Post("/").
+			Filter(func(request *http.Request) bool {
+				return strings.Contains(util.SafeReadBody(request), "eth_getBalance")
+			}).
+			Reply(200).
+			Delay(delay).
+			JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1234"}`))
+	}
+
+	t.Run("UpstreamServesWhenCacheReadIsSlow", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+		defer util.AssertNoPendingMocks(t, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		network, _ := setup(t, ctx, 5*time.Second)
+		mockUpstream(0)
+
+		start := time.Now()
+		resp, err := network.Forward(ctx, request(network))
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), time.Second, "a slow cache read must not delay the upstream answer")
+		assert.False(t, resp.FromCache())
+		jrr, err := resp.JsonRpcResponse()
+		require.NoError(t, err)
+		assert.Equal(t, `"0x1234"`, jrr.GetResultString())
+	})
+
+	t.Run("LateCacheHitBeatsSlowerUpstream", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		network, cache := setup(t, ctx, 200*time.Millisecond)
+		mockUpstream(5 * time.Second)
+
+		seed := request(network)
+		jrr, err := common.NewJsonRpcResponse(1, "0xcached", nil)
+		require.NoError(t, err)
+		require.NoError(t, cache.WithProjectId("prjA").Set(ctx, seed, common.NewNormalizedResponse().WithRequest(seed).WithJsonRpcResponse(jrr)))
+
+		cacheWins := telemetry.MetricCacheGetBudgetExceededTotal.WithLabelValues(network.projectId, network.Label(), "eth_getBalance", "cache")
+		before := promUtil.ToFloat64(cacheWins)
+
+		start := time.Now()
+		resp, err := network.Forward(ctx, request(network))
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 2*time.Second, "a cache hit past the budget must not wait for the upstream")
+		assert.True(t, resp.FromCache())
+		assert.Equal(t, before+1, promUtil.ToFloat64(cacheWins), "the hit must come from the race with the upstream")
+		jrr, err = resp.JsonRpcResponse()
+		require.NoError(t, err)
+		assert.Equal(t, `"0xcached"`, jrr.GetResultString())
+	})
+
+	t.Run("ReleasesTheLosingResponse", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		network := setupTestNetworkSimple(t, ctx, nil, nil)
+		req := request(network)
+		response := func(result string) *common.NormalizedResponse {
+			jrr, err := common.NewJsonRpcResponse(1, result, nil)
+			require.NoError(t, err)
+			return common.NewNormalizedResponse().WithRequest(req).WithJsonRpcResponse(jrr)
+		}
+		race := func(pending chan cacheReadResult, forward func(ctx context.Context) (*common.NormalizedResponse, error)) *common.NormalizedResponse {
+			resp, err := network.raceLateCacheRead(ctx, &log.Logger, req, "eth_getBalance", trace.SpanFromContext(ctx), nil, pending, forward)
+			require.NoError(t, err)
+			return resp
+		}
+
+		t.Run("LateCacheReadAfterUpstreamWins", func(t *testing.T) {
+			pending := make(chan cacheReadResult, 1)
+			upstream := response("0x1")
+			resp := race(pending, func(context.Context) (*common.NormalizedResponse, error) { return upstream, nil })
+			assert.Same(t, upstream, resp)
+			cached := response("0x2")
+			pending <- cacheReadResult{resp: cached}
+			assert.Eventually(t, func() bool { return cached.Request() == nil }, time.Second, 5*time.Millisecond)
+			assert.NotNil(t, upstream.Request(), "the served response must stay intact")
+		})
+
+		t.Run("CancelledUpstreamAfterCacheWins", func(t *testing.T) {
+			pending := make(chan cacheReadResult, 1)
+			cached := response("0x2")
+			pending <- cacheReadResult{resp: cached}
+			upstream := response("0x1")
+			resp := race(pending, func(ctx context.Context) (*common.NormalizedResponse, error) {
+				<-ctx.Done()
+				return upstream, nil
+			})
+			assert.Same(t, cached, resp)
+			assert.Eventually(t, func() bool { return upstream.Request() == nil }, time.Second, 5*time.Millisecond)
+			assert.NotNil(t, cached.Request(), "the served response must stay intact")
+		})
+	})
+}
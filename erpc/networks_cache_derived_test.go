@@ -0,0 +1,137 @@
+package erpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_CacheCrossPopulate(t *testing.T) {
+	const blockHash = "0x8d3b8c1e0bd2a1d4ad0e4ce5b4c11c0e0bc2fb3cbe6a3e0f2f0a4e0a3c2b1a09"
+	const txHash = "0x1111111111111111111111111111111111111111111111111111111111111111"
+	const block = `{"jsonrpc":"2.0","id":1,"result":{"number":"0x1","hash":"` + blockHash + `","timestamp":"0x5","transactions":[{"hash":"` + txHash + `","blockHash":"` + blockHash + `","blockNumber":"0x1","transactionIndex":"0x0"}]}}`
+
+	setup := func(t *testing.T, ctx context.Context, cp *common.CacheCrossPopulateConfig) *Network {
+		cacheCfg := &common.CacheConfig{
+			Connectors: []*common.ConnectorConfig{
+				{
+					Id:     "mem",
+					Driver: "memory",
+					Memory: &common.MemoryConnectorConfig{
+						MaxItems: 100_000, MaxTotalSize: "1GB",
+					},
+				},
+			},
+			Policies: []*common.CachePolicyConfig{
+				{
+					Network:   "*",
+					Method:    "*",
+					TTL:       common.FixedDuration(5 * time.Minute),
+					Connector: "mem",
+				},
+			},
+			CrossPopulate: cp,
+		}
+		require.NoError(t, cacheCfg.SetDefaults())
+		cache, err := evm.NewEvmJsonRpcCache(ctx, &log.Logger, cacheCfg)
+		require.NoError(t, err)
+		network := setupTestNetworkSimple(t, ctx, nil, nil)
+		network.cacheDal = cache.WithProjectId("prjA")
+		return network
+	}
+	request := func(network *Network, method, params string) *common.NormalizedRequest {
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"` + method + `","params":` + params + `,"id":1}`))
+		req.SetNetwork(network)
+		return req
+	}
+	waitForCached := func(t *testing.T, ctx context.Context, network *Network, method, params string) *common.NormalizedResponse {
+		var resp *common.NormalizedResponse
+		require.Eventually(t, func() bool {
+			r, err := network.cacheDal.Get(ctx, request(network, method, params))
+			if err != nil || r == nil || r.IsObjectNull(ctx) {
+				return false
+			}
+			resp = r
+			return true
+		}, 2*time.Second, 10*time.Millisecond)
+		return resp
+	}
+
+	t.Run("BlockByNumberPopulatesBlockAndTransactionEntries", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+		defer util.AssertNoPendingMocks(t, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		network := setup(t, ctx, &common.CacheCrossPopulateConfig{})
+		gock.New("http://rpc1.localhost").
+			Post("/").
+			Times(1).
+			Filter(func(request *http.Request) bool {
+				return strings.Contains(util.SafeReadBody(request), `"0x1",true`)
+			}).
+			Reply(200).
+			JSON([]byte(block))
+
+		_, err := network.Forward(ctx, request(network, "eth_getBlockByNumber", `["0x1",true]`))
+		require.NoError(t, err)
+
+		resp := waitForCached(t, ctx, network, "eth_getBlockByHash", `["`+blockHash+`",true]`)
+		jrr, err := resp.JsonRpcResponse(ctx)
+		require.NoError(t, err)
+		hash, err := jrr.PeekStringByPath(ctx, "hash")
+		require.NoError(t, err)
+		assert.Equal(t, blockHash, hash)
+
+		for _, lookup := range [][2]string{
+			{"eth_getTransactionByBlockHashAndIndex", `["` + blockHash + `","0x0"]`},
+			{"eth_getTransactionByBlockNumberAndIndex", `["0x1","0x0"]`},
+			{"eth_getTransactionByHash", `["` + txHash + `"]`},
+		} {
+			resp := waitForCached(t, ctx, network, lookup[0], lookup[1])
+			jrr, err := resp.JsonRpcResponse(ctx)
+			require.NoError(t, err)
+			hash, err := jrr.PeekStringByPath(ctx, "hash")
+			require.NoError(t, err)
+			assert.Equal(t, txHash, hash, lookup[0])
+		}
+	})
+
+	t.Run("OffWithoutConfig", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		network := setup(t, ctx, nil)
+		gock.New("http://rpc1.localhost").
+			Post("/").
+			Times(1).
+			Filter(func(request *http.Request) bool {
+				return strings.Contains(util.SafeReadBody(request), `"0x1",true`)
+			}).
+			Reply(200).
+			JSON([]byte(block))
+
+		_, err := network.Forward(ctx, request(network, "eth_getBlockByNumber", `["0x1",true]`))
+		require.NoError(t, err)
+		waitForCached(t, ctx, network, "eth_getBlockByNumber", `["0x1",true]`)
+
+		resp, err := network.cacheDal.Get(ctx, request(network, "eth_getBlockByHash", `["`+blockHash+`",true]`))
+		require.NoError(t, err)
+		assert.True(t, resp == nil || resp.IsObjectNull(ctx))
+	})
+}
@@ -0,0 +1,41 @@
+package erpc
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// storeErrorInCache hands a failed request's error to the cache in the
+// background, the same way successful responses are written. The cache only
+// keeps errors it classifies as deterministic (cache.errorResults).
+func (n *Network) storeErrorInCache(lg *zerolog.Logger, req *common.NormalizedRequest, method string, err error, forwardSpan trace.Span) {
+	cache, ok := n.cacheDal.(*evm.EvmJsonRpcCache)
+	if !ok || !cache.CachesErrorResults() {
+		return
+	}
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				telemetry.MetricUnexpectedPanicTotal.WithLabelValues(
+					"cache-set-error",
+					fmt.Sprintf("network:%s method:%s", n.networkId, method),
+					common.ErrorFingerprint(rec),
+				).Inc()
+				lg.Error().
+					Interface("panic", rec).
+					Str("stack", string(debug.Stack())).
+					Msgf("unexpected panic on cache-set-error")
+			}
+		}()
+		tracedCtx := trace.ContextWithSpanContext(n.appCtx, forwardSpan.SpanContext())
+		if err := cache.SetError(tracedCtx, req, err); err != nil {
+			lg.Warn().Err(err).Msgf("could not store error result in cache")
+		}
+	}()
+}
@@ -0,0 +1,142 @@
+package erpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	promUtil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_CacheErrorResults(t *testing.T) {
+	setup := func(t *testing.T, ctx context.Context, staleWhileRevalidate time.Duration) *Network {
+		cacheCfg := &common.CacheConfig{
+			Connectors: []*common.ConnectorConfig{
+				{
+					Id:     "mem",
+					Driver: "memory",
+					Memory: &common.MemoryConnectorConfig{
+						MaxItems: 100_000, MaxTotalSize: "1GB",
+					},
+				},
+			},
+			Policies: []*common.CachePolicyConfig{
+				{
+					Network:   "*",
+					Method:    "*",
+					TTL:       common.FixedDuration(5 * time.Minute),
+					Connector: "mem",
+					// Stamped entries share the envelope chain with error results.
+					StaleWhileRevalidate: common.Duration(staleWhileRevalidate),
+				},
+			},
+			ErrorResults: &common.CacheErrorResultsConfig{},
+		}
+		require.NoError(t, cacheCfg.SetDefaults())
+		cache, err := evm.NewEvmJsonRpcCache(ctx, &log.Logger, cacheCfg)
+		require.NoError(t, err)
+		network := setupTestNetworkSimple(t, ctx, nil, nil)
+		network.cacheDal = cache.WithProjectId("prjA")
+		return network
+	}
+	// Block 0x1 is well below the finalized block served by the state poller mocks.
+	request := func(network *Network, block string) *common.NormalizedRequest {
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_call","params":[{"to":"0x0000000000000000000000000000000000000001","data":"0xdeadbeef"},"` + block + `"],"id":1}`))
+		req.SetNetwork(network)
+		return req
+	}
+	mockUpstream := func(times int, status int, body string) {
+		gock.New("http://rpc1.localhost").
+			Post("/").
+			Times(times).
+			Filter(func(request *http.Request) bool {
+				return strings.Contains(util.SafeReadBody(request), "eth_call")
+			}).
+			Reply(status).
+			JSON([]byte(body))
+	}
+	const reverted = `{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted: not owner","data":"0x08c379a0"}}`
+
+	assertRevertServedFromCache := func(t *testing.T, staleWhileRevalidate time.Duration) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+		defer util.AssertNoPendingMocks(t, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		network := setup(t, ctx, staleWhileRevalidate)
+		mockUpstream(1, 200, reverted)
+
+		stored := telemetry.MetricCacheErrorResultSetTotal.WithLabelValues("prjA", network.Label(), "eth_call", "mem", "3")
+		before := promUtil.ToFloat64(stored)
+		_, err := network.Forward(ctx, request(network, "0x1"))
+		require.True(t, common.HasErrorCode(err, common.ErrCodeEndpointExecutionException), "got %v", err)
+		require.Eventually(t, func() bool { return promUtil.ToFloat64(stored) == before+1 }, 2*time.Second, 10*time.Millisecond)
+
+		// No upstream mock is left, so this one can only come from the cache.
+		_, err = network.Forward(ctx, request(network, "0x1"))
+		require.True(t, common.HasErrorCode(err, common.ErrCodeEndpointExecutionException), "got %v", err)
+		jre := &common.ErrJsonRpcExceptionInternal{}
+		require.ErrorAs(t, err, &jre)
+		assert.Equal(t, common.JsonRpcErrorEvmReverted, jre.NormalizedCode())
+		assert.Equal(t, "execution reverted: not owner", jre.Message)
+		assert.Equal(t, "0x08c379a0", jre.Details["data"])
+	}
+
+	t.Run("FinalizedRevertIsServedFromCache", func(t *testing.T) {
+		assertRevertServedFromCache(t, 0)
+	})
+
+	t.Run("FinalizedRevertIsServedFromCacheWithStaleWhileRevalidate", func(t *testing.T) {
+		assertRevertServedFromCache(t, time.Minute)
+	})
+
+	t.Run("UnfinalizedRevertIsNotCached", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+		defer util.AssertNoPendingMocks(t, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		network := setup(t, ctx, 0)
+		mockUpstream(2, 200, reverted)
+
+		for range 2 {
+			_, err := network.Forward(ctx, request(network, "latest"))
+			require.True(t, common.HasErrorCode(err, common.ErrCodeEndpointExecutionException), "got %v", err)
+			time.Sleep(100 * time.Millisecond)
+		}
+	})
+
+	t.Run("TransientErrorsAreNotCached", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		network := setup(t, ctx, 0)
+		mockUpstream(1, 429, `{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"rate limit exceeded"}}`)
+		mockUpstream(1, 200, `{"jsonrpc":"2.0","id":1,"result":"0x01"}`)
+
+		_, err := network.Forward(ctx, request(network, "0x1"))
+		require.Error(t, err)
+		time.Sleep(100 * time.Millisecond)
+
+		resp, err := network.Forward(ctx, request(network, "0x1"))
+		require.NoError(t, err)
+		assert.False(t, resp.FromCache())
+	})
+}
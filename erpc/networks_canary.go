@@ -0,0 +1,64 @@
+package erpc
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/upstream"
+)
+
+// applyCanaryWeights enforces canary rollouts on the ordered upstream list of
+// a request: a canary at weight w is moved to the front for a w share of
+// requests and left out of the list otherwise, so it serves roughly w of the
+// traffic including retries and hedges. Upstreams without an in-progress
+// rollout keep the order chosen by the selection policy.
+func (n *Network) applyCanaryWeights(ctx context.Context, upsList []common.Upstream) []common.Upstream {
+	var canaries []*upstream.Upstream
+	for _, u := range upsList {
+		cfg := u.Config()
+		if cfg == nil || cfg.Canary == nil || !cfg.Canary.Enabled {
+			continue
+		}
+		if ups, ok := u.(*upstream.Upstream); ok {
+			canaries = append(canaries, ups)
+		}
+	}
+	if len(canaries) == 0 {
+		return upsList
+	}
+
+	// Compare against every upstream of the network, not just the ones the
+	// policy kept for this request, so a canary excluded for being unhealthy
+	// still gets rolled back.
+	peers := n.upstreamsRegistry.GetNetworkUpstreams(ctx, n.networkId)
+	var promoted []common.Upstream
+	dropped := make(map[common.Upstream]bool, len(canaries))
+	for _, cu := range canaries {
+		cu.EvaluateCanary(peers)
+		weight, rollingOut := cu.CanaryWeight()
+		if !rollingOut {
+			continue
+		}
+		if weight > 0 && rand.Float64() < weight {
+			promoted = append(promoted, cu)
+		}
+		dropped[cu] = true
+	}
+	if len(dropped) == 0 {
+		return upsList
+	}
+
+	out := make([]common.Upstream, 0, len(upsList))
+	out = append(out, promoted...)
+	for _, u := range upsList {
+		if !dropped[u] {
+			out = append(out, u)
+		}
+	}
+	// A network made only of rolling-out canaries still has to serve requests.
+	if len(out) == 0 {
+		return upsList
+	}
+	return out
+}
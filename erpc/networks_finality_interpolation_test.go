@@ -174,3 +174,38 @@ func TestInterpolation_NumericBlockFinality(t *testing.T) {
 	finalityAfter := req.Finality(ctx)
 	assert.NotEqual(t, common.DataFinalityStateRealtime, finalityAfter, "Numeric block should still NOT be realtime after normalization")
 }
+
+// Test that evm.blockTagPolicy.unfinalizedDepth keeps near-head blocks unfinalized
+func TestFinality_BlockTagPolicy_UnfinalizedDepth(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	util.SetupMocksForEvmStatePoller()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	network, _ := setupTestNetworkForInterpolation(t, ctx, &common.NetworkConfig{
+		Architecture: common.ArchitectureEvm,
+		Evm: &common.EvmNetworkConfig{
+			ChainId: 123,
+			BlockTagPolicy: &common.EvmBlockTagPolicyConfig{
+				// Latest is 0x11118888, finalized is 0x11117777 (4369 blocks apart)
+				UnfinalizedDepth: 5000,
+			},
+		},
+	})
+
+	// Finalized per upstreams but within the configured depth from the head
+	req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0xabc","0x11117770"]}`))
+	req.SetNetwork(network)
+	assert.Equal(t, common.DataFinalityStateUnfinalized, network.GetFinality(ctx, req, nil))
+
+	// Deeper than the configured depth stays finalized
+	req = common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0xabc","0x11110000"]}`))
+	req.SetNetwork(network)
+	assert.Equal(t, common.DataFinalityStateFinalized, network.GetFinality(ctx, req, nil))
+
+	// Only finalized data is demoted
+	assert.Equal(t, common.DataFinalityStateUnknown, network.applyUnfinalizedDepth(ctx, 0x11117770, common.DataFinalityStateUnknown))
+	assert.Equal(t, common.DataFinalityStateRealtime, network.applyUnfinalizedDepth(ctx, 0x11117770, common.DataFinalityStateRealtime))
+}
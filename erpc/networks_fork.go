@@ -0,0 +1,62 @@
+package erpc
+
+import (
+	"context"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+)
+
+// forkConfig returns the network's fork settings, or nil when the network is
+// not a fork of a live chain.
+func (n *Network) forkConfig() *common.EvmForkConfig {
+	if n.cfg == nil || n.cfg.Evm == nil {
+		return nil
+	}
+	return n.cfg.Evm.Fork
+}
+
+// applyForkRouting splits a forked network's traffic: a request for a concrete
+// block at or below the fork block reads canonical chain data and goes to the
+// live upstreams. Everything else (tags, block hashes, writes, post-fork
+// blocks) goes to the fork nodes, which own the forked state.
+func (n *Network) applyForkRouting(ctx context.Context, req *common.NormalizedRequest, upsList []common.Upstream) []common.Upstream {
+	fork := n.forkConfig()
+	if fork == nil {
+		return upsList
+	}
+	var live, forkNodes []common.Upstream
+	for _, u := range upsList {
+		if fork.IsForkUpstream(u) {
+			forkNodes = append(forkNodes, u)
+		} else {
+			live = append(live, u)
+		}
+	}
+	if len(live) > 0 {
+		blockRef, blockNumber, _ := evm.ExtractBlockReferenceFromRequest(ctx, req)
+		if evm.IsPreForkBlock(fork, blockRef, blockNumber) {
+			return live
+		}
+	}
+	// A fork node serves pre-fork blocks as well (it proxies them to its own
+	// fork source), so it also covers for live upstreams being unavailable.
+	return forkNodes
+}
+
+// forkNodesOnly narrows the upstreams that decide a forked network's head to
+// the fork nodes: the live upstreams are ahead on the real chain, and their
+// head does not exist on the fork.
+func (n *Network) forkNodesOnly(ups []common.Upstream) []common.Upstream {
+	fork := n.forkConfig()
+	if fork == nil {
+		return ups
+	}
+	out := make([]common.Upstream, 0, len(ups))
+	for _, u := range ups {
+		if fork.IsForkUpstream(u) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
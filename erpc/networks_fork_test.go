@@ -0,0 +1,64 @@
+package erpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetwork_ApplyForkRouting(t *testing.T) {
+	ctx := context.Background()
+	live1 := common.NewFakeUpstream("mainnet-1")
+	live2 := common.NewFakeUpstream("mainnet-2")
+	anvil := common.NewFakeUpstream("anvil", common.WithTags("fork"))
+	all := []common.Upstream{live1, anvil, live2}
+
+	n := &Network{
+		networkId: "evm:1",
+		cfg: &common.NetworkConfig{
+			Architecture: common.ArchitectureEvm,
+			Evm: &common.EvmNetworkConfig{
+				ChainId: 1,
+				Fork:    &common.EvmForkConfig{BlockNumber: 1000, Upstreams: []string{"fork"}, InstanceId: "1000"},
+			},
+		},
+	}
+	route := func(body string, ups []common.Upstream) []common.Upstream {
+		req := common.NewNormalizedRequest([]byte(body))
+		req.SetNetwork(n)
+		return n.applyForkRouting(ctx, req, ups)
+	}
+
+	t.Run("PreForkBlockGoesToLiveUpstreams", func(t *testing.T) {
+		got := route(`{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["0x3e8",false]}`, all)
+		assert.Equal(t, []common.Upstream{live1, live2}, got)
+	})
+
+	t.Run("PostForkBlockGoesToForkNode", func(t *testing.T) {
+		got := route(`{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["0x3e9",false]}`, all)
+		assert.Equal(t, []common.Upstream{anvil}, got)
+	})
+
+	t.Run("WritesGoToForkNode", func(t *testing.T) {
+		got := route(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x02f8"]}`, all)
+		assert.Equal(t, []common.Upstream{anvil}, got)
+	})
+
+	t.Run("BlockHashesGoToForkNode", func(t *testing.T) {
+		got := route(`{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByHash","params":["0x8d3b8c1e0bd2a1d4ad0e4ce5b4c11c0e0bc2fb3cbe6a3e0f2f0a4e0a3c2b1a09",false]}`, all)
+		assert.Equal(t, []common.Upstream{anvil}, got)
+	})
+
+	t.Run("PreForkFallsBackToForkNodeWithoutLiveUpstreams", func(t *testing.T) {
+		got := route(`{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["0x10",false]}`, []common.Upstream{anvil})
+		assert.Equal(t, []common.Upstream{anvil}, got)
+	})
+
+	t.Run("NonForkedNetworkUnchanged", func(t *testing.T) {
+		plain := &Network{networkId: "evm:1", cfg: &common.NetworkConfig{Architecture: common.ArchitectureEvm, Evm: &common.EvmNetworkConfig{ChainId: 1}}}
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`))
+		assert.Equal(t, all, plain.applyForkRouting(ctx, req, all))
+	})
+}
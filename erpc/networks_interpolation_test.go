@@ -2116,3 +2116,47 @@ func TestInterpolation_UpstreamSkipping_DisabledByMethodConfig(t *testing.T) {
 	require.NotNil(t, resp.Upstream())
 	assert.Equal(t, "rpc1", resp.Upstream().Id(), "with enforcement disabled for method, upstream should not be skipped")
 }
+
+// Test that evm.blockTagPolicy rewrites "latest" to the policy tag before interpolation
+func TestInterpolation_BlockTagPolicy_RewritesLatest(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	util.SetupMocksForEvmStatePoller()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	network, _ := setupTestNetworkForInterpolation(t, ctx, &common.NetworkConfig{
+		Architecture: common.ArchitectureEvm,
+		Evm: &common.EvmNetworkConfig{
+			ChainId: 123,
+			BlockTagPolicy: &common.EvmBlockTagPolicyConfig{
+				RewriteLatestTo: "safe",
+				Methods:         []string{"eth_getCode", "eth_getBlockByNumber"},
+			},
+		},
+	})
+
+	req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getCode","params":["0xabc","latest"]}`))
+	req.SetNetwork(network)
+	jrq, err := req.JsonRpcRequest()
+	require.NoError(t, err)
+	evm.NormalizeHttpJsonRpc(ctx, req, jrq)
+	assert.Equal(t, "safe", jrq.Params[1], "latest should be rewritten to the policy tag")
+
+	req = common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["latest",false]}`))
+	req.SetNetwork(network)
+	jrq, err = req.JsonRpcRequest()
+	require.NoError(t, err)
+	evm.NormalizeHttpJsonRpc(ctx, req, jrq)
+	assert.Equal(t, "safe", jrq.Params[0], "policy applies even where latest is not interpolated")
+
+	// Methods outside the policy keep the regular latest → hex interpolation
+	req = common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0xabc","latest"]}`))
+	req.SetNetwork(network)
+	jrq, err = req.JsonRpcRequest()
+	require.NoError(t, err)
+	evm.NormalizeHttpJsonRpc(ctx, req, jrq)
+	assert.NotEqual(t, "safe", jrq.Params[1])
+	assert.True(t, strings.HasPrefix(jrq.Params[1].(string), "0x"), "expected hex block number, got %v", jrq.Params[1])
+}
@@ -0,0 +1,37 @@
+package erpc
+
+import (
+	"github.com/erpc/erpc/common"
+)
+
+// applyPreferredUpstreams moves the project's preferred upstreams to the
+// front of a request's upstream list, in the configured order, so they are
+// tried first whatever their score. The other upstreams keep the order chosen
+// by the selection policy and serve as the fallback for retries and hedges.
+// A preferred upstream the policy (or fork routing) left out of the list is
+// not added back.
+func (n *Network) applyPreferredUpstreams(upsList []common.Upstream) []common.Upstream {
+	if len(n.preferredUpstreams) == 0 || len(upsList) < 2 {
+		return upsList
+	}
+	out := make([]common.Upstream, 0, len(upsList))
+	moved := make(map[common.Upstream]bool, len(n.preferredUpstreams))
+	for _, id := range n.preferredUpstreams {
+		for _, u := range upsList {
+			if u.Id() == id && !moved[u] {
+				out = append(out, u)
+				moved[u] = true
+				break
+			}
+		}
+	}
+	if len(moved) == 0 {
+		return upsList
+	}
+	for _, u := range upsList {
+		if !moved[u] {
+			out = append(out, u)
+		}
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package erpc
+
+import (
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetwork_ApplyPreferredUpstreams(t *testing.T) {
+	shared1 := common.NewFakeUpstream("shared-1")
+	shared2 := common.NewFakeUpstream("shared-2")
+	dedicated1 := common.NewFakeUpstream("dedicated-1")
+	dedicated2 := common.NewFakeUpstream("dedicated-2")
+	all := []common.Upstream{shared1, dedicated2, shared2, dedicated1}
+
+	t.Run("PreferredGoFirstInConfiguredOrder", func(t *testing.T) {
+		n := &Network{preferredUpstreams: []string{"dedicated-1", "dedicated-2"}}
+		got := n.applyPreferredUpstreams(all)
+		assert.Equal(t, []common.Upstream{dedicated1, dedicated2, shared1, shared2}, got)
+	})
+
+	t.Run("PreferredLeftOutByPolicyIsNotAdded", func(t *testing.T) {
+		n := &Network{preferredUpstreams: []string{"dedicated-1", "dedicated-2"}}
+		got := n.applyPreferredUpstreams([]common.Upstream{shared1, dedicated2, shared2})
+		assert.Equal(t, []common.Upstream{dedicated2, shared1, shared2}, got)
+	})
+
+	t.Run("UnknownIdsKeepPolicyOrder", func(t *testing.T) {
+		n := &Network{preferredUpstreams: []string{"other"}}
+		assert.Equal(t, all, n.applyPreferredUpstreams(all))
+	})
+
+	t.Run("NoPreferredUpstreams", func(t *testing.T) {
+		n := &Network{}
+		assert.Equal(t, all, n.applyPreferredUpstreams(all))
+	})
+}
@@ -0,0 +1,243 @@
+package erpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+)
+
+// blockPrefetchMaxBackfill bounds how many blocks one head advance prefetches.
+// A bigger jump is a cold start or a catch-up rather than blocks clients are
+// about to ask for, so only its newest blocks are prefetched.
+const blockPrefetchMaxBackfill = 8
+
+// blockPrefetchTimeout bounds the prefetch requests of one block.
+const blockPrefetchTimeout = 30 * time.Second
+
+// blockPrefetchContextKey marks prefetch requests so they are not counted as
+// client interest in the methods they fetch.
+const blockPrefetchContextKey common.ContextKey = "blockPrefetch"
+
+// blockPrefetcher fetches the receipts (and optionally the blocks and logs) of
+// new heads through the network, so they are in the cache by the time clients
+// ask for them (evm.receiptsPrefetch).
+type blockPrefetcher struct {
+	network *Network
+	cfg     *common.EvmReceiptsPrefetchConfig
+
+	// last is the highest block handed off for prefetching. Every upstream's
+	// poller reports the same heads; only the first report of a block counts.
+	last  atomic.Int64
+	slots chan struct{}
+
+	// receiptsSeenAt / blocksSeenAt / logsSeenAt are the unix millis of the
+	// last client request of eth_getBlockReceipts / a block by number or
+	// hash / eth_getLogs.
+	receiptsSeenAt atomic.Int64
+	blocksSeenAt   atomic.Int64
+	logsSeenAt     atomic.Int64
+	// subscribers counts live block stream subscribers of the network.
+	subscribers atomic.Int32
+
+	attachMu sync.Mutex
+	attached map[string]bool
+}
+
+func newBlockPrefetcher(network *Network, cfg *common.EvmReceiptsPrefetchConfig) *blockPrefetcher {
+	maxConcurrency := cfg.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = 4
+	}
+	return &blockPrefetcher{
+		network:  network,
+		cfg:      cfg,
+		slots:    make(chan struct{}, maxConcurrency),
+		attached: make(map[string]bool),
+	}
+}
+
+// attach registers onHead with the state poller of every upstream of the
+// network not registered yet. OnLatestBlock registrations are permanent, so
+// each upstream is only registered once however often this is called.
+func (p *blockPrefetcher) attach(ctx context.Context) {
+	p.attachMu.Lock()
+	defer p.attachMu.Unlock()
+	for _, up := range p.network.upstreamsRegistry.GetNetworkUpstreams(ctx, p.network.networkId) {
+		if p.attached[up.Id()] {
+			continue
+		}
+		sp := up.EvmStatePoller()
+		if sp == nil || sp.IsObjectNull() {
+			continue
+		}
+		if reg, ok := sp.(interface{ OnLatestBlock(func(int64)) }); ok {
+			reg.OnLatestBlock(p.onHead)
+			p.attached[up.Id()] = true
+		}
+	}
+}
+
+// observe records a client request of method as interest in its data.
+func (p *blockPrefetcher) observe(ctx context.Context, method string) {
+	if ctx.Value(blockPrefetchContextKey) != nil {
+		return
+	}
+	switch method {
+	case "eth_getBlockReceipts":
+		p.receiptsSeenAt.Store(time.Now().UnixMilli())
+	case "eth_getBlockByNumber", "eth_getBlockByHash":
+		p.blocksSeenAt.Store(time.Now().UnixMilli())
+	case "eth_getLogs":
+		p.logsSeenAt.Store(time.Now().UnixMilli())
+	}
+}
+
+// interestedMethods returns the enabled methods worth prefetching right now:
+// those requested by clients within the interest window, or all of them with
+// always or while a block stream subscriber is connected. Blocks come first
+// and stand for both eth_getBlockByNumber and eth_getBlockByHash.
+func (p *blockPrefetcher) interestedMethods() []string {
+	all := (p.cfg.Always != nil && *p.cfg.Always) || p.subscribers.Load() > 0
+	since := time.Now().Add(-p.cfg.InterestWindow.Duration()).UnixMilli()
+	var methods []string
+	if p.cfg.Blocks != nil && *p.cfg.Blocks && (all || p.blocksSeenAt.Load() >= since) {
+		methods = append(methods, "eth_getBlockByNumber")
+	}
+	if (p.cfg.Receipts == nil || *p.cfg.Receipts) && (all || p.receiptsSeenAt.Load() >= since) {
+		methods = append(methods, "eth_getBlockReceipts")
+	}
+	if p.cfg.Logs != nil && *p.cfg.Logs && (all || p.logsSeenAt.Load() >= since) {
+		methods = append(methods, "eth_getLogs")
+	}
+	return methods
+}
+
+// onHead runs inside the poller's latest-block update path, so it only claims
+// the new blocks and hands each one off to a goroutine when a slot is free;
+// blocks that find no free slot are not prefetched.
+func (p *blockPrefetcher) onHead(head int64) {
+	var last int64
+	for {
+		last = p.last.Load()
+		if head <= last {
+			return
+		}
+		if p.last.CompareAndSwap(last, head) {
+			break
+		}
+	}
+	methods := p.interestedMethods()
+	if len(methods) == 0 {
+		return
+	}
+	from := last + 1
+	if last == 0 {
+		from = head
+	} else if head-from >= blockPrefetchMaxBackfill {
+		from = head - blockPrefetchMaxBackfill + 1
+	}
+	// Newest first: when slots run out it is the older blocks that are skipped.
+	for bn := head; bn >= from; bn-- {
+		select {
+		case p.slots <- struct{}{}:
+			go func(bn int64) {
+				defer func() { <-p.slots }()
+				p.prefetch(bn, methods)
+			}(bn)
+		default:
+			for _, method := range methods {
+				p.count(method, "skipped")
+			}
+		}
+	}
+}
+
+// prefetch forwards the requests of one block through the network, which
+// caches their responses like those of any client request.
+func (p *blockPrefetcher) prefetch(bn int64, methods []string) {
+	ctx, cancel := context.WithTimeout(context.WithValue(p.network.appCtx, blockPrefetchContextKey, true), blockPrefetchTimeout)
+	defer cancel()
+
+	hexBn := fmt.Sprintf("0x%x", bn)
+	for _, method := range methods {
+		var params []interface{}
+		var hash string
+		var onResult func(*common.JsonRpcResponse)
+		switch method {
+		case "eth_getLogs":
+			params = []interface{}{map[string]interface{}{"fromBlock": hexBn, "toBlock": hexBn}}
+		case "eth_getBlockByNumber":
+			params = []interface{}{hexBn, p.fullTransactions()}
+			onResult = func(jrr *common.JsonRpcResponse) {
+				hash, _ = jrr.PeekStringByPath(ctx, "hash")
+			}
+		default:
+			params = []interface{}{hexBn}
+		}
+		if err := p.forward(ctx, method, params, onResult); err != nil {
+			p.count(method, "error")
+			p.network.logger.Debug().Err(err).Str("method", method).Int64("blockNumber", bn).Msg("could not prefetch new head data")
+			continue
+		}
+		p.count(method, "success")
+
+		// The block by hash is cached under its own key; its hash is only
+		// known once the block by number is in.
+		if method == "eth_getBlockByNumber" && hash != "" {
+			if err := p.forward(ctx, "eth_getBlockByHash", []interface{}{hash, p.fullTransactions()}, nil); err != nil {
+				p.count("eth_getBlockByHash", "error")
+				p.network.logger.Debug().Err(err).Str("method", "eth_getBlockByHash").Int64("blockNumber", bn).Msg("could not prefetch new head data")
+				continue
+			}
+			p.count("eth_getBlockByHash", "success")
+		}
+	}
+}
+
+func (p *blockPrefetcher) fullTransactions() bool {
+	return p.cfg.BlockFullTransactions != nil && *p.cfg.BlockFullTransactions
+}
+
+// forward sends one request through the network. onResult, when set, is
+// called with the response before it is released.
+func (p *blockPrefetcher) forward(ctx context.Context, method string, params []interface{}, onResult func(*common.JsonRpcResponse)) error {
+	jrq := common.NewJsonRpcRequest(method, params)
+	if err := jrq.SetID(util.RandomID()); err != nil {
+		return err
+	}
+	req := common.NewNormalizedRequestFromJsonRpcRequest(jrq)
+	resp, err := p.network.Forward(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return nil
+	}
+	defer resp.Release()
+	jrr, err := resp.JsonRpcResponse(ctx)
+	if err != nil {
+		return err
+	}
+	if jrr != nil && jrr.Error != nil {
+		return jrr.Error
+	}
+	if jrr != nil && onResult != nil {
+		onResult(jrr)
+	}
+	return nil
+}
+
+func (p *blockPrefetcher) count(method, outcome string) {
+	telemetry.CounterHandle(telemetry.MetricNetworkEvmPrefetchTotal,
+		p.network.projectId,
+		p.network.Label(),
+		method,
+		outcome,
+	).Inc()
+}
@@ -0,0 +1,171 @@
+package erpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_ReceiptsPrefetch(t *testing.T) {
+	const head = int64(0x11118889)
+	const receipts = `{"jsonrpc":"2.0","id":1,"result":[{"transactionHash":"0x1111111111111111111111111111111111111111111111111111111111111111","blockNumber":"0x11118889","logs":[]}]}`
+
+	setup := func(t *testing.T, ctx context.Context, prefetchCfg *common.EvmReceiptsPrefetchConfig) *Network {
+		cacheCfg := &common.CacheConfig{
+			Connectors: []*common.ConnectorConfig{
+				{
+					Id:     "mem",
+					Driver: "memory",
+					Memory: &common.MemoryConnectorConfig{
+						MaxItems: 100_000, MaxTotalSize: "1GB",
+					},
+				},
+			},
+			Policies: []*common.CachePolicyConfig{
+				{
+					Network:   "*",
+					Method:    "*",
+					Finality:  common.DataFinalityStateUnfinalized,
+					TTL:       common.FixedDuration(5 * time.Minute),
+					Connector: "mem",
+				},
+			},
+		}
+		require.NoError(t, cacheCfg.SetDefaults())
+		cache, err := evm.NewEvmJsonRpcCache(ctx, &log.Logger, cacheCfg)
+		require.NoError(t, err)
+
+		network := setupTestNetworkSimple(t, ctx, nil, &common.NetworkConfig{
+			Architecture: common.ArchitectureEvm,
+			Evm: &common.EvmNetworkConfig{
+				ChainId:          123,
+				ReceiptsPrefetch: prefetchCfg,
+			},
+		})
+		network.cacheDal = cache.WithProjectId("prjA")
+		require.NotNil(t, network.prefetcher)
+		// The test helper bootstraps the network before its upstream, so the
+		// state poller only exists now.
+		network.prefetcher.attach(ctx)
+		return network
+	}
+	request := func(network *Network, method, params string) *common.NormalizedRequest {
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"` + method + `","params":` + params + `,"id":1}`))
+		req.SetNetwork(network)
+		return req
+	}
+	mockReceipts := func(blockNumber string) {
+		gock.New("http://rpc1.localhost").
+			Post("/").
+			Times(1).
+			Filter(func(request *http.Request) bool {
+				body := util.SafeReadBody(request)
+				return strings.Contains(body, "eth_getBlockReceipts") && strings.Contains(body, blockNumber)
+			}).
+			Reply(200).
+			JSON([]byte(receipts))
+	}
+	suggestHead := func(network *Network, bn int64) {
+		ups := network.upstreamsRegistry.GetNetworkUpstreams(context.TODO(), util.EvmNetworkId(123))
+		ups[0].EvmStatePoller().SuggestLatestBlock(bn)
+	}
+
+	t.Run("PrefetchesReceiptsOfNewHeadAfterClientInterest", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+		defer util.AssertNoPendingMocks(t, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		network := setup(t, ctx, &common.EvmReceiptsPrefetchConfig{MaxConcurrency: 2, InterestWindow: common.Duration(time.Minute)})
+
+		mockReceipts(`"0x11118888"`)
+		resp, err := network.Forward(ctx, request(network, "eth_getBlockReceipts", `["0x11118888"]`))
+		require.NoError(t, err)
+		resp.Release()
+
+		mockReceipts(`"0x11118889"`)
+		suggestHead(network, head)
+
+		require.Eventually(t, func() bool {
+			r, err := network.cacheDal.Get(ctx, request(network, "eth_getBlockReceipts", `["0x11118889"]`))
+			return err == nil && r != nil && !r.IsObjectNull(ctx)
+		}, 5*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("SkipsWithoutInterest", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		network := setup(t, ctx, &common.EvmReceiptsPrefetchConfig{MaxConcurrency: 2, InterestWindow: common.Duration(time.Minute)})
+
+		mockReceipts(`"0x11118889"`)
+		suggestHead(network, head)
+		time.Sleep(300 * time.Millisecond)
+
+		// Nobody asked for receipts, so the mock must still be pending.
+		util.AssertNoPendingMocks(t, 1)
+	})
+
+	t.Run("AlwaysPrefetchesBlockByNumberAndHash", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+		defer util.AssertNoPendingMocks(t, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		network := setup(t, ctx, &common.EvmReceiptsPrefetchConfig{
+			Receipts:       util.BoolPtr(false),
+			Blocks:         util.BoolPtr(true),
+			Always:         util.BoolPtr(true),
+			MaxConcurrency: 2,
+			InterestWindow: common.Duration(time.Minute),
+		})
+
+		const hash = "0x2222222222222222222222222222222222222222222222222222222222222222"
+		block := []byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x11118889","hash":"` + hash + `","timestamp":"0x6702a8f0","transactions":[]}}`)
+		gock.New("http://rpc1.localhost").
+			Post("/").
+			Times(1).
+			Filter(func(request *http.Request) bool {
+				body := util.SafeReadBody(request)
+				return strings.Contains(body, "eth_getBlockByNumber") && strings.Contains(body, `"0x11118889"`)
+			}).
+			Reply(200).
+			JSON(block)
+		gock.New("http://rpc1.localhost").
+			Post("/").
+			Times(1).
+			Filter(func(request *http.Request) bool {
+				body := util.SafeReadBody(request)
+				return strings.Contains(body, "eth_getBlockByHash") && strings.Contains(body, hash)
+			}).
+			Reply(200).
+			JSON(block)
+
+		// No client asked for blocks; always prefetches them anyway.
+		suggestHead(network, head)
+
+		require.Eventually(t, func() bool {
+			r, err := network.cacheDal.Get(ctx, request(network, "eth_getBlockByHash", `["`+hash+`",false]`))
+			return err == nil && r != nil && !r.IsObjectNull(ctx)
+		}, 5*time.Second, 20*time.Millisecond)
+		r, err := network.cacheDal.Get(ctx, request(network, "eth_getBlockByNumber", `["0x11118889",false]`))
+		require.NoError(t, err)
+		require.NotNil(t, r)
+	})
+}
@@ -90,7 +90,8 @@ func NewNetwork(
 	metricsTracker *health.Tracker,
 	policyEngine *policy.Engine,
 ) (*Network, error) {
-	lg := logger.With().Str("component", "proxy").Str("networkId", nwCfg.NetworkId()).Logger()
+	lg := logger.With().Str("component", "proxy").Str("networkId", nwCfg.NetworkId()).Logger().
+		Sample(common.LogLevels.Sampler(common.LogTarget{Kind: common.LogTargetNetwork, Id: nwCfg.NetworkId()}))
 
 	_ = projectId // network executor scope is per-network; project label comes from the metrics tracker.
 
@@ -169,6 +170,19 @@ func NewNetwork(
 	if nwCfg.Architecture == "" {
 		nwCfg.Architecture = common.ArchitectureEvm
 	}
+	if nwCfg.Evm != nil && nwCfg.Evm.ReceiptsPrefetch != nil {
+		network.prefetcher = newBlockPrefetcher(network, nwCfg.Evm.ReceiptsPrefetch)
+	}
+	if nwCfg.Evm != nil && nwCfg.Evm.Abi != nil {
+		reg, err := evm.NewAbiRegistry(&lg, nwCfg.Evm.ChainId, nwCfg.Evm.Abi)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create abi registry for network %s: %w", netId, err)
+		}
+		network.abiRegistry = reg
+	}
+	if nwCfg.Evm != nil && nwCfg.Evm.AddressActivity != nil {
+		network.addressActivity = evm.NewAddressActivityTracker(appCtx, &lg, network, nwCfg.Evm.AddressActivity)
+	}
 
 	return network, nil
 }
@@ -298,10 +312,16 @@ func (nr *NetworksRegistry) prepareNetwork(nwCfg *common.NetworkConfig) (*Networ
 		return nil, err
 	}
 
+	network.preferredUpstreams = nr.project.Config.PreferredUpstreams
+
 	switch nwCfg.Architecture {
 	case "evm":
 		if nr.evmJsonRpcCache != nil {
-			network.cacheDal = nr.evmJsonRpcCache.WithProjectId(nr.project.Config.Id)
+			cache := nr.evmJsonRpcCache.WithProjectId(nr.project.Config.Id)
+			if residency := nr.project.Config.CacheResidency; residency != "" {
+				cache = cache.WithResidency(residency)
+			}
+			network.cacheDal = cache
 		}
 	default:
 		return nil, errors.New("unknown network architecture")
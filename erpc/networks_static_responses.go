@@ -57,7 +57,7 @@ func (n *Network) tryServeStaticResponse(
 		n.projectId, n.Label(), method,
 	).Inc()
 
-	if lg.GetLevel() <= zerolog.DebugLevel {
+	if common.LogLevelEnabled(lg, zerolog.DebugLevel) {
 		lg.Debug().Str("method", method).Msg("served static response (no upstream contacted)")
 	}
 
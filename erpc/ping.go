@@ -0,0 +1,36 @@
+package erpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/erpc/erpc/common"
+)
+
+const pingMethod = "erpc_ping"
+
+// HandlePingRequest answers erpc_ping with the proxy's clock and, on a network
+// endpoint, how many of its upstreams are neither cordoned nor drained. It
+// never initializes a network or touches an upstream, so it is safe to serve
+// ahead of auth, rate limiters and routing.
+func (p *PreparedProject) HandlePingRequest(ctx context.Context, networkId string, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	result := map[string]interface{}{
+		"timestamp": time.Now().UnixMilli(),
+	}
+	if networkId != "" {
+		result["networkId"] = networkId
+		healthy := 0
+		// Only networks that are already up count; a ping must not bootstrap one.
+		if pn, ok := p.networksRegistry.preparedNetworks.Load(networkId); ok {
+			nw := pn.(*Network)
+			for _, ups := range nw.upstreamsRegistry.GetNetworkUpstreams(ctx, nw.networkId) {
+				if _, cordoned := ups.CordonedReason("*"); cordoned || ups.DrainState() != nil {
+					continue
+				}
+				healthy++
+			}
+		}
+		result["healthyUpstreams"] = healthy
+	}
+	return makeSelectionResponse(nq, result)
+}
@@ -0,0 +1,62 @@
+package erpc
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpServer_Ping(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	gock.EnableNetworking()
+	gock.NetworkingFilter(func(req *http.Request) bool {
+		return strings.Split(req.URL.Host, ":")[0] == "localhost"
+	})
+	util.SetupMocksForEvmStatePoller()
+	defer util.AssertNoPendingMocks(t, 0)
+
+	cfg := gzipE2ECfg()
+	cfg.Projects[0].Auth = &common.AuthConfig{
+		Strategies: []*common.AuthStrategyConfig{
+			{Type: common.AuthTypeSecret, Secret: &common.SecretStrategyConfig{Value: "test-secret"}},
+		},
+	}
+	sendRequest, _, _, shutdown, erpcInstance := createServerTestFixtures(cfg, t)
+	defer shutdown()
+
+	// Bring the network up the way real traffic would, so its upstreams count.
+	project, err := erpcInstance.GetProject("test_project")
+	require.NoError(t, err)
+	network, err := project.GetNetwork(t.Context(), "evm:123")
+	require.NoError(t, err)
+
+	t.Run("AnsweredWithoutAuthOrUpstreams", func(t *testing.T) {
+		status, _, body := sendRequest(`{"jsonrpc":"2.0","method":"erpc_ping","params":[],"id":7}`, nil, nil)
+		require.Equal(t, http.StatusOK, status, "body: %s", body)
+		assert.Contains(t, body, `"id":7`)
+		assert.Contains(t, body, `"timestamp":`)
+		assert.Contains(t, body, `"networkId":"evm:123"`)
+		assert.Contains(t, body, `"healthyUpstreams":1`)
+	})
+
+	t.Run("CordonedUpstreamsAreNotHealthy", func(t *testing.T) {
+		for _, ups := range network.upstreamsRegistry.GetNetworkUpstreams(t.Context(), network.networkId) {
+			ups.Cordon("*", "test")
+			defer ups.Uncordon("*", "test")
+		}
+		_, _, body := sendRequest(`{"jsonrpc":"2.0","method":"erpc_ping","params":[],"id":1}`, nil, nil)
+		assert.Contains(t, body, `"healthyUpstreams":0`)
+	})
+
+	t.Run("OtherMethodsStillNeedAuth", func(t *testing.T) {
+		_, _, body := sendRequest(`{"jsonrpc":"2.0","method":"eth_chainId","params":[],"id":1}`, nil, nil)
+		assert.Contains(t, body, "ErrAuthUnauthorized")
+	})
+}
@@ -22,9 +22,13 @@ type PreparedProject struct {
 	Logger                      *zerolog.Logger
 	networksRegistry            *NetworksRegistry
 	consumerAuthRegistry        *auth.AuthRegistry
+	consumerUsage               *ConsumerUsageTracker
+	consumerApiKeys             *consumerApiKeyIndex
+	analytics                   *AnalyticsRecorder
 	rateLimitersRegistry        *upstream.RateLimitersRegistry
 	upstreamsRegistry           *upstream.UpstreamsRegistry
 	policyEngine                *policy.Engine
+	subscriptionArchive         *SubscriptionArchiver
 	allowClientDirectiveMatcher common.MatcherFunc
 	cfgMu                       sync.RWMutex
 }
@@ -111,13 +115,20 @@ func (p *PreparedProject) Forward(ctx context.Context, networkId string, nq *com
 	}
 	// Ensure project label is available for budget decision metrics by setting network on request early
 	nq.SetNetwork(network)
+	method, _ := nq.Method()
+	// Refused before rate limits and cache: a node with unlocked accounts
+	// must never be reachable through the proxy by accident.
+	if !p.Config.AllowWalletMethods && evm.IsWalletMethod(method) {
+		telemetry.MetricWalletMethodRefusedTotal.WithLabelValues(p.Config.Id, network.Label(), method).Inc()
+		err := common.NewErrWalletMethodDisabled(method)
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
 	if err := p.AcquireRateLimitPermit(ctx, nq); err != nil {
 		common.SetTraceSpanError(span, err)
 		return nil, err
 	}
 
-	method, _ := nq.Method()
-
 	// Get initial finality from request
 	reqFinality := nq.Finality(ctx)
 
@@ -132,8 +143,22 @@ func (p *PreparedProject) Forward(ctx context.Context, networkId string, nq *com
 		Interface("id", nq.ID()).
 		Str("ptr", fmt.Sprintf("%p", nq)).
 		Logger()
+	if cid := common.CorrelationIdFromContext(ctx); cid != "" {
+		lg = lg.With().Str("correlationId", cid).Logger()
+	}
+
+	rewrite := common.FindMethodRewrite(p.Config.MethodRewrites, method)
+	if rewrite != nil {
+		if _, err := nq.ApplyMethodRewrite(ctx, rewrite); err != nil {
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		}
+	}
 
 	resp, err := p.doForward(ctx, network, nq)
+	if err == nil && rewrite != nil {
+		err = rewrite.UnwrapResponse(ctx, resp)
+	}
 
 	shadowUpstreams := network.ShadowUpstreams()
 	if len(shadowUpstreams) > 0 {
@@ -164,6 +189,18 @@ func (p *PreparedProject) Forward(ctx context.Context, networkId string, nq *com
 		}
 	}
 
+	if err == nil && resp != nil && p.subscriptionArchive != nil {
+		p.subscriptionArchive.Observe(ctx, network.Id(), method, nq, resp)
+	}
+
+	// Trim the result after shadow requests cloned it, so they compare full
+	// results.
+	if err == nil && resp != nil {
+		if dirs := nq.Directives(); dirs != nil && dirs.Fields != "" {
+			err = common.ProjectResponse(ctx, resp, dirs.Fields)
+		}
+	}
+
 	if err != nil {
 		common.SetTraceSpanError(span, err)
 	}
@@ -204,7 +241,7 @@ func (p *PreparedProject) Forward(ctx context.Context, networkId string, nq *com
 		).Inc()
 		dur := time.Since(start)
 		resp.SetDuration(dur)
-		if lg.GetLevel() == zerolog.TraceLevel {
+		if common.LogLevelEnabled(&lg, zerolog.TraceLevel) {
 			lg.Info().Dur("durationMs", dur).Object("response", resp).Msgf("successfully forwarded request for network")
 		} else {
 			lg.Info().Dur("durationMs", dur).Msgf("successfully forwarded request for network")
@@ -223,7 +260,7 @@ func (p *PreparedProject) Forward(ctx context.Context, networkId string, nq *com
 		if common.IsClientError(err) || common.HasErrorCode(err, common.ErrCodeEndpointExecutionException) {
 			lg.Info().Err(err).Msgf("finished forwarding request for network with some client-side exception")
 		} else {
-			if lg.GetLevel() <= zerolog.DebugLevel {
+			if common.LogLevelEnabled(&lg, zerolog.DebugLevel) {
 				lg.Info().Err(err).Object("request", nq).Msgf("failed to forward request for network")
 			} else {
 				lg.Info().Err(err).Msgf("failed to forward request for network")
@@ -190,6 +190,19 @@ func (r *ProjectsRegistry) RegisterProject(prjCfg *common.ProjectConfig) (*Prepa
 		}
 		pp.consumerAuthRegistry = consumerAuthRegistry
 	}
+	if prjCfg.SelfService != nil && prjCfg.SelfService.Enabled {
+		pp.consumerUsage = NewConsumerUsageTracker(prjCfg.SelfService)
+		if pp.consumerAuthRegistry != nil {
+			pp.consumerApiKeys = newConsumerApiKeyIndex(pp.consumerAuthRegistry.DatabaseConnectors)
+		}
+	}
+	if prjCfg.SubscriptionArchive != nil {
+		archiver, err := NewSubscriptionArchiver(r.appCtx, &lg, prjCfg.Id, prjCfg.SubscriptionArchive)
+		if err != nil {
+			return nil, err
+		}
+		pp.subscriptionArchive = archiver
+	}
 
 	pp.upstreamsRegistry = upstreamsRegistry
 	pp.policyEngine = policy.NewEngine(
@@ -127,6 +127,114 @@ func TestProject_Forward(t *testing.T) {
 
 		log.Logger.Info().Msgf("Last Resp: %+v", lastResp)
 	})
+
+	t.Run("WalletMethodsRefusedUnlessAllowed", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+		defer util.AssertNoPendingMocks(t, 0)
+
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Filter(func(request *http.Request) bool {
+				body := util.SafeReadBody(request)
+				return strings.Contains(body, "eth_accounts")
+			}).
+			Reply(200).
+			JSON(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      1,
+				"result":  []string{"0x1111111111111111111111111111111111111111"},
+			})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rateLimitersRegistry, err := upstream.NewRateLimitersRegistry(context.Background(), &common.RateLimiterConfig{}, &log.Logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ssr, err := data.NewSharedStateRegistry(ctx, &log.Logger, &common.SharedStateConfig{
+			Connector: &common.ConnectorConfig{
+				Driver: "memory",
+				Memory: &common.MemoryConnectorConfig{
+					MaxItems: 100_000, MaxTotalSize: "1GB",
+				},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		newProject := func(id string, allowWalletMethods bool) *common.ProjectConfig {
+			return &common.ProjectConfig{
+				Id:                 id,
+				AllowWalletMethods: allowWalletMethods,
+				Networks: []*common.NetworkConfig{
+					{
+						Architecture: common.ArchitectureEvm,
+						Evm: &common.EvmNetworkConfig{
+							ChainId: 123,
+						},
+					},
+				},
+				Upstreams: []*common.UpstreamConfig{
+					{
+						Id:       "rpc1",
+						Endpoint: "http://rpc1.localhost",
+						Type:     common.UpstreamTypeEvm,
+						Evm: &common.EvmUpstreamConfig{
+							ChainId: 123,
+						},
+					},
+				},
+			}
+		}
+		prjReg, err := NewProjectsRegistry(
+			ctx,
+			&log.Logger,
+			[]*common.ProjectConfig{newProject("locked", false), newProject("passThrough", true)},
+			ssr,
+			nil,
+			rateLimitersRegistry,
+			thirdparty.NewVendorsRegistry(),
+			nil, // ProxyPoolRegistry
+			nil, // userScript
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prjReg.Bootstrap(ctx)
+		time.Sleep(100 * time.Millisecond)
+
+		locked, err := prjReg.GetProject("locked")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, method := range []string{"eth_accounts", "eth_sign", "eth_signTypedData_v4", "eth_sendTransaction", "personal_unlockAccount"} {
+			req := common.NewNormalizedRequest([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"%s","params":[]}`, method)))
+			_, err := locked.Forward(ctx, "evm:123", req)
+			if !common.HasErrorCode(err, common.ErrCodeWalletMethodDisabled) {
+				t.Errorf("expected %s to be refused with ErrWalletMethodDisabled, got %v", method, err)
+			}
+		}
+
+		passThrough, err := prjReg.GetProject("passThrough")
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_accounts","params":[]}`))
+		resp, err := passThrough.Forward(ctx, "evm:123", req)
+		if err != nil {
+			t.Fatalf("expected eth_accounts to be forwarded, got %v", err)
+		}
+		jrr, err := resp.JsonRpcResponse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(jrr.GetResultString(), "0x1111111111111111111111111111111111111111") {
+			t.Errorf("unexpected result %s", jrr.GetResultString())
+		}
+	})
 }
 func TestProject_TimeoutScenarios(t *testing.T) {
 	t.Run("UpstreamTimeout", func(t *testing.T) {
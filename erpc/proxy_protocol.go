@@ -0,0 +1,281 @@
+package erpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+)
+
+// PROXY protocol (https://www.haproxy.org/download/2.9/doc/proxy-protocol.txt)
+// lets a TCP load balancer such as an AWS NLB pass the client address in a
+// header sent before any application data.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+const (
+	// proxyV1MaxLength is the longest valid v1 header, CRLF included.
+	proxyV1MaxLength = 107
+
+	proxyV2CmdLocal = 0x0
+	proxyV2CmdProxy = 0x1
+	proxyV2FamTCP4  = 0x11
+	proxyV2FamTCP6  = 0x21
+)
+
+var errProxyHeaderMissing = errors.New("connection did not start with a PROXY protocol header")
+
+// proxyProtocolListener reads the PROXY header of connections accepted from
+// trusted sources and reports the client address it carries as the
+// connection's remote address. Other connections are served as they are.
+type proxyProtocolListener struct {
+	net.Listener
+	trusted       func(net.IP) bool
+	required      bool
+	headerTimeout time.Duration
+}
+
+// withProxyProtocol wraps ln when server.proxyProtocol is configured.
+func withProxyProtocol(ln net.Listener, cfg *common.ProxyProtocolConfig) net.Listener {
+	if cfg == nil {
+		return ln
+	}
+	pl := &proxyProtocolListener{
+		Listener: ln,
+		trusted:  newIPAllowList(cfg.TrustedSources),
+		required: cfg.Required != nil && *cfg.Required,
+	}
+	if cfg.HeaderTimeout != nil {
+		pl.headerTimeout = cfg.HeaderTimeout.Duration()
+	}
+	return pl
+}
+
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if !l.trusted(parseRemoteIP(conn.RemoteAddr().String())) {
+		return conn, nil
+	}
+	// The header is read on first use, from the connection's own goroutine,
+	// so a peer that is slow to send it does not hold up Accept.
+	return &proxyProtocolConn{
+		Conn:          conn,
+		reader:        bufio.NewReader(conn),
+		required:      l.required,
+		headerTimeout: l.headerTimeout,
+	}, nil
+}
+
+type proxyProtocolConn struct {
+	net.Conn
+	reader        *bufio.Reader
+	required      bool
+	headerTimeout time.Duration
+
+	once       sync.Once
+	remoteAddr net.Addr
+	localAddr  net.Addr
+	err        error
+}
+
+func (c *proxyProtocolConn) init() {
+	c.once.Do(func() {
+		if c.headerTimeout > 0 {
+			c.Conn.SetReadDeadline(time.Now().Add(c.headerTimeout))
+			defer c.Conn.SetReadDeadline(time.Time{})
+		}
+		src, dst, found, err := readProxyHeader(c.reader)
+		switch {
+		case err != nil:
+			c.err = fmt.Errorf("invalid PROXY protocol header from %s: %w", c.Conn.RemoteAddr(), err)
+		case !found && c.required:
+			c.err = errProxyHeaderMissing
+		}
+		if c.err != nil {
+			c.Conn.Close()
+			return
+		}
+		c.remoteAddr, c.localAddr = src, dst
+	})
+}
+
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.init()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.init()
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *proxyProtocolConn) LocalAddr() net.Addr {
+	c.init()
+	if c.localAddr != nil {
+		return c.localAddr
+	}
+	return c.Conn.LocalAddr()
+}
+
+// readProxyHeader consumes a v1 or v2 header if r starts with one. src and dst
+// are nil when the header carries no addresses (v1 UNKNOWN, v2 LOCAL such as
+// load balancer health checks, or non-TCP families).
+func readProxyHeader(r *bufio.Reader) (src, dst net.Addr, found bool, err error) {
+	// Peek one byte at a time so a client that sends less than a full
+	// signature before waiting for a response is never blocked on.
+	first, err := r.Peek(1)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, false, nil
+		}
+		return nil, nil, false, err
+	}
+	switch first[0] {
+	case 'P':
+		if p, err := r.Peek(6); err != nil || string(p) != "PROXY " {
+			return nil, nil, false, nil
+		}
+		src, dst, err = readProxyV1(r)
+		return src, dst, true, err
+	case proxyV2Signature[0]:
+		if p, err := r.Peek(len(proxyV2Signature)); err != nil || !bytes.Equal(p, proxyV2Signature) {
+			return nil, nil, false, nil
+		}
+		src, dst, err = readProxyV2(r)
+		return src, dst, true, err
+	}
+	return nil, nil, false, nil
+}
+
+func readProxyV1(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	var line []byte
+	for len(line) < proxyV1MaxLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, fmt.Errorf("v1 header is not terminated within %d bytes", proxyV1MaxLength)
+	}
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, fmt.Errorf("malformed v1 header %q", line)
+	}
+	src, err := proxyV1Addr(fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+	dst, err := proxyV1Addr(fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, dst, nil
+}
+
+func proxyV1Addr(host, port string) (net.Addr, error) {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid v1 address %q", host)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid v1 port %q", port)
+	}
+	return &net.TCPAddr{IP: ip, Port: int(p)}, nil
+}
+
+func readProxyV2(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, err
+	}
+	if header[12]>>4 != 2 {
+		return nil, nil, fmt.Errorf("unsupported v2 version %d", header[12]>>4)
+	}
+	cmd, fam := header[12]&0x0f, header[13]
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, nil, err
+	}
+	switch cmd {
+	case proxyV2CmdLocal:
+		return nil, nil, nil
+	case proxyV2CmdProxy:
+	default:
+		return nil, nil, fmt.Errorf("unsupported v2 command %d", cmd)
+	}
+
+	// TLVs after the addresses are ignored.
+	switch fam {
+	case proxyV2FamTCP4:
+		if len(payload) < 12 {
+			return nil, nil, fmt.Errorf("v2 TCP4 address block is %d bytes", len(payload))
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))},
+			&net.TCPAddr{IP: net.IP(payload[4:8]), Port: int(binary.BigEndian.Uint16(payload[10:12]))}, nil
+	case proxyV2FamTCP6:
+		if len(payload) < 36 {
+			return nil, nil, fmt.Errorf("v2 TCP6 address block is %d bytes", len(payload))
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))},
+			&net.TCPAddr{IP: net.IP(payload[16:32]), Port: int(binary.BigEndian.Uint16(payload[34:36]))}, nil
+	}
+	// UDP and unix families carry no TCP client address; keep the peer's.
+	return nil, nil, nil
+}
+
+// newIPAllowList matches IPs against a list of IPs and CIDRs, as in
+// server.trustedIPForwarders. Invalid entries are rejected by validation.
+func newIPAllowList(entries []string) func(net.IP) bool {
+	ips := make(map[string]struct{}, len(entries))
+	var nets []*net.IPNet
+	for _, entry := range entries {
+		val := strings.TrimSpace(entry)
+		if strings.Contains(val, "/") {
+			if _, ipnet, err := net.ParseCIDR(val); err == nil {
+				nets = append(nets, ipnet)
+			}
+		} else if ip := net.ParseIP(val); ip != nil {
+			ips[ip.String()] = struct{}{}
+		}
+	}
+	return func(ip net.IP) bool {
+		if ip == nil {
+			return false
+		}
+		if _, ok := ips[ip.String()]; ok {
+			return true
+		}
+		for _, n := range nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,146 @@
+package erpc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func proxyV2Header(cmd, fam byte, addrs []byte) []byte {
+	h := append([]byte(nil), proxyV2Signature...)
+	h = append(h, 0x20|cmd, fam, 0, 0)
+	binary.BigEndian.PutUint16(h[14:16], uint16(len(addrs)))
+	return append(h, addrs...)
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	read := func(t *testing.T, raw string) (net.Addr, net.Addr, bool, error, string) {
+		r := bufio.NewReader(strings.NewReader(raw))
+		src, dst, found, err := readProxyHeader(r)
+		rest, _ := io.ReadAll(r)
+		return src, dst, found, err, string(rest)
+	}
+
+	t.Run("V1TCP4", func(t *testing.T) {
+		src, dst, found, err, rest := read(t, "PROXY TCP4 203.0.113.7 10.0.0.5 51234 4000\r\nGET / HTTP/1.1\r\n")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "203.0.113.7:51234", src.String())
+		assert.Equal(t, "10.0.0.5:4000", dst.String())
+		assert.Equal(t, "GET / HTTP/1.1\r\n", rest)
+	})
+
+	t.Run("V1Unknown", func(t *testing.T) {
+		src, _, found, err, rest := read(t, "PROXY UNKNOWN\r\nGET /")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Nil(t, src)
+		assert.Equal(t, "GET /", rest)
+	})
+
+	t.Run("V1Malformed", func(t *testing.T) {
+		_, _, found, err, _ := read(t, "PROXY TCP4 not-an-ip 10.0.0.5 1 2\r\n")
+		assert.True(t, found)
+		assert.Error(t, err)
+	})
+
+	t.Run("V2TCP4", func(t *testing.T) {
+		addrs := []byte{198, 51, 100, 9, 10, 0, 0, 5, 0xC8, 0x00, 0x0F, 0xA0}
+		addrs = append(addrs, 0x04, 0x00, 0x01, 0xFF) // a TLV, ignored
+		src, dst, found, err, rest := read(t, string(proxyV2Header(proxyV2CmdProxy, proxyV2FamTCP4, addrs))+"POST /")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "198.51.100.9:51200", src.String())
+		assert.Equal(t, "10.0.0.5:4000", dst.String())
+		assert.Equal(t, "POST /", rest)
+	})
+
+	t.Run("V2TCP6", func(t *testing.T) {
+		addrs := make([]byte, 36)
+		copy(addrs[0:16], net.ParseIP("2001:db8::1"))
+		copy(addrs[16:32], net.ParseIP("2001:db8::2"))
+		binary.BigEndian.PutUint16(addrs[32:34], 443)
+		binary.BigEndian.PutUint16(addrs[34:36], 4000)
+		src, _, found, err, _ := read(t, string(proxyV2Header(proxyV2CmdProxy, proxyV2FamTCP6, addrs)))
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "[2001:db8::1]:443", src.String())
+	})
+
+	t.Run("V2LocalKeepsPeerAddress", func(t *testing.T) {
+		src, _, found, err, rest := read(t, string(proxyV2Header(proxyV2CmdLocal, 0, nil))+"GET /")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Nil(t, src)
+		assert.Equal(t, "GET /", rest)
+	})
+
+	t.Run("NoHeader", func(t *testing.T) {
+		_, _, found, err, rest := read(t, "POST / HTTP/1.1\r\n")
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Equal(t, "POST / HTTP/1.1\r\n", rest)
+	})
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	serve := func(t *testing.T, cfg *common.ProxyProtocolConfig) (addr string, remotes chan string) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		remotes = make(chan string, 1)
+		srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			remotes <- r.RemoteAddr
+		})}
+		go srv.Serve(withProxyProtocol(ln, cfg))
+		t.Cleanup(func() { srv.Close() })
+		return ln.Addr().String(), remotes
+	}
+	send := func(t *testing.T, addr, header string) (*http.Response, error) {
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = conn.Write([]byte(header + "GET / HTTP/1.1\r\nHost: x\r\n\r\n"))
+		require.NoError(t, err)
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		return http.ReadResponse(bufio.NewReader(conn), nil)
+	}
+
+	t.Run("TrustedSourceReportsClientAddress", func(t *testing.T) {
+		addr, remotes := serve(t, &common.ProxyProtocolConfig{TrustedSources: []string{"127.0.0.1/8"}})
+		_, err := send(t, addr, "PROXY TCP4 203.0.113.7 10.0.0.5 51234 4000\r\n")
+		require.NoError(t, err)
+		assert.Equal(t, "203.0.113.7:51234", <-remotes)
+	})
+
+	t.Run("HeaderIsOptionalByDefault", func(t *testing.T) {
+		addr, remotes := serve(t, &common.ProxyProtocolConfig{TrustedSources: []string{"127.0.0.1"}})
+		_, err := send(t, addr, "")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(<-remotes, "127.0.0.1:"))
+	})
+
+	t.Run("RequiredClosesConnectionsWithoutHeader", func(t *testing.T) {
+		addr, _ := serve(t, &common.ProxyProtocolConfig{TrustedSources: []string{"127.0.0.1"}, Required: util.BoolPtr(true)})
+		_, err := send(t, addr, "")
+		assert.Error(t, err)
+	})
+
+	t.Run("UntrustedSourceCannotSpoofAddress", func(t *testing.T) {
+		addr, remotes := serve(t, &common.ProxyProtocolConfig{TrustedSources: []string{"10.0.0.0/8"}})
+		// The header is left in the stream, so the request is not valid HTTP.
+		resp, err := send(t, addr, "PROXY TCP4 203.0.113.7 10.0.0.5 51234 4000\r\n")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Empty(t, remotes)
+	})
+}
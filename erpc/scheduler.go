@@ -0,0 +1,395 @@
+package erpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+)
+
+const (
+	ScheduledJobRunSucceeded = "succeeded"
+	ScheduledJobRunFailed    = "failed"
+	ScheduledJobRunSkipped   = "skipped"
+
+	// schedulerLockWait is how long an instance waits for a job lock before
+	// assuming another instance is running it and skipping this tick.
+	schedulerLockWait = 2 * time.Second
+)
+
+type ScheduledJobRun struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// scheduledJobRunner executes one run of a job; returned errors mark the run as failed.
+type scheduledJobRunner func(ctx context.Context, s *Scheduler, cfg *common.ScheduledJobConfig) error
+
+var scheduledJobRunners = map[common.ScheduledJobType]scheduledJobRunner{
+	common.ScheduledJobTypeBackfill:     runScheduledBackfill,
+	common.ScheduledJobTypeWarm:         runScheduledWarm,
+	common.ScheduledJobTypeCacheSweep:   runScheduledCacheSweep,
+	common.ScheduledJobTypeQuotaReset:   runScheduledQuotaReset,
+	common.ScheduledJobTypeHealthReport: runScheduledHealthReport,
+}
+
+type scheduledJob struct {
+	cfg      *common.ScheduledJobConfig
+	schedule *util.CronSchedule
+
+	mu      sync.RWMutex
+	running bool
+	nextRun time.Time
+	history []ScheduledJobRun
+}
+
+// Scheduler runs the maintenance jobs declared under `scheduler.jobs`, each on
+// its own cron schedule, guarded by a cluster-wide lock so a job runs on at
+// most one instance per tick. With sharedState.leases, jobs only run on the
+// replica holding the scheduler lease.
+type Scheduler struct {
+	erpc        *ERPC
+	sharedState data.SharedStateRegistry
+	lease       *data.Lease
+	logger      *zerolog.Logger
+	historySize int
+	jobs        []*scheduledJob
+}
+
+// schedulerLeaseName is the single-writer role of the replica running jobs.
+const schedulerLeaseName = "scheduler"
+
+func NewScheduler(
+	logger *zerolog.Logger,
+	erpc *ERPC,
+	sharedState data.SharedStateRegistry,
+	cfg *common.SchedulerConfig,
+) (*Scheduler, error) {
+	lg := logger.With().Str("component", "scheduler").Logger()
+	s := &Scheduler{
+		erpc:        erpc,
+		sharedState: sharedState,
+		logger:      &lg,
+		historySize: cfg.HistorySize,
+	}
+	for _, jc := range cfg.Jobs {
+		if jc == nil {
+			continue
+		}
+		schedule, err := util.ParseCronSchedule(jc.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("scheduler job '%s': %w", jc.Id, err)
+		}
+		if _, ok := scheduledJobRunners[jc.Type]; !ok {
+			return nil, fmt.Errorf("scheduler job '%s': unsupported type '%s'", jc.Id, jc.Type)
+		}
+		s.jobs = append(s.jobs, &scheduledJob{cfg: jc, schedule: schedule})
+	}
+	if sharedState != nil && len(s.jobs) > 0 {
+		s.lease = sharedState.GetLease(schedulerLeaseName)
+	}
+	return s, nil
+}
+
+// Start launches one loop per job; loops stop when ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+	if len(s.jobs) > 0 {
+		s.logger.Info().Int("jobs", len(s.jobs)).Msg("scheduler started")
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *scheduledJob) {
+	for {
+		next := job.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Warn().Str("jobId", job.cfg.Id).Msg("schedule has no upcoming activation; stopping job loop")
+			return
+		}
+		job.mu.Lock()
+		job.nextRun = next
+		job.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.runOnce(ctx, job, next)
+	}
+}
+
+// runOnce runs the activation of job scheduled at tick.
+func (s *Scheduler) runOnce(ctx context.Context, job *scheduledJob, tick time.Time) ScheduledJobRun {
+	lg := s.logger.With().Str("jobId", job.cfg.Id).Str("type", string(job.cfg.Type)).Logger()
+	run := ScheduledJobRun{StartedAt: time.Now()}
+	timeout := job.cfg.Timeout.Duration()
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			telemetry.MetricUnexpectedPanicTotal.WithLabelValues(
+				"scheduler-job",
+				fmt.Sprintf("job:%s", job.cfg.Id),
+				common.ErrorFingerprint(rec),
+			).Inc()
+			lg.Error().Interface("panic", rec).Str("stack", string(debug.Stack())).Msg("unexpected panic in scheduled job")
+			run.Status = ScheduledJobRunFailed
+			run.Error = fmt.Sprintf("panic: %v", rec)
+		}
+		run.FinishedAt = time.Now()
+		job.record(run, s.historySize)
+		telemetry.MetricScheduledJobRunTotal.WithLabelValues(job.cfg.Id, string(job.cfg.Type), run.Status).Inc()
+	}()
+
+	if s.lease != nil {
+		leaseCtx, held := s.lease.Held()
+		if !held {
+			lg.Debug().Str("leaseHolder", s.lease.Holder()).Msg("skipping scheduled job run; another instance holds the scheduler lease")
+			run.Status = ScheduledJobRunSkipped
+			run.Error = "scheduler lease is held by another instance"
+			return run
+		}
+		// A run stops when the lease is lost, as its new holder may start
+		// the next one.
+		var stop context.CancelFunc
+		ctx, stop = context.WithCancel(ctx)
+		defer stop()
+		defer context.AfterFunc(leaseCtx, stop)()
+	}
+
+	if s.sharedState != nil {
+		// The lock is held until the next activation: released right after a
+		// fast run, it would let an instance whose timer fired late still take
+		// it within schedulerLockWait and run the same tick again.
+		releaseAt := job.schedule.Next(tick)
+		lockTtl := max(timeout, time.Until(releaseAt))
+		lockCtx, cancel := context.WithTimeoutCause(ctx, schedulerLockWait, errors.New("timeout acquiring scheduler job lock"))
+		lock, err := s.sharedState.Lock(lockCtx, "scheduler/"+job.cfg.Id, lockTtl)
+		cancel()
+		if err != nil || lock == nil || lock.IsNil() {
+			lg.Debug().Err(err).Msg("skipping scheduled job run; lock is held by another instance")
+			run.Status = ScheduledJobRunSkipped
+			if err != nil {
+				run.Error = err.Error()
+			}
+			return run
+		}
+		defer func() {
+			time.AfterFunc(max(time.Until(releaseAt), 0), func() {
+				unlockCtx, cancel := context.WithTimeout(context.Background(), schedulerLockWait)
+				defer cancel()
+				if err := lock.Unlock(unlockCtx); err != nil {
+					lg.Warn().Err(err).Msg("failed to release scheduler job lock")
+				}
+			})
+		}()
+	}
+
+	job.mu.Lock()
+	job.running = true
+	job.mu.Unlock()
+	defer func() {
+		job.mu.Lock()
+		job.running = false
+		job.mu.Unlock()
+	}()
+
+	runCtx, cancel := context.WithTimeoutCause(ctx, timeout, fmt.Errorf("scheduled job '%s' exceeded timeout of %s", job.cfg.Id, timeout))
+	defer cancel()
+
+	lg.Debug().Msg("running scheduled job")
+	if err := scheduledJobRunners[job.cfg.Type](runCtx, s, job.cfg); err != nil {
+		lg.Warn().Err(err).Msg("scheduled job failed")
+		run.Status = ScheduledJobRunFailed
+		run.Error = err.Error()
+		return run
+	}
+	run.Status = ScheduledJobRunSucceeded
+	return run
+}
+
+func (j *scheduledJob) record(run ScheduledJobRun, historySize int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.history = append(j.history, run)
+	if historySize > 0 && len(j.history) > historySize {
+		j.history = j.history[len(j.history)-historySize:]
+	}
+}
+
+// Snapshot returns the state and recent run history of every job, most recent run first.
+func (s *Scheduler) Snapshot() []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		job.mu.RLock()
+		history := make([]ScheduledJobRun, 0, len(job.history))
+		for i := len(job.history) - 1; i >= 0; i-- {
+			history = append(history, job.history[i])
+		}
+		row := map[string]interface{}{
+			"id":       job.cfg.Id,
+			"type":     job.cfg.Type,
+			"schedule": job.cfg.Schedule,
+			"running":  job.running,
+			"history":  history,
+		}
+		if !job.nextRun.IsZero() {
+			row["nextRunAt"] = job.nextRun
+		}
+		job.mu.RUnlock()
+		out = append(out, row)
+	}
+	return out
+}
+
+func runScheduledBackfill(ctx context.Context, s *Scheduler, cfg *common.ScheduledJobConfig) error {
+	bc := cfg.Backfill
+	network, err := s.erpc.GetNetwork(ctx, bc.ProjectId, bc.NetworkId)
+	if err != nil {
+		return err
+	}
+	head := network.EvmHighestFinalizedBlockNumber(ctx)
+	if head <= 0 {
+		head = network.EvmHighestLatestBlockNumber(ctx)
+	}
+	if head <= 0 {
+		return fmt.Errorf("network %s head is not known yet", bc.NetworkId)
+	}
+	from := head - bc.LastBlocks + 1
+	if from < 0 {
+		from = 0
+	}
+	job, err := s.erpc.backfills.Start(network, backfillParams{
+		ProjectID:   bc.ProjectId,
+		NetworkID:   bc.NetworkId,
+		FromBlock:   from,
+		ToBlock:     head,
+		Methods:     bc.Methods,
+		RateLimit:   bc.RateLimit,
+		Concurrency: bc.Concurrency,
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-job.Done():
+	case <-ctx.Done():
+		s.erpc.backfills.Cancel(job.Id)
+		<-job.Done()
+		return context.Cause(ctx)
+	}
+	snap := job.snapshot()
+	if failed, _ := snap["failed"].(int64); failed > 0 {
+		return fmt.Errorf("backfill %s finished with %d failed requests (last error: %v)", job.Id, failed, snap["lastError"])
+	}
+	return nil
+}
+
+func runScheduledWarm(ctx context.Context, s *Scheduler, cfg *common.ScheduledJobConfig) error {
+	wc := cfg.Warm
+	network, err := s.erpc.GetNetwork(ctx, wc.ProjectId, wc.NetworkId)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, r := range wc.Requests {
+		params := r.Params
+		if params == nil {
+			params = []interface{}{}
+		}
+		if err := forwardInternalRequest(ctx, network, r.Method, params); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Method, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func runScheduledCacheSweep(ctx context.Context, s *Scheduler, cfg *common.ScheduledJobConfig) error {
+	cs := cfg.CacheSweep
+	rows, err := s.erpc.purgeCache(ctx, &purgeCacheParams{
+		ConnectorId:        cs.ConnectorId,
+		PartitionKeyPrefix: cs.PartitionKeyPrefix,
+	})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, row := range rows {
+		if row.Error != "" {
+			errs = append(errs, fmt.Errorf("%s: %s", row.Connector, row.Error))
+		}
+	}
+	s.logger.Info().Str("jobId", cfg.Id).Interface("results", rows).Msg("cache entries swept by scheduled job")
+	return errors.Join(errs...)
+}
+
+func runScheduledQuotaReset(_ context.Context, s *Scheduler, cfg *common.ScheduledJobConfig) error {
+	qr := cfg.QuotaReset
+	prj, err := s.erpc.GetProject(qr.ProjectId)
+	if err != nil {
+		return err
+	}
+	reset := prj.consumerUsage.Reset(qr.UserIds)
+	s.logger.Info().Str("jobId", cfg.Id).Str("projectId", qr.ProjectId).Int("consumers", reset).Msg("consumer usage counters reset by scheduled job")
+	return nil
+}
+
+func runScheduledHealthReport(ctx context.Context, s *Scheduler, cfg *common.ScheduledJobConfig) error {
+	hr := cfg.HealthReport
+	var projects []*PreparedProject
+	if hr.ProjectId != "" {
+		prj, err := s.erpc.GetProject(hr.ProjectId)
+		if err != nil {
+			return err
+		}
+		projects = append(projects, prj)
+	} else {
+		projects = s.erpc.GetProjects()
+	}
+	reports := make(map[string]*ProjectHealthInfo, len(projects))
+	for _, prj := range projects {
+		health, err := prj.GatherHealthInfo()
+		if err != nil {
+			return fmt.Errorf("project %s: %w", prj.Config.Id, err)
+		}
+		reports[prj.Config.Id] = health
+	}
+	instanceId := ""
+	if s.sharedState != nil {
+		instanceId = s.sharedState.GetInstanceId()
+	}
+	return postWebhookJSON(ctx, hr.Url, hr.Headers, map[string]interface{}{
+		"event":       "healthReport",
+		"jobId":       cfg.Id,
+		"generatedAt": time.Now().UTC(),
+		"instanceId":  instanceId,
+		"projects":    reports,
+	})
+}
+
+// handleListScheduledJobs returns every scheduler job with its next activation
+// and recent run history on this instance.
+func (e *ERPC) handleListScheduledJobs(_ context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jobs := []map[string]interface{}{}
+	if e.scheduler != nil {
+		jobs = e.scheduler.Snapshot()
+	}
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"jobs": jobs,
+	})
+}
@@ -0,0 +1,164 @@
+package erpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSchedulerSharedState(t *testing.T, ctx context.Context) data.SharedStateRegistry {
+	cfg := &common.SharedStateConfig{
+		Connector: &common.ConnectorConfig{
+			Driver: common.DriverMemory,
+			Memory: &common.MemoryConnectorConfig{MaxItems: 1000, MaxTotalSize: "10MB"},
+		},
+	}
+	require.NoError(t, cfg.SetDefaults("test"))
+	ssr, err := data.NewSharedStateRegistry(ctx, &log.Logger, cfg)
+	require.NoError(t, err)
+	return ssr
+}
+
+func TestScheduler_RunOnce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const testType common.ScheduledJobType = "test"
+	var calls int
+	var fail bool
+	scheduledJobRunners[testType] = func(ctx context.Context, s *Scheduler, cfg *common.ScheduledJobConfig) error {
+		calls++
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	}
+	defer delete(scheduledJobRunners, testType)
+
+	ssr := newTestSchedulerSharedState(t, ctx)
+	s, err := NewScheduler(&log.Logger, nil, ssr, &common.SchedulerConfig{
+		HistorySize: 2,
+		Jobs: []*common.ScheduledJobConfig{
+			{Id: "job1", Schedule: "@hourly", Type: testType, Timeout: common.Duration(time.Minute)},
+		},
+	})
+	require.NoError(t, err)
+	job := s.jobs[0]
+	// Activations whose next one is already past release the lock right away.
+	pastTick := time.Now().Add(-3 * time.Hour).Truncate(time.Hour)
+
+	run := s.runOnce(ctx, job, pastTick)
+	assert.Equal(t, ScheduledJobRunSucceeded, run.Status)
+
+	fail = true
+	run = s.runOnce(ctx, job, pastTick.Add(time.Hour))
+	assert.Equal(t, ScheduledJobRunFailed, run.Status)
+	assert.Equal(t, "boom", run.Error)
+
+	// Another instance holding the lock makes this tick a no-op
+	var lock data.DistributedLock
+	require.Eventually(t, func() bool {
+		lockCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		lock, err = ssr.Lock(lockCtx, "scheduler/job1", time.Minute)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	run = s.runOnce(ctx, job, pastTick.Add(2*time.Hour))
+	assert.Equal(t, ScheduledJobRunSkipped, run.Status)
+	require.NoError(t, lock.Unlock(ctx))
+	assert.Equal(t, 2, calls)
+
+	snap := s.Snapshot()
+	require.Len(t, snap, 1)
+	history := snap[0]["history"].([]ScheduledJobRun)
+	require.Len(t, history, 2, "history is capped at historySize")
+	assert.Equal(t, ScheduledJobRunSkipped, history[0].Status, "most recent run comes first")
+	assert.Equal(t, ScheduledJobRunFailed, history[1].Status)
+}
+
+func TestScheduler_HoldsLockUntilNextActivation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const testType common.ScheduledJobType = "test"
+	var calls int
+	scheduledJobRunners[testType] = func(ctx context.Context, s *Scheduler, cfg *common.ScheduledJobConfig) error {
+		calls++
+		return nil
+	}
+	defer delete(scheduledJobRunners, testType)
+
+	// Two replicas sharing the lock store.
+	ssr := newTestSchedulerSharedState(t, ctx)
+	cfg := &common.SchedulerConfig{
+		Jobs: []*common.ScheduledJobConfig{
+			{Id: "job1", Schedule: "@hourly", Type: testType, Timeout: common.Duration(time.Minute)},
+		},
+	}
+	a, err := NewScheduler(&log.Logger, nil, ssr, cfg)
+	require.NoError(t, err)
+	b, err := NewScheduler(&log.Logger, nil, ssr, cfg)
+	require.NoError(t, err)
+
+	tick := time.Now().Truncate(time.Hour)
+	assert.Equal(t, ScheduledJobRunSucceeded, a.runOnce(ctx, a.jobs[0], tick).Status)
+	// The run is over, but a replica whose timer fired late for the same
+	// tick must not run it again.
+	assert.Equal(t, ScheduledJobRunSkipped, b.runOnce(ctx, b.jobs[0], tick).Status)
+	assert.Equal(t, 1, calls)
+}
+
+func TestNewScheduler_RejectsUnknownType(t *testing.T) {
+	_, err := NewScheduler(&log.Logger, nil, nil, &common.SchedulerConfig{
+		Jobs: []*common.ScheduledJobConfig{{Id: "x", Schedule: "@daily", Type: "compact"}},
+	})
+	require.Error(t, err)
+}
+
+func TestScheduler_RunsOnLeaseHolder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const testType common.ScheduledJobType = "test"
+	var runCtx context.Context
+	scheduledJobRunners[testType] = func(ctx context.Context, s *Scheduler, cfg *common.ScheduledJobConfig) error {
+		runCtx = ctx
+		return nil
+	}
+	defer delete(scheduledJobRunners, testType)
+
+	cfg := &common.SharedStateConfig{
+		Connector: &common.ConnectorConfig{
+			Driver: common.DriverMemory,
+			Memory: &common.MemoryConnectorConfig{MaxItems: 1000, MaxTotalSize: "10MB"},
+		},
+		Leases: &common.SharedLeasesConfig{RenewInterval: common.Duration(100 * time.Millisecond)},
+	}
+	require.NoError(t, cfg.SetDefaults("test"))
+	ssr, err := data.NewSharedStateRegistry(ctx, &log.Logger, cfg)
+	require.NoError(t, err)
+
+	s, err := NewScheduler(&log.Logger, nil, ssr, &common.SchedulerConfig{
+		Jobs: []*common.ScheduledJobConfig{
+			{Id: "job1", Schedule: "@hourly", Type: testType, Timeout: common.Duration(time.Minute)},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, s.lease)
+
+	require.Eventually(t, func() bool {
+		_, held := s.lease.Held()
+		return held
+	}, 2*time.Second, 20*time.Millisecond, "the only replica takes the scheduler lease")
+	run := s.runOnce(ctx, s.jobs[0], time.Now().Add(-2*time.Hour).Truncate(time.Hour))
+	assert.Equal(t, ScheduledJobRunSucceeded, run.Status)
+	require.NotNil(t, runCtx)
+	assert.Error(t, runCtx.Err(), "the run context ends with the run")
+}
@@ -0,0 +1,436 @@
+package erpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+)
+
+const (
+	// selfServiceKeyScanLimit bounds how many records the erpc_myApiKeys index
+	// scans per auth connector, since connectors have no index by user id.
+	selfServiceKeyScanLimit = 10_000
+	selfServiceKeyPageSize  = 500
+	selfServiceKeyIndexTTL  = 30 * time.Second
+)
+
+type ConsumerErrorRecord struct {
+	At        time.Time `json:"at"`
+	Method    string    `json:"method"`
+	NetworkId string    `json:"networkId,omitempty"`
+	Code      string    `json:"code"`
+	Message   string    `json:"message"`
+}
+
+type consumerMethodUsage struct {
+	Requests    int64 `json:"requests"`
+	Errors      int64 `json:"errors"`
+	RateLimited int64 `json:"rateLimited"`
+}
+
+type consumerUsage struct {
+	firstSeenAt  time.Time
+	lastSeenAt   time.Time
+	total        consumerMethodUsage
+	methods      map[string]*consumerMethodUsage
+	recentErrors []ConsumerErrorRecord
+}
+
+// ConsumerUsageTracker keeps per-user request counters and recent failures
+// in memory, so authenticated consumers can inspect their own traffic via the
+// erpc_my* self-service methods. Counters are per instance and reset on restart.
+type ConsumerUsageTracker struct {
+	mu               sync.Mutex
+	users            map[string]*consumerUsage
+	recentErrorsSize int
+	maxUsers         int
+}
+
+func NewConsumerUsageTracker(cfg *common.SelfServiceConfig) *ConsumerUsageTracker {
+	return &ConsumerUsageTracker{
+		users:            make(map[string]*consumerUsage),
+		recentErrorsSize: cfg.RecentErrorsSize,
+		maxUsers:         cfg.MaxTrackedUsers,
+	}
+}
+
+func (t *ConsumerUsageTracker) Record(userId, method, networkId string, err error) {
+	if t == nil || userId == "" {
+		return
+	}
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u, ok := t.users[userId]
+	if !ok {
+		if t.maxUsers > 0 && len(t.users) >= t.maxUsers {
+			t.evictLeastRecentLocked()
+		}
+		u = &consumerUsage{
+			firstSeenAt: now,
+			methods:     make(map[string]*consumerMethodUsage),
+		}
+		t.users[userId] = u
+	}
+	u.lastSeenAt = now
+
+	mu, ok := u.methods[method]
+	if !ok {
+		mu = &consumerMethodUsage{}
+		u.methods[method] = mu
+	}
+	u.total.Requests++
+	mu.Requests++
+	if err == nil {
+		return
+	}
+	u.total.Errors++
+	mu.Errors++
+	if common.HasErrorCode(
+		err,
+		common.ErrCodeAuthRateLimitRuleExceeded,
+		common.ErrCodeProjectRateLimitRuleExceeded,
+		common.ErrCodeNetworkRateLimitRuleExceeded,
+	) {
+		u.total.RateLimited++
+		mu.RateLimited++
+	}
+	if t.recentErrorsSize <= 0 {
+		return
+	}
+	rec := ConsumerErrorRecord{
+		At:        now,
+		Method:    method,
+		NetworkId: networkId,
+		Code:      "ErrUnknown",
+		Message:   "unexpected error",
+	}
+	// Only the top-level code and message are kept; nested causes may name
+	// upstreams or endpoints the tenant must not see.
+	if se, ok := err.(common.StandardError); ok {
+		rec.Code = string(se.Base().Code)
+		rec.Message = se.Base().Message
+	}
+	u.recentErrors = append(u.recentErrors, rec)
+	if len(u.recentErrors) > t.recentErrorsSize {
+		u.recentErrors = u.recentErrors[len(u.recentErrors)-t.recentErrorsSize:]
+	}
+}
+
+func (t *ConsumerUsageTracker) evictLeastRecentLocked() {
+	var oldestId string
+	var oldest time.Time
+	for id, u := range t.users {
+		if oldestId == "" || u.lastSeenAt.Before(oldest) {
+			oldestId, oldest = id, u.lastSeenAt
+		}
+	}
+	delete(t.users, oldestId)
+}
+
+// Usage returns the counters of a user, or nil if the user has not been seen.
+func (t *ConsumerUsageTracker) Usage(userId string) map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u, ok := t.users[userId]
+	if !ok {
+		return nil
+	}
+	methods := make(map[string]consumerMethodUsage, len(u.methods))
+	for m, mu := range u.methods {
+		methods[m] = *mu
+	}
+	return map[string]interface{}{
+		"firstSeenAt": u.firstSeenAt,
+		"lastSeenAt":  u.lastSeenAt,
+		"requests":    u.total.Requests,
+		"errors":      u.total.Errors,
+		"rateLimited": u.total.RateLimited,
+		"methods":     methods,
+	}
+}
+
+// Reset drops the counters and recent errors of the given users, or of every
+// user when userIds is empty, and returns how many were tracked.
+func (t *ConsumerUsageTracker) Reset(userIds []string) int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(userIds) == 0 {
+		n := len(t.users)
+		t.users = make(map[string]*consumerUsage)
+		return n
+	}
+	n := 0
+	for _, id := range userIds {
+		if _, ok := t.users[id]; ok {
+			delete(t.users, id)
+			n++
+		}
+	}
+	return n
+}
+
+// RecentErrors returns the latest failures of a user, most recent first.
+func (t *ConsumerUsageTracker) RecentErrors(userId string) []ConsumerErrorRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := []ConsumerErrorRecord{}
+	if u, ok := t.users[userId]; ok {
+		for i := len(u.recentErrors) - 1; i >= 0; i-- {
+			out = append(out, u.recentErrors[i])
+		}
+	}
+	return out
+}
+
+func isSelfServiceMethod(method string) bool {
+	switch method {
+	case "erpc_myApiKeys", "erpc_myUsage", "erpc_myRateLimits", "erpc_myRecentErrors":
+		return true
+	}
+	return false
+}
+
+// RecordConsumerOutcome feeds the self-service usage tracker; it is a no-op
+// when self-service is disabled or the request carries no authenticated user.
+func (p *PreparedProject) RecordConsumerOutcome(nq *common.NormalizedRequest, method, networkId string, err error) {
+	if p.consumerUsage == nil || nq == nil || nq.User() == nil {
+		return
+	}
+	p.consumerUsage.Record(nq.User().Id, method, networkId, err)
+}
+
+// HandleSelfServiceRequest serves the erpc_my* methods for the consumer
+// authenticated on the request. It returns handled=false when self-service is
+// not enabled or the method is not a self-service method, so the request
+// continues through the normal proxy path.
+func (p *PreparedProject) HandleSelfServiceRequest(ctx context.Context, nq *common.NormalizedRequest, method string) (resp *common.NormalizedResponse, handled bool, err error) {
+	if p.consumerUsage == nil || !isSelfServiceMethod(method) {
+		return nil, false, nil
+	}
+	user := nq.User()
+	if user == nil || user.Id == "" {
+		return nil, true, common.NewErrAuthUnauthorized("selfService", "self-service methods require an authenticated consumer")
+	}
+
+	switch method {
+	case "erpc_myApiKeys":
+		resp, err = p.handleMyApiKeys(ctx, nq, user)
+	case "erpc_myUsage":
+		resp, err = p.handleMyUsage(ctx, nq, user)
+	case "erpc_myRateLimits":
+		resp, err = p.handleMyRateLimits(nq, user)
+	case "erpc_myRecentErrors":
+		resp, err = makeSelectionResponse(nq, map[string]interface{}{
+			"userId": user.Id,
+			"errors": p.consumerUsage.RecentErrors(user.Id),
+		})
+	}
+	return resp, true, err
+}
+
+// handleMyUsage returns the in-memory counters of the caller and, when
+// analytics rollups are configured, the caller's own rollups of this project
+// as "history". The projectId and userId params are ignored: a consumer only
+// ever sees its own traffic.
+func (p *PreparedProject) handleMyUsage(ctx context.Context, nq *common.NormalizedRequest, user *common.User) (*common.NormalizedResponse, error) {
+	result := map[string]interface{}{
+		"userId": user.Id,
+		"usage":  p.consumerUsage.Usage(user.Id),
+	}
+	if p.analytics != nil {
+		q, err := parseAnalyticsQuery(nq, []string{AnalyticsGroupByNetwork, AnalyticsGroupByMethod})
+		if err != nil {
+			return nil, err
+		}
+		q.ProjectId, q.UserId = p.Config.Id, user.Id
+		points, totals, err := p.analytics.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		result["history"] = map[string]interface{}{
+			"resolution": q.Resolution,
+			"from":       q.From.UTC(),
+			"to":         q.To.UTC(),
+			"points":     points,
+			"totals":     totals,
+		}
+	}
+	return makeSelectionResponse(nq, result)
+}
+
+func (p *PreparedProject) handleMyApiKeys(ctx context.Context, nq *common.NormalizedRequest, user *common.User) (*common.NormalizedResponse, error) {
+	apiKeys := []map[string]interface{}{}
+	truncated := false
+	if p.consumerApiKeys != nil {
+		keys, trunc, err := p.consumerApiKeys.Keys(ctx, user.Id)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, keys...)
+		truncated = trunc
+	}
+
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"userId":    user.Id,
+		"apiKeys":   apiKeys,
+		"truncated": truncated,
+	})
+}
+
+// consumerApiKeyIndex groups the records of the auth database connectors by
+// user id. Connectors have no such index, so it is built with one scan and
+// reused for selfServiceKeyIndexTTL; erpc_myApiKeys then costs a map lookup
+// instead of a table scan per call, at the price of up to a TTL of staleness.
+type consumerApiKeyIndex struct {
+	mu         sync.Mutex
+	connectors func() []data.Connector
+	ttl        time.Duration
+	builtAt    time.Time
+	byUser     map[string][]map[string]interface{}
+	truncated  bool
+}
+
+func newConsumerApiKeyIndex(connectors func() []data.Connector) *consumerApiKeyIndex {
+	return &consumerApiKeyIndex{
+		connectors: connectors,
+		ttl:        selfServiceKeyIndexTTL,
+	}
+}
+
+// Keys returns the masked keys of userId and whether any connector was only
+// partially scanned. Concurrent callers wait for a single rebuild.
+func (x *consumerApiKeyIndex) Keys(ctx context.Context, userId string) ([]map[string]interface{}, bool, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.byUser == nil || time.Since(x.builtAt) >= x.ttl {
+		byUser, truncated, err := x.build(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		x.byUser = byUser
+		x.truncated = truncated
+		x.builtAt = time.Now()
+	}
+	return x.byUser[userId], x.truncated, nil
+}
+
+func (x *consumerApiKeyIndex) build(ctx context.Context) (map[string][]map[string]interface{}, bool, error) {
+	byUser := make(map[string][]map[string]interface{})
+	truncated := false
+	for _, connector := range x.connectors() {
+		scanned := 0
+		token := ""
+		for {
+			items, next, err := connector.List(ctx, data.ConnectorMainIndex, selfServiceKeyPageSize, token)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to list API keys of connector %s: %w", connector.Id(), err)
+			}
+			for _, item := range items {
+				if item.RangeKey == "" {
+					continue
+				}
+				key := map[string]interface{}{
+					"key":         maskApiKey(item.PartitionKey),
+					"connectorId": connector.Id(),
+					"enabled":     true,
+				}
+				var userData map[string]interface{}
+				if err := json.Unmarshal(item.Value, &userData); err == nil {
+					if enabled, ok := userData["enabled"].(bool); ok {
+						key["enabled"] = enabled
+					}
+					if budget, ok := userData["rateLimitBudget"].(string); ok && budget != "" {
+						key["rateLimitBudget"] = budget
+					}
+				}
+				byUser[item.RangeKey] = append(byUser[item.RangeKey], key)
+			}
+			scanned += len(items)
+			if next == "" {
+				break
+			}
+			if scanned >= selfServiceKeyScanLimit {
+				truncated = true
+				break
+			}
+			token = next
+		}
+	}
+	return byUser, truncated, nil
+}
+
+func (p *PreparedProject) handleMyRateLimits(nq *common.NormalizedRequest, user *common.User) (*common.NormalizedResponse, error) {
+	budgets := []map[string]interface{}{}
+	for _, b := range []map[string]interface{}{
+		p.describeRateLimitBudget("consumer", user.RateLimitBudget),
+		p.describeRateLimitBudget("project", p.Config.RateLimitBudget),
+	} {
+		if b != nil {
+			budgets = append(budgets, b)
+		}
+	}
+
+	rateLimited := map[string]int64{}
+	if usage := p.consumerUsage.Usage(user.Id); usage != nil {
+		for m, mu := range usage["methods"].(map[string]consumerMethodUsage) {
+			if mu.RateLimited > 0 {
+				rateLimited[m] = mu.RateLimited
+			}
+		}
+	}
+
+	return makeSelectionResponse(nq, map[string]interface{}{
+		"userId":      user.Id,
+		"budgets":     budgets,
+		"rateLimited": rateLimited,
+	})
+}
+
+// describeRateLimitBudget lists the rules of a budget as seen by a consumer,
+// or returns nil when budgetId is empty or unknown.
+func (p *PreparedProject) describeRateLimitBudget(scope, budgetId string) map[string]interface{} {
+	if budgetId == "" || p.rateLimitersRegistry == nil {
+		return nil
+	}
+	rlb, err := p.rateLimitersRegistry.GetBudget(budgetId)
+	if err != nil || rlb == nil {
+		return nil
+	}
+	rules := []map[string]interface{}{}
+	for _, rc := range rlb.RuleConfigs() {
+		rules = append(rules, map[string]interface{}{
+			"method":   rc.Method,
+			"maxCount": rc.MaxCount,
+			"period":   rc.Period.String(),
+			"scope":    rc.ScopeString(),
+		})
+	}
+	return map[string]interface{}{
+		"scope":  scope,
+		"budget": budgetId,
+		"rules":  rules,
+	}
+}
+
+// maskApiKey keeps just enough of a key for the owner to recognize it.
+func maskApiKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "****" + key[len(key)-4:]
+}
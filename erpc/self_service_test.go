@@ -0,0 +1,198 @@
+package erpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/upstream"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSelfServiceTestRequest(t *testing.T, method string, user *common.User) *common.NormalizedRequest {
+	jrq := common.NewJsonRpcRequest(method, []interface{}{})
+	jrq.ID = 1
+	nq := common.NewNormalizedRequestFromJsonRpcRequest(jrq)
+	nq.SetUser(user)
+	return nq
+}
+
+func selfServiceResult(t *testing.T, resp *common.NormalizedResponse) map[string]interface{} {
+	jrr, err := resp.JsonRpcResponse(context.Background())
+	require.NoError(t, err)
+	var result map[string]interface{}
+	require.NoError(t, common.SonicCfg.Unmarshal(jrr.GetResultBytes(), &result))
+	return result
+}
+
+func TestConsumerUsageTracker_Record(t *testing.T) {
+	tr := NewConsumerUsageTracker(&common.SelfServiceConfig{RecentErrorsSize: 2, MaxTrackedUsers: 2})
+
+	tr.Record("alice", "eth_call", "evm:1", nil)
+	tr.Record("alice", "eth_call", "evm:1", common.NewErrProjectRateLimitRuleExceeded("main", "tenant", "method:eth_call"))
+	tr.Record("alice", "eth_getLogs", "evm:1", errors.New("dial tcp 10.0.0.7:8545: connection refused"))
+	tr.Record("alice", "eth_chainId", "evm:1", common.NewErrInvalidRequest(errors.New("bad")))
+
+	usage := tr.Usage("alice")
+	require.NotNil(t, usage)
+	assert.EqualValues(t, 4, usage["requests"])
+	assert.EqualValues(t, 3, usage["errors"])
+	assert.EqualValues(t, 1, usage["rateLimited"])
+	methods := usage["methods"].(map[string]consumerMethodUsage)
+	assert.EqualValues(t, 2, methods["eth_call"].Requests)
+	assert.EqualValues(t, 1, methods["eth_call"].RateLimited)
+
+	recent := tr.RecentErrors("alice")
+	require.Len(t, recent, 2, "recent errors are capped at recentErrorsSize")
+	assert.Equal(t, "eth_chainId", recent[0].Method, "most recent error comes first")
+	assert.Equal(t, string(common.ErrCodeInvalidRequest), recent[0].Code)
+	assert.Equal(t, "ErrUnknown", recent[1].Code)
+	assert.NotContains(t, recent[1].Message, "10.0.0.7", "non-standard errors must not leak internal details")
+
+	tr.Record("bob", "eth_call", "evm:1", nil)
+	tr.Record("carol", "eth_call", "evm:1", nil)
+	assert.Nil(t, tr.Usage("alice"), "least recently seen user is evicted beyond maxTrackedUsers")
+	assert.NotNil(t, tr.Usage("carol"))
+
+	assert.Equal(t, 1, tr.Reset([]string{"bob", "dave"}))
+	assert.Nil(t, tr.Usage("bob"))
+	assert.NotNil(t, tr.Usage("carol"))
+	assert.Equal(t, 1, tr.Reset(nil))
+	assert.Nil(t, tr.Usage("carol"))
+}
+
+func TestPreparedProject_HandleSelfServiceRequest(t *testing.T) {
+	ctx := context.Background()
+	rlr, err := upstream.NewRateLimitersRegistry(ctx, &common.RateLimiterConfig{
+		Budgets: []*common.RateLimitBudgetConfig{
+			{
+				Id: "tenant-basic",
+				Rules: []*common.RateLimitRuleConfig{
+					{Method: "*", MaxCount: 100, Period: common.RateLimitPeriodSecond, PerUser: true},
+				},
+			},
+		},
+	}, &log.Logger)
+	require.NoError(t, err)
+
+	cfg := &common.SelfServiceConfig{Enabled: true}
+	cfg.SetDefaults()
+	pp := &PreparedProject{
+		Config:               &common.ProjectConfig{Id: "main"},
+		rateLimitersRegistry: rlr,
+		consumerUsage:        NewConsumerUsageTracker(cfg),
+	}
+	user := &common.User{Id: "alice", RateLimitBudget: "tenant-basic"}
+
+	t.Run("RequiresAuthenticatedUser", func(t *testing.T) {
+		_, handled, err := pp.HandleSelfServiceRequest(ctx, newSelfServiceTestRequest(t, "erpc_myUsage", nil), "erpc_myUsage")
+		assert.True(t, handled)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthUnauthorized))
+	})
+
+	t.Run("IgnoresRegularMethods", func(t *testing.T) {
+		_, handled, err := pp.HandleSelfServiceRequest(ctx, newSelfServiceTestRequest(t, "eth_call", user), "eth_call")
+		assert.False(t, handled)
+		assert.NoError(t, err)
+	})
+
+	t.Run("DisabledProjectPassesThrough", func(t *testing.T) {
+		disabled := &PreparedProject{Config: &common.ProjectConfig{Id: "main"}}
+		_, handled, _ := disabled.HandleSelfServiceRequest(ctx, newSelfServiceTestRequest(t, "erpc_myUsage", user), "erpc_myUsage")
+		assert.False(t, handled)
+	})
+
+	t.Run("UsageAndRateLimits", func(t *testing.T) {
+		pp.RecordConsumerOutcome(newSelfServiceTestRequest(t, "eth_call", user), "eth_call", "evm:1", nil)
+		pp.RecordConsumerOutcome(newSelfServiceTestRequest(t, "eth_call", user), "eth_call", "evm:1",
+			common.NewErrAuthRateLimitRuleExceeded("main", "secret", "tenant-basic", "method:eth_call", "alice", "n/a"))
+		// Another tenant's traffic is never visible
+		pp.RecordConsumerOutcome(newSelfServiceTestRequest(t, "eth_call", &common.User{Id: "bob"}), "eth_call", "evm:1", nil)
+
+		resp, handled, err := pp.HandleSelfServiceRequest(ctx, newSelfServiceTestRequest(t, "erpc_myUsage", user), "erpc_myUsage")
+		require.True(t, handled)
+		require.NoError(t, err)
+		usage := selfServiceResult(t, resp)["usage"].(map[string]interface{})
+		assert.EqualValues(t, 2, usage["requests"])
+		assert.EqualValues(t, 1, usage["rateLimited"])
+
+		resp, _, err = pp.HandleSelfServiceRequest(ctx, newSelfServiceTestRequest(t, "erpc_myRateLimits", user), "erpc_myRateLimits")
+		require.NoError(t, err)
+		result := selfServiceResult(t, resp)
+		budgets := result["budgets"].([]interface{})
+		require.Len(t, budgets, 1)
+		budget := budgets[0].(map[string]interface{})
+		assert.Equal(t, "consumer", budget["scope"])
+		assert.Equal(t, "tenant-basic", budget["budget"])
+		rule := budget["rules"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "second", rule["period"])
+		assert.Equal(t, "user", rule["scope"])
+		assert.EqualValues(t, 1, result["rateLimited"].(map[string]interface{})["eth_call"])
+
+		resp, _, err = pp.HandleSelfServiceRequest(ctx, newSelfServiceTestRequest(t, "erpc_myRecentErrors", user), "erpc_myRecentErrors")
+		require.NoError(t, err)
+		recent := selfServiceResult(t, resp)["errors"].([]interface{})
+		require.Len(t, recent, 1)
+		assert.Equal(t, string(common.ErrCodeAuthRateLimitRuleExceeded), recent[0].(map[string]interface{})["code"])
+	})
+}
+
+func TestMaskApiKey(t *testing.T) {
+	assert.Equal(t, "sk_l****cdef", maskApiKey("sk_live_0123456789abcdef"))
+	assert.Equal(t, "****", maskApiKey("short"))
+}
+
+// listCountingConnector serves a fixed auth table through List and counts the
+// calls, leaving every other Connector method unimplemented.
+type listCountingConnector struct {
+	data.Connector
+	items []data.KeyValuePair
+	lists int
+}
+
+func (c *listCountingConnector) Id() string { return "auth-db" }
+
+func (c *listCountingConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]data.KeyValuePair, string, error) {
+	c.lists++
+	return c.items, "", nil
+}
+
+func TestPreparedProject_HandleMyApiKeys_UsesIndex(t *testing.T) {
+	ctx := context.Background()
+	connector := &listCountingConnector{items: []data.KeyValuePair{
+		{PartitionKey: "sk_live_alice_0001", RangeKey: "alice", Value: []byte(`{"enabled":true,"rateLimitBudget":"tenant-basic"}`)},
+		{PartitionKey: "sk_live_alice_0002", RangeKey: "alice", Value: []byte(`{"enabled":false}`)},
+		{PartitionKey: "sk_live_bob_00001", RangeKey: "bob", Value: []byte(`{}`)},
+	}}
+	pp := &PreparedProject{
+		Config:          &common.ProjectConfig{Id: "main"},
+		consumerUsage:   NewConsumerUsageTracker(&common.SelfServiceConfig{Enabled: true}),
+		consumerApiKeys: newConsumerApiKeyIndex(func() []data.Connector { return []data.Connector{connector} }),
+	}
+
+	myKeys := func(userId string) []interface{} {
+		resp, handled, err := pp.HandleSelfServiceRequest(ctx, newSelfServiceTestRequest(t, "erpc_myApiKeys", &common.User{Id: userId}), "erpc_myApiKeys")
+		require.True(t, handled)
+		require.NoError(t, err)
+		result := selfServiceResult(t, resp)
+		assert.Equal(t, false, result["truncated"])
+		return result["apiKeys"].([]interface{})
+	}
+
+	alice := myKeys("alice")
+	require.Len(t, alice, 2)
+	assert.Equal(t, "tenant-basic", alice[0].(map[string]interface{})["rateLimitBudget"])
+	assert.Equal(t, false, alice[1].(map[string]interface{})["enabled"])
+	assert.Len(t, myKeys("bob"), 1)
+	assert.Len(t, myKeys("carol"), 0)
+	assert.Equal(t, 1, connector.lists, "callers within the TTL must share one scan")
+
+	pp.consumerApiKeys.builtAt = time.Now().Add(-selfServiceKeyIndexTTL)
+	assert.Len(t, myKeys("alice"), 2)
+	assert.Equal(t, 2, connector.lists, "an expired index must be rebuilt")
+}
@@ -0,0 +1,423 @@
+package erpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+)
+
+const (
+	subscriptionArchiveKindHeads = "heads"
+	subscriptionArchiveKindLogs  = "logs"
+
+	// subscriptionArchiveFilterIdleTimeout is how long the kind of a filter
+	// is remembered after it was last polled; nodes expire idle filters
+	// after a few minutes.
+	subscriptionArchiveFilterIdleTimeout = time.Hour
+	// subscriptionArchiveMaxFilters bounds the remembered filter kinds.
+	subscriptionArchiveMaxFilters = 100_000
+)
+
+// ArchivedSubscriptionEvent is one line of an archive object.
+type ArchivedSubscriptionEvent struct {
+	ProjectId string `json:"projectId"`
+	NetworkId string `json:"networkId"`
+	// Kind is "head" or "log".
+	Kind            string    `json:"kind"`
+	BlockNumber     string    `json:"blockNumber,omitempty"`
+	BlockHash       string    `json:"blockHash"`
+	TransactionHash string    `json:"transactionHash,omitempty"`
+	LogIndex        string    `json:"logIndex,omitempty"`
+	Removed         bool      `json:"removed,omitempty"`
+	ObservedAt      time.Time `json:"observedAt"`
+	// Event is the head's block hash or the log, as served to the client.
+	Event json.RawMessage `json:"event"`
+}
+
+type subscriptionArchiveJob struct {
+	networkId  string
+	kind       string
+	result     []byte
+	observedAt time.Time
+}
+
+type archivedFilter struct {
+	kind   string
+	seenAt time.Time
+}
+
+// subscriptionArchiveBatch is the content of one future object.
+type subscriptionArchiveBatch struct {
+	networkId string
+	kind      string
+	day       string
+	buf       bytes.Buffer
+	count     int
+	seen      map[string]struct{}
+}
+
+// SubscriptionArchiver writes the heads and logs that eth_getFilterChanges
+// (and erpc_getDecodedFilterChanges) serve to clients into gzip-compressed
+// NDJSON objects, partitioned by network and day. The kind of a filter is
+// learned from the eth_newFilter / eth_newBlockFilter response that created
+// it; filters created elsewhere are archived when their changes are logs.
+//
+// Responses are copied and queued, then parsed and batched by one worker, so
+// requests never wait on storage; when the queue is full they are dropped
+// and counted. An event served to several filters within one batch is
+// written once.
+type SubscriptionArchiver struct {
+	logger        *zerolog.Logger
+	projectId     string
+	prefix        string
+	instance      string
+	events        map[string]bool
+	maxRecords    int
+	maxSize       int
+	flushInterval time.Duration
+	uploadTimeout time.Duration
+	put           func(ctx context.Context, key string, body []byte) error
+
+	jobs    chan subscriptionArchiveJob
+	seq     atomic.Uint64
+	uploads sync.WaitGroup
+
+	filtersMu sync.Mutex
+	filters   map[string]*archivedFilter
+
+	// batches is only used by the worker goroutine.
+	batches map[string]*subscriptionArchiveBatch
+}
+
+func NewSubscriptionArchiver(appCtx context.Context, logger *zerolog.Logger, projectId string, cfg *common.SubscriptionArchiveConfig) (*SubscriptionArchiver, error) {
+	bucket, prefix, ok := cfg.BucketAndPrefix()
+	if !ok {
+		return nil, fmt.Errorf("invalid subscription archive path '%s'", cfg.Path)
+	}
+	maxSize, err := util.ParseByteSize(cfg.MaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription archive maxSize: %w", err)
+	}
+	client, err := data.NewS3Client(cfg.Auth, cfg.Region, cfg.Endpoint, cfg.ForcePathStyle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription archive client: %w", err)
+	}
+
+	lg := logger.With().Str("component", "subscriptionArchive").Str("bucket", bucket).Logger()
+	a := &SubscriptionArchiver{
+		logger:        &lg,
+		projectId:     projectId,
+		prefix:        prefix,
+		instance:      processInstanceName(),
+		events:        make(map[string]bool, len(cfg.Events)),
+		maxRecords:    cfg.MaxRecords,
+		maxSize:       maxSize,
+		flushInterval: cfg.FlushInterval.Duration(),
+		uploadTimeout: cfg.UploadTimeout.Duration(),
+		jobs:          make(chan subscriptionArchiveJob, cfg.QueueSize),
+		filters:       make(map[string]*archivedFilter),
+		batches:       make(map[string]*subscriptionArchiveBatch),
+	}
+	for _, e := range cfg.Events {
+		a.events[e] = true
+	}
+	a.put = func(ctx context.Context, key string, body []byte) error {
+		_, err := client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+			Bucket:          aws.String(bucket),
+			Key:             aws.String(key),
+			Body:            bytes.NewReader(body),
+			ContentType:     aws.String("application/x-ndjson"),
+			ContentEncoding: aws.String("gzip"),
+		})
+		return err
+	}
+	go a.run(appCtx)
+	return a, nil
+}
+
+// processInstanceName names this process in the keys it writes, so replicas
+// (and restarts of one replica) writing the same partition never overwrite
+// each other's objects.
+func processInstanceName() string {
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "erpc"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return strings.NewReplacer("/", "_", ":", "_", " ", "_").Replace(host) + "-" + hex.EncodeToString(suffix)
+}
+
+// Observe looks at a successful response of the project. Filter creations and
+// removals update the known filter kinds; filter changes are queued for
+// archiving. It never blocks.
+func (a *SubscriptionArchiver) Observe(ctx context.Context, networkId, method string, nq *common.NormalizedRequest, resp *common.NormalizedResponse) {
+	if a == nil || resp == nil {
+		return
+	}
+	switch method {
+	case "eth_newFilter", "eth_newBlockFilter", "eth_uninstallFilter",
+		"eth_getFilterChanges", "erpc_getDecodedFilterChanges":
+	default:
+		return
+	}
+
+	jrr, err := resp.JsonRpcResponse(ctx)
+	if err != nil || jrr == nil || jrr.Error != nil {
+		return
+	}
+	var result bytes.Buffer
+	if _, err := jrr.WriteResultTo(&result, false); err != nil {
+		return
+	}
+
+	now := time.Now()
+	switch method {
+	case "eth_newFilter", "eth_newBlockFilter":
+		var filterId string
+		if err := common.SonicCfg.Unmarshal(result.Bytes(), &filterId); err != nil || filterId == "" {
+			return
+		}
+		kind := subscriptionArchiveKindLogs
+		if method == "eth_newBlockFilter" {
+			kind = subscriptionArchiveKindHeads
+		}
+		a.rememberFilter(networkId, filterId, kind, now)
+		return
+	}
+
+	filterId := subscriptionArchiveFilterId(ctx, nq)
+	if method == "eth_uninstallFilter" {
+		a.filtersMu.Lock()
+		delete(a.filters, networkId+"|"+filterId)
+		a.filtersMu.Unlock()
+		return
+	}
+	if result.Len() <= 2 {
+		// "[]" or "null": nothing new since the last poll.
+		return
+	}
+
+	kind := ""
+	a.filtersMu.Lock()
+	if f, ok := a.filters[networkId+"|"+filterId]; ok {
+		f.seenAt = now
+		kind = f.kind
+	}
+	a.filtersMu.Unlock()
+	if kind != "" && !a.events[kind] {
+		return
+	}
+
+	select {
+	case a.jobs <- subscriptionArchiveJob{networkId: networkId, kind: kind, result: result.Bytes(), observedAt: now}:
+	default:
+		if kind == "" {
+			kind = "unknown"
+		}
+		telemetry.MetricSubscriptionArchiveEventsTotal.WithLabelValues(a.projectId, networkId, kind, "dropped").Inc()
+	}
+}
+
+func subscriptionArchiveFilterId(ctx context.Context, nq *common.NormalizedRequest) string {
+	jrq, err := nq.JsonRpcRequest(ctx)
+	if err != nil || jrq == nil {
+		return ""
+	}
+	jrq.RLock()
+	defer jrq.RUnlock()
+	if len(jrq.Params) == 0 {
+		return ""
+	}
+	id, _ := jrq.Params[0].(string)
+	return id
+}
+
+func (a *SubscriptionArchiver) rememberFilter(networkId, filterId, kind string, now time.Time) {
+	a.filtersMu.Lock()
+	defer a.filtersMu.Unlock()
+	if len(a.filters) >= subscriptionArchiveMaxFilters {
+		// Full of filters nobody polls any more; their changes are still
+		// archived when they turn out to be logs.
+		a.pruneFiltersLocked(now.Add(-subscriptionArchiveFilterIdleTimeout / 4))
+		if len(a.filters) >= subscriptionArchiveMaxFilters {
+			return
+		}
+	}
+	a.filters[networkId+"|"+filterId] = &archivedFilter{kind: kind, seenAt: now}
+}
+
+func (a *SubscriptionArchiver) pruneFiltersLocked(before time.Time) {
+	for k, f := range a.filters {
+		if f.seenAt.Before(before) {
+			delete(a.filters, k)
+		}
+	}
+}
+
+func (a *SubscriptionArchiver) run(ctx context.Context) {
+	ticker := time.NewTicker(a.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Write out what is buffered; new responses are no longer read.
+			a.flushAll()
+			a.uploads.Wait()
+			return
+		case job := <-a.jobs:
+			a.process(job)
+		case now := <-ticker.C:
+			a.flushAll()
+			a.filtersMu.Lock()
+			a.pruneFiltersLocked(now.Add(-subscriptionArchiveFilterIdleTimeout))
+			a.filtersMu.Unlock()
+		}
+	}
+}
+
+// process adds the events of one filter changes response to their batches.
+// Strings are block hashes of a block filter; objects are logs.
+func (a *SubscriptionArchiver) process(job subscriptionArchiveJob) {
+	var items []json.RawMessage
+	if err := common.SonicCfg.Unmarshal(job.result, &items); err != nil {
+		a.logger.Debug().Err(err).Str("networkId", job.networkId).Msg("skipping filter changes that are not a JSON array")
+		return
+	}
+	for _, item := range items {
+		ev := &ArchivedSubscriptionEvent{
+			ProjectId:  a.projectId,
+			NetworkId:  job.networkId,
+			ObservedAt: job.observedAt.UTC(),
+			Event:      item,
+		}
+		var kind, dedupKey string
+		switch {
+		case len(item) > 0 && item[0] == '"' && job.kind == subscriptionArchiveKindHeads:
+			if err := common.SonicCfg.Unmarshal(item, &ev.BlockHash); err != nil {
+				continue
+			}
+			kind, ev.Kind, dedupKey = subscriptionArchiveKindHeads, "head", ev.BlockHash
+		case len(item) > 0 && item[0] == '{' && job.kind != subscriptionArchiveKindHeads:
+			if !a.events[subscriptionArchiveKindLogs] {
+				continue
+			}
+			var lg struct {
+				BlockNumber     string `json:"blockNumber"`
+				BlockHash       string `json:"blockHash"`
+				TransactionHash string `json:"transactionHash"`
+				LogIndex        string `json:"logIndex"`
+				Removed         bool   `json:"removed"`
+			}
+			if err := common.SonicCfg.Unmarshal(item, &lg); err != nil {
+				continue
+			}
+			kind, ev.Kind = subscriptionArchiveKindLogs, "log"
+			ev.BlockNumber, ev.BlockHash, ev.TransactionHash, ev.LogIndex, ev.Removed = lg.BlockNumber, lg.BlockHash, lg.TransactionHash, lg.LogIndex, lg.Removed
+			dedupKey = fmt.Sprintf("%s|%s|%t", ev.BlockHash, ev.LogIndex, ev.Removed)
+		default:
+			// Transaction hashes of pending transaction filters, or hashes
+			// of a filter whose kind is unknown.
+			continue
+		}
+		a.add(kind, dedupKey, ev)
+	}
+}
+
+func (a *SubscriptionArchiver) add(kind, dedupKey string, ev *ArchivedSubscriptionEvent) {
+	day := ev.ObservedAt.Format("2006-01-02")
+	key := ev.NetworkId + "|" + kind + "|" + day
+	b := a.batches[key]
+	if b == nil {
+		b = &subscriptionArchiveBatch{networkId: ev.NetworkId, kind: kind, day: day, seen: make(map[string]struct{})}
+		a.batches[key] = b
+	}
+	if _, dup := b.seen[dedupKey]; dup {
+		telemetry.MetricSubscriptionArchiveEventsTotal.WithLabelValues(a.projectId, ev.NetworkId, kind, "duplicate").Inc()
+		return
+	}
+	line, err := common.SonicCfg.Marshal(ev)
+	if err != nil {
+		return
+	}
+	b.seen[dedupKey] = struct{}{}
+	b.buf.Write(line)
+	b.buf.WriteByte('\n')
+	b.count++
+	if b.count >= a.maxRecords || b.buf.Len() >= a.maxSize {
+		delete(a.batches, key)
+		a.upload(b)
+	}
+}
+
+func (a *SubscriptionArchiver) flushAll() {
+	for key, b := range a.batches {
+		delete(a.batches, key)
+		a.upload(b)
+	}
+}
+
+// upload writes the batch in the background; a failed upload is logged and
+// its events are lost.
+func (a *SubscriptionArchiver) upload(b *subscriptionArchiveBatch) {
+	if b.count == 0 {
+		return
+	}
+	key := a.objectKey(b, time.Now().UTC())
+	a.uploads.Add(1)
+	go func() {
+		defer a.uploads.Done()
+		var body bytes.Buffer
+		zw := gzip.NewWriter(&body)
+		_, err := zw.Write(b.buf.Bytes())
+		if err == nil {
+			err = zw.Close()
+		}
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), a.uploadTimeout)
+			err = a.put(ctx, key, body.Bytes())
+			cancel()
+		}
+		if err != nil {
+			a.logger.Warn().Err(err).Str("key", key).Int("events", b.count).Msg("failed to upload subscription archive object")
+			telemetry.MetricSubscriptionArchiveUploadsTotal.WithLabelValues(a.projectId, "error").Inc()
+			telemetry.MetricSubscriptionArchiveEventsTotal.WithLabelValues(a.projectId, b.networkId, b.kind, "upload_error").Add(float64(b.count))
+			return
+		}
+		a.logger.Debug().Str("key", key).Int("events", b.count).Int("bytes", body.Len()).Msg("uploaded subscription archive object")
+		telemetry.MetricSubscriptionArchiveUploadsTotal.WithLabelValues(a.projectId, "success").Inc()
+		telemetry.MetricSubscriptionArchiveEventsTotal.WithLabelValues(a.projectId, b.networkId, b.kind, "uploaded").Add(float64(b.count))
+	}()
+}
+
+// objectKey is "<prefix>network=<networkId>/date=<day>/<kind>-<time>-<instance>-<seq>.ndjson.gz",
+// with ":" in the network id replaced by "_". The Hive-style partitions let
+// Athena, BigQuery or Spark prune by network and day.
+func (a *SubscriptionArchiver) objectKey(b *subscriptionArchiveBatch, now time.Time) string {
+	return fmt.Sprintf("%snetwork=%s/date=%s/%s-%s-%s-%06d.ndjson.gz",
+		a.prefix,
+		strings.ReplaceAll(b.networkId, ":", "_"),
+		b.day,
+		b.kind,
+		now.Format("150405"),
+		a.instance,
+		a.seq.Add(1),
+	)
+}
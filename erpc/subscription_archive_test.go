@@ -0,0 +1,175 @@
+package erpc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionArchiver(t *testing.T) {
+	ctx := context.Background()
+
+	newArchiver := func() (*SubscriptionArchiver, map[string][]ArchivedSubscriptionEvent, *sync.Mutex) {
+		objects := map[string][]ArchivedSubscriptionEvent{}
+		mu := &sync.Mutex{}
+		a := &SubscriptionArchiver{
+			logger:        &log.Logger,
+			projectId:     "main",
+			prefix:        "events/",
+			instance:      "test",
+			events:        map[string]bool{"heads": true, "logs": true},
+			maxRecords:    1000,
+			maxSize:       1 << 20,
+			flushInterval: time.Minute,
+			uploadTimeout: time.Second,
+			jobs:          make(chan subscriptionArchiveJob, 10),
+			filters:       map[string]*archivedFilter{},
+			batches:       map[string]*subscriptionArchiveBatch{},
+		}
+		a.put = func(ctx context.Context, key string, body []byte) error {
+			zr, err := gzip.NewReader(bytes.NewReader(body))
+			require.NoError(t, err)
+			var events []ArchivedSubscriptionEvent
+			sc := bufio.NewScanner(zr)
+			for sc.Scan() {
+				var ev ArchivedSubscriptionEvent
+				require.NoError(t, json.Unmarshal(sc.Bytes(), &ev))
+				events = append(events, ev)
+			}
+			mu.Lock()
+			objects[key] = events
+			mu.Unlock()
+			return nil
+		}
+		return a, objects, mu
+	}
+	respond := func(method string, params string, result string) (*common.NormalizedRequest, *common.NormalizedResponse) {
+		nq := common.NewNormalizedRequest([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"%s","params":%s}`, method, params)))
+		jrr, err := common.NewJsonRpcResponse(1, json.RawMessage(result), nil)
+		require.NoError(t, err)
+		return nq, common.NewNormalizedResponse().WithRequest(nq).WithJsonRpcResponse(jrr)
+	}
+	observe := func(a *SubscriptionArchiver, method, params, result string) {
+		nq, resp := respond(method, params, result)
+		a.Observe(ctx, "evm:1", method, nq, resp)
+	}
+	drain := func(a *SubscriptionArchiver) {
+		for len(a.jobs) > 0 {
+			a.process(<-a.jobs)
+		}
+		a.flushAll()
+		a.uploads.Wait()
+	}
+	log1 := `{"address":"0xa","blockNumber":"0x10","blockHash":"0xb1","transactionHash":"0xt1","logIndex":"0x0","removed":false}`
+	log2 := `{"address":"0xa","blockNumber":"0x10","blockHash":"0xb1","transactionHash":"0xt1","logIndex":"0x1","removed":false}`
+
+	t.Run("ArchivesHeadsOfBlockFiltersAndLogs", func(t *testing.T) {
+		a, objects, mu := newArchiver()
+		observe(a, "eth_newBlockFilter", `[]`, `"0x1"`)
+		observe(a, "eth_newFilter", `[{"address":"0xa"}]`, `"0x2"`)
+		observe(a, "eth_getFilterChanges", `["0x1"]`, `["0xb1","0xb2"]`)
+		observe(a, "eth_getFilterChanges", `["0x2"]`, "["+log1+"]")
+		drain(a)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, objects, 2)
+		for key, events := range objects {
+			assert.True(t, strings.HasPrefix(key, "events/network=evm_1/date="), key)
+			assert.True(t, strings.HasSuffix(key, ".ndjson.gz"), key)
+			switch {
+			case strings.Contains(key, "/heads-"):
+				require.Len(t, events, 2)
+				assert.Equal(t, "head", events[0].Kind)
+				assert.Equal(t, "0xb1", events[0].BlockHash)
+				assert.Equal(t, "main", events[0].ProjectId)
+			case strings.Contains(key, "/logs-"):
+				require.Len(t, events, 1)
+				assert.Equal(t, "log", events[0].Kind)
+				assert.Equal(t, "0x10", events[0].BlockNumber)
+				assert.Equal(t, "0xt1", events[0].TransactionHash)
+				assert.JSONEq(t, log1, string(events[0].Event))
+			default:
+				t.Fatalf("unexpected object %s", key)
+			}
+		}
+	})
+
+	t.Run("WritesALogPolledByTwoFiltersOnce", func(t *testing.T) {
+		a, objects, mu := newArchiver()
+		observe(a, "eth_getFilterChanges", `["0x5"]`, "["+log1+"]")
+		observe(a, "eth_getFilterChanges", `["0x6"]`, "["+log1+","+log2+"]")
+		drain(a)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, objects, 1)
+		for _, events := range objects {
+			assert.Len(t, events, 2)
+		}
+	})
+
+	t.Run("SkipsHashesOfUnknownFiltersAndDisabledKinds", func(t *testing.T) {
+		a, objects, mu := newArchiver()
+		a.events = map[string]bool{"logs": true}
+		observe(a, "eth_newBlockFilter", `[]`, `"0x1"`)
+		observe(a, "eth_getFilterChanges", `["0x1"]`, `["0xb1"]`)
+		// Pending transaction filters and filters created before a restart
+		// return hashes that cannot be told apart.
+		observe(a, "eth_getFilterChanges", `["0x9"]`, `["0xh1"]`)
+		drain(a)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Empty(t, objects)
+	})
+
+	t.Run("UninstallForgetsTheFilter", func(t *testing.T) {
+		a, _, _ := newArchiver()
+		observe(a, "eth_newBlockFilter", `[]`, `"0x1"`)
+		require.Len(t, a.filters, 1)
+		observe(a, "eth_uninstallFilter", `["0x1"]`, `true`)
+		assert.Empty(t, a.filters)
+	})
+
+	t.Run("FlushesABatchAtMaxRecords", func(t *testing.T) {
+		a, objects, mu := newArchiver()
+		a.maxRecords = 1
+		observe(a, "eth_getFilterChanges", `["0x5"]`, "["+log1+","+log2+"]")
+		a.process(<-a.jobs)
+		a.uploads.Wait()
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Len(t, objects, 2)
+		assert.Empty(t, a.batches)
+	})
+}
+
+func TestSubscriptionArchiveConfigBucketAndPrefix(t *testing.T) {
+	for path, want := range map[string][2]string{
+		"s3://bucket":          {"bucket", ""},
+		"s3://bucket/events":   {"bucket", "events/"},
+		"gs://lake/erpc/subs/": {"lake", "erpc/subs/"},
+	} {
+		bucket, prefix, ok := (&common.SubscriptionArchiveConfig{Path: path}).BucketAndPrefix()
+		assert.True(t, ok, path)
+		assert.Equal(t, want, [2]string{bucket, prefix}, path)
+	}
+	for _, path := range []string{"", "s3://", "https://bucket/x", "bucket/x"} {
+		_, _, ok := (&common.SubscriptionArchiveConfig{Path: path}).BucketAndPrefix()
+		assert.False(t, ok, path)
+	}
+}
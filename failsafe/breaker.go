@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
 	"github.com/rs/zerolog"
 )
 
@@ -165,7 +166,7 @@ func (b *Breaker) TryAcquirePermit() bool {
 			return b.TryAcquirePermit()
 		}
 		delay := b.cfg.HalfOpenAfter.Duration()
-		if delay <= 0 || time.Since(b.openedAt) >= delay {
+		if delay <= 0 || util.Since(b.openedAt) >= delay {
 			b.transitionLocked(StateHalfOpen, "half_open_delay_elapsed")
 			b.halfOpenInflight = 1
 			return true
@@ -224,14 +225,14 @@ func (b *Breaker) Record(o Outcome) {
 				b.resetWindowLocked()
 				b.transitionLocked(StateClosed, "half_open_success_threshold")
 			} else {
-				b.openedAt = time.Now()
+				b.openedAt = util.Now()
 				b.transitionLocked(StateOpen, "half_open_failure")
 			}
 			b.halfOpenSuccess = 0
 			b.halfOpenFailure = 0
 		} else if o == OutcomeFailure && b.halfOpenFailure > 0 {
 			// Single failure in HalfOpen immediately re-opens.
-			b.openedAt = time.Now()
+			b.openedAt = util.Now()
 			b.transitionLocked(StateOpen, "half_open_failure")
 			b.halfOpenSuccess = 0
 			b.halfOpenFailure = 0
@@ -293,7 +294,7 @@ func (b *Breaker) checkOpenLocked() {
 		return
 	}
 	if b.failures >= failCount {
-		b.openedAt = time.Now()
+		b.openedAt = util.Now()
 		b.resetWindowLocked()
 		b.transitionLocked(StateOpen, "failure_threshold")
 	}
@@ -341,6 +342,20 @@ func (b *Breaker) State() State {
 	return State(b.state.Load())
 }
 
+// OpenWindow returns when an open breaker opened and when it lets the next
+// trial through (half-open). ok is false unless the breaker is open.
+func (b *Breaker) OpenWindow() (since, until time.Time, ok bool) {
+	if b == nil || State(b.state.Load()) != StateOpen {
+		return time.Time{}, time.Time{}, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if State(b.state.Load()) != StateOpen {
+		return time.Time{}, time.Time{}, false
+	}
+	return b.openedAt, b.openedAt.Add(b.cfg.HalfOpenAfter.Duration()), true
+}
+
 // Metrics returns lifetime counts. Read-only.
 func (b *Breaker) Metrics() (failures, successes, executions uint64) {
 	if b == nil {
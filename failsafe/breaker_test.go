@@ -0,0 +1,43 @@
+package failsafe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_HalfOpenAfterDelay(t *testing.T) {
+	clock := util.NewVirtualClock(time.Now())
+	defer util.SetClock(clock)()
+
+	logger := zerolog.Nop()
+	b := NewBreaker(&common.CircuitBreakerPolicyConfig{
+		FailureThresholdCount:    2,
+		FailureThresholdCapacity: 2,
+		HalfOpenAfter:            common.Duration(30 * time.Second),
+		SuccessThresholdCount:    1,
+		SuccessThresholdCapacity: 1,
+	}, &logger)
+
+	b.Record(OutcomeFailure)
+	b.Record(OutcomeFailure)
+	assert.False(t, b.TryAcquirePermit())
+
+	clock.Advance(29 * time.Second)
+	assert.False(t, b.TryAcquirePermit())
+
+	clock.Advance(time.Second)
+	assert.True(t, b.TryAcquirePermit(), "the half-open delay has elapsed")
+	assert.False(t, b.TryAcquirePermit(), "only one trial permit is in flight")
+
+	b.Record(OutcomeFailure)
+	assert.False(t, b.TryAcquirePermit(), "a failed trial re-opens for another full delay")
+	clock.Advance(30 * time.Second)
+	assert.True(t, b.TryAcquirePermit())
+	b.Record(OutcomeSuccess)
+	assert.True(t, b.TryAcquirePermit())
+}
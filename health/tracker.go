@@ -8,6 +8,7 @@ import (
 
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 )
@@ -54,7 +55,10 @@ type metadataKey struct {
 type NetworkMetadata struct {
 	evmLatestBlockNumber    atomic.Int64
 	evmLatestBlockTimestamp atomic.Int64
-	evmFinalizedBlockNumber atomic.Int64
+	// evmLatestBlockTimestampBlock is the block evmLatestBlockTimestamp belongs
+	// to; the latest block number may advance without a timestamp.
+	evmLatestBlockTimestampBlock atomic.Int64
+	evmFinalizedBlockNumber      atomic.Int64
 
 	// Dynamic block time via EMA on on-chain block timestamps.
 	// Uses block.timestamp (integer seconds) normalized by block count gap.
@@ -82,7 +86,7 @@ type Timer struct {
 }
 
 func (t *Timer) ObserveDuration(isSuccess bool) {
-	duration := time.Since(t.start)
+	duration := util.Since(t.start)
 	t.tracker.RecordUpstreamDuration(t.upstream, t.method, duration, isSuccess, t.compositeType, t.finality, t.userId)
 }
 
@@ -131,6 +135,20 @@ type TrackedMetrics struct {
 	// look idle to the very next sweep tick) and refreshed on every
 	// hot-path write.
 	LastAccessedAtMs atomic.Int64 `json:"-"`
+
+	// peers holds other replicas' counts for the same upstream and method,
+	// set by cluster routing sync (see Tracker.SetPeerCounts). The rates
+	// below include them; the counters above stay local.
+	peers atomic.Pointer[PeerCounts]
+}
+
+// PeerCounts are the rolling-window counts other replicas observed for one
+// upstream and method.
+type PeerCounts struct {
+	Requests          int64 `json:"r"`
+	Errors            int64 `json:"e,omitempty"`
+	RemoteRateLimited int64 `json:"t,omitempty"`
+	Misbehaviors      int64 `json:"m,omitempty"`
 }
 
 // newTrackedMetrics constructs an empty TrackedMetrics with all
@@ -146,7 +164,7 @@ func newTrackedMetrics(logger *zerolog.Logger) *TrackedMetrics {
 	}
 	// Seed LastAccessedAtMs to "now" so a brand-new entry doesn't
 	// look idle to a sweep that fires before the first hot-path write.
-	tm.LastAccessedAtMs.Store(time.Now().UnixMilli())
+	tm.LastAccessedAtMs.Store(util.Now().UnixMilli())
 	return tm
 }
 
@@ -158,12 +176,26 @@ func (m *TrackedMetrics) touch(nowMs int64) {
 	m.LastAccessedAtMs.Store(nowMs)
 }
 
-func (m *TrackedMetrics) ErrorRate() float64 {
+// rate divides local plus peer events by local plus peer requests.
+func (m *TrackedMetrics) rate(local int64, peer func(*PeerCounts) int64) float64 {
 	reqs := m.RequestsTotal.Load()
+	if pc := m.peers.Load(); pc != nil {
+		reqs += pc.Requests
+		local += peer(pc)
+	}
 	if reqs == 0 {
 		return 0
 	}
-	return float64(m.ErrorsTotal.Load()) / float64(reqs)
+	return float64(local) / float64(reqs)
+}
+
+// Peers returns the other replicas' counts included in the rates, or nil.
+func (m *TrackedMetrics) Peers() *PeerCounts {
+	return m.peers.Load()
+}
+
+func (m *TrackedMetrics) ErrorRate() float64 {
+	return m.rate(m.ErrorsTotal.Load(), func(pc *PeerCounts) int64 { return pc.Errors })
 }
 
 func (m *TrackedMetrics) GetResponseQuantiles() common.QuantileTracker {
@@ -171,20 +203,11 @@ func (m *TrackedMetrics) GetResponseQuantiles() common.QuantileTracker {
 }
 
 func (m *TrackedMetrics) ThrottledRate() float64 {
-	reqs := m.RequestsTotal.Load()
-	if reqs == 0 {
-		return 0
-	}
-	throttled := float64(m.RemoteRateLimitedTotal.Load())
-	return throttled / float64(reqs)
+	return m.rate(m.RemoteRateLimitedTotal.Load(), func(pc *PeerCounts) int64 { return pc.RemoteRateLimited })
 }
 
 func (m *TrackedMetrics) MisbehaviorRate() float64 {
-	reqs := m.RequestsTotal.Load()
-	if reqs == 0 {
-		return 0
-	}
-	return float64(m.MisbehaviorsTotal.Load()) / float64(reqs)
+	return m.rate(m.MisbehaviorsTotal.Load(), func(pc *PeerCounts) int64 { return pc.Misbehaviors })
 }
 
 func (m *TrackedMetrics) MarshalJSON() ([]byte, error) {
@@ -201,6 +224,7 @@ func (m *TrackedMetrics) MarshalJSON() ([]byte, error) {
 		"errorRate":              m.ErrorRate(),
 		"throttledRate":          m.ThrottledRate(),
 		"misbehaviorRate":        m.MisbehaviorRate(),
+		"peers":                  m.peers.Load(),
 	})
 }
 
@@ -233,6 +257,7 @@ func (m *TrackedMetrics) Reset() {
 	m.ResponseQuantiles.Reset()
 	m.Cordoned.Store(false)
 	m.LastCordonedReason.Store("")
+	m.peers.Store(nil)
 }
 
 // ------------------------------------
@@ -341,7 +366,7 @@ func (t *Tracker) getUpstreamRequestDurationObserver(up common.Upstream, method,
 		finality:  finality.String(),
 		user:      userId,
 	}
-	nowMs := time.Now().UnixMilli()
+	nowMs := util.Now().UnixMilli()
 	if v, ok := t.urdObsCache.Load(key); ok {
 		co := v.(*cachedObserver)
 		co.lastAccessedAtMs.Store(nowMs)
@@ -371,7 +396,7 @@ type rrltKey struct {
 
 func (t *Tracker) getRemoteRateLimitedCounter(up common.Upstream, method, userId, agentName, finality string) prometheus.Counter {
 	key := rrltKey{t.projectId, up.VendorName(), up.NetworkLabel(), up.Id(), method, userId, agentName, finality}
-	nowMs := time.Now().UnixMilli()
+	nowMs := util.Now().UnixMilli()
 	if v, ok := t.remoteRateLimitedCounterCache.Load(key); ok {
 		cc := v.(*cachedCounter)
 		cc.lastAccessedAtMs.Store(nowMs)
@@ -527,7 +552,7 @@ func (t *Tracker) rotateMetricsLoop(ctx context.Context) {
 		// to a 1ms floor; the caller almost certainly meant "fast".
 		interval = time.Millisecond
 	}
-	ticker := time.NewTicker(interval)
+	ticker := util.NewTicker(interval)
 	defer ticker.Stop()
 	// Tick counter so we can schedule the idle sweep at a coarser
 	// cadence than rotation (every Nth tick) — sweep work is O(map size)
@@ -538,7 +563,7 @@ func (t *Tracker) rotateMetricsLoop(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			t.upsMetrics.Range(func(key, value any) bool {
 				if tm, ok := value.(*TrackedMetrics); ok {
 					tm.Rotate()
@@ -596,7 +621,7 @@ const sweepEveryRotations = uint64(rollingBuckets)
 // this, even with the in-memory cache evicted, the Prometheus
 // registry would keep the series forever (append-only model).
 func (t *Tracker) sweepIdle() {
-	cutoffMs := time.Now().Add(-t.idleEvictionAfter).UnixMilli()
+	cutoffMs := util.Now().Add(-t.idleEvictionAfter).UnixMilli()
 
 	t.upsMetrics.Range(func(key, value any) bool {
 		k := key.(upstreamKey)
@@ -808,7 +833,7 @@ func (t *Tracker) Cordon(upstream common.Upstream, method, reason string) {
 		// Only record the start timestamp on the OFF→ON transition so
 		// repeated cordons (e.g. operator updating the reason) don't
 		// reset the duration accounting mid-cordon.
-		tm.CordonedAtMs.Store(time.Now().UnixMilli())
+		tm.CordonedAtMs.Store(util.Now().UnixMilli())
 		telemetry.MetricUpstreamCordonEventTotal.WithLabelValues(
 			t.projectId, upstream.NetworkId(), upstream.Id(), "cordon",
 		).Inc()
@@ -833,7 +858,7 @@ func (t *Tracker) Uncordon(upstream common.Upstream, method string, reason strin
 	if wasCordoned {
 		startedMs := tm.CordonedAtMs.Swap(0)
 		if startedMs > 0 {
-			dur := time.Duration(time.Now().UnixMilli()-startedMs) * time.Millisecond
+			dur := time.Duration(util.Now().UnixMilli()-startedMs) * time.Millisecond
 			if dur > 0 {
 				telemetry.MetricUpstreamCordonDurationSeconds.WithLabelValues(
 					t.projectId, upstream.NetworkId(), upstream.Id(),
@@ -894,7 +919,7 @@ func (t *Tracker) CordonedReason(upstream common.Upstream, method string) (strin
 // ------------------------------------
 
 func (t *Tracker) RecordUpstreamRequest(up common.Upstream, method string, finality common.DataFinalityState) {
-	nowMs := time.Now().UnixMilli()
+	nowMs := util.Now().UnixMilli()
 	for _, k := range t.getUpsKeys(up, method, finality) {
 		tm := t.getUpsMetrics(k)
 		tm.RequestsTotal.Add(1)
@@ -912,7 +937,7 @@ func (t *Tracker) RecordUpstreamDurationStart(upstream common.Upstream, method s
 		compositeType = "none"
 	}
 	return &Timer{
-		start:         time.Now(),
+		start:         util.Now(),
 		upstream:      upstream,
 		method:        method,
 		compositeType: compositeType,
@@ -937,7 +962,7 @@ func (t *Tracker) RecordUpstreamDuration(up common.Upstream, method string, d ti
 		// Hard upstream errors (connection refused, server 5xx,
 		// throttling) stay out so an upstream that's failing fast
 		// doesn't get crowned "fastest in the pool".
-		nowMs := time.Now().UnixMilli()
+		nowMs := util.Now().UnixMilli()
 		for _, k := range t.getUpsKeys(up, method, finality) {
 			tm := t.getUpsMetrics(k)
 			tm.ResponseQuantiles.Add(sec)
@@ -982,7 +1007,7 @@ func (t *Tracker) RecordUpstreamFailure(up common.Upstream, method string, final
 		return
 	}
 
-	nowMs := time.Now().UnixMilli()
+	nowMs := util.Now().UnixMilli()
 	for _, k := range t.getUpsKeys(up, method, finality) {
 		tm := t.getUpsMetrics(k)
 		tm.ErrorsTotal.Add(1)
@@ -996,7 +1021,7 @@ func (t *Tracker) RecordUpstreamFailure(up common.Upstream, method string, final
 }
 
 func (t *Tracker) RecordUpstreamMisbehavior(up common.Upstream, method string, finality common.DataFinalityState) {
-	nowMs := time.Now().UnixMilli()
+	nowMs := util.Now().UnixMilli()
 	for _, k := range t.getUpsKeys(up, method, finality) {
 		tm := t.getUpsMetrics(k)
 		tm.MisbehaviorsTotal.Add(1)
@@ -1023,7 +1048,7 @@ func (t *Tracker) RecordUpstreamRemoteRateLimited(ctx context.Context, up common
 		finality = common.DataFinalityStateAll
 	}
 
-	nowMs := time.Now().UnixMilli()
+	nowMs := util.Now().UnixMilli()
 	for _, k := range t.getUpsKeys(up, method, finality) {
 		tm := t.getUpsMetrics(k)
 		tm.RemoteRateLimitedTotal.Add(1)
@@ -1055,7 +1080,7 @@ func (t *Tracker) RecordUpstreamRemoteRateLimited(ctx context.Context, up common
 // going to the wildcard slot could see specific-bucket entries
 // evicted out from under the read path.
 func (t *Tracker) GetUpstreamMethodMetrics(up common.Upstream, method string, finality common.DataFinalityState) *TrackedMetrics {
-	nowMs := time.Now().UnixMilli()
+	nowMs := util.Now().UnixMilli()
 	if finality == common.DataFinalityStateAll || !t.trackByFinality.Load() {
 		tm := t.getUpsMetrics(upstreamKey{up, method, common.DataFinalityStateAll})
 		tm.touch(nowMs)
@@ -1137,6 +1162,21 @@ func (t *Tracker) GetUpstreamMetrics(ups common.Upstream) map[string]*TrackedMet
 	return out
 }
 
+// SetPeerCounts replaces the other replicas' counts included in the rates
+// of (ups, method); nil removes them. They are kept on the all-finalities
+// bucket only, like the counts GetUpstreamMetrics returns.
+func (t *Tracker) SetPeerCounts(ups common.Upstream, method string, pc *PeerCounts) {
+	if pc == nil {
+		if v, ok := t.upsMetrics.Load(upstreamKey{ups, method, common.DataFinalityStateAll}); ok {
+			v.(*TrackedMetrics).peers.Store(nil)
+		}
+		return
+	}
+	tm := t.getUpsMetrics(upstreamKey{ups, method, common.DataFinalityStateAll})
+	tm.touch(util.Now().UnixMilli())
+	tm.peers.Store(pc)
+}
+
 func (t *Tracker) GetNetworkMethodMetrics(network, method string) *TrackedMetrics {
 	return t.getNtwMetrics(networkKey{network, method})
 }
@@ -1349,9 +1389,12 @@ func (t *Tracker) SetLatestBlockNumber(upstream common.Upstream, blockNumber int
 
 		// Atomically update timestamp when network-level block number is updated
 		if blockTimestamp > 0 {
+			// Timestamp first: a concurrent reader that sees the new block
+			// then also sees its timestamp (or a newer one), never an older one.
 			ntwMeta.evmLatestBlockTimestamp.Store(blockTimestamp)
+			ntwMeta.evmLatestBlockTimestampBlock.Store(blockNumber)
 
-			detectedAtMs := time.Now().UnixMilli()
+			detectedAtMs := util.Now().UnixMilli()
 			distanceMs := detectedAtMs - blockTimestamp*1000
 			telemetry.MetricNetworkLatestBlockTimestampDistance.WithLabelValues(
 				t.projectId,
@@ -1547,6 +1590,15 @@ func (t *Tracker) GetNetworkBlockTime(networkId string) time.Duration {
 	return 0
 }
 
+// GetNetworkLatestBlockTimestamp returns the highest block of a network whose
+// timestamp is known, with that timestamp in unix seconds. Both are 0 until a
+// state poller fetched a block that carried a timestamp.
+func (t *Tracker) GetNetworkLatestBlockTimestamp(networkId string) (blockNumber int64, unixSeconds int64) {
+	ntwMeta := t.getMetadata(metadataKey{nil, networkId})
+	blockNumber = ntwMeta.evmLatestBlockTimestampBlock.Load()
+	return blockNumber, ntwMeta.evmLatestBlockTimestamp.Load()
+}
+
 func (t *Tracker) SetFinalizedBlockNumber(upstream common.Upstream, blockNumber int64) {
 	lg := upstream.Logger().With().Str("networkId", upstream.NetworkId()).Logger()
 
@@ -525,6 +525,21 @@ func TestSetLatestBlockTimestampForNetwork(t *testing.T) {
 		assert.Equal(t, int64(600), ntwMeta.evmLatestBlockNumber.Load(), "Block number should still be updated")
 	})
 
+	t.Run("PairsTimestampWithItsBlock", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, "test-project", 5*time.Minute)
+		tracker.Bootstrap(context.Background())
+
+		ups := common.NewFakeUpstream("test-upstream-5")
+		now := time.Now().Unix()
+
+		tracker.SetLatestBlockNumber(ups, 1000, now-10)
+		tracker.SetLatestBlockNumber(ups, 1001, 0)
+
+		block, ts := tracker.GetNetworkLatestBlockTimestamp("evm:123")
+		assert.Equal(t, int64(1000), block, "a head without timestamp must not move the timestamped block")
+		assert.Equal(t, now-10, ts)
+	})
+
 	t.Run("CalculatesCorrectDistance", func(t *testing.T) {
 		tracker := NewTracker(&log.Logger, "test-project", 5*time.Minute)
 		tracker.Bootstrap(context.Background())
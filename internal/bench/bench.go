@@ -0,0 +1,596 @@
+// Package bench drives JSON-RPC load against a running eRPC instance and
+// summarizes what came back. It backs the `erpc bench` command.
+//
+// Requests are either synthetic (a weighted method mix whose params are
+// built around the current head block) or replayed from a recorded
+// workload file. Cache and upstream attribution come from the response
+// headers eRPC already sets (X-ERPC-Cache, X-ERPC-Upstream), so nothing
+// has to be enabled on the server side.
+package bench
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMix is used when neither a method mix nor a workload is given.
+const DefaultMix = "eth_blockNumber=4,eth_getBlockByNumber=2,eth_getBalance=2,eth_getLogs=1,eth_call=1"
+
+// headWindow is how far behind the head synthetic block numbers may land,
+// so a run mixes realtime and (cacheable) finalized blocks.
+const headWindow = 128
+
+// MethodWeight is one entry of a synthetic method mix.
+type MethodWeight struct {
+	Method string
+	Weight int
+}
+
+// Options configures a bench run.
+type Options struct {
+	// Target is the eRPC URL requests are POSTed to, e.g.
+	// http://localhost:4000/main/evm/1.
+	Target string
+	// Mix is the weighted method mix for synthetic requests. Ignored when
+	// Workload is set.
+	Mix []MethodWeight
+	// Workload holds recorded JSON-RPC request bodies, replayed round-robin.
+	Workload [][]byte
+	// Concurrency is the number of workers sending requests. Defaults to 1.
+	Concurrency int
+	// Duration bounds the run. Zero means no time limit, in which case
+	// MaxRequests must be set.
+	Duration time.Duration
+	// MaxRequests bounds the number of requests sent. Zero means no limit.
+	MaxRequests int
+	// Timeout is the per-request timeout. Defaults to 30s.
+	Timeout time.Duration
+	// Headers are added to every request (e.g. authentication).
+	Headers map[string]string
+	// Seed makes synthetic params reproducible. Zero picks a random seed.
+	Seed int64
+	// Client overrides the HTTP client, mainly for tests.
+	Client *http.Client
+}
+
+// Latency summarizes a set of request durations, in milliseconds.
+type Latency struct {
+	P50  float64 `json:"p50Ms"`
+	P90  float64 `json:"p90Ms"`
+	P99  float64 `json:"p99Ms"`
+	Max  float64 `json:"maxMs"`
+	Mean float64 `json:"meanMs"`
+}
+
+// MethodReport holds the results for one method.
+type MethodReport struct {
+	Requests int     `json:"requests"`
+	Errors   int     `json:"errors"`
+	Latency  Latency `json:"latency"`
+
+	durations []time.Duration
+}
+
+// Report is the outcome of a bench run.
+type Report struct {
+	Target       string                   `json:"target"`
+	Concurrency  int                      `json:"concurrency"`
+	Elapsed      time.Duration            `json:"-"`
+	ElapsedSecs  float64                  `json:"elapsedSeconds"`
+	Requests     int                      `json:"requests"`
+	Errors       int                      `json:"errors"`
+	Throughput   float64                  `json:"requestsPerSecond"`
+	Latency      Latency                  `json:"latency"`
+	CacheHits    int                      `json:"cacheHits"`
+	CacheMisses  int                      `json:"cacheMisses"`
+	CacheHitRate float64                  `json:"cacheHitRate"`
+	Upstreams    map[string]int           `json:"upstreams"`
+	Methods      map[string]*MethodReport `json:"methods"`
+	ErrorSamples map[string]int           `json:"errorSamples,omitempty"`
+}
+
+// result is what a worker records for a single request.
+type result struct {
+	method   string
+	duration time.Duration
+	err      string
+	cache    string
+	upstream string
+}
+
+// ParseMix parses a method mix such as "eth_blockNumber=5,eth_getBalance=2".
+// A method without a weight counts as weight 1.
+func ParseMix(s string) ([]MethodWeight, error) {
+	var mix []MethodWeight
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		method, weight, hasWeight := strings.Cut(part, "=")
+		mw := MethodWeight{Method: strings.TrimSpace(method), Weight: 1}
+		if mw.Method == "" {
+			return nil, fmt.Errorf("empty method name in mix entry %q", part)
+		}
+		if hasWeight {
+			w, err := strconv.Atoi(strings.TrimSpace(weight))
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("invalid weight in mix entry %q: must be a positive integer", part)
+			}
+			mw.Weight = w
+		}
+		mix = append(mix, mw)
+	}
+	if len(mix) == 0 {
+		return nil, errors.New("method mix is empty")
+	}
+	return mix, nil
+}
+
+// LoadWorkload reads a recorded workload: one JSON-RPC request (or batch)
+// per line. Blank lines and lines starting with '#' are skipped.
+func LoadWorkload(r io.Reader) ([][]byte, error) {
+	var out [][]byte
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		b := bytes.TrimSpace(sc.Bytes())
+		if len(b) == 0 || b[0] == '#' {
+			continue
+		}
+		if !json.Valid(b) {
+			return nil, fmt.Errorf("workload line %d is not valid JSON", line)
+		}
+		out = append(out, append([]byte(nil), b...))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, errors.New("workload has no requests")
+	}
+	return out, nil
+}
+
+// Run sends requests to opts.Target until the duration elapses, the
+// request budget is spent or ctx is cancelled, then returns the report.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Target == "" {
+		return nil, errors.New("target is required")
+	}
+	if opts.Duration <= 0 && opts.MaxRequests <= 0 {
+		return nil, errors.New("either a duration or a request count is required")
+	}
+	if len(opts.Workload) == 0 && len(opts.Mix) == 0 {
+		mix, err := ParseMix(DefaultMix)
+		if err != nil {
+			return nil, err
+		}
+		opts.Mix = mix
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{
+			Transport: &http.Transport{
+				MaxIdleConns:        opts.Concurrency * 2,
+				MaxIdleConnsPerHost: opts.Concurrency * 2,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		}
+	}
+
+	b := &bencher{opts: opts, client: client}
+	if len(opts.Workload) == 0 {
+		head, err := b.fetchHead(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch head block from target: %w", err)
+		}
+		b.head = head
+	}
+
+	runCtx := ctx
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	results := make(chan result, opts.Concurrency*4)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			b.work(runCtx, rand.New(rand.NewSource(opts.Seed+int64(worker))), results)
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	rep := newReport(opts)
+	for r := range results {
+		rep.add(r)
+	}
+	rep.finish(time.Since(start))
+	return rep, nil
+}
+
+type bencher struct {
+	opts   Options
+	client *http.Client
+	head   int64
+	sent   atomic.Int64
+}
+
+func (b *bencher) work(ctx context.Context, rng *rand.Rand, results chan<- result) {
+	for ctx.Err() == nil {
+		n := b.sent.Add(1)
+		if b.opts.MaxRequests > 0 && n > int64(b.opts.MaxRequests) {
+			return
+		}
+		var method string
+		var body []byte
+		if len(b.opts.Workload) > 0 {
+			body = b.opts.Workload[(n-1)%int64(len(b.opts.Workload))]
+			method = methodOf(body)
+		} else {
+			method = pickMethod(rng, b.opts.Mix)
+			body = buildRequest(n, method, syntheticParams(rng, method, b.head))
+		}
+		r := b.send(ctx, body)
+		// A request cut short by the end of the run says nothing about
+		// the target, so it is not reported.
+		if ctx.Err() != nil && r.err != "" {
+			return
+		}
+		r.method = method
+		results <- r
+	}
+}
+
+func (b *bencher) send(ctx context.Context, body []byte) result {
+	reqCtx, cancel := context.WithTimeout(ctx, b.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, b.opts.Target, bytes.NewReader(body))
+	if err != nil {
+		return result{err: "request: " + err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range b.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return result{duration: time.Since(start), err: "transport: " + errorKind(err)}
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	r := result{
+		duration: time.Since(start),
+		cache:    resp.Header.Get("X-ERPC-Cache"),
+		upstream: resp.Header.Get("X-ERPC-Upstream"),
+	}
+	switch {
+	case err != nil:
+		r.err = "transport: " + errorKind(err)
+	case resp.StatusCode >= 400:
+		r.err = "http " + strconv.Itoa(resp.StatusCode)
+	default:
+		if code, ok := jsonRpcErrorCode(respBody); ok {
+			r.err = "rpc " + strconv.Itoa(code)
+		}
+	}
+	return r
+}
+
+func (b *bencher) fetchHead(ctx context.Context) (int64, error) {
+	body := buildRequest(0, "eth_blockNumber", []interface{}{})
+	reqCtx, cancel := context.WithTimeout(ctx, b.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, b.opts.Target, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range b.opts.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Result string          `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return 0, fmt.Errorf("unexpected response (http %d): %w", resp.StatusCode, err)
+	}
+	if len(out.Error) > 0 && string(out.Error) != "null" {
+		return 0, fmt.Errorf("eth_blockNumber failed: %s", out.Error)
+	}
+	head, err := strconv.ParseInt(strings.TrimPrefix(out.Result, "0x"), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid eth_blockNumber result %q", out.Result)
+	}
+	return head, nil
+}
+
+func pickMethod(rng *rand.Rand, mix []MethodWeight) string {
+	total := 0
+	for _, mw := range mix {
+		total += mw.Weight
+	}
+	n := rng.Intn(total)
+	for _, mw := range mix {
+		if n < mw.Weight {
+			return mw.Method
+		}
+		n -= mw.Weight
+	}
+	return mix[len(mix)-1].Method
+}
+
+// syntheticParams builds plausible params for common read methods. Block
+// numbers are picked within headWindow blocks of the head and addresses
+// from a small pool, so repeated runs exercise the cache the way real
+// traffic would. Unknown methods are sent without params.
+func syntheticParams(rng *rand.Rand, method string, head int64) []interface{} {
+	block := head
+	if head > headWindow {
+		block = head - rng.Int63n(headWindow)
+	}
+	blockHex := "0x" + strconv.FormatInt(block, 16)
+	addr := fmt.Sprintf("0x%040x", rng.Intn(16)+1)
+
+	switch method {
+	case "eth_getBlockByNumber":
+		return []interface{}{blockHex, false}
+	case "eth_getBlockReceipts", "eth_getBlockTransactionCountByNumber":
+		return []interface{}{blockHex}
+	case "eth_getBalance", "eth_getTransactionCount", "eth_getCode":
+		return []interface{}{addr, blockHex}
+	case "eth_getStorageAt":
+		return []interface{}{addr, "0x0", blockHex}
+	case "eth_call":
+		return []interface{}{map[string]string{"to": addr, "data": "0x"}, blockHex}
+	case "eth_getLogs":
+		return []interface{}{map[string]string{"fromBlock": blockHex, "toBlock": blockHex}}
+	default:
+		return []interface{}{}
+	}
+}
+
+func buildRequest(id int64, method string, params []interface{}) []byte {
+	b, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	return b
+}
+
+// methodOf labels a recorded request by its method; batches are labelled
+// "batch".
+func methodOf(body []byte) string {
+	if len(body) > 0 && body[0] == '[' {
+		return "batch"
+	}
+	var req struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.Method == "" {
+		return "unknown"
+	}
+	return req.Method
+}
+
+// jsonRpcErrorCode returns the code of the JSON-RPC error in body, if any.
+// For a batch the first failed item counts.
+func jsonRpcErrorCode(body []byte) (int, bool) {
+	type item struct {
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var items []item
+		if err := json.Unmarshal(body, &items); err != nil {
+			return 0, false
+		}
+		for _, it := range items {
+			if it.Error != nil {
+				return it.Error.Code, true
+			}
+		}
+		return 0, false
+	}
+	var it item
+	if err := json.Unmarshal(body, &it); err != nil || it.Error == nil {
+		return 0, false
+	}
+	return it.Error.Code, true
+}
+
+func errorKind(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	return err.Error()
+}
+
+func newReport(opts Options) *Report {
+	return &Report{
+		Target:       opts.Target,
+		Concurrency:  opts.Concurrency,
+		Upstreams:    map[string]int{},
+		Methods:      map[string]*MethodReport{},
+		ErrorSamples: map[string]int{},
+	}
+}
+
+func (rep *Report) add(r result) {
+	m, ok := rep.Methods[r.method]
+	if !ok {
+		m = &MethodReport{}
+		rep.Methods[r.method] = m
+	}
+	m.Requests++
+	m.durations = append(m.durations, r.duration)
+	rep.Requests++
+	if r.err != "" {
+		m.Errors++
+		rep.Errors++
+		rep.ErrorSamples[r.err]++
+	}
+	switch r.cache {
+	case "HIT":
+		rep.CacheHits++
+	case "MISS":
+		rep.CacheMisses++
+	}
+	if r.upstream != "" {
+		rep.Upstreams[r.upstream]++
+	}
+}
+
+func (rep *Report) finish(elapsed time.Duration) {
+	rep.Elapsed = elapsed
+	rep.ElapsedSecs = elapsed.Seconds()
+	if elapsed > 0 {
+		rep.Throughput = float64(rep.Requests) / elapsed.Seconds()
+	}
+	if n := rep.CacheHits + rep.CacheMisses; n > 0 {
+		rep.CacheHitRate = float64(rep.CacheHits) / float64(n)
+	}
+	var all []time.Duration
+	for _, m := range rep.Methods {
+		m.Latency = summarize(m.durations)
+		all = append(all, m.durations...)
+	}
+	rep.Latency = summarize(all)
+}
+
+func summarize(ds []time.Duration) Latency {
+	if len(ds) == 0 {
+		return Latency{}
+	}
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	return Latency{
+		P50:  ms(percentile(sorted, 0.50)),
+		P90:  ms(percentile(sorted, 0.90)),
+		P99:  ms(percentile(sorted, 0.99)),
+		Max:  ms(sorted[len(sorted)-1]),
+		Mean: ms(sum / time.Duration(len(sorted))),
+	}
+}
+
+// percentile uses the nearest-rank method on an ascending slice.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	idx := int(q*float64(len(sorted))+0.999999) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// WriteText renders the report for humans.
+func (rep *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "target:       %s\n", rep.Target)
+	fmt.Fprintf(w, "concurrency:  %d\n", rep.Concurrency)
+	fmt.Fprintf(w, "elapsed:      %s\n", rep.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "requests:     %d (%.1f req/s)\n", rep.Requests, rep.Throughput)
+	fmt.Fprintf(w, "errors:       %d (%s)\n", rep.Errors, pct(rep.Errors, rep.Requests))
+	fmt.Fprintf(w, "latency (ms): p50=%.2f p90=%.2f p99=%.2f max=%.2f mean=%.2f\n",
+		rep.Latency.P50, rep.Latency.P90, rep.Latency.P99, rep.Latency.Max, rep.Latency.Mean)
+	if n := rep.CacheHits + rep.CacheMisses; n > 0 {
+		fmt.Fprintf(w, "cache:        %d hits / %d misses (%s hit rate)\n", rep.CacheHits, rep.CacheMisses, pct(rep.CacheHits, n))
+	} else {
+		fmt.Fprintf(w, "cache:        no X-ERPC-Cache headers seen\n")
+	}
+
+	fmt.Fprintf(w, "\n%-40s %9s %7s %9s %9s %9s %9s\n", "method", "requests", "errors", "p50", "p90", "p99", "max")
+	for _, name := range sortedKeys(rep.Methods) {
+		m := rep.Methods[name]
+		fmt.Fprintf(w, "%-40s %9d %7d %9.2f %9.2f %9.2f %9.2f\n",
+			name, m.Requests, m.Errors, m.Latency.P50, m.Latency.P90, m.Latency.P99, m.Latency.Max)
+	}
+
+	if len(rep.Upstreams) > 0 {
+		fmt.Fprintf(w, "\n%-40s %9s %7s\n", "upstream", "requests", "share")
+		total := 0
+		for _, n := range rep.Upstreams {
+			total += n
+		}
+		for _, name := range sortedKeys(rep.Upstreams) {
+			fmt.Fprintf(w, "%-40s %9d %7s\n", name, rep.Upstreams[name], pct(rep.Upstreams[name], total))
+		}
+	}
+
+	if len(rep.ErrorSamples) > 0 {
+		fmt.Fprintf(w, "\n%-40s %9s\n", "error", "count")
+		for _, name := range sortedKeys(rep.ErrorSamples) {
+			fmt.Fprintf(w, "%-40s %9d\n", name, rep.ErrorSamples[name])
+		}
+	}
+}
+
+func pct(n, total int) string {
+	if total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,157 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMix(t *testing.T) {
+	mix, err := ParseMix("eth_blockNumber=5, eth_getBalance=2,eth_chainId")
+	require.NoError(t, err)
+	assert.Equal(t, []MethodWeight{
+		{Method: "eth_blockNumber", Weight: 5},
+		{Method: "eth_getBalance", Weight: 2},
+		{Method: "eth_chainId", Weight: 1},
+	}, mix)
+
+	_, err = ParseMix("eth_blockNumber=0")
+	assert.Error(t, err)
+	_, err = ParseMix("=3")
+	assert.Error(t, err)
+	_, err = ParseMix(" , ")
+	assert.Error(t, err)
+}
+
+func TestLoadWorkload(t *testing.T) {
+	wl, err := LoadWorkload(strings.NewReader(`
+# recorded from prod
+{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}
+
+[{"jsonrpc":"2.0","id":2,"method":"eth_blockNumber","params":[]}]
+`))
+	require.NoError(t, err)
+	require.Len(t, wl, 2)
+	assert.Equal(t, "eth_chainId", methodOf(wl[0]))
+	assert.Equal(t, "batch", methodOf(wl[1]))
+
+	_, err = LoadWorkload(strings.NewReader("{not json}\n"))
+	assert.Error(t, err)
+	_, err = LoadWorkload(strings.NewReader("\n# nothing\n"))
+	assert.Error(t, err)
+}
+
+func TestRun_Synthetic(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		_ = json.Unmarshal(body, &req)
+		mu.Lock()
+		seen[req.Method]++
+		mu.Unlock()
+		assert.Equal(t, "secret", r.Header.Get("X-ERPC-Secret-Token"))
+
+		switch req.Method {
+		case "eth_blockNumber":
+			w.Header().Set("X-ERPC-Cache", "MISS")
+			w.Header().Set("X-ERPC-Upstream", "alchemy")
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1000"}`))
+		case "eth_getBlockByNumber":
+			assert.Len(t, req.Params, 2)
+			w.Header().Set("X-ERPC-Cache", "HIT")
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+		default:
+			w.Header().Set("X-ERPC-Upstream", "infura")
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	rep, err := Run(context.Background(), Options{
+		Target:      srv.URL,
+		Mix:         []MethodWeight{{"eth_blockNumber", 1}, {"eth_getBlockByNumber", 1}, {"eth_foo", 1}},
+		Concurrency: 4,
+		MaxRequests: 90,
+		Headers:     map[string]string{"X-ERPC-Secret-Token": "secret"},
+		Seed:        42,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 90, rep.Requests)
+	total := 0
+	for _, m := range rep.Methods {
+		total += m.Requests
+	}
+	assert.Equal(t, 90, total)
+	require.Contains(t, rep.Methods, "eth_foo")
+	assert.Equal(t, rep.Methods["eth_foo"].Requests, rep.Methods["eth_foo"].Errors)
+	assert.Equal(t, rep.Methods["eth_foo"].Errors, rep.Errors)
+	assert.Equal(t, rep.Errors, rep.ErrorSamples["rpc -32601"])
+	assert.Equal(t, rep.Methods["eth_getBlockByNumber"].Requests, rep.CacheHits)
+	assert.Equal(t, rep.Methods["eth_blockNumber"].Requests, rep.CacheMisses)
+	assert.Equal(t, rep.Methods["eth_blockNumber"].Requests, rep.Upstreams["alchemy"])
+	assert.Equal(t, rep.Methods["eth_foo"].Requests, rep.Upstreams["infura"])
+	assert.LessOrEqual(t, rep.Latency.P50, rep.Latency.P99)
+	assert.LessOrEqual(t, rep.Latency.P99, rep.Latency.Max)
+
+	// The head lookup is not part of the report.
+	assert.Equal(t, rep.Methods["eth_blockNumber"].Requests+1, seen["eth_blockNumber"])
+
+	var sb strings.Builder
+	rep.WriteText(&sb)
+	assert.Contains(t, sb.String(), "eth_getBlockByNumber")
+	assert.Contains(t, sb.String(), "hit rate")
+}
+
+func TestRun_WorkloadAndDuration(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "down") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":"0x1"}]`))
+	}))
+	defer srv.Close()
+
+	wl, err := LoadWorkload(strings.NewReader(`[{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}]`))
+	require.NoError(t, err)
+
+	rep, err := Run(context.Background(), Options{
+		Target:      srv.URL,
+		Workload:    wl,
+		Concurrency: 2,
+		Duration:    200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.Positive(t, rep.Requests)
+	assert.Zero(t, rep.Errors)
+	assert.Equal(t, rep.Requests, rep.Methods["batch"].Requests)
+
+	rep, err = Run(context.Background(), Options{
+		Target:      srv.URL + "/down",
+		Workload:    wl,
+		MaxRequests: 3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, rep.Errors)
+	assert.Equal(t, 3, rep.ErrorSamples["http 503"])
+}
+
+func TestRun_RequiresBound(t *testing.T) {
+	_, err := Run(context.Background(), Options{Target: "http://localhost:1"})
+	assert.Error(t, err)
+}
@@ -249,6 +249,12 @@ func (p *Prober) tryReserveProbe(u common.Upstream, cfg *ProbeConfig) bool {
 		return false
 	}
 
+	// Retired upstreams get no traffic at all, mirrored or not.
+	if r, ok := u.(interface{ Retired() bool }); ok && r.Retired() {
+		telemetry.MetricSelectionProbeSkipped.WithLabelValues(p.networkID, "retired").Inc()
+		return false
+	}
+
 	// Per-upstream opt-out via routing.probe: off.
 	if cu := u.Config(); cu != nil && cu.Routing != nil && cu.Routing.Probe == common.ProbeModeOff {
 		telemetry.MetricSelectionProbeSkipped.WithLabelValues(p.networkID, "opt_out").Inc()
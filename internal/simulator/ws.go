@@ -1,12 +1,16 @@
 package simulator
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/bytedance/sonic"
+	"github.com/bytedance/sonic/ast"
 	"github.com/coder/websocket"
 	"github.com/coder/websocket/wsjson"
 	"github.com/erpc/erpc/internal/policy"
@@ -122,23 +126,59 @@ func (s *Session) subscribeTraces() (<-chan TraceEvent, func()) {
 }
 
 // reader parses inbound frames and dispatches them to the orchestrator.
+//
+// One read buffer is reused for the whole session, so a busy connection
+// does not allocate a fresh slice per frame. dispatch must therefore not
+// retain data past its return: payloads are decoded with encoding/json,
+// which copies strings and RawMessage out of the buffer.
 func (s *Session) reader(ctx context.Context) {
+	var buf bytes.Buffer
 	for {
-		_, data, err := s.conn.Read(ctx)
+		_, r, err := s.conn.Reader(ctx)
 		if err != nil {
 			return
 		}
-		var msg struct {
-			Kind string          `json:"kind"`
-			Raw  json.RawMessage `json:"-"`
+		buf.Reset()
+		if _, err := buf.ReadFrom(r); err != nil {
+			return
 		}
-		// Decode just the kind so we know what shape to expect next.
-		if err := json.Unmarshal(data, &msg); err != nil {
+		data := buf.Bytes()
+		kind, err := frameKind(data)
+		if err != nil {
 			s.send("error", map[string]string{"msg": "bad frame: " + err.Error()})
 			continue
 		}
-		s.dispatch(ctx, msg.Kind, data)
+		s.dispatch(ctx, kind, data)
+	}
+}
+
+// frameKind extracts only the top-level "kind" field, so frames are routed
+// without unmarshalling their (possibly large) payload twice. A frame
+// without a kind, or with a null one, has kind "" and goes to dispatch like
+// any other. Frames the fast path cannot handle (malformed JSON, a frame
+// that is not an object, a kind that is not a string) are decoded with
+// encoding/json, so they are accepted or rejected exactly as before.
+func frameKind(data []byte) (string, error) {
+	if sonic.Valid(data) {
+		node, err := sonic.Get(data, "kind")
+		switch {
+		case errors.Is(err, ast.ErrNotExist):
+			return "", nil
+		case err == nil && node.TypeSafe() == ast.V_NULL:
+			return "", nil
+		case err == nil:
+			if kind, err := node.StrictString(); err == nil {
+				return kind, nil
+			}
+		}
+	}
+	var msg struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return "", err
 	}
+	return msg.Kind, nil
 }
 
 func (s *Session) dispatch(ctx context.Context, kind string, data []byte) {
@@ -0,0 +1,39 @@
+package simulator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestFrameKind verifies that routing frames on the pre-extracted kind
+// accepts and rejects the same frames as decoding them with encoding/json,
+// including frames without a kind.
+func TestFrameKind(t *testing.T) {
+	frames := []string{
+		`{"kind":"hello"}`,
+		`{"req":{"id":1,"method":"eth_chainId"},"kind":"send-one"}`,
+		`{"id":"up1","patch":{}}`,
+		`{}`,
+		`{"kind":null}`,
+		`{"kind":5}`,
+		`{"kind":"hello",`,
+		`[1]`,
+		`null`,
+		`not json`,
+	}
+	for _, frame := range frames {
+		var want struct {
+			Kind string `json:"kind"`
+		}
+		wantErr := json.Unmarshal([]byte(frame), &want)
+
+		kind, err := frameKind([]byte(frame))
+		if (err != nil) != (wantErr != nil) {
+			t.Errorf("frameKind(%s) error = %v, encoding/json error = %v", frame, err, wantErr)
+			continue
+		}
+		if kind != want.Kind {
+			t.Errorf("frameKind(%s) = %q, want %q", frame, kind, want.Kind)
+		}
+	}
+}
@@ -64,6 +64,12 @@ var (
 		Help:      "Latest block number of upstreams.",
 	}, []string{"project", "vendor", "network", "upstream"})
 
+	MetricUpstreamClientInfo = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_client_info",
+		Help:      "Node client and version of upstreams as reported by web3_clientVersion (always 1).",
+	}, []string{"project", "vendor", "network", "upstream", "client", "version"})
+
 	MetricUpstreamFinalizedBlockNumber = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "erpc",
 		Name:      "upstream_finalized_block_number",
@@ -106,7 +112,13 @@ var (
 		Help:      "Unix timestamp (seconds) of the finalized block available in a read-through cache connector, per network.",
 	}, []string{"connector", "network"})
 
-	MetricNetworkLatestBlockTimestampDistance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	MetricDynamoDBDaxFallbackTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "dynamodb_dax_fallback_total",
+		Help:      "Total number of DynamoDB connector calls sent directly to DynamoDB because the DAX cluster did not answer.",
+	}, []string{"connector", "operation"})
+
+	MetricNetworkLatestBlockTimestampDistance =promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "erpc",
 		Name:      "network_latest_block_timestamp_distance_seconds",
 		Help:      "Distance in seconds between the latest block timestamp and current time for a network.",
@@ -274,7 +286,7 @@ var (
 	MetricSelectionProbeSkipped = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "selection_probe_skipped_total",
-		Help:      "Probe candidates that were skipped before firing. reason ∈ {write_method, opt_out, sampled_out, max_concurrent, no_method}. `write_method` and `opt_out` are safety/policy gates; `sampled_out` and `max_concurrent` are throughput controls.",
+		Help:      "Probe candidates that were skipped before firing. reason ∈ {write_method, opt_out, retired, sampled_out, max_concurrent, no_method}. `write_method`, `opt_out` and `retired` are safety/policy gates; `sampled_out` and `max_concurrent` are throughput controls.",
 	}, []string{"network", "reason"})
 
 	MetricSelectionProbeDropped = promauto.NewCounterVec(prometheus.CounterOpts{
@@ -296,6 +308,18 @@ var (
 		Buckets:   []float64{1, 10, 60, 300, 900, 1800, 3600, 7200, 21600, 86400},
 	}, []string{"project", "network", "upstream"})
 
+	MetricBackfillRequestTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "backfill_request_total",
+		Help:      "Requests issued by admin-triggered cache backfill jobs. `outcome` ∈ {`success`,`failure`}.",
+	}, []string{"project", "network", "category", "outcome"})
+
+	MetricScheduledJobRunTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "scheduled_job_run_total",
+		Help:      "Runs of scheduler jobs. `status` ∈ {`succeeded`,`failed`,`skipped`}; skipped means another instance held the job lock.",
+	}, []string{"job", "type", "status"})
+
 	MetricUpstreamStaleLatestBlock = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "upstream_stale_latest_block_total",
@@ -332,6 +356,24 @@ var (
 		Help:      "Total number of failed split eth_getLogs sub-requests (network-scoped).",
 	}, []string{"project", "network", "user", "agent_name"})
 
+	MetricNetworkEvmGetLogsCompletenessChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "network_evm_get_logs_completeness_checks_total",
+		Help:      "Total number of eth_getLogs completeness cross-checks by source and outcome (complete, truncated, error).",
+	}, []string{"project", "network", "upstream", "source", "outcome"})
+
+	MetricNetworkEvmAddressActivityBlocksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "network_evm_address_activity_blocks_total",
+		Help:      "Total number of blocks handled by the address activity scanner by outcome (scanned, error, skipped).",
+	}, []string{"project", "network", "outcome"})
+
+	MetricNetworkEvmPrefetchTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "network_evm_prefetch_total",
+		Help:      "Total number of new-head prefetch requests by method and outcome (success, error, skipped).",
+	}, []string{"project", "network", "method", "outcome"})
+
 	MetricNetworkEvmGetLogsForcedSplits = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "network_evm_get_logs_forced_splits_total",
@@ -403,6 +445,18 @@ var (
 		Help:      "Total number of requests received for a network.",
 	}, []string{"project", "network", "category", "finality", "user", "agent_name"})
 
+	MetricNetworkRequestMalformedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "network_request_malformed_total",
+		Help:      "Total number of requests rejected by the strict request validation, by the JSON-RPC error code they were answered with.",
+	}, []string{"project", "network", "code"})
+
+	MetricWalletMethodRefusedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "wallet_method_refused_total",
+		Help:      "Total number of requests refused because they use accounts of upstream nodes and the project does not allow wallet methods.",
+	}, []string{"project", "network", "method"})
+
 	MetricNetworkMultiplexedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "network_multiplexed_request_total",
@@ -481,6 +535,61 @@ var (
 		Help:      "Total circuit-breaker state transitions per upstream and direction (closed_to_open/half_open_to_open/half_open_to_closed/open_to_half_open).",
 	}, []string{"project", "upstream", "transition"})
 
+	// MetricUpstreamCanaryWeight tracks the share of traffic a canary
+	// upstream is allowed to serve; 0 after an automatic rollback.
+	MetricUpstreamCanaryWeight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_canary_weight",
+		Help:      "Current traffic weight (0-1) of a canary upstream rollout.",
+	}, []string{"project", "network", "upstream"})
+
+	// MetricUpstreamRetired is 1 while an upstream is retired for having been
+	// dead for its configured retirement period.
+	MetricUpstreamRetired = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_retired",
+		Help:      "Whether the upstream is retired (1) after failing every request for its retirement period.",
+	}, []string{"project", "network", "upstream"})
+
+	// MetricClusterRoutingSyncTotal counts the routing state syncs between
+	// replicas, by outcome.
+	MetricClusterRoutingSyncTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "cluster_routing_sync_total",
+		Help:      "Total routing state syncs with the other replicas, by outcome (success, publish_failed, read_failed).",
+	}, []string{"project", "outcome"})
+
+	// MetricClusterRoutingPeers is the number of other replicas whose routing
+	// state was used by the last sync.
+	MetricClusterRoutingPeers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "cluster_routing_peers",
+		Help:      "Number of other replicas whose fresh routing state was used by the last sync.",
+	}, []string{"project"})
+
+	// MetricLeaseHeld tells whether this replica holds a single-writer lease.
+	MetricLeaseHeld = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "lease_held",
+		Help:      "Whether this replica holds the lease (1) of a single-writer role (sharedState.leases).",
+	}, []string{"lease"})
+
+	// MetricLeaseTransitionTotal counts the times this replica acquired or
+	// lost a lease, by transition.
+	MetricLeaseTransitionTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "lease_transition_total",
+		Help:      "Total lease transitions of this replica (acquired, released, expired, taken_over).",
+	}, []string{"lease", "transition"})
+
+	// MetricLeaseCampaignTotal counts the attempts to acquire or renew a
+	// lease, by outcome.
+	MetricLeaseCampaignTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "lease_campaign_total",
+		Help:      "Total attempts to acquire or renew a lease, by outcome (holder, follower, failed).",
+	}, []string{"lease", "outcome"})
+
 	MetricNetworkFailedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "network_failed_request_total",
@@ -505,6 +614,12 @@ var (
 		Help:      "Maximum number of requests allowed per second for a rate limiter budget (including auto-tuner).",
 	}, []string{"budget", "method", "scope"})
 
+	MetricRateLimiterDiscoveredLimit = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "rate_limiter_discovered_limit",
+		Help:      "Limit advertised by a provider's 429 responses, converted to the rule's period, that currently caps the auto-tuner.",
+	}, []string{"budget", "method"})
+
 	MetricRateLimiterBudgetDecisionTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "rate_limiter_budget_decision_total",
@@ -588,12 +703,200 @@ var (
 		Help:      "Total number of cached items rejected due to block timestamp age exceeding policy TTL",
 	}, []string{"project", "network", "method", "connector", "policy", "ttl"})
 
+	MetricCacheGetBudgetExceededTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "cache_get_budget_exceeded_total",
+		Help:      "Total number of cache reads that outlived their policies' maxReadLatency, by whether the late cache read or the upstream forward answered first.",
+	}, []string{"project", "network", "method", "winner"})
+
+	MetricCacheErrorResultSetTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "cache_error_result_set_total",
+		Help:      "Total number of deterministic upstream errors written to the cache (cache.errorResults), by normalized JSON-RPC error code.",
+	}, []string{"project", "network", "method", "connector", "code"})
+
+	MetricCacheGetKeyCollisionTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "cache_get_key_collision_total",
+		Help:      "Total number of cached values whose stored request did not match the request that looked them up (cache.keyHash.collisionAudit).",
+	}, []string{"project", "network", "method", "connector"})
+
+	MetricCacheSetDowngradeRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "cache_set_downgrade_rejected_total",
+		Help:      "Total number of cache writes (cache.records) rejected because the stored entry is derived from a later finalized block.",
+	}, []string{"project", "network", "method", "connector", "policy"})
+
+	MetricCacheGetEmptyInvalidatedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "cache_get_empty_invalidated_total",
+		Help:      "Total number of cached emptyish results (cache.*.policies.*.emptyTtl) treated as a miss because the network has a newer latest block than when they were stored.",
+	}, []string{"project", "network", "method", "connector"})
+
+	MetricCacheRevalidateTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "cache_revalidate_total",
+		Help:      "Total number of near-expiry cache entries refreshed in the background (cache.*.policies.*.staleWhileRevalidate), by outcome (success, failed, in_flight).",
+	}, []string{"project", "network", "method", "connector", "outcome"})
+
+	MetricCacheHitRate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "cache_hit_rate",
+		Help:      "Cache hit rate (hits / (hits + misses)) over the last cache.hitRateReport interval.",
+	}, []string{"project", "network", "method"})
+
+	MetricCacheHitRateBudgetBreachTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "cache_hit_rate_budget_breach_total",
+		Help:      "Total number of cache.hitRateReport intervals whose hit rate fell below the matching budget.",
+	}, []string{"project", "network", "method"})
+
+	MetricCacheHitRateBudgetBreached = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "cache_hit_rate_budget_breached",
+		Help:      "Whether the hit rate of the last cache.hitRateReport interval was below the matching budget (1) or not (0).",
+	}, []string{"project", "network", "method"})
+
 	MetricCacheSetOriginalBytes = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "cache_set_original_bytes_total",
 		Help:      "Total number of original (uncompressed) bytes for cache set operations.",
 	}, []string{"project", "network", "category", "connector", "policy", "ttl"})
 
+	MetricCacheGetCorruptedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "cache_get_corrupted_total",
+		Help:      "Total number of cached values that failed integrity checks on read and were treated as misses.",
+	}, []string{"project", "network", "category", "connector", "reason"})
+
+	MetricConnectorTombstonesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "connector_tombstones_total",
+		Help:      "Total number of tombstone operations by connector: written, purged, purge_failed and write_dropped.",
+	}, []string{"connector", "operation"})
+
+	MetricConnectorOverflowTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "connector_overflow_total",
+		Help:      "Total number of S3 overflow operations by connector: operation is put, get or delete; outcome is ok, miss or error.",
+	}, []string{"connector", "operation", "outcome"})
+
+	MetricConnectorCompressionBytesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "connector_compression_bytes_total",
+		Help:      "Total bytes of values at or above the compression threshold by connector and algorithm, before (original) and after (stored) compression; stored/original is the compression ratio.",
+	}, []string{"connector", "algorithm", "size"})
+
+	MetricConnectorOverflowBytesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "connector_overflow_bytes_total",
+		Help:      "Total bytes written to (put) and read from (get) S3 overflow storage by connector.",
+	}, []string{"connector", "operation"})
+
+	MetricConnectorLayeredRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "connector_layered_requests_total",
+		Help:      "Total number of layered connector reads per tier (l1, l2) by outcome (hit, miss, error).",
+	}, []string{"connector", "tier", "outcome"})
+
+	MetricConnectorLayeredWriteBackTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "connector_layered_writeback_total",
+		Help:      "Total number of write-back L2 writes by outcome: flushed, failed, or direct when the queue was full.",
+	}, []string{"connector", "outcome"})
+
+	MetricConnectorEvictionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "connector_evictions_total",
+		Help:      "Total number of entries evicted by disk-backed connectors to stay under their maximum size.",
+	}, []string{"connector"})
+
+	MetricConnectorDiskBytes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "connector_disk_bytes",
+		Help:      "Disk usage of embedded connectors: live entries (live) and files on disk (lsm, vlog).",
+	}, []string{"connector", "kind"})
+
+	MetricConnectorBufferedRowsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "connector_buffered_rows_total",
+		Help:      "Total number of rows written through the insert buffer of batching connectors, by outcome: inserted, failed, or rejected when the buffer was full.",
+	}, []string{"connector", "outcome"})
+
+	MetricConnectorState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "connector_state",
+		Help:      "Current state of each connector: 1 for the state it is in (initializing, healthy, degraded), 0 for the others.",
+	}, []string{"connector", "state"})
+
+	MetricConnectorBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "connector_breaker_state",
+		Help:      "Current circuit breaker state of each connector: 1 for the state it is in (closed, open, half_open), 0 for the others.",
+	}, []string{"connector", "state"})
+
+	MetricConnectorBreakerRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "connector_breaker_rejected_total",
+		Help:      "Total number of connector operations (get, set, delete) failed immediately because the connector's circuit breaker was open.",
+	}, []string{"connector", "operation"})
+
+	// MetricSubscriptionLagBlocks is how far the slowest block stream
+	// subscriber of a network is behind the head it was woken up for.
+	MetricSubscriptionLagBlocks = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "subscription_lag_blocks",
+		Help:      "Blocks the slowest block stream subscriber of a network is behind the network head.",
+	}, []string{"project", "network"})
+
+	MetricAlertsFiring = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "alerts_firing",
+		Help:      "Number of alerts currently firing per alerting rule.",
+	}, []string{"rule", "severity"})
+
+	MetricAlertNotificationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "alert_notifications_total",
+		Help:      "Total number of alert notifications delivered to sinks, by status (firing, resolved) and outcome (sent, failed).",
+	}, []string{"rule", "sink", "status", "outcome"})
+
+	MetricConnectorSchemaVersion = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "connector_schema_version",
+		Help:      "Schema migration version of the structures (tables, indexes, key layouts) each connector manages.",
+	}, []string{"connector"})
+
+	MetricIdempotencyRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "idempotency_requests_total",
+		Help:      "Total number of requests carrying an idempotency key, by outcome (executed, replayed, conflict, store_error).",
+	}, []string{"project", "method", "outcome"})
+
+	MetricJournalEntriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "journal_entries_total",
+		Help:      "Total number of journaled write requests by outcome (completed, failed, mined, rebroadcast, lost, store_error).",
+	}, []string{"project", "network", "method", "outcome"})
+
+	MetricAnalyticsRollupWritesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "analytics_rollup_writes_total",
+		Help:      "Total number of analytics rollup records written by resolution (1m, 1h) and outcome (success, error).",
+	}, []string{"project", "resolution", "outcome"})
+
+	MetricSubscriptionArchiveEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "subscription_archive_events_total",
+		Help:      "Total number of subscription events handled by the archive by kind (heads, logs) and outcome (uploaded, duplicate, dropped, upload_error).",
+	}, []string{"project", "network", "kind", "outcome"})
+
+	MetricSubscriptionArchiveUploadsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "subscription_archive_uploads_total",
+		Help:      "Total number of subscription archive objects written by outcome (success, error).",
+	}, []string{"project", "outcome"})
+
 	MetricCacheSetCompressedBytes = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "cache_set_compressed_bytes_total",
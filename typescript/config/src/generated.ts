@@ -164,8 +164,56 @@ export interface Config {
   projects?: (ProjectConfig | undefined)[];
   rateLimiters?: RateLimiterConfig;
   metrics?: MetricsConfig;
+  /**
+   * Management serves the operator endpoints on a listener of their own:
+   * see ManagementConfig. Nil keeps them on the public listener.
+   */
+  management?: ManagementConfig;
   proxyPools?: (ProxyPoolConfig | undefined)[];
   tracing?: TracingConfig;
+  scheduler?: SchedulerConfig;
+  /**
+   * Alerting evaluates threshold rules over eRPC's own metrics and notifies
+   * webhook, Slack or PagerDuty sinks, for deployments without Alertmanager.
+   */
+  alerting?: AlertingConfig;
+  /**
+   * ClientQuirks adjusts how upstreams are used based on the node client
+   * and version they report via web3_clientVersion, for every upstream of
+   * every project at once. Upstreams only detect their client when it is
+   * set; an empty list detects without changing anything.
+   */
+  clientQuirks?: (ClientQuirkConfig | undefined)[];
+}
+/**
+ * ClientQuirkConfig describes how a node client (optionally limited to a
+ * version range) differs from what eRPC expects by default.
+ */
+export interface ClientQuirkConfig {
+  /**
+   * Client matches the lowercased client name, e.g. "reth" or "geth|bor".
+   */
+  client: string;
+  /**
+   * MinVersion and MaxVersion bound the versions the quirk applies to,
+   * both inclusive. Either can be left empty.
+   */
+  minVersion?: string;
+  maxVersion?: string;
+  /**
+   * IgnoreMethods are methods the client does not serve. An upstream's own
+   * allowMethods still wins.
+   */
+  ignoreMethods?: string[];
+  /**
+   * MaxBatchSize caps the JSON-RPC batches sent to the client.
+   */
+  maxBatchSize?: number /* int */;
+  /**
+   * UnsupportedErrors are error message substrings with which the client
+   * reports an unsupported method, beyond the ones eRPC already knows.
+   */
+  unsupportedErrors?: string[];
 }
 export interface ServerConfig {
   listenV4?: boolean;
@@ -211,6 +259,185 @@ export interface ServerConfig {
    * CreditUnitsProvider and UpstreamConfig.CreditUnits.
    */
   costHeaders?: boolean;
+  /**
+   * ResponseCompression picks the encodings negotiated with clients and the
+   * body size above which responses are compressed. Projects can override it.
+   * Setting enableGzip to false turns response compression off entirely.
+   */
+  responseCompression?: ResponseCompressionConfig;
+  /**
+   * ETag adds a weak ETag, derived from the JSON-RPC result, to successful
+   * single (non-batch) responses served from cache or about finalized data,
+   * and answers a matching If-None-Match with 304 Not Modified and no body.
+   * Defaults to false.
+   */
+  etag?: boolean;
+  /**
+   * CorrelationId assigns each HTTP request an id that is logged with it,
+   * recorded on its traces, returned in its response and error bodies, and
+   * sent to the upstreams serving it.
+   */
+  correlationId?: CorrelationIdConfig;
+  /**
+   * UnixSocket also serves the HTTP API on a unix domain socket, e.g. for
+   * an application running next to erpc in the same pod or host. Set
+   * listenV4/listenV6 to false to serve only on the socket.
+   */
+  unixSocket?: UnixSocketConfig;
+  /**
+   * SocketActivation serves the HTTP API on the stream sockets passed by
+   * systemd (LISTEN_FDS), in addition to the listeners above. TLS applies
+   * to them when enabled.
+   */
+  socketActivation?: boolean;
+  /**
+   * ProxyProtocol reads PROXY protocol v1/v2 headers on the TCP listeners
+   * (HTTP and gRPC) so the client address seen by rate limiting, logs and
+   * trustedIPForwarders is the one a load balancer such as an AWS NLB
+   * received the connection from.
+   */
+  proxyProtocol?: ProxyProtocolConfig;
+  /**
+   * Attestation signs single (non-batch) JSON-RPC responses with an
+   * ed25519 key held by erpc, so a client can later prove which body was
+   * served for which request.
+   */
+  attestation?: AttestationConfig;
+  /**
+   * RequestValidation rejects malformed JSON-RPC requests (wrong jsonrpc
+   * version, ill-typed id, method or params) with a JSON-RPC error before
+   * they are parsed and forwarded.
+   */
+  requestValidation?: RequestValidationConfig;
+}
+/**
+ * AttestationConfig controls signed response attestations.
+ */
+export interface AttestationConfig {
+  /**
+   * Enabled defaults to true when the section is present.
+   */
+  enabled?: boolean;
+  /**
+   * PrivateKey is the ed25519 key, as a 32-byte seed or a 64-byte private
+   * key, hex or base64 encoded.
+   */
+  privateKey: string;
+  /**
+   * KeyId names the key in each attestation so verifiers can pick the
+   * right public key after a rotation. Defaults to the first 16 hex
+   * characters of the sha256 of the public key.
+   */
+  keyId?: string;
+  /**
+   * Header carries the attestation. Defaults to "X-ERPC-Attestation".
+   */
+  header?: string;
+}
+/**
+ * RequestValidationConfig controls the strict checks of incoming JSON-RPC
+ * requests. See ValidateJsonRpcRequestStrict.
+ */
+export interface RequestValidationConfig {
+  /**
+   * Enabled defaults to true when the section is present.
+   */
+  enabled?: boolean;
+  /**
+   * MaxMethodLength caps the length of the method name. Defaults to 64.
+   */
+  maxMethodLength?: number /* int */;
+  /**
+   * MaxIdLength caps the length of a string or numeric id. Defaults to 128.
+   */
+  maxIdLength?: number /* int */;
+  /**
+   * MaxParams caps the number of positional params. Defaults to 32.
+   */
+  maxParams?: number /* int */;
+}
+/**
+ * ProxyProtocolConfig controls PROXY protocol headers on incoming connections.
+ */
+export interface ProxyProtocolConfig {
+  /**
+   * TrustedSources lists the peers (IPs or CIDRs) whose PROXY header is
+   * read; connections from other peers are served as they are, so clients
+   * cannot spoof their address. Defaults to server.trustedIPForwarders.
+   */
+  trustedSources?: string[];
+  /**
+   * Required closes connections from trusted sources that do not start
+   * with a PROXY header. Defaults to false.
+   */
+  required?: boolean;
+  /**
+   * HeaderTimeout bounds how long a trusted source may take to send the
+   * header. Defaults to 5s.
+   */
+  headerTimeout?: Duration;
+}
+/**
+ * UnixSocketConfig is a unix domain socket listener of the HTTP API.
+ */
+export interface UnixSocketConfig {
+  /**
+   * Path of the socket file. A stale socket left by a previous process is
+   * replaced; any other existing file is an error.
+   */
+  path: string;
+  /**
+   * Mode sets the socket file permissions as an octal string. Defaults to
+   * "0660" so only the owner and group can connect.
+   */
+  mode?: string;
+}
+/**
+ * CorrelationIdConfig controls the per-request correlation id.
+ */
+export interface CorrelationIdConfig {
+  /**
+   * Enabled defaults to true.
+   */
+  enabled?: boolean;
+  /**
+   * Header carries the id in client requests, responses and upstream
+   * requests. Defaults to "X-Request-Id".
+   */
+  header?: string;
+  /**
+   * TrustClient reuses a valid id sent by the client instead of generating
+   * one. Defaults to true.
+   */
+  trustClient?: boolean;
+  /**
+   * ForwardToUpstreams sends the id to HTTP upstreams. Defaults to true.
+   */
+  forwardToUpstreams?: boolean;
+}
+/**
+ * ContentEncoding is an HTTP response encoding negotiated via Accept-Encoding.
+ */
+export type ContentEncoding = string;
+export const ContentEncodingBrotli: ContentEncoding = "br";
+export const ContentEncodingZstd: ContentEncoding = "zstd";
+export const ContentEncodingGzip: ContentEncoding = "gzip";
+/**
+ * ResponseCompressionConfig controls HTTP response compression. Each response
+ * uses the encoding the client accepts with the highest q-value; ties go to
+ * the earliest entry of Encodings. At project level, unset fields inherit the
+ * server's values.
+ */
+export interface ResponseCompressionConfig {
+  enabled?: boolean;
+  /**
+   * Encodings offered to clients, most preferred first. Defaults to ["gzip"].
+   */
+  encodings?: ContentEncoding[];
+  /**
+   * Threshold is the minimum body size in bytes to compress. Defaults to 1024.
+   */
+  threshold?: number /* int */;
 }
 /**
  * ExecutionHeadersMode controls how much per-request execution detail is
@@ -236,6 +463,13 @@ export interface HealthCheckConfig {
   mode?: HealthCheckMode;
   auth?: AuthConfig;
   defaultEval?: string;
+  /**
+   * FailOnCacheUnavailable fails the healthcheck while a connector of
+   * database.evmJsonRpcCache is not healthy (still initializing, or
+   * unreachable), for deployments that must not take traffic without
+   * their cache. Default: false, the cache is treated as optional.
+   */
+  failOnCacheUnavailable?: boolean;
 }
 export type HealthCheckMode = string;
 export const HealthCheckModeSimple: HealthCheckMode = "simple";
@@ -286,10 +520,252 @@ export interface ForceTraceMatcher {
    */
   method?: string;
 }
+/**
+ * ManagementConfig is a second HTTP listener for the operator endpoints —
+ * /metrics, /healthcheck, /admin and /debug/pprof/ — so they can be bound to
+ * localhost or an internal interface, with their own TLS and auth, while the
+ * public listener only serves RPC traffic.
+ */
+export interface ManagementConfig {
+  host?: string;
+  port?: number /* int */;
+  tls?: TLSConfig;
+  /**
+   * Auth guards /metrics and /debug/pprof/. The healthcheck and admin API
+   * keep using healthCheck.auth and admin.auth.
+   */
+  auth?: AuthConfig;
+  metrics?: boolean;
+  healthCheck?: boolean;
+  admin?: boolean;
+  pprof?: boolean;
+  /**
+   * Exclusive stops serving the endpoints enabled here anywhere else: the
+   * public listener answers 404 for them and the metrics.port listener is
+   * not started.
+   */
+  exclusive?: boolean;
+}
 export interface AdminConfig {
   auth?: AuthConfig;
   cors?: CORSConfig;
 }
+/**
+ * SchedulerConfig declares maintenance jobs run on cron schedules. Each run
+ * takes a cluster-wide lock (via the shared state connector) so a job fires on
+ * one instance at a time, and recent runs are exposed via the admin API.
+ */
+export interface SchedulerConfig {
+  /**
+   * HistorySize is how many past runs are kept per job.
+   */
+  historySize?: number /* int */;
+  jobs?: (ScheduledJobConfig | undefined)[];
+}
+export type ScheduledJobType = string;
+export const ScheduledJobTypeBackfill: ScheduledJobType = "backfill";
+export const ScheduledJobTypeWarm: ScheduledJobType = "warm";
+export const ScheduledJobTypeCacheSweep: ScheduledJobType = "cacheSweep";
+export const ScheduledJobTypeQuotaReset: ScheduledJobType = "quotaReset";
+export const ScheduledJobTypeHealthReport: ScheduledJobType = "healthReport";
+export interface ScheduledJobConfig {
+  id: string;
+  /**
+   * Schedule is a 5-field cron expression ("*\/10 * * * *"), a descriptor
+   * such as "@hourly", or "@every <duration>". Evaluated in UTC.
+   */
+  schedule: string;
+  type: 'backfill' | 'warm' | 'cacheSweep' | 'quotaReset' | 'healthReport';
+  /**
+   * Timeout bounds a single run. The cluster-wide lock taken by a run is
+   * held until the next activation, or for Timeout if longer, so a crashed
+   * instance cannot block the job forever.
+   */
+  timeout?: Duration;
+  backfill?: ScheduledBackfillConfig;
+  warm?: ScheduledWarmConfig;
+  cacheSweep?: ScheduledCacheSweepConfig;
+  quotaReset?: ScheduledQuotaResetConfig;
+  healthReport?: ScheduledHealthReportConfig;
+}
+/**
+ * ScheduledBackfillConfig pre-populates the cache with the most recent
+ * finalized blocks on every run (see the erpc_startBackfill admin method).
+ */
+export interface ScheduledBackfillConfig {
+  projectId: string;
+  networkId: string;
+  /**
+   * LastBlocks is how many blocks, ending at the network's finalized head,
+   * are walked on each run.
+   */
+  lastBlocks: number /* int64 */;
+  methods?: string[];
+  rateLimit?: number /* float64 */;
+  concurrency?: number /* int */;
+}
+/**
+ * ScheduledWarmConfig forwards a fixed set of requests through a network so
+ * their responses are (re)cached before clients ask for them.
+ */
+export interface ScheduledWarmConfig {
+  projectId: string;
+  networkId: string;
+  requests: (ScheduledWarmRequest | undefined)[];
+}
+export interface ScheduledWarmRequest {
+  method: string;
+  params?: any[];
+}
+/**
+ * ScheduledCacheSweepConfig deletes the cache entries whose partition key
+ * starts with PartitionKeyPrefix on every run (see the erpc_purgeCache admin
+ * method), e.g. to drop a network's entries on connectors without TTLs.
+ */
+export interface ScheduledCacheSweepConfig {
+  /**
+   * ConnectorId limits the sweep to one cache connector; all of them when empty.
+   */
+  connectorId?: string;
+  partitionKeyPrefix: string;
+}
+/**
+ * ScheduledQuotaResetConfig clears the per-consumer usage counters of a
+ * project (see the erpc_myUsage self-service method), so they cover the
+ * period since the last reset, e.g. a billing month.
+ */
+export interface ScheduledQuotaResetConfig {
+  projectId: string;
+  /**
+   * UserIds limits the reset to these consumers; all of them when empty.
+   */
+  userIds?: string[];
+}
+/**
+ * ScheduledHealthReportConfig posts the health of a project's upstreams (as
+ * returned by the erpc_project admin method) to a webhook on every run.
+ */
+export interface ScheduledHealthReportConfig {
+  /**
+   * ProjectId limits the report to one project; all of them when empty.
+   */
+  projectId?: string;
+  url: string;
+  /**
+   * Headers are added to every request, e.g. for authentication.
+   */
+  headers?: { [key: string]: string};
+}
+/**
+ * AlertingConfig declares alert rules evaluated every EvaluationInterval
+ * against the metrics this instance exports. An alert is identified by its
+ * rule and the labels of the series that triggered it, and is delivered once
+ * when it starts firing (again every RepeatInterval while it keeps firing)
+ * and once when it resolves. Each instance evaluates and notifies on its own.
+ */
+export interface AlertingConfig {
+  evaluationInterval?: Duration;
+  /**
+   * RepeatInterval re-notifies alerts that are still firing.
+   */
+  repeatInterval?: Duration;
+  /**
+   * SendResolved notifies sinks when a firing alert clears.
+   */
+  sendResolved?: boolean;
+  sinks?: (AlertSinkConfig | undefined)[];
+  rules?: (AlertRuleConfig | undefined)[];
+}
+export type AlertSinkType = string;
+export const AlertSinkTypeWebhook: AlertSinkType = "webhook";
+export const AlertSinkTypeSlack: AlertSinkType = "slack";
+export const AlertSinkTypePagerDuty: AlertSinkType = "pagerduty";
+export interface AlertSinkConfig {
+  id: string;
+  type: 'webhook' | 'slack' | 'pagerduty';
+  /**
+   * Url is the endpoint of a webhook sink or the incoming webhook URL of a
+   * Slack sink. PagerDuty sinks default to the Events API v2 endpoint.
+   */
+  url?: string;
+  /**
+   * Headers are added to every webhook request, e.g. for authentication.
+   */
+  headers?: { [key: string]: string};
+  /**
+   * RoutingKey is the integration key of a PagerDuty Events API v2 service.
+   */
+  routingKey?: string;
+}
+export type AlertRuleType = string;
+/**
+ * AlertRuleTypeUpstreamDown fires for every upstream cordoned on all
+ * methods or retired.
+ */
+export const AlertRuleTypeUpstreamDown: AlertRuleType = "upstreamDown";
+/**
+ * AlertRuleTypeConnectorUnhealthy fires for every connector that is not
+ * in the healthy state.
+ */
+export const AlertRuleTypeConnectorUnhealthy: AlertRuleType = "connectorUnhealthy";
+/**
+ * AlertRuleTypeErrorRate fires for every network whose share of failed
+ * requests since the previous evaluation is above Threshold.
+ */
+export const AlertRuleTypeErrorRate: AlertRuleType = "errorRate";
+/**
+ * AlertRuleTypeSubscriptionLag fires for every network whose slowest
+ * block stream subscriber is more than Threshold blocks behind the head.
+ */
+export const AlertRuleTypeSubscriptionLag: AlertRuleType = "subscriptionLag";
+/**
+ * AlertRuleTypeCacheHitRateBudget fires for every project, network and
+ * method whose last cache.hitRateReport interval was below its budget.
+ */
+export const AlertRuleTypeCacheHitRateBudget: AlertRuleType = "cacheHitRateBudget";
+/**
+ * AlertRuleTypeMetric compares any exported gauge (its value) or counter
+ * (its per-second rate since the previous evaluation) with Threshold.
+ */
+export const AlertRuleTypeMetric: AlertRuleType = "metric";
+export type AlertSeverity = string;
+export const AlertSeverityInfo: AlertSeverity = "info";
+export const AlertSeverityWarning: AlertSeverity = "warning";
+export const AlertSeverityCritical: AlertSeverity = "critical";
+export interface AlertRuleConfig {
+  id: string;
+  type: 'upstreamDown' | 'connectorUnhealthy' | 'errorRate' | 'subscriptionLag' | 'cacheHitRateBudget' | 'metric';
+  severity?: 'info' | 'warning' | 'critical';
+  /**
+   * Metric is the full name of the series a metric rule watches, e.g.
+   * "erpc_network_served_tip_advance_age_seconds".
+   */
+  metric?: string;
+  /**
+   * Labels restrict the rule to series whose labels match these patterns,
+   * e.g. {project: main, network: "evm:*"}.
+   */
+  labels?: { [key: string]: string};
+  /**
+   * Threshold is the error-rate fraction, the lag in blocks or the metric
+   * value above (or, with Below, under) which the rule fires.
+   */
+  threshold?: number /* float64 */;
+  below?: boolean;
+  /**
+   * MinRequests ignores networks that served fewer requests since the
+   * previous evaluation, so an error-rate rule does not fire on one failure.
+   */
+  minRequests?: number /* int64 */;
+  /**
+   * For is how long the condition must hold before the alert fires.
+   */
+  for?: Duration;
+  /**
+   * Sinks are the ids of the sinks to notify; empty notifies all of them.
+   */
+  sinks?: string[];
+}
 export interface AliasingConfig {
   rules: (AliasingRuleConfig | undefined)[];
 }
@@ -302,41 +778,345 @@ export interface AliasingRuleConfig {
 export interface DatabaseConfig {
   evmJsonRpcCache?: CacheConfig;
   sharedState?: SharedStateConfig;
+  idempotency?: IdempotencyConfig;
+  journal?: JournalConfig;
+  analytics?: AnalyticsConfig;
+}
+/**
+ * IdempotencyConfig deduplicates retried write requests that carry an
+ * idempotency key (Idempotency-Key header or "idempotencyKey" body field),
+ * across every replica sharing the connector.
+ */
+export interface IdempotencyConfig {
+  /**
+   * Connector stores the responses of completed requests and the locks of
+   * in-flight ones. Use a shared driver (redis, postgresql, dynamodb) so
+   * retries landing on another replica are deduplicated too.
+   */
+  connector?: ConnectorConfig;
+  /**
+   * Ttl is how long a completed response is replayed for its key.
+   */
+  ttl?: Duration;
+  /**
+   * LockTtl bounds how long an in-flight request holds its key; a retry
+   * with the same key waits up to this long for the original to finish.
+   */
+  lockTtl?: Duration;
+  /**
+   * Methods lists the methods (wildcards allowed) for which keys are
+   * honored. Keys sent with other methods are ignored.
+   */
+  methods?: string[];
+}
+/**
+ * JournalConfig records write requests (transaction broadcasts) while they
+ * are in flight, so that requests abandoned by a crashed or killed replica
+ * can be resolved — found on-chain, re-broadcast or reported lost — instead
+ * of leaving their callers with an ambiguous timeout.
+ */
+export interface JournalConfig {
+  /**
+   * Connector stores the journal. Use a shared driver (redis, postgresql,
+   * dynamodb) so surviving replicas can recover a crashed one's entries.
+   */
+  connector?: ConnectorConfig;
+  /**
+   * Methods lists the methods (wildcards allowed) that are journaled.
+   */
+  methods?: string[];
+  /**
+   * Ttl is how long an entry is kept once its outcome is known.
+   */
+  ttl?: Duration;
+  /**
+   * RecoverAfter is how old a pending entry must be before it is treated
+   * as abandoned. Keep it well above the longest request timeout.
+   */
+  recoverAfter?: Duration;
+  /**
+   * RecoveryInterval is how often abandoned entries are looked for.
+   */
+  recoveryInterval?: Duration;
+  /**
+   * Rebroadcast re-sends abandoned transactions that are not found on-chain
+   * instead of only reporting them lost. Requests without a transaction
+   * hash are never re-sent.
+   */
+  rebroadcast?: boolean;
+}
+/**
+ * AnalyticsConfig aggregates proxied requests into per-minute and per-hour
+ * rollups (requests, errors, credit units, cache hits by project, network,
+ * method and consumer) and persists them through a connector, so usage can
+ * be queried without an external analytics database.
+ */
+export interface AnalyticsConfig {
+  /**
+   * Connector stores the rollups. Every replica writes its own records,
+   * which are summed when read, so replicas can share one store.
+   */
+  connector?: ConnectorConfig;
+  /**
+   * FlushInterval is how often the rollups of open buckets are written.
+   */
+  flushInterval?: Duration;
+  /**
+   * MinuteRetention is how long per-minute rollups are kept.
+   */
+  minuteRetention?: Duration;
+  /**
+   * HourRetention is how long per-hour rollups are kept.
+   */
+  hourRetention?: Duration;
+  /**
+   * MaxRowsPerBucket caps the distinct network/method/consumer rows of one
+   * bucket of a replica; further combinations are folded into an "other"
+   * method row of their network.
+   */
+  maxRowsPerBucket?: number /* int */;
+}
+export interface SharedStateConfig {
+  /**
+   * ClusterKey identifies the logical group for shared counters across replicas (multi-tenant friendly)
+   */
+  clusterKey?: string;
+  /**
+   * Connector contains the storage driver configuration (redis, postgresql, dynamodb, memory)
+   */
+  connector?: ConnectorConfig;
+  /**
+   * FallbackTimeout is the timeout for remote storage operations (get/set/publish).
+   * It is a seconds-scale network timeout and NOT a foreground latency budget.
+   */
+  fallbackTimeout?: Duration;
+  /**
+   * LockTtl is the expiration for the distributed lock key in the backing store.
+   * Should comfortably exceed the expected duration of remote writes.
+   */
+  lockTtl?: Duration;
+  /**
+   * LockMaxWait caps how long the foreground path will wait to acquire the lock
+   * before proceeding locally and deferring the remote write to background.
+   */
+  lockMaxWait?: Duration;
+  /**
+   * UpdateMaxWait caps how long the foreground path will spend computing a new value
+   * (e.g., polling latest block) before returning the current local value.
+   */
+  updateMaxWait?: Duration;
+  /**
+   * Routing shares each replica's view of upstream health through the
+   * connector, so that all replicas converge on the same routing decisions
+   * instead of each rediscovering a failing upstream. Off when nil.
+   */
+  routing?: SharedRoutingConfig;
+  /**
+   * Leases elects one replica to run single-writer work such as scheduled
+   * jobs, and hands the role to another replica when it dies. Off when nil,
+   * in which case such work is only guarded by per-run locks.
+   */
+  leases?: SharedLeasesConfig;
+}
+/**
+ * SharedRoutingConfig selects which upstream health signals replicas share.
+ * Every replica periodically publishes its own signals and reads those of
+ * its peers.
+ */
+export interface SharedRoutingConfig {
+  /**
+   * SyncInterval is how often a replica publishes its signals and reads its
+   * peers'. Default: 5s.
+   */
+  syncInterval?: Duration;
+  /**
+   * StaleAfter is how long a peer's signals are used after it published
+   * them, so replicas that stop publishing drop out. Default: 30s.
+   */
+  staleAfter?: Duration;
+  /**
+   * Metrics adds the peers' request, error, throttled and misbehavior counts
+   * of each upstream and method to the rates upstreams are scored by.
+   * Default: true.
+   */
+  metrics?: boolean;
+  /**
+   * CircuitBreakers cordons an upstream on every replica while its circuit
+   * breaker is open on any of them. Default: true.
+   */
+  circuitBreakers?: boolean;
+}
+/**
+ * SharedLeasesConfig tunes the leases through which replicas elect the holder
+ * of a single-writer role.
+ */
+export interface SharedLeasesConfig {
+  /**
+   * Ttl is how long a holder keeps its lease without renewing it, and so
+   * how long a role stays vacant after its holder dies. Default: 15s.
+   */
+  ttl?: Duration;
+  /**
+   * RenewInterval is how often the holder renews its lease and the other
+   * replicas try to take it over. Default: 5s.
+   */
+  renewInterval?: Duration;
+}
+export interface CacheConfig {
+  connectors?: TsConnectorConfig[];
+  policies?: (CachePolicyConfig | undefined)[];
+  compression?: CompressionConfig;
+  integrity?: CacheIntegrityConfig;
+  /**
+   * GetTimeout is the hot-path budget for a cache lookup across all matching
+   * connectors. It never extends the request's own deadline, only shortens it.
+   */
+  getTimeout?: Duration;
+  /**
+   * SetTimeout bounds a background cache write, which runs after the response
+   * has been sent and therefore is not tied to the request's deadline.
+   */
+  setTimeout?: Duration;
+  /**
+   * HitRateReport periodically aggregates the cache hit rate per network and
+   * method, and warns when it falls below a configured budget. Off when nil.
+   */
+  hitRateReport?: CacheHitRateReportConfig;
+  /**
+   * KeyHash selects how cache keys are derived from requests.
+   */
+  keyHash?: CacheKeyHashConfig;
+  /**
+   * ErrorResults caches deterministic upstream errors so that repeating a
+   * failing call does not reach the upstreams again. Off when nil.
+   */
+  errorResults?: CacheErrorResultsConfig;
+  /**
+   * CrossPopulate also writes the entries of other methods a cached block
+   * response answers, e.g. eth_getBlockByHash for eth_getBlockByNumber.
+   * Off when nil.
+   */
+  crossPopulate?: CacheCrossPopulateConfig;
+  /**
+   * Records stores the block number, finality and upstream of every value
+   * with it, and writes values conditionally so that a response derived
+   * from an unfinalized or earlier block never replaces one derived from a
+   * finalized block.
+   */
+  records?: CacheRecordsConfig;
+}
+/**
+ * CacheRecordsConfig turns cached values into versioned records. Conditional
+ * writes use the connector's native primitive (a ConditionExpression on
+ * DynamoDB, a Lua script on Redis, ON CONFLICT ... WHERE on PostgreSQL);
+ * other connectors write unconditionally.
+ */
+export interface CacheRecordsConfig {
+  enabled?: boolean;
+}
+/**
+ * CacheCrossPopulateConfig selects which entries are derived from a cached
+ * eth_getBlockByNumber or eth_getBlockByHash response. Entries keyed by block
+ * number are only derived from finalized blocks.
+ */
+export interface CacheCrossPopulateConfig {
+  /**
+   * Blocks stores the block under the other of eth_getBlockByNumber and
+   * eth_getBlockByHash. Defaults to true.
+   */
+  blocks?: boolean;
+  /**
+   * TransactionsByIndex stores eth_getTransactionByBlockHashAndIndex and
+   * eth_getTransactionByBlockNumberAndIndex for every transaction of a
+   * full-transaction block. Defaults to true.
+   */
+  transactionsByIndex?: boolean;
+  /**
+   * TransactionsByHash stores eth_getTransactionByHash for every transaction
+   * of a full-transaction block, for indexers that fetch a block and then
+   * its transactions one by one. Defaults to true.
+   */
+  transactionsByHash?: boolean;
 }
-export interface SharedStateConfig {
+/**
+ * CacheErrorResultsConfig sets how long each class of deterministic error is
+ * cached. Only execution reverts of calls at a finalized block and non-retryable
+ * invalid-params errors are cached; transient errors (timeouts, rate limits,
+ * server-side failures) never are. The TTL of a matching cache policy still
+ * applies when it is shorter.
+ */
+export interface CacheErrorResultsConfig {
   /**
-   * ClusterKey identifies the logical group for shared counters across replicas (multi-tenant friendly)
+   * ExecutionRevertedTtl defaults to 5m.
    */
-  clusterKey?: string;
+  executionRevertedTtl: Duration;
   /**
-   * Connector contains the storage driver configuration (redis, postgresql, dynamodb, memory)
+   * InvalidParamsTtl defaults to 1m.
    */
-  connector?: ConnectorConfig;
+  invalidParamsTtl: Duration;
+}
+/**
+ * CacheKeyHashAlgorithm hashes the canonical request params into a cache key.
+ */
+export type CacheKeyHashAlgorithm = string;
+export const CacheKeyHashSha256: CacheKeyHashAlgorithm = "sha256";
+export const CacheKeyHashXxhash: CacheKeyHashAlgorithm = "xxhash";
+export const CacheKeyHashBlake3: CacheKeyHashAlgorithm = "blake3";
+/**
+ * CacheKeyHashConfig controls cache key derivation. Changing the algorithm
+ * changes every key, so existing entries are no longer found.
+ */
+export interface CacheKeyHashConfig {
   /**
-   * FallbackTimeout is the timeout for remote storage operations (get/set/publish).
-   * It is a seconds-scale network timeout and NOT a foreground latency budget.
+   * Algorithm defaults to sha256. xxhash is the cheapest but only 64 bits
+   * wide; blake3 is as collision-resistant as sha256 and faster.
    */
-  fallbackTimeout?: Duration;
+  algorithm: CacheKeyHashAlgorithm;
   /**
-   * LockTtl is the expiration for the distributed lock key in the backing store.
-   * Should comfortably exceed the expected duration of remote writes.
+   * CollisionAudit stores the canonical request alongside every cached value
+   * and checks it on read, so a key collision is served as a miss instead of
+   * another request's result.
    */
-  lockTtl?: Duration;
+  collisionAudit?: boolean;
+}
+/**
+ * CacheHitRateReportConfig controls the scheduled cache statistics report.
+ * Every interval, lookups (hits + misses) are aggregated per project, network
+ * and method; each aggregate with at least minRequests lookups is checked
+ * against the first matching budget.
+ */
+export interface CacheHitRateReportConfig {
   /**
-   * LockMaxWait caps how long the foreground path will wait to acquire the lock
-   * before proceeding locally and deferring the remote write to background.
+   * Interval between reports. Defaults to 5m.
    */
-  lockMaxWait?: Duration;
+  interval: Duration;
   /**
-   * UpdateMaxWait caps how long the foreground path will spend computing a new value
-   * (e.g., polling latest block) before returning the current local value.
+   * MinRequests is the number of lookups below which an aggregate is
+   * reported but never alerted on. Defaults to 100.
    */
-  updateMaxWait?: Duration;
+  minRequests: number /* int */;
+  /**
+   * Budgets are matched in order against network and method. Breaches are
+   * delivered by alerting rules of type cacheHitRateBudget.
+   */
+  budgets: (CacheHitRateBudgetConfig | undefined)[];
 }
-export interface CacheConfig {
-  connectors?: TsConnectorConfig[];
-  policies?: (CachePolicyConfig | undefined)[];
-  compression?: CompressionConfig;
+/**
+ * CacheHitRateBudgetConfig is the minimum acceptable hit rate for the networks
+ * and methods it matches. Network and Method accept wildcards and default to "*".
+ */
+export interface CacheHitRateBudgetConfig {
+  network: string;
+  method: string;
+  minHitRate: number /* float64 */;
+}
+/**
+ * CacheIntegrityConfig stores a checksum alongside every cached value and
+ * verifies it on read. A mismatch is treated as a cache miss and the record is
+ * deleted so it gets refilled from an upstream.
+ */
+export interface CacheIntegrityConfig {
+  enabled?: boolean;
 }
 export interface CompressionConfig {
   enabled?: boolean;
@@ -384,6 +1164,31 @@ export interface CachePolicyConfig {
    * BlockTimeAdaptiveDuration.
    */
   ttl?: Duration | BlockTimeAdaptiveDuration;
+  /**
+   * MaxReadLatency bounds how long a request waits on this policy's connector
+   * before it is also forwarded to upstreams; whichever answers first is
+   * served. Zero (default) waits for the cache read up to cache.getTimeout.
+   */
+  maxReadLatency?: Duration;
+  /**
+   * StaleWhileRevalidate refreshes an entry from upstreams in the background
+   * when it is read within this window before its TTL runs out; the read is
+   * still served from the cache. Zero (default) lets entries expire.
+   */
+  staleWhileRevalidate?: Duration;
+  /**
+   * EmptyTtl stores emptyish results (null, [], "0x") for this long instead
+   * of ttl, even when empty is 'ignore', so that polling for data that does
+   * not exist yet (e.g. receipts of pending transactions) is answered from
+   * the cache. Zero (default) leaves emptyish results to empty.
+   */
+  emptyTtl?: Duration;
+  /**
+   * EmptyInvalidateOnNewHead stops serving an emptyish result stored with
+   * emptyTtl once the network has a newer latest block than when it was
+   * stored. Default: true when emptyTtl is set.
+   */
+  emptyInvalidateOnNewHead?: boolean;
 }
 export type ConnectorDriverType = string;
 export const DriverMemory: ConnectorDriverType = "memory";
@@ -391,6 +1196,13 @@ export const DriverRedis: ConnectorDriverType = "redis";
 export const DriverPostgreSQL: ConnectorDriverType = "postgresql";
 export const DriverDynamoDB: ConnectorDriverType = "dynamodb";
 export const DriverGrpc: ConnectorDriverType = "grpc";
+export const DriverTiered: ConnectorDriverType = "tiered";
+export const DriverMemcached: ConnectorDriverType = "memcached";
+export const DriverLayered: ConnectorDriverType = "layered";
+export const DriverCassandra: ConnectorDriverType = "cassandra";
+export const DriverMongoDB: ConnectorDriverType = "mongodb";
+export const DriverBadger: ConnectorDriverType = "badger";
+export const DriverClickHouse: ConnectorDriverType = "clickhouse";
 export interface ConnectorConfig {
   id?: string;
   driver: TsConnectorDriverType;
@@ -403,13 +1215,278 @@ export interface ConnectorConfig {
    * for upstreams must not disable a normal cache).
    */
   tags?: string[];
+  /**
+   * Residency labels where the connector stores its data (e.g. "eu"). A
+   * project with cacheResidency only reads and writes connectors with the
+   * same label.
+   */
+  residency?: string;
   memory?: MemoryConnectorConfig;
   redis?: RedisConnectorConfig;
   dynamodb?: DynamoDBConnectorConfig;
   postgresql?: PostgreSQLConnectorConfig;
   grpc?: GrpcConnectorConfig;
+  tiered?: TieredConnectorConfig;
+  memcached?: MemcachedConnectorConfig;
+  layered?: LayeredConnectorConfig;
+  cassandra?: CassandraConnectorConfig;
+  mongodb?: MongoDBConnectorConfig;
+  badger?: BadgerConnectorConfig;
+  clickhouse?: ClickHouseConnectorConfig;
   failsafeForGets?: (FailsafeConfig | undefined)[];
   failsafeForSets?: (FailsafeConfig | undefined)[];
+  /**
+   * Tombstones makes Delete soft: see TombstoneConfig. Nil deletes entries
+   * immediately.
+   */
+  tombstones?: TombstoneConfig;
+  /**
+   * Overflow stores values too large for the connector in S3: see
+   * OverflowConfig. Nil passes every value to the connector as is.
+   */
+  overflow?: OverflowConfig;
+  /**
+   * Compression compresses values before they reach the connector (and
+   * overflow): see ConnectorCompressionConfig. Nil stores values as is.
+   */
+  compression?: ConnectorCompressionConfig;
+  /**
+   * Encryption encrypts values at rest with AES-GCM: see
+   * ConnectorEncryptionConfig. Nil stores values in plaintext.
+   */
+  encryption?: ConnectorEncryptionConfig;
+  /**
+   * CircuitBreaker stops sending operations to a backend that keeps
+   * failing: see ConnectorCircuitBreakerConfig.
+   */
+  circuitBreaker?: ConnectorCircuitBreakerConfig;
+}
+/**
+ * ConnectorCircuitBreakerConfig opens a breaker around a connector after
+ * consecutive transport failures or timeouts. While it is open, reads and
+ * writes fail immediately instead of waiting for the backend to time out, so
+ * the cache is bypassed. Once ProbeInterval has passed, one operation (or a
+ * background ping if no operation comes) checks the backend, and a success
+ * closes the breaker again.
+ */
+export interface ConnectorCircuitBreakerConfig {
+  /**
+   * Enabled defaults to true for drivers that reach a backend over the
+   * network, and to false for memory, badger, tiered and layered
+   * connectors (the connectors a tiered or layered one is made of have
+   * their own breakers).
+   */
+  enabled?: boolean;
+  /**
+   * FailureThreshold is how many consecutive failures open the breaker.
+   * Defaults to 5.
+   */
+  failureThreshold?: number /* int */;
+  /**
+   * ProbeInterval is how long the breaker stays open before the backend
+   * is checked again. Defaults to 10s.
+   */
+  probeInterval?: Duration;
+}
+/**
+ * ConnectorEncryptionConfig encrypts values with AES-256-GCM before they
+ * reach the connector (and overflow) and decrypts them on reads. Each stored
+ * value names the key it was encrypted with, so keys can be rotated: add the
+ * new key, make it the primary, and keep the old one listed until the values
+ * it encrypted have expired.
+ */
+export interface ConnectorEncryptionConfig {
+  /**
+   * PrimaryKeyId is the key new values are encrypted with. Default: the
+   * first key.
+   */
+  primaryKeyId?: string;
+  /**
+   * Keys are every key values may be encrypted with.
+   */
+  keys: (EncryptionKeyConfig | undefined)[];
+}
+/**
+ * EncryptionKeyConfig loads one 256-bit data key, either as base64 from an
+ * environment variable or as a data key encrypted by AWS KMS (the
+ * CiphertextBlob of kms:GenerateDataKey), decrypted once at startup.
+ */
+export interface EncryptionKeyConfig {
+  /**
+   * Id is written with every value encrypted with this key, so it must not
+   * be reused for a different key. At most 255 bytes.
+   */
+  id: string;
+  /**
+   * Source is "env" or "kms". Default: inferred from the fields set.
+   */
+  source?: 'env' | 'kms';
+  /**
+   * Env is the environment variable holding the base64 key (source env).
+   */
+  env?: string;
+  /**
+   * EncryptedDataKey is the base64 KMS ciphertext of the key (source kms).
+   */
+  encryptedDataKey?: string;
+  /**
+   * KmsRegion and Auth are used to call kms:Decrypt (source kms); nil Auth
+   * uses the default AWS credential chain.
+   */
+  kmsRegion?: string;
+  auth?: AwsAuthConfig;
+}
+/**
+ * ConnectorCompressionConfig compresses values of at least Threshold bytes
+ * on Set and decompresses them on Get, List and Scan. Compressed values carry
+ * a short header naming the algorithm, so values written uncompressed or with
+ * another algorithm stay readable when the config changes.
+ */
+export interface ConnectorCompressionConfig {
+  /**
+   * Algorithm is "zstd" (smaller) or "snappy" (faster). Default: zstd.
+   */
+  algorithm?: 'zstd' | 'snappy';
+  /**
+   * Threshold is the smallest value size (e.g. "1KB") that is compressed.
+   * Default: 1KB.
+   */
+  threshold?: ByteSize;
+  /**
+   * ZstdLevel is "fastest", "default", "better" or "best". Default:
+   * fastest.
+   */
+  zstdLevel?: string;
+}
+/**
+ * OverflowConfig moves values larger than Threshold (e.g. eth_getLogs over a
+ * wide range, debug_traceBlock) out of the connector into S3 and keeps only a
+ * small pointer in the connector, so stores with an item size limit such as
+ * DynamoDB (400KB) can still cache them. Objects expire through S3 lifecycle
+ * rules; see S3OverflowConfig.
+ */
+export interface OverflowConfig {
+  /**
+   * Threshold is the value size (e.g. "350KB") above which values are
+   * written to S3.
+   */
+  threshold: ByteSize;
+  s3?: S3OverflowConfig;
+}
+/**
+ * S3OverflowConfig is where oversized values are stored. Objects are grouped
+ * under "<prefix>ttl-<N>d/" by their TTL rounded up to 1, 7, 30, 90 or 365
+ * days (or "<prefix>permanent/"), so one lifecycle rule per group expires
+ * them.
+ */
+export interface S3OverflowConfig {
+  bucket: string;
+  prefix: string;
+  region: string;
+  endpoint: string;
+  auth?: AwsAuthConfig;
+  /**
+   * ForcePathStyle addresses the bucket as "<endpoint>/<bucket>" instead of
+   * a virtual host, as most S3-compatible stores (MinIO, localstack) need.
+   */
+  forcePathStyle: boolean;
+  /**
+   * ManageLifecycle installs the expiration rules for the TTL groups on the
+   * bucket at startup, keeping any other rules. Disable it when the bucket's
+   * lifecycle is managed elsewhere (IaC), and create the same rules there.
+   */
+  manageLifecycle?: boolean;
+  initTimeout: Duration;
+  getTimeout: Duration;
+  setTimeout: Duration;
+}
+/**
+ * TombstoneConfig turns connector deletes (reorg invalidation, purges,
+ * corrupted values) into tombstones: the entry is overwritten with a marker
+ * that every replica reads as a miss, and is physically deleted in background
+ * batches once GracePeriod has passed. Writes to a tombstoned key made by the
+ * same replica during the grace period are dropped, so an in-flight upstream
+ * response cannot resurrect the invalidated value.
+ */
+export interface TombstoneConfig {
+  /**
+   * GracePeriod is how long the tombstone is kept (and its TTL) before the
+   * key is physically deleted.
+   */
+  gracePeriod: Duration;
+  /**
+   * BatchSize caps how many keys are physically deleted per flush.
+   */
+  batchSize: number /* int */;
+  /**
+   * FlushInterval is how often due tombstones are physically deleted.
+   */
+  flushInterval: Duration;
+}
+/**
+ * TieredConnectorConfig splits a cache between a small, fast "hot" connector
+ * (memory/Redis/DynamoDB) and a deep, cheap "cold" one (e.g. PostgreSQL or a
+ * gRPC-backed archive). Entries that outlive OffloadAfter (finalized blocks,
+ * old receipts) are written to both tiers but only kept in the hot tier for
+ * OffloadAfter, so the hot store stays bounded while the cold store retains
+ * the long tail. Reads go to the hot tier first and transparently fall back
+ * to the cold tier on a miss.
+ */
+export interface TieredConnectorConfig {
+  hot: TsConnectorConfig;
+  cold: TsConnectorConfig;
+  /**
+   * OffloadAfter is how long an entry stays in the hot tier. Entries whose
+   * TTL is longer (or unlimited) are also written to the cold tier with their
+   * original TTL; shorter-lived entries (e.g. realtime data) never leave the
+   * hot tier.
+   */
+  offloadAfter?: Duration;
+  /**
+   * PromoteOnRead copies a cold-tier hit back into the hot tier (for
+   * OffloadAfter) so repeatedly requested historical data is served hot.
+   */
+  promoteOnRead?: boolean;
+}
+export type LayeredWriteMode = string;
+export const LayeredWriteThrough: LayeredWriteMode = "writeThrough";
+export const LayeredWriteBack: LayeredWriteMode = "writeBack";
+/**
+ * LayeredConnectorConfig puts an in-process memory cache (L1) in front of a
+ * shared remote connector (L2) such as Redis, DynamoDB or PostgreSQL, so hot
+ * keys like latest-block responses are served without a network round trip.
+ * Unlike TieredConnectorConfig every entry is written to L2, whatever its TTL,
+ * so replicas keep sharing what each of them fetched.
+ */
+export interface LayeredConnectorConfig {
+  l1?: MemoryConnectorConfig;
+  l2: TsConnectorConfig;
+  /**
+   * L1Ttl caps how long an entry is kept in L1. It bounds how long a
+   * replica can serve a value that was overwritten or deleted in L2 by
+   * another replica.
+   */
+  l1Ttl?: Duration;
+  /**
+   * L2Ttl caps the TTL of entries written to L2. Zero keeps the TTL of the
+   * cache policy.
+   */
+  l2Ttl?: Duration;
+  /**
+   * WriteMode is "writeThrough" (Set returns once both tiers are written)
+   * or "writeBack" (Set returns once L1 is written; L2 writes are queued
+   * and flushed in batches every FlushInterval).
+   */
+  writeMode?: 'writeThrough' | 'writeBack';
+  /**
+   * FlushInterval is how often queued L2 writes are flushed (writeBack).
+   */
+  flushInterval?: Duration;
+  /**
+   * MaxPending caps the queued L2 writes (writeBack); beyond it writes go to
+   * L2 synchronously, as in writeThrough.
+   */
+  maxPending?: number /* int */;
 }
 export interface GrpcConnectorConfig {
   bootstrap?: string;
@@ -456,6 +1533,191 @@ export interface RedisConnectorConfig {
   setTimeout?: Duration;
   lockRetryInterval?: Duration;
   iamAuth?: RedisIAMAuthConfig;
+  cluster?: RedisClusterConfig;
+  sentinel?: RedisSentinelConfig;
+}
+/**
+ * MemcachedConnectorConfig stores entries in one or more memcached servers,
+ * spread over them by key hash. Memcached cannot enumerate keys, so List and
+ * Scan are not supported; the reverse index is emulated with extra keys.
+ */
+export interface MemcachedConnectorConfig {
+  /**
+   * Servers are the memcached "host:port" addresses (or unix socket paths).
+   */
+  servers: string[];
+  /**
+   * KeyPrefix is prepended to every key, to share servers between
+   * deployments.
+   */
+  keyPrefix?: string;
+  tls?: TLSConfig;
+  /**
+   * ConnPoolSize is the number of idle connections kept per server.
+   */
+  connPoolSize?: number /* int */;
+  initTimeout?: Duration;
+  getTimeout?: Duration;
+  setTimeout?: Duration;
+  lockRetryInterval?: Duration;
+  /**
+   * StatePollInterval is how often shared counters are polled, since
+   * memcached has no pub/sub.
+   */
+  statePollInterval?: Duration;
+}
+export interface CassandraConnectorConfig {
+  /**
+   * Hosts are the contact points ("host" or "host:port", default port 9042).
+   */
+  hosts: string[];
+  /**
+   * Keyspace must exist; the connector creates its tables in it.
+   */
+  keyspace: string;
+  table?: string;
+  /**
+   * LocalDC routes queries to the replicas of this datacenter first.
+   */
+  localDC?: string;
+  /**
+   * Consistency of reads and writes, e.g. "LOCAL_QUORUM" or "LOCAL_ONE".
+   */
+  consistency?: string;
+  username?: string;
+  password?: string;
+  tls?: TLSConfig;
+  /**
+   * NumConns is the number of connections opened to each host.
+   */
+  numConns?: number /* int */;
+  initTimeout?: Duration;
+  getTimeout?: Duration;
+  setTimeout?: Duration;
+  statePollInterval?: Duration;
+  lockRetryInterval?: Duration;
+}
+/**
+ * BadgerConnectorConfig stores entries in an embedded BadgerDB database on
+ * local disk, for single-instance deployments that want a cache surviving
+ * restarts without running Redis. Locks and shared counters are local to the
+ * process, as for the memory connector.
+ */
+export interface BadgerConnectorConfig {
+  /**
+   * Dir is the database directory, created if missing. Only one process
+   * can open it at a time.
+   */
+  dir: string;
+  /**
+   * MaxDiskSize caps the size of the live entries. Above it, the least
+   * recently used entries are evicted. Empty means no cap.
+   */
+  maxDiskSize?: ByteSize;
+  /**
+   * EvictionInterval is how often the size is checked against MaxDiskSize.
+   */
+  evictionInterval?: Duration;
+  /**
+   * GCInterval is how often value log files are garbage collected, so the
+   * space of expired, overwritten and evicted values is reclaimed.
+   */
+  gcInterval?: Duration;
+  /**
+   * GCDiscardRatio is the share of stale data above which a value log file
+   * is rewritten, between 0 and 1 exclusive.
+   */
+  gcDiscardRatio?: number /* float64 */;
+  /**
+   * SyncWrites fsyncs every write. Without it a crash can lose the last
+   * writes, which a cache can usually afford.
+   */
+  syncWrites?: boolean;
+  initTimeout?: Duration;
+}
+/**
+ * MongoDBConnectorConfig stores entries as documents of one collection with
+ * a unique index on (partition key, range key), a second index on (range key,
+ * partition key) for the reverse index, and a TTL index on the expiry date.
+ */
+export interface MongoDBConnectorConfig {
+  /**
+   * URI is the mongodb:// or mongodb+srv:// connection string. Options in
+   * it are applied first; the fields below override them when set.
+   */
+  uri: string;
+  database: string;
+  collection?: string;
+  /**
+   * ReplicaSet is the replica set name to connect to.
+   */
+  replicaSet?: string;
+  /**
+   * ReadPreference is one of "primary", "primaryPreferred", "secondary",
+   * "secondaryPreferred" or "nearest". Reads from secondaries may lag.
+   */
+  readPreference?: string;
+  /**
+   * WriteConcern is "majority" or the number of members that must
+   * acknowledge a write.
+   */
+  writeConcern?: string;
+  username?: string;
+  password?: string;
+  tls?: TLSConfig;
+  /**
+   * MaxPoolSize is the largest number of connections per server.
+   */
+  maxPoolSize?: number /* uint64 */;
+  initTimeout?: Duration;
+  getTimeout?: Duration;
+  setTimeout?: Duration;
+  statePollInterval?: Duration;
+  lockRetryInterval?: Duration;
+}
+/**
+ * ClickHouseConnectorConfig stores cache entries in an append-only
+ * ClickHouse table over the HTTP interface, for long-term storage of
+ * finalized responses (blocks, receipts, logs) that can also be queried for
+ * analytics. Writes are buffered and inserted in batches; the table is
+ * partitioned by expiry date so expired data is dropped a partition at a
+ * time. It can only be used as a cache connector.
+ */
+export interface ClickHouseConnectorConfig {
+  /**
+   * Url is the HTTP(S) interface endpoint, e.g. "http://clickhouse:8123".
+   */
+  url: string;
+  database?: string;
+  table?: string;
+  username?: string;
+  password?: string;
+  tls?: TLSConfig;
+  /**
+   * PartitionBy is the width of the expiry date partitions: "day", "week"
+   * or "month". It only applies when the table is created.
+   */
+  partitionBy?: string;
+  /**
+   * BatchSize is the largest number of rows sent in one INSERT. A full
+   * batch is flushed without waiting for FlushInterval.
+   */
+  batchSize?: number /* int */;
+  /**
+   * FlushInterval is the longest a write waits in the buffer.
+   */
+  flushInterval?: Duration;
+  /**
+   * MaxBufferedRows caps the rows waiting to be inserted. Writes beyond it
+   * are rejected until the buffer drains.
+   */
+  maxBufferedRows?: number /* int */;
+  initTimeout?: Duration;
+  getTimeout?: Duration;
+  /**
+   * SetTimeout bounds each batch INSERT and each DELETE.
+   */
+  setTimeout?: Duration;
 }
 export interface DynamoDBConnectorConfig {
   table?: string;
@@ -472,6 +1734,33 @@ export interface DynamoDBConnectorConfig {
   maxRetries?: number /* int */;
   statePollInterval?: Duration;
   lockRetryInterval?: Duration;
+  /**
+   * ChunkSize is the largest value stored in a single item. Larger values
+   * are split into chunk items of this size plus a manifest item under the
+   * original key, to stay below DynamoDB's 400KB item limit.
+   */
+  chunkSize?: ByteSize;
+  /**
+   * Dax routes item reads and writes through a DynamoDB Accelerator cluster
+   * in front of the table. Table management always goes to DynamoDB.
+   */
+  dax?: DynamoDBDaxConfig;
+}
+/**
+ * DynamoDBDaxConfig points the DynamoDB connector at a DAX cluster. While the
+ * cluster is unreachable, calls fall back to the plain DynamoDB client.
+ */
+export interface DynamoDBDaxConfig {
+  /**
+   * Endpoint is the cluster discovery endpoint, "dax://<host>:8111", or
+   * "daxs://<host>" for clusters with encryption in transit.
+   */
+  endpoint: string;
+  /**
+   * FallbackFor is how long calls go straight to DynamoDB after the cluster
+   * failed to answer, before it is tried again.
+   */
+  fallbackFor: Duration;
 }
 export interface PostgreSQLConnectorConfig {
   connectionUri: string;
@@ -493,11 +1782,30 @@ export interface PostgreSQLConnectorConfig {
   skipSchemaSetup?: boolean;
 }
 export interface AwsAuthConfig {
-  mode: 'file' | 'env' | 'secret'; // "file", "env", "secret"
+  mode: 'file' | 'env' | 'secret' | 'assumeRole' | 'webIdentity'; // "file", "env", "secret", "assumeRole", "webIdentity"
   credentialsFile: string;
   profile: string;
   accessKeyID: string;
   secretAccessKey: string;
+  /**
+   * RoleArn is the IAM role to assume (assumeRole) or to exchange the web
+   * identity token for (webIdentity; defaults to AWS_ROLE_ARN).
+   */
+  roleArn?: string;
+  /**
+   * ExternalId is passed to sts:AssumeRole when the role's trust policy
+   * requires one (assumeRole only).
+   */
+  externalId?: string;
+  /**
+   * RoleSessionName names the assumed-role session (default "erpc").
+   */
+  roleSessionName?: string;
+  /**
+   * WebIdentityTokenFile is the OIDC token file for webIdentity mode
+   * (defaults to AWS_WEB_IDENTITY_TOKEN_FILE, which EKS IRSA injects).
+   */
+  webIdentityTokenFile?: string;
 }
 /**
  * RedisIAMAuthConfig enables AWS IAM authentication for ElastiCache (Valkey ≥7.2
@@ -524,6 +1832,42 @@ export interface RedisIAMAuthConfig {
    */
   auth?: AwsAuthConfig;
 }
+/**
+ * RedisClusterConfig configures the Redis connector for a Redis Cluster.
+ * Keys are routed to their hash slot, so a "{tag}" in a key places it with
+ * the other keys of the same tag.
+ */
+export interface RedisClusterConfig {
+  /**
+   * Addrs are "host:port" seed nodes the rest of the cluster is discovered
+   * from. The host of uri/addr is always used as a seed too.
+   */
+  addrs?: string[];
+  /**
+   * MaxRedirects caps the MOVED/ASK redirects followed per command.
+   * Defaults to 3.
+   */
+  maxRedirects?: number /* int */;
+}
+/**
+ * RedisSentinelConfig configures the Redis connector for a Sentinel-managed
+ * master.
+ */
+export interface RedisSentinelConfig {
+  /**
+   * MasterName is the name the sentinels monitor the master under.
+   */
+  masterName: string;
+  /**
+   * Addrs are the "host:port" addresses of the sentinels.
+   */
+  addrs: string[];
+  /**
+   * Username and Password authenticate to the sentinels, which often use
+   * other ACL users than the data nodes.
+   */
+  username?: string;
+}
 /**
  * PostgreSQLIAMAuthConfig enables AWS IAM authentication for RDS PostgreSQL.
  * eRPC mints SigV4-presigned tokens via pgxpool.BeforeConnect on each new pool
@@ -565,6 +1909,55 @@ export interface ProjectConfig {
   allowClientDirectives?: string;
   ignoreMethods?: string[];
   allowMethods?: string[];
+  /**
+   * AllowWalletMethods forwards methods that use the accounts of upstream
+   * nodes (eth_accounts, eth_sign*, eth_sendTransaction, personal_*) instead
+   * of refusing them. Only enable it when the upstreams are nodes you run
+   * and their accounts are meant to be used through this project.
+   */
+  allowWalletMethods?: boolean;
+  /**
+   * MethodRewrites alias methods and rewrite params of inbound requests
+   * before cache lookup and upstream selection (e.g. force "safe" instead of
+   * "latest" for every eth_call of this project). First matching rule wins.
+   */
+  methodRewrites?: (MethodRewriteConfig | undefined)[];
+  /**
+   * SelfService exposes tenant-scoped erpc_my* methods on the project
+   * endpoint so consumers can inspect their own keys, usage, rate limits and
+   * recent errors using the same credentials they send requests with.
+   */
+  selfService?: SelfServiceConfig;
+  /**
+   * Capabilities exposes the erpc_capabilities method on network endpoints
+   * so clients can discover what the proxy offers for that network.
+   */
+  capabilities?: CapabilitiesConfig;
+  /**
+   * ResponseCompression overrides server.responseCompression for this
+   * project's endpoints, e.g. to disable compression or offer br/zstd.
+   */
+  responseCompression?: ResponseCompressionConfig;
+  /**
+   * PreferredUpstreams lists upstream ids (e.g. dedicated nodes) that are
+   * tried first, in this order, for every network of the project whatever
+   * their score. The rest of the upstreams follow in policy order as a
+   * fallback.
+   */
+  preferredUpstreams?: string[];
+  /**
+   * CacheResidency restricts the project's cache reads and writes to the
+   * database.evmJsonRpcCache connectors whose residency is this label
+   * (e.g. "eu"), for data that must stay in a region. Policies on other
+   * connectors are ignored for the project. Empty uses every policy.
+   */
+  cacheResidency?: string;
+  /**
+   * SubscriptionArchive writes the heads and logs served to subscription
+   * clients (eth_getFilterChanges on block and log filters) to object
+   * storage, as a historical event lake of what the project delivered.
+   */
+  subscriptionArchive?: SubscriptionArchiveConfig;
   /**
    * ScoreMetricsWindowSize is the tumbling window the per-upstream
    * health tracker uses for its rolling counters (errorRate, p50/p70/
@@ -579,6 +1972,67 @@ export interface ProjectConfig {
    */
   scoreMetricsWindowSize?: Duration;
 }
+export interface SelfServiceConfig {
+  enabled: boolean;
+  /**
+   * RecentErrorsSize is how many of the latest failed requests are kept per
+   * user for erpc_myRecentErrors.
+   */
+  recentErrorsSize?: number /* int */;
+  /**
+   * MaxTrackedUsers bounds the in-memory usage tracker; when exceeded the
+   * least recently seen user is evicted.
+   */
+  maxTrackedUsers?: number /* int */;
+}
+export interface CapabilitiesConfig {
+  enabled: boolean;
+}
+/**
+ * SubscriptionArchiveConfig batches subscription events into objects under
+ * "<prefix>network=<networkId>/date=<YYYY-MM-DD>/", one file per event kind
+ * and flush. GCS buckets are written through its S3-compatible XML API, with
+ * HMAC keys as auth.
+ */
+export interface SubscriptionArchiveConfig {
+  /**
+   * Path is the destination, "s3://bucket/prefix/" or "gs://bucket/prefix/".
+   */
+  path: string;
+  /**
+   * Format of the objects. Only "ndjson" (gzip-compressed newline-delimited
+   * JSON) is supported.
+   */
+  format?: string;
+  /**
+   * Events lists the kinds archived: "heads" and/or "logs".
+   */
+  events?: string[];
+  region?: string;
+  /**
+   * Endpoint overrides the storage endpoint, e.g. for MinIO. Defaults to
+   * https://storage.googleapis.com for gs:// paths.
+   */
+  endpoint?: string;
+  forcePathStyle?: boolean;
+  auth?: AwsAuthConfig;
+  /**
+   * FlushInterval is the longest an event waits in memory before upload.
+   */
+  flushInterval?: Duration;
+  /**
+   * MaxRecords and MaxSize flush a batch early once it holds that many
+   * events or uncompressed bytes.
+   */
+  maxRecords?: number /* int */;
+  maxSize?: string;
+  /**
+   * QueueSize bounds the responses waiting to be parsed; when full, new
+   * ones are dropped rather than slowing down requests.
+   */
+  queueSize?: number /* int */;
+  uploadTimeout?: Duration;
+}
 /**
  * LegacyProjectFields collects the deprecated project-level scoring +
  * routing keys. The translator inspects these to synthesize a
@@ -676,6 +2130,12 @@ export interface UpstreamConfig {
    */
   creditUnits?: { [key: string]: number /* int64 */};
   shadow?: ShadowUpstreamConfig;
+  /**
+   * MethodRewrites alias methods and rewrite params only for what is sent to
+   * this upstream; cache keys and other upstreams keep seeing the original
+   * request. Inherited from upstreamDefaults when unset.
+   */
+  methodRewrites?: (MethodRewriteConfig | undefined)[];
   /**
    * Routing holds per-upstream routing hints consumed by the selection
    * policy. `scoreMultipliers` bias this upstream's rank inside
@@ -687,6 +2147,77 @@ export interface UpstreamConfig {
    * matching the Tags inheritance pattern).
    */
   routing?: UpstreamRoutingConfig;
+  /**
+   * Maintenance lists windows during which the upstream is drained: no new
+   * requests are routed to it and failures are not recorded against it.
+   */
+  maintenance?: (UpstreamMaintenanceWindowConfig | undefined)[];
+  /**
+   * Canary rolls a newly added upstream into traffic gradually and rolls it
+   * back to 0% when it performs worse than the rest of the network's pool.
+   */
+  canary?: UpstreamCanaryConfig;
+  /**
+   * Retirement takes an upstream out of routing and health probing once it
+   * has failed every request for DeadFor. upstreamDown alerting rules
+   * notify operators.
+   * Inherited from upstreamDefaults when unset.
+   */
+  retirement?: UpstreamRetirementConfig;
+}
+/**
+ * UpstreamRetirementConfig retires an upstream that has not served a single
+ * successful request for DeadFor while at least MinFailures requests failed
+ * against it. A retired upstream stays out of routing until it is reinstated
+ * via the erpc_unretireUpstream admin method. The failure history is written
+ * to shared state, so a restart resumes it instead of starting over.
+ */
+export interface UpstreamRetirementConfig {
+  enabled: boolean;
+  deadFor?: Duration;
+  /**
+   * MinFailures guards against retiring an upstream that merely saw no
+   * traffic: this many failures must be recorded since its last success.
+   */
+  minFailures?: number /* int64 */;
+}
+/**
+ * UpstreamCanaryConfig describes a staged rollout: the upstream serves
+ * Weights[i] of the network's requests for StepDuration before moving to the
+ * next step. At every evaluation its error rate and latency are compared with
+ * the other upstreams of the network; exceeding either threshold rolls it back
+ * to 0% until reset via the erpc_resetCanary admin method.
+ */
+export interface UpstreamCanaryConfig {
+  enabled: boolean;
+  weights?: number /* float64 */[];
+  stepDuration?: Duration;
+  /**
+   * MinSamples is the number of requests (in the current health-tracker
+   * window) required before the canary is compared with the pool or promoted.
+   */
+  minSamples?: number /* int64 */;
+  /**
+   * MaxErrorRateDelta rolls back when the canary error rate exceeds the pool
+   * error rate by more than this many points, e.g. 0.05 = 5 percentage points.
+   */
+  maxErrorRateDelta?: number /* float64 */;
+  /**
+   * MaxLatencyRatio rolls back when the canary p90 latency exceeds the pool
+   * p90 latency multiplied by this ratio.
+   */
+  maxLatencyRatio?: number /* float64 */;
+}
+/**
+ * UpstreamMaintenanceWindowConfig is either recurring (schedule + duration)
+ * or one-off (start + end, RFC3339).
+ */
+export interface UpstreamMaintenanceWindowConfig {
+  schedule?: string;
+  duration?: Duration;
+  start?: string;
+  end?: string;
+  reason?: string;
 }
 /**
  * UpstreamRoutingConfig holds per-upstream routing hints. Today this is
@@ -787,6 +2318,17 @@ export interface RateLimitAutoTuneConfig {
   decreaseFactor: number /* float64 */;
   minBudget: number /* int */;
   maxBudget: number /* int */;
+  /**
+   * DiscoverLimits learns the provider's effective limit from its 429
+   * responses (RateLimit / X-RateLimit headers, or "N requests per second"
+   * in the error message) and lowers the budget straight to it.
+   */
+  discoverLimits?: boolean;
+  /**
+   * DiscoveredLimitTtl is how long a discovered limit caps increases after
+   * it was last seen; afterwards the tuner probes upward again.
+   */
+  discoveredLimitTtl?: Duration;
 }
 export interface JsonRpcUpstreamConfig {
   supportsBatch?: boolean;
@@ -1001,6 +2543,14 @@ export interface ConsensusPolicyConfig {
    * Default is false (normal behavior - cancel remaining requests on short-circuit).
    */
   fireAndForget?: boolean;
+  /**
+   * Quorum when true, returns as soon as agreementThreshold participants agree on
+   * the same non-empty result, instead of waiting until the leading group's lead
+   * is unassailable by the remaining participants. Remaining requests are then
+   * cancelled (unless FireAndForget is set). Scope it to the methods that need it
+   * with the failsafe's matchMethod. Default is false.
+   */
+  quorum?: boolean;
   /**
    * MaxWaitOnResult caps how long consensus waits for additional participants
    * AFTER at least one non-empty response has arrived. Use this to bound
@@ -1129,6 +2679,18 @@ export interface RateLimitRuleConfig {
   perIP?: boolean;
   perUser?: boolean;
   perNetwork?: boolean;
+  /**
+   * SmoothingWindow additionally enforces the rule in sub-windows of this
+   * period (e.g. "second" for a per-minute rule). Each sub-window admits its
+   * even share of maxCount plus Burst, so a whole period's quota cannot be
+   * spent at once right after a window boundary.
+   */
+  smoothingWindow?: RateLimitPeriod;
+  /**
+   * Burst is the headroom each smoothing sub-window allows above its even
+   * share of maxCount. Requires SmoothingWindow.
+   */
+  burst?: number /* uint32 */;
 }
 /**
  * RateLimitPeriod enumerates supported periods for rate limiting.
@@ -1163,6 +2725,32 @@ export interface NetworkConfig {
   multiplexing?: boolean;
   staticResponses?: (StaticResponseConfig | undefined)[];
 }
+/**
+ * MethodRewriteConfig aliases a method and/or rewrites its positional params.
+ * Method is a wildcard pattern matched against the incoming method name; the
+ * first matching rule in a list is applied. ResultField optionally unwraps a
+ * field of an object result so an aliased method can return the shape the
+ * client asked for (e.g. alchemy_getTransactionReceipts → {receipts: [...]}).
+ */
+export interface MethodRewriteConfig {
+  method: string;
+  alias?: string;
+  params?: (ParamRewriteConfig | undefined)[];
+  resultField?: string;
+}
+/**
+ * ParamRewriteConfig rewrites the param at Index. When From is set the rule
+ * only applies if the current value equals it (e.g. "latest" → "safe"); when
+ * To is set the value is replaced; when WrapField is set the resulting value is
+ * wrapped into an object under that key (e.g. "0x1" → {"blockNumber":"0x1"}).
+ * An Index equal to the current params length appends To as a new param.
+ */
+export interface ParamRewriteConfig {
+  index: number /* int */;
+  from?: any;
+  to?: any;
+  wrapField?: string;
+}
 /**
  * StaticResponseConfig declares a canned JSON-RPC response for a specific
  * (method, params) pair on a network. When an inbound request matches, the
@@ -1338,6 +2926,351 @@ export interface EvmNetworkConfig {
    *     finalized head; an unfinalized block's empty is treated as not-yet-confirmed.
    */
   emptyResultConfidence?: AvailbilityConfidence;
+  /**
+   * BlockTagPolicy gives risk-averse consumers reorg-safe reads by default:
+   * user-supplied "latest" is rewritten to "safe"/"finalized" for the
+   * selected methods, and numeric blocks close to the head are treated as
+   * unfinalized for caching. Nil disables the policy. See
+   * EvmBlockTagPolicyConfig.
+   */
+  blockTagPolicy?: EvmBlockTagPolicyConfig;
+  /**
+   * Fork marks the network as a local fork of a live chain (e.g. anvil
+   * --fork-url). Reads of blocks up to the fork block go to the live
+   * upstreams, everything else to the fork nodes. Nil means not a fork.
+   * See EvmForkConfig.
+   */
+  fork?: EvmForkConfig;
+  /**
+   * NormalizeResponses rewrites block, transaction, receipt and log results
+   * into one canonical encoding (null vs missing fields, empty arrays,
+   * quantity padding, hex case, key order) so responses are byte-stable
+   * whichever node client answered. Default: false.
+   */
+  normalizeResponses?: boolean;
+  /**
+   * GetLogsCompletenessCheck cross-checks a sample of eth_getLogs results
+   * for finalized ranges against an independent source, flagging upstreams
+   * that return silently truncated results. Nil disables it. See
+   * EvmGetLogsCompletenessConfig.
+   */
+  getLogsCompletenessCheck?: EvmGetLogsCompletenessConfig;
+  /**
+   * ReceiptsPrefetch fetches the receipts (and optionally the logs) of every
+   * new head into the cache before clients ask for them. Nil disables it.
+   * See EvmReceiptsPrefetchConfig.
+   */
+  receiptsPrefetch?: EvmReceiptsPrefetchConfig;
+  /**
+   * Abi is a registry of known contract ABIs used to decode revert data
+   * and, through erpc_getDecodedLogs, event logs. Nil disables it. See
+   * EvmAbiConfig.
+   */
+  abi?: EvmAbiConfig;
+  /**
+   * AddressActivity serves erpc_addressActivity, which notifies clients
+   * when watched addresses appear in new blocks. Nil disables it. See
+   * EvmAddressActivityConfig.
+   */
+  addressActivity?: EvmAddressActivityConfig;
+  /**
+   * TagEmulation rewrites the "finalized"/"safe" block tags to concrete
+   * block numbers for nodes that do not support them, so clients can use
+   * them on every network. Nil disables it. See EvmTagEmulationConfig.
+   */
+  tagEmulation?: EvmTagEmulationConfig;
+}
+export type GetLogsCompletenessSource = string;
+/**
+ * GetLogsCompletenessSourceReceipts recounts matching logs from the block
+ * receipts, using the header logsBloom to skip blocks that cannot match.
+ */
+export const GetLogsCompletenessSourceReceipts: GetLogsCompletenessSource = "receipts";
+/**
+ * GetLogsCompletenessSourceUpstream repeats the eth_getLogs request on a
+ * different upstream and compares the counts.
+ */
+export const GetLogsCompletenessSourceUpstream: GetLogsCompletenessSource = "upstream";
+/**
+ * EvmGetLogsCompletenessConfig verifies that eth_getLogs results of finalized
+ * ranges are complete.
+ */
+export interface EvmGetLogsCompletenessConfig {
+  /**
+   * Source is what results are compared against: "receipts" (default) or
+   * "upstream".
+   */
+  source?: GetLogsCompletenessSource;
+  /**
+   * SampleRate is the fraction (0..1] of eligible responses that are
+   * checked. Default: 0.1.
+   */
+  sampleRate?: number /* float64 */;
+  /**
+   * MaxBlockRange skips requests spanning more blocks than this, bounding
+   * the cost of a check (one header and up to one receipts lookup per block
+   * with the receipts source). Default: 10.
+   */
+  maxBlockRange?: number /* int64 */;
+  /**
+   * Reject runs the check before the response is returned and turns a
+   * truncated result into a content validation error, so it is retried on
+   * another upstream. When false (default) the check runs in the background
+   * and only flags the upstream.
+   */
+  reject?: boolean;
+}
+/**
+ * EvmReceiptsPrefetchConfig prefetches the data of new blocks on head
+ * advances, while clients show interest in it (or always, with Always).
+ */
+export interface EvmReceiptsPrefetchConfig {
+  /**
+   * Receipts prefetches eth_getBlockReceipts. Default: true.
+   */
+  receipts?: boolean;
+  /**
+   * Blocks also prefetches eth_getBlockByNumber and, with the hash it
+   * returns, eth_getBlockByHash. Default: false.
+   */
+  blocks?: boolean;
+  /**
+   * BlockFullTransactions requests prefetched blocks with full transaction
+   * objects instead of transaction hashes. Each variant is cached under
+   * its own key, so match what clients ask for. Default: false.
+   */
+  blockFullTransactions?: boolean;
+  /**
+   * Logs also prefetches eth_getLogs of the whole block
+   * ({fromBlock: n, toBlock: n}, no address or topics). Default: false.
+   */
+  logs?: boolean;
+  /**
+   * Always prefetches every enabled method on every head, without waiting
+   * for clients to request it first. Default: false.
+   */
+  always?: boolean;
+  /**
+   * MaxConcurrency caps the blocks being prefetched at once; an advance
+   * that finds no free slot is skipped. Default: 4.
+   */
+  maxConcurrency?: number /* int */;
+  /**
+   * InterestWindow is how recently a client must have requested a method
+   * (or a block stream subscriber been connected) for its data to be
+   * prefetched. Default: 1m.
+   */
+  interestWindow?: Duration;
+}
+/**
+ * EvmAbiConfig lists contract ABIs known to a network and where to fetch
+ * the ABIs of other verified contracts.
+ */
+/**
+ * EvmAddressActivityConfig bounds the address activity watchers of a
+ * network. Each new block is scanned once for all watchers: its transactions
+ * (sender and recipient) and, optionally, its token transfer logs.
+ */
+export interface EvmAddressActivityConfig {
+  /**
+   * MaxWatchers caps the watchers installed at once. Default: 1000.
+   */
+  maxWatchers?: number /* int */;
+  /**
+   * MaxAddresses caps the addresses of one watcher. Default: 1000.
+   */
+  maxAddresses?: number /* int */;
+  /**
+   * MaxEvents caps the events buffered for one watcher between polls;
+   * the oldest are dropped beyond it. Default: 10000.
+   */
+  maxEvents?: number /* int */;
+  /**
+   * WatcherTtl removes watchers that have not been polled for this long,
+   * like nodes do with filters. Default: 5m.
+   */
+  watcherTtl?: Duration;
+  /**
+   * TokenTransfers also matches the from and to of ERC-20/721 Transfer and
+   * ERC-1155 TransferSingle/TransferBatch logs, at the cost of one
+   * eth_getLogs per block. Default: true.
+   */
+  tokenTransfers?: boolean;
+}
+/**
+ * EvmTagEmulationConfig emulates block tags the network's nodes do not
+ * support. Each emulated tag is resolved to a block number, either a fixed
+ * depth below the latest block or the value reported by an L1 checkpoint
+ * method, and requests carry that number instead of the tag.
+ */
+export interface EvmTagEmulationConfig {
+  /**
+   * Tags lists the tags to emulate: "finalized" and/or "safe". Default:
+   * both.
+   */
+  tags?: string[];
+  /**
+   * FinalizedDepth is how many blocks below the latest block "finalized"
+   * is emulated at. Default: evm.fallbackFinalityDepth.
+   */
+  finalizedDepth?: number /* int64 */;
+  /**
+   * SafeDepth is how many blocks below the latest block "safe" is
+   * emulated at. Default: finalizedDepth.
+   */
+  safeDepth?: number /* int64 */;
+  /**
+   * Checkpoint reads the tags from a method reporting the L2 blocks
+   * checkpointed on L1 (e.g. optimism_syncStatus) instead of using
+   * depths. Nil uses depths.
+   */
+  checkpoint?: EvmTagCheckpointConfig;
+}
+/**
+ * EvmTagCheckpointConfig describes the method the emulated tags are read
+ * from and where its result holds each block number.
+ */
+export interface EvmTagCheckpointConfig {
+  /**
+   * Method is called through the network, e.g. optimism_syncStatus.
+   */
+  method: string;
+  /**
+   * Params are sent with Method. Default: none.
+   */
+  params?: any[];
+  /**
+   * FinalizedPath is the dot-separated path of the finalized block number
+   * in the result, e.g. "finalized_l2.number".
+   */
+  finalizedPath?: string;
+  /**
+   * SafePath is the dot-separated path of the safe block number in the
+   * result, e.g. "safe_l2.number".
+   */
+  safePath?: string;
+  /**
+   * RefreshInterval is how long a checkpoint is reused before it is
+   * fetched again. Default: 5s.
+   */
+  refreshInterval?: Duration;
+}
+export interface EvmAbiConfig {
+  /**
+   * Contracts are ABIs provided in config. They take precedence over
+   * fetched ABIs and are never refetched.
+   */
+  contracts?: (EvmAbiContractConfig | undefined)[];
+  /**
+   * Sourcify fetches the ABIs of contracts verified on Sourcify. Nil
+   * disables it.
+   */
+  sourcify?: EvmAbiSourcifyConfig;
+  /**
+   * Etherscan fetches the ABIs of contracts verified on Etherscan (API v2,
+   * which covers every chain Etherscan supports). Tried after Sourcify.
+   * Nil disables it.
+   */
+  etherscan?: EvmAbiEtherscanConfig;
+  /**
+   * CacheTtl is how long a fetched ABI, or the absence of one, is kept
+   * before it is fetched again. Default: 24h.
+   */
+  cacheTtl?: Duration;
+  /**
+   * FetchTimeout bounds one fetch from Sourcify or Etherscan. Default: 5s.
+   */
+  fetchTimeout?: Duration;
+  /**
+   * DecodeReverts appends the decoded revert reason or custom error to the
+   * message of eth_call and eth_estimateGas revert errors. Default: true.
+   */
+  decodeReverts?: boolean;
+  /**
+   * DecodedLogs enables erpc_getDecodedLogs, erpc_getDecodedFilterChanges
+   * and erpc_getDecodedFilterLogs, which take the params of eth_getLogs,
+   * eth_getFilterChanges and eth_getFilterLogs and add the decoded event to
+   * each log. Default: false.
+   */
+  decodedLogs?: boolean;
+}
+export interface EvmAbiContractConfig {
+  /**
+   * Address is the contract address. For a proxy, list the
+   * implementation's ABI under the proxy address.
+   */
+  address: string;
+  /**
+   * Name labels decoded output, e.g. "USDC".
+   */
+  name?: string;
+  /**
+   * Abi is the JSON ABI. Exactly one of Abi and AbiFile is set.
+   */
+  abi?: string;
+  /**
+   * AbiFile is the path of a JSON ABI file, or of a compiler artifact
+   * with an "abi" field.
+   */
+  abiFile?: string;
+}
+export interface EvmAbiSourcifyConfig {
+  /**
+   * Endpoint is the Sourcify server. Default: https://sourcify.dev/server.
+   */
+  endpoint?: string;
+}
+export interface EvmAbiEtherscanConfig {
+  /**
+   * Endpoint is the Etherscan API. Default: https://api.etherscan.io/v2/api.
+   */
+  endpoint?: string;
+  apiKey: string;
+}
+/**
+ * EvmForkConfig layers a fork node over a live chain.
+ */
+export interface EvmForkConfig {
+  /**
+   * BlockNumber is the block the fork was taken at. Blocks at or below it
+   * are canonical chain data and are served by the live upstreams.
+   */
+  blockNumber: number /* int64 */;
+  /**
+   * Upstreams selects the fork nodes by upstream id or tag (same syntax as
+   * the use-upstream directive). Every other upstream of the network is a
+   * live upstream.
+   */
+  upstreams: string[];
+  /**
+   * InstanceId namespaces cache entries of post-fork state, so restarting a
+   * fork or running several over one shared cache never mixes their state.
+   * Defaults to the fork block number; set a new value whenever the fork
+   * node is restarted at the same block.
+   */
+  instanceId?: string;
+}
+/**
+ * EvmBlockTagPolicyConfig enforces reorg-safe block tags for a network.
+ */
+export interface EvmBlockTagPolicyConfig {
+  /**
+   * RewriteLatestTo is the tag user-supplied "latest" is rewritten to before
+   * interpolation and caching: "safe" or "finalized". Empty disables the
+   * rewrite.
+   */
+  rewriteLatestTo?: string;
+  /**
+   * Methods limits the rewrite to matching methods (wildcard patterns).
+   * Empty applies it to every method with a block reference.
+   */
+  methods?: string[];
+  /**
+   * UnfinalizedDepth treats numeric blocks within this many blocks of the
+   * served tip as unfinalized, even when upstreams already report them as
+   * finalized, so they are cached with unfinalized TTLs. Zero disables it.
+   */
+  unfinalizedDepth?: number /* int64 */;
 }
 /**
  * EvmServedTipConfig controls how the network derives the "latest"/"finalized"
@@ -9,6 +9,8 @@ import type {
     RedisConnectorConfig,
     SecretStrategyConfig,
     SiweStrategyConfig,
+    TieredConnectorConfig,
+    MemcachedConnectorConfig,
   } from "../generated";
   
   /**
@@ -55,7 +57,9 @@ import type {
     | "memory"
     | "redis"
     | "postgresql"
-    | "dynamodb";
+    | "dynamodb"
+    | "tiered"
+    | "memcached";
   
   /**
    * Connector config depending on the upstream type
@@ -80,6 +84,16 @@ import type {
         id: string;
         driver: "postgresql";
         postgresql: PostgreSQLConnectorConfig;
+      }
+    | {
+        id: string;
+        driver: "tiered";
+        tiered: TieredConnectorConfig;
+      }
+    | {
+        id: string;
+        driver: "memcached";
+        memcached: MemcachedConnectorConfig;
       };
   
   /**
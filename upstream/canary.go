@@ -0,0 +1,191 @@
+package upstream
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+)
+
+// canaryEvalInterval bounds how often a canary is compared with its pool;
+// evaluations are triggered from the request path.
+const canaryEvalInterval = 5 * time.Second
+
+type canaryRollout struct {
+	mu         sync.Mutex
+	state      common.UpstreamCanaryState
+	lastEvalAt time.Time
+}
+
+type canarySample struct {
+	requests  int64
+	errors    int64
+	latencyMs float64
+}
+
+// CanaryState returns a snapshot of the rollout, or nil if canary is not enabled.
+func (u *Upstream) CanaryState() *common.UpstreamCanaryState {
+	c := u.canaryRollout()
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := c.state
+	return &st
+}
+
+// CanaryWeight returns the share of requests the upstream may serve while its
+// rollout is in progress. ok is false when there is no rollout to apply
+// (canary disabled or completed), in which case the upstream routes normally.
+func (u *Upstream) CanaryWeight() (weight float64, ok bool) {
+	c := u.canaryRollout()
+	if c == nil {
+		return 0, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state.Status == common.UpstreamCanaryStatusCompleted {
+		return 0, false
+	}
+	return c.state.Weight, true
+}
+
+// ResetCanary restarts the rollout from the first step, e.g. after the
+// upstream was fixed following an automatic rollback.
+func (u *Upstream) ResetCanary() *common.UpstreamCanaryState {
+	c := u.canaryRollout()
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	c.setStepLocked(u, 0, time.Now())
+	st := c.state
+	c.mu.Unlock()
+	u.logger.Info().Float64("weight", st.Weight).Msg("canary rollout reset to first step")
+	return &st
+}
+
+// EvaluateCanary compares the canary with its peers and promotes it to the
+// next step or rolls it back. It is a no-op if called again within
+// canaryEvalInterval, so it is cheap to call on every request.
+func (u *Upstream) EvaluateCanary(peers []*Upstream) {
+	c := u.canaryRollout()
+	if c == nil {
+		return
+	}
+	now := time.Now()
+	c.mu.Lock()
+	if c.state.Status != common.UpstreamCanaryStatusProgressing || now.Sub(c.lastEvalAt) < canaryEvalInterval {
+		c.mu.Unlock()
+		return
+	}
+	c.lastEvalAt = now
+	c.mu.Unlock()
+
+	cfg := u.config.Canary
+	own := u.canarySample()
+	if own.requests < cfg.MinSamples {
+		return
+	}
+	// Peers are sampled without holding our lock: another canary may be
+	// evaluating concurrently and asking for our weight.
+	var pool canarySample
+	var latencyWeight float64
+	for _, p := range peers {
+		if p == nil || p == u {
+			continue
+		}
+		if _, rollingOut := p.CanaryWeight(); rollingOut {
+			continue
+		}
+		s := p.canarySample()
+		pool.requests += s.requests
+		pool.errors += s.errors
+		latencyWeight += s.latencyMs * float64(s.requests)
+	}
+	// Without enough pool traffic there is nothing to compare against; hold
+	// the current step rather than promote blindly.
+	if pool.requests < cfg.MinSamples {
+		return
+	}
+	pool.latencyMs = latencyWeight / float64(pool.requests)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state.Status != common.UpstreamCanaryStatusProgressing {
+		return
+	}
+	ownErrRate := float64(own.errors) / float64(own.requests)
+	poolErrRate := float64(pool.errors) / float64(pool.requests)
+	if ownErrRate > poolErrRate+cfg.MaxErrorRateDelta {
+		c.rollbackLocked(u, now, fmt.Sprintf("error rate %.1f%% exceeds pool error rate %.1f%% by more than %.1f points", ownErrRate*100, poolErrRate*100, cfg.MaxErrorRateDelta*100))
+		return
+	}
+	if pool.latencyMs > 0 && own.latencyMs > pool.latencyMs*cfg.MaxLatencyRatio {
+		c.rollbackLocked(u, now, fmt.Sprintf("p90 latency %.0fms exceeds %.1fx pool p90 latency %.0fms", own.latencyMs, cfg.MaxLatencyRatio, pool.latencyMs))
+		return
+	}
+	if now.Sub(c.state.Since) >= cfg.StepDuration.Duration() {
+		c.setStepLocked(u, c.state.Step+1, now)
+		u.logger.Info().Int("step", c.state.Step).Float64("weight", c.state.Weight).Str("status", string(c.state.Status)).Msg("canary promoted to next rollout step")
+	}
+}
+
+func (c *canaryRollout) rollbackLocked(u *Upstream, now time.Time, reason string) {
+	c.state.Status = common.UpstreamCanaryStatusRolledBack
+	c.state.Weight = 0
+	c.state.Since = now
+	c.state.Reason = reason
+	telemetry.MetricUpstreamCanaryWeight.WithLabelValues(u.ProjectId, u.NetworkLabel(), u.config.Id).Set(0)
+	u.logger.Warn().Int("step", c.state.Step).Str("reason", reason).Msg("canary rolled back to 0% of traffic")
+}
+
+func (c *canaryRollout) setStepLocked(u *Upstream, step int, now time.Time) {
+	weights := u.config.Canary.Weights
+	if step >= len(weights) {
+		step = len(weights) - 1
+	}
+	c.state = common.UpstreamCanaryState{
+		Status: common.UpstreamCanaryStatusProgressing,
+		Step:   step,
+		Weight: weights[step],
+		Since:  now,
+	}
+	if step == len(weights)-1 && weights[step] >= 1 {
+		c.state.Status = common.UpstreamCanaryStatusCompleted
+	}
+	telemetry.MetricUpstreamCanaryWeight.WithLabelValues(u.ProjectId, u.NetworkLabel(), u.config.Id).Set(c.state.Weight)
+}
+
+func (u *Upstream) canarySample() canarySample {
+	if u.metricsTracker == nil {
+		return canarySample{}
+	}
+	tm := u.metricsTracker.GetUpstreamMethodMetrics(u, "*", common.DataFinalityStateAll)
+	if tm == nil {
+		return canarySample{}
+	}
+	return canarySample{
+		requests:  tm.RequestsTotal.Load(),
+		errors:    tm.ErrorsTotal.Load(),
+		latencyMs: float64(tm.ResponseQuantiles.GetQuantile(0.9).Milliseconds()),
+	}
+}
+
+// canaryRollout lazily starts the rollout at the first step, so the step
+// timer begins when the upstream is first considered for traffic.
+func (u *Upstream) canaryRollout() *canaryRollout {
+	cfg := u.config.Canary
+	if cfg == nil || !cfg.Enabled || len(cfg.Weights) == 0 {
+		return nil
+	}
+	u.canaryOnce.Do(func() {
+		c := &canaryRollout{}
+		c.setStepLocked(u, 0, time.Now())
+		u.canary = c
+	})
+	return u.canary
+}
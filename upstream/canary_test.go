@@ -0,0 +1,120 @@
+package upstream
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCanaryTestUpstream(id string, canary *common.UpstreamCanaryConfig, mt *health.Tracker) *Upstream {
+	if canary != nil {
+		canary.SetDefaults()
+	}
+	return &Upstream{
+		ProjectId:      "test",
+		config:         &common.UpstreamConfig{Id: id, Canary: canary},
+		logger:         &log.Logger,
+		metricsTracker: mt,
+	}
+}
+
+func recordCanaryTestTraffic(mt *health.Tracker, u *Upstream, requests, failures int, latency time.Duration) {
+	for i := 0; i < requests; i++ {
+		mt.RecordUpstreamRequest(u, "eth_call", common.DataFinalityStateAll)
+		if i < failures {
+			mt.RecordUpstreamFailure(u, "eth_call", common.DataFinalityStateAll, errors.New("connection reset"))
+		} else {
+			mt.RecordUpstreamDuration(u, "eth_call", latency, true, "none", common.DataFinalityStateAll, "n/a")
+		}
+	}
+}
+
+func TestUpstream_EvaluateCanary(t *testing.T) {
+	t.Run("PromotesThroughStepsUntilCompleted", func(t *testing.T) {
+		mt := health.NewTracker(&log.Logger, "test", time.Minute)
+		canary := newCanaryTestUpstream("canary", &common.UpstreamCanaryConfig{
+			Enabled:      true,
+			Weights:      []float64{0.1, 1},
+			StepDuration: common.Duration(time.Millisecond),
+			MinSamples:   10,
+		}, mt)
+		peer := newCanaryTestUpstream("peer", nil, mt)
+		recordCanaryTestTraffic(mt, canary, 20, 0, 50*time.Millisecond)
+		recordCanaryTestTraffic(mt, peer, 100, 1, 50*time.Millisecond)
+
+		weight, rollingOut := canary.CanaryWeight()
+		require.True(t, rollingOut)
+		assert.Equal(t, 0.1, weight)
+
+		time.Sleep(2 * time.Millisecond)
+		canary.EvaluateCanary([]*Upstream{canary, peer})
+		st := canary.CanaryState()
+		assert.Equal(t, common.UpstreamCanaryStatusCompleted, st.Status)
+		assert.Equal(t, 1, st.Step)
+		_, rollingOut = canary.CanaryWeight()
+		assert.False(t, rollingOut, "a completed rollout routes like any other upstream")
+	})
+
+	t.Run("RollsBackOnErrorRate", func(t *testing.T) {
+		mt := health.NewTracker(&log.Logger, "test", time.Minute)
+		canary := newCanaryTestUpstream("canary", &common.UpstreamCanaryConfig{
+			Enabled:    true,
+			MinSamples: 10,
+		}, mt)
+		peer := newCanaryTestUpstream("peer", nil, mt)
+		recordCanaryTestTraffic(mt, canary, 20, 5, 50*time.Millisecond)
+		recordCanaryTestTraffic(mt, peer, 100, 1, 50*time.Millisecond)
+
+		canary.EvaluateCanary([]*Upstream{peer})
+		st := canary.CanaryState()
+		assert.Equal(t, common.UpstreamCanaryStatusRolledBack, st.Status)
+		assert.Contains(t, st.Reason, "error rate")
+		weight, rollingOut := canary.CanaryWeight()
+		assert.True(t, rollingOut)
+		assert.Zero(t, weight)
+
+		st = canary.ResetCanary()
+		assert.Equal(t, common.UpstreamCanaryStatusProgressing, st.Status)
+		assert.Equal(t, 0.01, st.Weight)
+	})
+
+	t.Run("RollsBackOnLatency", func(t *testing.T) {
+		mt := health.NewTracker(&log.Logger, "test", time.Minute)
+		canary := newCanaryTestUpstream("canary", &common.UpstreamCanaryConfig{
+			Enabled:    true,
+			MinSamples: 10,
+		}, mt)
+		peer := newCanaryTestUpstream("peer", nil, mt)
+		recordCanaryTestTraffic(mt, canary, 20, 0, 900*time.Millisecond)
+		recordCanaryTestTraffic(mt, peer, 100, 0, 100*time.Millisecond)
+
+		canary.EvaluateCanary([]*Upstream{peer})
+		st := canary.CanaryState()
+		assert.Equal(t, common.UpstreamCanaryStatusRolledBack, st.Status)
+		assert.Contains(t, st.Reason, "latency")
+	})
+
+	t.Run("HoldsWithoutEnoughSamples", func(t *testing.T) {
+		mt := health.NewTracker(&log.Logger, "test", time.Minute)
+		canary := newCanaryTestUpstream("canary", &common.UpstreamCanaryConfig{
+			Enabled:      true,
+			StepDuration: common.Duration(time.Millisecond),
+			MinSamples:   50,
+		}, mt)
+		peer := newCanaryTestUpstream("peer", nil, mt)
+		recordCanaryTestTraffic(mt, canary, 5, 5, 0)
+		recordCanaryTestTraffic(mt, peer, 100, 0, 50*time.Millisecond)
+
+		time.Sleep(2 * time.Millisecond)
+		canary.EvaluateCanary([]*Upstream{peer})
+		st := canary.CanaryState()
+		assert.Equal(t, common.UpstreamCanaryStatusProgressing, st.Status)
+		assert.Equal(t, 0, st.Step)
+	})
+}
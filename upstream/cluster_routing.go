@@ -0,0 +1,258 @@
+package upstream
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+)
+
+// clusterRoutingCordonReason marks the cordons placed by cluster routing, so
+// that it only lifts its own.
+const clusterRoutingCordonReason = "circuit breaker open on another replica"
+
+// clusterRoutingState is what one replica publishes about the upstreams of
+// a project.
+type clusterRoutingState struct {
+	// UpdatedAt is unix milliseconds.
+	UpdatedAt int64                   `json:"t"`
+	Upstreams []*clusterUpstreamState `json:"u"`
+}
+
+type clusterUpstreamState struct {
+	Network     string `json:"n"`
+	Upstream    string `json:"id"`
+	BreakerOpen bool   `json:"b,omitempty"`
+	// BreakerOpenSince and BreakerOpenUntil are when the open breaker opened
+	// and when it half-opens, in unix milliseconds. Peers keep their cordon
+	// until BreakerOpenUntil even if this replica stops reporting the
+	// breaker open earlier. Zero in states of replicas that predate them.
+	BreakerOpenSince int64                         `json:"bs,omitempty"`
+	BreakerOpenUntil int64                         `json:"bu,omitempty"`
+	Methods          map[string]*health.PeerCounts `json:"m,omitempty"`
+}
+
+// clusterRouting publishes this replica's upstream health signals and
+// applies its peers' to the local tracker and cordons.
+type clusterRouting struct {
+	registry *UpstreamsRegistry
+	ssr      data.SharedStateRegistry
+	cfg      *common.SharedRoutingConfig
+	logger   *zerolog.Logger
+	key      string
+
+	// peerMethods and cordoned remember what the previous sync applied, so
+	// that it can be undone once peers stop reporting it. cordoned holds the
+	// unix milliseconds until which a cordon is kept, or 0 to keep it only
+	// while a peer reports the breaker open.
+	peerMethods map[*Upstream]map[string]bool
+	cordoned    map[*Upstream]int64
+}
+
+func newClusterRouting(registry *UpstreamsRegistry, ssr data.SharedStateRegistry, cfg *common.SharedRoutingConfig) *clusterRouting {
+	lg := registry.logger.With().Str("component", "clusterRouting").Logger()
+	return &clusterRouting{
+		registry:    registry,
+		ssr:         ssr,
+		cfg:         cfg,
+		logger:      &lg,
+		key:         "routing/" + registry.prjId,
+		peerMethods: map[*Upstream]map[string]bool{},
+		cordoned:    map[*Upstream]int64{},
+	}
+}
+
+func (c *clusterRouting) run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.SyncInterval.Duration())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.sync(ctx)
+		}
+	}
+}
+
+// sync publishes the local state and applies the fresh states of the peers.
+func (c *clusterRouting) sync(ctx context.Context) {
+	now := time.Now()
+	ups := c.registry.GetAllUpstreams()
+
+	local, err := common.SonicCfg.Marshal(c.localState(ups, now))
+	if err == nil {
+		err = c.ssr.PutInstanceState(ctx, c.key, local, c.cfg.StaleAfter.Duration())
+	}
+	if err != nil {
+		// Peers stop seeing this replica after staleAfter, but it can still
+		// use theirs.
+		telemetry.MetricClusterRoutingSyncTotal.WithLabelValues(c.registry.prjId, "publish_failed").Inc()
+		c.logger.Warn().Err(err).Msg("failed to publish routing state to the other replicas")
+	}
+
+	raw, err := c.ssr.GetInstanceStates(ctx, c.key)
+	if err != nil {
+		// Keep the previous peer state rather than flapping on a transient
+		// connector error; it is dropped by the next successful sync.
+		telemetry.MetricClusterRoutingSyncTotal.WithLabelValues(c.registry.prjId, "read_failed").Inc()
+		c.logger.Warn().Err(err).Msg("failed to read routing state of the other replicas")
+		return
+	}
+	self := c.ssr.GetInstanceId()
+	cutoff := now.Add(-c.cfg.StaleAfter.Duration()).UnixMilli()
+	peers := map[string]*clusterRoutingState{}
+	for id, b := range raw {
+		if id == self {
+			continue
+		}
+		var st clusterRoutingState
+		if err := common.SonicCfg.Unmarshal(b, &st); err != nil {
+			c.logger.Debug().Err(err).Str("peer", id).Msg("ignoring unreadable routing state of a replica")
+			continue
+		}
+		if st.UpdatedAt < cutoff {
+			continue
+		}
+		peers[id] = &st
+	}
+	c.apply(ups, peers, now)
+	telemetry.MetricClusterRoutingPeers.WithLabelValues(c.registry.prjId).Set(float64(len(peers)))
+	telemetry.MetricClusterRoutingSyncTotal.WithLabelValues(c.registry.prjId, "success").Inc()
+}
+
+// localState collects the counts this replica observed itself, leaving out
+// those received from peers so that they are not echoed back.
+func (c *clusterRouting) localState(ups []*Upstream, now time.Time) *clusterRoutingState {
+	st := &clusterRoutingState{UpdatedAt: now.UnixMilli()}
+	tracker := c.registry.metricsTracker
+	for _, u := range ups {
+		us := &clusterUpstreamState{
+			Network:  u.NetworkId(),
+			Upstream: u.Id(),
+		}
+		// A breaker is not reported while the upstream is cordoned because
+		// of a peer's: it gets no traffic to half-open with, and two
+		// replicas would otherwise keep each other's cordon up forever.
+		if since, until, open := u.CircuitBreakerOpenWindow(); open {
+			if _, peerCordoned := c.cordoned[u]; !peerCordoned {
+				us.BreakerOpen = true
+				us.BreakerOpenSince = since.UnixMilli()
+				us.BreakerOpenUntil = until.UnixMilli()
+			}
+		}
+		if *c.cfg.Metrics && tracker != nil {
+			for method, m := range tracker.GetUpstreamMetrics(u) {
+				reqs := m.RequestsTotal.Load()
+				if reqs == 0 {
+					continue
+				}
+				if us.Methods == nil {
+					us.Methods = map[string]*health.PeerCounts{}
+				}
+				us.Methods[method] = &health.PeerCounts{
+					Requests:          reqs,
+					Errors:            m.ErrorsTotal.Load(),
+					RemoteRateLimited: m.RemoteRateLimitedTotal.Load(),
+					Misbehaviors:      m.MisbehaviorsTotal.Load(),
+				}
+			}
+		}
+		if us.BreakerOpen || len(us.Methods) > 0 {
+			st.Upstreams = append(st.Upstreams, us)
+		}
+	}
+	return st
+}
+
+func (c *clusterRouting) apply(ups []*Upstream, peers map[string]*clusterRoutingState, now time.Time) {
+	type upstreamRef struct{ network, id string }
+	counts := map[upstreamRef]map[string]*health.PeerCounts{}
+	openOn := map[upstreamRef][]string{}
+	openUntil := map[upstreamRef]int64{}
+	for peer, st := range peers {
+		for _, us := range st.Upstreams {
+			ref := upstreamRef{us.Network, us.Upstream}
+			// A breaker whose half-open time has passed only stays open until
+			// its next request, which a cordon would never send.
+			if us.BreakerOpen && (us.BreakerOpenUntil == 0 || us.BreakerOpenUntil > now.UnixMilli()) {
+				openOn[ref] = append(openOn[ref], peer)
+				if us.BreakerOpenUntil > openUntil[ref] {
+					openUntil[ref] = us.BreakerOpenUntil
+				}
+			}
+			for method, pc := range us.Methods {
+				if counts[ref] == nil {
+					counts[ref] = map[string]*health.PeerCounts{}
+				}
+				sum := counts[ref][method]
+				if sum == nil {
+					sum = &health.PeerCounts{}
+					counts[ref][method] = sum
+				}
+				sum.Requests += pc.Requests
+				sum.Errors += pc.Errors
+				sum.RemoteRateLimited += pc.RemoteRateLimited
+				sum.Misbehaviors += pc.Misbehaviors
+			}
+		}
+	}
+
+	tracker := c.registry.metricsTracker
+	for _, u := range ups {
+		ref := upstreamRef{u.NetworkId(), u.Id()}
+		if *c.cfg.Metrics && tracker != nil {
+			prev := c.peerMethods[u]
+			next := make(map[string]bool, len(counts[ref]))
+			for method, pc := range counts[ref] {
+				tracker.SetPeerCounts(u, method, pc)
+				next[method] = true
+			}
+			for method := range prev {
+				if !next[method] {
+					tracker.SetPeerCounts(u, method, nil)
+				}
+			}
+			c.peerMethods[u] = next
+		}
+		if *c.cfg.CircuitBreakers {
+			c.applyBreaker(u, openOn[ref], openUntil[ref], now)
+		}
+	}
+}
+
+// applyBreaker cordons u while its circuit breaker is open on a peer, and
+// keeps the cordon until the latest half-open time the peers reported, so
+// it does not flap with their publishing. It then lifts the cordon once no
+// peer reports the breaker open, unless another cordon has replaced it in
+// the meantime.
+func (c *clusterRouting) applyBreaker(u *Upstream, openOn []string, until int64, now time.Time) {
+	held, ours := c.cordoned[u]
+	if len(openOn) > 0 {
+		if reason, cordoned := u.CordonedReason("*"); cordoned {
+			// Already out of routing, by an earlier sync or another cordon.
+			if ours && reason == clusterRoutingCordonReason {
+				c.cordoned[u] = max(held, until)
+			}
+			return
+		}
+		sort.Strings(openOn)
+		c.logger.Info().Str("upstreamId", u.Id()).Strs("peers", openOn).Time("until", time.UnixMilli(until)).Msg("cordoning upstream whose circuit breaker is open on other replicas")
+		u.Cordon("*", clusterRoutingCordonReason)
+		c.cordoned[u] = max(held, until)
+		return
+	}
+	if !ours || held > now.UnixMilli() {
+		return
+	}
+	delete(c.cordoned, u)
+	if current, cordoned := u.CordonedReason("*"); cordoned && current == clusterRoutingCordonReason {
+		c.logger.Info().Str("upstreamId", u.Id()).Msg("uncordoning upstream whose circuit breaker is no longer open on other replicas")
+		u.Uncordon("*", current)
+	}
+}
@@ -0,0 +1,219 @@
+package upstream
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/failsafe"
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clusterRoutingTestStore is the connector shared by the replicas of a test.
+type clusterRoutingTestStore struct {
+	mu     sync.Mutex
+	states map[string]map[string][]byte
+}
+
+// clusterRoutingTestSSR is one replica's view of a clusterRoutingTestStore.
+type clusterRoutingTestSSR struct {
+	data.SharedStateRegistry
+	store *clusterRoutingTestStore
+	id    string
+}
+
+func (r *clusterRoutingTestSSR) GetInstanceId() string { return r.id }
+
+func (r *clusterRoutingTestSSR) PutInstanceState(_ context.Context, key string, value []byte, _ time.Duration) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if r.store.states[key] == nil {
+		r.store.states[key] = map[string][]byte{}
+	}
+	r.store.states[key][r.id] = value
+	return nil
+}
+
+func (r *clusterRoutingTestSSR) GetInstanceStates(_ context.Context, key string) (map[string][]byte, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	out := map[string][]byte{}
+	for id, v := range r.store.states[key] {
+		out[id] = v
+	}
+	return out, nil
+}
+
+type clusterRoutingTestReplica struct {
+	routing *clusterRouting
+	ssr     *clusterRoutingTestSSR
+	ups     *Upstream
+	breaker *failsafe.Breaker
+	tracker *health.Tracker
+}
+
+func newClusterRoutingTestReplica(store *clusterRoutingTestStore, id string, halfOpenAfter time.Duration) *clusterRoutingTestReplica {
+	tracker := health.NewTracker(&log.Logger, "test", time.Minute)
+	breaker := failsafe.NewBreaker(&common.CircuitBreakerPolicyConfig{
+		FailureThresholdCount:    1,
+		FailureThresholdCapacity: 1,
+		HalfOpenAfter:            common.Duration(halfOpenAfter),
+		SuccessThresholdCount:    1,
+		SuccessThresholdCapacity: 1,
+	}, &log.Logger)
+	ups := &Upstream{
+		ProjectId:         "test",
+		appCtx:            context.Background(),
+		config:            &common.UpstreamConfig{Id: "rpc1"},
+		logger:            &log.Logger,
+		metricsTracker:    tracker,
+		failsafeExecutors: []*upstreamExecutor{{method: "*", breaker: breaker, logger: &log.Logger}},
+	}
+	ups.networkId.Store("evm:1")
+	registry := &UpstreamsRegistry{
+		prjId:          "test",
+		logger:         &log.Logger,
+		metricsTracker: tracker,
+		allUpstreams:   []*Upstream{ups},
+		upstreamsMu:    &sync.RWMutex{},
+	}
+	cfg := &common.SharedRoutingConfig{}
+	cfg.SetDefaults()
+	ssr := &clusterRoutingTestSSR{store: store, id: id}
+	return &clusterRoutingTestReplica{
+		routing: newClusterRouting(registry, ssr, cfg),
+		ssr:     ssr,
+		ups:     ups,
+		breaker: breaker,
+		tracker: tracker,
+	}
+}
+
+func TestClusterRouting(t *testing.T) {
+	util.ConfigureTestLogger()
+	ctx := context.Background()
+
+	const halfOpenAfter = 50 * time.Millisecond
+	newReplicas := func() (*clusterRoutingTestReplica, *clusterRoutingTestReplica) {
+		store := &clusterRoutingTestStore{states: map[string]map[string][]byte{}}
+		return newClusterRoutingTestReplica(store, "a", halfOpenAfter), newClusterRoutingTestReplica(store, "b", halfOpenAfter)
+	}
+	syncAll := func(replicas ...*clusterRoutingTestReplica) {
+		for range 2 {
+			for _, r := range replicas {
+				r.routing.sync(ctx)
+			}
+		}
+	}
+
+	t.Run("RatesIncludePeerCounts", func(t *testing.T) {
+		a, b := newReplicas()
+		ma := a.tracker.GetUpstreamMethodMetrics(a.ups, "eth_call", common.DataFinalityStateAll)
+		ma.RequestsTotal.Add(10)
+		ma.ErrorsTotal.Add(5)
+		ma.RemoteRateLimitedTotal.Add(2)
+		b.tracker.GetUpstreamMethodMetrics(b.ups, "eth_call", common.DataFinalityStateAll).RequestsTotal.Add(10)
+
+		syncAll(a, b)
+
+		mb := b.tracker.GetUpstreamMethodMetrics(b.ups, "eth_call", common.DataFinalityStateAll)
+		assert.InDelta(t, 0.25, mb.ErrorRate(), 1e-9)
+		assert.InDelta(t, 0.1, mb.ThrottledRate(), 1e-9)
+		assert.InDelta(t, 0.25, ma.ErrorRate(), 1e-9)
+		assert.Equal(t, int64(10), ma.RequestsTotal.Load(), "local counters are not changed by peer counts")
+
+		ma.Reset()
+		syncAll(a, b)
+		assert.Nil(t, mb.Peers(), "peer counts are dropped once the peer stops reporting them")
+		assert.Equal(t, 0.0, mb.ErrorRate())
+	})
+
+	t.Run("OpenBreakerCordonsOnPeers", func(t *testing.T) {
+		a, b := newReplicas()
+		a.breaker.Record(failsafe.OutcomeFailure)
+		require.Equal(t, failsafe.StateOpen, a.breaker.State())
+
+		syncAll(a, b)
+		reason, cordoned := b.ups.CordonedReason("*")
+		assert.True(t, cordoned)
+		assert.Equal(t, clusterRoutingCordonReason, reason)
+		_, cordoned = a.ups.CordonedReason("*")
+		assert.False(t, cordoned, "the replica whose breaker opened is not cordoned by itself")
+
+		time.Sleep(halfOpenAfter)
+		require.True(t, a.breaker.TryAcquirePermit())
+		a.breaker.Record(failsafe.OutcomeSuccess)
+		require.Equal(t, failsafe.StateClosed, a.breaker.State())
+
+		syncAll(a, b)
+		_, cordoned = b.ups.CordonedReason("*")
+		assert.False(t, cordoned, "the cordon is lifted once the breaker closes")
+	})
+
+	t.Run("OtherCordonsAreLeftAlone", func(t *testing.T) {
+		a, b := newReplicas()
+		a.breaker.Record(failsafe.OutcomeFailure)
+		syncAll(a, b)
+		b.ups.Cordon("*", "maintenance")
+
+		time.Sleep(halfOpenAfter)
+		require.True(t, a.breaker.TryAcquirePermit())
+		a.breaker.Record(failsafe.OutcomeSuccess)
+		syncAll(a, b)
+
+		reason, cordoned := b.ups.CordonedReason("*")
+		assert.True(t, cordoned)
+		assert.Equal(t, "maintenance", reason)
+	})
+
+	t.Run("CordonIsKeptUntilHalfOpen", func(t *testing.T) {
+		a, b := newReplicas()
+		a.breaker.Record(failsafe.OutcomeFailure)
+		syncAll(a, b)
+		_, cordoned := b.ups.CordonedReason("*")
+		require.True(t, cordoned)
+
+		// The peer stops reporting the breaker, e.g. because it restarted.
+		a.ssr.store.mu.Lock()
+		delete(a.ssr.store.states[a.routing.key], "a")
+		a.ssr.store.mu.Unlock()
+		b.routing.sync(ctx)
+		_, cordoned = b.ups.CordonedReason("*")
+		assert.True(t, cordoned, "the cordon is kept until the breaker's half-open time")
+
+		time.Sleep(halfOpenAfter)
+		b.routing.sync(ctx)
+		_, cordoned = b.ups.CordonedReason("*")
+		assert.False(t, cordoned, "the cordon is lifted after the half-open time")
+	})
+
+	t.Run("OpenBreakerPastHalfOpenIsIgnored", func(t *testing.T) {
+		a, b := newReplicas()
+		a.breaker.Record(failsafe.OutcomeFailure)
+		time.Sleep(halfOpenAfter)
+		require.Equal(t, failsafe.StateOpen, a.breaker.State(), "the breaker half-opens on its next request only")
+
+		syncAll(a, b)
+		_, cordoned := b.ups.CordonedReason("*")
+		assert.False(t, cordoned)
+	})
+
+	t.Run("StalePeersAreIgnored", func(t *testing.T) {
+		a, b := newReplicas()
+		a.breaker.Record(failsafe.OutcomeFailure)
+		a.routing.sync(ctx)
+		b.routing.cfg.StaleAfter = common.Duration(time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+
+		b.routing.sync(ctx)
+		_, cordoned := b.ups.CordonedReason("*")
+		assert.False(t, cordoned)
+	})
+}
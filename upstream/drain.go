@@ -0,0 +1,125 @@
+package upstream
+
+import (
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+)
+
+type maintenanceWindowCache struct {
+	state      *common.UpstreamDrainState
+	validUntil time.Time
+}
+
+// Drain stops routing new requests to the upstream; in-flight requests finish
+// normally and their failures are not recorded against the upstream's health.
+// A zero duration keeps the upstream drained until Undrain is called.
+func (u *Upstream) Drain(mode common.UpstreamDrainMode, reason string, duration time.Duration) *common.UpstreamDrainState {
+	now := time.Now()
+	st := &common.UpstreamDrainState{
+		Mode:   mode,
+		Reason: reason,
+		Source: "admin",
+		Since:  now,
+	}
+	if duration > 0 {
+		st.Until = now.Add(duration)
+	}
+	u.manualDrain.Store(st)
+	u.logger.Info().Str("mode", string(mode)).Str("reason", reason).Dur("duration", duration).Msg("upstream drained; new requests will be routed elsewhere")
+	return st
+}
+
+// Undrain lifts a drain set via Drain. Configured maintenance windows still apply.
+func (u *Upstream) Undrain() bool {
+	if st := u.manualDrain.Swap(nil); st != nil {
+		u.logger.Info().Str("mode", string(st.Mode)).Msg("upstream undrained; accepting new requests")
+		return true
+	}
+	return false
+}
+
+// DrainState returns the active drain, if any: a retirement takes precedence
+// over an admin drain, which takes precedence over a maintenance window.
+func (u *Upstream) DrainState() *common.UpstreamDrainState {
+	if st := u.retired.Load(); st != nil {
+		return st
+	}
+	now := time.Now()
+	if st := u.manualDrain.Load(); st != nil {
+		if st.Until.IsZero() || now.Before(st.Until) {
+			return st
+		}
+		u.manualDrain.CompareAndSwap(st, nil)
+	}
+
+	windows := u.Config().Maintenance
+	if len(windows) == 0 {
+		return nil
+	}
+	if c := u.maintenanceWindow.Load(); c != nil && now.Before(c.validUntil) {
+		return c.state
+	}
+	state, validUntil := evaluateMaintenanceWindows(windows, now)
+	u.maintenanceWindow.Store(&maintenanceWindowCache{state: state, validUntil: validUntil})
+	return state
+}
+
+// evaluateMaintenanceWindows returns the window open at now (the one closing
+// last when several overlap) and the time until which that answer holds.
+func evaluateMaintenanceWindows(windows []*common.UpstreamMaintenanceWindowConfig, now time.Time) (*common.UpstreamDrainState, time.Time) {
+	// Cron windows only open on whole minutes, so re-evaluating at the next
+	// minute boundary is enough to notice them.
+	validUntil := now.Truncate(time.Minute).Add(time.Minute)
+	var active *common.UpstreamDrainState
+	for _, w := range windows {
+		if w == nil {
+			continue
+		}
+		var start, end time.Time
+		if w.Schedule != "" {
+			schedule, err := util.ParseCronSchedule(w.Schedule)
+			if err != nil {
+				continue
+			}
+			d := w.Duration.Duration()
+			// The first activation after now-d is the only one whose window can still be open
+			start = schedule.Next(now.Add(-d))
+			if start.IsZero() || start.After(now) {
+				continue
+			}
+			end = start.Add(d)
+		} else {
+			var err error
+			if start, err = time.Parse(time.RFC3339, w.Start); err != nil {
+				continue
+			}
+			if end, err = time.Parse(time.RFC3339, w.End); err != nil {
+				continue
+			}
+			if now.Before(start) {
+				if start.Before(validUntil) {
+					validUntil = start
+				}
+				continue
+			}
+			if !now.Before(end) {
+				continue
+			}
+		}
+		if end.Before(validUntil) {
+			validUntil = end
+		}
+		if active == nil || end.After(active.Until) {
+			active = &common.UpstreamDrainState{
+				Mode:   common.UpstreamDrainModeMaintenance,
+				Reason: w.Reason,
+				Source: "window",
+				Since:  start,
+				Until:  end,
+			}
+		}
+	}
+	return active, validUntil
+}
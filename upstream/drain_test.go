@@ -0,0 +1,81 @@
+package upstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateMaintenanceWindows(t *testing.T) {
+	// Every day 02:00-03:00 UTC
+	nightly := &common.UpstreamMaintenanceWindowConfig{
+		Schedule: "0 2 * * *",
+		Duration: common.Duration(time.Hour),
+		Reason:   "nightly node restart",
+	}
+	oneOff := &common.UpstreamMaintenanceWindowConfig{
+		Start:  "2026-03-10T12:00:00Z",
+		End:    "2026-03-10T14:00:00Z",
+		Reason: "provider migration",
+	}
+
+	t.Run("CronWindowOpen", func(t *testing.T) {
+		now := time.Date(2026, 3, 9, 2, 30, 15, 0, time.UTC)
+		st, validUntil := evaluateMaintenanceWindows([]*common.UpstreamMaintenanceWindowConfig{nightly}, now)
+		require.NotNil(t, st)
+		assert.Equal(t, common.UpstreamDrainModeMaintenance, st.Mode)
+		assert.Equal(t, "window", st.Source)
+		assert.Equal(t, "nightly node restart", st.Reason)
+		assert.Equal(t, time.Date(2026, 3, 9, 2, 0, 0, 0, time.UTC), st.Since)
+		assert.Equal(t, time.Date(2026, 3, 9, 3, 0, 0, 0, time.UTC), st.Until)
+		assert.Equal(t, time.Date(2026, 3, 9, 2, 31, 0, 0, time.UTC), validUntil)
+	})
+
+	t.Run("CronWindowClosed", func(t *testing.T) {
+		now := time.Date(2026, 3, 9, 3, 0, 0, 0, time.UTC)
+		st, _ := evaluateMaintenanceWindows([]*common.UpstreamMaintenanceWindowConfig{nightly}, now)
+		assert.Nil(t, st, "window end is exclusive")
+	})
+
+	t.Run("OneOffWindow", func(t *testing.T) {
+		before := time.Date(2026, 3, 10, 11, 59, 30, 0, time.UTC)
+		st, validUntil := evaluateMaintenanceWindows([]*common.UpstreamMaintenanceWindowConfig{oneOff}, before)
+		assert.Nil(t, st)
+		assert.Equal(t, time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC), validUntil)
+
+		during := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+		st, _ = evaluateMaintenanceWindows([]*common.UpstreamMaintenanceWindowConfig{nightly, oneOff}, during)
+		require.NotNil(t, st)
+		assert.Equal(t, "provider migration", st.Reason)
+
+		after := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+		st, _ = evaluateMaintenanceWindows([]*common.UpstreamMaintenanceWindowConfig{oneOff}, after)
+		assert.Nil(t, st)
+	})
+}
+
+func TestUpstream_Drain(t *testing.T) {
+	u := &Upstream{
+		config: &common.UpstreamConfig{Id: "rpc1"},
+		logger: &log.Logger,
+	}
+	assert.Nil(t, u.DrainState())
+	assert.False(t, u.Undrain(), "nothing to undrain")
+
+	st := u.Drain(common.UpstreamDrainModeDraining, "rotating keys", 0)
+	assert.Same(t, st, u.DrainState())
+	reason, skip := u.shouldSkip(context.Background(), nil)
+	assert.True(t, skip)
+	assert.True(t, common.HasErrorCode(reason, common.ErrCodeUpstreamDraining))
+	assert.True(t, u.Undrain())
+	assert.Nil(t, u.DrainState())
+
+	u.Drain(common.UpstreamDrainModeMaintenance, "short", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	assert.Nil(t, u.DrainState(), "timed drain lifts itself once expired")
+}
@@ -4,6 +4,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/erpc/erpc/telemetry"
 	"github.com/rs/zerolog"
 )
 
@@ -19,6 +20,16 @@ type RateLimitAutoTuner struct {
 	minBudget          int
 	maxBudget          int
 	mu                 sync.Mutex
+
+	// discoveredLimitTtl enables limit discovery when > 0; ceilings holds the
+	// discovered limits per rule, each capping increases until it expires.
+	discoveredLimitTtl time.Duration
+	ceilings           map[*RateLimitRule]discoveredCeiling
+}
+
+type discoveredCeiling struct {
+	maxCount uint32
+	until    time.Time
 }
 
 type ErrorCounter struct {
@@ -68,6 +79,83 @@ func (arl *RateLimitAutoTuner) RecordError(method string) {
 	arl.maybeAdjust(method)
 }
 
+// EnableLimitDiscovery makes RecordRateLimited learn the provider's limits
+// from its 429 responses; a learned limit caps increases for ttl after it
+// was last seen.
+func (arl *RateLimitAutoTuner) EnableLimitDiscovery(ttl time.Duration) {
+	arl.mu.Lock()
+	defer arl.mu.Unlock()
+	arl.discoveredLimitTtl = ttl
+	arl.ceilings = make(map[*RateLimitRule]discoveredCeiling)
+}
+
+// RecordRateLimited records a 429 from the provider. When the response
+// advertises the provider's limit, every rule matching the method is lowered
+// to it right away instead of shrinking by decreaseFactor period after period.
+func (arl *RateLimitAutoTuner) RecordRateLimited(method string, err error) {
+	arl.mu.Lock()
+	defer arl.mu.Unlock()
+
+	if arl.discoveredLimitTtl > 0 {
+		if limit, ok := DiscoverRateLimit(err); ok {
+			arl.applyDiscoveredLimit(method, limit)
+		}
+	}
+
+	c := arl.getOrCreateCounter(method)
+	c.totalCount++
+	c.errorCount++
+	arl.maybeAdjust(method)
+}
+
+// applyDiscoveredLimit must be called while arl.mu is held.
+func (arl *RateLimitAutoTuner) applyDiscoveredLimit(method string, limit DiscoveredRateLimit) {
+	rules, err := arl.budget.GetRulesByMethod(method)
+	if err != nil {
+		arl.logger.Warn().Err(err).Str("method", method).Msg("auto-tuner: failed to get rules")
+		return
+	}
+	for _, rule := range rules {
+		target := limit.MaxCountFor(rule.Config.Period)
+		if arl.minBudget > 0 && target < uint32(arl.minBudget) {
+			target = uint32(arl.minBudget)
+		}
+		arl.ceilings[rule] = discoveredCeiling{maxCount: target, until: time.Now().Add(arl.discoveredLimitTtl)}
+		telemetry.MetricRateLimiterDiscoveredLimit.WithLabelValues(arl.budget.Id, rule.Config.Method).Set(float64(target))
+
+		current := arl.budget.RuleConfigMaxCount(rule)
+		if current <= target {
+			continue
+		}
+		if err := arl.budget.AdjustBudget(rule, target); err != nil {
+			continue
+		}
+		arl.logger.Info().
+			Str("method", rule.Config.Method).
+			Str("triggeredBy", method).
+			Uint32("from", current).
+			Uint32("to", target).
+			Float64("discoveredCount", limit.Count).
+			Dur("discoveredWindow", limit.Window).
+			Msg("auto-tuner: lowering rate limit budget to the limit advertised by the provider")
+	}
+}
+
+// ceilingFor returns the discovered ceiling capping increases of rule, or 0.
+// Must be called while arl.mu is held.
+func (arl *RateLimitAutoTuner) ceilingFor(rule *RateLimitRule) int {
+	c, ok := arl.ceilings[rule]
+	if !ok {
+		return 0
+	}
+	if time.Now().After(c.until) {
+		delete(arl.ceilings, rule)
+		telemetry.MetricRateLimiterDiscoveredLimit.DeleteLabelValues(arl.budget.Id, rule.Config.Method)
+		return 0
+	}
+	return int(c.maxCount)
+}
+
 func (arl *RateLimitAutoTuner) getOrCreateCounter(method string) *ErrorCounter {
 	if c, exists := arl.errorCounts[method]; exists {
 		return c
@@ -118,13 +206,17 @@ func (arl *RateLimitAutoTuner) maybeAdjust(method string) {
 
 	for _, rule := range rules {
 		var factor float64
+		maxBudget := arl.maxBudget
 		if direction == "decrease" {
 			factor = arl.decreaseFactor
 		} else {
 			factor = arl.increaseFactor
+			if ceiling := arl.ceilingFor(rule); ceiling > 0 && (maxBudget <= 0 || ceiling < maxBudget) {
+				maxBudget = ceiling
+			}
 		}
 
-		prev, next, changed := arl.budget.AdjustBudgetByFactor(rule, factor, arl.minBudget, arl.maxBudget)
+		prev, next, changed := arl.budget.AdjustBudgetByFactor(rule, factor, arl.minBudget, maxBudget)
 		if !changed {
 			continue
 		}
@@ -12,6 +12,7 @@ import (
 	pb "github.com/envoyproxy/go-control-plane/envoy/service/ratelimit/v3"
 	"github.com/envoyproxy/ratelimit/src/config"
 	"github.com/envoyproxy/ratelimit/src/limiter"
+	"github.com/envoyproxy/ratelimit/src/utils"
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/telemetry"
 	"github.com/prometheus/client_golang/prometheus"
@@ -77,6 +78,29 @@ func (b *RateLimiterBudget) GetRulesByMethod(method string) ([]*RateLimitRule, e
 	return rules, nil
 }
 
+// RuleConfigs returns a copy of the budget's rule configs, safe to read while
+// the auto-tuner adjusts MaxCount concurrently.
+func (b *RateLimiterBudget) RuleConfigs() []common.RateLimitRuleConfig {
+	b.rulesMu.RLock()
+	defer b.rulesMu.RUnlock()
+
+	cfgs := make([]common.RateLimitRuleConfig, 0, len(b.Rules))
+	for _, rule := range b.Rules {
+		if rule.Config != nil {
+			cfgs = append(cfgs, *rule.Config)
+		}
+	}
+	return cfgs
+}
+
+// RuleConfigMaxCount returns the rule's current MaxCount, safe to read while
+// the auto-tuner adjusts it concurrently.
+func (b *RateLimiterBudget) RuleConfigMaxCount(rule *RateLimitRule) uint32 {
+	b.rulesMu.RLock()
+	defer b.rulesMu.RUnlock()
+	return rule.Config.MaxCount
+}
+
 // AdjustBudget updates the MaxCount for the provided rule and refreshes telemetry.
 func (b *RateLimiterBudget) AdjustBudget(rule *RateLimitRule, newMaxCount uint32) error {
 	if rule == nil || rule.Config == nil {
@@ -139,6 +163,42 @@ func (b *RateLimiterBudget) AdjustBudgetByFactor(rule *RateLimitRule, factor flo
 	return prev, next, true
 }
 
+// RuleMaxCountChange describes one rule updated by SetMaxCount.
+type RuleMaxCountChange struct {
+	Method           string `json:"method"`
+	Scope            string `json:"scope,omitempty"`
+	PreviousMaxCount uint32 `json:"previousMaxCount"`
+	MaxCount         uint32 `json:"maxCount"`
+}
+
+// SetMaxCount sets MaxCount of the rules whose method pattern is exactly
+// method, or of every rule when method is empty. Unlike GetRulesByMethod it
+// does not expand wildcards: "eth_*" selects the rule written as "eth_*".
+func (b *RateLimiterBudget) SetMaxCount(method string, maxCount uint32) []RuleMaxCountChange {
+	b.rulesMu.Lock()
+	defer b.rulesMu.Unlock()
+
+	changes := make([]RuleMaxCountChange, 0, len(b.Rules))
+	for _, rule := range b.Rules {
+		if rule.Config == nil || (method != "" && rule.Config.Method != method) {
+			continue
+		}
+		changes = append(changes, RuleMaxCountChange{
+			Method:           rule.Config.Method,
+			Scope:            rule.Config.ScopeString(),
+			PreviousMaxCount: rule.Config.MaxCount,
+			MaxCount:         maxCount,
+		})
+		if rule.Config.MaxCount == maxCount {
+			continue
+		}
+		b.logger.Warn().Str("method", rule.Config.Method).Msgf("setting rate limiter budget from: %d to: %d", rule.Config.MaxCount, maxCount)
+		rule.Config.MaxCount = maxCount
+		telemetry.MetricRateLimiterBudgetMaxCount.WithLabelValues(b.Id, rule.Config.Method, rule.Config.ScopeString()).Set(float64(maxCount))
+	}
+	return changes
+}
+
 // ruleResult holds the result of evaluating a single rule.
 type ruleResult struct {
 	rule    *RateLimitRule
@@ -276,6 +336,16 @@ func (b *RateLimiterBudget) evaluateRule(ctx context.Context, rule *RateLimitRul
 	limit := config.NewRateLimit(rule.Config.MaxCount, rule.Config.Period.Unit(), rlStats, false, false, "", nil, false)
 	limits := []*config.RateLimit{limit}
 
+	// A smoothed rule is also enforced per sub-window. Its descriptor carries
+	// the window so its counter never shares a key with the main window's
+	// (cache keys are aligned timestamps, which coincide at boundaries).
+	if sw := rule.Config.SmoothingWindow; sw != nil {
+		swEntries := append(entries[:len(entries):len(entries)], &pb_struct.RateLimitDescriptor_Entry{Key: "window", Value: sw.String()})
+		rlReq.Descriptors = append(rlReq.Descriptors, &pb_struct.RateLimitDescriptor{Entries: swEntries})
+		swStats := b.registry.statsManager.NewStats(statsKey + ".window_" + sw.String())
+		limits = append(limits, config.NewRateLimit(rule.smoothedMaxCount(), sw.Unit(), swStats, false, false, "", nil, false))
+	}
+
 	_, doSpan := common.StartSpan(ctx, "RateLimiter.DoLimit",
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(
@@ -303,7 +373,7 @@ func (b *RateLimiterBudget) evaluateRule(ctx context.Context, rule *RateLimitRul
 		return true // fail-open
 	}
 
-	isOverLimit := len(statuses) > 0 && statuses[0].Code == pb.RateLimitResponse_OVER_LIMIT
+	isOverLimit := anyOverLimit(statuses)
 	if isOverLimit {
 		doSpan.SetAttributes(attribute.String("result", "over_limit"))
 	} else {
@@ -314,6 +384,34 @@ func (b *RateLimiterBudget) evaluateRule(ctx context.Context, rule *RateLimitRul
 	return !isOverLimit
 }
 
+// anyOverLimit reports whether any descriptor of a DoLimit call (the main
+// window, and the smoothing sub-window when configured) is over its limit.
+func anyOverLimit(statuses []*pb.RateLimitResponse_DescriptorStatus) bool {
+	for _, st := range statuses {
+		if st != nil && st.Code == pb.RateLimitResponse_OVER_LIMIT {
+			return true
+		}
+	}
+	return false
+}
+
+// smoothedMaxCount returns the limit of one smoothing sub-window: the rule's
+// maxCount spread evenly across the sub-windows of its period (rounded up, at
+// least 1), plus the configured burst headroom.
+func (r *RateLimitRule) smoothedMaxCount() uint32 {
+	period := utils.UnitToDivider(r.Config.Period.Unit())
+	window := utils.UnitToDivider(r.Config.SmoothingWindow.Unit())
+	share := (int64(r.Config.MaxCount)*window + period - 1) / period
+	if share < 1 {
+		share = 1
+	}
+	total := share + int64(r.Config.Burst)
+	if total > math.MaxUint32 {
+		total = math.MaxUint32
+	}
+	return uint32(total)
+}
+
 // statsKeySuffix returns the pre-computed suffix for stats key.
 func (r *RateLimitRule) statsKeySuffix() string {
 	suffix := ""
@@ -425,7 +523,7 @@ func (b *RateLimiterBudget) doLimitWithTimeout(
 		// by the timeout branch below to keep buckets clean.
 		dur := time.Since(start).Seconds()
 		if statuses != nil {
-			isOverLimit := len(statuses) > 0 && statuses[0].Code == pb.RateLimitResponse_OVER_LIMIT
+			isOverLimit := anyOverLimit(statuses)
 			if isOverLimit && b.durationOverlimit != nil {
 				b.durationOverlimit.Observe(dur)
 			} else if !isOverLimit && b.durationOK != nil {
@@ -443,7 +541,7 @@ func (b *RateLimiterBudget) doLimitWithTimeout(
 		}
 		// Sample the warn log; under sustained pressure this fires hundreds of
 		// times per second and dwarfs the rest of the log volume.
-		if b.logger.GetLevel() <= zerolog.DebugLevel {
+		if common.LogLevelEnabled(b.logger, zerolog.DebugLevel) {
 			b.logger.Debug().
 				Str("budget", b.Id).
 				Str("method", method).
@@ -0,0 +1,139 @@
+package upstream
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/envoyproxy/ratelimit/src/utils"
+	"github.com/erpc/erpc/common"
+)
+
+// DiscoveredRateLimit is a provider's effective rate limit as advertised by
+// one of its 429 responses: Count requests per Window.
+type DiscoveredRateLimit struct {
+	Count  float64
+	Window time.Duration
+}
+
+// MaxCountFor converts the limit into a maxCount for a rule of the given
+// period, rounded down so the budget stays under the provider's ceiling.
+func (d DiscoveredRateLimit) MaxCountFor(period common.RateLimitPeriod) uint32 {
+	periodSec := float64(utils.UnitToDivider(period.Unit()))
+	v := math.Floor(d.Count * periodSec / d.Window.Seconds())
+	if v < 1 {
+		return 1
+	}
+	if v > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(v)
+}
+
+// capacityMessagePattern matches limits stated in capacity error messages,
+// e.g. "exceeded 25 requests per second", "limited to 10 req/s",
+// "300 compute units per second".
+var capacityMessagePattern = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*(?:requests?|reqs?|calls?|rps|compute units?|cus?)?\s*(?:per|/)\s*(second|sec|s|minute|min|m)s?\b`)
+
+// DiscoverRateLimit extracts the provider's limit from a capacity-exceeded
+// error. Headers (kept in the error details by the error normalizer) win over
+// the message; only limits with a known window are returned, since a bare
+// X-RateLimit-Limit may well be a daily quota.
+func DiscoverRateLimit(err error) (DiscoveredRateLimit, bool) {
+	var messages []string
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		be, ok := e.(interface{ Base() *common.BaseError })
+		if !ok || be.Base() == nil {
+			messages = append(messages, e.Error())
+			continue
+		}
+		base := be.Base()
+		if d, ok := discoverFromHeaders(base.Details["headers"]); ok {
+			return d, true
+		}
+		messages = append(messages, base.Message)
+	}
+	for _, msg := range messages {
+		if d, ok := discoverFromMessage(msg); ok {
+			return d, true
+		}
+	}
+	return DiscoveredRateLimit{}, false
+}
+
+func discoverFromHeaders(raw interface{}) (DiscoveredRateLimit, bool) {
+	get := func(string) string { return "" }
+	switch h := raw.(type) {
+	case map[string]interface{}:
+		get = func(k string) string {
+			v, _ := h[k].(string)
+			return v
+		}
+	case http.Header:
+		get = h.Get
+	default:
+		return DiscoveredRateLimit{}, false
+	}
+
+	// IETF draft: "100;w=60" or `"default";q=100;w=60`.
+	for _, name := range []string{"ratelimit-policy", "x-ratelimit-policy"} {
+		if d, ok := parseRateLimitPolicy(get(name)); ok {
+			return d, true
+		}
+	}
+	// Per-window headers (Kong and similar gateways); the shortest window is
+	// the one requests are hitting.
+	for _, w := range []struct {
+		suffix string
+		window time.Duration
+	}{{"second", time.Second}, {"minute", time.Minute}} {
+		if n, err := strconv.ParseFloat(strings.TrimSpace(get("x-ratelimit-limit-"+w.suffix)), 64); err == nil && n > 0 {
+			return DiscoveredRateLimit{Count: n, Window: w.window}, true
+		}
+	}
+	return DiscoveredRateLimit{}, false
+}
+
+func parseRateLimitPolicy(v string) (DiscoveredRateLimit, bool) {
+	if v == "" {
+		return DiscoveredRateLimit{}, false
+	}
+	// Several policies may be listed; the first is the one that applies.
+	policy := strings.SplitN(v, ",", 2)[0]
+	var count, window float64
+	for i, part := range strings.Split(policy, ";") {
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.HasPrefix(part, "q="):
+			count, _ = strconv.ParseFloat(part[2:], 64)
+		case strings.HasPrefix(part, "w="):
+			window, _ = strconv.ParseFloat(part[2:], 64)
+		case i == 0:
+			count, _ = strconv.ParseFloat(part, 64)
+		}
+	}
+	if count <= 0 || window <= 0 {
+		return DiscoveredRateLimit{}, false
+	}
+	return DiscoveredRateLimit{Count: count, Window: time.Duration(window * float64(time.Second))}, true
+}
+
+func discoverFromMessage(msg string) (DiscoveredRateLimit, bool) {
+	m := capacityMessagePattern.FindStringSubmatch(msg)
+	if m == nil {
+		return DiscoveredRateLimit{}, false
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || n <= 0 {
+		return DiscoveredRateLimit{}, false
+	}
+	window := time.Second
+	if u := strings.ToLower(m[2]); strings.HasPrefix(u, "m") {
+		window = time.Minute
+	}
+	return DiscoveredRateLimit{Count: n, Window: window}, true
+}
@@ -0,0 +1,113 @@
+package upstream
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func capacityErr(msg string, headers map[string]interface{}) error {
+	return common.NewErrEndpointCapacityExceeded(
+		common.NewErrJsonRpcExceptionInternal(429, common.JsonRpcErrorCapacityExceeded, msg, nil, map[string]interface{}{
+			"headers": headers,
+		}),
+	)
+}
+
+func TestDiscoverRateLimit(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want DiscoveredRateLimit
+		ok   bool
+	}{
+		{"PolicyHeader", capacityErr("too many requests", map[string]interface{}{"ratelimit-policy": "100;w=60"}), DiscoveredRateLimit{100, time.Minute}, true},
+		{"NamedPolicyHeader", capacityErr("too many requests", map[string]interface{}{"ratelimit-policy": `"default";q=25;w=1, "burst";q=100;w=10`}), DiscoveredRateLimit{25, time.Second}, true},
+		{"PerWindowHeaders", capacityErr("too many requests", map[string]interface{}{"x-ratelimit-limit-minute": "600", "x-ratelimit-limit-second": "15"}), DiscoveredRateLimit{15, time.Second}, true},
+		{"HeadersWinOverMessage", capacityErr("exceeded 50 requests per second", map[string]interface{}{"ratelimit-policy": "10;w=1"}), DiscoveredRateLimit{10, time.Second}, true},
+		{"Message", capacityErr("Your app has exceeded 25 requests per second", nil), DiscoveredRateLimit{25, time.Second}, true},
+		{"MessageShortUnit", capacityErr("requests limited to 300 req/min", nil), DiscoveredRateLimit{300, time.Minute}, true},
+		{"BareLimitHeaderIsIgnored", capacityErr("too many requests", map[string]interface{}{"x-ratelimit-limit": "100000"}), DiscoveredRateLimit{}, false},
+		{"MonthlyQuotaIsIgnored", capacityErr("exceeded 1000000 requests per month", nil), DiscoveredRateLimit{}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := DiscoverRateLimit(tc.err)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("RawHttpHeaders", func(t *testing.T) {
+		h := http.Header{}
+		h.Set("RateLimit-Policy", "5;w=1")
+		err := common.NewErrJsonRpcExceptionInternal(0, common.JsonRpcErrorServerSideException, "unknown", nil, map[string]interface{}{"headers": h})
+		got, ok := DiscoverRateLimit(err)
+		require.True(t, ok)
+		assert.Equal(t, DiscoveredRateLimit{5, time.Second}, got)
+	})
+}
+
+func TestDiscoveredRateLimit_MaxCountFor(t *testing.T) {
+	perMinute := DiscoveredRateLimit{Count: 600, Window: time.Minute}
+	assert.Equal(t, uint32(10), perMinute.MaxCountFor(common.RateLimitPeriodSecond))
+	assert.Equal(t, uint32(600), perMinute.MaxCountFor(common.RateLimitPeriodMinute))
+	assert.Equal(t, uint32(1), DiscoveredRateLimit{Count: 30, Window: time.Minute}.MaxCountFor(common.RateLimitPeriodSecond), "never below 1")
+}
+
+func TestRateLimitAutoTuner_LimitDiscovery(t *testing.T) {
+	logger := zerolog.Nop()
+	registry, err := NewRateLimitersRegistry(context.Background(), &common.RateLimiterConfig{
+		Store: &common.RateLimitStoreConfig{Driver: "memory"},
+		Budgets: []*common.RateLimitBudgetConfig{
+			{
+				Id: "provider",
+				Rules: []*common.RateLimitRuleConfig{
+					{Method: "*", MaxCount: 100, Period: common.RateLimitPeriodSecond},
+				},
+			},
+		},
+	}, &logger)
+	require.NoError(t, err)
+	budget, err := registry.GetBudget("provider")
+	require.NoError(t, err)
+	rule := budget.Rules[0]
+
+	tuner := NewRateLimitAutoTuner(&logger, budget, time.Hour, 0.1, 1.5, 0.95, 0, 1000)
+	tuner.EnableLimitDiscovery(time.Hour)
+
+	tuner.RecordRateLimited("eth_call", capacityErr("too many requests", map[string]interface{}{"ratelimit-policy": "1200;w=60"}))
+	assert.Equal(t, uint32(20), budget.RuleConfigMaxCount(rule), "lowered straight to the advertised limit")
+
+	// An error-free period would normally grow the budget by increaseFactor,
+	// but the discovered limit caps it while fresh.
+	tuner.mu.Lock()
+	tuner.lastAdjustments["eth_call"] = time.Now().Add(-2 * time.Hour)
+	tuner.mu.Unlock()
+	for i := 0; i < 20; i++ {
+		tuner.RecordSuccess("eth_call")
+	}
+	assert.Equal(t, uint32(20), budget.RuleConfigMaxCount(rule))
+
+	// Once the discovered limit expires the tuner probes upward again.
+	tuner.mu.Lock()
+	tuner.ceilings[rule] = discoveredCeiling{maxCount: 20, until: time.Now().Add(-time.Second)}
+	tuner.lastAdjustments["eth_call"] = time.Now().Add(-2 * time.Hour)
+	tuner.mu.Unlock()
+	for i := 0; i < 20; i++ {
+		tuner.RecordSuccess("eth_call")
+	}
+	assert.Equal(t, uint32(30), budget.RuleConfigMaxCount(rule))
+
+	t.Run("DisabledDiscoveryOnlyCountsErrors", func(t *testing.T) {
+		plain := NewRateLimitAutoTuner(&logger, budget, time.Hour, 0.1, 1.5, 0.95, 0, 1000)
+		plain.RecordRateLimited("eth_call", capacityErr("too many requests", map[string]interface{}{"ratelimit-policy": "60;w=60"}))
+		assert.Equal(t, uint32(30), budget.RuleConfigMaxCount(rule))
+	})
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"net"
+	"net/url"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -16,7 +19,6 @@ import (
 	"github.com/envoyproxy/ratelimit/src/redis"
 	"github.com/envoyproxy/ratelimit/src/settings"
 	"github.com/envoyproxy/ratelimit/src/stats"
-	"github.com/envoyproxy/ratelimit/src/utils"
 
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/data"
@@ -69,7 +71,7 @@ func (r *RateLimitersRegistry) bootstrap() error {
 	} else if r.cfg.Store != nil && r.cfg.Store.Driver == "memory" {
 		// Explicitly configured for memory
 		r.envoyCache = NewMemoryRateLimitCache(
-			utils.NewTimeSourceImpl(),
+			clockTimeSource{},
 			rand.New(rand.NewSource(time.Now().Unix())), // #nosec G404
 			0,
 			defaultNearLimitRatio(r.cfg.Store.NearLimitRatio),
@@ -105,13 +107,10 @@ func (r *RateLimitersRegistry) connectRedisTask(ctx context.Context) (err error)
 	store := gostats.NewStore(gostats.NewNullSink(), false)
 	mgr := stats.NewStatManager(store, settings.NewSettings())
 	useTLS := r.cfg.Store.Redis.TLS != nil && r.cfg.Store.Redis.TLS.Enabled
-	url := r.cfg.Store.Redis.URI
-	if url == "" {
-		url = r.cfg.Store.Redis.Addr
-	}
+	redisType, target, auth := envoyRedisTarget(r.cfg.Store.Redis)
 	poolSize := r.cfg.Store.Redis.ConnPoolSize
 
-	r.logger.Debug().Str("url", util.RedactEndpoint(url)).Bool("tls", useTLS).Int("poolSize", poolSize).Msg("attempting to connect to Redis for rate limiting")
+	r.logger.Debug().Str("url", util.RedactEndpoint(target)).Str("redisType", redisType).Bool("tls", useTLS).Int("poolSize", poolSize).Msg("attempting to connect to Redis for rate limiting")
 
 	var client redis.Client
 	if iam := r.cfg.Store.Redis.IAMAuth; iam != nil && iam.Enabled {
@@ -123,10 +122,10 @@ func (r *RateLimitersRegistry) connectRedisTask(ctx context.Context) (err error)
 		client = redis.NewClientImpl(
 			store.Scope("erpc_rl"),
 			useTLS,
-			r.cfg.Store.Redis.Username,
+			auth,
 			"tcp",
-			"single",
-			url,
+			redisType,
+			target,
 			poolSize,
 			5*time.Millisecond,
 			32,
@@ -139,7 +138,7 @@ func (r *RateLimitersRegistry) connectRedisTask(ctx context.Context) (err error)
 	cache := redis.NewFixedRateLimitCacheImpl(
 		client,
 		nil,
-		utils.NewTimeSourceImpl(),
+		clockTimeSource{},
 		rand.New(rand.NewSource(time.Now().UnixNano())), // #nosec G404
 		5,
 		nil,
@@ -156,10 +155,50 @@ func (r *RateLimitersRegistry) connectRedisTask(ctx context.Context) (err error)
 	r.envoyCache = cache
 	r.cacheMu.Unlock()
 
-	r.logger.Info().Str("url", util.RedactEndpoint(url)).Msg("successfully connected to Redis for rate limiting")
+	r.logger.Info().Str("url", util.RedactEndpoint(target)).Msg("successfully connected to Redis for rate limiting")
 	return nil
 }
 
+// envoyRedisTarget returns the redis type, url and auth the envoy rate limit
+// client dials for cfg. A single server is dialed by its URI. Cluster nodes
+// ("host:port,…") and the master found through sentinel ("master,sentinel,…")
+// are dialed by address, so they get the credentials of the URI as auth.
+func envoyRedisTarget(cfg *common.RedisConnectorConfig) (redisType, target, auth string) {
+	if cfg.Cluster == nil && cfg.Sentinel == nil {
+		target = cfg.URI
+		if target == "" {
+			target = cfg.Addr
+		}
+		return "single", target, cfg.Username
+	}
+
+	var host string
+	if u, err := url.Parse(cfg.URI); err == nil {
+		host = u.Host
+		if host != "" && u.Port() == "" {
+			host = net.JoinHostPort(host, "6379")
+		}
+		if u.User != nil {
+			auth = u.User.Username()
+			if pw, ok := u.User.Password(); ok {
+				if auth != "" {
+					auth += ":" + pw
+				} else {
+					auth = pw
+				}
+			}
+		}
+	}
+	if cfg.Sentinel != nil {
+		return "sentinel", strings.Join(append([]string{cfg.Sentinel.MasterName}, cfg.Sentinel.Addrs...), ","), auth
+	}
+	addrs := append([]string{}, cfg.Cluster.Addrs...)
+	if host != "" && !slices.Contains(addrs, host) {
+		addrs = append(addrs, host)
+	}
+	return "cluster", strings.Join(addrs, ","), auth
+}
+
 // initializeBudgets creates the rate limiter budgets
 func (r *RateLimitersRegistry) initializeBudgets() {
 	for _, budgetCfg := range r.cfg.Budgets {
@@ -234,6 +273,9 @@ func (r *RateLimitersRegistry) GetBudget(budgetId string) (*RateLimiterBudget, e
 }
 
 func (r *RateLimitersRegistry) GetBudgets() []*common.RateLimitBudgetConfig {
+	if r.cfg == nil {
+		return nil
+	}
 	return r.cfg.Budgets
 }
 
@@ -256,6 +298,24 @@ func (r *RateLimitersRegistry) AdjustBudget(budgetId string, method string, newM
 	return nil
 }
 
+// SetBudgetMaxCount sets MaxCount of a budget's rules at runtime (see
+// RateLimiterBudget.SetMaxCount). It fails when the budget does not exist or
+// no rule matches method.
+func (r *RateLimitersRegistry) SetBudgetMaxCount(budgetId string, method string, maxCount uint32) ([]RuleMaxCountChange, error) {
+	budget, err := r.GetBudget(budgetId)
+	if err != nil {
+		return nil, err
+	}
+	if budget == nil {
+		return nil, fmt.Errorf("budget id is required")
+	}
+	changes := budget.SetMaxCount(method, maxCount)
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("budget '%s' has no rule for method '%s'", budgetId, method)
+	}
+	return changes, nil
+}
+
 func defaultNearLimitRatio(val float32) float32 {
 	if val > 0 && val < 1 {
 		return val
@@ -298,3 +358,9 @@ func remoteAdmissionCap(connPoolSize int) int {
 	}
 	return cap
 }
+
+// clockTimeSource feeds envoy's fixed windows from the process-wide clock, so
+// a util.VirtualClock also drives rate limit budgets.
+type clockTimeSource struct{}
+
+func (clockTimeSource) UnixNow() int64 { return util.Now().Unix() }
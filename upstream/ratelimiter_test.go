@@ -134,6 +134,62 @@ func TestRateLimiterBudget_GetRulesByMethod(t *testing.T) {
 	})
 }
 
+func TestRateLimitersRegistry_SetBudgetMaxCount(t *testing.T) {
+	logger := zerolog.Nop()
+	cfg := &common.RateLimiterConfig{
+		Store: &common.RateLimitStoreConfig{Driver: "memory"},
+		Budgets: []*common.RateLimitBudgetConfig{
+			{
+				Id: "test-budget",
+				Rules: []*common.RateLimitRuleConfig{
+					{
+						Method:   "eth_*",
+						MaxCount: 10,
+						Period:   common.RateLimitPeriodSecond,
+					},
+					{
+						Method:   "eth_getLogs",
+						MaxCount: 5,
+						Period:   common.RateLimitPeriodSecond,
+						PerIP:    true,
+					},
+				},
+			},
+		},
+	}
+	registry, err := NewRateLimitersRegistry(context.Background(), cfg, &logger)
+	require.NoError(t, err)
+	budget, err := registry.GetBudget("test-budget")
+	require.NoError(t, err)
+
+	t.Run("method pattern is matched exactly", func(t *testing.T) {
+		changes, err := registry.SetBudgetMaxCount("test-budget", "eth_getLogs", 50)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, RuleMaxCountChange{Method: "eth_getLogs", Scope: "ip", PreviousMaxCount: 5, MaxCount: 50}, changes[0])
+		assert.Equal(t, []uint32{10, 50}, []uint32{budget.RuleConfigs()[0].MaxCount, budget.RuleConfigs()[1].MaxCount})
+	})
+
+	t.Run("empty method sets every rule", func(t *testing.T) {
+		changes, err := registry.SetBudgetMaxCount("test-budget", "", 7)
+		require.NoError(t, err)
+		assert.Len(t, changes, 2)
+		for _, rc := range budget.RuleConfigs() {
+			assert.Equal(t, uint32(7), rc.MaxCount)
+		}
+	})
+
+	t.Run("no matching rule", func(t *testing.T) {
+		_, err := registry.SetBudgetMaxCount("test-budget", "eth_call", 1)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown budget", func(t *testing.T) {
+		_, err := registry.SetBudgetMaxCount("non-existing", "", 1)
+		assert.IsType(t, &common.ErrRateLimitBudgetNotFound{}, err)
+	})
+}
+
 func TestRateLimiter_ConcurrentPermits(t *testing.T) {
 	t.Skip("Concurrent permits test skipped pending stabilization of Envoy-based memory limiter semantics")
 	logger := zerolog.Nop()
@@ -232,3 +288,92 @@ func TestRateLimiter_ExceedCapacity(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, ok)
 }
+
+type fakeRateLimitClock struct{ now int64 }
+
+func (c *fakeRateLimitClock) UnixNow() int64 { return c.now }
+
+func TestRateLimiterBudget_SmoothingWindow(t *testing.T) {
+	logger := zerolog.Nop()
+	second := common.RateLimitPeriodSecond
+	cfg := &common.RateLimiterConfig{
+		Store: &common.RateLimitStoreConfig{Driver: "memory"},
+		Budgets: []*common.RateLimitBudgetConfig{
+			{
+				Id: "test-budget",
+				Rules: []*common.RateLimitRuleConfig{
+					{
+						Method:          "eth_getLogs",
+						MaxCount:        120,
+						Period:          common.RateLimitPeriodMinute,
+						SmoothingWindow: &second,
+						Burst:           1,
+					},
+				},
+			},
+		},
+	}
+	registry, err := NewRateLimitersRegistry(context.Background(), cfg, &logger)
+	require.NoError(t, err)
+
+	// Start mid-minute so the sub-windows below stay within one minute window.
+	clock := &fakeRateLimitClock{now: 1_700_000_040}
+	registry.envoyCache = NewMemoryRateLimitCache(clock, nil, 0, 0.8, "test_", registry.statsManager)
+
+	budget, err := registry.GetBudget("test-budget")
+	require.NoError(t, err)
+	acquire := func() bool {
+		ok, err := budget.TryAcquirePermit(context.Background(), "", nil, "eth_getLogs", "", "", "", "upstream")
+		require.NoError(t, err)
+		return ok
+	}
+
+	// 120/minute spread over seconds is 2/s, plus a burst of 1.
+	for i := 0; i < 3; i++ {
+		assert.True(t, acquire(), "request %d within the smoothed second", i)
+	}
+	assert.False(t, acquire(), "a per-minute quota must not be spent within one second")
+
+	clock.now++
+	assert.True(t, acquire(), "the next second admits again")
+
+	t.Run("main window still applies", func(t *testing.T) {
+		rules, err := budget.GetRulesByMethod("eth_getLogs")
+		require.NoError(t, err)
+		require.NoError(t, budget.AdjustBudget(rules[0], 5))
+		clock.now++
+		// 5/minute rounds up to 1/s, plus burst: 2 per second, but only 5 per
+		// minute in total and 5 hits were already counted this minute.
+		assert.False(t, acquire())
+	})
+}
+
+func TestEnvoyRedisTarget(t *testing.T) {
+	single := &common.RedisConnectorConfig{Addr: "redis:6379", Username: "app", Password: "secret"}
+	require.NoError(t, single.SetDefaults())
+	typ, target, auth := envoyRedisTarget(single)
+	assert.Equal(t, "single", typ)
+	assert.Equal(t, single.URI, target)
+	assert.Empty(t, auth, "single servers take their credentials from the uri")
+
+	cluster := &common.RedisConnectorConfig{
+		Username: "app",
+		Password: "secret",
+		Cluster:  &common.RedisClusterConfig{Addrs: []string{"n1:7000", "n2:7000"}},
+	}
+	require.NoError(t, cluster.SetDefaults())
+	typ, target, auth = envoyRedisTarget(cluster)
+	assert.Equal(t, "cluster", typ)
+	assert.Equal(t, "n1:7000,n2:7000", target)
+	assert.Equal(t, "app:secret", auth)
+
+	sentinel := &common.RedisConnectorConfig{
+		URI:      "redis://:secret@ignored:6379/0",
+		Sentinel: &common.RedisSentinelConfig{MasterName: "mymaster", Addrs: []string{"s1:26379", "s2:26379"}},
+	}
+	require.NoError(t, sentinel.SetDefaults())
+	typ, target, auth = envoyRedisTarget(sentinel)
+	assert.Equal(t, "sentinel", typ)
+	assert.Equal(t, "mymaster,s1:26379,s2:26379", target)
+	assert.Equal(t, "secret", auth)
+}
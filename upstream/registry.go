@@ -109,6 +109,11 @@ func (u *UpstreamsRegistry) Bootstrap(ctx context.Context) {
 			u.logger.Info().Msg("upstreams registration completed")
 		}
 	}()
+	if u.sharedStateRegistry != nil {
+		if cfg := u.sharedStateRegistry.GetRoutingConfig(); cfg != nil {
+			go newClusterRouting(u, u.sharedStateRegistry, cfg).run(u.appCtx)
+		}
+	}
 }
 
 func (u *UpstreamsRegistry) NewUpstream(cfg *common.UpstreamConfig) (*Upstream, error) {
@@ -525,7 +530,7 @@ func (u *UpstreamsRegistry) buildProviderBootstrapTask(
 			if err != nil {
 				return err
 			}
-			if lg.GetLevel() <= zerolog.DebugLevel {
+			if common.LogLevelEnabled(&lg, zerolog.DebugLevel) {
 				lg.Debug().Interface("upstreams", upsCfgs).Msgf("created %d upstream(s) from provider", len(upsCfgs))
 			} else {
 				lg.Info().Msgf("registering %d upstream(s) from provider", len(upsCfgs))
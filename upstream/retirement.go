@@ -0,0 +1,277 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+)
+
+// retirementPersistInterval bounds how often an unchanged failure streak is
+// written to shared state; starting or ending a streak is written at once.
+const retirementPersistInterval = 30 * time.Second
+
+// retirementTracker keeps the failure history used to decide retirement. The
+// history is also written to shared state, so a restarted replica resumes the
+// DeadFor period instead of starting a fresh one.
+type retirementTracker struct {
+	mu            sync.Mutex
+	lastSuccessAt time.Time
+	failingSince  time.Time
+	failures      int64
+	// resetAt is when Unretire last cleared the history; older history of
+	// other replicas is ignored on restore.
+	resetAt     time.Time
+	persistedAt time.Time
+
+	// persistMu orders writes to shared state and holds them back until the
+	// history of the previous run has been restored.
+	persistMu sync.Mutex
+}
+
+// retirementRecord is one replica's failure history of an upstream, as kept
+// in shared state.
+type retirementRecord struct {
+	LastSuccessAt time.Time `json:"lastSuccessAt,omitempty"`
+	FailingSince  time.Time `json:"failingSince,omitempty"`
+	Failures      int64     `json:"failures"`
+	ResetAt       time.Time `json:"resetAt,omitempty"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Retired reports whether the upstream was retired for being dead for its
+// configured retirement period.
+func (u *Upstream) Retired() bool {
+	return u.retired.Load() != nil
+}
+
+// RetirementState returns the failure history and, when retired, the drain
+// state applied by the retirement. It is nil if retirement is not enabled.
+func (u *Upstream) RetirementState() *common.UpstreamRetirementState {
+	t := u.retirementTracker()
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	st := &common.UpstreamRetirementState{
+		LastSuccessAt: t.lastSuccessAt,
+		FailingSince:  t.failingSince,
+		Failures:      t.failures,
+	}
+	t.mu.Unlock()
+	st.Retired = u.retired.Load()
+	return st
+}
+
+// Unretire puts a retired upstream back into routing and health probing and
+// starts its failure history over, so it gets a full DeadFor period again.
+func (u *Upstream) Unretire() bool {
+	st := u.retired.Swap(nil)
+	if st == nil {
+		return false
+	}
+	if t := u.retirementTracker(); t != nil {
+		t.mu.Lock()
+		t.failingSince = time.Time{}
+		t.failures = 0
+		t.resetAt = time.Now()
+		t.persistedAt = t.resetAt
+		t.mu.Unlock()
+		u.persistRetirement(t)
+	}
+	telemetry.MetricUpstreamRetired.WithLabelValues(u.ProjectId, u.NetworkLabel(), u.config.Id).Set(0)
+	u.logger.Info().Time("retiredAt", st.Since).Msg("upstream unretired; accepting new requests")
+	return true
+}
+
+func (u *Upstream) recordRetirementSuccess() {
+	t := u.retirementTracker()
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.mu.Lock()
+	persist := t.failures > 0 || now.Sub(t.persistedAt) >= retirementPersistInterval
+	t.lastSuccessAt = now
+	t.failingSince = time.Time{}
+	t.failures = 0
+	if persist {
+		t.persistedAt = now
+	}
+	t.mu.Unlock()
+	if persist {
+		u.persistRetirement(t)
+	}
+}
+
+// recordRetirementFailure retires the upstream once it has failed at least
+// MinFailures requests and not served any successfully for DeadFor.
+func (u *Upstream) recordRetirementFailure() {
+	t := u.retirementTracker()
+	if t == nil || u.Retired() {
+		return
+	}
+	cfg := u.config.Retirement
+	now := time.Now()
+	t.mu.Lock()
+	if t.failures == 0 {
+		t.failingSince = now
+	}
+	t.failures++
+	dead := t.failures >= cfg.MinFailures && now.Sub(t.failingSince) >= cfg.DeadFor.Duration()
+	failingSince, failures := t.failingSince, t.failures
+	persist := failures == 1 || now.Sub(t.persistedAt) >= retirementPersistInterval
+	if persist {
+		t.persistedAt = now
+	}
+	t.mu.Unlock()
+	if persist {
+		u.persistRetirement(t)
+	}
+	if !dead {
+		return
+	}
+
+	st := &common.UpstreamDrainState{
+		Mode:   common.UpstreamDrainModeRetired,
+		Reason: fmt.Sprintf("no successful request since %s (%d failures)", failingSince.UTC().Format(time.RFC3339), failures),
+		Source: "retirement",
+		Since:  now,
+	}
+	// Concurrent failures may all cross the threshold; only the first one
+	// retires the upstream. upstreamDown alert rules pick it up from the
+	// retired gauge.
+	if !u.retired.CompareAndSwap(nil, st) {
+		return
+	}
+	telemetry.MetricUpstreamRetired.WithLabelValues(u.ProjectId, u.NetworkLabel(), u.config.Id).Set(1)
+	u.logger.Warn().
+		Time("failingSince", failingSince).
+		Int64("failures", failures).
+		Dur("deadFor", cfg.DeadFor.Duration()).
+		Msg("upstream retired after failing every request for its retirement period; use erpc_unretireUpstream to reinstate it")
+}
+
+func (u *Upstream) retirementTracker() *retirementTracker {
+	cfg := u.config.Retirement
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	u.retirementOnce.Do(func() {
+		t := &retirementTracker{}
+		if u.sharedStateRegistry != nil {
+			t.persistMu.Lock()
+			go func() {
+				defer t.persistMu.Unlock()
+				u.restoreRetirement(t)
+			}()
+		}
+		u.retirement = t
+	})
+	return u.retirement
+}
+
+func (u *Upstream) retirementStateKey() string {
+	return "retirement/" + common.UniqueUpstreamKey(u)
+}
+
+// persistRetirement writes this replica's failure history to shared state in
+// the background. Each write takes the history as it is when the write runs,
+// so the last one always carries the latest state.
+func (u *Upstream) persistRetirement(t *retirementTracker) {
+	if u.sharedStateRegistry == nil {
+		return
+	}
+	go func() {
+		t.persistMu.Lock()
+		defer t.persistMu.Unlock()
+		t.mu.Lock()
+		rec := retirementRecord{
+			LastSuccessAt: t.lastSuccessAt,
+			FailingSince:  t.failingSince,
+			Failures:      t.failures,
+			ResetAt:       t.resetAt,
+			UpdatedAt:     time.Now(),
+		}
+		t.mu.Unlock()
+		value, err := common.SonicCfg.Marshal(rec)
+		if err != nil {
+			return
+		}
+		// Kept for two DeadFor periods so a long outage of the replicas
+		// doesn't drop a streak that is about to retire the upstream.
+		ttl := 2 * u.config.Retirement.DeadFor.Duration()
+		if err := u.sharedStateRegistry.PutInstanceState(u.appCtx, u.retirementStateKey(), value, ttl); err != nil {
+			u.logger.Debug().Err(err).Msg("failed to persist upstream retirement history")
+		}
+	}()
+}
+
+// restoreRetirement merges the failure histories that replicas (this one's
+// previous run included) left in shared state into t. A success or an
+// unretire seen by any replica ends the streaks before it; the oldest streak
+// after that is resumed, with the largest failure count among the replicas.
+func (u *Upstream) restoreRetirement(t *retirementTracker) {
+	ctx, cancel := context.WithTimeout(u.appCtx, 10*time.Second)
+	defer cancel()
+	states, err := u.sharedStateRegistry.GetInstanceStates(ctx, u.retirementStateKey())
+	if err != nil {
+		u.logger.Debug().Err(err).Msg("failed to restore upstream retirement history")
+		return
+	}
+	records := make([]retirementRecord, 0, len(states))
+	var lastSuccessAt, boundary time.Time
+	for _, raw := range states {
+		var rec retirementRecord
+		if err := common.SonicCfg.Unmarshal(raw, &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+		if rec.LastSuccessAt.After(lastSuccessAt) {
+			lastSuccessAt = rec.LastSuccessAt
+		}
+		if rec.LastSuccessAt.After(boundary) {
+			boundary = rec.LastSuccessAt
+		}
+		if rec.ResetAt.After(boundary) {
+			boundary = rec.ResetAt
+		}
+	}
+	var failingSince time.Time
+	var failures int64
+	for _, rec := range records {
+		if rec.Failures == 0 || rec.UpdatedAt.Before(boundary) {
+			continue
+		}
+		since := rec.FailingSince
+		if since.Before(boundary) {
+			since = boundary
+		}
+		if failingSince.IsZero() || since.Before(failingSince) {
+			failingSince = since
+		}
+		if rec.Failures > failures {
+			failures = rec.Failures
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// A success or unretire on this replica since startup ends the restored
+	// streak.
+	settledLocally := !t.lastSuccessAt.IsZero() || !t.resetAt.IsZero()
+	if lastSuccessAt.After(t.lastSuccessAt) {
+		t.lastSuccessAt = lastSuccessAt
+	}
+	if failures == 0 || settledLocally {
+		return
+	}
+	if t.failures == 0 || failingSince.Before(t.failingSince) {
+		t.failingSince = failingSince
+	}
+	t.failures += failures
+	u.logger.Info().Time("failingSince", t.failingSince).Int64("failures", t.failures).Msg("restored upstream retirement history from shared state")
+}
@@ -0,0 +1,198 @@
+package upstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	promUtil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRetirementTestUpstream(retirement *common.UpstreamRetirementConfig) *Upstream {
+	return &Upstream{
+		ProjectId: "test",
+		appCtx:    context.Background(),
+		config:    &common.UpstreamConfig{Id: "rpc1", Retirement: retirement},
+		logger:    &log.Logger,
+	}
+}
+
+func TestUpstream_Retirement(t *testing.T) {
+	t.Run("RetiresAfterDeadForAndMinFailures", func(t *testing.T) {
+		u := newRetirementTestUpstream(&common.UpstreamRetirementConfig{
+			Enabled:     true,
+			DeadFor:     common.Duration(20 * time.Millisecond),
+			MinFailures: 3,
+		})
+		u.recordRetirementFailure()
+		u.recordRetirementFailure()
+		u.recordRetirementFailure()
+		assert.False(t, u.Retired(), "failures within deadFor do not retire")
+
+		time.Sleep(25 * time.Millisecond)
+		u.recordRetirementFailure()
+		require.True(t, u.Retired())
+		st := u.DrainState()
+		require.NotNil(t, st)
+		assert.Equal(t, common.UpstreamDrainModeRetired, st.Mode)
+		assert.Equal(t, "retirement", st.Source)
+
+		_, skip := u.shouldSkip(context.Background(), common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`)))
+		assert.True(t, skip, "a retired upstream is excluded from routing")
+
+		rs := u.RetirementState()
+		require.NotNil(t, rs.Retired)
+		assert.Equal(t, int64(4), rs.Failures)
+	})
+
+	t.Run("MinFailuresGuardsIdleUpstreams", func(t *testing.T) {
+		u := newRetirementTestUpstream(&common.UpstreamRetirementConfig{
+			Enabled:     true,
+			DeadFor:     common.Duration(time.Millisecond),
+			MinFailures: 5,
+		})
+		u.recordRetirementFailure()
+		time.Sleep(2 * time.Millisecond)
+		u.recordRetirementFailure()
+		assert.False(t, u.Retired())
+	})
+
+	t.Run("SuccessResetsHistory", func(t *testing.T) {
+		u := newRetirementTestUpstream(&common.UpstreamRetirementConfig{
+			Enabled:     true,
+			DeadFor:     common.Duration(10 * time.Millisecond),
+			MinFailures: 1,
+		})
+		u.recordRetirementFailure()
+		time.Sleep(15 * time.Millisecond)
+		u.recordRetirementSuccess()
+		u.recordRetirementFailure()
+		assert.False(t, u.Retired(), "the dead period restarts after a success")
+		assert.False(t, u.RetirementState().LastSuccessAt.IsZero())
+	})
+
+	t.Run("UnretireRestoresRoutingAndRestartsHistory", func(t *testing.T) {
+		u := newRetirementTestUpstream(&common.UpstreamRetirementConfig{
+			Enabled:     true,
+			DeadFor:     common.Duration(time.Millisecond),
+			MinFailures: 1,
+		})
+		u.recordRetirementFailure()
+		time.Sleep(2 * time.Millisecond)
+		u.recordRetirementFailure()
+		require.True(t, u.Retired())
+		assert.False(t, u.Undrain(), "undrain does not lift a retirement")
+		assert.True(t, u.Retired())
+
+		assert.True(t, u.Unretire())
+		assert.False(t, u.Retired())
+		assert.Nil(t, u.DrainState())
+		assert.Equal(t, int64(0), u.RetirementState().Failures)
+		assert.False(t, u.Unretire())
+	})
+
+	t.Run("DisabledKeepsNoHistory", func(t *testing.T) {
+		u := newRetirementTestUpstream(nil)
+		u.recordRetirementFailure()
+		assert.Nil(t, u.RetirementState())
+		assert.False(t, u.Retired())
+	})
+
+	t.Run("ExportsRetiredGaugeForAlerting", func(t *testing.T) {
+		u := newRetirementTestUpstream(&common.UpstreamRetirementConfig{
+			Enabled:     true,
+			DeadFor:     common.Duration(time.Millisecond),
+			MinFailures: 1,
+		})
+		retired := telemetry.MetricUpstreamRetired.WithLabelValues("test", u.NetworkLabel(), "rpc1")
+		u.recordRetirementFailure()
+		time.Sleep(2 * time.Millisecond)
+		u.recordRetirementFailure()
+		require.True(t, u.Retired())
+		assert.Equal(t, 1.0, promUtil.ToFloat64(retired), "upstreamDown alert rules notify sinks from this gauge")
+
+		require.True(t, u.Unretire())
+		assert.Equal(t, 0.0, promUtil.ToFloat64(retired))
+	})
+}
+
+func TestUpstream_RetirementSharedState(t *testing.T) {
+	cfg := &common.UpstreamRetirementConfig{
+		Enabled:     true,
+		DeadFor:     common.Duration(50 * time.Millisecond),
+		MinFailures: 1,
+	}
+	// Each call is a replica (or a restarted process) with its own instance id.
+	replica := func(store *clusterRoutingTestStore, id string) *Upstream {
+		u := newRetirementTestUpstream(cfg)
+		u.networkId.Store("evm:1")
+		u.sharedStateRegistry = &clusterRoutingTestSSR{store: store, id: id}
+		return u
+	}
+	persisted := func(t *testing.T, store *clusterRoutingTestStore, u *Upstream, id string) retirementRecord {
+		t.Helper()
+		var rec retirementRecord
+		require.Eventually(t, func() bool {
+			store.mu.Lock()
+			raw := store.states[u.retirementStateKey()][id]
+			store.mu.Unlock()
+			return raw != nil && common.SonicCfg.Unmarshal(raw, &rec) == nil
+		}, time.Second, time.Millisecond)
+		return rec
+	}
+	restored := func(t *testing.T, u *Upstream) *common.UpstreamRetirementState {
+		t.Helper()
+		u.retirementTracker()
+		// Restoring holds back persisting; a write going through means it is done.
+		u.retirement.persistMu.Lock()
+		u.retirement.persistMu.Unlock() //nolint:staticcheck // SA2001: waits for the restore to release it
+		return u.RetirementState()
+	}
+
+	t.Run("RestartResumesDeadForPeriod", func(t *testing.T) {
+		store := &clusterRoutingTestStore{states: map[string]map[string][]byte{}}
+		first := replica(store, "pod-a")
+		first.recordRetirementFailure()
+		rec := persisted(t, store, first, "pod-a")
+		assert.Equal(t, int64(1), rec.Failures)
+		assert.False(t, rec.FailingSince.IsZero())
+
+		time.Sleep(60 * time.Millisecond)
+		restarted := replica(store, "pod-b")
+		st := restored(t, restarted)
+		assert.Equal(t, int64(1), st.Failures)
+		assert.WithinDuration(t, rec.FailingSince, st.FailingSince, time.Millisecond)
+		restarted.recordRetirementFailure()
+		assert.True(t, restarted.Retired(), "the restored streak already covers deadFor")
+
+		// Unretiring is persisted too, so the next restart starts over.
+		require.True(t, restarted.Unretire())
+		require.Eventually(t, func() bool {
+			return !persisted(t, store, restarted, "pod-b").ResetAt.IsZero()
+		}, time.Second, time.Millisecond)
+		again := replica(store, "pod-c")
+		assert.Equal(t, int64(0), restored(t, again).Failures)
+	})
+
+	t.Run("SuccessOnAnotherReplicaEndsStreak", func(t *testing.T) {
+		store := &clusterRoutingTestStore{states: map[string]map[string][]byte{}}
+		failing := replica(store, "pod-a")
+		failing.recordRetirementFailure()
+		persisted(t, store, failing, "pod-a")
+
+		time.Sleep(2 * time.Millisecond)
+		healthy := replica(store, "pod-b")
+		healthy.recordRetirementSuccess()
+		persisted(t, store, healthy, "pod-b")
+
+		st := restored(t, replica(store, "pod-c"))
+		assert.Equal(t, int64(0), st.Failures)
+		assert.True(t, st.FailingSince.IsZero())
+		assert.False(t, st.LastSuccessAt.IsZero())
+	})
+}
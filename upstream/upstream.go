@@ -188,6 +188,18 @@ type Upstream struct {
 	statePollerOnce      sync.Once
 	// True after successful chainId detection/validation; enables short-circuit in EvmGetChainId.
 	chainIdValidated atomic.Bool
+	// Node client reported by web3_clientVersion; nil until detected.
+	nodeClient atomic.Pointer[common.NodeClient]
+
+	manualDrain       atomic.Pointer[common.UpstreamDrainState]
+	maintenanceWindow atomic.Pointer[maintenanceWindowCache]
+
+	canary     *canaryRollout
+	canaryOnce sync.Once
+
+	retired        atomic.Pointer[common.UpstreamDrainState]
+	retirement     *retirementTracker
+	retirementOnce sync.Once
 }
 
 func NewUpstream(
@@ -201,7 +213,8 @@ func NewUpstream(
 	mt *health.Tracker,
 	ssr data.SharedStateRegistry,
 ) (*Upstream, error) {
-	lg := logger.With().Str("upstreamId", cfg.Id).Logger()
+	lg := logger.With().Str("upstreamId", cfg.Id).Logger().
+		Sample(common.LogLevels.Sampler(common.LogTarget{Kind: common.LogTargetUpstream, Id: cfg.Id}))
 
 	// Build one upstreamExecutor per Failsafe config entry, plus a no-op
 	// catch-all so unmatched (method, finality) pairs always resolve.
@@ -387,18 +400,27 @@ func (u *Upstream) Tracker() common.HealthTracker {
 // single canonical state is what the operator wants — we surface the
 // catch-all because that's what serves the vast majority of methods.
 func (u *Upstream) CircuitBreakerState() failsafe.State {
+	return u.wildcardBreaker().State()
+}
+
+// CircuitBreakerOpenWindow returns when the upstream-wide circuit breaker
+// opened and when it half-opens; ok is false unless it is open.
+func (u *Upstream) CircuitBreakerOpenWindow() (since, until time.Time, ok bool) {
+	return u.wildcardBreaker().OpenWindow()
+}
+
+// wildcardBreaker is the breaker of the executor matching every method and
+// finality, or nil, which reads as a closed breaker.
+func (u *Upstream) wildcardBreaker() *failsafe.Breaker {
 	if u == nil {
-		return failsafe.StateClosed
+		return nil
 	}
 	for _, fe := range u.failsafeExecutors {
 		if fe.MatchMethod() == "*" && len(fe.MatchFinality()) == 0 {
-			if br := fe.Breaker(); br != nil {
-				return br.State()
-			}
-			return failsafe.StateClosed
+			return fe.Breaker()
 		}
 	}
-	return failsafe.StateClosed
+	return nil
 }
 
 func (u *Upstream) Logger() *zerolog.Logger {
@@ -476,6 +498,37 @@ func (u *Upstream) getFailsafeExecutor(req *common.NormalizedRequest) *upstreamE
 	return nil
 }
 
+// sendRequest sends the request via the upstream's client, applying this
+// upstream's methodRewrites (if any) on a derived copy so that the original
+// request — and thus cache keys and other upstreams — is left untouched.
+func (u *Upstream) sendRequest(ctx context.Context, nrq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	cfg := u.Config()
+	if len(cfg.MethodRewrites) == 0 {
+		return u.Client.SendRequest(ctx, nrq)
+	}
+	method, err := nrq.Method()
+	if err != nil {
+		return nil, err
+	}
+	rule := common.FindMethodRewrite(cfg.MethodRewrites, method)
+	if rule == nil {
+		return u.Client.SendRequest(ctx, nrq)
+	}
+	sendRq, err := nrq.WithMethodRewrite(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	nrs, err := u.Client.SendRequest(ctx, sendRq)
+	if err != nil || nrs == nil {
+		return nrs, err
+	}
+	nrs.WithRequest(nrq)
+	if err := rule.UnwrapResponse(ctx, nrs); err != nil {
+		return nil, common.NewErrUpstreamRequest(err, u, u.NetworkId(), method, 0, 0, 0, 0)
+	}
+	return nrs, nil
+}
+
 func (u *Upstream) Forward(ctx context.Context, nrq *common.NormalizedRequest, byPassMethodExclusion, isHedgeAttempt bool) (*common.NormalizedResponse, error) {
 	// TODO Should we move byPassMethodExclusion to directives? How do we prevent clients from setting it?
 	startTime := time.Now()
@@ -534,6 +587,9 @@ func (u *Upstream) Forward(ctx context.Context, nrq *common.NormalizedRequest, b
 	}
 
 	lg := u.logger.With().Str("method", method).Str("networkId", u.NetworkId()).Interface("id", nrq.ID()).Logger()
+	if cid := common.CorrelationIdFromContext(ctx); cid != "" {
+		lg = lg.With().Str("correlationId", cid).Logger()
+	}
 
 	if limitersBudget != nil {
 		lg.Trace().Str("budget", cfg.RateLimitBudget).Msgf("checking upstream-level rate limiters budget")
@@ -707,7 +763,7 @@ func (u *Upstream) Forward(ctx context.Context, nrq *common.NormalizedRequest, b
 					attribute.String("client.type", string(clientType)),
 				),
 			)
-			nrs, errCall := u.Client.SendRequest(ctx, nrq)
+			nrs, errCall := u.sendRequest(ctx, nrq)
 			sendSpan.End()
 			isSuccess := false
 			if errCall == nil && nrs != nil {
@@ -739,7 +795,7 @@ func (u *Upstream) Forward(ctx context.Context, nrq *common.NormalizedRequest, b
 				lg.Debug().Err(errCall).Object("response", nrs).Msgf("upstream request ended with non-nil response")
 			} else {
 				if errCall != nil {
-					if lg.GetLevel() == zerolog.TraceLevel && errors.Is(errCall, context.Canceled) {
+					if common.LogLevelEnabled(&lg, zerolog.TraceLevel) && errors.Is(errCall, context.Canceled) {
 						lg.Trace().Err(errCall).Msgf("upstream request ended due to context cancellation")
 					} else {
 						lg.Debug().Err(errCall).Msgf("upstream request ended with error")
@@ -787,9 +843,11 @@ func (u *Upstream) Forward(ctx context.Context, nrq *common.NormalizedRequest, b
 					// upstreams that never win the hedge race.
 				} else {
 					if common.HasErrorCode(errCall, common.ErrCodeEndpointCapacityExceeded) {
-						u.recordRemoteRateLimit(ctx, method, nrq)
+						u.recordRemoteRateLimit(ctx, method, nrq, errCall)
 					}
-					if !hedgeAttempt {
+					// Failures of requests still in flight on a drained upstream are
+					// expected and must not penalize it once it is back.
+					if !hedgeAttempt && u.DrainState() == nil {
 						u.metricsTracker.RecordUpstreamFailure(
 							u,
 							method,
@@ -797,6 +855,13 @@ func (u *Upstream) Forward(ctx context.Context, nrq *common.NormalizedRequest, b
 							errCall,
 						)
 					}
+					// A revert means the node executed the call, so it counts as
+					// alive for retirement purposes.
+					if common.HasErrorCode(errCall, common.ErrCodeEndpointExecutionException) {
+						u.recordRetirementSuccess()
+					} else if u.DrainState() == nil {
+						u.recordRetirementFailure()
+					}
 					severity := common.ClassifySeverity(errCall)
 					telemetry.MetricUpstreamErrorTotal.WithLabelValues(
 						u.ProjectId,
@@ -881,6 +946,7 @@ func (u *Upstream) Forward(ctx context.Context, nrq *common.NormalizedRequest, b
 
 			if isSuccess {
 				u.recordRequestSuccess(method)
+				u.recordRetirementSuccess()
 			}
 
 			return nrs, nil
@@ -1409,6 +1475,9 @@ func (u *Upstream) initRateLimitAutoTuner() {
 					cfg.MinBudget,
 					cfg.MaxBudget,
 				)
+				if cfg.DiscoverLimits != nil && *cfg.DiscoverLimits {
+					u.rateLimiterAutoTuner.EnableLimitDiscovery(cfg.DiscoveredLimitTtl.Duration())
+				}
 			}
 		}
 	}
@@ -1420,7 +1489,7 @@ func (u *Upstream) recordRequestSuccess(method string) {
 	}
 }
 
-func (u *Upstream) recordRemoteRateLimit(ctx context.Context, method string, nrq *common.NormalizedRequest) {
+func (u *Upstream) recordRemoteRateLimit(ctx context.Context, method string, nrq *common.NormalizedRequest, err error) {
 	u.metricsTracker.RecordUpstreamRemoteRateLimited(
 		ctx,
 		u,
@@ -1429,7 +1498,7 @@ func (u *Upstream) recordRemoteRateLimit(ctx context.Context, method string, nrq
 	)
 
 	if u.rateLimiterAutoTuner != nil {
-		u.rateLimiterAutoTuner.RecordError(method)
+		u.rateLimiterAutoTuner.RecordRateLimited(method, err)
 	}
 }
 
@@ -1460,6 +1529,22 @@ func (u *Upstream) ShouldHandleMethod(method string) (v bool, err error) {
 		}
 	}
 
+	if v {
+	quirks:
+		for _, q := range common.ClientQuirksFor(u.NodeClient()) {
+			for _, m := range q.IgnoreMethods {
+				match, err := common.WildcardMatch(m, method)
+				if err != nil {
+					return false, err
+				}
+				if match {
+					v = false
+					break quirks
+				}
+			}
+		}
+	}
+
 	if cfg.AllowMethods != nil {
 		for _, m := range cfg.AllowMethods {
 			match, err := common.WildcardMatch(m, method)
@@ -1479,6 +1564,18 @@ func (u *Upstream) ShouldHandleMethod(method string) (v bool, err error) {
 	return v, nil
 }
 
+// MethodSupport returns what is known so far about the methods this upstream
+// handles: every method it has been asked about, including ones auto-ignored
+// after the upstream reported them unsupported.
+func (u *Upstream) MethodSupport() map[string]bool {
+	out := make(map[string]bool)
+	u.supportedMethods.Range(func(k, v interface{}) bool {
+		out[k.(string)] = v.(bool)
+		return true
+	})
+	return out
+}
+
 func (u *Upstream) detectFeatures(ctx context.Context) error {
 	cfg := u.Config()
 
@@ -1544,6 +1641,8 @@ func (u *Upstream) detectFeatures(ctx context.Context) error {
 			armer.SetExpectedChainId(uint64(realChainID))
 		}
 
+		u.detectNodeClient(ctx)
+
 		// @deprecated: NodeType-specific logic removed; availability is handled by blockAvailability bounds.
 
 		// TODO evm: check trace methods availability (by engine? erigon/geth/etc)
@@ -1555,6 +1654,73 @@ func (u *Upstream) detectFeatures(ctx context.Context) error {
 	return nil
 }
 
+// detectNodeClient records the node client behind the upstream and applies
+// the client quirks matching it, when clientQuirks is configured. Many
+// providers do not serve web3_clientVersion, so failures are only logged;
+// detection is retried on the next bootstrap.
+func (u *Upstream) detectNodeClient(ctx context.Context) {
+	if !common.ClientDetectionEnabled() || u.nodeClient.Load() != nil {
+		return
+	}
+	pr := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":75413,"method":"web3_clientVersion","params":[]}`))
+	pr.SetDirectives(&common.RequestDirectives{IsInternal: true})
+	resp, err := u.Forward(ctx, pr, true, false)
+	if resp != nil {
+		defer resp.Release()
+	}
+	if err == nil {
+		var jrr *common.JsonRpcResponse
+		jrr, err = resp.JsonRpcResponse()
+		if err == nil && jrr.Error != nil {
+			err = jrr.Error
+		}
+		if err == nil {
+			var raw string
+			if err = common.SonicCfg.Unmarshal(jrr.GetResultBytes(), &raw); err == nil {
+				if nc := common.ParseClientVersion(raw); nc != nil {
+					u.setNodeClient(nc)
+					return
+				}
+			}
+		}
+	}
+	u.logger.Debug().Err(err).Msg("could not detect upstream node client via web3_clientVersion")
+}
+
+func (u *Upstream) setNodeClient(nc *common.NodeClient) {
+	u.nodeClient.Store(nc)
+	// Method support resolved before detection did not account for quirks.
+	u.supportedMethods.Range(func(k, _ interface{}) bool {
+		u.supportedMethods.Delete(k)
+		return true
+	})
+	for _, q := range common.ClientQuirksFor(nc) {
+		if q.MaxBatchSize > 0 {
+			if c, ok := u.Client.(interface{ CapBatchMaxSize(int) }); ok {
+				c.CapBatchMaxSize(q.MaxBatchSize)
+			}
+		}
+	}
+	telemetry.MetricUpstreamClientInfo.WithLabelValues(
+		u.ProjectId,
+		u.VendorName(),
+		u.NetworkLabel(),
+		u.Id(),
+		nc.Name,
+		nc.Version,
+	).Set(1)
+	u.logger.Info().Str("client", nc.Name).Str("clientVersion", nc.Version).Msg("detected upstream node client")
+}
+
+// NodeClient returns the node client reported by the upstream, or nil when
+// it is not known (yet).
+func (u *Upstream) NodeClient() *common.NodeClient {
+	if u == nil {
+		return nil
+	}
+	return u.nodeClient.Load()
+}
+
 func (u *Upstream) guessVendorName() string {
 	endpoint := u.config.Endpoint
 	if endpoint == "" {
@@ -1593,6 +1759,9 @@ func (u *Upstream) shouldSkip(ctx context.Context, req *common.NormalizedRequest
 	if u.config.Shadow != nil && u.config.Shadow.Enabled {
 		return common.NewErrUpstreamShadowing(u.config.Id), true
 	}
+	if st := u.DrainState(); st != nil {
+		return common.NewErrUpstreamDraining(u.config.Id, st.Mode, st.Reason), true
+	}
 	method, _ := req.Method()
 
 	if u.config.Evm != nil && u.config.Evm.SkipWhenSyncing != nil && *u.config.Evm.SkipWhenSyncing {
@@ -1639,6 +1808,7 @@ func (u *Upstream) MarshalJSON() ([]byte, error) {
 		Id        string                            `json:"id"`
 		Metrics   map[string]*health.TrackedMetrics `json:"metrics"`
 		NetworkId string                            `json:"networkId"`
+		Client    *common.NodeClient                `json:"client,omitempty"`
 	}
 
 	metrics := u.metricsTracker.GetUpstreamMetrics(u)
@@ -1647,6 +1817,7 @@ func (u *Upstream) MarshalJSON() ([]byte, error) {
 		Id:        u.config.Id,
 		Metrics:   metrics,
 		NetworkId: u.NetworkId(),
+		Client:    u.NodeClient(),
 	}
 
 	return sonic.Marshal(uppub)
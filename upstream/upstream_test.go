@@ -70,6 +70,41 @@ func TestUpstream_SkipLogic(t *testing.T) {
 		assert.Nil(t, reason)
 	})
 
+	t.Run("ClientQuirkIgnoresMethod", func(t *testing.T) {
+		common.SetClientQuirks([]*common.ClientQuirkConfig{
+			{Client: "reth", MinVersion: "1.2.0", IgnoreMethods: []string{"trace_*"}},
+		})
+		defer common.SetClientQuirks(nil)
+
+		upstream := &Upstream{
+			config: &common.UpstreamConfig{
+				Id:           "test",
+				AllowMethods: []string{"trace_block"},
+			},
+			logger: &zerolog.Logger{},
+		}
+		upstream.nodeClient.Store(common.ParseClientVersion("reth/v1.3.1-0e9a3d8/x86_64-unknown-linux-gnu"))
+
+		reason, skip := upstream.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"trace_filter"}`)))
+		assert.True(t, skip)
+		assert.Contains(t, reason.Error(), "ErrUpstreamMethodIgnored")
+
+		// The upstream's own allowMethods still wins over the quirk.
+		reason, skip = upstream.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"trace_block"}`)))
+		assert.False(t, skip)
+		assert.Nil(t, reason)
+
+		// Versions outside the quirk's range are unaffected.
+		older := &Upstream{
+			config: &common.UpstreamConfig{Id: "test"},
+			logger: &zerolog.Logger{},
+		}
+		older.nodeClient.Store(common.ParseClientVersion("reth/v1.1.0/x86_64-unknown-linux-gnu"))
+		reason, skip = older.shouldSkip(context.TODO(), common.NewNormalizedRequest([]byte(`{"method":"trace_filter"}`)))
+		assert.False(t, skip)
+		assert.Nil(t, reason)
+	})
+
 	t.Run("MultipleMethodsWithWildcard", func(t *testing.T) {
 		upstream := &Upstream{
 			config: &common.UpstreamConfig{
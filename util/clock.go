@@ -0,0 +1,271 @@
+package util
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the time source used by pollers, TTL checks, backoff timers, rate
+// limiter windows and circuit breakers. The process-wide clock is the wall
+// clock; tests (and programs embedding erpc packages) can install a
+// VirtualClock with SetClock and move time forward explicitly instead of
+// sleeping.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer mirrors *time.Timer, with the channel exposed as a method so that
+// virtual implementations can provide their own.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker mirrors *time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+type clockHolder struct{ Clock }
+
+var currentClock atomic.Pointer[clockHolder]
+
+func init() {
+	currentClock.Store(&clockHolder{RealClock{}})
+}
+
+// SetClock replaces the process-wide clock and returns a function restoring
+// the previous one. Components capture timers and tickers when they start, so
+// install the clock before constructing them. Passing nil restores the wall
+// clock.
+func SetClock(c Clock) (restore func()) {
+	if c == nil {
+		c = RealClock{}
+	}
+	prev := currentClock.Swap(&clockHolder{c})
+	return func() { currentClock.Store(prev) }
+}
+
+// GetClock returns the process-wide clock.
+func GetClock() Clock {
+	return currentClock.Load().Clock
+}
+
+// Now returns the current time of the process-wide clock.
+func Now() time.Time {
+	return GetClock().Now()
+}
+
+// Since returns the time elapsed since t on the process-wide clock.
+func Since(t time.Time) time.Duration {
+	return GetClock().Now().Sub(t)
+}
+
+// NewTimer creates a timer on the process-wide clock.
+func NewTimer(d time.Duration) Timer {
+	return GetClock().NewTimer(d)
+}
+
+// NewTicker creates a ticker on the process-wide clock. Like time.NewTicker
+// it panics when d <= 0.
+func NewTicker(d time.Duration) Ticker {
+	return GetClock().NewTicker(d)
+}
+
+// After is the process-wide clock equivalent of time.After.
+func After(d time.Duration) <-chan time.Time {
+	return GetClock().NewTimer(d).C()
+}
+
+// RealClock is the wall clock.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time { return time.Now() }
+
+func (RealClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (RealClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// VirtualClock is a Clock that only moves when Advance or Set is called.
+// Timers and tickers fire in deadline order during the advance, each seeing
+// Now() equal to its own deadline. Like the runtime's timers, channels hold a
+// single pending tick and a slow receiver drops the rest.
+type VirtualClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*virtualWaiter
+}
+
+var _ Clock = (*VirtualClock)(nil)
+
+// NewVirtualClock returns a VirtualClock starting at start.
+func NewVirtualClock(start time.Time) *VirtualClock {
+	c := &VirtualClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *VirtualClock) NewTimer(d time.Duration) Timer {
+	return virtualTimer{c.addWaiter(d, 0)}
+}
+
+func (c *VirtualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for VirtualClock.NewTicker")
+	}
+	return virtualTicker{c.addWaiter(d, d)}
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker whose
+// deadline falls within the interval.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+	c.Set(target)
+}
+
+// Set moves the clock to t. Moving backwards only changes Now(); nothing
+// fires.
+func (c *VirtualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		w := c.nextDueLocked(t)
+		if w == nil {
+			break
+		}
+		c.now = w.at
+		select {
+		case w.c <- w.at:
+		default:
+		}
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+		} else {
+			c.removeLocked(w)
+		}
+	}
+	c.now = t
+}
+
+// BlockUntil waits until at least n timers or tickers are pending. Tests use
+// it to make sure a goroutine has armed its timer before calling Advance.
+func (c *VirtualClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// Pending returns the number of active timers and tickers.
+func (c *VirtualClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+func (c *VirtualClock) nextDueLocked(limit time.Time) *virtualWaiter {
+	if len(c.waiters) == 0 {
+		return nil
+	}
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].at.Before(c.waiters[j].at)
+	})
+	if w := c.waiters[0]; !w.at.After(limit) {
+		return w
+	}
+	return nil
+}
+
+func (c *VirtualClock) addWaiter(d, period time.Duration) *virtualWaiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &virtualWaiter{clock: c, c: make(chan time.Time, 1), period: period}
+	c.armLocked(w, d)
+	return w
+}
+
+func (c *VirtualClock) armLocked(w *virtualWaiter, d time.Duration) bool {
+	active := c.removeLocked(w)
+	w.at = c.now.Add(d)
+	c.waiters = append(c.waiters, w)
+	c.cond.Broadcast()
+	return active
+}
+
+func (c *VirtualClock) removeLocked(w *virtualWaiter) bool {
+	for i, o := range c.waiters {
+		if o == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type virtualWaiter struct {
+	clock  *VirtualClock
+	c      chan time.Time
+	at     time.Time
+	period time.Duration
+}
+
+func (w *virtualWaiter) C() <-chan time.Time { return w.c }
+
+func (w *virtualWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.removeLocked(w)
+}
+
+func (w *virtualWaiter) reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	if w.period > 0 {
+		w.period = d
+	}
+	return w.clock.armLocked(w, d)
+}
+
+type virtualTimer struct{ *virtualWaiter }
+
+func (t virtualTimer) Reset(d time.Duration) bool { return t.reset(d) }
+
+type virtualTicker struct{ *virtualWaiter }
+
+func (t virtualTicker) Stop() { t.virtualWaiter.Stop() }
+
+func (t virtualTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for VirtualClock ticker Reset")
+	}
+	t.reset(d)
+}
@@ -0,0 +1,99 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtualClock(t *testing.T) {
+	start := time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC)
+
+	t.Run("TimersFireInDeadlineOrder", func(t *testing.T) {
+		c := NewVirtualClock(start)
+		late := c.NewTimer(3 * time.Second)
+		early := c.NewTimer(time.Second)
+		stopped := c.NewTimer(2 * time.Second)
+		assert.True(t, stopped.Stop())
+		assert.False(t, stopped.Stop())
+
+		c.Advance(999 * time.Millisecond)
+		assert.Len(t, early.C(), 0)
+
+		c.Advance(5 * time.Second)
+		assert.Equal(t, start.Add(time.Second), <-early.C())
+		assert.Equal(t, start.Add(3*time.Second), <-late.C())
+		assert.Len(t, stopped.C(), 0)
+		assert.Equal(t, start.Add(5999*time.Millisecond), c.Now())
+		assert.Zero(t, c.Pending())
+	})
+
+	t.Run("TickerKeepsOnePendingTick", func(t *testing.T) {
+		c := NewVirtualClock(start)
+		tk := c.NewTicker(time.Second)
+		c.Advance(3500 * time.Millisecond)
+		assert.Equal(t, start.Add(time.Second), <-tk.C(), "later ticks are dropped while the first is unread")
+		assert.Len(t, tk.C(), 0)
+
+		c.Advance(time.Second)
+		assert.Equal(t, start.Add(4*time.Second), <-tk.C())
+
+		tk.Reset(10 * time.Second)
+		c.Advance(9 * time.Second)
+		assert.Len(t, tk.C(), 0)
+		c.Advance(time.Second)
+		assert.Len(t, tk.C(), 1)
+
+		tk.Stop()
+		<-tk.C()
+		c.Advance(time.Minute)
+		assert.Len(t, tk.C(), 0)
+	})
+
+	t.Run("TimerResetRearms", func(t *testing.T) {
+		c := NewVirtualClock(start)
+		tm := c.NewTimer(time.Second)
+		c.Advance(time.Second)
+		<-tm.C()
+		assert.False(t, tm.Reset(time.Second), "an expired timer is not active")
+		assert.True(t, tm.Reset(2*time.Second))
+		c.Advance(time.Second)
+		assert.Len(t, tm.C(), 0)
+		c.Advance(time.Second)
+		assert.Equal(t, start.Add(3*time.Second), <-tm.C())
+	})
+
+	t.Run("BlockUntilWaitsForArmedTimers", func(t *testing.T) {
+		c := NewVirtualClock(start)
+		done := make(chan struct{})
+		go func() {
+			<-c.NewTimer(time.Minute).C()
+			close(done)
+		}()
+		c.BlockUntil(1)
+		c.Advance(time.Minute)
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("timer did not fire")
+		}
+	})
+}
+
+func TestSetClock(t *testing.T) {
+	c := NewVirtualClock(time.Unix(1000, 0))
+	restore := SetClock(c)
+	assert.Equal(t, time.Unix(1000, 0), Now())
+	c.Advance(time.Minute)
+	assert.Equal(t, time.Minute, Since(time.Unix(1000, 0)))
+	ch := After(time.Second)
+	c.Advance(time.Second)
+	require.Len(t, ch, 1)
+
+	restore()
+	_, ok := GetClock().(RealClock)
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now(), Now(), time.Second)
+}
@@ -20,6 +20,7 @@ func ExtractUsefulHeaders(r *http.Response) map[string]interface{} {
 			strings.Contains(kl, "parent") ||
 			strings.Contains(kl, "error") ||
 			strings.Contains(kl, "rate-limit") ||
+			strings.HasPrefix(kl, "ratelimit") ||
 			kl == "content-type" ||
 			kl == "content-length" ||
 			kl == "server" ||